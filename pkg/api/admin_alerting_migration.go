@@ -0,0 +1,34 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrations/ualert"
+)
+
+// AdminPreviewAlertingMigration returns the report of a dry run of the migration of the dashboard alerts to unified
+// alerting: the alert rules and the contact points the dashboard alerts and the notification channels would be
+// migrated to, and the ones that can't be migrated.
+func (hs *HTTPServer) AdminPreviewAlertingMigration(c *models.ReqContext) response.Response {
+	report, err := hs.SQLStore.PreviewDashAlertMigration(c.Req.Context())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to preview the alerting migration", err)
+	}
+	return response.JSON(http.StatusOK, report)
+}
+
+// AdminRollbackAlertingMigration rolls back the migration of the dashboard alerts to unified alerting, they are
+// migrated again at the next start with unified alerting enabled.
+func (hs *HTTPServer) AdminRollbackAlertingMigration(c *models.ReqContext) response.Response {
+	err := hs.SQLStore.RollbackDashAlertMigration(c.Req.Context())
+	if errors.Is(err, ualert.ErrDashAlertMigrationNotRun) {
+		return response.Error(http.StatusConflict, err.Error(), err)
+	}
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to roll back the alerting migration", err)
+	}
+	return response.Success("Alerting migration rolled back")
+}
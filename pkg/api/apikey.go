@@ -26,10 +26,12 @@ func GetAPIKeys(c *models.ReqContext) response.Response {
 			expiration = &v
 		}
 		result[i] = &models.ApiKeyDTO{
-			Id:         t.Id,
-			Name:       t.Name,
-			Role:       t.Role,
-			Expiration: expiration,
+			Id:           t.Id,
+			Name:         t.Name,
+			Role:         t.Role,
+			Expiration:   expiration,
+			Scope:        t.Scope,
+			ScopeFolders: t.ScopeFolders,
 		}
 	}
 
@@ -59,6 +61,9 @@ func (hs *HTTPServer) AddAPIKey(c *models.ReqContext, cmd models.AddApiKeyComman
 	if !cmd.Role.IsValid() {
 		return response.Error(400, "Invalid role specified", nil)
 	}
+	if err := models.ValidateApiKeyScope(cmd.Scope, cmd.ScopeFolders); err != nil {
+		return response.Error(400, err.Error(), nil)
+	}
 
 	if hs.Cfg.ApiKeyMaxSecondsToLive != -1 {
 		if cmd.SecondsToLive == 0 {
@@ -199,6 +199,9 @@ func listenToSystemSignals(ctx context.Context, s *server.Server) {
 			if err := log.Reload(); err != nil {
 				fmt.Fprintf(os.Stderr, "Failed to reload loggers: %s\n", err)
 			}
+			if err := s.ReloadProvisioning(); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to reload provisioning: %s\n", err)
+			}
 		case sig := <-signalChan:
 			ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
 			defer cancel()
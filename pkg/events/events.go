@@ -62,3 +62,57 @@ type DataSourceCreated struct {
 	UID       string    `json:"uid"`
 	OrgID     int64     `json:"org_id"`
 }
+
+// AlertingResourceChanged is published when a user creates, updates or deletes an alerting resource, for auditing.
+// Before and After are the SHA-256 digests of the JSON of the resource before and after the change, empty if there
+// is no resource.
+type AlertingResourceChanged struct {
+	Timestamp    time.Time `json:"timestamp"`
+	OrgID        int64     `json:"org_id"`
+	UserID       int64     `json:"user_id"`
+	Login        string    `json:"login"`
+	APIKeyID     int64     `json:"api_key_id,omitempty"`
+	Action       string    `json:"action"`
+	ResourceType string    `json:"resource_type"`
+	ResourceUID  string    `json:"resource_uid,omitempty"`
+	Before       string    `json:"before,omitempty"`
+	After        string    `json:"after,omitempty"`
+}
+
+// AlertmanagerConfigurationSaved is published when a version of the Alertmanager configuration of an organization of
+// unified alerting is saved, so that the configuration is applied without waiting for the next sync.
+type AlertmanagerConfigurationSaved struct {
+	Timestamp time.Time `json:"timestamp"`
+	OrgID     int64     `json:"org_id"`
+}
+
+// AlertStateChanged is published when the state of an alert of unified alerting changes, for the clients streaming
+// the state of the alerts of the organization.
+type AlertStateChanged struct {
+	Timestamp     time.Time         `json:"timestamp"`
+	OrgID         int64             `json:"org_id"`
+	RuleUID       string            `json:"rule_uid"`
+	RuleTitle     string            `json:"rule_title"`
+	NamespaceUID  string            `json:"namespace_uid"`
+	Labels        map[string]string `json:"labels"`
+	PreviousState string            `json:"previous_state"`
+	State         string            `json:"state"`
+	Value         string            `json:"value,omitempty"`
+}
+
+// AlertNotificationSent is published when an integration of a contact point of unified alerting sends a
+// notification, for the clients streaming the notifications of the organization.
+type AlertNotificationSent struct {
+	Timestamp   time.Time         `json:"timestamp"`
+	OrgID       int64             `json:"org_id"`
+	Receiver    string            `json:"receiver"`
+	Integration string            `json:"integration"`
+	GroupLabels map[string]string `json:"group_labels,omitempty"`
+	Alerts      []NotifiedAlert   `json:"alerts"`
+}
+
+// NotifiedAlert is an alert of a notification.
+type NotifiedAlert struct {
+	Labels map[string]string `json:"labels"`
+	Status string            `json:"status"`
+}
@@ -0,0 +1,113 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// AbsentCommand is an expression command that tells whether a query returned no series, so a rule can alert when
+// an expected series disappears instead of handling it as no data. The result is a single number with the labels
+// of the command, which is 1 if no series of the query has these labels and a value, and 0 otherwise.
+type AbsentCommand struct {
+	VarToCheck string
+	// Labels are the labels the expected series must have, and the labels of the result.
+	Labels data.Labels
+	refID  string
+}
+
+// NewAbsentCommand creates a new AbsentCommand.
+func NewAbsentCommand(refID, varToCheck string, labels data.Labels) *AbsentCommand {
+	return &AbsentCommand{
+		VarToCheck: varToCheck,
+		Labels:     labels,
+		refID:      refID,
+	}
+}
+
+// UnmarshalAbsentCommand creates an AbsentCommand from Grafana's frontend query.
+func UnmarshalAbsentCommand(rn *rawNode) (*AbsentCommand, error) {
+	rawVar, ok := rn.Query["expression"]
+	if !ok {
+		return nil, fmt.Errorf("no variable specified to check the absence of for refId %v", rn.RefID)
+	}
+	varToCheck, ok := rawVar.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected absent input variable to be a string, got %T for refId %v", rawVar, rn.RefID)
+	}
+	varToCheck = strings.TrimPrefix(varToCheck, "$")
+
+	var labels data.Labels
+	if rawLabels, ok := rn.Query["labels"]; ok && rawLabels != nil {
+		m, ok := rawLabels.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected absent labels to be an object, got %T for refId %v", rawLabels, rn.RefID)
+		}
+		labels = make(data.Labels, len(m))
+		for k, v := range m {
+			s, ok := v.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected absent label %q to be a string, got %T for refId %v", k, v, rn.RefID)
+			}
+			labels[k] = s
+		}
+	}
+
+	return NewAbsentCommand(rn.RefID, varToCheck, labels), nil
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (ac *AbsentCommand) NeedsVars() []string {
+	return []string{ac.VarToCheck}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (ac *AbsentCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathexp.Results, error) {
+	absent := 1.0
+	for _, val := range vars[ac.VarToCheck].Values {
+		if ac.matches(val.GetLabels()) && hasValue(val) {
+			absent = 0
+			break
+		}
+	}
+	var labels data.Labels
+	if ac.Labels != nil {
+		labels = ac.Labels.Copy()
+	}
+	num := mathexp.NewNumber(ac.refID, labels)
+	num.SetValue(&absent)
+	return mathexp.Results{Values: []mathexp.Value{num}}, nil
+}
+
+// matches returns true if the labels have all the labels of the command.
+func (ac *AbsentCommand) matches(labels data.Labels) bool {
+	for k, v := range ac.Labels {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// hasValue returns true if the value is a number with a value, or a series with a point with a value.
+func hasValue(val mathexp.Value) bool {
+	switch v := val.(type) {
+	case mathexp.Number:
+		return v.GetFloat64Value() != nil
+	case mathexp.Scalar:
+		return v.GetFloat64Value() != nil
+	case mathexp.Series:
+		for i := 0; i < v.Len(); i++ {
+			if v.GetValue(i) != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package expr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+func TestAbsentCommand(t *testing.T) {
+	series := func(labels data.Labels, values ...*float64) mathexp.Series {
+		s := mathexp.NewSeries("A", labels, len(values))
+		for i, v := range values {
+			require.NoError(t, s.SetPoint(i, time.Unix(int64(i), 0), v))
+		}
+		return s
+	}
+	execute := func(t *testing.T, labels map[string]interface{}, values ...mathexp.Value) mathexp.Number {
+		t.Helper()
+		query := map[string]interface{}{"type": "absent", "expression": "$A"}
+		if labels != nil {
+			query["labels"] = labels
+		}
+		cmd, err := UnmarshalAbsentCommand(&rawNode{RefID: "B", Query: query})
+		require.NoError(t, err)
+		require.Equal(t, []string{"A"}, cmd.NeedsVars())
+		res, err := cmd.Execute(context.Background(), mathexp.Vars{"A": mathexp.Results{Values: values}})
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		return res.Values[0].(mathexp.Number)
+	}
+
+	t.Run("no series is absent", func(t *testing.T) {
+		num := execute(t, map[string]interface{}{"job": "api"})
+		require.Equal(t, fp(1), num.GetFloat64Value())
+		require.Equal(t, data.Labels{"job": "api"}, num.GetLabels())
+	})
+
+	t.Run("series without values are absent", func(t *testing.T) {
+		num := execute(t, nil, series(data.Labels{"job": "api"}, nil, nil), series(nil))
+		require.Equal(t, fp(1), num.GetFloat64Value())
+		require.Nil(t, num.GetLabels())
+	})
+
+	t.Run("a series with a value is present", func(t *testing.T) {
+		num := execute(t, nil, series(data.Labels{"job": "api"}, nil, fp(3)))
+		require.Equal(t, fp(0), num.GetFloat64Value())
+	})
+
+	t.Run("only the series with the labels are checked", func(t *testing.T) {
+		values := []mathexp.Value{series(data.Labels{"job": "api", "instance": "a"}, fp(1))}
+		require.Equal(t, fp(0), execute(t, map[string]interface{}{"job": "api"}, values...).GetFloat64Value())
+		require.Equal(t, fp(1), execute(t, map[string]interface{}{"job": "db"}, values...).GetFloat64Value())
+	})
+
+	t.Run("fails if the labels are not strings", func(t *testing.T) {
+		_, err := UnmarshalAbsentCommand(&rawNode{RefID: "B", Query: map[string]interface{}{
+			"expression": "A",
+			"labels":     map[string]interface{}{"job": 1.0},
+		}})
+		require.Error(t, err)
+	})
+}
@@ -119,11 +119,16 @@ func (gr *ReduceCommand) NeedsVars() []string {
 func (gr *ReduceCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathexp.Results, error) {
 	newRes := mathexp.Results{}
 	for _, val := range vars[gr.VarToReduce].Values {
-		series, ok := val.(mathexp.Series)
-		if !ok {
+		var num mathexp.Number
+		var err error
+		switch series := val.(type) {
+		case mathexp.Series:
+			num, err = series.Reduce(gr.refID, gr.Reducer)
+		case mathexp.SpilledSeries:
+			num, err = series.Reduce(gr.refID, gr.Reducer)
+		default:
 			return newRes, fmt.Errorf("can only reduce type series, got type %v", val.Type())
 		}
-		num, err := series.Reduce(gr.refID, gr.Reducer)
 		if err != nil {
 			return newRes, err
 		}
@@ -138,22 +143,30 @@ type ResampleCommand struct {
 	VarToResample string
 	Downsampler   string
 	Upsampler     string
-	TimeRange     TimeRange
-	refID         string
+	// Fill is the fill mode of the points without value of the resampled series: null, previous, zero or drop.
+	Fill      string
+	TimeRange TimeRange
+	refID     string
 }
 
 // NewResampleCommand creates a new ResampleCMD.
-func NewResampleCommand(refID, rawWindow, varToResample string, downsampler string, upsampler string, tr TimeRange) (*ResampleCommand, error) {
+func NewResampleCommand(refID, rawWindow, varToResample string, downsampler string, upsampler string, fill string, tr TimeRange) (*ResampleCommand, error) {
 	// TODO: validate reducer here, before execution
 	window, err := gtime.ParseDuration(rawWindow)
 	if err != nil {
 		return nil, fmt.Errorf(`failed to parse resample "window" duration field %q: %w`, window, err)
 	}
+	switch fill {
+	case "", mathexp.FillNull, mathexp.FillPrevious, mathexp.FillZero, mathexp.FillDrop:
+	default:
+		return nil, fmt.Errorf("unsupported resample fill mode %q, should be one of null, previous, zero or drop", fill)
+	}
 	return &ResampleCommand{
 		Window:        window,
 		VarToResample: varToResample,
 		Downsampler:   downsampler,
 		Upsampler:     upsampler,
+		Fill:          fill,
 		TimeRange:     tr,
 		refID:         refID,
 	}, nil
@@ -199,7 +212,16 @@ func UnmarshalResampleCommand(rn *rawNode) (*ResampleCommand, error) {
 		return nil, fmt.Errorf("expected resample downsampler to be a string, got type %T for refId %v", upsampler, rn.RefID)
 	}
 
-	return NewResampleCommand(rn.RefID, window, varToResample, downsampler, upsampler, rn.TimeRange)
+	// the fill mode is optional, the points without value are null by default
+	var fill string
+	if rawFill, ok := rn.Query["fill"]; ok {
+		fill, ok = rawFill.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected resample fill to be a string, got type %T for refId %v", rawFill, rn.RefID)
+		}
+	}
+
+	return NewResampleCommand(rn.RefID, window, varToResample, downsampler, upsampler, fill, rn.TimeRange)
 }
 
 // NeedsVars returns the variable names (refIds) that are dependencies
@@ -221,6 +243,10 @@ func (gr *ResampleCommand) Execute(ctx context.Context, vars mathexp.Vars) (math
 		if err != nil {
 			return newRes, err
 		}
+		num, err = num.Fill(gr.refID, gr.Fill)
+		if err != nil {
+			return newRes, err
+		}
 		newRes.Values = append(newRes.Values, num)
 	}
 	return newRes, nil
@@ -240,6 +266,20 @@ const (
 	TypeResample
 	// TypeClassicConditions is the CMDType for the classic condition operation.
 	TypeClassicConditions
+	// TypeThreshold is the CMDType for a threshold expression.
+	TypeThreshold
+	// TypeMovingWindow is the CMDType for a moving window expression.
+	TypeMovingWindow
+	// TypeRate is the CMDType for a rate expression.
+	TypeRate
+	// TypeOutliers is the CMDType for an outlier detection expression.
+	TypeOutliers
+	// TypeForecast is the CMDType for a forecast expression.
+	TypeForecast
+	// TypeAbsent is the CMDType for an absent series expression.
+	TypeAbsent
+	// TypeJoin is the CMDType for a join expression.
+	TypeJoin
 )
 
 func (gt CommandType) String() string {
@@ -252,6 +292,20 @@ func (gt CommandType) String() string {
 		return "resample"
 	case TypeClassicConditions:
 		return "classic_conditions"
+	case TypeThreshold:
+		return "threshold"
+	case TypeMovingWindow:
+		return "moving_window"
+	case TypeRate:
+		return "rate"
+	case TypeOutliers:
+		return "outliers"
+	case TypeForecast:
+		return "forecast"
+	case TypeAbsent:
+		return "absent"
+	case TypeJoin:
+		return "join"
 	default:
 		return "unknown"
 	}
@@ -268,6 +322,20 @@ func ParseCommandType(s string) (CommandType, error) {
 		return TypeResample, nil
 	case "classic_conditions":
 		return TypeClassicConditions, nil
+	case "threshold":
+		return TypeThreshold, nil
+	case "moving_window":
+		return TypeMovingWindow, nil
+	case "rate":
+		return TypeRate, nil
+	case "outliers":
+		return TypeOutliers, nil
+	case "forecast":
+		return TypeForecast, nil
+	case "absent":
+		return TypeAbsent, nil
+	case "join":
+		return TypeJoin, nil
 	default:
 		return TypeUnknown, fmt.Errorf("'%v' is not a recognized expression type", s)
 	}
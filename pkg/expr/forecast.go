@@ -0,0 +1,121 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/gtime"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+const (
+	defaultForecastAlpha = 0.5
+	defaultForecastBeta  = 0.1
+)
+
+// ForecastCommand is an expression command that turns series into the value predicted at a horizon after their
+// last point, such as the usage of a disk in 4 hours.
+type ForecastCommand struct {
+	VarToForecast string
+	Algorithm     string
+	Horizon       time.Duration
+	// Alpha and Beta are the smoothing factors of the level and the trend of Holt's method.
+	Alpha float64
+	Beta  float64
+	refID string
+}
+
+// NewForecastCommand creates a new ForecastCommand.
+func NewForecastCommand(refID, varToForecast, algorithm, rawHorizon string, alpha, beta float64) (*ForecastCommand, error) {
+	switch algorithm {
+	case mathexp.ForecastLinear, mathexp.ForecastHolt:
+	default:
+		return nil, fmt.Errorf("forecast algorithm %q is not one of %s, %s for refId %v", algorithm, mathexp.ForecastLinear, mathexp.ForecastHolt, refID)
+	}
+	horizon, err := gtime.ParseDuration(rawHorizon)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to parse forecast "horizon" duration field %q: %w`, rawHorizon, err)
+	}
+	if alpha <= 0 || alpha > 1 || beta <= 0 || beta > 1 {
+		return nil, fmt.Errorf("forecast smoothing factors must be greater than 0 and at most 1, got alpha %v and beta %v for refId %v", alpha, beta, refID)
+	}
+	return &ForecastCommand{
+		VarToForecast: varToForecast,
+		Algorithm:     algorithm,
+		Horizon:       horizon,
+		Alpha:         alpha,
+		Beta:          beta,
+		refID:         refID,
+	}, nil
+}
+
+// UnmarshalForecastCommand creates a ForecastCommand from Grafana's frontend query.
+func UnmarshalForecastCommand(rn *rawNode) (*ForecastCommand, error) {
+	rawVar, ok := rn.Query["expression"]
+	if !ok {
+		return nil, fmt.Errorf("no variable specified to forecast for refId %v", rn.RefID)
+	}
+	varToForecast, ok := rawVar.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected forecast input variable to be a string, got %T for refId %v", rawVar, rn.RefID)
+	}
+	varToForecast = strings.TrimPrefix(varToForecast, "$")
+
+	rawAlgorithm, ok := rn.Query["algorithm"]
+	if !ok {
+		return nil, fmt.Errorf("no algorithm specified in forecast command for refId %v", rn.RefID)
+	}
+	algorithm, ok := rawAlgorithm.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected forecast algorithm to be a string, got %T for refId %v", rawAlgorithm, rn.RefID)
+	}
+
+	rawHorizon, ok := rn.Query["horizon"]
+	if !ok {
+		return nil, fmt.Errorf("no horizon specified in forecast command for refId %v", rn.RefID)
+	}
+	horizon, ok := rawHorizon.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected forecast horizon to be a string, got %T for refId %v", rawHorizon, rn.RefID)
+	}
+
+	alpha, beta := defaultForecastAlpha, defaultForecastBeta
+	if rawAlpha, ok := rn.Query["alpha"]; ok && rawAlpha != nil {
+		if alpha, ok = rawAlpha.(float64); !ok {
+			return nil, fmt.Errorf("expected forecast alpha to be a number, got %T for refId %v", rawAlpha, rn.RefID)
+		}
+	}
+	if rawBeta, ok := rn.Query["beta"]; ok && rawBeta != nil {
+		if beta, ok = rawBeta.(float64); !ok {
+			return nil, fmt.Errorf("expected forecast beta to be a number, got %T for refId %v", rawBeta, rn.RefID)
+		}
+	}
+
+	return NewForecastCommand(rn.RefID, varToForecast, algorithm, horizon, alpha, beta)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (fc *ForecastCommand) NeedsVars() []string {
+	return []string{fc.VarToForecast}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (fc *ForecastCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathexp.Results, error) {
+	newRes := mathexp.Results{}
+	for _, val := range vars[fc.VarToForecast].Values {
+		series, ok := val.(mathexp.Series)
+		if !ok {
+			return newRes, fmt.Errorf("can only forecast type series, got type %v", val.Type())
+		}
+		num, err := series.Forecast(fc.refID, fc.Algorithm, fc.Horizon, fc.Alpha, fc.Beta)
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, num)
+	}
+	return newRes, nil
+}
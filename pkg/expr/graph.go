@@ -4,9 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/grafana/grafana/pkg/expr/mathexp"
 
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	tlog "github.com/opentracing/opentracing-go/log"
 	"gonum.org/v1/gonum/graph"
 	"gonum.org/v1/gonum/graph/simple"
 	"gonum.org/v1/gonum/graph/topo"
@@ -49,17 +53,49 @@ type DataPipeline []Node
 // map of the refId of the of each command
 func (dp *DataPipeline) execute(c context.Context, s *Service) (mathexp.Vars, error) {
 	vars := make(mathexp.Vars)
+	trace := traceFromContext(c)
+	observer := queryObserverFromContext(c)
 	for _, node := range *dp {
-		res, err := node.Execute(c, vars, s)
+		start := time.Now()
+		span, spanCtx := startNodeSpan(c, node)
+		res, err := node.Execute(spanCtx, vars, s)
+		if trace != nil {
+			trace.record(node, vars, res, err, time.Since(start))
+		}
+		if dsNode, ok := node.(*DSNode); ok && observer != nil {
+			observer(queryStats(dsNode, res, err, time.Since(start)))
+		}
 		if err != nil {
+			ext.Error.Set(span, true)
+			span.LogFields(tlog.Error(err))
+			span.Finish()
 			return nil, err
 		}
+		span.Finish()
 
 		vars[node.RefID()] = res
 	}
 	return vars, nil
 }
 
+// startNodeSpan starts the span of the execution of the node, a child of the span of the context.
+func startNodeSpan(c context.Context, node Node) (opentracing.Span, context.Context) {
+	operation := "expr.expression"
+	if node.NodeType() == TypeDatasourceNode {
+		operation = "expr.datasource"
+	}
+	span, spanCtx := opentracing.StartSpanFromContext(c, operation)
+	span.SetTag("ref_id", node.RefID())
+	switch n := node.(type) {
+	case *DSNode:
+		span.SetTag("datasource_uid", n.datasourceUID)
+		span.SetTag("org_id", n.orgID)
+	case *CMDNode:
+		span.SetTag("command", n.CMDType.String())
+	}
+	return span, spanCtx
+}
+
 // BuildPipeline builds a graph of the nodes, and returns the nodes in an
 // executable order.
 func (s *Service) buildPipeline(req *Request) (DataPipeline, error) {
@@ -141,6 +177,7 @@ func (s *Service) buildGraph(req *Request) (*simple.DirectedGraph, error) {
 			Query:         rawQueryProp,
 			RefID:         query.RefID,
 			TimeRange:     query.TimeRange,
+			TimeShift:     query.TimeShift,
 			QueryType:     query.QueryType,
 			DatasourceUID: query.DatasourceUID,
 		}
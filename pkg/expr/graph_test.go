@@ -1,9 +1,12 @@
 package expr
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
 
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
 	"github.com/stretchr/testify/require"
 )
 
@@ -218,3 +221,32 @@ func getRefIDOrder(nodes []Node) []string {
 	}
 	return ids
 }
+
+func TestDataPipelineSpans(t *testing.T) {
+	tracer := mocktracer.New()
+	opentracing.SetGlobalTracer(tracer)
+	t.Cleanup(func() { opentracing.SetGlobalTracer(opentracing.NoopTracer{}) })
+	parent := tracer.StartSpan("alerting.rule.evaluation")
+	ctx := opentracing.ContextWithSpan(context.Background(), parent)
+
+	math, err := NewMathCommand("A", "1 + 1")
+	require.NoError(t, err)
+	reduce := NewReduceCommand("B", "mean", "A")
+	pipeline := DataPipeline{
+		&CMDNode{baseNode: baseNode{refID: "A"}, CMDType: TypeMath, Command: math},
+		&CMDNode{baseNode: baseNode{refID: "B"}, CMDType: TypeReduce, Command: reduce},
+	}
+	_, err = pipeline.execute(ctx, nil)
+	require.Error(t, err)
+
+	spans := tracer.FinishedSpans()
+	require.Len(t, spans, 2)
+	for i, refID := range []string{"A", "B"} {
+		require.Equal(t, "expr.expression", spans[i].OperationName)
+		require.Equal(t, refID, spans[i].Tag("ref_id"))
+		require.Equal(t, parent.Context().(mocktracer.MockSpanContext).SpanID, spans[i].ParentID)
+	}
+	require.Equal(t, "math", spans[0].Tag("command"))
+	require.Nil(t, spans[0].Tag("error"))
+	require.Equal(t, true, spans[1].Tag("error"), "the span of the node that failed is in error")
+}
@@ -0,0 +1,115 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/gtime"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// JoinCommand is an expression command that combines the results of two queries, possibly to different
+// datasources, by pairing their series on labels and time, such as the errors from Loki divided by the requests
+// from Prometheus.
+type JoinCommand struct {
+	Left  string
+	Right string
+	// On are the labels the pairs must have the same values for, the shared labels of the pairs when empty.
+	On       []string
+	Operator string
+	// Tolerance is the maximum time between the paired points of the series.
+	Tolerance time.Duration
+	refID     string
+}
+
+// NewJoinCommand creates a new JoinCommand.
+func NewJoinCommand(refID, left, right string, on []string, operator, rawTolerance string) (*JoinCommand, error) {
+	var tolerance time.Duration
+	if rawTolerance != "" {
+		var err error
+		if tolerance, err = gtime.ParseDuration(rawTolerance); err != nil {
+			return nil, fmt.Errorf(`failed to parse join "tolerance" duration field %q: %w`, rawTolerance, err)
+		}
+		if tolerance < 0 {
+			return nil, fmt.Errorf("join tolerance must not be negative, got %v for refId %v", tolerance, refID)
+		}
+	}
+	return &JoinCommand{
+		Left:      left,
+		Right:     right,
+		On:        on,
+		Operator:  operator,
+		Tolerance: tolerance,
+		refID:     refID,
+	}, nil
+}
+
+// UnmarshalJoinCommand creates a JoinCommand from Grafana's frontend query.
+func UnmarshalJoinCommand(rn *rawNode) (*JoinCommand, error) {
+	getVar := func(key string) (string, error) {
+		rawVar, ok := rn.Query[key]
+		if !ok {
+			return "", fmt.Errorf("no %s variable specified to join for refId %v", key, rn.RefID)
+		}
+		v, ok := rawVar.(string)
+		if !ok {
+			return "", fmt.Errorf("expected join %s variable to be a string, got %T for refId %v", key, rawVar, rn.RefID)
+		}
+		return strings.TrimPrefix(v, "$"), nil
+	}
+	left, err := getVar("left")
+	if err != nil {
+		return nil, err
+	}
+	right, err := getVar("right")
+	if err != nil {
+		return nil, err
+	}
+
+	rawOperator, ok := rn.Query["operator"]
+	if !ok {
+		return nil, fmt.Errorf("no operator specified in join command for refId %v", rn.RefID)
+	}
+	operator, ok := rawOperator.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected join operator to be a string, got %T for refId %v", rawOperator, rn.RefID)
+	}
+
+	var on []string
+	if rawOn, ok := rn.Query["on"]; ok && rawOn != nil {
+		list, ok := rawOn.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected join labels to be a list, got %T for refId %v", rawOn, rn.RefID)
+		}
+		for _, rawLabel := range list {
+			label, ok := rawLabel.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected join labels to be strings, got %T for refId %v", rawLabel, rn.RefID)
+			}
+			on = append(on, label)
+		}
+	}
+
+	var tolerance string
+	if rawTolerance, ok := rn.Query["tolerance"]; ok && rawTolerance != nil {
+		if tolerance, ok = rawTolerance.(string); !ok {
+			return nil, fmt.Errorf("expected join tolerance to be a string, got %T for refId %v", rawTolerance, rn.RefID)
+		}
+	}
+
+	return NewJoinCommand(rn.RefID, left, right, on, operator, tolerance)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (jc *JoinCommand) NeedsVars() []string {
+	return []string{jc.Left, jc.Right}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (jc *JoinCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathexp.Results, error) {
+	return mathexp.Join(jc.refID, vars[jc.Left], vars[jc.Right], jc.On, jc.Operator, jc.Tolerance)
+}
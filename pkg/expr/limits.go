@@ -0,0 +1,145 @@
+package expr
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// ResultLimits caps the size of the result of each data source query of a Request, so a query returning more data
+// than expected doesn't exhaust the memory of the evaluation. A limit of 0 is no limit.
+//
+// The results over a limit are truncated deterministically: the frames and the series are sorted by labels and the
+// first ones are kept, the series keep their most recent points. A warning notice is attached to the first frame of
+// the truncated result.
+type ResultLimits struct {
+	// MaxFrames is the maximum number of frames of the response of a query.
+	MaxFrames int
+	// MaxSeries is the maximum number of series, or numbers, of the result of a query.
+	MaxSeries int
+	// MaxDataPoints is the maximum number of points of all the series of the result of a query.
+	MaxDataPoints int
+}
+
+// IsEmpty returns true if there is no limit.
+func (l ResultLimits) IsEmpty() bool {
+	return l.MaxFrames <= 0 && l.MaxSeries <= 0 && l.MaxDataPoints <= 0
+}
+
+// limitFrames truncates the frames of the response of the query refID to MaxFrames.
+func (l ResultLimits) limitFrames(refID string, frames data.Frames) (data.Frames, []data.Notice) {
+	if l.MaxFrames <= 0 || len(frames) <= l.MaxFrames {
+		return frames, nil
+	}
+	sorted := make(data.Frames, len(frames))
+	copy(sorted, frames)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return frameKey(sorted[i]) < frameKey(sorted[j])
+	})
+	expressionsResultTruncations.WithLabelValues("frames").Inc()
+	return sorted[:l.MaxFrames], []data.Notice{truncationNotice(refID, len(frames), l.MaxFrames, "frames")}
+}
+
+// limitValues truncates the values of the result of the query refID to MaxSeries and MaxDataPoints.
+func (l ResultLimits) limitValues(refID string, vals []mathexp.Value) ([]mathexp.Value, []data.Notice) {
+	var notices []data.Notice
+	sorted := false
+	sortValues := func() {
+		if sorted {
+			return
+		}
+		s := make([]mathexp.Value, len(vals))
+		copy(s, vals)
+		sort.SliceStable(s, func(i, j int) bool {
+			return s[i].GetLabels().String() < s[j].GetLabels().String()
+		})
+		vals, sorted = s, true
+	}
+
+	if l.MaxSeries > 0 && len(vals) > l.MaxSeries {
+		sortValues()
+		notices = append(notices, truncationNotice(refID, len(vals), l.MaxSeries, "series"))
+		expressionsResultTruncations.WithLabelValues("series").Inc()
+		vals = vals[:l.MaxSeries]
+	}
+
+	if l.MaxDataPoints <= 0 {
+		return vals, notices
+	}
+	total := 0
+	for _, v := range vals {
+		if s, ok := v.(mathexp.Series); ok {
+			total += s.Len()
+		}
+	}
+	if total <= l.MaxDataPoints {
+		return vals, notices
+	}
+	sortValues()
+	notices = append(notices, truncationNotice(refID, total, l.MaxDataPoints, "data points"))
+	expressionsResultTruncations.WithLabelValues("datapoints").Inc()
+
+	remaining := l.MaxDataPoints
+	limited := make([]mathexp.Value, 0, len(vals))
+	for _, v := range vals {
+		s, ok := v.(mathexp.Series)
+		if !ok {
+			limited = append(limited, v)
+			continue
+		}
+		if remaining == 0 {
+			continue
+		}
+		if s.Len() > remaining {
+			s = lastPoints(s, remaining)
+		}
+		remaining -= s.Len()
+		limited = append(limited, s)
+	}
+	return limited, notices
+}
+
+// lastPoints returns a series with the n most recent points of the series.
+func lastPoints(s mathexp.Series, n int) mathexp.Series {
+	s.SortByTime(false)
+	out := mathexp.NewSeries(s.GetName(), s.GetLabels(), n)
+	offset := s.Len() - n
+	for i := 0; i < n; i++ {
+		t, v := s.GetPoint(offset + i)
+		_ = out.SetPoint(i, t, v) // the index is within the size of the series
+	}
+	return out
+}
+
+func frameKey(f *data.Frame) string {
+	var b strings.Builder
+	b.WriteString(f.Name)
+	for _, field := range f.Fields {
+		b.WriteString("\x00")
+		b.WriteString(field.Labels.String())
+	}
+	return b.String()
+}
+
+func truncationNotice(refID string, count, limit int, what string) data.Notice {
+	return data.Notice{
+		Severity: data.NoticeSeverityWarning,
+		Text:     fmt.Sprintf("the result of query %s was truncated: %d %s exceed the limit of %d", refID, count, what, limit),
+	}
+}
+
+// attachNotices attaches the notices to the first frame of the values.
+func attachNotices(vals []mathexp.Value, notices []data.Notice) {
+	if len(notices) == 0 || len(vals) == 0 {
+		return
+	}
+	frame := vals[0].AsDataFrame()
+	if frame.Meta == nil {
+		frame.Meta = &data.FrameMeta{}
+	}
+	frame.Meta.Notices = append(frame.Meta.Notices, notices...)
+}
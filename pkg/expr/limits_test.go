@@ -0,0 +1,74 @@
+package expr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+func TestResultLimits(t *testing.T) {
+	series := func(pod string, points int) mathexp.Value {
+		s := mathexp.NewSeries("A", data.Labels{"pod": pod}, points)
+		for i := 0; i < points; i++ {
+			require.NoError(t, s.SetPoint(i, time.Unix(int64(i*10), 0), fp(float64(i))))
+		}
+		return s
+	}
+	pods := func(vals []mathexp.Value) []string {
+		result := make([]string, 0, len(vals))
+		for _, v := range vals {
+			result = append(result, v.GetLabels()["pod"])
+		}
+		return result
+	}
+
+	t.Run("no limits keep the values", func(t *testing.T) {
+		vals := []mathexp.Value{series("b", 3), series("a", 3)}
+		limited, notices := ResultLimits{}.limitValues("A", vals)
+		require.Equal(t, vals, limited)
+		require.Empty(t, notices)
+	})
+
+	t.Run("series over the limit are truncated by labels", func(t *testing.T) {
+		vals := []mathexp.Value{series("c", 1), series("a", 1), series("b", 1)}
+		limited, notices := ResultLimits{MaxSeries: 2}.limitValues("A", vals)
+		require.Equal(t, []string{"a", "b"}, pods(limited))
+		require.Len(t, notices, 1)
+		require.Equal(t, data.NoticeSeverityWarning, notices[0].Severity)
+		require.Equal(t, "the result of query A was truncated: 3 series exceed the limit of 2", notices[0].Text)
+	})
+
+	t.Run("data points over the limit keep the most recent points", func(t *testing.T) {
+		vals := []mathexp.Value{series("b", 3), series("a", 3), series("c", 3)}
+		limited, notices := ResultLimits{MaxDataPoints: 5}.limitValues("A", vals)
+		require.Equal(t, []string{"a", "b"}, pods(limited))
+		require.Equal(t, 3, limited[0].(mathexp.Series).Len())
+		b := limited[1].(mathexp.Series)
+		require.Equal(t, 2, b.Len())
+		require.Equal(t, time.Unix(10, 0), b.GetTime(0))
+		require.Equal(t, fp(2), b.GetValue(1))
+		require.Len(t, notices, 1)
+		require.Equal(t, "the result of query A was truncated: 9 data points exceed the limit of 5", notices[0].Text)
+	})
+
+	t.Run("frames over the limit are truncated by labels", func(t *testing.T) {
+		frames := data.Frames{
+			series("b", 1).AsDataFrame(),
+			series("a", 1).AsDataFrame(),
+		}
+		limited, notices := ResultLimits{MaxFrames: 1}.limitFrames("A", frames)
+		require.Equal(t, data.Frames{frames[1]}, limited)
+		require.Len(t, notices, 1)
+	})
+
+	t.Run("notices are attached to the first frame", func(t *testing.T) {
+		vals := []mathexp.Value{series("a", 1)}
+		notices := []data.Notice{truncationNotice("A", 2, 1, "series")}
+		attachNotices(vals, notices)
+		require.Equal(t, notices, vals[0].AsDataFrame().Meta.Notices)
+	})
+}
@@ -0,0 +1,100 @@
+package expr
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// EvalModeLogs is the evalMode of a data source query which returns log lines instead of series or numbers. Each
+// stream of log lines becomes a number, the count of its lines, with the most recent lines as samples.
+const EvalModeLogs = "logs"
+
+// defaultLogSamples is the number of sample lines kept by stream when the query does not set logSamples.
+const defaultLogSamples = 5
+
+// LogSamples are the most recent lines of a stream of log lines counted by a query in logs mode, newest first. They
+// are the Custom meta of the frame of the number of the stream.
+type LogSamples []string
+
+// logsFrameFields returns the index of the time field and of the line field of a frame with log lines, or -1.
+func logsFrameFields(frame *data.Frame) (timeIdx, lineIdx int) {
+	timeIdx, lineIdx = -1, -1
+	for i, field := range frame.Fields {
+		switch field.Type() {
+		case data.FieldTypeTime, data.FieldTypeNullableTime:
+			if timeIdx < 0 {
+				timeIdx = i
+			}
+		case data.FieldTypeString, data.FieldTypeNullableString:
+			if lineIdx < 0 {
+				lineIdx = i
+			}
+		case data.FieldTypeFloat64, data.FieldTypeNullableFloat64, data.FieldTypeInt64, data.FieldTypeNullableInt64:
+			// a frame with numbers is a series or a table, not log lines
+			return -1, -1
+		}
+	}
+	return timeIdx, lineIdx
+}
+
+// extractLogCount returns the number of lines of a frame with log lines, with the labels of the line field and its
+// samples most recent lines as LogSamples.
+func extractLogCount(frame *data.Frame, samples int) (mathexp.Number, error) {
+	timeIdx, lineIdx := logsFrameFields(frame)
+	if timeIdx < 0 || lineIdx < 0 {
+		return mathexp.Number{}, fmt.Errorf("expected a frame of log lines with a time field and a string field, got frame %q with %d fields", frame.Name, len(frame.Fields))
+	}
+	timeField, lineField := frame.Fields[timeIdx], frame.Fields[lineIdx]
+
+	type entry struct {
+		t    time.Time
+		line string
+	}
+	entries := make([]entry, 0, lineField.Len())
+	for i := 0; i < lineField.Len(); i++ {
+		t, ok := timeField.ConcreteAt(i)
+		if !ok {
+			continue
+		}
+		line, ok := lineField.ConcreteAt(i)
+		if !ok {
+			continue
+		}
+		entries = append(entries, entry{t: t.(time.Time), line: line.(string)})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].t.After(entries[j].t)
+	})
+
+	var labels data.Labels
+	if lineField.Labels != nil {
+		labels = lineField.Labels.Copy()
+	}
+	n := mathexp.NewNumber(frame.Name, labels)
+	count := float64(len(entries))
+	n.SetValue(&count)
+
+	if samples > len(entries) {
+		samples = len(entries)
+	}
+	lines := make(LogSamples, 0, samples)
+	for _, e := range entries[:samples] {
+		lines = append(lines, e.line)
+	}
+	n.SetMeta(lines)
+	return n, nil
+}
+
+// zeroLogCount returns the number of a query in logs mode without lines.
+func zeroLogCount(refID string) mathexp.Number {
+	n := mathexp.NewNumber(refID, nil)
+	zero := 0.0
+	n.SetValue(&zero)
+	n.SetMeta(LogSamples{})
+	return n
+}
@@ -0,0 +1,45 @@
+package expr
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractLogCount(t *testing.T) {
+	frame := data.NewFrame("stream",
+		data.NewField("time", nil, []time.Time{time.Unix(1, 0), time.Unix(3, 0), time.Unix(2, 0)}),
+		data.NewField("line", data.Labels{"app": "api"}, []string{"first", "third", "second"}),
+	)
+
+	t.Run("counts the lines and keeps the most recent ones", func(t *testing.T) {
+		n, err := extractLogCount(frame, 2)
+		require.NoError(t, err)
+		require.Equal(t, fp(3), n.GetFloat64Value())
+		require.Equal(t, data.Labels{"app": "api"}, n.GetLabels())
+		require.Equal(t, LogSamples{"third", "second"}, n.GetMeta())
+	})
+
+	t.Run("keeps all the lines when there are fewer than the samples", func(t *testing.T) {
+		n, err := extractLogCount(frame, 10)
+		require.NoError(t, err)
+		require.Equal(t, LogSamples{"third", "second", "first"}, n.GetMeta())
+	})
+
+	t.Run("fails with a series", func(t *testing.T) {
+		series := data.NewFrame("series",
+			data.NewField("time", nil, []time.Time{time.Unix(1, 0)}),
+			data.NewField("value", nil, []float64{1}),
+		)
+		_, err := extractLogCount(series, 2)
+		require.Error(t, err)
+	})
+
+	t.Run("no lines is a zero count", func(t *testing.T) {
+		n := zeroLogCount("A")
+		require.Equal(t, fp(0), n.GetFloat64Value())
+		require.Equal(t, LogSamples{}, n.GetMeta())
+	})
+}
@@ -0,0 +1,78 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	// ForecastLinear fits a line to the points of the series with the least squares method.
+	ForecastLinear = "linear"
+	// ForecastHolt smooths the level and the trend of the series with Holt's double exponential smoothing, the
+	// method of Holt-Winters without seasonality, which follows the recent changes of the trend more closely.
+	ForecastHolt = "holt"
+)
+
+// Forecast turns the Series into a Number that is the value predicted at the horizon after the last point of the
+// Series. The points without a value are skipped, the Number has no value if less than two points have a value.
+// Holt's method uses the average interval between the points as the step of the smoothing, the smoothing factors
+// alpha of the level and beta of the trend are between 0 and 1. The Series must be sorted by time.
+func (s Series) Forecast(refID, algorithm string, horizon time.Duration, alpha, beta float64) (Number, error) {
+	var l data.Labels
+	if s.GetLabels() != nil {
+		l = s.GetLabels().Copy()
+	}
+	number := NewNumber(refID, l)
+
+	times := make([]time.Time, 0, s.Len())
+	values := make([]float64, 0, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		t, v := s.GetPoint(i)
+		if v == nil || math.IsNaN(*v) {
+			continue
+		}
+		times = append(times, t)
+		values = append(values, *v)
+	}
+	if len(values) < 2 {
+		return number, nil
+	}
+	last := times[len(times)-1]
+	if !last.After(times[0]) {
+		return number, fmt.Errorf("can only forecast a series sorted by time")
+	}
+
+	var f float64
+	switch algorithm {
+	case ForecastLinear:
+		// The times are relative to the last point to keep the precision of the floats.
+		var sumX, sumY, sumXY, sumXX float64
+		for i, t := range times {
+			x := t.Sub(last).Seconds()
+			sumX += x
+			sumY += values[i]
+			sumXY += x * values[i]
+			sumXX += x * x
+		}
+		n := float64(len(values))
+		slope := (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
+		intercept := (sumY - slope*sumX) / n
+		f = intercept + slope*horizon.Seconds()
+	case ForecastHolt:
+		level, trend := values[0], values[1]-values[0]
+		for _, v := range values[1:] {
+			prevLevel := level
+			level = alpha*v + (1-alpha)*(level+trend)
+			trend = beta*(level-prevLevel) + (1-beta)*trend
+		}
+		step := last.Sub(times[0]).Seconds() / float64(len(values)-1)
+		f = level + trend*horizon.Seconds()/step
+	default:
+		return number, fmt.Errorf("forecast algorithm %v not implemented", algorithm)
+	}
+	number.SetValue(&f)
+	return number, nil
+}
@@ -0,0 +1,60 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForecastSeries(t *testing.T) {
+	// The series grows by 1 every 10 seconds.
+	input := makeSeries("", data.Labels{"disk": "sda"}, tp{
+		time.Unix(0, 0), float64Pointer(10),
+	}, tp{
+		time.Unix(10, 0), float64Pointer(11),
+	}, tp{
+		time.Unix(20, 0), float64Pointer(12),
+	}, tp{
+		time.Unix(30, 0), float64Pointer(13),
+	}, tp{
+		time.Unix(40, 0), float64Pointer(14),
+	})
+
+	for _, algorithm := range []string{ForecastLinear, ForecastHolt} {
+		t.Run(algorithm, func(t *testing.T) {
+			number, err := input.Forecast("B", algorithm, time.Minute, 0.5, 0.1)
+			require.NoError(t, err)
+			require.Equal(t, data.Labels{"disk": "sda"}, number.GetLabels())
+			require.InDelta(t, 20, *number.GetFloat64Value(), 0.0001)
+		})
+	}
+
+	t.Run("the points without a value are skipped", func(t *testing.T) {
+		number, err := makeSeries("", nil, tp{
+			time.Unix(0, 0), float64Pointer(10),
+		}, tp{
+			time.Unix(10, 0), nil,
+		}, tp{
+			time.Unix(20, 0), float64Pointer(12),
+		}).Forecast("B", ForecastLinear, time.Minute, 0.5, 0.1)
+		require.NoError(t, err)
+		require.InDelta(t, 18, *number.GetFloat64Value(), 0.0001)
+	})
+
+	t.Run("a series with a single value has no forecast", func(t *testing.T) {
+		number, err := makeSeries("", nil, tp{
+			time.Unix(0, 0), float64Pointer(10),
+		}, tp{
+			time.Unix(10, 0), nil,
+		}).Forecast("B", ForecastLinear, time.Minute, 0.5, 0.1)
+		require.NoError(t, err)
+		require.Nil(t, number.GetFloat64Value())
+	})
+
+	t.Run("fails with an unknown algorithm", func(t *testing.T) {
+		_, err := input.Forecast("B", "arima", time.Minute, 0.5, 0.1)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,143 @@
+package mathexp
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// Join applies the binary operator to the pairs of values of the left and the right results that have the same
+// labels on, or the same shared labels when on is empty. Unlike the unions of math expressions, the values don't
+// need to have the same labels, so the results of queries to different datasources can be combined. The values
+// without a pair are dropped. The result of a pair has the labels of the left value merged with the labels of the
+// right value. The points of the series are paired with the closest point of the other series within the
+// tolerance, a point without a pair has no value. The series must be sorted by time.
+func Join(refID string, left, right Results, on []string, op string, tolerance time.Duration) (Results, error) {
+	if _, err := binaryOp(op, 1, 1); err != nil {
+		return Results{}, err
+	}
+	e := &State{RefID: refID}
+	res := Results{}
+	for _, l := range left.Values {
+		for _, r := range right.Values {
+			if !joinable(l.GetLabels(), r.GetLabels(), on) {
+				continue
+			}
+			labels := mergeLabels(l.GetLabels(), r.GetLabels())
+			var (
+				value Value
+				err   error
+			)
+			switch lv := l.(type) {
+			case Series:
+				switch rv := r.(type) {
+				case Series:
+					value, err = joinSeries(refID, labels, op, lv, rv, tolerance)
+				case Number:
+					value, err = e.biSeriesNumber(labels, op, lv, rv.GetFloat64Value(), true)
+				default:
+					err = fmt.Errorf("can only join series and numbers, got type %v", r.Type())
+				}
+			case Number:
+				switch rv := r.(type) {
+				case Series:
+					value, err = e.biSeriesNumber(labels, op, rv, lv.GetFloat64Value(), false)
+				case Number:
+					value, err = e.biScalarNumber(labels, op, lv, rv.GetFloat64Value(), true)
+				default:
+					err = fmt.Errorf("can only join series and numbers, got type %v", r.Type())
+				}
+			default:
+				err = fmt.Errorf("can only join series and numbers, got type %v", l.Type())
+			}
+			if err != nil {
+				return res, err
+			}
+			res.Values = append(res.Values, value)
+		}
+	}
+	return res, nil
+}
+
+// joinable returns true if the labels have the same values for the on labels, or for their shared labels when on
+// is empty.
+func joinable(left, right data.Labels, on []string) bool {
+	if len(on) == 0 {
+		for k, v := range left {
+			if rv, ok := right[k]; ok && rv != v {
+				return false
+			}
+		}
+		return true
+	}
+	for _, k := range on {
+		lv, lok := left[k]
+		rv, rok := right[k]
+		if !lok || !rok || lv != rv {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeLabels returns the labels of left with the labels of right it doesn't have.
+func mergeLabels(left, right data.Labels) data.Labels {
+	if len(left) == 0 && len(right) == 0 {
+		return nil
+	}
+	labels := make(data.Labels, len(left)+len(right))
+	for k, v := range right {
+		labels[k] = v
+	}
+	for k, v := range left {
+		labels[k] = v
+	}
+	return labels
+}
+
+// joinSeries has the points of the left series, paired with the closest points of the right series within the
+// tolerance.
+func joinSeries(refID string, labels data.Labels, op string, left, right Series, tolerance time.Duration) (Series, error) {
+	newSeries := NewSeries(refID, labels, left.Len())
+	for i := 0; i < left.Len(); i++ {
+		t, lF := left.GetPoint(i)
+		rF := closestValue(right, t, tolerance)
+		var value *float64
+		if lF != nil && rF != nil {
+			f, err := binaryOp(op, *lF, *rF)
+			if err != nil {
+				return newSeries, err
+			}
+			value = &f
+		}
+		if err := newSeries.SetPoint(i, t, value); err != nil {
+			return newSeries, err
+		}
+	}
+	return newSeries, nil
+}
+
+// closestValue returns the value of the point of the series closest to t within the tolerance, nil if there is
+// none.
+func closestValue(s Series, t time.Time, tolerance time.Duration) *float64 {
+	idx := sort.Search(s.Len(), func(i int) bool { return !s.GetTime(i).Before(t) })
+	best, bestDistance := -1, tolerance
+	for _, i := range []int{idx - 1, idx} {
+		if i < 0 || i >= s.Len() {
+			continue
+		}
+		distance := s.GetTime(i).Sub(t)
+		if distance < 0 {
+			distance = -distance
+		}
+		if distance <= bestDistance {
+			best, bestDistance = i, distance
+		}
+	}
+	if best < 0 {
+		return nil
+	}
+	return s.GetValue(best)
+}
@@ -0,0 +1,78 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJoin(t *testing.T) {
+	errors := Results{Values: []Value{
+		makeSeries("errors", data.Labels{"service": "api", "stream": "stderr"}, tp{
+			time.Unix(0, 0), float64Pointer(2),
+		}, tp{
+			time.Unix(10, 0), float64Pointer(4),
+		}, tp{
+			time.Unix(20, 0), float64Pointer(6),
+		}),
+		makeSeries("errors", data.Labels{"service": "db", "stream": "stderr"}, tp{
+			time.Unix(0, 0), float64Pointer(1),
+		}),
+	}}
+	requests := Results{Values: []Value{
+		makeSeries("requests", data.Labels{"service": "api", "instance": "a"}, tp{
+			time.Unix(1, 0), float64Pointer(20),
+		}, tp{
+			time.Unix(9, 0), float64Pointer(40),
+		}),
+	}}
+
+	t.Run("series are paired on the labels and the closest points", func(t *testing.T) {
+		res, err := Join("C", errors, requests, []string{"service"}, "/", 2*time.Second)
+		require.NoError(t, err)
+		require.Equal(t, Results{Values: []Value{
+			makeSeries("C", data.Labels{"service": "api", "stream": "stderr", "instance": "a"}, tp{
+				time.Unix(0, 0), float64Pointer(0.1),
+			}, tp{
+				time.Unix(10, 0), float64Pointer(0.1),
+			}, tp{
+				time.Unix(20, 0), nil,
+			}),
+		}}, res)
+	})
+
+	t.Run("the shared labels are compared without on labels", func(t *testing.T) {
+		res, err := Join("C", errors, requests, nil, "/", 0)
+		require.NoError(t, err)
+		require.Len(t, res.Values, 1)
+		require.Equal(t, data.Labels{"service": "api", "stream": "stderr", "instance": "a"}, res.Values[0].GetLabels())
+	})
+
+	t.Run("series are combined with numbers", func(t *testing.T) {
+		n := makeNumber("", data.Labels{"service": "db"}, float64Pointer(10))
+		res, err := Join("C", errors, Results{Values: []Value{n}}, []string{"service"}, "*", 0)
+		require.NoError(t, err)
+		require.Equal(t, Results{Values: []Value{
+			makeSeries("C", data.Labels{"service": "db", "stream": "stderr"}, tp{
+				time.Unix(0, 0), float64Pointer(10),
+			}),
+		}}, res)
+	})
+
+	t.Run("numbers are combined", func(t *testing.T) {
+		a := makeNumber("", data.Labels{"service": "api"}, float64Pointer(6))
+		b := makeNumber("", data.Labels{"service": "api", "instance": "a"}, float64Pointer(4))
+		res, err := Join("C", Results{Values: []Value{a}}, Results{Values: []Value{b}}, []string{"service"}, "-", 0)
+		require.NoError(t, err)
+		require.Equal(t, Results{Values: []Value{
+			makeNumber("C", data.Labels{"service": "api", "instance": "a"}, float64Pointer(2)),
+		}}, res)
+	})
+
+	t.Run("fails with an unknown operator", func(t *testing.T) {
+		_, err := Join("C", errors, requests, nil, "%%", 0)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,43 @@
+package mathexp
+
+import (
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// Rate turns the Series into the per-second rate of change between its consecutive points. The result has a point
+// less than the Series, each point has the time of the second point of its pair, and no value if one of the two
+// points has no value. When counter is true the Series is a cumulative counter which may be reset to zero, so a
+// decrease is a reset and the rate of the pair is computed from zero. The Series must be sorted by time.
+func (s Series) Rate(refID string, counter bool) (Series, error) {
+	var l data.Labels
+	if s.GetLabels() != nil {
+		l = s.GetLabels().Copy()
+	}
+	if s.Len() < 2 {
+		return NewSeries(refID, l, 0), nil
+	}
+	result := NewSeries(refID, l, s.Len()-1)
+	for i := 1; i < s.Len(); i++ {
+		prevTime, prev := s.GetPoint(i - 1)
+		t, v := s.GetPoint(i)
+		seconds := t.Sub(prevTime).Seconds()
+		if seconds <= 0 {
+			return s, fmt.Errorf("can only compute the rate of a series sorted by time, the point at %v is not after the point at %v", t, prevTime)
+		}
+		var value *float64
+		if prev != nil && v != nil {
+			delta := *v - *prev
+			if counter && delta < 0 {
+				delta = *v
+			}
+			r := delta / seconds
+			value = &r
+		}
+		if err := result.SetPoint(i-1, t, value); err != nil {
+			return s, err
+		}
+	}
+	return result, nil
+}
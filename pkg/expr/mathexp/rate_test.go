@@ -0,0 +1,66 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateSeries(t *testing.T) {
+	input := makeSeries("", data.Labels{"host": "a"}, tp{
+		time.Unix(0, 0), float64Pointer(10),
+	}, tp{
+		time.Unix(10, 0), float64Pointer(30),
+	}, tp{
+		time.Unix(20, 0), float64Pointer(5),
+	}, tp{
+		time.Unix(30, 0), nil,
+	}, tp{
+		time.Unix(40, 0), float64Pointer(25),
+	})
+
+	t.Run("derivative", func(t *testing.T) {
+		series, err := input.Rate("B", false)
+		require.NoError(t, err)
+		require.Equal(t, makeSeries("B", data.Labels{"host": "a"}, tp{
+			time.Unix(10, 0), float64Pointer(2),
+		}, tp{
+			time.Unix(20, 0), float64Pointer(-2.5),
+		}, tp{
+			time.Unix(30, 0), nil,
+		}, tp{
+			time.Unix(40, 0), nil,
+		}), series)
+	})
+
+	t.Run("counter with a reset", func(t *testing.T) {
+		series, err := input.Rate("B", true)
+		require.NoError(t, err)
+		require.Equal(t, makeSeries("B", data.Labels{"host": "a"}, tp{
+			time.Unix(10, 0), float64Pointer(2),
+		}, tp{
+			time.Unix(20, 0), float64Pointer(0.5),
+		}, tp{
+			time.Unix(30, 0), nil,
+		}, tp{
+			time.Unix(40, 0), nil,
+		}), series)
+	})
+
+	t.Run("a series with a single point has no rate", func(t *testing.T) {
+		series, err := makeSeries("", nil, tp{time.Unix(0, 0), float64Pointer(1)}).Rate("B", false)
+		require.NoError(t, err)
+		require.Equal(t, 0, series.Len())
+	})
+
+	t.Run("fails if the series is not sorted by time", func(t *testing.T) {
+		_, err := makeSeries("", nil, tp{
+			time.Unix(10, 0), float64Pointer(1),
+		}, tp{
+			time.Unix(0, 0), float64Pointer(2),
+		}).Rate("B", false)
+		require.Error(t, err)
+	})
+}
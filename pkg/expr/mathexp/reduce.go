@@ -3,6 +3,9 @@ package mathexp
 import (
 	"fmt"
 	"math"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
@@ -69,6 +72,81 @@ func Count(fv *Float64Field) *float64 {
 	return &f
 }
 
+func Last(fv *Float64Field) *float64 {
+	if fv.Len() == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	v := fv.GetValue(fv.Len() - 1)
+	if v == nil {
+		nan := math.NaN()
+		return &nan
+	}
+	f := *v
+	return &f
+}
+
+// Variance returns the population variance of the values.
+func Variance(fv *Float64Field) *float64 {
+	if fv.Len() == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	mean := Avg(fv)
+	if math.IsNaN(*mean) {
+		return mean
+	}
+	var f float64
+	for i := 0; i < fv.Len(); i++ {
+		d := *fv.GetValue(i) - *mean
+		f += d * d
+	}
+	f /= float64(fv.Len())
+	return &f
+}
+
+// StdDev returns the population standard deviation of the values.
+func StdDev(fv *Float64Field) *float64 {
+	f := math.Sqrt(*Variance(fv))
+	return &f
+}
+
+// Percentile returns the value below which the percentage p of the values fall, interpolated linearly between the
+// two closest values.
+func Percentile(fv *Float64Field, p float64) *float64 {
+	if fv.Len() == 0 {
+		nan := math.NaN()
+		return &nan
+	}
+	vals := make([]float64, 0, fv.Len())
+	for i := 0; i < fv.Len(); i++ {
+		v := fv.GetValue(i)
+		if v == nil || math.IsNaN(*v) {
+			nan := math.NaN()
+			return &nan
+		}
+		vals = append(vals, *v)
+	}
+	sort.Float64s(vals)
+	rank := p / 100 * float64(len(vals)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	f := vals[lower] + (vals[upper]-vals[lower])*(rank-float64(lower))
+	return &f
+}
+
+// parsePercentile returns the percentage of a percentile reducer, such as 99.9 for "p99.9".
+func parsePercentile(rFunc string) (float64, bool) {
+	if !strings.HasPrefix(rFunc, "p") {
+		return 0, false
+	}
+	p, err := strconv.ParseFloat(strings.TrimPrefix(rFunc, "p"), 64)
+	if err != nil || p < 0 || p > 100 {
+		return 0, false
+	}
+	return p, true
+}
+
 // Reduce turns the Series into a Number based on the given reduction function
 func (s Series) Reduce(refID, rFunc string) (Number, error) {
 	var l data.Labels
@@ -90,8 +168,20 @@ func (s Series) Reduce(refID, rFunc string) (Number, error) {
 		f = Max(&floatField)
 	case "count":
 		f = Count(&floatField)
+	case "last":
+		f = Last(&floatField)
+	case "median":
+		f = Percentile(&floatField, 50)
+	case "stddev":
+		f = StdDev(&floatField)
+	case "variance":
+		f = Variance(&floatField)
 	default:
-		return number, fmt.Errorf("reduction %v not implemented", rFunc)
+		p, ok := parsePercentile(rFunc)
+		if !ok {
+			return number, fmt.Errorf("reduction %v not implemented", rFunc)
+		}
+		f = Percentile(&floatField, p)
 	}
 	number.SetValue(f)
 
@@ -30,6 +30,20 @@ var seriesEmpty = Vars{
 	},
 }
 
+var aSeriesOfTen = Vars{
+	"A": Results{
+		[]Value{
+			func() Series {
+				points := make([]tp, 0, 10)
+				for i := 1; i <= 10; i++ {
+					points = append(points, tp{time.Unix(int64(i), 0), float64Pointer(float64(i))})
+				}
+				return makeSeries("temp", nil, points...)
+			}(),
+		},
+	},
+}
+
 func TestSeriesReduce(t *testing.T) {
 	var tests = []struct {
 		name        string
@@ -191,6 +205,79 @@ func TestSeriesReduce(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:        "p90 series",
+			red:         "p90",
+			varToReduce: "A",
+			vars:        aSeriesOfTen,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(9.1)),
+				},
+			},
+		},
+		{
+			name:        "median series",
+			red:         "median",
+			varToReduce: "A",
+			vars:        aSeriesOfTen,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(5.5)),
+				},
+			},
+		},
+		{
+			name:        "variance series",
+			red:         "variance",
+			varToReduce: "A",
+			vars:        aSeriesOfTen,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(8.25)),
+				},
+			},
+		},
+		{
+			name:        "stddev series with nil value",
+			red:         "stddev",
+			varToReduce: "A",
+			vars:        seriesWithNil,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, NaN),
+				},
+			},
+		},
+		{
+			name:        "last series",
+			red:         "last",
+			varToReduce: "A",
+			vars:        aSeriesOfTen,
+			errIs:       require.NoError,
+			resultsIs:   require.Equal,
+			results: Results{
+				[]Value{
+					makeNumber("", nil, float64Pointer(10)),
+				},
+			},
+		},
+		{
+			name:        "invalid percentile",
+			red:         "p101",
+			varToReduce: "A",
+			vars:        aSeriesOfTen,
+			errIs:       require.Error,
+			resultsIs:   require.Equal,
+		},
 		{
 			name:        "mean series with labels",
 			red:         "mean",
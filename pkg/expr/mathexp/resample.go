@@ -80,3 +80,47 @@ func (s Series) Resample(refID string, interval time.Duration, downsampler strin
 	}
 	return resampled, nil
 }
+
+// The fill modes of the points of a resampled series without value.
+const (
+	FillNull     = "null"
+	FillPrevious = "previous"
+	FillZero     = "zero"
+	FillDrop     = "drop"
+)
+
+// Fill fills the points of the series without value: null keeps them, previous sets them to the previous value,
+// zero to 0, and drop removes them. The points before the first value are dropped when filled with the previous
+// value.
+func (s Series) Fill(refID string, mode string) (Series, error) {
+	switch mode {
+	case "", FillNull:
+		return s, nil
+	case FillPrevious, FillZero, FillDrop:
+	default:
+		return s, fmt.Errorf("fill mode %v not implemented", mode)
+	}
+
+	filled := NewSeries(refID, s.GetLabels(), 0)
+	var previous *float64
+	for i := 0; i < s.Len(); i++ {
+		t, v := s.GetPoint(i)
+		if v == nil {
+			switch mode {
+			case FillPrevious:
+				v = previous
+			case FillZero:
+				zero := 0.0
+				v = &zero
+			}
+			if v == nil {
+				continue
+			}
+		}
+		previous = v
+		if err := filled.AppendPoint(i, t, v); err != nil {
+			return s, err
+		}
+	}
+	return filled, nil
+}
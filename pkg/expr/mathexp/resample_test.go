@@ -258,3 +258,72 @@ func TestResampleSeries(t *testing.T) {
 		})
 	}
 }
+
+func TestFillSeries(t *testing.T) {
+	series := makeSeries("", nil, tp{
+		time.Unix(0, 0), nil,
+	}, tp{
+		time.Unix(5, 0), float64Pointer(2),
+	}, tp{
+		time.Unix(10, 0), nil,
+	}, tp{
+		time.Unix(15, 0), float64Pointer(3),
+	})
+
+	var tests = []struct {
+		name   string
+		mode   string
+		series Series
+	}{
+		{
+			name:   "null keeps the points without value",
+			mode:   FillNull,
+			series: series,
+		},
+		{
+			name: "previous sets the points to the previous value",
+			mode: FillPrevious,
+			series: makeSeries("", nil, tp{
+				time.Unix(5, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(10, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(15, 0), float64Pointer(3),
+			}),
+		},
+		{
+			name: "zero sets the points to 0",
+			mode: FillZero,
+			series: makeSeries("", nil, tp{
+				time.Unix(0, 0), float64Pointer(0),
+			}, tp{
+				time.Unix(5, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(10, 0), float64Pointer(0),
+			}, tp{
+				time.Unix(15, 0), float64Pointer(3),
+			}),
+		},
+		{
+			name: "drop removes the points",
+			mode: FillDrop,
+			series: makeSeries("", nil, tp{
+				time.Unix(5, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(15, 0), float64Pointer(3),
+			}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filled, err := series.Fill("", tt.mode)
+			require.NoError(t, err)
+			assert.Equal(t, tt.series, filled)
+		})
+	}
+
+	t.Run("unknown fill mode", func(t *testing.T) {
+		_, err := series.Fill("", "linear")
+		require.Error(t, err)
+	})
+}
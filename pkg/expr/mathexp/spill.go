@@ -0,0 +1,300 @@
+package mathexp
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+// spilledPointSize is the size of a point in a spill file: the time in nanoseconds, a null flag and the value.
+const spilledPointSize = 8 + 1 + 8
+
+// SpilledSeries is a Series whose points were written to a temporary file, so that a very large series is not held
+// in memory. It is reduced by streaming the points from the file, the other operations load it back with Load.
+type SpilledSeries struct {
+	// Frame is the frame of the series without its points.
+	Frame *data.Frame
+	// Path is the path of the file of the points.
+	Path string
+	// Points is the number of points of the series.
+	Points int
+}
+
+// SpillSeries writes the points of the series to a new file in dir, the default directory for temporary files if
+// empty. The file must be removed with Remove when the series is no longer used.
+func SpillSeries(dir string, s Series) (SpilledSeries, error) {
+	f, err := ioutil.TempFile(dir, "series-")
+	if err != nil {
+		return SpilledSeries{}, fmt.Errorf("failed to create the spill file: %w", err)
+	}
+	spilled := SpilledSeries{Frame: emptySeriesFrame(s.Frame), Path: f.Name(), Points: s.Len()}
+
+	w := bufio.NewWriter(f)
+	var buf [spilledPointSize]byte
+	for i := 0; i < s.Len(); i++ {
+		t, v := s.GetPoint(i)
+		binary.LittleEndian.PutUint64(buf[0:8], uint64(t.UnixNano()))
+		buf[8] = 0
+		binary.LittleEndian.PutUint64(buf[9:], 0)
+		if v != nil {
+			buf[8] = 1
+			binary.LittleEndian.PutUint64(buf[9:], math.Float64bits(*v))
+		}
+		if _, err = w.Write(buf[:]); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return SpilledSeries{}, fmt.Errorf("failed to write the spill file: %w", err)
+	}
+	return spilled, nil
+}
+
+// emptySeriesFrame returns a copy of the frame of a series without its points.
+func emptySeriesFrame(frame *data.Frame) *data.Frame {
+	empty := frame.EmptyCopy()
+	empty.Meta = frame.Meta
+	for i, field := range frame.Fields {
+		empty.Fields[i].Labels = field.Labels
+		empty.Fields[i].Config = field.Config
+	}
+	return empty
+}
+
+// Type returns the Value type and allows it to fulfill the Value interface.
+func (s SpilledSeries) Type() parse.ReturnType { return parse.TypeSeriesSet }
+
+// Value returns the actual value allows it to fulfill the Value interface.
+func (s SpilledSeries) Value() interface{} { return &s }
+
+func (s SpilledSeries) GetLabels() data.Labels { return s.Frame.Fields[seriesTypeValIdx].Labels }
+
+func (s SpilledSeries) SetLabels(ls data.Labels) { s.Frame.Fields[seriesTypeValIdx].Labels = ls }
+
+func (s SpilledSeries) GetMeta() interface{} {
+	return s.Frame.Meta.Custom
+}
+
+func (s SpilledSeries) SetMeta(v interface{}) {
+	s.Frame.SetMeta(&data.FrameMeta{Custom: v})
+}
+
+// AsDataFrame returns the frame of the series, without its points.
+func (s SpilledSeries) AsDataFrame() *data.Frame { return s.Frame }
+
+// Len returns the number of points of the series.
+func (s SpilledSeries) Len() int { return s.Points }
+
+// Remove removes the file of the points of the series.
+func (s SpilledSeries) Remove() error {
+	if err := os.Remove(s.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Load reads the points of the series back in memory.
+func (s SpilledSeries) Load() (Series, error) {
+	frame := emptySeriesFrame(s.Frame)
+	frame.Fields[seriesTypeTimeIdx].Extend(s.Points)
+	frame.Fields[seriesTypeValIdx].Extend(s.Points)
+	series := Series{Frame: frame}
+	i := 0
+	err := s.each(func(t time.Time, v *float64) {
+		_ = series.SetPoint(i, t, v) // the index is within the number of points
+		i++
+	})
+	if err != nil {
+		return Series{}, err
+	}
+	return series, nil
+}
+
+// each calls fn with the points of the series, in order.
+func (s SpilledSeries) each(fn func(t time.Time, v *float64)) error {
+	f, err := os.Open(s.Path)
+	if err != nil {
+		return fmt.Errorf("failed to open the spill file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	r := bufio.NewReader(f)
+	var buf [spilledPointSize]byte
+	for i := 0; i < s.Points; i++ {
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			return fmt.Errorf("failed to read the spill file: %w", err)
+		}
+		t := time.Unix(0, int64(binary.LittleEndian.Uint64(buf[0:8]))).UTC()
+		var v *float64
+		if buf[8] == 1 {
+			f := math.Float64frombits(binary.LittleEndian.Uint64(buf[9:]))
+			v = &f
+		}
+		fn(t, v)
+	}
+	return nil
+}
+
+// Reduce turns the SpilledSeries into a Number based on the given reduction function, with the same results as the
+// reduction of the Series. The points are streamed from the file, except for the median and the percentiles that
+// hold the values of the series in memory.
+func (s SpilledSeries) Reduce(refID, rFunc string) (Number, error) {
+	var l data.Labels
+	if s.GetLabels() != nil {
+		l = s.GetLabels().Copy()
+	}
+	number := NewNumber(refID, l)
+
+	var p float64
+	switch rFunc {
+	case "sum", "mean", "min", "max", "count", "last", "stddev", "variance":
+	case "median":
+		p = 50
+	default:
+		var ok bool
+		if p, ok = parsePercentile(rFunc); !ok {
+			return number, fmt.Errorf("reduction %v not implemented", rFunc)
+		}
+	}
+
+	var f float64
+	var err error
+	switch rFunc {
+	case "count":
+		f = float64(s.Points)
+	case "stddev":
+		f, err = s.variance()
+		f = math.Sqrt(f)
+	case "variance":
+		f, err = s.variance()
+	case "sum", "mean", "min", "max", "last":
+		var st spilledStats
+		if err = s.each(st.add); err != nil {
+			break
+		}
+		f = st.result(rFunc)
+	default:
+		f, err = s.percentile(p)
+	}
+	if err != nil {
+		return number, err
+	}
+	number.SetValue(&f)
+	return number, nil
+}
+
+// spilledStats are the statistics of the points of a spilled series computed in a single pass.
+type spilledStats struct {
+	count    int
+	sum      float64
+	min, max float64
+	// noValue is true if a point is null or NaN, which reduces to NaN.
+	noValue bool
+	last    *float64
+}
+
+func (st *spilledStats) add(_ time.Time, v *float64) {
+	st.count++
+	st.last = v
+	if v == nil || math.IsNaN(*v) {
+		st.noValue = true
+		return
+	}
+	st.sum += *v
+	if st.count == 1 || *v < st.min {
+		st.min = *v
+	}
+	if st.count == 1 || *v > st.max {
+		st.max = *v
+	}
+}
+
+func (st *spilledStats) result(rFunc string) float64 {
+	switch rFunc {
+	case "last":
+		if st.last == nil {
+			return math.NaN()
+		}
+		return *st.last
+	case "sum":
+		if st.noValue {
+			return math.NaN()
+		}
+		return st.sum
+	case "mean":
+		if st.noValue {
+			return math.NaN()
+		}
+		return st.sum / float64(st.count)
+	}
+	// min and max
+	if st.noValue || st.count == 0 {
+		return math.NaN()
+	}
+	if rFunc == "min" {
+		return st.min
+	}
+	return st.max
+}
+
+// variance returns the population variance of the values, in two passes over the file.
+func (s SpilledSeries) variance() (float64, error) {
+	var st spilledStats
+	if err := s.each(st.add); err != nil {
+		return 0, err
+	}
+	if st.count == 0 || st.noValue {
+		return math.NaN(), nil
+	}
+	mean := st.sum / float64(st.count)
+	var f float64
+	err := s.each(func(_ time.Time, v *float64) {
+		d := *v - mean
+		f += d * d
+	})
+	return f / float64(st.count), err
+}
+
+// percentile returns the percentile p of the values, which are loaded in memory to be sorted.
+func (s SpilledSeries) percentile(p float64) (float64, error) {
+	if s.Points == 0 {
+		return math.NaN(), nil
+	}
+	vals := make([]float64, 0, s.Points)
+	noValue := false
+	err := s.each(func(_ time.Time, v *float64) {
+		if v == nil || math.IsNaN(*v) {
+			noValue = true
+			return
+		}
+		vals = append(vals, *v)
+	})
+	if err != nil {
+		return 0, err
+	}
+	if noValue {
+		return math.NaN(), nil
+	}
+	sort.Float64s(vals)
+	rank := p / 100 * float64(len(vals)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return vals[lower] + (vals[upper]-vals[lower])*(rank-float64(lower)), nil
+}
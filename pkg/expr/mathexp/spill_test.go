@@ -0,0 +1,66 @@
+package mathexp
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpilledSeries(t *testing.T) {
+	points := make([]tp, 0, 100)
+	for i := 0; i < 100; i++ {
+		points = append(points, tp{time.Unix(int64(i), 0), float64Pointer(math.Sin(float64(i)) * 10)})
+	}
+	withNil := append([]tp{{time.Unix(100, 0), nil}}, points...)
+	reducers := []string{"sum", "mean", "min", "max", "count", "last", "median", "stddev", "variance", "p90", "p0"}
+
+	for name, s := range map[string]Series{
+		"series":          makeSeries("temp", data.Labels{"host": "a"}, points...),
+		"series with nil": makeSeries("temp", data.Labels{"host": "a"}, withNil...),
+		"empty series":    makeSeries("temp", nil),
+	} {
+		t.Run(name, func(t *testing.T) {
+			spilled, err := SpillSeries(t.TempDir(), s)
+			require.NoError(t, err)
+			require.Equal(t, s.Len(), spilled.Len())
+			require.Equal(t, 0, spilled.AsDataFrame().Rows())
+			require.Equal(t, s.GetLabels(), spilled.GetLabels())
+
+			for _, r := range reducers {
+				expected, err := s.Reduce("B", r)
+				require.NoError(t, err)
+				actual, err := spilled.Reduce("B", r)
+				require.NoError(t, err)
+				require.Equal(t, expected.GetLabels(), actual.GetLabels())
+				e, a := expected.GetFloat64Value(), actual.GetFloat64Value()
+				if math.IsNaN(*e) {
+					require.True(t, math.IsNaN(*a), r)
+					continue
+				}
+				require.InDelta(t, *e, *a, 1e-9, r)
+			}
+
+			loaded, err := spilled.Load()
+			require.NoError(t, err)
+			require.Equal(t, s.Len(), loaded.Len())
+			for i := 0; i < s.Len(); i++ {
+				require.True(t, s.GetTime(i).Equal(loaded.GetTime(i)))
+				require.Equal(t, s.GetValue(i), loaded.GetValue(i))
+			}
+
+			require.NoError(t, spilled.Remove())
+			_, err = spilled.Load()
+			require.Error(t, err)
+		})
+	}
+
+	t.Run("unknown reducers fail", func(t *testing.T) {
+		spilled, err := SpillSeries(t.TempDir(), makeSeries("temp", nil, points...))
+		require.NoError(t, err)
+		_, err = spilled.Reduce("B", "first")
+		require.EqualError(t, err, "reduction first not implemented")
+	})
+}
@@ -0,0 +1,56 @@
+package mathexp
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// MovingWindow turns the Series into a Series of the same length, where each point is the reduction of the points
+// of the window that ends at the point, such as the mean of the last 5 minutes. The points without a value are
+// skipped, a point gets no value if there is no value in its window. The Series must be sorted by time.
+func (s Series) MovingWindow(refID string, window time.Duration, reducer string) (Series, error) {
+	var rFunc func(fv *Float64Field) *float64
+	switch reducer {
+	case "sum":
+		rFunc = Sum
+	case "mean":
+		rFunc = Avg
+	case "min":
+		rFunc = Min
+	case "max":
+		rFunc = Max
+	default:
+		return s, fmt.Errorf("moving window reduction %v not implemented", reducer)
+	}
+
+	var l data.Labels
+	if s.GetLabels() != nil {
+		l = s.GetLabels().Copy()
+	}
+	result := NewSeries(refID, l, s.Len())
+	start := 0
+	for i := 0; i < s.Len(); i++ {
+		t := s.GetTime(i)
+		for !s.GetTime(start).After(t.Add(-window)) {
+			start++
+		}
+		vals := make([]*float64, 0, i-start+1)
+		for j := start; j <= i; j++ {
+			if v := s.GetValue(j); v != nil && !math.IsNaN(*v) {
+				vals = append(vals, v)
+			}
+		}
+		var value *float64
+		if len(vals) > 0 {
+			ff := Float64Field(*data.NewField("", nil, vals))
+			value = rFunc(&ff)
+		}
+		if err := result.SetPoint(i, t, value); err != nil {
+			return s, err
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,91 @@
+package mathexp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMovingWindowSeries(t *testing.T) {
+	input := makeSeries("", data.Labels{"host": "a"}, tp{
+		time.Unix(0, 0), float64Pointer(2),
+	}, tp{
+		time.Unix(5, 0), float64Pointer(4),
+	}, tp{
+		time.Unix(10, 0), nil,
+	}, tp{
+		time.Unix(15, 0), float64Pointer(9),
+	}, tp{
+		time.Unix(30, 0), nil,
+	})
+
+	var tests = []struct {
+		name    string
+		window  time.Duration
+		reducer string
+		series  Series
+	}{
+		{
+			name:    "mean over two points",
+			window:  10 * time.Second,
+			reducer: "mean",
+			series: makeSeries("B", data.Labels{"host": "a"}, tp{
+				time.Unix(0, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(5, 0), float64Pointer(3),
+			}, tp{
+				time.Unix(10, 0), float64Pointer(4),
+			}, tp{
+				time.Unix(15, 0), float64Pointer(9),
+			}, tp{
+				time.Unix(30, 0), nil,
+			}),
+		},
+		{
+			name:    "max over three points",
+			window:  15 * time.Second,
+			reducer: "max",
+			series: makeSeries("B", data.Labels{"host": "a"}, tp{
+				time.Unix(0, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(5, 0), float64Pointer(4),
+			}, tp{
+				time.Unix(10, 0), float64Pointer(4),
+			}, tp{
+				time.Unix(15, 0), float64Pointer(9),
+			}, tp{
+				time.Unix(30, 0), nil,
+			}),
+		},
+		{
+			name:    "sum over three points",
+			window:  15 * time.Second,
+			reducer: "sum",
+			series: makeSeries("B", data.Labels{"host": "a"}, tp{
+				time.Unix(0, 0), float64Pointer(2),
+			}, tp{
+				time.Unix(5, 0), float64Pointer(6),
+			}, tp{
+				time.Unix(10, 0), float64Pointer(6),
+			}, tp{
+				time.Unix(15, 0), float64Pointer(13),
+			}, tp{
+				time.Unix(30, 0), nil,
+			}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			series, err := input.MovingWindow("B", tt.window, tt.reducer)
+			require.NoError(t, err)
+			require.Equal(t, tt.series, series)
+		})
+	}
+
+	t.Run("fails with an unknown reducer", func(t *testing.T) {
+		_, err := input.MovingWindow("B", time.Minute, "median")
+		require.Error(t, err)
+	})
+}
@@ -30,6 +30,7 @@ type rawNode struct {
 	Query         map[string]interface{}
 	QueryType     string
 	TimeRange     TimeRange
+	TimeShift     time.Duration
 	DatasourceUID string
 }
 
@@ -89,6 +90,13 @@ func (gn *CMDNode) NodeType() NodeType {
 // other nodes they must have already been executed and their results must
 // already by in vars.
 func (gn *CMDNode) Execute(ctx context.Context, vars mathexp.Vars, s *Service) (mathexp.Results, error) {
+	// The reductions stream the spilled series, the other commands need them in memory.
+	if _, ok := gn.Command.(*ReduceCommand); !ok {
+		var err error
+		if vars, err = loadSpilled(vars, gn.Command.NeedsVars()); err != nil {
+			return mathexp.Results{}, err
+		}
+	}
 	return gn.Command.Execute(ctx, vars)
 }
 
@@ -115,6 +123,20 @@ func buildCMDNode(dp *simple.DirectedGraph, rn *rawNode) (*CMDNode, error) {
 		node.Command, err = UnmarshalResampleCommand(rn)
 	case TypeClassicConditions:
 		node.Command, err = classic.UnmarshalConditionsCmd(rn.Query, rn.RefID)
+	case TypeThreshold:
+		node.Command, err = UnmarshalThresholdCommand(rn)
+	case TypeMovingWindow:
+		node.Command, err = UnmarshalMovingWindowCommand(rn)
+	case TypeRate:
+		node.Command, err = UnmarshalRateCommand(rn)
+	case TypeOutliers:
+		node.Command, err = UnmarshalOutliersCommand(rn)
+	case TypeForecast:
+		node.Command, err = UnmarshalForecastCommand(rn)
+	case TypeAbsent:
+		node.Command, err = UnmarshalAbsentCommand(rn)
+	case TypeJoin:
+		node.Command, err = UnmarshalJoinCommand(rn)
 	default:
 		return nil, fmt.Errorf("expression command type '%v' in '%v' not implemented", commandType, rn.RefID)
 	}
@@ -140,9 +162,14 @@ type DSNode struct {
 	orgID      int64
 	queryType  string
 	timeRange  TimeRange
+	timeShift  time.Duration
 	intervalMS int64
 	maxDP      int64
 	request    Request
+	// evalMode is EvalModeLogs to count the log lines returned by the query, logSamples is the number of sample lines
+	// kept by stream.
+	evalMode   string
+	logSamples int
 }
 
 // NodeType returns the data pipeline node type.
@@ -167,6 +194,7 @@ func (s *Service) buildDSNode(dp *simple.DirectedGraph, rn *rawNode, req *Reques
 		intervalMS: defaultIntervalMS,
 		maxDP:      defaultMaxDP,
 		timeRange:  rn.TimeRange,
+		timeShift:  rn.TimeShift,
 		request:    *req,
 	}
 
@@ -201,6 +229,29 @@ func (s *Service) buildDSNode(dp *simple.DirectedGraph, rn *rawNode, req *Reques
 		dsNode.maxDP = int64(floatMaxDP)
 	}
 
+	if rawEvalMode, ok := rn.Query["evalMode"]; ok {
+		evalMode, ok := rawEvalMode.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected evalMode to be a string, got type %T for refId %v", rawEvalMode, rn.RefID)
+		}
+		if evalMode != "" && evalMode != EvalModeLogs {
+			return nil, fmt.Errorf("evalMode %q is not supported for refId %v, the only eval mode is %v", evalMode, rn.RefID, EvalModeLogs)
+		}
+		dsNode.evalMode = evalMode
+		dsNode.logSamples = defaultLogSamples
+	}
+
+	if rawLogSamples, ok := rn.Query["logSamples"]; ok {
+		floatLogSamples, ok := rawLogSamples.(float64)
+		if !ok {
+			return nil, fmt.Errorf("expected logSamples to be an float64, got type %T for refId %v", rawLogSamples, rn.RefID)
+		}
+		if floatLogSamples < 0 {
+			return nil, fmt.Errorf("logSamples must not be negative, got %v for refId %v", floatLogSamples, rn.RefID)
+		}
+		dsNode.logSamples = int(floatLogSamples)
+	}
+
 	return dsNode, nil
 }
 
@@ -230,24 +281,49 @@ func (dn *DSNode) Execute(ctx context.Context, vars mathexp.Vars, s *Service) (m
 		},
 	}
 
-	resp, err := s.queryData(ctx, &backend.QueryDataRequest{
-		PluginContext: pc,
-		Queries:       q,
-		Headers:       dn.request.Headers,
-	})
-
-	if err != nil {
-		return mathexp.Results{}, err
+	var resp *backend.QueryDataResponse
+	if dn.request.Fixtures != nil {
+		frames, ok := dn.request.Fixtures[dn.refID]
+		if !ok {
+			return mathexp.Results{}, fmt.Errorf("no fixture for query %v", dn.refID)
+		}
+		resp = &backend.QueryDataResponse{Responses: backend.Responses{dn.refID: {Frames: frames}}}
+	} else {
+		var err error
+		resp, err = s.queryData(ctx, &backend.QueryDataRequest{
+			PluginContext: pc,
+			Queries:       q,
+			Headers:       dn.request.Headers,
+		})
+		if err != nil {
+			return mathexp.Results{}, err
+		}
 	}
 
 	vals := make([]mathexp.Value, 0)
+	var notices []data.Notice
 	for refID, qr := range resp.Responses {
 		if qr.Error != nil {
 			return mathexp.Results{}, fmt.Errorf("failed to execute query %v: %w", refID, qr.Error)
 		}
 
-		if len(qr.Frames) == 1 {
-			frame := qr.Frames[0]
+		frames, frameNotices := dn.request.Limits.limitFrames(refID, qr.Frames)
+		notices = append(notices, frameNotices...)
+
+		if dn.evalMode == EvalModeLogs {
+			logger.Debug("expression datasource query (logs)", "query", refID)
+			for _, frame := range frames {
+				n, err := extractLogCount(frame, dn.logSamples)
+				if err != nil {
+					return mathexp.Results{}, fmt.Errorf("failed to count the log lines of query %v: %w", refID, err)
+				}
+				vals = append(vals, n)
+			}
+			continue
+		}
+
+		if len(frames) == 1 {
+			frame := frames[0]
 			if frame.TimeSeriesSchema().Type == data.TimeSeriesTypeNot && isNumberTable(frame) {
 				logger.Debug("expression datasource query (numberSet)", "query", refID)
 				numberSet, err := extractNumberSet(frame)
@@ -258,28 +334,59 @@ func (dn *DSNode) Execute(ctx context.Context, vars mathexp.Vars, s *Service) (m
 					vals = append(vals, n)
 				}
 
-				return mathexp.Results{
-					Values: vals,
-				}, nil
+				return dn.limitResults(ctx, vals, notices)
 			}
 		}
 
-		for _, frame := range qr.Frames {
+		for _, frame := range frames {
 			logger.Debug("expression datasource query (seriesSet)", "query", refID)
 			series, err := WideToMany(frame)
 			if err != nil {
 				return mathexp.Results{}, err
 			}
 			for _, s := range series {
+				if dn.timeShift != 0 {
+					if err := shiftSeries(s, dn.timeShift); err != nil {
+						return mathexp.Results{}, err
+					}
+				}
 				vals = append(vals, s)
 			}
 		}
 	}
+	if dn.evalMode == EvalModeLogs && len(vals) == 0 {
+		// no stream has a line, which is a count of zero rather than no data
+		vals = append(vals, zeroLogCount(dn.refID))
+	}
+	return dn.limitResults(ctx, vals, notices)
+}
+
+// limitResults truncates the values to the result limits of the request, the notices of the truncations are attached
+// to the first frame of the results. The largest series are then spilled to disk if the values are still over the
+// spill threshold of the request.
+func (dn *DSNode) limitResults(ctx context.Context, vals []mathexp.Value, notices []data.Notice) (mathexp.Results, error) {
+	vals, valueNotices := dn.request.Limits.limitValues(dn.refID, vals)
+	attachNotices(vals, append(notices, valueNotices...))
+	vals, err := dn.request.Spill.spill(spillFilesFromContext(ctx), dn.refID, vals)
+	if err != nil {
+		return mathexp.Results{}, err
+	}
 	return mathexp.Results{
 		Values: vals,
 	}, nil
 }
 
+// shiftSeries moves the times of the points of the series forward by the duration.
+func shiftSeries(s mathexp.Series, d time.Duration) error {
+	for i := 0; i < s.Len(); i++ {
+		t, v := s.GetPoint(i)
+		if err := s.SetPoint(i, t.Add(d), v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func isNumberTable(frame *data.Frame) bool {
 	if frame == nil || frame.Fields == nil {
 		return false
@@ -0,0 +1,199 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+const (
+	// OutliersZScore flags the numbers whose distance from the mean is greater than Threshold standard deviations.
+	OutliersZScore = "zscore"
+	// OutliersMAD flags the numbers whose distance from the median is greater than Threshold median absolute
+	// deviations, which is less sensitive than the z-score to the outliers themselves.
+	OutliersMAD = "mad"
+	// OutliersDBSCAN groups the numbers that are at most Threshold apart, and flags the numbers outside the largest
+	// group.
+	OutliersDBSCAN = "dbscan"
+
+	// madScale makes the median absolute deviation comparable to the standard deviation of normal distributions.
+	madScale = 1.4826
+)
+
+// OutliersCommand is an expression command that flags the numbers that deviate from the other numbers of the
+// same query, such as the pod that behaves differently from its peers.
+type OutliersCommand struct {
+	VarToCheck string
+	Algorithm  string
+	Threshold  float64
+	refID      string
+}
+
+// NewOutliersCommand creates a new OutliersCommand.
+func NewOutliersCommand(refID, varToCheck, algorithm string, threshold float64) (*OutliersCommand, error) {
+	switch algorithm {
+	case OutliersZScore, OutliersMAD, OutliersDBSCAN:
+	default:
+		return nil, fmt.Errorf("outliers algorithm %q is not one of %s, %s, %s for refId %v", algorithm, OutliersZScore, OutliersMAD, OutliersDBSCAN, refID)
+	}
+	if threshold <= 0 {
+		return nil, fmt.Errorf("outliers threshold must be positive, got %v for refId %v", threshold, refID)
+	}
+	return &OutliersCommand{
+		VarToCheck: varToCheck,
+		Algorithm:  algorithm,
+		Threshold:  threshold,
+		refID:      refID,
+	}, nil
+}
+
+// UnmarshalOutliersCommand creates an OutliersCommand from Grafana's frontend query.
+func UnmarshalOutliersCommand(rn *rawNode) (*OutliersCommand, error) {
+	rawVar, ok := rn.Query["expression"]
+	if !ok {
+		return nil, fmt.Errorf("no variable specified to detect the outliers of for refId %v", rn.RefID)
+	}
+	varToCheck, ok := rawVar.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected outliers input variable to be a string, got %T for refId %v", rawVar, rn.RefID)
+	}
+	varToCheck = strings.TrimPrefix(varToCheck, "$")
+
+	rawAlgorithm, ok := rn.Query["algorithm"]
+	if !ok {
+		return nil, fmt.Errorf("no algorithm specified in outliers command for refId %v", rn.RefID)
+	}
+	algorithm, ok := rawAlgorithm.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected outliers algorithm to be a string, got %T for refId %v", rawAlgorithm, rn.RefID)
+	}
+
+	rawThreshold, ok := rn.Query["threshold"]
+	if !ok {
+		return nil, fmt.Errorf("no threshold specified in outliers command for refId %v", rn.RefID)
+	}
+	threshold, ok := rawThreshold.(float64)
+	if !ok {
+		return nil, fmt.Errorf("expected outliers threshold to be a number, got %T for refId %v", rawThreshold, rn.RefID)
+	}
+
+	return NewOutliersCommand(rn.RefID, varToCheck, algorithm, threshold)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (oc *OutliersCommand) NeedsVars() []string {
+	return []string{oc.VarToCheck}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute. The result is 1 for the numbers that are outliers, and 0 for the others.
+// The numbers without a value have no value, and are not part of the population.
+func (oc *OutliersCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathexp.Results, error) {
+	newRes := mathexp.Results{}
+	values := vars[oc.VarToCheck].Values
+	labels := make([]data.Labels, 0, len(values))
+	nums := make([]*float64, 0, len(values))
+	population := make([]float64, 0, len(values))
+	for _, val := range values {
+		num, ok := val.(mathexp.Number)
+		if !ok {
+			return newRes, fmt.Errorf("can only detect the outliers of type number, got type %v", val.Type())
+		}
+		labels = append(labels, num.GetLabels())
+		v := num.GetFloat64Value()
+		if v != nil && math.IsNaN(*v) {
+			v = nil
+		}
+		nums = append(nums, v)
+		if v != nil {
+			population = append(population, *v)
+		}
+	}
+
+	isOutlier := oc.detector(population)
+	for i, v := range nums {
+		num := mathexp.NewNumber(oc.refID, labels[i])
+		if v != nil {
+			var outlier float64
+			if isOutlier(*v) {
+				outlier = 1
+			}
+			num.SetValue(&outlier)
+		}
+		newRes.Values = append(newRes.Values, num)
+	}
+	return newRes, nil
+}
+
+// detector returns the function that tells whether a value is an outlier of the population.
+func (oc *OutliersCommand) detector(population []float64) func(float64) bool {
+	if len(population) < 2 {
+		return func(float64) bool { return false }
+	}
+	switch oc.Algorithm {
+	case OutliersMAD:
+		median := medianOf(population)
+		deviations := make([]float64, 0, len(population))
+		for _, v := range population {
+			deviations = append(deviations, math.Abs(v-median))
+		}
+		mad := medianOf(deviations) * madScale
+		return func(v float64) bool {
+			return math.Abs(v-median) > oc.Threshold*mad
+		}
+	case OutliersDBSCAN:
+		lower, upper := largestCluster(population, oc.Threshold)
+		return func(v float64) bool {
+			return v < lower || v > upper
+		}
+	default:
+		var sum float64
+		for _, v := range population {
+			sum += v
+		}
+		mean := sum / float64(len(population))
+		var variance float64
+		for _, v := range population {
+			variance += (v - mean) * (v - mean)
+		}
+		stddev := math.Sqrt(variance / float64(len(population)))
+		return func(v float64) bool {
+			return math.Abs(v-mean) > oc.Threshold*stddev
+		}
+	}
+}
+
+func medianOf(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// largestCluster returns the bounds of the largest group of values that are at most epsilon apart from their
+// neighbours, the first one if several groups have the same size.
+func largestCluster(values []float64, epsilon float64) (float64, float64) {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	bestStart, bestEnd, start := 0, 0, 0
+	for i := 1; i <= len(sorted); i++ {
+		if i < len(sorted) && sorted[i]-sorted[i-1] <= epsilon {
+			continue
+		}
+		if i-1-start > bestEnd-bestStart {
+			bestStart, bestEnd = start, i-1
+		}
+		start = i
+	}
+	return sorted[bestStart], sorted[bestEnd]
+}
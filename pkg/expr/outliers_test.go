@@ -0,0 +1,80 @@
+package expr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+func TestOutliersCommand(t *testing.T) {
+	numbers := func(values ...*float64) []mathexp.Value {
+		result := make([]mathexp.Value, 0, len(values))
+		for i, v := range values {
+			n := mathexp.NewNumber("B", data.Labels{"pod": string(rune('a' + i))})
+			n.SetValue(v)
+			result = append(result, n)
+		}
+		return result
+	}
+	execute := func(t *testing.T, algorithm string, threshold float64, values ...mathexp.Value) []*float64 {
+		t.Helper()
+		cmd, err := UnmarshalOutliersCommand(&rawNode{RefID: "C", Query: map[string]interface{}{
+			"type":       "outliers",
+			"expression": "$B",
+			"algorithm":  algorithm,
+			"threshold":  threshold,
+		}})
+		require.NoError(t, err)
+		require.Equal(t, []string{"B"}, cmd.NeedsVars())
+		res, err := cmd.Execute(context.Background(), mathexp.Vars{"B": mathexp.Results{Values: values}})
+		require.NoError(t, err)
+		result := make([]*float64, 0, len(res.Values))
+		for i, v := range res.Values {
+			require.Equal(t, values[i].GetLabels(), v.GetLabels())
+			result = append(result, v.(mathexp.Number).GetFloat64Value())
+		}
+		return result
+	}
+
+	t.Run("zscore", func(t *testing.T) {
+		require.Equal(t, []*float64{fp(0), fp(0), fp(0), fp(0), fp(1), nil}, execute(t, OutliersZScore, 1.5,
+			numbers(fp(10), fp(11), fp(9), fp(10), fp(30), nil)...))
+	})
+
+	t.Run("mad", func(t *testing.T) {
+		require.Equal(t, []*float64{fp(0), fp(0), fp(0), fp(0), fp(1)}, execute(t, OutliersMAD, 3,
+			numbers(fp(10), fp(11), fp(9), fp(10), fp(30))...))
+	})
+
+	t.Run("dbscan", func(t *testing.T) {
+		require.Equal(t, []*float64{fp(0), fp(0), fp(0), fp(1), fp(1)}, execute(t, OutliersDBSCAN, 2,
+			numbers(fp(10), fp(11), fp(12), fp(20), fp(21))...))
+	})
+
+	t.Run("a single number is not an outlier", func(t *testing.T) {
+		require.Equal(t, []*float64{fp(0)}, execute(t, OutliersZScore, 1, numbers(fp(10))...))
+	})
+
+	t.Run("fails if the values are not valid", func(t *testing.T) {
+		for _, query := range []map[string]interface{}{
+			{"expression": "B", "algorithm": "iforest", "threshold": 3.0},
+			{"expression": "B", "algorithm": "zscore", "threshold": 0.0},
+			{"expression": "B", "algorithm": "zscore"},
+			{"algorithm": "zscore", "threshold": 3.0},
+		} {
+			_, err := UnmarshalOutliersCommand(&rawNode{RefID: "C", Query: query})
+			require.Error(t, err)
+		}
+	})
+
+	t.Run("fails with series", func(t *testing.T) {
+		cmd, err := NewOutliersCommand("C", "B", OutliersZScore, 3)
+		require.NoError(t, err)
+		_, err = cmd.Execute(context.Background(), mathexp.Vars{"B": mathexp.Results{Values: []mathexp.Value{mathexp.NewSeries("B", nil, 0)}}})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,61 @@
+package expr
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+type queryObserverKey struct{}
+
+// QueryObserver is called after each datasource query of the pipelines executed with a context returned by
+// WithQueryObserver, such as to attribute the load of the datasources to the alert rules.
+type QueryObserver func(q QueryStats)
+
+// QueryStats are the statistics of a datasource query of a pipeline.
+type QueryStats struct {
+	RefID         string
+	OrgID         int64
+	DatasourceUID string
+	Duration      time.Duration
+	// Series is the number of series and numbers of the result, and DataPoints their number of points.
+	Series     int
+	DataPoints int
+	Error      error
+}
+
+// WithQueryObserver returns a context calling the observer after each datasource query of the pipelines.
+func WithQueryObserver(ctx context.Context, observer QueryObserver) context.Context {
+	return context.WithValue(ctx, queryObserverKey{}, observer)
+}
+
+func queryObserverFromContext(ctx context.Context) QueryObserver {
+	o, _ := ctx.Value(queryObserverKey{}).(QueryObserver)
+	return o
+}
+
+func queryStats(dn *DSNode, res mathexp.Results, err error, duration time.Duration) QueryStats {
+	q := QueryStats{
+		RefID:         dn.refID,
+		OrgID:         dn.orgID,
+		DatasourceUID: dn.datasourceUID,
+		Duration:      duration,
+		Error:         err,
+	}
+	if err != nil {
+		return q
+	}
+	q.Series = len(res.Values)
+	for _, val := range res.Values {
+		switch s := val.(type) {
+		case mathexp.Series:
+			q.DataPoints += s.Len()
+		case mathexp.SpilledSeries:
+			q.DataPoints += s.Len()
+		default:
+			q.DataPoints++
+		}
+	}
+	return q
+}
@@ -0,0 +1,41 @@
+package expr
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+func TestQueryStats(t *testing.T) {
+	dn := &DSNode{baseNode: baseNode{refID: "A"}, orgID: 1, datasourceUID: "prometheus"}
+	res := mathexp.Results{Values: []mathexp.Value{
+		mathexp.NewSeries("A", data.Labels{"pod": "a"}, 3),
+		mathexp.NewSeries("A", data.Labels{"pod": "b"}, 2),
+		mathexp.NewNumber("A", data.Labels{"pod": "c"}),
+	}}
+
+	q := queryStats(dn, res, nil, time.Second)
+	require.Equal(t, QueryStats{RefID: "A", OrgID: 1, DatasourceUID: "prometheus", Duration: time.Second, Series: 3, DataPoints: 6}, q)
+
+	err := errors.New("timeout")
+	q = queryStats(dn, mathexp.Results{}, err, time.Second)
+	require.Equal(t, err, q.Error)
+	require.Zero(t, q.Series)
+}
+
+func TestQueryObserverFromContext(t *testing.T) {
+	require.Nil(t, queryObserverFromContext(context.Background()))
+
+	var observed []QueryStats
+	ctx := WithQueryObserver(context.Background(), func(q QueryStats) {
+		observed = append(observed, q)
+	})
+	queryObserverFromContext(ctx)(QueryStats{RefID: "A"})
+	require.Equal(t, []QueryStats{{RefID: "A"}}, observed)
+}
@@ -0,0 +1,74 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// RateCommand is an expression command that converts series into their per-second rate of change, so the rate of
+// cumulative series can be alerted on with datasources that have no rate function.
+type RateCommand struct {
+	VarToRate string
+	// Counter is true if the series are counters whose decreases are resets.
+	Counter bool
+	refID   string
+}
+
+// NewRateCommand creates a new RateCommand.
+func NewRateCommand(refID, varToRate string, counter bool) *RateCommand {
+	return &RateCommand{
+		VarToRate: varToRate,
+		Counter:   counter,
+		refID:     refID,
+	}
+}
+
+// UnmarshalRateCommand creates a RateCommand from Grafana's frontend query.
+func UnmarshalRateCommand(rn *rawNode) (*RateCommand, error) {
+	rawVar, ok := rn.Query["expression"]
+	if !ok {
+		return nil, fmt.Errorf("no variable specified to compute the rate of for refId %v", rn.RefID)
+	}
+	varToRate, ok := rawVar.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected rate input variable to be a string, got %T for refId %v", rawVar, rn.RefID)
+	}
+	varToRate = strings.TrimPrefix(varToRate, "$")
+
+	var counter bool
+	if rawCounter, ok := rn.Query["counter"]; ok && rawCounter != nil {
+		counter, ok = rawCounter.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected rate counter to be a boolean, got %T for refId %v", rawCounter, rn.RefID)
+		}
+	}
+
+	return NewRateCommand(rn.RefID, varToRate, counter), nil
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (rc *RateCommand) NeedsVars() []string {
+	return []string{rc.VarToRate}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (rc *RateCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathexp.Results, error) {
+	newRes := mathexp.Results{}
+	for _, val := range vars[rc.VarToRate].Values {
+		series, ok := val.(mathexp.Series)
+		if !ok {
+			return newRes, fmt.Errorf("can only compute the rate of type series, got type %v", val.Type())
+		}
+		rate, err := series.Rate(rc.refID, rc.Counter)
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, rate)
+	}
+	return newRes, nil
+}
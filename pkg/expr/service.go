@@ -41,6 +41,8 @@ func (s *Service) BuildPipeline(req *Request) (DataPipeline, error) {
 // ExecutePipeline executes an expression pipeline and returns all the results.
 func (s *Service) ExecutePipeline(ctx context.Context, pipeline DataPipeline) (*backend.QueryDataResponse, error) {
 	res := backend.NewQueryDataResponse()
+	ctx, spilled := withSpillFiles(ctx)
+	defer spilled.remove()
 	vars, err := pipeline.execute(ctx, s)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,159 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// seriesPointSize is the estimated size in memory of a point of a series: its time and its nullable value.
+const seriesPointSize = 24 + 8 + 8
+
+// SpillOptions spill the largest series of the result of each data source query of a Request to temporary files, so
+// that a single query returning a huge result doesn't exhaust the memory of the evaluation. The spilled series are
+// reduced by streaming their points from the files, the other expressions load them back in memory.
+type SpillOptions struct {
+	// Threshold is the estimated size in bytes of the series of the result of a query above which the largest series
+	// are spilled, until the size of the others is below it. 0 never spills.
+	Threshold int64
+	// Dir is the directory of the spill files, the default directory for temporary files if empty.
+	Dir string
+}
+
+// IsEmpty returns true if the series are never spilled.
+func (o SpillOptions) IsEmpty() bool {
+	return o.Threshold <= 0
+}
+
+type spillFilesKey struct{}
+
+// spillFiles are the series spilled by the execution of a pipeline, removed when it finishes.
+type spillFiles struct {
+	mtx    sync.Mutex
+	series []mathexp.SpilledSeries
+}
+
+func withSpillFiles(ctx context.Context) (context.Context, *spillFiles) {
+	f := &spillFiles{}
+	return context.WithValue(ctx, spillFilesKey{}, f), f
+}
+
+func spillFilesFromContext(ctx context.Context) *spillFiles {
+	f, _ := ctx.Value(spillFilesKey{}).(*spillFiles)
+	return f
+}
+
+func (f *spillFiles) add(s mathexp.SpilledSeries) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	f.series = append(f.series, s)
+}
+
+// remove removes the files of the spilled series.
+func (f *spillFiles) remove() {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	for _, s := range f.series {
+		if err := s.Remove(); err != nil {
+			logger.Warn("failed to remove a spill file", "path", s.Path, "err", err)
+		}
+	}
+	f.series = nil
+}
+
+// spill spills the largest series of the result of the query refID while the estimated size of its series is over
+// the threshold. A notice is attached to the frames of the spilled series, which are returned without their points.
+func (o SpillOptions) spill(files *spillFiles, refID string, vals []mathexp.Value) ([]mathexp.Value, error) {
+	if o.IsEmpty() || files == nil {
+		return vals, nil
+	}
+	var size int64
+	var largest []int
+	for i, v := range vals {
+		if s, ok := v.(mathexp.Series); ok {
+			size += int64(s.Len()) * seriesPointSize
+			largest = append(largest, i)
+		}
+	}
+	if size <= o.Threshold {
+		return vals, nil
+	}
+	sort.SliceStable(largest, func(i, j int) bool {
+		return vals[largest[i]].(mathexp.Series).Len() > vals[largest[j]].(mathexp.Series).Len()
+	})
+
+	spilled := make([]mathexp.Value, len(vals))
+	copy(spilled, vals)
+	for _, i := range largest {
+		if size <= o.Threshold {
+			break
+		}
+		s := vals[i].(mathexp.Series)
+		ss, err := mathexp.SpillSeries(o.Dir, s)
+		if err != nil {
+			return nil, fmt.Errorf("failed to spill the result of query %v: %w", refID, err)
+		}
+		files.add(ss)
+		expressionsResultSpills.Inc()
+		if ss.Frame.Meta == nil {
+			ss.Frame.Meta = &data.FrameMeta{}
+		} else {
+			meta := *ss.Frame.Meta
+			ss.Frame.Meta = &meta
+		}
+		ss.Frame.Meta.Notices = append(ss.Frame.Meta.Notices, data.Notice{
+			Severity: data.NoticeSeverityInfo,
+			Text:     fmt.Sprintf("the %d points of this series of query %s were spilled to disk and are not returned", ss.Points, refID),
+		})
+		spilled[i] = ss
+		size -= int64(s.Len()) * seriesPointSize
+	}
+	return spilled, nil
+}
+
+// loadSpilled returns the variables with the spilled series of the variables names loaded back in memory, for the
+// expressions that can't stream them.
+func loadSpilled(vars mathexp.Vars, names []string) (mathexp.Vars, error) {
+	var loaded mathexp.Vars
+	for _, name := range names {
+		res, ok := vars[name]
+		if !ok {
+			continue
+		}
+		var values mathexp.Values
+		for i, v := range res.Values {
+			ss, ok := v.(mathexp.SpilledSeries)
+			if !ok {
+				continue
+			}
+			if values == nil {
+				values = make(mathexp.Values, len(res.Values))
+				copy(values, res.Values)
+			}
+			s, err := ss.Load()
+			if err != nil {
+				return nil, fmt.Errorf("failed to load the spilled series of %v: %w", name, err)
+			}
+			values[i] = s
+		}
+		if values == nil {
+			continue
+		}
+		if loaded == nil {
+			loaded = make(mathexp.Vars, len(vars))
+			for k, v := range vars {
+				loaded[k] = v
+			}
+		}
+		loaded[name] = mathexp.Results{Values: values}
+	}
+	if loaded == nil {
+		return vars, nil
+	}
+	return loaded, nil
+}
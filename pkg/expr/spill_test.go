@@ -0,0 +1,80 @@
+package expr
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+func TestSpill(t *testing.T) {
+	series := func(pod string, points int) *data.Frame {
+		times := make([]time.Time, points)
+		values := make([]*float64, points)
+		for i := 0; i < points; i++ {
+			times[i] = time.Unix(int64(i*10), 0)
+			values[i] = fp(float64(i))
+		}
+		return data.NewFrame("",
+			data.NewField("Time", nil, times),
+			data.NewField("Value", data.Labels{"pod": pod}, values))
+	}
+	execute := func(t *testing.T, spill SpillOptions, cmd Command) map[string]data.Frames {
+		s := Service{}
+		req := Request{
+			Spill:    spill,
+			Fixtures: map[string]data.Frames{"A": {series("big", 1000), series("small", 10)}},
+		}
+		pl := DataPipeline{
+			&DSNode{baseNode: baseNode{refID: "A"}, request: req},
+			&CMDNode{baseNode: baseNode{refID: "B"}, Command: cmd},
+		}
+		res, err := s.ExecutePipeline(context.Background(), pl)
+		require.NoError(t, err)
+		frames := map[string]data.Frames{}
+		for refID, r := range res.Responses {
+			frames[refID] = r.Frames
+		}
+		return frames
+	}
+	t.Run("the series under the threshold are not spilled", func(t *testing.T) {
+		vals := []mathexp.Value{mathexp.NewSeries("A", nil, 10)}
+		spilled, err := SpillOptions{Threshold: 10 * seriesPointSize}.spill(&spillFiles{}, "A", vals)
+		require.NoError(t, err)
+		require.Equal(t, vals, spilled)
+	})
+
+	t.Run("the largest series are spilled and reduced", func(t *testing.T) {
+		dir := t.TempDir()
+		frames := execute(t, SpillOptions{Threshold: 100 * seriesPointSize, Dir: dir}, NewReduceCommand("B", "sum", "A"))
+
+		require.Len(t, frames["A"], 2)
+		require.Equal(t, 0, frames["A"][0].Rows())
+		require.Len(t, frames["A"][0].Meta.Notices, 1)
+		require.Equal(t, "the 1000 points of this series of query A were spilled to disk and are not returned", frames["A"][0].Meta.Notices[0].Text)
+		require.Equal(t, 10, frames["A"][1].Rows())
+
+		require.Len(t, frames["B"], 2)
+		require.Equal(t, fp(499500), frames["B"][0].Fields[0].At(0))
+		require.Equal(t, fp(45), frames["B"][1].Fields[0].At(0))
+
+		// The spill files are removed when the pipeline finishes.
+		files, err := ioutil.ReadDir(dir)
+		require.NoError(t, err)
+		require.Empty(t, files)
+	})
+
+	t.Run("the spilled series are loaded by the other expressions", func(t *testing.T) {
+		cmd, err := NewMathCommand("B", "$A * 2")
+		require.NoError(t, err)
+		frames := execute(t, SpillOptions{Threshold: 1, Dir: t.TempDir()}, cmd)
+		require.Len(t, frames["B"], 2)
+		require.Equal(t, 1000, frames["B"][0].Rows())
+		require.Equal(t, fp(1998), frames["B"][0].Fields[1].At(999))
+	})
+}
@@ -0,0 +1,185 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+const (
+	// ThresholdIsAbove is the threshold type of the values above the threshold.
+	ThresholdIsAbove = "gt"
+	// ThresholdIsBelow is the threshold type of the values below the threshold.
+	ThresholdIsBelow = "lt"
+
+	// ThresholdPreviouslyFiringKey is the key of the labels of the values that passed the threshold at the previous
+	// evaluation in the query of a threshold command, it's set by the caller that keeps the results.
+	ThresholdPreviouslyFiringKey = "previouslyFiring"
+)
+
+// ThresholdCommand is an expression command that compares numbers with a threshold, such as "$B > 90". With an exit
+// value the comparison has an hysteresis: the numbers that passed the enter value at the previous evaluation pass
+// the threshold until they pass back the exit value, such as below 80 for an enter value of 90.
+type ThresholdCommand struct {
+	ReferenceVar  string
+	ThresholdType string
+	Enter         float64
+	// Exit is the value the numbers that passed the threshold must pass back, nil without hysteresis.
+	Exit *float64
+	// PreviouslyFiring are the labels of the numbers that passed the threshold at the previous evaluation.
+	PreviouslyFiring []data.Labels
+	refID            string
+}
+
+// NewThresholdCommand creates a new ThresholdCommand.
+func NewThresholdCommand(refID, referenceVar, thresholdType string, enter float64, exit *float64, previouslyFiring []data.Labels) (*ThresholdCommand, error) {
+	switch thresholdType {
+	case ThresholdIsAbove:
+		if exit != nil && *exit > enter {
+			return nil, fmt.Errorf("exit value %v of threshold type %s must not be greater than the enter value %v", *exit, thresholdType, enter)
+		}
+	case ThresholdIsBelow:
+		if exit != nil && *exit < enter {
+			return nil, fmt.Errorf("exit value %v of threshold type %s must not be less than the enter value %v", *exit, thresholdType, enter)
+		}
+	default:
+		return nil, fmt.Errorf("threshold type %q is not one of %s, %s", thresholdType, ThresholdIsAbove, ThresholdIsBelow)
+	}
+	return &ThresholdCommand{
+		ReferenceVar:     referenceVar,
+		ThresholdType:    thresholdType,
+		Enter:            enter,
+		Exit:             exit,
+		PreviouslyFiring: previouslyFiring,
+		refID:            refID,
+	}, nil
+}
+
+// UnmarshalThresholdCommand creates a ThresholdCommand from Grafana's frontend query.
+func UnmarshalThresholdCommand(rn *rawNode) (*ThresholdCommand, error) {
+	rawVar, ok := rn.Query["expression"]
+	if !ok {
+		return nil, fmt.Errorf("no variable specified to apply the threshold to for refId %v", rn.RefID)
+	}
+	referenceVar, ok := rawVar.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected threshold variable to be a string, got %T for refId %v", rawVar, rn.RefID)
+	}
+	referenceVar = strings.TrimPrefix(referenceVar, "$")
+
+	rawType, ok := rn.Query["thresholdType"]
+	if !ok {
+		return nil, fmt.Errorf("no threshold type specified for refId %v", rn.RefID)
+	}
+	thresholdType, ok := rawType.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected threshold type to be a string, got %T for refId %v", rawType, rn.RefID)
+	}
+
+	rawEnter, ok := rn.Query["enter"]
+	if !ok {
+		return nil, fmt.Errorf("no enter value specified for the threshold of refId %v", rn.RefID)
+	}
+	enter, ok := rawEnter.(float64)
+	if !ok {
+		return nil, fmt.Errorf("expected threshold enter value to be a number, got %T for refId %v", rawEnter, rn.RefID)
+	}
+
+	var exit *float64
+	if rawExit, ok := rn.Query["exit"]; ok && rawExit != nil {
+		e, ok := rawExit.(float64)
+		if !ok {
+			return nil, fmt.Errorf("expected threshold exit value to be a number, got %T for refId %v", rawExit, rn.RefID)
+		}
+		exit = &e
+	}
+
+	var previouslyFiring []data.Labels
+	if rawFiring, ok := rn.Query[ThresholdPreviouslyFiringKey]; ok && rawFiring != nil {
+		list, ok := rawFiring.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected previously firing labels to be a list, got %T for refId %v", rawFiring, rn.RefID)
+		}
+		for _, rawLabels := range list {
+			m, ok := rawLabels.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("expected previously firing labels to be objects, got %T for refId %v", rawLabels, rn.RefID)
+			}
+			labels := make(data.Labels, len(m))
+			for k, v := range m {
+				s, ok := v.(string)
+				if !ok {
+					return nil, fmt.Errorf("expected previously firing label %q to be a string, got %T for refId %v", k, v, rn.RefID)
+				}
+				labels[k] = s
+			}
+			previouslyFiring = append(previouslyFiring, labels)
+		}
+	}
+
+	return NewThresholdCommand(rn.RefID, referenceVar, thresholdType, enter, exit, previouslyFiring)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (tc *ThresholdCommand) NeedsVars() []string {
+	return []string{tc.ReferenceVar}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute. The result is 1 for the numbers that pass the threshold, and 0 for the others.
+func (tc *ThresholdCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathexp.Results, error) {
+	newRes := mathexp.Results{}
+	for _, val := range vars[tc.ReferenceVar].Values {
+		var (
+			labels data.Labels
+			value  *float64
+		)
+		switch v := val.(type) {
+		case mathexp.Number:
+			labels = v.GetLabels()
+			value = v.GetFloat64Value()
+		case mathexp.Scalar:
+			value = v.GetFloat64Value()
+		default:
+			return newRes, fmt.Errorf("can only apply a threshold to numbers, got type %v", val.Type())
+		}
+
+		num := mathexp.NewNumber(tc.refID, labels)
+		if value != nil {
+			var passed float64
+			if tc.passes(*value, labels) {
+				passed = 1
+			}
+			num.SetValue(&passed)
+		}
+		newRes.Values = append(newRes.Values, num)
+	}
+	return newRes, nil
+}
+
+// passes compares the value with the exit value if the number passed the threshold at the previous evaluation,
+// and with the enter value otherwise.
+func (tc *ThresholdCommand) passes(value float64, labels data.Labels) bool {
+	threshold := tc.Enter
+	if tc.Exit != nil && tc.previouslyFiring(labels) {
+		threshold = *tc.Exit
+	}
+	if tc.ThresholdType == ThresholdIsBelow {
+		return value < threshold
+	}
+	return value > threshold
+}
+
+func (tc *ThresholdCommand) previouslyFiring(labels data.Labels) bool {
+	for _, l := range tc.PreviouslyFiring {
+		if l.Equals(labels) {
+			return true
+		}
+	}
+	return false
+}
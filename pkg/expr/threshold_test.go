@@ -0,0 +1,95 @@
+package expr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+func TestThresholdCommand(t *testing.T) {
+	number := func(labels data.Labels, value *float64) mathexp.Number {
+		n := mathexp.NewNumber("B", labels)
+		n.SetValue(value)
+		return n
+	}
+	execute := func(t *testing.T, query map[string]interface{}, values ...mathexp.Value) []*float64 {
+		t.Helper()
+		cmd, err := UnmarshalThresholdCommand(&rawNode{RefID: "C", Query: query})
+		require.NoError(t, err)
+		require.Equal(t, []string{"B"}, cmd.NeedsVars())
+		res, err := cmd.Execute(context.Background(), mathexp.Vars{"B": mathexp.Results{Values: values}})
+		require.NoError(t, err)
+		result := make([]*float64, 0, len(res.Values))
+		for _, v := range res.Values {
+			result = append(result, v.(mathexp.Number).GetFloat64Value())
+		}
+		return result
+	}
+
+	t.Run("compares the numbers with the enter value", func(t *testing.T) {
+		query := map[string]interface{}{"type": "threshold", "expression": "$B", "thresholdType": "gt", "enter": 90.0}
+		require.Equal(t, []*float64{fp(1), fp(0), nil}, execute(t, query,
+			number(data.Labels{"instance": "a"}, fp(95)),
+			number(data.Labels{"instance": "b"}, fp(85)),
+			number(data.Labels{"instance": "c"}, nil),
+		))
+	})
+
+	t.Run("compares the previously firing numbers with the exit value", func(t *testing.T) {
+		query := map[string]interface{}{
+			"type":          "threshold",
+			"expression":    "B",
+			"thresholdType": "gt",
+			"enter":         90.0,
+			"exit":          80.0,
+			ThresholdPreviouslyFiringKey: []interface{}{
+				map[string]interface{}{"instance": "a"},
+				map[string]interface{}{"instance": "b"},
+			},
+		}
+		require.Equal(t, []*float64{fp(1), fp(0), fp(0)}, execute(t, query,
+			number(data.Labels{"instance": "a"}, fp(85)),
+			number(data.Labels{"instance": "b"}, fp(75)),
+			number(data.Labels{"instance": "c"}, fp(85)),
+		))
+	})
+
+	t.Run("compares with lt thresholds", func(t *testing.T) {
+		query := map[string]interface{}{
+			"type":                       "threshold",
+			"expression":                 "B",
+			"thresholdType":              "lt",
+			"enter":                      10.0,
+			"exit":                       20.0,
+			ThresholdPreviouslyFiringKey: []interface{}{map[string]interface{}{"instance": "a"}},
+		}
+		require.Equal(t, []*float64{fp(1), fp(0), fp(1)}, execute(t, query,
+			number(data.Labels{"instance": "a"}, fp(15)),
+			number(data.Labels{"instance": "b"}, fp(15)),
+			number(data.Labels{"instance": "c"}, fp(5)),
+		))
+	})
+
+	t.Run("fails if the values are not valid", func(t *testing.T) {
+		for _, query := range []map[string]interface{}{
+			{"expression": "B", "thresholdType": "eq", "enter": 90.0},
+			{"expression": "B", "thresholdType": "gt", "enter": 90.0, "exit": 95.0},
+			{"expression": "B", "thresholdType": "lt", "enter": 10.0, "exit": 5.0},
+			{"expression": "B", "thresholdType": "gt"},
+		} {
+			_, err := UnmarshalThresholdCommand(&rawNode{RefID: "C", Query: query})
+			require.Error(t, err)
+		}
+	})
+
+	t.Run("fails for series", func(t *testing.T) {
+		cmd, err := NewThresholdCommand("C", "B", ThresholdIsAbove, 90, nil, nil)
+		require.NoError(t, err)
+		_, err = cmd.Execute(context.Background(), mathexp.Vars{"B": mathexp.Results{Values: mathexp.Values{mathexp.NewSeries("B", nil, 0)}}})
+		require.Error(t, err)
+	})
+}
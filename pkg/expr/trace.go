@@ -0,0 +1,131 @@
+package expr
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+type traceKey struct{}
+
+// Trace records the execution of the nodes of the pipelines executed with a context returned by WithTrace, to show
+// where a pipeline loses data or produces values without a value.
+type Trace struct {
+	mtx   sync.Mutex
+	nodes []NodeTrace
+}
+
+// NodeTrace is the execution of a node of a pipeline.
+type NodeTrace struct {
+	RefID    string `json:"refId"`
+	NodeType string `json:"nodeType"`
+	// Command is the type of expression command of the expression nodes.
+	Command string `json:"command,omitempty"`
+	// Inputs are the refIDs the expression nodes use.
+	Inputs []string      `json:"inputs,omitempty"`
+	Frames []*data.Frame `json:"frames,omitempty"`
+	// Dropped are the labels of the values of the inputs that are not in the output, such as the series of a math
+	// expression without a matching series in the other variable.
+	Dropped []data.Labels `json:"dropped,omitempty"`
+	// NoValues is the number of numbers and points of the output that are null or NaN.
+	NoValues int    `json:"noValues,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// WithTrace returns a context that records the execution of the pipelines into the returned trace.
+func WithTrace(ctx context.Context) (context.Context, *Trace) {
+	t := &Trace{}
+	return context.WithValue(ctx, traceKey{}, t), t
+}
+
+func traceFromContext(ctx context.Context) *Trace {
+	t, _ := ctx.Value(traceKey{}).(*Trace)
+	return t
+}
+
+// Nodes returns the executions of the nodes, in the order of execution.
+func (t *Trace) Nodes() []NodeTrace {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	return append([]NodeTrace(nil), t.nodes...)
+}
+
+func (t *Trace) record(node Node, vars mathexp.Vars, res mathexp.Results, err error, duration time.Duration) {
+	nt := NodeTrace{
+		RefID:    node.RefID(),
+		NodeType: node.NodeType().String(),
+		Duration: duration.String(),
+	}
+	if cmdNode, ok := node.(*CMDNode); ok {
+		nt.Command = cmdNode.CMDType.String()
+		nt.Inputs = cmdNode.Command.NeedsVars()
+		if err == nil {
+			nt.Dropped = droppedLabels(nt.Inputs, vars, res)
+		}
+	}
+	if err != nil {
+		nt.Error = err.Error()
+	} else {
+		nt.Frames = res.Values.AsDataFrames(node.RefID())
+		nt.NoValues = countNoValues(res)
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.nodes = append(t.nodes, nt)
+}
+
+// droppedLabels returns the labels of the values of the inputs that no value of the output has, the values of the
+// output have the labels of their inputs or more.
+func droppedLabels(inputs []string, vars mathexp.Vars, res mathexp.Results) []data.Labels {
+	var dropped []data.Labels
+	for _, refID := range inputs {
+		for _, in := range vars[refID].Values {
+			labels := in.GetLabels()
+			found := false
+			for _, out := range res.Values {
+				// A value without labels combines its inputs, such as a classic condition.
+				if len(out.GetLabels()) == 0 || out.GetLabels().Contains(labels) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				dropped = append(dropped, labels)
+			}
+		}
+	}
+	return dropped
+}
+
+func countNoValues(res mathexp.Results) int {
+	noValue := func(v *float64) bool {
+		return v == nil || math.IsNaN(*v)
+	}
+	count := 0
+	for _, val := range res.Values {
+		switch v := val.(type) {
+		case mathexp.Number:
+			if noValue(v.GetFloat64Value()) {
+				count++
+			}
+		case mathexp.Scalar:
+			if noValue(v.GetFloat64Value()) {
+				count++
+			}
+		case mathexp.Series:
+			for i := 0; i < v.Len(); i++ {
+				if noValue(v.GetValue(i)) {
+					count++
+				}
+			}
+		}
+	}
+	return count
+}
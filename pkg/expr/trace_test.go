@@ -0,0 +1,65 @@
+package expr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+func TestTrace(t *testing.T) {
+	number := func(labels data.Labels, value *float64) mathexp.Value {
+		n := mathexp.NewNumber("A", labels)
+		n.SetValue(value)
+		return n
+	}
+	vars := mathexp.Vars{
+		"A": mathexp.Results{Values: []mathexp.Value{
+			number(data.Labels{"host": "a"}, fp(1)),
+			number(data.Labels{"host": "b"}, nil),
+		}},
+		"B": mathexp.Results{Values: []mathexp.Value{
+			number(data.Labels{"host": "a"}, fp(2)),
+		}},
+	}
+	math, err := NewMathCommand("C", "$A + $B")
+	require.NoError(t, err)
+	node := &CMDNode{baseNode: baseNode{refID: "C"}, CMDType: TypeMath, Command: math}
+
+	ctx, trace := WithTrace(context.Background())
+	require.Same(t, trace, traceFromContext(ctx))
+	res, err := node.Execute(ctx, vars, nil)
+	require.NoError(t, err)
+	trace.record(node, vars, res, nil, 0)
+
+	nodes := trace.Nodes()
+	require.Len(t, nodes, 1)
+	require.Equal(t, "C", nodes[0].RefID)
+	require.Equal(t, "math", nodes[0].Command)
+	require.Equal(t, []string{"A", "B"}, nodes[0].Inputs)
+	require.Equal(t, []data.Labels{{"host": "b"}}, nodes[0].Dropped)
+	require.Len(t, nodes[0].Frames, 1)
+	require.Empty(t, nodes[0].Error)
+
+	t.Run("the error of a node is recorded", func(t *testing.T) {
+		reduce := &CMDNode{baseNode: baseNode{refID: "D"}, CMDType: TypeReduce, Command: NewReduceCommand("D", "mean", "A")}
+		_, err := reduce.Execute(ctx, vars, nil)
+		require.Error(t, err)
+		trace.record(reduce, vars, mathexp.Results{}, err, 0)
+		nodes := trace.Nodes()
+		require.Len(t, nodes, 2)
+		require.Equal(t, err.Error(), nodes[1].Error)
+		require.Empty(t, nodes[1].Frames)
+	})
+}
+
+func TestCountNoValues(t *testing.T) {
+	s := mathexp.NewSeries("A", nil, 3)
+	_ = s.SetPoint(0, time.Unix(0, 0), fp(1))
+	n := mathexp.NewNumber("A", nil)
+	require.Equal(t, 3, countNoValues(mathexp.Results{Values: []mathexp.Value{s, n}}))
+}
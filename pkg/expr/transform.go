@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
@@ -16,7 +17,9 @@ import (
 )
 
 var (
-	expressionsQuerySummary *prometheus.SummaryVec
+	expressionsQuerySummary      *prometheus.SummaryVec
+	expressionsResultTruncations *prometheus.CounterVec
+	expressionsResultSpills      prometheus.Counter
 )
 
 func init() {
@@ -29,7 +32,22 @@ func init() {
 		[]string{"status"},
 	)
 
-	prometheus.MustRegister(expressionsQuerySummary)
+	expressionsResultTruncations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "expressions_result_truncations_total",
+			Help: "The number of data source query results truncated by a result limit",
+		},
+		[]string{"limit"},
+	)
+
+	expressionsResultSpills = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "expressions_result_spilled_series_total",
+			Help: "The number of series of data source query results spilled to disk",
+		},
+	)
+
+	prometheus.MustRegister(expressionsQuerySummary, expressionsResultTruncations, expressionsResultSpills)
 }
 
 // WrapTransformData creates and executes transform requests
@@ -65,6 +83,13 @@ type Request struct {
 	Debug   bool
 	OrgId   int64
 	Queries []Query
+	// Limits caps the size of the result of each data source query.
+	Limits ResultLimits
+	// Spill spills the largest series of the result of each data source query to disk.
+	Spill SpillOptions
+	// Fixtures are the frames of the data source queries by RefID, returned instead of querying the data sources
+	// to test the expressions. A query without fixture fails.
+	Fixtures map[string]data.Frames
 }
 
 // Query is like plugins.DataSubQuery, but with a a time range, and only the UID
@@ -77,6 +102,8 @@ type Query struct {
 	Interval      time.Duration
 	QueryType     string
 	MaxDataPoints int64
+	// TimeShift is the duration the time range was moved back by, the times of the results are moved forward by it.
+	TimeShift time.Duration
 }
 
 // TimeRange is a time.Time based TimeRange.
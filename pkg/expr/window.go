@@ -0,0 +1,99 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/gtime"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+)
+
+// MovingWindowCommand is an expression command that replaces each point of the series with the reduction of the
+// points of the window that ends at the point, such as the mean of the last 5 minutes, to smooth noisy series.
+type MovingWindowCommand struct {
+	Window      time.Duration
+	VarToWindow string
+	Reducer     string
+	refID       string
+}
+
+// NewMovingWindowCommand creates a new MovingWindowCommand.
+func NewMovingWindowCommand(refID, rawWindow, varToWindow, reducer string) (*MovingWindowCommand, error) {
+	window, err := gtime.ParseDuration(rawWindow)
+	if err != nil {
+		return nil, fmt.Errorf(`failed to parse moving window "window" duration field %q: %w`, rawWindow, err)
+	}
+	if window <= 0 {
+		return nil, fmt.Errorf("moving window duration must be positive, got %v for refId %v", window, refID)
+	}
+	switch reducer {
+	case "mean", "min", "max", "sum":
+	default:
+		return nil, fmt.Errorf("moving window reducer %q is not one of mean, min, max, sum for refId %v", reducer, refID)
+	}
+	return &MovingWindowCommand{
+		Window:      window,
+		VarToWindow: varToWindow,
+		Reducer:     reducer,
+		refID:       refID,
+	}, nil
+}
+
+// UnmarshalMovingWindowCommand creates a MovingWindowCommand from Grafana's frontend query.
+func UnmarshalMovingWindowCommand(rn *rawNode) (*MovingWindowCommand, error) {
+	rawVar, ok := rn.Query["expression"]
+	if !ok {
+		return nil, fmt.Errorf("no variable specified to apply the moving window to for refId %v", rn.RefID)
+	}
+	varToWindow, ok := rawVar.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected moving window input variable to be a string, got %T for refId %v", rawVar, rn.RefID)
+	}
+	varToWindow = strings.TrimPrefix(varToWindow, "$")
+
+	rawWindow, ok := rn.Query["window"]
+	if !ok {
+		return nil, fmt.Errorf("no time duration specified for the window in moving window command for refId %v", rn.RefID)
+	}
+	window, ok := rawWindow.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected moving window duration to be a string, got %T for refId %v", rawWindow, rn.RefID)
+	}
+
+	rawReducer, ok := rn.Query["reducer"]
+	if !ok {
+		return nil, fmt.Errorf("no reducer specified in moving window command for refId %v", rn.RefID)
+	}
+	reducer, ok := rawReducer.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected moving window reducer to be a string, got %T for refId %v", rawReducer, rn.RefID)
+	}
+
+	return NewMovingWindowCommand(rn.RefID, window, varToWindow, reducer)
+}
+
+// NeedsVars returns the variable names (refIds) that are dependencies
+// to execute the command and allows the command to fulfill the Command interface.
+func (wc *MovingWindowCommand) NeedsVars() []string {
+	return []string{wc.VarToWindow}
+}
+
+// Execute runs the command and returns the results or an error if the command
+// failed to execute.
+func (wc *MovingWindowCommand) Execute(ctx context.Context, vars mathexp.Vars) (mathexp.Results, error) {
+	newRes := mathexp.Results{}
+	for _, val := range vars[wc.VarToWindow].Values {
+		series, ok := val.(mathexp.Series)
+		if !ok {
+			return newRes, fmt.Errorf("can only apply a moving window to type series, got type %v", val.Type())
+		}
+		windowed, err := series.MovingWindow(wc.refID, wc.Window, wc.Reducer)
+		if err != nil {
+			return newRes, err
+		}
+		newRes.Values = append(newRes.Values, windowed)
+	}
+	return newRes, nil
+}
@@ -162,6 +162,41 @@ func TestMiddlewareContext(t *testing.T) {
 		assert.Equal(t, models.ROLE_EDITOR, sc.context.OrgRole)
 	})
 
+	middlewareScenario(t, "Valid API key, but out of its scope", func(t *testing.T, sc *scenarioContext) {
+		keyhash, err := util.EncodePassword("v5nAwpMafFP6znaS4urhdWDLS5511M42", "asd")
+		require.NoError(t, err)
+
+		bus.AddHandler("test", func(query *models.GetApiKeyByNameQuery) error {
+			query.Result = &models.ApiKey{OrgId: 12, Role: models.ROLE_EDITOR, Key: keyhash, Scope: models.ApiKeyScopeAlerting}
+			return nil
+		})
+
+		sc.fakeReq("GET", "/").withValidApiKey().exec()
+
+		assert.Equal(t, 403, sc.resp.Code)
+		assert.Equal(t, "The scope of the API key does not allow this request", sc.respJson["message"])
+	})
+
+	middlewareScenario(t, "Valid API key, in its scope", func(t *testing.T, sc *scenarioContext) {
+		keyhash, err := util.EncodePassword("v5nAwpMafFP6znaS4urhdWDLS5511M42", "asd")
+		require.NoError(t, err)
+
+		bus.AddHandler("test", func(query *models.GetApiKeyByNameQuery) error {
+			query.Result = &models.ApiKey{OrgId: 12, Role: models.ROLE_EDITOR, Key: keyhash,
+				Scope: models.ApiKeyScopeAlerting, ScopeFolders: []string{"ops"}}
+			return nil
+		})
+
+		// the alerting API registers its routes
+		models.RegisterAlertingAPIRoute("GET", "/api/ruler/{Recipient}/api/v1/rules", true)
+		sc.m.Get("/api/ruler/grafana/api/v1/rules", sc.defaultHandler)
+		sc.fakeReq("GET", "/api/ruler/grafana/api/v1/rules").withValidApiKey().exec()
+
+		require.Equal(t, 200, sc.resp.Code)
+		assert.True(t, sc.context.IsSignedIn)
+		assert.Equal(t, []string{"ops"}, sc.context.ApiKeyFolders)
+	})
+
 	middlewareScenario(t, "Valid API key, but does not match DB hash", func(t *testing.T, sc *scenarioContext) {
 		const keyhash = "Something_not_matching"
 
@@ -2,6 +2,10 @@ package models
 
 import (
 	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -10,8 +14,75 @@ var (
 	ErrInvalidApiKey           = errors.New("invalid API key")
 	ErrInvalidApiKeyExpiration = errors.New("negative value for SecondsToLive")
 	ErrDuplicateApiKey         = errors.New("API key, organization ID and name must be unique")
+	ErrInvalidApiKeyScope      = errors.New("invalid API key scope")
 )
 
+// The scopes of the API keys. The keys without scope can use all the API their role has access to.
+const (
+	// ApiKeyScopeAlerting restricts the key to the alerting API.
+	ApiKeyScopeAlerting = "alerting"
+	// ApiKeyScopeAlertingRead restricts the key to the read requests of the alerting API.
+	ApiKeyScopeAlertingRead = "alerting:read"
+)
+
+// alertingAPIRoute is a route of the alerting API, the segments of its path with the parameters in braces.
+type alertingAPIRoute struct {
+	method   string
+	segments []string
+	// rules is true for the routes of the alert rules, which the keys restricted to folders can use.
+	rules bool
+}
+
+// alertingAPIRoutes are the routes of the alerting API by method and path, registered by the alerting API.
+var alertingAPIRoutes = struct {
+	sync.RWMutex
+	routes map[string]alertingAPIRoute
+}{routes: make(map[string]alertingAPIRoute)}
+
+// RegisterAlertingAPIRoute registers a route of the alerting API, whose path has its parameters in braces, such as
+// /api/ruler/{Recipient}/api/v1/rules. The routes of the alert rules can be used by the keys restricted to folders.
+func RegisterAlertingAPIRoute(method, path string, rules bool) {
+	alertingAPIRoutes.Lock()
+	defer alertingAPIRoutes.Unlock()
+	alertingAPIRoutes.routes[method+path] = alertingAPIRoute{method: method, segments: strings.Split(path, "/"), rules: rules}
+}
+
+// isAlertingAPIRoute returns true if the request with the method and path is a request of a registered route of the
+// alerting API, of a route of the alert rules if rules is true.
+func isAlertingAPIRoute(method, path string, rules bool) bool {
+	if method == http.MethodHead {
+		method = http.MethodGet
+	}
+	segments := strings.Split(strings.TrimSuffix(path, "/"), "/")
+	alertingAPIRoutes.RLock()
+	defer alertingAPIRoutes.RUnlock()
+	for _, route := range alertingAPIRoutes.routes {
+		if route.method == method && (route.rules || !rules) && matchRoutePath(route.segments, segments) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchRoutePath returns true if the segments of a path are the ones of a route, whose parameters match any segment.
+func matchRoutePath(route, segments []string) bool {
+	if len(route) != len(segments) {
+		return false
+	}
+	for i, s := range route {
+		if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+			if segments[i] == "" {
+				return false
+			}
+			continue
+		}
+		if s != segments[i] {
+			return false
+		}
+	}
+	return true
+}
+
 type ApiKey struct {
 	Id      int64
 	OrgId   int64
@@ -21,6 +92,44 @@ type ApiKey struct {
 	Created time.Time
 	Updated time.Time
 	Expires *int64
+	// Scope restricts the API the key can use, it is empty if the key is not restricted.
+	Scope string
+	// ScopeFolders restricts the alert rules the key can access to the ones of the folders with these UIDs.
+	ScopeFolders []string
+}
+
+// ValidateApiKeyScope fails if the scope is unknown, or if the key is restricted to folders without being restricted
+// to the alerting API.
+func ValidateApiKeyScope(scope string, folders []string) error {
+	switch scope {
+	case "":
+		if len(folders) > 0 {
+			return fmt.Errorf("%w: only the keys with an alerting scope can be restricted to folders", ErrInvalidApiKeyScope)
+		}
+	case ApiKeyScopeAlerting, ApiKeyScopeAlertingRead:
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidApiKeyScope, scope)
+	}
+	return nil
+}
+
+// ApiKeyScopeAllows returns true if a key with the scope, restricted to the folders if any, can be used for the
+// request with the method and path.
+func ApiKeyScopeAllows(scope string, folders []string, method, path string) bool {
+	if scope == "" {
+		return true
+	}
+	if !isAlertingAPIRoute(method, path, len(folders) > 0) {
+		return false
+	}
+	switch scope {
+	case ApiKeyScopeAlerting:
+		return true
+	case ApiKeyScopeAlertingRead:
+		return method == http.MethodGet || method == http.MethodHead
+	default:
+		return false
+	}
 }
 
 // ---------------------
@@ -31,6 +140,8 @@ type AddApiKeyCommand struct {
 	OrgId         int64    `json:"-"`
 	Key           string   `json:"-"`
 	SecondsToLive int64    `json:"secondsToLive"`
+	Scope         string   `json:"scope"`
+	ScopeFolders  []string `json:"scopeFolders"`
 
 	Result *ApiKey `json:"-"`
 }
@@ -64,8 +175,10 @@ type GetApiKeyByIdQuery struct {
 // DTO & Projections
 
 type ApiKeyDTO struct {
-	Id         int64      `json:"id"`
-	Name       string     `json:"name"`
-	Role       RoleType   `json:"role"`
-	Expiration *time.Time `json:"expiration,omitempty"`
+	Id           int64      `json:"id"`
+	Name         string     `json:"name"`
+	Role         RoleType   `json:"role"`
+	Expiration   *time.Time `json:"expiration,omitempty"`
+	Scope        string     `json:"scope,omitempty"`
+	ScopeFolders []string   `json:"scopeFolders,omitempty"`
 }
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateApiKeyScope(t *testing.T) {
+	require.NoError(t, ValidateApiKeyScope("", nil))
+	require.NoError(t, ValidateApiKeyScope(ApiKeyScopeAlerting, nil))
+	require.NoError(t, ValidateApiKeyScope(ApiKeyScopeAlertingRead, []string{"ops"}))
+	require.ErrorIs(t, ValidateApiKeyScope("dashboards", nil), ErrInvalidApiKeyScope)
+	require.ErrorIs(t, ValidateApiKeyScope("", []string{"ops"}), ErrInvalidApiKeyScope)
+}
+
+func TestApiKeyScopeAllows(t *testing.T) {
+	RegisterAlertingAPIRoute(http.MethodGet, "/api/ruler/{Recipient}/api/v1/rules", true)
+	RegisterAlertingAPIRoute(http.MethodPost, "/api/ruler/{Recipient}/api/v1/rules/{Namespace}", true)
+	RegisterAlertingAPIRoute(http.MethodGet, "/api/alertmanager/{Recipient}/api/v2/silences", false)
+	RegisterAlertingAPIRoute(http.MethodPost, "/api/alertmanager/{Recipient}/api/v2/silences", false)
+	RegisterAlertingAPIRoute(http.MethodPost, "/api/alertmanager/{Recipient}/config/api/v1/alerts", false)
+	RegisterAlertingAPIRoute(http.MethodGet, "/api/v1/rules/insights", true)
+	RegisterAlertingAPIRoute(http.MethodGet, "/api/v1/alerts", false)
+	RegisterAlertingAPIRoute(http.MethodPost, "/api/v1/rule/test", true)
+	RegisterAlertingAPIRoute(http.MethodPost, "/api/v1/rule/unit-test", true)
+	RegisterAlertingAPIRoute(http.MethodPost, "/api/v1/rule/validate-templates", true)
+	RegisterAlertingAPIRoute(http.MethodGet, "/api/v1/incidents", false)
+
+	tc := map[string]struct {
+		scope    string
+		folders  []string
+		method   string
+		path     string
+		expected bool
+	}{
+		"the keys without scope can use all the API": {
+			method: http.MethodPost, path: "/api/dashboards/db", expected: true,
+		},
+		"the alerting keys can use the alerting API": {
+			scope: ApiKeyScopeAlerting, method: http.MethodPost, path: "/api/ruler/grafana/api/v1/rules/ops", expected: true,
+		},
+		"the alerting keys cannot use the other API": {
+			scope: ApiKeyScopeAlerting, method: http.MethodGet, path: "/api/dashboards/uid/abc", expected: false,
+		},
+		"the read-only alerting keys can read the alerting API": {
+			scope: ApiKeyScopeAlertingRead, method: http.MethodGet, path: "/api/alertmanager/grafana/api/v2/silences", expected: true,
+		},
+		"the read-only alerting keys cannot write the alerting API": {
+			scope: ApiKeyScopeAlertingRead, method: http.MethodPost, path: "/api/alertmanager/grafana/api/v2/silences", expected: false,
+		},
+		"the alerting keys with folders can use the rules API": {
+			scope: ApiKeyScopeAlerting, folders: []string{"ops"}, method: http.MethodPost, path: "/api/ruler/grafana/api/v1/rules/ops", expected: true,
+		},
+		"the alerting keys with folders cannot use the other alerting API": {
+			scope: ApiKeyScopeAlerting, folders: []string{"ops"}, method: http.MethodPost, path: "/api/alertmanager/grafana/config/api/v1/alerts", expected: false,
+		},
+		"the read-only alerting keys can read the rule insights": {
+			scope: ApiKeyScopeAlertingRead, method: http.MethodGet, path: "/api/v1/rules/insights", expected: true,
+		},
+		"the read-only alerting keys can read the alerts": {
+			scope: ApiKeyScopeAlertingRead, method: http.MethodGet, path: "/api/v1/alerts", expected: true,
+		},
+		"the alerting keys can test rules": {
+			scope: ApiKeyScopeAlerting, method: http.MethodPost, path: "/api/v1/rule/test", expected: true,
+		},
+		"the alerting keys with folders can test rules": {
+			scope: ApiKeyScopeAlerting, folders: []string{"ops"}, method: http.MethodPost, path: "/api/v1/rule/test", expected: true,
+		},
+		"the alerting keys can run the unit tests of rules": {
+			scope: ApiKeyScopeAlerting, method: http.MethodPost, path: "/api/v1/rule/unit-test", expected: true,
+		},
+		"the alerting keys can validate the templates of rules": {
+			scope: ApiKeyScopeAlerting, method: http.MethodPost, path: "/api/v1/rule/validate-templates", expected: true,
+		},
+		"the read-only alerting keys can read the incidents": {
+			scope: ApiKeyScopeAlertingRead, method: http.MethodGet, path: "/api/v1/incidents", expected: true,
+		},
+		"the read-only alerting keys can read with HEAD requests": {
+			scope: ApiKeyScopeAlertingRead, method: http.MethodHead, path: "/api/v1/alerts", expected: true,
+		},
+		"the alerting keys can use the routes with a trailing slash": {
+			scope: ApiKeyScopeAlerting, method: http.MethodGet, path: "/api/ruler/grafana/api/v1/rules/", expected: true,
+		},
+		"the alerting keys cannot use the paths starting like a route": {
+			scope: ApiKeyScopeAlerting, method: http.MethodGet, path: "/api/v1/alertsettings", expected: false,
+		},
+		"the alerting keys cannot use the paths under a route": {
+			scope: ApiKeyScopeAlerting, method: http.MethodGet, path: "/api/v1/alerts/settings", expected: false,
+		},
+		"the alerting keys cannot use a route with another method": {
+			scope: ApiKeyScopeAlerting, method: http.MethodDelete, path: "/api/v1/incidents", expected: false,
+		},
+		"the alerting keys cannot use a route with an empty parameter": {
+			scope: ApiKeyScopeAlerting, method: http.MethodPost, path: "/api/ruler/grafana/api/v1/rules//", expected: false,
+		},
+		"the keys with an unknown scope cannot use the API": {
+			scope: "dashboards", method: http.MethodGet, path: "/api/ruler/grafana/api/v1/rules", expected: false,
+		},
+	}
+
+	for name, tt := range tc {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tt.expected, ApiKeyScopeAllows(tt.scope, tt.folders, tt.method, tt.path))
+		})
+	}
+}
@@ -18,6 +18,7 @@ const (
 	DS_ACCESS_DIRECT  = "direct"
 	DS_ACCESS_PROXY   = "proxy"
 	DS_ES_OPEN_DISTRO = "grafana-es-open-distro-datasource"
+	DS_ALERTMANAGER   = "alertmanager"
 )
 
 var (
@@ -37,6 +37,10 @@ type SendWebhookSync struct {
 	HttpMethod  string
 	HttpHeader  map[string]string
 	ContentType string
+	// ProxyURL is the HTTP proxy the webhook is sent through, the proxy of the environment is used if empty.
+	ProxyURL string
+	// ResponseBody, if not nil, is called with the body of a successful response, up to 64KB.
+	ResponseBody func(body []byte)
 }
 
 type SendResetPasswordEmailCommand struct {
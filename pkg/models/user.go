@@ -179,6 +179,7 @@ type SignedInUser struct {
 	Name           string
 	Email          string
 	ApiKeyId       int64
+	ApiKeyFolders  []string
 	OrgCount       int
 	IsGrafanaAdmin bool
 	IsAnonymous    bool
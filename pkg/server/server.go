@@ -194,6 +194,12 @@ func (s *Server) Shutdown(ctx context.Context, reason string) error {
 	return err
 }
 
+// ReloadProvisioning provisions again the resources that are reloaded on SIGHUP.
+func (s *Server) ReloadProvisioning() error {
+	s.log.Info("Reloading alerting provisioning")
+	return s.provisioningService.ProvisionAlerting()
+}
+
 // ExitCode returns an exit code for a given error.
 func (s *Server) ExitCode(runError error) int {
 	if runError != nil {
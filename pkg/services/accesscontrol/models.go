@@ -90,6 +90,29 @@ const (
 	// Plugin actions
 	ActionPluginsManage = "plugins:manage"
 
+	// Alerting rules actions, scoped by folder
+	ActionAlertingRuleRead  = "alert.rules:read"
+	ActionAlertingRuleWrite = "alert.rules:write"
+
+	// Alerting instances and silences actions
+	ActionAlertingInstanceRead   = "alert.instances:read"
+	ActionAlertingSilencesCreate = "alert.silences:create"
+
+	// Alerting notifications actions
+	ActionAlertingNotificationsRead  = "alert.notifications:read"
+	ActionAlertingNotificationsWrite = "alert.notifications:write"
+
+	// Alerting notification policies actions, for the notification policies owned by the teams of the user
+	ActionAlertingTeamPoliciesWrite = "alert.notifications.team-policies:write"
+
+	// Alerting admin configuration actions
+	ActionAlertingAdminConfigRead  = "alert.admin-config:read"
+	ActionAlertingAdminConfigWrite = "alert.admin-config:write"
+
+	// Alerting provisioning actions
+	ActionAlertingProvisioningRead  = "alert.provisioning:read"
+	ActionAlertingProvisioningWrite = "alert.provisioning:write"
+
 	// Global Scopes
 	ScopeGlobalUsersAll = "global:users:*"
 
@@ -98,6 +121,9 @@ const (
 
 	// Settings scope
 	ScopeSettingsAll = "settings:*"
+
+	// Folders scope
+	ScopeFoldersAll = "folders:*"
 )
 
 const RoleGrafanaAdmin = "Grafana Admin"
@@ -18,6 +18,12 @@ func Scope(parts ...string) string {
 	return b.String()
 }
 
+// ScopeFolder returns the scope of the folder with the UID
+// e.g. ScopeFolder("abc") return "folders:uid:abc"
+func ScopeFolder(uid string) string {
+	return Scope("folders", "uid", uid)
+}
+
 // Parameter returns injectable scope part
 // e.g. Scope("users", Parameter(":id")) or "users:" + Parameter(":id")
 func Parameter(key string) string {
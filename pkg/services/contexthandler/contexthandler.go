@@ -219,10 +219,21 @@ func (h *ContextHandler) initContextWithAPIKey(reqContext *models.ReqContext) bo
 		return true
 	}
 
+	// check for scope
+	path := reqContext.Req.URL.Path
+	if h.Cfg.ServeFromSubPath {
+		path = strings.TrimPrefix(path, h.Cfg.AppSubURL)
+	}
+	if !models.ApiKeyScopeAllows(apikey.Scope, apikey.ScopeFolders, reqContext.Req.Method, path) {
+		reqContext.JsonApiErr(403, "The scope of the API key does not allow this request", nil)
+		return true
+	}
+
 	reqContext.IsSignedIn = true
 	reqContext.SignedInUser = &models.SignedInUser{}
 	reqContext.OrgRole = apikey.Role
 	reqContext.ApiKeyId = apikey.Id
+	reqContext.ApiKeyFolders = apikey.ScopeFolders
 	reqContext.OrgId = apikey.OrgId
 	return true
 }
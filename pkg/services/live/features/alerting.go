@@ -0,0 +1,76 @@
+package features
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+const (
+	AlertingStateChannel         = "grafana/alerting/state"
+	AlertingNotificationsChannel = "grafana/alerting/notifications"
+)
+
+// AlertingHandler manages the `grafana/alerting/*` channels, which stream the changes of the state of the alerts and
+// the notifications sent by unified alerting.
+type AlertingHandler struct {
+	Publisher models.ChannelPublisher
+	// LocalPublisher publishes to the clients of this instance only. The rules are evaluated by all the instances of
+	// a HA cluster, each instance publishes the changes of the state to its own clients.
+	LocalPublisher models.ChannelPublisher
+}
+
+// GetHandlerForPath called on init
+func (h *AlertingHandler) GetHandlerForPath(_ string) (models.ChannelHandler, error) {
+	return h, nil
+}
+
+// OnSubscribe allows the users of the organization to subscribe to the state of its alerts and its notifications,
+// as they can read the alerts from the API.
+func (h *AlertingHandler) OnSubscribe(_ context.Context, user *models.SignedInUser, e models.SubscribeEvent) (models.SubscribeReply, backend.SubscribeStreamStatus, error) {
+	switch e.Path {
+	case "state", "notifications":
+		if !user.HasRole(models.ROLE_VIEWER) {
+			return models.SubscribeReply{}, backend.SubscribeStreamStatusPermissionDenied, nil
+		}
+		return models.SubscribeReply{}, backend.SubscribeStreamStatusOK, nil
+	}
+
+	logger.Error("Unknown alerting channel", "path", e.Path)
+	return models.SubscribeReply{}, backend.SubscribeStreamStatusNotFound, nil
+}
+
+// OnPublish denies the publications of the clients, only Grafana publishes to the alerting channels.
+func (h *AlertingHandler) OnPublish(_ context.Context, _ *models.SignedInUser, _ models.PublishEvent) (models.PublishReply, backend.PublishStreamStatus, error) {
+	return models.PublishReply{}, backend.PublishStreamStatusPermissionDenied, nil
+}
+
+// HandleAlertStateChanged publishes the change of the state of an alert to the clients of this instance.
+func (h *AlertingHandler) HandleAlertStateChanged(e *events.AlertStateChanged) error {
+	h.publish(h.LocalPublisher, e.OrgID, AlertingStateChannel, e)
+	return nil
+}
+
+// HandleAlertNotificationSent publishes a notification to the clients of all the instances, the notifications are
+// sent by one instance of a HA cluster.
+func (h *AlertingHandler) HandleAlertNotificationSent(e *events.AlertNotificationSent) error {
+	h.publish(h.Publisher, e.OrgID, AlertingNotificationsChannel, e)
+	return nil
+}
+
+// publish publishes the event to the channel, the errors are logged for the other listeners of the event to be
+// called.
+func (h *AlertingHandler) publish(publisher models.ChannelPublisher, orgID int64, channel string, e interface{}) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		logger.Error("Error marshaling alerting event", "channel", channel, "error", err)
+		return
+	}
+	if err := publisher(orgID, channel, data); err != nil {
+		logger.Error("Error publishing alerting event", "channel", channel, "error", err)
+	}
+}
@@ -0,0 +1,75 @@
+package features
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+type publication struct {
+	orgID   int64
+	channel string
+	data    []byte
+}
+
+func TestAlertingHandler_OnSubscribe(t *testing.T) {
+	h := &AlertingHandler{}
+	handler, err := h.GetHandlerForPath("state")
+	require.NoError(t, err)
+
+	tests := []struct {
+		path   string
+		role   models.RoleType
+		status backend.SubscribeStreamStatus
+	}{
+		{path: "state", role: models.ROLE_VIEWER, status: backend.SubscribeStreamStatusOK},
+		{path: "notifications", role: models.ROLE_EDITOR, status: backend.SubscribeStreamStatusOK},
+		{path: "state", role: "", status: backend.SubscribeStreamStatusPermissionDenied},
+		{path: "rules", role: models.ROLE_ADMIN, status: backend.SubscribeStreamStatusNotFound},
+	}
+	for _, tt := range tests {
+		_, status, err := handler.OnSubscribe(
+			context.Background(),
+			&models.SignedInUser{OrgId: 1, UserId: 2, OrgRole: tt.role},
+			models.SubscribeEvent{Channel: "grafana/alerting/" + tt.path, Path: tt.path},
+		)
+		require.NoError(t, err)
+		require.Equal(t, tt.status, status, "path %s, role %s", tt.path, tt.role)
+	}
+
+	_, status, err := handler.OnPublish(context.Background(), &models.SignedInUser{OrgId: 1, OrgRole: models.ROLE_ADMIN}, models.PublishEvent{Path: "state"})
+	require.NoError(t, err)
+	require.Equal(t, backend.PublishStreamStatusPermissionDenied, status)
+}
+
+func TestAlertingHandler_Publish(t *testing.T) {
+	var local, broadcast []publication
+	h := &AlertingHandler{
+		Publisher: func(orgID int64, channel string, data []byte) error {
+			broadcast = append(broadcast, publication{orgID, channel, data})
+			return nil
+		},
+		LocalPublisher: func(orgID int64, channel string, data []byte) error {
+			local = append(local, publication{orgID, channel, data})
+			return nil
+		},
+	}
+
+	require.NoError(t, h.HandleAlertStateChanged(&events.AlertStateChanged{OrgID: 2, RuleUID: "rule", State: "Alerting"}))
+	require.Len(t, local, 1)
+	require.Empty(t, broadcast)
+	require.Equal(t, int64(2), local[0].orgID)
+	require.Equal(t, AlertingStateChannel, local[0].channel)
+	var state events.AlertStateChanged
+	require.NoError(t, json.Unmarshal(local[0].data, &state))
+	require.Equal(t, "rule", state.RuleUID)
+
+	require.NoError(t, h.HandleAlertNotificationSent(&events.AlertNotificationSent{OrgID: 2, Receiver: "ops"}))
+	require.Len(t, broadcast, 1)
+	require.Equal(t, AlertingNotificationsChannel, broadcast[0].channel)
+}
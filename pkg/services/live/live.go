@@ -15,6 +15,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/localcache"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/middleware"
@@ -163,6 +164,15 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	g.GrafanaScope.Dashboards = dash
 	g.GrafanaScope.Features["dashboard"] = dash
 	g.GrafanaScope.Features["broadcast"] = features.NewBroadcastRunner(g.storage)
+	alerting := &features.AlertingHandler{
+		Publisher: g.Publish,
+		LocalPublisher: func(orgID int64, channel string, data []byte) error {
+			return channelLocalPublisher.PublishLocal(orgchannel.PrependOrgID(orgID, channel), data)
+		},
+	}
+	g.GrafanaScope.Features["alerting"] = alerting
+	bus.AddEventListener(alerting.HandleAlertStateChanged)
+	bus.AddEventListener(alerting.HandleAlertNotificationSent)
 
 	var managedStreamRunner *managedstream.Runner
 	if g.IsHA() {
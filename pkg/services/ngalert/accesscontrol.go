@@ -0,0 +1,144 @@
+package ngalert
+
+import (
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+const (
+	rulesReader         = "fixed:alerting:rules:read"
+	rulesWriter         = "fixed:alerting:rules:edit"
+	instancesReader     = "fixed:alerting:instances:read"
+	silencesWriter      = "fixed:alerting:silences:edit"
+	notificationsReader = "fixed:alerting:notifications:read"
+	notificationsWriter = "fixed:alerting:notifications:edit"
+	teamPoliciesWriter  = "fixed:alerting:notifications.team-policies:edit"
+	adminConfigWriter   = "fixed:alerting:admin:edit"
+	provisioningWriter  = "fixed:alerting:provisioning:edit"
+)
+
+var (
+	rulesReaderRole = accesscontrol.RoleDTO{
+		Version:     1,
+		Name:        rulesReader,
+		Description: "Read the alert rules of all the folders",
+		Permissions: []accesscontrol.Permission{
+			{
+				Action: accesscontrol.ActionAlertingRuleRead,
+				Scope:  accesscontrol.ScopeFoldersAll,
+			},
+		},
+	}
+
+	rulesWriterRole = accesscontrol.RoleDTO{
+		Version:     1,
+		Name:        rulesWriter,
+		Description: "Read, create, update and delete the alert rules of all the folders",
+		Permissions: accesscontrol.ConcatPermissions(rulesReaderRole.Permissions, []accesscontrol.Permission{
+			{
+				Action: accesscontrol.ActionAlertingRuleWrite,
+				Scope:  accesscontrol.ScopeFoldersAll,
+			},
+		}),
+	}
+
+	instancesReaderRole = accesscontrol.RoleDTO{
+		Version:     1,
+		Name:        instancesReader,
+		Description: "Read the alerts and the silences",
+		Permissions: []accesscontrol.Permission{
+			{
+				Action: accesscontrol.ActionAlertingInstanceRead,
+			},
+		},
+	}
+
+	silencesWriterRole = accesscontrol.RoleDTO{
+		Version:     1,
+		Name:        silencesWriter,
+		Description: "Read the alerts and the silences, create and expire silences and acknowledge escalations",
+		Permissions: accesscontrol.ConcatPermissions(instancesReaderRole.Permissions, []accesscontrol.Permission{
+			{
+				Action: accesscontrol.ActionAlertingSilencesCreate,
+			},
+		}),
+	}
+
+	notificationsReaderRole = accesscontrol.RoleDTO{
+		Version:     1,
+		Name:        notificationsReader,
+		Description: "Read the Alertmanager configuration: contact points, notification policies and templates",
+		Permissions: []accesscontrol.Permission{
+			{
+				Action: accesscontrol.ActionAlertingNotificationsRead,
+			},
+		},
+	}
+
+	notificationsWriterRole = accesscontrol.RoleDTO{
+		Version:     1,
+		Name:        notificationsWriter,
+		Description: "Read, update and test the Alertmanager configuration, and send alerts to the Alertmanager",
+		Permissions: accesscontrol.ConcatPermissions(notificationsReaderRole.Permissions, []accesscontrol.Permission{
+			{
+				Action: accesscontrol.ActionAlertingNotificationsWrite,
+			},
+		}),
+	}
+
+	teamPoliciesWriterRole = accesscontrol.RoleDTO{
+		Version:     1,
+		Name:        teamPoliciesWriter,
+		Description: "Read the Alertmanager configuration and update the notification policies owned by the teams of the user",
+		Permissions: accesscontrol.ConcatPermissions(notificationsReaderRole.Permissions, []accesscontrol.Permission{
+			{
+				Action: accesscontrol.ActionAlertingTeamPoliciesWrite,
+			},
+		}),
+	}
+
+	adminConfigWriterRole = accesscontrol.RoleDTO{
+		Version:     1,
+		Name:        adminConfigWriter,
+		Description: "Read and update the alerting configuration of the organization: external Alertmanagers and maintenance mode",
+		Permissions: []accesscontrol.Permission{
+			{
+				Action: accesscontrol.ActionAlertingAdminConfigRead,
+			},
+			{
+				Action: accesscontrol.ActionAlertingAdminConfigWrite,
+			},
+		},
+	}
+
+	provisioningWriterRole = accesscontrol.RoleDTO{
+		Version:     1,
+		Name:        provisioningWriter,
+		Description: "Read and update the alerting resources with the provisioning API",
+		Permissions: []accesscontrol.Permission{
+			{
+				Action: accesscontrol.ActionAlertingProvisioningRead,
+			},
+			{
+				Action: accesscontrol.ActionAlertingProvisioningWrite,
+			},
+		},
+	}
+)
+
+// declareFixedRoles declares the fixed roles of alerting, with the same access as the roles of the organization
+// had before the alerting permissions. The role editing the notification policies of the teams is granted by the
+// administrators, the editors can already edit all of them.
+func declareFixedRoles(ac accesscontrol.AccessControl) error {
+	return ac.DeclareFixedRoles(
+		accesscontrol.RoleRegistration{Role: rulesReaderRole, Grants: []string{string(models.ROLE_VIEWER)}},
+		accesscontrol.RoleRegistration{Role: rulesWriterRole, Grants: []string{string(models.ROLE_EDITOR)}},
+		accesscontrol.RoleRegistration{Role: instancesReaderRole, Grants: []string{string(models.ROLE_VIEWER)}},
+		accesscontrol.RoleRegistration{Role: silencesWriterRole, Grants: []string{string(models.ROLE_EDITOR)}},
+		accesscontrol.RoleRegistration{Role: notificationsReaderRole, Grants: []string{string(models.ROLE_EDITOR)}},
+		accesscontrol.RoleRegistration{Role: notificationsWriterRole, Grants: []string{string(models.ROLE_EDITOR)}},
+		accesscontrol.RoleRegistration{Role: teamPoliciesWriterRole},
+		accesscontrol.RoleRegistration{Role: adminConfigWriterRole, Grants: []string{string(models.ROLE_ADMIN)}},
+		accesscontrol.RoleRegistration{Role: provisioningWriterRole, Grants: []string{string(models.ROLE_ADMIN)}},
+	)
+}
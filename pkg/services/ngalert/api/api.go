@@ -7,12 +7,17 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/datasourceproxy"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
+	"github.com/grafana/grafana/pkg/services/ngalert/sender"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/quota"
@@ -23,9 +28,17 @@ import (
 // timeNow makes it possible to test usage of time
 var timeNow = time.Now
 
+// GitSync provisions the alerting resources from a Git repository.
+type GitSync interface {
+	Status() apimodels.GitSyncStatus
+	Sync(ctx context.Context, force bool) apimodels.GitSyncStatus
+}
+
 type Scheduler interface {
 	AlertmanagersFor(orgID int64) []*url.URL
 	DroppedAlertmanagersFor(orgID int64) []*url.URL
+	AlertmanagersHealthFor(orgID int64) []sender.TargetHealth
+	EvaluationLagFor(orgID int64) time.Duration
 }
 
 type Alertmanager interface {
@@ -39,11 +52,22 @@ type Alertmanager interface {
 	DeleteSilence(silenceID string) error
 	GetSilence(silenceID string) (apimodels.GettableSilence, error)
 	ListSilences(filter []string) (apimodels.GettableSilences, error)
+	ExpireRecurringSilence(rs *ngmodels.RecurringSilence) error
 
 	// Alerts
+	ApplyLabelPolicies(alerts apimodels.PostableAlerts)
+	PutAlerts(alerts apimodels.PostableAlerts) error
 	GetAlerts(active, silenced, inhibited bool, filter []string, receiver string) (apimodels.GettableAlerts, error)
 	GetAlertGroups(active, silenced, inhibited bool, filter []string, receiver string) (apimodels.AlertGroups, error)
 
+	// Escalations
+	GetEscalations() []notifier.Escalation
+	NextEscalationStep(e notifier.Escalation) (apimodels.EscalationStep, time.Time, bool)
+	AcknowledgeEscalation(id, user string) error
+
+	// Notification log
+	GetNotificationLog(filter []string, receivers string) (apimodels.GettableNotificationLog, error)
+
 	// Testing
 	TestReceivers(ctx context.Context, c apimodels.TestReceiversConfigParams) (*notifier.TestReceiversResult, error)
 }
@@ -60,36 +84,134 @@ type API struct {
 	InstanceStore        store.InstanceStore
 	AlertingStore        store.AlertingStore
 	AdminConfigStore     store.AdminConfigurationStore
+	ProvenanceStore      store.ProvisioningStore
+	HeartbeatStore       store.HeartbeatStore
+	RuleTemplateStore    store.RuleTemplateStore
+	FolderDefaultsStore  store.FolderDefaultsStore
 	DataProxy            *datasourceproxy.DataSourceProxyService
 	MultiOrgAlertmanager *notifier.MultiOrgAlertmanager
 	StateManager         *state.Manager
+	RuleInsights         RuleInsights
+	AccessControl        accesscontrol.AccessControl
+	// GitSync is nil if the Git sync is not configured.
+	GitSync GitSync
+	// StateAnnotationStore is nil unless the annotations of the alerts are written in the dedicated table.
+	StateAnnotationStore store.StateAnnotationStore
+	// DatasourceMaintenanceStore stores the maintenance windows of the datasources.
+	DatasourceMaintenanceStore store.DatasourceMaintenanceStore
+
+	AlertRuleService          *provisioning.AlertRuleService
+	AlertmanagerConfigService *provisioning.AlertmanagerConfigService
+	MuteTimingService         *provisioning.MuteTimingService
 }
 
 // RegisterAPIEndpoints registers API handlers
 func (api *API) RegisterAPIEndpoints(m *metrics.Metrics) {
+	// the requests load the alert rules of their organization, when the organizations are loaded lazily
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		scoped := *api
+		scoped.RouteRegister = group
+		scoped.registerAPIEndpoints(m)
+	}, api.activateOrg)
+}
+
+// activateOrg is the middleware recording the requests of the organizations for the scheduler.
+func (api *API) activateOrg(c *models.ReqContext) {
+	if api.Schedule == nil || c.SignedInUser == nil || c.SignedInUser.OrgId <= 0 {
+		return
+	}
+	api.Schedule.ActivateOrg(c.SignedInUser.OrgId)
+}
+
+func (api *API) registerAPIEndpoints(m *metrics.Metrics) {
 	logger := log.New("ngalert.api")
 	proxy := &AlertingProxy{
 		DataProxy: api.DataProxy,
 	}
+	ruleStore := api.ruleStore(logger)
 
 	// Register endpoints for proxying to Alertmanager-compatible backends.
 	api.RegisterAlertmanagerApiEndpoints(NewForkedAM(
 		api.DatasourceCache,
 		NewLotexAM(proxy, logger),
-		AlertmanagerSrv{store: api.AlertingStore, mam: api.MultiOrgAlertmanager, log: logger},
+		AlertmanagerSrv{store: api.AlertingStore, provenanceStore: api.ProvenanceStore, mam: api.MultiOrgAlertmanager, QuotaService: api.QuotaService, ac: api.AccessControl, log: logger},
 	), m)
 	// Register endpoints for proxying to Prometheus-compatible backends.
 	api.RegisterPrometheusApiEndpoints(NewForkedProm(
 		api.DatasourceCache,
 		NewLotexProm(proxy, logger),
-		PrometheusSrv{log: logger, manager: api.StateManager, store: api.RuleStore},
+		PrometheusSrv{log: logger, manager: api.StateManager, store: ruleStore},
 	), m)
+	api.RegisterPrometheusCompatApiEndpoints(PrometheusSrv{log: logger, manager: api.StateManager, store: ruleStore}, m)
 	// Register endpoints for proxying to Cortex Ruler-compatible backends.
 	api.RegisterRulerApiEndpoints(NewForkedRuler(
 		api.DatasourceCache,
 		NewLotexRuler(proxy, logger),
-		RulerSrv{DatasourceCache: api.DatasourceCache, QuotaService: api.QuotaService, manager: api.StateManager, store: api.RuleStore, log: logger},
+		RulerSrv{DatasourceCache: api.DatasourceCache, QuotaService: api.QuotaService, manager: api.StateManager, store: ruleStore, provenanceStore: api.ProvenanceStore, log: logger},
 	), m)
+	// Register endpoints for managing Grafana rules as Prometheus alerting rules, with the Cortex ruler API.
+	api.RegisterPrometheusRulerApiEndpoints(
+		RulerSrv{DatasourceCache: api.DatasourceCache, QuotaService: api.QuotaService, manager: api.StateManager, store: ruleStore, provenanceStore: api.ProvenanceStore, log: logger},
+		m,
+	)
+	api.RegisterRuleVersionsApiEndpoints(
+		RulerSrv{DatasourceCache: api.DatasourceCache, QuotaService: api.QuotaService, manager: api.StateManager, store: ruleStore, provenanceStore: api.ProvenanceStore, log: logger},
+		m,
+	)
+	api.RegisterRuleGroupsApiEndpoints(
+		RulerSrv{DatasourceCache: api.DatasourceCache, QuotaService: api.QuotaService, manager: api.StateManager, store: ruleStore, provenanceStore: api.ProvenanceStore, log: logger},
+		m,
+	)
+	api.RegisterRuleMoveApiEndpoints(
+		RulerSrv{DatasourceCache: api.DatasourceCache, QuotaService: api.QuotaService, manager: api.StateManager, store: ruleStore, provenanceStore: api.ProvenanceStore, log: logger},
+		m,
+	)
+	api.RegisterRuleBulkApiEndpoints(
+		RulerSrv{DatasourceCache: api.DatasourceCache, QuotaService: api.QuotaService, manager: api.StateManager, store: ruleStore, provenanceStore: api.ProvenanceStore, log: logger},
+		m,
+	)
+	api.RegisterRuleCloneApiEndpoints(
+		RulerSrv{DatasourceCache: api.DatasourceCache, QuotaService: api.QuotaService, manager: api.StateManager, store: ruleStore, provenanceStore: api.ProvenanceStore, log: logger},
+		m,
+	)
+	api.RegisterRuleSearchApiEndpoints(
+		RulerSrv{DatasourceCache: api.DatasourceCache, QuotaService: api.QuotaService, manager: api.StateManager, store: ruleStore, provenanceStore: api.ProvenanceStore, log: logger},
+		m,
+	)
+	api.RegisterSloApiEndpoints(
+		RulerSrv{DatasourceCache: api.DatasourceCache, QuotaService: api.QuotaService, manager: api.StateManager, store: ruleStore, provenanceStore: api.ProvenanceStore, log: logger},
+		m,
+	)
+	api.RegisterRuleShadowApiEndpoints(RulerSrv{
+		DatasourceCache:  api.DatasourceCache,
+		QuotaService:     api.QuotaService,
+		manager:          api.StateManager,
+		store:            ruleStore,
+		provenanceStore:  api.ProvenanceStore,
+		log:              logger,
+		stateAnnotations: api.StateAnnotationStore,
+	}, m)
+	api.RegisterFolderDefaultsApiEndpoints(FolderDefaultsSrv{store: ruleStore, defaults: api.FolderDefaultsStore, log: logger}, m)
+	api.RegisterRuleTemplatesApiEndpoints(RuleTemplateSrv{
+		RulerSrv:  RulerSrv{DatasourceCache: api.DatasourceCache, QuotaService: api.QuotaService, manager: api.StateManager, store: ruleStore, provenanceStore: api.ProvenanceStore, log: logger},
+		templates: api.RuleTemplateStore,
+	}, m)
+	api.RegisterRuleInsightsApiEndpoints(RuleInsightsSrv{store: ruleStore, insights: api.RuleInsights, log: logger}, m)
+	api.RegisterAlertInstancesApiEndpoints(AlertInstancesSrv{store: ruleStore, manager: api.StateManager, log: logger}, m)
+	api.RegisterRecurringSilencesApiEndpoints(AlertmanagerSrv{store: api.AlertingStore, provenanceStore: api.ProvenanceStore, mam: api.MultiOrgAlertmanager, QuotaService: api.QuotaService, log: logger}, m)
+	api.RegisterEscalationsApiEndpoints(AlertmanagerSrv{store: api.AlertingStore, provenanceStore: api.ProvenanceStore, mam: api.MultiOrgAlertmanager, QuotaService: api.QuotaService, log: logger}, m)
+	api.RegisterNotificationLogApiEndpoints(AlertmanagerSrv{store: api.AlertingStore, provenanceStore: api.ProvenanceStore, mam: api.MultiOrgAlertmanager, QuotaService: api.QuotaService, log: logger}, m)
+	api.RegisterAlertmanagerImportApiEndpoints(AlertmanagerSrv{store: api.AlertingStore, provenanceStore: api.ProvenanceStore, mam: api.MultiOrgAlertmanager, QuotaService: api.QuotaService, log: logger}, m)
+	api.RegisterProvisioningApiEndpoints(ProvisioningSrv{
+		log:             logger,
+		alertRules:      api.AlertRuleService,
+		amConfigs:       api.AlertmanagerConfigService,
+		muteTimings:     api.MuteTimingService,
+		ruleStore:       ruleStore,
+		DatasourceCache: api.DatasourceCache,
+		QuotaService:    api.QuotaService,
+		manager:         api.StateManager,
+	}, m)
 	api.RegisterTestingApiEndpoints(TestingApiSrv{
 		AlertingProxy:   proxy,
 		Cfg:             api.Cfg,
@@ -101,5 +223,14 @@ func (api *API) RegisterAPIEndpoints(m *metrics.Metrics) {
 		store:     api.AdminConfigStore,
 		log:       logger,
 		scheduler: api.Schedule,
+		mam:       api.MultiOrgAlertmanager,
+		gitSync:   api.GitSync,
+		ruleStore: api.RuleStore,
+	}, m)
+	api.RegisterHeartbeatApiEndpoints(HeartbeatSrv{store: api.HeartbeatStore, log: logger}, m)
+	api.RegisterDatasourceMaintenanceApiEndpoints(DatasourceMaintenanceSrv{
+		store:           api.DatasourceMaintenanceStore,
+		DatasourceCache: api.DatasourceCache,
+		log:             logger,
 	}, m)
 }
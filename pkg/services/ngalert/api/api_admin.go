@@ -1,23 +1,39 @@
 package api
 
 import (
+	_ "embed"
 	"errors"
 	"net/http"
+	"sort"
+	"strconv"
+	"time"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/sender"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/util"
 
 	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
 )
 
+// openAPIDocument is the OpenAPI 3 document of the API, generated from its definitions with make openapi.json in the
+// tooling directory.
+//
+//go:embed tooling/openapi.json
+var openAPIDocument []byte
+
 type AdminSrv struct {
 	scheduler Scheduler
 	store     store.AdminConfigurationStore
+	mam       *notifier.MultiOrgAlertmanager
+	// gitSync is nil if the Git sync is not configured.
+	gitSync   GitSync
+	ruleStore store.RuleStore
 	log       log.Logger
 }
 
@@ -38,11 +54,23 @@ func (srv AdminSrv) RouteGetAlertmanagers(c *models.ReqContext) response.Respons
 	})
 }
 
-func (srv AdminSrv) RouteGetNGalertConfig(c *models.ReqContext) response.Response {
-	if c.OrgRole != models.ROLE_ADMIN {
-		return accessForbiddenResp()
+func (srv AdminSrv) RouteGetAlertmanagersHealth(c *models.ReqContext) response.Response {
+	health := srv.scheduler.AlertmanagersHealthFor(c.OrgId)
+	resp := apimodels.GettableAlertmanagersHealth{Targets: make([]apimodels.AlertmanagerTargetHealth, 0, len(health))}
+	for _, h := range health {
+		resp.Targets = append(resp.Targets, apimodels.AlertmanagerTargetHealth{
+			URL:        h.URL,
+			Healthy:    h.Healthy,
+			Version:    h.Version,
+			ConfigHash: h.ConfigHash,
+			LastCheck:  h.LastCheck,
+			LastError:  h.LastError,
+		})
 	}
+	return response.JSON(http.StatusOK, resp)
+}
 
+func (srv AdminSrv) RouteGetNGalertConfig(c *models.ReqContext) response.Response {
 	cfg, err := srv.store.GetAdminConfiguration(c.OrgId)
 	if err != nil {
 		if errors.Is(err, store.ErrNoAdminConfiguration) {
@@ -55,18 +83,51 @@ func (srv AdminSrv) RouteGetNGalertConfig(c *models.ReqContext) response.Respons
 	}
 
 	resp := apimodels.GettableNGalertConfig{
-		Alertmanagers: cfg.Alertmanagers,
+		Alertmanagers:       cfg.Alertmanagers,
+		Auth:                authFromModel(cfg.Auth),
+		AlertmanagersChoice: cfg.SendAlertsTo.String(),
 	}
 	return response.JSON(http.StatusOK, resp)
 }
 
 func (srv AdminSrv) RoutePostNGalertConfig(c *models.ReqContext, body apimodels.PostableNGalertConfig) response.Response {
-	if c.OrgRole != models.ROLE_ADMIN {
-		return accessForbiddenResp()
+	// The secrets that aren't sent again are kept.
+	var existingAuth *ngmodels.ExternalAlertmanagerAuth
+	existing, err := srv.store.GetAdminConfiguration(c.OrgId)
+	if err != nil && !errors.Is(err, store.ErrNoAdminConfiguration) {
+		msg := "failed to fetch admin configuration from the database"
+		srv.log.Error(msg, "err", err)
+		return ErrResp(http.StatusInternalServerError, err, msg)
+	}
+	if existing != nil {
+		existingAuth = existing.Auth
+	}
+
+	auth, err := authToModel(body.Auth, existingAuth)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid authentication of the Alertmanagers")
+	}
+
+	sendAlertsTo, err := ngmodels.ParseAlertmanagersChoice(body.AlertmanagersChoice)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	if sendAlertsTo == ngmodels.ExternalAlertmanagers && len(body.Alertmanagers) == 0 {
+		hasDatasource, err := srv.hasDatasourceAlertmanager(c.OrgId)
+		if err != nil {
+			msg := "failed to fetch the Alertmanager datasources from the database"
+			srv.log.Error(msg, "err", err)
+			return ErrResp(http.StatusInternalServerError, err, msg)
+		}
+		if !hasDatasource {
+			return ErrResp(http.StatusBadRequest, errors.New("at least one external Alertmanager is required to only send alerts to external Alertmanagers"), "")
+		}
 	}
 
 	cfg := &ngmodels.AdminConfiguration{
 		Alertmanagers: body.Alertmanagers,
+		Auth:          auth,
+		SendAlertsTo:  sendAlertsTo,
 		OrgID:         c.OrgId,
 	}
 
@@ -81,10 +142,6 @@ func (srv AdminSrv) RoutePostNGalertConfig(c *models.ReqContext, body apimodels.
 }
 
 func (srv AdminSrv) RouteDeleteNGalertConfig(c *models.ReqContext) response.Response {
-	if c.OrgRole != models.ROLE_ADMIN {
-		return accessForbiddenResp()
-	}
-
 	err := srv.store.DeleteAdminConfiguration(c.OrgId)
 	if err != nil {
 		srv.log.Error("unable to delete configuration", "err", err)
@@ -93,3 +150,384 @@ func (srv AdminSrv) RouteDeleteNGalertConfig(c *models.ReqContext) response.Resp
 
 	return response.JSON(http.StatusOK, util.DynMap{"message": "admin configuration deleted"})
 }
+
+func (srv AdminSrv) RouteGetGitSyncStatus(c *models.ReqContext) response.Response {
+	if srv.gitSync == nil {
+		return ErrResp(http.StatusNotFound, errors.New("the Git sync is not configured"), "")
+	}
+	return response.JSON(http.StatusOK, srv.gitSync.Status())
+}
+
+func (srv AdminSrv) RoutePostGitSync(c *models.ReqContext) response.Response {
+	if srv.gitSync == nil {
+		return ErrResp(http.StatusNotFound, errors.New("the Git sync is not configured"), "")
+	}
+	return response.JSON(http.StatusOK, srv.gitSync.Sync(c.Req.Context(), true))
+}
+
+func (srv AdminSrv) RouteGetOrgsAlertingHealth(c *models.ReqContext) response.Response {
+	counts, err := srv.ruleStore.CountAlertRules()
+	if err != nil {
+		msg := "failed to count the alert rules of the organizations"
+		srv.log.Error(msg, "err", err)
+		return ErrResp(http.StatusInternalServerError, err, msg)
+	}
+
+	orgs := map[int64]*apimodels.GettableOrgAlertingHealth{}
+	org := func(orgID int64) *apimodels.GettableOrgAlertingHealth {
+		h, ok := orgs[orgID]
+		if !ok {
+			h = &apimodels.GettableOrgAlertingHealth{OrgID: orgID}
+			orgs[orgID] = h
+		}
+		return h
+	}
+	for _, count := range counts {
+		h := org(count.OrgID)
+		h.Rules = count.Rules
+		h.PausedRules = count.Paused
+	}
+	for orgID, am := range srv.mam.AlertmanagersHealth() {
+		h := org(orgID)
+		h.AlertmanagerReady = am.Ready
+		h.ConfigHash = am.ConfigHash
+		h.LastSyncAt = am.LastSyncAt
+		h.SyncError = am.SyncError
+		h.NotificationAttempts = am.NotificationAttempts
+		h.NotificationFailures = am.NotificationFailures
+		h.NotificationFailureRate = am.FailureRate()
+		h.LastNotificationError = am.LastNotificationError
+		h.LastNotificationErrorAt = am.LastNotificationErrorAt
+	}
+
+	resp := make(apimodels.GettableOrgsAlertingHealth, 0, len(orgs))
+	for orgID, h := range orgs {
+		h.EvaluationLagSeconds = srv.scheduler.EvaluationLagFor(orgID).Seconds()
+		resp = append(resp, *h)
+	}
+	sort.Slice(resp, func(i, j int) bool { return resp[i].OrgID < resp[j].OrgID })
+	return response.JSON(http.StatusOK, resp)
+}
+
+func (srv AdminSrv) RoutePostOrgsAlertmanagersSync(c *models.ReqContext) response.Response {
+	var orgID int64
+	if c.Query("orgId") != "" {
+		var err error
+		if orgID, err = strconv.ParseInt(c.Query("orgId"), 10, 64); err != nil || orgID < 1 {
+			return ErrResp(http.StatusBadRequest, errors.New("the organization ID should be a positive integer"), "")
+		}
+	}
+
+	syncErrs, err := srv.mam.ResyncAlertmanagers(c.Req.Context(), orgID)
+	if err != nil {
+		if errors.Is(err, notifier.ErrOrgNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		msg := "failed to load the organizations"
+		srv.log.Error(msg, "err", err)
+		return ErrResp(http.StatusInternalServerError, err, msg)
+	}
+
+	health := srv.mam.AlertmanagersHealth()
+	resp := apimodels.GettableAlertmanagersSync{Orgs: make([]apimodels.AlertmanagerSyncResult, 0, len(syncErrs))}
+	for id, syncErr := range syncErrs {
+		result := apimodels.AlertmanagerSyncResult{OrgID: id, ConfigHash: health[id].ConfigHash}
+		if syncErr != nil {
+			result.Error = syncErr.Error()
+		}
+		resp.Orgs = append(resp.Orgs, result)
+	}
+	sort.Slice(resp.Orgs, func(i, j int) bool { return resp.Orgs[i].OrgID < resp.Orgs[j].OrgID })
+	return response.JSON(http.StatusOK, resp)
+}
+
+func (srv AdminSrv) RouteGetStandby(c *models.ReqContext) response.Response {
+	return response.JSON(http.StatusOK, standbyToAPI(srv.mam.StandbyStatus()))
+}
+
+func (srv AdminSrv) RoutePostStandbyPromote(c *models.ReqContext) response.Response {
+	status, err := srv.mam.Promote()
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	return response.JSON(http.StatusOK, standbyToAPI(status))
+}
+
+func (srv AdminSrv) RoutePostStandbyDemote(c *models.ReqContext) response.Response {
+	status, err := srv.mam.Demote()
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	return response.JSON(http.StatusOK, standbyToAPI(status))
+}
+
+func standbyToAPI(s notifier.StandbyStatus) apimodels.GettableStandby {
+	return apimodels.GettableStandby{Mode: s.Mode, Active: s.Active, Promoted: s.Promoted, Position: s.Position}
+}
+
+func (srv AdminSrv) RouteGetMaintenanceMode(c *models.ReqContext) response.Response {
+	am, errResp := srv.alertmanagerFor(c.OrgId)
+	if errResp != nil {
+		return errResp
+	}
+
+	m := am.GetMaintenanceMode()
+	if !m.Active(timeNow()) {
+		return ErrResp(http.StatusNotFound, ngmodels.ErrNoMaintenanceMode, "")
+	}
+
+	return response.JSON(http.StatusOK, apimodels.GettableMaintenanceMode{
+		Reason:    m.Reason,
+		CreatedBy: m.CreatedBy,
+		ExpiresAt: time.Unix(m.ExpiresAt, 0).UTC(),
+	})
+}
+
+func (srv AdminSrv) RoutePostMaintenanceMode(c *models.ReqContext, body apimodels.PostableMaintenanceMode) response.Response {
+	if body.Duration <= 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("duration should be positive"), "invalid maintenance mode")
+	}
+
+	am, errResp := srv.alertmanagerFor(c.OrgId)
+	if errResp != nil {
+		return errResp
+	}
+
+	m := &ngmodels.MaintenanceMode{
+		Reason:    body.Reason,
+		CreatedBy: c.SignedInUser.Login,
+		ExpiresAt: timeNow().Add(time.Duration(body.Duration)).Unix(),
+	}
+	if err := am.SaveAndApplyMaintenanceMode(m); err != nil {
+		msg := "failed to save the maintenance mode to the database"
+		srv.log.Error(msg, "err", err)
+		return ErrResp(http.StatusInternalServerError, err, msg)
+	}
+
+	return response.JSON(http.StatusCreated, util.DynMap{"message": "maintenance mode enabled"})
+}
+
+func (srv AdminSrv) RouteDeleteMaintenanceMode(c *models.ReqContext) response.Response {
+	am, errResp := srv.alertmanagerFor(c.OrgId)
+	if errResp != nil {
+		return errResp
+	}
+
+	if err := am.DeleteMaintenanceMode(); err != nil {
+		srv.log.Error("unable to delete maintenance mode", "err", err)
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+
+	return response.JSON(http.StatusOK, util.DynMap{"message": "maintenance mode disabled"})
+}
+
+func (srv AdminSrv) RouteGetLabelPolicies(c *models.ReqContext) response.Response {
+	am, errResp := srv.alertmanagerFor(c.OrgId)
+	if errResp != nil {
+		return errResp
+	}
+
+	p := am.GetLabelPolicies()
+	if p == nil {
+		return ErrResp(http.StatusNotFound, ngmodels.ErrNoLabelPolicies, "")
+	}
+
+	resp := apimodels.GettableLabelPolicies{
+		Policies:  make([]apimodels.LabelPolicy, 0, len(p.Policies)),
+		UpdatedBy: p.UpdatedBy,
+		UpdatedAt: time.Unix(p.UpdatedAt, 0).UTC(),
+	}
+	for _, policy := range p.Policies {
+		resp.Policies = append(resp.Policies, apimodels.LabelPolicy{
+			Action:      string(policy.Action),
+			Matchers:    policy.Matchers,
+			Label:       policy.Label,
+			TargetLabel: policy.TargetLabel,
+			Value:       policy.Value,
+			Overwrite:   policy.Overwrite,
+			Lookup:      policy.Lookup,
+			LookupURL:   policy.LookupURL,
+			Default:     policy.Default,
+		})
+	}
+	return response.JSON(http.StatusOK, resp)
+}
+
+func (srv AdminSrv) RoutePostLabelPolicies(c *models.ReqContext, body apimodels.PostableLabelPolicies) response.Response {
+	p := &ngmodels.LabelPolicies{
+		Policies:  make(ngmodels.LabelPolicyList, 0, len(body.Policies)),
+		UpdatedBy: c.SignedInUser.Login,
+	}
+	for i, policy := range body.Policies {
+		lookup := policy.Lookup
+		if policy.LookupCSV != "" {
+			if len(lookup) > 0 {
+				return ErrResp(http.StatusBadRequest, errors.New("either the lookup or the lookup CSV should be given, not both"), "invalid label policy %d", i)
+			}
+			var err error
+			if lookup, err = notifier.ParseLabelPolicyLookup([]byte(policy.LookupCSV)); err != nil {
+				return ErrResp(http.StatusBadRequest, err, "invalid label policy %d", i)
+			}
+		}
+		p.Policies = append(p.Policies, ngmodels.LabelPolicy{
+			Action:      ngmodels.LabelPolicyAction(policy.Action),
+			Matchers:    policy.Matchers,
+			Label:       policy.Label,
+			TargetLabel: policy.TargetLabel,
+			Value:       policy.Value,
+			Overwrite:   policy.Overwrite,
+			Lookup:      lookup,
+			LookupURL:   policy.LookupURL,
+			Default:     policy.Default,
+		})
+	}
+
+	am, errResp := srv.alertmanagerFor(c.OrgId)
+	if errResp != nil {
+		return errResp
+	}
+
+	if err := am.SaveAndApplyLabelPolicies(p); err != nil {
+		if errors.Is(err, ngmodels.ErrLabelPolicyFailedValidation) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		msg := "failed to save the label policies to the database"
+		srv.log.Error(msg, "err", err)
+		return ErrResp(http.StatusInternalServerError, err, msg)
+	}
+
+	return response.JSON(http.StatusCreated, util.DynMap{"message": "label policies updated"})
+}
+
+func (srv AdminSrv) RouteDeleteLabelPolicies(c *models.ReqContext) response.Response {
+	am, errResp := srv.alertmanagerFor(c.OrgId)
+	if errResp != nil {
+		return errResp
+	}
+
+	if err := am.DeleteLabelPolicies(); err != nil {
+		srv.log.Error("unable to delete label policies", "err", err)
+		return ErrResp(http.StatusInternalServerError, err, "")
+	}
+
+	return response.JSON(http.StatusOK, util.DynMap{"message": "label policies deleted"})
+}
+
+func (srv AdminSrv) RouteGetOpenAPIDocument(c *models.ReqContext) response.Response {
+	return response.Respond(http.StatusOK, openAPIDocument).SetHeader("Content-Type", "application/json")
+}
+
+func (srv AdminSrv) alertmanagerFor(orgID int64) (*notifier.Alertmanager, response.Response) {
+	am, err := srv.mam.AlertmanagerFor(orgID)
+	if err != nil {
+		if errors.Is(err, notifier.ErrNoAlertmanagerForOrg) {
+			return nil, ErrResp(http.StatusNotFound, err, "")
+		}
+		if errors.Is(err, notifier.ErrAlertmanagerNotReady) {
+			return nil, ErrResp(http.StatusConflict, err, "")
+		}
+		return nil, ErrResp(http.StatusInternalServerError, err, "unable to obtain org's Alertmanager")
+	}
+	return am, nil
+}
+
+// hasDatasourceAlertmanager returns whether an Alertmanager datasource of the organization handles Grafana managed alerts.
+func (srv AdminSrv) hasDatasourceAlertmanager(orgID int64) (bool, error) {
+	dss, err := srv.store.GetAlertmanagerDatasources()
+	if err != nil {
+		return false, err
+	}
+	for _, ds := range dss {
+		if ds.OrgId == orgID && sender.HandlesGrafanaManagedAlerts(ds) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// authToModel validates the authentication of the Alertmanagers and encrypts its secrets, the secrets left empty
+// are taken from the existing authentication.
+func authToModel(auth *apimodels.ExternalAlertmanagerAuth, existing *ngmodels.ExternalAlertmanagerAuth) (*ngmodels.ExternalAlertmanagerAuth, error) {
+	if auth == nil || (auth.TLS == nil && auth.OAuth2 == nil) {
+		return nil, nil
+	}
+	if existing == nil {
+		existing = &ngmodels.ExternalAlertmanagerAuth{}
+	}
+
+	res := &ngmodels.ExternalAlertmanagerAuth{}
+	if t := auth.TLS; t != nil {
+		key := ""
+		if t.ClientKey != "" {
+			encrypted, err := ngmodels.EncryptSecret(t.ClientKey)
+			if err != nil {
+				return nil, err
+			}
+			key = encrypted
+		} else if existing.TLS != nil {
+			key = existing.TLS.ClientKey
+		}
+		if (t.ClientCert == "") != (key == "") {
+			return nil, errors.New("the TLS client certificate and key should be set together")
+		}
+		res.TLS = &ngmodels.ExternalAlertmanagerTLS{
+			CACert:             t.CACert,
+			ClientCert:         t.ClientCert,
+			ClientKey:          key,
+			ServerName:         t.ServerName,
+			InsecureSkipVerify: t.InsecureSkipVerify,
+		}
+	}
+
+	if o := auth.OAuth2; o != nil {
+		if o.ClientID == "" || o.TokenURL == "" {
+			return nil, errors.New("the OAuth2 client ID and token URL are required")
+		}
+		secret := ""
+		if o.ClientSecret != "" {
+			encrypted, err := ngmodels.EncryptSecret(o.ClientSecret)
+			if err != nil {
+				return nil, err
+			}
+			secret = encrypted
+		} else if existing.OAuth2 != nil {
+			secret = existing.OAuth2.ClientSecret
+		}
+		if secret == "" {
+			return nil, errors.New("the OAuth2 client secret is required")
+		}
+		res.OAuth2 = &ngmodels.ExternalAlertmanagerOAuth2{
+			ClientID:     o.ClientID,
+			ClientSecret: secret,
+			TokenURL:     o.TokenURL,
+			Scopes:       o.Scopes,
+		}
+	}
+	return res, nil
+}
+
+// authFromModel returns the authentication of the Alertmanagers without its secrets.
+func authFromModel(auth *ngmodels.ExternalAlertmanagerAuth) *apimodels.ExternalAlertmanagerAuth {
+	if auth == nil {
+		return nil
+	}
+	res := &apimodels.ExternalAlertmanagerAuth{}
+	if t := auth.TLS; t != nil {
+		res.TLS = &apimodels.ExternalAlertmanagerTLS{
+			CACert:             t.CACert,
+			ClientCert:         t.ClientCert,
+			ClientKeySet:       t.ClientKey != "",
+			ServerName:         t.ServerName,
+			InsecureSkipVerify: t.InsecureSkipVerify,
+		}
+	}
+	if o := auth.OAuth2; o != nil {
+		res.OAuth2 = &apimodels.ExternalAlertmanagerOAuth2{
+			ClientID:        o.ClientID,
+			ClientSecretSet: o.ClientSecret != "",
+			TokenURL:        o.TokenURL,
+			Scopes:          o.Scopes,
+		}
+	}
+	return res
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/macaron.v1"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/models"
+	acmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+// routeRecorder records the routes registered by the API.
+type routeRecorder struct {
+	routes []string
+}
+
+func (r *routeRecorder) Handle(method, pattern string, _ []macaron.Handler) {
+	r.routes = append(r.routes, method+" "+pattern)
+}
+
+func (r *routeRecorder) Get(pattern string, handlers ...macaron.Handler) {
+	r.Handle(http.MethodGet, pattern, handlers)
+}
+
+func TestRouteGetOpenAPIDocument(t *testing.T) {
+	var doc struct {
+		OpenAPI string                                `json:"openapi"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+
+	t.Run("serves the document", func(t *testing.T) {
+		resp := AdminSrv{}.RouteGetOpenAPIDocument(&models.ReqContext{})
+		require.Equal(t, http.StatusOK, resp.Status())
+		require.NoError(t, json.Unmarshal(resp.Body(), &doc))
+		require.Equal(t, "3.0.3", doc.OpenAPI)
+	})
+
+	t.Run("documents all the routes", func(t *testing.T) {
+		api := &API{RouteRegister: routing.NewRouteRegister(), AccessControl: acmock.New()}
+		api.RegisterAPIEndpoints(metrics.NewMetrics(prometheus.NewRegistry()))
+		recorder := &routeRecorder{}
+		api.RouteRegister.Register(recorder)
+
+		documented := make([]string, 0, len(recorder.routes))
+		for path, item := range doc.Paths {
+			for method := range item {
+				documented = append(documented, strings.ToUpper(method)+" "+toMacaronPath(path))
+			}
+		}
+		require.ElementsMatch(t, recorder.routes, documented)
+	})
+}
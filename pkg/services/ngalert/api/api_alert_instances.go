@@ -0,0 +1,225 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/pkg/labels"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+const (
+	defaultAlertInstancesLimit = 100
+	maxAlertInstancesLimit     = 1000
+)
+
+// alertInstanceStates are the states the alert instances can be filtered by.
+var alertInstanceStates = map[string]eval.State{
+	"normal":   eval.Normal,
+	"pending":  eval.Pending,
+	"alerting": eval.Alerting,
+	"nodata":   eval.NoData,
+	"error":    eval.Error,
+}
+
+type AlertInstancesSrv struct {
+	store   store.RuleStore
+	manager *state.Manager
+	log     log.Logger
+}
+
+// alertInstancesFilter are the filters and the pagination of the alert instances.
+type alertInstancesFilter struct {
+	matchers   []*labels.Matcher
+	states     map[eval.State]struct{}
+	folderUIDs []string
+	limit      int
+	offset     int
+}
+
+func parseAlertInstancesFilter(c *models.ReqContext) (alertInstancesFilter, error) {
+	f := alertInstancesFilter{folderUIDs: c.QueryStrings("folder_uid"), limit: defaultAlertInstancesLimit}
+	for _, s := range c.QueryStrings("filter") {
+		matchers, err := labels.ParseMatchers(s)
+		if err != nil {
+			return f, fmt.Errorf("invalid filter %q: %w", s, err)
+		}
+		f.matchers = append(f.matchers, matchers...)
+	}
+	for _, s := range c.QueryStrings("state") {
+		st, ok := alertInstanceStates[s]
+		if !ok {
+			return f, fmt.Errorf("unknown state %q", s)
+		}
+		if f.states == nil {
+			f.states = map[eval.State]struct{}{}
+		}
+		f.states[st] = struct{}{}
+	}
+	var err error
+	if c.Query("limit") != "" {
+		if f.limit, err = strconv.Atoi(c.Query("limit")); err != nil || f.limit < 1 || f.limit > maxAlertInstancesLimit {
+			return f, fmt.Errorf("limit must be between 1 and %d", maxAlertInstancesLimit)
+		}
+	}
+	if c.Query("offset") != "" {
+		if f.offset, err = strconv.Atoi(c.Query("offset")); err != nil || f.offset < 0 {
+			return f, fmt.Errorf("offset must not be negative")
+		}
+	}
+	return f, nil
+}
+
+// namespaceUIDs returns the UIDs of the folders of the instances, among the folders visible to the user.
+func (f alertInstancesFilter) namespaceUIDs(namespaces map[string]*models.Folder) []string {
+	return ruleListOptions{folderUIDs: f.folderUIDs}.namespaceUIDs(namespaces)
+}
+
+func (f alertInstancesFilter) matches(s *state.State) bool {
+	if f.states != nil {
+		if _, ok := f.states[s.State]; !ok {
+			return false
+		}
+	}
+	for _, m := range f.matchers {
+		if !m.Matches(s.Labels[m.Name]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (srv AlertInstancesSrv) RouteGetAlertInstances(c *models.ReqContext) response.Response {
+	filter, err := parseAlertInstancesFilter(c)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid parameters")
+	}
+
+	result := apimodels.AlertInstances{Instances: []apimodels.AlertInstance{}}
+	namespaces, err := srv.store.GetNamespaces(c.SignedInUser.OrgId, c.SignedInUser)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get namespaces visible to the user")
+	}
+	// Without a namespace the query would return the rules of all folders.
+	namespaceUIDs := filter.namespaceUIDs(namespaces)
+	if len(namespaceUIDs) == 0 {
+		return response.JSON(http.StatusOK, result)
+	}
+	query := ngmodels.SearchAlertRulesQuery{OrgID: c.SignedInUser.OrgId, NamespaceUIDs: namespaceUIDs, SortBy: ngmodels.AlertRulesSortByTitle}
+	if err := srv.store.SearchAlertRules(&query); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the alert rules")
+	}
+
+	for _, r := range query.Result {
+		for _, instance := range toAlertInstances(r, srv.manager.GetStatesForRuleUID(c.SignedInUser.OrgId, r.UID), filter) {
+			if result.Total >= filter.offset && result.Total < filter.offset+filter.limit {
+				result.Instances = append(result.Instances, instance)
+			}
+			result.Total++
+		}
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// toAlertInstances returns the instances of the rule matching the filter, sorted by labels.
+func toAlertInstances(r *ngmodels.AlertRule, states []*state.State, filter alertInstancesFilter) []apimodels.AlertInstance {
+	result := make([]apimodels.AlertInstance, 0, len(states))
+	for _, s := range states {
+		if !filter.matches(s) {
+			continue
+		}
+		value := ""
+		if len(s.Results) > 0 && s.State == eval.Alerting {
+			value = s.Results[0].EvaluationString
+		}
+		result = append(result, apimodels.AlertInstance{
+			Labels:         map[string]string(s.Labels),
+			Annotations:    s.Annotations,
+			State:          s.State.String(),
+			ActiveAt:       s.StartsAt,
+			LastEvaluation: s.LastEvaluationTime,
+			Value:          value,
+			RuleUID:        r.UID,
+			RuleTitle:      r.Title,
+			FolderUID:      r.NamespaceUID,
+			RuleGroup:      r.RuleGroup,
+		})
+	}
+	// The instances are sorted so that the pages don't change between the requests.
+	sort.Slice(result, func(i, j int) bool {
+		return data.Labels(result[i].Labels).String() < data.Labels(result[j].Labels).String()
+	})
+	return result
+}
+
+func (srv AlertInstancesSrv) RouteGetIncidents(c *models.ReqContext) response.Response {
+	incidents := srv.manager.Incidents(c.SignedInUser.OrgId)
+	result := apimodels.Incidents{Enabled: incidents != nil, Incidents: []apimodels.Incident{}}
+	if len(incidents) == 0 {
+		return response.JSON(http.StatusOK, result)
+	}
+
+	namespaces, err := srv.store.GetNamespaces(c.SignedInUser.OrgId, c.SignedInUser)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get namespaces visible to the user")
+	}
+	if len(namespaces) == 0 {
+		return response.JSON(http.StatusOK, result)
+	}
+	namespaceUIDs := make([]string, 0, len(namespaces))
+	for uid := range namespaces {
+		namespaceUIDs = append(namespaceUIDs, uid)
+	}
+	query := ngmodels.SearchAlertRulesQuery{OrgID: c.SignedInUser.OrgId, NamespaceUIDs: namespaceUIDs}
+	if err := srv.store.SearchAlertRules(&query); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the alert rules")
+	}
+	rules := make(map[string]*ngmodels.AlertRule, len(query.Result))
+	for _, r := range query.Result {
+		rules[r.UID] = r
+	}
+
+	for _, incident := range incidents {
+		if i, ok := toIncident(incident, rules); ok {
+			result.Incidents = append(result.Incidents, i)
+		}
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// toIncident returns the incident with the alerts of the rules visible to the user, false if it has none.
+func toIncident(incident state.Incident, rules map[string]*ngmodels.AlertRule) (apimodels.Incident, bool) {
+	result := apimodels.Incident{
+		ID:           incident.ID,
+		Labels:       map[string]string(incident.Labels),
+		StartsAt:     incident.StartsAt,
+		LastActiveAt: incident.LastActiveAt,
+		Firing:       incident.Firing(),
+	}
+	for _, a := range incident.Alerts {
+		r, ok := rules[a.RuleUID]
+		if !ok {
+			continue
+		}
+		result.Alerts = append(result.Alerts, apimodels.IncidentAlert{
+			Labels:    map[string]string(a.Labels),
+			StartsAt:  a.StartsAt,
+			Firing:    a.Firing,
+			RuleUID:   r.UID,
+			RuleTitle: r.Title,
+			FolderUID: r.NamespaceUID,
+		})
+	}
+	return result, len(result.Alerts) > 0
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestToAlertInstances(t *testing.T) {
+	rule := &ngmodels.AlertRule{UID: "rule", Title: "Rule", NamespaceUID: "folder", RuleGroup: "group"}
+	states := []*state.State{
+		{AlertRuleUID: "rule", State: eval.Alerting, Labels: data.Labels{"severity": "critical", "team": "db-primary"}, Results: []state.Evaluation{{EvaluationString: "[ var='A' value=3 ]"}}},
+		{AlertRuleUID: "rule", State: eval.Normal, Labels: data.Labels{"severity": "critical", "team": "db-backup"}},
+		{AlertRuleUID: "rule", State: eval.Alerting, Labels: data.Labels{"severity": "warning", "team": "db-primary"}},
+		{AlertRuleUID: "rule", State: eval.Alerting, Labels: data.Labels{"severity": "critical", "team": "web"}},
+	}
+	matchers, err := labels.ParseMatchers(`{severity="critical",team=~"db.*"}`)
+	require.NoError(t, err)
+
+	result := toAlertInstances(rule, states, alertInstancesFilter{matchers: matchers})
+	require.Len(t, result, 2)
+	require.Equal(t, "db-backup", result[0].Labels["team"], "the instances are sorted by labels")
+	require.Equal(t, "Normal", result[0].State)
+	require.Empty(t, result[0].Value)
+	require.Equal(t, "db-primary", result[1].Labels["team"])
+	require.Equal(t, "[ var='A' value=3 ]", result[1].Value)
+	require.Equal(t, "Rule", result[1].RuleTitle)
+	require.Equal(t, "folder", result[1].FolderUID)
+
+	result = toAlertInstances(rule, states, alertInstancesFilter{matchers: matchers, states: map[eval.State]struct{}{eval.Alerting: {}}})
+	require.Len(t, result, 1)
+	require.Equal(t, "db-primary", result[0].Labels["team"])
+}
+
+func TestToIncident(t *testing.T) {
+	rules := map[string]*ngmodels.AlertRule{"db": {UID: "db", Title: "Database down", NamespaceUID: "folder"}}
+	incident := state.Incident{
+		ID:     "1f",
+		Labels: data.Labels{"cluster": "eu"},
+		Alerts: []state.IncidentAlert{
+			{RuleUID: "db", Labels: data.Labels{"cluster": "eu", "alertname": "DatabaseDown"}, Firing: true},
+			{RuleUID: "hidden", Labels: data.Labels{"cluster": "eu", "alertname": "HighLatency"}, Firing: true},
+		},
+	}
+
+	result, ok := toIncident(incident, rules)
+	require.True(t, ok)
+	require.Equal(t, "1f", result.ID)
+	require.True(t, result.Firing)
+	require.Len(t, result.Alerts, 1, "only the alerts of the rules visible to the user are returned")
+	require.Equal(t, "Database down", result.Alerts[0].RuleTitle)
+	require.Equal(t, "folder", result.Alerts[0].FolderUID)
+
+	_, ok = toIncident(incident, map[string]*ngmodels.AlertRule{})
+	require.False(t, ok)
+}
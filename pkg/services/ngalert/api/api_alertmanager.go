@@ -12,10 +12,13 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/util"
 )
 
@@ -25,9 +28,12 @@ const (
 )
 
 type AlertmanagerSrv struct {
-	mam   *notifier.MultiOrgAlertmanager
-	store store.AlertingStore
-	log   log.Logger
+	mam             *notifier.MultiOrgAlertmanager
+	store           store.AlertingStore
+	provenanceStore store.ProvisioningStore
+	QuotaService    *quota.QuotaService
+	ac              ac.AccessControl
+	log             log.Logger
 }
 
 type UnknownReceiverError struct {
@@ -102,15 +108,21 @@ func (srv AlertmanagerSrv) RouteGetAMStatus(c *models.ReqContext) response.Respo
 }
 
 func (srv AlertmanagerSrv) RouteCreateSilence(c *models.ReqContext, postableSilence apimodels.PostableSilence) response.Response {
-	if !c.HasUserRole(models.ROLE_EDITOR) {
-		return ErrResp(http.StatusForbidden, errors.New("permission denied"), "")
-	}
-
 	am, errResp := srv.AlertmanagerFor(c.OrgId)
 	if errResp != nil {
 		return errResp
 	}
 
+	if postableSilence.ID == "" {
+		used, err := countActiveSilences(am)
+		if err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "failed to list silences")
+		}
+		if errResp := quotaErrResp(srv.QuotaService.CheckQuotaWithUsage(c, quotaTargetSilence, used, 1)); errResp != nil {
+			return errResp
+		}
+	}
+
 	silenceID, err := am.CreateSilence(&postableSilence)
 	if err != nil {
 		if errors.Is(err, notifier.ErrSilenceNotFound) {
@@ -127,8 +139,8 @@ func (srv AlertmanagerSrv) RouteCreateSilence(c *models.ReqContext, postableSile
 }
 
 func (srv AlertmanagerSrv) RouteDeleteAlertingConfig(c *models.ReqContext) response.Response {
-	if !c.HasUserRole(models.ROLE_EDITOR) {
-		return ErrResp(http.StatusForbidden, errors.New("permission denied"), "")
+	if errResp := provisionedErrResp(srv.checkNoProvisionedConfig(c)); errResp != nil {
+		return errResp
 	}
 
 	am, errResp := srv.AlertmanagerFor(c.OrgId)
@@ -145,10 +157,6 @@ func (srv AlertmanagerSrv) RouteDeleteAlertingConfig(c *models.ReqContext) respo
 }
 
 func (srv AlertmanagerSrv) RouteDeleteSilence(c *models.ReqContext) response.Response {
-	if !c.HasUserRole(models.ROLE_EDITOR) {
-		return ErrResp(http.StatusForbidden, errors.New("permission denied"), "")
-	}
-
 	am, errResp := srv.AlertmanagerFor(c.OrgId)
 	if errResp != nil {
 		return errResp
@@ -165,10 +173,6 @@ func (srv AlertmanagerSrv) RouteDeleteSilence(c *models.ReqContext) response.Res
 }
 
 func (srv AlertmanagerSrv) RouteGetAlertingConfig(c *models.ReqContext) response.Response {
-	if !c.HasUserRole(models.ROLE_EDITOR) {
-		return ErrResp(http.StatusForbidden, errors.New("permission denied"), "")
-	}
-
 	query := ngmodels.GetLatestAlertmanagerConfigurationQuery{OrgID: c.OrgId}
 	if err := srv.store.GetLatestAlertmanagerConfiguration(&query); err != nil {
 		if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
@@ -182,10 +186,21 @@ func (srv AlertmanagerSrv) RouteGetAlertingConfig(c *models.ReqContext) response
 		return ErrResp(http.StatusInternalServerError, err, "failed to unmarshal alertmanager configuration")
 	}
 
+	contactPoints, err := srv.provenanceStore.GetProvenances(c.OrgId, ngmodels.ResourceTypeContactPoint)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the provenance of the contact points")
+	}
+	routeProvenance, err := srv.provenanceStore.GetProvenance(c.OrgId, ngmodels.ResourceTypeNotificationPolicy, ngmodels.NotificationPolicyResourceKey)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the provenance of the notification policies")
+	}
+
 	result := apimodels.GettableUserConfig{
 		TemplateFiles: cfg.TemplateFiles,
+		Fingerprint:   query.Result.Fingerprint(),
 		AlertmanagerConfig: apimodels.GettableApiAlertingConfig{
-			Config: cfg.AlertmanagerConfig.Config,
+			Config:          cfg.AlertmanagerConfig.Config,
+			RouteProvenance: routeProvenance,
 		},
 	}
 	for _, recv := range cfg.AlertmanagerConfig.Receivers {
@@ -207,6 +222,7 @@ func (srv AlertmanagerSrv) RouteGetAlertingConfig(c *models.ReqContext) response
 				Name:                  pr.Name,
 				Type:                  pr.Type,
 				DisableResolveMessage: pr.DisableResolveMessage,
+				Locale:                pr.Locale,
 				Settings:              pr.Settings,
 				SecureFields:          secureFields,
 			}
@@ -218,6 +234,7 @@ func (srv AlertmanagerSrv) RouteGetAlertingConfig(c *models.ReqContext) response
 			},
 		}
 		gettableApiReceiver.Name = recv.Name
+		gettableApiReceiver.Provenance = contactPoints[recv.Name]
 		result.AlertmanagerConfig.Receivers = append(result.AlertmanagerConfig.Receivers, &gettableApiReceiver)
 	}
 
@@ -311,10 +328,15 @@ func (srv AlertmanagerSrv) RouteGetSilences(c *models.ReqContext) response.Respo
 }
 
 func (srv AlertmanagerSrv) RoutePostAlertingConfig(c *models.ReqContext, body apimodels.PostableUserConfig) response.Response {
-	if !c.HasUserRole(models.ROLE_EDITOR) {
-		return ErrResp(http.StatusForbidden, errors.New("permission denied"), "")
+	if errResp := srv.saveAlertingConfig(c, &body); errResp != nil {
+		return errResp
 	}
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": "configuration created"})
+}
 
+// saveAlertingConfig saves and applies the Alertmanager configuration of the organization, it returns the error
+// response if it can't.
+func (srv AlertmanagerSrv) saveAlertingConfig(c *models.ReqContext, body *apimodels.PostableUserConfig) response.Response {
 	// Get the last known working configuration
 	query := ngmodels.GetLatestAlertmanagerConfigurationQuery{OrgID: c.OrgId}
 	if err := srv.store.GetLatestAlertmanagerConfiguration(&query); err != nil {
@@ -332,6 +354,38 @@ func (srv AlertmanagerSrv) RoutePostAlertingConfig(c *models.ReqContext, body ap
 		return ErrResp(http.StatusInternalServerError, err, "")
 	}
 
+	// The provisioned parts of the configuration are compared with their secure settings decrypted.
+	currentConfig, err := notifier.LoadDefault()
+	if query.Result != nil {
+		currentConfig, err = notifier.Load([]byte(query.Result.AlertmanagerConfiguration))
+		if err == nil {
+			err = currentConfig.DecryptSecureSettings()
+		}
+	}
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to load latest configuration")
+	}
+	if errResp := provisionedErrResp(srv.checkProvisionedConfig(c, currentConfig, body)); errResp != nil {
+		return errResp
+	}
+	if err := srv.checkOwnedPolicyChanges(c, currentConfig, body); err != nil {
+		if errors.Is(err, provisioning.ErrPolicyNotOwned) {
+			return ErrResp(http.StatusForbidden, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to check the permissions to the notification policies")
+	}
+	if err := provisioning.CheckPolicyOwners(&body.AlertmanagerConfig); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid policy owners")
+	}
+	if err := provisioning.CheckResolvedNotifications(&body.AlertmanagerConfig); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid resolved notification policies")
+	}
+	used := int64(len(currentConfig.AlertmanagerConfig.Receivers))
+	added := int64(len(body.AlertmanagerConfig.Receivers)) - used
+	if errResp := quotaErrResp(srv.QuotaService.CheckQuotaWithUsage(c, quotaTargetContactPoint, used, added)); errResp != nil {
+		return errResp
+	}
+
 	if err := body.ProcessConfig(); err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "failed to post process Alertmanager configuration")
 	}
@@ -341,23 +395,53 @@ func (srv AlertmanagerSrv) RoutePostAlertingConfig(c *models.ReqContext, body ap
 		return errResp
 	}
 
-	if err := am.SaveAndApplyConfig(&body); err != nil {
+	if err := am.SaveAndApplyConfig(body); err != nil {
+		if errors.Is(err, store.ErrAlertmanagerConfigurationConflict) {
+			return ErrResp(http.StatusConflict, err, "")
+		}
 		srv.log.Error("unable to save and apply alertmanager configuration", "err", err)
 		return ErrResp(http.StatusBadRequest, err, "failed to save and apply Alertmanager configuration")
 	}
-
-	return response.JSON(http.StatusAccepted, util.DynMap{"message": "configuration created"})
+	return nil
 }
 
-func (srv AlertmanagerSrv) RoutePostAMAlerts(_ *models.ReqContext, _ apimodels.PostableAlerts) response.Response {
-	return NotImplementedResp
+// checkOwnedPolicyChanges returns an error wrapping provisioning.ErrPolicyNotOwned if the user can only change
+// the notification policies owned by its teams, and the new configuration changes something else.
+func (srv AlertmanagerSrv) checkOwnedPolicyChanges(c *models.ReqContext, current, new *apimodels.PostableUserConfig) error {
+	if srv.ac == nil || srv.ac.IsDisabled() {
+		return nil
+	}
+	canWrite, err := srv.ac.Evaluate(c.Req.Context(), c.SignedInUser, ac.EvalPermission(ac.ActionAlertingNotificationsWrite))
+	if err != nil {
+		return err
+	}
+	if canWrite {
+		return nil
+	}
+	return provisioning.CheckOwnedPolicyChanges(current, new, c.SignedInUser.Teams)
 }
 
-func (srv AlertmanagerSrv) RoutePostTestReceivers(c *models.ReqContext, body apimodels.TestReceiversConfigParams) response.Response {
-	if !c.HasUserRole(models.ROLE_EDITOR) {
-		return accessForbiddenResp()
+// RoutePostAMAlerts receives alerts pushed by external sources, they go through the label policies, routing,
+// silences and contact points of the organization like the alerts of Grafana managed rules.
+func (srv AlertmanagerSrv) RoutePostAMAlerts(c *models.ReqContext, body apimodels.PostableAlerts) response.Response {
+	am, errResp := srv.AlertmanagerFor(c.OrgId)
+	if errResp != nil {
+		return errResp
+	}
+
+	am.ApplyLabelPolicies(body)
+	if err := am.PutAlerts(body); err != nil {
+		// The valid alerts are received even if some of them fail validation.
+		var validationErr *notifier.AlertValidationError
+		if errors.As(err, &validationErr) {
+			return ErrResp(http.StatusBadRequest, err, "invalid alerts")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to receive alerts")
 	}
+	return response.JSON(http.StatusOK, util.DynMap{"message": "alerts received"})
+}
 
+func (srv AlertmanagerSrv) RoutePostTestReceivers(c *models.ReqContext, body apimodels.TestReceiversConfigParams) response.Response {
 	if err := srv.loadSecureSettings(c.OrgId, body.Receivers); err != nil {
 		var unknownReceiverError UnknownReceiverError
 		if errors.As(err, &unknownReceiverError) {
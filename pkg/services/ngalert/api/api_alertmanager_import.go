@@ -0,0 +1,61 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+)
+
+func (srv AlertmanagerSrv) RoutePostAlertmanagerImport(c *models.ReqContext, body apimodels.PostableAlertmanagerImport) response.Response {
+	var raw []byte
+	switch {
+	case body.URL != "" && body.Configuration != "":
+		return ErrResp(http.StatusBadRequest, errors.New("either the URL or the configuration of the Alertmanager should be given, not both"), "")
+	case body.URL != "":
+		var err error
+		raw, err = notifier.FetchAlertmanagerConfig(c.Req.Context(), body.URL)
+		if errors.Is(err, notifier.ErrAlertmanagerURLNotAllowed) {
+			return ErrResp(http.StatusForbidden, err, "")
+		}
+		if err != nil {
+			return ErrResp(http.StatusBadRequest, err, "failed to fetch the Alertmanager configuration")
+		}
+	case body.Configuration != "":
+		raw = []byte(body.Configuration)
+	default:
+		return ErrResp(http.StatusBadRequest, errors.New("the URL or the configuration of the Alertmanager should be given"), "")
+	}
+
+	cfg, unsupported, err := notifier.ImportAlertmanagerConfig(raw, body.TemplateFiles)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	result := apimodels.AlertmanagerImportResult{
+		ContactPoints: make([]apimodels.ImportedContactPoint, 0, len(cfg.AlertmanagerConfig.Receivers)),
+		Unsupported:   unsupported,
+	}
+	if result.Unsupported == nil {
+		result.Unsupported = []string{}
+	}
+	for _, r := range cfg.AlertmanagerConfig.Receivers {
+		cp := apimodels.ImportedContactPoint{Name: r.Name, Integrations: make([]string, 0, len(r.GrafanaManagedReceivers))}
+		for _, gr := range r.GrafanaManagedReceivers {
+			cp.Integrations = append(cp.Integrations, gr.Type)
+		}
+		result.ContactPoints = append(result.ContactPoints, cp)
+	}
+	if body.DryRun {
+		return response.JSON(http.StatusOK, result)
+	}
+
+	if errResp := srv.saveAlertingConfig(c, cfg); errResp != nil {
+		return errResp
+	}
+	result.Applied = true
+	return response.JSON(http.StatusOK, result)
+}
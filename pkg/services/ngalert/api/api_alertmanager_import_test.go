@@ -0,0 +1,52 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/macaron.v1"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestRoutePostAlertmanagerImport(t *testing.T) {
+	srv := AlertmanagerSrv{log: log.New("test")}
+	post := func(body apimodels.PostableAlertmanagerImport) response.Response {
+		return srv.RoutePostAlertmanagerImport(&models.ReqContext{Context: &macaron.Context{}, SignedInUser: &models.SignedInUser{OrgId: 1}}, body)
+	}
+
+	t.Run("reports the imported contact points of a dry run", func(t *testing.T) {
+		resp := post(apimodels.PostableAlertmanagerImport{
+			Configuration: `
+route:
+  receiver: team-a
+receivers:
+- name: team-a
+  webhook_configs:
+  - url: https://example.com/hook
+  wechat_configs:
+  - corp_id: corp
+    api_secret: secret
+`,
+			DryRun: true,
+		})
+		require.Equal(t, http.StatusOK, resp.Status())
+
+		var result apimodels.AlertmanagerImportResult
+		require.NoError(t, json.Unmarshal(resp.(*response.NormalResponse).Body(), &result))
+		require.False(t, result.Applied)
+		require.Equal(t, []apimodels.ImportedContactPoint{{Name: "team-a", Integrations: []string{"webhook"}}}, result.ContactPoints)
+		require.Equal(t, []string{"receivers[team-a].wechat_configs[0]: WeChat is not supported"}, result.Unsupported)
+	})
+
+	t.Run("rejects invalid requests", func(t *testing.T) {
+		require.Equal(t, http.StatusBadRequest, post(apimodels.PostableAlertmanagerImport{}).Status())
+		require.Equal(t, http.StatusBadRequest, post(apimodels.PostableAlertmanagerImport{URL: "http://localhost:9093", Configuration: "route: {}"}).Status())
+		require.Equal(t, http.StatusBadRequest, post(apimodels.PostableAlertmanagerImport{Configuration: "route:\n  receiver: unknown\n", DryRun: true}).Status())
+	})
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// DatasourceMaintenanceSrv manages the maintenance windows of the datasources.
+type DatasourceMaintenanceSrv struct {
+	store           store.DatasourceMaintenanceStore
+	DatasourceCache datasources.CacheService
+	log             log.Logger
+}
+
+func (srv DatasourceMaintenanceSrv) RouteGetDatasourceMaintenanceWindows(c *models.ReqContext) response.Response {
+	datasourceUID, errResp := srv.datasourceUID(c)
+	if errResp != nil {
+		return errResp
+	}
+	windows, err := srv.store.GetDatasourceMaintenanceWindows(c.SignedInUser.OrgId, datasourceUID)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the maintenance windows of the datasource")
+	}
+	result := make(apimodels.DatasourceMaintenanceWindows, 0, len(windows))
+	for _, w := range windows {
+		result = append(result, toDatasourceMaintenanceWindow(w))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv DatasourceMaintenanceSrv) RoutePostDatasourceMaintenanceWindow(c *models.ReqContext, body apimodels.PostableDatasourceMaintenanceWindow) response.Response {
+	datasourceUID, errResp := srv.datasourceUID(c)
+	if errResp != nil {
+		return errResp
+	}
+	w := &ngmodels.DatasourceMaintenanceWindow{
+		OrgID:         c.SignedInUser.OrgId,
+		DatasourceUID: datasourceUID,
+		Reason:        body.Reason,
+		CreatedBy:     c.SignedInUser.Login,
+		StartsAt:      body.StartsAt.Unix(),
+		EndsAt:        body.EndsAt.Unix(),
+	}
+	if err := srv.store.SaveDatasourceMaintenanceWindow(w); err != nil {
+		if errors.Is(err, ngmodels.ErrDatasourceMaintenanceWindowFailedValidation) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to save the maintenance window of the datasource")
+	}
+	return response.JSON(http.StatusCreated, toDatasourceMaintenanceWindow(w))
+}
+
+func (srv DatasourceMaintenanceSrv) RouteDeleteDatasourceMaintenanceWindow(c *models.ReqContext) response.Response {
+	datasourceUID, errResp := srv.datasourceUID(c)
+	if errResp != nil {
+		return errResp
+	}
+	id, err := strconv.ParseInt(c.Params(":WindowID"), 10, 64)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid maintenance window ID")
+	}
+	if err := srv.store.DeleteDatasourceMaintenanceWindow(c.SignedInUser.OrgId, datasourceUID, id); err != nil {
+		if errors.Is(err, ngmodels.ErrDatasourceMaintenanceWindowNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to delete the maintenance window of the datasource")
+	}
+	return response.JSON(http.StatusOK, util.DynMap{"message": "maintenance window deleted"})
+}
+
+// datasourceUID returns the UID of the datasource of the request, if the datasource exists in the organization of
+// the user.
+func (srv DatasourceMaintenanceSrv) datasourceUID(c *models.ReqContext) (string, response.Response) {
+	uid := c.Params(":DatasourceUID")
+	if _, err := srv.DatasourceCache.GetDatasourceByUID(uid, c.SignedInUser, c.SkipCache); err != nil {
+		if errors.Is(err, models.ErrDataSourceNotFound) {
+			return "", ErrResp(http.StatusNotFound, err, "")
+		}
+		if errors.Is(err, models.ErrDataSourceAccessDenied) {
+			return "", ErrResp(http.StatusForbidden, err, "")
+		}
+		return "", ErrResp(http.StatusInternalServerError, err, "failed to get the datasource")
+	}
+	return uid, nil
+}
+
+func toDatasourceMaintenanceWindow(w *ngmodels.DatasourceMaintenanceWindow) apimodels.DatasourceMaintenanceWindow {
+	return apimodels.DatasourceMaintenanceWindow{
+		ID:            w.ID,
+		DatasourceUID: w.DatasourceUID,
+		Reason:        w.Reason,
+		CreatedBy:     w.CreatedBy,
+		StartsAt:      time.Unix(w.StartsAt, 0).UTC(),
+		EndsAt:        time.Unix(w.EndsAt, 0).UTC(),
+	}
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func (srv AlertmanagerSrv) RouteGetEscalations(c *models.ReqContext) response.Response {
+	am, errResp := srv.AlertmanagerFor(c.OrgId)
+	if errResp != nil {
+		return errResp
+	}
+
+	escalations := am.GetEscalations()
+	result := make(apimodels.GettableEscalations, 0, len(escalations))
+	for _, e := range escalations {
+		ge := apimodels.GettableEscalation{
+			ID:             e.ID,
+			GroupKey:       e.GroupKey,
+			GroupLabels:    e.GroupLabels,
+			Receiver:       e.Receiver,
+			Alerts:         e.Alerts(),
+			StartedAt:      e.StartedAt,
+			Step:           e.Step,
+			AcknowledgedBy: e.AcknowledgedBy,
+		}
+		if !e.AcknowledgedAt.IsZero() {
+			ackAt := e.AcknowledgedAt
+			ge.AcknowledgedAt = &ackAt
+		} else if step, at, ok := am.NextEscalationStep(e); ok {
+			ge.NextReceiver = step.Receiver
+			ge.NextEscalationAt = &at
+		}
+		result = append(result, ge)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv AlertmanagerSrv) RouteAcknowledgeEscalation(c *models.ReqContext) response.Response {
+	am, errResp := srv.AlertmanagerFor(c.OrgId)
+	if errResp != nil {
+		return errResp
+	}
+
+	if err := am.AcknowledgeEscalation(c.Params(":EscalationID"), c.SignedInUser.Login); err != nil {
+		if errors.Is(err, notifier.ErrEscalationNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to acknowledge escalation")
+	}
+	return response.JSON(http.StatusOK, util.DynMap{"message": "escalation acknowledged"})
+}
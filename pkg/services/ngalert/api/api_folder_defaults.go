@@ -0,0 +1,85 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// FolderDefaultsSrv manages the defaults the alert rules of the folders inherit.
+type FolderDefaultsSrv struct {
+	store    store.RuleStore
+	defaults store.FolderDefaultsStore
+	log      log.Logger
+}
+
+func (srv FolderDefaultsSrv) RouteGetFolderDefaults(c *models.ReqContext) response.Response {
+	folderUID := c.Params(":FolderUID")
+	if _, err := srv.store.GetNamespaceByUID(folderUID, c.SignedInUser.OrgId, c.SignedInUser, false); err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+	d, err := srv.defaults.GetFolderDefaults(c.SignedInUser.OrgId, folderUID)
+	if err != nil {
+		if errors.Is(err, ngmodels.ErrFolderDefaultsNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the defaults of the folder")
+	}
+	return response.JSON(http.StatusOK, toFolderDefaults(d))
+}
+
+func (srv FolderDefaultsSrv) RoutePutFolderDefaults(c *models.ReqContext, body apimodels.PostableFolderDefaults) response.Response {
+	folderUID := c.Params(":FolderUID")
+	if _, err := srv.store.GetNamespaceByUID(folderUID, c.SignedInUser.OrgId, c.SignedInUser, true); err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+	d := &ngmodels.FolderDefaults{
+		OrgID:           c.SignedInUser.OrgId,
+		FolderUID:       folderUID,
+		IntervalSeconds: int64(time.Duration(body.Interval).Seconds()),
+		NoDataState:     ngmodels.NoDataState(body.NoDataState),
+		ExecErrState:    ngmodels.ExecutionErrorState(body.ExecErrState),
+		Labels:          body.Labels,
+		UpdatedBy:       c.SignedInUser.Login,
+	}
+	if err := srv.defaults.SaveFolderDefaults(d); err != nil {
+		if errors.Is(err, ngmodels.ErrFolderDefaultsFailedValidation) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to save the defaults of the folder")
+	}
+	return response.JSON(http.StatusAccepted, toFolderDefaults(d))
+}
+
+func (srv FolderDefaultsSrv) RouteDeleteFolderDefaults(c *models.ReqContext) response.Response {
+	folderUID := c.Params(":FolderUID")
+	if _, err := srv.store.GetNamespaceByUID(folderUID, c.SignedInUser.OrgId, c.SignedInUser, true); err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+	if err := srv.defaults.DeleteFolderDefaults(c.SignedInUser.OrgId, folderUID); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to delete the defaults of the folder")
+	}
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": "folder defaults deleted"})
+}
+
+func toFolderDefaults(d *ngmodels.FolderDefaults) apimodels.FolderDefaults {
+	return apimodels.FolderDefaults{
+		FolderUID:    d.FolderUID,
+		Interval:     model.Duration(time.Duration(d.IntervalSeconds) * time.Second),
+		NoDataState:  apimodels.NoDataState(d.NoDataState),
+		ExecErrState: apimodels.ExecutionErrorState(d.ExecErrState),
+		Labels:       d.Labels,
+		UpdatedBy:    d.UpdatedBy,
+		Updated:      time.Unix(d.UpdatedAt, 0).UTC(),
+	}
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type HeartbeatSrv struct {
+	store store.HeartbeatStore
+	log   log.Logger
+}
+
+func (srv HeartbeatSrv) RoutePostHeartbeat(c *models.ReqContext) response.Response {
+	h, err := srv.store.RecordHeartbeat(c.Params(":Token"), timeNow())
+	if errors.Is(err, ngmodels.ErrHeartbeatNotFound) {
+		return ErrResp(http.StatusNotFound, err, "")
+	}
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to record heartbeat")
+	}
+	srv.log.Debug("heartbeat received", "org", h.OrgID, "rule_uid", h.RuleUID)
+	return response.JSON(http.StatusOK, util.DynMap{"message": "heartbeat received"})
+}
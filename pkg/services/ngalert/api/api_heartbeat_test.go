@@ -0,0 +1,79 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/macaron.v1"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type fakeHeartbeatStore struct {
+	heartbeats map[string]*ngmodels.AlertRuleHeartbeat
+}
+
+func (s *fakeHeartbeatStore) GetAlertRuleHeartbeat(orgID int64, ruleUID string) (*ngmodels.AlertRuleHeartbeat, error) {
+	for _, h := range s.heartbeats {
+		if h.OrgID == orgID && h.RuleUID == ruleUID {
+			return h, nil
+		}
+	}
+	return nil, ngmodels.ErrHeartbeatNotFound
+}
+
+func (s *fakeHeartbeatStore) RecordHeartbeat(token string, receivedAt time.Time) (*ngmodels.AlertRuleHeartbeat, error) {
+	h, ok := s.heartbeats[token]
+	if !ok {
+		return nil, ngmodels.ErrHeartbeatNotFound
+	}
+	h.ReceivedAt = receivedAt.Unix()
+	return h, nil
+}
+
+func TestRoutePostHeartbeat(t *testing.T) {
+	now := time.Unix(1000, 0)
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() { timeNow = time.Now })
+
+	store := &fakeHeartbeatStore{heartbeats: map[string]*ngmodels.AlertRuleHeartbeat{
+		"token": {OrgID: 1, RuleUID: "rule", Token: "token"},
+	}}
+	srv := HeartbeatSrv{store: store, log: log.New("test")}
+	post := func(token string) int {
+		c := &models.ReqContext{Context: &macaron.Context{}}
+		c.ReplaceAllParams(macaron.Params{":Token": token})
+		return srv.RoutePostHeartbeat(c).Status()
+	}
+
+	require.Equal(t, http.StatusOK, post("token"))
+	require.Equal(t, now.Unix(), store.heartbeats["token"].ReceivedAt)
+	require.Equal(t, http.StatusNotFound, post("unknown"))
+}
+
+func TestHeartbeatApiEndpoints(t *testing.T) {
+	store := &fakeHeartbeatStore{heartbeats: map[string]*ngmodels.AlertRuleHeartbeat{
+		"token": {OrgID: 1, RuleUID: "rule", Token: "token"},
+	}}
+	api := &API{RouteRegister: routing.NewRouteRegister()}
+	api.RegisterHeartbeatApiEndpoints(HeartbeatSrv{store: store, log: log.New("test")}, metrics.NewMetrics(prometheus.NewRegistry()))
+
+	m := macaron.New()
+	m.Use(func(c *macaron.Context) {
+		c.Map(&models.ReqContext{Context: c, SignedInUser: &models.SignedInUser{}, IsSignedIn: false})
+	})
+	api.RouteRegister.Register(m.Router)
+
+	// The token is the secret of the route, it needs no sign in.
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/ngalert/heartbeat/token", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+}
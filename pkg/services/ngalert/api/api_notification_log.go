@@ -0,0 +1,26 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+)
+
+func (srv AlertmanagerSrv) RouteGetNotificationLog(c *models.ReqContext) response.Response {
+	am, errResp := srv.AlertmanagerFor(c.OrgId)
+	if errResp != nil {
+		return errResp
+	}
+
+	notificationLog, err := am.GetNotificationLog(c.QueryStrings("filter"), c.Query("receiver"))
+	if err != nil {
+		if errors.Is(err, notifier.ErrGetNotificationLogBadPayload) {
+			return ErrResp(http.StatusBadRequest, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the notification log")
+	}
+	return response.JSON(http.StatusOK, notificationLog)
+}
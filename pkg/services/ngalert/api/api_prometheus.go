@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
@@ -61,14 +62,25 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *models.ReqContext) response.Res
 		},
 	}
 
+	opts, err := parseRuleListOptions(c)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid parameters")
+	}
+	excludeAlerts := false
+	if c.Query("exclude_alerts") != "" {
+		if excludeAlerts, err = strconv.ParseBool(c.Query("exclude_alerts")); err != nil {
+			return ErrResp(http.StatusBadRequest, fmt.Errorf("exclude_alerts must be true or false"), "invalid parameters")
+		}
+	}
+
 	namespaceMap, err := srv.store.GetNamespaces(c.OrgId, c.SignedInUser)
 	if err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "failed to get namespaces visible to the user")
 	}
 
-	namespaceUIDs := make([]string, len(namespaceMap))
-	for k := range namespaceMap {
-		namespaceUIDs = append(namespaceUIDs, k)
+	namespaceUIDs := opts.namespaceUIDs(namespaceMap)
+	if len(namespaceUIDs) == 0 && len(opts.folderUIDs) > 0 {
+		return response.JSON(http.StatusOK, ruleResponse)
 	}
 
 	ruleGroupQuery := ngmodels.ListOrgRuleGroupsQuery{
@@ -82,11 +94,18 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *models.ReqContext) response.Res
 		return response.JSON(http.StatusInternalServerError, ruleResponse)
 	}
 
+	// listed is the number of rule groups that match the filters, on all the pages.
+	listed := 0
 	for _, r := range ruleGroupQuery.Result {
 		if len(r) < 3 {
 			continue
 		}
 		groupId, namespaceUID, namespace := r[0], r[1], r[2]
+		// Unless the rules are filtered, the rules of the groups that aren't in the page aren't loaded.
+		if !opts.filtersRules() && !opts.inPage(listed) {
+			listed++
+			continue
+		}
 		alertRuleQuery := ngmodels.ListRuleGroupAlertRulesQuery{OrgID: c.SignedInUser.OrgId, NamespaceUID: namespaceUID, RuleGroup: groupId}
 		if err := srv.store.GetRuleGroupAlertRules(&alertRuleQuery); err != nil {
 			ruleResponse.DiscoveryBase.Status = "error"
@@ -106,11 +125,13 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *models.ReqContext) response.Res
 
 		for _, rule := range alertRuleQuery.Result {
 			var queryStr string
-			encodedQuery, err := json.Marshal(rule.Data)
-			if err != nil {
-				queryStr = err.Error()
-			} else {
-				queryStr = string(encodedQuery)
+			if !opts.excludeQueries {
+				encodedQuery, err := json.Marshal(rule.Data)
+				if err != nil {
+					queryStr = err.Error()
+				} else {
+					queryStr = string(encodedQuery)
+				}
 			}
 			alertingRule := apimodels.AlertingRule{
 				State:       "inactive",
@@ -128,7 +149,14 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *models.ReqContext) response.Res
 				LastEvaluation: time.Time{},
 			}
 
-			for _, alertState := range srv.manager.GetStatesForRuleUID(c.OrgId, rule.UID) {
+			states := srv.manager.GetStatesForRuleUID(c.OrgId, rule.UID)
+			if opts.filtersRules() {
+				ruleState, health := ruleStateAndHealth(states)
+				if !opts.matches(rule, ruleState, health) {
+					continue
+				}
+			}
+			for _, alertState := range states {
 				activeAt := alertState.StartsAt
 				valString := ""
 				if len(alertState.Results) > 0 && alertState.State == eval.Alerting {
@@ -167,14 +195,33 @@ func (srv PrometheusSrv) RouteGetRuleStatuses(c *models.ReqContext) response.Res
 					newRule.LastError = alertState.Error.Error()
 					newRule.Health = "error"
 				}
-				alertingRule.Alerts = append(alertingRule.Alerts, alert)
+				if len(alertState.Warnings) > 0 {
+					newRule.Warnings = alertState.Warnings
+				}
+				if !excludeAlerts {
+					alertingRule.Alerts = append(alertingRule.Alerts, alert)
+				}
 			}
 
 			alertingRule.Rule = newRule
 			newGroup.Rules = append(newGroup.Rules, alertingRule)
 			newGroup.Interval = float64(rule.IntervalSeconds)
 		}
+		if opts.filtersRules() {
+			if len(newGroup.Rules) == 0 {
+				continue
+			}
+			listed++
+			if !opts.inPage(listed - 1) {
+				continue
+			}
+		} else {
+			listed++
+		}
 		ruleResponse.Data.RuleGroups = append(ruleResponse.Data.RuleGroups, newGroup)
 	}
+	if opts.limit > 0 {
+		ruleResponse.Data.Total = listed
+	}
 	return response.JSON(http.StatusOK, ruleResponse)
 }
@@ -0,0 +1,256 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+// The Prometheus compatible endpoints serve the Grafana managed rules and alerts in the exact schema of the
+// Prometheus HTTP API, unlike RouteGetRuleStatuses and RouteGetAlertStatuses which keep the states of Grafana for
+// the frontend.
+
+func (srv PrometheusSrv) RouteGetPrometheusCompatRules(c *models.ReqContext) response.Response {
+	ruleResponse := apimodels.PrometheusCompatRuleResponse{
+		DiscoveryBase: apimodels.DiscoveryBase{
+			Status: "success",
+		},
+		Data: apimodels.PrometheusCompatRuleDiscovery{
+			RuleGroups: []apimodels.PrometheusCompatRuleGroup{},
+		},
+	}
+	switch c.Query("type") {
+	case "", "alert":
+	case "record":
+		// The Grafana managed rules are all alerting rules.
+		return response.JSON(http.StatusOK, ruleResponse)
+	default:
+		ruleResponse.DiscoveryBase.Status = "error"
+		ruleResponse.DiscoveryBase.Error = fmt.Sprintf("unsupported type %q, must be alert or record", c.Query("type"))
+		ruleResponse.DiscoveryBase.ErrorType = apiv1.ErrBadData
+		return response.JSON(http.StatusBadRequest, ruleResponse)
+	}
+
+	rules, namespaces, err := srv.visibleRules(c)
+	if err != nil {
+		ruleResponse.DiscoveryBase.Status = "error"
+		ruleResponse.DiscoveryBase.Error = fmt.Sprintf("failure getting rules: %s", err.Error())
+		ruleResponse.DiscoveryBase.ErrorType = apiv1.ErrServer
+		return response.JSON(http.StatusInternalServerError, ruleResponse)
+	}
+	ruleResponse.Data.RuleGroups = toPrometheusCompatGroups(rules, namespaces, func(ruleUID string) []*state.State {
+		return srv.manager.GetStatesForRuleUID(c.OrgId, ruleUID)
+	})
+	return response.JSON(http.StatusOK, ruleResponse)
+}
+
+func (srv PrometheusSrv) RouteGetPrometheusCompatAlerts(c *models.ReqContext) response.Response {
+	alertResponse := apimodels.PrometheusCompatAlertResponse{
+		DiscoveryBase: apimodels.DiscoveryBase{
+			Status: "success",
+		},
+		Data: apimodels.PrometheusCompatAlertDiscovery{
+			Alerts: []apimodels.PrometheusCompatAlert{},
+		},
+	}
+	rules, _, err := srv.visibleRules(c)
+	if err != nil {
+		alertResponse.DiscoveryBase.Status = "error"
+		alertResponse.DiscoveryBase.Error = fmt.Sprintf("failure getting rules: %s", err.Error())
+		alertResponse.DiscoveryBase.ErrorType = apiv1.ErrServer
+		return response.JSON(http.StatusInternalServerError, alertResponse)
+	}
+	for _, r := range rules {
+		for _, s := range srv.manager.GetStatesForRuleUID(c.OrgId, r.UID) {
+			if alert, ok := toPrometheusCompatAlert(s, r.Condition); ok {
+				alertResponse.Data.Alerts = append(alertResponse.Data.Alerts, alert)
+			}
+		}
+	}
+	sortPrometheusCompatAlerts(alertResponse.Data.Alerts)
+	return response.JSON(http.StatusOK, alertResponse)
+}
+
+// visibleRules returns the rules in the folders the user can view, and the folders.
+func (srv PrometheusSrv) visibleRules(c *models.ReqContext) ([]*ngmodels.AlertRule, map[string]*models.Folder, error) {
+	namespaceMap, err := srv.store.GetNamespaces(c.OrgId, c.SignedInUser)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Without a namespace the query would return the rules of all folders.
+	if len(namespaceMap) == 0 {
+		return nil, namespaceMap, nil
+	}
+	namespaceUIDs := make([]string, 0, len(namespaceMap))
+	for uid := range namespaceMap {
+		namespaceUIDs = append(namespaceUIDs, uid)
+	}
+	q := ngmodels.ListAlertRulesQuery{OrgID: c.SignedInUser.OrgId, NamespaceUIDs: namespaceUIDs}
+	if err := srv.store.GetOrgAlertRules(&q); err != nil {
+		return nil, nil, err
+	}
+	return q.Result, namespaceMap, nil
+}
+
+// toPrometheusCompatGroups returns the Prometheus rule groups of the rules, ordered by folder title and group name.
+// The rules of a group are in the order they were created.
+func toPrometheusCompatGroups(rules []*ngmodels.AlertRule, namespaces map[string]*models.Folder, states func(ruleUID string) []*state.State) []apimodels.PrometheusCompatRuleGroup {
+	rules = append([]*ngmodels.AlertRule(nil), rules...)
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].ID < rules[j].ID
+	})
+
+	type groupKey struct{ namespaceUID, name string }
+	groups := make(map[groupKey]*apimodels.PrometheusCompatRuleGroup)
+	keys := make([]groupKey, 0)
+	for _, r := range rules {
+		namespace, ok := namespaces[r.NamespaceUID]
+		if !ok {
+			continue
+		}
+		key := groupKey{namespaceUID: r.NamespaceUID, name: r.RuleGroup}
+		g, ok := groups[key]
+		if !ok {
+			g = &apimodels.PrometheusCompatRuleGroup{
+				Name:     r.RuleGroup,
+				File:     namespace.Title,
+				Rules:    []apimodels.PrometheusCompatAlertingRule{},
+				Interval: float64(r.IntervalSeconds),
+			}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		rule := toPrometheusCompatRule(r, states(r.UID))
+		g.Rules = append(g.Rules, rule)
+		// The rules of a group are evaluated one after the other.
+		g.EvaluationTime += rule.EvaluationTime
+		if rule.LastEvaluation.After(g.LastEvaluation) {
+			g.LastEvaluation = rule.LastEvaluation
+		}
+	}
+
+	result := make([]apimodels.PrometheusCompatRuleGroup, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, *groups[key])
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].File != result[j].File {
+			return result[i].File < result[j].File
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+func toPrometheusCompatRule(r *ngmodels.AlertRule, states []*state.State) apimodels.PrometheusCompatAlertingRule {
+	query, ok := prometheusRuleExpr(r)
+	if !ok {
+		encodedQuery, err := json.Marshal(r.Data)
+		if err != nil {
+			query = err.Error()
+		} else {
+			query = string(encodedQuery)
+		}
+	}
+	rule := apimodels.PrometheusCompatAlertingRule{
+		State:       "inactive",
+		Name:        r.Title,
+		Query:       query,
+		Duration:    r.For.Seconds(),
+		Labels:      nonNilLabels(r.Labels),
+		Annotations: nonNilLabels(r.Annotations),
+		Alerts:      []apimodels.PrometheusCompatAlert{},
+		Health:      "unknown",
+		Type:        string(apiv1.RuleTypeAlerting),
+	}
+
+	failed := false
+	for _, s := range states {
+		if s.LastEvaluationTime.After(rule.LastEvaluation) {
+			rule.LastEvaluation = s.LastEvaluationTime
+			rule.EvaluationTime = s.EvaluationDuration.Seconds()
+		}
+		if s.State == eval.Error || s.Error != nil {
+			failed = true
+			if s.Error != nil {
+				rule.LastError = s.Error.Error()
+			}
+		}
+		alert, ok := toPrometheusCompatAlert(s, r.Condition)
+		if !ok {
+			continue
+		}
+		rule.Alerts = append(rule.Alerts, alert)
+		if alert.State == "firing" {
+			rule.State = "firing"
+		} else if rule.State == "inactive" {
+			rule.State = "pending"
+		}
+	}
+	// Prometheus has no health for the queries without data, they are evaluated successfully.
+	switch {
+	case failed:
+		rule.Health = "err"
+	case !rule.LastEvaluation.IsZero():
+		rule.Health = "ok"
+	}
+	sortPrometheusCompatAlerts(rule.Alerts)
+	return rule
+}
+
+// toPrometheusCompatAlert returns the Prometheus alert of the state, and false if it's neither pending nor firing.
+// The value is the value of the condition of the last evaluation, or its description for the classic conditions
+// which have no value.
+func toPrometheusCompatAlert(s *state.State, condition string) (apimodels.PrometheusCompatAlert, bool) {
+	var alertState string
+	switch s.State {
+	case eval.Pending:
+		alertState = "pending"
+	case eval.Alerting:
+		alertState = "firing"
+	default:
+		return apimodels.PrometheusCompatAlert{}, false
+	}
+	value := ""
+	if len(s.Results) > 0 {
+		last := s.Results[len(s.Results)-1]
+		value = last.EvaluationString
+		if v, ok := last.Values[condition]; ok && v.Value != nil {
+			value = strconv.FormatFloat(*v.Value, 'e', 10, 64)
+		}
+	}
+	activeAt := s.StartsAt
+	return apimodels.PrometheusCompatAlert{
+		Labels:      nonNilLabels(s.Labels),
+		Annotations: nonNilLabels(s.Annotations),
+		State:       alertState,
+		ActiveAt:    &activeAt,
+		Value:       value,
+	}, true
+}
+
+func sortPrometheusCompatAlerts(alerts []apimodels.PrometheusCompatAlert) {
+	sort.Slice(alerts, func(i, j int) bool {
+		return data.Labels(alerts[i].Labels).String() < data.Labels(alerts[j].Labels).String()
+	})
+}
+
+// nonNilLabels returns the labels, or empty labels instead of nil so they are encoded as an object like in Prometheus.
+func nonNilLabels(l map[string]string) map[string]string {
+	if l == nil {
+		return map[string]string{}
+	}
+	return l
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestToPrometheusCompatGroups(t *testing.T) {
+	promData, err := prometheusRuleData("up == 0", "prom-uid")
+	require.NoError(t, err)
+	rules := []*ngmodels.AlertRule{
+		{ID: 2, UID: "broken", Title: "Broken", Condition: "C", NamespaceUID: "folder", RuleGroup: "group", IntervalSeconds: 60},
+		{ID: 1, UID: "down", Title: "Down", Condition: prometheusConditionRefID, Data: promData, NamespaceUID: "folder", RuleGroup: "group", IntervalSeconds: 60, For: time.Minute, Labels: map[string]string{"severity": "critical"}},
+		{ID: 3, UID: "new", Title: "New", Condition: "C", NamespaceUID: "folder", RuleGroup: "another", IntervalSeconds: 10},
+		{ID: 4, UID: "hidden", Title: "Hidden", NamespaceUID: "other", RuleGroup: "group"},
+	}
+	namespaces := map[string]*models.Folder{"folder": {Uid: "folder", Title: "Folder"}}
+	evaluatedAt := time.Date(2021, 10, 14, 10, 15, 0, 0, time.UTC)
+	value := 2.0
+	states := map[string][]*state.State{
+		"down": {
+			{State: eval.Pending, Labels: data.Labels{"instance": "b"}, StartsAt: evaluatedAt, LastEvaluationTime: evaluatedAt, EvaluationDuration: time.Second},
+			{
+				State: eval.Alerting, Labels: data.Labels{"instance": "a"}, StartsAt: evaluatedAt, LastEvaluationTime: evaluatedAt, EvaluationDuration: time.Second,
+				Results: []state.Evaluation{{Values: map[string]state.EvaluationValue{prometheusConditionRefID: {Value: &value}}}},
+			},
+			{State: eval.Normal, Labels: data.Labels{"instance": "c"}, LastEvaluationTime: evaluatedAt, EvaluationDuration: time.Second},
+		},
+		"broken": {
+			{State: eval.Error, Error: errors.New("timeout"), LastEvaluationTime: evaluatedAt, EvaluationDuration: 2 * time.Second},
+		},
+	}
+
+	groups := toPrometheusCompatGroups(rules, namespaces, func(ruleUID string) []*state.State { return states[ruleUID] })
+	require.Len(t, groups, 2, "the rules in the folders the user can't view are not returned")
+	require.Equal(t, "another", groups[0].Name)
+	require.Equal(t, "unknown", groups[0].Rules[0].Health, "the rules that were not evaluated have an unknown health")
+
+	group := groups[1]
+	require.Equal(t, "Folder", group.File)
+	require.Equal(t, 60.0, group.Interval)
+	require.Equal(t, 3.0, group.EvaluationTime)
+	require.Equal(t, evaluatedAt, group.LastEvaluation)
+	require.Equal(t, []string{"Down", "Broken"}, []string{group.Rules[0].Name, group.Rules[1].Name})
+
+	down := group.Rules[0]
+	require.Equal(t, "up == 0", down.Query)
+	require.Equal(t, "firing", down.State)
+	require.Equal(t, "ok", down.Health)
+	require.Equal(t, 60.0, down.Duration)
+	require.Len(t, down.Alerts, 2, "the normal alerts are not returned")
+	require.Equal(t, "firing", down.Alerts[0].State)
+	require.Equal(t, "2.0000000000e+00", down.Alerts[0].Value)
+	require.Equal(t, "pending", down.Alerts[1].State)
+
+	broken := group.Rules[1]
+	require.Equal(t, "inactive", broken.State)
+	require.Equal(t, "err", broken.Health)
+	require.Equal(t, "timeout", broken.LastError)
+
+	// The fields are encoded as in Prometheus, without the empty ones being omitted.
+	encoded, err := json.Marshal(groups[0].Rules[0])
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"state": "inactive",
+		"name": "New",
+		"query": "null",
+		"duration": 0,
+		"labels": {},
+		"annotations": {},
+		"alerts": [],
+		"health": "unknown",
+		"evaluationTime": 0,
+		"lastEvaluation": "0001-01-01T00:00:00Z",
+		"type": "alerting"
+	}`, string(encoded))
+}
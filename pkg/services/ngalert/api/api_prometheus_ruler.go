@@ -0,0 +1,463 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func (srv RulerSrv) RouteGetPrometheusRules(c *models.ReqContext) response.Response {
+	namespaceMap, err := srv.store.GetNamespaces(c.OrgId, c.SignedInUser)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get namespaces visible to the user")
+	}
+
+	namespaceUIDs := make([]string, 0, len(namespaceMap))
+	for k := range namespaceMap {
+		namespaceUIDs = append(namespaceUIDs, k)
+	}
+
+	q := ngmodels.ListAlertRulesQuery{
+		OrgID:         c.SignedInUser.OrgId,
+		NamespaceUIDs: namespaceUIDs,
+	}
+	if err := srv.store.GetOrgAlertRules(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get alert rules")
+	}
+
+	rulesByNamespace := make(map[string][]*ngmodels.AlertRule)
+	for _, r := range q.Result {
+		folder, ok := namespaceMap[r.NamespaceUID]
+		if !ok {
+			srv.log.Error("namespace not visible to the user", "user", c.SignedInUser.UserId, "namespace", r.NamespaceUID, "rule", r.UID)
+			continue
+		}
+		rulesByNamespace[folder.Title] = append(rulesByNamespace[folder.Title], r)
+	}
+
+	result := apimodels.PrometheusNamespaceConfigResponse{}
+	for namespace, rules := range rulesByNamespace {
+		if groups := srv.toPrometheusGroups(namespace, rules); len(groups) > 0 {
+			result[namespace] = groups
+		}
+	}
+	return prometheusYAMLResp(http.StatusOK, result)
+}
+
+func (srv RulerSrv) RouteGetPrometheusNamespaceRules(c *models.ReqContext) response.Response {
+	namespaceTitle := c.Params(":Namespace")
+	namespace, err := srv.store.GetNamespaceByTitle(namespaceTitle, c.SignedInUser.OrgId, c.SignedInUser, false)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	q := ngmodels.ListNamespaceAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+	}
+	if err := srv.store.GetNamespaceAlertRules(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get namespace alert rules")
+	}
+
+	result := apimodels.PrometheusNamespaceConfigResponse{}
+	if groups := srv.toPrometheusGroups(namespaceTitle, q.Result); len(groups) > 0 {
+		result[namespaceTitle] = groups
+	}
+	return prometheusYAMLResp(http.StatusOK, result)
+}
+
+func (srv RulerSrv) RouteGetPrometheusRuleGroup(c *models.ReqContext) response.Response {
+	namespaceTitle := c.Params(":Namespace")
+	namespace, err := srv.store.GetNamespaceByTitle(namespaceTitle, c.SignedInUser.OrgId, c.SignedInUser, false)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	ruleGroup := c.Params(":Groupname")
+	q := ngmodels.ListRuleGroupAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+		RuleGroup:    ruleGroup,
+	}
+	if err := srv.store.GetRuleGroupAlertRules(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get group alert rules")
+	}
+	if len(q.Result) == 0 {
+		return ErrResp(http.StatusNotFound, ngmodels.ErrRuleGroupNamespaceNotFound, "")
+	}
+
+	groups, incompatible := grafanaRulesToPrometheusGroups(q.Result)
+	if err, ok := incompatible[ruleGroup]; ok {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	return prometheusYAMLResp(http.StatusOK, groups[0])
+}
+
+func (srv RulerSrv) RoutePostPrometheusRuleGroup(c *models.ReqContext) response.Response {
+	namespaceTitle := c.Params(":Namespace")
+	namespace, err := srv.store.GetNamespaceByTitle(namespaceTitle, c.SignedInUser.OrgId, c.SignedInUser, true)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	b, err := ioutil.ReadAll(c.Req.Body)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to read the rule group")
+	}
+	var group apimodels.PrometheusRuleGroup
+	if err := yaml.Unmarshal(b, &group); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to parse the rule group")
+	}
+	if group.Name == "" {
+		return ErrResp(http.StatusBadRequest, errors.New("rule group name is not valid"), "")
+	}
+
+	datasourceUID, err := srv.prometheusDatasourceUID(c, c.Req.Header.Get(apimodels.DatasourceUIDHeader))
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	q := ngmodels.ListRuleGroupAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+		RuleGroup:    group.Name,
+	}
+	if err := srv.store.GetRuleGroupAlertRules(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get group alert rules")
+	}
+	// Grafana managed rules are not overwritten by Prometheus rules, they can't be listed back.
+	if _, incompatible := grafanaRulesToPrometheusGroups(q.Result); len(incompatible) > 0 {
+		return ErrResp(http.StatusConflict, incompatible[group.Name], "rule group %q is not managed as Prometheus alerting rules", group.Name)
+	}
+
+	ruleGroupConfig, err := prometheusRuleGroupToGrafana(group, datasourceUID, q.Result)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to convert the rule group")
+	}
+	return srv.updateAlertRulesInGroup(c, namespace, ruleGroupConfig)
+}
+
+func (srv RulerSrv) RoutePostPrometheusImport(c *models.ReqContext) response.Response {
+	namespaceTitle := c.Params(":Namespace")
+	namespace, err := srv.store.GetNamespaceByTitle(namespaceTitle, c.SignedInUser.OrgId, c.SignedInUser, true)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	b, err := ioutil.ReadAll(c.Req.Body)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to read the rule file")
+	}
+	var file apimodels.PrometheusRuleFile
+	if err := yaml.Unmarshal(b, &file); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to parse the rule file")
+	}
+
+	datasourceUID, err := srv.prometheusDatasourceUID(c, c.Req.Header.Get(apimodels.DatasourceUIDHeader))
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	q := ngmodels.ListNamespaceAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+	}
+	if err := srv.store.GetNamespaceAlertRules(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get namespace alert rules")
+	}
+
+	ruleGroupConfigs, groups, err := prometheusRuleFileToGrafana(file, datasourceUID, q.Result)
+	if err != nil {
+		if errors.Is(err, errNotPrometheusCompatible) {
+			return ErrResp(http.StatusConflict, err, "")
+		}
+		return ErrResp(http.StatusBadRequest, err, "failed to convert the rule file")
+	}
+
+	replaced := make(map[string]struct{}, len(ruleGroupConfigs))
+	for _, g := range ruleGroupConfigs {
+		replaced[g.Name] = struct{}{}
+	}
+	replacedUIDs := make([]string, 0)
+	for _, r := range q.Result {
+		if _, ok := replaced[r.RuleGroup]; ok {
+			replacedUIDs = append(replacedUIDs, r.UID)
+		}
+	}
+	if errResp := provisionedErrResp(srv.checkRulesNotProvisioned(c, replacedUIDs)); errResp != nil {
+		return errResp
+	}
+
+	numOfNewRules := 0
+	updatedUIDs := make([]string, 0)
+	cmds := make([]store.UpdateRuleGroupCmd, 0, len(ruleGroupConfigs))
+	for _, ruleGroupConfig := range ruleGroupConfigs {
+		for _, r := range ruleGroupConfig.Rules {
+			cond := ngmodels.Condition{
+				Condition: r.GrafanaManagedAlert.Condition,
+				OrgID:     c.SignedInUser.OrgId,
+				Data:      r.GrafanaManagedAlert.Data,
+			}
+			if err := validateCondition(cond, c.SignedInUser, c.SkipCache, srv.DatasourceCache); err != nil {
+				return ErrResp(http.StatusBadRequest, err, "failed to validate alert rule %q", r.GrafanaManagedAlert.Title)
+			}
+			if r.GrafanaManagedAlert.UID == "" {
+				numOfNewRules++
+			} else {
+				updatedUIDs = append(updatedUIDs, r.GrafanaManagedAlert.UID)
+			}
+		}
+		cmds = append(cmds, store.UpdateRuleGroupCmd{
+			OrgID:           c.SignedInUser.OrgId,
+			NamespaceUID:    namespace.Uid,
+			RuleGroupConfig: ruleGroupConfig,
+			UpdatedBy:       c.SignedInUser.Login,
+			UpdatedByUserID: c.SignedInUser.UserId,
+		})
+	}
+
+	// like for the rule groups updated one at a time, the quotas are checked in advance
+	if errResp := srv.checkRuleQuota(c, numOfNewRules); errResp != nil {
+		return errResp
+	}
+	existingGroups := make(map[string]struct{})
+	for _, r := range q.Result {
+		existingGroups[r.RuleGroup] = struct{}{}
+	}
+	numOfNewGroups := 0
+	for _, g := range ruleGroupConfigs {
+		if _, ok := existingGroups[g.Name]; !ok && len(g.Rules) > 0 {
+			numOfNewGroups++
+		}
+	}
+	if errResp := quotaErrResp(srv.QuotaService.CheckQuota(c, quotaTargetAlertRuleGroup, int64(numOfNewGroups))); errResp != nil {
+		return errResp
+	}
+
+	result := apimodels.PrometheusImportResult{DryRun: c.QueryBool("dryRun"), Groups: groups}
+	if result.DryRun {
+		return response.JSON(http.StatusOK, result)
+	}
+
+	// All the groups are imported in a single transaction, a failing group leaves the namespace unchanged.
+	if err := srv.store.UpdateRuleGroups(cmds); err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) || errors.Is(err, ngmodels.ErrAlertRuleUniqueConstraintViolation) {
+			return ErrResp(http.StatusBadRequest, err, "failed to import the rule file")
+		}
+		if errors.Is(err, ngmodels.ErrAlertRuleVersionConflict) {
+			return ErrResp(http.StatusConflict, err, "failed to import the rule file")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to import the rule file")
+	}
+
+	for _, uid := range updatedUIDs {
+		srv.manager.RemoveByRuleUID(c.OrgId, uid)
+	}
+
+	return response.JSON(http.StatusAccepted, result)
+}
+
+func (srv RulerSrv) RouteDeletePrometheusNamespaceRules(c *models.ReqContext) response.Response {
+	namespaceTitle := c.Params(":Namespace")
+	namespace, err := srv.store.GetNamespaceByTitle(namespaceTitle, c.SignedInUser.OrgId, c.SignedInUser, true)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	q := ngmodels.ListNamespaceAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+	}
+	if err := srv.store.GetNamespaceAlertRules(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get namespace alert rules")
+	}
+
+	if errResp := provisionedErrResp(srv.checkRulesNotProvisioned(c, ruleUIDs(q.Result))); errResp != nil {
+		return errResp
+	}
+
+	// Only the groups that can be listed are deleted, the other Grafana managed rules of the folder are kept.
+	groups, _ := grafanaRulesToPrometheusGroups(q.Result)
+	for _, g := range groups {
+		uids, err := srv.store.DeleteRuleGroupAlertRules(c.SignedInUser.OrgId, namespace.Uid, g.Name)
+		if err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "failed to delete rule group %q", g.Name)
+		}
+		for _, uid := range uids {
+			srv.manager.RemoveByRuleUID(c.SignedInUser.OrgId, uid)
+		}
+	}
+
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": "namespace rules deleted"})
+}
+
+func (srv RulerSrv) RouteDeletePrometheusRuleGroup(c *models.ReqContext) response.Response {
+	return srv.RouteDeleteRuleGroupConfig(c)
+}
+
+// toPrometheusGroups converts the rules of a namespace to Prometheus rule groups, skipping the groups
+// that can't be represented as Prometheus alerting rules.
+func (srv RulerSrv) toPrometheusGroups(namespace string, rules []*ngmodels.AlertRule) []apimodels.PrometheusRuleGroup {
+	groups, incompatible := grafanaRulesToPrometheusGroups(rules)
+	for group, err := range incompatible {
+		srv.log.Debug("skipping rule group that is not compatible with Prometheus", "namespace", namespace, "group", group, "err", err)
+	}
+	return groups
+}
+
+// prometheusDatasourceUID returns the UID of the Prometheus datasource queried by the rules created from Prometheus
+// alerting rules. It is the datasource of the X-Grafana-Alerting-Datasource-UID header, or the default datasource
+// of the organization for clients that can't set headers.
+// prometheusDatasourceUID returns the UID of the Prometheus datasource with the UID, or of the default datasource
+// of the organization when the UID is empty.
+func (srv RulerSrv) prometheusDatasourceUID(c *models.ReqContext, uid string) (string, error) {
+	var ds *models.DataSource
+	if uid != "" {
+		var err error
+		ds, err = srv.DatasourceCache.GetDatasourceByUID(uid, c.SignedInUser, c.SkipCache)
+		if err != nil {
+			return "", fmt.Errorf("failed to get datasource %q: %w", uid, err)
+		}
+	} else {
+		q := models.GetDefaultDataSourceQuery{OrgId: c.OrgId, User: c.SignedInUser}
+		if err := bus.Dispatch(&q); err != nil {
+			return "", fmt.Errorf("failed to get the default datasource, set the %s header: %w", apimodels.DatasourceUIDHeader, err)
+		}
+		ds = q.Result
+	}
+
+	if ds.Type != models.DS_PROMETHEUS {
+		return "", fmt.Errorf("datasource %q is not a Prometheus datasource", ds.Name)
+	}
+	return ds.Uid, nil
+}
+
+func prometheusYAMLResp(status int, body interface{}) response.Response {
+	b, err := yaml.Marshal(body)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to marshal the rules")
+	}
+	return response.Respond(status, b).SetHeader("Content-Type", "application/yaml")
+}
+
+func (srv RulerSrv) RouteGetPrometheusExport(c *models.ReqContext) response.Response {
+	namespaceMap, err := srv.store.GetNamespaces(c.OrgId, c.SignedInUser)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get namespaces visible to the user")
+	}
+
+	namespaceUIDs := make([]string, 0, len(namespaceMap))
+	for k := range namespaceMap {
+		namespaceUIDs = append(namespaceUIDs, k)
+	}
+
+	q := ngmodels.ListAlertRulesQuery{
+		OrgID:         c.SignedInUser.OrgId,
+		NamespaceUIDs: namespaceUIDs,
+	}
+	if err := srv.store.GetOrgAlertRules(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get alert rules")
+	}
+
+	rulesByNamespace := make(map[string][]*ngmodels.AlertRule)
+	namespaces := make([]string, 0)
+	for _, r := range q.Result {
+		folder, ok := namespaceMap[r.NamespaceUID]
+		if !ok {
+			srv.log.Error("namespace not visible to the user", "user", c.SignedInUser.UserId, "namespace", r.NamespaceUID, "rule", r.UID)
+			continue
+		}
+		if _, ok := rulesByNamespace[folder.Title]; !ok {
+			namespaces = append(namespaces, folder.Title)
+		}
+		rulesByNamespace[folder.Title] = append(rulesByNamespace[folder.Title], r)
+	}
+	sort.Strings(namespaces)
+
+	datasourceType := srv.datasourceTypes(c)
+	result := &yaml.Node{Kind: yaml.MappingNode}
+	for _, namespace := range namespaces {
+		file, err := exportPrometheusRuleGroups(rulesByNamespace[namespace], datasourceType)
+		if err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "failed to export the rules of namespace %q", namespace)
+		}
+		result.Content = append(result.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: namespace}, file)
+	}
+	return prometheusYAMLResp(http.StatusOK, result)
+}
+
+func (srv RulerSrv) RouteGetPrometheusNamespaceExport(c *models.ReqContext) response.Response {
+	namespaceTitle := c.Params(":Namespace")
+	namespace, err := srv.store.GetNamespaceByTitle(namespaceTitle, c.SignedInUser.OrgId, c.SignedInUser, false)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	q := ngmodels.ListNamespaceAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+	}
+	if err := srv.store.GetNamespaceAlertRules(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get namespace alert rules")
+	}
+
+	file, err := exportPrometheusRuleGroups(q.Result, srv.datasourceTypes(c))
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to export the rules")
+	}
+	return prometheusYAMLResp(http.StatusOK, file)
+}
+
+func (srv RulerSrv) RouteGetPrometheusRuleGroupExport(c *models.ReqContext) response.Response {
+	namespaceTitle := c.Params(":Namespace")
+	namespace, err := srv.store.GetNamespaceByTitle(namespaceTitle, c.SignedInUser.OrgId, c.SignedInUser, false)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	q := ngmodels.ListRuleGroupAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+		RuleGroup:    c.Params(":Groupname"),
+	}
+	if err := srv.store.GetRuleGroupAlertRules(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get group alert rules")
+	}
+	if len(q.Result) == 0 {
+		return ErrResp(http.StatusNotFound, ngmodels.ErrRuleGroupNamespaceNotFound, "")
+	}
+
+	file, err := exportPrometheusRuleGroups(q.Result, srv.datasourceTypes(c))
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to export the rules")
+	}
+	return prometheusYAMLResp(http.StatusOK, file)
+}
+
+// datasourceTypes returns a function returning the type of the datasources visible to the user.
+func (srv RulerSrv) datasourceTypes(c *models.ReqContext) func(uid string) string {
+	types := make(map[string]string)
+	return func(uid string) string {
+		if t, ok := types[uid]; ok {
+			return t
+		}
+		t := "unknown"
+		if ds, err := srv.DatasourceCache.GetDatasourceByUID(uid, c.SignedInUser, c.SkipCache); err == nil {
+			t = ds.Type
+		}
+		types[uid] = t
+		return t
+	}
+}
@@ -0,0 +1,907 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// ProvisioningSrv implements the provisioning API, managing alerting resources by stable identifiers with
+// idempotent requests. Every change sets the provenance of the resource to ngmodels.ProvenanceAPI, or to the
+// provenance of the ProvenanceHeader. The resources provisioned by other means can't be changed.
+type ProvisioningSrv struct {
+	log             log.Logger
+	alertRules      *provisioning.AlertRuleService
+	amConfigs       *provisioning.AlertmanagerConfigService
+	muteTimings     *provisioning.MuteTimingService
+	ruleStore       store.RuleStore
+	DatasourceCache datasources.CacheService
+	QuotaService    *quota.QuotaService
+	manager         *state.Manager
+}
+
+func (srv ProvisioningSrv) RouteGetAlertRule(c *models.ReqContext) response.Response {
+	rule, provenance, err := srv.alertRules.GetAlertRule(c.OrgId, c.Params(":UID"))
+	if err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to get alert rule")
+	}
+	return response.JSON(http.StatusOK, toProvisionedAlertRule(rule, provenance))
+}
+
+func (srv ProvisioningSrv) RoutePostAlertRule(c *models.ReqContext, body apimodels.ProvisionedAlertRule) response.Response {
+	if body.UID != "" {
+		_, _, err := srv.alertRules.GetAlertRule(c.OrgId, body.UID)
+		if err == nil {
+			return ErrResp(http.StatusConflict, fmt.Errorf("alert rule %q already exists", body.UID), "")
+		}
+		if !errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+			return ErrResp(http.StatusInternalServerError, err, "failed to get alert rule")
+		}
+	}
+	return srv.saveAlertRule(c, body, true, http.StatusCreated)
+}
+
+func (srv ProvisioningSrv) RoutePutAlertRule(c *models.ReqContext, body apimodels.ProvisionedAlertRule) response.Response {
+	uid := c.Params(":UID")
+	if body.UID != "" && body.UID != uid {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("the UID of the rule %q doesn't match the UID of the path %q", body.UID, uid), "")
+	}
+	body.UID = uid
+
+	_, provenance, err := srv.alertRules.GetAlertRule(c.OrgId, uid)
+	switch {
+	case errors.Is(err, ngmodels.ErrAlertRuleNotFound):
+		return srv.saveAlertRule(c, body, true, http.StatusOK)
+	case err != nil:
+		return ErrResp(http.StatusInternalServerError, err, "failed to get alert rule")
+	}
+	if errResp := provisionedErrResp(checkProvisionedByAPI(c, provenance, "alert rule", uid)); errResp != nil {
+		return errResp
+	}
+	return srv.saveAlertRule(c, body, false, http.StatusOK)
+}
+
+// saveAlertRule validates and saves the rule of the request, the quota is checked for new rules.
+func (srv ProvisioningSrv) saveAlertRule(c *models.ReqContext, body apimodels.ProvisionedAlertRule, isNew bool, status int) response.Response {
+	newProvenance, err := provenanceFromRequest(c)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	if body.FolderUID == "" || body.RuleGroup == "" {
+		return ErrResp(http.StatusBadRequest, errors.New("the folder and the rule group of the rule are required"), "")
+	}
+	if _, err := srv.ruleStore.GetNamespaceByUID(body.FolderUID, c.OrgId, c.SignedInUser, true); err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	rule := fromProvisionedAlertRule(c.OrgId, body)
+	cond := ngmodels.Condition{Condition: rule.Condition, OrgID: c.OrgId, Data: rule.Data}
+	if err := validateCondition(cond, c.SignedInUser, c.SkipCache, srv.DatasourceCache); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to validate alert rule %q", rule.Title)
+	}
+
+	if isNew {
+		if errResp := quotaErrResp(srv.QuotaService.CheckQuota(c, quotaTargetAlertRule, 1)); errResp != nil {
+			return errResp
+		}
+		groupRules, _, err := srv.alertRules.GetRuleGroup(c.OrgId, rule.NamespaceUID, rule.RuleGroup)
+		if err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "failed to get rule group")
+		}
+		if len(groupRules) == 0 {
+			if errResp := quotaErrResp(srv.QuotaService.CheckQuota(c, quotaTargetAlertRuleGroup, 1)); errResp != nil {
+				return errResp
+			}
+		}
+	}
+
+	saved, err := srv.alertRules.SaveAlertRule(rule, newProvenance, c.SignedInUser.Login, c.SignedInUser.UserId)
+	if err != nil {
+		return alertRuleErrResp(err, "failed to save alert rule")
+	}
+	srv.manager.RemoveByRuleUID(c.OrgId, saved.UID)
+	return response.JSON(status, toProvisionedAlertRule(saved, newProvenance))
+}
+
+func (srv ProvisioningSrv) RouteDeleteAlertRule(c *models.ReqContext) response.Response {
+	uid := c.Params(":UID")
+	_, provenance, err := srv.alertRules.GetAlertRule(c.OrgId, uid)
+	switch {
+	case errors.Is(err, ngmodels.ErrAlertRuleNotFound):
+		return response.JSON(http.StatusOK, util.DynMap{"message": "alert rule deleted"})
+	case err != nil:
+		return ErrResp(http.StatusInternalServerError, err, "failed to get alert rule")
+	}
+	if errResp := provisionedErrResp(checkProvisionedByAPI(c, provenance, "alert rule", uid)); errResp != nil {
+		return errResp
+	}
+
+	if err := srv.alertRules.DeleteAlertRule(c.OrgId, uid); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to delete alert rule")
+	}
+	srv.manager.RemoveByRuleUID(c.OrgId, uid)
+	return response.JSON(http.StatusOK, util.DynMap{"message": "alert rule deleted"})
+}
+
+func (srv ProvisioningSrv) RouteGetAlertRuleGroup(c *models.ReqContext) response.Response {
+	folderUID, group := c.Params(":FolderUID"), c.Params(":Group")
+	if _, err := srv.ruleStore.GetNamespaceByUID(folderUID, c.OrgId, c.SignedInUser, false); err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	rules, provenances, err := srv.alertRules.GetRuleGroup(c.OrgId, folderUID, group)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get rule group")
+	}
+	if len(rules) == 0 {
+		return ErrResp(http.StatusNotFound, fmt.Errorf("rule group %q not found", group), "")
+	}
+	return response.JSON(http.StatusOK, toAlertRuleGroup(folderUID, group, rules, provenances))
+}
+
+func (srv ProvisioningSrv) RoutePutAlertRuleGroup(c *models.ReqContext, body apimodels.AlertRuleGroup) response.Response {
+	newProvenance, err := provenanceFromRequest(c)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	folderUID, group := c.Params(":FolderUID"), c.Params(":Group")
+	if _, err := srv.ruleStore.GetNamespaceByUID(folderUID, c.OrgId, c.SignedInUser, true); err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+	if errResp := srv.replaceRuleGroup(c, folderUID, group, body, newProvenance); errResp != nil {
+		return errResp
+	}
+
+	rules, provenances, err := srv.alertRules.GetRuleGroup(c.OrgId, folderUID, group)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get rule group")
+	}
+	return response.JSON(http.StatusOK, toAlertRuleGroup(folderUID, group, rules, provenances))
+}
+
+// replaceRuleGroup validates the rules of the body and replaces the rule group of the folder with them, the rules
+// of other rule groups can't be moved to it. The quotas are checked for the new rules, the user must have been
+// checked to be allowed to edit the rules of the folder.
+func (srv ProvisioningSrv) replaceRuleGroup(c *models.ReqContext, folderUID, group string, body apimodels.AlertRuleGroup, newProvenance ngmodels.Provenance) response.Response {
+	if body.Interval <= 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("the interval of the rule group must be positive"), "")
+	}
+
+	existing, provenances, err := srv.alertRules.GetRuleGroup(c.OrgId, folderUID, group)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get rule group")
+	}
+	inGroup := make(map[string]struct{}, len(existing))
+	for _, r := range existing {
+		inGroup[r.UID] = struct{}{}
+		if errResp := provisionedErrResp(checkProvisionedByAPI(c, provenances[r.UID], "alert rule", r.UID)); errResp != nil {
+			return errResp
+		}
+	}
+
+	seen := make(map[string]struct{}, len(body.Rules))
+	groupConfig := apimodels.PostableRuleGroupConfig{
+		Name:     group,
+		Interval: model.Duration(time.Duration(body.Interval) * time.Second),
+	}
+	for _, r := range body.Rules {
+		if r.UID == "" {
+			r.UID = util.GenerateShortUID()
+		}
+		if _, ok := seen[r.UID]; ok {
+			return ErrResp(http.StatusBadRequest, fmt.Errorf("conflicting UID %q found", r.UID), "failed to validate alert rule %q", r.Title)
+		}
+		seen[r.UID] = struct{}{}
+
+		if _, ok := inGroup[r.UID]; !ok {
+			// The rules of other groups can't be moved to this one.
+			_, _, err := srv.alertRules.GetAlertRule(c.OrgId, r.UID)
+			switch {
+			case err == nil:
+				return ErrResp(http.StatusBadRequest, fmt.Errorf("alert rule %q belongs to another rule group", r.UID), "")
+			case !errors.Is(err, ngmodels.ErrAlertRuleNotFound):
+				return ErrResp(http.StatusInternalServerError, err, "failed to get alert rule")
+			}
+		}
+
+		rule := fromProvisionedAlertRule(c.OrgId, r)
+		cond := ngmodels.Condition{Condition: rule.Condition, OrgID: c.OrgId, Data: rule.Data}
+		if err := validateCondition(cond, c.SignedInUser, c.SkipCache, srv.DatasourceCache); err != nil {
+			return ErrResp(http.StatusBadRequest, err, "failed to validate alert rule %q", rule.Title)
+		}
+		groupConfig.Rules = append(groupConfig.Rules, toPostableExtendedRuleNode(rule))
+	}
+
+	if errResp := quotaErrResp(srv.QuotaService.CheckQuota(c, quotaTargetAlertRule, int64(len(seen)-len(inGroup)))); errResp != nil {
+		return errResp
+	}
+	if len(existing) == 0 && len(seen) > 0 {
+		if errResp := quotaErrResp(srv.QuotaService.CheckQuota(c, quotaTargetAlertRuleGroup, 1)); errResp != nil {
+			return errResp
+		}
+	}
+
+	if err := srv.alertRules.ReplaceRuleGroup(c.OrgId, folderUID, groupConfig, newProvenance, c.SignedInUser.Login, c.SignedInUser.UserId); err != nil {
+		return alertRuleErrResp(err, "failed to update rule group")
+	}
+	for uid := range inGroup {
+		srv.manager.RemoveByRuleUID(c.OrgId, uid)
+	}
+	for uid := range seen {
+		srv.manager.RemoveByRuleUID(c.OrgId, uid)
+	}
+	return nil
+}
+
+func (srv ProvisioningSrv) RouteGetContactPoints(c *models.ReqContext) response.Response {
+	receivers, provenances, err := srv.amConfigs.GetContactPoints(c.OrgId)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get contact points")
+	}
+	result := make(apimodels.GettableContactPoints, 0, len(receivers))
+	for _, r := range receivers {
+		result = append(result, toGettableContactPoint(r, provenances[r.Name]))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv ProvisioningSrv) RoutePutContactPoint(c *models.ReqContext, body apimodels.PostableContactPoint) response.Response {
+	newProvenance, err := provenanceFromRequest(c)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	name := c.Params(":Name")
+	if len(body.Receivers) == 0 {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("contact point %q has no receivers", name), "")
+	}
+
+	receivers, provenances, err := srv.amConfigs.GetContactPoints(c.OrgId)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get contact points")
+	}
+	if errResp := provisionedErrResp(checkProvisionedByAPI(c, provenances[name], "contact point", name)); errResp != nil {
+		return errResp
+	}
+
+	contactPoint := &apimodels.PostableApiReceiver{
+		Receiver: config.Receiver{Name: name},
+		PostableGrafanaReceivers: apimodels.PostableGrafanaReceivers{
+			GrafanaManagedReceivers: body.Receivers,
+		},
+	}
+	if current := findContactPoint(receivers, name); current != nil {
+		keepReceiverUIDs(current, contactPoint)
+	} else if errResp := quotaErrResp(srv.QuotaService.CheckQuotaWithUsage(c, quotaTargetContactPoint, int64(len(receivers)), 1)); errResp != nil {
+		return errResp
+	}
+	for _, r := range contactPoint.GrafanaManagedReceivers {
+		if r.Name == "" {
+			r.Name = name
+		}
+	}
+
+	err = srv.amConfigs.Apply(c.OrgId, provisioning.AlertmanagerConfigChanges{
+		ContactPoints: []*apimodels.PostableApiReceiver{contactPoint},
+	}, newProvenance)
+	if err != nil {
+		return amConfigErrResp(err, "failed to save contact point")
+	}
+
+	receivers, _, err = srv.amConfigs.GetContactPoints(c.OrgId)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get contact points")
+	}
+	saved := findContactPoint(receivers, name)
+	if saved == nil {
+		return ErrResp(http.StatusInternalServerError, fmt.Errorf("contact point %q not found after saving it", name), "")
+	}
+	return response.JSON(http.StatusOK, toGettableContactPoint(saved, newProvenance))
+}
+
+func (srv ProvisioningSrv) RouteDeleteContactPoint(c *models.ReqContext) response.Response {
+	name := c.Params(":Name")
+	receivers, provenances, err := srv.amConfigs.GetContactPoints(c.OrgId)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get contact points")
+	}
+	if findContactPoint(receivers, name) == nil {
+		return response.JSON(http.StatusOK, util.DynMap{"message": "contact point deleted"})
+	}
+	if errResp := provisionedErrResp(checkProvisionedByAPI(c, provenances[name], "contact point", name)); errResp != nil {
+		return errResp
+	}
+
+	err = srv.amConfigs.Apply(c.OrgId, provisioning.AlertmanagerConfigChanges{DeleteContactPoints: []string{name}}, ngmodels.ProvenanceAPI)
+	if err != nil {
+		return amConfigErrResp(err, "failed to delete contact point")
+	}
+	return response.JSON(http.StatusOK, util.DynMap{"message": "contact point deleted"})
+}
+
+func (srv ProvisioningSrv) RouteGetContactPointUsage(c *models.ReqContext) response.Response {
+	rules, errResp := srv.orgAlertRules(c)
+	if errResp != nil {
+		return errResp
+	}
+	usage, err := srv.amConfigs.GetContactPointUsage(c.OrgId, c.Params(":Name"), rules)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the usage of the contact point")
+	}
+	result := apimodels.ContactPointUsage{
+		Routes:      usage.Routes,
+		Escalations: usage.Escalations,
+		Rules:       toRuleReferences(usage.Rules),
+	}
+	if result.Routes == nil {
+		result.Routes = []string{}
+	}
+	if result.Escalations == nil {
+		result.Escalations = []string{}
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv ProvisioningSrv) RouteGetPolicyTree(c *models.ReqContext) response.Response {
+	route, provenance, err := srv.amConfigs.GetPolicies(c.OrgId)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get notification policies")
+	}
+	return response.JSON(http.StatusOK, apimodels.NotificationPolicyTree{Route: route, Provenance: provenance})
+}
+
+func (srv ProvisioningSrv) RoutePutPolicyTree(c *models.ReqContext, body apimodels.NotificationPolicyTree) response.Response {
+	newProvenance, err := provenanceFromRequest(c)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	route, err := validatedRoute(body.Route)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid notification policies")
+	}
+
+	_, provenance, err := srv.amConfigs.GetPolicies(c.OrgId)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get notification policies")
+	}
+	if errResp := provisionedErrResp(checkProvisionedByAPI(c, provenance, "notification policy tree", ngmodels.NotificationPolicyResourceKey)); errResp != nil {
+		return errResp
+	}
+
+	if err := srv.amConfigs.Apply(c.OrgId, provisioning.AlertmanagerConfigChanges{Policies: route}, newProvenance); err != nil {
+		return amConfigErrResp(err, "failed to save notification policies")
+	}
+	return response.JSON(http.StatusOK, apimodels.NotificationPolicyTree{Route: route, Provenance: newProvenance})
+}
+
+func (srv ProvisioningSrv) RouteResetPolicyTree(c *models.ReqContext) response.Response {
+	_, provenance, err := srv.amConfigs.GetPolicies(c.OrgId)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get notification policies")
+	}
+	if errResp := provisionedErrResp(checkProvisionedByAPI(c, provenance, "notification policy tree", ngmodels.NotificationPolicyResourceKey)); errResp != nil {
+		return errResp
+	}
+
+	if err := srv.amConfigs.Apply(c.OrgId, provisioning.AlertmanagerConfigChanges{ResetPolicies: true}, ngmodels.ProvenanceAPI); err != nil {
+		return amConfigErrResp(err, "failed to reset notification policies")
+	}
+	return response.JSON(http.StatusOK, util.DynMap{"message": "notification policies reset"})
+}
+
+func (srv ProvisioningSrv) RouteGetPolicyRoutes(c *models.ReqContext) response.Response {
+	routes, provenance, err := srv.amConfigs.GetRoutes(c.OrgId)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get notification policies")
+	}
+	result := make(apimodels.ProvisionedRoutes, 0, len(routes))
+	for _, r := range routes {
+		route := *r.Route
+		route.Routes = nil
+		r.Route = &route
+		result = append(result, toProvisionedRoute(&r, provenance))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv ProvisioningSrv) RouteGetPolicyRoute(c *models.ReqContext) response.Response {
+	id := c.Params(":RouteID")
+	routes, provenance, err := srv.amConfigs.GetRoutes(c.OrgId)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get notification policies")
+	}
+	for _, r := range routes {
+		if r.ID == id {
+			return response.JSON(http.StatusOK, toProvisionedRoute(&r, provenance))
+		}
+	}
+	return ErrResp(http.StatusNotFound, fmt.Errorf("%w: %s", provisioning.ErrRouteNotFound, id), "")
+}
+
+func (srv ProvisioningSrv) RoutePutPolicyRoute(c *models.ReqContext, body apimodels.PostableRoute) response.Response {
+	route, err := validatedChildRoute(body.Route)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid notification policy")
+	}
+	if body.ResolvedNotifications != nil {
+		if err := body.ResolvedNotifications.Validate(); err != nil {
+			return ErrResp(http.StatusBadRequest, err, "invalid notification policy")
+		}
+	}
+	newProvenance, errResp := srv.policyTreeProvenance(c)
+	if errResp != nil {
+		return errResp
+	}
+	updated, err := srv.amConfigs.UpdateRoute(c.OrgId, c.Params(":RouteID"), body.Version, route, body.ResolvedNotifications, newProvenance)
+	if err != nil {
+		return amConfigErrResp(err, "failed to save notification policy")
+	}
+	return response.JSON(http.StatusOK, toProvisionedRoute(updated, newProvenance))
+}
+
+func (srv ProvisioningSrv) RouteDeletePolicyRoute(c *models.ReqContext) response.Response {
+	newProvenance, errResp := srv.policyTreeProvenance(c)
+	if errResp != nil {
+		return errResp
+	}
+	if err := srv.amConfigs.DeleteRoute(c.OrgId, c.Params(":RouteID"), c.Query("version"), newProvenance); err != nil {
+		return amConfigErrResp(err, "failed to delete notification policy")
+	}
+	return response.JSON(http.StatusOK, util.DynMap{"message": "notification policy deleted"})
+}
+
+func (srv ProvisioningSrv) RoutePostPolicyRoute(c *models.ReqContext, body apimodels.PostableRoute) response.Response {
+	route, err := validatedChildRoute(body.Route)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid notification policy")
+	}
+	if body.ResolvedNotifications != nil {
+		if err := body.ResolvedNotifications.Validate(); err != nil {
+			return ErrResp(http.StatusBadRequest, err, "invalid notification policy")
+		}
+	}
+	newProvenance, errResp := srv.policyTreeProvenance(c)
+	if errResp != nil {
+		return errResp
+	}
+	created, err := srv.amConfigs.CreateRoute(c.OrgId, c.Params(":RouteID"), body.Version, route, body.ResolvedNotifications, routeIndex(body.Index), newProvenance)
+	if err != nil {
+		return amConfigErrResp(err, "failed to create notification policy")
+	}
+	return response.JSON(http.StatusCreated, toProvisionedRoute(created, newProvenance))
+}
+
+func (srv ProvisioningSrv) RouteMovePolicyRoute(c *models.ReqContext, body apimodels.PostableRouteMove) response.Response {
+	if body.ParentID == "" {
+		return ErrResp(http.StatusBadRequest, errors.New("the new parent of the notification policy is missing"), "")
+	}
+	newProvenance, errResp := srv.policyTreeProvenance(c)
+	if errResp != nil {
+		return errResp
+	}
+	moved, err := srv.amConfigs.MoveRoute(c.OrgId, c.Params(":RouteID"), body.Version, body.ParentID, routeIndex(body.Index), newProvenance)
+	if err != nil {
+		return amConfigErrResp(err, "failed to move notification policy")
+	}
+	return response.JSON(http.StatusOK, toProvisionedRoute(moved, newProvenance))
+}
+
+// policyTreeProvenance returns the provenance of the request, or an error response if the notification policy
+// tree can't be changed through the API.
+func (srv ProvisioningSrv) policyTreeProvenance(c *models.ReqContext) (ngmodels.Provenance, response.Response) {
+	newProvenance, err := provenanceFromRequest(c)
+	if err != nil {
+		return "", ErrResp(http.StatusBadRequest, err, "")
+	}
+	_, provenance, err := srv.amConfigs.GetPolicies(c.OrgId)
+	if err != nil {
+		return "", ErrResp(http.StatusInternalServerError, err, "failed to get notification policies")
+	}
+	if errResp := provisionedErrResp(checkProvisionedByAPI(c, provenance, "notification policy tree", ngmodels.NotificationPolicyResourceKey)); errResp != nil {
+		return "", errResp
+	}
+	return newProvenance, nil
+}
+
+func (srv ProvisioningSrv) RouteGetMuteTimings(c *models.ReqContext) response.Response {
+	muteTimings, provenances, err := srv.muteTimings.GetMuteTimings(c.OrgId)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get mute timings")
+	}
+	result := make(apimodels.ProvisionedMuteTimings, 0, len(muteTimings))
+	for _, rs := range muteTimings {
+		result = append(result, apimodels.ProvisionedMuteTiming{
+			GettableRecurringSilence: toGettableRecurringSilence(rs),
+			Provenance:               provenances[rs.UID],
+		})
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv ProvisioningSrv) RouteGetMuteTiming(c *models.ReqContext) response.Response {
+	rs, provenance, err := srv.muteTimings.GetMuteTiming(c.OrgId, c.Params(":UID"))
+	if err != nil {
+		if errors.Is(err, ngmodels.ErrRecurringSilenceNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to get mute timing")
+	}
+	return response.JSON(http.StatusOK, apimodels.ProvisionedMuteTiming{
+		GettableRecurringSilence: toGettableRecurringSilence(rs),
+		Provenance:               provenance,
+	})
+}
+
+func (srv ProvisioningSrv) RoutePutMuteTiming(c *models.ReqContext, body apimodels.PostableRecurringSilence) response.Response {
+	newProvenance, err := provenanceFromRequest(c)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	uid := c.Params(":UID")
+	if body.UID != "" && body.UID != uid {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("the UID of the mute timing %q doesn't match the UID of the path %q", body.UID, uid), "")
+	}
+	body.UID = uid
+
+	rs, err := fromPostableRecurringSilence(c, body)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	_, provenance, err := srv.muteTimings.GetMuteTiming(c.OrgId, uid)
+	if err != nil && !errors.Is(err, ngmodels.ErrRecurringSilenceNotFound) {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get mute timing")
+	}
+	if errResp := provisionedErrResp(checkProvisionedByAPI(c, provenance, "mute timing", uid)); errResp != nil {
+		return errResp
+	}
+	if errors.Is(err, ngmodels.ErrRecurringSilenceNotFound) {
+		if errResp := quotaErrResp(srv.QuotaService.CheckQuota(c, quotaTargetMuteTiming, 1)); errResp != nil {
+			return errResp
+		}
+	}
+
+	if err := srv.muteTimings.SaveMuteTiming(rs, newProvenance); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to save mute timing")
+	}
+	return response.JSON(http.StatusOK, apimodels.ProvisionedMuteTiming{
+		GettableRecurringSilence: toGettableRecurringSilence(rs),
+		Provenance:               newProvenance,
+	})
+}
+
+func (srv ProvisioningSrv) RouteDeleteMuteTiming(c *models.ReqContext) response.Response {
+	uid := c.Params(":UID")
+	_, provenance, err := srv.muteTimings.GetMuteTiming(c.OrgId, uid)
+	switch {
+	case errors.Is(err, ngmodels.ErrRecurringSilenceNotFound):
+		return response.JSON(http.StatusOK, util.DynMap{"message": "mute timing deleted"})
+	case err != nil:
+		return ErrResp(http.StatusInternalServerError, err, "failed to get mute timing")
+	}
+	if errResp := provisionedErrResp(checkProvisionedByAPI(c, provenance, "mute timing", uid)); errResp != nil {
+		return errResp
+	}
+
+	if err := srv.muteTimings.DeleteMuteTiming(c.OrgId, uid); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to delete mute timing")
+	}
+	return response.JSON(http.StatusOK, util.DynMap{"message": "mute timing deleted"})
+}
+
+func (srv ProvisioningSrv) RouteGetMuteTimingUsage(c *models.ReqContext) response.Response {
+	rules, errResp := srv.orgAlertRules(c)
+	if errResp != nil {
+		return errResp
+	}
+	usage, err := srv.muteTimings.GetMuteTimingUsage(c.OrgId, c.Params(":UID"), rules)
+	if err != nil {
+		if errors.Is(err, ngmodels.ErrRecurringSilenceNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the usage of the mute timing")
+	}
+	return response.JSON(http.StatusOK, apimodels.MuteTimingUsage{Rules: toRuleReferences(usage.Rules)})
+}
+
+// orgAlertRules returns the alert rules of the organization of the request.
+func (srv ProvisioningSrv) orgAlertRules(c *models.ReqContext) ([]*ngmodels.AlertRule, response.Response) {
+	q := ngmodels.ListAlertRulesQuery{OrgID: c.OrgId}
+	if err := srv.ruleStore.GetOrgAlertRules(&q); err != nil {
+		return nil, ErrResp(http.StatusInternalServerError, err, "failed to get alert rules")
+	}
+	return q.Result, nil
+}
+
+func toRuleReferences(rules []*ngmodels.AlertRule) []apimodels.RuleReference {
+	refs := make([]apimodels.RuleReference, 0, len(rules))
+	for _, r := range rules {
+		refs = append(refs, apimodels.RuleReference{
+			UID:       r.UID,
+			Title:     r.Title,
+			FolderUID: r.NamespaceUID,
+			RuleGroup: r.RuleGroup,
+		})
+	}
+	return refs
+}
+
+func alertRuleErrResp(err error, msg string) response.Response {
+	switch {
+	case errors.Is(err, ngmodels.ErrAlertRuleNotFound):
+		return ErrResp(http.StatusNotFound, err, msg)
+	case errors.Is(err, ngmodels.ErrAlertRuleFailedValidation), errors.Is(err, ngmodels.ErrAlertRuleUniqueConstraintViolation):
+		return ErrResp(http.StatusBadRequest, err, msg)
+	case errors.Is(err, ngmodels.ErrAlertRuleVersionConflict):
+		return ErrResp(http.StatusConflict, err, msg)
+	}
+	return ErrResp(http.StatusInternalServerError, err, msg)
+}
+
+func amConfigErrResp(err error, msg string) response.Response {
+	if errors.Is(err, provisioning.ErrInvalidConfigChanges) {
+		return ErrResp(http.StatusBadRequest, err, msg)
+	}
+	if errors.Is(err, provisioning.ErrResourceInUse) {
+		return ErrResp(http.StatusConflict, err, msg)
+	}
+	if errors.Is(err, provisioning.ErrRouteNotFound) {
+		return ErrResp(http.StatusNotFound, err, msg)
+	}
+	if errors.Is(err, provisioning.ErrRouteVersionConflict) {
+		return ErrResp(http.StatusConflict, err, msg)
+	}
+	if errors.Is(err, store.ErrAlertmanagerConfigurationConflict) {
+		return ErrResp(http.StatusConflict, err, msg)
+	}
+	return ErrResp(http.StatusInternalServerError, err, msg)
+}
+
+func toProvisionedAlertRule(r *ngmodels.AlertRule, provenance ngmodels.Provenance) apimodels.ProvisionedAlertRule {
+	return apimodels.ProvisionedAlertRule{
+		UID:          r.UID,
+		OrgID:        r.OrgID,
+		FolderUID:    r.NamespaceUID,
+		RuleGroup:    r.RuleGroup,
+		Title:        r.Title,
+		Condition:    r.Condition,
+		Data:         r.Data,
+		NoDataState:  r.NoDataState,
+		ExecErrState: r.ExecErrState,
+		For:          model.Duration(r.For),
+		Annotations:  r.Annotations,
+		Labels:       r.Labels,
+		IsPaused:     r.IsPaused,
+		Composite:    toCompositeCondition(r.Composite),
+		Thresholds:   toThresholds(r.Thresholds),
+		Heartbeat:    toHeartbeatCondition(r.Heartbeat),
+		Links:        toRuleLinks(r.Links),
+		ShadowOf:     r.ShadowOf,
+		ActiveFrom:   toOptionalTime(r.ActiveFrom),
+		ActiveUntil:  toOptionalTime(r.ActiveUntil),
+		Version:      r.Version,
+		Updated:      r.Updated,
+		Provenance:   provenance,
+
+		HoldOnDatasourceMaintenance: r.HoldOnDatasourceMaintenance,
+	}
+}
+
+func fromProvisionedAlertRule(orgID int64, r apimodels.ProvisionedAlertRule) ngmodels.AlertRule {
+	rule := ngmodels.AlertRule{
+		OrgID:        orgID,
+		UID:          r.UID,
+		NamespaceUID: r.FolderUID,
+		RuleGroup:    r.RuleGroup,
+		Title:        r.Title,
+		Condition:    r.Condition,
+		Data:         r.Data,
+		NoDataState:  r.NoDataState,
+		ExecErrState: r.ExecErrState,
+		For:          time.Duration(r.For),
+		Annotations:  r.Annotations,
+		Labels:       r.Labels,
+		IsPaused:     r.IsPaused,
+		ShadowOf:     r.ShadowOf,
+		Version:      r.Version,
+
+		HoldOnDatasourceMaintenance: r.HoldOnDatasourceMaintenance,
+	}
+	if r.Composite != nil {
+		rule.Composite = *r.Composite
+	}
+	if r.Thresholds != nil {
+		rule.Thresholds = *r.Thresholds
+	}
+	if r.Heartbeat != nil {
+		rule.Heartbeat = *r.Heartbeat
+	}
+	if r.Links != nil {
+		rule.Links = *r.Links
+	}
+	if r.ActiveFrom != nil {
+		rule.ActiveFrom = *r.ActiveFrom
+	}
+	if r.ActiveUntil != nil {
+		rule.ActiveUntil = *r.ActiveUntil
+	}
+	return rule
+}
+
+func toPostableExtendedRuleNode(r ngmodels.AlertRule) apimodels.PostableExtendedRuleNode {
+	return apimodels.PostableExtendedRuleNode{
+		ApiRuleNode: &apimodels.ApiRuleNode{
+			For:         model.Duration(r.For),
+			Annotations: r.Annotations,
+			Labels:      r.Labels,
+		},
+		GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+			Title:        r.Title,
+			Condition:    r.Condition,
+			Data:         r.Data,
+			UID:          r.UID,
+			NoDataState:  apimodels.NoDataState(r.NoDataState),
+			ExecErrState: apimodels.ExecutionErrorState(r.ExecErrState),
+			Version:      r.Version,
+			IsPaused:     &r.IsPaused,
+			Composite:    toCompositeCondition(r.Composite),
+			Thresholds:   toThresholds(r.Thresholds),
+			Heartbeat:    toHeartbeatCondition(r.Heartbeat),
+			Links:        toRuleLinks(r.Links),
+			ShadowOf:     r.ShadowOf,
+			ActiveFrom:   toOptionalTime(r.ActiveFrom),
+			ActiveUntil:  toOptionalTime(r.ActiveUntil),
+
+			HoldOnDatasourceMaintenance: r.HoldOnDatasourceMaintenance,
+		},
+	}
+}
+
+func toAlertRuleGroup(folderUID, group string, rules []*ngmodels.AlertRule, provenances map[string]ngmodels.Provenance) apimodels.AlertRuleGroup {
+	result := apimodels.AlertRuleGroup{
+		Title:     group,
+		FolderUID: folderUID,
+		Rules:     make([]apimodels.ProvisionedAlertRule, 0, len(rules)),
+	}
+	for _, r := range rules {
+		result.Interval = r.IntervalSeconds
+		result.Rules = append(result.Rules, toProvisionedAlertRule(r, provenances[r.UID]))
+	}
+	return result
+}
+
+// toGettableContactPoint returns a contact point without the values of its secure settings, the secure settings of
+// the receiver must be decrypted.
+func toGettableContactPoint(r *apimodels.PostableApiReceiver, provenance ngmodels.Provenance) apimodels.GettableContactPoint {
+	result := apimodels.GettableContactPoint{
+		Name:       r.Name,
+		Receivers:  make([]*apimodels.GettableGrafanaReceiver, 0, len(r.GrafanaManagedReceivers)),
+		Provenance: provenance,
+	}
+	for _, gr := range r.GrafanaManagedReceivers {
+		secureFields := make(map[string]bool, len(gr.SecureSettings))
+		for k := range gr.SecureSettings {
+			secureFields[k] = true
+		}
+		result.Receivers = append(result.Receivers, &apimodels.GettableGrafanaReceiver{
+			UID:                   gr.UID,
+			Name:                  gr.Name,
+			Type:                  gr.Type,
+			DisableResolveMessage: gr.DisableResolveMessage,
+			Locale:                gr.Locale,
+			Settings:              gr.Settings,
+			SecureFields:          secureFields,
+		})
+	}
+	return result
+}
+
+func findContactPoint(receivers []*apimodels.PostableApiReceiver, name string) *apimodels.PostableApiReceiver {
+	for _, r := range receivers {
+		if r.Name == name {
+			return r
+		}
+	}
+	return nil
+}
+
+// keepReceiverUIDs gives the receivers of the new contact point without a UID the UID of the current receiver
+// of the same type at the same position, so that sending the same contact point again doesn't change it and
+// keeps its secure settings.
+func keepReceiverUIDs(current, new *apimodels.PostableApiReceiver) {
+	used := make(map[string]struct{}, len(new.GrafanaManagedReceivers))
+	for _, r := range new.GrafanaManagedReceivers {
+		if r.UID != "" {
+			used[r.UID] = struct{}{}
+		}
+	}
+	for i, r := range new.GrafanaManagedReceivers {
+		if r.UID != "" || i >= len(current.GrafanaManagedReceivers) {
+			continue
+		}
+		c := current.GrafanaManagedReceivers[i]
+		if _, ok := used[c.UID]; ok || c.Type != r.Type {
+			continue
+		}
+		r.UID = c.UID
+		used[c.UID] = struct{}{}
+	}
+}
+
+// validatedRoute returns the validated notification policy tree. The route is unmarshalled from YAML to be
+// validated and to parse its group by labels, as when the Alertmanager configuration is loaded.
+func validatedRoute(route *config.Route) (*config.Route, error) {
+	if route == nil {
+		return nil, errors.New("the notification policy tree has no route")
+	}
+	result, err := validatedChildRoute(route)
+	if err != nil {
+		return nil, err
+	}
+	if result.Receiver == "" {
+		return nil, errors.New("the root route must have a receiver")
+	}
+	if len(result.Matchers) > 0 || len(result.Match) > 0 || len(result.MatchRE) > 0 {
+		return nil, errors.New("the root route must not have any matchers")
+	}
+	return result, nil
+}
+
+// validatedChildRoute returns the validated route of the notification policy tree, unmarshalled from YAML as in
+// validatedRoute.
+func validatedChildRoute(route *config.Route) (*config.Route, error) {
+	if route == nil {
+		return nil, errors.New("the notification policy has no route")
+	}
+	b, err := yaml.Marshal(route)
+	if err != nil {
+		return nil, err
+	}
+	var result config.Route
+	if err := yaml.Unmarshal(b, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// routeIndex returns the index of a route among the children of its parent, -1 to add it last.
+func routeIndex(index *int) int {
+	if index == nil {
+		return -1
+	}
+	return *index
+}
+
+func toProvisionedRoute(r *provisioning.PolicyRoute, provenance ngmodels.Provenance) apimodels.ProvisionedRoute {
+	return apimodels.ProvisionedRoute{
+		ID:                    r.ID,
+		ParentID:              r.ParentID,
+		Version:               r.Version,
+		Route:                 r.Route,
+		ResolvedNotifications: r.ResolvedNotifications,
+		Provenance:            provenance,
+	}
+}
@@ -0,0 +1,451 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/prometheus/alertmanager/config"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+)
+
+func (srv ProvisioningSrv) RouteGetAlertingBundle(c *models.ReqContext) response.Response {
+	format := c.Query("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "yaml" {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("unknown format %q, it should be json or yaml", format), "")
+	}
+	folderUIDs, ruleUIDs := c.QueryStrings("folder_uid"), c.QueryStrings("rule_uid")
+	if len(folderUIDs) == 0 && len(ruleUIDs) == 0 {
+		return ErrResp(http.StatusBadRequest, errors.New("the folders or the rules to export should be given"), "")
+	}
+
+	orgRules, errResp := srv.orgAlertRules(c)
+	if errResp != nil {
+		return errResp
+	}
+	rules, err := selectBundleRules(orgRules, folderUIDs, ruleUIDs)
+	if err != nil {
+		return ErrResp(http.StatusNotFound, err, "")
+	}
+
+	bundle := apimodels.AlertingBundle{
+		Folders:       []apimodels.BundleFolder{},
+		RuleGroups:    []apimodels.AlertRuleGroup{},
+		ContactPoints: []apimodels.BundleContactPoint{},
+		MuteTimings:   []apimodels.PostableRecurringSilence{},
+		Templates:     map[string]string{},
+	}
+	for _, uid := range bundleFolderUIDs(rules, folderUIDs) {
+		folder, err := srv.ruleStore.GetNamespaceByUID(uid, c.OrgId, c.SignedInUser, false)
+		if err != nil {
+			return toNamespaceErrorResponse(err)
+		}
+		bundle.Folders = append(bundle.Folders, apimodels.BundleFolder{UID: folder.Uid, Title: folder.Title})
+	}
+	bundle.RuleGroups = toBundleRuleGroups(rules)
+
+	muteTimings, _, err := srv.muteTimings.GetMuteTimings(c.OrgId)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get mute timings")
+	}
+	deps, err := srv.amConfigs.GetRuleDependencies(c.OrgId, rules, muteTimings)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the dependencies of the rules")
+	}
+	receivers, _, err := srv.amConfigs.GetContactPoints(c.OrgId)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get contact points")
+	}
+	for _, name := range deps.ContactPoints {
+		if r := findContactPoint(receivers, name); r != nil {
+			bundle.ContactPoints = append(bundle.ContactPoints, toBundleContactPoint(r))
+		}
+	}
+	for _, uid := range deps.MuteTimings {
+		for _, rs := range muteTimings {
+			if rs.UID == uid {
+				bundle.MuteTimings = append(bundle.MuteTimings, toGettableRecurringSilence(rs).PostableRecurringSilence)
+			}
+		}
+	}
+	templates, _, err := srv.amConfigs.GetTemplates(c.OrgId)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get templates")
+	}
+	for _, name := range deps.Templates {
+		bundle.Templates[name] = templates[name]
+	}
+
+	if format == "yaml" {
+		return alertingBundleYAMLResp(bundle)
+	}
+	return response.JSON(http.StatusOK, bundle)
+}
+
+func (srv ProvisioningSrv) RoutePostAlertingBundle(c *models.ReqContext) response.Response {
+	newProvenance, err := provenanceFromRequest(c)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	b, err := ioutil.ReadAll(c.Req.Body)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to read the bundle")
+	}
+	bundle, err := parseAlertingBundle(b)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to parse the bundle")
+	}
+
+	result := apimodels.AlertingBundleImportResult{
+		DryRun:     c.QueryBool("dryRun"),
+		Created:    newBundleResources(),
+		Resolved:   newBundleResources(),
+		RuleGroups: []apimodels.BundleRuleGroup{},
+	}
+
+	// The folders of the bundle are resolved first, the rule groups reference them by their UID in the bundle.
+	folderUIDs := make(map[string]string, len(bundle.Folders))
+	for _, f := range bundle.Folders {
+		uid, created, errResp := srv.resolveBundleFolder(c, f, result.DryRun)
+		if errResp != nil {
+			return errResp
+		}
+		folderUIDs[f.UID] = uid
+		if created {
+			result.Created.Folders = append(result.Created.Folders, uid)
+		} else {
+			result.Resolved.Folders = append(result.Resolved.Folders, uid)
+		}
+	}
+	for _, g := range bundle.RuleGroups {
+		if _, ok := folderUIDs[g.FolderUID]; ok {
+			continue
+		}
+		if _, err := srv.ruleStore.GetNamespaceByUID(g.FolderUID, c.OrgId, c.SignedInUser, true); err != nil {
+			return toNamespaceErrorResponse(err)
+		}
+		folderUIDs[g.FolderUID] = g.FolderUID
+		result.Resolved.Folders = append(result.Resolved.Folders, g.FolderUID)
+	}
+
+	changes, errResp := srv.bundleConfigChanges(c, bundle, &result)
+	if errResp != nil {
+		return errResp
+	}
+	muteTimings, errResp := srv.bundleMuteTimings(c, bundle, &result)
+	if errResp != nil {
+		return errResp
+	}
+	for _, g := range bundle.RuleGroups {
+		result.RuleGroups = append(result.RuleGroups, apimodels.BundleRuleGroup{FolderUID: folderUIDs[g.FolderUID], Title: g.Title, Rules: len(g.Rules)})
+	}
+	if result.DryRun {
+		return response.JSON(http.StatusOK, result)
+	}
+
+	// The dependencies are created before the rules, the import can be retried if a rule group fails to be
+	// replaced as the created dependencies are then resolved.
+	if err := srv.amConfigs.Apply(c.OrgId, changes, newProvenance); err != nil {
+		return amConfigErrResp(err, "failed to save the contact points and templates of the bundle")
+	}
+	for _, rs := range muteTimings {
+		if err := srv.muteTimings.SaveMuteTiming(rs, newProvenance); err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "failed to save mute timing %q", rs.UID)
+		}
+	}
+	for _, g := range bundle.RuleGroups {
+		if errResp := srv.replaceRuleGroup(c, folderUIDs[g.FolderUID], g.Title, g, newProvenance); errResp != nil {
+			return errResp
+		}
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// resolveBundleFolder returns the UID of the folder of the bundle with the same UID, or else with the same title.
+// The folder is created with its UID and title if there is none, unless it's a dry run.
+func (srv ProvisioningSrv) resolveBundleFolder(c *models.ReqContext, f apimodels.BundleFolder, dryRun bool) (string, bool, response.Response) {
+	folder, err := srv.ruleStore.GetNamespaceByUID(f.UID, c.OrgId, c.SignedInUser, true)
+	if err == nil {
+		return folder.Uid, false, nil
+	}
+	if !errors.Is(err, models.ErrFolderNotFound) {
+		return "", false, toNamespaceErrorResponse(err)
+	}
+	folder, err = srv.ruleStore.GetNamespaceByTitle(f.Title, c.OrgId, c.SignedInUser, true)
+	if err == nil {
+		return folder.Uid, false, nil
+	}
+	if !errors.Is(err, models.ErrFolderNotFound) {
+		return "", false, toNamespaceErrorResponse(err)
+	}
+	if dryRun {
+		return f.UID, true, nil
+	}
+	folder, err = srv.ruleStore.CreateNamespace(f.Title, f.UID, c.OrgId, c.SignedInUser)
+	if err != nil {
+		return "", false, toNamespaceErrorResponse(err)
+	}
+	return folder.Uid, true, nil
+}
+
+// bundleConfigChanges returns the changes creating the contact points and templates of the bundle that don't
+// exist, and adds them to the result.
+func (srv ProvisioningSrv) bundleConfigChanges(c *models.ReqContext, bundle *apimodels.AlertingBundle, result *apimodels.AlertingBundleImportResult) (provisioning.AlertmanagerConfigChanges, response.Response) {
+	var changes provisioning.AlertmanagerConfigChanges
+
+	templates, _, err := srv.amConfigs.GetTemplates(c.OrgId)
+	if err != nil {
+		return changes, ErrResp(http.StatusInternalServerError, err, "failed to get templates")
+	}
+	names := make([]string, 0, len(bundle.Templates))
+	for name := range bundle.Templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := templates[name]; ok {
+			result.Resolved.Templates = append(result.Resolved.Templates, name)
+			continue
+		}
+		if changes.Templates == nil {
+			changes.Templates = map[string]string{}
+		}
+		changes.Templates[name] = bundle.Templates[name]
+		result.Created.Templates = append(result.Created.Templates, name)
+	}
+
+	receivers, _, err := srv.amConfigs.GetContactPoints(c.OrgId)
+	if err != nil {
+		return changes, ErrResp(http.StatusInternalServerError, err, "failed to get contact points")
+	}
+	for _, cp := range bundle.ContactPoints {
+		if findContactPoint(receivers, cp.Name) != nil {
+			result.Resolved.ContactPoints = append(result.Resolved.ContactPoints, cp.Name)
+			continue
+		}
+		if len(cp.Receivers) == 0 {
+			return changes, ErrResp(http.StatusBadRequest, fmt.Errorf("contact point %q has no receivers", cp.Name), "")
+		}
+		for _, r := range cp.Receivers {
+			if r.Name == "" {
+				r.Name = cp.Name
+			}
+		}
+		changes.ContactPoints = append(changes.ContactPoints, &apimodels.PostableApiReceiver{
+			Receiver:                 config.Receiver{Name: cp.Name},
+			PostableGrafanaReceivers: apimodels.PostableGrafanaReceivers{GrafanaManagedReceivers: cp.Receivers},
+		})
+		result.Created.ContactPoints = append(result.Created.ContactPoints, cp.Name)
+	}
+	if len(changes.ContactPoints) > 0 {
+		if errResp := quotaErrResp(srv.QuotaService.CheckQuotaWithUsage(c, quotaTargetContactPoint, int64(len(receivers)), int64(len(changes.ContactPoints)))); errResp != nil {
+			return changes, errResp
+		}
+	}
+	return changes, nil
+}
+
+// bundleMuteTimings returns the validated mute timings of the bundle that don't exist, and adds them to the result.
+func (srv ProvisioningSrv) bundleMuteTimings(c *models.ReqContext, bundle *apimodels.AlertingBundle, result *apimodels.AlertingBundleImportResult) ([]*ngmodels.RecurringSilence, response.Response) {
+	var created []*ngmodels.RecurringSilence
+	for _, mt := range bundle.MuteTimings {
+		if mt.UID == "" {
+			return nil, ErrResp(http.StatusBadRequest, errors.New("the mute timings of the bundle must have a UID"), "")
+		}
+		_, _, err := srv.muteTimings.GetMuteTiming(c.OrgId, mt.UID)
+		if err == nil {
+			result.Resolved.MuteTimings = append(result.Resolved.MuteTimings, mt.UID)
+			continue
+		}
+		if !errors.Is(err, ngmodels.ErrRecurringSilenceNotFound) {
+			return nil, ErrResp(http.StatusInternalServerError, err, "failed to get mute timing")
+		}
+		rs, err := fromPostableRecurringSilence(c, mt)
+		if err != nil {
+			return nil, ErrResp(http.StatusBadRequest, err, "invalid mute timing %q", mt.UID)
+		}
+		created = append(created, rs)
+		result.Created.MuteTimings = append(result.Created.MuteTimings, mt.UID)
+	}
+	if len(created) > 0 {
+		if errResp := quotaErrResp(srv.QuotaService.CheckQuota(c, quotaTargetMuteTiming, int64(len(created)))); errResp != nil {
+			return nil, errResp
+		}
+	}
+	return created, nil
+}
+
+// ruleGroupKey identifies a rule group by its folder and its title.
+type ruleGroupKey struct{ namespaceUID, ruleGroup string }
+
+func groupKeyOf(r *ngmodels.AlertRule) ruleGroupKey {
+	return ruleGroupKey{namespaceUID: r.NamespaceUID, ruleGroup: r.RuleGroup}
+}
+
+// selectBundleRules returns the rules of the folders, and the rules of the rule groups of the rules.
+func selectBundleRules(rules []*ngmodels.AlertRule, folderUIDs, ruleUIDs []string) ([]*ngmodels.AlertRule, error) {
+	folders := make(map[string]struct{}, len(folderUIDs))
+	for _, uid := range folderUIDs {
+		folders[uid] = struct{}{}
+	}
+	groups := make(map[ruleGroupKey]struct{}, len(ruleUIDs))
+	for _, uid := range ruleUIDs {
+		var found bool
+		for _, r := range rules {
+			if r.UID == uid {
+				groups[groupKeyOf(r)] = struct{}{}
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: %s", ngmodels.ErrAlertRuleNotFound, uid)
+		}
+	}
+
+	var result []*ngmodels.AlertRule
+	for _, r := range rules {
+		_, inFolder := folders[r.NamespaceUID]
+		_, inGroup := groups[groupKeyOf(r)]
+		if inFolder || inGroup {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+// bundleFolderUIDs returns the UIDs of the exported folders and of the folders of the rules.
+func bundleFolderUIDs(rules []*ngmodels.AlertRule, folderUIDs []string) []string {
+	seen := map[string]struct{}{}
+	var result []string
+	add := func(uid string) {
+		if _, ok := seen[uid]; !ok {
+			seen[uid] = struct{}{}
+			result = append(result, uid)
+		}
+	}
+	for _, uid := range folderUIDs {
+		add(uid)
+	}
+	for _, r := range rules {
+		add(r.NamespaceUID)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// toBundleRuleGroups returns the rule groups of the rules, without the fields specific to the Grafana instance they
+// are exported from.
+func toBundleRuleGroups(rules []*ngmodels.AlertRule) []apimodels.AlertRuleGroup {
+	byGroup := map[ruleGroupKey][]*ngmodels.AlertRule{}
+	var keys []ruleGroupKey
+	for _, r := range rules {
+		key := groupKeyOf(r)
+		if _, ok := byGroup[key]; !ok {
+			keys = append(keys, key)
+		}
+		byGroup[key] = append(byGroup[key], r)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].namespaceUID != keys[j].namespaceUID {
+			return keys[i].namespaceUID < keys[j].namespaceUID
+		}
+		return keys[i].ruleGroup < keys[j].ruleGroup
+	})
+
+	result := make([]apimodels.AlertRuleGroup, 0, len(keys))
+	for _, key := range keys {
+		group := toAlertRuleGroup(key.namespaceUID, key.ruleGroup, byGroup[key], nil)
+		for i := range group.Rules {
+			group.Rules[i].OrgID = 0
+			group.Rules[i].Version = 0
+			group.Rules[i].Updated = time.Time{}
+		}
+		result = append(result, group)
+	}
+	return result
+}
+
+// toBundleContactPoint returns the contact point without the UIDs and the secure settings of its receivers.
+func toBundleContactPoint(r *apimodels.PostableApiReceiver) apimodels.BundleContactPoint {
+	result := apimodels.BundleContactPoint{
+		Name:      r.Name,
+		Receivers: make([]*apimodels.PostableGrafanaReceiver, 0, len(r.GrafanaManagedReceivers)),
+	}
+	for _, gr := range r.GrafanaManagedReceivers {
+		result.Receivers = append(result.Receivers, &apimodels.PostableGrafanaReceiver{
+			Name:                  gr.Name,
+			Type:                  gr.Type,
+			DisableResolveMessage: gr.DisableResolveMessage,
+			Locale:                gr.Locale,
+			Settings:              gr.Settings,
+		})
+	}
+	return result
+}
+
+func newBundleResources() apimodels.BundleResources {
+	return apimodels.BundleResources{
+		Folders:       []string{},
+		ContactPoints: []string{},
+		MuteTimings:   []string{},
+		Templates:     []string{},
+	}
+}
+
+// parseAlertingBundle parses a bundle in JSON or in YAML. The YAML is converted to JSON before being unmarshalled,
+// as the models of the rules only have JSON tags.
+func parseAlertingBundle(b []byte) (*apimodels.AlertingBundle, error) {
+	var v interface{}
+	if err := yaml.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	j, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var bundle apimodels.AlertingBundle
+	if err := json.Unmarshal(j, &bundle); err != nil {
+		return nil, err
+	}
+	return &bundle, nil
+}
+
+// alertingBundleYAMLResp returns the bundle in YAML, with the fields in the same order as in JSON.
+func alertingBundleYAMLResp(bundle apimodels.AlertingBundle) response.Response {
+	j, err := json.Marshal(bundle)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to marshal the bundle")
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(j, &node); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to marshal the bundle")
+	}
+	resetYAMLStyle(&node)
+	b, err := yaml.Marshal(&node)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to marshal the bundle")
+	}
+	return response.Respond(http.StatusOK, b).SetHeader("Content-Type", "application/yaml")
+}
+
+// resetYAMLStyle resets the flow and quoted styles of the node parsed from JSON to the block style.
+func resetYAMLStyle(n *yaml.Node) {
+	n.Style = 0
+	for _, c := range n.Content {
+		resetYAMLStyle(c)
+	}
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestSelectBundleRules(t *testing.T) {
+	rules := []*ngmodels.AlertRule{
+		{UID: "a", NamespaceUID: "db", RuleGroup: "latency"},
+		{UID: "b", NamespaceUID: "db", RuleGroup: "errors"},
+		{UID: "c", NamespaceUID: "web", RuleGroup: "latency"},
+		{UID: "d", NamespaceUID: "web", RuleGroup: "latency"},
+		{UID: "e", NamespaceUID: "web", RuleGroup: "errors"},
+	}
+
+	selected, err := selectBundleRules(rules, []string{"db"}, []string{"c"})
+	require.NoError(t, err)
+	uids := make([]string, 0, len(selected))
+	for _, r := range selected {
+		uids = append(uids, r.UID)
+	}
+	require.Equal(t, []string{"a", "b", "c", "d"}, uids, "the rules of the rule group of a selected rule are exported")
+	require.Equal(t, []string{"db", "other", "web"}, bundleFolderUIDs(selected, []string{"other"}))
+
+	_, err = selectBundleRules(rules, nil, []string{"unknown"})
+	require.ErrorIs(t, err, ngmodels.ErrAlertRuleNotFound)
+}
+
+func TestToBundleRuleGroups(t *testing.T) {
+	rules := []*ngmodels.AlertRule{
+		{UID: "c", OrgID: 1, NamespaceUID: "web", RuleGroup: "latency", IntervalSeconds: 60, Version: 3, Updated: time.Now()},
+		{UID: "a", OrgID: 1, NamespaceUID: "db", RuleGroup: "latency", IntervalSeconds: 30, Version: 1},
+		{UID: "b", OrgID: 1, NamespaceUID: "db", RuleGroup: "latency", IntervalSeconds: 30, Version: 2},
+	}
+
+	groups := toBundleRuleGroups(rules)
+	require.Len(t, groups, 2)
+	require.Equal(t, "db", groups[0].FolderUID)
+	require.Equal(t, int64(30), groups[0].Interval)
+	require.Len(t, groups[0].Rules, 2)
+	require.Equal(t, "web", groups[1].FolderUID)
+	rule := groups[1].Rules[0]
+	require.Equal(t, "c", rule.UID)
+	require.Zero(t, rule.OrgID)
+	require.Zero(t, rule.Version)
+	require.True(t, rule.Updated.IsZero())
+}
+
+func TestToBundleContactPoint(t *testing.T) {
+	cp := toBundleContactPoint(&apimodels.PostableApiReceiver{
+		PostableGrafanaReceivers: apimodels.PostableGrafanaReceivers{GrafanaManagedReceivers: []*apimodels.PostableGrafanaReceiver{
+			{UID: "uid", Name: "slack", Type: "slack", Settings: simplejson.New(), SecureSettings: map[string]string{"url": "secret"}},
+		}},
+	})
+	require.Len(t, cp.Receivers, 1)
+	require.Empty(t, cp.Receivers[0].UID)
+	require.Empty(t, cp.Receivers[0].SecureSettings)
+	require.Equal(t, "slack", cp.Receivers[0].Type)
+}
+
+func TestAlertingBundleYAML(t *testing.T) {
+	bundle := apimodels.AlertingBundle{
+		Folders: []apimodels.BundleFolder{{UID: "db", Title: "Databases"}},
+		RuleGroups: []apimodels.AlertRuleGroup{{
+			Title:     "latency",
+			FolderUID: "db",
+			Interval:  60,
+			Rules: []apimodels.ProvisionedAlertRule{{
+				UID:       "a",
+				Title:     "Slow queries",
+				Condition: "B",
+				Data: []ngmodels.AlertQuery{{
+					RefID:         "B",
+					DatasourceUID: "-100",
+					Model:         []byte(`{"type":"math","expression":"1 > 0"}`),
+				}},
+				Labels: map[string]string{"team": "db", "critical": "true"},
+			}},
+		}},
+		ContactPoints: []apimodels.BundleContactPoint{},
+		MuteTimings:   []apimodels.PostableRecurringSilence{{UID: "night", StartTime: "22:00", Duration: "8h"}},
+		Templates:     map[string]string{"db": `{{ define "db.title" }}Database{{ end }}`},
+	}
+
+	resp := alertingBundleYAMLResp(bundle).(*response.NormalResponse)
+	require.Equal(t, http.StatusOK, resp.Status())
+	require.Contains(t, string(resp.Body()), "folders:\n    - uid: db\n      title: Databases\n")
+
+	parsed, err := parseAlertingBundle(resp.Body())
+	require.NoError(t, err)
+	require.Equal(t, bundle.Folders, parsed.Folders)
+	require.Equal(t, bundle.MuteTimings, parsed.MuteTimings)
+	require.Equal(t, bundle.Templates, parsed.Templates)
+	require.Equal(t, "true", parsed.RuleGroups[0].Rules[0].Labels["critical"])
+	require.JSONEq(t, `{"type":"math","expression":"1 > 0"}`, string(parsed.RuleGroups[0].Rules[0].Data[0].Model))
+
+	_, err = parseAlertingBundle([]byte(`{"folders": [{"uid": "db", "title": "Databases"}]}`))
+	require.NoError(t, err, "the bundle can be imported in JSON")
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/macaron.v1"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestValidatedRoute(t *testing.T) {
+	t.Run("the group by labels are parsed", func(t *testing.T) {
+		route, err := validatedRoute(&config.Route{
+			Receiver:   "default",
+			GroupByStr: []string{"alertname", "team"},
+			Routes:     []*config.Route{{Receiver: "team", Matchers: config.Matchers{}}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []model.LabelName{"alertname", "team"}, route.GroupBy)
+		require.Len(t, route.Routes, 1)
+	})
+
+	t.Run("the root route must have a receiver", func(t *testing.T) {
+		_, err := validatedRoute(&config.Route{})
+		require.Error(t, err)
+	})
+
+	t.Run("the root route must not have matchers", func(t *testing.T) {
+		_, err := validatedRoute(&config.Route{Receiver: "default", Match: map[string]string{"team": "sre"}})
+		require.Error(t, err)
+	})
+
+	t.Run("a missing route is invalid", func(t *testing.T) {
+		_, err := validatedRoute(nil)
+		require.Error(t, err)
+	})
+}
+
+func TestKeepReceiverUIDs(t *testing.T) {
+	current := &apimodels.PostableApiReceiver{
+		PostableGrafanaReceivers: apimodels.PostableGrafanaReceivers{
+			GrafanaManagedReceivers: []*apimodels.PostableGrafanaReceiver{
+				{UID: "email-uid", Type: "email"},
+				{UID: "slack-uid", Type: "slack"},
+				{UID: "pagerduty-uid", Type: "pagerduty"},
+			},
+		},
+	}
+	new := &apimodels.PostableApiReceiver{
+		PostableGrafanaReceivers: apimodels.PostableGrafanaReceivers{
+			GrafanaManagedReceivers: []*apimodels.PostableGrafanaReceiver{
+				{Type: "email"},
+				{Type: "slack"},
+				{UID: "email-uid", Type: "email"},
+				{Type: "webhook"},
+			},
+		},
+	}
+
+	keepReceiverUIDs(current, new)
+	uids := make([]string, 0, len(new.GrafanaManagedReceivers))
+	for _, r := range new.GrafanaManagedReceivers {
+		uids = append(uids, r.UID)
+	}
+	// The first receiver doesn't get the UID used by the third one, and the types must match.
+	require.Equal(t, []string{"", "slack-uid", "email-uid", ""}, uids)
+}
+
+func TestToGettableContactPoint(t *testing.T) {
+	r := &apimodels.PostableApiReceiver{
+		Receiver: config.Receiver{Name: "ops"},
+		PostableGrafanaReceivers: apimodels.PostableGrafanaReceivers{
+			GrafanaManagedReceivers: []*apimodels.PostableGrafanaReceiver{
+				{UID: "uid", Name: "ops", Type: "slack", SecureSettings: map[string]string{"url": "https://hooks.slack.com/secret"}},
+			},
+		},
+	}
+
+	cp := toGettableContactPoint(r, ngmodels.ProvenanceAPI)
+	require.Equal(t, "ops", cp.Name)
+	require.Equal(t, ngmodels.ProvenanceAPI, cp.Provenance)
+	require.Len(t, cp.Receivers, 1)
+	require.Equal(t, map[string]bool{"url": true}, cp.Receivers[0].SecureFields)
+}
+
+func reqContext(t *testing.T, role models.RoleType, headers map[string]string) *models.ReqContext {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPut, "", nil)
+	require.NoError(t, err)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	return &models.ReqContext{SignedInUser: &models.SignedInUser{OrgId: 1, OrgRole: role}, Context: &macaron.Context{Req: req}}
+}
+
+func TestCheckProvisionedByAPI(t *testing.T) {
+	c := reqContext(t, models.ROLE_ADMIN, nil)
+	require.NoError(t, checkProvisionedByAPI(c, ngmodels.ProvenanceNone, "alert rule", "uid"))
+	require.NoError(t, checkProvisionedByAPI(c, ngmodels.ProvenanceUI, "alert rule", "uid"))
+	require.NoError(t, checkProvisionedByAPI(c, ngmodels.ProvenanceAPI, "alert rule", "uid"))
+	require.NoError(t, checkProvisionedByAPI(c, ngmodels.ProvenanceTerraform, "alert rule", "uid"))
+	require.ErrorIs(t, checkProvisionedByAPI(c, ngmodels.ProvenanceFile, "alert rule", "uid"), ngmodels.ErrProvisionedResource)
+
+	t.Run("admins can override the provenance", func(t *testing.T) {
+		override := map[string]string{OverrideProvenanceHeader: "true"}
+		require.NoError(t, checkProvisionedByAPI(reqContext(t, models.ROLE_ADMIN, override), ngmodels.ProvenanceFile, "alert rule", "uid"))
+		require.ErrorIs(t, checkProvisionedByAPI(reqContext(t, models.ROLE_EDITOR, override), ngmodels.ProvenanceFile, "alert rule", "uid"), ngmodels.ErrProvisionedResource)
+	})
+}
+
+func TestProvenanceFromRequest(t *testing.T) {
+	p, err := provenanceFromRequest(reqContext(t, models.ROLE_ADMIN, nil))
+	require.NoError(t, err)
+	require.Equal(t, ngmodels.ProvenanceAPI, p)
+
+	p, err = provenanceFromRequest(reqContext(t, models.ROLE_ADMIN, map[string]string{ProvenanceHeader: "terraform"}))
+	require.NoError(t, err)
+	require.Equal(t, ngmodels.ProvenanceTerraform, p)
+
+	_, err = provenanceFromRequest(reqContext(t, models.ROLE_ADMIN, map[string]string{ProvenanceHeader: "file"}))
+	require.Error(t, err)
+}
+
+type fakeProvisioningStore struct {
+	provenances map[string]ngmodels.Provenance
+}
+
+func (f *fakeProvisioningStore) GetProvenance(_ int64, recordType, key string) (ngmodels.Provenance, error) {
+	return f.provenances[recordType+"/"+key], nil
+}
+
+func (f *fakeProvisioningStore) GetProvenances(_ int64, recordType string) (map[string]ngmodels.Provenance, error) {
+	result := make(map[string]ngmodels.Provenance)
+	for k, p := range f.provenances {
+		if strings.HasPrefix(k, recordType+"/") && p != ngmodels.ProvenanceNone {
+			result[strings.TrimPrefix(k, recordType+"/")] = p
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeProvisioningStore) SetProvenance(_ int64, recordType, key string, p ngmodels.Provenance) error {
+	f.provenances[recordType+"/"+key] = p
+	return nil
+}
+
+func TestCheckRulesNotProvisioned(t *testing.T) {
+	newSrv := func() (RulerSrv, *fakeProvisioningStore) {
+		store := &fakeProvisioningStore{provenances: map[string]ngmodels.Provenance{
+			"alertRule/file-uid": ngmodels.ProvenanceFile,
+			"alertRule/ui-uid":   ngmodels.ProvenanceUI,
+		}}
+		return RulerSrv{provenanceStore: store, log: log.New("test")}, store
+	}
+
+	t.Run("provisioned rules can't be changed", func(t *testing.T) {
+		srv, _ := newSrv()
+		require.NoError(t, srv.checkRulesNotProvisioned(reqContext(t, models.ROLE_ADMIN, nil), []string{"uid", "ui-uid"}))
+		err := srv.checkRulesNotProvisioned(reqContext(t, models.ROLE_ADMIN, nil), []string{"uid", "file-uid"})
+		require.ErrorIs(t, err, ngmodels.ErrProvisionedResource)
+	})
+
+	t.Run("the rules are taken over when an admin overrides their provenance", func(t *testing.T) {
+		srv, store := newSrv()
+		c := reqContext(t, models.ROLE_ADMIN, map[string]string{OverrideProvenanceHeader: "true"})
+		require.NoError(t, srv.checkRulesNotProvisioned(c, []string{"uid", "file-uid"}))
+		require.Equal(t, ngmodels.ProvenanceUI, store.provenances["alertRule/file-uid"])
+	})
+
+	t.Run("only admins can override the provenance", func(t *testing.T) {
+		srv, store := newSrv()
+		c := reqContext(t, models.ROLE_EDITOR, map[string]string{OverrideProvenanceHeader: "true"})
+		require.ErrorIs(t, srv.checkRulesNotProvisioned(c, []string{"file-uid"}), ngmodels.ErrProvisionedResource)
+		require.Equal(t, ngmodels.ProvenanceFile, store.provenances["alertRule/file-uid"])
+	})
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func (srv AlertmanagerSrv) RouteGetRecurringSilences(c *models.ReqContext) response.Response {
+	q := ngmodels.ListRecurringSilencesQuery{OrgID: c.OrgId}
+	if err := srv.store.ListRecurringSilences(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to list recurring silences")
+	}
+
+	result := make(apimodels.GettableRecurringSilences, 0, len(q.Result))
+	for _, rs := range q.Result {
+		result = append(result, toGettableRecurringSilence(rs))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv AlertmanagerSrv) RouteGetRecurringSilence(c *models.ReqContext) response.Response {
+	q := ngmodels.GetRecurringSilenceQuery{OrgID: c.OrgId, UID: c.Params(":RecurringSilenceUID")}
+	if err := srv.store.GetRecurringSilence(&q); err != nil {
+		if errors.Is(err, ngmodels.ErrRecurringSilenceNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to get recurring silence")
+	}
+	return response.JSON(http.StatusOK, toGettableRecurringSilence(q.Result))
+}
+
+func (srv AlertmanagerSrv) RouteCreateRecurringSilence(c *models.ReqContext, body apimodels.PostableRecurringSilence) response.Response {
+	rs, err := fromPostableRecurringSilence(c, body)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	am, errResp := srv.AlertmanagerFor(c.OrgId)
+	if errResp != nil {
+		return errResp
+	}
+
+	if rs.UID != "" {
+		if errResp := provisionedErrResp(srv.checkMuteTimingNotProvisioned(c, rs.UID)); errResp != nil {
+			return errResp
+		}
+		q := ngmodels.GetRecurringSilenceQuery{OrgID: c.OrgId, UID: rs.UID}
+		if err := srv.store.GetRecurringSilence(&q); err != nil {
+			if errors.Is(err, ngmodels.ErrRecurringSilenceNotFound) {
+				return ErrResp(http.StatusNotFound, err, "")
+			}
+			return ErrResp(http.StatusInternalServerError, err, "failed to get recurring silence")
+		}
+		// The recurrence rule might have changed, the silence of the current occurrence is re-created on the next sync.
+		if err := am.ExpireRecurringSilence(q.Result); err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "failed to expire the silence of the recurring silence")
+		}
+	} else if errResp := quotaErrResp(srv.QuotaService.CheckQuota(c, quotaTargetMuteTiming, 1)); errResp != nil {
+		return errResp
+	}
+
+	if err := srv.store.SaveRecurringSilence(&ngmodels.SaveRecurringSilenceCmd{RecurringSilence: rs}); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to save recurring silence")
+	}
+	return response.JSON(http.StatusAccepted, toGettableRecurringSilence(rs))
+}
+
+func (srv AlertmanagerSrv) RouteDeleteRecurringSilence(c *models.ReqContext) response.Response {
+	q := ngmodels.GetRecurringSilenceQuery{OrgID: c.OrgId, UID: c.Params(":RecurringSilenceUID")}
+	if err := srv.store.GetRecurringSilence(&q); err != nil {
+		if errors.Is(err, ngmodels.ErrRecurringSilenceNotFound) {
+			return ErrResp(http.StatusNotFound, err, "")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to get recurring silence")
+	}
+	if errResp := provisionedErrResp(srv.checkMuteTimingNotProvisioned(c, q.UID)); errResp != nil {
+		return errResp
+	}
+
+	am, errResp := srv.AlertmanagerFor(c.OrgId)
+	if errResp != nil {
+		return errResp
+	}
+	if err := am.ExpireRecurringSilence(q.Result); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to expire the silence of the recurring silence")
+	}
+
+	if err := srv.store.DeleteRecurringSilence(c.OrgId, q.UID); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to delete recurring silence")
+	}
+	return response.JSON(http.StatusOK, util.DynMap{"message": "recurring silence deleted"})
+}
+
+// fromPostableRecurringSilence returns the validated recurring silence of the request body, created by the signed in
+// user if the body has no creator.
+func fromPostableRecurringSilence(c *models.ReqContext, body apimodels.PostableRecurringSilence) (*ngmodels.RecurringSilence, error) {
+	rs := &ngmodels.RecurringSilence{
+		OrgID:     c.OrgId,
+		UID:       body.UID,
+		Matchers:  body.Matchers,
+		Comment:   body.Comment,
+		CreatedBy: body.CreatedBy,
+		Weekdays:  body.Weekdays,
+		StartTime: body.StartTime,
+		Timezone:  body.Timezone,
+	}
+	if rs.CreatedBy == "" {
+		rs.CreatedBy = c.SignedInUser.Login
+	}
+	duration, err := time.ParseDuration(body.Duration)
+	if err != nil {
+		return nil, fmt.Errorf("invalid duration: %w", err)
+	}
+	rs.DurationSeconds = int64(duration.Seconds())
+	if err := rs.Validate(); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
+
+func toGettableRecurringSilence(rs *ngmodels.RecurringSilence) apimodels.GettableRecurringSilence {
+	result := apimodels.GettableRecurringSilence{
+		PostableRecurringSilence: apimodels.PostableRecurringSilence{
+			UID:       rs.UID,
+			Matchers:  rs.Matchers,
+			Comment:   rs.Comment,
+			CreatedBy: rs.CreatedBy,
+			Weekdays:  rs.Weekdays,
+			StartTime: rs.StartTime,
+			Duration:  (time.Duration(rs.DurationSeconds) * time.Second).String(),
+			Timezone:  rs.Timezone,
+		},
+		LastSilenceID: rs.LastSilenceID,
+	}
+	if rs.LastStartsAt > 0 {
+		t := time.Unix(rs.LastStartsAt, 0).UTC()
+		result.LastStartsAt = &t
+	}
+	return result
+}
@@ -0,0 +1,180 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+func (srv RulerSrv) RoutePostBulkRuleOperation(c *models.ReqContext, body apimodels.PostableBulkRuleOperation) response.Response {
+	selector := body.Selector
+	if len(selector.Matchers) == 0 && len(selector.FolderUIDs) == 0 && selector.DatasourceUID == "" {
+		return ErrResp(http.StatusBadRequest, errors.New("the selector must have at least one criterion"), "")
+	}
+	change, err := bulkRuleChange(body)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid operation")
+	}
+
+	query := ngmodels.SearchAlertRulesQuery{
+		OrgID:  c.SignedInUser.OrgId,
+		Labels: map[string]string{},
+		SortBy: ngmodels.AlertRulesSortByTitle,
+	}
+	filter := ruleSearchFilter{datasourceUID: selector.DatasourceUID}
+	for _, m := range selector.Matchers {
+		matcher, err := labels.ParseMatcher(m)
+		if err != nil {
+			return ErrResp(http.StatusBadRequest, err, "invalid matcher %q", m)
+		}
+		if matcher.Type == labels.MatchEqual && matcher.Value != "" {
+			query.Labels[matcher.Name] = matcher.Value
+		}
+		filter.matchers = append(filter.matchers, matcher)
+	}
+
+	namespaces, err := srv.store.GetNamespaces(c.SignedInUser.OrgId, c.SignedInUser)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get namespaces visible to the user")
+	}
+	query.NamespaceUIDs = ruleListOptions{folderUIDs: selector.FolderUIDs}.namespaceUIDs(namespaces)
+
+	result := apimodels.BulkRuleOperationResult{DryRun: body.DryRun, Rules: []apimodels.BulkRuleRef{}}
+	if len(query.NamespaceUIDs) == 0 {
+		return response.JSON(http.StatusOK, result)
+	}
+	if err := srv.store.SearchAlertRules(&query); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to search the alert rules")
+	}
+
+	affected := make([]*ngmodels.AlertRule, 0)
+	changed := make([]store.UpsertRule, 0)
+	for _, r := range query.Result {
+		if !filter.matches(r, "", "") {
+			continue
+		}
+		if change != nil {
+			n := *r
+			if !change(&n) {
+				continue
+			}
+			changed = append(changed, store.UpsertRule{Existing: r, New: n, UpdatedBy: c.SignedInUser.Login, UpdatedByUserID: c.SignedInUser.UserId})
+		}
+		affected = append(affected, r)
+		result.Rules = append(result.Rules, apimodels.BulkRuleRef{UID: r.UID, Title: r.Title, FolderUID: r.NamespaceUID, RuleGroup: r.RuleGroup})
+	}
+	if body.DryRun || len(affected) == 0 {
+		return response.JSON(http.StatusOK, result)
+	}
+
+	checked := make(map[string]struct{})
+	for _, r := range affected {
+		if _, ok := checked[r.NamespaceUID]; ok {
+			continue
+		}
+		if _, err := srv.store.GetNamespaceByUID(r.NamespaceUID, c.SignedInUser.OrgId, c.SignedInUser, true); err != nil {
+			return toNamespaceErrorResponse(err)
+		}
+		checked[r.NamespaceUID] = struct{}{}
+	}
+	if errResp := provisionedErrResp(srv.checkRulesNotProvisioned(c, ruleUIDs(affected))); errResp != nil {
+		return errResp
+	}
+
+	if change == nil {
+		err = srv.store.DeleteAlertRulesByUID(c.SignedInUser.OrgId, ruleUIDs(affected)...)
+	} else {
+		err = srv.store.UpsertAlertRules(changed)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, ngmodels.ErrAlertRuleFailedValidation):
+			return ErrResp(http.StatusBadRequest, err, "failed to apply the operation")
+		case errors.Is(err, ngmodels.ErrAlertRuleVersionConflict):
+			return ErrResp(http.StatusConflict, err, "failed to apply the operation")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to apply the operation")
+	}
+
+	for _, r := range affected {
+		srv.manager.RemoveByRuleUID(c.SignedInUser.OrgId, r.UID)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// bulkRuleChange returns the function that applies the operation to a rule and returns true if the rule was
+// changed, or nil for the deletion.
+func bulkRuleChange(op apimodels.PostableBulkRuleOperation) (func(r *ngmodels.AlertRule) bool, error) {
+	switch op.Operation {
+	case apimodels.BulkRuleOperationPause, apimodels.BulkRuleOperationResume:
+		paused := op.Operation == apimodels.BulkRuleOperationPause
+		return func(r *ngmodels.AlertRule) bool {
+			if r.IsPaused == paused {
+				return false
+			}
+			r.IsPaused = paused
+			return true
+		}, nil
+	case apimodels.BulkRuleOperationDelete:
+		return nil, nil
+	}
+
+	if op.Key == "" {
+		return nil, fmt.Errorf("the key of operation %q is missing", op.Operation)
+	}
+	switch op.Operation {
+	case apimodels.BulkRuleOperationAddLabel:
+		return func(r *ngmodels.AlertRule) bool {
+			return setKey(&r.Labels, op.Key, op.Value)
+		}, nil
+	case apimodels.BulkRuleOperationRemoveLabel:
+		return func(r *ngmodels.AlertRule) bool {
+			return removeKey(&r.Labels, op.Key)
+		}, nil
+	case apimodels.BulkRuleOperationSetAnnotation:
+		return func(r *ngmodels.AlertRule) bool {
+			return setKey(&r.Annotations, op.Key, op.Value)
+		}, nil
+	case apimodels.BulkRuleOperationRemoveAnnotation:
+		return func(r *ngmodels.AlertRule) bool {
+			return removeKey(&r.Annotations, op.Key)
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown operation %q", op.Operation)
+}
+
+// setKey sets the key of a copy of the map, so the map of the rule read from the store is not changed.
+func setKey(m *map[string]string, key, value string) bool {
+	if v, ok := (*m)[key]; ok && v == value {
+		return false
+	}
+	c := make(map[string]string, len(*m)+1)
+	for k, v := range *m {
+		c[k] = v
+	}
+	c[key] = value
+	*m = c
+	return true
+}
+
+func removeKey(m *map[string]string, key string) bool {
+	if _, ok := (*m)[key]; !ok {
+		return false
+	}
+	c := make(map[string]string, len(*m))
+	for k, v := range *m {
+		if k != key {
+			c[k] = v
+		}
+	}
+	*m = c
+	return true
+}
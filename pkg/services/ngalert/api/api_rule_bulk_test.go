@@ -0,0 +1,70 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestBulkRuleChange(t *testing.T) {
+	apply := func(t *testing.T, op apimodels.PostableBulkRuleOperation, r ngmodels.AlertRule) (ngmodels.AlertRule, bool) {
+		t.Helper()
+		change, err := bulkRuleChange(op)
+		require.NoError(t, err)
+		changed := change(&r)
+		return r, changed
+	}
+	rule := ngmodels.AlertRule{
+		Labels:      map[string]string{"team": "sre"},
+		Annotations: map[string]string{"contact_point": "email"},
+	}
+
+	t.Run("pauses and resumes", func(t *testing.T) {
+		paused, changed := apply(t, apimodels.PostableBulkRuleOperation{Operation: apimodels.BulkRuleOperationPause}, rule)
+		require.True(t, changed)
+		require.True(t, paused.IsPaused)
+		_, changed = apply(t, apimodels.PostableBulkRuleOperation{Operation: apimodels.BulkRuleOperationPause}, paused)
+		require.False(t, changed)
+		resumed, changed := apply(t, apimodels.PostableBulkRuleOperation{Operation: apimodels.BulkRuleOperationResume}, paused)
+		require.True(t, changed)
+		require.False(t, resumed.IsPaused)
+	})
+
+	t.Run("changes copies of the labels and annotations", func(t *testing.T) {
+		r, changed := apply(t, apimodels.PostableBulkRuleOperation{Operation: apimodels.BulkRuleOperationAddLabel, Key: "severity", Value: "critical"}, rule)
+		require.True(t, changed)
+		require.Equal(t, map[string]string{"team": "sre", "severity": "critical"}, r.Labels)
+		require.Equal(t, map[string]string{"team": "sre"}, rule.Labels)
+
+		_, changed = apply(t, apimodels.PostableBulkRuleOperation{Operation: apimodels.BulkRuleOperationAddLabel, Key: "team", Value: "sre"}, rule)
+		require.False(t, changed)
+
+		r, changed = apply(t, apimodels.PostableBulkRuleOperation{Operation: apimodels.BulkRuleOperationRemoveLabel, Key: "team"}, rule)
+		require.True(t, changed)
+		require.Empty(t, r.Labels)
+
+		r, changed = apply(t, apimodels.PostableBulkRuleOperation{Operation: apimodels.BulkRuleOperationSetAnnotation, Key: "contact_point", Value: "slack"}, rule)
+		require.True(t, changed)
+		require.Equal(t, map[string]string{"contact_point": "slack"}, r.Annotations)
+		require.Equal(t, "email", rule.Annotations["contact_point"])
+
+		_, changed = apply(t, apimodels.PostableBulkRuleOperation{Operation: apimodels.BulkRuleOperationRemoveAnnotation, Key: "runbook_url"}, rule)
+		require.False(t, changed)
+	})
+
+	t.Run("deletes", func(t *testing.T) {
+		change, err := bulkRuleChange(apimodels.PostableBulkRuleOperation{Operation: apimodels.BulkRuleOperationDelete})
+		require.NoError(t, err)
+		require.Nil(t, change)
+	})
+
+	t.Run("rejects invalid operations", func(t *testing.T) {
+		_, err := bulkRuleChange(apimodels.PostableBulkRuleOperation{Operation: "archive", Key: "a"})
+		require.Error(t, err)
+		_, err = bulkRuleChange(apimodels.PostableBulkRuleOperation{Operation: apimodels.BulkRuleOperationAddLabel})
+		require.Error(t, err)
+	})
+}
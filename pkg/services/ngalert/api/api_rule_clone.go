@@ -0,0 +1,229 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func (srv RulerSrv) RoutePostCloneRule(c *models.ReqContext, body apimodels.PostableRuleClone) response.Response {
+	rule, namespace, errResp := srv.getRuleWithNamespace(c, false)
+	if errResp != nil {
+		return errResp
+	}
+	target, errResp := srv.getCloneTarget(c, namespace, body.FolderUID)
+	if errResp != nil {
+		return errResp
+	}
+	ruleGroup := rule.RuleGroup
+	if body.RuleGroup != "" {
+		ruleGroup = body.RuleGroup
+	}
+
+	clone, err := applySubstitutions(*rule, body.Substitutions)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to apply the substitutions")
+	}
+	if body.Title != "" {
+		clone.Title = body.Title
+	}
+	if body.Shadow {
+		if rule.IsShadow() {
+			return ErrResp(http.StatusBadRequest, fmt.Errorf("alert rule %q is a shadow rule", rule.UID), "")
+		}
+		clone.ShadowOf = rule.UID
+	}
+	return srv.cloneRules(c, []ngmodels.AlertRule{clone}, target, ruleGroup, false)
+}
+
+func (srv RulerSrv) RoutePostCloneRuleGroup(c *models.ReqContext, body apimodels.PostableRuleGroupClone) response.Response {
+	namespace, err := srv.store.GetNamespaceByTitle(c.Params(":Namespace"), c.SignedInUser.OrgId, c.SignedInUser, false)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+	ruleGroup := c.Params(":Groupname")
+	q := ngmodels.ListRuleGroupAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+		RuleGroup:    ruleGroup,
+	}
+	if err := srv.store.GetRuleGroupAlertRules(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get group alert rules")
+	}
+	if len(q.Result) == 0 {
+		return ErrResp(http.StatusNotFound, fmt.Errorf("rule group %q not found", ruleGroup), "")
+	}
+
+	target, errResp := srv.getCloneTarget(c, namespace, body.FolderUID)
+	if errResp != nil {
+		return errResp
+	}
+	targetGroup := ruleGroup
+	if body.RuleGroup != "" {
+		targetGroup = body.RuleGroup
+	}
+	if target.Uid == namespace.Uid && targetGroup == ruleGroup {
+		return ErrResp(http.StatusBadRequest, errors.New("the copy of the rule group must be in another folder or have another name"), "")
+	}
+
+	clones := make([]ngmodels.AlertRule, 0, len(q.Result))
+	for _, r := range q.Result {
+		clone, err := applySubstitutions(*r, body.Substitutions)
+		if err != nil {
+			return ErrResp(http.StatusBadRequest, err, "failed to apply the substitutions to alert rule %q", r.Title)
+		}
+		clones = append(clones, clone)
+	}
+	return srv.cloneRules(c, clones, target, targetGroup, true)
+}
+
+// getCloneTarget returns the folder of the copies, the folder of the source when folderUID is empty. The user must
+// be able to change the folder.
+func (srv RulerSrv) getCloneTarget(c *models.ReqContext, source *models.Folder, folderUID string) (*models.Folder, response.Response) {
+	if folderUID == "" {
+		folderUID = source.Uid
+	}
+	target, err := srv.store.GetNamespaceByUID(folderUID, c.SignedInUser.OrgId, c.SignedInUser, true)
+	if err != nil {
+		return nil, toNamespaceErrorResponse(err)
+	}
+	return target, nil
+}
+
+// cloneRules creates the copies in the rule group of the folder, the rule group must not exist when newGroup is true.
+func (srv RulerSrv) cloneRules(c *models.ReqContext, clones []ngmodels.AlertRule, target *models.Folder, ruleGroup string, newGroup bool) response.Response {
+	rules := make([]*ngmodels.AlertRule, 0, len(clones))
+	uids := make([]string, 0, len(clones))
+	for i := range clones {
+		r := &clones[i]
+		cond := ngmodels.Condition{Condition: r.Condition, OrgID: c.SignedInUser.OrgId, Data: r.Data}
+		if err := validateCondition(cond, c.SignedInUser, c.SkipCache, srv.DatasourceCache); err != nil {
+			return ErrResp(http.StatusBadRequest, err, "failed to validate the copy of alert rule %q", r.Title)
+		}
+		r.UID = util.GenerateShortUID()
+		rules = append(rules, r)
+		uids = append(uids, r.UID)
+	}
+	if errResp := srv.checkRuleQuota(c, len(rules)); errResp != nil {
+		return errResp
+	}
+
+	if err := srv.store.MoveAlertRules(store.MoveAlertRulesCmd{
+		OrgID:           c.SignedInUser.OrgId,
+		Rules:           rules,
+		NamespaceUID:    target.Uid,
+		RuleGroup:       ruleGroup,
+		NewGroup:        newGroup,
+		Copy:            true,
+		UpdatedBy:       c.SignedInUser.Login,
+		UpdatedByUserID: c.SignedInUser.UserId,
+	}); err != nil {
+		switch {
+		case errors.Is(err, ngmodels.ErrAlertRuleFailedValidation):
+			return ErrResp(http.StatusBadRequest, err, "failed to copy the alert rules")
+		case errors.Is(err, ngmodels.ErrAlertRuleUniqueConstraintViolation),
+			errors.Is(err, ngmodels.ErrRuleGroupExists):
+			return ErrResp(http.StatusConflict, err, "failed to copy the alert rules")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to copy the alert rules")
+	}
+
+	return response.JSON(http.StatusAccepted, apimodels.RuleCloneResult{
+		Message: fmt.Sprintf("%d alert rules copied", len(rules)),
+		UIDs:    uids,
+	})
+}
+
+// applySubstitutions returns a copy of the rule with the substitutions applied. The maps and the queries of the
+// rule are copied, so the rule is not changed.
+func applySubstitutions(r ngmodels.AlertRule, s apimodels.RuleSubstitutions) (ngmodels.AlertRule, error) {
+	// The texts are replaced in the order of their keys, so the result doesn't depend on the order of the map.
+	olds := make([]string, 0, len(s.Text))
+	for old := range s.Text {
+		if old == "" {
+			return r, errors.New("the text to replace must not be empty")
+		}
+		olds = append(olds, old)
+	}
+	sort.Strings(olds)
+	replace := func(v string) string {
+		for _, old := range olds {
+			v = strings.ReplaceAll(v, old, s.Text[old])
+		}
+		return v
+	}
+
+	r.Title = replace(r.Title)
+	r.Labels = substituteMap(r.Labels, replace, s.Labels)
+	r.Annotations = substituteMap(r.Annotations, replace, s.Annotations)
+
+	data := make([]ngmodels.AlertQuery, 0, len(r.Data))
+	for _, q := range r.Data {
+		if uid, ok := s.DatasourceUIDs[q.DatasourceUID]; ok {
+			q.DatasourceUID = uid
+		}
+		model, err := substituteModel(q.Model, replace, s.DatasourceUIDs)
+		if err != nil {
+			return r, fmt.Errorf("query %s: %w", q.RefID, err)
+		}
+		q.Model = model
+		data = append(data, q)
+	}
+	r.Data = data
+	return r, nil
+}
+
+// substituteMap returns a copy of the labels or annotations with the texts replaced in the values, and set added.
+func substituteMap(m map[string]string, replace func(string) string, set map[string]string) map[string]string {
+	if len(m) == 0 && len(set) == 0 {
+		return m
+	}
+	result := make(map[string]string, len(m)+len(set))
+	for k, v := range m {
+		result[k] = replace(v)
+	}
+	for k, v := range set {
+		result[k] = v
+	}
+	return result
+}
+
+// substituteModel replaces the texts in the query model, and the UID of its data source.
+func substituteModel(model json.RawMessage, replace func(string) string, datasourceUIDs map[string]string) (json.RawMessage, error) {
+	replaced := json.RawMessage(replace(string(model)))
+	if !json.Valid(replaced) {
+		return nil, errors.New("the model is not valid JSON after the substitutions")
+	}
+	if len(datasourceUIDs) == 0 {
+		return replaced, nil
+	}
+
+	var props map[string]interface{}
+	if err := json.Unmarshal(replaced, &props); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal query model: %w", err)
+	}
+	ds, ok := props["datasource"].(map[string]interface{})
+	if !ok {
+		return replaced, nil
+	}
+	uid, ok := ds["uid"].(string)
+	if !ok {
+		return replaced, nil
+	}
+	newUID, ok := datasourceUIDs[uid]
+	if !ok {
+		return replaced, nil
+	}
+	ds["uid"] = newUID
+	return json.Marshal(props)
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestApplySubstitutions(t *testing.T) {
+	rule := ngmodels.AlertRule{
+		Title:       "staging latency",
+		Labels:      map[string]string{"env": "staging", "team": "sre"},
+		Annotations: map[string]string{"summary": "latency is high in staging"},
+		Data: []ngmodels.AlertQuery{
+			{
+				RefID:         "A",
+				DatasourceUID: "prom-staging",
+				Model:         json.RawMessage(`{"datasource":{"type":"prometheus","uid":"prom-staging"},"expr":"latency{env=\"staging\"}"}`),
+			},
+			{
+				RefID:         "B",
+				DatasourceUID: "-100",
+				Model:         json.RawMessage(`{"type":"math","expression":"$A > 1"}`),
+			},
+		},
+	}
+
+	t.Run("replaces the texts, the data sources and sets labels and annotations", func(t *testing.T) {
+		clone, err := applySubstitutions(rule, apimodels.RuleSubstitutions{
+			Text:           map[string]string{"staging": "production"},
+			DatasourceUIDs: map[string]string{"prom-staging": "prom-production"},
+			Labels:         map[string]string{"severity": "critical"},
+			Annotations:    map[string]string{"runbook_url": "https://example.com"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "production latency", clone.Title)
+		require.Equal(t, map[string]string{"env": "production", "team": "sre", "severity": "critical"}, clone.Labels)
+		require.Equal(t, map[string]string{"summary": "latency is high in production", "runbook_url": "https://example.com"}, clone.Annotations)
+		require.Equal(t, "prom-production", clone.Data[0].DatasourceUID)
+		require.JSONEq(t, `{"datasource":{"type":"prometheus","uid":"prom-production"},"expr":"latency{env=\"production\"}"}`, string(clone.Data[0].Model))
+		require.Equal(t, "-100", clone.Data[1].DatasourceUID)
+		require.JSONEq(t, `{"type":"math","expression":"$A > 1"}`, string(clone.Data[1].Model))
+	})
+
+	t.Run("replaces only the data source if the texts don't change it", func(t *testing.T) {
+		clone, err := applySubstitutions(rule, apimodels.RuleSubstitutions{
+			DatasourceUIDs: map[string]string{"prom-staging": "prom-production"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, rule.Title, clone.Title)
+		require.Equal(t, "prom-production", clone.Data[0].DatasourceUID)
+		require.JSONEq(t, `{"datasource":{"type":"prometheus","uid":"prom-production"},"expr":"latency{env=\"staging\"}"}`, string(clone.Data[0].Model))
+	})
+
+	t.Run("doesn't change the rule", func(t *testing.T) {
+		_, err := applySubstitutions(rule, apimodels.RuleSubstitutions{
+			Text:   map[string]string{"staging": "production"},
+			Labels: map[string]string{"severity": "critical"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"env": "staging", "team": "sre"}, rule.Labels)
+		require.Equal(t, "prom-staging", rule.Data[0].DatasourceUID)
+		require.Contains(t, string(rule.Data[0].Model), "staging")
+	})
+
+	t.Run("fails if a model is not valid JSON after the substitutions", func(t *testing.T) {
+		_, err := applySubstitutions(rule, apimodels.RuleSubstitutions{Text: map[string]string{`"`: ""}})
+		require.Error(t, err)
+	})
+
+	t.Run("fails if a text to replace is empty", func(t *testing.T) {
+		_, err := applySubstitutions(rule, apimodels.RuleSubstitutions{Text: map[string]string{"": "production"}})
+		require.Error(t, err)
+	})
+}
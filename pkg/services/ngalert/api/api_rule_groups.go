@@ -0,0 +1,102 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+func (srv RulerSrv) RoutePutRuleGroup(c *models.ReqContext, ruleGroupConfig apimodels.PostableRuleGroupConfig) response.Response {
+	namespace, err := srv.store.GetNamespaceByTitle(c.Params(":Namespace"), c.SignedInUser.OrgId, c.SignedInUser, true)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+	ruleGroup := c.Params(":Groupname")
+	if ruleGroupConfig.Name == "" {
+		ruleGroupConfig.Name = ruleGroup
+	} else if ruleGroupConfig.Name != ruleGroup {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("the name of the rule group %q doesn't match the path", ruleGroupConfig.Name), "")
+	}
+
+	alertRuleUIDs, errResp := srv.validateRuleGroup(c, ruleGroupConfig)
+	if errResp != nil {
+		return errResp
+	}
+
+	q := ngmodels.ListRuleGroupAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+		RuleGroup:    ruleGroup,
+	}
+	if err := srv.store.GetRuleGroupAlertRules(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get group alert rules")
+	}
+	existing := q.Result
+	if err := checkRulesInGroup(existing, alertRuleUIDs); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to validate the rule group")
+	}
+	if errResp := provisionedErrResp(srv.checkRulesNotProvisioned(c, ruleUIDs(existing))); errResp != nil {
+		return errResp
+	}
+	if errResp := srv.checkRuleQuota(c, len(ruleGroupConfig.Rules)-len(alertRuleUIDs)); errResp != nil {
+		return errResp
+	}
+
+	if err := srv.store.UpdateRuleGroup(store.UpdateRuleGroupCmd{
+		OrgID:           c.SignedInUser.OrgId,
+		NamespaceUID:    namespace.Uid,
+		RuleGroupConfig: ruleGroupConfig,
+		UpdatedBy:       c.SignedInUser.Login,
+		UpdatedByUserID: c.SignedInUser.UserId,
+		// The group was checked above, it's replaced only if it's still the same.
+		Check: func(current []*ngmodels.AlertRule) error {
+			return checkSameRules(existing, current)
+		},
+	}); err != nil {
+		return updateRuleGroupErrResp(err)
+	}
+
+	for _, r := range existing {
+		srv.manager.RemoveByRuleUID(c.SignedInUser.OrgId, r.UID)
+	}
+
+	return srv.RouteGetRulegGroupConfig(c)
+}
+
+// checkRulesInGroup returns an error wrapping ngmodels.ErrAlertRuleFailedValidation if one of the UIDs is not the
+// UID of a rule of the group.
+func checkRulesInGroup(group []*ngmodels.AlertRule, uids map[string]struct{}) error {
+	inGroup := make(map[string]struct{}, len(group))
+	for _, r := range group {
+		inGroup[r.UID] = struct{}{}
+	}
+	for uid := range uids {
+		if _, ok := inGroup[uid]; !ok {
+			return fmt.Errorf("%w: alert rule %q is not in the rule group", ngmodels.ErrAlertRuleFailedValidation, uid)
+		}
+	}
+	return nil
+}
+
+// checkSameRules returns an error wrapping ngmodels.ErrAlertRuleVersionConflict if the rules of the group were
+// created, updated or deleted since they were read.
+func checkSameRules(read, current []*ngmodels.AlertRule) error {
+	versions := make(map[string]int64, len(read))
+	for _, r := range read {
+		versions[r.UID] = r.Version
+	}
+	if len(current) != len(read) {
+		return fmt.Errorf("%w: the rule group was changed", ngmodels.ErrAlertRuleVersionConflict)
+	}
+	for _, r := range current {
+		if v, ok := versions[r.UID]; !ok || v != r.Version {
+			return fmt.Errorf("%w: the rule group was changed", ngmodels.ErrAlertRuleVersionConflict)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestCheckRulesInGroup(t *testing.T) {
+	group := []*ngmodels.AlertRule{{UID: "a"}, {UID: "b"}}
+	require.NoError(t, checkRulesInGroup(group, map[string]struct{}{}))
+	require.NoError(t, checkRulesInGroup(group, map[string]struct{}{"a": {}, "b": {}}))
+	require.ErrorIs(t, checkRulesInGroup(group, map[string]struct{}{"a": {}, "c": {}}), ngmodels.ErrAlertRuleFailedValidation)
+}
+
+func TestCheckSameRules(t *testing.T) {
+	read := []*ngmodels.AlertRule{{UID: "a", Version: 1}, {UID: "b", Version: 3}}
+	require.NoError(t, checkSameRules(read, []*ngmodels.AlertRule{{UID: "b", Version: 3}, {UID: "a", Version: 1}}))
+	require.ErrorIs(t, checkSameRules(read, []*ngmodels.AlertRule{{UID: "a", Version: 2}, {UID: "b", Version: 3}}), ngmodels.ErrAlertRuleVersionConflict)
+	require.ErrorIs(t, checkSameRules(read, []*ngmodels.AlertRule{{UID: "a", Version: 1}}), ngmodels.ErrAlertRuleVersionConflict)
+	require.ErrorIs(t, checkSameRules(read, []*ngmodels.AlertRule{{UID: "a", Version: 1}, {UID: "c", Version: 1}}), ngmodels.ErrAlertRuleVersionConflict)
+}
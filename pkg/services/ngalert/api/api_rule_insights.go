@@ -0,0 +1,135 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/insights"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+const (
+	defaultRuleInsightsPeriod = 24 * time.Hour
+	defaultRuleInsightsLimit  = 100
+	maxRuleInsightsLimit      = 1000
+)
+
+// ruleInsightsSorts are the sorts of the insights, they return whether the first insight is sorted before the second.
+var ruleInsightsSorts = map[string]func(a, b apimodels.RuleInsight) bool{
+	"avg_duration": func(a, b apimodels.RuleInsight) bool { return a.AvgDurationSeconds > b.AvgDurationSeconds },
+	"max_duration": func(a, b apimodels.RuleInsight) bool { return a.MaxDurationSeconds > b.MaxDurationSeconds },
+	"failure_rate": func(a, b apimodels.RuleInsight) bool { return a.FailureRate > b.FailureRate },
+	"nodata_rate":  func(a, b apimodels.RuleInsight) bool { return a.NoDataRate > b.NoDataRate },
+}
+
+// RuleInsights returns the insights of the alert rules.
+type RuleInsights interface {
+	GetInsights(orgID int64, from time.Time) (map[string]*ngmodels.AlertRuleInsight, error)
+}
+
+type RuleInsightsSrv struct {
+	store    store.RuleStore
+	insights RuleInsights
+	log      log.Logger
+}
+
+func (srv RuleInsightsSrv) RouteGetRuleInsights(c *models.ReqContext) response.Response {
+	period := defaultRuleInsightsPeriod
+	if c.Query("period") != "" {
+		d, err := model.ParseDuration(c.Query("period"))
+		if err != nil || d <= 0 || time.Duration(d) > insights.Retention {
+			return ErrResp(http.StatusBadRequest, fmt.Errorf("period must be a duration up to %s", model.Duration(insights.Retention)), "invalid period")
+		}
+		period = time.Duration(d)
+	}
+	limit := defaultRuleInsightsLimit
+	if c.Query("limit") != "" {
+		var err error
+		if limit, err = strconv.Atoi(c.Query("limit")); err != nil || limit < 1 || limit > maxRuleInsightsLimit {
+			return ErrResp(http.StatusBadRequest, fmt.Errorf("limit must be between 1 and %d", maxRuleInsightsLimit), "invalid limit")
+		}
+	}
+	sortBy := c.Query("sort")
+	if sortBy == "" {
+		sortBy = "avg_duration"
+	}
+	if _, ok := ruleInsightsSorts[sortBy]; !ok {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("unknown sort %q", sortBy), "invalid sort")
+	}
+
+	from := timeNow().Add(-period).Truncate(insights.Period)
+	result := apimodels.RuleInsightsResult{From: from, Rules: []apimodels.RuleInsight{}}
+	namespaces, err := srv.store.GetNamespaces(c.SignedInUser.OrgId, c.SignedInUser)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get namespaces visible to the user")
+	}
+	// Without a namespace the query would return the rules of all folders.
+	if len(namespaces) == 0 {
+		return response.JSON(http.StatusOK, result)
+	}
+	query := ngmodels.SearchAlertRulesQuery{OrgID: c.SignedInUser.OrgId, SortBy: ngmodels.AlertRulesSortByTitle}
+	for uid := range namespaces {
+		query.NamespaceUIDs = append(query.NamespaceUIDs, uid)
+	}
+	if err := srv.store.SearchAlertRules(&query); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the alert rules")
+	}
+
+	ruleInsights, err := srv.insights.GetInsights(c.SignedInUser.OrgId, from)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the insights of the alert rules")
+	}
+	result.Rules = toRuleInsights(query.Result, namespaces, ruleInsights, sortBy, limit)
+	return response.JSON(http.StatusOK, result)
+}
+
+// toRuleInsights returns the insights of the rules evaluated in the period, sorted by sortBy.
+func toRuleInsights(rules []*ngmodels.AlertRule, namespaces map[string]*models.Folder, ruleInsights map[string]*ngmodels.AlertRuleInsight, sortBy string, limit int) []apimodels.RuleInsight {
+	result := make([]apimodels.RuleInsight, 0)
+	for _, r := range rules {
+		i, ok := ruleInsights[r.UID]
+		if !ok || i.Evaluations == 0 {
+			continue
+		}
+		insight := apimodels.RuleInsight{
+			UID:                r.UID,
+			Title:              r.Title,
+			FolderUID:          r.NamespaceUID,
+			RuleGroup:          r.RuleGroup,
+			Evaluations:        i.Evaluations,
+			Failures:           i.Failures,
+			NoData:             i.NoData,
+			FailureRate:        float64(i.Failures) / float64(i.Evaluations),
+			NoDataRate:         float64(i.NoData) / float64(i.Evaluations),
+			AvgDurationSeconds: i.DurationSeconds / float64(i.Evaluations),
+			MaxDurationSeconds: i.MaxDurationSeconds,
+			LastError:          i.LastError,
+		}
+		if namespace, ok := namespaces[r.NamespaceUID]; ok {
+			insight.FolderTitle = namespace.Title
+		}
+		if i.LastErrorAt > 0 {
+			at := time.Unix(i.LastErrorAt, 0).UTC()
+			insight.LastErrorAt = &at
+		}
+		result = append(result, insight)
+	}
+
+	// The rules are sorted by title, the rules with the same value keep this order.
+	less := ruleInsightsSorts[sortBy]
+	sort.SliceStable(result, func(i, j int) bool { return less(result[i], result[j]) })
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result
+}
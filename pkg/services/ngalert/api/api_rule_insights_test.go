@@ -0,0 +1,43 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestToRuleInsights(t *testing.T) {
+	rules := []*ngmodels.AlertRule{
+		{UID: "fast", Title: "fast", NamespaceUID: "folder", RuleGroup: "group"},
+		{UID: "not-evaluated", Title: "not evaluated", NamespaceUID: "folder"},
+		{UID: "slow", Title: "slow", NamespaceUID: "folder", RuleGroup: "group"},
+		{UID: "broken", Title: "broken", NamespaceUID: "folder", RuleGroup: "group"},
+	}
+	namespaces := map[string]*models.Folder{"folder": {Uid: "folder", Title: "Folder"}}
+	lastErrorAt := time.Date(2021, 10, 14, 10, 15, 0, 0, time.UTC)
+	insights := map[string]*ngmodels.AlertRuleInsight{
+		"fast":   {RuleUID: "fast", Evaluations: 4, DurationSeconds: 2, MaxDurationSeconds: 1},
+		"slow":   {RuleUID: "slow", Evaluations: 2, DurationSeconds: 10, MaxDurationSeconds: 6, NoData: 1},
+		"broken": {RuleUID: "broken", Evaluations: 4, DurationSeconds: 4, MaxDurationSeconds: 1, Failures: 3, LastError: "timeout", LastErrorAt: lastErrorAt.Unix()},
+		"other":  {RuleUID: "other", Evaluations: 1},
+	}
+
+	result := toRuleInsights(rules, namespaces, insights, "avg_duration", 10)
+	require.Len(t, result, 3, "the rules that were not evaluated and the rules the user can't see are not returned")
+	require.Equal(t, "slow", result[0].UID)
+	require.Equal(t, 5.0, result[0].AvgDurationSeconds)
+	require.Equal(t, 0.5, result[0].NoDataRate)
+	require.Equal(t, "Folder", result[0].FolderTitle)
+	require.Equal(t, []string{"broken", "fast"}, []string{result[1].UID, result[2].UID})
+
+	result = toRuleInsights(rules, namespaces, insights, "failure_rate", 1)
+	require.Len(t, result, 1)
+	require.Equal(t, "broken", result[0].UID)
+	require.Equal(t, 0.75, result[0].FailureRate)
+	require.Equal(t, "timeout", result[0].LastError)
+	require.Equal(t, lastErrorAt, *result[0].LastErrorAt)
+}
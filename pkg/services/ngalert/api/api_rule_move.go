@@ -0,0 +1,107 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func (srv RulerSrv) RoutePostMoveRule(c *models.ReqContext, body apimodels.PostableRuleMove) response.Response {
+	rule, namespace, errResp := srv.getRuleWithNamespace(c, true)
+	if errResp != nil {
+		return errResp
+	}
+	if body.RuleGroup == "" {
+		return ErrResp(http.StatusBadRequest, errors.New("rule group name is not valid"), "")
+	}
+	target := namespace
+	if body.FolderUID != "" && body.FolderUID != namespace.Uid {
+		var err error
+		if target, err = srv.store.GetNamespaceByUID(body.FolderUID, c.SignedInUser.OrgId, c.SignedInUser, true); err != nil {
+			return toNamespaceErrorResponse(err)
+		}
+	}
+	if target.Uid == rule.NamespaceUID && body.RuleGroup == rule.RuleGroup {
+		return ErrResp(http.StatusBadRequest, errors.New("the alert rule is already in the rule group"), "")
+	}
+
+	return srv.moveRules(c, []*ngmodels.AlertRule{rule}, target, body.RuleGroup, false)
+}
+
+func (srv RulerSrv) RoutePostMoveRuleGroup(c *models.ReqContext, body apimodels.PostableRuleGroupMove) response.Response {
+	namespace, err := srv.store.GetNamespaceByTitle(c.Params(":Namespace"), c.SignedInUser.OrgId, c.SignedInUser, true)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+	ruleGroup := c.Params(":Groupname")
+	q := ngmodels.ListRuleGroupAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+		RuleGroup:    ruleGroup,
+	}
+	if err := srv.store.GetRuleGroupAlertRules(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get group alert rules")
+	}
+	if len(q.Result) == 0 {
+		return ErrResp(http.StatusNotFound, fmt.Errorf("rule group %q not found", ruleGroup), "")
+	}
+
+	target := namespace
+	if body.FolderUID != "" && body.FolderUID != namespace.Uid {
+		if target, err = srv.store.GetNamespaceByUID(body.FolderUID, c.SignedInUser.OrgId, c.SignedInUser, true); err != nil {
+			return toNamespaceErrorResponse(err)
+		}
+	}
+	targetGroup := ruleGroup
+	if body.RuleGroup != "" {
+		targetGroup = body.RuleGroup
+	}
+	if target.Uid == namespace.Uid && targetGroup == ruleGroup {
+		return ErrResp(http.StatusBadRequest, errors.New("the rule group is already in the folder"), "")
+	}
+
+	return srv.moveRules(c, q.Result, target, targetGroup, true)
+}
+
+// moveRules moves the rules to the rule group of the folder, the rule group must not exist when newGroup is true.
+func (srv RulerSrv) moveRules(c *models.ReqContext, rules []*ngmodels.AlertRule, target *models.Folder, ruleGroup string, newGroup bool) response.Response {
+	if errResp := provisionedErrResp(srv.checkRulesNotProvisioned(c, ruleUIDs(rules))); errResp != nil {
+		return errResp
+	}
+
+	if err := srv.store.MoveAlertRules(store.MoveAlertRulesCmd{
+		OrgID:        c.SignedInUser.OrgId,
+		Rules:        rules,
+		NamespaceUID: target.Uid,
+		RuleGroup:    ruleGroup,
+		NewGroup:     newGroup,
+		UpdatedBy:    c.SignedInUser.Login,
+	}); err != nil {
+		switch {
+		case errors.Is(err, ngmodels.ErrAlertRuleFailedValidation):
+			return ErrResp(http.StatusBadRequest, err, "failed to move the alert rules")
+		case errors.Is(err, ngmodels.ErrAlertRuleVersionConflict),
+			errors.Is(err, ngmodels.ErrAlertRuleUniqueConstraintViolation),
+			errors.Is(err, ngmodels.ErrRuleGroupExists):
+			return ErrResp(http.StatusConflict, err, "failed to move the alert rules")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to move the alert rules")
+	}
+
+	for _, r := range rules {
+		srv.manager.RemoveByRuleUID(c.SignedInUser.OrgId, r.UID)
+	}
+
+	return response.JSON(http.StatusAccepted, util.DynMap{
+		"message":    fmt.Sprintf("%d alert rules moved", len(rules)),
+		"folder_uid": target.Uid,
+		"rule_group": ruleGroup,
+	})
+}
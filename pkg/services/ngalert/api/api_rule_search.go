@@ -0,0 +1,195 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+const (
+	defaultRuleSearchLimit = 100
+	maxRuleSearchLimit     = 1000
+)
+
+var ruleSearchStates = map[string]struct{}{
+	"firing":   {},
+	"pending":  {},
+	"inactive": {},
+	"error":    {},
+	"nodata":   {},
+}
+
+// ruleSearchFilter are the filters of a rule search that the database can't apply.
+type ruleSearchFilter struct {
+	matchers      []*labels.Matcher
+	datasourceUID string
+	states        map[string]struct{}
+}
+
+func (srv RulerSrv) RouteSearchRules(c *models.ReqContext) response.Response {
+	page, limit := 1, defaultRuleSearchLimit
+	var err error
+	if c.Query("page") != "" {
+		if page, err = strconv.Atoi(c.Query("page")); err != nil || page < 1 {
+			return ErrResp(http.StatusBadRequest, fmt.Errorf("page must be a positive number"), "invalid page")
+		}
+	}
+	if c.Query("limit") != "" {
+		if limit, err = strconv.Atoi(c.Query("limit")); err != nil || limit < 1 || limit > maxRuleSearchLimit {
+			return ErrResp(http.StatusBadRequest, fmt.Errorf("limit must be between 1 and %d", maxRuleSearchLimit), "invalid limit")
+		}
+	}
+
+	query := ngmodels.SearchAlertRulesQuery{
+		OrgID:  c.SignedInUser.OrgId,
+		Text:   c.Query("query"),
+		Labels: map[string]string{},
+		SortBy: ngmodels.AlertRulesSortByTitle,
+	}
+	sortBy := c.Query("sort")
+	if strings.HasPrefix(sortBy, "-") {
+		query.Desc = true
+		sortBy = sortBy[1:]
+	}
+	switch ngmodels.AlertRulesSortBy(sortBy) {
+	case "", ngmodels.AlertRulesSortByTitle:
+	case ngmodels.AlertRulesSortByUpdated:
+		query.SortBy = ngmodels.AlertRulesSortByUpdated
+	default:
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("unknown sort %q", c.Query("sort")), "invalid sort")
+	}
+
+	filter := ruleSearchFilter{datasourceUID: c.Query("datasource_uid")}
+	for _, m := range c.QueryStrings("matcher") {
+		matcher, err := labels.ParseMatcher(m)
+		if err != nil {
+			return ErrResp(http.StatusBadRequest, err, "invalid matcher %q", m)
+		}
+		// The rules that have the label are found by the database, a label with an empty value matches the
+		// rules that don't have the label.
+		if matcher.Type == labels.MatchEqual && matcher.Value != "" {
+			query.Labels[matcher.Name] = matcher.Value
+		}
+		filter.matchers = append(filter.matchers, matcher)
+	}
+	for _, s := range c.QueryStrings("state") {
+		if _, ok := ruleSearchStates[s]; !ok {
+			return ErrResp(http.StatusBadRequest, fmt.Errorf("unknown state %q", s), "invalid state")
+		}
+		if filter.states == nil {
+			filter.states = map[string]struct{}{}
+		}
+		filter.states[s] = struct{}{}
+	}
+
+	namespaces, err := srv.store.GetNamespaces(c.SignedInUser.OrgId, c.SignedInUser)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get namespaces visible to the user")
+	}
+	if folderUIDs := c.QueryStrings("folder_uid"); len(folderUIDs) > 0 {
+		for _, uid := range folderUIDs {
+			if _, ok := namespaces[uid]; ok {
+				query.NamespaceUIDs = append(query.NamespaceUIDs, uid)
+			}
+		}
+	} else {
+		for uid := range namespaces {
+			query.NamespaceUIDs = append(query.NamespaceUIDs, uid)
+		}
+	}
+
+	result := apimodels.RuleSearchResult{Rules: []apimodels.RuleSearchHit{}, Page: page, Limit: limit}
+	// Without a namespace the query would return the rules of all folders.
+	if len(query.NamespaceUIDs) == 0 {
+		return response.JSON(http.StatusOK, result)
+	}
+	if err := srv.store.SearchAlertRules(&query); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to search the alert rules")
+	}
+	provenances, err := srv.provenanceStore.GetProvenances(c.SignedInUser.OrgId, ngmodels.ResourceTypeAlertRule)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the provenance of the rules")
+	}
+
+	start := (page - 1) * limit
+	for _, r := range query.Result {
+		ruleState, health := ruleStateAndHealth(srv.manager.GetStatesForRuleUID(c.SignedInUser.OrgId, r.UID))
+		if !filter.matches(r, ruleState, health) {
+			continue
+		}
+		if result.Total >= start && result.Total < start+limit {
+			namespace := namespaces[r.NamespaceUID]
+			result.Rules = append(result.Rules, apimodels.RuleSearchHit{
+				Rule:        toGettableExtendedRuleNode(*r, namespace.Id, provenances[r.UID]),
+				FolderTitle: namespace.Title,
+				State:       ruleState,
+				Health:      health,
+			})
+		}
+		result.Total++
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (f ruleSearchFilter) matches(r *ngmodels.AlertRule, ruleState, health string) bool {
+	for _, m := range f.matchers {
+		if !m.Matches(r.Labels[m.Name]) {
+			return false
+		}
+	}
+	if f.datasourceUID != "" {
+		found := false
+		for _, q := range r.Data {
+			if q.DatasourceUID == f.datasourceUID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.states != nil {
+		_, stateOK := f.states[ruleState]
+		_, healthOK := f.states[health]
+		if !stateOK && !healthOK {
+			return false
+		}
+	}
+	return true
+}
+
+// ruleStateAndHealth returns the state and the health of a rule from the states of its alerts, the same way as
+// the Prometheus compatible rules API.
+func ruleStateAndHealth(states []*state.State) (string, string) {
+	ruleState, health := "inactive", "ok"
+	for _, s := range states {
+		switch s.State {
+		case eval.Normal:
+		case eval.Pending:
+			if ruleState == "inactive" {
+				ruleState = "pending"
+			}
+		case eval.Alerting:
+			ruleState = "firing"
+		case eval.Error:
+			health = "error"
+		case eval.NoData:
+			health = "nodata"
+		}
+		if s.Error != nil {
+			health = "error"
+		}
+	}
+	return ruleState, health
+}
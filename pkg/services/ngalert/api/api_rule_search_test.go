@@ -0,0 +1,55 @@
+package api
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestRuleStateAndHealth(t *testing.T) {
+	testCases := []struct {
+		name   string
+		states []*state.State
+		state  string
+		health string
+	}{
+		{name: "no alerts", state: "inactive", health: "ok"},
+		{name: "pending", states: []*state.State{{State: eval.Normal}, {State: eval.Pending}}, state: "pending", health: "ok"},
+		{name: "firing wins over pending", states: []*state.State{{State: eval.Alerting}, {State: eval.Pending}}, state: "firing", health: "ok"},
+		{name: "no data", states: []*state.State{{State: eval.NoData}}, state: "inactive", health: "nodata"},
+		{name: "error", states: []*state.State{{State: eval.Alerting, Error: errors.New("failed")}}, state: "firing", health: "error"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ruleState, health := ruleStateAndHealth(tc.states)
+			require.Equal(t, tc.state, ruleState)
+			require.Equal(t, tc.health, health)
+		})
+	}
+}
+
+func TestRuleSearchFilter(t *testing.T) {
+	rule := &ngmodels.AlertRule{
+		Labels: map[string]string{"team": "sre", "severity": "critical"},
+		Data:   []ngmodels.AlertQuery{{RefID: "A", DatasourceUID: "prometheus"}, {RefID: "B", DatasourceUID: "-100"}},
+	}
+	matcher := func(s string) *labels.Matcher {
+		m, err := labels.ParseMatcher(s)
+		require.NoError(t, err)
+		return m
+	}
+
+	require.True(t, ruleSearchFilter{}.matches(rule, "inactive", "ok"))
+	require.True(t, ruleSearchFilter{matchers: []*labels.Matcher{matcher(`team=~"sre|ops"`), matcher(`service=""`)}}.matches(rule, "inactive", "ok"))
+	require.False(t, ruleSearchFilter{matchers: []*labels.Matcher{matcher(`severity!="critical"`)}}.matches(rule, "inactive", "ok"))
+	require.True(t, ruleSearchFilter{datasourceUID: "prometheus"}.matches(rule, "inactive", "ok"))
+	require.False(t, ruleSearchFilter{datasourceUID: "loki"}.matches(rule, "inactive", "ok"))
+	require.True(t, ruleSearchFilter{states: map[string]struct{}{"firing": {}, "error": {}}}.matches(rule, "inactive", "error"))
+	require.False(t, ruleSearchFilter{states: map[string]struct{}{"firing": {}}}.matches(rule, "pending", "ok"))
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+const (
+	defaultShadowComparisonPeriod = 24 * time.Hour
+	maxShadowComparisonPeriod     = 7 * 24 * time.Hour
+)
+
+func (srv RulerSrv) RouteGetShadowRuleComparison(c *models.ReqContext) response.Response {
+	if srv.stateAnnotations == nil {
+		return ErrResp(http.StatusBadRequest, errors.New("the comparison of shadow rules requires state_annotations_backend to be table"), "")
+	}
+	period := defaultShadowComparisonPeriod
+	if c.Query("period") != "" {
+		d, err := model.ParseDuration(c.Query("period"))
+		if err != nil || d <= 0 || time.Duration(d) > maxShadowComparisonPeriod {
+			return ErrResp(http.StatusBadRequest, fmt.Errorf("period must be a duration up to %s", model.Duration(maxShadowComparisonPeriod)), "invalid period")
+		}
+		period = time.Duration(d)
+	}
+
+	shadow, _, errResp := srv.getRuleWithNamespace(c, false)
+	if errResp != nil {
+		return errResp
+	}
+	if !shadow.IsShadow() {
+		return ErrResp(http.StatusBadRequest, fmt.Errorf("alert rule %q is not a shadow rule", shadow.UID), "")
+	}
+	q := ngmodels.GetAlertRuleByUIDQuery{UID: shadow.ShadowOf, OrgID: c.SignedInUser.OrgId}
+	if err := srv.store.GetAlertRuleByUID(&q); err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+			return ErrResp(http.StatusNotFound, err, "original rule %q", shadow.ShadowOf)
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the original rule")
+	}
+	original := q.Result
+	// the user must see the states of both rules
+	if _, err := srv.store.GetNamespaceByUID(original.NamespaceUID, c.SignedInUser.OrgId, c.SignedInUser, false); err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	to := timeNow()
+	from := to.Add(-period)
+	result := apimodels.ShadowRuleComparison{From: from, To: to}
+	for _, t := range []struct {
+		rule     *ngmodels.AlertRule
+		timeline *apimodels.ShadowRuleTimeline
+	}{{original, &result.Original}, {shadow, &result.Shadow}} {
+		aq := ngmodels.GetAlertStateAnnotationsQuery{OrgID: c.SignedInUser.OrgId, RuleUID: t.rule.UID, From: from.UnixNano() / int64(time.Millisecond)}
+		if err := srv.stateAnnotations.GetAlertStateAnnotations(&aq); err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "failed to get the changes of the states of alert rule %q", t.rule.UID)
+		}
+		var states []*state.State
+		if srv.manager != nil {
+			states = srv.manager.GetStatesForRuleUID(t.rule.OrgID, t.rule.UID)
+		}
+		*t.timeline = toShadowRuleTimeline(t.rule, aq.Result, states, from, to)
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+// toShadowRuleTimeline returns the timeline of the states of the alerts of the rule between from and to, from the
+// annotations of the changes of the states in the period, the most recent first. The alerts firing since before the
+// period without a change of state have no annotation, they are found in the current states of the rule.
+func toShadowRuleTimeline(rule *ngmodels.AlertRule, annotations []*ngmodels.AlertStateAnnotation, states []*state.State, from, to time.Time) apimodels.ShadowRuleTimeline {
+	timeline := apimodels.ShadowRuleTimeline{
+		UID:         rule.UID,
+		Title:       rule.Title,
+		Transitions: make([]apimodels.RuleStateTransition, 0, len(annotations)),
+	}
+	var firing time.Duration
+	firingSince := map[string]time.Time{}
+	seen := map[string]struct{}{}
+	for i := len(annotations) - 1; i >= 0; i-- {
+		a := annotations[i]
+		at := time.Unix(0, a.Epoch*int64(time.Millisecond))
+		timeline.Transitions = append(timeline.Transitions, apimodels.RuleStateTransition{
+			Time:      at,
+			Labels:    a.Labels,
+			PrevState: a.PrevState,
+			NewState:  a.NewState,
+		})
+
+		instance := data.Labels(a.Labels).String()
+		if _, ok := seen[instance]; !ok {
+			seen[instance] = struct{}{}
+			// the first change of the period tells the state of the alert at its start
+			if a.PrevState == eval.Alerting.String() {
+				firingSince[instance] = from
+			}
+		}
+		since, isFiring := firingSince[instance]
+		switch {
+		case a.NewState == eval.Alerting.String() && !isFiring:
+			timeline.FiringCount++
+			firingSince[instance] = at
+		case a.NewState != eval.Alerting.String() && isFiring:
+			firing += at.Sub(since)
+			delete(firingSince, instance)
+		}
+	}
+	for _, since := range firingSince {
+		firing += to.Sub(since)
+	}
+	for _, s := range states {
+		if s.State == eval.Alerting && s.StartsAt.Before(from) {
+			firing += to.Sub(from)
+		}
+	}
+	timeline.FiringSeconds = firing.Seconds()
+	return timeline
+}
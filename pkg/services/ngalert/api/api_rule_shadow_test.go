@@ -0,0 +1,52 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestToShadowRuleTimeline(t *testing.T) {
+	from := time.Date(2021, 10, 14, 0, 0, 0, 0, time.UTC)
+	to := from.Add(10 * time.Hour)
+	annotation := func(instance, prev, new string, at time.Duration) *ngmodels.AlertStateAnnotation {
+		return &ngmodels.AlertStateAnnotation{
+			RuleUID:   "rule",
+			Labels:    map[string]string{"instance": instance},
+			PrevState: prev,
+			NewState:  new,
+			Epoch:     from.Add(at).UnixNano() / int64(time.Millisecond),
+		}
+	}
+	// the most recent first, like the store returns them
+	annotations := []*ngmodels.AlertStateAnnotation{
+		annotation("b", "Alerting", "Normal", 3*time.Hour),
+		annotation("a", "Pending", "Alerting", 2*time.Hour),
+		annotation("a", "Normal", "Pending", time.Hour),
+		annotation("c", "Normal", "Alerting", 30*time.Minute),
+		annotation("c", "Alerting", "Normal", 0),
+	}
+	states := []*state.State{
+		{State: eval.Alerting, StartsAt: from.Add(-time.Hour)},
+		{State: eval.Alerting, StartsAt: from.Add(2 * time.Hour)},
+		{State: eval.Normal, StartsAt: from.Add(-time.Hour)},
+	}
+
+	timeline := toShadowRuleTimeline(&ngmodels.AlertRule{UID: "rule", Title: "disk full"}, annotations, states, from, to)
+	require.Equal(t, "rule", timeline.UID)
+	require.Len(t, timeline.Transitions, 5)
+	require.Equal(t, from, timeline.Transitions[0].Time.UTC(), "the oldest transition is first")
+	require.Equal(t, 2, timeline.FiringCount)
+	// a fires for 8h, b for 3h from the start, c for 9h30m, and the alert firing since before the period for 10h
+	require.Equal(t, (30*time.Hour + 30*time.Minute).Seconds(), timeline.FiringSeconds)
+
+	empty := toShadowRuleTimeline(&ngmodels.AlertRule{UID: "shadow"}, nil, nil, from, to)
+	require.NotNil(t, empty.Transitions)
+	require.Zero(t, empty.FiringCount)
+	require.Zero(t, empty.FiringSeconds)
+}
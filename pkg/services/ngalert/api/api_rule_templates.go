@@ -0,0 +1,346 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// defaultRuleTemplateInterval is the interval of the rule groups created by the instantiation of a rule template
+// without an interval.
+const defaultRuleTemplateInterval = time.Minute
+
+// RuleTemplateSrv manages the rule templates, and the rules instantiated from them with the rule store of the ruler.
+type RuleTemplateSrv struct {
+	RulerSrv
+	templates store.RuleTemplateStore
+}
+
+func (srv RuleTemplateSrv) RouteGetRuleTemplates(c *models.ReqContext) response.Response {
+	templates, err := srv.templates.ListRuleTemplates(c.SignedInUser.OrgId)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the rule templates")
+	}
+	result := make(apimodels.GettableRuleTemplates, 0, len(templates))
+	for _, t := range templates {
+		result = append(result, toGettableRuleTemplate(t))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv RuleTemplateSrv) RouteGetRuleTemplate(c *models.ReqContext) response.Response {
+	t, errResp := srv.getRuleTemplate(c)
+	if errResp != nil {
+		return errResp
+	}
+	instances, err := srv.templates.ListRuleTemplateInstances(c.SignedInUser.OrgId, t.UID)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the instances of the rule template")
+	}
+
+	result := toGettableRuleTemplate(t)
+	for _, i := range instances {
+		result.Instances = append(result.Instances, apimodels.RuleTemplateInstance{
+			RuleUID:         i.RuleUID,
+			Parameters:      i.Parameters,
+			TemplateVersion: i.TemplateVersion,
+		})
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv RuleTemplateSrv) RoutePostRuleTemplate(c *models.ReqContext, body apimodels.PostableRuleTemplate) response.Response {
+	t := &ngmodels.RuleTemplate{
+		OrgID:       c.SignedInUser.OrgId,
+		UID:         body.UID,
+		Title:       body.Title,
+		Description: body.Description,
+		Parameters:  make(ngmodels.RuleTemplateParameters, 0, len(body.Parameters)),
+		Rule:        string(body.Rule),
+		UpdatedBy:   c.SignedInUser.Login,
+	}
+	for _, p := range body.Parameters {
+		t.Parameters = append(t.Parameters, ngmodels.RuleTemplateParameter{
+			Name:        p.Name,
+			Type:        ngmodels.RuleTemplateParameterType(p.Type),
+			Description: p.Description,
+			Default:     p.Default,
+		})
+	}
+	if err := validateRuleTemplate(t); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+
+	if err := srv.templates.SaveRuleTemplate(t); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to save the rule template")
+	}
+	return response.JSON(http.StatusAccepted, toGettableRuleTemplate(t))
+}
+
+func (srv RuleTemplateSrv) RouteDeleteRuleTemplate(c *models.ReqContext) response.Response {
+	if err := srv.templates.DeleteRuleTemplate(c.SignedInUser.OrgId, c.Params(":TemplateUID")); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to delete the rule template")
+	}
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": "rule template deleted"})
+}
+
+func (srv RuleTemplateSrv) RoutePostRuleTemplateInstance(c *models.ReqContext, body apimodels.PostableRuleTemplateInstance) response.Response {
+	t, errResp := srv.getRuleTemplate(c)
+	if errResp != nil {
+		return errResp
+	}
+	if body.RuleGroup == "" {
+		return ErrResp(http.StatusBadRequest, errors.New("rule group name is not valid"), "")
+	}
+	namespace, err := srv.store.GetNamespaceByUID(body.FolderUID, c.SignedInUser.OrgId, c.SignedInUser, true)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	rule, errResp := srv.renderRuleTemplate(c, t, body.Parameters)
+	if errResp != nil {
+		return errResp
+	}
+	rule.UID = util.GenerateShortUID()
+	rule.IntervalSeconds = int64(defaultRuleTemplateInterval.Seconds())
+	if body.Interval > 0 {
+		rule.IntervalSeconds = int64(time.Duration(body.Interval).Seconds())
+	}
+	if errResp := srv.checkRuleQuota(c, 1); errResp != nil {
+		return errResp
+	}
+
+	if err := srv.store.MoveAlertRules(store.MoveAlertRulesCmd{
+		OrgID:           c.SignedInUser.OrgId,
+		Rules:           []*ngmodels.AlertRule{&rule},
+		NamespaceUID:    namespace.Uid,
+		RuleGroup:       body.RuleGroup,
+		Copy:            true,
+		UpdatedBy:       c.SignedInUser.Login,
+		UpdatedByUserID: c.SignedInUser.UserId,
+	}); err != nil {
+		switch {
+		case errors.Is(err, ngmodels.ErrAlertRuleFailedValidation):
+			return ErrResp(http.StatusBadRequest, err, "failed to create the alert rule")
+		case errors.Is(err, ngmodels.ErrAlertRuleUniqueConstraintViolation):
+			return ErrResp(http.StatusConflict, err, "failed to create the alert rule")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to create the alert rule")
+	}
+
+	if err := srv.templates.SaveRuleTemplateInstance(&ngmodels.RuleTemplateInstance{
+		OrgID:           c.SignedInUser.OrgId,
+		TemplateUID:     t.UID,
+		RuleUID:         rule.UID,
+		Parameters:      body.Parameters,
+		TemplateVersion: t.Version,
+	}); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to save the rule template of alert rule %q", rule.UID)
+	}
+
+	return response.JSON(http.StatusAccepted, apimodels.RuleTemplateResult{
+		Message: "alert rule created from the rule template",
+		UIDs:    []string{rule.UID},
+	})
+}
+
+func (srv RuleTemplateSrv) RoutePostRuleTemplateInstancesUpdate(c *models.ReqContext) response.Response {
+	t, errResp := srv.getRuleTemplate(c)
+	if errResp != nil {
+		return errResp
+	}
+	instances, err := srv.templates.ListRuleTemplateInstances(c.SignedInUser.OrgId, t.UID)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the instances of the rule template")
+	}
+
+	upserts := make([]store.UpsertRule, 0, len(instances))
+	uids := make([]string, 0, len(instances))
+	writable := map[string]struct{}{}
+	for _, i := range instances {
+		q := ngmodels.GetAlertRuleByUIDQuery{UID: i.RuleUID, OrgID: c.SignedInUser.OrgId}
+		if err := srv.store.GetAlertRuleByUID(&q); err != nil {
+			if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+				return ErrResp(http.StatusNotFound, err, "alert rule %q of the rule template", i.RuleUID)
+			}
+			return ErrResp(http.StatusInternalServerError, err, "failed to get alert rule %q", i.RuleUID)
+		}
+		existing := q.Result
+		if _, ok := writable[existing.NamespaceUID]; !ok {
+			if _, err := srv.store.GetNamespaceByUID(existing.NamespaceUID, c.SignedInUser.OrgId, c.SignedInUser, true); err != nil {
+				return toNamespaceErrorResponse(err)
+			}
+			writable[existing.NamespaceUID] = struct{}{}
+		}
+
+		rule, errResp := srv.renderRuleTemplate(c, t, i.Parameters)
+		if errResp != nil {
+			return errResp
+		}
+		rule.UID = existing.UID
+		rule.NamespaceUID = existing.NamespaceUID
+		rule.RuleGroup = existing.RuleGroup
+		rule.RuleGroupIndex = existing.RuleGroupIndex
+		rule.IntervalSeconds = existing.IntervalSeconds
+		rule.GroupConcurrency = existing.GroupConcurrency
+		rule.IsPaused = existing.IsPaused
+		upserts = append(upserts, store.UpsertRule{
+			Existing:        existing,
+			New:             rule,
+			UpdatedBy:       c.SignedInUser.Login,
+			UpdatedByUserID: c.SignedInUser.UserId,
+		})
+		uids = append(uids, existing.UID)
+	}
+	if errResp := provisionedErrResp(srv.checkRulesNotProvisioned(c, uids)); errResp != nil {
+		return errResp
+	}
+
+	if err := srv.store.UpsertAlertRules(upserts); err != nil {
+		return updateRuleGroupErrResp(err)
+	}
+	for _, i := range instances {
+		i.TemplateVersion = t.Version
+		if err := srv.templates.SaveRuleTemplateInstance(i); err != nil {
+			return ErrResp(http.StatusInternalServerError, err, "failed to save the rule template of alert rule %q", i.RuleUID)
+		}
+		srv.manager.RemoveByRuleUID(c.SignedInUser.OrgId, i.RuleUID)
+	}
+
+	return response.JSON(http.StatusAccepted, apimodels.RuleTemplateResult{
+		Message: fmt.Sprintf("%d alert rules updated from version %d of the rule template", len(uids), t.Version),
+		UIDs:    uids,
+	})
+}
+
+func (srv RuleTemplateSrv) getRuleTemplate(c *models.ReqContext) (*ngmodels.RuleTemplate, response.Response) {
+	t, err := srv.templates.GetRuleTemplate(c.SignedInUser.OrgId, c.Params(":TemplateUID"))
+	if err != nil {
+		if errors.Is(err, ngmodels.ErrRuleTemplateNotFound) {
+			return nil, ErrResp(http.StatusNotFound, err, "")
+		}
+		return nil, ErrResp(http.StatusInternalServerError, err, "failed to get the rule template")
+	}
+	return t, nil
+}
+
+// renderRuleTemplate returns the rule rendered from the template with the values of its parameters, after
+// validating its condition.
+func (srv RuleTemplateSrv) renderRuleTemplate(c *models.ReqContext, t *ngmodels.RuleTemplate, values map[string]string) (ngmodels.AlertRule, response.Response) {
+	rule, err := renderRuleTemplate(c.SignedInUser.OrgId, t, values)
+	if err != nil {
+		return rule, ErrResp(http.StatusBadRequest, err, "failed to render the rule template")
+	}
+	cond := ngmodels.Condition{Condition: rule.Condition, OrgID: c.SignedInUser.OrgId, Data: rule.Data}
+	if err := validateCondition(cond, c.SignedInUser, c.SkipCache, srv.DatasourceCache); err != nil {
+		return rule, ErrResp(http.StatusBadRequest, err, "failed to validate alert rule %q", rule.Title)
+	}
+	return rule, nil
+}
+
+// renderRuleTemplate returns the rule rendered from the template, without its UID, folder, rule group and interval.
+func renderRuleTemplate(orgID int64, t *ngmodels.RuleTemplate, values map[string]string) (ngmodels.AlertRule, error) {
+	b, err := t.Render(values)
+	if err != nil {
+		return ngmodels.AlertRule{}, err
+	}
+	var node apimodels.PostableExtendedRuleNode
+	if err := json.Unmarshal(b, &node); err != nil {
+		return ngmodels.AlertRule{}, fmt.Errorf("%w: %s", ngmodels.ErrRuleTemplateFailedValidation, err)
+	}
+	if node.GrafanaManagedAlert == nil {
+		return ngmodels.AlertRule{}, fmt.Errorf("%w: the rule is not a Grafana managed rule", ngmodels.ErrRuleTemplateFailedValidation)
+	}
+
+	r := node.GrafanaManagedAlert
+	rule := ngmodels.AlertRule{
+		OrgID:        orgID,
+		Title:        r.Title,
+		Condition:    r.Condition,
+		Data:         r.Data,
+		NoDataState:  ngmodels.NoDataState(r.NoDataState),
+		ExecErrState: ngmodels.ExecutionErrorState(r.ExecErrState),
+		ShadowOf:     r.ShadowOf,
+
+		HoldOnDatasourceMaintenance: r.HoldOnDatasourceMaintenance,
+	}
+	if r.Composite != nil {
+		rule.Composite = *r.Composite
+	}
+	if r.Thresholds != nil {
+		rule.Thresholds = *r.Thresholds
+	}
+	if r.Heartbeat != nil {
+		rule.Heartbeat = *r.Heartbeat
+	}
+	if r.Links != nil {
+		rule.Links = *r.Links
+	}
+	if r.ActiveFrom != nil {
+		rule.ActiveFrom = *r.ActiveFrom
+	}
+	if r.ActiveUntil != nil {
+		rule.ActiveUntil = *r.ActiveUntil
+	}
+	if r.IsPaused != nil {
+		rule.IsPaused = *r.IsPaused
+	}
+	if node.ApiRuleNode != nil {
+		rule.For = time.Duration(node.ApiRuleNode.For)
+		rule.Labels = node.ApiRuleNode.Labels
+		rule.Annotations = node.ApiRuleNode.Annotations
+	}
+	return rule, nil
+}
+
+// validateRuleTemplate checks the template, and that its rule is a Grafana managed rule once rendered with sample
+// values of the parameters.
+func validateRuleTemplate(t *ngmodels.RuleTemplate) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	samples := make(map[string]string, len(t.Parameters))
+	for _, p := range t.Parameters {
+		switch {
+		case p.Default != nil:
+			samples[p.Name] = *p.Default
+		case p.Type == ngmodels.RuleTemplateParameterNumber:
+			samples[p.Name] = "0"
+		default:
+			samples[p.Name] = p.Name
+		}
+	}
+	_, err := renderRuleTemplate(t.OrgID, t, samples)
+	return err
+}
+
+func toGettableRuleTemplate(t *ngmodels.RuleTemplate) apimodels.GettableRuleTemplate {
+	result := apimodels.GettableRuleTemplate{
+		UID:         t.UID,
+		Title:       t.Title,
+		Description: t.Description,
+		Parameters:  make([]apimodels.RuleTemplateParameter, 0, len(t.Parameters)),
+		Rule:        json.RawMessage(t.Rule),
+		Version:     t.Version,
+		UpdatedBy:   t.UpdatedBy,
+		Updated:     time.Unix(t.UpdatedAt, 0).UTC(),
+	}
+	for _, p := range t.Parameters {
+		result.Parameters = append(result.Parameters, apimodels.RuleTemplateParameter{
+			Name:        p.Name,
+			Type:        string(p.Type),
+			Description: p.Description,
+			Default:     p.Default,
+		})
+	}
+	return result
+}
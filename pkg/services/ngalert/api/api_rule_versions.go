@@ -0,0 +1,211 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+func (srv RulerSrv) RouteGetRuleVersions(c *models.ReqContext) response.Response {
+	rule, namespace, errResp := srv.getRuleWithNamespace(c, false)
+	if errResp != nil {
+		return errResp
+	}
+
+	q := ngmodels.ListAlertRuleVersionsQuery{OrgID: c.SignedInUser.OrgId, RuleUID: rule.UID}
+	if err := srv.store.GetAlertRuleVersions(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the versions of the alert rule")
+	}
+
+	result := make(apimodels.GettableRuleVersions, 0, len(q.Result))
+	for _, v := range q.Result {
+		result = append(result, toGettableRuleVersion(v, namespace.Id))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (srv RulerSrv) RouteGetRuleVersion(c *models.ReqContext) response.Response {
+	rule, namespace, errResp := srv.getRuleWithNamespace(c, false)
+	if errResp != nil {
+		return errResp
+	}
+	version, err := strconv.ParseInt(c.Params(":Version"), 10, 64)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid version")
+	}
+	v, errResp := srv.getRuleVersion(c, rule.UID, version)
+	if errResp != nil {
+		return errResp
+	}
+	return response.JSON(http.StatusOK, toGettableRuleVersion(v, namespace.Id))
+}
+
+func (srv RulerSrv) RouteGetRuleVersionsDiff(c *models.ReqContext) response.Response {
+	rule, _, errResp := srv.getRuleWithNamespace(c, false)
+	if errResp != nil {
+		return errResp
+	}
+	from, err := strconv.ParseInt(c.Query("from"), 10, 64)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid from version")
+	}
+	// The latest version is compared when to is missing.
+	to := rule.Version
+	if c.Query("to") != "" {
+		if to, err = strconv.ParseInt(c.Query("to"), 10, 64); err != nil {
+			return ErrResp(http.StatusBadRequest, err, "invalid to version")
+		}
+	}
+
+	fromVersion, errResp := srv.getRuleVersion(c, rule.UID, from)
+	if errResp != nil {
+		return errResp
+	}
+	toVersion, errResp := srv.getRuleVersion(c, rule.UID, to)
+	if errResp != nil {
+		return errResp
+	}
+	return response.JSON(http.StatusOK, apimodels.RuleVersionDiff{
+		From:    from,
+		To:      to,
+		Changes: fromVersion.Diff(toVersion),
+	})
+}
+
+func (srv RulerSrv) RoutePostRestoreRuleVersion(c *models.ReqContext) response.Response {
+	rule, _, errResp := srv.getRuleWithNamespace(c, true)
+	if errResp != nil {
+		return errResp
+	}
+	version, err := strconv.ParseInt(c.Params(":Version"), 10, 64)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid version")
+	}
+	v, errResp := srv.getRuleVersion(c, rule.UID, version)
+	if errResp != nil {
+		return errResp
+	}
+	if errResp := provisionedErrResp(srv.checkRulesNotProvisioned(c, []string{rule.UID})); errResp != nil {
+		return errResp
+	}
+
+	cond := ngmodels.Condition{Condition: v.Condition, OrgID: c.SignedInUser.OrgId, Data: v.Data}
+	if err := validateCondition(cond, c.SignedInUser, c.SkipCache, srv.DatasourceCache); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to validate version %d of alert rule %q", version, rule.Title)
+	}
+
+	// The rule keeps its folder, rule group and the interval and concurrency of the group, the other fields are restored.
+	restored := *rule
+	restored.Title = v.Title
+	restored.Condition = v.Condition
+	restored.Data = v.Data
+	restored.NoDataState = v.NoDataState
+	restored.ExecErrState = v.ExecErrState
+	restored.For = v.For
+	restored.Annotations = v.Annotations
+	restored.Labels = v.Labels
+	restored.Composite = v.Composite
+	restored.Thresholds = v.Thresholds
+	restored.Heartbeat = v.Heartbeat
+	restored.Links = v.Links
+	restored.ShadowOf = v.ShadowOf
+	restored.ActiveFrom = v.ActiveFrom
+	restored.ActiveUntil = v.ActiveUntil
+	restored.HoldOnDatasourceMaintenance = v.HoldOnDatasourceMaintenance
+	if err := srv.store.UpsertAlertRules([]store.UpsertRule{{
+		Existing:        rule,
+		New:             restored,
+		UpdatedBy:       c.SignedInUser.Login,
+		UpdatedByUserID: c.SignedInUser.UserId,
+		RestoredFrom:    version,
+	}}); err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) {
+			return ErrResp(http.StatusBadRequest, err, "failed to restore the alert rule")
+		}
+		if errors.Is(err, ngmodels.ErrAlertRuleVersionConflict) {
+			return ErrResp(http.StatusConflict, err, "failed to restore the alert rule")
+		}
+		return ErrResp(http.StatusInternalServerError, err, "failed to restore the alert rule")
+	}
+
+	srv.manager.RemoveByRuleUID(c.SignedInUser.OrgId, rule.UID)
+
+	return response.JSON(http.StatusAccepted, util.DynMap{
+		"message": fmt.Sprintf("version %d of the alert rule restored", version),
+		"version": rule.Version + 1,
+	})
+}
+
+// getRuleWithNamespace returns the rule of the RuleUID path parameter, and its namespace if the user can view it,
+// or change it when withCanSave is true.
+func (srv RulerSrv) getRuleWithNamespace(c *models.ReqContext, withCanSave bool) (*ngmodels.AlertRule, *models.Folder, response.Response) {
+	q := ngmodels.GetAlertRuleByUIDQuery{UID: c.Params(":RuleUID"), OrgID: c.SignedInUser.OrgId}
+	if err := srv.store.GetAlertRuleByUID(&q); err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+			return nil, nil, ErrResp(http.StatusNotFound, err, "")
+		}
+		return nil, nil, ErrResp(http.StatusInternalServerError, err, "failed to get the alert rule")
+	}
+	namespace, err := srv.store.GetNamespaceByUID(q.Result.NamespaceUID, c.SignedInUser.OrgId, c.SignedInUser, withCanSave)
+	if err != nil {
+		return nil, nil, toNamespaceErrorResponse(err)
+	}
+	return q.Result, namespace, nil
+}
+
+func (srv RulerSrv) getRuleVersion(c *models.ReqContext, ruleUID string, version int64) (*ngmodels.AlertRuleVersion, response.Response) {
+	q := ngmodels.GetAlertRuleVersionQuery{OrgID: c.SignedInUser.OrgId, RuleUID: ruleUID, Version: version}
+	if err := srv.store.GetAlertRuleVersion(&q); err != nil {
+		if errors.Is(err, ngmodels.ErrAlertRuleVersionNotFound) {
+			return nil, ErrResp(http.StatusNotFound, err, "version %d", version)
+		}
+		return nil, ErrResp(http.StatusInternalServerError, err, "failed to get version %d of the alert rule", version)
+	}
+	return q.Result, nil
+}
+
+func toGettableRuleVersion(v *ngmodels.AlertRuleVersion, namespaceID int64) apimodels.GettableRuleVersion {
+	rule := ngmodels.AlertRule{
+		OrgID:           v.RuleOrgID,
+		Title:           v.Title,
+		Condition:       v.Condition,
+		Data:            v.Data,
+		Updated:         v.Created,
+		IntervalSeconds: v.IntervalSeconds,
+		Version:         v.Version,
+		UID:             v.RuleUID,
+		NamespaceUID:    v.RuleNamespaceUID,
+		RuleGroup:       v.RuleGroup,
+		NoDataState:     v.NoDataState,
+		ExecErrState:    v.ExecErrState,
+		For:             v.For,
+		Annotations:     v.Annotations,
+		Labels:          v.Labels,
+		Composite:       v.Composite,
+		Thresholds:      v.Thresholds,
+		Heartbeat:       v.Heartbeat,
+		Links:           v.Links,
+		ShadowOf:        v.ShadowOf,
+		ActiveFrom:      v.ActiveFrom,
+		ActiveUntil:     v.ActiveUntil,
+
+		HoldOnDatasourceMaintenance: v.HoldOnDatasourceMaintenance,
+		GroupConcurrency:            v.GroupConcurrency,
+	}
+	return apimodels.GettableRuleVersion{
+		Version:       v.Version,
+		ParentVersion: v.ParentVersion,
+		RestoredFrom:  v.RestoredFrom,
+		Created:       v.Created,
+		CreatedBy:     v.CreatedBy,
+		Rule:          toGettableExtendedRuleNode(rule, namespaceID, ngmodels.ProvenanceNone),
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/grafana/grafana/pkg/services/datasources"
@@ -23,10 +25,13 @@ import (
 
 type RulerSrv struct {
 	store           store.RuleStore
+	provenanceStore store.ProvisioningStore
 	DatasourceCache datasources.CacheService
 	QuotaService    *quota.QuotaService
 	manager         *state.Manager
 	log             log.Logger
+	// stateAnnotations is nil unless the annotations of the alerts are written in the dedicated table.
+	stateAnnotations store.StateAnnotationStore
 }
 
 func (srv RulerSrv) RouteDeleteNamespaceRulesConfig(c *models.ReqContext) response.Response {
@@ -36,6 +41,17 @@ func (srv RulerSrv) RouteDeleteNamespaceRulesConfig(c *models.ReqContext) respon
 		return toNamespaceErrorResponse(err)
 	}
 
+	q := ngmodels.ListNamespaceAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+	}
+	if err := srv.store.GetNamespaceAlertRules(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get namespace alert rules")
+	}
+	if errResp := provisionedErrResp(srv.checkRulesNotProvisioned(c, ruleUIDs(q.Result))); errResp != nil {
+		return errResp
+	}
+
 	uids, err := srv.store.DeleteNamespaceAlertRules(c.SignedInUser.OrgId, namespace.Uid)
 	if err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "failed to delete namespace alert rules")
@@ -55,6 +71,18 @@ func (srv RulerSrv) RouteDeleteRuleGroupConfig(c *models.ReqContext) response.Re
 		return toNamespaceErrorResponse(err)
 	}
 	ruleGroup := c.Params(":Groupname")
+	q := ngmodels.ListRuleGroupAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+		RuleGroup:    ruleGroup,
+	}
+	if err := srv.store.GetRuleGroupAlertRules(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get group alert rules")
+	}
+	if errResp := provisionedErrResp(srv.checkRulesNotProvisioned(c, ruleUIDs(q.Result))); errResp != nil {
+		return errResp
+	}
+
 	uids, err := srv.store.DeleteRuleGroupAlertRules(c.SignedInUser.OrgId, namespace.Uid, ruleGroup)
 
 	if err != nil {
@@ -86,6 +114,11 @@ func (srv RulerSrv) RouteGetNamespaceRulesConfig(c *models.ReqContext) response.
 		return ErrResp(http.StatusInternalServerError, err, "failed to update rule group")
 	}
 
+	provenances, err := srv.provenanceStore.GetProvenances(c.SignedInUser.OrgId, ngmodels.ResourceTypeAlertRule)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the provenance of the rules")
+	}
+
 	result := apimodels.NamespaceConfigResponse{}
 	ruleGroupConfigs := make(map[string]apimodels.GettableRuleGroupConfig)
 	for _, r := range q.Result {
@@ -93,14 +126,15 @@ func (srv RulerSrv) RouteGetNamespaceRulesConfig(c *models.ReqContext) response.
 		if !ok {
 			ruleGroupInterval := model.Duration(time.Duration(r.IntervalSeconds) * time.Second)
 			ruleGroupConfigs[r.RuleGroup] = apimodels.GettableRuleGroupConfig{
-				Name:     r.RuleGroup,
-				Interval: ruleGroupInterval,
+				Name:        r.RuleGroup,
+				Interval:    ruleGroupInterval,
+				Concurrency: r.GroupConcurrency,
 				Rules: []apimodels.GettableExtendedRuleNode{
-					toGettableExtendedRuleNode(*r, namespace.Id),
+					toGettableExtendedRuleNode(*r, namespace.Id, provenances[r.UID]),
 				},
 			}
 		} else {
-			ruleGroupConfig.Rules = append(ruleGroupConfig.Rules, toGettableExtendedRuleNode(*r, namespace.Id))
+			ruleGroupConfig.Rules = append(ruleGroupConfig.Rules, toGettableExtendedRuleNode(*r, namespace.Id, provenances[r.UID]))
 			ruleGroupConfigs[r.RuleGroup] = ruleGroupConfig
 		}
 	}
@@ -129,32 +163,46 @@ func (srv RulerSrv) RouteGetRulegGroupConfig(c *models.ReqContext) response.Resp
 		return ErrResp(http.StatusInternalServerError, err, "failed to get group alert rules")
 	}
 
+	provenances, err := srv.provenanceStore.GetProvenances(c.SignedInUser.OrgId, ngmodels.ResourceTypeAlertRule)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the provenance of the rules")
+	}
+
 	var ruleGroupInterval model.Duration
+	var ruleGroupConcurrency int64
 	ruleNodes := make([]apimodels.GettableExtendedRuleNode, 0, len(q.Result))
 	for _, r := range q.Result {
 		ruleGroupInterval = model.Duration(time.Duration(r.IntervalSeconds) * time.Second)
-		ruleNodes = append(ruleNodes, toGettableExtendedRuleNode(*r, namespace.Id))
+		ruleGroupConcurrency = r.GroupConcurrency
+		ruleNodes = append(ruleNodes, toGettableExtendedRuleNode(*r, namespace.Id, provenances[r.UID]))
 	}
 
 	result := apimodels.RuleGroupConfigResponse{
 		GettableRuleGroupConfig: apimodels.GettableRuleGroupConfig{
-			Name:     ruleGroup,
-			Interval: ruleGroupInterval,
-			Rules:    ruleNodes,
+			Name:        ruleGroup,
+			Interval:    ruleGroupInterval,
+			Rules:       ruleNodes,
+			Concurrency: ruleGroupConcurrency,
 		},
 	}
 	return response.JSON(http.StatusAccepted, result)
 }
 
 func (srv RulerSrv) RouteGetRulesConfig(c *models.ReqContext) response.Response {
+	opts, err := parseRuleListOptions(c)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid parameters")
+	}
+
 	namespaceMap, err := srv.store.GetNamespaces(c.OrgId, c.SignedInUser)
 	if err != nil {
 		return ErrResp(http.StatusInternalServerError, err, "failed to get namespaces visible to the user")
 	}
 
-	namespaceUIDs := make([]string, len(namespaceMap))
-	for k := range namespaceMap {
-		namespaceUIDs = append(namespaceUIDs, k)
+	result := apimodels.NamespaceConfigResponse{}
+	namespaceUIDs := opts.namespaceUIDs(namespaceMap)
+	if len(namespaceUIDs) == 0 && len(opts.folderUIDs) > 0 {
+		return response.JSON(http.StatusAccepted, result).SetHeader(ruleGroupsTotalHeader, "0")
 	}
 
 	q := ngmodels.ListAlertRulesQuery{
@@ -166,50 +214,65 @@ func (srv RulerSrv) RouteGetRulesConfig(c *models.ReqContext) response.Response
 		return ErrResp(http.StatusInternalServerError, err, "failed to get alert rules")
 	}
 
-	configs := make(map[string]map[string]apimodels.GettableRuleGroupConfig)
+	provenances, err := srv.provenanceStore.GetProvenances(c.SignedInUser.OrgId, ngmodels.ResourceTypeAlertRule)
+	if err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get the provenance of the rules")
+	}
+
+	type groupKey struct {
+		namespace string
+		group     string
+	}
+	groups := make(map[groupKey][]*ngmodels.AlertRule)
 	for _, r := range q.Result {
 		folder, ok := namespaceMap[r.NamespaceUID]
 		if !ok {
 			srv.log.Error("namespace not visible to the user", "user", c.SignedInUser.UserId, "namespace", r.NamespaceUID, "rule", r.UID)
 			continue
 		}
-		namespace := folder.Title
-		_, ok = configs[namespace]
-		if !ok {
-			ruleGroupInterval := model.Duration(time.Duration(r.IntervalSeconds) * time.Second)
-			configs[namespace] = make(map[string]apimodels.GettableRuleGroupConfig)
-			configs[namespace][r.RuleGroup] = apimodels.GettableRuleGroupConfig{
-				Name:     r.RuleGroup,
-				Interval: ruleGroupInterval,
-				Rules: []apimodels.GettableExtendedRuleNode{
-					toGettableExtendedRuleNode(*r, folder.Id),
-				},
-			}
-		} else {
-			ruleGroupConfig, ok := configs[namespace][r.RuleGroup]
-			if !ok {
-				ruleGroupInterval := model.Duration(time.Duration(r.IntervalSeconds) * time.Second)
-				configs[namespace][r.RuleGroup] = apimodels.GettableRuleGroupConfig{
-					Name:     r.RuleGroup,
-					Interval: ruleGroupInterval,
-					Rules: []apimodels.GettableExtendedRuleNode{
-						toGettableExtendedRuleNode(*r, folder.Id),
-					},
-				}
-			} else {
-				ruleGroupConfig.Rules = append(ruleGroupConfig.Rules, toGettableExtendedRuleNode(*r, folder.Id))
-				configs[namespace][r.RuleGroup] = ruleGroupConfig
+		if opts.filtersRules() {
+			ruleState, health := ruleStateAndHealth(srv.manager.GetStatesForRuleUID(c.SignedInUser.OrgId, r.UID))
+			if !opts.matches(r, ruleState, health) {
+				continue
 			}
 		}
+		key := groupKey{namespace: folder.Title, group: r.RuleGroup}
+		groups[key] = append(groups[key], r)
 	}
 
-	result := apimodels.NamespaceConfigResponse{}
-	for namespace, m := range configs {
-		for _, ruleGroupConfig := range m {
-			result[namespace] = append(result[namespace], ruleGroupConfig)
+	// The groups are sorted so that the pages are stable.
+	keys := make([]groupKey, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].namespace != keys[j].namespace {
+			return keys[i].namespace < keys[j].namespace
 		}
+		return keys[i].group < keys[j].group
+	})
+
+	for i, key := range keys {
+		if !opts.inPage(i) {
+			continue
+		}
+		rules := groups[key]
+		ruleGroupConfig := apimodels.GettableRuleGroupConfig{
+			Name:        key.group,
+			Interval:    model.Duration(time.Duration(rules[0].IntervalSeconds) * time.Second),
+			Rules:       make([]apimodels.GettableExtendedRuleNode, 0, len(rules)),
+			Concurrency: rules[0].GroupConcurrency,
+		}
+		for _, r := range rules {
+			node := toGettableExtendedRuleNode(*r, namespaceMap[r.NamespaceUID].Id, provenances[r.UID])
+			if opts.excludeQueries {
+				node.GrafanaManagedAlert.Data = nil
+			}
+			ruleGroupConfig.Rules = append(ruleGroupConfig.Rules, node)
+		}
+		result[key.namespace] = append(result[key.namespace], ruleGroupConfig)
 	}
-	return response.JSON(http.StatusAccepted, result)
+	return response.JSON(http.StatusAccepted, result).SetHeader(ruleGroupsTotalHeader, strconv.Itoa(len(keys)))
 }
 
 func (srv RulerSrv) RoutePostNameRulesConfig(c *models.ReqContext, ruleGroupConfig apimodels.PostableRuleGroupConfig) response.Response {
@@ -219,9 +282,68 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *models.ReqContext, ruleGroupConf
 		return toNamespaceErrorResponse(err)
 	}
 
+	return srv.updateAlertRulesInGroup(c, namespace, ruleGroupConfig)
+}
+
+// updateAlertRulesInGroup validates the rules of the group and replaces the rules of the group in the namespace with them.
+func (srv RulerSrv) updateAlertRulesInGroup(c *models.ReqContext, namespace *models.Folder, ruleGroupConfig apimodels.PostableRuleGroupConfig) response.Response {
+	alertRuleUIDs, errResp := srv.validateRuleGroup(c, ruleGroupConfig)
+	if errResp != nil {
+		return errResp
+	}
+
+	// Neither the provisioned rules of the group nor the provisioned rules moved to the group can be changed.
+	q := ngmodels.ListRuleGroupAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+		RuleGroup:    ruleGroupConfig.Name,
+	}
+	if err := srv.store.GetRuleGroupAlertRules(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get group alert rules")
+	}
+	uids := ruleUIDs(q.Result)
+	for uid := range alertRuleUIDs {
+		uids = append(uids, uid)
+	}
+	if errResp := provisionedErrResp(srv.checkRulesNotProvisioned(c, uids)); errResp != nil {
+		return errResp
+	}
+
+	if errResp := srv.checkRuleQuota(c, len(ruleGroupConfig.Rules)-len(alertRuleUIDs)); errResp != nil {
+		return errResp
+	}
+	if len(q.Result) == 0 && len(ruleGroupConfig.Rules) > 0 {
+		if errResp := quotaErrResp(srv.QuotaService.CheckQuota(c, quotaTargetAlertRuleGroup, 1)); errResp != nil {
+			return errResp
+		}
+	}
+
+	if err := srv.store.UpdateRuleGroup(store.UpdateRuleGroupCmd{
+		OrgID:           c.SignedInUser.OrgId,
+		NamespaceUID:    namespace.Uid,
+		RuleGroupConfig: ruleGroupConfig,
+		UpdatedBy:       c.SignedInUser.Login,
+		UpdatedByUserID: c.SignedInUser.UserId,
+	}); err != nil {
+		return updateRuleGroupErrResp(err)
+	}
+
+	for uid := range alertRuleUIDs {
+		srv.manager.RemoveByRuleUID(c.OrgId, uid)
+	}
+
+	return response.JSON(http.StatusAccepted, util.DynMap{"message": "rule group updated successfully"})
+}
+
+// validateRuleGroup validates the rules of the group, and returns the UIDs of the rules that have one.
+func (srv RulerSrv) validateRuleGroup(c *models.ReqContext, ruleGroupConfig apimodels.PostableRuleGroupConfig) (map[string]struct{}, response.Response) {
 	//TODO: Should this belong in alerting-api?
 	if ruleGroupConfig.Name == "" {
-		return ErrResp(http.StatusBadRequest, errors.New("rule group name is not valid"), "")
+		return nil, ErrResp(http.StatusBadRequest, errors.New("rule group name is not valid"), "")
+	}
+
+	if ruleGroupConfig.Concurrency < 0 {
+		return nil, ErrResp(http.StatusBadRequest, errors.New("rule group concurrency should not be negative"), "")
 	}
 
 	alertRuleUIDs := make(map[string]struct{})
@@ -232,53 +354,79 @@ func (srv RulerSrv) RoutePostNameRulesConfig(c *models.ReqContext, ruleGroupConf
 			Data:      r.GrafanaManagedAlert.Data,
 		}
 		if err := validateCondition(cond, c.SignedInUser, c.SkipCache, srv.DatasourceCache); err != nil {
-			return ErrResp(http.StatusBadRequest, err, "failed to validate alert rule %q", r.GrafanaManagedAlert.Title)
+			return nil, ErrResp(http.StatusBadRequest, err, "failed to validate alert rule %q", r.GrafanaManagedAlert.Title)
 		}
 		if r.GrafanaManagedAlert.UID != "" {
 			_, ok := alertRuleUIDs[r.GrafanaManagedAlert.UID]
 			if ok {
-				return ErrResp(http.StatusBadRequest, fmt.Errorf("conflicting UID %q found", r.GrafanaManagedAlert.UID), "failed to validate alert rule %q", r.GrafanaManagedAlert.Title)
+				return nil, ErrResp(http.StatusBadRequest, fmt.Errorf("conflicting UID %q found", r.GrafanaManagedAlert.UID), "failed to validate alert rule %q", r.GrafanaManagedAlert.Title)
 			}
 			alertRuleUIDs[r.GrafanaManagedAlert.UID] = struct{}{}
 		}
 	}
+	return alertRuleUIDs, nil
+}
 
-	numOfNewRules := len(ruleGroupConfig.Rules) - len(alertRuleUIDs)
-	if numOfNewRules > 0 {
-		// quotas are checked in advanced
-		// that is acceptable under the assumption that there will be only one alert rule under the rule group
-		// alternatively we should check the quotas after the rule group update
-		// and rollback the transaction in case of violation
-		limitReached, err := srv.QuotaService.QuotaReached(c, "alert_rule")
-		if err != nil {
-			return ErrResp(http.StatusInternalServerError, err, "failed to get quota")
-		}
-		if limitReached {
-			return ErrResp(http.StatusForbidden, errors.New("quota reached"), "")
-		}
+// checkRuleQuota returns an error response if the quota of alert rules doesn't allow the new rules.
+func (srv RulerSrv) checkRuleQuota(c *models.ReqContext, numOfNewRules int) response.Response {
+	// quotas are checked in advanced
+	// alternatively we should check the quotas after the rule group update
+	// and rollback the transaction in case of violation
+	return quotaErrResp(srv.QuotaService.CheckQuota(c, quotaTargetAlertRule, int64(numOfNewRules)))
+}
+
+// toCompositeCondition returns the condition of a composite rule, and nil for the other rules.
+func toCompositeCondition(c ngmodels.CompositeCondition) *ngmodels.CompositeCondition {
+	if !c.IsComposite() {
+		return nil
 	}
+	return &c
+}
 
-	if err := srv.store.UpdateRuleGroup(store.UpdateRuleGroupCmd{
-		OrgID:           c.SignedInUser.OrgId,
-		NamespaceUID:    namespace.Uid,
-		RuleGroupConfig: ruleGroupConfig,
-	}); err != nil {
-		if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
-			return ErrResp(http.StatusNotFound, err, "failed to update rule group")
-		} else if errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) {
-			return ErrResp(http.StatusBadRequest, err, "failed to update rule group")
-		}
-		return ErrResp(http.StatusInternalServerError, err, "failed to update rule group")
+// toThresholds returns the thresholds of a multi-threshold rule, and nil for the other rules.
+func toThresholds(t ngmodels.Thresholds) *ngmodels.Thresholds {
+	if t.IsEmpty() {
+		return nil
 	}
+	return &t
+}
 
-	for uid := range alertRuleUIDs {
-		srv.manager.RemoveByRuleUID(c.OrgId, uid)
+// toHeartbeatCondition returns the condition of a heartbeat rule, and nil for the other rules.
+func toHeartbeatCondition(c ngmodels.HeartbeatCondition) *ngmodels.HeartbeatCondition {
+	if !c.IsHeartbeat() {
+		return nil
 	}
+	return &c
+}
 
-	return response.JSON(http.StatusAccepted, util.DynMap{"message": "rule group updated successfully"})
+// toRuleLinks returns the links of a rule, and nil for the rules without links.
+func toRuleLinks(l ngmodels.RuleLinks) *ngmodels.RuleLinks {
+	if l.IsEmpty() {
+		return nil
+	}
+	return &l
+}
+
+// toOptionalTime returns the time, and nil for the zero time.
+func toOptionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func updateRuleGroupErrResp(err error) response.Response {
+	if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+		return ErrResp(http.StatusNotFound, err, "failed to update rule group")
+	} else if errors.Is(err, ngmodels.ErrAlertRuleFailedValidation) {
+		return ErrResp(http.StatusBadRequest, err, "failed to update rule group")
+	} else if errors.Is(err, ngmodels.ErrAlertRuleVersionConflict) {
+		return ErrResp(http.StatusConflict, err, "failed to update rule group")
+	}
+	return ErrResp(http.StatusInternalServerError, err, "failed to update rule group")
 }
 
-func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64) apimodels.GettableExtendedRuleNode {
+func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64, provenance ngmodels.Provenance) apimodels.GettableExtendedRuleNode {
 	gettableExtendedRuleNode := apimodels.GettableExtendedRuleNode{
 		GrafanaManagedAlert: &apimodels.GettableGrafanaRule{
 			ID:              r.ID,
@@ -295,6 +443,17 @@ func toGettableExtendedRuleNode(r ngmodels.AlertRule, namespaceID int64) apimode
 			RuleGroup:       r.RuleGroup,
 			NoDataState:     apimodels.NoDataState(r.NoDataState),
 			ExecErrState:    apimodels.ExecutionErrorState(r.ExecErrState),
+			IsPaused:        r.IsPaused,
+			Composite:       toCompositeCondition(r.Composite),
+			Thresholds:      toThresholds(r.Thresholds),
+			Heartbeat:       toHeartbeatCondition(r.Heartbeat),
+			Links:           toRuleLinks(r.Links),
+			ShadowOf:        r.ShadowOf,
+			ActiveFrom:      toOptionalTime(r.ActiveFrom),
+			ActiveUntil:     toOptionalTime(r.ActiveUntil),
+			Provenance:      provenance,
+
+			HoldOnDatasourceMaintenance: r.HoldOnDatasourceMaintenance,
 		},
 	}
 	gettableExtendedRuleNode.ApiRuleNode = &apimodels.ApiRuleNode{
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func (srv RulerSrv) RoutePostSLORules(c *models.ReqContext, body apimodels.PostableSLORules) response.Response {
+	namespace, err := srv.store.GetNamespaceByUID(body.FolderUID, c.SignedInUser.OrgId, c.SignedInUser, true)
+	if err != nil {
+		return toNamespaceErrorResponse(err)
+	}
+
+	group, err := sloRuleGroup(body)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to generate the SLO rules")
+	}
+	datasourceUID, err := srv.prometheusDatasourceUID(c, body.DatasourceUID)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "")
+	}
+	if c.QueryBool("dryRun") {
+		return response.JSON(http.StatusOK, apimodels.SLORules{DryRun: true, Group: group})
+	}
+
+	q := ngmodels.ListRuleGroupAlertRulesQuery{
+		OrgID:        c.SignedInUser.OrgId,
+		NamespaceUID: namespace.Uid,
+		RuleGroup:    group.Name,
+	}
+	if err := srv.store.GetRuleGroupAlertRules(&q); err != nil {
+		return ErrResp(http.StatusInternalServerError, err, "failed to get group alert rules")
+	}
+	// Like for the Prometheus rule groups, the Grafana managed rules of the group are not overwritten.
+	if _, incompatible := grafanaRulesToPrometheusGroups(q.Result); len(incompatible) > 0 {
+		return ErrResp(http.StatusConflict, incompatible[group.Name], "rule group %q is not managed as Prometheus alerting rules", group.Name)
+	}
+
+	ruleGroupConfig, err := prometheusRuleGroupToGrafana(group, datasourceUID, q.Result)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to convert the rule group")
+	}
+	return srv.updateAlertRulesInGroup(c, namespace, ruleGroupConfig)
+}
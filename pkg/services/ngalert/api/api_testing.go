@@ -5,7 +5,13 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	prometheusModel "github.com/prometheus/common/model"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -13,11 +19,21 @@ import (
 	"github.com/grafana/grafana/pkg/services/datasources"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb"
 	"github.com/grafana/grafana/pkg/util"
 )
 
+// defaultTestedRuleInterval is the evaluation interval of the tested rules without one, the default interval of the
+// rule groups.
+const defaultTestedRuleInterval = time.Minute
+
+// maxRuleUnitTestEvaluations is the maximum number of evaluations of a rule by its unit tests, from the time 0 to the
+// evaluation time of the last test.
+const maxRuleUnitTestEvaluations = 1000
+
 type TestingApiSrv struct {
 	*AlertingProxy
 	Cfg             *setting.Cfg
@@ -91,6 +107,17 @@ func (srv TestingApiSrv) RouteEvalQueries(c *models.ReqContext, cmd apimodels.Ev
 	}
 
 	evaluator := eval.Evaluator{Cfg: srv.Cfg, Log: srv.log}
+	if cmd.Debug {
+		// The trace is returned when the evaluation fails, to show the node that failed.
+		evalResults, trace, err := evaluator.QueriesAndExpressionsEvalWithTrace(c.SignedInUser.OrgId, cmd.Data, now, srv.DataService)
+		result := apimodels.EvalQueriesTraceResponse{Trace: trace}
+		if err != nil {
+			result.Error = err.Error()
+			return response.JSONStreaming(http.StatusBadRequest, result)
+		}
+		result.Results = evalResults.Responses
+		return response.JSONStreaming(http.StatusOK, result)
+	}
 	evalResults, err := evaluator.QueriesAndExpressionsEval(c.SignedInUser.OrgId, cmd.Data, now, srv.DataService)
 	if err != nil {
 		return ErrResp(http.StatusBadRequest, err, "Failed to evaluate queries and expressions")
@@ -98,3 +125,343 @@ func (srv TestingApiSrv) RouteEvalQueries(c *models.ReqContext, cmd apimodels.Ev
 
 	return response.JSONStreaming(http.StatusOK, evalResults)
 }
+
+func (srv TestingApiSrv) RouteTestInlineRule(c *models.ReqContext, body apimodels.TestInlineRulePayload) response.Response {
+	rule, err := testedRule(c.SignedInUser.OrgId, body)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid rule")
+	}
+	cond := ngmodels.Condition{
+		Condition:   rule.Condition,
+		OrgID:       rule.OrgID,
+		Data:        rule.Data,
+		OwnerUserID: c.SignedInUser.UserId,
+	}
+	if err := validateCondition(cond, c.SignedInUser, c.SkipCache, srv.DatasourceCache); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid condition")
+	}
+
+	now := body.Now
+	if now.IsZero() {
+		now = timeNow()
+	}
+	evaluator := eval.Evaluator{Cfg: srv.Cfg, Log: srv.log}
+	results, err := evaluator.ConditionEval(&cond, now, srv.DataService)
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "failed to evaluate the rule")
+	}
+	if !rule.Thresholds.IsEmpty() {
+		results = eval.ApplyThresholds(rule.Thresholds, results)
+	}
+
+	return response.JSON(http.StatusOK, apimodels.TestInlineRuleResponse{
+		Instances: toTestedRuleInstances(state.PreviewResults(srv.log, rule, results), results),
+		Warnings:  results.Warnings(),
+	})
+}
+
+// testedRule returns the alert rule of the payload, with the defaults of the saved rules.
+func testedRule(orgID int64, body apimodels.TestInlineRulePayload) (*ngmodels.AlertRule, error) {
+	r := body.Rule.GrafanaManagedAlert
+	if r == nil {
+		return nil, errors.New("the rule is not a Grafana managed rule")
+	}
+	if r.Composite != nil || r.Heartbeat != nil {
+		return nil, errors.New("composite and heartbeat rules can't be tested")
+	}
+	interval := time.Duration(body.Interval)
+	if interval == 0 {
+		interval = defaultTestedRuleInterval
+	}
+	if interval < time.Second {
+		return nil, fmt.Errorf("interval %s is less than a second", body.Interval)
+	}
+
+	rule := &ngmodels.AlertRule{
+		OrgID:           orgID,
+		UID:             r.UID,
+		Title:           r.Title,
+		Condition:       r.Condition,
+		Data:            r.Data,
+		NamespaceUID:    body.FolderUID,
+		IntervalSeconds: int64(interval.Seconds()),
+		NoDataState:     ngmodels.NoDataState(r.NoDataState),
+		ExecErrState:    ngmodels.ExecutionErrorState(r.ExecErrState),
+	}
+	if rule.NoDataState == "" {
+		rule.NoDataState = ngmodels.NoData
+	}
+	if rule.ExecErrState == "" {
+		rule.ExecErrState = ngmodels.AlertingErrState
+	}
+	if r.Thresholds != nil {
+		if err := r.Thresholds.Validate(r.Data); err != nil {
+			return nil, err
+		}
+		rule.Thresholds = *r.Thresholds
+	}
+	if n := body.Rule.ApiRuleNode; n != nil {
+		rule.For = time.Duration(n.For)
+		rule.Labels = n.Labels
+		rule.Annotations = n.Annotations
+	}
+	return rule, nil
+}
+
+// toTestedRuleInstances returns the alerts of the states of the evaluation results, in the order of the results.
+func toTestedRuleInstances(states []*state.State, results eval.Results) []apimodels.TestedRuleInstance {
+	instances := make([]apimodels.TestedRuleInstance, 0, len(states))
+	for i, s := range states {
+		result := results[i]
+		instance := apimodels.TestedRuleInstance{
+			Labels:          map[string]string(s.Labels),
+			Annotations:     s.Annotations,
+			State:           s.State.String(),
+			EvaluationState: result.State.String(),
+			Value:           result.EvaluationString,
+		}
+		if len(result.Values) > 0 {
+			instance.Values = make(map[string]*float64, len(result.Values))
+			for refID, v := range result.Values {
+				instance.Values[refID] = v.Value
+			}
+		}
+		if result.Error != nil {
+			instance.Error = result.Error.Error()
+		}
+		instances = append(instances, instance)
+	}
+	return instances
+}
+
+func (srv TestingApiSrv) RouteRunRuleUnitTests(c *models.ReqContext, body apimodels.RuleUnitTestPayload) response.Response {
+	rule, err := testedRule(c.SignedInUser.OrgId, apimodels.TestInlineRulePayload{Rule: body.Rule, FolderUID: body.FolderUID, Interval: body.Interval})
+	if err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid rule")
+	}
+	if err := validateRuleUnitTests(rule, body); err != nil {
+		return ErrResp(http.StatusBadRequest, err, "invalid unit tests")
+	}
+
+	// The data sources are not queried, so the permissions of the user on them are not checked.
+	cond := ngmodels.Condition{
+		Condition: rule.Condition,
+		OrgID:     rule.OrgID,
+		Data:      rule.Data,
+	}
+	evaluator := eval.Evaluator{Cfg: srv.Cfg, Log: srv.log}
+	return response.JSON(http.StatusOK, runRuleUnitTests(rule, body, func(now time.Time, fixtures map[string]data.Frames) eval.Results {
+		return evaluator.ConditionEvalWithFixtures(&cond, now, fixtures)
+	}, srv.log))
+}
+
+func (srv TestingApiSrv) RouteValidateRuleTemplates(c *models.ReqContext, body apimodels.ValidateRuleTemplatesPayload) response.Response {
+	r := body.Rule.GrafanaManagedAlert
+	if r == nil {
+		return ErrResp(http.StatusBadRequest, errors.New("the rule is not a Grafana managed rule"), "invalid rule")
+	}
+	rule := &ngmodels.AlertRule{
+		OrgID:     c.SignedInUser.OrgId,
+		UID:       r.UID,
+		Title:     r.Title,
+		Condition: r.Condition,
+		Data:      r.Data,
+	}
+	if n := body.Rule.ApiRuleNode; n != nil {
+		rule.Labels = n.Labels
+		rule.Annotations = n.Annotations
+	}
+	return response.JSON(http.StatusOK, toTemplatesValidation(state.ValidateTemplates(rule)))
+}
+
+func toTemplatesValidation(v state.TemplateValidation) apimodels.TemplatesValidation {
+	toIssues := func(issues []state.TemplateIssue) []apimodels.TemplateIssue {
+		result := make([]apimodels.TemplateIssue, 0, len(issues))
+		for _, i := range issues {
+			result = append(result, apimodels.TemplateIssue{Type: i.Kind, Name: i.Name, Message: i.Message})
+		}
+		return result
+	}
+	return apimodels.TemplatesValidation{
+		Errors:   toIssues(v.Errors),
+		Warnings: toIssues(v.Warnings),
+	}
+}
+
+// validateRuleUnitTests checks that the condition of the rule exists, that each data source query of the rule has a
+// fixture, and that the tests don't evaluate the rule too many times.
+func validateRuleUnitTests(rule *ngmodels.AlertRule, body apimodels.RuleUnitTestPayload) error {
+	queries := make(map[string]struct{}, len(rule.Data))
+	condition := false
+	for _, q := range rule.Data {
+		condition = condition || q.RefID == rule.Condition
+		isExpression, err := q.IsExpression()
+		if err != nil {
+			return err
+		}
+		if isExpression {
+			continue
+		}
+		if _, ok := body.Fixtures[q.RefID]; !ok {
+			return fmt.Errorf("no fixture for query %s", q.RefID)
+		}
+		queries[q.RefID] = struct{}{}
+	}
+	if !condition {
+		return fmt.Errorf("condition %s not found in any query or expression", rule.Condition)
+	}
+	for refID := range body.Fixtures {
+		if _, ok := queries[refID]; !ok {
+			return fmt.Errorf("fixture %s is not a data source query of the rule", refID)
+		}
+	}
+
+	if len(body.Tests) == 0 {
+		return errors.New("no test")
+	}
+	interval := time.Duration(rule.IntervalSeconds) * time.Second
+	for _, test := range body.Tests {
+		if test.EvalTime < 0 {
+			return fmt.Errorf("evaluation time %s is negative", test.EvalTime)
+		}
+		if time.Duration(test.EvalTime)/interval >= maxRuleUnitTestEvaluations {
+			return fmt.Errorf("evaluation time %s is more than %d evaluations of the rule", test.EvalTime, maxRuleUnitTestEvaluations)
+		}
+	}
+	return nil
+}
+
+// runRuleUnitTests evaluates the rule at each interval from the time 0 to the evaluation time of the last test, and
+// checks the firing alerts at the evaluation time of each test. The results are in the order of the tests.
+func runRuleUnitTests(rule *ngmodels.AlertRule, body apimodels.RuleUnitTestPayload, evaluate func(now time.Time, fixtures map[string]data.Frames) eval.Results, logger log.Logger) apimodels.RuleUnitTestResult {
+	order := make([]int, len(body.Tests))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return body.Tests[order[i]].EvalTime < body.Tests[order[j]].EvalTime
+	})
+
+	result := apimodels.RuleUnitTestResult{Passed: true, Tests: make([]apimodels.RuleUnitTestCaseResult, len(body.Tests))}
+	interval := time.Duration(rule.IntervalSeconds) * time.Second
+	preview := state.NewPreview(logger)
+	var states []*state.State
+	var errs []string
+	next := time.Unix(0, 0).UTC()
+	for _, i := range order {
+		test := body.Tests[i]
+		for at := time.Unix(0, 0).UTC().Add(time.Duration(test.EvalTime)); !next.After(at); next = next.Add(interval) {
+			results := evaluate(next, fixtureFrames(rule.Data, body.Fixtures, interval, next))
+			if !rule.Thresholds.IsEmpty() {
+				results = eval.ApplyThresholds(rule.Thresholds, results)
+			}
+			states = preview.ProcessEvalResults(rule, results)
+			errs = nil
+			for _, r := range results {
+				if r.Error != nil {
+					errs = append(errs, r.Error.Error())
+				}
+			}
+		}
+		result.Tests[i] = checkRuleUnitTest(test, states, errs)
+		result.Passed = result.Passed && result.Tests[i].Passed
+	}
+	return result
+}
+
+// fixtureFrames returns the frames of the fixtures of the queries evaluated at the time: a frame by series, with the
+// values in the time range of the query. The value at the index i of a series is at the time 0 plus i intervals.
+func fixtureFrames(queries []ngmodels.AlertQuery, fixtures map[string][]apimodels.FixtureSeries, interval time.Duration, now time.Time) map[string]data.Frames {
+	frames := make(map[string]data.Frames, len(fixtures))
+	for _, q := range queries {
+		series, ok := fixtures[q.RefID]
+		if !ok {
+			continue
+		}
+		tr := q.RelativeTimeRange.ToTimeRange(now)
+		queryFrames := make(data.Frames, 0, len(series))
+		for _, s := range series {
+			times := make([]time.Time, 0, len(s.Values))
+			values := make([]*float64, 0, len(s.Values))
+			for i, v := range s.Values {
+				t := time.Unix(0, 0).UTC().Add(time.Duration(i) * interval)
+				if t.Before(tr.From) || t.After(tr.To) {
+					continue
+				}
+				times = append(times, t)
+				values = append(values, v)
+			}
+			queryFrames = append(queryFrames, data.NewFrame("",
+				data.NewField("time", nil, times),
+				data.NewField("value", data.Labels(s.Labels), values),
+			))
+		}
+		frames[q.RefID] = queryFrames
+	}
+	return frames
+}
+
+// checkRuleUnitTest compares the firing alerts of the states with the expected alerts of the test. The test fails if
+// the evaluation failed.
+func checkRuleUnitTest(test apimodels.RuleUnitTestCase, states []*state.State, errs []string) apimodels.RuleUnitTestCaseResult {
+	result := apimodels.RuleUnitTestCaseResult{
+		EvalTime: test.EvalTime,
+		Alerts:   []apimodels.RuleUnitTestAlert{},
+		Errors:   errs,
+	}
+	for _, s := range states {
+		if s.State == eval.Alerting {
+			result.Alerts = append(result.Alerts, toRuleUnitTestAlert(s))
+		}
+	}
+	sort.Slice(result.Alerts, func(i, j int) bool {
+		return ruleUnitTestAlertKey(result.Alerts[i]) < ruleUnitTestAlertKey(result.Alerts[j])
+	})
+
+	expected := make(map[string]int, len(test.ExpectedAlerts))
+	for _, a := range test.ExpectedAlerts {
+		expected[ruleUnitTestAlertKey(a)]++
+	}
+	firing := make(map[string]int, len(result.Alerts))
+	for _, a := range result.Alerts {
+		key := ruleUnitTestAlertKey(a)
+		firing[key]++
+		if firing[key] > expected[key] {
+			result.Unexpected = append(result.Unexpected, a)
+		}
+	}
+	for _, a := range test.ExpectedAlerts {
+		key := ruleUnitTestAlertKey(a)
+		if firing[key] > 0 {
+			firing[key]--
+			continue
+		}
+		result.Missing = append(result.Missing, a)
+	}
+	result.Passed = len(result.Missing) == 0 && len(result.Unexpected) == 0 && len(result.Errors) == 0
+	return result
+}
+
+// toRuleUnitTestAlert returns the alert of the state without the alertname and the labels and annotations Grafana
+// adds, which start with __.
+func toRuleUnitTestAlert(s *state.State) apimodels.RuleUnitTestAlert {
+	alert := apimodels.RuleUnitTestAlert{
+		Labels:      make(map[string]string, len(s.Labels)),
+		Annotations: make(map[string]string, len(s.Annotations)),
+	}
+	for k, v := range s.Labels {
+		if k != prometheusModel.AlertNameLabel && !strings.HasPrefix(k, "__") {
+			alert.Labels[k] = v
+		}
+	}
+	for k, v := range s.Annotations {
+		if !strings.HasPrefix(k, "__") {
+			alert.Annotations[k] = v
+		}
+	}
+	return alert
+}
+
+func ruleUnitTestAlertKey(a apimodels.RuleUnitTestAlert) string {
+	return data.Labels(a.Labels).String() + "|" + data.Labels(a.Annotations).String()
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestTestedRule(t *testing.T) {
+	body := apimodels.TestInlineRulePayload{
+		Rule: apimodels.PostableExtendedRuleNode{
+			ApiRuleNode: &apimodels.ApiRuleNode{
+				For:    model.Duration(5 * time.Minute),
+				Labels: map[string]string{"severity": "critical"},
+			},
+			GrafanaManagedAlert: &apimodels.PostableGrafanaRule{Title: "disk", Condition: "B"},
+		},
+		FolderUID: "folder",
+	}
+	rule, err := testedRule(1, body)
+	require.NoError(t, err)
+	require.Equal(t, int64(60), rule.IntervalSeconds)
+	require.Equal(t, 5*time.Minute, rule.For)
+	require.Equal(t, "folder", rule.NamespaceUID)
+	require.Equal(t, ngmodels.NoData, rule.NoDataState)
+	require.Equal(t, ngmodels.AlertingErrState, rule.ExecErrState)
+
+	body.Rule.GrafanaManagedAlert.Composite = &ngmodels.CompositeCondition{RuleUIDs: []string{"a"}}
+	_, err = testedRule(1, body)
+	require.Error(t, err)
+
+	_, err = testedRule(1, apimodels.TestInlineRulePayload{Rule: apimodels.PostableExtendedRuleNode{ApiRuleNode: &apimodels.ApiRuleNode{}}})
+	require.Error(t, err, "only Grafana managed rules can be tested")
+}
+
+func TestToTestedRuleInstances(t *testing.T) {
+	rule := &ngmodels.AlertRule{
+		OrgID:           1,
+		Title:           "disk",
+		IntervalSeconds: 60,
+		ExecErrState:    ngmodels.AlertingErrState,
+		Annotations:     map[string]string{"summary": "{{ $labels.instance }} is full"},
+	}
+	value := 95.0
+	results := eval.Results{
+		{Instance: data.Labels{"instance": "a"}, State: eval.Alerting, Values: map[string]eval.NumberValueCapture{"B": {Value: &value}}},
+		{Instance: data.Labels{"instance": "b"}, State: eval.Error, Error: errors.New("timeout")},
+	}
+
+	instances := toTestedRuleInstances(state.PreviewResults(log.New("test"), rule, results), results)
+	require.Len(t, instances, 2)
+	require.Equal(t, "a is full", instances[0].Annotations["summary"])
+	require.Equal(t, "disk", instances[0].Labels["alertname"])
+	require.Equal(t, "Alerting", instances[0].State)
+	require.Equal(t, &value, instances[0].Values["B"])
+	require.Equal(t, "Alerting", instances[1].State, "the error state of the rule is applied")
+	require.Equal(t, "Error", instances[1].EvaluationState)
+	require.Equal(t, "timeout", instances[1].Error)
+}
+
+func TestValidateRuleUnitTests(t *testing.T) {
+	rule := &ngmodels.AlertRule{
+		Condition:       "B",
+		IntervalSeconds: 60,
+		Data: []ngmodels.AlertQuery{
+			{RefID: "A", DatasourceUID: "prometheus"},
+			{RefID: "B", DatasourceUID: expr.DatasourceUID},
+		},
+	}
+	body := apimodels.RuleUnitTestPayload{
+		Fixtures: map[string][]apimodels.FixtureSeries{"A": {{Values: []*float64{}}}},
+		Tests:    []apimodels.RuleUnitTestCase{{EvalTime: model.Duration(10 * time.Minute)}},
+	}
+	require.NoError(t, validateRuleUnitTests(rule, body))
+
+	body.Fixtures = map[string][]apimodels.FixtureSeries{"B": nil}
+	require.Error(t, validateRuleUnitTests(rule, body), "the query A has no fixture and B is an expression")
+
+	body.Fixtures = map[string][]apimodels.FixtureSeries{"A": nil}
+	body.Tests = []apimodels.RuleUnitTestCase{{EvalTime: model.Duration(maxRuleUnitTestEvaluations * time.Minute)}}
+	require.Error(t, validateRuleUnitTests(rule, body), "too many evaluations")
+
+	body.Tests = nil
+	require.Error(t, validateRuleUnitTests(rule, body), "no test")
+}
+
+func TestFixtureFrames(t *testing.T) {
+	one, two, three := 1.0, 2.0, 3.0
+	queries := []ngmodels.AlertQuery{{
+		RefID:             "A",
+		RelativeTimeRange: ngmodels.RelativeTimeRange{From: ngmodels.Duration(time.Minute)},
+	}}
+	fixtures := map[string][]apimodels.FixtureSeries{
+		"A": {{Labels: map[string]string{"instance": "a"}, Values: []*float64{&one, &two, nil, &three}}},
+	}
+
+	frames := fixtureFrames(queries, fixtures, time.Minute, time.Unix(120, 0))
+	require.Len(t, frames["A"], 1)
+	frame := frames["A"][0]
+	require.Equal(t, 2, frame.Rows(), "the values at 1m and 2m are in the time range")
+	require.Equal(t, time.Unix(60, 0).UTC(), frame.Fields[0].At(0))
+	require.Equal(t, &two, frame.Fields[1].At(0))
+	require.Nil(t, frame.Fields[1].At(1))
+	require.Equal(t, data.Labels{"instance": "a"}, frame.Fields[1].Labels)
+}
+
+func TestRunRuleUnitTests(t *testing.T) {
+	rule := &ngmodels.AlertRule{
+		Title:           "disk",
+		Condition:       "A",
+		IntervalSeconds: 60,
+		For:             90 * time.Second,
+		Labels:          map[string]string{"severity": "critical"},
+		Annotations:     map[string]string{"summary": "{{ $labels.instance }} is full"},
+		Data:            []ngmodels.AlertQuery{{RefID: "A", DatasourceUID: "prometheus"}},
+	}
+	low, high := 10.0, 95.0
+	body := apimodels.RuleUnitTestPayload{
+		Fixtures: map[string][]apimodels.FixtureSeries{
+			"A": {{Labels: map[string]string{"instance": "a"}, Values: []*float64{&low, &high, &high, &high, &high}}},
+		},
+		Tests: []apimodels.RuleUnitTestCase{
+			{
+				EvalTime: model.Duration(4 * time.Minute),
+				ExpectedAlerts: []apimodels.RuleUnitTestAlert{{
+					Labels:      map[string]string{"instance": "a", "severity": "critical"},
+					Annotations: map[string]string{"summary": "a is full"},
+				}},
+			},
+			{EvalTime: model.Duration(2 * time.Minute)},
+			{EvalTime: model.Duration(3*time.Minute + 30*time.Second)},
+		},
+	}
+
+	// The fake evaluation fires when the last value of the series in the frames of the fixtures is above 90.
+	evaluations := 0
+	evaluate := func(now time.Time, fixtures map[string]data.Frames) eval.Results {
+		evaluations++
+		var results eval.Results
+		for _, f := range fixtures["A"] {
+			result := eval.Result{Instance: f.Fields[1].Labels, State: eval.Normal, EvaluatedAt: now}
+			if v := f.Fields[1].At(f.Rows() - 1).(*float64); *v > 90 {
+				result.State = eval.Alerting
+			}
+			results = append(results, result)
+		}
+		return results
+	}
+
+	result := runRuleUnitTests(rule, body, evaluate, log.New("test"))
+	require.Equal(t, 5, evaluations, "the rule is evaluated from 0 to 4m")
+	require.False(t, result.Passed)
+	require.Len(t, result.Tests, 3)
+
+	require.True(t, result.Tests[0].Passed, "the alert is firing after 2m pending")
+	require.Len(t, result.Tests[0].Alerts, 1)
+
+	require.True(t, result.Tests[1].Passed, "the alert is pending")
+	require.Empty(t, result.Tests[1].Alerts)
+
+	require.False(t, result.Tests[2].Passed, "the alert fired at 3m")
+	require.Equal(t, model.Duration(3*time.Minute+30*time.Second), result.Tests[2].EvalTime)
+	require.Len(t, result.Tests[2].Unexpected, 1)
+	require.Equal(t, "a is full", result.Tests[2].Unexpected[0].Annotations["summary"])
+	require.Empty(t, result.Tests[2].Missing)
+}
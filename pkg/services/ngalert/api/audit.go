@@ -0,0 +1,267 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"gopkg.in/macaron.v1"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+var auditLogger = log.New("ngalert.audit")
+
+const (
+	auditCreate = "create"
+	auditUpdate = "update"
+	auditDelete = "delete"
+)
+
+// auditedResource is a type of alerting resource whose changes are audited, with the function returning its state
+// in an organization. The state is nil if there is no resource.
+type auditedResource struct {
+	name  string
+	state func(api *API, orgID int64, uid string) (interface{}, error)
+}
+
+var (
+	auditAlertRules = auditedResource{name: "alert-rules", state: func(api *API, orgID int64, _ string) (interface{}, error) {
+		q := ngmodels.ListAlertRulesQuery{OrgID: orgID}
+		err := api.RuleStore.GetOrgAlertRules(&q)
+		return q.Result, err
+	}}
+	auditAlertRule = auditedResource{name: "alert-rule", state: func(api *API, orgID int64, uid string) (interface{}, error) {
+		q := ngmodels.GetAlertRuleByUIDQuery{OrgID: orgID, UID: uid}
+		err := api.RuleStore.GetAlertRuleByUID(&q)
+		if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+			return nil, nil
+		}
+		return q.Result, err
+	}}
+	auditRuleTemplates = auditedResource{name: "rule-templates", state: func(api *API, orgID int64, _ string) (interface{}, error) {
+		return api.RuleTemplateStore.ListRuleTemplates(orgID)
+	}}
+	auditFolderDefaults = auditedResource{name: "folder-defaults", state: func(api *API, orgID int64, uid string) (interface{}, error) {
+		d, err := api.FolderDefaultsStore.GetFolderDefaults(orgID, uid)
+		if errors.Is(err, ngmodels.ErrFolderDefaultsNotFound) {
+			return nil, nil
+		}
+		return d, err
+	}}
+	auditSilences = auditedResource{name: "silences", state: func(api *API, orgID int64, _ string) (interface{}, error) {
+		am, err := api.MultiOrgAlertmanager.AlertmanagerFor(orgID)
+		if err != nil {
+			return nil, err
+		}
+		return am.ListSilences(nil)
+	}}
+	auditRecurringSilences = auditedResource{name: "recurring-silences", state: func(api *API, orgID int64, _ string) (interface{}, error) {
+		q := ngmodels.ListRecurringSilencesQuery{OrgID: orgID}
+		err := api.AlertingStore.ListRecurringSilences(&q)
+		return q.Result, err
+	}}
+	auditEscalation = auditedResource{name: "escalation", state: func(api *API, orgID int64, uid string) (interface{}, error) {
+		am, err := api.MultiOrgAlertmanager.AlertmanagerFor(orgID)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range am.GetEscalations() {
+			if e.ID == uid {
+				return e, nil
+			}
+		}
+		return nil, nil
+	}}
+	auditAlertmanagerConfig = auditedResource{name: "alertmanager-config", state: func(api *API, orgID int64, _ string) (interface{}, error) {
+		q := ngmodels.GetLatestAlertmanagerConfigurationQuery{OrgID: orgID}
+		err := api.AlertingStore.GetLatestAlertmanagerConfiguration(&q)
+		if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+			return nil, nil
+		}
+		return q.Result, err
+	}}
+	auditAdminConfig = auditedResource{name: "admin-config", state: func(api *API, orgID int64, _ string) (interface{}, error) {
+		cfg, err := api.AdminConfigStore.GetAdminConfiguration(orgID)
+		if errors.Is(err, store.ErrNoAdminConfiguration) {
+			return nil, nil
+		}
+		return cfg, err
+	}}
+	auditMaintenanceMode = auditedResource{name: "maintenance-mode", state: func(api *API, orgID int64, _ string) (interface{}, error) {
+		m, err := api.AlertingStore.GetMaintenanceMode(orgID)
+		if errors.Is(err, ngmodels.ErrNoMaintenanceMode) {
+			return nil, nil
+		}
+		return m, err
+	}}
+	auditDatasourceMaintenance = auditedResource{name: "datasource-maintenance-windows", state: func(api *API, orgID int64, uid string) (interface{}, error) {
+		return api.DatasourceMaintenanceStore.GetDatasourceMaintenanceWindows(orgID, uid)
+	}}
+	auditLabelPolicies = auditedResource{name: "label-policies", state: func(api *API, orgID int64, _ string) (interface{}, error) {
+		p, err := api.AlertingStore.GetLabelPolicies(orgID)
+		if errors.Is(err, ngmodels.ErrNoLabelPolicies) {
+			return nil, nil
+		}
+		return p, err
+	}}
+	auditGitSync = auditedResource{name: "git-sync", state: func(api *API, _ int64, _ string) (interface{}, error) {
+		if api.GitSync == nil {
+			return nil, nil
+		}
+		return api.GitSync.Status(), nil
+	}}
+	auditStandby = auditedResource{name: "standby", state: func(api *API, _ int64, _ string) (interface{}, error) {
+		return api.MultiOrgAlertmanager.StandbyStatus(), nil
+	}}
+)
+
+// auditedRoute is a route changing an alerting resource, identified by the parameters of the path, if any.
+type auditedRoute struct {
+	resource auditedResource
+	action   string
+	params   []string
+}
+
+var auditedRoutes = map[string]auditedRoute{
+	// Alert rules
+	http.MethodPost + "/api/ruler/{Recipient}/api/v1/rules/{Namespace}":                      {auditAlertRules, auditUpdate, []string{"Namespace"}},
+	http.MethodDelete + "/api/ruler/{Recipient}/api/v1/rules/{Namespace}":                    {auditAlertRules, auditDelete, []string{"Namespace"}},
+	http.MethodDelete + "/api/ruler/{Recipient}/api/v1/rules/{Namespace}/{Groupname}":        {auditAlertRules, auditDelete, []string{"Namespace", "Groupname"}},
+	http.MethodPost + "/api/ruler/grafana/prometheus/api/v1/rules/{Namespace}":               {auditAlertRules, auditUpdate, []string{"Namespace"}},
+	http.MethodDelete + "/api/ruler/grafana/prometheus/api/v1/rules/{Namespace}":             {auditAlertRules, auditDelete, []string{"Namespace"}},
+	http.MethodDelete + "/api/ruler/grafana/prometheus/api/v1/rules/{Namespace}/{Groupname}": {auditAlertRules, auditDelete, []string{"Namespace", "Groupname"}},
+	http.MethodPost + "/api/ruler/grafana/api/v1/import/prometheus/{Namespace}":              {auditAlertRules, auditCreate, []string{"Namespace"}},
+	http.MethodPut + "/api/ruler/grafana/api/v1/rules/{Namespace}/{Groupname}":               {auditAlertRules, auditUpdate, []string{"Namespace", "Groupname"}},
+	http.MethodPost + "/api/ruler/grafana/api/v1/rules/{Namespace}/{Groupname}/clone":        {auditAlertRules, auditCreate, []string{"Namespace", "Groupname"}},
+	http.MethodPost + "/api/ruler/grafana/api/v1/rules/{Namespace}/{Groupname}/move":         {auditAlertRules, auditUpdate, []string{"Namespace", "Groupname"}},
+	http.MethodPost + "/api/ruler/grafana/api/v1/rule/{RuleUID}/clone":                       {auditAlertRules, auditCreate, []string{"RuleUID"}},
+	http.MethodPost + "/api/ruler/grafana/api/v1/rule/{RuleUID}/move":                        {auditAlertRule, auditUpdate, []string{"RuleUID"}},
+	http.MethodPost + "/api/ruler/grafana/api/v1/rule/{RuleUID}/versions/{Version}/restore":  {auditAlertRule, auditUpdate, []string{"RuleUID"}},
+	http.MethodPost + "/api/ruler/grafana/api/v1/bulk":                                       {auditAlertRules, auditUpdate, nil},
+	http.MethodPost + "/api/ruler/grafana/api/v1/slo":                                        {auditAlertRules, auditUpdate, nil},
+	http.MethodPost + "/api/ruler/grafana/api/v1/templates":                                  {auditRuleTemplates, auditUpdate, nil},
+	http.MethodDelete + "/api/ruler/grafana/api/v1/template/{TemplateUID}":                   {auditRuleTemplates, auditDelete, []string{"TemplateUID"}},
+	http.MethodPost + "/api/ruler/grafana/api/v1/template/{TemplateUID}/instantiate":         {auditAlertRules, auditCreate, []string{"TemplateUID"}},
+	http.MethodPost + "/api/ruler/grafana/api/v1/template/{TemplateUID}/instances/update":    {auditAlertRules, auditUpdate, []string{"TemplateUID"}},
+	http.MethodPut + "/api/ruler/grafana/api/v1/folder/{FolderUID}/defaults":                 {auditFolderDefaults, auditUpdate, []string{"FolderUID"}},
+	http.MethodDelete + "/api/ruler/grafana/api/v1/folder/{FolderUID}/defaults":              {auditFolderDefaults, auditDelete, []string{"FolderUID"}},
+	http.MethodPost + "/api/v1/provisioning/alert-rules":                                     {auditAlertRules, auditCreate, nil},
+	http.MethodPut + "/api/v1/provisioning/alert-rules/{UID}":                                {auditAlertRule, auditUpdate, []string{"UID"}},
+	http.MethodDelete + "/api/v1/provisioning/alert-rules/{UID}":                             {auditAlertRule, auditDelete, []string{"UID"}},
+	http.MethodPut + "/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}":           {auditAlertRules, auditUpdate, []string{"FolderUID", "Group"}},
+	http.MethodPost + "/api/v1/provisioning/import":                                          {auditAlertRules, auditUpdate, nil},
+
+	// Silences
+	http.MethodPost + "/api/alertmanager/{Recipient}/api/v2/silences":                              {auditSilences, auditCreate, nil},
+	http.MethodDelete + "/api/alertmanager/{Recipient}/api/v2/silence/{SilenceId}":                 {auditSilences, auditDelete, []string{"SilenceId"}},
+	http.MethodPost + "/api/alertmanager/grafana/api/v1/recurring-silences":                        {auditRecurringSilences, auditCreate, nil},
+	http.MethodDelete + "/api/alertmanager/grafana/api/v1/recurring-silence/{RecurringSilenceUID}": {auditRecurringSilences, auditDelete, []string{"RecurringSilenceUID"}},
+	http.MethodPost + "/api/alertmanager/grafana/api/v1/escalation/{EscalationID}/ack":             {auditEscalation, auditUpdate, []string{"EscalationID"}},
+
+	// Contact points, notification policies, templates and mute timings
+	http.MethodPost + "/api/alertmanager/{Recipient}/config/api/v1/alerts":    {auditAlertmanagerConfig, auditUpdate, nil},
+	http.MethodDelete + "/api/alertmanager/{Recipient}/config/api/v1/alerts":  {auditAlertmanagerConfig, auditDelete, nil},
+	http.MethodPost + "/api/alertmanager/grafana/config/api/v1/import":        {auditAlertmanagerConfig, auditUpdate, nil},
+	http.MethodPut + "/api/v1/provisioning/contact-points/{Name}":             {auditAlertmanagerConfig, auditUpdate, []string{"Name"}},
+	http.MethodDelete + "/api/v1/provisioning/contact-points/{Name}":          {auditAlertmanagerConfig, auditDelete, []string{"Name"}},
+	http.MethodPut + "/api/v1/provisioning/mute-timings/{UID}":                {auditAlertmanagerConfig, auditUpdate, []string{"UID"}},
+	http.MethodDelete + "/api/v1/provisioning/mute-timings/{UID}":             {auditAlertmanagerConfig, auditDelete, []string{"UID"}},
+	http.MethodPut + "/api/v1/provisioning/policies":                          {auditAlertmanagerConfig, auditUpdate, nil},
+	http.MethodDelete + "/api/v1/provisioning/policies":                       {auditAlertmanagerConfig, auditDelete, nil},
+	http.MethodPut + "/api/v1/provisioning/policies/routes/{RouteID}":         {auditAlertmanagerConfig, auditUpdate, []string{"RouteID"}},
+	http.MethodDelete + "/api/v1/provisioning/policies/routes/{RouteID}":      {auditAlertmanagerConfig, auditDelete, []string{"RouteID"}},
+	http.MethodPost + "/api/v1/provisioning/policies/routes/{RouteID}/routes": {auditAlertmanagerConfig, auditCreate, []string{"RouteID"}},
+	http.MethodPost + "/api/v1/provisioning/policies/routes/{RouteID}/move":   {auditAlertmanagerConfig, auditUpdate, []string{"RouteID"}},
+
+	// Admin configuration
+	http.MethodPost + "/api/v1/ngalert/admin_config":     {auditAdminConfig, auditUpdate, nil},
+	http.MethodDelete + "/api/v1/ngalert/admin_config":   {auditAdminConfig, auditDelete, nil},
+	http.MethodPost + "/api/v1/ngalert/maintenance":      {auditMaintenanceMode, auditUpdate, nil},
+	http.MethodDelete + "/api/v1/ngalert/maintenance":    {auditMaintenanceMode, auditDelete, nil},
+	http.MethodPost + "/api/v1/ngalert/label_policies":   {auditLabelPolicies, auditUpdate, nil},
+	http.MethodDelete + "/api/v1/ngalert/label_policies": {auditLabelPolicies, auditDelete, nil},
+	http.MethodPost + "/api/v1/ngalert/git_sync":         {auditGitSync, auditUpdate, nil},
+	http.MethodPost + "/api/v1/ngalert/standby/promote":  {auditStandby, auditUpdate, nil},
+	http.MethodPost + "/api/v1/ngalert/standby/demote":   {auditStandby, auditUpdate, nil},
+
+	// Maintenance windows of the datasources
+	http.MethodPost + "/api/v1/ngalert/datasources/{DatasourceUID}/maintenance-windows":              {auditDatasourceMaintenance, auditCreate, []string{"DatasourceUID"}},
+	http.MethodDelete + "/api/v1/ngalert/datasources/{DatasourceUID}/maintenance-windows/{WindowID}": {auditDatasourceMaintenance, auditDelete, []string{"DatasourceUID"}},
+}
+
+// audit returns the middleware auditing the changes of the alerting resources made by the requests of a route. It
+// logs and publishes an events.AlertingResourceChanged event for each successful request, with the digests of the
+// resource before and after the request.
+func (api *API) audit(method, path string) macaron.Handler {
+	route, ok := auditedRoutes[method+path]
+	if !ok {
+		return func() {}
+	}
+	return func(c *models.ReqContext) {
+		params := make([]string, 0, len(route.params))
+		for _, p := range route.params {
+			params = append(params, c.Params(":"+p))
+		}
+		uid := strings.Join(params, "/")
+		orgID := c.SignedInUser.OrgId
+
+		before := api.auditDigest(route.resource, orgID, uid)
+		c.Next()
+		if status := c.Resp.Status(); status < 200 || status >= 300 {
+			return
+		}
+
+		event := &events.AlertingResourceChanged{
+			Timestamp:    time.Now(),
+			OrgID:        orgID,
+			UserID:       c.SignedInUser.UserId,
+			Login:        c.SignedInUser.Login,
+			APIKeyID:     c.SignedInUser.ApiKeyId,
+			Action:       route.action,
+			ResourceType: route.resource.name,
+			ResourceUID:  uid,
+			Before:       before,
+			After:        api.auditDigest(route.resource, orgID, uid),
+		}
+		auditLogger.Info("Alerting resource changed", "org", event.OrgID, "user", event.UserID, "login", event.Login,
+			"apiKey", event.APIKeyID, "action", event.Action, "resource", event.ResourceType, "uid", event.ResourceUID,
+			"before", event.Before, "after", event.After)
+		if err := bus.Publish(event); err != nil {
+			auditLogger.Error("Failed to publish the audit event", "err", err)
+		}
+	}
+}
+
+// auditDigest returns the SHA-256 digest of the JSON of the state of the resource, empty if there is no resource or
+// it couldn't be read.
+func (api *API) auditDigest(resource auditedResource, orgID int64, uid string) string {
+	state, err := resource.state(api, orgID, uid)
+	if errors.Is(err, notifier.ErrNoAlertmanagerForOrg) {
+		return ""
+	}
+	if err != nil {
+		auditLogger.Warn("Failed to read the audited resource", "org", orgID, "resource", resource.name, "uid", uid, "err", err)
+		return ""
+	}
+	if state == nil {
+		return ""
+	}
+	b, err := json.Marshal(state)
+	if err != nil {
+		auditLogger.Warn("Failed to encode the audited resource", "org", orgID, "resource", resource.name, "uid", uid, "err", err)
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
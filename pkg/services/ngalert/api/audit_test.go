@@ -0,0 +1,136 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/macaron.v1"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/models"
+	acmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+type fakeMaintenanceModeStore struct {
+	store.AlertingStore
+	mode *ngmodels.MaintenanceMode
+}
+
+func (f *fakeMaintenanceModeStore) GetMaintenanceMode(int64) (*ngmodels.MaintenanceMode, error) {
+	if f.mode == nil {
+		return nil, ngmodels.ErrNoMaintenanceMode
+	}
+	return f.mode, nil
+}
+
+type fakeAlertmanagerConfigStore struct {
+	store.AlertingStore
+	config *ngmodels.AlertConfiguration
+}
+
+func (f *fakeAlertmanagerConfigStore) GetLatestAlertmanagerConfiguration(q *ngmodels.GetLatestAlertmanagerConfigurationQuery) error {
+	if f.config == nil {
+		return store.ErrNoAlertmanagerConfiguration
+	}
+	q.Result = f.config
+	return nil
+}
+
+func TestAudit(t *testing.T) {
+	t.Run("the audited routes are routes of the API", func(t *testing.T) {
+		api := &API{AccessControl: acmock.New()}
+		for route := range auditedRoutes {
+			for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodDelete} {
+				if strings.HasPrefix(route, method+"/") {
+					require.NotPanics(t, func() {
+						api.authorize(method, strings.TrimPrefix(route, method))
+					}, route)
+				}
+			}
+		}
+	})
+
+	const path = "/api/v1/ngalert/maintenance"
+	setup := func(t *testing.T, status int) (*fakeMaintenanceModeStore, *[]*events.AlertingResourceChanged) {
+		t.Cleanup(bus.ClearBusHandlers)
+		published := make([]*events.AlertingResourceChanged, 0)
+		bus.AddEventListener(func(e *events.AlertingResourceChanged) error {
+			published = append(published, e)
+			return nil
+		})
+
+		fake := &fakeMaintenanceModeStore{}
+		api := &API{AlertingStore: fake}
+		m := macaron.New()
+		m.Use(func(c *macaron.Context) {
+			c.Map(&models.ReqContext{Context: c, SignedInUser: &models.SignedInUser{OrgId: 1, UserId: 2, Login: "admin"}})
+		})
+		m.Post(path, api.audit(http.MethodPost, path), func(c *models.ReqContext) {
+			if status == http.StatusOK {
+				fake.mode = &ngmodels.MaintenanceMode{OrgID: 1, Reason: "upgrade"}
+			}
+			c.Resp.WriteHeader(status)
+		})
+		m.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, path, nil))
+		return fake, &published
+	}
+
+	t.Run("publishes the changes", func(t *testing.T) {
+		_, published := setup(t, http.StatusOK)
+		require.Len(t, *published, 1)
+		e := (*published)[0]
+		require.Equal(t, int64(1), e.OrgID)
+		require.Equal(t, int64(2), e.UserID)
+		require.Equal(t, "admin", e.Login)
+		require.Equal(t, "update", e.Action)
+		require.Equal(t, "maintenance-mode", e.ResourceType)
+		require.Empty(t, e.Before)
+		require.Len(t, e.After, 64)
+	})
+
+	t.Run("does not publish the failed requests", func(t *testing.T) {
+		_, published := setup(t, http.StatusBadRequest)
+		require.Empty(t, *published)
+	})
+
+	t.Run("publishes the imports of Alertmanager configurations", func(t *testing.T) {
+		t.Cleanup(bus.ClearBusHandlers)
+		var published []*events.AlertingResourceChanged
+		bus.AddEventListener(func(e *events.AlertingResourceChanged) error {
+			published = append(published, e)
+			return nil
+		})
+
+		const importPath = "/api/alertmanager/grafana/config/api/v1/import"
+		fake := &fakeAlertmanagerConfigStore{config: &ngmodels.AlertConfiguration{OrgID: 1, AlertmanagerConfiguration: "{}"}}
+		api := &API{AlertingStore: fake}
+		m := macaron.New()
+		m.Use(func(c *macaron.Context) {
+			c.Map(&models.ReqContext{Context: c, SignedInUser: &models.SignedInUser{OrgId: 1, UserId: 2, Login: "admin"}})
+		})
+		m.Post(importPath, api.audit(http.MethodPost, importPath), func(c *models.ReqContext) {
+			fake.config = &ngmodels.AlertConfiguration{OrgID: 1, AlertmanagerConfiguration: `{"alertmanager_config": {"receivers": [{"name": "team-a"}]}}`}
+			c.Resp.WriteHeader(http.StatusOK)
+		})
+		m.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, importPath, nil))
+
+		require.Len(t, published, 1)
+		e := published[0]
+		require.Equal(t, "update", e.Action)
+		require.Equal(t, "alertmanager-config", e.ResourceType)
+		require.Len(t, e.Before, 64)
+		require.Len(t, e.After, 64)
+		require.NotEqual(t, e.Before, e.After)
+	})
+
+	t.Run("the routes without changes are not audited", func(t *testing.T) {
+		api := &API{}
+		require.IsType(t, func() {}, api.audit(http.MethodGet, path))
+	})
+}
@@ -0,0 +1,369 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"gopkg.in/macaron.v1"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	acmiddleware "github.com/grafana/grafana/pkg/services/accesscontrol/middleware"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// authorize returns the middleware authorizing the requests of a route: with the alerting permissions if access
+// control is enabled, with the role of the user in the organization otherwise. The permissions of the alert rules are
+// also checked by folder, by the rule store of the API. The route is registered as a route of the alerting API, which
+// the API keys restricted to the alerting API can use.
+func (api *API) authorize(method, path string) macaron.Handler {
+	handler, rules := api.authorizeRoute(method, path)
+	models.RegisterAlertingAPIRoute(method, path, rules)
+	return handler
+}
+
+// authorizeRoute returns the middleware authorizing the requests of a route, and true for the routes of the alert
+// rules, which the API keys restricted to folders can use.
+func (api *API) authorizeRoute(method, path string) (macaron.Handler, bool) {
+	fallback := middleware.ReqSignedIn
+	var eval ac.Evaluator
+	rules := false
+	switch method + path {
+	// Alert rules
+	case http.MethodGet + "/api/ruler/{Recipient}/api/v1/rules",
+		http.MethodGet + "/api/ruler/{Recipient}/api/v1/rules/{Namespace}",
+		http.MethodGet + "/api/ruler/{Recipient}/api/v1/rules/{Namespace}/{Groupname}",
+		http.MethodGet + "/api/ruler/grafana/prometheus/api/v1/rules",
+		http.MethodGet + "/api/ruler/grafana/prometheus/api/v1/rules/{Namespace}",
+		http.MethodGet + "/api/ruler/grafana/prometheus/api/v1/rules/{Namespace}/{Groupname}",
+		http.MethodGet + "/api/ruler/grafana/api/v1/export/prometheus",
+		http.MethodGet + "/api/ruler/grafana/api/v1/export/prometheus/{Namespace}",
+		http.MethodGet + "/api/ruler/grafana/api/v1/export/prometheus/{Namespace}/{Groupname}",
+		http.MethodGet + "/api/ruler/grafana/api/v1/rule/{RuleUID}/versions",
+		http.MethodGet + "/api/ruler/grafana/api/v1/rule/{RuleUID}/versions/{Version}",
+		http.MethodGet + "/api/ruler/grafana/api/v1/rule/{RuleUID}/versions/diff",
+		http.MethodGet + "/api/ruler/grafana/api/v1/rule/{RuleUID}/shadow/compare",
+		http.MethodGet + "/api/prometheus/{Recipient}/api/v1/rules",
+		http.MethodGet + "/api/prometheus/grafana/compat/api/v1/rules",
+		http.MethodGet + "/api/prometheus/grafana/api/v1/rules/search",
+		http.MethodGet + "/api/v1/rules/insights",
+		http.MethodGet + "/api/ruler/grafana/api/v1/templates",
+		http.MethodGet + "/api/ruler/grafana/api/v1/template/{TemplateUID}",
+		http.MethodGet + "/api/ruler/grafana/api/v1/folder/{FolderUID}/defaults",
+		http.MethodGet + "/api/v1/ngalert/datasources/{DatasourceUID}/maintenance-windows",
+		http.MethodPost + "/api/v1/eval",
+		http.MethodPost + "/api/v1/rule/test/{Recipient}",
+		http.MethodPost + "/api/v1/rule/test",
+		http.MethodPost + "/api/v1/rule/unit-test",
+		http.MethodPost + "/api/v1/rule/validate-templates":
+		eval = ac.EvalPermission(ac.ActionAlertingRuleRead)
+		rules = true
+	case http.MethodPost + "/api/ruler/{Recipient}/api/v1/rules/{Namespace}",
+		http.MethodDelete + "/api/ruler/{Recipient}/api/v1/rules/{Namespace}",
+		http.MethodDelete + "/api/ruler/{Recipient}/api/v1/rules/{Namespace}/{Groupname}",
+		http.MethodPost + "/api/ruler/grafana/prometheus/api/v1/rules/{Namespace}",
+		http.MethodDelete + "/api/ruler/grafana/prometheus/api/v1/rules/{Namespace}",
+		http.MethodDelete + "/api/ruler/grafana/prometheus/api/v1/rules/{Namespace}/{Groupname}",
+		http.MethodPost + "/api/ruler/grafana/api/v1/import/prometheus/{Namespace}",
+		http.MethodPut + "/api/ruler/grafana/api/v1/rules/{Namespace}/{Groupname}",
+		http.MethodPost + "/api/ruler/grafana/api/v1/rules/{Namespace}/{Groupname}/clone",
+		http.MethodPost + "/api/ruler/grafana/api/v1/rules/{Namespace}/{Groupname}/move",
+		http.MethodPost + "/api/ruler/grafana/api/v1/rule/{RuleUID}/clone",
+		http.MethodPost + "/api/ruler/grafana/api/v1/rule/{RuleUID}/move",
+		http.MethodPost + "/api/ruler/grafana/api/v1/rule/{RuleUID}/versions/{Version}/restore",
+		http.MethodPost + "/api/ruler/grafana/api/v1/bulk",
+		http.MethodPost + "/api/ruler/grafana/api/v1/slo",
+		http.MethodPost + "/api/ruler/grafana/api/v1/template/{TemplateUID}/instantiate",
+		http.MethodPost + "/api/ruler/grafana/api/v1/template/{TemplateUID}/instances/update",
+		http.MethodPut + "/api/ruler/grafana/api/v1/folder/{FolderUID}/defaults",
+		http.MethodDelete + "/api/ruler/grafana/api/v1/folder/{FolderUID}/defaults":
+		eval = ac.EvalPermission(ac.ActionAlertingRuleWrite)
+		rules = true
+	// The rule templates are not in a folder.
+	case http.MethodPost + "/api/ruler/grafana/api/v1/templates",
+		http.MethodDelete + "/api/ruler/grafana/api/v1/template/{TemplateUID}":
+		fallback = middleware.ReqEditorRole
+		eval = ac.EvalPermission(ac.ActionAlertingRuleWrite)
+
+	// Alert instances and silences
+	case http.MethodGet + "/api/prometheus/{Recipient}/api/v1/alerts",
+		http.MethodGet + "/api/prometheus/grafana/compat/api/v1/alerts",
+		http.MethodGet + "/api/v1/alerts",
+		http.MethodGet + "/api/v1/incidents",
+		http.MethodGet + "/api/alertmanager/{Recipient}/api/v2/alerts",
+		http.MethodGet + "/api/alertmanager/{Recipient}/api/v2/alerts/groups",
+		http.MethodGet + "/api/alertmanager/{Recipient}/api/v2/silences",
+		http.MethodGet + "/api/alertmanager/{Recipient}/api/v2/silence/{SilenceId}",
+		http.MethodGet + "/api/alertmanager/grafana/api/v1/recurring-silences",
+		http.MethodGet + "/api/alertmanager/grafana/api/v1/recurring-silence/{RecurringSilenceUID}",
+		http.MethodGet + "/api/alertmanager/grafana/api/v1/escalations":
+		eval = ac.EvalPermission(ac.ActionAlertingInstanceRead)
+	case http.MethodPost + "/api/alertmanager/{Recipient}/api/v2/silences",
+		http.MethodDelete + "/api/alertmanager/{Recipient}/api/v2/silence/{SilenceId}",
+		http.MethodPost + "/api/alertmanager/grafana/api/v1/recurring-silences",
+		http.MethodDelete + "/api/alertmanager/grafana/api/v1/recurring-silence/{RecurringSilenceUID}",
+		http.MethodPost + "/api/alertmanager/grafana/api/v1/escalation/{EscalationID}/ack":
+		fallback = middleware.ReqEditorRole
+		eval = ac.EvalPermission(ac.ActionAlertingSilencesCreate)
+
+	// Notifications
+	case http.MethodGet + "/api/alertmanager/{Recipient}/api/v2/status",
+		http.MethodGet + "/api/alertmanager/grafana/api/v1/notification-log":
+		eval = ac.EvalPermission(ac.ActionAlertingNotificationsRead)
+	case http.MethodGet + "/api/alertmanager/{Recipient}/config/api/v1/alerts":
+		fallback = middleware.ReqEditorRole
+		eval = ac.EvalPermission(ac.ActionAlertingNotificationsRead)
+	case http.MethodPost + "/api/alertmanager/{Recipient}/config/api/v1/alerts":
+		return api.authorizeAlertmanagerConfigWrite(), false
+	case http.MethodDelete + "/api/alertmanager/{Recipient}/config/api/v1/alerts",
+		http.MethodPost + "/api/alertmanager/{Recipient}/config/api/v1/receivers/test",
+		http.MethodPost + "/api/alertmanager/{Recipient}/api/v2/alerts":
+		fallback = middleware.ReqEditorRole
+		eval = ac.EvalPermission(ac.ActionAlertingNotificationsWrite)
+	case http.MethodPost + "/api/v1/receiver/test/{Recipient}":
+		eval = ac.EvalPermission(ac.ActionAlertingNotificationsWrite)
+	case http.MethodPost + "/api/alertmanager/grafana/config/api/v1/import":
+		fallback = middleware.ReqOrgAdmin
+		eval = ac.EvalPermission(ac.ActionAlertingNotificationsWrite)
+
+	// Documentation of the API
+	case http.MethodGet + "/api/v1/ngalert/openapi.json":
+		return fallback, false
+
+	// Admin configuration
+	case http.MethodGet + "/api/v1/ngalert/alertmanagers":
+		eval = ac.EvalPermission(ac.ActionAlertingAdminConfigRead)
+	case http.MethodGet + "/api/v1/ngalert/alertmanagers/health",
+		http.MethodGet + "/api/v1/ngalert/admin_config",
+		http.MethodGet + "/api/v1/ngalert/maintenance",
+		http.MethodGet + "/api/v1/ngalert/label_policies":
+		fallback = middleware.ReqOrgAdmin
+		eval = ac.EvalPermission(ac.ActionAlertingAdminConfigRead)
+	case http.MethodPost + "/api/v1/ngalert/admin_config",
+		http.MethodDelete + "/api/v1/ngalert/admin_config",
+		http.MethodPost + "/api/v1/ngalert/maintenance",
+		http.MethodDelete + "/api/v1/ngalert/maintenance",
+		http.MethodPost + "/api/v1/ngalert/label_policies",
+		http.MethodDelete + "/api/v1/ngalert/label_policies",
+		http.MethodPost + "/api/v1/ngalert/datasources/{DatasourceUID}/maintenance-windows",
+		http.MethodDelete + "/api/v1/ngalert/datasources/{DatasourceUID}/maintenance-windows/{WindowID}":
+		fallback = middleware.ReqOrgAdmin
+		eval = ac.EvalPermission(ac.ActionAlertingAdminConfigWrite)
+	// The Git sync provisions the resources of every organization.
+	case http.MethodGet + "/api/v1/ngalert/git_sync":
+		fallback = middleware.ReqGrafanaAdmin
+		eval = ac.EvalAll(ac.EvalPermission(ac.ActionSettingsRead), ac.EvalPermission(ac.ActionAlertingAdminConfigRead))
+	case http.MethodPost + "/api/v1/ngalert/git_sync":
+		fallback = middleware.ReqGrafanaAdmin
+		eval = ac.EvalAll(ac.EvalPermission(ac.ActionSettingsRead), ac.EvalPermission(ac.ActionAlertingAdminConfigWrite))
+	// The summary of the alerting health and the sync of the Alertmanagers of every organization.
+	case http.MethodGet + "/api/v1/ngalert/orgs/health":
+		fallback = middleware.ReqGrafanaAdmin
+		eval = ac.EvalAll(ac.EvalPermission(ac.ActionSettingsRead), ac.EvalPermission(ac.ActionAlertingAdminConfigRead))
+	case http.MethodPost + "/api/v1/ngalert/orgs/alertmanagers/sync":
+		fallback = middleware.ReqGrafanaAdmin
+		eval = ac.EvalAll(ac.EvalPermission(ac.ActionSettingsRead), ac.EvalPermission(ac.ActionAlertingAdminConfigWrite))
+	// The warm standby of the Alertmanagers of the replica.
+	case http.MethodGet + "/api/v1/ngalert/standby":
+		fallback = middleware.ReqGrafanaAdmin
+		eval = ac.EvalAll(ac.EvalPermission(ac.ActionSettingsRead), ac.EvalPermission(ac.ActionAlertingAdminConfigRead))
+	case http.MethodPost + "/api/v1/ngalert/standby/promote",
+		http.MethodPost + "/api/v1/ngalert/standby/demote":
+		fallback = middleware.ReqGrafanaAdmin
+		eval = ac.EvalAll(ac.EvalPermission(ac.ActionSettingsRead), ac.EvalPermission(ac.ActionAlertingAdminConfigWrite))
+
+	// Provisioning
+	case http.MethodGet + "/api/v1/provisioning/alert-rules/{UID}",
+		http.MethodGet + "/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}",
+		http.MethodGet + "/api/v1/provisioning/contact-points",
+		http.MethodGet + "/api/v1/provisioning/contact-points/{Name}/usage",
+		http.MethodGet + "/api/v1/provisioning/mute-timings",
+		http.MethodGet + "/api/v1/provisioning/mute-timings/{UID}",
+		http.MethodGet + "/api/v1/provisioning/mute-timings/{UID}/usage",
+		http.MethodGet + "/api/v1/provisioning/policies",
+		http.MethodGet + "/api/v1/provisioning/policies/routes",
+		http.MethodGet + "/api/v1/provisioning/policies/routes/{RouteID}",
+		http.MethodGet + "/api/v1/provisioning/export":
+		fallback = middleware.ReqOrgAdmin
+		eval = ac.EvalPermission(ac.ActionAlertingProvisioningRead)
+	case http.MethodPost + "/api/v1/provisioning/alert-rules",
+		http.MethodPut + "/api/v1/provisioning/alert-rules/{UID}",
+		http.MethodDelete + "/api/v1/provisioning/alert-rules/{UID}",
+		http.MethodPut + "/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}",
+		http.MethodPut + "/api/v1/provisioning/contact-points/{Name}",
+		http.MethodDelete + "/api/v1/provisioning/contact-points/{Name}",
+		http.MethodPut + "/api/v1/provisioning/mute-timings/{UID}",
+		http.MethodDelete + "/api/v1/provisioning/mute-timings/{UID}",
+		http.MethodPut + "/api/v1/provisioning/policies",
+		http.MethodDelete + "/api/v1/provisioning/policies",
+		http.MethodPut + "/api/v1/provisioning/policies/routes/{RouteID}",
+		http.MethodDelete + "/api/v1/provisioning/policies/routes/{RouteID}",
+		http.MethodPost + "/api/v1/provisioning/policies/routes/{RouteID}/routes",
+		http.MethodPost + "/api/v1/provisioning/policies/routes/{RouteID}/move",
+		http.MethodPost + "/api/v1/provisioning/import":
+		fallback = middleware.ReqOrgAdmin
+		eval = ac.EvalPermission(ac.ActionAlertingProvisioningWrite)
+	}
+
+	if eval == nil {
+		panic(fmt.Sprintf("no authorization of the route %s %s", method, path))
+	}
+	if api.AccessControl == nil {
+		return fallback, rules
+	}
+	return acmiddleware.Middleware(api.AccessControl)(fallback, eval), rules
+}
+
+// authorizeAlertmanagerConfigWrite returns the middleware authorizing the changes of the Alertmanager configuration.
+// The users who can only edit the notification policies of their teams can change the configuration of the Grafana
+// Alertmanager, the handler restricts them to these notification policies.
+func (api *API) authorizeAlertmanagerConfigWrite() macaron.Handler {
+	fallback := middleware.ReqEditorRole
+	if api.AccessControl == nil {
+		return fallback
+	}
+	write := ac.EvalPermission(ac.ActionAlertingNotificationsWrite)
+	grafana := acmiddleware.Middleware(api.AccessControl)(fallback, ac.EvalAny(write, ac.EvalPermission(ac.ActionAlertingTeamPoliciesWrite)))
+	other := acmiddleware.Middleware(api.AccessControl)(fallback, write)
+	return func(c *models.ReqContext) {
+		handler := other
+		if c.Params(":Recipient") == apimodels.GrafanaBackend.String() {
+			handler = grafana
+		}
+		if _, err := c.Invoke(handler); err != nil {
+			c.JsonApiErr(http.StatusInternalServerError, "Internal server error", err)
+		}
+	}
+}
+
+// authorizedRuleStore restricts the namespaces of the users to the folders they have the permission to read the alert
+// rules of, or to write them if the namespace is changed, when access control is enabled.
+type authorizedRuleStore struct {
+	store.RuleStore
+	ac  ac.AccessControl
+	log log.Logger
+}
+
+// ruleStore returns the rule store of the handlers of the API.
+func (api *API) ruleStore(logger log.Logger) store.RuleStore {
+	if api.AccessControl == nil || api.AccessControl.IsDisabled() {
+		return apiKeyFoldersRuleStore{RuleStore: api.RuleStore}
+	}
+	return apiKeyFoldersRuleStore{RuleStore: authorizedRuleStore{RuleStore: api.RuleStore, ac: api.AccessControl, log: logger}}
+}
+
+// apiKeyFoldersRuleStore restricts the namespaces of the API keys restricted to folders to these folders.
+type apiKeyFoldersRuleStore struct {
+	store.RuleStore
+}
+
+func isAPIKeyFolder(user *models.SignedInUser, folderUID string) bool {
+	if len(user.ApiKeyFolders) == 0 {
+		return true
+	}
+	for _, uid := range user.ApiKeyFolders {
+		if uid == folderUID {
+			return true
+		}
+	}
+	return false
+}
+
+// GetNamespaces returns the folders of the API key, if it is restricted to folders.
+func (s apiKeyFoldersRuleStore) GetNamespaces(orgID int64, user *models.SignedInUser) (map[string]*models.Folder, error) {
+	namespaces, err := s.RuleStore.GetNamespaces(orgID, user)
+	if err != nil {
+		return nil, err
+	}
+	for uid := range namespaces {
+		if !isAPIKeyFolder(user, uid) {
+			delete(namespaces, uid)
+		}
+	}
+	return namespaces, nil
+}
+
+// GetNamespaceByTitle returns the folder if it is one of the folders of the API key, if it is restricted to folders.
+func (s apiKeyFoldersRuleStore) GetNamespaceByTitle(title string, orgID int64, user *models.SignedInUser, withCanSave bool) (*models.Folder, error) {
+	folder, err := s.RuleStore.GetNamespaceByTitle(title, orgID, user, withCanSave)
+	if err != nil {
+		return nil, err
+	}
+	if !isAPIKeyFolder(user, folder.Uid) {
+		return nil, models.ErrFolderAccessDenied
+	}
+	return folder, nil
+}
+
+// GetNamespaceByUID returns the folder if it is one of the folders of the API key, if it is restricted to folders.
+func (s apiKeyFoldersRuleStore) GetNamespaceByUID(uid string, orgID int64, user *models.SignedInUser, withCanSave bool) (*models.Folder, error) {
+	if !isAPIKeyFolder(user, uid) {
+		return nil, models.ErrFolderAccessDenied
+	}
+	return s.RuleStore.GetNamespaceByUID(uid, orgID, user, withCanSave)
+}
+
+// CreateNamespace refuses to create folders for the API keys restricted to folders.
+func (s apiKeyFoldersRuleStore) CreateNamespace(title, uid string, orgID int64, user *models.SignedInUser) (*models.Folder, error) {
+	if len(user.ApiKeyFolders) > 0 {
+		return nil, models.ErrFolderAccessDenied
+	}
+	return s.RuleStore.CreateNamespace(title, uid, orgID, user)
+}
+
+func (s authorizedRuleStore) hasAccess(user *models.SignedInUser, action, folderUID string) bool {
+	ok, err := s.ac.Evaluate(context.Background(), user, ac.EvalPermission(action, ac.ScopeFolder(folderUID)))
+	if err != nil {
+		s.log.Error("failed to evaluate the permission to alert rules", "action", action, "folder", folderUID, "err", err)
+		return false
+	}
+	return ok
+}
+
+// GetNamespaces returns the folders the user can read the alert rules of.
+func (s authorizedRuleStore) GetNamespaces(orgID int64, user *models.SignedInUser) (map[string]*models.Folder, error) {
+	namespaces, err := s.RuleStore.GetNamespaces(orgID, user)
+	if err != nil {
+		return nil, err
+	}
+	for uid := range namespaces {
+		if !s.hasAccess(user, ac.ActionAlertingRuleRead, uid) {
+			delete(namespaces, uid)
+		}
+	}
+	return namespaces, nil
+}
+
+// GetNamespaceByTitle returns the folder if the user can read its alert rules, and write them if withCanSave is true.
+func (s authorizedRuleStore) GetNamespaceByTitle(title string, orgID int64, user *models.SignedInUser, withCanSave bool) (*models.Folder, error) {
+	// the permissions of the alert rules replace the permission to save the folder
+	folder, err := s.RuleStore.GetNamespaceByTitle(title, orgID, user, false)
+	if err != nil {
+		return nil, err
+	}
+	return s.authorizeNamespace(folder, user, withCanSave)
+}
+
+// GetNamespaceByUID returns the folder if the user can read its alert rules, and write them if withCanSave is true.
+func (s authorizedRuleStore) GetNamespaceByUID(uid string, orgID int64, user *models.SignedInUser, withCanSave bool) (*models.Folder, error) {
+	folder, err := s.RuleStore.GetNamespaceByUID(uid, orgID, user, false)
+	if err != nil {
+		return nil, err
+	}
+	return s.authorizeNamespace(folder, user, withCanSave)
+}
+
+func (s authorizedRuleStore) authorizeNamespace(folder *models.Folder, user *models.SignedInUser, withCanSave bool) (*models.Folder, error) {
+	if !s.hasAccess(user, ac.ActionAlertingRuleRead, folder.Uid) {
+		return nil, models.ErrFolderAccessDenied
+	}
+	if withCanSave && !s.hasAccess(user, ac.ActionAlertingRuleWrite, folder.Uid) {
+		return nil, ngmodels.ErrCannotEditNamespace
+	}
+	return folder, nil
+}
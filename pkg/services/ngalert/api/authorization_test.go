@@ -0,0 +1,151 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	acmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+func TestAuthorize(t *testing.T) {
+	t.Run("all the routes are authorized", func(t *testing.T) {
+		api := &API{RouteRegister: routing.NewRouteRegister(), AccessControl: acmock.New()}
+		require.NotPanics(t, func() {
+			api.RegisterAPIEndpoints(metrics.NewMetrics(prometheus.NewRegistry()))
+		})
+	})
+
+	t.Run("the routes are the routes the API keys restricted to the alerting API can use", func(t *testing.T) {
+		api := &API{RouteRegister: routing.NewRouteRegister(), AccessControl: acmock.New()}
+		api.RegisterAPIEndpoints(metrics.NewMetrics(prometheus.NewRegistry()))
+
+		require.True(t, models.ApiKeyScopeAllows(models.ApiKeyScopeAlerting, nil, http.MethodPost, "/api/ruler/grafana/api/v1/rules/ops"))
+		require.True(t, models.ApiKeyScopeAllows(models.ApiKeyScopeAlerting, nil, http.MethodPost, "/api/alertmanager/grafana/api/v2/silences"))
+		require.True(t, models.ApiKeyScopeAllows(models.ApiKeyScopeAlertingRead, nil, http.MethodGet, "/api/v1/alerts"))
+		require.False(t, models.ApiKeyScopeAllows(models.ApiKeyScopeAlerting, nil, http.MethodGet, "/api/v1/alertsettings"))
+		require.False(t, models.ApiKeyScopeAllows(models.ApiKeyScopeAlerting, nil, http.MethodGet, "/api/v1/ngalert/unknown"))
+
+		folders := []string{"ops"}
+		require.True(t, models.ApiKeyScopeAllows(models.ApiKeyScopeAlerting, folders, http.MethodPost, "/api/ruler/grafana/api/v1/rules/ops"))
+		require.True(t, models.ApiKeyScopeAllows(models.ApiKeyScopeAlerting, folders, http.MethodPost, "/api/v1/rule/test/grafana"))
+		require.False(t, models.ApiKeyScopeAllows(models.ApiKeyScopeAlerting, folders, http.MethodPost, "/api/alertmanager/grafana/api/v2/silences"))
+		require.False(t, models.ApiKeyScopeAllows(models.ApiKeyScopeAlerting, folders, http.MethodPost, "/api/ruler/grafana/api/v1/templates"))
+	})
+
+	t.Run("unknown routes panic", func(t *testing.T) {
+		api := &API{AccessControl: acmock.New()}
+		require.Panics(t, func() {
+			api.authorize("GET", "/api/v1/unknown")
+		})
+	})
+}
+
+type fakeNamespaceStore struct {
+	store.RuleStore
+	folders map[string]*models.Folder
+}
+
+func (f fakeNamespaceStore) GetNamespaces(int64, *models.SignedInUser) (map[string]*models.Folder, error) {
+	namespaces := make(map[string]*models.Folder, len(f.folders))
+	for uid, folder := range f.folders {
+		namespaces[uid] = folder
+	}
+	return namespaces, nil
+}
+
+func (f fakeNamespaceStore) GetNamespaceByUID(uid string, _ int64, _ *models.SignedInUser, withCanSave bool) (*models.Folder, error) {
+	if withCanSave {
+		return nil, ngmodels.ErrCannotEditNamespace
+	}
+	folder, ok := f.folders[uid]
+	if !ok {
+		return nil, models.ErrFolderNotFound
+	}
+	return folder, nil
+}
+
+func TestAuthorizedRuleStore(t *testing.T) {
+	folders := fakeNamespaceStore{folders: map[string]*models.Folder{
+		"ops": {Uid: "ops", Title: "Ops"},
+		"dev": {Uid: "dev", Title: "Dev"},
+	}}
+	user := &models.SignedInUser{OrgId: 1, OrgRole: models.ROLE_VIEWER}
+	s := authorizedRuleStore{
+		RuleStore: folders,
+		ac: acmock.New().WithPermissions([]*ac.Permission{
+			{Action: ac.ActionAlertingRuleRead, Scope: ac.ScopeFolder("ops")},
+			{Action: ac.ActionAlertingRuleRead, Scope: ac.ScopeFolder("dev")},
+			{Action: ac.ActionAlertingRuleWrite, Scope: ac.ScopeFolder("dev")},
+		}),
+		log: log.New("test"),
+	}
+
+	t.Run("the namespaces are the folders the user can read the rules of", func(t *testing.T) {
+		restricted := authorizedRuleStore{
+			RuleStore: folders,
+			ac:        acmock.New().WithPermissions([]*ac.Permission{{Action: ac.ActionAlertingRuleRead, Scope: ac.ScopeFolder("ops")}}),
+			log:       log.New("test"),
+		}
+		namespaces, err := restricted.GetNamespaces(1, user)
+		require.NoError(t, err)
+		require.Len(t, namespaces, 1)
+		require.Contains(t, namespaces, "ops")
+	})
+
+	t.Run("the permission to write the rules replaces the permission to save the folder", func(t *testing.T) {
+		folder, err := s.GetNamespaceByUID("dev", 1, user, true)
+		require.NoError(t, err)
+		require.Equal(t, "dev", folder.Uid)
+
+		_, err = s.GetNamespaceByUID("ops", 1, user, true)
+		require.ErrorIs(t, err, ngmodels.ErrCannotEditNamespace)
+
+		folder, err = s.GetNamespaceByUID("ops", 1, user, false)
+		require.NoError(t, err)
+		require.Equal(t, "ops", folder.Uid)
+	})
+
+	t.Run("the folders without the permission to read the rules are denied", func(t *testing.T) {
+		restricted := authorizedRuleStore{RuleStore: folders, ac: acmock.New(), log: log.New("test")}
+		_, err := restricted.GetNamespaceByUID("ops", 1, user, false)
+		require.ErrorIs(t, err, models.ErrFolderAccessDenied)
+	})
+}
+
+func TestAPIKeyFoldersRuleStore(t *testing.T) {
+	s := apiKeyFoldersRuleStore{RuleStore: fakeNamespaceStore{folders: map[string]*models.Folder{
+		"ops": {Uid: "ops", Title: "Ops"},
+		"dev": {Uid: "dev", Title: "Dev"},
+	}}}
+
+	t.Run("the users and the API keys without folders have all the namespaces", func(t *testing.T) {
+		namespaces, err := s.GetNamespaces(1, &models.SignedInUser{OrgId: 1})
+		require.NoError(t, err)
+		require.Len(t, namespaces, 2)
+	})
+
+	t.Run("the namespaces of the API keys with folders are these folders", func(t *testing.T) {
+		key := &models.SignedInUser{OrgId: 1, ApiKeyId: 1, ApiKeyFolders: []string{"dev"}}
+		namespaces, err := s.GetNamespaces(1, key)
+		require.NoError(t, err)
+		require.Len(t, namespaces, 1)
+		require.Contains(t, namespaces, "dev")
+
+		folder, err := s.GetNamespaceByUID("dev", 1, key, false)
+		require.NoError(t, err)
+		require.Equal(t, "dev", folder.Uid)
+
+		_, err = s.GetNamespaceByUID("ops", 1, key, false)
+		require.ErrorIs(t, err, models.ErrFolderAccessDenied)
+	})
+}
@@ -0,0 +1,49 @@
+/*Package api contains base API implementation of unified alerting
+ *
+ *Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ *
+ *Do not manually edit these files, please find ngalert/api/swagger-codegen/ for commands on how to generate them.
+ */
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+type AlertInstancesApiService interface {
+	RouteGetAlertInstances(*models.ReqContext) response.Response
+	RouteGetIncidents(*models.ReqContext) response.Response
+}
+
+func (api *API) RegisterAlertInstancesApiEndpoints(srv AlertInstancesApiService, m *metrics.Metrics) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Get(
+			toMacaronPath("/api/v1/alerts"),
+			api.authorize(http.MethodGet, "/api/v1/alerts"),
+			api.audit(http.MethodGet, "/api/v1/alerts"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/alerts",
+				srv.RouteGetAlertInstances,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/incidents"),
+			api.authorize(http.MethodGet, "/api/v1/incidents"),
+			api.audit(http.MethodGet, "/api/v1/incidents"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/incidents",
+				srv.RouteGetIncidents,
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
@@ -38,6 +38,8 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApiService, m *
 	api.RouteRegister.Group("", func(group routing.RouteRegister) {
 		group.Post(
 			toMacaronPath("/api/alertmanager/{Recipient}/api/v2/silences"),
+			api.authorize(http.MethodPost, "/api/alertmanager/{Recipient}/api/v2/silences"),
+			api.audit(http.MethodPost, "/api/alertmanager/{Recipient}/api/v2/silences"),
 			binding.Bind(apimodels.PostableSilence{}),
 			metrics.Instrument(
 				http.MethodPost,
@@ -48,6 +50,8 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApiService, m *
 		)
 		group.Delete(
 			toMacaronPath("/api/alertmanager/{Recipient}/config/api/v1/alerts"),
+			api.authorize(http.MethodDelete, "/api/alertmanager/{Recipient}/config/api/v1/alerts"),
+			api.audit(http.MethodDelete, "/api/alertmanager/{Recipient}/config/api/v1/alerts"),
 			metrics.Instrument(
 				http.MethodDelete,
 				"/api/alertmanager/{Recipient}/config/api/v1/alerts",
@@ -57,6 +61,8 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApiService, m *
 		)
 		group.Delete(
 			toMacaronPath("/api/alertmanager/{Recipient}/api/v2/silence/{SilenceId}"),
+			api.authorize(http.MethodDelete, "/api/alertmanager/{Recipient}/api/v2/silence/{SilenceId}"),
+			api.audit(http.MethodDelete, "/api/alertmanager/{Recipient}/api/v2/silence/{SilenceId}"),
 			metrics.Instrument(
 				http.MethodDelete,
 				"/api/alertmanager/{Recipient}/api/v2/silence/{SilenceId}",
@@ -66,6 +72,8 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApiService, m *
 		)
 		group.Get(
 			toMacaronPath("/api/alertmanager/{Recipient}/api/v2/alerts/groups"),
+			api.authorize(http.MethodGet, "/api/alertmanager/{Recipient}/api/v2/alerts/groups"),
+			api.audit(http.MethodGet, "/api/alertmanager/{Recipient}/api/v2/alerts/groups"),
 			metrics.Instrument(
 				http.MethodGet,
 				"/api/alertmanager/{Recipient}/api/v2/alerts/groups",
@@ -75,6 +83,8 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApiService, m *
 		)
 		group.Get(
 			toMacaronPath("/api/alertmanager/{Recipient}/api/v2/alerts"),
+			api.authorize(http.MethodGet, "/api/alertmanager/{Recipient}/api/v2/alerts"),
+			api.audit(http.MethodGet, "/api/alertmanager/{Recipient}/api/v2/alerts"),
 			metrics.Instrument(
 				http.MethodGet,
 				"/api/alertmanager/{Recipient}/api/v2/alerts",
@@ -84,6 +94,8 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApiService, m *
 		)
 		group.Get(
 			toMacaronPath("/api/alertmanager/{Recipient}/api/v2/status"),
+			api.authorize(http.MethodGet, "/api/alertmanager/{Recipient}/api/v2/status"),
+			api.audit(http.MethodGet, "/api/alertmanager/{Recipient}/api/v2/status"),
 			metrics.Instrument(
 				http.MethodGet,
 				"/api/alertmanager/{Recipient}/api/v2/status",
@@ -93,6 +105,8 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApiService, m *
 		)
 		group.Get(
 			toMacaronPath("/api/alertmanager/{Recipient}/config/api/v1/alerts"),
+			api.authorize(http.MethodGet, "/api/alertmanager/{Recipient}/config/api/v1/alerts"),
+			api.audit(http.MethodGet, "/api/alertmanager/{Recipient}/config/api/v1/alerts"),
 			metrics.Instrument(
 				http.MethodGet,
 				"/api/alertmanager/{Recipient}/config/api/v1/alerts",
@@ -102,6 +116,8 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApiService, m *
 		)
 		group.Get(
 			toMacaronPath("/api/alertmanager/{Recipient}/api/v2/silence/{SilenceId}"),
+			api.authorize(http.MethodGet, "/api/alertmanager/{Recipient}/api/v2/silence/{SilenceId}"),
+			api.audit(http.MethodGet, "/api/alertmanager/{Recipient}/api/v2/silence/{SilenceId}"),
 			metrics.Instrument(
 				http.MethodGet,
 				"/api/alertmanager/{Recipient}/api/v2/silence/{SilenceId}",
@@ -111,6 +127,8 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApiService, m *
 		)
 		group.Get(
 			toMacaronPath("/api/alertmanager/{Recipient}/api/v2/silences"),
+			api.authorize(http.MethodGet, "/api/alertmanager/{Recipient}/api/v2/silences"),
+			api.audit(http.MethodGet, "/api/alertmanager/{Recipient}/api/v2/silences"),
 			metrics.Instrument(
 				http.MethodGet,
 				"/api/alertmanager/{Recipient}/api/v2/silences",
@@ -120,6 +138,8 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApiService, m *
 		)
 		group.Post(
 			toMacaronPath("/api/alertmanager/{Recipient}/api/v2/alerts"),
+			api.authorize(http.MethodPost, "/api/alertmanager/{Recipient}/api/v2/alerts"),
+			api.audit(http.MethodPost, "/api/alertmanager/{Recipient}/api/v2/alerts"),
 			binding.Bind(apimodels.PostableAlerts{}),
 			metrics.Instrument(
 				http.MethodPost,
@@ -130,6 +150,8 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApiService, m *
 		)
 		group.Post(
 			toMacaronPath("/api/alertmanager/{Recipient}/config/api/v1/alerts"),
+			api.authorize(http.MethodPost, "/api/alertmanager/{Recipient}/config/api/v1/alerts"),
+			api.audit(http.MethodPost, "/api/alertmanager/{Recipient}/config/api/v1/alerts"),
 			binding.Bind(apimodels.PostableUserConfig{}),
 			metrics.Instrument(
 				http.MethodPost,
@@ -140,6 +162,8 @@ func (api *API) RegisterAlertmanagerApiEndpoints(srv AlertmanagerApiService, m *
 		)
 		group.Post(
 			toMacaronPath("/api/alertmanager/{Recipient}/config/api/v1/receivers/test"),
+			api.authorize(http.MethodPost, "/api/alertmanager/{Recipient}/config/api/v1/receivers/test"),
+			api.audit(http.MethodPost, "/api/alertmanager/{Recipient}/config/api/v1/receivers/test"),
 			binding.Bind(apimodels.TestReceiversConfigParams{}),
 			metrics.Instrument(
 				http.MethodPost,
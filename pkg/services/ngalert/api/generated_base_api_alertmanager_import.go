@@ -0,0 +1,41 @@
+/*Package api contains base API implementation of unified alerting
+ *
+ *Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ *
+ *Do not manually edit these files, please find ngalert/api/swagger-codegen/ for commands on how to generate them.
+ */
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-macaron/binding"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+type AlertmanagerImportApiService interface {
+	RoutePostAlertmanagerImport(*models.ReqContext, apimodels.PostableAlertmanagerImport) response.Response
+}
+
+func (api *API) RegisterAlertmanagerImportApiEndpoints(srv AlertmanagerImportApiService, m *metrics.Metrics) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Post(
+			toMacaronPath("/api/alertmanager/grafana/config/api/v1/import"),
+			api.authorize(http.MethodPost, "/api/alertmanager/grafana/config/api/v1/import"),
+			api.audit(http.MethodPost, "/api/alertmanager/grafana/config/api/v1/import"),
+			binding.Bind(apimodels.PostableAlertmanagerImport{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/grafana/config/api/v1/import",
+				srv.RoutePostAlertmanagerImport,
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
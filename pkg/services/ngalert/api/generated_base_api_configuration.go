@@ -20,16 +20,55 @@ import (
 )
 
 type ConfigurationApiService interface {
+	RouteDeleteLabelPolicies(*models.ReqContext) response.Response
+	RouteDeleteMaintenanceMode(*models.ReqContext) response.Response
 	RouteDeleteNGalertConfig(*models.ReqContext) response.Response
 	RouteGetAlertmanagers(*models.ReqContext) response.Response
+	RouteGetAlertmanagersHealth(*models.ReqContext) response.Response
+	RouteGetGitSyncStatus(*models.ReqContext) response.Response
+	RouteGetLabelPolicies(*models.ReqContext) response.Response
+	RouteGetMaintenanceMode(*models.ReqContext) response.Response
 	RouteGetNGalertConfig(*models.ReqContext) response.Response
+	RouteGetOpenAPIDocument(*models.ReqContext) response.Response
+	RouteGetOrgsAlertingHealth(*models.ReqContext) response.Response
+	RouteGetStandby(*models.ReqContext) response.Response
+	RoutePostGitSync(*models.ReqContext) response.Response
+	RoutePostLabelPolicies(*models.ReqContext, apimodels.PostableLabelPolicies) response.Response
+	RoutePostMaintenanceMode(*models.ReqContext, apimodels.PostableMaintenanceMode) response.Response
 	RoutePostNGalertConfig(*models.ReqContext, apimodels.PostableNGalertConfig) response.Response
+	RoutePostOrgsAlertmanagersSync(*models.ReqContext) response.Response
+	RoutePostStandbyDemote(*models.ReqContext) response.Response
+	RoutePostStandbyPromote(*models.ReqContext) response.Response
 }
 
 func (api *API) RegisterConfigurationApiEndpoints(srv ConfigurationApiService, m *metrics.Metrics) {
 	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Delete(
+			toMacaronPath("/api/v1/ngalert/label_policies"),
+			api.authorize(http.MethodDelete, "/api/v1/ngalert/label_policies"),
+			api.audit(http.MethodDelete, "/api/v1/ngalert/label_policies"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/v1/ngalert/label_policies",
+				srv.RouteDeleteLabelPolicies,
+				m,
+			),
+		)
+		group.Delete(
+			toMacaronPath("/api/v1/ngalert/maintenance"),
+			api.authorize(http.MethodDelete, "/api/v1/ngalert/maintenance"),
+			api.audit(http.MethodDelete, "/api/v1/ngalert/maintenance"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/v1/ngalert/maintenance",
+				srv.RouteDeleteMaintenanceMode,
+				m,
+			),
+		)
 		group.Delete(
 			toMacaronPath("/api/v1/ngalert/admin_config"),
+			api.authorize(http.MethodDelete, "/api/v1/ngalert/admin_config"),
+			api.audit(http.MethodDelete, "/api/v1/ngalert/admin_config"),
 			metrics.Instrument(
 				http.MethodDelete,
 				"/api/v1/ngalert/admin_config",
@@ -39,6 +78,8 @@ func (api *API) RegisterConfigurationApiEndpoints(srv ConfigurationApiService, m
 		)
 		group.Get(
 			toMacaronPath("/api/v1/ngalert/alertmanagers"),
+			api.authorize(http.MethodGet, "/api/v1/ngalert/alertmanagers"),
+			api.audit(http.MethodGet, "/api/v1/ngalert/alertmanagers"),
 			metrics.Instrument(
 				http.MethodGet,
 				"/api/v1/ngalert/alertmanagers",
@@ -46,8 +87,54 @@ func (api *API) RegisterConfigurationApiEndpoints(srv ConfigurationApiService, m
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/v1/ngalert/alertmanagers/health"),
+			api.authorize(http.MethodGet, "/api/v1/ngalert/alertmanagers/health"),
+			api.audit(http.MethodGet, "/api/v1/ngalert/alertmanagers/health"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/ngalert/alertmanagers/health",
+				srv.RouteGetAlertmanagersHealth,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/ngalert/git_sync"),
+			api.authorize(http.MethodGet, "/api/v1/ngalert/git_sync"),
+			api.audit(http.MethodGet, "/api/v1/ngalert/git_sync"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/ngalert/git_sync",
+				srv.RouteGetGitSyncStatus,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/ngalert/label_policies"),
+			api.authorize(http.MethodGet, "/api/v1/ngalert/label_policies"),
+			api.audit(http.MethodGet, "/api/v1/ngalert/label_policies"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/ngalert/label_policies",
+				srv.RouteGetLabelPolicies,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/ngalert/maintenance"),
+			api.authorize(http.MethodGet, "/api/v1/ngalert/maintenance"),
+			api.audit(http.MethodGet, "/api/v1/ngalert/maintenance"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/ngalert/maintenance",
+				srv.RouteGetMaintenanceMode,
+				m,
+			),
+		)
 		group.Get(
 			toMacaronPath("/api/v1/ngalert/admin_config"),
+			api.authorize(http.MethodGet, "/api/v1/ngalert/admin_config"),
+			api.audit(http.MethodGet, "/api/v1/ngalert/admin_config"),
 			metrics.Instrument(
 				http.MethodGet,
 				"/api/v1/ngalert/admin_config",
@@ -55,8 +142,78 @@ func (api *API) RegisterConfigurationApiEndpoints(srv ConfigurationApiService, m
 				m,
 			),
 		)
+		group.Get(
+			toMacaronPath("/api/v1/ngalert/openapi.json"),
+			api.authorize(http.MethodGet, "/api/v1/ngalert/openapi.json"),
+			api.audit(http.MethodGet, "/api/v1/ngalert/openapi.json"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/ngalert/openapi.json",
+				srv.RouteGetOpenAPIDocument,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/ngalert/orgs/health"),
+			api.authorize(http.MethodGet, "/api/v1/ngalert/orgs/health"),
+			api.audit(http.MethodGet, "/api/v1/ngalert/orgs/health"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/ngalert/orgs/health",
+				srv.RouteGetOrgsAlertingHealth,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/ngalert/standby"),
+			api.authorize(http.MethodGet, "/api/v1/ngalert/standby"),
+			api.audit(http.MethodGet, "/api/v1/ngalert/standby"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/ngalert/standby",
+				srv.RouteGetStandby,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/ngalert/git_sync"),
+			api.authorize(http.MethodPost, "/api/v1/ngalert/git_sync"),
+			api.audit(http.MethodPost, "/api/v1/ngalert/git_sync"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/ngalert/git_sync",
+				srv.RoutePostGitSync,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/ngalert/label_policies"),
+			api.authorize(http.MethodPost, "/api/v1/ngalert/label_policies"),
+			api.audit(http.MethodPost, "/api/v1/ngalert/label_policies"),
+			binding.Bind(apimodels.PostableLabelPolicies{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/ngalert/label_policies",
+				srv.RoutePostLabelPolicies,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/ngalert/maintenance"),
+			api.authorize(http.MethodPost, "/api/v1/ngalert/maintenance"),
+			api.audit(http.MethodPost, "/api/v1/ngalert/maintenance"),
+			binding.Bind(apimodels.PostableMaintenanceMode{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/ngalert/maintenance",
+				srv.RoutePostMaintenanceMode,
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/v1/ngalert/admin_config"),
+			api.authorize(http.MethodPost, "/api/v1/ngalert/admin_config"),
+			api.audit(http.MethodPost, "/api/v1/ngalert/admin_config"),
 			binding.Bind(apimodels.PostableNGalertConfig{}),
 			metrics.Instrument(
 				http.MethodPost,
@@ -65,5 +222,38 @@ func (api *API) RegisterConfigurationApiEndpoints(srv ConfigurationApiService, m
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/v1/ngalert/orgs/alertmanagers/sync"),
+			api.authorize(http.MethodPost, "/api/v1/ngalert/orgs/alertmanagers/sync"),
+			api.audit(http.MethodPost, "/api/v1/ngalert/orgs/alertmanagers/sync"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/ngalert/orgs/alertmanagers/sync",
+				srv.RoutePostOrgsAlertmanagersSync,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/ngalert/standby/promote"),
+			api.authorize(http.MethodPost, "/api/v1/ngalert/standby/promote"),
+			api.audit(http.MethodPost, "/api/v1/ngalert/standby/promote"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/ngalert/standby/promote",
+				srv.RoutePostStandbyPromote,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/ngalert/standby/demote"),
+			api.authorize(http.MethodPost, "/api/v1/ngalert/standby/demote"),
+			api.audit(http.MethodPost, "/api/v1/ngalert/standby/demote"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/ngalert/standby/demote",
+				srv.RoutePostStandbyDemote,
+				m,
+			),
+		)
 	}, middleware.ReqSignedIn)
 }
@@ -0,0 +1,65 @@
+/*Package api contains base API implementation of unified alerting
+ *
+ *Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ *
+ *Do not manually edit these files, please find ngalert/api/swagger-codegen/ for commands on how to generate them.
+ */
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-macaron/binding"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+type DatasourceMaintenanceApiService interface {
+	RouteDeleteDatasourceMaintenanceWindow(*models.ReqContext) response.Response
+	RouteGetDatasourceMaintenanceWindows(*models.ReqContext) response.Response
+	RoutePostDatasourceMaintenanceWindow(*models.ReqContext, apimodels.PostableDatasourceMaintenanceWindow) response.Response
+}
+
+func (api *API) RegisterDatasourceMaintenanceApiEndpoints(srv DatasourceMaintenanceApiService, m *metrics.Metrics) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Delete(
+			toMacaronPath("/api/v1/ngalert/datasources/{DatasourceUID}/maintenance-windows/{WindowID}"),
+			api.authorize(http.MethodDelete, "/api/v1/ngalert/datasources/{DatasourceUID}/maintenance-windows/{WindowID}"),
+			api.audit(http.MethodDelete, "/api/v1/ngalert/datasources/{DatasourceUID}/maintenance-windows/{WindowID}"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/v1/ngalert/datasources/{DatasourceUID}/maintenance-windows/{WindowID}",
+				srv.RouteDeleteDatasourceMaintenanceWindow,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/ngalert/datasources/{DatasourceUID}/maintenance-windows"),
+			api.authorize(http.MethodGet, "/api/v1/ngalert/datasources/{DatasourceUID}/maintenance-windows"),
+			api.audit(http.MethodGet, "/api/v1/ngalert/datasources/{DatasourceUID}/maintenance-windows"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/ngalert/datasources/{DatasourceUID}/maintenance-windows",
+				srv.RouteGetDatasourceMaintenanceWindows,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/ngalert/datasources/{DatasourceUID}/maintenance-windows"),
+			api.authorize(http.MethodPost, "/api/v1/ngalert/datasources/{DatasourceUID}/maintenance-windows"),
+			api.audit(http.MethodPost, "/api/v1/ngalert/datasources/{DatasourceUID}/maintenance-windows"),
+			binding.Bind(apimodels.PostableDatasourceMaintenanceWindow{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/ngalert/datasources/{DatasourceUID}/maintenance-windows",
+				srv.RoutePostDatasourceMaintenanceWindow,
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
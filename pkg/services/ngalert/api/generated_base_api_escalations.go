@@ -0,0 +1,49 @@
+/*Package api contains base API implementation of unified alerting
+ *
+ *Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ *
+ *Do not manually edit these files, please find ngalert/api/swagger-codegen/ for commands on how to generate them.
+ */
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+type EscalationsApiService interface {
+	RouteAcknowledgeEscalation(*models.ReqContext) response.Response
+	RouteGetEscalations(*models.ReqContext) response.Response
+}
+
+func (api *API) RegisterEscalationsApiEndpoints(srv EscalationsApiService, m *metrics.Metrics) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Post(
+			toMacaronPath("/api/alertmanager/grafana/api/v1/escalation/{EscalationID}/ack"),
+			api.authorize(http.MethodPost, "/api/alertmanager/grafana/api/v1/escalation/{EscalationID}/ack"),
+			api.audit(http.MethodPost, "/api/alertmanager/grafana/api/v1/escalation/{EscalationID}/ack"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/grafana/api/v1/escalation/{EscalationID}/ack",
+				srv.RouteAcknowledgeEscalation,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/alertmanager/grafana/api/v1/escalations"),
+			api.authorize(http.MethodGet, "/api/alertmanager/grafana/api/v1/escalations"),
+			api.audit(http.MethodGet, "/api/alertmanager/grafana/api/v1/escalations"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/alertmanager/grafana/api/v1/escalations",
+				srv.RouteGetEscalations,
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
@@ -0,0 +1,65 @@
+/*Package api contains base API implementation of unified alerting
+ *
+ *Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ *
+ *Do not manually edit these files, please find ngalert/api/swagger-codegen/ for commands on how to generate them.
+ */
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-macaron/binding"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+type FolderDefaultsApiService interface {
+	RouteDeleteFolderDefaults(*models.ReqContext) response.Response
+	RouteGetFolderDefaults(*models.ReqContext) response.Response
+	RoutePutFolderDefaults(*models.ReqContext, apimodels.PostableFolderDefaults) response.Response
+}
+
+func (api *API) RegisterFolderDefaultsApiEndpoints(srv FolderDefaultsApiService, m *metrics.Metrics) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Delete(
+			toMacaronPath("/api/ruler/grafana/api/v1/folder/{FolderUID}/defaults"),
+			api.authorize(http.MethodDelete, "/api/ruler/grafana/api/v1/folder/{FolderUID}/defaults"),
+			api.audit(http.MethodDelete, "/api/ruler/grafana/api/v1/folder/{FolderUID}/defaults"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/ruler/grafana/api/v1/folder/{FolderUID}/defaults",
+				srv.RouteDeleteFolderDefaults,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/ruler/grafana/api/v1/folder/{FolderUID}/defaults"),
+			api.authorize(http.MethodGet, "/api/ruler/grafana/api/v1/folder/{FolderUID}/defaults"),
+			api.audit(http.MethodGet, "/api/ruler/grafana/api/v1/folder/{FolderUID}/defaults"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/ruler/grafana/api/v1/folder/{FolderUID}/defaults",
+				srv.RouteGetFolderDefaults,
+				m,
+			),
+		)
+		group.Put(
+			toMacaronPath("/api/ruler/grafana/api/v1/folder/{FolderUID}/defaults"),
+			api.authorize(http.MethodPut, "/api/ruler/grafana/api/v1/folder/{FolderUID}/defaults"),
+			api.audit(http.MethodPut, "/api/ruler/grafana/api/v1/folder/{FolderUID}/defaults"),
+			binding.Bind(apimodels.PostableFolderDefaults{}),
+			metrics.Instrument(
+				http.MethodPut,
+				"/api/ruler/grafana/api/v1/folder/{FolderUID}/defaults",
+				srv.RoutePutFolderDefaults,
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
@@ -0,0 +1,31 @@
+/*Package api contains base API implementation of unified alerting
+ *
+ *Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ *
+ *Do not manually edit these files, please find ngalert/api/swagger-codegen/ for commands on how to generate them.
+ */
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+type HeartbeatApiService interface {
+	RoutePostHeartbeat(*models.ReqContext) response.Response
+}
+
+func (api *API) RegisterHeartbeatApiEndpoints(srv HeartbeatApiService, m *metrics.Metrics) {
+	api.RouteRegister.Post(
+		toMacaronPath("/api/v1/ngalert/heartbeat/{Token}"),
+		metrics.Instrument(
+			http.MethodPost,
+			"/api/v1/ngalert/heartbeat/{Token}",
+			srv.RoutePostHeartbeat,
+			m,
+		),
+	)
+}
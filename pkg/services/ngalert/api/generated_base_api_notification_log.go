@@ -0,0 +1,37 @@
+/*Package api contains base API implementation of unified alerting
+ *
+ *Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ *
+ *Do not manually edit these files, please find ngalert/api/swagger-codegen/ for commands on how to generate them.
+ */
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+type NotificationLogApiService interface {
+	RouteGetNotificationLog(*models.ReqContext) response.Response
+}
+
+func (api *API) RegisterNotificationLogApiEndpoints(srv NotificationLogApiService, m *metrics.Metrics) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Get(
+			toMacaronPath("/api/alertmanager/grafana/api/v1/notification-log"),
+			api.authorize(http.MethodGet, "/api/alertmanager/grafana/api/v1/notification-log"),
+			api.audit(http.MethodGet, "/api/alertmanager/grafana/api/v1/notification-log"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/alertmanager/grafana/api/v1/notification-log",
+				srv.RouteGetNotificationLog,
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
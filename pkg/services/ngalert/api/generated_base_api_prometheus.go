@@ -25,6 +25,8 @@ func (api *API) RegisterPrometheusApiEndpoints(srv PrometheusApiService, m *metr
 	api.RouteRegister.Group("", func(group routing.RouteRegister) {
 		group.Get(
 			toMacaronPath("/api/prometheus/{Recipient}/api/v1/alerts"),
+			api.authorize(http.MethodGet, "/api/prometheus/{Recipient}/api/v1/alerts"),
+			api.audit(http.MethodGet, "/api/prometheus/{Recipient}/api/v1/alerts"),
 			metrics.Instrument(
 				http.MethodGet,
 				"/api/prometheus/{Recipient}/api/v1/alerts",
@@ -34,6 +36,8 @@ func (api *API) RegisterPrometheusApiEndpoints(srv PrometheusApiService, m *metr
 		)
 		group.Get(
 			toMacaronPath("/api/prometheus/{Recipient}/api/v1/rules"),
+			api.authorize(http.MethodGet, "/api/prometheus/{Recipient}/api/v1/rules"),
+			api.audit(http.MethodGet, "/api/prometheus/{Recipient}/api/v1/rules"),
 			metrics.Instrument(
 				http.MethodGet,
 				"/api/prometheus/{Recipient}/api/v1/rules",
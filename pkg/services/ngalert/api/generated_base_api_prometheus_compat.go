@@ -0,0 +1,49 @@
+/*Package api contains base API implementation of unified alerting
+ *
+ *Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ *
+ *Do not manually edit these files, please find ngalert/api/swagger-codegen/ for commands on how to generate them.
+ */
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+type PrometheusCompatApiService interface {
+	RouteGetPrometheusCompatAlerts(*models.ReqContext) response.Response
+	RouteGetPrometheusCompatRules(*models.ReqContext) response.Response
+}
+
+func (api *API) RegisterPrometheusCompatApiEndpoints(srv PrometheusCompatApiService, m *metrics.Metrics) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Get(
+			toMacaronPath("/api/prometheus/grafana/compat/api/v1/alerts"),
+			api.authorize(http.MethodGet, "/api/prometheus/grafana/compat/api/v1/alerts"),
+			api.audit(http.MethodGet, "/api/prometheus/grafana/compat/api/v1/alerts"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/prometheus/grafana/compat/api/v1/alerts",
+				srv.RouteGetPrometheusCompatAlerts,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/prometheus/grafana/compat/api/v1/rules"),
+			api.authorize(http.MethodGet, "/api/prometheus/grafana/compat/api/v1/rules"),
+			api.audit(http.MethodGet, "/api/prometheus/grafana/compat/api/v1/rules"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/prometheus/grafana/compat/api/v1/rules",
+				srv.RouteGetPrometheusCompatRules,
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
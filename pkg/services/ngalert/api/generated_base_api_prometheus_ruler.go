@@ -0,0 +1,145 @@
+/*Package api contains base API implementation of unified alerting
+ *
+ *Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ *
+ *Do not manually edit these files, please find ngalert/api/swagger-codegen/ for commands on how to generate them.
+ */
+package api
+
+import (
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+type PrometheusRulerApiService interface {
+	RouteDeletePrometheusNamespaceRules(*models.ReqContext) response.Response
+	RouteDeletePrometheusRuleGroup(*models.ReqContext) response.Response
+	RouteGetPrometheusExport(*models.ReqContext) response.Response
+	RouteGetPrometheusNamespaceExport(*models.ReqContext) response.Response
+	RouteGetPrometheusNamespaceRules(*models.ReqContext) response.Response
+	RouteGetPrometheusRuleGroup(*models.ReqContext) response.Response
+	RouteGetPrometheusRuleGroupExport(*models.ReqContext) response.Response
+	RouteGetPrometheusRules(*models.ReqContext) response.Response
+	RoutePostPrometheusImport(*models.ReqContext) response.Response
+	RoutePostPrometheusRuleGroup(*models.ReqContext) response.Response
+}
+
+func (api *API) RegisterPrometheusRulerApiEndpoints(srv PrometheusRulerApiService, m *metrics.Metrics) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Delete(
+			toMacaronPath("/api/ruler/grafana/prometheus/api/v1/rules/{Namespace}"),
+			api.authorize(http.MethodDelete, "/api/ruler/grafana/prometheus/api/v1/rules/{Namespace}"),
+			api.audit(http.MethodDelete, "/api/ruler/grafana/prometheus/api/v1/rules/{Namespace}"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/ruler/grafana/prometheus/api/v1/rules/{Namespace}",
+				srv.RouteDeletePrometheusNamespaceRules,
+				m,
+			),
+		)
+		group.Delete(
+			toMacaronPath("/api/ruler/grafana/prometheus/api/v1/rules/{Namespace}/{Groupname}"),
+			api.authorize(http.MethodDelete, "/api/ruler/grafana/prometheus/api/v1/rules/{Namespace}/{Groupname}"),
+			api.audit(http.MethodDelete, "/api/ruler/grafana/prometheus/api/v1/rules/{Namespace}/{Groupname}"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/ruler/grafana/prometheus/api/v1/rules/{Namespace}/{Groupname}",
+				srv.RouteDeletePrometheusRuleGroup,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/ruler/grafana/api/v1/export/prometheus"),
+			api.authorize(http.MethodGet, "/api/ruler/grafana/api/v1/export/prometheus"),
+			api.audit(http.MethodGet, "/api/ruler/grafana/api/v1/export/prometheus"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/ruler/grafana/api/v1/export/prometheus",
+				srv.RouteGetPrometheusExport,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/ruler/grafana/api/v1/export/prometheus/{Namespace}"),
+			api.authorize(http.MethodGet, "/api/ruler/grafana/api/v1/export/prometheus/{Namespace}"),
+			api.audit(http.MethodGet, "/api/ruler/grafana/api/v1/export/prometheus/{Namespace}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/ruler/grafana/api/v1/export/prometheus/{Namespace}",
+				srv.RouteGetPrometheusNamespaceExport,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/ruler/grafana/api/v1/export/prometheus/{Namespace}/{Groupname}"),
+			api.authorize(http.MethodGet, "/api/ruler/grafana/api/v1/export/prometheus/{Namespace}/{Groupname}"),
+			api.audit(http.MethodGet, "/api/ruler/grafana/api/v1/export/prometheus/{Namespace}/{Groupname}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/ruler/grafana/api/v1/export/prometheus/{Namespace}/{Groupname}",
+				srv.RouteGetPrometheusRuleGroupExport,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/ruler/grafana/prometheus/api/v1/rules/{Namespace}"),
+			api.authorize(http.MethodGet, "/api/ruler/grafana/prometheus/api/v1/rules/{Namespace}"),
+			api.audit(http.MethodGet, "/api/ruler/grafana/prometheus/api/v1/rules/{Namespace}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/ruler/grafana/prometheus/api/v1/rules/{Namespace}",
+				srv.RouteGetPrometheusNamespaceRules,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/ruler/grafana/prometheus/api/v1/rules/{Namespace}/{Groupname}"),
+			api.authorize(http.MethodGet, "/api/ruler/grafana/prometheus/api/v1/rules/{Namespace}/{Groupname}"),
+			api.audit(http.MethodGet, "/api/ruler/grafana/prometheus/api/v1/rules/{Namespace}/{Groupname}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/ruler/grafana/prometheus/api/v1/rules/{Namespace}/{Groupname}",
+				srv.RouteGetPrometheusRuleGroup,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/ruler/grafana/prometheus/api/v1/rules"),
+			api.authorize(http.MethodGet, "/api/ruler/grafana/prometheus/api/v1/rules"),
+			api.audit(http.MethodGet, "/api/ruler/grafana/prometheus/api/v1/rules"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/ruler/grafana/prometheus/api/v1/rules",
+				srv.RouteGetPrometheusRules,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/ruler/grafana/api/v1/import/prometheus/{Namespace}"),
+			api.authorize(http.MethodPost, "/api/ruler/grafana/api/v1/import/prometheus/{Namespace}"),
+			api.audit(http.MethodPost, "/api/ruler/grafana/api/v1/import/prometheus/{Namespace}"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/ruler/grafana/api/v1/import/prometheus/{Namespace}",
+				srv.RoutePostPrometheusImport,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/ruler/grafana/prometheus/api/v1/rules/{Namespace}"),
+			api.authorize(http.MethodPost, "/api/ruler/grafana/prometheus/api/v1/rules/{Namespace}"),
+			api.audit(http.MethodPost, "/api/ruler/grafana/prometheus/api/v1/rules/{Namespace}"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/ruler/grafana/prometheus/api/v1/rules/{Namespace}",
+				srv.RoutePostPrometheusRuleGroup,
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
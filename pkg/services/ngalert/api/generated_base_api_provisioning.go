@@ -0,0 +1,349 @@
+/*Package api contains base API implementation of unified alerting
+ *
+ *Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ *
+ *Do not manually edit these files, please find ngalert/api/swagger-codegen/ for commands on how to generate them.
+ */
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-macaron/binding"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+type ProvisioningApiService interface {
+	RouteDeleteAlertRule(*models.ReqContext) response.Response
+	RouteDeleteContactPoint(*models.ReqContext) response.Response
+	RouteDeleteMuteTiming(*models.ReqContext) response.Response
+	RouteDeletePolicyRoute(*models.ReqContext) response.Response
+	RouteGetAlertRule(*models.ReqContext) response.Response
+	RouteGetAlertRuleGroup(*models.ReqContext) response.Response
+	RouteGetAlertingBundle(*models.ReqContext) response.Response
+	RouteGetContactPointUsage(*models.ReqContext) response.Response
+	RouteGetContactPoints(*models.ReqContext) response.Response
+	RouteGetMuteTiming(*models.ReqContext) response.Response
+	RouteGetMuteTimingUsage(*models.ReqContext) response.Response
+	RouteGetMuteTimings(*models.ReqContext) response.Response
+	RouteGetPolicyRoute(*models.ReqContext) response.Response
+	RouteGetPolicyRoutes(*models.ReqContext) response.Response
+	RouteGetPolicyTree(*models.ReqContext) response.Response
+	RouteMovePolicyRoute(*models.ReqContext, apimodels.PostableRouteMove) response.Response
+	RoutePostAlertRule(*models.ReqContext, apimodels.ProvisionedAlertRule) response.Response
+	RoutePostAlertingBundle(*models.ReqContext) response.Response
+	RoutePostPolicyRoute(*models.ReqContext, apimodels.PostableRoute) response.Response
+	RoutePutAlertRule(*models.ReqContext, apimodels.ProvisionedAlertRule) response.Response
+	RoutePutAlertRuleGroup(*models.ReqContext, apimodels.AlertRuleGroup) response.Response
+	RoutePutContactPoint(*models.ReqContext, apimodels.PostableContactPoint) response.Response
+	RoutePutMuteTiming(*models.ReqContext, apimodels.PostableRecurringSilence) response.Response
+	RoutePutPolicyRoute(*models.ReqContext, apimodels.PostableRoute) response.Response
+	RoutePutPolicyTree(*models.ReqContext, apimodels.NotificationPolicyTree) response.Response
+	RouteResetPolicyTree(*models.ReqContext) response.Response
+}
+
+func (api *API) RegisterProvisioningApiEndpoints(srv ProvisioningApiService, m *metrics.Metrics) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Delete(
+			toMacaronPath("/api/v1/provisioning/alert-rules/{UID}"),
+			api.authorize(http.MethodDelete, "/api/v1/provisioning/alert-rules/{UID}"),
+			api.audit(http.MethodDelete, "/api/v1/provisioning/alert-rules/{UID}"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/v1/provisioning/alert-rules/{UID}",
+				srv.RouteDeleteAlertRule,
+				m,
+			),
+		)
+		group.Delete(
+			toMacaronPath("/api/v1/provisioning/contact-points/{Name}"),
+			api.authorize(http.MethodDelete, "/api/v1/provisioning/contact-points/{Name}"),
+			api.audit(http.MethodDelete, "/api/v1/provisioning/contact-points/{Name}"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/v1/provisioning/contact-points/{Name}",
+				srv.RouteDeleteContactPoint,
+				m,
+			),
+		)
+		group.Delete(
+			toMacaronPath("/api/v1/provisioning/mute-timings/{UID}"),
+			api.authorize(http.MethodDelete, "/api/v1/provisioning/mute-timings/{UID}"),
+			api.audit(http.MethodDelete, "/api/v1/provisioning/mute-timings/{UID}"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/v1/provisioning/mute-timings/{UID}",
+				srv.RouteDeleteMuteTiming,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/alert-rules/{UID}"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/alert-rules/{UID}"),
+			api.audit(http.MethodGet, "/api/v1/provisioning/alert-rules/{UID}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/alert-rules/{UID}",
+				srv.RouteGetAlertRule,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}"),
+			api.audit(http.MethodGet, "/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}",
+				srv.RouteGetAlertRuleGroup,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/export"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/export"),
+			api.audit(http.MethodGet, "/api/v1/provisioning/export"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/export",
+				srv.RouteGetAlertingBundle,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/contact-points/{Name}/usage"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/contact-points/{Name}/usage"),
+			api.audit(http.MethodGet, "/api/v1/provisioning/contact-points/{Name}/usage"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/contact-points/{Name}/usage",
+				srv.RouteGetContactPointUsage,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/contact-points"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/contact-points"),
+			api.audit(http.MethodGet, "/api/v1/provisioning/contact-points"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/contact-points",
+				srv.RouteGetContactPoints,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/mute-timings/{UID}"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/mute-timings/{UID}"),
+			api.audit(http.MethodGet, "/api/v1/provisioning/mute-timings/{UID}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/mute-timings/{UID}",
+				srv.RouteGetMuteTiming,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/mute-timings/{UID}/usage"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/mute-timings/{UID}/usage"),
+			api.audit(http.MethodGet, "/api/v1/provisioning/mute-timings/{UID}/usage"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/mute-timings/{UID}/usage",
+				srv.RouteGetMuteTimingUsage,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/mute-timings"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/mute-timings"),
+			api.audit(http.MethodGet, "/api/v1/provisioning/mute-timings"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/mute-timings",
+				srv.RouteGetMuteTimings,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/policies"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/policies"),
+			api.audit(http.MethodGet, "/api/v1/provisioning/policies"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/policies",
+				srv.RouteGetPolicyTree,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/provisioning/alert-rules"),
+			api.authorize(http.MethodPost, "/api/v1/provisioning/alert-rules"),
+			api.audit(http.MethodPost, "/api/v1/provisioning/alert-rules"),
+			binding.Bind(apimodels.ProvisionedAlertRule{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/provisioning/alert-rules",
+				srv.RoutePostAlertRule,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/provisioning/import"),
+			api.authorize(http.MethodPost, "/api/v1/provisioning/import"),
+			api.audit(http.MethodPost, "/api/v1/provisioning/import"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/provisioning/import",
+				srv.RoutePostAlertingBundle,
+				m,
+			),
+		)
+		group.Put(
+			toMacaronPath("/api/v1/provisioning/alert-rules/{UID}"),
+			api.authorize(http.MethodPut, "/api/v1/provisioning/alert-rules/{UID}"),
+			api.audit(http.MethodPut, "/api/v1/provisioning/alert-rules/{UID}"),
+			binding.Bind(apimodels.ProvisionedAlertRule{}),
+			metrics.Instrument(
+				http.MethodPut,
+				"/api/v1/provisioning/alert-rules/{UID}",
+				srv.RoutePutAlertRule,
+				m,
+			),
+		)
+		group.Put(
+			toMacaronPath("/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}"),
+			api.authorize(http.MethodPut, "/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}"),
+			api.audit(http.MethodPut, "/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}"),
+			binding.Bind(apimodels.AlertRuleGroup{}),
+			metrics.Instrument(
+				http.MethodPut,
+				"/api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group}",
+				srv.RoutePutAlertRuleGroup,
+				m,
+			),
+		)
+		group.Put(
+			toMacaronPath("/api/v1/provisioning/contact-points/{Name}"),
+			api.authorize(http.MethodPut, "/api/v1/provisioning/contact-points/{Name}"),
+			api.audit(http.MethodPut, "/api/v1/provisioning/contact-points/{Name}"),
+			binding.Bind(apimodels.PostableContactPoint{}),
+			metrics.Instrument(
+				http.MethodPut,
+				"/api/v1/provisioning/contact-points/{Name}",
+				srv.RoutePutContactPoint,
+				m,
+			),
+		)
+		group.Put(
+			toMacaronPath("/api/v1/provisioning/mute-timings/{UID}"),
+			api.authorize(http.MethodPut, "/api/v1/provisioning/mute-timings/{UID}"),
+			api.audit(http.MethodPut, "/api/v1/provisioning/mute-timings/{UID}"),
+			binding.Bind(apimodels.PostableRecurringSilence{}),
+			metrics.Instrument(
+				http.MethodPut,
+				"/api/v1/provisioning/mute-timings/{UID}",
+				srv.RoutePutMuteTiming,
+				m,
+			),
+		)
+		group.Put(
+			toMacaronPath("/api/v1/provisioning/policies"),
+			api.authorize(http.MethodPut, "/api/v1/provisioning/policies"),
+			api.audit(http.MethodPut, "/api/v1/provisioning/policies"),
+			binding.Bind(apimodels.NotificationPolicyTree{}),
+			metrics.Instrument(
+				http.MethodPut,
+				"/api/v1/provisioning/policies",
+				srv.RoutePutPolicyTree,
+				m,
+			),
+		)
+		group.Delete(
+			toMacaronPath("/api/v1/provisioning/policies"),
+			api.authorize(http.MethodDelete, "/api/v1/provisioning/policies"),
+			api.audit(http.MethodDelete, "/api/v1/provisioning/policies"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/v1/provisioning/policies",
+				srv.RouteResetPolicyTree,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/policies/routes"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/policies/routes"),
+			api.audit(http.MethodGet, "/api/v1/provisioning/policies/routes"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/policies/routes",
+				srv.RouteGetPolicyRoutes,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/v1/provisioning/policies/routes/{RouteID}"),
+			api.authorize(http.MethodGet, "/api/v1/provisioning/policies/routes/{RouteID}"),
+			api.audit(http.MethodGet, "/api/v1/provisioning/policies/routes/{RouteID}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/v1/provisioning/policies/routes/{RouteID}",
+				srv.RouteGetPolicyRoute,
+				m,
+			),
+		)
+		group.Put(
+			toMacaronPath("/api/v1/provisioning/policies/routes/{RouteID}"),
+			api.authorize(http.MethodPut, "/api/v1/provisioning/policies/routes/{RouteID}"),
+			api.audit(http.MethodPut, "/api/v1/provisioning/policies/routes/{RouteID}"),
+			binding.Bind(apimodels.PostableRoute{}),
+			metrics.Instrument(
+				http.MethodPut,
+				"/api/v1/provisioning/policies/routes/{RouteID}",
+				srv.RoutePutPolicyRoute,
+				m,
+			),
+		)
+		group.Delete(
+			toMacaronPath("/api/v1/provisioning/policies/routes/{RouteID}"),
+			api.authorize(http.MethodDelete, "/api/v1/provisioning/policies/routes/{RouteID}"),
+			api.audit(http.MethodDelete, "/api/v1/provisioning/policies/routes/{RouteID}"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/v1/provisioning/policies/routes/{RouteID}",
+				srv.RouteDeletePolicyRoute,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/provisioning/policies/routes/{RouteID}/routes"),
+			api.authorize(http.MethodPost, "/api/v1/provisioning/policies/routes/{RouteID}/routes"),
+			api.audit(http.MethodPost, "/api/v1/provisioning/policies/routes/{RouteID}/routes"),
+			binding.Bind(apimodels.PostableRoute{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/provisioning/policies/routes/{RouteID}/routes",
+				srv.RoutePostPolicyRoute,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/provisioning/policies/routes/{RouteID}/move"),
+			api.authorize(http.MethodPost, "/api/v1/provisioning/policies/routes/{RouteID}/move"),
+			api.audit(http.MethodPost, "/api/v1/provisioning/policies/routes/{RouteID}/move"),
+			binding.Bind(apimodels.PostableRouteMove{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/provisioning/policies/routes/{RouteID}/move",
+				srv.RouteMovePolicyRoute,
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
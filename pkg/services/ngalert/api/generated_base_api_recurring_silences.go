@@ -0,0 +1,77 @@
+/*Package api contains base API implementation of unified alerting
+ *
+ *Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ *
+ *Do not manually edit these files, please find ngalert/api/swagger-codegen/ for commands on how to generate them.
+ */
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-macaron/binding"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+type RecurringSilencesApiService interface {
+	RouteCreateRecurringSilence(*models.ReqContext, apimodels.PostableRecurringSilence) response.Response
+	RouteDeleteRecurringSilence(*models.ReqContext) response.Response
+	RouteGetRecurringSilence(*models.ReqContext) response.Response
+	RouteGetRecurringSilences(*models.ReqContext) response.Response
+}
+
+func (api *API) RegisterRecurringSilencesApiEndpoints(srv RecurringSilencesApiService, m *metrics.Metrics) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Post(
+			toMacaronPath("/api/alertmanager/grafana/api/v1/recurring-silences"),
+			api.authorize(http.MethodPost, "/api/alertmanager/grafana/api/v1/recurring-silences"),
+			api.audit(http.MethodPost, "/api/alertmanager/grafana/api/v1/recurring-silences"),
+			binding.Bind(apimodels.PostableRecurringSilence{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/alertmanager/grafana/api/v1/recurring-silences",
+				srv.RouteCreateRecurringSilence,
+				m,
+			),
+		)
+		group.Delete(
+			toMacaronPath("/api/alertmanager/grafana/api/v1/recurring-silence/{RecurringSilenceUID}"),
+			api.authorize(http.MethodDelete, "/api/alertmanager/grafana/api/v1/recurring-silence/{RecurringSilenceUID}"),
+			api.audit(http.MethodDelete, "/api/alertmanager/grafana/api/v1/recurring-silence/{RecurringSilenceUID}"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/alertmanager/grafana/api/v1/recurring-silence/{RecurringSilenceUID}",
+				srv.RouteDeleteRecurringSilence,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/alertmanager/grafana/api/v1/recurring-silence/{RecurringSilenceUID}"),
+			api.authorize(http.MethodGet, "/api/alertmanager/grafana/api/v1/recurring-silence/{RecurringSilenceUID}"),
+			api.audit(http.MethodGet, "/api/alertmanager/grafana/api/v1/recurring-silence/{RecurringSilenceUID}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/alertmanager/grafana/api/v1/recurring-silence/{RecurringSilenceUID}",
+				srv.RouteGetRecurringSilence,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/alertmanager/grafana/api/v1/recurring-silences"),
+			api.authorize(http.MethodGet, "/api/alertmanager/grafana/api/v1/recurring-silences"),
+			api.audit(http.MethodGet, "/api/alertmanager/grafana/api/v1/recurring-silences"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/alertmanager/grafana/api/v1/recurring-silences",
+				srv.RouteGetRecurringSilences,
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
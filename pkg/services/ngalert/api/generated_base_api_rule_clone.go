@@ -0,0 +1,54 @@
+/*Package api contains base API implementation of unified alerting
+ *
+ *Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ *
+ *Do not manually edit these files, please find ngalert/api/swagger-codegen/ for commands on how to generate them.
+ */
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-macaron/binding"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+type RuleCloneApiService interface {
+	RoutePostCloneRule(*models.ReqContext, apimodels.PostableRuleClone) response.Response
+	RoutePostCloneRuleGroup(*models.ReqContext, apimodels.PostableRuleGroupClone) response.Response
+}
+
+func (api *API) RegisterRuleCloneApiEndpoints(srv RuleCloneApiService, m *metrics.Metrics) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Post(
+			toMacaronPath("/api/ruler/grafana/api/v1/rule/{RuleUID}/clone"),
+			api.authorize(http.MethodPost, "/api/ruler/grafana/api/v1/rule/{RuleUID}/clone"),
+			api.audit(http.MethodPost, "/api/ruler/grafana/api/v1/rule/{RuleUID}/clone"),
+			binding.Bind(apimodels.PostableRuleClone{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/ruler/grafana/api/v1/rule/{RuleUID}/clone",
+				srv.RoutePostCloneRule,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/ruler/grafana/api/v1/rules/{Namespace}/{Groupname}/clone"),
+			api.authorize(http.MethodPost, "/api/ruler/grafana/api/v1/rules/{Namespace}/{Groupname}/clone"),
+			api.audit(http.MethodPost, "/api/ruler/grafana/api/v1/rules/{Namespace}/{Groupname}/clone"),
+			binding.Bind(apimodels.PostableRuleGroupClone{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/ruler/grafana/api/v1/rules/{Namespace}/{Groupname}/clone",
+				srv.RoutePostCloneRuleGroup,
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
@@ -0,0 +1,41 @@
+/*Package api contains base API implementation of unified alerting
+ *
+ *Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ *
+ *Do not manually edit these files, please find ngalert/api/swagger-codegen/ for commands on how to generate them.
+ */
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-macaron/binding"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+type RuleGroupsApiService interface {
+	RoutePutRuleGroup(*models.ReqContext, apimodels.PostableRuleGroupConfig) response.Response
+}
+
+func (api *API) RegisterRuleGroupsApiEndpoints(srv RuleGroupsApiService, m *metrics.Metrics) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Put(
+			toMacaronPath("/api/ruler/grafana/api/v1/rules/{Namespace}/{Groupname}"),
+			api.authorize(http.MethodPut, "/api/ruler/grafana/api/v1/rules/{Namespace}/{Groupname}"),
+			api.audit(http.MethodPut, "/api/ruler/grafana/api/v1/rules/{Namespace}/{Groupname}"),
+			binding.Bind(apimodels.PostableRuleGroupConfig{}),
+			metrics.Instrument(
+				http.MethodPut,
+				"/api/ruler/grafana/api/v1/rules/{Namespace}/{Groupname}",
+				srv.RoutePutRuleGroup,
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
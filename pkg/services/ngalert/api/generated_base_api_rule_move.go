@@ -0,0 +1,54 @@
+/*Package api contains base API implementation of unified alerting
+ *
+ *Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ *
+ *Do not manually edit these files, please find ngalert/api/swagger-codegen/ for commands on how to generate them.
+ */
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-macaron/binding"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+type RuleMoveApiService interface {
+	RoutePostMoveRule(*models.ReqContext, apimodels.PostableRuleMove) response.Response
+	RoutePostMoveRuleGroup(*models.ReqContext, apimodels.PostableRuleGroupMove) response.Response
+}
+
+func (api *API) RegisterRuleMoveApiEndpoints(srv RuleMoveApiService, m *metrics.Metrics) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Post(
+			toMacaronPath("/api/ruler/grafana/api/v1/rule/{RuleUID}/move"),
+			api.authorize(http.MethodPost, "/api/ruler/grafana/api/v1/rule/{RuleUID}/move"),
+			api.audit(http.MethodPost, "/api/ruler/grafana/api/v1/rule/{RuleUID}/move"),
+			binding.Bind(apimodels.PostableRuleMove{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/ruler/grafana/api/v1/rule/{RuleUID}/move",
+				srv.RoutePostMoveRule,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/ruler/grafana/api/v1/rules/{Namespace}/{Groupname}/move"),
+			api.authorize(http.MethodPost, "/api/ruler/grafana/api/v1/rules/{Namespace}/{Groupname}/move"),
+			api.audit(http.MethodPost, "/api/ruler/grafana/api/v1/rules/{Namespace}/{Groupname}/move"),
+			binding.Bind(apimodels.PostableRuleGroupMove{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/ruler/grafana/api/v1/rules/{Namespace}/{Groupname}/move",
+				srv.RoutePostMoveRuleGroup,
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
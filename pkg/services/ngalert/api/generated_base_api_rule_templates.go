@@ -0,0 +1,102 @@
+/*Package api contains base API implementation of unified alerting
+ *
+ *Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ *
+ *Do not manually edit these files, please find ngalert/api/swagger-codegen/ for commands on how to generate them.
+ */
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-macaron/binding"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+type RuleTemplatesApiService interface {
+	RouteDeleteRuleTemplate(*models.ReqContext) response.Response
+	RouteGetRuleTemplate(*models.ReqContext) response.Response
+	RouteGetRuleTemplates(*models.ReqContext) response.Response
+	RoutePostRuleTemplate(*models.ReqContext, apimodels.PostableRuleTemplate) response.Response
+	RoutePostRuleTemplateInstance(*models.ReqContext, apimodels.PostableRuleTemplateInstance) response.Response
+	RoutePostRuleTemplateInstancesUpdate(*models.ReqContext) response.Response
+}
+
+func (api *API) RegisterRuleTemplatesApiEndpoints(srv RuleTemplatesApiService, m *metrics.Metrics) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Delete(
+			toMacaronPath("/api/ruler/grafana/api/v1/template/{TemplateUID}"),
+			api.authorize(http.MethodDelete, "/api/ruler/grafana/api/v1/template/{TemplateUID}"),
+			api.audit(http.MethodDelete, "/api/ruler/grafana/api/v1/template/{TemplateUID}"),
+			metrics.Instrument(
+				http.MethodDelete,
+				"/api/ruler/grafana/api/v1/template/{TemplateUID}",
+				srv.RouteDeleteRuleTemplate,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/ruler/grafana/api/v1/template/{TemplateUID}"),
+			api.authorize(http.MethodGet, "/api/ruler/grafana/api/v1/template/{TemplateUID}"),
+			api.audit(http.MethodGet, "/api/ruler/grafana/api/v1/template/{TemplateUID}"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/ruler/grafana/api/v1/template/{TemplateUID}",
+				srv.RouteGetRuleTemplate,
+				m,
+			),
+		)
+		group.Get(
+			toMacaronPath("/api/ruler/grafana/api/v1/templates"),
+			api.authorize(http.MethodGet, "/api/ruler/grafana/api/v1/templates"),
+			api.audit(http.MethodGet, "/api/ruler/grafana/api/v1/templates"),
+			metrics.Instrument(
+				http.MethodGet,
+				"/api/ruler/grafana/api/v1/templates",
+				srv.RouteGetRuleTemplates,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/ruler/grafana/api/v1/templates"),
+			api.authorize(http.MethodPost, "/api/ruler/grafana/api/v1/templates"),
+			api.audit(http.MethodPost, "/api/ruler/grafana/api/v1/templates"),
+			binding.Bind(apimodels.PostableRuleTemplate{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/ruler/grafana/api/v1/templates",
+				srv.RoutePostRuleTemplate,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/ruler/grafana/api/v1/template/{TemplateUID}/instantiate"),
+			api.authorize(http.MethodPost, "/api/ruler/grafana/api/v1/template/{TemplateUID}/instantiate"),
+			api.audit(http.MethodPost, "/api/ruler/grafana/api/v1/template/{TemplateUID}/instantiate"),
+			binding.Bind(apimodels.PostableRuleTemplateInstance{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/ruler/grafana/api/v1/template/{TemplateUID}/instantiate",
+				srv.RoutePostRuleTemplateInstance,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/ruler/grafana/api/v1/template/{TemplateUID}/instances/update"),
+			api.authorize(http.MethodPost, "/api/ruler/grafana/api/v1/template/{TemplateUID}/instances/update"),
+			api.audit(http.MethodPost, "/api/ruler/grafana/api/v1/template/{TemplateUID}/instances/update"),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/ruler/grafana/api/v1/template/{TemplateUID}/instances/update",
+				srv.RoutePostRuleTemplateInstancesUpdate,
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
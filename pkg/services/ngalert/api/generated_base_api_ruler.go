@@ -32,6 +32,8 @@ func (api *API) RegisterRulerApiEndpoints(srv RulerApiService, m *metrics.Metric
 	api.RouteRegister.Group("", func(group routing.RouteRegister) {
 		group.Delete(
 			toMacaronPath("/api/ruler/{Recipient}/api/v1/rules/{Namespace}"),
+			api.authorize(http.MethodDelete, "/api/ruler/{Recipient}/api/v1/rules/{Namespace}"),
+			api.audit(http.MethodDelete, "/api/ruler/{Recipient}/api/v1/rules/{Namespace}"),
 			metrics.Instrument(
 				http.MethodDelete,
 				"/api/ruler/{Recipient}/api/v1/rules/{Namespace}",
@@ -41,6 +43,8 @@ func (api *API) RegisterRulerApiEndpoints(srv RulerApiService, m *metrics.Metric
 		)
 		group.Delete(
 			toMacaronPath("/api/ruler/{Recipient}/api/v1/rules/{Namespace}/{Groupname}"),
+			api.authorize(http.MethodDelete, "/api/ruler/{Recipient}/api/v1/rules/{Namespace}/{Groupname}"),
+			api.audit(http.MethodDelete, "/api/ruler/{Recipient}/api/v1/rules/{Namespace}/{Groupname}"),
 			metrics.Instrument(
 				http.MethodDelete,
 				"/api/ruler/{Recipient}/api/v1/rules/{Namespace}/{Groupname}",
@@ -50,6 +54,8 @@ func (api *API) RegisterRulerApiEndpoints(srv RulerApiService, m *metrics.Metric
 		)
 		group.Get(
 			toMacaronPath("/api/ruler/{Recipient}/api/v1/rules/{Namespace}"),
+			api.authorize(http.MethodGet, "/api/ruler/{Recipient}/api/v1/rules/{Namespace}"),
+			api.audit(http.MethodGet, "/api/ruler/{Recipient}/api/v1/rules/{Namespace}"),
 			metrics.Instrument(
 				http.MethodGet,
 				"/api/ruler/{Recipient}/api/v1/rules/{Namespace}",
@@ -59,6 +65,8 @@ func (api *API) RegisterRulerApiEndpoints(srv RulerApiService, m *metrics.Metric
 		)
 		group.Get(
 			toMacaronPath("/api/ruler/{Recipient}/api/v1/rules/{Namespace}/{Groupname}"),
+			api.authorize(http.MethodGet, "/api/ruler/{Recipient}/api/v1/rules/{Namespace}/{Groupname}"),
+			api.audit(http.MethodGet, "/api/ruler/{Recipient}/api/v1/rules/{Namespace}/{Groupname}"),
 			metrics.Instrument(
 				http.MethodGet,
 				"/api/ruler/{Recipient}/api/v1/rules/{Namespace}/{Groupname}",
@@ -68,6 +76,8 @@ func (api *API) RegisterRulerApiEndpoints(srv RulerApiService, m *metrics.Metric
 		)
 		group.Get(
 			toMacaronPath("/api/ruler/{Recipient}/api/v1/rules"),
+			api.authorize(http.MethodGet, "/api/ruler/{Recipient}/api/v1/rules"),
+			api.audit(http.MethodGet, "/api/ruler/{Recipient}/api/v1/rules"),
 			metrics.Instrument(
 				http.MethodGet,
 				"/api/ruler/{Recipient}/api/v1/rules",
@@ -77,6 +87,8 @@ func (api *API) RegisterRulerApiEndpoints(srv RulerApiService, m *metrics.Metric
 		)
 		group.Post(
 			toMacaronPath("/api/ruler/{Recipient}/api/v1/rules/{Namespace}"),
+			api.authorize(http.MethodPost, "/api/ruler/{Recipient}/api/v1/rules/{Namespace}"),
+			api.audit(http.MethodPost, "/api/ruler/{Recipient}/api/v1/rules/{Namespace}"),
 			binding.Bind(apimodels.PostableRuleGroupConfig{}),
 			metrics.Instrument(
 				http.MethodPost,
@@ -0,0 +1,41 @@
+/*Package api contains base API implementation of unified alerting
+ *
+ *Generated by: Swagger Codegen (https://github.com/swagger-api/swagger-codegen.git)
+ *
+ *Do not manually edit these files, please find ngalert/api/swagger-codegen/ for commands on how to generate them.
+ */
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-macaron/binding"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+type SloApiService interface {
+	RoutePostSLORules(*models.ReqContext, apimodels.PostableSLORules) response.Response
+}
+
+func (api *API) RegisterSloApiEndpoints(srv SloApiService, m *metrics.Metrics) {
+	api.RouteRegister.Group("", func(group routing.RouteRegister) {
+		group.Post(
+			toMacaronPath("/api/ruler/grafana/api/v1/slo"),
+			api.authorize(http.MethodPost, "/api/ruler/grafana/api/v1/slo"),
+			api.audit(http.MethodPost, "/api/ruler/grafana/api/v1/slo"),
+			binding.Bind(apimodels.PostableSLORules{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/ruler/grafana/api/v1/slo",
+				srv.RoutePostSLORules,
+				m,
+			),
+		)
+	}, middleware.ReqSignedIn)
+}
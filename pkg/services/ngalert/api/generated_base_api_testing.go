@@ -21,14 +21,19 @@ import (
 
 type TestingApiService interface {
 	RouteEvalQueries(*models.ReqContext, apimodels.EvalQueriesPayload) response.Response
+	RouteRunRuleUnitTests(*models.ReqContext, apimodels.RuleUnitTestPayload) response.Response
+	RouteTestInlineRule(*models.ReqContext, apimodels.TestInlineRulePayload) response.Response
 	RouteTestReceiverConfig(*models.ReqContext, apimodels.ExtendedReceiver) response.Response
 	RouteTestRuleConfig(*models.ReqContext, apimodels.TestRulePayload) response.Response
+	RouteValidateRuleTemplates(*models.ReqContext, apimodels.ValidateRuleTemplatesPayload) response.Response
 }
 
 func (api *API) RegisterTestingApiEndpoints(srv TestingApiService, m *metrics.Metrics) {
 	api.RouteRegister.Group("", func(group routing.RouteRegister) {
 		group.Post(
 			toMacaronPath("/api/v1/eval"),
+			api.authorize(http.MethodPost, "/api/v1/eval"),
+			api.audit(http.MethodPost, "/api/v1/eval"),
 			binding.Bind(apimodels.EvalQueriesPayload{}),
 			metrics.Instrument(
 				http.MethodPost,
@@ -37,8 +42,34 @@ func (api *API) RegisterTestingApiEndpoints(srv TestingApiService, m *metrics.Me
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/v1/rule/unit-test"),
+			api.authorize(http.MethodPost, "/api/v1/rule/unit-test"),
+			api.audit(http.MethodPost, "/api/v1/rule/unit-test"),
+			binding.Bind(apimodels.RuleUnitTestPayload{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/rule/unit-test",
+				srv.RouteRunRuleUnitTests,
+				m,
+			),
+		)
+		group.Post(
+			toMacaronPath("/api/v1/rule/test"),
+			api.authorize(http.MethodPost, "/api/v1/rule/test"),
+			api.audit(http.MethodPost, "/api/v1/rule/test"),
+			binding.Bind(apimodels.TestInlineRulePayload{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/rule/test",
+				srv.RouteTestInlineRule,
+				m,
+			),
+		)
 		group.Post(
 			toMacaronPath("/api/v1/receiver/test/{Recipient}"),
+			api.authorize(http.MethodPost, "/api/v1/receiver/test/{Recipient}"),
+			api.audit(http.MethodPost, "/api/v1/receiver/test/{Recipient}"),
 			binding.Bind(apimodels.ExtendedReceiver{}),
 			metrics.Instrument(
 				http.MethodPost,
@@ -49,6 +80,8 @@ func (api *API) RegisterTestingApiEndpoints(srv TestingApiService, m *metrics.Me
 		)
 		group.Post(
 			toMacaronPath("/api/v1/rule/test/{Recipient}"),
+			api.authorize(http.MethodPost, "/api/v1/rule/test/{Recipient}"),
+			api.audit(http.MethodPost, "/api/v1/rule/test/{Recipient}"),
 			binding.Bind(apimodels.TestRulePayload{}),
 			metrics.Instrument(
 				http.MethodPost,
@@ -57,5 +90,17 @@ func (api *API) RegisterTestingApiEndpoints(srv TestingApiService, m *metrics.Me
 				m,
 			),
 		)
+		group.Post(
+			toMacaronPath("/api/v1/rule/validate-templates"),
+			api.authorize(http.MethodPost, "/api/v1/rule/validate-templates"),
+			api.audit(http.MethodPost, "/api/v1/rule/validate-templates"),
+			binding.Bind(apimodels.ValidateRuleTemplatesPayload{}),
+			metrics.Instrument(
+				http.MethodPost,
+				"/api/v1/rule/validate-templates",
+				srv.RouteValidateRuleTemplates,
+				m,
+			),
+		)
 	}, middleware.ReqSignedIn)
 }
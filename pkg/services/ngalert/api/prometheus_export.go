@@ -0,0 +1,385 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// reducerOverTime is the PromQL function of each reducer, applied to a subquery over the time range of the query.
+// The last value is the value of the query at the evaluation time.
+var reducerOverTime = map[string]string{
+	"mean":  "avg_over_time",
+	"min":   "min_over_time",
+	"max":   "max_over_time",
+	"sum":   "sum_over_time",
+	"count": "count_over_time",
+}
+
+var promQLOperators = map[string]string{
+	"+": "+", "-": "-", "*": "*", "/": "/", "%": "%", "**": "^",
+	"==": "==", "!=": "!=", ">": ">", ">=": ">=", "<": "<", "<=": "<=",
+	"&&": "and", "||": "or",
+}
+
+func isComparison(op string) bool {
+	switch op {
+	case "==", "!=", ">", ">=", "<", "<=":
+		return true
+	}
+	return false
+}
+
+// exportedPrometheusRule is the result of the export of a Grafana managed rule to a Prometheus alerting rule.
+type exportedPrometheusRule struct {
+	rule apimodels.ApiRuleNode
+	// warnings are the Grafana features of the rule that are lost in the export.
+	warnings []string
+	// err is why the rule can't be exported.
+	err error
+}
+
+// exportPrometheusRule converts a Grafana managed rule to a Prometheus alerting rule where possible. The rule can be
+// exported if its condition can be written in PromQL, i.e. it only queries Prometheus datasources and uses reduce
+// and math expressions.
+func exportPrometheusRule(r *ngmodels.AlertRule, datasourceType func(uid string) string) exportedPrometheusRule {
+	c := promQLConverter{
+		queries:        make(map[string]ngmodels.AlertQuery, len(r.Data)),
+		datasourceType: datasourceType,
+		visiting:       map[string]bool{},
+	}
+	for _, q := range r.Data {
+		c.queries[q.RefID] = q
+	}
+
+	promQL, err := c.condition(r.Condition)
+	if err != nil {
+		return exportedPrometheusRule{err: err}
+	}
+
+	var warnings []string
+	if r.NoDataState != ngmodels.OK {
+		warnings = append(warnings, fmt.Sprintf("the no data state %s is not supported, Prometheus doesn't fire without results", r.NoDataState))
+	}
+	return exportedPrometheusRule{
+		rule: apimodels.ApiRuleNode{
+			Alert:       r.Title,
+			Expr:        promQL,
+			For:         model.Duration(r.For),
+			Labels:      r.Labels,
+			Annotations: r.Annotations,
+		},
+		warnings: warnings,
+	}
+}
+
+// promQLConverter writes the queries and expressions of a rule in PromQL.
+type promQLConverter struct {
+	queries        map[string]ngmodels.AlertQuery
+	datasourceType func(uid string) string
+	visiting       map[string]bool
+}
+
+type queryModel struct {
+	Expr       string `json:"expr"`
+	Type       string `json:"type"`
+	Expression string `json:"expression"`
+	Reducer    string `json:"reducer"`
+}
+
+func (c promQLConverter) model(refID string) (ngmodels.AlertQuery, queryModel, error) {
+	q, ok := c.queries[refID]
+	if !ok {
+		return q, queryModel{}, fmt.Errorf("query %s not found", refID)
+	}
+	var m queryModel
+	if err := json.Unmarshal(q.Model, &m); err != nil {
+		return q, m, fmt.Errorf("failed to read query %s: %w", refID, err)
+	}
+	return q, m, nil
+}
+
+// condition returns the PromQL returning the firing series of the condition.
+func (c promQLConverter) condition(refID string) (string, error) {
+	q, m, err := c.model(refID)
+	if err != nil {
+		return "", err
+	}
+	if q.DatasourceUID != expr.DatasourceUID {
+		return "", fmt.Errorf("the condition %s is a time series query, not an expression", refID)
+	}
+
+	switch m.Type {
+	case "reduce":
+		// Every series returned by the query fires, like the series of a Prometheus alerting rule.
+		if m.Reducer == "count" {
+			if target, ok := c.queries[m.Expression]; ok && target.DatasourceUID != expr.DatasourceUID {
+				return c.query(m.Expression)
+			}
+		}
+	case "math":
+		root, err := c.parseMath(refID, m.Expression)
+		if err != nil {
+			return "", err
+		}
+		return c.boolean(root)
+	}
+	value, err := c.value(refID)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s != 0", value), nil
+}
+
+// query returns the PromQL of a datasource query.
+func (c promQLConverter) query(refID string) (string, error) {
+	q, m, err := c.model(refID)
+	if err != nil {
+		return "", err
+	}
+	if t := c.datasourceType(q.DatasourceUID); t != models.DS_PROMETHEUS {
+		return "", fmt.Errorf("query %s uses a %s datasource", refID, t)
+	}
+	if q.RelativeTimeRange.To != 0 {
+		return "", fmt.Errorf("the time range of query %s doesn't end at the evaluation time", refID)
+	}
+	if q.RelativeTimeRange.TimeShift != 0 {
+		return "", fmt.Errorf("the time range of query %s is shifted", refID)
+	}
+	if m.Expr == "" {
+		return "", fmt.Errorf("query %s has no expression", refID)
+	}
+	return m.Expr, nil
+}
+
+// value returns the PromQL of the values of an expression.
+func (c promQLConverter) value(refID string) (string, error) {
+	if c.visiting[refID] {
+		return "", fmt.Errorf("expression %s depends on itself", refID)
+	}
+	c.visiting[refID] = true
+	defer delete(c.visiting, refID)
+
+	q, m, err := c.model(refID)
+	if err != nil {
+		return "", err
+	}
+	if q.DatasourceUID != expr.DatasourceUID {
+		return "", fmt.Errorf("query %s is used without being reduced", refID)
+	}
+
+	switch m.Type {
+	case "reduce":
+		target, ok := c.queries[m.Expression]
+		if !ok || target.DatasourceUID == expr.DatasourceUID {
+			return "", fmt.Errorf("expression %s doesn't reduce a query", refID)
+		}
+		promQL, err := c.query(m.Expression)
+		if err != nil {
+			return "", err
+		}
+		if m.Reducer == "last" {
+			return fmt.Sprintf("(%s)", promQL), nil
+		}
+		f, ok := reducerOverTime[m.Reducer]
+		if !ok {
+			return "", fmt.Errorf("the reducer %s of expression %s is not supported", m.Reducer, refID)
+		}
+		window := model.Duration(time.Duration(target.RelativeTimeRange.From))
+		return fmt.Sprintf("%s((%s)[%s:])", f, promQL, window), nil
+	case "math":
+		root, err := c.parseMath(refID, m.Expression)
+		if err != nil {
+			return "", err
+		}
+		return c.arithmetic(root)
+	default:
+		return "", fmt.Errorf("%s expressions are not supported", m.Type)
+	}
+}
+
+func (c promQLConverter) parseMath(refID, expression string) (parse.Node, error) {
+	e, err := mathexp.New(expression)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the math expression %s: %w", refID, err)
+	}
+	return e.Tree.Root, nil
+}
+
+// boolean returns the PromQL of a math expression used as a condition, that only returns the firing series.
+func (c promQLConverter) boolean(n parse.Node) (string, error) {
+	if b, ok := n.(*parse.BinaryNode); ok {
+		switch {
+		case b.OpStr == "&&" || b.OpStr == "||":
+			l, err := c.boolean(b.Args[0])
+			if err != nil {
+				return "", err
+			}
+			r, err := c.boolean(b.Args[1])
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("(%s) %s (%s)", l, promQLOperators[b.OpStr], r), nil
+		case isComparison(b.OpStr):
+			l, err := c.arithmetic(b.Args[0])
+			if err != nil {
+				return "", err
+			}
+			r, err := c.arithmetic(b.Args[1])
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%s %s %s", l, b.OpStr, r), nil
+		}
+	}
+	v, err := c.arithmetic(n)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s != 0", v), nil
+}
+
+// arithmetic returns the PromQL of a math expression computing values.
+func (c promQLConverter) arithmetic(n parse.Node) (string, error) {
+	switch n := n.(type) {
+	case *parse.ScalarNode:
+		return n.Text, nil
+	case *parse.VarNode:
+		return c.value(n.Name)
+	case *parse.UnaryNode:
+		if n.OpStr != "-" {
+			return "", fmt.Errorf("the operator %s is not supported", n.OpStr)
+		}
+		v, err := c.arithmetic(n.Arg)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("-(%s)", v), nil
+	case *parse.BinaryNode:
+		if isComparison(n.OpStr) || n.OpStr == "&&" || n.OpStr == "||" {
+			return "", fmt.Errorf("the result of %s is used as a value", n)
+		}
+		op, ok := promQLOperators[n.OpStr]
+		if !ok {
+			return "", fmt.Errorf("the operator %s is not supported", n.OpStr)
+		}
+		l, err := c.arithmetic(n.Args[0])
+		if err != nil {
+			return "", err
+		}
+		r, err := c.arithmetic(n.Args[1])
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("(%s %s %s)", l, op, r), nil
+	case *parse.FuncNode:
+		switch n.Name {
+		case "nan":
+			return "NaN", nil
+		case "inf":
+			return "Inf", nil
+		case "abs", "log":
+			if len(n.Args) != 1 {
+				return "", fmt.Errorf("%s expects one argument", n.Name)
+			}
+			v, err := c.arithmetic(n.Args[0])
+			if err != nil {
+				return "", err
+			}
+			f := n.Name
+			if f == "log" {
+				f = "ln"
+			}
+			return fmt.Sprintf("%s(%s)", f, v), nil
+		}
+		return "", fmt.Errorf("the function %s is not supported", n.Name)
+	default:
+		return "", fmt.Errorf("%s is not supported", n)
+	}
+}
+
+// exportPrometheusRuleGroups exports the rules of a namespace to the groups of a Prometheus rule file, ordered by name.
+// The rules that can't be exported are left out, they are listed with the warnings of the exported rules in the
+// comments of the YAML.
+func exportPrometheusRuleGroups(rules []*ngmodels.AlertRule, datasourceType func(uid string) string) (*yaml.Node, error) {
+	rules = append([]*ngmodels.AlertRule(nil), rules...)
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].RuleGroup != rules[j].RuleGroup {
+			return rules[i].RuleGroup < rules[j].RuleGroup
+		}
+		return rules[i].ID < rules[j].ID
+	})
+
+	groups := &yaml.Node{Kind: yaml.SequenceNode}
+	for i := 0; i < len(rules); {
+		j := i
+		for j < len(rules) && rules[j].RuleGroup == rules[i].RuleGroup {
+			j++
+		}
+		group, err := exportPrometheusRuleGroup(rules[i:j], datasourceType)
+		if err != nil {
+			return nil, err
+		}
+		groups.Content = append(groups.Content, group)
+		i = j
+	}
+	return &yaml.Node{
+		Kind: yaml.MappingNode,
+		Content: []*yaml.Node{
+			{Kind: yaml.ScalarNode, Value: "groups"},
+			groups,
+		},
+	}, nil
+}
+
+// exportPrometheusRuleGroup exports the rules of a group, they are in the order they were created.
+func exportPrometheusRuleGroup(rules []*ngmodels.AlertRule, datasourceType func(uid string) string) (*yaml.Node, error) {
+	group := &yaml.Node{}
+	if err := group.Encode(apimodels.PrometheusRuleGroup{
+		Name:     rules[0].RuleGroup,
+		Interval: model.Duration(time.Duration(rules[0].IntervalSeconds) * time.Second),
+	}); err != nil {
+		return nil, err
+	}
+
+	ruleNodes := &yaml.Node{Kind: yaml.SequenceNode}
+	var excluded []string
+	for _, r := range rules {
+		exported := exportPrometheusRule(r, datasourceType)
+		if exported.err != nil {
+			excluded = append(excluded, fmt.Sprintf("%q is not exported: %s", r.Title, exported.err))
+			continue
+		}
+		n := &yaml.Node{}
+		if err := n.Encode(exported.rule); err != nil {
+			return nil, err
+		}
+		if len(exported.warnings) > 0 {
+			n.HeadComment = "Grafana only: " + strings.Join(exported.warnings, "; ")
+		}
+		ruleNodes.Content = append(ruleNodes.Content, n)
+	}
+	if len(excluded) > 0 {
+		group.HeadComment = "Grafana only: " + strings.Join(excluded, "\n")
+	}
+
+	// Replace the empty rules of the encoded group.
+	for i := 0; i+1 < len(group.Content); i += 2 {
+		if group.Content[i].Value == "rules" {
+			group.Content[i+1] = ruleNodes
+		}
+	}
+	return group, nil
+}
@@ -0,0 +1,170 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/expr"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func exportTestQuery(t *testing.T, refID, datasourceUID string, from time.Duration, model map[string]interface{}) ngmodels.AlertQuery {
+	t.Helper()
+	b, err := json.Marshal(model)
+	require.NoError(t, err)
+	return ngmodels.AlertQuery{
+		RefID:             refID,
+		DatasourceUID:     datasourceUID,
+		RelativeTimeRange: ngmodels.RelativeTimeRange{From: ngmodels.Duration(from)},
+		Model:             b,
+	}
+}
+
+func exportTestDatasourceTypes(uid string) string {
+	switch uid {
+	case "prom":
+		return "prometheus"
+	case "loki":
+		return "loki"
+	default:
+		return "unknown"
+	}
+}
+
+func TestExportPrometheusRule(t *testing.T) {
+	promQuery := func(refID string) ngmodels.AlertQuery {
+		return exportTestQuery(t, refID, "prom", 5*time.Minute, map[string]interface{}{"expr": `rate(http_requests_total[1m])`})
+	}
+	reduce := func(refID, reducer, expression string) ngmodels.AlertQuery {
+		return exportTestQuery(t, refID, expr.DatasourceUID, 0, map[string]interface{}{"type": "reduce", "reducer": reducer, "expression": expression})
+	}
+	math := func(refID, expression string) ngmodels.AlertQuery {
+		return exportTestQuery(t, refID, expr.DatasourceUID, 0, map[string]interface{}{"type": "math", "expression": expression})
+	}
+
+	testCases := []struct {
+		desc      string
+		condition string
+		data      []ngmodels.AlertQuery
+		expr      string
+		err       string
+	}{
+		{
+			desc:      "query with a threshold on the last value",
+			condition: "C",
+			data:      []ngmodels.AlertQuery{promQuery("A"), reduce("B", "last", "A"), math("C", "$B > 10")},
+			expr:      "(rate(http_requests_total[1m])) > 10",
+		},
+		{
+			desc:      "query with a range of the mean",
+			condition: "C",
+			data:      []ngmodels.AlertQuery{promQuery("A"), reduce("B", "mean", "A"), math("C", "$B > 10 && $B < 100")},
+			expr:      "(avg_over_time((rate(http_requests_total[1m]))[5m:]) > 10) and (avg_over_time((rate(http_requests_total[1m]))[5m:]) < 100)",
+		},
+		{
+			desc:      "arithmetic on the values",
+			condition: "C",
+			data:      []ngmodels.AlertQuery{promQuery("A"), reduce("B", "max", "A"), math("C", "abs($B * 2) >= 1")},
+			expr:      "abs((max_over_time((rate(http_requests_total[1m]))[5m:]) * 2)) >= 1",
+		},
+		{
+			desc:      "reduced value as condition",
+			condition: "B",
+			data:      []ngmodels.AlertQuery{promQuery("A"), reduce("B", "last", "A")},
+			expr:      "(rate(http_requests_total[1m])) != 0",
+		},
+		{
+			desc:      "series count as condition",
+			condition: "B",
+			data:      []ngmodels.AlertQuery{promQuery("A"), reduce("B", "count", "A")},
+			expr:      "rate(http_requests_total[1m])",
+		},
+		{
+			desc:      "query of another datasource",
+			condition: "B",
+			data: []ngmodels.AlertQuery{
+				exportTestQuery(t, "A", "loki", 5*time.Minute, map[string]interface{}{"expr": `count_over_time({job="api"}[1m])`}),
+				reduce("B", "last", "A"),
+			},
+			err: "query A uses a loki datasource",
+		},
+		{
+			desc:      "classic condition",
+			condition: "B",
+			data: []ngmodels.AlertQuery{
+				promQuery("A"),
+				exportTestQuery(t, "B", expr.DatasourceUID, 0, map[string]interface{}{"type": "classic_conditions"}),
+			},
+			err: "classic_conditions expressions are not supported",
+		},
+		{
+			desc:      "unsupported reducer",
+			condition: "C",
+			data:      []ngmodels.AlertQuery{promQuery("A"), reduce("B", "median", "A"), math("C", "$B > 1")},
+			err:       "the reducer median of expression B is not supported",
+		},
+		{
+			desc:      "comparison used as value",
+			condition: "C",
+			data:      []ngmodels.AlertQuery{promQuery("A"), reduce("B", "last", "A"), math("C", "($B > 1) * 2")},
+			err:       "the result of $B > 1 is used as a value",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			exported := exportPrometheusRule(&ngmodels.AlertRule{
+				Title:       "rule",
+				Condition:   tc.condition,
+				Data:        tc.data,
+				NoDataState: ngmodels.OK,
+			}, exportTestDatasourceTypes)
+			if tc.err != "" {
+				require.EqualError(t, exported.err, tc.err)
+				return
+			}
+			require.NoError(t, exported.err)
+			require.Equal(t, tc.expr, exported.rule.Expr)
+			require.Empty(t, exported.warnings)
+		})
+	}
+}
+
+func TestExportPrometheusRuleGroups(t *testing.T) {
+	promData, err := prometheusRuleData("up == 0", "prom")
+	require.NoError(t, err)
+	lokiData, err := prometheusRuleData(`count_over_time({job="api"}[1m]) > 0`, "loki")
+	require.NoError(t, err)
+
+	rules := []*ngmodels.AlertRule{
+		{ID: 3, Title: "Errors", Condition: "B", Data: lokiData, RuleGroup: "api", IntervalSeconds: 60, NoDataState: ngmodels.OK},
+		{ID: 1, Title: "Down", Condition: "B", Data: promData, RuleGroup: "api", IntervalSeconds: 60, NoDataState: ngmodels.OK,
+			For: 5 * time.Minute, Labels: map[string]string{"severity": "critical"}},
+		{ID: 2, Title: "NoTargets", Condition: "B", Data: promData, RuleGroup: "targets", IntervalSeconds: 120, NoDataState: ngmodels.Alerting},
+	}
+	file, err := exportPrometheusRuleGroups(rules, exportTestDatasourceTypes)
+	require.NoError(t, err)
+	b, err := yaml.Marshal(file)
+	require.NoError(t, err)
+
+	require.Equal(t, `groups:
+    # Grafana only: "Errors" is not exported: query A uses a loki datasource
+    - name: api
+      interval: 1m
+      rules:
+        - alert: Down
+          expr: up == 0
+          for: 5m
+          labels:
+            severity: critical
+    - name: targets
+      interval: 2m
+      rules:
+        # Grafana only: the no data state Alerting is not supported, Prometheus doesn't fire without results
+        - alert: NoTargets
+          expr: up == 0
+`, string(b))
+}
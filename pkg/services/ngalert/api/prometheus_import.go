@@ -0,0 +1,83 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// prometheusRuleFileToGrafana converts the groups of a Prometheus rule file to the rule groups replacing the groups
+// with the same name in a namespace, given the existing rules of the namespace. It returns the changes made to each
+// group. Groups of Grafana managed rules that can't be represented as Prometheus alerting rules are not overwritten,
+// an error wrapping errNotPrometheusCompatible is returned instead.
+func prometheusRuleFileToGrafana(file apimodels.PrometheusRuleFile, datasourceUID string, existing []*ngmodels.AlertRule) ([]apimodels.PostableRuleGroupConfig, []apimodels.PrometheusImportGroupResult, error) {
+	if len(file.Groups) == 0 {
+		return nil, nil, errors.New("rule file has no groups")
+	}
+
+	existingGroups := make(map[string][]*ngmodels.AlertRule)
+	for _, r := range existing {
+		existingGroups[r.RuleGroup] = append(existingGroups[r.RuleGroup], r)
+	}
+
+	imported := make(map[string]struct{}, len(file.Groups))
+	for _, group := range file.Groups {
+		if group.Name == "" {
+			return nil, nil, errors.New("rule group name is not valid")
+		}
+		if _, ok := imported[group.Name]; ok {
+			return nil, nil, fmt.Errorf("rule group %q is defined more than once", group.Name)
+		}
+		imported[group.Name] = struct{}{}
+	}
+
+	// Titles are unique in a namespace, so they must not be used by the groups that are kept.
+	titles := make(map[string]string)
+	for _, r := range existing {
+		if _, ok := imported[r.RuleGroup]; !ok {
+			titles[r.Title] = r.RuleGroup
+		}
+	}
+
+	configs := make([]apimodels.PostableRuleGroupConfig, 0, len(file.Groups))
+	results := make([]apimodels.PrometheusImportGroupResult, 0, len(file.Groups))
+	for _, group := range file.Groups {
+		rules := existingGroups[group.Name]
+		if _, incompatible := grafanaRulesToPrometheusGroups(rules); len(incompatible) > 0 {
+			return nil, nil, fmt.Errorf("rule group %q is not managed as Prometheus alerting rules: %w", group.Name, incompatible[group.Name])
+		}
+
+		config, err := prometheusRuleGroupToGrafana(group, datasourceUID, rules)
+		if err != nil {
+			return nil, nil, fmt.Errorf("rule group %q: %w", group.Name, err)
+		}
+
+		result := apimodels.PrometheusImportGroupResult{Name: group.Name}
+		kept := make(map[string]struct{}, len(config.Rules))
+		for _, r := range config.Rules {
+			title := r.GrafanaManagedAlert.Title
+			if other, ok := titles[title]; ok {
+				return nil, nil, fmt.Errorf("rule group %q: alerting rule %q already exists in rule group %q", group.Name, title, other)
+			}
+			titles[title] = group.Name
+
+			if r.GrafanaManagedAlert.UID != "" {
+				kept[r.GrafanaManagedAlert.UID] = struct{}{}
+				result.Updated = append(result.Updated, title)
+			} else {
+				result.Created = append(result.Created, title)
+			}
+		}
+		for _, r := range rules {
+			if _, ok := kept[r.UID]; !ok {
+				result.Deleted = append(result.Deleted, r.Title)
+			}
+		}
+
+		configs = append(configs, config)
+		results = append(results, result)
+	}
+	return configs, results, nil
+}
@@ -0,0 +1,110 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const prometheusRuleFileYAML = `
+groups:
+  - name: api
+    rules:
+      - alert: Down
+        expr: up == 0
+        for: 5m
+      - alert: HighLatency
+        expr: histogram_quantile(0.99, rate(http_request_duration_seconds_bucket[5m])) > 1
+  - name: targets
+    rules:
+      - alert: NoTargets
+        expr: absent(up)
+`
+
+func TestPrometheusRuleFileToGrafana(t *testing.T) {
+	var file apimodels.PrometheusRuleFile
+	require.NoError(t, yaml.Unmarshal([]byte(prometheusRuleFileYAML), &file))
+
+	data, err := prometheusRuleData("up == 0", "prom-uid")
+	require.NoError(t, err)
+	existing := []*ngmodels.AlertRule{
+		{ID: 1, UID: "down-uid", Title: "Down", Condition: prometheusConditionRefID, Data: data, RuleGroup: "api", IntervalSeconds: 60},
+		{ID: 2, UID: "old-uid", Title: "Old", Condition: prometheusConditionRefID, Data: data, RuleGroup: "api", IntervalSeconds: 60},
+		{ID: 3, UID: "other-uid", Title: "Other", Condition: "C", Data: data, RuleGroup: "grafana", IntervalSeconds: 60},
+	}
+
+	configs, results, err := prometheusRuleFileToGrafana(file, "prom-uid", existing)
+	require.NoError(t, err)
+	require.Len(t, configs, 2)
+	require.Equal(t, "api", configs[0].Name)
+	require.Equal(t, "down-uid", configs[0].Rules[0].GrafanaManagedAlert.UID)
+	require.Empty(t, configs[0].Rules[1].GrafanaManagedAlert.UID)
+	require.Equal(t, "targets", configs[1].Name)
+
+	require.Equal(t, []apimodels.PrometheusImportGroupResult{
+		{Name: "api", Created: []string{"HighLatency"}, Updated: []string{"Down"}, Deleted: []string{"Old"}},
+		{Name: "targets", Created: []string{"NoTargets"}},
+	}, results)
+}
+
+func TestPrometheusRuleFileToGrafana_Errors(t *testing.T) {
+	data, err := prometheusRuleData("up == 0", "prom-uid")
+	require.NoError(t, err)
+	existing := []*ngmodels.AlertRule{
+		{ID: 1, UID: "classic-uid", Title: "Classic", Condition: "C", Data: data, RuleGroup: "grafana", IntervalSeconds: 60},
+		{ID: 2, UID: "down-uid", Title: "Down", Condition: prometheusConditionRefID, Data: data, RuleGroup: "kept", IntervalSeconds: 60},
+	}
+	rule := func(title string) apimodels.ApiRuleNode {
+		return apimodels.ApiRuleNode{Alert: title, Expr: "up == 0"}
+	}
+
+	testCases := []struct {
+		desc   string
+		groups []apimodels.PrometheusRuleGroup
+		err    string
+	}{
+		{
+			desc: "no groups",
+			err:  "rule file has no groups",
+		},
+		{
+			desc:   "group without name",
+			groups: []apimodels.PrometheusRuleGroup{{Rules: []apimodels.ApiRuleNode{rule("A")}}},
+			err:    "rule group name is not valid",
+		},
+		{
+			desc: "duplicated group",
+			groups: []apimodels.PrometheusRuleGroup{
+				{Name: "api", Rules: []apimodels.ApiRuleNode{rule("A")}},
+				{Name: "api", Rules: []apimodels.ApiRuleNode{rule("B")}},
+			},
+			err: `rule group "api" is defined more than once`,
+		},
+		{
+			desc:   "title of a rule of another group",
+			groups: []apimodels.PrometheusRuleGroup{{Name: "api", Rules: []apimodels.ApiRuleNode{rule("Down")}}},
+			err:    `rule group "api": alerting rule "Down" already exists in rule group "kept"`,
+		},
+		{
+			desc:   "recording rule",
+			groups: []apimodels.PrometheusRuleGroup{{Name: "api", Rules: []apimodels.ApiRuleNode{{Record: "job:up:sum", Expr: "sum(up) by (job)"}}}},
+			err:    `rule group "api": recording rule "job:up:sum": recording rules are not supported`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, _, err := prometheusRuleFileToGrafana(apimodels.PrometheusRuleFile{Groups: tc.groups}, "prom-uid", existing)
+			require.EqualError(t, err, tc.err)
+		})
+	}
+
+	t.Run("group of Grafana managed rules", func(t *testing.T) {
+		file := apimodels.PrometheusRuleFile{Groups: []apimodels.PrometheusRuleGroup{{Name: "grafana", Rules: []apimodels.ApiRuleNode{rule("A")}}}}
+		_, _, err := prometheusRuleFileToGrafana(file, "prom-uid", existing)
+		require.ErrorIs(t, err, errNotPrometheusCompatible)
+	})
+}
@@ -0,0 +1,198 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/expr"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	prometheusQueryRefID     = "A"
+	prometheusConditionRefID = "B"
+	// prometheusQueryRange is the time range of the query of the rules converted from Prometheus alerting rules.
+	// Every series returned in the range is firing, so it is kept short for the alerts to resolve quickly.
+	prometheusQueryRange = time.Minute
+)
+
+// errNotPrometheusCompatible is returned for Grafana managed rules that can't be represented as Prometheus alerting rules.
+var errNotPrometheusCompatible = errors.New("rule cannot be represented as a Prometheus alerting rule")
+
+// prometheusRuleGroupToGrafana converts a group of Prometheus alerting rules to Grafana managed rules querying the datasource.
+// The rules keep the UID of the existing rules of the group with the same title, so they are updated instead of replaced.
+func prometheusRuleGroupToGrafana(group apimodels.PrometheusRuleGroup, datasourceUID string, existing []*ngmodels.AlertRule) (apimodels.PostableRuleGroupConfig, error) {
+	uids := make(map[string]string, len(existing))
+	for _, r := range existing {
+		uids[r.Title] = r.UID
+	}
+
+	result := apimodels.PostableRuleGroupConfig{
+		Name:     group.Name,
+		Interval: group.Interval,
+		Rules:    make([]apimodels.PostableExtendedRuleNode, 0, len(group.Rules)),
+	}
+	for _, rule := range group.Rules {
+		if rule.Record != "" {
+			return apimodels.PostableRuleGroupConfig{}, fmt.Errorf("recording rule %q: recording rules are not supported", rule.Record)
+		}
+		if rule.Alert == "" {
+			return apimodels.PostableRuleGroupConfig{}, errors.New("alerting rule name is empty")
+		}
+		if rule.Expr == "" {
+			return apimodels.PostableRuleGroupConfig{}, fmt.Errorf("alerting rule %q: expression is empty", rule.Alert)
+		}
+
+		data, err := prometheusRuleData(rule.Expr, datasourceUID)
+		if err != nil {
+			return apimodels.PostableRuleGroupConfig{}, fmt.Errorf("alerting rule %q: %w", rule.Alert, err)
+		}
+		result.Rules = append(result.Rules, apimodels.PostableExtendedRuleNode{
+			ApiRuleNode: &apimodels.ApiRuleNode{
+				For:         rule.For,
+				Labels:      rule.Labels,
+				Annotations: rule.Annotations,
+			},
+			GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+				Title:     rule.Alert,
+				Condition: prometheusConditionRefID,
+				Data:      data,
+				UID:       uids[rule.Alert],
+				// Like in Prometheus, a query without results doesn't fire.
+				NoDataState:  apimodels.OK,
+				ExecErrState: apimodels.AlertingErrState,
+			},
+		})
+	}
+	return result, nil
+}
+
+// prometheusRuleData returns the queries of a rule firing for every series returned by the PromQL expression:
+// the expression is queried from the datasource and the points of each series are counted.
+func prometheusRuleData(promQL, datasourceUID string) ([]ngmodels.AlertQuery, error) {
+	query, err := json.Marshal(map[string]interface{}{
+		"refId": prometheusQueryRefID,
+		"expr":  promQL,
+	})
+	if err != nil {
+		return nil, err
+	}
+	condition, err := json.Marshal(map[string]interface{}{
+		"refId":      prometheusConditionRefID,
+		"type":       "reduce",
+		"reducer":    "count",
+		"expression": prometheusQueryRefID,
+		"datasource": expr.DatasourceName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return []ngmodels.AlertQuery{
+		{
+			RefID:             prometheusQueryRefID,
+			RelativeTimeRange: ngmodels.RelativeTimeRange{From: ngmodels.Duration(prometheusQueryRange)},
+			DatasourceUID:     datasourceUID,
+			Model:             query,
+		},
+		{
+			RefID:         prometheusConditionRefID,
+			DatasourceUID: expr.DatasourceUID,
+			Model:         condition,
+		},
+	}, nil
+}
+
+// grafanaRuleToPrometheus converts a Grafana managed rule created from a Prometheus alerting rule back to it.
+// It returns errNotPrometheusCompatible for the rules that don't have the queries of prometheusRuleData.
+func grafanaRuleToPrometheus(r *ngmodels.AlertRule) (apimodels.ApiRuleNode, error) {
+	promQL, ok := prometheusRuleExpr(r)
+	if !ok {
+		return apimodels.ApiRuleNode{}, fmt.Errorf("%w: %q queries Grafana expressions or datasources", errNotPrometheusCompatible, r.Title)
+	}
+	return apimodels.ApiRuleNode{
+		Alert:       r.Title,
+		Expr:        promQL,
+		For:         model.Duration(r.For),
+		Labels:      r.Labels,
+		Annotations: r.Annotations,
+	}, nil
+}
+
+func prometheusRuleExpr(r *ngmodels.AlertRule) (string, bool) {
+	if r.Condition != prometheusConditionRefID || len(r.Data) != 2 {
+		return "", false
+	}
+
+	var promQL string
+	var hasCondition bool
+	for _, q := range r.Data {
+		m := map[string]interface{}{}
+		if err := json.Unmarshal(q.Model, &m); err != nil {
+			return "", false
+		}
+		switch q.RefID {
+		case prometheusQueryRefID:
+			if q.DatasourceUID == expr.DatasourceUID {
+				return "", false
+			}
+			promQL, _ = m["expr"].(string)
+		case prometheusConditionRefID:
+			hasCondition = q.DatasourceUID == expr.DatasourceUID &&
+				m["type"] == "reduce" && m["reducer"] == "count" && m["expression"] == prometheusQueryRefID
+		}
+	}
+	return promQL, promQL != "" && hasCondition
+}
+
+// grafanaRulesToPrometheusGroups groups the rules of a namespace into Prometheus rule groups, ordered by name.
+// The rules of a group are in the order they were created. The groups with rules that can't be represented as
+// Prometheus alerting rules are returned separately.
+func grafanaRulesToPrometheusGroups(rules []*ngmodels.AlertRule) ([]apimodels.PrometheusRuleGroup, map[string]error) {
+	rules = append([]*ngmodels.AlertRule(nil), rules...)
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].ID < rules[j].ID
+	})
+
+	groups := make(map[string]*apimodels.PrometheusRuleGroup)
+	names := make([]string, 0)
+	incompatible := make(map[string]error)
+	for _, r := range rules {
+		if _, ok := incompatible[r.RuleGroup]; ok {
+			continue
+		}
+		rule, err := grafanaRuleToPrometheus(r)
+		if err != nil {
+			incompatible[r.RuleGroup] = err
+			continue
+		}
+		g, ok := groups[r.RuleGroup]
+		if !ok {
+			g = &apimodels.PrometheusRuleGroup{
+				Name:     r.RuleGroup,
+				Interval: model.Duration(time.Duration(r.IntervalSeconds) * time.Second),
+			}
+			groups[r.RuleGroup] = g
+			names = append(names, r.RuleGroup)
+		}
+		g.Rules = append(g.Rules, rule)
+	}
+
+	result := make([]apimodels.PrometheusRuleGroup, 0, len(names))
+	for _, name := range names {
+		if _, ok := incompatible[name]; ok {
+			continue
+		}
+		result = append(result, *groups[name])
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result, incompatible
+}
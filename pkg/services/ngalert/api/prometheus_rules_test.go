@@ -0,0 +1,126 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/expr"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const prometheusRuleGroupYAML = `
+name: api
+interval: 1m
+rules:
+  - alert: HighErrorRate
+    expr: sum(rate(http_requests_total{code=~"5.."}[5m])) > 1
+    for: 5m
+    labels:
+      severity: critical
+    annotations:
+      summary: "{{ $labels.job }} has a high error rate"
+  - alert: Down
+    expr: up == 0
+`
+
+func TestPrometheusRuleGroupConversion(t *testing.T) {
+	var group apimodels.PrometheusRuleGroup
+	require.NoError(t, yaml.Unmarshal([]byte(prometheusRuleGroupYAML), &group))
+
+	existing := []*ngmodels.AlertRule{{UID: "existing-uid", Title: "Down"}}
+	config, err := prometheusRuleGroupToGrafana(group, "prom-uid", existing)
+	require.NoError(t, err)
+	require.Equal(t, "api", config.Name)
+	require.Equal(t, model.Duration(time.Minute), config.Interval)
+	require.Len(t, config.Rules, 2)
+
+	highErrorRate := config.Rules[0]
+	require.Equal(t, "HighErrorRate", highErrorRate.GrafanaManagedAlert.Title)
+	require.Empty(t, highErrorRate.GrafanaManagedAlert.UID)
+	require.Equal(t, model.Duration(5*time.Minute), highErrorRate.ApiRuleNode.For)
+	require.Equal(t, map[string]string{"severity": "critical"}, highErrorRate.ApiRuleNode.Labels)
+	require.Equal(t, prometheusConditionRefID, highErrorRate.GrafanaManagedAlert.Condition)
+	require.Equal(t, apimodels.OK, highErrorRate.GrafanaManagedAlert.NoDataState)
+
+	data := highErrorRate.GrafanaManagedAlert.Data
+	require.Len(t, data, 2)
+	require.Equal(t, "prom-uid", data[0].DatasourceUID)
+	require.Equal(t, expr.DatasourceUID, data[1].DatasourceUID)
+	m := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(data[0].Model, &m))
+	require.Equal(t, `sum(rate(http_requests_total{code=~"5.."}[5m])) > 1`, m["expr"])
+
+	// The UID of the rule with the same title is kept to update it.
+	require.Equal(t, "existing-uid", config.Rules[1].GrafanaManagedAlert.UID)
+
+	// Convert the rules back as they are stored.
+	rules := make([]*ngmodels.AlertRule, 0, len(config.Rules))
+	for i, r := range config.Rules {
+		rules = append(rules, &ngmodels.AlertRule{
+			ID:              int64(i + 1),
+			Title:           r.GrafanaManagedAlert.Title,
+			Condition:       r.GrafanaManagedAlert.Condition,
+			Data:            r.GrafanaManagedAlert.Data,
+			IntervalSeconds: 60,
+			RuleGroup:       config.Name,
+			For:             time.Duration(r.ApiRuleNode.For),
+			Labels:          r.ApiRuleNode.Labels,
+			Annotations:     r.ApiRuleNode.Annotations,
+		})
+	}
+	groups, incompatible := grafanaRulesToPrometheusGroups(rules)
+	require.Empty(t, incompatible)
+	require.Equal(t, []apimodels.PrometheusRuleGroup{group}, groups)
+}
+
+func TestPrometheusRuleGroupConversion_Errors(t *testing.T) {
+	testCases := []struct {
+		desc string
+		rule apimodels.ApiRuleNode
+		err  string
+	}{
+		{
+			desc: "recording rule",
+			rule: apimodels.ApiRuleNode{Record: "job:up:sum", Expr: "sum(up) by (job)"},
+			err:  `recording rule "job:up:sum": recording rules are not supported`,
+		},
+		{
+			desc: "missing name",
+			rule: apimodels.ApiRuleNode{Expr: "up == 0"},
+			err:  "alerting rule name is empty",
+		},
+		{
+			desc: "missing expression",
+			rule: apimodels.ApiRuleNode{Alert: "Down"},
+			err:  `alerting rule "Down": expression is empty`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			group := apimodels.PrometheusRuleGroup{Name: "group", Rules: []apimodels.ApiRuleNode{tc.rule}}
+			_, err := prometheusRuleGroupToGrafana(group, "prom-uid", nil)
+			require.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestGrafanaRulesToPrometheusGroups_Incompatible(t *testing.T) {
+	data, err := prometheusRuleData("up == 0", "prom-uid")
+	require.NoError(t, err)
+
+	rules := []*ngmodels.AlertRule{
+		{ID: 1, Title: "Down", Condition: prometheusConditionRefID, Data: data, RuleGroup: "prometheus", IntervalSeconds: 60},
+		{ID: 2, Title: "Classic", Condition: "C", Data: data, RuleGroup: "grafana", IntervalSeconds: 60},
+	}
+	groups, incompatible := grafanaRulesToPrometheusGroups(rules)
+	require.Len(t, groups, 1)
+	require.Equal(t, "prometheus", groups[0].Name)
+	require.Len(t, incompatible, 1)
+	require.ErrorIs(t, incompatible["grafana"], errNotPrometheusCompatible)
+}
@@ -0,0 +1,213 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+const (
+	// ProvenanceHeader sets the provenance of the resources changed with the provisioning API, "api" by default.
+	ProvenanceHeader = "X-Grafana-Provenance"
+	// OverrideProvenanceHeader lets organization admins change provisioned resources with the other APIs when it
+	// is "true". The resources are taken over in the UI.
+	OverrideProvenanceHeader = "X-Grafana-Override-Provenance"
+)
+
+// provisionedErrResp returns the response to a change of provisioned resources, or nil if the change is allowed.
+func provisionedErrResp(err error) response.Response {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, ngmodels.ErrProvisionedResource) {
+		return ErrResp(http.StatusConflict, err, "")
+	}
+	return ErrResp(http.StatusInternalServerError, err, "")
+}
+
+// checkRulesNotProvisioned returns an error wrapping ngmodels.ErrProvisionedResource if one of the rules is provisioned.
+func (srv RulerSrv) checkRulesNotProvisioned(c *models.ReqContext, uids []string) error {
+	provenances, err := srv.provenanceStore.GetProvenances(c.OrgId, ngmodels.ResourceTypeAlertRule)
+	if err != nil {
+		return fmt.Errorf("failed to get the provenance of the rules: %w", err)
+	}
+	guard := newProvenanceGuard(c, srv.provenanceStore, srv.log)
+	for _, uid := range uids {
+		if err := guard.check(ngmodels.ResourceTypeAlertRule, uid, provenances[uid], "alert rule %q", uid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkProvisionedByAPI returns an error wrapping ngmodels.ErrProvisionedResource if the resource is provisioned by
+// other means than the provisioning API, unless an admin overrides its provenance.
+func checkProvisionedByAPI(c *models.ReqContext, provenance ngmodels.Provenance, resource, key string) error {
+	if provenance.IsProvisioned() && !provenance.IsAPI() && !provenanceOverride(c) {
+		return fmt.Errorf("%w: %s %q is provisioned, its provenance is %q", ngmodels.ErrProvisionedResource, resource, key, provenance)
+	}
+	return nil
+}
+
+// provenanceFromRequest returns the provenance of the resources changed with the provisioning API, set with the
+// provenance header.
+func provenanceFromRequest(c *models.ReqContext) (ngmodels.Provenance, error) {
+	switch p := ngmodels.Provenance(c.Req.Header.Get(ProvenanceHeader)); p {
+	case ngmodels.ProvenanceNone:
+		return ngmodels.ProvenanceAPI, nil
+	case ngmodels.ProvenanceAPI, ngmodels.ProvenanceTerraform:
+		return p, nil
+	default:
+		return ngmodels.ProvenanceNone, fmt.Errorf("invalid provenance %q, it must be %q or %q", p, ngmodels.ProvenanceAPI, ngmodels.ProvenanceTerraform)
+	}
+}
+
+// provenanceOverride returns true if an organization admin overrides the provenance of the provisioned resources
+// changed by the request.
+func provenanceOverride(c *models.ReqContext) bool {
+	return c.Req.Header.Get(OverrideProvenanceHeader) == "true" && c.HasUserRole(models.ROLE_ADMIN)
+}
+
+// provenanceGuard prevents the changes of the provisioned resources. If an admin overrides their provenance, the
+// resources are released instead: they are taken over in the UI and get the ngmodels.ProvenanceUI provenance.
+type provenanceGuard struct {
+	orgID    int64
+	override bool
+	store    store.ProvisioningStore
+	log      log.Logger
+}
+
+func newProvenanceGuard(c *models.ReqContext, store store.ProvisioningStore, log log.Logger) provenanceGuard {
+	return provenanceGuard{orgID: c.OrgId, override: provenanceOverride(c), store: store, log: log}
+}
+
+// check returns an error wrapping ngmodels.ErrProvisionedResource if the resource is provisioned, or releases it
+// if its provenance is overridden. The resource is described by format and args in the error.
+func (g provenanceGuard) check(recordType, key string, provenance ngmodels.Provenance, format string, args ...interface{}) error {
+	if !provenance.IsProvisioned() {
+		return nil
+	}
+	if !g.override {
+		return fmt.Errorf("%w: %s is provisioned, its provenance is %q", ngmodels.ErrProvisionedResource, fmt.Sprintf(format, args...), provenance)
+	}
+	g.log.Warn("overriding the provenance of a provisioned resource", "org", g.orgID, "type", recordType, "key", key, "provenance", provenance)
+	if err := g.store.SetProvenance(g.orgID, recordType, key, ngmodels.ProvenanceUI); err != nil {
+		return fmt.Errorf("failed to override the provenance of %s: %w", fmt.Sprintf(format, args...), err)
+	}
+	return nil
+}
+
+func ruleUIDs(rules []*ngmodels.AlertRule) []string {
+	uids := make([]string, 0, len(rules))
+	for _, r := range rules {
+		uids = append(uids, r.UID)
+	}
+	return uids
+}
+
+// checkProvisionedConfig returns an error wrapping ngmodels.ErrProvisionedResource if the new Alertmanager
+// configuration changes the provisioned contact points, templates or notification policies of the current one.
+// The secure settings of both configurations must be decrypted.
+func (srv AlertmanagerSrv) checkProvisionedConfig(c *models.ReqContext, current, new *apimodels.PostableUserConfig) error {
+	guard := newProvenanceGuard(c, srv.provenanceStore, srv.log)
+
+	contactPoints, err := srv.provenanceStore.GetProvenances(c.OrgId, ngmodels.ResourceTypeContactPoint)
+	if err != nil {
+		return fmt.Errorf("failed to get the provenance of the contact points: %w", err)
+	}
+	for name, p := range contactPoints {
+		if sameJSON(findReceiver(current, name), findReceiver(new, name)) {
+			continue
+		}
+		if err := guard.check(ngmodels.ResourceTypeContactPoint, name, p, "contact point %q", name); err != nil {
+			return err
+		}
+	}
+
+	templates, err := srv.provenanceStore.GetProvenances(c.OrgId, ngmodels.ResourceTypeTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to get the provenance of the templates: %w", err)
+	}
+	for name, p := range templates {
+		cur, curOK := current.TemplateFiles[name]
+		nw, nwOK := new.TemplateFiles[name]
+		if cur == nw && curOK == nwOK {
+			continue
+		}
+		if err := guard.check(ngmodels.ResourceTypeTemplate, name, p, "template %q", name); err != nil {
+			return err
+		}
+	}
+
+	p, err := srv.provenanceStore.GetProvenance(c.OrgId, ngmodels.ResourceTypeNotificationPolicy, ngmodels.NotificationPolicyResourceKey)
+	if err != nil {
+		return fmt.Errorf("failed to get the provenance of the notification policies: %w", err)
+	}
+	if sameJSON(current.AlertmanagerConfig.Route, new.AlertmanagerConfig.Route) {
+		return nil
+	}
+	return guard.check(ngmodels.ResourceTypeNotificationPolicy, ngmodels.NotificationPolicyResourceKey, p, "the notification policy tree")
+}
+
+// checkNoProvisionedConfig returns an error wrapping ngmodels.ErrProvisionedResource if a part of the
+// Alertmanager configuration is provisioned.
+func (srv AlertmanagerSrv) checkNoProvisionedConfig(c *models.ReqContext) error {
+	guard := newProvenanceGuard(c, srv.provenanceStore, srv.log)
+	for _, recordType := range []string{ngmodels.ResourceTypeContactPoint, ngmodels.ResourceTypeTemplate, ngmodels.ResourceTypeNotificationPolicy} {
+		provenances, err := srv.provenanceStore.GetProvenances(c.OrgId, recordType)
+		if err != nil {
+			return fmt.Errorf("failed to get the provenance of the configuration: %w", err)
+		}
+		for key, p := range provenances {
+			if err := guard.check(recordType, key, p, "%s %q", recordType, key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkMuteTimingNotProvisioned returns an error wrapping ngmodels.ErrProvisionedResource if the recurring
+// silence is a provisioned mute timing.
+func (srv AlertmanagerSrv) checkMuteTimingNotProvisioned(c *models.ReqContext, uid string) error {
+	p, err := srv.provenanceStore.GetProvenance(c.OrgId, ngmodels.ResourceTypeMuteTiming, uid)
+	if err != nil {
+		return fmt.Errorf("failed to get the provenance of the recurring silence: %w", err)
+	}
+	return newProvenanceGuard(c, srv.provenanceStore, srv.log).check(ngmodels.ResourceTypeMuteTiming, uid, p, "recurring silence %q", uid)
+}
+
+func findReceiver(cfg *apimodels.PostableUserConfig, name string) *apimodels.PostableApiReceiver {
+	for _, r := range cfg.AlertmanagerConfig.Receivers {
+		if r.Name == name {
+			return r
+		}
+	}
+	return nil
+}
+
+// sameJSON returns true if the JSON representations of a and b are equal, regardless of the order of the keys.
+func sameJSON(a, b interface{}) bool {
+	ab, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bb, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	var av, bv interface{}
+	if json.Unmarshal(ab, &av) != nil || json.Unmarshal(bb, &bv) != nil {
+		return false
+	}
+	return reflect.DeepEqual(av, bv)
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// The quota targets of alerting.
+const (
+	quotaTargetAlertRule      = "alert_rule"
+	quotaTargetAlertRuleGroup = "alert_rule_group"
+	quotaTargetSilence        = "silence"
+	quotaTargetMuteTiming     = "mute_timing"
+	quotaTargetContactPoint   = "contact_point"
+)
+
+// quotaErrResp returns the response to a change reaching a quota, with the target, the limit and the usage of the
+// quota, or nil if the quota allows the change.
+func quotaErrResp(err error) response.Response {
+	if err == nil {
+		return nil
+	}
+	var reached *quota.QuotaReachedError
+	if errors.As(err, &reached) {
+		return response.JSON(http.StatusForbidden, util.DynMap{"message": reached.Error(), "quota": reached})
+	}
+	return ErrResp(http.StatusInternalServerError, err, "failed to get quota")
+}
+
+// countActiveSilences returns the number of the silences of the Alertmanager that are not expired, the usage of the
+// quota of silences.
+func countActiveSilences(am Alertmanager) (int64, error) {
+	silences, err := am.ListSilences(nil)
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	for _, s := range silences {
+		if s.Status == nil || s.Status.State == nil || *s.Status.State != string(types.SilenceStateExpired) {
+			count++
+		}
+	}
+	return count, nil
+}
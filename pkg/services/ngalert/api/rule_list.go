@@ -0,0 +1,90 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/models"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ruleGroupsTotalHeader is the header of the number of rule groups on all the pages.
+const ruleGroupsTotalHeader = "X-Grafana-Rule-Groups-Total"
+
+// ruleListOptions are the pagination, the filters and the fields of the endpoints that list the rule groups.
+// Without parameters the endpoints list all the rule groups, as before they were paginated.
+type ruleListOptions struct {
+	// limit is the number of rule groups of the page, 0 for all of them.
+	limit         int
+	offset        int
+	folderUIDs    []string
+	datasourceUID string
+	states        map[string]struct{}
+	// excludeQueries leaves out the queries of the rules, which are most of the size of the response.
+	excludeQueries bool
+}
+
+func parseRuleListOptions(c *models.ReqContext) (ruleListOptions, error) {
+	opts := ruleListOptions{
+		folderUIDs:    c.QueryStrings("folder_uid"),
+		datasourceUID: c.Query("datasource_uid"),
+	}
+	var err error
+	if c.Query("limit") != "" {
+		if opts.limit, err = strconv.Atoi(c.Query("limit")); err != nil || opts.limit < 1 {
+			return opts, fmt.Errorf("limit must be a positive number")
+		}
+	}
+	if c.Query("offset") != "" {
+		if opts.offset, err = strconv.Atoi(c.Query("offset")); err != nil || opts.offset < 0 {
+			return opts, fmt.Errorf("offset must not be negative")
+		}
+	}
+	for _, s := range c.QueryStrings("state") {
+		if _, ok := ruleSearchStates[s]; !ok {
+			return opts, fmt.Errorf("unknown state %q", s)
+		}
+		if opts.states == nil {
+			opts.states = map[string]struct{}{}
+		}
+		opts.states[s] = struct{}{}
+	}
+	if c.Query("exclude_queries") != "" {
+		if opts.excludeQueries, err = strconv.ParseBool(c.Query("exclude_queries")); err != nil {
+			return opts, fmt.Errorf("exclude_queries must be true or false")
+		}
+	}
+	return opts, nil
+}
+
+// namespaceUIDs returns the UIDs of the folders to list, among the folders visible to the user.
+func (o ruleListOptions) namespaceUIDs(namespaces map[string]*models.Folder) []string {
+	uids := make([]string, 0, len(namespaces))
+	if len(o.folderUIDs) > 0 {
+		for _, uid := range o.folderUIDs {
+			if _, ok := namespaces[uid]; ok {
+				uids = append(uids, uid)
+			}
+		}
+		return uids
+	}
+	for uid := range namespaces {
+		uids = append(uids, uid)
+	}
+	return uids
+}
+
+// filtersRules returns true if the rules of the groups are filtered, so a group is listed only if some of its
+// rules match.
+func (o ruleListOptions) filtersRules() bool {
+	return o.datasourceUID != "" || o.states != nil
+}
+
+func (o ruleListOptions) matches(r *ngmodels.AlertRule, ruleState, health string) bool {
+	return ruleSearchFilter{datasourceUID: o.datasourceUID, states: o.states}.matches(r, ruleState, health)
+}
+
+// inPage returns true if the rule group at the index is in the requested page.
+func (o ruleListOptions) inPage(index int) bool {
+	return index >= o.offset && (o.limit == 0 || index < o.offset+o.limit)
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/macaron.v1"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func TestParseRuleListOptions(t *testing.T) {
+	parse := func(t *testing.T, query string) (ruleListOptions, error) {
+		req, err := http.NewRequest(http.MethodGet, "/api/ruler/grafana/api/v1/rules?"+query, nil)
+		require.NoError(t, err)
+		return parseRuleListOptions(&models.ReqContext{Context: &macaron.Context{Req: req}})
+	}
+
+	t.Run("lists all the rule groups without parameters", func(t *testing.T) {
+		opts, err := parse(t, "")
+		require.NoError(t, err)
+		require.False(t, opts.filtersRules())
+		require.True(t, opts.inPage(0))
+		require.True(t, opts.inPage(10000))
+	})
+
+	t.Run("paginates the rule groups", func(t *testing.T) {
+		opts, err := parse(t, "limit=10&offset=20")
+		require.NoError(t, err)
+		require.False(t, opts.inPage(19))
+		require.True(t, opts.inPage(20))
+		require.True(t, opts.inPage(29))
+		require.False(t, opts.inPage(30))
+	})
+
+	t.Run("parses the filters", func(t *testing.T) {
+		opts, err := parse(t, "folder_uid=a&folder_uid=b&datasource_uid=prometheus&state=firing&exclude_queries=true")
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "b"}, opts.folderUIDs)
+		require.True(t, opts.filtersRules())
+		require.True(t, opts.excludeQueries)
+		require.Equal(t, map[string]struct{}{"firing": {}}, opts.states)
+	})
+
+	t.Run("rejects invalid parameters", func(t *testing.T) {
+		for _, query := range []string{"limit=0", "limit=ten", "offset=-1", "state=broken", "exclude_queries=maybe"} {
+			_, err := parse(t, query)
+			require.Error(t, err, query)
+		}
+	})
+}
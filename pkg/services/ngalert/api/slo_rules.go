@@ -0,0 +1,118 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/promql/parser"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+const (
+	// sloWindowReference is replaced with the windows of the rules in the error ratio query of an SLO.
+	sloWindowReference = "${window}"
+	sloDefaultPeriod   = 30 * 24 * time.Hour
+	sloDefaultInterval = time.Minute
+)
+
+// sloDefaultWindows are the windows recommended by the Site Reliability Workbook: a page for a fast burn of the
+// error budget and a ticket for a slow burn.
+var sloDefaultWindows = []apimodels.SLOBurnRateWindow{
+	{Severity: "page", LongWindow: model.Duration(time.Hour), ShortWindow: model.Duration(5 * time.Minute), BudgetConsumed: 0.02},
+	{Severity: "page", LongWindow: model.Duration(6 * time.Hour), ShortWindow: model.Duration(30 * time.Minute), BudgetConsumed: 0.05},
+	{Severity: "ticket", LongWindow: model.Duration(24 * time.Hour), ShortWindow: model.Duration(2 * time.Hour), BudgetConsumed: 0.1},
+	{Severity: "ticket", LongWindow: model.Duration(72 * time.Hour), ShortWindow: model.Duration(6 * time.Hour), BudgetConsumed: 0.1},
+}
+
+// sloRuleGroup generates the Prometheus alerting rules of the burn rates of an SLO. The rules of each window fire
+// when the error ratio over both the long and the short window exceeds the burn rate times the error budget.
+func sloRuleGroup(slo apimodels.PostableSLORules) (apimodels.PrometheusRuleGroup, error) {
+	if slo.Name == "" {
+		return apimodels.PrometheusRuleGroup{}, errors.New("SLO name is empty")
+	}
+	if slo.Objective <= 0 || slo.Objective >= 1 {
+		return apimodels.PrometheusRuleGroup{}, fmt.Errorf("objective %v is not between 0 and 1", slo.Objective)
+	}
+	if !strings.Contains(slo.ErrorRatioQuery, sloWindowReference) {
+		return apimodels.PrometheusRuleGroup{}, fmt.Errorf("error ratio query doesn't reference the window as %s", sloWindowReference)
+	}
+
+	period := time.Duration(slo.Period)
+	if period == 0 {
+		period = sloDefaultPeriod
+	}
+	interval := slo.Interval
+	if interval == 0 {
+		interval = model.Duration(sloDefaultInterval)
+	}
+	windows := slo.Windows
+	if len(windows) == 0 {
+		windows = sloDefaultWindows
+	}
+	group := apimodels.PrometheusRuleGroup{
+		Name:     slo.RuleGroup,
+		Interval: interval,
+		Rules:    make([]apimodels.ApiRuleNode, 0, len(windows)),
+	}
+	if group.Name == "" {
+		group.Name = slo.Name
+	}
+
+	budget := 1 - slo.Objective
+	for _, w := range windows {
+		if w.ShortWindow <= 0 || w.LongWindow <= w.ShortWindow {
+			return apimodels.PrometheusRuleGroup{}, fmt.Errorf("window %s/%s: the short window must be shorter than the long window", w.LongWindow, w.ShortWindow)
+		}
+		if time.Duration(w.LongWindow) > period {
+			return apimodels.PrometheusRuleGroup{}, fmt.Errorf("window %s is longer than the period %s", w.LongWindow, model.Duration(period))
+		}
+		if w.BudgetConsumed <= 0 || w.BudgetConsumed > 1 {
+			return apimodels.PrometheusRuleGroup{}, fmt.Errorf("window %s: budget consumed %v is not between 0 and 1", w.LongWindow, w.BudgetConsumed)
+		}
+
+		burnRate := w.BudgetConsumed * float64(period) / float64(w.LongWindow)
+		threshold := formatSLOFloat(burnRate * budget)
+		expr := fmt.Sprintf("(%s) > %s and (%s) > %s",
+			strings.ReplaceAll(slo.ErrorRatioQuery, sloWindowReference, w.LongWindow.String()), threshold,
+			strings.ReplaceAll(slo.ErrorRatioQuery, sloWindowReference, w.ShortWindow.String()), threshold)
+		if _, err := parser.ParseExpr(expr); err != nil {
+			return apimodels.PrometheusRuleGroup{}, fmt.Errorf("error ratio query is not valid PromQL: %w", err)
+		}
+
+		labels := make(map[string]string, len(slo.Labels)+3)
+		for k, v := range slo.Labels {
+			labels[k] = v
+		}
+		labels["slo"] = slo.Name
+		labels["long_window"] = w.LongWindow.String()
+		if w.Severity != "" {
+			labels["severity"] = w.Severity
+		}
+		annotations := make(map[string]string, len(slo.Annotations)+1)
+		for k, v := range slo.Annotations {
+			annotations[k] = v
+		}
+		if _, ok := annotations["summary"]; !ok {
+			annotations["summary"] = fmt.Sprintf("The error budget of the SLO %s is burning %s times too fast over %s.", slo.Name, formatSLOFloat(burnRate), w.LongWindow)
+		}
+
+		group.Rules = append(group.Rules, apimodels.ApiRuleNode{
+			Alert:       fmt.Sprintf("%s error budget burn %s/%s", slo.Name, w.LongWindow, w.ShortWindow),
+			Expr:        expr,
+			For:         w.For,
+			Labels:      labels,
+			Annotations: annotations,
+		})
+	}
+	return group, nil
+}
+
+func formatSLOFloat(f float64) string {
+	// Rounded to avoid the floating point noise of the products, such as 0.014400000000000001.
+	return strconv.FormatFloat(f, 'g', 10, 64)
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+const sloErrorRatioQuery = `sum(rate(http_requests_total{code=~"5.."}[${window}])) / sum(rate(http_requests_total[${window}]))`
+
+func TestSLORuleGroup(t *testing.T) {
+	group, err := sloRuleGroup(apimodels.PostableSLORules{
+		Name:            "checkout",
+		ErrorRatioQuery: sloErrorRatioQuery,
+		Objective:       0.999,
+		Labels:          map[string]string{"team": "payments"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "checkout", group.Name)
+	require.Equal(t, model.Duration(time.Minute), group.Interval)
+	require.Len(t, group.Rules, 4)
+
+	fast := group.Rules[0]
+	require.Equal(t, "checkout error budget burn 1h/5m", fast.Alert)
+	require.Equal(t, `(sum(rate(http_requests_total{code=~"5.."}[1h])) / sum(rate(http_requests_total[1h]))) > 0.0144 and `+
+		`(sum(rate(http_requests_total{code=~"5.."}[5m])) / sum(rate(http_requests_total[5m]))) > 0.0144`, fast.Expr)
+	require.Equal(t, map[string]string{"team": "payments", "slo": "checkout", "severity": "page", "long_window": "1h"}, fast.Labels)
+	require.Contains(t, fast.Annotations["summary"], "14.4 times")
+
+	slow := group.Rules[3]
+	require.Equal(t, "checkout error budget burn 3d/6h", slow.Alert)
+	require.Contains(t, slow.Expr, "[3d]")
+	require.Contains(t, slow.Expr, "> 0.001 and")
+	require.Equal(t, "ticket", slow.Labels["severity"])
+}
+
+func TestSLORuleGroup_CustomWindows(t *testing.T) {
+	group, err := sloRuleGroup(apimodels.PostableSLORules{
+		Name:            "checkout",
+		RuleGroup:       "slo",
+		ErrorRatioQuery: sloErrorRatioQuery,
+		Objective:       0.99,
+		Period:          model.Duration(7 * 24 * time.Hour),
+		Windows: []apimodels.SLOBurnRateWindow{
+			{LongWindow: model.Duration(2 * time.Hour), ShortWindow: model.Duration(10 * time.Minute), BudgetConsumed: 0.1, For: model.Duration(2 * time.Minute)},
+		},
+		Annotations: map[string]string{"summary": "checkout is failing"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "slo", group.Name)
+	require.Len(t, group.Rules, 1)
+	// 10% of a week in 2 hours is a burn rate of 8.4, times the error budget of 1%.
+	require.Contains(t, group.Rules[0].Expr, "> 0.084 and")
+	require.Equal(t, model.Duration(2*time.Minute), group.Rules[0].For)
+	require.NotContains(t, group.Rules[0].Labels, "severity")
+	require.Equal(t, map[string]string{"summary": "checkout is failing"}, group.Rules[0].Annotations)
+}
+
+func TestSLORuleGroup_Errors(t *testing.T) {
+	valid := func() apimodels.PostableSLORules {
+		return apimodels.PostableSLORules{Name: "checkout", ErrorRatioQuery: sloErrorRatioQuery, Objective: 0.999}
+	}
+	testCases := []struct {
+		desc   string
+		modify func(*apimodels.PostableSLORules)
+	}{
+		{desc: "no name", modify: func(s *apimodels.PostableSLORules) { s.Name = "" }},
+		{desc: "objective of 1", modify: func(s *apimodels.PostableSLORules) { s.Objective = 1 }},
+		{desc: "query without the window", modify: func(s *apimodels.PostableSLORules) { s.ErrorRatioQuery = "up" }},
+		{desc: "query that is not PromQL", modify: func(s *apimodels.PostableSLORules) { s.ErrorRatioQuery = "rate(errors[${window}]" }},
+		{desc: "short window longer than the long window", modify: func(s *apimodels.PostableSLORules) {
+			s.Windows = []apimodels.SLOBurnRateWindow{{LongWindow: model.Duration(time.Hour), ShortWindow: model.Duration(2 * time.Hour), BudgetConsumed: 0.1}}
+		}},
+		{desc: "long window longer than the period", modify: func(s *apimodels.PostableSLORules) {
+			s.Period = model.Duration(24 * time.Hour)
+		}},
+		{desc: "no budget consumed", modify: func(s *apimodels.PostableSLORules) {
+			s.Windows = []apimodels.SLOBurnRateWindow{{LongWindow: model.Duration(time.Hour), ShortWindow: model.Duration(5 * time.Minute)}}
+		}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			slo := valid()
+			tc.modify(&slo)
+			_, err := sloRuleGroup(slo)
+			require.Error(t, err)
+		})
+	}
+}
@@ -63,6 +63,8 @@ func main() {
 		}
 	}
 
+	markUnauthenticatedTags(data)
+
 	out, err := json.MarshalIndent(data, "", " ")
 	if err != nil {
 		log.Fatal(err)
@@ -73,3 +75,37 @@ func main() {
 		log.Fatal(err)
 	}
 }
+
+// markUnauthenticatedTags sets the x-all-unauthenticated extension on the operations of the tags whose operations
+// all have the x-unauthenticated extension. The operations of a tag are generated in the same file, which only
+// registers the routes behind the sign in if one of them needs it.
+func markUnauthenticatedTags(data map[string]interface{}) {
+	paths, _ := data["paths"].(map[string]interface{})
+	unauthenticated := make(map[string]bool)
+	opsByTag := make(map[string][]map[string]interface{})
+	for _, p := range paths {
+		methods, _ := p.(map[string]interface{})
+		for _, o := range methods {
+			op, ok := o.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			tags, _ := op["tags"].([]interface{})
+			if len(tags) == 0 {
+				continue
+			}
+			tag, _ := tags[0].(string)
+			all, seen := unauthenticated[tag]
+			unauthenticated[tag] = (all || !seen) && op["x-unauthenticated"] == true
+			opsByTag[tag] = append(opsByTag[tag], op)
+		}
+	}
+	for tag, all := range unauthenticated {
+		if !all {
+			continue
+		}
+		for _, op := range opsByTag[tag] {
+			op["x-all-unauthenticated"] = true
+		}
+	}
+}
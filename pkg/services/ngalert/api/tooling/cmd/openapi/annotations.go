@@ -0,0 +1,169 @@
+package main
+
+import (
+	"go/ast"
+	"strings"
+)
+
+// The go-swagger annotations of the definitions package read by the generator.
+
+// route is a swagger:route annotation.
+type route struct {
+	method      string
+	path        string
+	tag         string
+	operationID string
+	summary     string
+	description string
+	consumes    []string
+	produces    []string
+	// responses are the names of the models of the responses by status code.
+	responses map[string]string
+	// unauthenticated is set by the x-unauthenticated extension for the routes that are not behind the sign in,
+	// such as the routes authenticated by a token of their path.
+	unauthenticated bool
+}
+
+// fieldKeys are the keys of the annotations of the fields and the types.
+var fieldKeys = map[string]bool{
+	"in":                true,
+	"required":          true,
+	"default":           true,
+	"enum":              true,
+	"minimum":           true,
+	"maximum":           true,
+	"example":           true,
+	"format":            true,
+	"pattern":           true,
+	"name":              true,
+	"read only":         true,
+	"unique":            true,
+	"min items":         true,
+	"max items":         true,
+	"collection format": true,
+}
+
+// commentLines returns the lines of the comment without their indentation.
+func commentLines(cg *ast.CommentGroup) []string {
+	if cg == nil {
+		return nil
+	}
+	lines := strings.Split(cg.Text(), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimSpace(l)
+	}
+	return lines
+}
+
+// swaggerDirective returns the arguments of the swagger:<name> directive of the comment.
+func swaggerDirective(cg *ast.CommentGroup, name string) ([]string, bool) {
+	for _, l := range commentLines(cg) {
+		fields := strings.Fields(l)
+		if len(fields) > 0 && fields[0] == "swagger:"+name {
+			return fields[1:], true
+		}
+	}
+	return nil, false
+}
+
+// parseDoc splits the comment of a field or a type into its description and its annotations, by lower case key.
+func parseDoc(cg *ast.CommentGroup) (string, map[string]string) {
+	var description []string
+	annotations := make(map[string]string)
+	for _, l := range commentLines(cg) {
+		if strings.HasPrefix(l, "swagger:") {
+			continue
+		}
+		if i := strings.Index(l, ":"); i > 0 {
+			if key := strings.ToLower(strings.TrimSpace(l[:i])); fieldKeys[key] {
+				annotations[key] = strings.TrimSpace(l[i+1:])
+				continue
+			}
+		}
+		description = append(description, l)
+	}
+	return strings.TrimSpace(strings.Join(description, "\n")), annotations
+}
+
+// parseRoute parses the swagger:route annotation of the comment, if any.
+func parseRoute(cg *ast.CommentGroup) (*route, bool) {
+	lines := commentLines(cg)
+	start := -1
+	for i, l := range lines {
+		if strings.HasPrefix(l, "swagger:route ") {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return nil, false
+	}
+	fields := strings.Fields(lines[start])
+	if len(fields) != 5 {
+		return nil, false
+	}
+	r := &route{
+		method:      strings.ToLower(fields[1]),
+		path:        fields[2],
+		tag:         fields[3],
+		operationID: fields[4],
+		responses:   make(map[string]string),
+	}
+
+	var text []string
+	section := ""
+	for _, l := range lines[start+1:] {
+		switch l {
+		case "Consumes:", "Produces:", "Responses:", "Security:", "Schemes:", "Parameters:", "Extensions:":
+			section = strings.TrimSuffix(l, ":")
+			continue
+		}
+		switch section {
+		case "":
+			text = append(text, l)
+		case "Consumes":
+			if item := strings.TrimPrefix(l, "- "); item != l {
+				r.consumes = append(r.consumes, strings.TrimSpace(item))
+			}
+		case "Produces":
+			if item := strings.TrimPrefix(l, "- "); item != l {
+				r.produces = append(r.produces, strings.TrimSpace(item))
+			}
+		case "Responses":
+			if i := strings.Index(l, ":"); i > 0 {
+				r.responses[strings.TrimSpace(l[:i])] = strings.TrimSpace(l[i+1:])
+			}
+		case "Extensions":
+			if i := strings.Index(l, ":"); i > 0 && strings.TrimSpace(l[:i]) == "x-unauthenticated" {
+				r.unauthenticated = strings.TrimSpace(l[i+1:]) == "true"
+			}
+		}
+	}
+
+	// The first paragraph is the summary, the others the description.
+	paragraphs := strings.Split(strings.TrimSpace(strings.Join(text, "\n")), "\n\n")
+	r.summary = strings.ReplaceAll(paragraphs[0], "\n", " ")
+	if len(paragraphs) > 1 {
+		r.description = strings.TrimSpace(strings.Join(paragraphs[1:], "\n\n"))
+	}
+	return r, true
+}
+
+// metaInfo returns the description and the version of the swagger:meta annotation of the package.
+func metaInfo(cg *ast.CommentGroup) (string, string) {
+	var description []string
+	version := ""
+	inSections := false
+	for _, l := range commentLines(cg) {
+		if strings.HasPrefix(l, "Version:") {
+			version = strings.TrimSpace(strings.TrimPrefix(l, "Version:"))
+		}
+		if i := strings.Index(l, ":"); i > 0 && !strings.Contains(l[:i], " ") {
+			inSections = true
+		}
+		if !inSections {
+			description = append(description, l)
+		}
+	}
+	return strings.TrimSpace(strings.Join(description, "\n")), version
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+)
+
+// listedPackage is a package listed by go list.
+type listedPackage struct {
+	ImportPath string
+	Dir        string
+	GoFiles    []string
+	ImportMap  map[string]string
+	Standard   bool
+}
+
+// loader type checks a package and its dependencies from their sources, keeping the syntax of the packages out of
+// the standard library for their comments.
+type loader struct {
+	fset     *token.FileSet
+	listed   map[string]*listedPackage
+	byDir    map[string]*listedPackage
+	packages map[string]*types.Package
+	// syntax are the files of the packages out of the standard library.
+	syntax map[string][]*ast.File
+}
+
+func newLoader(pkg string) (*loader, error) {
+	cmd := exec.Command("go", "list", "-deps", "-json", pkg)
+	cmd.Env = append(os.Environ(), "CGO_ENABLED=0")
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the dependencies of %s: %w", pkg, err)
+	}
+
+	l := &loader{
+		fset:     token.NewFileSet(),
+		listed:   make(map[string]*listedPackage),
+		byDir:    make(map[string]*listedPackage),
+		packages: make(map[string]*types.Package),
+		syntax:   make(map[string][]*ast.File),
+	}
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for {
+		var p listedPackage
+		if err := dec.Decode(&p); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		l.listed[p.ImportPath] = &p
+		l.byDir[p.Dir] = &p
+	}
+	return l, nil
+}
+
+// load type checks the package, with the types of its expressions recorded in info.
+func (l *loader) load(path string, info *types.Info) (*types.Package, []*ast.File, error) {
+	p, ok := l.listed[path]
+	if !ok {
+		return nil, nil, fmt.Errorf("the package %s is not listed", path)
+	}
+
+	mode := parser.ParseComments
+	if p.Standard {
+		mode = 0
+	}
+	files := make([]*ast.File, 0, len(p.GoFiles))
+	for _, name := range p.GoFiles {
+		f, err := parser.ParseFile(l.fset, filepath.Join(p.Dir, name), nil, mode)
+		if err != nil {
+			return nil, nil, err
+		}
+		files = append(files, f)
+	}
+
+	var firstErr error
+	conf := types.Config{
+		Importer: l,
+		Sizes:    types.SizesFor("gc", runtime.GOARCH),
+		Error: func(err error) {
+			if firstErr == nil {
+				firstErr = err
+			}
+		},
+	}
+	pkg, _ := conf.Check(path, l.fset, files, info)
+
+	// The errors of the dependencies which do not prevent the type checking, such as unused imports of the
+	// standard library of newer Go versions, are ignored.
+	if info != nil && firstErr != nil {
+		return nil, nil, firstErr
+	}
+	l.packages[path] = pkg
+	if !p.Standard {
+		l.syntax[path] = files
+	}
+	return pkg, files, nil
+}
+
+func (l *loader) Import(path string) (*types.Package, error) {
+	return l.ImportFrom(path, "", 0)
+}
+
+func (l *loader) ImportFrom(path, dir string, _ types.ImportMode) (*types.Package, error) {
+	if path == "unsafe" {
+		return types.Unsafe, nil
+	}
+	if p, ok := l.byDir[dir]; ok {
+		if mapped, ok := p.ImportMap[path]; ok {
+			path = mapped
+		}
+	}
+	if pkg, ok := l.packages[path]; ok {
+		return pkg, nil
+	}
+	pkg, _, err := l.load(path, nil)
+	return pkg, err
+}
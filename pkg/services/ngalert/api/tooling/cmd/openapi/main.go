@@ -0,0 +1,296 @@
+// Command openapi generates the OpenAPI 3 document of the unified alerting API from the go-swagger annotations
+// of its definitions: the routes, their parameters and their responses, and the schemas of the types they use.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const definitionsPackage = "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+
+var pathParamRegex = regexp.MustCompile(`{([^}]+)}`)
+
+func main() {
+	var pkg, output string
+	flag.StringVar(&pkg, "pkg", definitionsPackage, "package of the definitions")
+	flag.StringVar(&output, "of", "", "output file")
+	flag.Parse()
+
+	if output == "" {
+		log.Fatal("no output file specified")
+	}
+
+	doc, err := generate(pkg)
+	if err != nil {
+		log.Fatal(err)
+	}
+	out, err := json.MarshalIndent(doc, "", " ")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	//nolint
+	if err := ioutil.WriteFile(output, append(out, '\n'), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// parameters are the parameters of an operation declared with swagger:parameters.
+type parameters struct {
+	params []*parameter
+	body   *requestBody
+}
+
+func generate(pkg string) (*document, error) {
+	l, err := newLoader(pkg)
+	if err != nil {
+		return nil, err
+	}
+	typesInfo := &types.Info{Defs: make(map[*ast.Ident]types.Object)}
+	_, syntax, err := l.load(pkg, typesInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	g := newSchemaGenerator()
+	for _, files := range l.syntax {
+		for _, f := range files {
+			g.addDocs(f)
+		}
+	}
+
+	doc := &document{
+		OpenAPI: "3.0.3",
+		Info:    info{Title: "Unified Alerting API"},
+		Paths:   make(map[string]pathItem),
+		Components: components{
+			SecuritySchemes: map[string]*securityScheme{
+				"basic":  {Type: "http", Scheme: "basic"},
+				"bearer": {Type: "http", Scheme: "bearer", Description: "An API key or a service account token."},
+			},
+		},
+		Security: []map[string][]string{{"basic": {}}, {"bearer": {}}},
+	}
+
+	var routes []*route
+	models := make(map[string]types.Type)
+	params := make(map[string][]*types.TypeName)
+	for _, f := range syntax {
+		if _, ok := swaggerDirective(f.Doc, "meta"); ok {
+			doc.Info.Description, doc.Info.Version = metaInfo(f.Doc)
+		}
+		for _, cg := range f.Comments {
+			if r, ok := parseRoute(cg); ok {
+				routes = append(routes, r)
+			}
+		}
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				obj, ok := typesInfo.Defs[ts.Name].(*types.TypeName)
+				if !ok {
+					continue
+				}
+				typeDoc := ts.Doc
+				if typeDoc == nil && len(gen.Specs) == 1 {
+					typeDoc = gen.Doc
+				}
+				models[ts.Name.Name] = obj.Type()
+				named, _ := unalias(obj.Type()).(*types.Named)
+
+				if args, ok := swaggerDirective(typeDoc, "model"); ok {
+					name := ts.Name.Name
+					if len(args) > 0 {
+						name = args[0]
+					}
+					models[name] = obj.Type()
+					if named != nil && name != named.Obj().Name() {
+						g.modelNames[named.Obj()] = name
+					}
+				}
+				if _, ok := swaggerDirective(typeDoc, "enum"); ok && named != nil {
+					g.enums[named.Obj()] = true
+				}
+				if ops, ok := swaggerDirective(typeDoc, "parameters"); ok {
+					for _, op := range ops {
+						params[op] = append(params[op], obj)
+					}
+				}
+			}
+		}
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].path != routes[j].path {
+			return routes[i].path < routes[j].path
+		}
+		return routes[i].method < routes[j].method
+	})
+	tags := make(map[string]bool)
+	for _, r := range routes {
+		if _, ok := doc.Paths[r.path][r.method]; ok {
+			return nil, fmt.Errorf("the operation %s %s is declared twice", r.method, r.path)
+		}
+		op, err := g.operation(r, params[r.operationID], models)
+		if err != nil {
+			return nil, err
+		}
+		if doc.Paths[r.path] == nil {
+			doc.Paths[r.path] = make(pathItem)
+		}
+		doc.Paths[r.path][r.method] = op
+		tags[r.tag] = true
+	}
+	for t := range tags {
+		doc.Tags = append(doc.Tags, tag{Name: t})
+	}
+	sort.Slice(doc.Tags, func(i, j int) bool { return doc.Tags[i].Name < doc.Tags[j].Name })
+	doc.Components.Schemas = g.schemas
+	return doc, nil
+}
+
+func (g *schemaGenerator) operation(r *route, paramTypes []*types.TypeName, models map[string]types.Type) (*operation, error) {
+	op := &operation{
+		Tags:        []string{r.tag},
+		OperationID: r.operationID,
+		Summary:     r.summary,
+		Description: r.description,
+		Responses:   make(map[string]*response),
+	}
+	if r.unauthenticated {
+		op.Security = &[]map[string][]string{}
+	}
+
+	inPath := make(map[string]bool)
+	for _, m := range pathParamRegex.FindAllStringSubmatch(r.path, -1) {
+		inPath[m[1]] = true
+	}
+	seen := make(map[string]bool)
+	for _, obj := range paramTypes {
+		p, err := g.parameters(obj, r.consumes)
+		if err != nil {
+			return nil, err
+		}
+		for _, param := range p.params {
+			key := param.In + "/" + param.Name
+			if seen[key] || (param.In == "path" && !inPath[param.Name]) {
+				continue
+			}
+			seen[key] = true
+			op.Parameters = append(op.Parameters, param)
+		}
+		if p.body != nil {
+			op.RequestBody = p.body
+		}
+	}
+	// The path parameters are required, they are added for the routes that do not declare them.
+	for _, m := range pathParamRegex.FindAllStringSubmatch(r.path, -1) {
+		if !seen["path/"+m[1]] {
+			op.Parameters = append(op.Parameters, &parameter{Name: m[1], In: "path", Required: true, Schema: &schema{Type: "string"}})
+		}
+	}
+	sort.SliceStable(op.Parameters, func(i, j int) bool {
+		return parameterOrder(op.Parameters[i].In) < parameterOrder(op.Parameters[j].In)
+	})
+
+	// The responses are walked in order, for the names of the schemas not to depend on the order of a map.
+	codes := make([]string, 0, len(r.responses))
+	for code := range r.responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		name := r.responses[code]
+		status, err := strconv.Atoi(code)
+		if err != nil {
+			return nil, fmt.Errorf("invalid status code %s of the operation %s", code, r.operationID)
+		}
+		resp := &response{Description: http.StatusText(status)}
+		if t, ok := models[name]; ok {
+			resp.Content = content(r.produces, g.schemaFor(t))
+		} else {
+			log.Printf("no model %s for the response %s of the operation %s", name, code, r.operationID)
+		}
+		op.Responses[code] = resp
+	}
+	return op, nil
+}
+
+func parameterOrder(in string) int {
+	switch in {
+	case "path":
+		return 0
+	case "query":
+		return 1
+	}
+	return 2
+}
+
+// parameters returns the parameters of a swagger:parameters struct.
+func (g *schemaGenerator) parameters(obj *types.TypeName, consumes []string) (*parameters, error) {
+	st, ok := obj.Type().Underlying().(*types.Struct)
+	if !ok {
+		return nil, fmt.Errorf("the parameters %s are not a struct", obj.Name())
+	}
+	p := &parameters{}
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		description, annotations := parseDoc(g.docs[f.Pos()])
+		name, _ := jsonTag(st.Tag(i))
+		if v, ok := annotations["name"]; ok {
+			name = v
+		}
+		if name == "" {
+			name = f.Name()
+		}
+
+		s := withAnnotations(g.schemaFor(f.Type()), f.Type(), "", annotations)
+		switch in := strings.ToLower(annotations["in"]); in {
+		case "body":
+			p.body = &requestBody{Description: description, Required: true, Content: content(consumes, s)}
+		case "path", "query", "header":
+			p.params = append(p.params, &parameter{
+				Name:        name,
+				In:          in,
+				Description: description,
+				Required:    in == "path" || annotations["required"] == "true",
+				Schema:      s,
+			})
+		case "":
+			// The fields without location are the fields of the body, the parameters are the body.
+			if p.body == nil {
+				p.body = &requestBody{Required: true, Content: content(consumes, g.schemaFor(obj.Type()))}
+			}
+		default:
+			return nil, fmt.Errorf("the parameter %s of %s is in %s, not in a path, a query, a header or a body", f.Name(), obj.Name(), in)
+		}
+	}
+	return p, nil
+}
+
+func content(contentTypes []string, s *schema) map[string]mediaType {
+	if len(contentTypes) == 0 {
+		contentTypes = []string{"application/json"}
+	}
+	c := make(map[string]mediaType, len(contentTypes))
+	for _, ct := range contentTypes {
+		c[ct] = mediaType{Schema: s}
+	}
+	return c
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	doc, err := generate(definitionsPackage)
+	require.NoError(t, err)
+
+	t.Run("the document is up to date", func(t *testing.T) {
+		generated, err := json.MarshalIndent(doc, "", " ")
+		require.NoError(t, err)
+		//nolint
+		current, err := ioutil.ReadFile("../../openapi.json")
+		require.NoError(t, err)
+		require.Equal(t, string(current), string(generated)+"\n", "openapi.json is out of date, run make openapi.json in the tooling directory")
+	})
+
+	t.Run("the references are defined", func(t *testing.T) {
+		var refs []string
+		var walk func(s *schema)
+		walk = func(s *schema) {
+			if s == nil {
+				return
+			}
+			if s.Ref != "" {
+				refs = append(refs, s.Ref)
+			}
+			walk(s.Items)
+			walk(s.AdditionalProperties)
+			for _, p := range s.Properties {
+				walk(p)
+			}
+			for _, a := range s.AllOf {
+				walk(a)
+			}
+		}
+		for _, s := range doc.Components.Schemas {
+			walk(s)
+		}
+		for _, item := range doc.Paths {
+			for _, op := range item {
+				for _, p := range op.Parameters {
+					walk(p.Schema)
+				}
+				if op.RequestBody != nil {
+					for _, c := range op.RequestBody.Content {
+						walk(c.Schema)
+					}
+				}
+				for _, r := range op.Responses {
+					for _, c := range r.Content {
+						walk(c.Schema)
+					}
+				}
+			}
+		}
+
+		require.NotEmpty(t, refs)
+		for _, ref := range refs {
+			require.Contains(t, doc.Components.Schemas, ref[len(schemaRefPrefix):])
+		}
+	})
+
+	t.Run("the path parameters are declared", func(t *testing.T) {
+		for path, item := range doc.Paths {
+			for method, op := range item {
+				declared := make(map[string]bool)
+				for _, p := range op.Parameters {
+					if p.In == "path" {
+						declared[p.Name] = true
+					}
+				}
+				for _, m := range pathParamRegex.FindAllStringSubmatch(path, -1) {
+					require.True(t, declared[m[1]], "%s %s does not declare %s", method, path, m[1])
+				}
+				require.Len(t, declared, len(pathParamRegex.FindAllString(path, -1)), "%s %s", method, path)
+			}
+		}
+	})
+}
@@ -0,0 +1,397 @@
+package main
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const schemaRefPrefix = "#/components/schemas/"
+
+// knownSchemas are the schemas of the types whose JSON encoding differs from their Go structure.
+var knownSchemas = map[string]func() *schema{
+	"time.Time":                                                 dateTimeSchema,
+	"time.Duration":                                             func() *schema { return &schema{Type: "integer", Format: "int64"} },
+	"encoding/json.RawMessage":                                  anySchema,
+	"encoding/json/jsontext.Value":                              anySchema,
+	"github.com/go-openapi/strfmt.DateTime":                     dateTimeSchema,
+	"github.com/go-openapi/strfmt.URI":                          uriSchema,
+	"github.com/grafana/grafana/pkg/components/simplejson.Json": anySchema,
+	"github.com/grafana/grafana/pkg/services/ngalert/models.Duration": func() *schema {
+		return &schema{Type: "number", Format: "double", Description: "A duration in seconds."}
+	},
+	"github.com/grafana/grafana-plugin-sdk-go/backend.DataResponse": objectSchema,
+	"github.com/grafana/grafana-plugin-sdk-go/data.ConfFloat64":     func() *schema { return &schema{Type: "number", Format: "double"} },
+	"github.com/grafana/grafana-plugin-sdk-go/data.Frame":           objectSchema,
+	"github.com/grafana/grafana-plugin-sdk-go/data.NoticeSeverity":  stringSchema,
+	"github.com/grafana/grafana-plugin-sdk-go/data.ValueMappings": func() *schema {
+		return &schema{Type: "array", Items: objectSchema()}
+	},
+	"github.com/prometheus/alertmanager/config.HostPort": stringSchema,
+	"github.com/prometheus/alertmanager/config.Matchers": func() *schema {
+		return &schema{Type: "array", Items: stringSchema(), Description: "Label matchers, such as severity=\"critical\"."}
+	},
+	"github.com/prometheus/alertmanager/config.Regexp":    stringSchema,
+	"github.com/prometheus/alertmanager/config.Secret":    stringSchema,
+	"github.com/prometheus/alertmanager/config.SecretURL": uriSchema,
+	"github.com/prometheus/alertmanager/config.URL":       uriSchema,
+	"github.com/prometheus/alertmanager/pkg/labels.Matcher": func() *schema {
+		return &schema{Type: "object", Properties: map[string]*schema{
+			"name":    stringSchema(),
+			"value":   stringSchema(),
+			"isRegex": {Type: "boolean"},
+			"isEqual": {Type: "boolean"},
+		}}
+	},
+	"github.com/prometheus/common/config.Secret": stringSchema,
+	"github.com/prometheus/common/config.URL":    uriSchema,
+	"github.com/prometheus/common/model.Duration": func() *schema {
+		return &schema{Type: "string", Description: "A duration such as 1m or 2h30m."}
+	},
+	"github.com/prometheus/prometheus/pkg/labels.Labels": labelsSchema,
+	"github.com/prometheus/prometheus/promql.Point":      pointSchema,
+	"github.com/prometheus/prometheus/promql.Sample": func() *schema {
+		return &schema{Type: "object", Properties: map[string]*schema{"metric": labelsSchema(), "value": pointSchema()}}
+	},
+}
+
+func anySchema() *schema      { return &schema{} }
+func stringSchema() *schema   { return &schema{Type: "string"} }
+func objectSchema() *schema   { return &schema{Type: "object"} }
+func dateTimeSchema() *schema { return &schema{Type: "string", Format: "date-time"} }
+func uriSchema() *schema      { return &schema{Type: "string", Format: "uri"} }
+func labelsSchema() *schema {
+	return &schema{Type: "object", AdditionalProperties: stringSchema()}
+}
+
+// pointSchema is the schema of a sample of Prometheus, its timestamp in seconds and its value as a string.
+func pointSchema() *schema {
+	return &schema{Type: "array", Items: anySchema(), Description: "The timestamp, in seconds, and the value of the sample."}
+}
+
+// schemaGenerator writes the schemas of the Go types as they are encoded in JSON, the named types as components.
+type schemaGenerator struct {
+	// docs are the comments of the types and the fields, by position of their names.
+	docs map[token.Pos]*ast.CommentGroup
+	// modelNames are the names set with swagger:model.
+	modelNames map[*types.TypeName]string
+	// enums are the types with swagger:enum.
+	enums map[*types.TypeName]bool
+
+	schemas map[string]*schema
+	names   map[*types.TypeName]string
+	taken   map[string]*types.TypeName
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{
+		docs:       make(map[token.Pos]*ast.CommentGroup),
+		modelNames: make(map[*types.TypeName]string),
+		enums:      make(map[*types.TypeName]bool),
+		schemas:    make(map[string]*schema),
+		names:      make(map[*types.TypeName]string),
+		taken:      make(map[string]*types.TypeName),
+	}
+}
+
+// addDocs records the comments of the types and of the fields of the file.
+func (g *schemaGenerator) addDocs(f *ast.File) {
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.GenDecl:
+			if n.Tok == token.TYPE && len(n.Specs) == 1 && n.Doc != nil {
+				g.docs[n.Specs[0].(*ast.TypeSpec).Name.Pos()] = n.Doc
+			}
+		case *ast.TypeSpec:
+			if n.Doc != nil {
+				g.docs[n.Name.Pos()] = n.Doc
+			}
+		case *ast.Field:
+			doc := n.Doc
+			if doc == nil {
+				doc = n.Comment
+			}
+			if doc == nil {
+				return true
+			}
+			for _, name := range n.Names {
+				g.docs[name.Pos()] = doc
+			}
+			if len(n.Names) == 0 {
+				g.docs[n.Type.Pos()] = doc
+			}
+		}
+		return true
+	})
+}
+
+// name returns the name of the component of the type: its swagger:model name, or its name, prefixed with its
+// package if another type has the same name.
+func (g *schemaGenerator) name(obj *types.TypeName) string {
+	if name, ok := g.names[obj]; ok {
+		return name
+	}
+	name, ok := g.modelNames[obj]
+	if !ok {
+		name = obj.Name()
+		if other, ok := g.taken[name]; ok && other != obj {
+			name = obj.Pkg().Name() + name
+		}
+	}
+	g.names[obj] = name
+	g.taken[name] = obj
+	return name
+}
+
+func (g *schemaGenerator) schemaFor(t types.Type) *schema {
+	switch t := unalias(t).(type) {
+	case *types.Named:
+		return g.namedSchema(t)
+	case *types.Pointer:
+		return g.schemaFor(t.Elem())
+	case *types.Basic:
+		return basicSchema(t)
+	case *types.Slice:
+		if b, ok := t.Elem().(*types.Basic); ok && b.Kind() == types.Byte {
+			return &schema{Type: "string", Format: "byte"}
+		}
+		return &schema{Type: "array", Items: g.schemaFor(t.Elem())}
+	case *types.Array:
+		return &schema{Type: "array", Items: g.schemaFor(t.Elem())}
+	case *types.Map:
+		return &schema{Type: "object", AdditionalProperties: g.schemaFor(t.Elem())}
+	case *types.Struct:
+		return g.structSchema(t)
+	}
+	// Interfaces, and the types without JSON encoding, can be any value.
+	return &schema{}
+}
+
+func (g *schemaGenerator) namedSchema(t *types.Named) *schema {
+	obj := t.Obj()
+	if obj.Pkg() != nil {
+		if known, ok := knownSchemas[obj.Pkg().Path()+"."+obj.Name()]; ok {
+			return known()
+		}
+	}
+	if implements(t, "MarshalText") && !implements(t, "MarshalJSON") {
+		return &schema{Type: "string"}
+	}
+	if _, ok := t.Underlying().(*types.Basic); ok && !g.enums[obj] {
+		if implements(t, "MarshalJSON") {
+			return &schema{}
+		}
+		return g.schemaFor(t.Underlying())
+	}
+	if _, ok := t.Underlying().(*types.Interface); ok {
+		return &schema{}
+	}
+
+	name := g.name(obj)
+	ref := &schema{Ref: schemaRefPrefix + name}
+	if _, ok := g.schemas[name]; ok {
+		return ref
+	}
+	s := &schema{}
+	g.schemas[name] = s
+	*s = *g.schemaFor(t.Underlying())
+	if description, _ := parseDoc(g.docs[obj.Pos()]); description != "" {
+		s.Description = description
+	}
+	if g.enums[obj] {
+		s.Enum = enumValues(t)
+	}
+	return ref
+}
+
+func (g *schemaGenerator) structSchema(t *types.Struct) *schema {
+	s := &schema{Type: "object", Properties: make(map[string]*schema)}
+	for i := 0; i < t.NumFields(); i++ {
+		f := t.Field(i)
+		name, opts := jsonTag(t.Tag(i))
+		if name == "-" && opts == "" {
+			continue
+		}
+		if f.Embedded() && name == "" {
+			if embedded, ok := g.embeddedSchema(f.Type()); ok {
+				for k, v := range embedded.Properties {
+					s.Properties[k] = v
+				}
+				s.Required = append(s.Required, embedded.Required...)
+				continue
+			}
+		}
+		if !f.Exported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name()
+		}
+
+		description, annotations := parseDoc(g.docs[f.Pos()])
+		p := g.schemaFor(f.Type())
+		if strings.Contains(opts, "string") {
+			p = &schema{Type: "string"}
+		}
+		p = withAnnotations(p, f.Type(), description, annotations)
+		if annotations["required"] == "true" {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = p
+	}
+	if len(s.Properties) == 0 {
+		s.Properties = nil
+	}
+	sort.Strings(s.Required)
+	return s
+}
+
+// embeddedSchema returns the schema of the struct of an embedded field, whose fields are encoded in the fields of
+// the struct that embeds it.
+func (g *schemaGenerator) embeddedSchema(t types.Type) (*schema, bool) {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok || implements(t, "MarshalJSON") {
+		return nil, false
+	}
+	return g.structSchema(st), true
+}
+
+// withAnnotations adds the description and the annotations of a field or a parameter to its schema. The siblings
+// of a reference are ignored, it is wrapped in allOf to keep them.
+func withAnnotations(s *schema, t types.Type, description string, annotations map[string]string) *schema {
+	_, hasDefault := annotations["default"]
+	_, hasEnum := annotations["enum"]
+	if description == "" && !hasDefault && !hasEnum && annotations["minimum"] == "" && annotations["maximum"] == "" {
+		return s
+	}
+	if s.Ref != "" {
+		s = &schema{AllOf: []*schema{s}}
+	} else {
+		c := *s
+		s = &c
+	}
+	s.Description = description
+	if hasDefault {
+		s.Default = annotationValue(t, annotations["default"])
+	}
+	if hasEnum {
+		for _, v := range strings.Split(annotations["enum"], ",") {
+			s.Enum = append(s.Enum, annotationValue(t, strings.TrimSpace(v)))
+		}
+	}
+	if v, err := strconv.ParseFloat(annotations["minimum"], 64); err == nil {
+		s.Minimum = &v
+	}
+	if v, err := strconv.ParseFloat(annotations["maximum"], 64); err == nil {
+		s.Maximum = &v
+	}
+	return s
+}
+
+// annotationValue converts the value of an annotation to the type of the field.
+func annotationValue(t types.Type, v string) interface{} {
+	b, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return v
+	}
+	switch {
+	case b.Info()&types.IsBoolean != 0:
+		if parsed, err := strconv.ParseBool(v); err == nil {
+			return parsed
+		}
+	case b.Info()&types.IsInteger != 0:
+		if parsed, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return parsed
+		}
+	case b.Info()&types.IsFloat != 0:
+		if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+			return parsed
+		}
+	}
+	return v
+}
+
+func basicSchema(t *types.Basic) *schema {
+	switch {
+	case t.Info()&types.IsBoolean != 0:
+		return &schema{Type: "boolean"}
+	case t.Info()&types.IsInteger != 0:
+		switch t.Kind() {
+		case types.Int32, types.Int16, types.Int8, types.Uint32, types.Uint16, types.Uint8:
+			return &schema{Type: "integer", Format: "int32"}
+		}
+		return &schema{Type: "integer", Format: "int64"}
+	case t.Info()&types.IsFloat != 0:
+		if t.Kind() == types.Float32 {
+			return &schema{Type: "number", Format: "float"}
+		}
+		return &schema{Type: "number", Format: "double"}
+	case t.Info()&types.IsString != 0:
+		return &schema{Type: "string"}
+	}
+	return &schema{}
+}
+
+// enumValues returns the values of the constants of the type, in the order of their declarations.
+func enumValues(t *types.Named) []interface{} {
+	scope := t.Obj().Pkg().Scope()
+	consts := make([]*types.Const, 0)
+	for _, name := range scope.Names() {
+		if c, ok := scope.Lookup(name).(*types.Const); ok && types.Identical(c.Type(), t) {
+			consts = append(consts, c)
+		}
+	}
+	sort.Slice(consts, func(i, j int) bool { return consts[i].Pos() < consts[j].Pos() })
+
+	values := make([]interface{}, 0, len(consts))
+	for _, c := range consts {
+		switch c.Val().Kind() {
+		case constant.String:
+			values = append(values, constant.StringVal(c.Val()))
+		case constant.Int:
+			v, _ := constant.Int64Val(c.Val())
+			values = append(values, v)
+		default:
+			values = append(values, c.Val().ExactString())
+		}
+	}
+	return values
+}
+
+// unalias returns the type an alias refers to, the aliases have their own type since Go 1.22.
+func unalias(t types.Type) types.Type {
+	for {
+		alias, ok := t.(interface{ Rhs() types.Type })
+		if !ok {
+			return t
+		}
+		t = alias.Rhs()
+	}
+}
+
+// implements returns whether the type, or a pointer to it, has the method.
+func implements(t types.Type, method string) bool {
+	if _, ok := t.(*types.Pointer); !ok {
+		t = types.NewPointer(t)
+	}
+	obj, _, _ := types.LookupFieldOrMethod(t, true, nil, method)
+	_, ok := obj.(*types.Func)
+	return ok
+}
+
+// jsonTag returns the name and the options of the json tag.
+func jsonTag(tag string) (string, string) {
+	v := reflect.StructTag(tag).Get("json")
+	if i := strings.Index(v, ","); i >= 0 {
+		return v[:i], v[i+1:]
+	}
+	return v, ""
+}
@@ -1,6 +1,11 @@
 package definitions
 
-import v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+import (
+	"time"
+
+	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+)
 
 // swagger:route GET /api/v1/ngalert/alertmanagers configuration RouteGetAlertmanagers
 //
@@ -12,6 +17,17 @@ import v1 "github.com/prometheus/client_golang/api/prometheus/v1"
 //     Responses:
 //		 200: GettableAlertmanagers
 
+// swagger:route GET /api/v1/ngalert/alertmanagers/health configuration RouteGetAlertmanagersHealth
+//
+//  Get the result of the last health check of each external Alertmanager of the user's organization.
+//  Alerts are not sent to the unhealthy ones, unless none is healthy.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//		 200: GettableAlertmanagersHealth
+
 // swagger:route GET /api/v1/ngalert/admin_config configuration RouteGetNGalertConfig
 //
 //  Get the NGalert configuration of the user's organization, returns 404 if no configuration is present.
@@ -46,6 +62,320 @@ import v1 "github.com/prometheus/client_golang/api/prometheus/v1"
 //       200: Ack
 //       500: Failure
 
+// swagger:route GET /api/v1/ngalert/maintenance configuration RouteGetMaintenanceMode
+//
+// Get the maintenance mode of the user's organization, returns 404 if the organization is not in maintenance mode.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableMaintenanceMode
+//       404: Failure
+
+// swagger:route POST /api/v1/ngalert/maintenance configuration RoutePostMaintenanceMode
+//
+// Puts the user's organization into maintenance mode: alert rules are still evaluated but no notification is sent
+// until the maintenance mode expires.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       201: Ack
+//       400: ValidationError
+
+// swagger:route DELETE /api/v1/ngalert/maintenance configuration RouteDeleteMaintenanceMode
+//
+// Takes the user's organization out of maintenance mode.
+//
+//     Responses:
+//       200: Ack
+//       500: Failure
+
+// swagger:route GET /api/v1/ngalert/label_policies configuration RouteGetLabelPolicies
+//
+// Get the label policies of the user's organization, returns 404 if it has none.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableLabelPolicies
+//       404: Failure
+
+// swagger:route POST /api/v1/ngalert/label_policies configuration RoutePostLabelPolicies
+//
+// Replaces the label policies of the user's organization. They rewrite the labels of the alerts, in order, before
+// they are routed by the embedded Alertmanager or sent to the external ones.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       201: Ack
+//       400: ValidationError
+
+// swagger:route DELETE /api/v1/ngalert/label_policies configuration RouteDeleteLabelPolicies
+//
+// Deletes the label policies of the user's organization.
+//
+//     Responses:
+//       200: Ack
+//       500: Failure
+
+// swagger:route GET /api/v1/ngalert/git_sync configuration RouteGetGitSyncStatus
+//
+// Get the status of the Git sync of the alerting resources, returns 404 if the Git sync is not configured.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GitSyncStatus
+//       404: Failure
+
+// swagger:route POST /api/v1/ngalert/git_sync configuration RoutePostGitSync
+//
+// Pulls the Git repository of the Git sync and applies its files now, even if the commit was already applied.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GitSyncStatus
+//       404: Failure
+
+// swagger:route GET /api/v1/ngalert/orgs/health configuration RouteGetOrgsAlertingHealth
+//
+// Get a summary of the alerting health of every organization, for the administrators of the server.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableOrgsAlertingHealth
+//       500: Failure
+
+// swagger:route POST /api/v1/ngalert/orgs/alertmanagers/sync configuration RoutePostOrgsAlertmanagersSync
+//
+// Syncs the Alertmanager configuration of an organization, or of every organization, from the database now instead
+// of at the next sync interval.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableAlertmanagersSync
+//       404: Failure
+//       500: Failure
+
+// swagger:route GET /api/v1/ngalert/standby configuration RouteGetStandby
+//
+// Get whether the Alertmanagers of this replica send the notifications or are a warm standby.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableStandby
+
+// swagger:route POST /api/v1/ngalert/standby/promote configuration RoutePostStandbyPromote
+//
+// Promotes this replica, its Alertmanagers send the notifications whatever the leader election.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableStandby
+//       400: ValidationError
+
+// swagger:route POST /api/v1/ngalert/standby/demote configuration RoutePostStandbyDemote
+//
+// Cancels the promotion of this replica, which becomes a warm standby again unless it's elected.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableStandby
+//       400: ValidationError
+
+// swagger:route GET /api/v1/ngalert/openapi.json configuration RouteGetOpenAPIDocument
+//
+// Get the OpenAPI 3 document of the unified alerting API.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: OpenAPIDocument
+
+// swagger:parameters RoutePostMaintenanceMode
+type MaintenanceMode struct {
+	// in:body
+	Body PostableMaintenanceMode
+}
+
+// swagger:parameters RoutePostLabelPolicies
+type LabelPoliciesParams struct {
+	// in:body
+	Body PostableLabelPolicies
+}
+
+// swagger:model
+type PostableLabelPolicies struct {
+	Policies []LabelPolicy `json:"policies"`
+}
+
+// swagger:model
+type GettableLabelPolicies struct {
+	Policies  []LabelPolicy `json:"policies"`
+	UpdatedBy string        `json:"updatedBy"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+}
+
+// LabelPolicy adds, renames or drops a label of the alerts, or sets a label to the value of another label in a
+// lookup table.
+// swagger:model
+type LabelPolicy struct {
+	// enum: add,rename,drop,map
+	Action string `json:"action"`
+	// Matchers select the alerts the policy applies to, like the matchers of the Alertmanager configuration. The
+	// policy applies to every alert if empty.
+	Matchers []string `json:"matchers,omitempty"`
+	// Label added, renamed or dropped, or read by a map policy.
+	Label string `json:"label"`
+	// New name of the label of a rename policy, or label set by a map policy.
+	TargetLabel string `json:"targetLabel,omitempty"`
+	// Value of the label of an add policy.
+	Value string `json:"value,omitempty"`
+	// Replace the existing value of the label set by the policy.
+	Overwrite bool `json:"overwrite,omitempty"`
+	// Values of the target label by value of the label, of a map policy.
+	Lookup map[string]string `json:"lookup,omitempty"`
+	// CSV file of the lookup table of a map policy, given instead of the lookup. Its two columns are the value of
+	// the label and the value of the target label.
+	LookupCSV string `json:"lookupCsv,omitempty"`
+	// URL the lookup table of a map policy is fetched from and refreshed every 5 minutes, as a CSV file or as a
+	// JSON object.
+	LookupURL string `json:"lookupUrl,omitempty"`
+	// Value of the target label of a map policy when the value of the label is not in the lookup table.
+	Default string `json:"default,omitempty"`
+}
+
+// GitSyncStatus is the status of the last sync of the alerting resources from the Git repository.
+// swagger:model
+type GitSyncStatus struct {
+	// Repository is the URL of the repository, without its password.
+	Repository string `json:"repository"`
+	Branch     string `json:"branch"`
+	Path       string `json:"path"`
+	// Commit is the last commit applied.
+	Commit string `json:"commit,omitempty"`
+	// LastSync is the time of the last sync, LastSuccess of the last sync that applied the files.
+	LastSync    time.Time `json:"lastSync,omitempty"`
+	LastSuccess time.Time `json:"lastSuccess,omitempty"`
+	// Error is the error of the last sync, if it failed.
+	Error string `json:"error,omitempty"`
+	// Conflicts are the resources of the files that are managed otherwise, the files are not applied until they
+	// are resolved.
+	Conflicts []GitSyncConflict `json:"conflicts,omitempty"`
+}
+
+// GitSyncConflict is a resource of the files of the Git repository that is provisioned otherwise, or taken over in
+// the UI.
+type GitSyncConflict struct {
+	OrgID int64 `json:"orgId"`
+	// enum: alertRule,contactPoint,notificationPolicy,muteTiming,template
+	ResourceType string `json:"resourceType"`
+	// Key is the UID or name of the resource.
+	Key        string `json:"key"`
+	Provenance string `json:"provenance"`
+}
+
+// GettableOrgsAlertingHealth is the alerting health of the organizations with rules or an Alertmanager, by ID.
+// swagger:model
+type GettableOrgsAlertingHealth []GettableOrgAlertingHealth
+
+// GettableOrgAlertingHealth is the alerting health of an organization.
+type GettableOrgAlertingHealth struct {
+	OrgID       int64 `json:"orgId"`
+	Rules       int64 `json:"rules"`
+	PausedRules int64 `json:"pausedRules"`
+	// AlertmanagerReady is false until the Alertmanager configuration of the organization is applied.
+	AlertmanagerReady bool `json:"alertmanagerReady"`
+	// ConfigHash is the hash of the Alertmanager configuration applied.
+	ConfigHash string `json:"configHash,omitempty"`
+	// LastSyncAt is when the Alertmanager configuration was last synced from the database, SyncError is the error
+	// of the sync if it failed.
+	LastSyncAt time.Time `json:"lastSyncAt,omitempty"`
+	SyncError  string    `json:"syncError,omitempty"`
+	// EvaluationLagSeconds is how late the latest evaluation of a rule of the organization started.
+	EvaluationLagSeconds float64 `json:"evaluationLagSeconds"`
+	// NotificationAttempts and NotificationFailures count the notifications since the start of the instance.
+	NotificationAttempts    int64     `json:"notificationAttempts"`
+	NotificationFailures    int64     `json:"notificationFailures"`
+	NotificationFailureRate float64   `json:"notificationFailureRate"`
+	LastNotificationError   string    `json:"lastNotificationError,omitempty"`
+	LastNotificationErrorAt time.Time `json:"lastNotificationErrorAt,omitempty"`
+}
+
+// swagger:parameters RoutePostOrgsAlertmanagersSync
+type AlertmanagersSyncParams struct {
+	// The ID of the organization to sync, all the organizations are synced if not given
+	// in: query
+	// required: false
+	OrgID int64 `json:"orgId"`
+}
+
+// GettableAlertmanagersSync is the result of a sync of the Alertmanagers.
+// swagger:model
+type GettableAlertmanagersSync struct {
+	Orgs []AlertmanagerSyncResult `json:"orgs"`
+}
+
+// AlertmanagerSyncResult is the result of the sync of the Alertmanager of an organization.
+type AlertmanagerSyncResult struct {
+	OrgID int64 `json:"orgId"`
+	// ConfigHash is the hash of the configuration applied after the sync.
+	ConfigHash string `json:"configHash,omitempty"`
+	// Error is the error of the sync, if it failed.
+	Error string `json:"error,omitempty"`
+}
+
+// GettableStandby is whether the Alertmanagers of a replica send the notifications or are a warm standby.
+// swagger:model
+type GettableStandby struct {
+	// enum: disabled,manual,leader_election
+	Mode string `json:"mode"`
+	// Active is true if the Alertmanagers of the replica send the notifications.
+	Active bool `json:"active"`
+	// Promoted is true if the replica was promoted with the API.
+	Promoted bool `json:"promoted"`
+	// Position is the position of the replica in the cluster, the leader elected is at 0.
+	Position int `json:"position"`
+}
+
+// OpenAPIDocument is an OpenAPI 3 document, generated from the definitions of the API.
+// swagger:model
+type OpenAPIDocument map[string]interface{}
+
+// swagger:model
+type PostableMaintenanceMode struct {
+	Reason string `json:"reason"`
+	// How long notifications are suppressed for.
+	Duration model.Duration `json:"duration"`
+}
+
+// swagger:model
+type GettableMaintenanceMode struct {
+	Reason    string    `json:"reason"`
+	CreatedBy string    `json:"createdBy"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
 // swagger:parameters RoutePostNGalertConfig
 type NGalertConfig struct {
 	// in:body
@@ -55,11 +385,49 @@ type NGalertConfig struct {
 // swagger:model
 type PostableNGalertConfig struct {
 	Alertmanagers []string `json:"alertmanagers"`
+	// Authentication of the alerts sent to the Alertmanagers.
+	Auth *ExternalAlertmanagerAuth `json:"auth,omitempty"`
+	// Which Alertmanagers the alerts are sent to, the embedded one and the external ones by default.
+	// enum: all,internal,external
+	AlertmanagersChoice string `json:"alertmanagersChoice,omitempty"`
 }
 
 // swagger:model
 type GettableNGalertConfig struct {
 	Alertmanagers []string `json:"alertmanagers"`
+	// Authentication of the alerts sent to the Alertmanagers, without the secrets.
+	Auth *ExternalAlertmanagerAuth `json:"auth,omitempty"`
+	// enum: all,internal,external
+	AlertmanagersChoice string `json:"alertmanagersChoice"`
+}
+
+// swagger:model
+type ExternalAlertmanagerAuth struct {
+	TLS    *ExternalAlertmanagerTLS    `json:"tls,omitempty"`
+	OAuth2 *ExternalAlertmanagerOAuth2 `json:"oauth2,omitempty"`
+}
+
+// ExternalAlertmanagerTLS is the mutual TLS configuration of the connections, the certificates and key are PEM encoded.
+// swagger:model
+type ExternalAlertmanagerTLS struct {
+	CACert     string `json:"caCert,omitempty"`
+	ClientCert string `json:"clientCert,omitempty"`
+	// The client key is never returned, when empty the stored one is kept.
+	ClientKey          string `json:"clientKey,omitempty"`
+	ClientKeySet       bool   `json:"clientKeySet,omitempty"`
+	ServerName         string `json:"serverName,omitempty"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"`
+}
+
+// ExternalAlertmanagerOAuth2 is the OAuth2 client credentials flow used to get the tokens of the requests.
+// swagger:model
+type ExternalAlertmanagerOAuth2 struct {
+	ClientID string `json:"clientId"`
+	// The client secret is never returned, when empty the stored one is kept.
+	ClientSecret    string   `json:"clientSecret,omitempty"`
+	ClientSecretSet bool     `json:"clientSecretSet,omitempty"`
+	TokenURL        string   `json:"tokenUrl"`
+	Scopes          []string `json:"scopes,omitempty"`
 }
 
 // swagger:model
@@ -67,3 +435,18 @@ type GettableAlertmanagers struct {
 	Status string                 `json:"status"`
 	Data   v1.AlertManagersResult `json:"data"`
 }
+
+// swagger:model
+type GettableAlertmanagersHealth struct {
+	Targets []AlertmanagerTargetHealth `json:"targets"`
+}
+
+// swagger:model
+type AlertmanagerTargetHealth struct {
+	URL        string    `json:"url"`
+	Healthy    bool      `json:"healthy"`
+	Version    string    `json:"version,omitempty"`
+	ConfigHash string    `json:"configHash,omitempty"`
+	LastCheck  time.Time `json:"lastCheck"`
+	LastError  string    `json:"lastError,omitempty"`
+}
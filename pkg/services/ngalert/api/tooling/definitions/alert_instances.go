@@ -0,0 +1,99 @@
+package definitions
+
+import "time"
+
+// swagger:route Get /api/v1/alerts alert_instances RouteGetAlertInstances
+//
+// Get the current alert instances of the Grafana managed rules in the folders the user can view, filtered by their
+// labels, state and folder, to build alert consoles.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: AlertInstances
+//       400: ValidationError
+
+// swagger:route Get /api/v1/incidents alert_instances RouteGetIncidents
+//
+// Get the open incidents, the firing alerts of the different rules correlated by the labels of correlation_labels.
+// Only the alerts of the rules in the folders the user can view are returned.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: Incidents
+
+// swagger:parameters RouteGetAlertInstances
+type AlertInstancesParams struct {
+	// Filter is a set of Prometheus label matchers the labels of the instances match, such as
+	// {severity="critical",team=~"db.*"}. The instances match all the filters.
+	// in:query
+	Filter []string `json:"filter"`
+	// State is normal, pending, alerting, nodata or error. The instances are in one of the states.
+	// in:query
+	State []string `json:"state"`
+	// FolderUID is the UID of the folder of the rules of the instances, the instances are in one of the folders.
+	// in:query
+	FolderUID []string `json:"folder_uid"`
+	// in:query
+	// default: 100
+	// maximum: 1000
+	Limit int `json:"limit"`
+	// in:query
+	Offset int `json:"offset"`
+}
+
+// swagger:model
+type AlertInstances struct {
+	// Total is the number of instances matching the filters, on all the pages.
+	Total     int             `json:"total"`
+	Instances []AlertInstance `json:"instances"`
+}
+
+// swagger:model
+type AlertInstance struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	State       string            `json:"state"`
+	// ActiveAt is when the instance entered its state.
+	ActiveAt       time.Time `json:"activeAt"`
+	LastEvaluation time.Time `json:"lastEvaluation"`
+	// Value is the value of the queries of the instance when it's alerting.
+	Value     string `json:"value,omitempty"`
+	RuleUID   string `json:"ruleUID"`
+	RuleTitle string `json:"ruleTitle"`
+	FolderUID string `json:"folderUID"`
+	RuleGroup string `json:"ruleGroup"`
+}
+
+// swagger:model
+type Incidents struct {
+	// Enabled is false when the alerts aren't correlated into incidents.
+	Enabled   bool       `json:"enabled"`
+	Incidents []Incident `json:"incidents"`
+}
+
+// swagger:model
+type Incident struct {
+	// ID is the value of the __incident_id__ label of the alerts of the incident.
+	ID string `json:"id"`
+	// Labels are the correlation labels shared by the alerts of the incident.
+	Labels   map[string]string `json:"labels"`
+	StartsAt time.Time         `json:"startsAt"`
+	// LastActiveAt is the last time an alert of the incident was firing.
+	LastActiveAt time.Time       `json:"lastActiveAt"`
+	Firing       bool            `json:"firing"`
+	Alerts       []IncidentAlert `json:"alerts"`
+}
+
+// swagger:model
+type IncidentAlert struct {
+	Labels    map[string]string `json:"labels"`
+	StartsAt  time.Time         `json:"startsAt"`
+	Firing    bool              `json:"firing"`
+	RuleUID   string            `json:"ruleUID"`
+	RuleTitle string            `json:"ruleTitle"`
+	FolderUID string            `json:"folderUID"`
+}
@@ -1,9 +1,11 @@
 package definitions
 
 import (
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"reflect"
 	"time"
 
@@ -11,9 +13,11 @@ import (
 	"github.com/pkg/errors"
 	amv2 "github.com/prometheus/alertmanager/api/v2/models"
 	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/common/model"
 	"gopkg.in/yaml.v3"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -25,6 +29,7 @@ import (
 //     Responses:
 //       201: Ack
 //       400: ValidationError
+//       409: Failure
 
 // swagger:route GET /api/alertmanager/{Recipient}/config/api/v1/alerts alertmanager RouteGetAlertingConfig
 //
@@ -62,6 +67,9 @@ import (
 //
 // create alertmanager alerts
 //
+// Pushes alerts from external sources, such as Prometheus servers, into the Grafana Alertmanager. Authenticate
+// with an API key of the Editor or Admin role.
+//
 //     Responses:
 //       200: Ack
 //       400: ValidationError
@@ -304,6 +312,16 @@ type PostableAlerts struct {
 	PostableAlerts []amv2.PostableAlert `yaml:"" json:""`
 }
 
+// UnmarshalJSON reads the alerts from a JSON array, the body sent by Prometheus and the Alertmanager clients.
+func (a *PostableAlerts) UnmarshalJSON(b []byte) error {
+	return json.Unmarshal(b, &a.PostableAlerts)
+}
+
+// MarshalJSON writes the alerts as a JSON array.
+func (a PostableAlerts) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.PostableAlerts)
+}
+
 // swagger:parameters RoutePostAlertingConfig
 type BodyAlertingConfig struct {
 	// in:body
@@ -329,7 +347,10 @@ type DatasourceReference struct {
 type PostableUserConfig struct {
 	TemplateFiles      map[string]string         `yaml:"template_files" json:"template_files"`
 	AlertmanagerConfig PostableApiAlertingConfig `yaml:"alertmanager_config" json:"alertmanager_config"`
-	amSimple           map[string]interface{}    `yaml:"-" json:"-"`
+	// Fingerprint is the fingerprint of the configuration the changes were made from, as returned by the API. The
+	// configuration is rejected with a 409 if it has been changed since. It is not checked when missing.
+	Fingerprint string                 `yaml:"-" json:"fingerprint,omitempty"`
+	amSimple    map[string]interface{} `yaml:"-" json:"-"`
 }
 
 func (c *PostableUserConfig) UnmarshalJSON(b []byte) error {
@@ -394,6 +415,23 @@ func (c *PostableUserConfig) ProcessConfig() error {
 	return processReceiverConfigs(c.AlertmanagerConfig.Receivers)
 }
 
+// DecryptSecureSettings replaces the encrypted secure settings of the Grafana receivers of a stored
+// configuration with their decrypted values. They are encrypted again by ProcessConfig.
+func (c *PostableUserConfig) DecryptSecureSettings() error {
+	for _, r := range c.AlertmanagerConfig.Receivers {
+		for _, gr := range r.GrafanaManagedReceivers {
+			for key := range gr.SecureSettings {
+				decrypted, err := gr.GetDecryptedSecret(key)
+				if err != nil {
+					return fmt.Errorf("failed to decrypt stored secure setting: %s: %w", key, err)
+				}
+				gr.SecureSettings[key] = decrypted
+			}
+		}
+	}
+	return nil
+}
+
 // MarshalYAML implements yaml.Marshaller.
 func (c *PostableUserConfig) MarshalYAML() (interface{}, error) {
 	yml, err := yaml.Marshal(c.amSimple)
@@ -436,6 +474,8 @@ func (c *PostableUserConfig) UnmarshalYAML(value *yaml.Node) error {
 type GettableUserConfig struct {
 	TemplateFiles      map[string]string         `yaml:"template_files" json:"template_files"`
 	AlertmanagerConfig GettableApiAlertingConfig `yaml:"alertmanager_config" json:"alertmanager_config"`
+	// readonly: true
+	Fingerprint string `yaml:"-" json:"fingerprint,omitempty"`
 
 	// amSimple stores a map[string]interface of the decoded alertmanager config.
 	// This enables circumventing the underlying alertmanager secret type
@@ -472,11 +512,13 @@ func (c *GettableUserConfig) MarshalJSON() ([]byte, error) {
 	type plain struct {
 		TemplateFiles      map[string]string      `yaml:"template_files" json:"template_files"`
 		AlertmanagerConfig map[string]interface{} `yaml:"alertmanager_config" json:"alertmanager_config"`
+		Fingerprint        string                 `yaml:"-" json:"fingerprint,omitempty"`
 	}
 
 	tmp := plain{
 		TemplateFiles:      c.TemplateFiles,
 		AlertmanagerConfig: c.amSimple,
+		Fingerprint:        c.Fingerprint,
 	}
 
 	return json.Marshal(tmp)
@@ -502,6 +544,9 @@ type GettableApiAlertingConfig struct {
 
 	// Override with our superset receiver type
 	Receivers []*GettableApiReceiver `yaml:"receivers,omitempty" json:"receivers,omitempty"`
+	// RouteProvenance is the provenance of the notification policy tree.
+	// readonly: true
+	RouteProvenance models.Provenance `yaml:"route_provenance,omitempty" json:"route_provenance,omitempty"`
 }
 
 func (c *GettableApiAlertingConfig) UnmarshalJSON(b []byte) error {
@@ -552,7 +597,16 @@ func (c *GettableApiAlertingConfig) validate() error {
 		}
 	}
 
-	return nil
+	if err := c.validateEscalations(receivers); err != nil {
+		return err
+	}
+	if err := c.validateEnrichments(receivers); err != nil {
+		return err
+	}
+	if err := c.validatePolicyOwners(); err != nil {
+		return err
+	}
+	return c.validateResolvedNotifications()
 }
 
 // Config is the top-level configuration for Alertmanager's config files.
@@ -561,6 +615,175 @@ type Config struct {
 	Route        *config.Route         `yaml:"route,omitempty" json:"route,omitempty"`
 	InhibitRules []*config.InhibitRule `yaml:"inhibit_rules,omitempty" json:"inhibit_rules,omitempty"`
 	Templates    []string              `yaml:"templates" json:"templates"`
+	// Escalations re-notify other receivers of the alert groups that remain firing and unacknowledged.
+	Escalations []*EscalationChain `yaml:"escalations,omitempty" json:"escalations,omitempty"`
+	// Enrichments add the annotations returned by HTTP services to the notifications of the receivers.
+	Enrichments []*EnrichmentConfig `yaml:"enrichments,omitempty" json:"enrichments,omitempty"`
+	// PolicyOwners delegate routes of the notification policy tree to teams.
+	PolicyOwners []*PolicyOwner `yaml:"policy_owners,omitempty" json:"policy_owners,omitempty"`
+	// ResolvedNotifications change the resolved notifications of routes of the notification policy tree.
+	ResolvedNotifications []*ResolvedNotificationPolicy `yaml:"resolved_notifications,omitempty" json:"resolved_notifications,omitempty"`
+}
+
+// EscalationChain applies to the alert groups notified to Receiver.
+type EscalationChain struct {
+	Receiver string           `yaml:"receiver" json:"receiver"`
+	Steps    []EscalationStep `yaml:"steps" json:"steps"`
+}
+
+// EscalationStep notifies Receiver once the alert group has been firing and unacknowledged
+// for Wait since the previous step.
+type EscalationStep struct {
+	Receiver string         `yaml:"receiver" json:"receiver"`
+	Wait     model.Duration `yaml:"wait" json:"wait"`
+}
+
+// PolicyOwner lets the members of the team TeamID with the permission to edit the notification policies of their
+// teams change the route RouteID of the notification policy tree and its children. RouteID is the ID of the route
+// in the provisioning API.
+type PolicyOwner struct {
+	RouteID string `yaml:"route_id" json:"route_id"`
+	TeamID  int64  `yaml:"team_id" json:"team_id"`
+}
+
+// ResolvedNotificationSettings change the notifications of the alerts that are resolved.
+type ResolvedNotificationSettings struct {
+	// Suppress drops the resolved alerts from the notifications, the receivers only get the firing alerts.
+	Suppress bool `yaml:"suppress,omitempty" json:"suppress,omitempty"`
+	// Delay keeps the resolved alerts firing for at least Delay, an alert firing again meanwhile is not notified
+	// as resolved then firing.
+	Delay model.Duration `yaml:"delay,omitempty" json:"delay,omitempty"`
+}
+
+// IsZero returns whether the settings leave the resolved notifications unchanged.
+func (s ResolvedNotificationSettings) IsZero() bool {
+	return !s.Suppress && s.Delay == 0
+}
+
+// Validate ensures that the resolved notifications are either suppressed or delayed.
+func (s ResolvedNotificationSettings) Validate() error {
+	if s.Delay < 0 {
+		return fmt.Errorf("the delay of the resolved notifications must be positive")
+	}
+	if s.Suppress && s.Delay > 0 {
+		return fmt.Errorf("the resolved notifications can't be both suppressed and delayed")
+	}
+	return nil
+}
+
+// ResolvedNotificationPolicy applies to the alert groups of the route RouteID of the notification policy tree and of
+// its children without their own policy. RouteID is the ID of the route in the provisioning API.
+type ResolvedNotificationPolicy struct {
+	RouteID                      string `yaml:"route_id" json:"route_id"`
+	ResolvedNotificationSettings `yaml:",inline"`
+}
+
+// EnrichmentConfig posts the labels of the alerts notified to Receivers, or to every receiver if empty, to URL and
+// merges the annotations it returns into the notifications. The annotations of the alerts are not overwritten.
+type EnrichmentConfig struct {
+	URL       string   `yaml:"url" json:"url"`
+	Receivers []string `yaml:"receivers,omitempty" json:"receivers,omitempty"`
+	// Timeout is how long the notifications wait for the service, they are sent without its
+	// annotations after it. Defaults to 2s.
+	Timeout model.Duration `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	// CacheTTL is how long the annotations returned for the labels of an alert are reused. Defaults to 5m.
+	CacheTTL model.Duration `yaml:"cache_ttl,omitempty" json:"cache_ttl,omitempty"`
+}
+
+// AppliesTo returns whether the enrichment applies to the notifications of the receiver.
+func (e *EnrichmentConfig) AppliesTo(receiver string) bool {
+	if len(e.Receivers) == 0 {
+		return true
+	}
+	for _, r := range e.Receivers {
+		if r == receiver {
+			return true
+		}
+	}
+	return false
+}
+
+// validateEnrichments ensures that the enrichments have an HTTP URL and reference known receivers. The host of the URL
+// must be in the whitelist of the data source proxy when there is one, so that the enrichments can't reach more hosts
+// than the data sources.
+func (c *Config) validateEnrichments(receivers map[string]struct{}) error {
+	for _, e := range c.Enrichments {
+		u, err := url.Parse(e.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("enrichment has an invalid URL (%s)", e.URL)
+		}
+		if len(setting.DataProxyWhiteList) > 0 && !setting.DataProxyWhiteList[u.Host] {
+			return fmt.Errorf("enrichment URL (%s) is not included in the data source proxy whitelist", e.URL)
+		}
+		for _, r := range e.Receivers {
+			if _, ok := receivers[r]; !ok {
+				return fmt.Errorf("enrichment for undefined receiver (%s)", r)
+			}
+		}
+		if e.Timeout < 0 || e.CacheTTL < 0 {
+			return fmt.Errorf("enrichment (%s) must have a positive timeout and cache TTL", e.URL)
+		}
+	}
+	return nil
+}
+
+// validatePolicyOwners ensures that the policy owners reference a route and a team once.
+func (c *Config) validatePolicyOwners() error {
+	owners := make(map[PolicyOwner]struct{}, len(c.PolicyOwners))
+	for _, o := range c.PolicyOwners {
+		if o.RouteID == "" || o.TeamID <= 0 {
+			return fmt.Errorf("policy owner must have a route ID and a team ID")
+		}
+		if _, ok := owners[*o]; ok {
+			return fmt.Errorf("duplicate policy owner (team %d) for route (%s)", o.TeamID, o.RouteID)
+		}
+		owners[*o] = struct{}{}
+	}
+	return nil
+}
+
+// validateResolvedNotifications ensures that the resolved notification policies are valid and reference a route once.
+func (c *Config) validateResolvedNotifications() error {
+	routes := make(map[string]struct{}, len(c.ResolvedNotifications))
+	for _, p := range c.ResolvedNotifications {
+		if p.RouteID == "" {
+			return fmt.Errorf("resolved notification policy must have a route ID")
+		}
+		if _, ok := routes[p.RouteID]; ok {
+			return fmt.Errorf("duplicate resolved notification policy for route (%s)", p.RouteID)
+		}
+		routes[p.RouteID] = struct{}{}
+		if err := p.Validate(); err != nil {
+			return fmt.Errorf("resolved notification policy for route (%s): %w", p.RouteID, err)
+		}
+	}
+	return nil
+}
+
+// validateEscalations ensures that the escalation chains reference known receivers.
+func (c *Config) validateEscalations(receivers map[string]struct{}) error {
+	chains := make(map[string]struct{}, len(c.Escalations))
+	for _, e := range c.Escalations {
+		if _, ok := receivers[e.Receiver]; !ok {
+			return fmt.Errorf("escalation chain for undefined receiver (%s)", e.Receiver)
+		}
+		if _, ok := chains[e.Receiver]; ok {
+			return fmt.Errorf("duplicate escalation chain for receiver (%s)", e.Receiver)
+		}
+		chains[e.Receiver] = struct{}{}
+		if len(e.Steps) == 0 {
+			return fmt.Errorf("escalation chain for receiver (%s) has no steps", e.Receiver)
+		}
+		for _, step := range e.Steps {
+			if _, ok := receivers[step.Receiver]; !ok {
+				return fmt.Errorf("escalation step to undefined receiver (%s)", step.Receiver)
+			}
+			if step.Wait <= 0 {
+				return fmt.Errorf("escalation step to receiver (%s) must have a positive wait", step.Receiver)
+			}
+		}
+	}
+	return nil
 }
 
 // Config is the entrypoint for the embedded Alertmanager config with the exception of receivers.
@@ -682,7 +905,16 @@ func (c *PostableApiAlertingConfig) validate() error {
 		}
 	}
 
-	return nil
+	if err := c.validateEscalations(receivers); err != nil {
+		return err
+	}
+	if err := c.validateEnrichments(receivers); err != nil {
+		return err
+	}
+	if err := c.validatePolicyOwners(); err != nil {
+		return err
+	}
+	return c.validateResolvedNotifications()
 }
 
 // Type requires validate has been called and just checks the first receiver type
@@ -717,6 +949,57 @@ func AllReceivers(route *config.Route) (res []string) {
 	return res
 }
 
+// RouteRootID is the ID of the root of the notification policy tree.
+const RouteRootID = "root"
+
+// RouteIDs returns the IDs of the routes of the tree, the ones of the provisioning API. The ID of a route is
+// generated from its matchers and the ID of its parent, so it's kept by the changes of the other routes and of the
+// settings of the route, but not by the changes of its matchers or by its move.
+func RouteIDs(root *config.Route) map[*config.Route]string {
+	ids := make(map[*config.Route]string)
+	if root == nil {
+		return ids
+	}
+	var walk func(r *config.Route, id string)
+	walk = func(r *config.Route, id string) {
+		ids[r] = id
+		for i, childID := range ChildRouteIDs(r, id) {
+			walk(r.Routes[i], childID)
+		}
+	}
+	walk(root, RouteRootID)
+	return ids
+}
+
+// ChildRouteIDs returns the IDs of the children of the route with the ID, in order.
+func ChildRouteIDs(r *config.Route, id string) []string {
+	ids := make([]string, 0, len(r.Routes))
+	ordinals := make(map[string]int, len(r.Routes))
+	for _, child := range r.Routes {
+		key := routeMatchersKey(child)
+		ids = append(ids, childRouteID(id, key, ordinals[key]))
+		ordinals[key]++
+	}
+	return ids
+}
+
+// routeMatchersKey returns the matchers of the route as a string.
+func routeMatchersKey(r *config.Route) string {
+	b, _ := json.Marshal(struct {
+		Match    map[string]string   `json:"match,omitempty"`
+		MatchRE  config.MatchRegexps `json:"match_re,omitempty"`
+		Matchers config.Matchers     `json:"matchers,omitempty"`
+	}{r.Match, r.MatchRE, r.Matchers})
+	return string(b)
+}
+
+// childRouteID returns the ID of a child of the route parentID with the matchers, the ordinal distinguishes the
+// children with the same matchers.
+func childRouteID(parentID, matchers string, ordinal int) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\n%s\n%d", parentID, matchers, ordinal)))
+	return fmt.Sprintf("%x", sum[:8])
+}
+
 type GettableGrafanaReceiver struct {
 	UID                   string           `json:"uid"`
 	Name                  string           `json:"name"`
@@ -724,6 +1007,8 @@ type GettableGrafanaReceiver struct {
 	DisableResolveMessage bool             `json:"disableResolveMessage"`
 	Settings              *simplejson.Json `json:"settings"`
 	SecureFields          map[string]bool  `json:"secureFields"`
+	// Locale is the locale of the default templates of the notifications, English if empty.
+	Locale string `json:"locale,omitempty"`
 }
 
 type PostableGrafanaReceiver struct {
@@ -733,6 +1018,8 @@ type PostableGrafanaReceiver struct {
 	DisableResolveMessage bool              `json:"disableResolveMessage"`
 	Settings              *simplejson.Json  `json:"settings"`
 	SecureSettings        map[string]string `json:"secureSettings"`
+	// Locale is the locale of the default templates of the notifications, English if empty.
+	Locale string `json:"locale,omitempty"`
 }
 
 func (r *PostableGrafanaReceiver) GetDecryptedSecret(key string) (string, error) {
@@ -803,6 +1090,8 @@ func (r ReceiverType) MatchesBackend(backend Backend) error {
 type GettableApiReceiver struct {
 	config.Receiver          `yaml:",inline"`
 	GettableGrafanaReceivers `yaml:",inline"`
+	// readonly: true
+	Provenance models.Provenance `yaml:"provenance,omitempty" json:"provenance,omitempty"`
 }
 
 func (r *GettableApiReceiver) UnmarshalJSON(b []byte) error {
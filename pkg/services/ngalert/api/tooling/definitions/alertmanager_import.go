@@ -0,0 +1,54 @@
+package definitions
+
+// swagger:route POST /api/alertmanager/grafana/config/api/v1/import alertmanager_import RoutePostAlertmanagerImport
+//
+// Import the configuration of an external Prometheus Alertmanager, fetched from its status API or given as YAML.
+// Its integrations are converted to Grafana managed contact points, its routes and inhibition rules are kept, and
+// it replaces the Alertmanager configuration of the user's organization unless it is a dry run. The fields that
+// can't be imported are reported.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: AlertmanagerImportResult
+//       400: ValidationError
+//       409: Failure
+
+// swagger:parameters RoutePostAlertmanagerImport
+type AlertmanagerImportParams struct {
+	// in:body
+	Body PostableAlertmanagerImport
+}
+
+// swagger:model
+type PostableAlertmanagerImport struct {
+	// URL of the Alertmanager the configuration is fetched from, with its basic auth credentials if any. Its
+	// secrets are redacted by the Alertmanager, they must be set after the import.
+	URL string `json:"url,omitempty"`
+	// YAML configuration of the Alertmanager, imported instead of fetching it.
+	Configuration string `json:"configuration,omitempty"`
+	// Content of the template files by file name, they are not part of the configuration.
+	TemplateFiles map[string]string `json:"template_files,omitempty"`
+	// Report what would be imported without saving the configuration.
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// swagger:model
+type AlertmanagerImportResult struct {
+	// Contact points of the imported configuration, with the types of their integrations.
+	ContactPoints []ImportedContactPoint `json:"contactPoints"`
+	// Fields that are not imported, and why.
+	Unsupported []string `json:"unsupported"`
+	// Whether the configuration was saved, false for a dry run.
+	Applied bool `json:"applied"`
+}
+
+// swagger:model
+type ImportedContactPoint struct {
+	Name         string   `json:"name"`
+	Integrations []string `json:"integrations"`
+}
@@ -11,6 +11,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
+
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 func Test_ApiReceiver_Marshaling(t *testing.T) {
@@ -670,3 +672,40 @@ func Test_Marshaling_Validation(t *testing.T) {
 	expected := []model.LabelName{"alertname"}
 	require.Equal(t, expected, tmp.AlertmanagerConfig.Config.Route.GroupBy)
 }
+
+func Test_PostableAlerts_Unmarshaling(t *testing.T) {
+	body := `[{"labels":{"alertname":"HighLatency","job":"api"},"annotations":{"summary":"latency is high"},"startsAt":"2021-10-01T10:00:00Z"},{"labels":{"alertname":"Down"}}]`
+
+	var alerts PostableAlerts
+	require.NoError(t, json.Unmarshal([]byte(body), &alerts))
+	require.Len(t, alerts.PostableAlerts, 2)
+	require.Equal(t, "HighLatency", alerts.PostableAlerts[0].Labels["alertname"])
+	require.Equal(t, "latency is high", alerts.PostableAlerts[0].Annotations["summary"])
+	require.Equal(t, "Down", alerts.PostableAlerts[1].Labels["alertname"])
+
+	b, err := json.Marshal(alerts)
+	require.NoError(t, err)
+	var roundtrip PostableAlerts
+	require.NoError(t, json.Unmarshal(b, &roundtrip))
+	require.Equal(t, alerts, roundtrip)
+}
+
+func Test_EnrichmentsValidation(t *testing.T) {
+	receivers := map[string]struct{}{"team": {}}
+	cfg := func(url string) *Config {
+		return &Config{Enrichments: []*EnrichmentConfig{{URL: url, Receivers: []string{"team"}}}}
+	}
+
+	require.NoError(t, cfg("http://enrichment:8080/annotations").validateEnrichments(receivers))
+	require.Error(t, cfg("ftp://enrichment/annotations").validateEnrichments(receivers))
+	require.Error(t, (&Config{Enrichments: []*EnrichmentConfig{{URL: "http://enrichment:8080", Receivers: []string{"other"}}}}).validateEnrichments(receivers))
+
+	t.Run("the host of the URL must be in the whitelist of the data source proxy", func(t *testing.T) {
+		t.Cleanup(func() { setting.DataProxyWhiteList = nil })
+		setting.DataProxyWhiteList = map[string]bool{"enrichment:8080": true}
+
+		require.NoError(t, cfg("http://enrichment:8080/annotations").validateEnrichments(receivers))
+		err := cfg("http://169.254.169.254/latest/meta-data").validateEnrichments(receivers)
+		require.EqualError(t, err, "enrichment URL (http://169.254.169.254/latest/meta-data) is not included in the data source proxy whitelist")
+	})
+}
@@ -11,13 +11,15 @@ import (
 
 // swagger:route Get /api/ruler/{Recipient}/api/v1/rules ruler RouteGetRulesConfig
 //
-// List rule groups
+// List rule groups. The rule groups can be paginated, and the total number of rule groups is returned in the
+// X-Grafana-Rule-Groups-Total header.
 //
 //     Produces:
 //     - application/json
 //
 //     Responses:
 //       202: NamespaceConfigResponse
+//       400: ValidationError
 
 // swagger:route POST /api/ruler/{Recipient}/api/v1/rules/{Namespace} ruler RoutePostNameRulesConfig
 //
@@ -29,6 +31,7 @@ import (
 //
 //     Responses:
 //       202: Ack
+//       409: Failure
 
 // swagger:route Get /api/ruler/{Recipient}/api/v1/rules/{Namespace} ruler RouteGetNamespaceRulesConfig
 //
@@ -99,6 +102,9 @@ type PostableRuleGroupConfig struct {
 	Name     string                     `yaml:"name" json:"name"`
 	Interval model.Duration             `yaml:"interval,omitempty" json:"interval,omitempty"`
 	Rules    []PostableExtendedRuleNode `yaml:"rules" json:"rules"`
+	// Concurrency is the maximum number of Grafana managed rules of the group evaluated at the same time, so that a
+	// group querying a rate-limited data source throttles itself without delaying the other groups. 0 is no limit.
+	Concurrency int64 `yaml:"concurrency,omitempty" json:"concurrency,omitempty"`
 }
 
 func (c *PostableRuleGroupConfig) UnmarshalJSON(b []byte) error {
@@ -145,6 +151,8 @@ type GettableRuleGroupConfig struct {
 	Name     string                     `yaml:"name" json:"name"`
 	Interval model.Duration             `yaml:"interval,omitempty" json:"interval,omitempty"`
 	Rules    []GettableExtendedRuleNode `yaml:"rules" json:"rules"`
+	// Concurrency is the maximum number of Grafana managed rules of the group evaluated at the same time, 0 is no limit.
+	Concurrency int64 `yaml:"concurrency,omitempty" json:"concurrency,omitempty"`
 }
 
 func (c *GettableRuleGroupConfig) UnmarshalJSON(b []byte) error {
@@ -299,22 +307,61 @@ type PostableGrafanaRule struct {
 	UID          string              `json:"uid" yaml:"uid"`
 	NoDataState  NoDataState         `json:"no_data_state" yaml:"no_data_state"`
 	ExecErrState ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
+	// Version is the version of the rule the update is made from, as returned by the API. The update is rejected
+	// with a 409 if the rule has been changed since. It is not checked when missing.
+	Version int64 `json:"version,omitempty" yaml:"version,omitempty"`
+	// IsPaused pauses the evaluation of the rule. The rule stays paused or not when missing.
+	IsPaused *bool `json:"is_paused,omitempty" yaml:"is_paused,omitempty"`
+	// Composite makes the rule a composite rule, evaluated over the states of other rules. Composite rules have no
+	// condition and data.
+	Composite *models.CompositeCondition `json:"composite,omitempty" yaml:"composite,omitempty"`
+	// Thresholds make the rule a multi-threshold rule, its alerts are labeled with the most severe threshold their
+	// value passes.
+	Thresholds *models.Thresholds `json:"thresholds,omitempty" yaml:"thresholds,omitempty"`
+	// Heartbeat makes the rule a heartbeat rule, which fires when its heartbeat URL has not been requested for the
+	// timeout. Heartbeat rules have no condition and data, and the token of their URL is generated.
+	Heartbeat *models.HeartbeatCondition `json:"heartbeat,omitempty" yaml:"heartbeat,omitempty"`
+	// Links are the links of the rule to its runbook and dashboard panel, rendered as buttons in the notifications.
+	// The dashboard and the panel must exist.
+	Links *models.RuleLinks `json:"links,omitempty" yaml:"links,omitempty"`
+	// ShadowOf makes the rule a shadow of the rule with the UID, in the same organization. Shadow rules are
+	// evaluated like other rules, but their alerts are not notified. The rule stops being a shadow when missing.
+	ShadowOf string `json:"shadow_of,omitempty" yaml:"shadow_of,omitempty"`
+	// ActiveFrom delays the evaluation of the rule until the time, such as a launch.
+	ActiveFrom *time.Time `json:"active_from,omitempty" yaml:"active_from,omitempty"`
+	// ActiveUntil stops the evaluation of the rule at the time, after ActiveFrom.
+	ActiveUntil *time.Time `json:"active_until,omitempty" yaml:"active_until,omitempty"`
+	// HoldOnDatasourceMaintenance skips the evaluation of the rule during the maintenance windows of the datasources
+	// of its queries, its alerts keep their state until the end of the windows.
+	HoldOnDatasourceMaintenance bool `json:"hold_on_datasource_maintenance,omitempty" yaml:"hold_on_datasource_maintenance,omitempty"`
 }
 
 // swagger:model
 type GettableGrafanaRule struct {
-	ID              int64               `json:"id" yaml:"id"`
-	OrgID           int64               `json:"orgId" yaml:"orgId"`
-	Title           string              `json:"title" yaml:"title"`
-	Condition       string              `json:"condition" yaml:"condition"`
-	Data            []models.AlertQuery `json:"data" yaml:"data"`
-	Updated         time.Time           `json:"updated" yaml:"updated"`
-	IntervalSeconds int64               `json:"intervalSeconds" yaml:"intervalSeconds"`
-	Version         int64               `json:"version" yaml:"version"`
-	UID             string              `json:"uid" yaml:"uid"`
-	NamespaceUID    string              `json:"namespace_uid" yaml:"namespace_uid"`
-	NamespaceID     int64               `json:"namespace_id" yaml:"namespace_id"`
-	RuleGroup       string              `json:"rule_group" yaml:"rule_group"`
-	NoDataState     NoDataState         `json:"no_data_state" yaml:"no_data_state"`
-	ExecErrState    ExecutionErrorState `json:"exec_err_state" yaml:"exec_err_state"`
+	ID              int64                      `json:"id" yaml:"id"`
+	OrgID           int64                      `json:"orgId" yaml:"orgId"`
+	Title           string                     `json:"title" yaml:"title"`
+	Condition       string                     `json:"condition" yaml:"condition"`
+	Data            []models.AlertQuery        `json:"data" yaml:"data"`
+	Updated         time.Time                  `json:"updated" yaml:"updated"`
+	IntervalSeconds int64                      `json:"intervalSeconds" yaml:"intervalSeconds"`
+	Version         int64                      `json:"version" yaml:"version"`
+	UID             string                     `json:"uid" yaml:"uid"`
+	NamespaceUID    string                     `json:"namespace_uid" yaml:"namespace_uid"`
+	NamespaceID     int64                      `json:"namespace_id" yaml:"namespace_id"`
+	RuleGroup       string                     `json:"rule_group" yaml:"rule_group"`
+	NoDataState     NoDataState                `json:"no_data_state" yaml:"no_data_state"`
+	ExecErrState    ExecutionErrorState        `json:"exec_err_state" yaml:"exec_err_state"`
+	IsPaused        bool                       `json:"is_paused" yaml:"is_paused"`
+	Composite       *models.CompositeCondition `json:"composite,omitempty" yaml:"composite,omitempty"`
+	Thresholds      *models.Thresholds         `json:"thresholds,omitempty" yaml:"thresholds,omitempty"`
+	Heartbeat       *models.HeartbeatCondition `json:"heartbeat,omitempty" yaml:"heartbeat,omitempty"`
+	Links           *models.RuleLinks          `json:"links,omitempty" yaml:"links,omitempty"`
+	ShadowOf        string                     `json:"shadow_of,omitempty" yaml:"shadow_of,omitempty"`
+	ActiveFrom      *time.Time                 `json:"active_from,omitempty" yaml:"active_from,omitempty"`
+	ActiveUntil     *time.Time                 `json:"active_until,omitempty" yaml:"active_until,omitempty"`
+
+	HoldOnDatasourceMaintenance bool `json:"hold_on_datasource_maintenance,omitempty" yaml:"hold_on_datasource_maintenance,omitempty"`
+	// readonly: true
+	Provenance models.Provenance `json:"provenance,omitempty" yaml:"provenance,omitempty"`
 }
@@ -0,0 +1,77 @@
+package definitions
+
+import (
+	"time"
+)
+
+// swagger:route GET /api/v1/ngalert/datasources/{DatasourceUID}/maintenance-windows datasource_maintenance RouteGetDatasourceMaintenanceWindows
+//
+// Get the maintenance windows of a datasource, by start.
+//
+//     Responses:
+//       200: DatasourceMaintenanceWindows
+//       404: Failure
+
+// swagger:route POST /api/v1/ngalert/datasources/{DatasourceUID}/maintenance-windows datasource_maintenance RoutePostDatasourceMaintenanceWindow
+//
+// Plan a maintenance window of a datasource. The alert rules holding during the maintenance of their datasources are
+// not evaluated in the window, and their alerts keep their state until it ends.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       201: DatasourceMaintenanceWindow
+//       400: ValidationError
+//       404: Failure
+
+// swagger:route DELETE /api/v1/ngalert/datasources/{DatasourceUID}/maintenance-windows/{WindowID} datasource_maintenance RouteDeleteDatasourceMaintenanceWindow
+//
+// Delete a maintenance window of a datasource, the rules depending on it are evaluated again if it was in effect.
+//
+//     Responses:
+//       200: Ack
+//       404: Failure
+
+// swagger:parameters RouteGetDatasourceMaintenanceWindows
+type DatasourceMaintenanceWindowsParams struct {
+	// in:path
+	DatasourceUID string
+}
+
+// swagger:parameters RoutePostDatasourceMaintenanceWindow
+type PostDatasourceMaintenanceWindowParams struct {
+	// in:path
+	DatasourceUID string
+	// in:body
+	Body PostableDatasourceMaintenanceWindow
+}
+
+// swagger:parameters RouteDeleteDatasourceMaintenanceWindow
+type DeleteDatasourceMaintenanceWindowParams struct {
+	// in:path
+	DatasourceUID string
+	// in:path
+	WindowID int64
+}
+
+// swagger:model
+type PostableDatasourceMaintenanceWindow struct {
+	Reason string `json:"reason"`
+	// StartsAt and EndsAt are the start and the end of the planned downtime of the datasource.
+	StartsAt time.Time `json:"startsAt"`
+	EndsAt   time.Time `json:"endsAt"`
+}
+
+// swagger:model
+type DatasourceMaintenanceWindow struct {
+	ID            int64     `json:"id"`
+	DatasourceUID string    `json:"datasourceUid"`
+	Reason        string    `json:"reason"`
+	CreatedBy     string    `json:"createdBy"`
+	StartsAt      time.Time `json:"startsAt"`
+	EndsAt        time.Time `json:"endsAt"`
+}
+
+// swagger:model
+type DatasourceMaintenanceWindows []DatasourceMaintenanceWindow
@@ -0,0 +1,54 @@
+package definitions
+
+import (
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// swagger:route GET /api/alertmanager/grafana/api/v1/escalations escalations RouteGetEscalations
+//
+// Get the escalation state of the firing alert groups notified to a receiver with an escalation chain.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableEscalations
+
+// swagger:route POST /api/alertmanager/grafana/api/v1/escalation/{EscalationID}/ack escalations RouteAcknowledgeEscalation
+//
+// Acknowledge an escalated alert group, its next escalation steps are not notified.
+//
+//     Responses:
+//       200: Ack
+//       404: Failure
+
+// swagger:parameters RouteAcknowledgeEscalation
+type AcknowledgeEscalationParams struct {
+	// in:path
+	EscalationID string
+}
+
+// swagger:model
+type GettableEscalation struct {
+	ID          string         `json:"id"`
+	GroupKey    string         `json:"groupKey"`
+	GroupLabels model.LabelSet `json:"groupLabels"`
+	// Receiver the alert group is routed to, its escalation chain is defined in the Alertmanager configuration.
+	Receiver string `json:"receiver"`
+	// Number of firing alerts in the group.
+	Alerts    int       `json:"alerts"`
+	StartedAt time.Time `json:"startedAt"`
+	// Number of escalation steps already notified.
+	Step int `json:"step"`
+	// Receiver of the next escalation step, empty if every step was notified.
+	NextReceiver string `json:"nextReceiver,omitempty"`
+	// When the next escalation step is notified unless the alert group is acknowledged or resolved.
+	NextEscalationAt *time.Time `json:"nextEscalationAt,omitempty"`
+	AcknowledgedBy   string     `json:"acknowledgedBy,omitempty"`
+	AcknowledgedAt   *time.Time `json:"acknowledgedAt,omitempty"`
+}
+
+// swagger:model
+type GettableEscalations []GettableEscalation
@@ -0,0 +1,73 @@
+package definitions
+
+import (
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// swagger:route GET /api/ruler/grafana/api/v1/folder/{FolderUID}/defaults folder_defaults RouteGetFolderDefaults
+//
+// Get the defaults the alert rules of a folder inherit.
+//
+//     Responses:
+//       200: FolderDefaults
+//       404: Failure
+
+// swagger:route PUT /api/ruler/grafana/api/v1/folder/{FolderUID}/defaults folder_defaults RoutePutFolderDefaults
+//
+// Set the defaults the alert rules of a folder inherit unless they override them. The defaults apply to the existing
+// rules of the folder, which are saved with their own settings only.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       202: FolderDefaults
+//       400: ValidationError
+//       404: Failure
+
+// swagger:route DELETE /api/ruler/grafana/api/v1/folder/{FolderUID}/defaults folder_defaults RouteDeleteFolderDefaults
+//
+// Delete the defaults of a folder. The settings the rules of the folder inherited are then the defaults of the rules.
+//
+//     Responses:
+//       202: Ack
+//       404: Failure
+
+// swagger:parameters RouteGetFolderDefaults RouteDeleteFolderDefaults
+type FolderDefaultsParams struct {
+	// in:path
+	FolderUID string
+}
+
+// swagger:parameters RoutePutFolderDefaults
+type PutFolderDefaultsParams struct {
+	// in:path
+	FolderUID string
+	// in:body
+	Body PostableFolderDefaults
+}
+
+// swagger:model
+type PostableFolderDefaults struct {
+	// Interval is the interval of the rule groups without one.
+	Interval model.Duration `json:"interval,omitempty"`
+	// NoDataState is the state of the rules without one.
+	NoDataState NoDataState `json:"no_data_state,omitempty"`
+	// ExecErrState is the state of the rules without one.
+	ExecErrState ExecutionErrorState `json:"exec_err_state,omitempty"`
+	// Labels are added to the labels of the rules, a label of a rule overrides the folder label with the same name.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// swagger:model
+type FolderDefaults struct {
+	FolderUID    string              `json:"folder_uid"`
+	Interval     model.Duration      `json:"interval,omitempty"`
+	NoDataState  NoDataState         `json:"no_data_state,omitempty"`
+	ExecErrState ExecutionErrorState `json:"exec_err_state,omitempty"`
+	Labels       map[string]string   `json:"labels,omitempty"`
+	UpdatedBy    string              `json:"updated_by"`
+	Updated      time.Time           `json:"updated"`
+}
@@ -0,0 +1,20 @@
+package definitions
+
+// swagger:route POST /api/v1/ngalert/heartbeat/{Token} heartbeat RoutePostHeartbeat
+//
+// Record a heartbeat of the heartbeat rule with the token. The request needs no authentication, the token is the
+// secret, and its body is ignored so that it can be the webhook of the Watchdog alert of an external Alertmanager.
+//
+//     Responses:
+//       200: Ack
+//       404: Failure
+//
+//     Extensions:
+//       x-unauthenticated: true
+
+// swagger:parameters RoutePostHeartbeat
+type HeartbeatParams struct {
+	// Token is the token of the heartbeat rule.
+	// in:path
+	Token string
+}
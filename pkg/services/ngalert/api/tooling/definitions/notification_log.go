@@ -0,0 +1,61 @@
+package definitions
+
+import (
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// swagger:route GET /api/alertmanager/grafana/api/v1/notification-log notification_log RouteGetNotificationLog
+//
+// Get when the alert groups were last notified and to which receivers, from the notification log of the Grafana
+// Alertmanager.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableNotificationLog
+//       400: ValidationError
+
+// swagger:parameters RouteGetNotificationLog
+type NotificationLogParams struct {
+	// A list of matchers to filter the alert groups by their group labels
+	// in: query
+	// required: false
+	Matchers []string `json:"filter"`
+
+	// A regex matching receivers to filter the notifications by
+	// in: query
+	// required: false
+	Receivers string `json:"receiver"`
+}
+
+// swagger:model
+type GettableNotificationLog []GettableNotificationLogGroup
+
+// swagger:model
+type GettableNotificationLogGroup struct {
+	GroupKey    string         `json:"groupKey"`
+	GroupLabels model.LabelSet `json:"groupLabels"`
+	// When the alert group was last notified, to any receiver.
+	LastNotifiedAt time.Time `json:"lastNotifiedAt"`
+	// The last notification to each integration of the receivers, the most recent first.
+	Notifications []GettableNotificationLogEntry `json:"notifications"`
+}
+
+// swagger:model
+type GettableNotificationLogEntry struct {
+	Receiver string `json:"receiver"`
+	// Name of the integration of the receiver, such as email or slack.
+	Integration string `json:"integration"`
+	// Index of the integration in the receiver.
+	Index      int       `json:"index"`
+	NotifiedAt time.Time `json:"notifiedAt"`
+	// Number of firing alerts of the group at the notification.
+	FiringAlerts int `json:"firingAlerts"`
+	// Number of resolved alerts of the group at the notification.
+	ResolvedAlerts int `json:"resolvedAlerts"`
+	// When the notification is removed from the notification log.
+	ExpiresAt time.Time `json:"expiresAt"`
+}
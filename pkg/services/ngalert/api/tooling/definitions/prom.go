@@ -46,10 +46,39 @@ type DiscoveryBase struct {
 	Error string `json:"error,omitempty"`
 }
 
+// swagger:parameters RouteGetRulesConfig RouteGetRuleStatuses
+type RuleListParams struct {
+	// Limit is the number of rule groups to list, all of them when it's missing.
+	// in:query
+	Limit int `json:"limit"`
+	// in:query
+	Offset int `json:"offset"`
+	// in:query
+	FolderUID []string `json:"folder_uid"`
+	// DatasourceUID lists the rules that query the data source.
+	// in:query
+	DatasourceUID string `json:"datasource_uid"`
+	// State is one of firing, pending, inactive, error or nodata.
+	// in:query
+	State []string `json:"state"`
+	// ExcludeQueries leaves out the queries of the rules.
+	// in:query
+	ExcludeQueries bool `json:"exclude_queries"`
+}
+
+// swagger:parameters RouteGetRuleStatuses
+type RuleStatusesParams struct {
+	// ExcludeAlerts leaves out the alerts of the rules.
+	// in:query
+	ExcludeAlerts bool `json:"exclude_alerts"`
+}
+
 // swagger:model
 type RuleDiscovery struct {
 	// required: true
 	RuleGroups []*RuleGroup `json:"groups"`
+	// Total is the number of rule groups on all the pages, when the rule groups are paginated.
+	Total int `json:"totalGroups,omitempty"`
 }
 
 // AlertDiscovery has info for all active alerts.
@@ -105,6 +134,8 @@ type Rule struct {
 	// required: true
 	Health    string `json:"health"`
 	LastError string `json:"lastError"`
+	// Warnings of the last evaluation, such as the truncation of query results over the result limits.
+	Warnings []string `json:"warnings,omitempty"`
 	// required: true
 	Type           v1.RuleType `json:"type"`
 	LastEvaluation time.Time   `json:"lastEvaluation"`
@@ -0,0 +1,117 @@
+package definitions
+
+import (
+	"time"
+)
+
+// swagger:route GET /api/prometheus/grafana/compat/api/v1/rules prometheus_compat RouteGetPrometheusCompatRules
+//
+// Get the evaluation statuses of the Grafana managed rules in the folders the user can view, in the schema of the
+// Prometheus HTTP API, for the tools reading the rules of Prometheus.
+//
+//     Responses:
+//       200: PrometheusCompatRuleResponse
+//       400: PrometheusCompatRuleResponse
+
+// swagger:route GET /api/prometheus/grafana/compat/api/v1/alerts prometheus_compat RouteGetPrometheusCompatAlerts
+//
+// Get the pending and firing alerts of the Grafana managed rules, in the schema of the Prometheus HTTP API, for the
+// tools reading the alerts of Prometheus.
+//
+//     Responses:
+//       200: PrometheusCompatAlertResponse
+
+// swagger:parameters RouteGetPrometheusCompatRules
+type PrometheusCompatRuleParams struct {
+	// Type is alert or record, the rules are of the type. The Grafana managed rules are all alerting rules.
+	// in:query
+	Type string `json:"type"`
+}
+
+// swagger:model
+type PrometheusCompatRuleResponse struct {
+	// in: body
+	DiscoveryBase
+	// in: body
+	Data PrometheusCompatRuleDiscovery `json:"data"`
+}
+
+// swagger:model
+type PrometheusCompatRuleDiscovery struct {
+	// required: true
+	RuleGroups []PrometheusCompatRuleGroup `json:"groups"`
+}
+
+// swagger:model
+type PrometheusCompatRuleGroup struct {
+	// required: true
+	Name string `json:"name"`
+	// File is the title of the folder of the rule group.
+	// required: true
+	File string `json:"file"`
+	// required: true
+	Rules []PrometheusCompatAlertingRule `json:"rules"`
+	// required: true
+	Interval       float64   `json:"interval"`
+	EvaluationTime float64   `json:"evaluationTime"`
+	LastEvaluation time.Time `json:"lastEvaluation"`
+}
+
+// swagger:model
+type PrometheusCompatAlertingRule struct {
+	// State is pending, firing or inactive.
+	// required: true
+	State string `json:"state"`
+	// required: true
+	Name string `json:"name"`
+	// Query is the PromQL expression of the rules created from Prometheus alerting rules, and the queries of the
+	// rule encoded in JSON otherwise.
+	// required: true
+	Query string `json:"query"`
+	// required: true
+	Duration float64 `json:"duration"`
+	// required: true
+	Labels labels `json:"labels"`
+	// required: true
+	Annotations labels `json:"annotations"`
+	// required: true
+	Alerts []PrometheusCompatAlert `json:"alerts"`
+	// Health is ok, err or unknown.
+	// required: true
+	Health         string    `json:"health"`
+	LastError      string    `json:"lastError,omitempty"`
+	EvaluationTime float64   `json:"evaluationTime"`
+	LastEvaluation time.Time `json:"lastEvaluation"`
+	// Type is always alerting.
+	// required: true
+	Type string `json:"type"`
+}
+
+// swagger:model
+type PrometheusCompatAlertResponse struct {
+	// in: body
+	DiscoveryBase
+	// in: body
+	Data PrometheusCompatAlertDiscovery `json:"data"`
+}
+
+// swagger:model
+type PrometheusCompatAlertDiscovery struct {
+	// required: true
+	Alerts []PrometheusCompatAlert `json:"alerts"`
+}
+
+// swagger:model
+type PrometheusCompatAlert struct {
+	// required: true
+	Labels labels `json:"labels"`
+	// required: true
+	Annotations labels `json:"annotations"`
+	// State is pending or firing.
+	// required: true
+	State    string     `json:"state"`
+	ActiveAt *time.Time `json:"activeAt,omitempty"`
+	// Value is the value of the condition of the rule, in the format of Prometheus.
+	// required: true
+	Value string `json:"value"`
+}
@@ -0,0 +1,197 @@
+package definitions
+
+import (
+	"github.com/prometheus/common/model"
+)
+
+// swagger:route Get /api/ruler/grafana/prometheus/api/v1/rules prometheus_ruler RouteGetPrometheusRules
+//
+// List the Grafana managed rule groups that can be represented as Prometheus alerting rules.
+// Together with the routes below this implements the Cortex ruler API, so cortextool and mimirtool
+// can manage Grafana managed rules with the address http(s)://<grafana>/api/ruler/grafana/prometheus.
+//
+//     Produces:
+//     - application/yaml
+//
+//     Responses:
+//       200: PrometheusNamespaceConfigResponse
+
+// swagger:route POST /api/ruler/grafana/prometheus/api/v1/rules/{Namespace} prometheus_ruler RoutePostPrometheusRuleGroup
+//
+// Creates or updates a rule group from Prometheus alerting rules. The namespace is the title of an existing folder.
+// The rules query the Prometheus datasource of the X-Grafana-Alerting-Datasource-UID header, or the default
+// datasource of the organization when the header is missing.
+//
+//     Consumes:
+//     - application/yaml
+//
+//     Responses:
+//       202: Ack
+//       400: ValidationError
+//
+//     Extensions:
+//       x-raw-body: true
+
+// swagger:route Get /api/ruler/grafana/prometheus/api/v1/rules/{Namespace} prometheus_ruler RouteGetPrometheusNamespaceRules
+//
+// Get the rule groups of a namespace that can be represented as Prometheus alerting rules.
+//
+//     Produces:
+//     - application/yaml
+//
+//     Responses:
+//       200: PrometheusNamespaceConfigResponse
+
+// swagger:route Delete /api/ruler/grafana/prometheus/api/v1/rules/{Namespace} prometheus_ruler RouteDeletePrometheusNamespaceRules
+//
+// Delete the rule groups of a namespace that can be represented as Prometheus alerting rules.
+//
+//     Responses:
+//       202: Ack
+
+// swagger:route Get /api/ruler/grafana/prometheus/api/v1/rules/{Namespace}/{Groupname} prometheus_ruler RouteGetPrometheusRuleGroup
+//
+// Get a rule group as Prometheus alerting rules.
+//
+//     Produces:
+//     - application/yaml
+//
+//     Responses:
+//       200: PrometheusRuleGroup
+//       400: ValidationError
+
+// swagger:route Delete /api/ruler/grafana/prometheus/api/v1/rules/{Namespace}/{Groupname} prometheus_ruler RouteDeletePrometheusRuleGroup
+//
+// Delete rule group
+//
+//     Responses:
+//       202: Ack
+
+// swagger:route Get /api/ruler/grafana/api/v1/export/prometheus prometheus_ruler RouteGetPrometheusExport
+//
+// Export the Grafana managed rules as Prometheus alerting rules, with a rule file per namespace. The rules that
+// can't be written in PromQL are left out and listed in the comments, with the Grafana features lost in the export.
+//
+//     Produces:
+//     - application/yaml
+//
+//     Responses:
+//       200: PrometheusRulesExport
+
+// swagger:route Get /api/ruler/grafana/api/v1/export/prometheus/{Namespace} prometheus_ruler RouteGetPrometheusNamespaceExport
+//
+// Export the rules of a namespace as a Prometheus rule file.
+//
+//     Produces:
+//     - application/yaml
+//
+//     Responses:
+//       200: PrometheusRuleFile
+
+// swagger:route Get /api/ruler/grafana/api/v1/export/prometheus/{Namespace}/{Groupname} prometheus_ruler RouteGetPrometheusRuleGroupExport
+//
+// Export a rule group as a Prometheus rule file.
+//
+//     Produces:
+//     - application/yaml
+//
+//     Responses:
+//       200: PrometheusRuleFile
+//       404: Failure
+
+// swagger:route POST /api/ruler/grafana/api/v1/import/prometheus/{Namespace} prometheus_ruler RoutePostPrometheusImport
+//
+// Import a Prometheus rule file in a namespace. Each alerting rule is converted to a Grafana managed rule querying
+// the Prometheus datasource of the X-Grafana-Alerting-Datasource-UID header, or the default datasource of the
+// organization, and each group replaces the rule group with the same name. The groups are imported in a single
+// transaction. With dryRun the changes are returned without being applied.
+//
+//     Consumes:
+//     - application/yaml
+//
+//     Responses:
+//       200: PrometheusImportResult
+//       202: PrometheusImportResult
+//       400: ValidationError
+//       409: Failure
+//
+//     Extensions:
+//       x-raw-body: true
+
+// DatasourceUIDHeader is the header selecting the datasource queried by the rules created from Prometheus alerting rules.
+const DatasourceUIDHeader = "X-Grafana-Alerting-Datasource-UID"
+
+// swagger:parameters RoutePostPrometheusRuleGroup
+type PrometheusNamespaceConfig struct {
+	// in:path
+	Namespace string
+	// in:header
+	DatasourceUID string `json:"X-Grafana-Alerting-Datasource-UID"`
+	// in:body
+	Body PrometheusRuleGroup
+}
+
+// swagger:parameters RoutePostPrometheusImport
+type PrometheusImportParams struct {
+	// in:path
+	Namespace string
+	// in:header
+	DatasourceUID string `json:"X-Grafana-Alerting-Datasource-UID"`
+	// Return the changes without applying them.
+	// in:query
+	// required:false
+	DryRun bool `json:"dryRun"`
+	// in:body
+	Body PrometheusRuleFile
+}
+
+// swagger:parameters RouteGetPrometheusNamespaceRules RouteDeletePrometheusNamespaceRules RouteGetPrometheusNamespaceExport
+type PathPrometheusNamespaceConfig struct {
+	// in: path
+	Namespace string
+}
+
+// swagger:parameters RouteGetPrometheusRuleGroup RouteDeletePrometheusRuleGroup RouteGetPrometheusRuleGroupExport
+type PathPrometheusRuleGroupConfig struct {
+	// in: path
+	Namespace string
+	// in: path
+	Groupname string
+}
+
+// PrometheusRuleGroup is a rule group in the format of Prometheus rule files.
+// swagger:model
+type PrometheusRuleGroup struct {
+	Name     string         `yaml:"name" json:"name"`
+	Interval model.Duration `yaml:"interval,omitempty" json:"interval,omitempty"`
+	Rules    []ApiRuleNode  `yaml:"rules" json:"rules"`
+}
+
+// PrometheusNamespaceConfigResponse is the rule groups per namespace, in the format of the Cortex ruler API.
+// swagger:model
+type PrometheusNamespaceConfigResponse map[string][]PrometheusRuleGroup
+
+// PrometheusRuleFile is a Prometheus rule file.
+// swagger:model
+type PrometheusRuleFile struct {
+	Groups []PrometheusRuleGroup `yaml:"groups" json:"groups"`
+}
+
+// PrometheusRulesExport is the Prometheus rule file of each namespace.
+// swagger:model
+type PrometheusRulesExport map[string]PrometheusRuleFile
+
+// PrometheusImportResult is the changes made to the rule groups by the import of a Prometheus rule file.
+// swagger:model
+type PrometheusImportResult struct {
+	DryRun bool                          `json:"dryRun"`
+	Groups []PrometheusImportGroupResult `json:"groups"`
+}
+
+// PrometheusImportGroupResult is the titles of the rules created, updated and deleted in a rule group.
+type PrometheusImportGroupResult struct {
+	Name    string   `json:"name"`
+	Created []string `json:"created,omitempty"`
+	Updated []string `json:"updated,omitempty"`
+	Deleted []string `json:"deleted,omitempty"`
+}
@@ -0,0 +1,670 @@
+package definitions
+
+import (
+	"time"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// swagger:route GET /api/v1/provisioning/alert-rules/{UID} provisioning RouteGetAlertRule
+//
+// Get a Grafana managed alert rule by UID.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: ProvisionedAlertRule
+//       404: Failure
+
+// swagger:route POST /api/v1/provisioning/alert-rules provisioning RoutePostAlertRule
+//
+// Create a Grafana managed alert rule. A UID is generated if the rule has none.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       201: ProvisionedAlertRule
+//       400: ValidationError
+//       409: Failure
+
+// swagger:route PUT /api/v1/provisioning/alert-rules/{UID} provisioning RoutePutAlertRule
+//
+// Create the alert rule with the UID, or update it. Sending the same rule again doesn't change it.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: ProvisionedAlertRule
+//       400: ValidationError
+//       409: Failure
+
+// swagger:route DELETE /api/v1/provisioning/alert-rules/{UID} provisioning RouteDeleteAlertRule
+//
+// Delete an alert rule. Deleting a rule that doesn't exist is not an error.
+//
+//     Responses:
+//       200: Ack
+//       409: Failure
+
+// swagger:route GET /api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group} provisioning RouteGetAlertRuleGroup
+//
+// Get a rule group.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: AlertRuleGroup
+//       404: Failure
+
+// swagger:route PUT /api/v1/provisioning/folder/{FolderUID}/rule-groups/{Group} provisioning RoutePutAlertRuleGroup
+//
+// Replace the interval and the rules of a rule group. The rules without a UID get a generated one, the rules of
+// the group that are not in the request are deleted.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: AlertRuleGroup
+//       400: ValidationError
+//       409: Failure
+
+// swagger:route GET /api/v1/provisioning/contact-points provisioning RouteGetContactPoints
+//
+// Get the contact points of the Grafana Alertmanager.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableContactPoints
+
+// swagger:route PUT /api/v1/provisioning/contact-points/{Name} provisioning RoutePutContactPoint
+//
+// Create the contact point with the name, or replace it. The secure settings missing from a receiver are kept
+// from the receiver with the same UID.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableContactPoint
+//       400: ValidationError
+//       409: Failure
+
+// swagger:route DELETE /api/v1/provisioning/contact-points/{Name} provisioning RouteDeleteContactPoint
+//
+// Delete a contact point. It must not be used by the notification policies or the escalation chains, the
+// conflict lists them.
+//
+//     Responses:
+//       200: Ack
+//       400: ValidationError
+//       409: Failure
+
+// swagger:route GET /api/v1/provisioning/contact-points/{Name}/usage provisioning RouteGetContactPointUsage
+//
+// Get the notification policies and escalation chains using a contact point, and the rules whose alerts are routed
+// to it by their labels.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: ContactPointUsage
+
+// swagger:route GET /api/v1/provisioning/policies provisioning RouteGetPolicyTree
+//
+// Get the notification policy tree.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: NotificationPolicyTree
+
+// swagger:route PUT /api/v1/provisioning/policies provisioning RoutePutPolicyTree
+//
+// Replace the notification policy tree.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: NotificationPolicyTree
+//       400: ValidationError
+//       409: Failure
+
+// swagger:route DELETE /api/v1/provisioning/policies provisioning RouteResetPolicyTree
+//
+// Reset the notification policy tree to the default one.
+//
+//     Responses:
+//       200: Ack
+//       409: Failure
+
+// swagger:route GET /api/v1/provisioning/policies/routes provisioning RouteGetPolicyRoutes
+//
+// Get the routes of the notification policy tree without their children, the parents before their children.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: ProvisionedRoutes
+
+// swagger:route GET /api/v1/provisioning/policies/routes/{RouteID} provisioning RouteGetPolicyRoute
+//
+// Get a route of the notification policy tree with its children.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: ProvisionedRoute
+//       404: Failure
+
+// swagger:route PUT /api/v1/provisioning/policies/routes/{RouteID} provisioning RoutePutPolicyRoute
+//
+// Replace a route of the notification policy tree. Its children are kept if the route has none. The ID of the
+// route changes with its matchers.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: ProvisionedRoute
+//       400: ValidationError
+//       404: Failure
+//       409: Failure
+
+// swagger:route DELETE /api/v1/provisioning/policies/routes/{RouteID} provisioning RouteDeletePolicyRoute
+//
+// Delete a route of the notification policy tree and its children. The root can't be deleted.
+//
+//     Responses:
+//       200: Ack
+//       400: ValidationError
+//       404: Failure
+//       409: Failure
+
+// swagger:route POST /api/v1/provisioning/policies/routes/{RouteID}/routes provisioning RoutePostPolicyRoute
+//
+// Create a route among the children of a route of the notification policy tree.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       201: ProvisionedRoute
+//       400: ValidationError
+//       404: Failure
+//       409: Failure
+
+// swagger:route POST /api/v1/provisioning/policies/routes/{RouteID}/move provisioning RouteMovePolicyRoute
+//
+// Move a route of the notification policy tree and its children to the children of another route. The ID of the
+// route changes with its parent.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: ProvisionedRoute
+//       400: ValidationError
+//       404: Failure
+//       409: Failure
+
+// swagger:route GET /api/v1/provisioning/mute-timings provisioning RouteGetMuteTimings
+//
+// Get the mute timings, the recurring silences muting the alerts at given times of the week.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: ProvisionedMuteTimings
+
+// swagger:route GET /api/v1/provisioning/mute-timings/{UID} provisioning RouteGetMuteTiming
+//
+// Get a mute timing by UID.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: ProvisionedMuteTiming
+//       404: Failure
+
+// swagger:route PUT /api/v1/provisioning/mute-timings/{UID} provisioning RoutePutMuteTiming
+//
+// Create the mute timing with the UID, or update it. The silence of the current occurrence is kept if the
+// recurrence is unchanged.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: ProvisionedMuteTiming
+//       400: ValidationError
+//       409: Failure
+
+// swagger:route DELETE /api/v1/provisioning/mute-timings/{UID} provisioning RouteDeleteMuteTiming
+//
+// Delete a mute timing and expire the silence of its current occurrence. Deleting a mute timing that doesn't
+// exist is not an error.
+//
+//     Responses:
+//       200: Ack
+//       409: Failure
+
+// swagger:route GET /api/v1/provisioning/mute-timings/{UID}/usage provisioning RouteGetMuteTimingUsage
+//
+// Get the rules whose alerts are muted by a mute timing, by their labels.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: MuteTimingUsage
+//       404: Failure
+
+// swagger:route GET /api/v1/provisioning/export provisioning RouteGetAlertingBundle
+//
+// Export the rules of folders, or a set of rules, as a self-contained bundle with their folders and the contact
+// points, mute timings and templates their notifications depend on, to import them in another Grafana instance.
+// The dependencies are found by the labels of the rules. The secure settings of the contact points are not
+// exported, they must be set after the import.
+//
+//     Produces:
+//     - application/json
+//     - application/yaml
+//
+//     Responses:
+//       200: AlertingBundle
+//       400: ValidationError
+//       404: Failure
+
+// swagger:route POST /api/v1/provisioning/import provisioning RoutePostAlertingBundle
+//
+// Import a bundle exported by RouteGetAlertingBundle, in JSON or YAML. The folders are resolved by UID then by
+// title, the contact points by name, the mute timings by UID and the templates by name, and they are created if
+// they don't exist. The existing dependencies are not changed. The rule groups of the bundle replace the rule groups
+// with the same name. With dryRun nothing is created and the resolution of the dependencies is returned.
+//
+//     Consumes:
+//     - application/json
+//     - application/yaml
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: AlertingBundleImportResult
+//       400: ValidationError
+//       409: Failure
+//
+//     Extensions:
+//       x-raw-body: true
+
+// swagger:parameters RouteGetAlertRule RouteDeleteAlertRule RouteGetMuteTiming RouteDeleteMuteTiming RouteGetMuteTimingUsage
+type ProvisioningUIDParams struct {
+	// in:path
+	UID string
+}
+
+// swagger:parameters RoutePostAlertRule
+type PostAlertRuleParams struct {
+	// in:body
+	Body ProvisionedAlertRule
+}
+
+// swagger:parameters RoutePutAlertRule
+type PutAlertRuleParams struct {
+	// in:path
+	UID string
+	// in:body
+	Body ProvisionedAlertRule
+}
+
+// swagger:parameters RouteGetAlertRuleGroup
+type RuleGroupPathParams struct {
+	// in:path
+	FolderUID string
+	// in:path
+	Group string
+}
+
+// swagger:parameters RoutePutAlertRuleGroup
+type PutAlertRuleGroupParams struct {
+	// in:path
+	FolderUID string
+	// in:path
+	Group string
+	// in:body
+	Body AlertRuleGroup
+}
+
+// swagger:parameters RouteDeleteContactPoint RouteGetContactPointUsage
+type ContactPointNameParams struct {
+	// in:path
+	Name string
+}
+
+// swagger:parameters RoutePutContactPoint
+type PutContactPointParams struct {
+	// in:path
+	Name string
+	// in:body
+	Body PostableContactPoint
+}
+
+// swagger:parameters RoutePutPolicyTree
+type PutPolicyTreeParams struct {
+	// in:body
+	Body NotificationPolicyTree
+}
+
+// swagger:parameters RouteGetPolicyRoute
+type PolicyRouteIDParams struct {
+	// in:path
+	RouteID string
+}
+
+// swagger:parameters RoutePutPolicyRoute RoutePostPolicyRoute
+type PostPolicyRouteParams struct {
+	// in:path
+	RouteID string
+	// in:body
+	Body PostableRoute
+}
+
+// swagger:parameters RouteDeletePolicyRoute
+type DeletePolicyRouteParams struct {
+	// in:path
+	RouteID string
+	// The expected version of the route, the deletion fails with a conflict if it's not the current version.
+	// in:query
+	Version string `json:"version"`
+}
+
+// swagger:parameters RouteMovePolicyRoute
+type MovePolicyRouteParams struct {
+	// in:path
+	RouteID string
+	// in:body
+	Body PostableRouteMove
+}
+
+// swagger:parameters RouteGetAlertingBundle
+type AlertingBundleExportParams struct {
+	// FolderUID exports the rules of the folder.
+	// in:query
+	FolderUID []string `json:"folder_uid"`
+	// RuleUID exports the rule, and the other rules of its rule group.
+	// in:query
+	RuleUID []string `json:"rule_uid"`
+	// Format of the bundle, json or yaml.
+	// in:query
+	// default: json
+	Format string `json:"format"`
+}
+
+// swagger:parameters RoutePostAlertingBundle
+type AlertingBundleImportParams struct {
+	// Return the resolution of the dependencies without importing the bundle.
+	// in:query
+	// required:false
+	DryRun bool `json:"dryRun"`
+	// in:body
+	Body AlertingBundle
+}
+
+// swagger:parameters RoutePutMuteTiming
+type PutMuteTimingParams struct {
+	// in:path
+	UID string
+	// in:body
+	Body PostableRecurringSilence
+}
+
+// swagger:model
+type ProvisionedAlertRule struct {
+	// UID of the rule, stable across updates.
+	UID string `json:"uid"`
+	// readonly: true
+	OrgID int64 `json:"orgID"`
+	// UID of the folder of the rule.
+	FolderUID string `json:"folderUID"`
+	// Rule group of the rule, it can't be changed once the rule is created.
+	RuleGroup    string                     `json:"ruleGroup"`
+	Title        string                     `json:"title"`
+	Condition    string                     `json:"condition"`
+	Data         []models.AlertQuery        `json:"data"`
+	NoDataState  models.NoDataState         `json:"noDataState"`
+	ExecErrState models.ExecutionErrorState `json:"execErrState"`
+	For          model.Duration             `json:"for"`
+	Annotations  map[string]string          `json:"annotations,omitempty"`
+	Labels       map[string]string          `json:"labels,omitempty"`
+	IsPaused     bool                       `json:"isPaused,omitempty"`
+	Composite    *models.CompositeCondition `json:"composite,omitempty"`
+	Thresholds   *models.Thresholds         `json:"thresholds,omitempty"`
+	Heartbeat    *models.HeartbeatCondition `json:"heartbeat,omitempty"`
+	Links        *models.RuleLinks          `json:"links,omitempty"`
+	ShadowOf     string                     `json:"shadow_of,omitempty"`
+	ActiveFrom   *time.Time                 `json:"active_from,omitempty"`
+	ActiveUntil  *time.Time                 `json:"active_until,omitempty"`
+
+	HoldOnDatasourceMaintenance bool `json:"hold_on_datasource_maintenance,omitempty"`
+	// Version of the rule. The updates with a version are rejected with a 409 if the rule has been changed since.
+	Version int64 `json:"version,omitempty"`
+	// readonly: true
+	Updated time.Time `json:"updated,omitempty"`
+	// readonly: true
+	Provenance models.Provenance `json:"provenance,omitempty"`
+}
+
+// swagger:model
+type AlertRuleGroup struct {
+	// readonly: true
+	Title string `json:"title"`
+	// readonly: true
+	FolderUID string `json:"folderUID"`
+	// Interval of the rules of the group, in seconds.
+	Interval int64                  `json:"interval"`
+	Rules    []ProvisionedAlertRule `json:"rules"`
+}
+
+// swagger:model
+type PostableContactPoint struct {
+	Receivers []*PostableGrafanaReceiver `json:"receivers"`
+}
+
+// swagger:model
+type GettableContactPoint struct {
+	Name      string                     `json:"name"`
+	Receivers []*GettableGrafanaReceiver `json:"receivers"`
+	// readonly: true
+	Provenance models.Provenance `json:"provenance,omitempty"`
+}
+
+// swagger:model
+type GettableContactPoints []GettableContactPoint
+
+// swagger:model
+type NotificationPolicyTree struct {
+	Route *config.Route `json:"route"`
+	// readonly: true
+	Provenance models.Provenance `json:"provenance,omitempty"`
+}
+
+// swagger:model
+type ProvisionedRoute struct {
+	// ID of the route, generated from its matchers and the ID of its parent. The ID of the root is "root".
+	// readonly: true
+	ID string `json:"id"`
+	// readonly: true
+	ParentID string `json:"parentId,omitempty"`
+	// Version of the route, changed by any change of the route or its children.
+	// readonly: true
+	Version string        `json:"version"`
+	Route   *config.Route `json:"route"`
+	// ResolvedNotifications suppress or delay the resolved notifications of the alert groups of the route, and of
+	// its children without their own settings.
+	ResolvedNotifications *ResolvedNotificationSettings `json:"resolved_notifications,omitempty"`
+	// readonly: true
+	Provenance models.Provenance `json:"provenance,omitempty"`
+}
+
+// swagger:model
+type ProvisionedRoutes []ProvisionedRoute
+
+// swagger:model
+type PostableRoute struct {
+	Route *config.Route `json:"route"`
+	// ResolvedNotifications suppress or delay the resolved notifications of the alert groups of the route, and of
+	// its children without their own settings. They replace the settings of the replaced route, which are removed
+	// if empty.
+	ResolvedNotifications *ResolvedNotificationSettings `json:"resolved_notifications,omitempty"`
+	// Version is the expected version of the replaced route, or of the parent of the created route. The change
+	// fails with a conflict if it's not the current version, it's not checked if empty.
+	Version string `json:"version,omitempty"`
+	// Index of the created route among the children of its parent, the route is added last if empty.
+	Index *int `json:"index,omitempty"`
+}
+
+// swagger:model
+type PostableRouteMove struct {
+	// ParentID is the ID of the new parent of the route.
+	ParentID string `json:"parentId"`
+	// Index of the route among the children of its new parent, the route is added last if empty.
+	Index *int `json:"index,omitempty"`
+	// Version is the expected version of the moved route, it's not checked if empty.
+	Version string `json:"version,omitempty"`
+}
+
+// swagger:model
+type ProvisionedMuteTiming struct {
+	GettableRecurringSilence
+	// readonly: true
+	Provenance models.Provenance `json:"provenance,omitempty"`
+}
+
+// swagger:model
+type ProvisionedMuteTimings []ProvisionedMuteTiming
+
+// RuleReference identifies an alert rule.
+// swagger:model
+type RuleReference struct {
+	UID       string `json:"uid"`
+	Title     string `json:"title"`
+	FolderUID string `json:"folderUID"`
+	RuleGroup string `json:"ruleGroup"`
+}
+
+// swagger:model
+type ContactPointUsage struct {
+	// Routes are the paths of the notification policies notifying the contact point, such as route.routes[0].
+	Routes []string `json:"routes"`
+	// Escalations are the receivers of the escalation chains with a step notifying the contact point.
+	Escalations []string `json:"escalations"`
+	// Rules are the rules whose alerts are routed to the contact point by their labels. The labels of the query
+	// results are not known before the evaluation, so the alerts of other rules can be routed to it too.
+	Rules []RuleReference `json:"rules"`
+}
+
+// swagger:model
+type MuteTimingUsage struct {
+	// Rules are the rules whose alerts are muted by the mute timing by their labels.
+	Rules []RuleReference `json:"rules"`
+}
+
+// swagger:model
+type AlertingBundle struct {
+	// Folders are the folders of the rule groups.
+	Folders    []BundleFolder   `json:"folders"`
+	RuleGroups []AlertRuleGroup `json:"ruleGroups"`
+	// ContactPoints are the contact points the alerts of the rules are routed to by the notification policies, by
+	// name. The notification policies are not part of the bundle.
+	ContactPoints []BundleContactPoint       `json:"contactPoints"`
+	MuteTimings   []PostableRecurringSilence `json:"muteTimings"`
+	// Templates are the template files defining the templates used by the contact points, by name.
+	Templates map[string]string `json:"templates"`
+}
+
+// swagger:model
+type BundleFolder struct {
+	UID   string `json:"uid"`
+	Title string `json:"title"`
+}
+
+// swagger:model
+type BundleContactPoint struct {
+	Name      string                     `json:"name"`
+	Receivers []*PostableGrafanaReceiver `json:"receivers"`
+}
+
+// swagger:model
+type AlertingBundleImportResult struct {
+	DryRun bool `json:"dryRun"`
+	// Created are the dependencies created by the import, or that would be created by a dry run.
+	Created BundleResources `json:"created"`
+	// Resolved are the dependencies that already exist, they are not changed by the import.
+	Resolved BundleResources `json:"resolved"`
+	// RuleGroups are the replaced rule groups, by folder UID and title.
+	RuleGroups []BundleRuleGroup `json:"ruleGroups"`
+}
+
+// swagger:model
+type BundleResources struct {
+	// Folders are the UIDs of the folders in the Grafana instance.
+	Folders []string `json:"folders"`
+	// ContactPoints are the names of the contact points.
+	ContactPoints []string `json:"contactPoints"`
+	// MuteTimings are the UIDs of the mute timings.
+	MuteTimings []string `json:"muteTimings"`
+	// Templates are the names of the templates.
+	Templates []string `json:"templates"`
+}
+
+// swagger:model
+type BundleRuleGroup struct {
+	FolderUID string `json:"folderUID"`
+	Title     string `json:"title"`
+	// Rules are the number of rules of the group.
+	Rules int `json:"rules"`
+}
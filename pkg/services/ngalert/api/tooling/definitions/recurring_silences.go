@@ -0,0 +1,92 @@
+package definitions
+
+import (
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+)
+
+// swagger:route GET /api/alertmanager/grafana/api/v1/recurring-silences recurring_silences RouteGetRecurringSilences
+//
+// Get the recurring silences of the user's organization.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableRecurringSilences
+
+// swagger:route POST /api/alertmanager/grafana/api/v1/recurring-silences recurring_silences RouteCreateRecurringSilence
+//
+// Create or update a recurring silence. The silence of the current or next occurrence is created
+// by the Grafana Alertmanager shortly before the occurrence starts.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       202: GettableRecurringSilence
+//       400: ValidationError
+
+// swagger:route GET /api/alertmanager/grafana/api/v1/recurring-silence/{RecurringSilenceUID} recurring_silences RouteGetRecurringSilence
+//
+// Get a recurring silence by UID.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableRecurringSilence
+//       404: Failure
+
+// swagger:route DELETE /api/alertmanager/grafana/api/v1/recurring-silence/{RecurringSilenceUID} recurring_silences RouteDeleteRecurringSilence
+//
+// Delete a recurring silence and expire the silence of its current occurrence.
+//
+//     Responses:
+//       200: Ack
+//       404: Failure
+
+// swagger:parameters RouteCreateRecurringSilence
+type CreateRecurringSilenceParams struct {
+	// in:body
+	Body PostableRecurringSilence
+}
+
+// swagger:parameters RouteGetRecurringSilence RouteDeleteRecurringSilence
+type GetDeleteRecurringSilenceParams struct {
+	// in:path
+	RecurringSilenceUID string
+}
+
+// swagger:model
+type PostableRecurringSilence struct {
+	// UID of the recurring silence to update, a new recurring silence is created if empty.
+	UID       string        `json:"uid,omitempty"`
+	Matchers  amv2.Matchers `json:"matchers"`
+	Comment   string        `json:"comment"`
+	CreatedBy string        `json:"createdBy"`
+	// Weekdays the silence recurs on, 0 being Sunday. Every day if empty.
+	Weekdays []time.Weekday `json:"weekdays,omitempty"`
+	// Time of day at which each occurrence starts, in the format HH:MM.
+	StartTime string `json:"startTime"`
+	// Duration of each occurrence, e.g. 2h30m.
+	Duration string `json:"duration"`
+	// IANA timezone of the start time, UTC if empty.
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// swagger:model
+type GettableRecurringSilence struct {
+	PostableRecurringSilence
+	// ID of the silence created for the last materialized occurrence.
+	LastSilenceID string `json:"lastSilenceId,omitempty"`
+	// Start of the last materialized occurrence.
+	LastStartsAt *time.Time `json:"lastStartsAt,omitempty"`
+}
+
+// swagger:model
+type GettableRecurringSilences []GettableRecurringSilence
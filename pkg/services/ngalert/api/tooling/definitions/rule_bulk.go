@@ -0,0 +1,77 @@
+package definitions
+
+// swagger:route POST /api/ruler/grafana/api/v1/bulk rule_bulk RoutePostBulkRuleOperation
+//
+// Apply an operation to all the Grafana managed rules that match a selector, in a single transaction. With dry_run
+// the rules are listed but not changed.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: BulkRuleOperationResult
+//       400: ValidationError
+//       403: Failure
+//       409: Failure
+
+// swagger:parameters RoutePostBulkRuleOperation
+type BulkRuleOperationParams struct {
+	// in:body
+	Body PostableBulkRuleOperation
+}
+
+// BulkRuleOperation is an operation applied to several rules.
+// swagger:enum BulkRuleOperation
+type BulkRuleOperation string
+
+const (
+	BulkRuleOperationPause            BulkRuleOperation = "pause"
+	BulkRuleOperationResume           BulkRuleOperation = "resume"
+	BulkRuleOperationDelete           BulkRuleOperation = "delete"
+	BulkRuleOperationAddLabel         BulkRuleOperation = "add_label"
+	BulkRuleOperationRemoveLabel      BulkRuleOperation = "remove_label"
+	BulkRuleOperationSetAnnotation    BulkRuleOperation = "set_annotation"
+	BulkRuleOperationRemoveAnnotation BulkRuleOperation = "remove_annotation"
+)
+
+// swagger:model
+type PostableBulkRuleOperation struct {
+	// required: true
+	Selector RuleSelector `json:"selector"`
+	// required: true
+	Operation BulkRuleOperation `json:"operation"`
+	// Key is the label or the annotation of the label and annotation operations.
+	Key string `json:"key,omitempty"`
+	// Value is the value of the label or the annotation of add_label and set_annotation.
+	Value  string `json:"value,omitempty"`
+	DryRun bool   `json:"dry_run,omitempty"`
+}
+
+// RuleSelector selects the rules that match all its criteria, at least one of them is required.
+// swagger:model
+type RuleSelector struct {
+	// Matchers are label matchers, such as severity="critical" or team=~"sre|ops".
+	Matchers []string `json:"matchers,omitempty"`
+	// FolderUIDs selects the rules of the folders.
+	FolderUIDs []string `json:"folder_uids,omitempty"`
+	// DatasourceUID selects the rules that query the data source.
+	DatasourceUID string `json:"datasource_uid,omitempty"`
+}
+
+// swagger:model
+type BulkRuleOperationResult struct {
+	DryRun bool `json:"dry_run"`
+	// Rules are the rules changed by the operation, or that would be changed without dry_run.
+	Rules []BulkRuleRef `json:"rules"`
+}
+
+// swagger:model
+type BulkRuleRef struct {
+	UID       string `json:"uid"`
+	Title     string `json:"title"`
+	FolderUID string `json:"folder_uid"`
+	RuleGroup string `json:"rule_group"`
+}
@@ -0,0 +1,89 @@
+package definitions
+
+// swagger:route POST /api/ruler/grafana/api/v1/rule/{RuleUID}/clone rule_clone RoutePostCloneRule
+//
+// Create a copy of a Grafana managed rule, in a rule group of the same or another folder. A copy added to an
+// existing rule group gets the interval of the group.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       202: RuleCloneResult
+//       400: ValidationError
+//       404: Failure
+//       409: Failure
+
+// swagger:route POST /api/ruler/grafana/api/v1/rules/{Namespace}/{Groupname}/clone rule_clone RoutePostCloneRuleGroup
+//
+// Create a copy of a Grafana managed rule group, in another folder or with another name. The rule group must not
+// exist in the folder.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       202: RuleCloneResult
+//       400: ValidationError
+//       404: Failure
+//       409: Failure
+
+// swagger:parameters RoutePostCloneRule
+type CloneRuleParams struct {
+	// in:path
+	RuleUID string
+	// in:body
+	Body PostableRuleClone
+}
+
+// swagger:parameters RoutePostCloneRuleGroup
+type CloneRuleGroupParams struct {
+	// in:path
+	Namespace string
+	// in:path
+	Groupname string
+	// in:body
+	Body PostableRuleGroupClone
+}
+
+// swagger:model
+type PostableRuleClone struct {
+	// FolderUID is the folder of the copy, the folder of the rule when it's missing.
+	FolderUID string `json:"folder_uid,omitempty"`
+	// RuleGroup is the rule group of the copy, the rule group of the rule when it's missing.
+	RuleGroup string `json:"rule_group,omitempty"`
+	// Title is the title of the copy, the title of the rule after the substitutions when it's missing.
+	Title         string            `json:"title,omitempty"`
+	Substitutions RuleSubstitutions `json:"substitutions,omitempty"`
+	// Shadow makes the copy a shadow of the rule, evaluated alongside it without notifying.
+	Shadow bool `json:"shadow,omitempty"`
+}
+
+// swagger:model
+type PostableRuleGroupClone struct {
+	// FolderUID is the folder of the copy, the folder of the rule group when it's missing.
+	FolderUID string `json:"folder_uid,omitempty"`
+	// RuleGroup is the name of the copy, the name of the rule group when it's missing.
+	RuleGroup     string            `json:"rule_group,omitempty"`
+	Substitutions RuleSubstitutions `json:"substitutions,omitempty"`
+}
+
+// RuleSubstitutions are the changes made to the copies of the rules.
+// swagger:model
+type RuleSubstitutions struct {
+	// Text replaces each key with its value in the titles, the label values, the annotations and the query models.
+	Text map[string]string `json:"text,omitempty"`
+	// DatasourceUIDs replaces the data sources of the queries, by UID.
+	DatasourceUIDs map[string]string `json:"datasource_uids,omitempty"`
+	// Labels sets labels of the rules.
+	Labels map[string]string `json:"labels,omitempty"`
+	// Annotations sets annotations of the rules.
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// swagger:model
+type RuleCloneResult struct {
+	Message string `json:"message"`
+	// UIDs are the UIDs of the copies, in the order of the rules.
+	UIDs []string `json:"uids"`
+}
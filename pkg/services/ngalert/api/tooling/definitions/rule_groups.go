@@ -0,0 +1,29 @@
+package definitions
+
+// swagger:route PUT /api/ruler/grafana/api/v1/rules/{Namespace}/{Groupname} rule_groups RoutePutRuleGroup
+//
+// Replace the rules of a Grafana managed rule group in a single transaction. The rules are created, updated and
+// deleted, the interval of the group is set and the rules are ordered as in the request, or nothing is changed.
+// The rules with an UID must be in the group, and the group must not be changed by someone else while it's replaced.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       202: RuleGroupConfigResponse
+//       400: ValidationError
+//       404: Failure
+//       409: Failure
+
+// swagger:parameters RoutePutRuleGroup
+type PutRuleGroupParams struct {
+	// in:path
+	Namespace string
+	// in:path
+	Groupname string
+	// in:body
+	Body PostableRuleGroupConfig
+}
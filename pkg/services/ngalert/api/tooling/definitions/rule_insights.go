@@ -0,0 +1,59 @@
+package definitions
+
+import "time"
+
+// swagger:route Get /api/v1/rules/insights rule_insights RouteGetRuleInsights
+//
+// Get the health and usage insights of the Grafana managed rules in the folders the user can view, to find the
+// slowest and the most broken rules.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: RuleInsightsResult
+//       400: ValidationError
+
+// swagger:parameters RouteGetRuleInsights
+type RuleInsightsParams struct {
+	// Period is the duration of the evaluations aggregated, such as 6h or 7d, up to 7 days.
+	// in:query
+	// default: 24h
+	Period string `json:"period"`
+	// Sort is avg_duration, max_duration, failure_rate or nodata_rate, the greatest first.
+	// in:query
+	// default: avg_duration
+	Sort string `json:"sort"`
+	// in:query
+	// default: 100
+	// maximum: 1000
+	Limit int `json:"limit"`
+}
+
+// swagger:model
+type RuleInsightsResult struct {
+	// From is the start of the period, truncated to the hour.
+	From  time.Time     `json:"from"`
+	Rules []RuleInsight `json:"rules"`
+}
+
+// swagger:model
+type RuleInsight struct {
+	UID         string `json:"uid"`
+	Title       string `json:"title"`
+	FolderUID   string `json:"folder_uid"`
+	FolderTitle string `json:"folder_title"`
+	RuleGroup   string `json:"rule_group"`
+	Evaluations int64  `json:"evaluations"`
+	// Failures are the evaluations that failed or had results in error.
+	Failures int64 `json:"failures"`
+	// NoData are the evaluations that had results without data.
+	NoData             int64   `json:"nodata"`
+	FailureRate        float64 `json:"failure_rate"`
+	NoDataRate         float64 `json:"nodata_rate"`
+	AvgDurationSeconds float64 `json:"avg_duration_seconds"`
+	MaxDurationSeconds float64 `json:"max_duration_seconds"`
+	// LastError is the error of the last failed evaluation of the period.
+	LastError   string     `json:"last_error,omitempty"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+}
@@ -0,0 +1,62 @@
+package definitions
+
+// swagger:route POST /api/ruler/grafana/api/v1/rule/{RuleUID}/move rule_move RoutePostMoveRule
+//
+// Move a Grafana managed rule to a rule group of the same or another folder. A rule moved to an existing rule
+// group gets the interval of the group.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       202: Ack
+//       400: ValidationError
+//       404: Failure
+//       409: Failure
+
+// swagger:route POST /api/ruler/grafana/api/v1/rules/{Namespace}/{Groupname}/move rule_move RoutePostMoveRuleGroup
+//
+// Move a Grafana managed rule group to another folder, or rename it. The rule group must not exist in the folder.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       202: Ack
+//       400: ValidationError
+//       404: Failure
+//       409: Failure
+
+// swagger:parameters RoutePostMoveRule
+type MoveRuleParams struct {
+	// in:path
+	RuleUID string
+	// in:body
+	Body PostableRuleMove
+}
+
+// swagger:parameters RoutePostMoveRuleGroup
+type MoveRuleGroupParams struct {
+	// in:path
+	Namespace string
+	// in:path
+	Groupname string
+	// in:body
+	Body PostableRuleGroupMove
+}
+
+// swagger:model
+type PostableRuleMove struct {
+	// FolderUID is the folder of the rule group, the folder of the rule when it's missing.
+	FolderUID string `json:"folder_uid"`
+	// required: true
+	RuleGroup string `json:"rule_group"`
+}
+
+// swagger:model
+type PostableRuleGroupMove struct {
+	// FolderUID is the folder of the rule group, the folder of the rule group when it's missing.
+	FolderUID string `json:"folder_uid"`
+	// RuleGroup is the new name of the rule group, its name when it's missing.
+	RuleGroup string `json:"rule_group"`
+}
@@ -0,0 +1,60 @@
+package definitions
+
+// swagger:route Get /api/prometheus/grafana/api/v1/rules/search rule_search RouteSearchRules
+//
+// Search the Grafana managed rules in the folders the user can view.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: RuleSearchResult
+//       400: ValidationError
+
+// swagger:parameters RouteSearchRules
+type RuleSearchParams struct {
+	// Query is searched, case insensitively, in the titles and the annotations of the rules.
+	// in:query
+	Query string `json:"query"`
+	// Matcher is a label matcher, such as severity="critical" or team=~"sre|ops".
+	// in:query
+	Matcher []string `json:"matcher"`
+	// in:query
+	FolderUID []string `json:"folder_uid"`
+	// DatasourceUID returns the rules that query the data source.
+	// in:query
+	DatasourceUID string `json:"datasource_uid"`
+	// State is one of firing, pending, inactive, error or nodata.
+	// in:query
+	State []string `json:"state"`
+	// in:query
+	// default: 1
+	Page int `json:"page"`
+	// in:query
+	// default: 100
+	// maximum: 1000
+	Limit int `json:"limit"`
+	// Sort is title or updated, prefixed with a - for the descending order.
+	// in:query
+	// default: title
+	Sort string `json:"sort"`
+}
+
+// swagger:model
+type RuleSearchResult struct {
+	Rules []RuleSearchHit `json:"rules"`
+	// Total is the number of rules found, on all pages.
+	Total int `json:"total"`
+	Page  int `json:"page"`
+	Limit int `json:"limit"`
+}
+
+// swagger:model
+type RuleSearchHit struct {
+	Rule        GettableExtendedRuleNode `json:"rule"`
+	FolderTitle string                   `json:"folder_title"`
+	// State is firing, pending or inactive.
+	State string `json:"state"`
+	// Health is ok, error or nodata.
+	Health string `json:"health"`
+}
@@ -0,0 +1,57 @@
+package definitions
+
+import (
+	"time"
+)
+
+// swagger:route Get /api/ruler/grafana/api/v1/rule/{RuleUID}/shadow/compare rule_shadow RouteGetShadowRuleComparison
+//
+// Compare the changes of the states of the alerts of a shadow rule with the ones of its original rule. The changes
+// are read from the state annotations table, so state_annotations_backend must be set to table.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: ShadowRuleComparison
+//       400: ValidationError
+//       404: Failure
+
+// swagger:parameters RouteGetShadowRuleComparison
+type ShadowRuleComparisonParams struct {
+	// The UID of the shadow rule.
+	// in:path
+	RuleUID string
+	// The period of the comparison, up to 7d.
+	// in:query
+	// default: 24h
+	Period string `json:"period"`
+}
+
+// swagger:model
+type ShadowRuleComparison struct {
+	From     time.Time          `json:"from"`
+	To       time.Time          `json:"to"`
+	Original ShadowRuleTimeline `json:"original"`
+	Shadow   ShadowRuleTimeline `json:"shadow"`
+}
+
+// ShadowRuleTimeline is the timeline of the states of the alerts of a rule in the period of a comparison.
+type ShadowRuleTimeline struct {
+	UID   string `json:"uid"`
+	Title string `json:"title"`
+	// Transitions are the changes of the states of the alerts, the oldest first.
+	Transitions []RuleStateTransition `json:"transitions"`
+	// FiringCount is the number of times an alert started firing.
+	FiringCount int `json:"firing_count"`
+	// FiringSeconds is the sum of the durations the alerts were firing.
+	FiringSeconds float64 `json:"firing_seconds"`
+}
+
+// RuleStateTransition is a change of the state of an alert.
+type RuleStateTransition struct {
+	Time      time.Time         `json:"time"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	PrevState string            `json:"prev_state"`
+	NewState  string            `json:"new_state"`
+}
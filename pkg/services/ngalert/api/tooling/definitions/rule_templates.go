@@ -0,0 +1,154 @@
+package definitions
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+// swagger:route GET /api/ruler/grafana/api/v1/templates rule_templates RouteGetRuleTemplates
+//
+// List the rule templates of the user's organization.
+//
+//     Responses:
+//       200: GettableRuleTemplates
+
+// swagger:route POST /api/ruler/grafana/api/v1/templates rule_templates RoutePostRuleTemplate
+//
+// Create a rule template, or update the rule template with the UID of the body. The rules instantiated from the
+// template are not changed until their bulk update.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       202: GettableRuleTemplate
+//       400: ValidationError
+
+// swagger:route GET /api/ruler/grafana/api/v1/template/{TemplateUID} rule_templates RouteGetRuleTemplate
+//
+// Get a rule template, with the rules instantiated from it.
+//
+//     Responses:
+//       200: GettableRuleTemplate
+//       404: Failure
+
+// swagger:route DELETE /api/ruler/grafana/api/v1/template/{TemplateUID} rule_templates RouteDeleteRuleTemplate
+//
+// Delete a rule template. The rules instantiated from it are kept.
+//
+//     Responses:
+//       202: Ack
+
+// swagger:route POST /api/ruler/grafana/api/v1/template/{TemplateUID}/instantiate rule_templates RoutePostRuleTemplateInstance
+//
+// Create a rule from a rule template, with the values of its parameters, in a rule group of a folder. A rule added
+// to an existing rule group gets the interval of the group.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       202: RuleTemplateResult
+//       400: ValidationError
+//       404: Failure
+//       409: Failure
+
+// swagger:route POST /api/ruler/grafana/api/v1/template/{TemplateUID}/instances/update rule_templates RoutePostRuleTemplateInstancesUpdate
+//
+// Render the rules instantiated from a rule template again with the current version of the template and the values
+// of their parameters. The rules keep their UID, folder, rule group and interval.
+//
+//     Responses:
+//       202: RuleTemplateResult
+//       400: ValidationError
+//       404: Failure
+//       409: Failure
+
+// swagger:parameters RouteGetRuleTemplate RouteDeleteRuleTemplate RoutePostRuleTemplateInstancesUpdate
+type RuleTemplateParams struct {
+	// in:path
+	TemplateUID string
+}
+
+// swagger:parameters RoutePostRuleTemplate
+type PostRuleTemplateParams struct {
+	// in:body
+	Body PostableRuleTemplate
+}
+
+// swagger:parameters RoutePostRuleTemplateInstance
+type RuleTemplateInstanceParams struct {
+	// in:path
+	TemplateUID string
+	// in:body
+	Body PostableRuleTemplateInstance
+}
+
+// RuleTemplateParameter is a parameter of a rule template, referenced as ${name} in the strings of its rule.
+// swagger:model
+type RuleTemplateParameter struct {
+	Name string `json:"name"`
+	// A string of the rule that is only the reference to a number parameter is replaced with the number, so
+	// thresholds can be parameters.
+	// enum: string,number,datasource
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+	// Default is the value of the parameter when it's not supplied, the parameter is required without it.
+	Default *string `json:"default,omitempty"`
+}
+
+// swagger:model
+type PostableRuleTemplate struct {
+	// UID of the template to update, a template is created without it.
+	UID         string                  `json:"uid,omitempty"`
+	Title       string                  `json:"title"`
+	Description string                  `json:"description,omitempty"`
+	Parameters  []RuleTemplateParameter `json:"parameters"`
+	// Rule is the definition of the rules, in the format of the Grafana managed rules of the ruler API.
+	Rule json.RawMessage `json:"rule"`
+}
+
+// swagger:model
+type GettableRuleTemplate struct {
+	UID         string                  `json:"uid"`
+	Title       string                  `json:"title"`
+	Description string                  `json:"description,omitempty"`
+	Parameters  []RuleTemplateParameter `json:"parameters"`
+	Rule        json.RawMessage         `json:"rule"`
+	Version     int64                   `json:"version"`
+	UpdatedBy   string                  `json:"updated_by"`
+	Updated     time.Time               `json:"updated"`
+	// Instances are the rules instantiated from the template, only with a single template.
+	Instances []RuleTemplateInstance `json:"instances,omitempty"`
+}
+
+// swagger:model
+type GettableRuleTemplates []GettableRuleTemplate
+
+// RuleTemplateInstance is a rule instantiated from a rule template.
+// swagger:model
+type RuleTemplateInstance struct {
+	RuleUID    string            `json:"rule_uid"`
+	Parameters map[string]string `json:"parameters"`
+	// TemplateVersion is the version of the template the rule was last rendered from.
+	TemplateVersion int64 `json:"template_version"`
+}
+
+// swagger:model
+type PostableRuleTemplateInstance struct {
+	FolderUID string `json:"folder_uid"`
+	RuleGroup string `json:"rule_group"`
+	// Interval is the interval of the rule group if it doesn't exist.
+	Interval model.Duration `json:"interval,omitempty"`
+	// Parameters are the values of the parameters of the template, by name.
+	Parameters map[string]string `json:"parameters"`
+}
+
+// swagger:model
+type RuleTemplateResult struct {
+	Message string `json:"message"`
+	// UIDs are the UIDs of the rules created or updated.
+	UIDs []string `json:"uids"`
+}
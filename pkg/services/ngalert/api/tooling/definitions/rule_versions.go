@@ -0,0 +1,97 @@
+package definitions
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// swagger:route Get /api/ruler/grafana/api/v1/rule/{RuleUID}/versions rule_versions RouteGetRuleVersions
+//
+// List the versions of a Grafana managed rule, the latest first.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableRuleVersions
+//       404: Failure
+
+// swagger:route Get /api/ruler/grafana/api/v1/rule/{RuleUID}/versions/{Version} rule_versions RouteGetRuleVersion
+//
+// Get a version of a Grafana managed rule.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: GettableRuleVersion
+//       404: Failure
+
+// swagger:route Get /api/ruler/grafana/api/v1/rule/{RuleUID}/versions/diff rule_versions RouteGetRuleVersionsDiff
+//
+// Compare two versions of a Grafana managed rule.
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: RuleVersionDiff
+//       400: ValidationError
+//       404: Failure
+
+// swagger:route POST /api/ruler/grafana/api/v1/rule/{RuleUID}/versions/{Version}/restore rule_versions RoutePostRestoreRuleVersion
+//
+// Restore a version of a Grafana managed rule. The definition of the version becomes a new version of the rule,
+// which stays in its current folder and rule group.
+//
+//     Responses:
+//       202: Ack
+//       400: ValidationError
+//       404: Failure
+//       409: Failure
+
+// swagger:parameters RouteGetRuleVersions
+type RuleVersionsParams struct {
+	// in:path
+	RuleUID string
+}
+
+// swagger:parameters RouteGetRuleVersion RoutePostRestoreRuleVersion
+type RuleVersionParams struct {
+	// in:path
+	RuleUID string
+	// in:path
+	Version int64
+}
+
+// swagger:parameters RouteGetRuleVersionsDiff
+type RuleVersionsDiffParams struct {
+	// in:path
+	RuleUID string
+	// in:query
+	From int64 `json:"from"`
+	// in:query
+	To int64 `json:"to"`
+}
+
+// swagger:model
+type GettableRuleVersion struct {
+	Version       int64 `json:"version"`
+	ParentVersion int64 `json:"parent_version,omitempty"`
+	// RestoredFrom is the version restored by this version, if any.
+	RestoredFrom int64                    `json:"restored_from,omitempty"`
+	Created      time.Time                `json:"created"`
+	CreatedBy    string                   `json:"created_by,omitempty"`
+	Rule         GettableExtendedRuleNode `json:"rule"`
+}
+
+// swagger:model
+type GettableRuleVersions []GettableRuleVersion
+
+// swagger:model
+type RuleVersionDiff struct {
+	From    int64                           `json:"from"`
+	To      int64                           `json:"to"`
+	Changes []models.AlertRuleVersionChange `json:"changes"`
+}
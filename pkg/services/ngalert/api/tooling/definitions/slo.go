@@ -0,0 +1,75 @@
+package definitions
+
+import (
+	"github.com/prometheus/common/model"
+)
+
+// swagger:route POST /api/ruler/grafana/api/v1/slo slo RoutePostSLORules
+//
+// Generate the multi-window, multi-burn-rate alerting rules of a service level objective, and save them as a rule
+// group replacing the rule group of the same name. With dryRun the generated rules are returned without being
+// saved.
+//
+//     Consumes:
+//     - application/json
+//
+//     Responses:
+//       200: SLORules
+//       202: Ack
+//       400: ValidationError
+//       404: Failure
+//       409: Failure
+
+// swagger:parameters RoutePostSLORules
+type PostSLORulesParams struct {
+	// in:query
+	DryRun bool `json:"dryRun"`
+	// in:body
+	Body PostableSLORules
+}
+
+// PostableSLORules is a service level objective, from which the burn rate alerting rules are generated.
+// swagger:model
+type PostableSLORules struct {
+	// Name of the SLO, in the titles and the slo label of the rules.
+	Name      string `json:"name"`
+	FolderUID string `json:"folder_uid"`
+	// RuleGroup is the rule group of the rules, the name of the SLO by default.
+	RuleGroup string `json:"rule_group,omitempty"`
+	// DatasourceUID is the Prometheus datasource queried by the rules, the default datasource of the organization
+	// by default.
+	DatasourceUID string `json:"datasource_uid,omitempty"`
+	// ErrorRatioQuery is the PromQL query of the ratio of the failed events, between 0 and 1, over the range
+	// ${window}. For example sum(rate(http_requests_total{code=~"5.."}[${window}])) / sum(rate(http_requests_total[${window}])).
+	ErrorRatioQuery string `json:"error_ratio_query"`
+	// Objective is the target of the ratio of successful events, between 0 and 1, such as 0.999.
+	Objective float64 `json:"objective"`
+	// Period is the period of the objective, 30 days by default.
+	Period model.Duration `json:"period,omitempty"`
+	// Interval is the evaluation interval of the rule group, 1 minute by default.
+	Interval model.Duration `json:"interval,omitempty"`
+	// Windows are the pairs of windows of the rules. By default, a page for 2% of the error budget burned in
+	// 1 hour or 5% in 6 hours and a ticket for 10% in 1 day or 3 days.
+	Windows     []SLOBurnRateWindow `json:"windows,omitempty"`
+	Labels      map[string]string   `json:"labels,omitempty"`
+	Annotations map[string]string   `json:"annotations,omitempty"`
+}
+
+// SLOBurnRateWindow is a rule firing when the error budget is burned faster than the burn rate consuming the
+// budget in the long window, both over the long window and the short window, so the alert resolves quickly.
+type SLOBurnRateWindow struct {
+	// Severity is the severity label of the rule.
+	Severity    string         `json:"severity"`
+	LongWindow  model.Duration `json:"long_window"`
+	ShortWindow model.Duration `json:"short_window"`
+	// BudgetConsumed is the ratio of the error budget of the period consumed in the long window, such as 0.02.
+	BudgetConsumed float64        `json:"budget_consumed"`
+	For            model.Duration `json:"for,omitempty"`
+}
+
+// SLORules is the rule group generated from a service level objective.
+// swagger:model
+type SLORules struct {
+	DryRun bool                `json:"dryRun"`
+	Group  PrometheusRuleGroup `json:"group"`
+}
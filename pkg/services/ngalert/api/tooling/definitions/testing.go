@@ -7,8 +7,10 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/common/model"
 	"github.com/prometheus/prometheus/promql"
 )
 
@@ -52,6 +54,54 @@ import (
 //
 //     Responses:
 //       200: EvalQueriesResponse
+//       400: EvalQueriesTraceResponse
+
+// swagger:route Post /api/v1/rule/test testing RouteTestInlineRule
+//
+// Evaluate a Grafana managed rule that doesn't need to be saved, and get its alerts with their labels and annotations
+// rendered, to test the rule before saving it.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: TestInlineRuleResponse
+//       400: ValidationError
+
+// swagger:route Post /api/v1/rule/unit-test testing RouteRunRuleUnitTests
+//
+// Run unit tests of a Grafana managed rule: the rule is evaluated on the series of fixtures instead of the results of
+// its data source queries, and its firing alerts at the evaluation times of the tests are compared to the expected
+// alerts.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: RuleUnitTestResult
+//       400: ValidationError
+
+// swagger:route Post /api/v1/rule/validate-templates testing RouteValidateRuleTemplates
+//
+// Validate the templates of the labels and annotations of a Grafana managed rule, so that the templates that fail
+// are found before the rule is saved rather than when the alerts are sent. The variables the templates use are
+// checked against the queries and expressions of the rule.
+//
+//     Consumes:
+//     - application/json
+//
+//     Produces:
+//     - application/json
+//
+//     Responses:
+//       200: TemplatesValidation
+//       400: ValidationError
 
 // swagger:parameters RouteTestReceiverConfig
 type TestReceiverRequest struct {
@@ -83,6 +133,141 @@ type EvalQueriesRequest struct {
 type EvalQueriesPayload struct {
 	Data []models.AlertQuery `json:"data"`
 	Now  time.Time           `json:"now"`
+	// Debug returns the trace of the execution of each query and expression with the results.
+	Debug bool `json:"debug,omitempty"`
+}
+
+// swagger:parameters RouteTestInlineRule
+type TestInlineRuleRequest struct {
+	// in:body
+	Body TestInlineRulePayload
+}
+
+// swagger:model
+type TestInlineRulePayload struct {
+	// Rule is the rule, as in the rule groups of the ruler API. Composite and heartbeat rules can't be tested.
+	Rule PostableExtendedRuleNode `json:"rule"`
+	// FolderUID is the UID of the folder of the rule, for the labels of its alerts.
+	FolderUID string `json:"folder_uid,omitempty"`
+	// Interval is the evaluation interval of the rule group of the rule, 1m by default.
+	Interval model.Duration `json:"interval,omitempty"`
+	// Now is the time of the evaluation, the current time by default.
+	Now time.Time `json:"now,omitempty"`
+}
+
+// swagger:model
+type TestInlineRuleResponse struct {
+	Instances []TestedRuleInstance `json:"instances"`
+	// Warnings of the evaluation, such as the truncation of query results over the result limits.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// swagger:model
+type TestedRuleInstance struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+	// State is the state of the alert after the evaluation, as if the rule was evaluated for the first time. The
+	// no data and error states of the rule are applied, and the alerts of rules with a pending period are Pending.
+	State string `json:"state"`
+	// EvaluationState is the result of the condition: Normal, Alerting, NoData or Error.
+	EvaluationState string `json:"evaluationState"`
+	Value           string `json:"value,omitempty"`
+	// Values are the values of the reduce and math expressions, by RefID.
+	Values map[string]*float64 `json:"values,omitempty"`
+	Error  string              `json:"error,omitempty"`
+}
+
+// swagger:parameters RouteRunRuleUnitTests
+type RuleUnitTestRequest struct {
+	// in:body
+	Body RuleUnitTestPayload
+}
+
+// swagger:model
+type RuleUnitTestPayload struct {
+	// Rule is the rule, as in the rule groups of the ruler API. Composite and heartbeat rules can't be tested.
+	Rule PostableExtendedRuleNode `json:"rule"`
+	// FolderUID is the UID of the folder of the rule, for the labels of its alerts.
+	FolderUID string `json:"folder_uid,omitempty"`
+	// Interval is the evaluation interval of the rule and the interval of the values of the fixtures, 1m by default.
+	Interval model.Duration `json:"interval,omitempty"`
+	// Fixtures are the series returned by the data source queries of the rule, by RefID.
+	Fixtures map[string][]FixtureSeries `json:"fixtures"`
+	Tests    []RuleUnitTestCase         `json:"tests"`
+}
+
+// swagger:model
+type FixtureSeries struct {
+	Labels map[string]string `json:"labels,omitempty"`
+	// Values are the values of the series at each interval from the time 0, null for a missing value.
+	Values []*float64 `json:"values"`
+}
+
+// swagger:model
+type RuleUnitTestCase struct {
+	// EvalTime is the time of the test from the time 0. The rule is evaluated at each interval from the time 0, and
+	// the test checks the alerts of the last evaluation at or before the time.
+	EvalTime model.Duration `json:"eval_time"`
+	// ExpectedAlerts are the alerts expected to be firing at the time, no alert is expected to fire when empty.
+	ExpectedAlerts []RuleUnitTestAlert `json:"exp_alerts"`
+}
+
+// swagger:model
+type RuleUnitTestAlert struct {
+	// Labels are the labels of the alert, without the alertname and the labels Grafana adds for the rule.
+	Labels map[string]string `json:"labels"`
+	// Annotations are the annotations of the alert, without the annotations starting with __.
+	Annotations map[string]string `json:"annotations"`
+}
+
+// swagger:model
+type RuleUnitTestResult struct {
+	// Passed is true if all the tests passed.
+	Passed bool                     `json:"passed"`
+	Tests  []RuleUnitTestCaseResult `json:"tests"`
+}
+
+// swagger:model
+type RuleUnitTestCaseResult struct {
+	EvalTime model.Duration `json:"eval_time"`
+	// Passed is true if the firing alerts are the expected alerts and the evaluation had no error.
+	Passed bool `json:"passed"`
+	// Alerts are the firing alerts at the time.
+	Alerts []RuleUnitTestAlert `json:"alerts"`
+	// Missing are the expected alerts that are not firing.
+	Missing []RuleUnitTestAlert `json:"missing,omitempty"`
+	// Unexpected are the firing alerts that are not expected.
+	Unexpected []RuleUnitTestAlert `json:"unexpected,omitempty"`
+	// Errors are the errors of the evaluation.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// swagger:parameters RouteValidateRuleTemplates
+type ValidateRuleTemplatesRequest struct {
+	// in:body
+	Body ValidateRuleTemplatesPayload
+}
+
+// swagger:model
+type ValidateRuleTemplatesPayload struct {
+	// Rule is the rule, as in the rule groups of the ruler API.
+	Rule PostableExtendedRuleNode `json:"rule"`
+}
+
+// swagger:model
+type TemplatesValidation struct {
+	// Errors are the errors of the templates that fail for every alert.
+	Errors []TemplateIssue `json:"errors"`
+	// Warnings are the issues of the templates that fail for some alerts, such as a label missing from some series.
+	Warnings []TemplateIssue `json:"warnings"`
+}
+
+// swagger:model
+type TemplateIssue struct {
+	// Type is label or annotation.
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Message string `json:"message"`
 }
 
 func (p *TestRulePayload) UnmarshalJSON(b []byte) error {
@@ -127,6 +312,16 @@ type TestRuleResponse struct {
 // swagger:model
 type EvalQueriesResponse = backend.QueryDataResponse
 
+// EvalQueriesTraceResponse is the response of the evaluation of queries in debug mode.
+// swagger:model
+type EvalQueriesTraceResponse struct {
+	Results map[string]backend.DataResponse `json:"results,omitempty"`
+	// Trace has the execution of each query and expression, in the order of execution.
+	Trace []expr.NodeTrace `json:"trace"`
+	// Error is the error of the evaluation, the last node of the trace is the one that failed.
+	Error string `json:"error,omitempty"`
+}
+
 // swagger:model
 type AlertInstancesResponse struct {
 	// Instances is an array of arrow encoded dataframes
@@ -225,9 +225,10 @@ func validateQueriesAndExpressions(data []ngmodels.AlertQuery, user *models.Sign
 
 func conditionEval(c *models.ReqContext, cmd ngmodels.EvalAlertConditionCommand, datasourceCache datasources.CacheService, dataService *tsdb.Service, cfg *setting.Cfg, log log.Logger) response.Response {
 	evalCond := ngmodels.Condition{
-		Condition: cmd.Condition,
-		OrgID:     c.SignedInUser.OrgId,
-		Data:      cmd.Data,
+		Condition:   cmd.Condition,
+		OrgID:       c.SignedInUser.OrgId,
+		Data:        cmd.Data,
+		OwnerUserID: c.SignedInUser.UserId,
 	}
 	if err := validateCondition(evalCond, c.SignedInUser, c.SkipCache, datasourceCache); err != nil {
 		return ErrResp(http.StatusBadRequest, err, "invalid condition")
@@ -239,6 +240,15 @@ func conditionEval(c *models.ReqContext, cmd ngmodels.EvalAlertConditionCommand,
 	}
 
 	evaluator := eval.Evaluator{Cfg: cfg, Log: log}
+	if cmd.Debug {
+		evalResults, trace := evaluator.ConditionEvalWithTrace(&evalCond, now, dataService)
+		frame := evalResults.AsDataFrame()
+		return response.JSONStreaming(http.StatusOK, util.DynMap{
+			"instances": []*data.Frame{&frame},
+			"trace":     trace,
+			"warnings":  evalResults.Warnings(),
+		})
+	}
 	evalResults, err := evaluator.ConditionEval(&evalCond, now, dataService)
 	if err != nil {
 		return ErrResp(http.StatusBadRequest, err, "Failed to evaluate conditions")
@@ -247,6 +257,7 @@ func conditionEval(c *models.ReqContext, cmd ngmodels.EvalAlertConditionCommand,
 	frame := evalResults.AsDataFrame()
 	return response.JSONStreaming(http.StatusOK, util.DynMap{
 		"instances": []*data.Frame{&frame},
+		"warnings":  evalResults.Warnings(),
 	})
 }
 
@@ -257,8 +268,3 @@ func ErrResp(status int, err error, msg string, args ...interface{}) *response.N
 	}
 	return response.Error(status, err.Error(), nil)
 }
-
-// accessForbiddenResp creates a response of forbidden access.
-func accessForbiddenResp() response.Response {
-	return ErrResp(http.StatusForbidden, errors.New("Permission denied"), "")
-}
@@ -4,6 +4,7 @@ package eval
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"runtime/debug"
 	"sort"
@@ -53,6 +54,10 @@ type ExecutionResults struct {
 	Error error
 
 	Results data.Frames
+
+	// Warnings are the warnings of the execution of the queries and expressions, such as the truncation of results
+	// over the result limits.
+	Warnings []string
 }
 
 // Results is a slice of evaluated alert instances states.
@@ -77,6 +82,9 @@ type Result struct {
 	// It does not contain values for classic conditions as the values
 	// in classic conditions do not have a RefID.
 	Values map[string]NumberValueCapture
+
+	// Warnings are the warnings of the evaluation of the rule, the same for all its instances.
+	Warnings []string
 }
 
 // State is an enum of the evaluation State for an alert instance.
@@ -113,7 +121,16 @@ func (s State) String() string {
 type AlertExecCtx struct {
 	OrgID              int64
 	ExpressionsEnabled bool
-	Log                log.Logger
+	ResultLimits       expr.ResultLimits
+	SpillOptions       expr.SpillOptions
+	// MaxQueryRange is the maximum time range of the data source queries of the organization, 0 is no limit.
+	MaxQueryRange time.Duration
+	// OwnerUserID is the user whose data source permissions the queries are executed with, 0 for the service
+	// identity.
+	OwnerUserID int64
+	// Fixtures are the frames of the data source queries by RefID, returned instead of querying the data sources.
+	Fixtures map[string]data.Frames
+	Log      log.Logger
 
 	Ctx context.Context
 }
@@ -126,10 +143,22 @@ func GetExprRequest(ctx AlertExecCtx, data []models.AlertQuery, now time.Time) (
 			// Some data sources check this in query method as sometimes alerting needs special considerations.
 			"FromAlert": "true",
 		},
+		Limits:   ctx.ResultLimits,
+		Spill:    ctx.SpillOptions,
+		Fixtures: ctx.Fixtures,
 	}
 
 	for i := range data {
 		q := data[i]
+		if ctx.MaxQueryRange > 0 {
+			isExpr, err := q.IsExpression()
+			if err != nil {
+				return nil, err
+			}
+			if r := q.RelativeTimeRange.From - q.RelativeTimeRange.To; !isExpr && time.Duration(r) > ctx.MaxQueryRange {
+				return nil, fmt.Errorf("the time range %v of query %s exceeds the maximum query range %v of the organization", time.Duration(r), q.RefID, ctx.MaxQueryRange)
+			}
+		}
 		model, err := q.GetModel()
 		if err != nil {
 			return nil, fmt.Errorf("failed to get query model: %w", err)
@@ -144,6 +173,16 @@ func GetExprRequest(ctx AlertExecCtx, data []models.AlertQuery, now time.Time) (
 			return nil, fmt.Errorf("failed to retrieve maxDatapoints from the model: %w", err)
 		}
 
+		// the datasource returns fewer data points over a long time range
+		if q.Downsampling != nil {
+			r := time.Duration(q.RelativeTimeRange.From - q.RelativeTimeRange.To)
+			maxDatapoints, interval = q.Downsampling.Apply(r, maxDatapoints, interval)
+			model, err = withDownsampling(model, maxDatapoints, interval)
+			if err != nil {
+				return nil, fmt.Errorf("failed to downsample query %s: %w", q.RefID, err)
+			}
+		}
+
 		req.Queries = append(req.Queries, expr.Query{
 			TimeRange: expr.TimeRange{
 				From: q.RelativeTimeRange.ToTimeRange(now).From,
@@ -155,15 +194,30 @@ func GetExprRequest(ctx AlertExecCtx, data []models.AlertQuery, now time.Time) (
 			RefID:         q.RefID,
 			MaxDataPoints: maxDatapoints,
 			QueryType:     q.QueryType,
+			TimeShift:     time.Duration(q.RelativeTimeRange.TimeShift),
 		})
 	}
 	return req, nil
 }
 
+// withDownsampling sets the maximum number of data points and the interval of a query model, which the
+// datasources read them from.
+func withDownsampling(model []byte, maxDataPoints int64, interval time.Duration) ([]byte, error) {
+	props := make(map[string]interface{})
+	if err := json.Unmarshal(model, &props); err != nil {
+		return nil, err
+	}
+	props["maxDataPoints"] = maxDataPoints
+	props["intervalMs"] = interval.Milliseconds()
+	return json.Marshal(props)
+}
+
 type NumberValueCapture struct {
 	Var    string // RefID
 	Labels data.Labels
 	Value  *float64
+	// Samples are the most recent log lines of the value of a query in logs mode, newest first.
+	Samples []string
 }
 
 func executeCondition(ctx AlertExecCtx, c *models.Condition, now time.Time, dataService *tsdb.Service) ExecutionResults {
@@ -178,11 +232,12 @@ func executeCondition(ctx AlertExecCtx, c *models.Condition, now time.Time, data
 	// eval captures for the '__value_string__' annotation and the Value property of the API response.
 	captures := make([]NumberValueCapture, 0, len(execResp.Responses))
 
-	captureVal := func(refID string, labels data.Labels, value *float64) {
+	captureVal := func(refID string, labels data.Labels, value *float64, samples []string) {
 		captures = append(captures, NumberValueCapture{
-			Var:    refID,
-			Value:  value,
-			Labels: labels.Copy(),
+			Var:     refID,
+			Value:   value,
+			Labels:  labels.Copy(),
+			Samples: samples,
 		})
 	}
 
@@ -190,6 +245,7 @@ func executeCondition(ctx AlertExecCtx, c *models.Condition, now time.Time, data
 		// for each frame within each response, the response can contain several data types including time-series data.
 		// For now, we favour simplicity and only care about single scalar values.
 		for _, frame := range res.Frames {
+			result.Warnings = append(result.Warnings, frameWarnings(frame)...)
 			if len(frame.Fields) != 1 || frame.Fields[0].Type() != data.FieldTypeNullableFloat64 {
 				continue
 			}
@@ -197,7 +253,13 @@ func executeCondition(ctx AlertExecCtx, c *models.Condition, now time.Time, data
 			if frame.Fields[0].Len() == 1 {
 				v = frame.At(0, 0).(*float64) // type checked above
 			}
-			captureVal(frame.RefID, frame.Fields[0].Labels, v)
+			var samples []string
+			if frame.Meta != nil {
+				if s, ok := frame.Meta.Custom.(expr.LogSamples); ok {
+					samples = s
+				}
+			}
+			captureVal(frame.RefID, frame.Fields[0].Labels, v, samples)
 		}
 
 		if refID == c.Condition {
@@ -230,9 +292,36 @@ func executeCondition(ctx AlertExecCtx, c *models.Condition, now time.Time, data
 		}
 	}
 
+	result.Warnings = uniqueSorted(result.Warnings)
+	return result
+}
+
+// uniqueSorted sorts the strings and removes the duplicates, an expression can return the frames of its input.
+func uniqueSorted(s []string) []string {
+	sort.Strings(s)
+	result := s[:0]
+	for i, v := range s {
+		if i == 0 || v != s[i-1] {
+			result = append(result, v)
+		}
+	}
 	return result
 }
 
+// frameWarnings returns the text of the warning notices of the frame.
+func frameWarnings(frame *data.Frame) []string {
+	if frame.Meta == nil {
+		return nil
+	}
+	var warnings []string
+	for _, n := range frame.Meta.Notices {
+		if n.Severity == data.NoticeSeverityWarning {
+			warnings = append(warnings, n.Text)
+		}
+	}
+	return warnings
+}
+
 func executeQueriesAndExpressions(ctx AlertExecCtx, data []models.AlertQuery, now time.Time, dataService *tsdb.Service) (resp *backend.QueryDataResponse, err error) {
 	defer func() {
 		if e := recover(); e != nil {
@@ -251,6 +340,13 @@ func executeQueriesAndExpressions(ctx AlertExecCtx, data []models.AlertQuery, no
 		return nil, err
 	}
 
+	// No data source is queried with fixtures.
+	if ctx.Fixtures == nil {
+		if err := checkDatasourcePermissions(ctx.Ctx, ctx.OrgID, ctx.OwnerUserID, data); err != nil {
+			return nil, err
+		}
+	}
+
 	exprService := expr.Service{
 		Cfg:         &setting.Cfg{ExpressionsEnabled: ctx.ExpressionsEnabled},
 		DataService: dataService,
@@ -384,9 +480,20 @@ func evaluateExecutionResult(execResults ExecutionResults, ts time.Time) Results
 		seenLabels[labelsStr] = true
 	}
 
+	for i := range evalResults {
+		evalResults[i].Warnings = execResults.Warnings
+	}
 	return evalResults
 }
 
+// Warnings returns the warnings of the evaluation.
+func (evalResults Results) Warnings() []string {
+	if len(evalResults) == 0 {
+		return nil
+	}
+	return evalResults[0].Warnings
+}
+
 // AsDataFrame forms the EvalResults in Frame suitable for displaying in the table panel of the front end.
 // It displays one row per alert instance, with a column for each label and one for the alerting state.
 func (evalResults Results) AsDataFrame() data.Frame {
@@ -431,25 +538,84 @@ func (evalResults Results) AsDataFrame() data.Frame {
 	return *frame
 }
 
+// resultLimits returns the limits of the results of the queries of the configuration.
+func (e *Evaluator) resultLimits() expr.ResultLimits {
+	return expr.ResultLimits{
+		MaxFrames:     e.Cfg.MaxQueryResultFrames,
+		MaxSeries:     e.Cfg.MaxQueryResultSeries,
+		MaxDataPoints: e.Cfg.MaxQueryResultDataPoints,
+	}
+}
+
+// spillOptions returns the options of the spilling of the results of the queries of the configuration.
+func (e *Evaluator) spillOptions() expr.SpillOptions {
+	return expr.SpillOptions{
+		Threshold: int64(e.Cfg.QueryResultSpillThresholdMB) << 20,
+		Dir:       e.Cfg.QueryResultSpillDir,
+	}
+}
+
 // ConditionEval executes conditions and evaluates the result.
 func (e *Evaluator) ConditionEval(condition *models.Condition, now time.Time, dataService *tsdb.Service) (Results, error) {
-	alertCtx, cancelFn := context.WithTimeout(context.Background(), alertingEvaluationTimeout)
+	return e.conditionEval(context.Background(), condition, now, dataService), nil
+}
+
+// ConditionEvalWithContext executes conditions and evaluates the result, with the spans of the queries and
+// expressions children of the span of the context.
+func (e *Evaluator) ConditionEvalWithContext(ctx context.Context, condition *models.Condition, now time.Time, dataService *tsdb.Service) (Results, error) {
+	return e.conditionEval(ctx, condition, now, dataService), nil
+}
+
+// ConditionEvalWithTrace executes conditions and evaluates the result, and returns the trace of the execution of the
+// queries and expressions.
+func (e *Evaluator) ConditionEvalWithTrace(condition *models.Condition, now time.Time, dataService *tsdb.Service) (Results, []expr.NodeTrace) {
+	ctx, trace := expr.WithTrace(context.Background())
+	evalResults := e.conditionEval(ctx, condition, now, dataService)
+	return evalResults, trace.Nodes()
+}
+
+// ConditionEvalWithFixtures executes conditions with the frames of the fixtures as the results of the data source
+// queries, by RefID, and evaluates the result.
+func (e *Evaluator) ConditionEvalWithFixtures(condition *models.Condition, now time.Time, fixtures map[string]data.Frames) Results {
+	if fixtures == nil {
+		fixtures = map[string]data.Frames{}
+	}
+	return e.conditionEvalWithFixtures(context.Background(), condition, now, nil, fixtures)
+}
+
+func (e *Evaluator) conditionEval(ctx context.Context, condition *models.Condition, now time.Time, dataService *tsdb.Service) Results {
+	return e.conditionEvalWithFixtures(ctx, condition, now, dataService, nil)
+}
+
+func (e *Evaluator) conditionEvalWithFixtures(ctx context.Context, condition *models.Condition, now time.Time, dataService *tsdb.Service, fixtures map[string]data.Frames) Results {
+	alertCtx, cancelFn := context.WithTimeout(ctx, alertingEvaluationTimeout)
 	defer cancelFn()
 
-	alertExecCtx := AlertExecCtx{OrgID: condition.OrgID, Ctx: alertCtx, ExpressionsEnabled: e.Cfg.ExpressionsEnabled, Log: e.Log}
+	alertExecCtx := AlertExecCtx{OrgID: condition.OrgID, OwnerUserID: condition.OwnerUserID, Ctx: alertCtx, ExpressionsEnabled: e.Cfg.ExpressionsEnabled, ResultLimits: e.resultLimits(), SpillOptions: e.spillOptions(), MaxQueryRange: e.Cfg.AlertingLimitsForOrg(condition.OrgID).MaxQueryRange, Fixtures: fixtures, Log: e.Log}
 
 	execResult := executeCondition(alertExecCtx, condition, now, dataService)
 
-	evalResults := evaluateExecutionResult(execResult, now)
-	return evalResults, nil
+	return evaluateExecutionResult(execResult, now)
 }
 
 // QueriesAndExpressionsEval executes queries and expressions and returns the result.
 func (e *Evaluator) QueriesAndExpressionsEval(orgID int64, data []models.AlertQuery, now time.Time, dataService *tsdb.Service) (*backend.QueryDataResponse, error) {
-	alertCtx, cancelFn := context.WithTimeout(context.Background(), alertingEvaluationTimeout)
+	return e.queriesAndExpressionsEval(context.Background(), orgID, data, now, dataService)
+}
+
+// QueriesAndExpressionsEvalWithTrace executes queries and expressions and returns the result, and the trace of the
+// execution, which has the node that failed if the execution failed.
+func (e *Evaluator) QueriesAndExpressionsEvalWithTrace(orgID int64, data []models.AlertQuery, now time.Time, dataService *tsdb.Service) (*backend.QueryDataResponse, []expr.NodeTrace, error) {
+	ctx, trace := expr.WithTrace(context.Background())
+	execResult, err := e.queriesAndExpressionsEval(ctx, orgID, data, now, dataService)
+	return execResult, trace.Nodes(), err
+}
+
+func (e *Evaluator) queriesAndExpressionsEval(ctx context.Context, orgID int64, data []models.AlertQuery, now time.Time, dataService *tsdb.Service) (*backend.QueryDataResponse, error) {
+	alertCtx, cancelFn := context.WithTimeout(ctx, alertingEvaluationTimeout)
 	defer cancelFn()
 
-	alertExecCtx := AlertExecCtx{OrgID: orgID, Ctx: alertCtx, ExpressionsEnabled: e.Cfg.ExpressionsEnabled, Log: e.Log}
+	alertExecCtx := AlertExecCtx{OrgID: orgID, Ctx: alertCtx, ExpressionsEnabled: e.Cfg.ExpressionsEnabled, ResultLimits: e.resultLimits(), SpillOptions: e.spillOptions(), MaxQueryRange: e.Cfg.AlertingLimitsForOrg(orgID).MaxQueryRange, Log: e.Log}
 
 	execResult, err := executeQueriesAndExpressions(alertExecCtx, data, now, dataService)
 	if err != nil {
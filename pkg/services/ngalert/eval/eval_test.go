@@ -1,11 +1,13 @@
 package eval
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/stretchr/testify/require"
 	ptr "github.com/xorcare/pointer"
 )
@@ -276,6 +278,33 @@ func TestEvaluateExecutionResult(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "warnings are set on all the results",
+			execResults: ExecutionResults{
+				Results: []*data.Frame{
+					data.NewFrame("",
+						data.NewField("", data.Labels{"a": "b"}, []*float64{ptr.Float64(0)}),
+					),
+					data.NewFrame("",
+						data.NewField("", data.Labels{"a": "c"}, []*float64{ptr.Float64(1)}),
+					),
+				},
+				Warnings: []string{"the result of query A was truncated: 3 series exceed the limit of 2"},
+			},
+			expectResultLength: 2,
+			expectResults: Results{
+				{
+					State:    Normal,
+					Instance: data.Labels{"a": "b"},
+					Warnings: []string{"the result of query A was truncated: 3 series exceed the limit of 2"},
+				},
+				{
+					State:    Alerting,
+					Instance: data.Labels{"a": "c"},
+					Warnings: []string{"the result of query A was truncated: 3 series exceed the limit of 2"},
+				},
+			},
+		},
 	}
 
 	for _, tc := range cases {
@@ -287,6 +316,7 @@ func TestEvaluateExecutionResult(t *testing.T) {
 			for i, r := range res {
 				require.Equal(t, tc.expectResults[i].State, r.State)
 				require.Equal(t, tc.expectResults[i].Instance, r.Instance)
+				require.Equal(t, tc.expectResults[i].Warnings, r.Warnings)
 				if tc.expectResults[i].State == Error {
 					require.EqualError(t, tc.expectResults[i].Error, r.Error.Error())
 				}
@@ -294,3 +324,34 @@ func TestEvaluateExecutionResult(t *testing.T) {
 		})
 	}
 }
+
+func TestGetExprRequestDownsampling(t *testing.T) {
+	query := models.AlertQuery{
+		RefID:             "A",
+		DatasourceUID:     "prometheus",
+		RelativeTimeRange: models.RelativeTimeRange{From: models.Duration(24 * time.Hour)},
+		Model:             json.RawMessage(`{"expr": "up"}`),
+		Downsampling:      &models.QueryDownsampling{MaxDataPoints: 1440},
+	}
+
+	req, err := GetExprRequest(AlertExecCtx{}, []models.AlertQuery{query}, time.Now())
+	require.NoError(t, err)
+	require.Len(t, req.Queries, 1)
+	require.Equal(t, int64(1440), req.Queries[0].MaxDataPoints)
+	require.Equal(t, time.Minute, req.Queries[0].Interval)
+	require.JSONEq(t, `{"expr": "up", "maxDataPoints": 1440, "intervalMs": 60000}`, string(req.Queries[0].JSON))
+}
+
+func TestGetExprRequestFixtures(t *testing.T) {
+	query := models.AlertQuery{
+		RefID:             "A",
+		DatasourceUID:     "prometheus",
+		RelativeTimeRange: models.RelativeTimeRange{From: models.Duration(5 * time.Minute)},
+		Model:             json.RawMessage(`{"expr": "up"}`),
+	}
+	fixtures := map[string]data.Frames{"A": {data.NewFrame("")}}
+
+	req, err := GetExprRequest(AlertExecCtx{Fixtures: fixtures}, []models.AlertQuery{query}, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, fixtures, req.Fixtures)
+}
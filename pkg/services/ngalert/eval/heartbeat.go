@@ -0,0 +1,34 @@
+package eval
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// heartbeatAgeVar is the name of the value with the seconds since the last heartbeat in the results of heartbeat
+// rules.
+const heartbeatAgeVar = "seconds_since_heartbeat"
+
+// EvaluateHeartbeat evaluates the condition of a heartbeat rule, without queries: the rule is alerting when the last
+// heartbeat, received at lastHeartbeat, is older than its timeout.
+func EvaluateHeartbeat(condition models.HeartbeatCondition, lastHeartbeat time.Time, now time.Time) Results {
+	age := now.Sub(lastHeartbeat)
+	seconds := age.Seconds()
+	result := Result{
+		Instance:         data.Labels{},
+		State:            Normal,
+		EvaluatedAt:      now,
+		EvaluationString: fmt.Sprintf("last heartbeat %s ago, timeout %s", age.Truncate(time.Second), condition.Timeout()),
+		Values: map[string]NumberValueCapture{
+			heartbeatAgeVar: {Var: heartbeatAgeVar, Value: &seconds},
+		},
+	}
+	if age > condition.Timeout() {
+		result.State = Alerting
+	}
+	return Results{result}
+}
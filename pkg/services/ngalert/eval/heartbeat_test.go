@@ -0,0 +1,31 @@
+package eval
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestEvaluateHeartbeat(t *testing.T) {
+	now := time.Unix(1000, 0)
+	condition := models.HeartbeatCondition{Token: "token", TimeoutSeconds: 300}
+
+	t.Run("is normal within the timeout", func(t *testing.T) {
+		results := EvaluateHeartbeat(condition, now.Add(-5*time.Minute), now)
+		require.Len(t, results, 1)
+		require.Equal(t, Normal, results[0].State)
+		require.Equal(t, now, results[0].EvaluatedAt)
+		require.Equal(t, 300.0, *results[0].Values["seconds_since_heartbeat"].Value)
+	})
+
+	t.Run("is alerting after the timeout", func(t *testing.T) {
+		results := EvaluateHeartbeat(condition, now.Add(-6*time.Minute), now)
+		require.Len(t, results, 1)
+		require.Equal(t, Alerting, results[0].State)
+		require.Equal(t, 360.0, *results[0].Values["seconds_since_heartbeat"].Value)
+		require.Equal(t, "last heartbeat 6m0s ago, timeout 5m0s", results[0].EvaluationString)
+	})
+}
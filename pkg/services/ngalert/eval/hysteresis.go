@@ -0,0 +1,53 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// WithPreviouslyFiring returns the queries and expressions of a rule with the labels of the numbers that passed its
+// threshold expressions with an exit value at the previous evaluation, so that they're compared with the exit value.
+// previouslyFiring returns the labels for the RefID of an expression. The queries are copies, only the models of the
+// threshold expressions are changed.
+func WithPreviouslyFiring(queries []models.AlertQuery, previouslyFiring func(refID string) []data.Labels) ([]models.AlertQuery, error) {
+	result := make([]models.AlertQuery, 0, len(queries))
+	for _, q := range queries {
+		// the copy has no cached model properties, the model is read again
+		c := models.AlertQuery{
+			RefID:             q.RefID,
+			QueryType:         q.QueryType,
+			RelativeTimeRange: q.RelativeTimeRange,
+			DatasourceUID:     q.DatasourceUID,
+			Model:             q.Model,
+		}
+		if isExpr, _ := q.IsExpression(); isExpr {
+			model, err := withPreviouslyFiring(q.Model, previouslyFiring(q.RefID))
+			if err != nil {
+				return nil, fmt.Errorf("expression %s: %w", q.RefID, err)
+			}
+			c.Model = model
+		}
+		result = append(result, c)
+	}
+	return result, nil
+}
+
+func withPreviouslyFiring(model json.RawMessage, labels []data.Labels) (json.RawMessage, error) {
+	var props map[string]interface{}
+	if err := json.Unmarshal(model, &props); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal query model: %w", err)
+	}
+	if props["type"] != expr.TypeThreshold.String() || props["exit"] == nil {
+		return model, nil
+	}
+	if labels == nil {
+		labels = []data.Labels{}
+	}
+	props[expr.ThresholdPreviouslyFiringKey] = labels
+	return json.Marshal(props)
+}
@@ -0,0 +1,37 @@
+package eval
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestWithPreviouslyFiring(t *testing.T) {
+	queries := []models.AlertQuery{
+		{RefID: "A", DatasourceUID: "prometheus", Model: json.RawMessage(`{"expr":"up"}`)},
+		{RefID: "B", DatasourceUID: expr.DatasourceUID, Model: json.RawMessage(`{"type":"reduce","expression":"A","reducer":"last"}`)},
+		{RefID: "C", DatasourceUID: expr.DatasourceUID, Model: json.RawMessage(`{"type":"threshold","expression":"B","thresholdType":"gt","enter":90,"exit":80}`)},
+		{RefID: "D", DatasourceUID: expr.DatasourceUID, Model: json.RawMessage(`{"type":"threshold","expression":"B","thresholdType":"gt","enter":90}`)},
+	}
+	requested := make([]string, 0)
+	result, err := WithPreviouslyFiring(queries, func(refID string) []data.Labels {
+		requested = append(requested, refID)
+		if refID == "C" {
+			return []data.Labels{{"instance": "a"}}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"B", "C", "D"}, requested)
+
+	require.JSONEq(t, `{"expr":"up"}`, string(result[0].Model))
+	require.JSONEq(t, string(queries[1].Model), string(result[1].Model))
+	require.JSONEq(t, `{"type":"threshold","expression":"B","thresholdType":"gt","enter":90,"exit":80,"previouslyFiring":[{"instance":"a"}]}`, string(result[2].Model))
+	require.JSONEq(t, string(queries[3].Model), string(result[3].Model))
+	require.NotContains(t, string(queries[2].Model), "previouslyFiring")
+}
@@ -0,0 +1,71 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/bus"
+	gfmodels "github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// checkDatasourcePermissions fails if the user, the owner of the queries, cannot query all the data sources of the
+// queries of the organization. The queries without owner are executed with the service identity, which can query
+// all the data sources, and so are the queries when the data source permissions are not available.
+func checkDatasourcePermissions(ctx context.Context, orgID, userID int64, data []models.AlertQuery) error {
+	if userID == 0 {
+		return nil
+	}
+
+	userQuery := gfmodels.GetSignedInUserQuery{UserId: userID, OrgId: orgID}
+	if err := bus.DispatchCtx(ctx, &userQuery); err != nil {
+		if errors.Is(err, gfmodels.ErrUserNotFound) {
+			return fmt.Errorf("%w: the owner of the queries, user %d, does not exist", gfmodels.ErrDataSourceAccessDenied, userID)
+		}
+		return fmt.Errorf("failed to get the owner of the queries: %w", err)
+	}
+	if userQuery.Result.OrgId != orgID {
+		return fmt.Errorf("%w: the owner of the queries, user %d, is not a member of the organization", gfmodels.ErrDataSourceAccessDenied, userID)
+	}
+
+	datasources := make([]*gfmodels.DataSource, 0, len(data))
+	seen := make(map[string]struct{}, len(data))
+	for _, q := range data {
+		isExpr, err := q.IsExpression()
+		if err != nil {
+			return err
+		}
+		if _, ok := seen[q.DatasourceUID]; isExpr || ok {
+			continue
+		}
+		seen[q.DatasourceUID] = struct{}{}
+
+		dsQuery := gfmodels.GetDataSourceQuery{Uid: q.DatasourceUID, OrgId: orgID}
+		if err := bus.DispatchCtx(ctx, &dsQuery); err != nil {
+			return fmt.Errorf("failed to get the data source %s of query %s: %w", q.DatasourceUID, q.RefID, err)
+		}
+		datasources = append(datasources, dsQuery.Result)
+	}
+	if len(datasources) == 0 {
+		return nil
+	}
+
+	filterQuery := gfmodels.DatasourcesPermissionFilterQuery{User: userQuery.Result, Datasources: datasources}
+	if err := bus.DispatchCtx(ctx, &filterQuery); err != nil {
+		if errors.Is(err, bus.ErrHandlerNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to check the data source permissions of the owner of the queries: %w", err)
+	}
+	allowed := make(map[string]struct{}, len(filterQuery.Result))
+	for _, ds := range filterQuery.Result {
+		allowed[ds.Uid] = struct{}{}
+	}
+	for _, ds := range datasources {
+		if _, ok := allowed[ds.Uid]; !ok {
+			return fmt.Errorf("%w: the owner of the queries, user %s, cannot query the data source %s", gfmodels.ErrDataSourceAccessDenied, userQuery.Result.Login, ds.Name)
+		}
+	}
+	return nil
+}
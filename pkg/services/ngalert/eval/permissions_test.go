@@ -0,0 +1,76 @@
+package eval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	gfmodels "github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestCheckDatasourcePermissions(t *testing.T) {
+	queries := []models.AlertQuery{
+		{RefID: "A", DatasourceUID: "prometheus"},
+		{RefID: "B", DatasourceUID: "loki"},
+		{RefID: "C", DatasourceUID: "-100"},
+	}
+
+	setup := func(t *testing.T, allowed ...string) {
+		t.Cleanup(bus.ClearBusHandlers)
+		bus.AddHandlerCtx("test", func(ctx context.Context, q *gfmodels.GetSignedInUserQuery) error {
+			if q.UserId != 1 {
+				return gfmodels.ErrUserNotFound
+			}
+			q.Result = &gfmodels.SignedInUser{UserId: 1, OrgId: q.OrgId, Login: "editor"}
+			return nil
+		})
+		bus.AddHandler("test", func(q *gfmodels.GetDataSourceQuery) error {
+			q.Result = &gfmodels.DataSource{Uid: q.Uid, Name: q.Uid, OrgId: q.OrgId}
+			return nil
+		})
+		if allowed == nil {
+			return
+		}
+		bus.AddHandler("test", func(q *gfmodels.DatasourcesPermissionFilterQuery) error {
+			for _, ds := range q.Datasources {
+				for _, uid := range allowed {
+					if ds.Uid == uid {
+						q.Result = append(q.Result, ds)
+					}
+				}
+			}
+			return nil
+		})
+	}
+
+	t.Run("the service identity can query all the data sources", func(t *testing.T) {
+		setup(t)
+		require.NoError(t, checkDatasourcePermissions(context.Background(), 1, 0, queries))
+	})
+
+	t.Run("the owner can query all the data sources without data source permissions", func(t *testing.T) {
+		setup(t)
+		require.NoError(t, checkDatasourcePermissions(context.Background(), 1, 1, queries))
+	})
+
+	t.Run("the owner can query the data sources it has permissions for", func(t *testing.T) {
+		setup(t, "prometheus", "loki")
+		require.NoError(t, checkDatasourcePermissions(context.Background(), 1, 1, queries))
+	})
+
+	t.Run("fails if the owner cannot query one of the data sources", func(t *testing.T) {
+		setup(t, "prometheus")
+		err := checkDatasourcePermissions(context.Background(), 1, 1, queries)
+		require.ErrorIs(t, err, gfmodels.ErrDataSourceAccessDenied)
+		require.Contains(t, err.Error(), "cannot query the data source loki")
+	})
+
+	t.Run("fails if the owner does not exist", func(t *testing.T) {
+		setup(t, "prometheus", "loki")
+		err := checkDatasourcePermissions(context.Background(), 1, 2, queries)
+		require.ErrorIs(t, err, gfmodels.ErrDataSourceAccessDenied)
+	})
+}
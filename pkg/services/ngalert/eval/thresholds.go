@@ -0,0 +1,56 @@
+package eval
+
+import (
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ApplyThresholds returns the results of a multi-threshold rule, with a result per threshold for each result of the
+// condition, labeled with the labels of the threshold. An alerting result is alerting at the most severe threshold
+// its value passes and normal at the others, so that the alerts of the other thresholds are resolved. The alerting
+// results that pass no threshold are normal, and the no data and error results are not changed.
+func ApplyThresholds(thresholds models.Thresholds, results Results) Results {
+	applied := make(Results, 0, len(results)*len(thresholds.Levels))
+	for _, r := range results {
+		if r.State != Normal && r.State != Alerting {
+			applied = append(applied, r)
+			continue
+		}
+
+		level := -1
+		if r.State == Alerting {
+			v, ok := r.Values[thresholds.RefID]
+			if !ok || v.Value == nil {
+				r.State = Error
+				r.Error = fmt.Errorf("no value of %s to compare with the thresholds", thresholds.RefID)
+				applied = append(applied, r)
+				continue
+			}
+			for i, t := range thresholds.Levels {
+				if thresholds.Passes(*v.Value, t) {
+					level = i
+				}
+			}
+		}
+
+		for i, t := range thresholds.Levels {
+			lr := r
+			lr.Instance = make(data.Labels, len(r.Instance)+len(t.Labels))
+			for k, v := range r.Instance {
+				lr.Instance[k] = v
+			}
+			for k, v := range t.Labels {
+				lr.Instance[k] = v
+			}
+			lr.State = Normal
+			if i == level {
+				lr.State = Alerting
+			}
+			applied = append(applied, lr)
+		}
+	}
+	return applied
+}
@@ -0,0 +1,82 @@
+package eval
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestApplyThresholds(t *testing.T) {
+	thresholds := models.Thresholds{
+		RefID: "B",
+		Type:  models.ThresholdTypeGreaterThan,
+		Levels: []models.Threshold{
+			{Value: 80, Labels: map[string]string{"severity": "warning"}},
+			{Value: 90, Labels: map[string]string{"severity": "critical"}},
+		},
+	}
+	result := func(state State, value float64) Result {
+		return Result{
+			Instance: data.Labels{"instance": "a"},
+			State:    state,
+			Values:   map[string]NumberValueCapture{"B": {Var: "B", Value: &value}},
+		}
+	}
+	states := func(results Results) map[string]State {
+		s := make(map[string]State, len(results))
+		for _, r := range results {
+			s[r.Instance.String()] = r.State
+		}
+		return s
+	}
+
+	t.Run("alerts at the most severe threshold passed", func(t *testing.T) {
+		require.Equal(t, map[string]State{
+			"instance=a, severity=warning":  Normal,
+			"instance=a, severity=critical": Alerting,
+		}, states(ApplyThresholds(thresholds, Results{result(Alerting, 95)})))
+		require.Equal(t, map[string]State{
+			"instance=a, severity=warning":  Alerting,
+			"instance=a, severity=critical": Normal,
+		}, states(ApplyThresholds(thresholds, Results{result(Alerting, 85)})))
+	})
+
+	t.Run("is normal if no threshold is passed or the condition is normal", func(t *testing.T) {
+		normal := map[string]State{
+			"instance=a, severity=warning":  Normal,
+			"instance=a, severity=critical": Normal,
+		}
+		require.Equal(t, normal, states(ApplyThresholds(thresholds, Results{result(Alerting, 50)})))
+		require.Equal(t, normal, states(ApplyThresholds(thresholds, Results{result(Normal, 95)})))
+	})
+
+	t.Run("compares with lt thresholds", func(t *testing.T) {
+		lt := models.Thresholds{
+			RefID: "B",
+			Type:  models.ThresholdTypeLessThan,
+			Levels: []models.Threshold{
+				{Value: 20, Labels: map[string]string{"severity": "warning"}},
+				{Value: 10, Labels: map[string]string{"severity": "critical"}},
+			},
+		}
+		require.Equal(t, map[string]State{
+			"instance=a, severity=warning":  Alerting,
+			"instance=a, severity=critical": Normal,
+		}, states(ApplyThresholds(lt, Results{result(Alerting, 15)})))
+	})
+
+	t.Run("keeps the no data and error results", func(t *testing.T) {
+		results := ApplyThresholds(thresholds, Results{{Instance: data.Labels{"instance": "a"}, State: NoData}})
+		require.Equal(t, map[string]State{"instance=a": NoData}, states(results))
+	})
+
+	t.Run("is an error without the value", func(t *testing.T) {
+		results := ApplyThresholds(thresholds, Results{{Instance: data.Labels{"instance": "a"}, State: Alerting}})
+		require.Len(t, results, 1)
+		require.Equal(t, Error, results[0].State)
+		require.Error(t, results[0].Error)
+	})
+}
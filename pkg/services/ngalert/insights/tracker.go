@@ -0,0 +1,180 @@
+// Package insights tracks the health and the usage of the alert rules: the duration of their evaluations, their
+// failures and their results without data.
+package insights
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+const (
+	// Period is the period of the aggregates of the evaluations.
+	Period = time.Hour
+	// Retention is how long the aggregates are kept in the database.
+	Retention = 7 * 24 * time.Hour
+
+	flushInterval = 5 * time.Minute
+)
+
+// timeNow makes it possible to test usage of time
+var timeNow = time.Now
+
+type insightKey struct {
+	orgID       int64
+	ruleUID     string
+	periodStart int64
+}
+
+// Tracker tracks the evaluations of the alert rules in memory, and adds them periodically to the aggregates of the
+// rules saved in the database for each hour.
+type Tracker struct {
+	store store.InsightsStore
+	log   log.Logger
+
+	// flushMtx makes the reads wait for the tracked evaluations to be saved, for them to be counted once.
+	flushMtx sync.RWMutex
+	mtx      sync.Mutex
+	pending  map[insightKey]*models.AlertRuleInsight
+}
+
+func NewTracker(store store.InsightsStore, logger log.Logger) *Tracker {
+	return &Tracker{
+		store:   store,
+		log:     logger,
+		pending: make(map[insightKey]*models.AlertRuleInsight),
+	}
+}
+
+// RecordEvaluation tracks an evaluation of an alert rule. An evaluation fails if it returns an error or a result in
+// error.
+func (t *Tracker) RecordEvaluation(key models.AlertRuleKey, now time.Time, duration time.Duration, results eval.Results, err error) {
+	i := &models.AlertRuleInsight{
+		OrgID:              key.OrgID,
+		RuleUID:            key.UID,
+		PeriodStart:        now.Truncate(Period).Unix(),
+		Evaluations:        1,
+		DurationSeconds:    duration.Seconds(),
+		MaxDurationSeconds: duration.Seconds(),
+	}
+	var failed, noData bool
+	for _, r := range results {
+		switch r.State {
+		case eval.Error:
+			failed = true
+			if err == nil {
+				err = r.Error
+			}
+		case eval.NoData:
+			noData = true
+		}
+	}
+	if err != nil {
+		failed = true
+		i.LastError, i.LastErrorAt = err.Error(), now.Unix()
+	}
+	if failed {
+		i.Failures = 1
+	}
+	if noData {
+		i.NoData = 1
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.add(i)
+}
+
+func (t *Tracker) add(i *models.AlertRuleInsight) {
+	k := insightKey{orgID: i.OrgID, ruleUID: i.RuleUID, periodStart: i.PeriodStart}
+	if p, ok := t.pending[k]; ok {
+		p.Add(i)
+		return
+	}
+	t.pending[k] = i
+}
+
+// GetInsights returns the insights of the alert rules of the organization evaluated since from, truncated to the
+// hour, by rule UID.
+func (t *Tracker) GetInsights(orgID int64, from time.Time) (map[string]*models.AlertRuleInsight, error) {
+	t.flushMtx.RLock()
+	defer t.flushMtx.RUnlock()
+
+	periodStart := from.Truncate(Period).Unix()
+	q := models.GetAlertRuleInsightsQuery{OrgID: orgID, From: periodStart}
+	if err := t.store.GetAlertRuleInsights(&q); err != nil {
+		return nil, err
+	}
+	insights := make(map[string]*models.AlertRuleInsight)
+	add := func(i *models.AlertRuleInsight) {
+		if existing, ok := insights[i.RuleUID]; ok {
+			existing.Add(i)
+			return
+		}
+		insights[i.RuleUID] = &models.AlertRuleInsight{OrgID: i.OrgID, RuleUID: i.RuleUID, PeriodStart: periodStart}
+		insights[i.RuleUID].Add(i)
+	}
+	for _, i := range q.Result {
+		add(i)
+	}
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	for k, i := range t.pending {
+		if k.orgID == orgID && k.periodStart >= periodStart {
+			add(i)
+		}
+	}
+	return insights, nil
+}
+
+// Run saves the tracked evaluations periodically and deletes the aggregates older than the retention, until the
+// context is done.
+func (t *Tracker) Run(ctx context.Context) error {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			t.flush()
+			if err := t.store.DeleteAlertRuleInsightsBefore(timeNow().Add(-Retention).Unix()); err != nil {
+				t.log.Error("failed to delete the expired insights of the alert rules", "err", err)
+			}
+		case <-ctx.Done():
+			t.flush()
+			return nil
+		}
+	}
+}
+
+// flush saves the tracked evaluations, they are tracked again if they can't be saved.
+func (t *Tracker) flush() {
+	t.flushMtx.Lock()
+	defer t.flushMtx.Unlock()
+
+	t.mtx.Lock()
+	batch := t.pending
+	t.pending = make(map[insightKey]*models.AlertRuleInsight)
+	t.mtx.Unlock()
+	if len(batch) == 0 {
+		return
+	}
+
+	insights := make([]*models.AlertRuleInsight, 0, len(batch))
+	for _, i := range batch {
+		insights = append(insights, i)
+	}
+	if err := t.store.SaveAlertRuleInsights(insights); err != nil {
+		t.log.Error("failed to save the insights of the alert rules", "rules", len(insights), "err", err)
+		t.mtx.Lock()
+		defer t.mtx.Unlock()
+		for _, i := range insights {
+			t.add(i)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package insights
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type fakeInsightsStore struct {
+	saved   []*models.AlertRuleInsight
+	saveErr error
+}
+
+func (f *fakeInsightsStore) GetAlertRuleInsights(query *models.GetAlertRuleInsightsQuery) error {
+	for _, i := range f.saved {
+		if i.OrgID == query.OrgID && i.PeriodStart >= query.From {
+			query.Result = append(query.Result, i)
+		}
+	}
+	return nil
+}
+
+func (f *fakeInsightsStore) SaveAlertRuleInsights(insights []*models.AlertRuleInsight) error {
+	if f.saveErr != nil {
+		return f.saveErr
+	}
+	f.saved = append(f.saved, insights...)
+	return nil
+}
+
+func (f *fakeInsightsStore) DeleteAlertRuleInsightsBefore(int64) error {
+	return nil
+}
+
+func TestTracker(t *testing.T) {
+	store := &fakeInsightsStore{}
+	tracker := NewTracker(store, log.New("test"))
+	key := models.AlertRuleKey{OrgID: 1, UID: "rule"}
+	now := time.Date(2021, 10, 14, 10, 15, 0, 0, time.UTC)
+
+	tracker.RecordEvaluation(key, now.Add(-time.Hour), time.Second, eval.Results{{State: eval.Normal}}, nil)
+	tracker.RecordEvaluation(key, now, 3*time.Second, eval.Results{{State: eval.NoData}}, nil)
+	tracker.RecordEvaluation(key, now, time.Second, eval.Results{{State: eval.Error, Error: errors.New("query failed")}}, nil)
+	tracker.RecordEvaluation(models.AlertRuleKey{OrgID: 2, UID: "other"}, now, time.Second, nil, errors.New("timeout"))
+
+	check := func(t *testing.T) {
+		insights, err := tracker.GetInsights(1, now)
+		require.NoError(t, err)
+		require.Len(t, insights, 1)
+		i := insights["rule"]
+		require.Equal(t, int64(2), i.Evaluations, "the evaluations of the previous hour are not counted")
+		require.Equal(t, int64(1), i.Failures)
+		require.Equal(t, int64(1), i.NoData)
+		require.Equal(t, 4.0, i.DurationSeconds)
+		require.Equal(t, 3.0, i.MaxDurationSeconds)
+		require.Equal(t, "query failed", i.LastError)
+		require.Equal(t, now.Unix(), i.LastErrorAt)
+	}
+
+	t.Run("the evaluations are tracked in memory", check)
+
+	t.Run("the evaluations are tracked again if they can't be saved", func(t *testing.T) {
+		store.saveErr = errors.New("database is locked")
+		tracker.flush()
+		require.Empty(t, store.saved)
+		check(t)
+	})
+
+	t.Run("the evaluations are saved", func(t *testing.T) {
+		store.saveErr = nil
+		tracker.flush()
+		require.Len(t, store.saved, 3)
+		require.Empty(t, tracker.pending)
+		check(t)
+	})
+
+	t.Run("the evaluations are saved when the context is done", func(t *testing.T) {
+		tracker.RecordEvaluation(key, now, time.Second, nil, nil)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.NoError(t, tracker.Run(ctx))
+		require.Len(t, store.saved, 4)
+	})
+}
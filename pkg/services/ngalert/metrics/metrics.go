@@ -44,6 +44,55 @@ type Metrics struct {
 	EvalFailures         *prometheus.CounterVec
 	EvalDuration         *prometheus.SummaryVec
 	GroupRules           *prometheus.GaugeVec
+	// EvalThrottled counts the evaluations delayed by the limit of concurrent evaluations of the organization, and
+	// InstancesLimited the evaluations over the limit of alert instances of the organization.
+	EvalThrottled    *prometheus.CounterVec
+	InstancesLimited *prometheus.CounterVec
+	// SchedulerQueueDepth is the number of evaluations waiting for a worker of the scheduler, SchedulerBusyWorkers
+	// the number of workers evaluating a rule and EvalShed counts the evaluations shed, by reason.
+	SchedulerQueueDepth  prometheus.Gauge
+	SchedulerBusyWorkers prometheus.Gauge
+	EvalShed             *prometheus.CounterVec
+	// EvalHeld counts the evaluations skipped during the maintenance of the datasources of their rule, the alerts of
+	// the rule keeping their state.
+	EvalHeld *prometheus.CounterVec
+	// EvalGroupThrottled counts the evaluations delayed by the limit of concurrent evaluations of the rule group.
+	EvalGroupThrottled *prometheus.CounterVec
+	// LoadedOrgs is the number of organizations whose alert rules are loaded, when they are loaded lazily.
+	LoadedOrgs prometheus.Gauge
+	// SuppressedNotifications counts the notifications not sent because of the maintenance mode.
+	SuppressedNotifications *prometheus.CounterVec
+	// NotificationsOverQuota counts the notifications over the notification quotas of the organization, by
+	// integration type.
+	NotificationsOverQuota *prometheus.CounterVec
+	// EvaluationErrorAlertsBatched counts the alerts of the rules failing to evaluate batched into the summaries of
+	// the error notification window of the organization.
+	EvaluationErrorAlertsBatched prometheus.Counter
+	// AlertEnrichments counts the lookups of the annotations of the alerts notified by the enrichment services, by
+	// result: cached, fetched or failed.
+	AlertEnrichments *prometheus.CounterVec
+	// NotificationAttempts, NotificationSuccesses and NotificationFailures count the attempts to send a notification
+	// by each integration of the receivers, and NotificationLatency is their duration.
+	NotificationAttempts  *prometheus.CounterVec
+	NotificationSuccesses *prometheus.CounterVec
+	NotificationFailures  *prometheus.CounterVec
+	NotificationLatency   *prometheus.HistogramVec
+	// DispatchShardQueued is the number of flushes of alert groups waiting for their dispatch shard, and
+	// DispatchShardFlushDuration the duration of the flushes of each shard.
+	DispatchShardQueued        *prometheus.GaugeVec
+	DispatchShardFlushDuration *prometheus.HistogramVec
+	// ExternalAlertmanagerHealthy is the result of the last health check of each external Alertmanager.
+	ExternalAlertmanagerHealthy *prometheus.GaugeVec
+	// StateRemoteWriteSamples counts the samples of the ALERTS and ALERTS_FOR_STATE series by result: sent, failed
+	// or dropped.
+	StateRemoteWriteSamples *prometheus.CounterVec
+	// RuleEvalDuration, RuleEvalResults and RuleAlerts are the metrics by rule of the rules opting in, see RuleMetrics.
+	RuleEvalDuration *prometheus.HistogramVec
+	RuleEvalResults  *prometheus.CounterVec
+	RuleAlerts       *prometheus.GaugeVec
+	// RuleMetricsDropped counts the evaluations of the rules opting in to the metrics by rule that are not exported
+	// because of the maximum number of rules.
+	RuleMetricsDropped prometheus.Counter
 }
 
 func NewMetrics(r prometheus.Registerer) *Metrics {
@@ -104,6 +153,69 @@ func NewMetrics(r prometheus.Registerer) *Metrics {
 			},
 			[]string{"user"},
 		),
+		EvalThrottled: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "rule_evaluations_throttled_total",
+				Help:      "The total number of rule evaluations delayed by the limit of concurrent evaluations of the organization.",
+			},
+			[]string{"user"},
+		),
+		InstancesLimited: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "rule_evaluations_instances_limited_total",
+				Help:      "The total number of rule evaluations failed by the limit of alert instances of the organization.",
+			},
+			[]string{"user"},
+		),
+		SchedulerQueueDepth: promauto.With(r).NewGauge(prometheus.GaugeOpts{
+			Namespace: "grafana",
+			Subsystem: "alerting",
+			Name:      "scheduler_queue_depth",
+			Help:      "The number of rule evaluations waiting for a worker of the scheduler.",
+		}),
+		SchedulerBusyWorkers: promauto.With(r).NewGauge(prometheus.GaugeOpts{
+			Namespace: "grafana",
+			Subsystem: "alerting",
+			Name:      "scheduler_busy_workers",
+			Help:      "The number of workers of the scheduler evaluating a rule.",
+		}),
+		LoadedOrgs: promauto.With(r).NewGauge(prometheus.GaugeOpts{
+			Namespace: "grafana",
+			Subsystem: "alerting",
+			Name:      "scheduler_loaded_orgs",
+			Help:      "The number of organizations whose alert rules are loaded by the scheduler.",
+		}),
+		EvalShed: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "rule_evaluations_shed_total",
+				Help:      "The total number of rule evaluations shed by the scheduler, because its queue was full or the next evaluation of the rule was due.",
+			},
+			[]string{"user", "reason"},
+		),
+		EvalHeld: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "rule_evaluations_held_total",
+				Help:      "The total number of rule evaluations skipped during the maintenance of the datasources of the rule.",
+			},
+			[]string{"user"},
+		),
+		EvalGroupThrottled: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "rule_evaluations_group_throttled_total",
+				Help:      "The total number of rule evaluations delayed by the limit of concurrent evaluations of the rule group.",
+			},
+			[]string{"user"},
+		),
 		// TODO: once rule groups support multiple rules, consider partitioning
 		// on rule group as well as tenant, similar to loki|cortex.
 		GroupRules: promauto.With(r).NewGaugeVec(
@@ -115,6 +227,151 @@ func NewMetrics(r prometheus.Registerer) *Metrics {
 			},
 			[]string{"user"},
 		),
+		SuppressedNotifications: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "suppressed_notifications_total",
+				Help:      "The total number of notifications suppressed by the maintenance mode.",
+			},
+			[]string{"receiver"},
+		),
+		NotificationsOverQuota: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "notifications_over_quota_total",
+				Help:      "The total number of alerts of the notifications over the notification quotas.",
+			},
+			[]string{"integration"},
+		),
+		EvaluationErrorAlertsBatched: promauto.With(r).NewCounter(
+			prometheus.CounterOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "evaluation_error_alerts_batched_total",
+				Help:      "The total number of alerts of the rules failing to evaluate batched into summaries.",
+			},
+		),
+		AlertEnrichments: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "alert_enrichments_total",
+				Help:      "The total number of lookups of the annotations of the notified alerts by the enrichment services.",
+			},
+			[]string{"result"},
+		),
+		NotificationAttempts: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "notification_attempts_total",
+				Help:      "The total number of attempts to send a notification.",
+			},
+			[]string{"org", "receiver", "integration"},
+		),
+		NotificationSuccesses: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "notification_successes_total",
+				Help:      "The total number of notifications sent.",
+			},
+			[]string{"org", "receiver", "integration"},
+		),
+		NotificationFailures: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "notification_failures_total",
+				Help:      "The total number of attempts to send a notification that failed.",
+			},
+			[]string{"org", "receiver", "integration"},
+		),
+		NotificationLatency: promauto.With(r).NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "notification_latency_seconds",
+				Help:      "The duration of the attempts to send a notification.",
+				Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+			},
+			[]string{"org", "receiver", "integration"},
+		),
+		DispatchShardQueued: promauto.With(r).NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "dispatch_shard_queued_flushes",
+				Help:      "The number of flushes of alert groups waiting for their dispatch shard.",
+			},
+			[]string{"org", "shard"},
+		),
+		DispatchShardFlushDuration: promauto.With(r).NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "dispatch_shard_flush_duration_seconds",
+				Help:      "The duration of the flushes of the alert groups by dispatch shard.",
+				Buckets:   []float64{0.1, 0.5, 1, 5, 10, 30, 60, 300},
+			},
+			[]string{"org", "shard"},
+		),
+		ExternalAlertmanagerHealthy: promauto.With(r).NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "external_alertmanager_healthy",
+				Help:      "Whether the last health check of the external Alertmanager succeeded.",
+			},
+			[]string{"org", "alertmanager"},
+		),
+		StateRemoteWriteSamples: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "state_remote_write_samples_total",
+				Help:      "The total number of samples of the ALERTS and ALERTS_FOR_STATE series remote written, by result.",
+			},
+			[]string{"result"},
+		),
+		RuleEvalDuration: promauto.With(r).NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "rule_evaluation_duration_by_rule_seconds",
+				Help:      "The duration of the evaluations of the rules opting in to the metrics by rule.",
+				Buckets:   prometheus.DefBuckets,
+			},
+			[]string{"org", "rule_uid"},
+		),
+		RuleEvalResults: promauto.With(r).NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "rule_evaluation_results_by_rule_total",
+				Help:      "The total number of results of the evaluations of the rules opting in to the metrics by rule, by state.",
+			},
+			[]string{"org", "rule_uid", "state"},
+		),
+		RuleAlerts: promauto.With(r).NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "rule_alerts_by_rule",
+				Help:      "How many alerts of the rules opting in to the metrics by rule by state.",
+			},
+			[]string{"org", "rule_uid", "state"},
+		),
+		RuleMetricsDropped: promauto.With(r).NewCounter(
+			prometheus.CounterOpts{
+				Namespace: "grafana",
+				Subsystem: "alerting",
+				Name:      "rule_metrics_dropped_evaluations_total",
+				Help:      "The total number of evaluations of the rules opting in to the metrics by rule not exported because of the maximum number of rules.",
+			},
+		),
 	}
 }
 
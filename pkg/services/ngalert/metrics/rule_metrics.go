@@ -0,0 +1,114 @@
+package metrics
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+type ruleMetricsKey struct {
+	orgID   int64
+	ruleUID string
+}
+
+// ruleSeries are the label values of the series exported for a rule, to delete them when the rule stops being
+// exported.
+type ruleSeries struct {
+	results map[string]struct{}
+	alerts  map[string]struct{}
+}
+
+// RuleMetrics exports the metrics by rule of the rules opting in, with a rule_uid label. The rules are exported in
+// the order they opt in, up to a maximum number of rules bounding the cardinality of the metrics; the evaluations of
+// the rules over the maximum are counted by RuleMetricsDropped.
+type RuleMetrics struct {
+	metrics  *Metrics
+	maxRules int
+
+	mtx   sync.Mutex
+	rules map[ruleMetricsKey]*ruleSeries
+}
+
+// NewRuleMetrics returns the metrics by rule of at most maxRules rules, 0 is no limit.
+func NewRuleMetrics(m *Metrics, maxRules int) *RuleMetrics {
+	return &RuleMetrics{
+		metrics:  m,
+		maxRules: maxRules,
+		rules:    make(map[ruleMetricsKey]*ruleSeries),
+	}
+}
+
+// ObserveEvaluation exports the duration of an evaluation of the rule and the number of its results by state.
+func (m *RuleMetrics) ObserveEvaluation(orgID int64, ruleUID string, duration time.Duration, results map[string]int) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	series, ok := m.series(orgID, ruleUID)
+	if !ok {
+		m.metrics.RuleMetricsDropped.Inc()
+		return
+	}
+	org := fmt.Sprint(orgID)
+	m.metrics.RuleEvalDuration.WithLabelValues(org, ruleUID).Observe(duration.Seconds())
+	for state, n := range results {
+		series.results[state] = struct{}{}
+		m.metrics.RuleEvalResults.WithLabelValues(org, ruleUID, state).Add(float64(n))
+	}
+}
+
+// ObserveAlerts exports the number of alerts of the rule by state, the states without alerts are exported as 0.
+func (m *RuleMetrics) ObserveAlerts(orgID int64, ruleUID string, alerts map[string]int) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	series, ok := m.rules[ruleMetricsKey{orgID: orgID, ruleUID: ruleUID}]
+	if !ok {
+		return
+	}
+	org := fmt.Sprint(orgID)
+	for state := range series.alerts {
+		if _, ok := alerts[state]; !ok {
+			m.metrics.RuleAlerts.WithLabelValues(org, ruleUID, state).Set(0)
+		}
+	}
+	for state, n := range alerts {
+		series.alerts[state] = struct{}{}
+		m.metrics.RuleAlerts.WithLabelValues(org, ruleUID, state).Set(float64(n))
+	}
+}
+
+// Forget deletes the metrics of the rule, such as when it is deleted or stops opting in, freeing its place for
+// another rule.
+func (m *RuleMetrics) Forget(orgID int64, ruleUID string) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	key := ruleMetricsKey{orgID: orgID, ruleUID: ruleUID}
+	series, ok := m.rules[key]
+	if !ok {
+		return
+	}
+	org := fmt.Sprint(orgID)
+	m.metrics.RuleEvalDuration.DeleteLabelValues(org, ruleUID)
+	for state := range series.results {
+		m.metrics.RuleEvalResults.DeleteLabelValues(org, ruleUID, state)
+	}
+	for state := range series.alerts {
+		m.metrics.RuleAlerts.DeleteLabelValues(org, ruleUID, state)
+	}
+	delete(m.rules, key)
+}
+
+// series returns the series of the rule, adding the rule if it is under the maximum number of rules.
+func (m *RuleMetrics) series(orgID int64, ruleUID string) (*ruleSeries, bool) {
+	key := ruleMetricsKey{orgID: orgID, ruleUID: ruleUID}
+	if series, ok := m.rules[key]; ok {
+		return series, true
+	}
+	if m.maxRules > 0 && len(m.rules) >= m.maxRules {
+		return nil, false
+	}
+	series := &ruleSeries{results: make(map[string]struct{}), alerts: make(map[string]struct{})}
+	m.rules[key] = series
+	return series, true
+}
@@ -0,0 +1,50 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleMetrics(t *testing.T) {
+	m := NewMetrics(prometheus.NewRegistry())
+	rm := NewRuleMetrics(m, 2)
+
+	rm.ObserveEvaluation(1, "a", time.Second, map[string]int{"normal": 2, "alerting": 1})
+	rm.ObserveEvaluation(1, "a", time.Second, map[string]int{"alerting": 1})
+	rm.ObserveEvaluation(2, "b", time.Second, map[string]int{"error": 1})
+	rm.ObserveAlerts(1, "a", map[string]int{"normal": 2, "alerting": 1})
+
+	require.Equal(t, 2.0, testutil.ToFloat64(m.RuleEvalResults.WithLabelValues("1", "a", "alerting")))
+	require.Equal(t, 2.0, testutil.ToFloat64(m.RuleEvalResults.WithLabelValues("1", "a", "normal")))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.RuleEvalResults.WithLabelValues("2", "b", "error")))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.RuleAlerts.WithLabelValues("1", "a", "alerting")))
+
+	t.Run("the rules over the maximum are dropped", func(t *testing.T) {
+		rm.ObserveEvaluation(1, "c", time.Second, map[string]int{"normal": 1})
+		rm.ObserveAlerts(1, "c", map[string]int{"normal": 1})
+		require.Equal(t, 1.0, testutil.ToFloat64(m.RuleMetricsDropped))
+		require.Equal(t, 3, testutil.CollectAndCount(m.RuleEvalResults))
+		require.Equal(t, 2, testutil.CollectAndCount(m.RuleAlerts))
+	})
+
+	t.Run("the states without alerts are 0", func(t *testing.T) {
+		rm.ObserveAlerts(1, "a", map[string]int{"normal": 3})
+		require.Equal(t, 0.0, testutil.ToFloat64(m.RuleAlerts.WithLabelValues("1", "a", "alerting")))
+		require.Equal(t, 3.0, testutil.ToFloat64(m.RuleAlerts.WithLabelValues("1", "a", "normal")))
+	})
+
+	t.Run("forgetting a rule deletes its series and frees its place", func(t *testing.T) {
+		rm.Forget(1, "a")
+		require.Equal(t, 1, testutil.CollectAndCount(m.RuleEvalResults))
+		require.Equal(t, 0, testutil.CollectAndCount(m.RuleAlerts))
+		require.Equal(t, 1, testutil.CollectAndCount(m.RuleEvalDuration))
+
+		rm.ObserveEvaluation(1, "c", time.Second, map[string]int{"normal": 1})
+		require.Equal(t, 1.0, testutil.ToFloat64(m.RuleEvalResults.WithLabelValues("1", "c", "normal")))
+		require.Equal(t, 1.0, testutil.ToFloat64(m.RuleMetricsDropped))
+	})
+}
@@ -2,9 +2,49 @@ package models
 
 import (
 	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// AlertmanagersChoice is which Alertmanagers the alerts of an organization are sent to.
+type AlertmanagersChoice int
+
+const (
+	// AllAlertmanagers sends the alerts to the embedded Alertmanager and the external ones.
+	AllAlertmanagers AlertmanagersChoice = iota
+	// InternalAlertmanager only sends the alerts to the embedded Alertmanager.
+	InternalAlertmanager
+	// ExternalAlertmanagers only sends the alerts to the external Alertmanagers.
+	ExternalAlertmanagers
 )
 
+var alertmanagersChoiceNames = map[AlertmanagersChoice]string{
+	AllAlertmanagers:      "all",
+	InternalAlertmanager:  "internal",
+	ExternalAlertmanagers: "external",
+}
+
+func (c AlertmanagersChoice) String() string {
+	return alertmanagersChoiceNames[c]
+}
+
+// ParseAlertmanagersChoice returns the AlertmanagersChoice of its name, an empty name is AllAlertmanagers.
+func ParseAlertmanagersChoice(name string) (AlertmanagersChoice, error) {
+	if name == "" {
+		return AllAlertmanagers, nil
+	}
+	for c, n := range alertmanagersChoiceNames {
+		if n == name {
+			return c, nil
+		}
+	}
+	return AllAlertmanagers, fmt.Errorf("unknown Alertmanagers choice %q, should be one of all, internal or external", name)
+}
+
 // AdminConfiguration represents the ngalert administration configuration settings.
 type AdminConfiguration struct {
 	ID    int64 `xorm:"pk autoincr 'id'"`
@@ -13,12 +53,75 @@ type AdminConfiguration struct {
 	// List of Alertmanager(s) URL to push alerts to.
 	Alertmanagers []string
 
+	// Auth is how the alerts pushed to the Alertmanager(s) are authenticated, on top of the basic
+	// authentication of their URL.
+	Auth *ExternalAlertmanagerAuth `xorm:"json"`
+
+	// SendAlertsTo is which Alertmanagers the alerts are sent to.
+	SendAlertsTo AlertmanagersChoice `xorm:"send_alerts_to"`
+
 	CreatedAt int64 `xorm:"created"`
 	UpdatedAt int64 `xorm:"updated"`
 }
 
+// ExternalAlertmanagerAuth is the authentication of the alerts pushed to the external Alertmanager(s).
+type ExternalAlertmanagerAuth struct {
+	TLS    *ExternalAlertmanagerTLS    `json:"tls,omitempty"`
+	OAuth2 *ExternalAlertmanagerOAuth2 `json:"oauth2,omitempty"`
+}
+
+// ExternalAlertmanagerTLS is the TLS configuration of the connections to the external Alertmanager(s).
+// The certificates and key are PEM encoded.
+type ExternalAlertmanagerTLS struct {
+	CACert     string `json:"caCert,omitempty"`
+	ClientCert string `json:"clientCert,omitempty"`
+	// ClientKey is encrypted with the secret key of Grafana.
+	ClientKey          string `json:"clientKey,omitempty"`
+	ServerName         string `json:"serverName,omitempty"`
+	InsecureSkipVerify bool   `json:"insecureSkipVerify,omitempty"`
+}
+
+// ExternalAlertmanagerOAuth2 is the OAuth2 client credentials flow used to get the tokens of the requests.
+type ExternalAlertmanagerOAuth2 struct {
+	ClientID string `json:"clientId"`
+	// ClientSecret is encrypted with the secret key of Grafana.
+	ClientSecret string   `json:"clientSecret,omitempty"`
+	TokenURL     string   `json:"tokenUrl"`
+	Scopes       []string `json:"scopes,omitempty"`
+}
+
 func (ac *AdminConfiguration) AsSHA256() string {
 	h := sha256.New()
 	_, _ = h.Write([]byte(fmt.Sprintf("%v", ac.Alertmanagers)))
+	_, _ = h.Write([]byte(ac.SendAlertsTo.String()))
+	if ac.Auth != nil {
+		auth, _ := json.Marshal(ac.Auth)
+		_, _ = h.Write(auth)
+	}
 	return fmt.Sprintf("%x", h.Sum(nil))
 }
+
+// EncryptSecret encrypts a secret of the admin configuration before it's stored.
+func EncryptSecret(secret string) (string, error) {
+	encrypted, err := util.Encrypt([]byte(secret), setting.SecretKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt secret: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(encrypted), nil
+}
+
+// DecryptSecret decrypts a secret of the admin configuration encrypted with EncryptSecret.
+func DecryptSecret(secret string) (string, error) {
+	if secret == "" {
+		return "", nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode secret: %w", err)
+	}
+	decrypted, err := util.Decrypt(decoded, setting.SecretKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret: %w", err)
+	}
+	return string(decrypted), nil
+}
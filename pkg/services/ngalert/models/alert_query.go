@@ -42,17 +42,21 @@ func (d *Duration) UnmarshalJSON(b []byte) error {
 type RelativeTimeRange struct {
 	From Duration `json:"from"`
 	To   Duration `json:"to"`
+	// TimeShift moves the time range back, such as a day to compare with the same time yesterday. The times of
+	// the results are moved forward by the same duration, so they can be compared with the other queries.
+	TimeShift Duration `json:"timeShift,omitempty"`
 }
 
-// isValid checks that From duration is greater than To duration.
+// isValid checks that From duration is greater than To duration, and that the time shift is not negative.
 func (rtr *RelativeTimeRange) isValid() bool {
-	return rtr.From > rtr.To
+	return rtr.From > rtr.To && rtr.TimeShift >= 0
 }
 
 func (rtr *RelativeTimeRange) ToTimeRange(now time.Time) backend.TimeRange {
+	shifted := now.Add(-time.Duration(rtr.TimeShift))
 	return backend.TimeRange{
-		From: now.Add(-time.Duration(rtr.From)),
-		To:   now.Add(-time.Duration(rtr.To)),
+		From: shifted.Add(-time.Duration(rtr.From)),
+		To:   shifted.Add(-time.Duration(rtr.To)),
 	}
 }
 
@@ -74,9 +78,40 @@ type AlertQuery struct {
 	// JSON is the raw JSON query and includes the above properties as well as custom properties.
 	Model json.RawMessage `json:"model"`
 
+	// Downsampling requests fewer data points to the datasource over long time ranges, nil is no downsampling.
+	Downsampling *QueryDownsampling `json:"downsampling,omitempty"`
+
 	modelProps map[string]interface{}
 }
 
+// QueryDownsampling limits the data points of a query requested to its datasource.
+type QueryDownsampling struct {
+	// MaxDataPoints is the maximum number of data points of each series, 0 is the maximum of the query model.
+	MaxDataPoints int64 `json:"maxDataPoints,omitempty"`
+	// MinInterval is the minimum interval between the data points, 0 is the interval of the query model.
+	MinInterval Duration `json:"minInterval,omitempty"`
+}
+
+// Apply returns the maximum number of data points and the interval requested for a time range of length r, from
+// those of the query model. The interval is widened so that the time range has at most the maximum number of data
+// points.
+func (d QueryDownsampling) Apply(r time.Duration, maxDataPoints int64, interval time.Duration) (int64, time.Duration) {
+	if d.MaxDataPoints > 0 && (maxDataPoints <= 0 || d.MaxDataPoints < maxDataPoints) {
+		maxDataPoints = d.MaxDataPoints
+	}
+	if minInterval := time.Duration(d.MinInterval); minInterval > interval {
+		interval = minInterval
+	}
+	if maxDataPoints > 0 {
+		// rounded up to the millisecond, the precision of the interval of the query model
+		step := (r + time.Duration(maxDataPoints) - 1) / time.Duration(maxDataPoints)
+		if step = (step + time.Millisecond - 1).Truncate(time.Millisecond); step > interval {
+			interval = step
+		}
+	}
+	return maxDataPoints, interval
+}
+
 func (aq *AlertQuery) setModelProps() error {
 	aq.modelProps = make(map[string]interface{})
 	err := json.Unmarshal(aq.Model, &aq.modelProps)
@@ -235,5 +270,14 @@ func (aq *AlertQuery) PreSave() error {
 	if ok := isExpression || aq.RelativeTimeRange.isValid(); !ok {
 		return fmt.Errorf("invalid relative time range: %+v", aq.RelativeTimeRange)
 	}
+
+	if d := aq.Downsampling; d != nil {
+		if isExpression {
+			return fmt.Errorf("query %s: the expressions cannot be downsampled", aq.RefID)
+		}
+		if d.MaxDataPoints < 0 || d.MinInterval < 0 {
+			return fmt.Errorf("query %s: invalid downsampling: the maximum number of data points and the minimum interval must not be negative", aq.RefID)
+		}
+	}
 	return nil
 }
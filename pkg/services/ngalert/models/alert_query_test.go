@@ -6,6 +6,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -245,3 +247,41 @@ func TestAlertQueryMarshalling(t *testing.T) {
 		}
 	}
 }
+
+func TestRelativeTimeRangeTimeShift(t *testing.T) {
+	now := time.Date(2021, 10, 12, 10, 0, 0, 0, time.UTC)
+	rtr := RelativeTimeRange{From: Duration(5 * time.Minute), TimeShift: Duration(24 * time.Hour)}
+	require.True(t, rtr.isValid())
+	require.Equal(t, backend.TimeRange{
+		From: time.Date(2021, 10, 11, 9, 55, 0, 0, time.UTC),
+		To:   time.Date(2021, 10, 11, 10, 0, 0, 0, time.UTC),
+	}, rtr.ToTimeRange(now))
+
+	rtr.TimeShift = Duration(-time.Hour)
+	require.False(t, rtr.isValid())
+}
+
+func TestQueryDownsampling(t *testing.T) {
+	t.Run("the interval is widened to the maximum number of data points", func(t *testing.T) {
+		maxDataPoints, interval := QueryDownsampling{MaxDataPoints: 1000}.Apply(7*24*time.Hour, 43200, time.Second)
+		require.Equal(t, int64(1000), maxDataPoints)
+		require.Equal(t, 604800*time.Millisecond, interval)
+	})
+
+	t.Run("the minimum interval applies to short time ranges", func(t *testing.T) {
+		maxDataPoints, interval := QueryDownsampling{MinInterval: Duration(time.Minute)}.Apply(time.Hour, 43200, time.Second)
+		require.Equal(t, int64(43200), maxDataPoints)
+		require.Equal(t, time.Minute, interval)
+	})
+
+	t.Run("the query model is kept when it requests fewer data points", func(t *testing.T) {
+		maxDataPoints, interval := QueryDownsampling{MaxDataPoints: 1000}.Apply(time.Hour, 100, time.Minute)
+		require.Equal(t, int64(100), maxDataPoints)
+		require.Equal(t, time.Minute, interval)
+	})
+
+	t.Run("the expressions cannot be downsampled", func(t *testing.T) {
+		q := AlertQuery{RefID: "B", DatasourceUID: expr.DatasourceUID, Model: json.RawMessage(`{}`), Downsampling: &QueryDownsampling{MaxDataPoints: 10}}
+		require.Error(t, q.PreSave())
+	})
+}
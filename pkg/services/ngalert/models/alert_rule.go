@@ -9,6 +9,12 @@ import (
 var (
 	// ErrAlertRuleNotFound is an error for an unknown alert rule.
 	ErrAlertRuleNotFound = fmt.Errorf("could not find alert rule")
+	// ErrAlertRuleVersionNotFound is an error for an unknown version of an alert rule.
+	ErrAlertRuleVersionNotFound = errors.New("could not find alert rule version")
+	// ErrAlertRuleVersionConflict is an error for an update of an alert rule that was changed since it was fetched.
+	ErrAlertRuleVersionConflict = errors.New("alert rule version conflict")
+	// ErrRuleGroupExists is an error for a rule group moved to the name of an existing rule group.
+	ErrRuleGroupExists = errors.New("rule group already exists")
 	// ErrAlertRuleFailedGenerateUniqueUID is an error for failure to generate alert rule UID
 	ErrAlertRuleFailedGenerateUniqueUID = errors.New("failed to generate alert rule UID")
 	// ErrCannotEditNamespace is an error returned if the user does not have permissions to edit the namespace
@@ -46,6 +52,24 @@ const (
 const (
 	RuleUIDLabel      = "__alert_rule_uid__"
 	NamespaceUIDLabel = "__alert_rule_namespace_uid__"
+	// RuleMetricsLabel is the label of the rules opting in to the metrics by rule, with the value true.
+	RuleMetricsLabel = "__alert_rule_metrics__"
+	// StateAnnotationsLabel is the label of the rules opting out of the annotations of the changes of the states of
+	// their alerts, with the value false.
+	StateAnnotationsLabel = "__alert_rule_state_annotations__"
+	// IncidentIDLabel is the label of the alerts sent to the Alertmanager with the identifier of the incident they're
+	// correlated into.
+	IncidentIDLabel = "__incident_id__"
+)
+
+const (
+	// ScreenshotURLAnnotation is the private annotation with the public URL of the screenshot of the rule's panel.
+	ScreenshotURLAnnotation = "__alertImageUrl__"
+	// ScreenshotPathAnnotation is the private annotation with the path on disk of the screenshot of the rule's panel.
+	ScreenshotPathAnnotation = "__alertImagePath__"
+	// EvaluationErrorAnnotation is the private annotation with the error of the alerts firing because their rule
+	// failed to evaluate.
+	EvaluationErrorAnnotation = "__evaluation_error__"
 )
 
 // AlertRule is the model for alert rules in unified alerting.
@@ -68,6 +92,35 @@ type AlertRule struct {
 	For         time.Duration
 	Annotations map[string]string
 	Labels      map[string]string
+	// RuleGroupIndex is the position of the rule in its group, starting at 1.
+	RuleGroupIndex int `xorm:"rule_group_idx"`
+	// IsPaused rules are not evaluated.
+	IsPaused bool `xorm:"is_paused"`
+	// Composite is the condition of the composite rules, which have no queries.
+	Composite CompositeCondition `xorm:"composite"`
+	// Thresholds label the alerts of the multi-threshold rules.
+	Thresholds Thresholds `xorm:"thresholds"`
+	// Heartbeat is the condition of the heartbeat rules, which have no queries.
+	Heartbeat HeartbeatCondition `xorm:"heartbeat"`
+	// OwnerUserID is the user whose data source permissions the queries of the rule are executed with, the user who
+	// created the rule or last changed its queries. The queries of the rules without owner, such as the provisioned
+	// and the migrated rules, are executed with the service identity.
+	OwnerUserID int64 `xorm:"owner_user_id"`
+	// Links are the links of the rule to its runbook and dashboard panel.
+	Links RuleLinks `xorm:"links"`
+	// ShadowOf is the UID of the rule the rule is a shadow of. Shadow rules are evaluated alongside their original
+	// rule and keep their state history, but their alerts are not notified.
+	ShadowOf string `xorm:"shadow_of"`
+	// ActiveFrom and ActiveUntil are the activation window of the rule, which is only evaluated from ActiveFrom and
+	// until ActiveUntil when they are set.
+	ActiveFrom  time.Time `xorm:"active_from"`
+	ActiveUntil time.Time `xorm:"active_until"`
+	// HoldOnDatasourceMaintenance rules are not evaluated during the maintenance windows of the datasources of their
+	// queries, and their alerts keep their state until the end of the windows.
+	HoldOnDatasourceMaintenance bool `xorm:"hold_on_datasource_maintenance"`
+	// GroupConcurrency is the maximum number of rules of the rule group evaluated at the same time, 0 is no limit. Like
+	// the interval, it's a setting of the group saved with each of its rules.
+	GroupConcurrency int64 `xorm:"group_concurrency"`
 }
 
 // AlertRuleKey is the alert definition identifier
@@ -85,6 +138,27 @@ func (alertRule *AlertRule) GetKey() AlertRuleKey {
 	return AlertRuleKey{OrgID: alertRule.OrgID, UID: alertRule.UID}
 }
 
+// IsActive returns true if the time is in the activation window of the rule.
+func (alertRule *AlertRule) IsActive(t time.Time) bool {
+	return (alertRule.ActiveFrom.IsZero() || !t.Before(alertRule.ActiveFrom)) &&
+		(alertRule.ActiveUntil.IsZero() || t.Before(alertRule.ActiveUntil))
+}
+
+// IsShadow returns true if the rule is the shadow of another rule, and its alerts are not notified.
+func (alertRule *AlertRule) IsShadow() bool {
+	return alertRule.ShadowOf != ""
+}
+
+// GetAnnotation returns the annotation of the rule, the private annotations of its links taking precedence over the
+// annotations set by the user.
+func (alertRule *AlertRule) GetAnnotation(name string) (string, bool) {
+	if v, ok := alertRule.Links.Annotations()[name]; ok {
+		return v, true
+	}
+	v, ok := alertRule.Annotations[name]
+	return v, ok
+}
+
 // PreSave sets default values and loads the updated model for each alert query.
 func (alertRule *AlertRule) PreSave(timeNow func() time.Time) error {
 	for i, q := range alertRule.Data {
@@ -109,7 +183,9 @@ type AlertRuleVersion struct {
 	RestoredFrom     int64
 	Version          int64
 
-	Created         time.Time
+	Created time.Time
+	// CreatedBy is the login of the user who created the version, or the provisioner.
+	CreatedBy       string
 	Title           string
 	Condition       string
 	Data            []AlertQuery
@@ -121,6 +197,17 @@ type AlertRuleVersion struct {
 	For         time.Duration
 	Annotations map[string]string
 	Labels      map[string]string
+	Composite   CompositeCondition `xorm:"composite"`
+	Thresholds  Thresholds         `xorm:"thresholds"`
+	Heartbeat   HeartbeatCondition `xorm:"heartbeat"`
+	Links       RuleLinks          `xorm:"links"`
+	ShadowOf    string             `xorm:"shadow_of"`
+	ActiveFrom  time.Time          `xorm:"active_from"`
+	ActiveUntil time.Time          `xorm:"active_until"`
+
+	HoldOnDatasourceMaintenance bool `xorm:"hold_on_datasource_maintenance"`
+
+	GroupConcurrency int64 `xorm:"group_concurrency"`
 }
 
 // GetAlertRuleByUIDQuery is the query for retrieving/deleting an alert rule by UID and organisation ID.
@@ -139,6 +226,58 @@ type ListAlertRulesQuery struct {
 	Result []*AlertRule
 }
 
+// ListAlertRulesByIDsQuery is the query for listing alert rules by ID.
+type ListAlertRulesByIDsQuery struct {
+	IDs []int64
+
+	Result []*AlertRule
+}
+
+// AlertRulesSortBy is a column the searched alert rules can be sorted by.
+type AlertRulesSortBy string
+
+const (
+	AlertRulesSortByTitle   AlertRulesSortBy = "title"
+	AlertRulesSortByUpdated AlertRulesSortBy = "updated"
+)
+
+// SearchAlertRulesQuery is the query for searching the alert rules of an organisation.
+type SearchAlertRulesQuery struct {
+	OrgID         int64
+	NamespaceUIDs []string
+	// Text is searched, case insensitively, in the title and the annotations of the rules.
+	Text string
+	// Labels are the labels the rules must have, including the labels they inherit from their folders.
+	Labels map[string]string
+	SortBy AlertRulesSortBy
+	Desc   bool
+
+	Result []*AlertRule
+}
+
+// AlertRulesWatermark changes with every change of the alert rules: the updates increase the sum of the versions,
+// the deletions decrease the count and the new rules get a greater ID.
+type AlertRulesWatermark struct {
+	Count      int64 `xorm:"rule_count"`
+	VersionSum int64 `xorm:"version_sum"`
+	MaxID      int64 `xorm:"max_id"`
+	// FolderDefaults changes with every change of the defaults of the folders, which change the rules of the folders
+	// without changing their versions.
+	FolderDefaults FolderDefaultsWatermark `xorm:"-"`
+}
+
+// FolderDefaultsWatermark changes with every change of the defaults of the folders, like AlertRulesWatermark.
+type FolderDefaultsWatermark struct {
+	Count      int64 `xorm:"defaults_count"`
+	VersionSum int64 `xorm:"version_sum"`
+	MaxID      int64 `xorm:"max_id"`
+}
+
+// GetAlertRulesWatermarkQuery is the query for retrieving the watermark of the alert rules of all organisations.
+type GetAlertRulesWatermarkQuery struct {
+	Result AlertRulesWatermark
+}
+
 // ListNamespaceAlertRulesQuery is the query for listing namespace alert rules
 type ListNamespaceAlertRulesQuery struct {
 	OrgID int64
@@ -148,6 +287,23 @@ type ListNamespaceAlertRulesQuery struct {
 	Result []*AlertRule
 }
 
+// ListAlertRuleVersionsQuery is the query for listing the versions of an alert rule, the latest first.
+type ListAlertRuleVersionsQuery struct {
+	OrgID   int64
+	RuleUID string
+
+	Result []*AlertRuleVersion
+}
+
+// GetAlertRuleVersionQuery is the query for retrieving a version of an alert rule.
+type GetAlertRuleVersionQuery struct {
+	OrgID   int64
+	RuleUID string
+	Version int64
+
+	Result *AlertRuleVersion
+}
+
 // ListRuleGroupAlertRulesQuery is the query for listing rule group alert rules
 type ListRuleGroupAlertRulesQuery struct {
 	OrgID int64
@@ -159,6 +315,13 @@ type ListRuleGroupAlertRulesQuery struct {
 }
 
 // ListOrgRuleGroupsQuery is the query for listing unique rule groups
+// AlertRuleCount is the number of alert rules of an organization.
+type AlertRuleCount struct {
+	OrgID  int64 `xorm:"org_id"`
+	Rules  int64 `xorm:"rules"`
+	Paused int64 `xorm:"paused"`
+}
+
 type ListOrgRuleGroupsQuery struct {
 	OrgID         int64
 	NamespaceUIDs []string
@@ -173,6 +336,9 @@ type Condition struct {
 	// the Data property to get the results for.
 	Condition string `json:"condition"`
 	OrgID     int64  `json:"-"`
+	// OwnerUserID is the user whose data source permissions the queries are executed with, 0 for the service
+	// identity.
+	OwnerUserID int64 `json:"-"`
 
 	// Data is an array of data source queries and/or server side expressions.
 	Data []AlertQuery `json:"data"`
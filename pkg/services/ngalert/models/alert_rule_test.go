@@ -0,0 +1,24 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertRuleIsActive(t *testing.T) {
+	launch := time.Date(2021, 10, 14, 9, 0, 0, 0, time.UTC)
+	end := launch.Add(24 * time.Hour)
+
+	require.True(t, (&AlertRule{}).IsActive(launch), "the rules without activation window are always active")
+
+	rule := &AlertRule{ActiveFrom: launch, ActiveUntil: end}
+	require.False(t, rule.IsActive(launch.Add(-time.Second)))
+	require.True(t, rule.IsActive(launch))
+	require.True(t, rule.IsActive(end.Add(-time.Second)))
+	require.False(t, rule.IsActive(end))
+
+	require.True(t, (&AlertRule{ActiveFrom: launch}).IsActive(end.Add(time.Hour)))
+	require.True(t, (&AlertRule{ActiveUntil: end}).IsActive(launch.Add(-time.Hour)))
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// AlertRuleVersionChange is a field of an alert rule that differs between two versions. The labels and annotations
+// are compared per key, their fields are named labels.<key> and annotations.<key>.
+type AlertRuleVersionChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from,omitempty"`
+	To    interface{} `json:"to,omitempty"`
+}
+
+// Diff returns the fields of the alert rule that changed from the version v to the version other.
+func (v *AlertRuleVersion) Diff(other *AlertRuleVersion) []AlertRuleVersionChange {
+	changes := make([]AlertRuleVersionChange, 0)
+	add := func(field string, from, to interface{}) {
+		if !reflect.DeepEqual(from, to) {
+			changes = append(changes, AlertRuleVersionChange{Field: field, From: from, To: to})
+		}
+	}
+
+	add("title", v.Title, other.Title)
+	add("namespace_uid", v.RuleNamespaceUID, other.RuleNamespaceUID)
+	add("rule_group", v.RuleGroup, other.RuleGroup)
+	add("condition", v.Condition, other.Condition)
+	if !SameQueries(v.Data, other.Data) {
+		changes = append(changes, AlertRuleVersionChange{Field: "data", From: v.Data, To: other.Data})
+	}
+	add("interval_seconds", v.IntervalSeconds, other.IntervalSeconds)
+	add("no_data_state", v.NoDataState, other.NoDataState)
+	add("exec_err_state", v.ExecErrState, other.ExecErrState)
+	add("for", v.For.String(), other.For.String())
+	add("composite", v.Composite, other.Composite)
+	add("thresholds", v.Thresholds, other.Thresholds)
+	add("heartbeat", v.Heartbeat, other.Heartbeat)
+	add("links", v.Links, other.Links)
+	add("shadow_of", v.ShadowOf, other.ShadowOf)
+	add("active_from", windowTime(v.ActiveFrom), windowTime(other.ActiveFrom))
+	add("active_until", windowTime(v.ActiveUntil), windowTime(other.ActiveUntil))
+	add("hold_on_datasource_maintenance", v.HoldOnDatasourceMaintenance, other.HoldOnDatasourceMaintenance)
+	add("group_concurrency", v.GroupConcurrency, other.GroupConcurrency)
+	changes = append(changes, diffMap("labels", v.Labels, other.Labels)...)
+	changes = append(changes, diffMap("annotations", v.Annotations, other.Annotations)...)
+	return changes
+}
+
+// SameQueries compares the queries by their JSON representation, as the models of the queries are raw JSON
+// messages that may be formatted differently.
+func SameQueries(a, b []AlertQuery) bool {
+	aj, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	bj, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	var av, bv interface{}
+	if json.Unmarshal(aj, &av) != nil || json.Unmarshal(bj, &bv) != nil {
+		return false
+	}
+	return reflect.DeepEqual(av, bv)
+}
+
+func diffMap(field string, from, to map[string]string) []AlertRuleVersionChange {
+	keys := make(map[string]struct{}, len(from)+len(to))
+	for k := range from {
+		keys[k] = struct{}{}
+	}
+	for k := range to {
+		keys[k] = struct{}{}
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	changes := make([]AlertRuleVersionChange, 0)
+	for _, k := range sorted {
+		f, fok := from[k]
+		t, tok := to[k]
+		if f == t && fok == tok {
+			continue
+		}
+		change := AlertRuleVersionChange{Field: field + "." + k}
+		if fok {
+			change.From = f
+		}
+		if tok {
+			change.To = t
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// windowTime returns the time of an activation window in UTC, and nil when it's not set.
+func windowTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC()
+}
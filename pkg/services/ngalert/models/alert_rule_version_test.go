@@ -0,0 +1,52 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertRuleVersionDiff(t *testing.T) {
+	v1 := &AlertRuleVersion{
+		Title:       "high latency",
+		Condition:   "A",
+		Data:        []AlertQuery{{RefID: "A", DatasourceUID: "-100", Model: json.RawMessage(`{"type": "math", "expression": "1 > 0"}`)}},
+		For:         time.Minute,
+		NoDataState: NoData,
+		Labels:      map[string]string{"team": "sre", "severity": "warning"},
+	}
+
+	t.Run("the same definitions have no changes", func(t *testing.T) {
+		v2 := *v1
+		v2.Version = 2
+		v2.Data = []AlertQuery{{RefID: "A", DatasourceUID: "-100", Model: json.RawMessage(`{"expression":"1 > 0","type":"math"}`)}}
+		require.Empty(t, v1.Diff(&v2))
+	})
+
+	t.Run("the changed fields are listed", func(t *testing.T) {
+		v2 := *v1
+		v2.Title = "very high latency"
+		v2.For = 5 * time.Minute
+		v2.Labels = map[string]string{"team": "sre", "severity": "critical", "service": "api"}
+		v2.Annotations = map[string]string{"summary": "latency is high"}
+		require.Equal(t, []AlertRuleVersionChange{
+			{Field: "title", From: "high latency", To: "very high latency"},
+			{Field: "for", From: "1m0s", To: "5m0s"},
+			{Field: "labels.service", To: "api"},
+			{Field: "labels.severity", From: "warning", To: "critical"},
+			{Field: "annotations.summary", To: "latency is high"},
+		}, v1.Diff(&v2))
+	})
+
+	t.Run("the activation window is compared in UTC", func(t *testing.T) {
+		launch := time.Date(2021, 10, 14, 9, 0, 0, 0, time.UTC)
+		v2 := *v1
+		v2.ActiveFrom = launch.In(time.FixedZone("CEST", 2*60*60))
+		v3 := *v1
+		v3.ActiveFrom = launch
+		require.Equal(t, []AlertRuleVersionChange{{Field: "active_from", To: launch}}, v1.Diff(&v2))
+		require.Empty(t, v2.Diff(&v3))
+	})
+}
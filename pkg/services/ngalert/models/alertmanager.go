@@ -1,5 +1,10 @@
 package models
 
+import (
+	"crypto/sha256"
+	"fmt"
+)
+
 const AlertConfigurationVersion = 1
 
 // AlertConfiguration represents a single version of the Alerting Engine Configuration.
@@ -13,6 +18,12 @@ type AlertConfiguration struct {
 	OrgID                     int64 `xorm:"org_id"`
 }
 
+// Fingerprint returns the fingerprint of the configuration, the clients send it back with their changes so that
+// they don't overwrite the changes made meanwhile.
+func (c *AlertConfiguration) Fingerprint() string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(c.AlertmanagerConfiguration)))
+}
+
 // GetLatestAlertmanagerConfigurationQuery is the query to get the latest alertmanager configuration.
 type GetLatestAlertmanagerConfigurationQuery struct {
 	OrgID  int64
@@ -25,4 +36,7 @@ type SaveAlertmanagerConfigurationCmd struct {
 	ConfigurationVersion      string
 	Default                   bool
 	OrgID                     int64
+	// FetchedFingerprint is the fingerprint of the configuration the new one was made from, if any. The
+	// configuration is not saved if the latest configuration has another fingerprint.
+	FetchedFingerprint string
 }
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+const (
+	// AlertmanagerStateNotificationLog is the kind of the snapshots of the notification log.
+	AlertmanagerStateNotificationLog = "nflog"
+	// AlertmanagerStateSilences is the kind of the snapshots of the silences.
+	AlertmanagerStateSilences = "silences"
+)
+
+var (
+	// ErrNoAlertmanagerState is an error for when no snapshot of the Alertmanager state is stored.
+	ErrNoAlertmanagerState = errors.New("no Alertmanager state found")
+	// ErrAlertmanagerStateCorrupted is an error for a snapshot that doesn't match its checksum.
+	ErrAlertmanagerStateCorrupted = errors.New("Alertmanager state is corrupted")
+)
+
+// AlertmanagerState is a snapshot of a component of the state of an organization's embedded Alertmanager.
+type AlertmanagerState struct {
+	ID    int64  `xorm:"pk autoincr 'id'" json:"-"`
+	OrgID int64  `xorm:"org_id" json:"orgId"`
+	Kind  string `xorm:"kind" json:"kind"`
+	// Data is the base64 encoded snapshot.
+	Data string `xorm:"data" json:"data"`
+	// Checksum is the hex encoded SHA-256 of the snapshot.
+	Checksum string `xorm:"checksum" json:"checksum"`
+
+	UpdatedAt int64 `xorm:"updated" json:"updatedAt"`
+}
+
+// NewAlertmanagerState returns the state of kind for a snapshot.
+func NewAlertmanagerState(orgID int64, kind string, snapshot []byte) *AlertmanagerState {
+	return &AlertmanagerState{
+		OrgID:    orgID,
+		Kind:     kind,
+		Data:     base64.StdEncoding.EncodeToString(snapshot),
+		Checksum: checksum(snapshot),
+	}
+}
+
+// Snapshot returns the decoded snapshot after verifying its checksum.
+func (s *AlertmanagerState) Snapshot() ([]byte, error) {
+	b, err := base64.StdEncoding.DecodeString(s.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrAlertmanagerStateCorrupted, err)
+	}
+	if checksum(b) != s.Checksum {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrAlertmanagerStateCorrupted)
+	}
+	return b, nil
+}
+
+func checksum(b []byte) string {
+	return fmt.Sprintf("%x", sha256.Sum256(b))
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// CompositeCondition is the condition of a composite rule, which is evaluated over the states of other rules of its
+// organisation instead of queries. A rule is firing when one of its alerts is firing.
+type CompositeCondition struct {
+	// RuleUIDs are the UIDs of the rules the condition is evaluated over.
+	RuleUIDs []string `json:"rule_uids"`
+	// MinFiring is the number of the rules that must be firing for the composite rule to fire, all of them when
+	// it's 0.
+	MinFiring int `json:"min_firing,omitempty"`
+}
+
+// IsComposite returns true if the condition has rules, that is if it's the condition of a composite rule.
+func (c CompositeCondition) IsComposite() bool {
+	return len(c.RuleUIDs) > 0
+}
+
+// Threshold returns the number of the rules that must be firing for the composite rule to fire.
+func (c CompositeCondition) Threshold() int {
+	if c.MinFiring == 0 {
+		return len(c.RuleUIDs)
+	}
+	return c.MinFiring
+}
+
+// Validate checks the condition of the composite rule with UID ruleUID, which can be empty for a new rule.
+func (c CompositeCondition) Validate(ruleUID string) error {
+	seen := make(map[string]struct{}, len(c.RuleUIDs))
+	for _, uid := range c.RuleUIDs {
+		if uid == "" {
+			return fmt.Errorf("%w: composite condition has an empty rule UID", ErrAlertRuleFailedValidation)
+		}
+		if uid == ruleUID {
+			return fmt.Errorf("%w: composite condition refers to its own rule", ErrAlertRuleFailedValidation)
+		}
+		if _, ok := seen[uid]; ok {
+			return fmt.Errorf("%w: composite condition has the rule %q more than once", ErrAlertRuleFailedValidation, uid)
+		}
+		seen[uid] = struct{}{}
+	}
+	if c.MinFiring < 0 || c.MinFiring > len(c.RuleUIDs) {
+		return fmt.Errorf("%w: composite condition min_firing (%d) should be between 0 and the number of rules (%d)", ErrAlertRuleFailedValidation, c.MinFiring, len(c.RuleUIDs))
+	}
+	return nil
+}
+
+// FromDB loads the condition stored in the database as JSON, the rules that are not composite have no condition.
+// FromDB is part of the xorm Conversion interface.
+func (c *CompositeCondition) FromDB(b []byte) error {
+	*c = CompositeCondition{}
+	if len(b) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, c)
+}
+
+// ToDB stores the condition as JSON, and nothing for the rules that are not composite.
+// ToDB is part of the xorm Conversion interface.
+func (c *CompositeCondition) ToDB() ([]byte, error) {
+	if !c.IsComposite() {
+		return nil, nil
+	}
+	return json.Marshal(c)
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompositeCondition(t *testing.T) {
+	t.Run("validates the rules and the minimum", func(t *testing.T) {
+		require.NoError(t, CompositeCondition{RuleUIDs: []string{"a", "b"}, MinFiring: 2}.Validate("c"))
+		require.NoError(t, CompositeCondition{RuleUIDs: []string{"a", "b"}}.Validate(""))
+		for _, c := range []CompositeCondition{
+			{RuleUIDs: []string{"a", "b"}, MinFiring: 3},
+			{RuleUIDs: []string{"a", "b"}, MinFiring: -1},
+			{RuleUIDs: []string{"a", "a"}},
+			{RuleUIDs: []string{"a", ""}},
+			{RuleUIDs: []string{"a", "c"}},
+		} {
+			require.ErrorIs(t, c.Validate("c"), ErrAlertRuleFailedValidation)
+		}
+	})
+
+	t.Run("is stored as JSON, and nothing for the rules that are not composite", func(t *testing.T) {
+		c := CompositeCondition{RuleUIDs: []string{"a", "b"}, MinFiring: 1}
+		b, err := c.ToDB()
+		require.NoError(t, err)
+		var loaded CompositeCondition
+		require.NoError(t, loaded.FromDB(b))
+		require.Equal(t, c, loaded)
+
+		b, err = (&CompositeCondition{}).ToDB()
+		require.NoError(t, err)
+		require.Empty(t, b)
+		require.NoError(t, loaded.FromDB(b))
+		require.False(t, loaded.IsComposite())
+	})
+}
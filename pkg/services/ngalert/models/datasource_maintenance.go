@@ -0,0 +1,65 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/expr"
+)
+
+var (
+	// ErrDatasourceMaintenanceWindowNotFound is an error for an unknown maintenance window of a datasource.
+	ErrDatasourceMaintenanceWindowNotFound = errors.New("datasource maintenance window not found")
+	// ErrDatasourceMaintenanceWindowFailedValidation is an error for an invalid maintenance window of a datasource.
+	ErrDatasourceMaintenanceWindowFailedValidation = errors.New("invalid datasource maintenance window")
+)
+
+// DatasourceMaintenanceWindow is a planned downtime of a datasource. The alert rules depending on the datasource that
+// hold during its maintenance are not evaluated in the window, and their alerts keep their state.
+type DatasourceMaintenanceWindow struct {
+	ID            int64  `xorm:"pk autoincr 'id'"`
+	OrgID         int64  `xorm:"org_id"`
+	DatasourceUID string `xorm:"datasource_uid"`
+	Reason        string `xorm:"reason"`
+	CreatedBy     string `xorm:"created_by"`
+	// StartsAt and EndsAt are the unix timestamps of the start and the end of the window.
+	StartsAt int64 `xorm:"starts_at"`
+	EndsAt   int64 `xorm:"ends_at"`
+
+	CreatedAt int64 `xorm:"created"`
+	UpdatedAt int64 `xorm:"updated"`
+}
+
+// Active returns whether the datasource is in maintenance at t.
+func (w *DatasourceMaintenanceWindow) Active(t time.Time) bool {
+	return w.StartsAt <= t.Unix() && t.Unix() < w.EndsAt
+}
+
+// Validate checks that the window ends after it starts.
+func (w *DatasourceMaintenanceWindow) Validate() error {
+	if w.DatasourceUID == "" {
+		return fmt.Errorf("%w: datasource is required", ErrDatasourceMaintenanceWindowFailedValidation)
+	}
+	if w.EndsAt <= w.StartsAt {
+		return fmt.Errorf("%w: the window should end after it starts", ErrDatasourceMaintenanceWindowFailedValidation)
+	}
+	return nil
+}
+
+// DatasourceUIDs returns the UIDs of the datasources the queries of the rule depend on, without the expressions.
+func (alertRule *AlertRule) DatasourceUIDs() []string {
+	var uids []string
+	seen := map[string]struct{}{}
+	for _, q := range alertRule.Data {
+		if q.DatasourceUID == expr.DatasourceUID {
+			continue
+		}
+		if _, ok := seen[q.DatasourceUID]; ok {
+			continue
+		}
+		seen[q.DatasourceUID] = struct{}{}
+		uids = append(uids, q.DatasourceUID)
+	}
+	return uids
+}
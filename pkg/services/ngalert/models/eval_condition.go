@@ -11,6 +11,8 @@ type EvalAlertConditionCommand struct {
 	Condition string       `json:"condition"`
 	Data      []AlertQuery `json:"data"`
 	Now       time.Time    `json:"now"`
+	// Debug returns the trace of the execution of each query and expression with the instances.
+	Debug bool `json:"debug,omitempty"`
 }
 
 func (cmd *EvalAlertConditionCommand) UnmarshalJSON(b []byte) error {
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+var (
+	// ErrFolderDefaultsNotFound is an error for a folder without defaults.
+	ErrFolderDefaultsNotFound = errors.New("folder has no defaults")
+	// ErrFolderDefaultsFailedValidation is an error for invalid folder defaults.
+	ErrFolderDefaultsFailedValidation = errors.New("invalid folder defaults")
+)
+
+// FolderDefaults are the settings the alert rules of a folder inherit unless they override them. The rules are saved
+// with their own settings only, the defaults are applied when the rules are read, so that their changes apply to the
+// existing rules.
+type FolderDefaults struct {
+	ID        int64  `xorm:"pk autoincr 'id'"`
+	OrgID     int64  `xorm:"org_id"`
+	FolderUID string `xorm:"folder_uid"`
+	// Version is increased by every change of the defaults, for the scheduler to load the rules of the folder again.
+	Version int64
+	// IntervalSeconds is the interval of the rule groups saved without one, the default of the scheduler if 0.
+	IntervalSeconds int64
+	// NoDataState and ExecErrState are the states of the rules created without them, the defaults of the rules if
+	// empty.
+	NoDataState  NoDataState
+	ExecErrState ExecutionErrorState
+	// Labels are added to the labels of the rules, a label of a rule overrides the folder label with the same name.
+	Labels    map[string]string
+	UpdatedBy string
+	UpdatedAt int64 `xorm:"updated"`
+}
+
+// Validate checks that the interval is a multiple of the interval of the scheduler, and the states and labels
+// are valid for a rule.
+func (d *FolderDefaults) Validate(baseInterval time.Duration) error {
+	if d.IntervalSeconds < 0 || d.IntervalSeconds%int64(baseInterval.Seconds()) != 0 {
+		return fmt.Errorf("%w: interval (%v) should be divided exactly by scheduler interval: %v", ErrFolderDefaultsFailedValidation, time.Duration(d.IntervalSeconds)*time.Second, baseInterval)
+	}
+	switch d.NoDataState {
+	case "", Alerting, NoData, OK:
+	default:
+		return fmt.Errorf("%w: unknown no data state %q", ErrFolderDefaultsFailedValidation, d.NoDataState)
+	}
+	switch d.ExecErrState {
+	case "", AlertingErrState:
+	default:
+		return fmt.Errorf("%w: unknown execution error state %q", ErrFolderDefaultsFailedValidation, d.ExecErrState)
+	}
+	for name := range d.Labels {
+		if !model.LabelName(name).IsValid() || strings.HasPrefix(name, "__") {
+			return fmt.Errorf("%w: invalid label name %q", ErrFolderDefaultsFailedValidation, name)
+		}
+	}
+	return nil
+}
+
+// Apply sets the interval and the states the rule doesn't have, and adds the labels the rule doesn't have.
+func (d *FolderDefaults) Apply(rule *AlertRule) {
+	if rule.IntervalSeconds == 0 {
+		rule.IntervalSeconds = d.IntervalSeconds
+	}
+	if rule.NoDataState == "" {
+		rule.NoDataState = d.NoDataState
+	}
+	if rule.ExecErrState == "" {
+		rule.ExecErrState = d.ExecErrState
+	}
+	if len(d.Labels) == 0 {
+		return
+	}
+	labels := make(map[string]string, len(d.Labels)+len(rule.Labels))
+	for k, v := range d.Labels {
+		labels[k] = v
+	}
+	for k, v := range rule.Labels {
+		labels[k] = v
+	}
+	rule.Labels = labels
+}
+
+// Trim removes the interval, the states and the labels of the rule that are the same as the defaults, so that the rule
+// is saved with its own settings and keeps inheriting the defaults when they change.
+func (d *FolderDefaults) Trim(rule *AlertRule) {
+	if d.IntervalSeconds != 0 && rule.IntervalSeconds == d.IntervalSeconds {
+		rule.IntervalSeconds = 0
+	}
+	if d.NoDataState != "" && rule.NoDataState == d.NoDataState {
+		rule.NoDataState = ""
+	}
+	if d.ExecErrState != "" && rule.ExecErrState == d.ExecErrState {
+		rule.ExecErrState = ""
+	}
+	if len(d.Labels) == 0 || len(rule.Labels) == 0 {
+		return
+	}
+	labels := make(map[string]string, len(rule.Labels))
+	for k, v := range rule.Labels {
+		if inherited, ok := d.Labels[k]; !ok || inherited != v {
+			labels[k] = v
+		}
+	}
+	rule.Labels = labels
+}
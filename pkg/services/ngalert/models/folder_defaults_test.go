@@ -0,0 +1,122 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFolderDefaults_Validate(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		defaults FolderDefaults
+		valid    bool
+	}{
+		{
+			desc:     "valid defaults",
+			defaults: FolderDefaults{IntervalSeconds: 60, NoDataState: OK, ExecErrState: AlertingErrState, Labels: map[string]string{"team": "db"}},
+			valid:    true,
+		},
+		{
+			desc:  "no defaults",
+			valid: true,
+		},
+		{
+			desc:     "interval not divided by the scheduler interval",
+			defaults: FolderDefaults{IntervalSeconds: 15},
+		},
+		{
+			desc:     "negative interval",
+			defaults: FolderDefaults{IntervalSeconds: -10},
+		},
+		{
+			desc:     "unknown no data state",
+			defaults: FolderDefaults{NoDataState: "Pending"},
+		},
+		{
+			desc:     "unknown execution error state",
+			defaults: FolderDefaults{ExecErrState: "OK"},
+		},
+		{
+			desc:     "invalid label name",
+			defaults: FolderDefaults{Labels: map[string]string{"the team": "db"}},
+		},
+		{
+			desc:     "reserved label name",
+			defaults: FolderDefaults{Labels: map[string]string{RuleUIDLabel: "a"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := tc.defaults.Validate(10 * time.Second)
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, ErrFolderDefaultsFailedValidation)
+			}
+		})
+	}
+}
+
+func TestFolderDefaults_Apply(t *testing.T) {
+	defaults := FolderDefaults{
+		IntervalSeconds: 300,
+		NoDataState:     OK,
+		ExecErrState:    AlertingErrState,
+		Labels:          map[string]string{"team": "db", "severity": "warning"},
+	}
+
+	t.Run("the rule inherits the defaults it doesn't have", func(t *testing.T) {
+		rule := AlertRule{Labels: map[string]string{"service": "api"}}
+		defaults.Apply(&rule)
+		require.Equal(t, int64(300), rule.IntervalSeconds)
+		require.Equal(t, OK, rule.NoDataState)
+		require.Equal(t, AlertingErrState, rule.ExecErrState)
+		require.Equal(t, map[string]string{"team": "db", "severity": "warning", "service": "api"}, rule.Labels)
+	})
+
+	t.Run("the rule overrides the defaults", func(t *testing.T) {
+		rule := AlertRule{IntervalSeconds: 60, NoDataState: Alerting, Labels: map[string]string{"severity": "critical"}}
+		defaults.Apply(&rule)
+		require.Equal(t, int64(60), rule.IntervalSeconds)
+		require.Equal(t, Alerting, rule.NoDataState)
+		require.Equal(t, map[string]string{"team": "db", "severity": "critical"}, rule.Labels)
+	})
+}
+
+func TestFolderDefaults_Trim(t *testing.T) {
+	defaults := FolderDefaults{
+		IntervalSeconds: 300,
+		NoDataState:     OK,
+		Labels:          map[string]string{"team": "db", "severity": "warning"},
+	}
+
+	t.Run("the rule keeps inheriting the defaults it has", func(t *testing.T) {
+		rule := AlertRule{IntervalSeconds: 300, NoDataState: OK, Labels: map[string]string{"team": "db", "service": "api"}}
+		defaults.Trim(&rule)
+		require.Zero(t, rule.IntervalSeconds)
+		require.Empty(t, rule.NoDataState)
+		require.Equal(t, map[string]string{"service": "api"}, rule.Labels)
+	})
+
+	t.Run("the rule keeps its own settings", func(t *testing.T) {
+		rule := AlertRule{IntervalSeconds: 60, NoDataState: Alerting, ExecErrState: AlertingErrState, Labels: map[string]string{"severity": "critical"}}
+		defaults.Trim(&rule)
+		require.Equal(t, int64(60), rule.IntervalSeconds)
+		require.Equal(t, Alerting, rule.NoDataState)
+		require.Equal(t, AlertingErrState, rule.ExecErrState)
+		require.Equal(t, map[string]string{"severity": "critical"}, rule.Labels)
+	})
+
+	t.Run("the defaults applied to the trimmed rule are the settings of the rule", func(t *testing.T) {
+		rule := AlertRule{IntervalSeconds: 300, NoDataState: Alerting, Labels: map[string]string{"team": "db", "severity": "critical"}}
+		trimmed := rule
+		defaults.Trim(&trimmed)
+		defaults.Apply(&trimmed)
+		require.Equal(t, rule.IntervalSeconds, trimmed.IntervalSeconds)
+		require.Equal(t, rule.NoDataState, trimmed.NoDataState)
+		require.Equal(t, rule.Labels, trimmed.Labels)
+	})
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrHeartbeatNotFound is an error for when no heartbeat rule has a token.
+var ErrHeartbeatNotFound = errors.New("heartbeat not found")
+
+// HeartbeatCondition is the condition of a heartbeat rule, a dead man's switch which is evaluated without queries
+// and fires when no heartbeat has been received for its timeout. The heartbeats are the requests to the heartbeat
+// URL of the rule, sent by a cron job or by the Watchdog alert of an external Alertmanager.
+type HeartbeatCondition struct {
+	// Token is the secret part of the heartbeat URL of the rule, generated when the rule is created.
+	Token string `json:"token,omitempty"`
+	// TimeoutSeconds is the time after the last heartbeat, or the creation of the rule, after which the rule fires.
+	TimeoutSeconds int64 `json:"timeout_seconds"`
+}
+
+// IsHeartbeat returns true if the condition has a timeout, that is if it's the condition of a heartbeat rule.
+func (c HeartbeatCondition) IsHeartbeat() bool {
+	return c.TimeoutSeconds > 0
+}
+
+// Timeout returns the time after the last heartbeat after which the rule fires.
+func (c HeartbeatCondition) Timeout() time.Duration {
+	return time.Duration(c.TimeoutSeconds) * time.Second
+}
+
+// Validate checks the condition of the heartbeat rule evaluated every intervalSeconds.
+func (c HeartbeatCondition) Validate(intervalSeconds int64) error {
+	if c.TimeoutSeconds < intervalSeconds {
+		return fmt.Errorf("%w: heartbeat timeout (%ds) should be at least the interval of the rule (%ds)", ErrAlertRuleFailedValidation, c.TimeoutSeconds, intervalSeconds)
+	}
+	return nil
+}
+
+// FromDB loads the condition stored in the database as JSON, the rules that are not heartbeat rules have no
+// condition.
+// FromDB is part of the xorm Conversion interface.
+func (c *HeartbeatCondition) FromDB(b []byte) error {
+	*c = HeartbeatCondition{}
+	if len(b) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, c)
+}
+
+// ToDB stores the condition as JSON, and nothing for the rules that are not heartbeat rules.
+// ToDB is part of the xorm Conversion interface.
+func (c *HeartbeatCondition) ToDB() ([]byte, error) {
+	if !c.IsHeartbeat() {
+		return nil, nil
+	}
+	return json.Marshal(c)
+}
+
+// AlertRuleHeartbeat is the last heartbeat received by a heartbeat rule.
+type AlertRuleHeartbeat struct {
+	ID      int64  `xorm:"pk autoincr 'id'"`
+	OrgID   int64  `xorm:"org_id"`
+	RuleUID string `xorm:"rule_uid"`
+	Token   string `xorm:"token"`
+	// ReceivedAt is the unix timestamp of the last heartbeat, or of the creation of the rule before the first one.
+	ReceivedAt int64 `xorm:"received_at"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeartbeatCondition(t *testing.T) {
+	t.Run("validates the timeout against the interval", func(t *testing.T) {
+		require.NoError(t, HeartbeatCondition{TimeoutSeconds: 300}.Validate(60))
+		require.NoError(t, HeartbeatCondition{TimeoutSeconds: 60}.Validate(60))
+		require.ErrorIs(t, HeartbeatCondition{TimeoutSeconds: 30}.Validate(60), ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("is stored as JSON, and nothing for the rules that are not heartbeat rules", func(t *testing.T) {
+		c := HeartbeatCondition{Token: "token", TimeoutSeconds: 300}
+		b, err := c.ToDB()
+		require.NoError(t, err)
+		var loaded HeartbeatCondition
+		require.NoError(t, loaded.FromDB(b))
+		require.Equal(t, c, loaded)
+
+		b, err = (&HeartbeatCondition{Token: "token"}).ToDB()
+		require.NoError(t, err)
+		require.Empty(t, b)
+		require.NoError(t, loaded.FromDB(b))
+		require.False(t, loaded.IsHeartbeat())
+	})
+}
@@ -0,0 +1,45 @@
+package models
+
+// AlertRuleInsight aggregates the evaluations of an alert rule over a period, to find the slowest and the most
+// broken rules.
+type AlertRuleInsight struct {
+	ID      int64  `xorm:"pk autoincr 'id'"`
+	OrgID   int64  `xorm:"org_id"`
+	RuleUID string `xorm:"rule_uid"`
+	// PeriodStart is the unix timestamp of the start of the period, an hour.
+	PeriodStart int64 `xorm:"period_start"`
+
+	Evaluations int64 `xorm:"evaluations"`
+	// Failures are the evaluations that failed or had results in error.
+	Failures int64 `xorm:"failures"`
+	// NoData are the evaluations that had results without data.
+	NoData             int64   `xorm:"no_data"`
+	DurationSeconds    float64 `xorm:"duration_seconds"`
+	MaxDurationSeconds float64 `xorm:"max_duration_seconds"`
+	LastError          string  `xorm:"last_error"`
+	// LastErrorAt is the unix timestamp of the last failure, 0 if the rule did not fail.
+	LastErrorAt int64 `xorm:"last_error_at"`
+}
+
+// Add adds the evaluations of other to the insight.
+func (i *AlertRuleInsight) Add(other *AlertRuleInsight) {
+	i.Evaluations += other.Evaluations
+	i.Failures += other.Failures
+	i.NoData += other.NoData
+	i.DurationSeconds += other.DurationSeconds
+	if other.MaxDurationSeconds > i.MaxDurationSeconds {
+		i.MaxDurationSeconds = other.MaxDurationSeconds
+	}
+	if other.LastErrorAt > i.LastErrorAt {
+		i.LastError, i.LastErrorAt = other.LastError, other.LastErrorAt
+	}
+}
+
+// GetAlertRuleInsightsQuery is the query for the insights of the alert rules of an organization.
+type GetAlertRuleInsightsQuery struct {
+	OrgID int64
+	// From is the unix timestamp of the start of the first period returned.
+	From int64
+
+	Result []*AlertRuleInsight
+}
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+)
+
+var (
+	// ErrNoLabelPolicies is an error for when an organization has no label policies.
+	ErrNoLabelPolicies = errors.New("organization has no label policies")
+	// ErrLabelPolicyFailedValidation is an error for an invalid label policy.
+	ErrLabelPolicyFailedValidation = errors.New("invalid label policy")
+)
+
+// LabelPolicyAction is what a label policy does to the labels of the alerts.
+type LabelPolicyAction string
+
+const (
+	// LabelPolicyAdd sets Label to Value, unless the alert has the label and Overwrite is false.
+	LabelPolicyAdd LabelPolicyAction = "add"
+	// LabelPolicyRename renames Label to TargetLabel.
+	LabelPolicyRename LabelPolicyAction = "rename"
+	// LabelPolicyDrop removes Label.
+	LabelPolicyDrop LabelPolicyAction = "drop"
+	// LabelPolicyMap sets TargetLabel to the value of Label in the lookup table of the policy.
+	LabelPolicyMap LabelPolicyAction = "map"
+)
+
+// LabelPolicy rewrites the labels of the alerts of an organization before they are routed, by the embedded
+// Alertmanager as well as by the external ones.
+type LabelPolicy struct {
+	Action LabelPolicyAction `json:"action"`
+	// Matchers select the alerts the policy applies to, in the format of the matchers of the Alertmanager
+	// configuration. The policy applies to every alert if empty.
+	Matchers    []string `json:"matchers,omitempty"`
+	Label       string   `json:"label"`
+	TargetLabel string   `json:"targetLabel,omitempty"`
+	Value       string   `json:"value,omitempty"`
+	// Overwrite replaces the existing value of the label set by an add or a map policy, or by a rename policy.
+	Overwrite bool `json:"overwrite,omitempty"`
+	// Lookup is the table of the values of TargetLabel by value of Label of a map policy.
+	Lookup map[string]string `json:"lookup,omitempty"`
+	// LookupURL is the URL the lookup table of a map policy is fetched from instead, as a CSV file whose two columns
+	// are the value of Label and the value of TargetLabel, or as a JSON object.
+	LookupURL string `json:"lookupUrl,omitempty"`
+	// Default is the value of TargetLabel when the value of Label is not in the lookup table, the label is not set
+	// if empty.
+	Default string `json:"default,omitempty"`
+}
+
+// ParseMatchers returns the matchers selecting the alerts the policy applies to.
+func (p LabelPolicy) ParseMatchers() (labels.Matchers, error) {
+	matchers := make(labels.Matchers, 0, len(p.Matchers))
+	for _, s := range p.Matchers {
+		m, err := labels.ParseMatcher(s)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid matcher %q: %s", ErrLabelPolicyFailedValidation, s, err)
+		}
+		matchers = append(matchers, m)
+	}
+	return matchers, nil
+}
+
+// Validate checks that the policy has the fields of its action.
+func (p LabelPolicy) Validate() error {
+	if !model.LabelName(p.Label).IsValid() {
+		return fmt.Errorf("%w: invalid label %q", ErrLabelPolicyFailedValidation, p.Label)
+	}
+	if _, err := p.ParseMatchers(); err != nil {
+		return err
+	}
+
+	switch p.Action {
+	case LabelPolicyAdd:
+		if p.Value == "" {
+			return fmt.Errorf("%w: an add policy should have a value", ErrLabelPolicyFailedValidation)
+		}
+	case LabelPolicyRename, LabelPolicyMap:
+		if !model.LabelName(p.TargetLabel).IsValid() {
+			return fmt.Errorf("%w: invalid target label %q", ErrLabelPolicyFailedValidation, p.TargetLabel)
+		}
+		if p.TargetLabel == p.Label {
+			return fmt.Errorf("%w: the target label should not be the label", ErrLabelPolicyFailedValidation)
+		}
+		if p.Action == LabelPolicyMap && len(p.Lookup) == 0 && p.LookupURL == "" {
+			return fmt.Errorf("%w: a map policy should have a lookup table or a lookup URL", ErrLabelPolicyFailedValidation)
+		}
+		if p.Action == LabelPolicyMap && len(p.Lookup) > 0 && p.LookupURL != "" {
+			return fmt.Errorf("%w: a map policy should have either a lookup table or a lookup URL, not both", ErrLabelPolicyFailedValidation)
+		}
+	case LabelPolicyDrop:
+	default:
+		return fmt.Errorf("%w: unknown action %q", ErrLabelPolicyFailedValidation, p.Action)
+	}
+
+	// the label of a map policy is only read
+	if isReservedLabel(p.Label) && p.Action != LabelPolicyMap {
+		return fmt.Errorf("%w: label %q is reserved", ErrLabelPolicyFailedValidation, p.Label)
+	}
+	if isReservedLabel(p.TargetLabel) {
+		return fmt.Errorf("%w: label %q is reserved", ErrLabelPolicyFailedValidation, p.TargetLabel)
+	}
+	return nil
+}
+
+// isReservedLabel returns whether the label is one of the labels identifying the rule of the alerts, which are
+// not rewritten.
+func isReservedLabel(label string) bool {
+	return label == RuleUIDLabel || label == NamespaceUIDLabel || label == model.AlertNameLabel
+}
+
+// LabelPolicyList is the ordered list of the label policies of an organization, each policy applies to the labels
+// rewritten by the previous ones.
+type LabelPolicyList []LabelPolicy
+
+// FromDB loads the policies stored in the database as JSON.
+// FromDB is part of the xorm Conversion interface.
+func (l *LabelPolicyList) FromDB(b []byte) error {
+	*l = nil
+	if len(b) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, l)
+}
+
+// ToDB stores the policies as JSON.
+// ToDB is part of the xorm Conversion interface.
+func (l *LabelPolicyList) ToDB() ([]byte, error) {
+	return json.Marshal(l)
+}
+
+// LabelPolicies are the label policies of an organization.
+type LabelPolicies struct {
+	ID        int64           `xorm:"pk autoincr 'id'"`
+	OrgID     int64           `xorm:"org_id"`
+	Policies  LabelPolicyList `xorm:"policies"`
+	UpdatedBy string          `xorm:"updated_by"`
+	UpdatedAt int64           `xorm:"updated"`
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabelPolicy_Validate(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		policy LabelPolicy
+		valid  bool
+	}{
+		{
+			desc:   "add policy",
+			policy: LabelPolicy{Action: LabelPolicyAdd, Matchers: []string{`team=~"a|b"`}, Label: "region", Value: "eu"},
+			valid:  true,
+		},
+		{
+			desc:   "add policy without a value",
+			policy: LabelPolicy{Action: LabelPolicyAdd, Label: "region"},
+		},
+		{
+			desc:   "rename policy",
+			policy: LabelPolicy{Action: LabelPolicyRename, Label: "env", TargetLabel: "environment"},
+			valid:  true,
+		},
+		{
+			desc:   "rename policy to the same label",
+			policy: LabelPolicy{Action: LabelPolicyRename, Label: "env", TargetLabel: "env"},
+		},
+		{
+			desc:   "drop policy",
+			policy: LabelPolicy{Action: LabelPolicyDrop, Label: "instance"},
+			valid:  true,
+		},
+		{
+			desc:   "map policy reading a reserved label",
+			policy: LabelPolicy{Action: LabelPolicyMap, Label: RuleUIDLabel, TargetLabel: "team", Lookup: map[string]string{"uid": "a"}},
+			valid:  true,
+		},
+		{
+			desc:   "map policy without a lookup table",
+			policy: LabelPolicy{Action: LabelPolicyMap, Label: "service", TargetLabel: "team"},
+		},
+		{
+			desc:   "map policy with a lookup table and a lookup URL",
+			policy: LabelPolicy{Action: LabelPolicyMap, Label: "service", TargetLabel: "team", Lookup: map[string]string{"db": "a"}, LookupURL: "http://example.com"},
+		},
+		{
+			desc:   "policy dropping a reserved label",
+			policy: LabelPolicy{Action: LabelPolicyDrop, Label: "alertname"},
+		},
+		{
+			desc:   "policy writing a reserved label",
+			policy: LabelPolicy{Action: LabelPolicyRename, Label: "folder", TargetLabel: NamespaceUIDLabel},
+		},
+		{
+			desc:   "invalid label",
+			policy: LabelPolicy{Action: LabelPolicyDrop, Label: "in-valid"},
+		},
+		{
+			desc:   "invalid matcher",
+			policy: LabelPolicy{Action: LabelPolicyDrop, Matchers: []string{`team`}, Label: "instance"},
+		},
+		{
+			desc:   "unknown action",
+			policy: LabelPolicy{Action: "replace", Label: "instance"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := tc.policy.Validate()
+			if tc.valid {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrLabelPolicyFailedValidation)
+		})
+	}
+}
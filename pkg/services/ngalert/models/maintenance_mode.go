@@ -0,0 +1,28 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// ErrNoMaintenanceMode is an error for when an organization is not in maintenance mode.
+var ErrNoMaintenanceMode = fmt.Errorf("organization is not in maintenance mode")
+
+// MaintenanceMode puts the alerting of an organization into maintenance: alert rules are still
+// evaluated and their state tracked, but no notification is sent until it expires.
+type MaintenanceMode struct {
+	ID        int64  `xorm:"pk autoincr 'id'"`
+	OrgID     int64  `xorm:"org_id"`
+	Reason    string `xorm:"reason"`
+	CreatedBy string `xorm:"created_by"`
+	// ExpiresAt is the unix timestamp after which notifications are sent again.
+	ExpiresAt int64 `xorm:"expires_at"`
+
+	CreatedAt int64 `xorm:"created"`
+	UpdatedAt int64 `xorm:"updated"`
+}
+
+// Active returns whether the maintenance mode is still in effect at t.
+func (m *MaintenanceMode) Active(t time.Time) bool {
+	return m != nil && t.Unix() < m.ExpiresAt
+}
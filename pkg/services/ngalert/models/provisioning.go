@@ -0,0 +1,61 @@
+package models
+
+import "errors"
+
+// ErrProvisionedResource is an error for a change to a provisioned resource that is not made by its provisioner.
+var ErrProvisionedResource = errors.New("the resource is provisioned and cannot be modified")
+
+// Provenance is the origin of a resource. Resources with a provenance are managed outside of Grafana
+// and are not modified from the UI.
+type Provenance string
+
+const (
+	// ProvenanceNone is the provenance of the resources created in Grafana.
+	ProvenanceNone Provenance = ""
+	// ProvenanceFile is the provenance of the resources provisioned from files of the provisioning directory.
+	ProvenanceFile Provenance = "file"
+	// ProvenanceAPI is the provenance of the resources provisioned with the provisioning HTTP API.
+	ProvenanceAPI Provenance = "api"
+	// ProvenanceTerraform is the provenance of the resources provisioned with the provisioning HTTP API by the
+	// Grafana Terraform provider.
+	ProvenanceTerraform Provenance = "terraform"
+	// ProvenanceGit is the provenance of the resources provisioned from the files of the Git repository of the
+	// Git sync.
+	ProvenanceGit Provenance = "git"
+	// ProvenanceUI is the provenance of the provisioned resources taken over in the UI by an admin overriding
+	// their provenance. They are managed in Grafana, until they are provisioned again.
+	ProvenanceUI Provenance = "ui"
+)
+
+// IsProvisioned returns true if the resource is managed outside of Grafana.
+func (p Provenance) IsProvisioned() bool {
+	return p != ProvenanceNone && p != ProvenanceUI
+}
+
+// IsAPI returns true if the resource is provisioned with the provisioning HTTP API.
+func (p Provenance) IsAPI() bool {
+	return p == ProvenanceAPI || p == ProvenanceTerraform
+}
+
+// The types of alerting resources that can be provisioned.
+const (
+	ResourceTypeAlertRule          = "alertRule"
+	ResourceTypeContactPoint       = "contactPoint"
+	ResourceTypeNotificationPolicy = "notificationPolicy"
+	ResourceTypeMuteTiming         = "muteTiming"
+	ResourceTypeTemplate           = "template"
+)
+
+// NotificationPolicyResourceKey is the key of the provenance of the notification policy tree, an organization
+// has a single tree.
+const NotificationPolicyResourceKey = "root"
+
+// ProvenanceRecord is the provenance of a resource, identified by its type and key in the organization.
+// The key is the UID of alert rules and mute timings, and the name of contact points and templates.
+type ProvenanceRecord struct {
+	ID         int64      `xorm:"pk autoincr 'id'"`
+	OrgID      int64      `xorm:"org_id"`
+	RecordKey  string     `xorm:"record_key"`
+	RecordType string     `xorm:"record_type"`
+	Provenance Provenance `xorm:"provenance"`
+}
@@ -0,0 +1,158 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+)
+
+var (
+	// ErrRecurringSilenceNotFound is an error for an unknown recurring silence.
+	ErrRecurringSilenceNotFound = fmt.Errorf("could not find recurring silence")
+	// ErrRecurringSilenceFailedValidation is an error for an invalid recurring silence.
+	ErrRecurringSilenceFailedValidation = errors.New("invalid recurring silence")
+)
+
+// recurrenceSearchDays is how many days ahead we look for the next occurrence of a recurring silence.
+const recurrenceSearchDays = 8
+
+// RecurringSilence is a silence template that ngalert materializes into concrete
+// Alertmanager silences ahead of each of its occurrences.
+type RecurringSilence struct {
+	ID        int64  `xorm:"pk autoincr 'id'"`
+	OrgID     int64  `xorm:"org_id"`
+	UID       string `xorm:"uid"`
+	Matchers  amv2.Matchers
+	Comment   string
+	CreatedBy string
+
+	// Weekdays the silence recurs on. An empty list means every day.
+	Weekdays []time.Weekday
+	// StartTime is the time of day, in the format HH:MM, at which each occurrence starts.
+	StartTime string
+	// DurationSeconds is how long each occurrence lasts.
+	DurationSeconds int64
+	// Timezone is the IANA location used to interpret StartTime. An empty value means UTC.
+	Timezone string
+
+	// LastStartsAt is the start of the last occurrence that was materialized as a silence.
+	LastStartsAt int64
+	// LastSilenceID is the ID of the Alertmanager silence created for the last occurrence.
+	LastSilenceID string `xorm:"last_silence_id"`
+
+	CreatedAt int64 `xorm:"created"`
+	UpdatedAt int64 `xorm:"updated"`
+}
+
+// Validate checks that the recurrence rule of the silence can be evaluated.
+func (s *RecurringSilence) Validate() error {
+	if len(s.Matchers) == 0 {
+		return fmt.Errorf("%w: at least one matcher is required", ErrRecurringSilenceFailedValidation)
+	}
+	for _, m := range s.Matchers {
+		if m.Name == nil || *m.Name == "" {
+			return fmt.Errorf("%w: matcher name is empty", ErrRecurringSilenceFailedValidation)
+		}
+	}
+	if _, _, err := s.parseStartTime(); err != nil {
+		return fmt.Errorf("%w: %s", ErrRecurringSilenceFailedValidation, err)
+	}
+	if _, err := s.location(); err != nil {
+		return fmt.Errorf("%w: invalid timezone %q", ErrRecurringSilenceFailedValidation, s.Timezone)
+	}
+	if s.DurationSeconds <= 0 {
+		return fmt.Errorf("%w: duration should be positive", ErrRecurringSilenceFailedValidation)
+	}
+	if s.DurationSeconds > int64((24 * time.Hour).Seconds()) {
+		return fmt.Errorf("%w: duration should not be greater than 24h", ErrRecurringSilenceFailedValidation)
+	}
+	for _, d := range s.Weekdays {
+		if d < time.Sunday || d > time.Saturday {
+			return fmt.Errorf("%w: invalid weekday %d", ErrRecurringSilenceFailedValidation, d)
+		}
+	}
+	return nil
+}
+
+// NextOccurrence returns the start and end of the first occurrence of the silence that ends after t.
+func (s *RecurringSilence) NextOccurrence(t time.Time) (time.Time, time.Time, error) {
+	hour, minute, err := s.parseStartTime()
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	loc, err := s.location()
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	duration := time.Duration(s.DurationSeconds) * time.Second
+
+	// Start looking from the previous day, an occurrence that started yesterday might still be active.
+	day := t.In(loc).AddDate(0, 0, -1)
+	for i := 0; i < recurrenceSearchDays; i++ {
+		start := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, loc)
+		end := start.Add(duration)
+		if s.recursOn(start.Weekday()) && end.After(t) {
+			return start, end, nil
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("no occurrence found after %s", t)
+}
+
+func (s *RecurringSilence) recursOn(d time.Weekday) bool {
+	if len(s.Weekdays) == 0 {
+		return true
+	}
+	for _, w := range s.Weekdays {
+		if w == d {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *RecurringSilence) parseStartTime() (int, int, error) {
+	t, err := time.Parse("15:04", s.StartTime)
+	if err != nil {
+		return 0, 0, fmt.Errorf("start time %q should be in the format HH:MM", s.StartTime)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+func (s *RecurringSilence) location() (*time.Location, error) {
+	if s.Timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(s.Timezone)
+}
+
+// GetRecurringSilenceQuery is the query for retrieving a recurring silence by UID and organisation ID.
+type GetRecurringSilenceQuery struct {
+	OrgID int64
+	UID   string
+
+	Result *RecurringSilence
+}
+
+// ListRecurringSilencesQuery is the query for listing the recurring silences of an organisation.
+// If OrgID is zero the recurring silences of all organisations are returned.
+type ListRecurringSilencesQuery struct {
+	OrgID int64
+
+	Result []*RecurringSilence
+}
+
+// SaveRecurringSilenceCmd is the command for creating or updating a recurring silence.
+type SaveRecurringSilenceCmd struct {
+	RecurringSilence *RecurringSilence
+}
+
+// UpdateRecurringSilenceMaterializationCmd records the last occurrence materialized as a silence.
+type UpdateRecurringSilenceMaterializationCmd struct {
+	OrgID         int64
+	UID           string
+	LastStartsAt  int64
+	LastSilenceID string
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecurringSilence_NextOccurrence(t *testing.T) {
+	// 2021-07-05 is a Monday.
+	monday := time.Date(2021, 7, 5, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		desc          string
+		silence       RecurringSilence
+		now           time.Time
+		expectedStart time.Time
+	}{
+		{
+			desc:          "occurrence later in the day",
+			silence:       RecurringSilence{StartTime: "22:00", DurationSeconds: 3600},
+			now:           monday.Add(10 * time.Hour),
+			expectedStart: monday.Add(22 * time.Hour),
+		},
+		{
+			desc:          "occurrence in progress",
+			silence:       RecurringSilence{StartTime: "09:30", DurationSeconds: 3600},
+			now:           monday.Add(10 * time.Hour),
+			expectedStart: monday.Add(9*time.Hour + 30*time.Minute),
+		},
+		{
+			desc:          "occurrence that started the day before is still in progress",
+			silence:       RecurringSilence{StartTime: "23:00", DurationSeconds: 4 * 3600},
+			now:           monday.Add(time.Hour),
+			expectedStart: monday.Add(-time.Hour),
+		},
+		{
+			desc:          "next matching weekday",
+			silence:       RecurringSilence{StartTime: "02:00", DurationSeconds: 3600, Weekdays: []time.Weekday{time.Saturday}},
+			now:           monday.Add(10 * time.Hour),
+			expectedStart: monday.AddDate(0, 0, 5).Add(2 * time.Hour),
+		},
+		{
+			desc:          "start time in the silence timezone",
+			silence:       RecurringSilence{StartTime: "12:00", DurationSeconds: 3600, Timezone: "Europe/Paris"},
+			now:           monday,
+			expectedStart: monday.Add(10 * time.Hour),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			start, end, err := tc.silence.NextOccurrence(tc.now)
+			require.NoError(t, err)
+			require.True(t, tc.expectedStart.Equal(start), "expected start %s, got %s", tc.expectedStart, start)
+			require.Equal(t, time.Duration(tc.silence.DurationSeconds)*time.Second, end.Sub(start))
+		})
+	}
+}
+
+func TestRecurringSilence_Validate(t *testing.T) {
+	name, value, isRegex := "alertname", "test", false
+	matchers := amv2.Matchers{{Name: &name, Value: &value, IsRegex: &isRegex}}
+
+	testCases := []struct {
+		desc    string
+		silence RecurringSilence
+		valid   bool
+	}{
+		{
+			desc:    "valid silence",
+			silence: RecurringSilence{Matchers: matchers, StartTime: "08:00", DurationSeconds: 3600, Timezone: "America/New_York"},
+			valid:   true,
+		},
+		{
+			desc:    "without matchers",
+			silence: RecurringSilence{StartTime: "08:00", DurationSeconds: 3600},
+		},
+		{
+			desc:    "invalid start time",
+			silence: RecurringSilence{Matchers: matchers, StartTime: "8am", DurationSeconds: 3600},
+		},
+		{
+			desc:    "invalid timezone",
+			silence: RecurringSilence{Matchers: matchers, StartTime: "08:00", DurationSeconds: 3600, Timezone: "Mars/Olympus"},
+		},
+		{
+			desc:    "duration longer than a day",
+			silence: RecurringSilence{Matchers: matchers, StartTime: "08:00", DurationSeconds: 25 * 3600},
+		},
+		{
+			desc:    "invalid weekday",
+			silence: RecurringSilence{Matchers: matchers, StartTime: "08:00", DurationSeconds: 3600, Weekdays: []time.Weekday{7}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := tc.silence.Validate()
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, ErrRecurringSilenceFailedValidation)
+			}
+		})
+	}
+}
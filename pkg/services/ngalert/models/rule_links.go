@@ -0,0 +1,101 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+const (
+	// DashboardUIDAnnotation is the private annotation with the UID of the dashboard of the rule.
+	DashboardUIDAnnotation = "__dashboardUid__"
+	// PanelIDAnnotation is the private annotation with the ID of the panel of the rule in its dashboard.
+	PanelIDAnnotation = "__panelId__"
+	// RunbookURLAnnotation is the private annotation with the URL of the runbook of the rule.
+	RunbookURLAnnotation = "__runbookUrl__"
+)
+
+// RuleLinks are the links of a rule to its runbook and to the dashboard panel of its data, which the notifications
+// render as buttons. Unlike the annotations, the links are checked when the rule is saved.
+type RuleLinks struct {
+	// RunbookURL is the absolute http or https URL of the runbook of the rule.
+	RunbookURL string `json:"runbook_url,omitempty"`
+	// DashboardUID is the UID of a dashboard of the organization of the rule.
+	DashboardUID string `json:"dashboard_uid,omitempty"`
+	// PanelID is the ID of a panel of the dashboard.
+	PanelID int64 `json:"panel_id,omitempty"`
+}
+
+// IsEmpty returns true if the rule has no link.
+func (l RuleLinks) IsEmpty() bool {
+	return l == RuleLinks{}
+}
+
+// Validate checks the runbook URL and that a panel is in a dashboard. The existence of the dashboard and of the
+// panel is checked by the store.
+func (l RuleLinks) Validate() error {
+	if l.RunbookURL != "" {
+		u, err := url.Parse(l.RunbookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w: runbook URL %q should be an absolute http or https URL", ErrAlertRuleFailedValidation, l.RunbookURL)
+		}
+	}
+	if l.PanelID < 0 {
+		return fmt.Errorf("%w: panel ID %d should be positive", ErrAlertRuleFailedValidation, l.PanelID)
+	}
+	if l.PanelID > 0 && l.DashboardUID == "" {
+		return fmt.Errorf("%w: the panel %d has no dashboard", ErrAlertRuleFailedValidation, l.PanelID)
+	}
+	return nil
+}
+
+// Annotations returns the private annotations of the links, which are added to the alerts of the rule.
+func (l RuleLinks) Annotations() map[string]string {
+	annotations := make(map[string]string, 3)
+	if l.RunbookURL != "" {
+		annotations[RunbookURLAnnotation] = l.RunbookURL
+	}
+	if l.DashboardUID != "" {
+		annotations[DashboardUIDAnnotation] = l.DashboardUID
+		if l.PanelID > 0 {
+			annotations[PanelIDAnnotation] = strconv.FormatInt(l.PanelID, 10)
+		}
+	}
+	return annotations
+}
+
+// FromDB loads the links stored in the database as JSON, the rules without links have none.
+// FromDB is part of the xorm Conversion interface.
+func (l *RuleLinks) FromDB(b []byte) error {
+	*l = RuleLinks{}
+	if len(b) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, l)
+}
+
+// ToDB stores the links as JSON, and nothing for the rules without links.
+// ToDB is part of the xorm Conversion interface.
+func (l *RuleLinks) ToDB() ([]byte, error) {
+	if l.IsEmpty() {
+		return nil, nil
+	}
+	return json.Marshal(l)
+}
+
+// HasPanel returns true if the dashboard has a panel with the ID, including the panels of the collapsed rows.
+func HasPanel(dashboard *simplejson.Json, panelID int64) bool {
+	for _, p := range dashboard.Get("panels").MustArray() {
+		panel := simplejson.NewFromAny(p)
+		if id, err := panel.Get("id").Int64(); err == nil && id == panelID {
+			return true
+		}
+		if HasPanel(panel, panelID) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleLinks(t *testing.T) {
+	t.Run("validates the runbook URL and the panel", func(t *testing.T) {
+		require.NoError(t, RuleLinks{}.Validate())
+		require.NoError(t, RuleLinks{RunbookURL: "https://example.com/runbook", DashboardUID: "dash", PanelID: 2}.Validate())
+		require.ErrorIs(t, RuleLinks{RunbookURL: "/runbook"}.Validate(), ErrAlertRuleFailedValidation)
+		require.ErrorIs(t, RuleLinks{RunbookURL: "ftp://example.com/runbook"}.Validate(), ErrAlertRuleFailedValidation)
+		require.ErrorIs(t, RuleLinks{PanelID: 2}.Validate(), ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("take precedence over the annotations", func(t *testing.T) {
+		rule := AlertRule{
+			Annotations: map[string]string{DashboardUIDAnnotation: "old", PanelIDAnnotation: "1"},
+			Links:       RuleLinks{DashboardUID: "dash", PanelID: 2},
+		}
+		uid, ok := rule.GetAnnotation(DashboardUIDAnnotation)
+		require.True(t, ok)
+		require.Equal(t, "dash", uid)
+		panelID, _ := rule.GetAnnotation(PanelIDAnnotation)
+		require.Equal(t, "2", panelID)
+		_, ok = rule.GetAnnotation(RunbookURLAnnotation)
+		require.False(t, ok)
+	})
+
+	t.Run("are stored as JSON, and nothing for the rules without links", func(t *testing.T) {
+		l := RuleLinks{RunbookURL: "https://example.com/runbook"}
+		b, err := l.ToDB()
+		require.NoError(t, err)
+		var loaded RuleLinks
+		require.NoError(t, loaded.FromDB(b))
+		require.Equal(t, l, loaded)
+
+		b, err = (&RuleLinks{}).ToDB()
+		require.NoError(t, err)
+		require.Empty(t, b)
+	})
+}
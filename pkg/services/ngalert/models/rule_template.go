@@ -0,0 +1,192 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrRuleTemplateNotFound is an error for an unknown rule template.
+	ErrRuleTemplateNotFound = errors.New("could not find rule template")
+	// ErrRuleTemplateFailedValidation is an error for an invalid rule template, or invalid parameters of a rule
+	// template.
+	ErrRuleTemplateFailedValidation = errors.New("invalid rule template")
+)
+
+// ruleTemplateReference matches the references to the parameters in the rule definition of a rule template.
+var ruleTemplateReference = regexp.MustCompile(`\$\{([a-zA-Z_][a-zA-Z0-9_]*)\}`)
+
+// RuleTemplateParameterType is the type of the value of a parameter of a rule template.
+type RuleTemplateParameterType string
+
+const (
+	RuleTemplateParameterString RuleTemplateParameterType = "string"
+	// RuleTemplateParameterNumber is a number, such as a threshold. A string of the rule definition that is only a
+	// reference to the parameter is replaced with the number.
+	RuleTemplateParameterNumber RuleTemplateParameterType = "number"
+	// RuleTemplateParameterDatasource is the UID of a data source.
+	RuleTemplateParameterDatasource RuleTemplateParameterType = "datasource"
+)
+
+// RuleTemplateParameter is a parameter of a rule template, referenced as ${name} in the rule definition.
+type RuleTemplateParameter struct {
+	Name        string                    `json:"name"`
+	Type        RuleTemplateParameterType `json:"type"`
+	Description string                    `json:"description,omitempty"`
+	// Default is the value of the parameter when it's not supplied, the parameter is required if nil.
+	Default *string `json:"default,omitempty"`
+}
+
+// RuleTemplateParameters are the parameters of a rule template.
+type RuleTemplateParameters []RuleTemplateParameter
+
+// FromDB loads the parameters stored in the database as JSON.
+// FromDB is part of the xorm Conversion interface.
+func (p *RuleTemplateParameters) FromDB(b []byte) error {
+	*p = nil
+	if len(b) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, p)
+}
+
+// ToDB stores the parameters as JSON.
+// ToDB is part of the xorm Conversion interface.
+func (p *RuleTemplateParameters) ToDB() ([]byte, error) {
+	return json.Marshal(p)
+}
+
+// RuleTemplate is a parameterized definition of an alert rule, from which rules are instantiated by supplying the
+// values of its parameters.
+type RuleTemplate struct {
+	ID          int64  `xorm:"pk autoincr 'id'"`
+	OrgID       int64  `xorm:"org_id"`
+	UID         string `xorm:"uid"`
+	Title       string
+	Description string
+	Parameters  RuleTemplateParameters `xorm:"parameters"`
+	// Rule is the JSON of the rule definition, in the format of the rules of the ruler API. Its strings reference
+	// the parameters as ${name}.
+	Rule string
+	// Version is incremented by each update of the template, the instances record the version they were rendered
+	// from.
+	Version   int64
+	UpdatedBy string
+	UpdatedAt int64 `xorm:"updated"`
+}
+
+// Validate checks the parameters of the template, and that the rule definition only references them.
+func (t *RuleTemplate) Validate() error {
+	if t.Title == "" {
+		return fmt.Errorf("%w: title is empty", ErrRuleTemplateFailedValidation)
+	}
+	params := make(map[string]RuleTemplateParameter, len(t.Parameters))
+	for _, p := range t.Parameters {
+		if !ruleTemplateReference.MatchString("${" + p.Name + "}") {
+			return fmt.Errorf("%w: invalid parameter name %q", ErrRuleTemplateFailedValidation, p.Name)
+		}
+		if _, ok := params[p.Name]; ok {
+			return fmt.Errorf("%w: duplicate parameter %q", ErrRuleTemplateFailedValidation, p.Name)
+		}
+		switch p.Type {
+		case RuleTemplateParameterString, RuleTemplateParameterDatasource:
+		case RuleTemplateParameterNumber:
+			if p.Default != nil {
+				if _, err := strconv.ParseFloat(*p.Default, 64); err != nil {
+					return fmt.Errorf("%w: default of parameter %q is not a number", ErrRuleTemplateFailedValidation, p.Name)
+				}
+			}
+		default:
+			return fmt.Errorf("%w: parameter %q has an unknown type %q", ErrRuleTemplateFailedValidation, p.Name, p.Type)
+		}
+		params[p.Name] = p
+	}
+
+	if !json.Valid([]byte(t.Rule)) {
+		return fmt.Errorf("%w: rule is not valid JSON", ErrRuleTemplateFailedValidation)
+	}
+	for _, m := range ruleTemplateReference.FindAllStringSubmatch(t.Rule, -1) {
+		if _, ok := params[m[1]]; !ok {
+			return fmt.Errorf("%w: rule references the undefined parameter %q", ErrRuleTemplateFailedValidation, m[1])
+		}
+	}
+	return nil
+}
+
+// Render returns the JSON of the rule definition with the references to the parameters replaced with their values,
+// or their defaults when they are not supplied.
+func (t *RuleTemplate) Render(values map[string]string) ([]byte, error) {
+	resolved := make(map[string]string, len(t.Parameters))
+	types := make(map[string]RuleTemplateParameterType, len(t.Parameters))
+	for _, p := range t.Parameters {
+		types[p.Name] = p.Type
+		v, ok := values[p.Name]
+		if !ok {
+			if p.Default == nil {
+				return nil, fmt.Errorf("%w: parameter %q is required", ErrRuleTemplateFailedValidation, p.Name)
+			}
+			v = *p.Default
+		}
+		if p.Type == RuleTemplateParameterNumber {
+			if _, err := strconv.ParseFloat(v, 64); err != nil {
+				return nil, fmt.Errorf("%w: parameter %q is not a number", ErrRuleTemplateFailedValidation, p.Name)
+			}
+		}
+		resolved[p.Name] = v
+	}
+	for name := range values {
+		if _, ok := resolved[name]; !ok {
+			return nil, fmt.Errorf("%w: unknown parameter %q", ErrRuleTemplateFailedValidation, name)
+		}
+	}
+
+	d := json.NewDecoder(bytes.NewReader([]byte(t.Rule)))
+	d.UseNumber()
+	var rule interface{}
+	if err := d.Decode(&rule); err != nil {
+		return nil, fmt.Errorf("%w: rule is not valid JSON", ErrRuleTemplateFailedValidation)
+	}
+	return json.Marshal(renderRuleTemplateValue(rule, resolved, types))
+}
+
+// renderRuleTemplateValue replaces the references to the parameters in the strings of the decoded JSON value.
+func renderRuleTemplateValue(v interface{}, values map[string]string, types map[string]RuleTemplateParameterType) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = renderRuleTemplateValue(e, values, types)
+		}
+		return v
+	case []interface{}:
+		for i, e := range v {
+			v[i] = renderRuleTemplateValue(e, values, types)
+		}
+		return v
+	case string:
+		if m := ruleTemplateReference.FindStringSubmatch(v); m != nil && m[0] == v && types[m[1]] == RuleTemplateParameterNumber {
+			return json.Number(values[m[1]])
+		}
+		return ruleTemplateReference.ReplaceAllStringFunc(v, func(ref string) string {
+			return values[strings.TrimSuffix(strings.TrimPrefix(ref, "${"), "}")]
+		})
+	}
+	return v
+}
+
+// RuleTemplateInstance is the back-reference from an alert rule to the rule template it was instantiated from, with
+// the values of the parameters, so the rule can be rendered again when the template is updated.
+type RuleTemplateInstance struct {
+	ID          int64             `xorm:"pk autoincr 'id'"`
+	OrgID       int64             `xorm:"org_id"`
+	TemplateUID string            `xorm:"template_uid"`
+	RuleUID     string            `xorm:"rule_uid"`
+	Parameters  map[string]string `xorm:"parameters"`
+	// TemplateVersion is the version of the template the rule was last rendered from.
+	TemplateVersion int64
+	UpdatedAt       int64 `xorm:"updated"`
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleTemplate_Validate(t *testing.T) {
+	def := "80"
+	notANumber := "high"
+	testCases := []struct {
+		desc     string
+		template RuleTemplate
+		valid    bool
+	}{
+		{
+			desc: "valid template",
+			template: RuleTemplate{Title: "CPU", Rule: `{"title": "CPU of ${host}", "threshold": "${threshold}"}`, Parameters: RuleTemplateParameters{
+				{Name: "host", Type: RuleTemplateParameterString},
+				{Name: "threshold", Type: RuleTemplateParameterNumber, Default: &def},
+			}},
+			valid: true,
+		},
+		{
+			desc:     "template without a title",
+			template: RuleTemplate{Rule: `{}`},
+		},
+		{
+			desc:     "invalid parameter name",
+			template: RuleTemplate{Title: "CPU", Rule: `{}`, Parameters: RuleTemplateParameters{{Name: "the host", Type: RuleTemplateParameterString}}},
+		},
+		{
+			desc: "duplicate parameter",
+			template: RuleTemplate{Title: "CPU", Rule: `{}`, Parameters: RuleTemplateParameters{
+				{Name: "host", Type: RuleTemplateParameterString},
+				{Name: "host", Type: RuleTemplateParameterDatasource},
+			}},
+		},
+		{
+			desc:     "unknown parameter type",
+			template: RuleTemplate{Title: "CPU", Rule: `{}`, Parameters: RuleTemplateParameters{{Name: "host", Type: "bool"}}},
+		},
+		{
+			desc:     "number parameter with a default that is not a number",
+			template: RuleTemplate{Title: "CPU", Rule: `{}`, Parameters: RuleTemplateParameters{{Name: "threshold", Type: RuleTemplateParameterNumber, Default: &notANumber}}},
+		},
+		{
+			desc:     "rule that is not JSON",
+			template: RuleTemplate{Title: "CPU", Rule: `{"title":`},
+		},
+		{
+			desc:     "rule referencing an undefined parameter",
+			template: RuleTemplate{Title: "CPU", Rule: `{"title": "CPU of ${host}"}`},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			err := tc.template.Validate()
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, ErrRuleTemplateFailedValidation)
+			}
+		})
+	}
+}
+
+func TestRuleTemplate_Render(t *testing.T) {
+	def := "80"
+	template := RuleTemplate{
+		Title: "CPU",
+		Rule:  `{"title": "CPU of ${host}", "data": [{"datasourceUid": "${ds}", "model": {"threshold": "${threshold}", "expr": "cpu{host=\"${host}\"} > ${threshold}", "max": 100}}]}`,
+		Parameters: RuleTemplateParameters{
+			{Name: "host", Type: RuleTemplateParameterString},
+			{Name: "ds", Type: RuleTemplateParameterDatasource},
+			{Name: "threshold", Type: RuleTemplateParameterNumber, Default: &def},
+		},
+	}
+	require.NoError(t, template.Validate())
+
+	t.Run("references are replaced with the values", func(t *testing.T) {
+		rule, err := template.Render(map[string]string{"host": "db", "ds": "prom", "threshold": "90.5"})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"title": "CPU of db", "data": [{"datasourceUid": "prom", "model": {"threshold": 90.5, "expr": "cpu{host=\"db\"} > 90.5", "max": 100}}]}`, string(rule))
+	})
+
+	t.Run("defaults are used for the missing values", func(t *testing.T) {
+		rule, err := template.Render(map[string]string{"host": "db", "ds": "prom"})
+		require.NoError(t, err)
+		require.JSONEq(t, `{"title": "CPU of db", "data": [{"datasourceUid": "prom", "model": {"threshold": 80, "expr": "cpu{host=\"db\"} > 80", "max": 100}}]}`, string(rule))
+	})
+
+	t.Run("required parameter is missing", func(t *testing.T) {
+		_, err := template.Render(map[string]string{"host": "db"})
+		require.ErrorIs(t, err, ErrRuleTemplateFailedValidation)
+	})
+
+	t.Run("number parameter is not a number", func(t *testing.T) {
+		_, err := template.Render(map[string]string{"host": "db", "ds": "prom", "threshold": "high"})
+		require.ErrorIs(t, err, ErrRuleTemplateFailedValidation)
+	})
+
+	t.Run("unknown parameter", func(t *testing.T) {
+		_, err := template.Render(map[string]string{"host": "db", "ds": "prom", "team": "a"})
+		require.ErrorIs(t, err, ErrRuleTemplateFailedValidation)
+	})
+}
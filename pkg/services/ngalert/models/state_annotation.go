@@ -0,0 +1,30 @@
+package models
+
+// AlertStateAnnotation is an annotation of a change of the state of an alert, in the dedicated table of the
+// annotations of the alerts.
+type AlertStateAnnotation struct {
+	ID    int64 `xorm:"pk autoincr 'id'"`
+	OrgID int64 `xorm:"org_id"`
+	// RuleUID is empty for the annotations moved from the annotations table, which don't know their rule.
+	RuleUID string            `xorm:"rule_uid"`
+	Labels  map[string]string `xorm:"labels"`
+	// DashboardUID and PanelID are the dashboard panel of the rule, empty if the rule has none.
+	DashboardUID string `xorm:"dashboard_uid"`
+	PanelID      int64  `xorm:"panel_id"`
+	PrevState    string `xorm:"prev_state"`
+	NewState     string `xorm:"new_state"`
+	Text         string `xorm:"text"`
+	// Epoch is the time of the change in milliseconds, like in the annotations table.
+	Epoch int64 `xorm:"epoch"`
+}
+
+// GetAlertStateAnnotationsQuery is the query for the annotations of the changes of the states of the alerts of an
+// organization, of a rule if RuleUID is set.
+type GetAlertStateAnnotationsQuery struct {
+	OrgID   int64
+	RuleUID string
+	// From excludes the annotations before the time in milliseconds, when set.
+	From int64
+
+	Result []*AlertStateAnnotation
+}
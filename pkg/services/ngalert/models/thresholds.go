@@ -0,0 +1,91 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ThresholdType is the comparison of the value of a multi-threshold rule with its thresholds.
+type ThresholdType string
+
+const (
+	// ThresholdTypeGreaterThan thresholds are passed by the values above them.
+	ThresholdTypeGreaterThan ThresholdType = "gt"
+	// ThresholdTypeLessThan thresholds are passed by the values below them.
+	ThresholdTypeLessThan ThresholdType = "lt"
+)
+
+// Threshold is a level of a multi-threshold rule, with the labels of the alerts that pass it.
+type Threshold struct {
+	Value  float64           `json:"value"`
+	Labels map[string]string `json:"labels"`
+}
+
+// Thresholds are the ordered thresholds of a multi-threshold rule. Each alert of the rule is at the most severe
+// threshold its value passes, so that a single rule can produce alerts with different labels, such as a warning
+// and a critical severity.
+type Thresholds struct {
+	// RefID is the query or expression with the value of the alerts, a reduce or math expression.
+	RefID string        `json:"ref_id"`
+	Type  ThresholdType `json:"type"`
+	// Levels are ordered from the least to the most severe.
+	Levels []Threshold `json:"levels"`
+}
+
+// IsEmpty returns true if the rule has no thresholds.
+func (t Thresholds) IsEmpty() bool {
+	return len(t.Levels) == 0
+}
+
+// Passes returns true if the value passes the threshold.
+func (t Thresholds) Passes(value float64, threshold Threshold) bool {
+	if t.Type == ThresholdTypeLessThan {
+		return value < threshold.Value
+	}
+	return value > threshold.Value
+}
+
+// Validate checks the thresholds of a rule with the queries and expressions data.
+func (t Thresholds) Validate(data []AlertQuery) error {
+	if t.Type != ThresholdTypeGreaterThan && t.Type != ThresholdTypeLessThan {
+		return fmt.Errorf("%w: threshold type %q should be %s or %s", ErrAlertRuleFailedValidation, t.Type, ThresholdTypeGreaterThan, ThresholdTypeLessThan)
+	}
+	found := false
+	for _, q := range data {
+		if q.RefID == t.RefID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return fmt.Errorf("%w: thresholds refer to %q, which is not a query or expression of the rule", ErrAlertRuleFailedValidation, t.RefID)
+	}
+	for i, l := range t.Levels {
+		if len(l.Labels) == 0 {
+			return fmt.Errorf("%w: threshold %v has no labels", ErrAlertRuleFailedValidation, l.Value)
+		}
+		if i > 0 && !t.Passes(l.Value, t.Levels[i-1]) {
+			return fmt.Errorf("%w: threshold %v should be more severe than threshold %v", ErrAlertRuleFailedValidation, l.Value, t.Levels[i-1].Value)
+		}
+	}
+	return nil
+}
+
+// FromDB loads the thresholds stored in the database as JSON.
+// FromDB is part of the xorm Conversion interface.
+func (t *Thresholds) FromDB(b []byte) error {
+	*t = Thresholds{}
+	if len(b) == 0 {
+		return nil
+	}
+	return json.Unmarshal(b, t)
+}
+
+// ToDB stores the thresholds as JSON, and nothing for the rules without thresholds.
+// ToDB is part of the xorm Conversion interface.
+func (t *Thresholds) ToDB() ([]byte, error) {
+	if t.IsEmpty() {
+		return nil, nil
+	}
+	return json.Marshal(t)
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestThresholdsValidate(t *testing.T) {
+	data := []AlertQuery{{RefID: "A"}, {RefID: "B"}}
+	warning := map[string]string{"severity": "warning"}
+	critical := map[string]string{"severity": "critical"}
+
+	require.NoError(t, Thresholds{RefID: "B", Type: ThresholdTypeGreaterThan, Levels: []Threshold{{Value: 80, Labels: warning}, {Value: 90, Labels: critical}}}.Validate(data))
+	require.NoError(t, Thresholds{RefID: "B", Type: ThresholdTypeLessThan, Levels: []Threshold{{Value: 20, Labels: warning}, {Value: 10, Labels: critical}}}.Validate(data))
+
+	for name, th := range map[string]Thresholds{
+		"unknown type":   {RefID: "B", Type: "eq", Levels: []Threshold{{Value: 80, Labels: warning}}},
+		"unknown ref ID": {RefID: "C", Type: ThresholdTypeGreaterThan, Levels: []Threshold{{Value: 80, Labels: warning}}},
+		"no labels":      {RefID: "B", Type: ThresholdTypeGreaterThan, Levels: []Threshold{{Value: 80}}},
+		"wrong order":    {RefID: "B", Type: ThresholdTypeGreaterThan, Levels: []Threshold{{Value: 90, Labels: critical}, {Value: 80, Labels: warning}}},
+		"wrong lt order": {RefID: "B", Type: ThresholdTypeLessThan, Levels: []Threshold{{Value: 10, Labels: critical}, {Value: 20, Labels: warning}}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.ErrorIs(t, th.Validate(data), ErrAlertRuleFailedValidation)
+		})
+	}
+}
@@ -2,15 +2,25 @@ package ngalert
 
 import (
 	"context"
+	"path/filepath"
 	"time"
 
 	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana/pkg/components/imguploader"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/quota"
+	"github.com/grafana/grafana/pkg/services/rendering"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/api"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/insights"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/ngalert/querylog"
+	"github.com/grafana/grafana/pkg/services/ngalert/remote"
+	"github.com/grafana/grafana/pkg/services/ngalert/remotewrite"
+	"github.com/grafana/grafana/pkg/services/ngalert/screenshot"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 
@@ -20,6 +30,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
 	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
+	"github.com/grafana/grafana/pkg/services/provisioning/alerting"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb"
@@ -39,7 +50,7 @@ const (
 
 func ProvideService(cfg *setting.Cfg, dataSourceCache datasources.CacheService, routeRegister routing.RouteRegister,
 	sqlStore *sqlstore.SQLStore, dataService *tsdb.Service, dataProxy *datasourceproxy.DataSourceProxyService,
-	quotaService *quota.QuotaService, m *metrics.Metrics) (*AlertNG, error) {
+	quotaService *quota.QuotaService, renderService rendering.Service, m *metrics.Metrics, ac accesscontrol.AccessControl) (*AlertNG, error) {
 	ng := &AlertNG{
 		Cfg:             cfg,
 		DataSourceCache: dataSourceCache,
@@ -48,7 +59,9 @@ func ProvideService(cfg *setting.Cfg, dataSourceCache datasources.CacheService,
 		DataService:     dataService,
 		DataProxy:       dataProxy,
 		QuotaService:    quotaService,
+		RenderService:   renderService,
 		Metrics:         m,
+		AccessControl:   ac,
 		Log:             log.New("ngalert"),
 	}
 
@@ -56,6 +69,10 @@ func ProvideService(cfg *setting.Cfg, dataSourceCache datasources.CacheService,
 		return ng, nil
 	}
 
+	if err := declareFixedRoles(ac); err != nil {
+		return nil, err
+	}
+
 	if err := ng.init(); err != nil {
 		return nil, err
 	}
@@ -72,13 +89,28 @@ type AlertNG struct {
 	DataService     *tsdb.Service
 	DataProxy       *datasourceproxy.DataSourceProxyService
 	QuotaService    *quota.QuotaService
+	RenderService   rendering.Service
 	Metrics         *metrics.Metrics
+	AccessControl   accesscontrol.AccessControl
 	Log             log.Logger
 	schedule        schedule.ScheduleService
 	stateManager    *state.Manager
+	evalWorker      *remote.Server
+	evalClient      *remote.Client
+	stateExporter   *remotewrite.Exporter
+	queryLogger     *querylog.Logger
+	ruleInsights    *insights.Tracker
+	gitSync         *alerting.GitSync
+	// stateAnnotations is set when the annotations of the alerts are written in the dedicated table.
+	stateAnnotations store.StateAnnotationStore
 
 	// Alerting notification services
 	MultiOrgAlertmanager *notifier.MultiOrgAlertmanager
+
+	// Services managing the provisioned alerting resources
+	AlertRuleService          *provisioning.AlertRuleService
+	AlertmanagerConfigService *provisioning.AlertmanagerConfigService
+	MuteTimingService         *provisioning.MuteTimingService
 }
 
 func (ng *AlertNG) init() error {
@@ -95,33 +127,107 @@ func (ng *AlertNG) init() error {
 		Logger:                 ng.Log,
 	}
 
-	ng.MultiOrgAlertmanager = notifier.NewMultiOrgAlertmanager(ng.Cfg, store, store)
+	multiOrgAlertmanager, err := notifier.NewMultiOrgAlertmanager(ng.Cfg, store, store, ng.Metrics)
+	if err != nil {
+		return err
+	}
+	ng.MultiOrgAlertmanager = multiOrgAlertmanager
 
 	// Let's make sure we're able to complete an initial sync of Alertmanagers before we start the alerting components.
 	if err := ng.MultiOrgAlertmanager.LoadAndSyncAlertmanagersForOrgs(context.Background()); err != nil {
 		return err
 	}
 
+	evaluator := eval.Evaluator{Cfg: ng.Cfg, Log: ng.Log}
+	if ng.Cfg.EvaluationWorkerListenAddr != "" {
+		ng.evalWorker = remote.NewServer(evaluator, ng.DataService, ng.Cfg.EvaluationWorkerToken, log.New("ngalert.evaluation-worker"))
+	}
+	var remoteEvaluator schedule.RemoteEvaluator
+	if len(ng.Cfg.EvaluationWorkers) > 0 {
+		ng.evalClient, err = remote.NewClient(ng.Cfg.EvaluationWorkers, ng.Cfg.EvaluationWorkerToken)
+		if err != nil {
+			return err
+		}
+		remoteEvaluator = ng.evalClient
+	}
+	var stateExporter schedule.StateExporter
+	if ng.Cfg.StateRemoteWriteURL != "" {
+		ng.stateExporter = remotewrite.NewExporter(ng.Cfg, ng.Metrics, log.New("ngalert.state-remote-write"))
+		stateExporter = ng.stateExporter
+	}
+	var queryLogger schedule.QueryLogger
+	if ng.Cfg.QueryLog != "" && !ng.Cfg.EvaluationWorkerOnly {
+		ng.queryLogger, err = querylog.NewLogger(ng.Cfg, log.New("ngalert.query-log"))
+		if err != nil {
+			return err
+		}
+		queryLogger = ng.queryLogger
+	}
+
+	ng.ruleInsights = insights.NewTracker(store, log.New("ngalert.insights"))
+
 	schedCfg := schedule.SchedulerCfg{
 		C:                       clock.New(),
 		BaseInterval:            baseInterval,
 		Logger:                  log.New("ngalert.scheduler"),
 		MaxAttempts:             maxAttempts,
-		Evaluator:               eval.Evaluator{Cfg: ng.Cfg, Log: ng.Log},
+		Evaluator:               evaluator,
+		RemoteEvaluator:         remoteEvaluator,
+		StateExporter:           stateExporter,
+		QueryLogger:             queryLogger,
+		EvaluationRecorder:      ng.ruleInsights,
 		InstanceStore:           store,
 		RuleStore:               store,
 		AdminConfigStore:        store,
 		OrgStore:                store,
+		HeartbeatStore:          store,
 		MultiOrgNotifier:        ng.MultiOrgAlertmanager,
 		Metrics:                 ng.Metrics,
 		AdminConfigPollInterval: ng.Cfg.AdminConfigPollInterval,
+		RuleMetricsMaxRules:     ng.Cfg.RuleMetricsMaxRules,
+		PoolSize:                ng.Cfg.SchedulerPoolSize,
+		MaxQueueSize:            ng.Cfg.SchedulerMaxQueueSize,
+		LazyOrgLoading:          ng.Cfg.LazyOrgLoading,
+		OrgWarmUpBatchSize:      ng.Cfg.OrgWarmUpBatchSize,
+		OrgIdleTTL:              ng.Cfg.OrgIdleTTL,
+
+		DatasourceMaintenanceStore: store,
+	}
+	var screenshots screenshot.ScreenshotService
+	if ng.Cfg.ScreenshotsEnabled && ng.RenderService != nil {
+		uploader, err := imguploader.NewImageUploader()
+		if err != nil {
+			return err
+		}
+		screenshots = screenshot.NewRenderScreenshotService(ng.Cfg, ng.RenderService, uploader)
+	}
+	if ng.Cfg.AlertStateAnnotationsBackend == "table" {
+		ng.stateAnnotations = store
 	}
-	stateManager := state.NewManager(ng.Log, ng.Metrics, store, store)
+	stateManager := state.NewManager(ng.Log, ng.Metrics, store, store, screenshots, state.NewAnnotationWriter(ng.Cfg.AlertStateAnnotationsBackend, store), state.NewCorrelator(ng.Cfg.CorrelationLabels, ng.Cfg.CorrelationWindow))
 	schedule := schedule.NewScheduler(schedCfg, ng.DataService, ng.Cfg.AppURL, stateManager)
 
 	ng.stateManager = stateManager
 	ng.schedule = schedule
 
+	provisioningLogger := log.New("ngalert.provisioning")
+	ng.AlertRuleService = provisioning.NewAlertRuleService(store, store, provisioningLogger)
+	ng.AlertmanagerConfigService = provisioning.NewAlertmanagerConfigService(store, store, ng.MultiOrgAlertmanager, provisioningLogger)
+	ng.MuteTimingService = provisioning.NewMuteTimingService(store, store, ng.MultiOrgAlertmanager, provisioningLogger)
+	if ng.Cfg.GitSyncRepository != "" {
+		ng.gitSync = alerting.NewGitSync(alerting.GitSyncConfig{
+			Repository: ng.Cfg.GitSyncRepository,
+			Branch:     ng.Cfg.GitSyncBranch,
+			Path:       ng.Cfg.GitSyncPath,
+			Interval:   ng.Cfg.GitSyncInterval,
+			Dir:        filepath.Join(ng.Cfg.DataPath, "alerting", "git-sync"),
+		}, ng.SQLStore, alerting.Services{
+			AlertRules:          ng.AlertRuleService,
+			AlertmanagerConfigs: ng.AlertmanagerConfigService,
+			MuteTimings:         ng.MuteTimingService,
+		})
+	}
+
 	api := api.API{
 		Cfg:                  ng.Cfg,
 		DatasourceCache:      ng.DataSourceCache,
@@ -134,29 +240,99 @@ func (ng *AlertNG) init() error {
 		RuleStore:            store,
 		AlertingStore:        store,
 		AdminConfigStore:     store,
+		ProvenanceStore:      store,
+		HeartbeatStore:       store,
+		RuleTemplateStore:    store,
+		FolderDefaultsStore:  store,
 		MultiOrgAlertmanager: ng.MultiOrgAlertmanager,
 		StateManager:         ng.stateManager,
+		RuleInsights:         ng.ruleInsights,
+		AccessControl:        ng.AccessControl,
+
+		AlertRuleService:          ng.AlertRuleService,
+		AlertmanagerConfigService: ng.AlertmanagerConfigService,
+		MuteTimingService:         ng.MuteTimingService,
+
+		DatasourceMaintenanceStore: store,
+	}
+	if ng.gitSync != nil {
+		api.GitSync = ng.gitSync
+	}
+	if ng.stateAnnotations != nil {
+		api.StateAnnotationStore = ng.stateAnnotations
 	}
 	api.RegisterAPIEndpoints(ng.Metrics)
 
 	return nil
 }
 
-// Run starts the scheduler and Alertmanager.
+// Run starts the scheduler and Alertmanager, and the evaluation worker if enabled.
 func (ng *AlertNG) Run(ctx context.Context) error {
 	ng.Log.Debug("ngalert starting")
-	ng.stateManager.Warm()
+	if ng.evalClient != nil {
+		defer func() {
+			if err := ng.evalClient.Close(); err != nil {
+				ng.Log.Warn("failed to close the connections to the evaluation workers", "err", err)
+			}
+		}()
+	}
 
 	children, subCtx := errgroup.WithContext(ctx)
-	children.Go(func() error {
-		return ng.schedule.Run(subCtx)
-	})
+	if ng.evalWorker != nil {
+		children.Go(func() error {
+			return ng.evalWorker.Run(subCtx, ng.Cfg.EvaluationWorkerListenAddr)
+		})
+	}
+	// an instance running as an evaluation worker only evaluates the rules of the schedulers
+	if !ng.Cfg.EvaluationWorkerOnly {
+		// the states of the organizations loaded lazily are loaded with their rules
+		if !ng.Cfg.LazyOrgLoading {
+			ng.stateManager.Warm()
+		}
+		if ng.stateAnnotations != nil {
+			go ng.moveStateAnnotations()
+		}
+		children.Go(func() error {
+			return ng.schedule.Run(subCtx)
+		})
+		if ng.stateExporter != nil {
+			children.Go(func() error {
+				return ng.stateExporter.Run(subCtx)
+			})
+		}
+		if ng.queryLogger != nil {
+			children.Go(func() error {
+				return ng.queryLogger.Run(subCtx)
+			})
+		}
+		children.Go(func() error {
+			return ng.ruleInsights.Run(subCtx)
+		})
+		if ng.gitSync != nil {
+			children.Go(func() error {
+				return ng.gitSync.Run(subCtx)
+			})
+		}
+	}
 	children.Go(func() error {
 		return ng.MultiOrgAlertmanager.Run(subCtx)
 	})
 	return children.Wait()
 }
 
+// moveStateAnnotations moves the annotations of the alerts written in the annotations table to the dedicated table,
+// in the background since there can be many of them.
+func (ng *AlertNG) moveStateAnnotations() {
+	moved, err := ng.stateAnnotations.MoveStateAnnotations(store.StateAnnotationsBatchSize)
+	if err != nil {
+		ng.Log.Error("failed to move the annotations of the alerts to their table", "moved", moved, "err", err)
+		return
+	}
+	if moved > 0 {
+		ng.Log.Info("moved the annotations of the alerts to their table", "moved", moved)
+	}
+}
+
 // IsDisabled returns true if the alerting service is disable for this instance.
 func (ng *AlertNG) IsDisabled() bool {
 	if ng.Cfg == nil {
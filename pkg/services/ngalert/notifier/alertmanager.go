@@ -33,13 +33,17 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels"
+	"github.com/grafana/grafana/pkg/services/ngalert/secrets"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
 const (
 	workingDir = "alerting"
+	// defaultTemplateFile is the file of the default templates in the working directory.
+	defaultTemplateFile = "__default__.tmpl"
 	// How long should we keep silences and notification entries on-disk after they've served their purpose.
+	// The retention of the notification entries is configured by notification_log_retention.
 	retentionNotificationsAndSilences = 5 * 24 * time.Hour
 	// maintenanceNotificationAndSilences how often should we flush and gargabe collect notifications and silences
 	maintenanceNotificationAndSilences = 15 * time.Minute
@@ -104,50 +108,139 @@ type Alertmanager struct {
 	config          *apimodels.PostableUserConfig
 	configHash      [16]byte
 	orgID           int64
+
+	// warnedSilences keeps the end time of the silences we've already sent an expiry warning for.
+	warnedSilencesMtx sync.Mutex
+	warnedSilences    map[string]time.Time
+
+	maintenanceMtx sync.RWMutex
+	maintenance    *ngmodels.MaintenanceMode
+
+	labelPoliciesMtx      sync.RWMutex
+	labelPolicies         *ngmodels.LabelPolicies
+	compiledLabelPolicies []compiledLabelPolicy
+	// labelPolicyLookupsFetchedAt is when the lookup tables of the label policies were last fetched.
+	labelPolicyLookupsFetchedAt time.Time
+
+	escalations *escalations
+	// enrichments caches the annotations returned by the enrichment services of the configuration.
+	enrichments *enrichments
+	// resolvedNotifications suppress or delay the resolved notifications of the routes of the configuration.
+	resolvedNotifications *resolvedNotifications
+	// groupResponses are the responses of the webhooks to the notifications of the alert groups.
+	groupResponses *groupResponses
+
+	// notificationQuotas counts the notifications sent against the notification quotas of the organization.
+	notificationQuotas *notificationQuotas
+	// evaluationErrors batches the alerts of the rules failing to evaluate into summaries.
+	evaluationErrors *evaluationErrors
+
+	// secrets resolves the receiver settings referencing an external secret manager.
+	secrets *secrets.Resolver
+
+	// selfMonitor records the attempts to send the notifications, if not nil.
+	selfMonitor *SelfMonitor
+	// standby drops the notifications while the replica is a warm standby, if not nil.
+	standby *standby
+	// dispatchShards runs the flushes of the alert groups sharded by group key, if not nil.
+	dispatchShards *dispatchShards
+	// health records the syncs of the configuration and the attempts to send the notifications.
+	health *alertmanagerHealth
+
+	// stateStore persists the notification log and silences, they are only kept on the local disk if nil.
+	stateStore StateStore
+	// persistedState are the checksums of the last state persisted, by kind.
+	persistedState map[string]string
+
+	// peer gossips the notification log and silences with the Alertmanagers of the other replicas.
+	peer ClusterPeer
 }
 
-func newAlertmanager(orgID int64, cfg *setting.Cfg, store store.AlertingStore, m *metrics.Metrics) (*Alertmanager, error) {
+func newAlertmanager(orgID int64, cfg *setting.Cfg, store store.AlertingStore, stateStore StateStore, peer ClusterPeer, m *metrics.Metrics) (*Alertmanager, error) {
 	am := &Alertmanager{
-		Settings:          cfg,
-		stopc:             make(chan struct{}),
-		logger:            log.New("alertmanager", "org", orgID),
-		marker:            types.NewMarker(m.Registerer),
-		stageMetrics:      notify.NewMetrics(m.Registerer),
-		dispatcherMetrics: dispatch.NewDispatcherMetrics(m.Registerer),
-		Store:             store,
-		Metrics:           m,
-		orgID:             orgID,
+		Settings:              cfg,
+		stopc:                 make(chan struct{}),
+		logger:                log.New("alertmanager", "org", orgID),
+		marker:                types.NewMarker(m.Registerer),
+		stageMetrics:          notify.NewMetrics(m.Registerer),
+		dispatcherMetrics:     dispatch.NewDispatcherMetrics(m.Registerer),
+		Store:                 store,
+		Metrics:               m,
+		orgID:                 orgID,
+		warnedSilences:        map[string]time.Time{},
+		escalations:           newEscalations(),
+		enrichments:           newEnrichments(),
+		resolvedNotifications: newResolvedNotifications(),
+		groupResponses:        newGroupResponses(),
+		notificationQuotas:    newNotificationQuotas(),
+		evaluationErrors:      newEvaluationErrors(),
+		health:                &alertmanagerHealth{},
+		secrets:               secrets.NewResolver(cfg, orgID),
+		stateStore:            stateStore,
+		persistedState:        map[string]string{},
+		peer:                  peer,
 	}
 
 	am.gokitLogger = gokit_log.NewLogfmtLogger(logging.NewWrapper(am.logger))
 
 	// Initialize the notification log
+	nflogFile := filepath.Join(am.WorkingDirPath(), "notifications")
+	am.restoreState(ngmodels.AlertmanagerStateNotificationLog, nflogFile)
+	am.discardCorruptedSnapshot(nflogFile, func() error {
+		_, err := nflog.New(nflog.WithSnapshot(nflogFile))
+		return err
+	})
+	if removed, err := compactNotificationLog(nflogFile, am.notificationLogRetention(), time.Now()); err != nil {
+		am.logger.Error("failed to compact the notification log", "file", nflogFile, "err", err)
+	} else if removed > 0 {
+		am.logger.Info("removed the notifications older than the retention from the notification log", "removed", removed)
+	}
 	am.wg.Add(1)
 	var err error
 	am.notificationLog, err = nflog.New(
-		nflog.WithRetention(retentionNotificationsAndSilences),
-		nflog.WithSnapshot(filepath.Join(am.WorkingDirPath(), "notifications")),
-		nflog.WithMaintenance(maintenanceNotificationAndSilences, am.stopc, am.wg.Done),
+		nflog.WithRetention(am.notificationLogRetention()),
+		nflog.WithSnapshot(nflogFile),
+		nflog.WithMaintenance(am.notificationLogMaintenanceInterval(), am.stopc, am.wg.Done),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("unable to initialize the notification log component of alerting: %w", err)
 	}
+	c := am.peer.AddState(fmt.Sprintf("notificationlog:%d", am.orgID), am.notificationLog, m.Registerer)
+	am.notificationLog.SetBroadcast(c.Broadcast)
+
 	// Initialize silences
+	silencesFile := filepath.Join(am.WorkingDirPath(), "silences")
+	am.restoreState(ngmodels.AlertmanagerStateSilences, silencesFile)
+	am.discardCorruptedSnapshot(silencesFile, func() error {
+		_, err := silence.New(silence.Options{SnapshotFile: silencesFile})
+		return err
+	})
 	am.silences, err = silence.New(silence.Options{
 		Metrics:      m.Registerer,
-		SnapshotFile: filepath.Join(am.WorkingDirPath(), "silences"),
+		SnapshotFile: silencesFile,
 		Retention:    retentionNotificationsAndSilences,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("unable to initialize the silencing component of alerting: %w", err)
 	}
+	c = am.peer.AddState(fmt.Sprintf("silences:%d", am.orgID), am.silences, m.Registerer)
+	am.silences.SetBroadcast(c.Broadcast)
 
 	am.wg.Add(1)
 	go func() {
-		am.silences.Maintenance(15*time.Minute, filepath.Join(am.WorkingDirPath(), "silences"), am.stopc)
+		am.silences.Maintenance(15*time.Minute, silencesFile, am.stopc)
 		am.wg.Done()
 	}()
 
+	if am.stateStore != nil {
+		am.wg.Add(1)
+		go am.runStatePersistence()
+	}
+
+	if cfg.DispatchShards > 0 {
+		am.dispatchShards = newDispatchShards(am, cfg.DispatchShards)
+	}
+
 	// Initialize in-memory alerts
 	am.alerts, err = mem.NewAlerts(context.Background(), am.marker, memoryAlertsGCInterval, am.gokitLogger)
 	if err != nil {
@@ -222,6 +315,9 @@ func (am *Alertmanager) SaveAndApplyDefaultConfig() error {
 // SaveAndApplyConfig saves the configuration the database and applies the configuration to the Alertmanager.
 // It rollbacks the save if we fail to apply the configuration.
 func (am *Alertmanager) SaveAndApplyConfig(cfg *apimodels.PostableUserConfig) error {
+	// The fingerprint of the configuration the changes were made from is not part of the configuration.
+	fetchedFingerprint := cfg.Fingerprint
+	cfg.Fingerprint = ""
 	rawConfig, err := json.Marshal(&cfg)
 	if err != nil {
 		return fmt.Errorf("failed to serialize to the Alertmanager configuration: %w", err)
@@ -234,6 +330,7 @@ func (am *Alertmanager) SaveAndApplyConfig(cfg *apimodels.PostableUserConfig) er
 		AlertmanagerConfiguration: string(rawConfig),
 		ConfigurationVersion:      fmt.Sprintf("v%d", ngmodels.AlertConfigurationVersion),
 		OrgID:                     am.orgID,
+		FetchedFingerprint:        fetchedFingerprint,
 	}
 
 	err = am.Store.SaveAlertmanagerConfigurationWithCallback(cmd, func() error {
@@ -297,7 +394,7 @@ func (am *Alertmanager) SyncAndApplyConfigFromDatabase() error {
 	return nil
 }
 
-func (am *Alertmanager) getTemplate() (*template.Template, error) {
+func (am *Alertmanager) getTemplate() (*templates, error) {
 	am.reloadConfigMtx.RLock()
 	defer am.reloadConfigMtx.RUnlock()
 	if !am.ready() {
@@ -307,7 +404,7 @@ func (am *Alertmanager) getTemplate() (*template.Template, error) {
 	for name := range am.config.TemplateFiles {
 		paths = append(paths, filepath.Join(am.WorkingDirPath(), name))
 	}
-	return am.templateFromPaths(paths...)
+	return am.newTemplates(paths)
 }
 
 func (am *Alertmanager) templateFromPaths(paths ...string) (*template.Template, error) {
@@ -344,7 +441,7 @@ func (am *Alertmanager) applyConfig(cfg *apimodels.PostableUserConfig, rawConfig
 	if cfg.TemplateFiles == nil {
 		cfg.TemplateFiles = map[string]string{}
 	}
-	cfg.TemplateFiles["__default__.tmpl"] = channels.DefaultTemplateString
+	cfg.TemplateFiles[defaultTemplateFile] = channels.DefaultTemplateString
 
 	// next, we need to make sure we persist the templates to disk.
 	paths, templatesChanged, err := PersistTemplates(cfg, am.WorkingDirPath())
@@ -359,7 +456,7 @@ func (am *Alertmanager) applyConfig(cfg *apimodels.PostableUserConfig, rawConfig
 	}
 
 	// With the templates persisted, create the template list using the paths.
-	tmpl, err := am.templateFromPaths(paths...)
+	tmpl, err := am.newTemplates(paths)
 	if err != nil {
 		return err
 	}
@@ -384,13 +481,28 @@ func (am *Alertmanager) applyConfig(cfg *apimodels.PostableUserConfig, rawConfig
 
 	inhibitionStage := notify.NewMuteStage(am.inhibitor)
 	silencingStage := notify.NewMuteStage(am.silencer)
+	am.escalations.setChains(cfg.AlertmanagerConfig.Escalations)
 	for name := range integrationsMap {
-		stage := am.createReceiverStage(name, integrationsMap[name], waitFunc, am.notificationLog)
-		routingStage[name] = notify.MultiStage{silencingStage, inhibitionStage, stage}
+		stage := am.createReceiverStage(name, integrationsMap[name], am.waitFunc, am.notificationLog)
+		stages := notify.MultiStage{&standbyStage{am: am, receiver: name}, &maintenanceStage{am: am, receiver: name}, silencingStage, inhibitionStage, &evaluationErrorStage{am: am}}
+		if am.escalations.hasChain(name) {
+			stages = append(stages, &escalationStage{escalations: am.escalations, receiver: name})
+		}
+		for _, e := range cfg.AlertmanagerConfig.Enrichments {
+			if e.AppliesTo(name) {
+				stages = append(stages, &enrichmentStage{enrichments: am.enrichments, config: e, receiver: name, logger: am.logger, metrics: am.Metrics})
+			}
+		}
+		routingStage[name] = append(stages, stage)
 	}
 
 	am.route = dispatch.NewRoute(cfg.AlertmanagerConfig.Route, nil)
-	am.dispatcher = dispatch.NewDispatcher(am.alerts, am.route, routingStage, am.marker, timeoutFunc, am.gokitLogger, am.dispatcherMetrics)
+	am.resolvedNotifications.setRoutes(cfg.AlertmanagerConfig.Route, am.route, cfg.AlertmanagerConfig.ResolvedNotifications)
+	var stage notify.Stage = routingStage
+	if am.dispatchShards != nil {
+		stage = &shardedStage{shards: am.dispatchShards, stage: routingStage}
+	}
+	am.dispatcher = dispatch.NewDispatcher(am.alerts, am.route, stage, am.marker, am.timeoutFunc, am.gokitLogger, am.dispatcherMetrics)
 
 	am.wg.Add(1)
 	go func() {
@@ -415,7 +527,7 @@ func (am *Alertmanager) WorkingDirPath() string {
 }
 
 // buildIntegrationsMap builds a map of name to the list of Grafana integration notifiers off of a list of receiver config.
-func (am *Alertmanager) buildIntegrationsMap(receivers []*apimodels.PostableApiReceiver, templates *template.Template) (map[string][]notify.Integration, error) {
+func (am *Alertmanager) buildIntegrationsMap(receivers []*apimodels.PostableApiReceiver, templates *templates) (map[string][]notify.Integration, error) {
 	integrationsMap := make(map[string][]notify.Integration, len(receivers))
 	for _, receiver := range receivers {
 		integrations, err := am.buildReceiverIntegrations(receiver, templates)
@@ -434,19 +546,34 @@ type NotificationChannel interface {
 }
 
 // buildReceiverIntegrations builds a list of integration notifiers off of a receiver config.
-func (am *Alertmanager) buildReceiverIntegrations(receiver *apimodels.PostableApiReceiver, tmpl *template.Template) ([]notify.Integration, error) {
+func (am *Alertmanager) buildReceiverIntegrations(receiver *apimodels.PostableApiReceiver, tmpl *templates) ([]notify.Integration, error) {
 	var integrations []notify.Integration
 	for i, r := range receiver.GrafanaManagedReceivers {
 		n, err := am.buildReceiverIntegration(r, tmpl)
 		if err != nil {
 			return nil, err
 		}
+		if r.Type == "webhook" {
+			integration := r.UID
+			if integration == "" {
+				integration = fmt.Sprintf("%s/%d", receiver.Name, i)
+			}
+			n = &groupResponseNotifier{NotificationChannel: n, am: am, integration: integration}
+		}
+		// The notifications dropped by the quotas are not sent, they are not published.
+		n = &eventNotifier{NotificationChannel: n, am: am, integrationType: r.Type}
+		n = &instrumentedNotifier{NotificationChannel: n, am: am, integrationType: r.Type}
+		if am.Settings != nil && hasNotificationQuota(r.Type, am.Settings.AlertingLimitsForOrg(am.orgID)) {
+			n = &quotaNotifier{NotificationChannel: n, am: am, integrationType: r.Type}
+		}
+		n = &tracedNotifier{NotificationChannel: n, orgID: am.orgID, integrationType: r.Type}
+		n = &resolvedNotifier{NotificationChannel: n, am: am}
 		integrations = append(integrations, notify.NewIntegration(n, n, r.Type, i))
 	}
 	return integrations, nil
 }
 
-func (am *Alertmanager) buildReceiverIntegration(r *apimodels.PostableGrafanaReceiver, tmpl *template.Template) (NotificationChannel, error) {
+func (am *Alertmanager) buildReceiverIntegration(r *apimodels.PostableGrafanaReceiver, templates *templates) (NotificationChannel, error) {
 	// secure settings are already encrypted at this point
 	secureSettings := securejsondata.SecureJsonData(make(map[string][]byte, len(r.SecureSettings)))
 
@@ -461,68 +588,88 @@ func (am *Alertmanager) buildReceiverIntegration(r *apimodels.PostableGrafanaRec
 		secureSettings[k] = d
 	}
 
-	var (
-		cfg = &channels.NotificationChannelConfig{
-			UID:                   r.UID,
-			Name:                  r.Name,
-			Type:                  r.Type,
-			DisableResolveMessage: r.DisableResolveMessage,
-			Settings:              r.Settings,
-			SecureSettings:        secureSettings,
+	tmpl, err := templates.forLocale(r.Locale)
+	if err != nil {
+		return nil, InvalidReceiverError{
+			Receiver: r,
+			Err:      err,
 		}
-		n   NotificationChannel
-		err error
-	)
-	switch r.Type {
+	}
+
+	cfg := &channels.NotificationChannelConfig{
+		UID:                   r.UID,
+		Name:                  r.Name,
+		Type:                  r.Type,
+		DisableResolveMessage: r.DisableResolveMessage,
+		Settings:              r.Settings,
+		SecureSettings:        secureSettings,
+	}
+
+	// Settings referencing an external secret manager are resolved when sending notifications.
+	refs := secretReferences(cfg)
+	if len(refs) > 0 {
+		for _, ref := range refs {
+			if err := am.secrets.Configured(ref); err != nil {
+				return nil, InvalidReceiverError{
+					Receiver: r,
+					Err:      err,
+				}
+			}
+		}
+		return newSecretRefNotifier(cfg, refs, tmpl, am.secrets), nil
+	}
+
+	n, err := newNotificationChannel(cfg, tmpl)
+	if err != nil {
+		return nil, InvalidReceiverError{
+			Receiver: r,
+			Err:      err,
+		}
+	}
+
+	return n, nil
+}
+
+// newNotificationChannel builds the notifier of the integration type of the config.
+func newNotificationChannel(cfg *channels.NotificationChannelConfig, tmpl *template.Template) (NotificationChannel, error) {
+	switch cfg.Type {
 	case "email":
-		n, err = channels.NewEmailNotifier(cfg, tmpl) // Email notifier already has a default template.
+		return channels.NewEmailNotifier(cfg, tmpl) // Email notifier already has a default template.
 	case "pagerduty":
-		n, err = channels.NewPagerdutyNotifier(cfg, tmpl)
+		return channels.NewPagerdutyNotifier(cfg, tmpl)
 	case "pushover":
-		n, err = channels.NewPushoverNotifier(cfg, tmpl)
+		return channels.NewPushoverNotifier(cfg, tmpl)
 	case "slack":
-		n, err = channels.NewSlackNotifier(cfg, tmpl)
+		return channels.NewSlackNotifier(cfg, tmpl)
 	case "telegram":
-		n, err = channels.NewTelegramNotifier(cfg, tmpl)
+		return channels.NewTelegramNotifier(cfg, tmpl)
 	case "victorops":
-		n, err = channels.NewVictoropsNotifier(cfg, tmpl)
+		return channels.NewVictoropsNotifier(cfg, tmpl)
 	case "teams":
-		n, err = channels.NewTeamsNotifier(cfg, tmpl)
+		return channels.NewTeamsNotifier(cfg, tmpl)
 	case "dingding":
-		n, err = channels.NewDingDingNotifier(cfg, tmpl)
+		return channels.NewDingDingNotifier(cfg, tmpl)
 	case "kafka":
-		n, err = channels.NewKafkaNotifier(cfg, tmpl)
+		return channels.NewKafkaNotifier(cfg, tmpl)
 	case "webhook":
-		n, err = channels.NewWebHookNotifier(cfg, tmpl)
+		return channels.NewWebHookNotifier(cfg, tmpl)
 	case "sensugo":
-		n, err = channels.NewSensuGoNotifier(cfg, tmpl)
+		return channels.NewSensuGoNotifier(cfg, tmpl)
 	case "discord":
-		n, err = channels.NewDiscordNotifier(cfg, tmpl)
+		return channels.NewDiscordNotifier(cfg, tmpl)
 	case "googlechat":
-		n, err = channels.NewGoogleChatNotifier(cfg, tmpl)
+		return channels.NewGoogleChatNotifier(cfg, tmpl)
 	case "LINE":
-		n, err = channels.NewLineNotifier(cfg, tmpl)
+		return channels.NewLineNotifier(cfg, tmpl)
 	case "threema":
-		n, err = channels.NewThreemaNotifier(cfg, tmpl)
+		return channels.NewThreemaNotifier(cfg, tmpl)
 	case "opsgenie":
-		n, err = channels.NewOpsgenieNotifier(cfg, tmpl)
+		return channels.NewOpsgenieNotifier(cfg, tmpl)
 	case "prometheus-alertmanager":
-		n, err = channels.NewAlertmanagerNotifier(cfg, tmpl)
+		return channels.NewAlertmanagerNotifier(cfg, tmpl)
 	default:
-		return nil, InvalidReceiverError{
-			Receiver: r,
-			Err:      fmt.Errorf("notifier %s is not supported", r.Type),
-		}
+		return nil, fmt.Errorf("notifier %s is not supported", cfg.Type)
 	}
-
-	if err != nil {
-		return nil, InvalidReceiverError{
-			Receiver: r,
-			Err:      err,
-		}
-	}
-
-	return n, nil
 }
 
 // PutAlerts receives the alerts and then sends them through the corresponding route based on whenever the alert has a receiver embedded or not
@@ -586,6 +733,7 @@ func (am *Alertmanager) PutAlerts(postableAlerts apimodels.PostableAlerts) error
 			continue
 		}
 
+		am.delayResolved(alert, now)
 		alerts = append(alerts, alert)
 	}
 
@@ -691,19 +839,17 @@ func (am *Alertmanager) createReceiverStage(name string, integrations []notify.I
 	return fs
 }
 
-func waitFunc() time.Duration {
-	// When it's a single instance, we don't need additional wait. The routing policies will have their own group wait.
-	// We need >0 wait here in case we have peers to sync the notification state with. 0 wait in that case can result
-	// in duplicate notifications being sent.
-	// TODO: we have setting.AlertingNotificationTimeout in legacy settings. Either use that or separate set of config
-	// for clustering with intuitive name, like "PeerTimeout".
-	return 0
+// waitFunc is how long the Alertmanager waits before sending a notification. Each peer of the cluster waits one
+// peer timeout more than the previous one, so the notification log of the peer that sent it is received first.
+// A single instance has position 0 and doesn't wait, the routing policies have their own group wait.
+func (am *Alertmanager) waitFunc() time.Duration {
+	return time.Duration(am.peer.Position()) * am.Settings.HAPeerTimeout
 }
 
-func timeoutFunc(d time.Duration) time.Duration {
+func (am *Alertmanager) timeoutFunc(d time.Duration) time.Duration {
 	//TODO: What does MinTimeout means here?
 	if d < notify.MinTimeout {
 		d = notify.MinTimeout
 	}
-	return d + waitFunc()
+	return d + am.waitFunc()
 }
@@ -23,6 +23,7 @@ import (
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/logging"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 	"github.com/grafana/grafana/pkg/setting"
@@ -47,7 +48,7 @@ func setupAMTest(t *testing.T) *Alertmanager {
 		Logger:                 log.New("alertmanager-test"),
 	}
 
-	am, err := newAlertmanager(1, cfg, store, m)
+	am, err := newAlertmanager(1, cfg, store, nil, &NilPeer{}, m)
 	require.NoError(t, err)
 	return am
 }
@@ -58,6 +59,58 @@ func TestAlertmanager_ShouldUseDefaultConfigurationWhenNoConfiguration(t *testin
 	require.NotNil(t, am.config)
 }
 
+func TestAlertmanager_SaveAndApplyConfigRejectsStaleChanges(t *testing.T) {
+	am := setupAMTest(t)
+	require.NoError(t, am.SaveAndApplyDefaultConfig())
+
+	latestFingerprint := func() string {
+		q := &ngmodels.GetLatestAlertmanagerConfigurationQuery{OrgID: 1}
+		require.NoError(t, am.Store.GetLatestAlertmanagerConfiguration(q))
+		return q.Result.Fingerprint()
+	}
+	fetched := latestFingerprint()
+
+	cfg, err := LoadDefault()
+	require.NoError(t, err)
+	cfg.TemplateFiles = map[string]string{"a": "{{ define \"a\" }}a{{ end }}"}
+	cfg.Fingerprint = fetched
+	require.NoError(t, am.SaveAndApplyConfig(cfg))
+	require.Empty(t, cfg.Fingerprint)
+	require.NotEqual(t, fetched, latestFingerprint())
+
+	// The second change was made from the configuration before the first one.
+	cfg, err = LoadDefault()
+	require.NoError(t, err)
+	cfg.Fingerprint = fetched
+	require.ErrorIs(t, am.SaveAndApplyConfig(cfg), store.ErrAlertmanagerConfigurationConflict)
+
+	// The configuration is saved regardless of the latest one without a fingerprint.
+	cfg, err = LoadDefault()
+	require.NoError(t, err)
+	require.NoError(t, am.SaveAndApplyConfig(cfg))
+}
+
+type positionedPeer struct {
+	NilPeer
+	position int
+}
+
+func (p *positionedPeer) Position() int { return p.position }
+
+func TestAlertmanager_WaitFunc(t *testing.T) {
+	am := setupAMTest(t)
+	am.Settings.HAPeerTimeout = 15 * time.Second
+
+	// A single instance doesn't wait before sending notifications.
+	require.Equal(t, time.Duration(0), am.waitFunc())
+	require.Equal(t, 30*time.Second, am.timeoutFunc(30*time.Second))
+
+	// Each peer waits one peer timeout more than the previous one.
+	am.peer = &positionedPeer{position: 2}
+	require.Equal(t, 30*time.Second, am.waitFunc())
+	require.Equal(t, 60*time.Second, am.timeoutFunc(30*time.Second))
+}
+
 func TestPutAlert(t *testing.T) {
 	am := setupAMTest(t)
 
@@ -104,7 +104,7 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 		},
 	}
 
-	return []*alerting.NotifierPlugin{
+	notifiers := []*alerting.NotifierPlugin{
 		{
 			Type:        "dingding",
 			Name:        "DingDing",
@@ -824,4 +824,38 @@ func GetAvailableNotifiers() []*alerting.NotifierPlugin {
 			},
 		},
 	}
+
+	// Every notification channel sending HTTP requests can be sent through a proxy.
+	for _, n := range notifiers {
+		if n.Type == "email" {
+			continue
+		}
+		n.Options = append(n.Options, proxyOptions()...)
+	}
+	return notifiers
+}
+
+func proxyOptions() []alerting.NotifierOption {
+	return []alerting.NotifierOption{
+		{
+			Label:        "Proxy URL",
+			Description:  "HTTP proxy the notifications are sent through, e.g. http://proxy.example.com:3128. The proxy of the environment is used if empty.",
+			Element:      alerting.ElementTypeInput,
+			InputType:    alerting.InputTypeText,
+			PropertyName: "proxyUrl",
+		},
+		{
+			Label:        "Proxy username",
+			Element:      alerting.ElementTypeInput,
+			InputType:    alerting.InputTypeText,
+			PropertyName: "proxyUsername",
+		},
+		{
+			Label:        "Proxy password",
+			Element:      alerting.ElementTypeInput,
+			InputType:    alerting.InputTypePassword,
+			PropertyName: "proxyPassword",
+			Secure:       true,
+		},
+	}
 }
@@ -43,6 +43,11 @@ func NewAlertmanagerNotifier(model *NotificationChannelConfig, t *template.Templ
 	basicAuthUser := model.Settings.Get("basicAuthUser").MustString()
 	basicAuthPassword := model.DecryptedValue("basicAuthPassword", model.Settings.Get("basicAuthPassword").MustString())
 
+	proxyURL, err := model.ProxyURL()
+	if err != nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "invalid proxy URL", Err: err}
+	}
+
 	return &AlertmanagerNotifier{
 		NotifierBase: old_notifiers.NewNotifierBase(&models.AlertNotification{
 			Uid:                   model.UID,
@@ -54,6 +59,7 @@ func NewAlertmanagerNotifier(model *NotificationChannelConfig, t *template.Templ
 		basicAuthUser:     basicAuthUser,
 		basicAuthPassword: basicAuthPassword,
 		logger:            log.New("alerting.notifier.prometheus-alertmanager"),
+		proxyURL:          proxyURL,
 	}, nil
 }
 
@@ -65,6 +71,7 @@ type AlertmanagerNotifier struct {
 	basicAuthUser     string
 	basicAuthPassword string
 	logger            log.Logger
+	proxyURL          string
 }
 
 // Notify sends alert notifications to Alertmanager.
@@ -85,6 +92,7 @@ func (n *AlertmanagerNotifier) Notify(ctx context.Context, as ...*types.Alert) (
 			user:     n.basicAuthUser,
 			password: n.basicAuthPassword,
 			body:     body,
+			proxyURL: n.proxyURL,
 		}, n.logger); err != nil {
 			n.logger.Warn("Failed to send to Alertmanager", "error", err, "alertmanager", n.Name, "url", u.String())
 			errCnt++
@@ -7,54 +7,79 @@ import (
 
 	"github.com/prometheus/alertmanager/template"
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/templatefuncs"
 )
 
+// The functions of the registry are added to the functions of the Alertmanager, that every template parsed with
+// template.FromGlobs uses.
+func init() {
+	for name, f := range templatefuncs.FuncMap() {
+		template.DefaultFuncs[name] = f
+	}
+}
+
 var DefaultTemplateString = `
-{{ define "__subject" }}[{{ .Status | toUpper }}{{ if eq .Status "firing" }}:{{ .Alerts.Firing | len }}{{ end }}] {{ .GroupLabels.SortedPairs.Values | join " " }} {{ if gt (len .CommonLabels) (len .GroupLabels) }}({{ with .CommonLabels.Remove .GroupLabels.Names }}{{ .Values | join " " }}{{ end }}){{ end }}{{ end }}
+{{ define "__locale.status" }}{{ . | toUpper }}{{ end }}
+{{ define "__locale.Firing" }}Firing{{ end }}
+{{ define "__locale.Resolved" }}Resolved{{ end }}
+{{ define "__locale.Labels" }}Labels{{ end }}
+{{ define "__locale.Annotations" }}Annotations{{ end }}
+{{ define "__locale.Source" }}Source{{ end }}
+{{ define "__locale.Silence" }}Silence{{ end }}
+{{ define "__locale.Dashboard" }}Dashboard{{ end }}
+{{ define "__locale.Panel" }}Panel{{ end }}
+{{ define "__locale.Runbook" }}Runbook{{ end }}
+{{ define "locale.date" }}{{ date "2006-01-02 15:04:05 MST" . }}{{ end }}
+{{ define "locale.number" }}{{ formatNumber "." "," . }}{{ end }}
+{{ define "__subject" }}[{{ template "__locale.status" .Status }}{{ if eq .Status "firing" }}:{{ .Alerts.Firing | len }}{{ end }}] {{ .GroupLabels.SortedPairs.Values | join " " }} {{ if gt (len .CommonLabels) (len .GroupLabels) }}({{ with .CommonLabels.Remove .GroupLabels.Names }}{{ .Values | join " " }}{{ end }}){{ end }}{{ end }}
 
 {{ define "__text_alert_list" }}{{ range . }}
-Labels:
+{{ template "__locale.Labels" }}:
 {{ range .Labels.SortedPairs }} - {{ .Name }} = {{ .Value }}
-{{ end }}Annotations:
+{{ end }}{{ template "__locale.Annotations" }}:
 {{ range .Annotations.SortedPairs }} - {{ .Name }} = {{ .Value }}
-{{ end }}{{ if gt (len .GeneratorURL) 0 }}Source: {{ .GeneratorURL }}
-{{ end }}{{ if gt (len .SilenceURL) 0 }}Silence: {{ .SilenceURL }}
-{{ end }}{{ if gt (len .DashboardURL) 0 }}Dashboard: {{ .DashboardURL }}
-{{ end }}{{ if gt (len .PanelURL) 0 }}Panel: {{ .PanelURL }}
+{{ end }}{{ if gt (len .GeneratorURL) 0 }}{{ template "__locale.Source" }}: {{ .GeneratorURL }}
+{{ end }}{{ if gt (len .SilenceURL) 0 }}{{ template "__locale.Silence" }}: {{ .SilenceURL }}
+{{ end }}{{ if gt (len .DashboardURL) 0 }}{{ template "__locale.Dashboard" }}: {{ .DashboardURL }}
+{{ end }}{{ if gt (len .PanelURL) 0 }}{{ template "__locale.Panel" }}: {{ .PanelURL }}
+{{ end }}{{ if gt (len .RunbookURL) 0 }}{{ template "__locale.Runbook" }}: {{ .RunbookURL }}
 {{ end }}{{ end }}{{ end }}
 
 {{ define "default.title" }}{{ template "__subject" . }}{{ end }}
 
-{{ define "default.message" }}{{ if gt (len .Alerts.Firing) 0 }}**Firing**
+{{ define "default.message" }}{{ if gt (len .Alerts.Firing) 0 }}**{{ template "__locale.Firing" }}**
 {{ template "__text_alert_list" .Alerts.Firing }}{{ if gt (len .Alerts.Resolved) 0 }}
 
-{{ end }}{{ end }}{{ if gt (len .Alerts.Resolved) 0 }}**Resolved**
+{{ end }}{{ end }}{{ if gt (len .Alerts.Resolved) 0 }}**{{ template "__locale.Resolved" }}**
 {{ template "__text_alert_list" .Alerts.Resolved }}{{ end }}{{ end }}
 
 
 {{ define "__teams_text_alert_list" }}{{ range . }}
-Labels:
+{{ template "__locale.Labels" }}:
 {{ range .Labels.SortedPairs }} - {{ .Name }} = {{ .Value }}
 {{ end }}
-Annotations:
+{{ template "__locale.Annotations" }}:
 {{ range .Annotations.SortedPairs }} - {{ .Name }} = {{ .Value }}
 {{ end }}
-{{ if gt (len .GeneratorURL) 0 }}Source: {{ .GeneratorURL }}
+{{ if gt (len .GeneratorURL) 0 }}{{ template "__locale.Source" }}: {{ .GeneratorURL }}
 
-{{ end }}{{ if gt (len .SilenceURL) 0 }}Silence: {{ .SilenceURL }}
+{{ end }}{{ if gt (len .SilenceURL) 0 }}{{ template "__locale.Silence" }}: {{ .SilenceURL }}
 
-{{ end }}{{ if gt (len .DashboardURL) 0 }}Dashboard: {{ .DashboardURL }}
+{{ end }}{{ if gt (len .DashboardURL) 0 }}{{ template "__locale.Dashboard" }}: {{ .DashboardURL }}
 
-{{ end }}{{ if gt (len .PanelURL) 0 }}Panel: {{ .PanelURL }}
+{{ end }}{{ if gt (len .PanelURL) 0 }}{{ template "__locale.Panel" }}: {{ .PanelURL }}
+
+{{ end }}{{ if gt (len .RunbookURL) 0 }}{{ template "__locale.Runbook" }}: {{ .RunbookURL }}
 
 {{ end }}
 {{ end }}{{ end }}
 
 
-{{ define "teams.default.message" }}{{ if gt (len .Alerts.Firing) 0 }}**Firing**
+{{ define "teams.default.message" }}{{ if gt (len .Alerts.Firing) 0 }}**{{ template "__locale.Firing" }}**
 {{ template "__teams_text_alert_list" .Alerts.Firing }}{{ if gt (len .Alerts.Resolved) 0 }}
 
-{{ end }}{{ end }}{{ if gt (len .Alerts.Resolved) 0 }}**Resolved**
+{{ end }}{{ end }}{{ if gt (len .Alerts.Resolved) 0 }}**{{ template "__locale.Resolved" }}**
 {{ template "__teams_text_alert_list" .Alerts.Resolved }}{{ end }}{{ end }}
 `
 
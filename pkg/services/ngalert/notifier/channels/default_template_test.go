@@ -197,6 +197,11 @@ Silence: http://localhost/grafana/alerting/silence/new?alertmanager=grafana&matc
 
 `,
 		},
+		{
+			// The functions of the templatefuncs registry.
+			templateString: `{{ .CommonLabels | toJson }} {{ .Alerts.Firing | len | div 1 | humanizePercentage }}`,
+			expected:       `{"alertname":"alert1"} 50%`,
+		},
 	}
 
 	for _, c := range cases {
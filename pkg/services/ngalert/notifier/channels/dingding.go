@@ -30,6 +30,11 @@ func NewDingDingNotifier(model *NotificationChannelConfig, t *template.Template)
 
 	msgType := model.Settings.Get("msgType").MustString(defaultDingdingMsgType)
 
+	proxyURL, err := model.ProxyURL()
+	if err != nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "invalid proxy URL", Err: err}
+	}
+
 	return &DingDingNotifier{
 		NotifierBase: old_notifiers.NewNotifierBase(&models.AlertNotification{
 			Uid:                   model.UID,
@@ -38,22 +43,24 @@ func NewDingDingNotifier(model *NotificationChannelConfig, t *template.Template)
 			DisableResolveMessage: model.DisableResolveMessage,
 			Settings:              model.Settings,
 		}),
-		MsgType: msgType,
-		URL:     url,
-		Message: model.Settings.Get("message").MustString(`{{ template "default.message" .}}`),
-		log:     log.New("alerting.notifier.dingding"),
-		tmpl:    t,
+		MsgType:  msgType,
+		URL:      url,
+		Message:  model.Settings.Get("message").MustString(`{{ template "default.message" .}}`),
+		log:      log.New("alerting.notifier.dingding"),
+		tmpl:     t,
+		proxyURL: proxyURL,
 	}, nil
 }
 
 // DingDingNotifier is responsible for sending alert notifications to ding ding.
 type DingDingNotifier struct {
 	old_notifiers.NotifierBase
-	MsgType string
-	URL     string
-	Message string
-	tmpl    *template.Template
-	log     log.Logger
+	MsgType  string
+	URL      string
+	Message  string
+	tmpl     *template.Template
+	log      log.Logger
+	proxyURL string
 }
 
 // Notify sends the alert notification to dingding.
@@ -112,8 +119,9 @@ func (dd *DingDingNotifier) Notify(ctx context.Context, as ...*types.Alert) (boo
 	}
 
 	cmd := &models.SendWebhookSync{
-		Url:  u,
-		Body: string(body),
+		Url:      u,
+		Body:     string(body),
+		ProxyURL: dd.proxyURL,
 	}
 
 	if err := bus.DispatchCtx(ctx, cmd); err != nil {
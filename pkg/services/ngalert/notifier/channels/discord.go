@@ -24,6 +24,7 @@ type DiscordNotifier struct {
 	Content    string
 	AvatarURL  string
 	WebhookURL string
+	proxyURL   string
 }
 
 func NewDiscordNotifier(model *NotificationChannelConfig, t *template.Template) (*DiscordNotifier, error) {
@@ -40,6 +41,11 @@ func NewDiscordNotifier(model *NotificationChannelConfig, t *template.Template)
 
 	content := model.Settings.Get("message").MustString(`{{ template "default.message" . }}`)
 
+	proxyURL, err := model.ProxyURL()
+	if err != nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "invalid proxy URL", Err: err}
+	}
+
 	return &DiscordNotifier{
 		NotifierBase: old_notifiers.NewNotifierBase(&models.AlertNotification{
 			Uid:                   model.UID,
@@ -54,6 +60,7 @@ func NewDiscordNotifier(model *NotificationChannelConfig, t *template.Template)
 		WebhookURL: discordURL,
 		log:        log.New("alerting.notifier.discord"),
 		tmpl:       t,
+		proxyURL:   proxyURL,
 	}, nil
 }
 
@@ -106,6 +113,7 @@ func (d DiscordNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool,
 		HttpMethod:  "POST",
 		ContentType: "application/json",
 		Body:        string(body),
+		ProxyURL:    d.proxyURL,
 	}
 
 	if err := bus.DispatchCtx(ctx, cmd); err != nil {
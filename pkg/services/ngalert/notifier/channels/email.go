@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/url"
 	"path"
+	"path/filepath"
 
 	"github.com/prometheus/alertmanager/template"
 	"github.com/prometheus/alertmanager/types"
@@ -79,6 +80,20 @@ func (en *EmailNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool,
 		en.log.Debug("failed to parse external URL", "url", en.tmpl.ExternalURL.String(), "err", err.Error())
 	}
 
+	// Screenshots that were not uploaded to an external image storage are embedded in the email.
+	var embeddedFiles []string
+	embedded := map[string]struct{}{}
+	for i, alert := range data.Alerts {
+		if alert.ImageURL != "" || alert.imagePath == "" {
+			continue
+		}
+		data.Alerts[i].EmbeddedImage = filepath.Base(alert.imagePath)
+		if _, ok := embedded[alert.imagePath]; !ok {
+			embedded[alert.imagePath] = struct{}{}
+			embeddedFiles = append(embeddedFiles, alert.imagePath)
+		}
+	}
+
 	cmd := &models.SendEmailCommandSync{
 		SendEmailCommand: models.SendEmailCommand{
 			Subject: title,
@@ -94,9 +109,10 @@ func (en *EmailNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool,
 				"RuleUrl":           ruleURL,
 				"AlertPageUrl":      alertPageURL,
 			},
-			To:          en.Addresses,
-			SingleEmail: en.SingleEmail,
-			Template:    "ng_alert_notification",
+			To:            en.Addresses,
+			SingleEmail:   en.SingleEmail,
+			Template:      "ng_alert_notification",
+			EmbeddedFiles: embeddedFiles,
 		},
 	}
 
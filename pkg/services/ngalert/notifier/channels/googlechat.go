@@ -20,9 +20,10 @@ import (
 // alert notifications to Google chat.
 type GoogleChatNotifier struct {
 	old_notifiers.NotifierBase
-	URL  string
-	log  log.Logger
-	tmpl *template.Template
+	URL      string
+	log      log.Logger
+	tmpl     *template.Template
+	proxyURL string
 }
 
 func NewGoogleChatNotifier(model *NotificationChannelConfig, t *template.Template) (*GoogleChatNotifier, error) {
@@ -31,6 +32,11 @@ func NewGoogleChatNotifier(model *NotificationChannelConfig, t *template.Templat
 		return nil, receiverInitError{Cfg: *model, Reason: "could not find url property in settings"}
 	}
 
+	proxyURL, err := model.ProxyURL()
+	if err != nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "invalid proxy URL", Err: err}
+	}
+
 	return &GoogleChatNotifier{
 		NotifierBase: old_notifiers.NewNotifierBase(&models.AlertNotification{
 			Uid:                   model.UID,
@@ -39,9 +45,10 @@ func NewGoogleChatNotifier(model *NotificationChannelConfig, t *template.Templat
 			DisableResolveMessage: model.DisableResolveMessage,
 			Settings:              model.Settings,
 		}),
-		URL:  url,
-		log:  log.New("alerting.notifier.googlechat"),
-		tmpl: t,
+		URL:      url,
+		log:      log.New("alerting.notifier.googlechat"),
+		tmpl:     t,
+		proxyURL: proxyURL,
 	}, nil
 }
 
@@ -122,7 +129,8 @@ func (gcn *GoogleChatNotifier) Notify(ctx context.Context, as ...*types.Alert) (
 		HttpHeader: map[string]string{
 			"Content-Type": "application/json; charset=UTF-8",
 		},
-		Body: string(body),
+		Body:     string(body),
+		ProxyURL: gcn.proxyURL,
 	}
 
 	if err := bus.DispatchCtx(ctx, cmd); err != nil {
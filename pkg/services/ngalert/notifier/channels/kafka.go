@@ -24,6 +24,7 @@ type KafkaNotifier struct {
 	Topic    string
 	log      log.Logger
 	tmpl     *template.Template
+	proxyURL string
 }
 
 // NewKafkaNotifier is the constructor function for the Kafka notifier.
@@ -37,6 +38,11 @@ func NewKafkaNotifier(model *NotificationChannelConfig, t *template.Template) (*
 		return nil, receiverInitError{Cfg: *model, Reason: "could not find kafka topic property in settings"}
 	}
 
+	proxyURL, err := model.ProxyURL()
+	if err != nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "invalid proxy URL", Err: err}
+	}
+
 	return &KafkaNotifier{
 		NotifierBase: old_notifiers.NewNotifierBase(&models.AlertNotification{
 			Uid:                   model.UID,
@@ -49,6 +55,7 @@ func NewKafkaNotifier(model *NotificationChannelConfig, t *template.Template) (*
 		Topic:    topic,
 		log:      log.New("alerting.notifier.kafka"),
 		tmpl:     t,
+		proxyURL: proxyURL,
 	}, nil
 }
 
@@ -107,6 +114,7 @@ func (kn *KafkaNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool,
 			"Content-Type": "application/vnd.kafka.json.v2+json",
 			"Accept":       "application/vnd.kafka.v2+json",
 		},
+		ProxyURL: kn.proxyURL,
 	}
 
 	if err := bus.DispatchCtx(ctx, cmd); err != nil {
@@ -26,6 +26,11 @@ func NewLineNotifier(model *NotificationChannelConfig, t *template.Template) (*L
 		return nil, receiverInitError{Cfg: *model, Reason: "could not find token in settings"}
 	}
 
+	proxyURL, err := model.ProxyURL()
+	if err != nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "invalid proxy URL", Err: err}
+	}
+
 	return &LineNotifier{
 		NotifierBase: old_notifiers.NewNotifierBase(&models.AlertNotification{
 			Uid:                   model.UID,
@@ -34,9 +39,10 @@ func NewLineNotifier(model *NotificationChannelConfig, t *template.Template) (*L
 			DisableResolveMessage: model.DisableResolveMessage,
 			Settings:              model.Settings,
 		}),
-		Token: token,
-		log:   log.New("alerting.notifier.line"),
-		tmpl:  t,
+		Token:    token,
+		log:      log.New("alerting.notifier.line"),
+		tmpl:     t,
+		proxyURL: proxyURL,
 	}, nil
 }
 
@@ -44,9 +50,10 @@ func NewLineNotifier(model *NotificationChannelConfig, t *template.Template) (*L
 // alert notifications to LINE.
 type LineNotifier struct {
 	old_notifiers.NotifierBase
-	Token string
-	log   log.Logger
-	tmpl  *template.Template
+	Token    string
+	log      log.Logger
+	tmpl     *template.Template
+	proxyURL string
 }
 
 // Notify send an alert notification to LINE
@@ -78,7 +85,8 @@ func (ln *LineNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, e
 			"Authorization": fmt.Sprintf("Bearer %s", ln.Token),
 			"Content-Type":  "application/x-www-form-urlencoded;charset=UTF-8",
 		},
-		Body: form.Encode(),
+		Body:     form.Encode(),
+		ProxyURL: ln.proxyURL,
 	}
 
 	if err := bus.DispatchCtx(ctx, cmd); err != nil {
@@ -0,0 +1,144 @@
+package channels
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Locale is a locale of the default templates: the translations of their phrases and the formats of the dates and
+// the numbers of the templates locale.date and locale.number.
+type Locale struct {
+	// Phrases are the translations of the phrases of the default templates, by their English phrase. The phrases
+	// without translation are in English.
+	Phrases map[string]string
+	// DateLayout is the layout of the dates in UTC, in the format of the Go time package.
+	DateLayout         string
+	DecimalSeparator   string
+	ThousandsSeparator string
+}
+
+// LocalePhrases are the English phrases of the default templates, FIRING and RESOLVED are the statuses in the titles.
+var LocalePhrases = []string{"FIRING", "RESOLVED", "Firing", "Resolved", "Labels", "Annotations", "Source", "Silence", "Dashboard", "Panel", "Runbook"}
+
+// Catalog provides the locales of the default templates.
+type Catalog interface {
+	Locale(name string) (Locale, bool)
+}
+
+// Locales is a Catalog of the locales by name.
+type Locales map[string]Locale
+
+func (l Locales) Locale(name string) (Locale, bool) {
+	locale, ok := l[name]
+	return locale, ok
+}
+
+var localeNameRegex = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})*$`)
+
+var builtinLocales = Locales{
+	"de": {
+		Phrases: map[string]string{
+			"FIRING": "AKTIV", "RESOLVED": "BEHOBEN", "Firing": "Aktiv", "Resolved": "Behoben", "Labels": "Labels",
+			"Annotations": "Annotationen", "Source": "Quelle", "Silence": "Stummschalten", "Dashboard": "Dashboard",
+			"Panel": "Panel", "Runbook": "Runbook",
+		},
+		DateLayout:         "02.01.2006 15:04:05 MST",
+		DecimalSeparator:   ",",
+		ThousandsSeparator: ".",
+	},
+	"es": {
+		Phrases: map[string]string{
+			"FIRING": "ACTIVA", "RESOLVED": "RESUELTA", "Firing": "Activas", "Resolved": "Resueltas", "Labels": "Etiquetas",
+			"Annotations": "Anotaciones", "Source": "Origen", "Silence": "Silenciar", "Dashboard": "Panel de control",
+			"Panel": "Panel", "Runbook": "Procedimiento",
+		},
+		DateLayout:         "02/01/2006 15:04:05 MST",
+		DecimalSeparator:   ",",
+		ThousandsSeparator: ".",
+	},
+	"fr": {
+		Phrases: map[string]string{
+			"FIRING": "EN ALERTE", "RESOLVED": "RÉSOLUE", "Firing": "En alerte", "Resolved": "Résolues", "Labels": "Étiquettes",
+			"Annotations": "Annotations", "Source": "Source", "Silence": "Mettre en silence", "Dashboard": "Tableau de bord",
+			"Panel": "Panneau", "Runbook": "Procédure",
+		},
+		DateLayout:         "02/01/2006 15:04:05 MST",
+		DecimalSeparator:   ",",
+		ThousandsSeparator: " ",
+	},
+}
+
+var (
+	catalogsMtx sync.RWMutex
+	catalogs    = []Catalog{builtinLocales}
+)
+
+// RegisterCatalog adds a catalog of locales, which takes precedence over the catalogs registered before it and over
+// the built-in locales.
+func RegisterCatalog(c Catalog) {
+	catalogsMtx.Lock()
+	defer catalogsMtx.Unlock()
+	catalogs = append([]Catalog{c}, catalogs...)
+}
+
+// LookupLocale returns the locale of the catalogs with the name, such as fr, or the locale of its language if there
+// is none for its region, such as fr for fr-CA.
+func LookupLocale(name string) (Locale, error) {
+	if !localeNameRegex.MatchString(name) {
+		return Locale{}, fmt.Errorf("invalid locale %q", name)
+	}
+	catalogsMtx.RLock()
+	defer catalogsMtx.RUnlock()
+	for _, n := range []string{name, strings.SplitN(name, "-", 2)[0]} {
+		for _, c := range catalogs {
+			if locale, ok := c.Locale(n); ok {
+				return locale, nil
+			}
+		}
+	}
+	return Locale{}, fmt.Errorf("unknown locale %q", name)
+}
+
+// TemplateString returns the definitions of the templates of the locale, which replace the English definitions of
+// DefaultTemplateString when they are parsed after it.
+func (l Locale) TemplateString() string {
+	var b strings.Builder
+	firing, hasFiring := l.Phrases["FIRING"]
+	resolved, hasResolved := l.Phrases["RESOLVED"]
+	if hasFiring || hasResolved {
+		b.WriteString(`{{ define "__locale.status" }}`)
+		if hasFiring {
+			fmt.Fprintf(&b, `{{ if eq . "firing" }}{{ %s }}{{ else }}`, strconv.Quote(firing))
+		}
+		if hasResolved {
+			fmt.Fprintf(&b, `{{ if eq . "resolved" }}{{ %s }}{{ else }}`, strconv.Quote(resolved))
+		}
+		b.WriteString(`{{ . | toUpper }}`)
+		if hasResolved {
+			b.WriteString(`{{ end }}`)
+		}
+		if hasFiring {
+			b.WriteString(`{{ end }}`)
+		}
+		b.WriteString("{{ end }}\n")
+	}
+	for _, phrase := range LocalePhrases[2:] {
+		if t, ok := l.Phrases[phrase]; ok {
+			fmt.Fprintf(&b, `{{ define "__locale.%s" }}{{ %s }}{{ end }}`+"\n", phrase, strconv.Quote(t))
+		}
+	}
+	if l.DateLayout != "" {
+		fmt.Fprintf(&b, `{{ define "locale.date" }}{{ date %s . }}{{ end }}`+"\n", strconv.Quote(l.DateLayout))
+	}
+	if l.DecimalSeparator != "" || l.ThousandsSeparator != "" {
+		decimal := l.DecimalSeparator
+		if decimal == "" {
+			decimal = "."
+		}
+		fmt.Fprintf(&b, `{{ define "locale.number" }}{{ formatNumber %s %s . }}{{ end }}`+"\n", strconv.Quote(decimal), strconv.Quote(l.ThousandsSeparator))
+	}
+	return b.String()
+}
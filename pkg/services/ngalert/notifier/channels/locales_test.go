@@ -0,0 +1,32 @@
+package channels
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupLocale(t *testing.T) {
+	RegisterCatalog(Locales{"zz": {Phrases: map[string]string{"Firing": "{{ Zz }}"}}})
+
+	l, err := LookupLocale("zz-ZZ")
+	require.NoError(t, err)
+	// The phrases are not templates.
+	require.Equal(t, `{{ define "__locale.Firing" }}{{ "{{ Zz }}" }}{{ end }}`+"\n", l.TemplateString())
+
+	_, err = LookupLocale("fr")
+	require.NoError(t, err)
+	_, err = LookupLocale("yy")
+	require.EqualError(t, err, `unknown locale "yy"`)
+	_, err = LookupLocale("../fr")
+	require.EqualError(t, err, `invalid locale "../fr"`)
+}
+
+func TestBuiltinLocales(t *testing.T) {
+	for name, l := range builtinLocales {
+		for _, phrase := range LocalePhrases {
+			require.NotEmpty(t, l.Phrases[phrase], "%s has no translation of %s", name, phrase)
+		}
+		require.NotEmpty(t, l.DateLayout, name)
+	}
+}
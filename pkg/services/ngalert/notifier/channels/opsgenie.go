@@ -40,6 +40,7 @@ type OpsgenieNotifier struct {
 	SendTagsAs       string
 	tmpl             *template.Template
 	log              log.Logger
+	proxyURL         string
 }
 
 // NewOpsgenieNotifier is the constructor for the Opsgenie notifier
@@ -62,6 +63,11 @@ func NewOpsgenieNotifier(model *NotificationChannelConfig, t *template.Template)
 		}
 	}
 
+	proxyURL, err := model.ProxyURL()
+	if err != nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "invalid proxy URL", Err: err}
+	}
+
 	return &OpsgenieNotifier{
 		NotifierBase: old_notifiers.NewNotifierBase(&models.AlertNotification{
 			Uid:                   model.UID,
@@ -77,6 +83,7 @@ func NewOpsgenieNotifier(model *NotificationChannelConfig, t *template.Template)
 		SendTagsAs:       sendTagsAs,
 		tmpl:             t,
 		log:              log.New("alerting.notifier." + model.Name),
+		proxyURL:         proxyURL,
 	}, nil
 }
 
@@ -114,6 +121,7 @@ func (on *OpsgenieNotifier) Notify(ctx context.Context, as ...*types.Alert) (boo
 			"Content-Type":  "application/json",
 			"Authorization": fmt.Sprintf("GenieKey %s", on.APIKey),
 		},
+		ProxyURL: on.proxyURL,
 	}
 
 	if err := bus.DispatchCtx(ctx, cmd); err != nil {
@@ -39,6 +39,7 @@ type PagerdutyNotifier struct {
 	Summary       string
 	tmpl          *template.Template
 	log           log.Logger
+	proxyURL      string
 }
 
 // NewPagerdutyNotifier is the constructor for the PagerDuty notifier
@@ -52,6 +53,11 @@ func NewPagerdutyNotifier(model *NotificationChannelConfig, t *template.Template
 		return nil, receiverInitError{Cfg: *model, Reason: "could not find integration key property in settings"}
 	}
 
+	proxyURL, err := model.ProxyURL()
+	if err != nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "invalid proxy URL", Err: err}
+	}
+
 	return &PagerdutyNotifier{
 		NotifierBase: old_notifiers.NewNotifierBase(&models.AlertNotification{
 			Uid:                   model.UID,
@@ -74,6 +80,7 @@ func NewPagerdutyNotifier(model *NotificationChannelConfig, t *template.Template
 		Summary:   model.Settings.Get("summary").MustString(`{{ template "default.title" . }}`),
 		tmpl:      t,
 		log:       log.New("alerting.notifier." + model.Name),
+		proxyURL:  proxyURL,
 	}, nil
 }
 
@@ -103,6 +110,7 @@ func (pn *PagerdutyNotifier) Notify(ctx context.Context, as ...*types.Alert) (bo
 		HttpHeader: map[string]string{
 			"Content-Type": "application/json",
 		},
+		ProxyURL: pn.proxyURL,
 	}
 	if err := bus.DispatchCtx(ctx, cmd); err != nil {
 		return false, fmt.Errorf("send notification to Pagerduty: %w", err)
@@ -37,6 +37,7 @@ type PushoverNotifier struct {
 	Message          string
 	tmpl             *template.Template
 	log              log.Logger
+	proxyURL         string
 }
 
 // NewSlackNotifier is the constructor for the Slack notifier
@@ -68,6 +69,11 @@ func NewPushoverNotifier(model *NotificationChannelConfig, t *template.Template)
 	if APIToken == "" {
 		return nil, receiverInitError{Cfg: *model, Reason: "API token not found"}
 	}
+	proxyURL, err := model.ProxyURL()
+	if err != nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "invalid proxy URL", Err: err}
+	}
+
 	return &PushoverNotifier{
 		NotifierBase: old_notifiers.NewNotifierBase(&models.AlertNotification{
 			Uid:                   model.UID,
@@ -90,6 +96,7 @@ func NewPushoverNotifier(model *NotificationChannelConfig, t *template.Template)
 		Message:          model.Settings.Get("message").MustString(`{{ template "default.message" .}}`),
 		tmpl:             t,
 		log:              log.New("alerting.notifier.pushover"),
+		proxyURL:         proxyURL,
 	}, nil
 }
 
@@ -106,6 +113,7 @@ func (pn *PushoverNotifier) Notify(ctx context.Context, as ...*types.Alert) (boo
 		HttpMethod: "POST",
 		HttpHeader: headers,
 		Body:       uploadBody.String(),
+		ProxyURL:   pn.proxyURL,
 	}
 
 	if err := bus.DispatchCtx(ctx, cmd); err != nil {
@@ -28,6 +28,7 @@ type SensuGoNotifier struct {
 	Handler   string
 	APIKey    string
 	Message   string
+	proxyURL  string
 }
 
 // NewSensuGoNotifier is the constructor for the SensuGo notifier
@@ -46,6 +47,11 @@ func NewSensuGoNotifier(model *NotificationChannelConfig, t *template.Template)
 		return nil, receiverInitError{Cfg: *model, Reason: "could not find the API key property in settings"}
 	}
 
+	proxyURL, err := model.ProxyURL()
+	if err != nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "invalid proxy URL", Err: err}
+	}
+
 	return &SensuGoNotifier{
 		NotifierBase: old_notifiers.NewNotifierBase(&models.AlertNotification{
 			Uid:                   model.UID,
@@ -64,6 +70,7 @@ func NewSensuGoNotifier(model *NotificationChannelConfig, t *template.Template)
 		Message:   model.Settings.Get("message").MustString(`{{ template "default.message" .}}`),
 		log:       log.New("alerting.notifier.sensugo"),
 		tmpl:      t,
+		proxyURL:  proxyURL,
 	}, nil
 }
 
@@ -144,6 +151,7 @@ func (sn *SensuGoNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool
 			"Content-Type":  "application/json",
 			"Authorization": fmt.Sprintf("Key %s", sn.APIKey),
 		},
+		ProxyURL: sn.proxyURL,
 	}
 	if err := bus.DispatchCtx(ctx, cmd); err != nil {
 		sn.log.Error("Failed to send Sensu Go event", "error", err, "sensugo", sn.Name)
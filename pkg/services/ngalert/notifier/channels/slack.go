@@ -42,6 +42,7 @@ type SlackNotifier struct {
 	MentionGroups  []string
 	MentionChannel string
 	Token          string
+	proxyURL       string
 }
 
 var reRecipient *regexp.Regexp = regexp.MustCompile("^((@[a-z0-9][a-zA-Z0-9._-]*)|(#[^ .A-Z]{1,79})|([a-zA-Z0-9]+))$")
@@ -106,6 +107,11 @@ func NewSlackNotifier(model *NotificationChannelConfig, t *template.Template) (*
 		}
 	}
 
+	proxyURL, err := model.ProxyURL()
+	if err != nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "invalid proxy URL", Err: err}
+	}
+
 	return &SlackNotifier{
 		NotifierBase: old_notifiers.NewNotifierBase(&models.AlertNotification{
 			Uid:                   model.UID,
@@ -127,6 +133,7 @@ func NewSlackNotifier(model *NotificationChannelConfig, t *template.Template) (*
 		Title:          model.Settings.Get("title").MustString(`{{ template "default.title" . }}`),
 		log:            log.New("alerting.notifier.slack"),
 		tmpl:           t,
+		proxyURL:       proxyURL,
 	}, nil
 }
 
@@ -151,6 +158,7 @@ type attachment struct {
 	FooterIcon string              `json:"footer_icon"`
 	Color      string              `json:"color,omitempty"`
 	Ts         int64               `json:"ts,omitempty"`
+	ImageURL   string              `json:"image_url,omitempty"`
 }
 
 // Notify sends an alert notification to Slack.
@@ -182,7 +190,7 @@ func (sn *SlackNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool,
 		request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", sn.Token))
 	}
 
-	if err := sendSlackRequest(request, sn.log); err != nil {
+	if err := sendSlackRequest(request, sn.proxyURL, sn.log); err != nil {
 		return false, err
 	}
 	return true, nil
@@ -190,12 +198,16 @@ func (sn *SlackNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool,
 
 // sendSlackRequest sends a request to the Slack API.
 // Stubbable by tests.
-var sendSlackRequest = func(request *http.Request, logger log.Logger) error {
+var sendSlackRequest = func(request *http.Request, proxyURL string, logger log.Logger) error {
+	proxy, err := proxyFunc(proxyURL)
+	if err != nil {
+		return fmt.Errorf("invalid proxy URL: %w", err)
+	}
 	netTransport := &http.Transport{
 		TLSClientConfig: &tls.Config{
 			Renegotiation: tls.RenegotiateFreelyAsClient,
 		},
-		Proxy: http.ProxyFromEnvironment,
+		Proxy: proxy,
 		DialContext: (&net.Dialer{
 			Timeout: 30 * time.Second,
 		}).DialContext,
@@ -243,7 +255,7 @@ var sendSlackRequest = func(request *http.Request, logger log.Logger) error {
 func (sn *SlackNotifier) buildSlackMessage(ctx context.Context, as []*types.Alert) (*slackMessage, error) {
 	alerts := types.Alerts(as...)
 	var tmplErr error
-	tmpl, _ := TmplText(ctx, sn.tmpl, as, sn.log, &tmplErr)
+	tmpl, data := TmplText(ctx, sn.tmpl, as, sn.log, &tmplErr)
 
 	ruleURL := joinUrlPath(sn.tmpl.ExternalURL.String(), "/alerting/list", sn.log)
 
@@ -270,6 +282,14 @@ func (sn *SlackNotifier) buildSlackMessage(ctx context.Context, as []*types.Aler
 		sn.log.Debug("failed to template Slack message", "err", tmplErr.Error())
 	}
 
+	// Slack can only show one image per attachment, use the screenshot of the first alert that has one.
+	for _, alert := range data.Alerts {
+		if alert.ImageURL != "" {
+			req.Attachments[0].ImageURL = alert.ImageURL
+			break
+		}
+	}
+
 	mentionsBuilder := strings.Builder{}
 	appendSpace := func() {
 		if mentionsBuilder.Len() > 0 {
@@ -18,10 +18,11 @@ import (
 // alert notifications to Microsoft teams.
 type TeamsNotifier struct {
 	old_notifiers.NotifierBase
-	URL     string
-	Message string
-	tmpl    *template.Template
-	log     log.Logger
+	URL      string
+	Message  string
+	tmpl     *template.Template
+	log      log.Logger
+	proxyURL string
 }
 
 // NewTeamsNotifier is the constructor for Teams notifier.
@@ -35,6 +36,11 @@ func NewTeamsNotifier(model *NotificationChannelConfig, t *template.Template) (*
 		return nil, receiverInitError{Cfg: *model, Reason: "could not find url property in settings"}
 	}
 
+	proxyURL, err := model.ProxyURL()
+	if err != nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "invalid proxy URL", Err: err}
+	}
+
 	return &TeamsNotifier{
 		NotifierBase: old_notifiers.NewNotifierBase(&models.AlertNotification{
 			Uid:                   model.UID,
@@ -43,10 +49,11 @@ func NewTeamsNotifier(model *NotificationChannelConfig, t *template.Template) (*
 			DisableResolveMessage: model.DisableResolveMessage,
 			Settings:              model.Settings,
 		}),
-		URL:     u,
-		Message: model.Settings.Get("message").MustString(`{{ template "teams.default.message" .}}`),
-		log:     log.New("alerting.notifier.teams"),
-		tmpl:    t,
+		URL:      u,
+		Message:  model.Settings.Get("message").MustString(`{{ template "teams.default.message" .}}`),
+		log:      log.New("alerting.notifier.teams"),
+		tmpl:     t,
+		proxyURL: proxyURL,
 	}, nil
 }
 
@@ -96,7 +103,7 @@ func (tn *TeamsNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool,
 	if err != nil {
 		return false, errors.Wrap(err, "marshal json")
 	}
-	cmd := &models.SendWebhookSync{Url: u, Body: string(b)}
+	cmd := &models.SendWebhookSync{Url: u, Body: string(b), ProxyURL: tn.proxyURL}
 
 	if err := bus.DispatchCtx(ctx, cmd); err != nil {
 		return false, errors.Wrap(err, "send notification to Teams")
@@ -28,6 +28,7 @@ type TelegramNotifier struct {
 	Message  string
 	log      log.Logger
 	tmpl     *template.Template
+	proxyURL string
 }
 
 // NewTelegramNotifier is the constructor for the Telegram notifier
@@ -48,6 +49,11 @@ func NewTelegramNotifier(model *NotificationChannelConfig, t *template.Template)
 		return nil, receiverInitError{Cfg: *model, Reason: "could not find Chat Id in settings"}
 	}
 
+	proxyURL, err := model.ProxyURL()
+	if err != nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "invalid proxy URL", Err: err}
+	}
+
 	return &TelegramNotifier{
 		NotifierBase: old_notifiers.NewNotifierBase(&models.AlertNotification{
 			Uid:                   model.UID,
@@ -61,6 +67,7 @@ func NewTelegramNotifier(model *NotificationChannelConfig, t *template.Template)
 		Message:  message,
 		tmpl:     t,
 		log:      log.New("alerting.notifier.telegram"),
+		proxyURL: proxyURL,
 	}, nil
 }
 
@@ -106,6 +113,7 @@ func (tn *TelegramNotifier) Notify(ctx context.Context, as ...*types.Alert) (boo
 		HttpHeader: map[string]string{
 			"Content-Type": w.FormDataContentType(),
 		},
+		ProxyURL: tn.proxyURL,
 	}
 
 	if err := bus.DispatchCtx(ctx, cmd); err != nil {
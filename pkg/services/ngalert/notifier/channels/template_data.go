@@ -30,6 +30,14 @@ type ExtendedAlert struct {
 	DashboardURL string      `json:"dashboardURL"`
 	PanelURL     string      `json:"panelURL"`
 	ValueString  string      `json:"valueString"`
+	// RunbookURL is the URL of the runbook of the alert's rule.
+	RunbookURL string `json:"runbookURL,omitempty"`
+	// ImageURL is the public URL of the screenshot of the alert's panel.
+	ImageURL string `json:"imageURL,omitempty"`
+	// EmbeddedImage is the name of the screenshot embedded in emails.
+	EmbeddedImage string `json:"-"`
+
+	imagePath string
 }
 
 type ExtendedAlerts []ExtendedAlert
@@ -65,6 +73,7 @@ func extendAlert(alert template.Alert, externalURL string, logger log.Logger) *E
 		EndsAt:       alert.EndsAt,
 		GeneratorURL: alert.GeneratorURL,
 		Fingerprint:  alert.Fingerprint,
+		RunbookURL:   alert.Annotations["__runbookUrl__"],
 	}
 
 	// fill in some grafana-specific urls
@@ -90,6 +99,8 @@ func extendAlert(alert template.Alert, externalURL string, logger log.Logger) *E
 
 	if alert.Annotations != nil {
 		extended.ValueString = alert.Annotations[`__value_string__`]
+		extended.ImageURL = alert.Annotations["__alertImageUrl__"]
+		extended.imagePath = alert.Annotations["__alertImagePath__"]
 	}
 
 	matchers := make([]string, 0)
@@ -30,6 +30,7 @@ type ThreemaNotifier struct {
 	APISecret   string
 	log         log.Logger
 	tmpl        *template.Template
+	proxyURL    string
 }
 
 // NewThreemaNotifier is the constructor for the Threema notifier
@@ -62,6 +63,11 @@ func NewThreemaNotifier(model *NotificationChannelConfig, t *template.Template)
 		return nil, receiverInitError{Cfg: *model, Reason: "could not find Threema API secret in settings"}
 	}
 
+	proxyURL, err := model.ProxyURL()
+	if err != nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "invalid proxy URL", Err: err}
+	}
+
 	return &ThreemaNotifier{
 		NotifierBase: old_notifiers.NewNotifierBase(&models.AlertNotification{
 			Uid:                   model.UID,
@@ -75,6 +81,7 @@ func NewThreemaNotifier(model *NotificationChannelConfig, t *template.Template)
 		APISecret:   apiSecret,
 		log:         log.New("alerting.notifier.threema"),
 		tmpl:        t,
+		proxyURL:    proxyURL,
 	}, nil
 }
 
@@ -118,6 +125,7 @@ func (tn *ThreemaNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool
 		HttpHeader: map[string]string{
 			"Content-Type": "application/x-www-form-urlencoded",
 		},
+		ProxyURL: tn.proxyURL,
 	}
 	if err := bus.DispatchCtx(ctx, cmd); err != nil {
 		tn.log.Error("Failed to send threema notification", "error", err, "webhook", tn.Name)
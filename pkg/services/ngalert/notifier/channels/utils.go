@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -72,10 +73,50 @@ func (an *NotificationChannelConfig) DecryptedValue(field string, fallback strin
 	return fallback
 }
 
+// ProxyURL returns the URL of the HTTP proxy the notifications are sent through, including the proxy credentials.
+// It returns an empty string if no proxy is configured, in which case the proxy of the environment is used.
+func (an *NotificationChannelConfig) ProxyURL() (string, error) {
+	if an.Settings == nil {
+		return "", nil
+	}
+	proxy := an.Settings.Get("proxyUrl").MustString()
+	if proxy == "" {
+		return "", nil
+	}
+
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5" {
+		return "", fmt.Errorf("unsupported proxy scheme %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return "", errors.New("proxy URL has no host")
+	}
+	if user := an.Settings.Get("proxyUsername").MustString(); user != "" {
+		u.User = url.UserPassword(user, an.DecryptedValue("proxyPassword", an.Settings.Get("proxyPassword").MustString()))
+	}
+	return u.String(), nil
+}
+
+// proxyFunc returns the proxy function of the transports sending requests through proxyURL.
+func proxyFunc(proxyURL string) (func(*http.Request) (*url.URL, error), error) {
+	if proxyURL == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, err
+	}
+	return http.ProxyURL(u), nil
+}
+
 type httpCfg struct {
 	body     []byte
 	user     string
 	password string
+	proxyURL string
 }
 
 // sendHTTPRequest sends an HTTP request.
@@ -95,11 +136,15 @@ var sendHTTPRequest = func(ctx context.Context, url *url.URL, cfg httpCfg, logge
 
 	request.Header.Set("Content-Type", "application/json")
 	request.Header.Set("User-Agent", "Grafana")
+	proxy, err := proxyFunc(cfg.proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
 	netTransport := &http.Transport{
 		TLSClientConfig: &tls.Config{
 			Renegotiation: tls.RenegotiateFreelyAsClient,
 		},
-		Proxy: http.ProxyFromEnvironment,
+		Proxy: proxy,
 		DialContext: (&net.Dialer{
 			Timeout: 30 * time.Second,
 		}).DialContext,
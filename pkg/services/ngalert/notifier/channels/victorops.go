@@ -34,6 +34,11 @@ func NewVictoropsNotifier(model *NotificationChannelConfig, t *template.Template
 		return nil, receiverInitError{Cfg: *model, Reason: "could not find victorops url property in settings"}
 	}
 
+	proxyURL, err := model.ProxyURL()
+	if err != nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "invalid proxy URL", Err: err}
+	}
+
 	return &VictoropsNotifier{
 		NotifierBase: old_notifiers.NewNotifierBase(&models.AlertNotification{
 			Uid:                   model.UID,
@@ -46,6 +51,7 @@ func NewVictoropsNotifier(model *NotificationChannelConfig, t *template.Template
 		MessageType: model.Settings.Get("messageType").MustString(),
 		log:         log.New("alerting.notifier.victorops"),
 		tmpl:        t,
+		proxyURL:    proxyURL,
 	}, nil
 }
 
@@ -58,6 +64,7 @@ type VictoropsNotifier struct {
 	MessageType string
 	log         log.Logger
 	tmpl        *template.Template
+	proxyURL    string
 }
 
 // Notify sends notification to Victorops via POST to URL endpoint
@@ -102,8 +109,9 @@ func (vn *VictoropsNotifier) Notify(ctx context.Context, as ...*types.Alert) (bo
 		return false, err
 	}
 	cmd := &models.SendWebhookSync{
-		Url:  u,
-		Body: string(b),
+		Url:      u,
+		Body:     string(b),
+		ProxyURL: vn.proxyURL,
 	}
 
 	if err := bus.DispatchCtx(ctx, cmd); err != nil {
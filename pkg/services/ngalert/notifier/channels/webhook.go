@@ -26,6 +26,7 @@ type WebhookNotifier struct {
 	MaxAlerts  int
 	log        log.Logger
 	tmpl       *template.Template
+	proxyURL   string
 }
 
 // NewWebHookNotifier is the constructor for
@@ -38,6 +39,11 @@ func NewWebHookNotifier(model *NotificationChannelConfig, t *template.Template)
 	if url == "" {
 		return nil, receiverInitError{Cfg: *model, Reason: "could not find url property in settings"}
 	}
+	proxyURL, err := model.ProxyURL()
+	if err != nil {
+		return nil, receiverInitError{Cfg: *model, Reason: "invalid proxy URL", Err: err}
+	}
+
 	return &WebhookNotifier{
 		NotifierBase: old_notifiers.NewNotifierBase(&models.AlertNotification{
 			Uid:                   model.UID,
@@ -53,6 +59,7 @@ func NewWebHookNotifier(model *NotificationChannelConfig, t *template.Template)
 		MaxAlerts:  model.Settings.Get("maxAlerts").MustInt(0),
 		log:        log.New("alerting.notifier.webhook"),
 		tmpl:       t,
+		proxyURL:   proxyURL,
 	}, nil
 }
 
@@ -65,6 +72,10 @@ type webhookMessage struct {
 	GroupKey        string `json:"groupKey"`
 	TruncatedAlerts int    `json:"truncatedAlerts"`
 
+	// Response is the JSON object returned by the webhook for the previous notifications of the alert group, merged
+	// in the order of the responses.
+	Response map[string]interface{} `json:"response,omitempty"`
+
 	// Deprecated, to be removed in 8.1.
 	// These are present to make migration a little less disruptive.
 	Title   string `json:"title"`
@@ -90,6 +101,10 @@ func (wn *WebhookNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool
 		Title:           tmpl(`{{ template "default.title" . }}`),
 		Message:         tmpl(`{{ template "default.message" . }}`),
 	}
+	response, hasResponse := groupResponseFromContext(ctx)
+	if hasResponse {
+		msg.Response = response.Get()
+	}
 
 	if types.Alerts(as...).Status() == model.AlertFiring {
 		msg.State = string(models.AlertStateAlerting)
@@ -112,6 +127,16 @@ func (wn *WebhookNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool
 		Password:   wn.Password,
 		Body:       string(body),
 		HttpMethod: wn.HTTPMethod,
+		ProxyURL:   wn.proxyURL,
+	}
+	if hasResponse {
+		cmd.ResponseBody = func(body []byte) {
+			var obj map[string]interface{}
+			// Only the JSON objects are kept, the other responses are ignored.
+			if err := json.Unmarshal(body, &obj); err == nil && len(obj) > 0 {
+				response.Merge(obj)
+			}
+		}
 	}
 
 	if err := bus.DispatchCtx(ctx, cmd); err != nil {
@@ -121,6 +146,27 @@ func (wn *WebhookNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool
 	return true, nil
 }
 
+// GroupResponse keeps the responses of a webhook to the notifications of an alert group, such as the ID of the ticket
+// created by the first notification, so that the next notifications of the group include them.
+type GroupResponse interface {
+	// Get returns the responses to the previous notifications merged, nil if there are none.
+	Get() map[string]interface{}
+	// Merge merges the response into the previous ones, its fields replace the fields with the same name.
+	Merge(response map[string]interface{})
+}
+
+type groupResponseKey struct{}
+
+// WithGroupResponse returns a context whose webhook notifications include the responses to the previous ones.
+func WithGroupResponse(ctx context.Context, r GroupResponse) context.Context {
+	return context.WithValue(ctx, groupResponseKey{}, r)
+}
+
+func groupResponseFromContext(ctx context.Context) (GroupResponse, bool) {
+	r, ok := ctx.Value(groupResponseKey{}).(GroupResponse)
+	return r, ok
+}
+
 func truncateAlerts(maxAlerts int, alerts []*types.Alert) ([]*types.Alert, int) {
 	if maxAlerts > 0 && len(alerts) > maxAlerts {
 		return alerts[:maxAlerts], len(alerts) - maxAlerts
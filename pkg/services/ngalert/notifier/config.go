@@ -73,6 +73,11 @@ func PersistTemplates(cfg *api.PostableUserConfig, path string) ([]string, bool,
 	return paths, templatesChanged, nil
 }
 
+// LoadDefault returns the configuration the Alertmanager of an organization starts with.
+func LoadDefault() (*api.PostableUserConfig, error) {
+	return Load([]byte(alertmanagerDefaultConfiguration))
+}
+
 func Load(rawConfig []byte) (*api.PostableUserConfig, error) {
 	cfg := &api.PostableUserConfig{}
 
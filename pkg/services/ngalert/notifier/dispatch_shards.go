@@ -0,0 +1,126 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+)
+
+// errDispatchShardsStopped is an error for a flush sent to the dispatch shards of a stopped Alertmanager.
+var errDispatchShardsStopped = errors.New("the dispatch shards are stopped")
+
+// dispatchShardQueueSize is the number of flushes a shard accepts before the flushes of its groups wait.
+const dispatchShardQueueSize = 1024
+
+// dispatchShards runs the flushes of the alert groups on a fixed number of workers, chosen by a hash of the group key.
+// The flushes of a shard run one at a time, so that a flush of a very large group only delays the groups of its shard
+// instead of all the notifications of the organization.
+type dispatchShards struct {
+	am     *Alertmanager
+	shards []chan *dispatchFlush
+}
+
+// dispatchFlush is a flush of an alert group waiting for its shard.
+type dispatchFlush struct {
+	ctx    context.Context
+	logger log.Logger
+	alerts []*types.Alert
+	stage  notify.Stage
+	done   chan dispatchFlushResult
+}
+
+type dispatchFlushResult struct {
+	ctx    context.Context
+	alerts []*types.Alert
+	err    error
+}
+
+// newDispatchShards starts the workers of the shards, they stop with the Alertmanager.
+func newDispatchShards(am *Alertmanager, n int) *dispatchShards {
+	d := &dispatchShards{am: am, shards: make([]chan *dispatchFlush, n)}
+	for i := range d.shards {
+		d.shards[i] = make(chan *dispatchFlush, dispatchShardQueueSize)
+		am.wg.Add(1)
+		go d.run(i)
+	}
+	return d
+}
+
+// shardFor returns the shard of the alert group.
+func (d *dispatchShards) shardFor(groupKey string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(groupKey))
+	return int(h.Sum32() % uint32(len(d.shards)))
+}
+
+func (d *dispatchShards) run(shard int) {
+	defer d.am.wg.Done()
+	org, label := fmt.Sprint(d.am.orgID), fmt.Sprint(shard)
+	queued := d.am.Metrics.DispatchShardQueued.WithLabelValues(org, label)
+	duration := d.am.Metrics.DispatchShardFlushDuration.WithLabelValues(org, label)
+	for {
+		select {
+		case <-d.am.stopc:
+			return
+		case f := <-d.shards[shard]:
+			queued.Dec()
+			// The flush timed out or the dispatcher was stopped while waiting, the group is flushed again later.
+			if err := f.ctx.Err(); err != nil {
+				f.done <- dispatchFlushResult{ctx: f.ctx, err: err}
+				continue
+			}
+			start := time.Now()
+			ctx, alerts, err := f.stage.Exec(f.ctx, f.logger, f.alerts...)
+			duration.Observe(time.Since(start).Seconds())
+			f.done <- dispatchFlushResult{ctx: ctx, alerts: alerts, err: err}
+		}
+	}
+}
+
+// exec sends the flush to the shard of its group and waits for it.
+func (d *dispatchShards) exec(ctx context.Context, l log.Logger, stage notify.Stage, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+	groupKey, _ := notify.GroupKey(ctx)
+	shard := d.shardFor(groupKey)
+	f := &dispatchFlush{
+		ctx:    ctx,
+		logger: l,
+		alerts: alerts,
+		stage:  stage,
+		done:   make(chan dispatchFlushResult, 1),
+	}
+
+	queued := d.am.Metrics.DispatchShardQueued.WithLabelValues(fmt.Sprint(d.am.orgID), fmt.Sprint(shard))
+	queued.Inc()
+	select {
+	case d.shards[shard] <- f:
+	case <-ctx.Done():
+		queued.Dec()
+		return ctx, nil, ctx.Err()
+	case <-d.am.stopc:
+		queued.Dec()
+		return ctx, nil, errDispatchShardsStopped
+	}
+
+	select {
+	case r := <-f.done:
+		return r.ctx, r.alerts, r.err
+	case <-d.am.stopc:
+		return ctx, nil, errDispatchShardsStopped
+	}
+}
+
+// shardedStage runs the stage of the flushes on the dispatch shards.
+type shardedStage struct {
+	shards *dispatchShards
+	stage  notify.Stage
+}
+
+func (s *shardedStage) Exec(ctx context.Context, l log.Logger, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+	return s.shards.exec(ctx, l, s.stage, alerts...)
+}
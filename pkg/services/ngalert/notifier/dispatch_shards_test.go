@@ -0,0 +1,111 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+)
+
+type blockingStage struct {
+	started chan string
+	release chan struct{}
+}
+
+func (s *blockingStage) Exec(ctx context.Context, _ log.Logger, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+	groupKey, _ := notify.GroupKey(ctx)
+	s.started <- groupKey
+	if groupKey == "large" {
+		<-s.release
+	}
+	return ctx, alerts, nil
+}
+
+func TestDispatchShards(t *testing.T) {
+	am := &Alertmanager{orgID: 1, stopc: make(chan struct{}), Metrics: metrics.NewMetrics(prometheus.NewRegistry())}
+	shards := newDispatchShards(am, 2)
+	t.Cleanup(func() {
+		close(am.stopc)
+		am.wg.Wait()
+	})
+
+	// Finds a group of the other shard than the large group, and one of the same shard.
+	other, same := "", ""
+	for i := 0; other == "" || same == ""; i++ {
+		key := fmt.Sprintf("group-%d", i)
+		if shards.shardFor(key) == shards.shardFor("large") {
+			same = key
+		} else {
+			other = key
+		}
+	}
+
+	s := &blockingStage{started: make(chan string, 3), release: make(chan struct{})}
+	stage := &shardedStage{shards: shards, stage: s}
+	alert := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "HighLatency"}}}
+	flush := func(groupKey string) chan error {
+		errc := make(chan error, 1)
+		go func() {
+			_, alerts, err := stage.Exec(notify.WithGroupKey(context.Background(), groupKey), log.NewNopLogger(), alert)
+			if err == nil && len(alerts) != 1 {
+				err = fmt.Errorf("expected 1 alert, got %d", len(alerts))
+			}
+			errc <- err
+		}()
+		return errc
+	}
+
+	large := flush("large")
+	require.Equal(t, "large", <-s.started)
+	sameShard := flush(same)
+	otherShard := flush(other)
+
+	// The flush of the other shard isn't delayed by the large group.
+	require.Equal(t, other, <-s.started)
+	require.NoError(t, <-otherShard)
+
+	// The flush of the same shard waits for the large group.
+	require.Eventually(t, func() bool {
+		queued := am.Metrics.DispatchShardQueued.WithLabelValues("1", fmt.Sprint(shards.shardFor(same)))
+		return testutil.ToFloat64(queued) == 1
+	}, time.Second, 10*time.Millisecond)
+	select {
+	case key := <-s.started:
+		t.Fatalf("flush of %s started before the large group was flushed", key)
+	default:
+	}
+
+	close(s.release)
+	require.NoError(t, <-large)
+	require.Equal(t, same, <-s.started)
+	require.NoError(t, <-sameShard)
+	require.Equal(t, 2, testutil.CollectAndCount(am.Metrics.DispatchShardFlushDuration))
+}
+
+func TestDispatchShards_ExpiredFlush(t *testing.T) {
+	am := &Alertmanager{orgID: 1, stopc: make(chan struct{}), Metrics: metrics.NewMetrics(prometheus.NewRegistry())}
+	shards := newDispatchShards(am, 1)
+	t.Cleanup(func() {
+		close(am.stopc)
+		am.wg.Wait()
+	})
+
+	s := &blockingStage{started: make(chan string, 1), release: make(chan struct{})}
+	stage := &shardedStage{shards: shards, stage: s}
+	ctx, cancel := context.WithCancel(notify.WithGroupKey(context.Background(), "group"))
+	cancel()
+
+	_, _, err := stage.Exec(ctx, log.NewNopLogger())
+	require.ErrorIs(t, err, context.Canceled)
+	require.Empty(t, s.started)
+}
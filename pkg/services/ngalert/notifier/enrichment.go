@@ -0,0 +1,234 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	gokit_log "github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	defaultEnrichmentTimeout  = 2 * time.Second
+	defaultEnrichmentCacheTTL = 5 * time.Minute
+	// maxEnrichmentConcurrency is the maximum number of concurrent requests to an enrichment service by notification.
+	maxEnrichmentConcurrency = 8
+	// maxEnrichmentResponseSize is the maximum size of the response of an enrichment service.
+	maxEnrichmentResponseSize = 1 << 20
+	// enrichmentClientTimeout bounds the requests to the enrichment services, whatever the timeout of the enrichment.
+	enrichmentClientTimeout = 30 * time.Second
+)
+
+// enrichmentClient is the client of the enrichment services, whose redirects are checked like the URL.
+var enrichmentClient = &http.Client{
+	Timeout: enrichmentClientTimeout,
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return checkEnrichmentURL(req.URL)
+	},
+}
+
+// checkEnrichmentURL fails if the host of the URL is not in the whitelist of the data source proxy when there is one,
+// as the configuration can be older than the whitelist.
+func checkEnrichmentURL(u *url.URL) error {
+	if len(setting.DataProxyWhiteList) > 0 && !setting.DataProxyWhiteList[u.Host] {
+		return fmt.Errorf("the host of the enrichment URL is not included in the data source proxy whitelist: %s", u.Host)
+	}
+	return nil
+}
+
+// enrichmentRequest is the body posted to an enrichment service for an alert.
+type enrichmentRequest struct {
+	Labels model.LabelSet `json:"labels"`
+}
+
+// enrichmentResponse is the body returned by an enrichment service.
+type enrichmentResponse struct {
+	Annotations model.LabelSet `json:"annotations"`
+}
+
+type enrichmentCacheEntry struct {
+	annotations model.LabelSet
+	expiresAt   time.Time
+}
+
+// enrichments caches the annotations returned by the enrichment services, by URL and by labels of the alerts.
+type enrichments struct {
+	mtx       sync.Mutex
+	cache     map[string]enrichmentCacheEntry
+	lastPrune time.Time
+}
+
+func newEnrichments() *enrichments {
+	return &enrichments{cache: map[string]enrichmentCacheEntry{}}
+}
+
+func enrichmentCacheKey(url string, a *types.Alert) string {
+	return url + "/" + a.Fingerprint().String()
+}
+
+func (e *enrichments) get(key string, now time.Time) (model.LabelSet, bool) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	entry, ok := e.cache[key]
+	if !ok || now.After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.annotations, true
+}
+
+func (e *enrichments) set(key string, annotations model.LabelSet, expiresAt time.Time) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	e.cache[key] = enrichmentCacheEntry{annotations: annotations, expiresAt: expiresAt}
+}
+
+// prune removes the expired entries, at most once per default cache TTL.
+func (e *enrichments) prune(now time.Time) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	if now.Sub(e.lastPrune) < defaultEnrichmentCacheTTL {
+		return
+	}
+	e.lastPrune = now
+	for key, entry := range e.cache {
+		if now.After(entry.expiresAt) {
+			delete(e.cache, key)
+		}
+	}
+}
+
+// enrichmentStage merges the annotations returned by an enrichment service into the alerts notified to a receiver.
+// The alerts are notified without the annotations of the service when it fails or doesn't answer in time.
+type enrichmentStage struct {
+	enrichments *enrichments
+	config      *apimodels.EnrichmentConfig
+	receiver    string
+	logger      log.Logger
+	metrics     *metrics.Metrics
+}
+
+func (s *enrichmentStage) Exec(ctx context.Context, _ gokit_log.Logger, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+	timeout, ttl := defaultEnrichmentTimeout, defaultEnrichmentCacheTTL
+	if s.config.Timeout > 0 {
+		timeout = time.Duration(s.config.Timeout)
+	}
+	if s.config.CacheTTL > 0 {
+		ttl = time.Duration(s.config.CacheTTL)
+	}
+	lookupCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	now := time.Now()
+	s.enrichments.prune(now)
+
+	annotations := make([]model.LabelSet, len(alerts))
+	sem := make(chan struct{}, maxEnrichmentConcurrency)
+	var wg sync.WaitGroup
+	for i, a := range alerts {
+		key := enrichmentCacheKey(s.config.URL, a)
+		if cached, ok := s.enrichments.get(key, now); ok {
+			s.metrics.AlertEnrichments.WithLabelValues("cached").Inc()
+			annotations[i] = cached
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, a *types.Alert, key string) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-lookupCtx.Done():
+				s.metrics.AlertEnrichments.WithLabelValues("failed").Inc()
+				return
+			}
+			res, err := s.fetch(lookupCtx, a)
+			if err != nil {
+				s.metrics.AlertEnrichments.WithLabelValues("failed").Inc()
+				s.logger.Warn("failed to enrich alert", "receiver", s.receiver, "url", s.config.URL, "alert", a.Name(), "err", err)
+				return
+			}
+			s.metrics.AlertEnrichments.WithLabelValues("fetched").Inc()
+			s.enrichments.set(key, res, now.Add(ttl))
+			annotations[i] = res
+		}(i, a, key)
+	}
+	wg.Wait()
+
+	res := make([]*types.Alert, 0, len(alerts))
+	for i, a := range alerts {
+		if len(annotations[i]) == 0 {
+			res = append(res, a)
+			continue
+		}
+		// The alerts are shared by the receivers of the alert group, the enriched alert is a copy.
+		enriched := *a
+		enriched.Annotations = a.Annotations.Clone()
+		if enriched.Annotations == nil {
+			enriched.Annotations = model.LabelSet{}
+		}
+		for k, v := range annotations[i] {
+			if _, ok := enriched.Annotations[k]; !ok {
+				enriched.Annotations[k] = v
+			}
+		}
+		res = append(res, &enriched)
+	}
+	return ctx, res, nil
+}
+
+func (s *enrichmentStage) fetch(ctx context.Context, a *types.Alert) (model.LabelSet, error) {
+	body, err := json.Marshal(enrichmentRequest{Labels: a.Labels})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if err := checkEnrichmentURL(req.URL); err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := enrichmentClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxEnrichmentResponseSize))
+	if err != nil {
+		return nil, err
+	}
+	var r enrichmentResponse
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	if r.Annotations == nil {
+		r.Annotations = model.LabelSet{}
+	}
+	return r.Annotations, nil
+}
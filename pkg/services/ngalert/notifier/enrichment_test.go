@@ -0,0 +1,135 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestEnrichmentStage(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		var req enrichmentRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		switch req.Labels["service"] {
+		case "db":
+			_, _ = w.Write([]byte(`{"annotations": {"owner": "storage", "runbook_url": "https://example.com/db", "summary": "from service"}}`))
+		case "slow":
+			time.Sleep(time.Second)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	stage := &enrichmentStage{
+		enrichments: newEnrichments(),
+		config:      &apimodels.EnrichmentConfig{URL: server.URL, Timeout: model.Duration(100 * time.Millisecond)},
+		receiver:    "team",
+		logger:      log.New("enrichment-test"),
+		metrics:     metrics.NewMetrics(prometheus.NewRegistry()),
+	}
+
+	db := &types.Alert{Alert: model.Alert{
+		Labels:      model.LabelSet{"alertname": "test", "service": "db"},
+		Annotations: model.LabelSet{"summary": "from rule"},
+	}}
+	unknown := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "test", "service": "web"}}}
+
+	_, res, err := stage.Exec(context.Background(), nil, db, unknown)
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+	require.Equal(t, model.LabelSet{"summary": "from rule", "owner": "storage", "runbook_url": "https://example.com/db"}, res[0].Annotations)
+	require.Equal(t, model.LabelSet{"summary": "from rule"}, db.Annotations, "the notified alert should not be modified")
+	require.Same(t, unknown, res[1], "the alert should be notified as is when the service fails")
+	require.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	t.Run("annotations are cached", func(t *testing.T) {
+		_, res, err := stage.Exec(context.Background(), nil, db)
+		require.NoError(t, err)
+		require.Equal(t, model.LabelValue("storage"), res[0].Annotations["owner"])
+		require.Equal(t, int32(2), atomic.LoadInt32(&requests))
+	})
+
+	t.Run("a slow service doesn't block the notification", func(t *testing.T) {
+		slow := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "test", "service": "slow"}}}
+		start := time.Now()
+		_, res, err := stage.Exec(context.Background(), nil, slow)
+		require.NoError(t, err)
+		require.Less(t, time.Since(start), 500*time.Millisecond)
+		require.Same(t, slow, res[0])
+	})
+}
+
+func TestEnrichmentStage_DataProxyWhiteList(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		_, _ = w.Write([]byte(`{"annotations": {"owner": "storage"}}`))
+	}))
+	defer server.Close()
+	redirect := httptest.NewServer(http.RedirectHandler(server.URL, http.StatusTemporaryRedirect))
+	defer redirect.Close()
+
+	newStage := func(url string) *enrichmentStage {
+		return &enrichmentStage{
+			enrichments: newEnrichments(),
+			config:      &apimodels.EnrichmentConfig{URL: url},
+			receiver:    "team",
+			logger:      log.New("enrichment-test"),
+			metrics:     metrics.NewMetrics(prometheus.NewRegistry()),
+		}
+	}
+	alert := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "test"}}}
+
+	t.Run("the URL is rejected when its host is not in the whitelist", func(t *testing.T) {
+		t.Cleanup(func() { setting.DataProxyWhiteList = nil })
+		setting.DataProxyWhiteList = map[string]bool{"enrichment:8080": true}
+
+		_, res, err := newStage(server.URL).Exec(context.Background(), nil, alert)
+		require.NoError(t, err)
+		require.Same(t, alert, res[0])
+		require.Equal(t, int32(0), atomic.LoadInt32(&requests))
+	})
+
+	t.Run("the redirects are rejected when their host is not in the whitelist", func(t *testing.T) {
+		t.Cleanup(func() { setting.DataProxyWhiteList = nil })
+		setting.DataProxyWhiteList = map[string]bool{redirect.Listener.Addr().String(): true}
+
+		_, res, err := newStage(redirect.URL).Exec(context.Background(), nil, alert)
+		require.NoError(t, err)
+		require.Same(t, alert, res[0])
+		require.Equal(t, int32(0), atomic.LoadInt32(&requests))
+	})
+
+	t.Run("the URL is allowed when its host is in the whitelist", func(t *testing.T) {
+		t.Cleanup(func() { setting.DataProxyWhiteList = nil })
+		setting.DataProxyWhiteList = map[string]bool{server.Listener.Addr().String(): true}
+
+		_, res, err := newStage(server.URL).Exec(context.Background(), nil, alert)
+		require.NoError(t, err)
+		require.Equal(t, model.LabelValue("storage"), res[0].Annotations["owner"])
+		require.Equal(t, int32(1), atomic.LoadInt32(&requests))
+	})
+}
+
+func TestEnrichmentConfig_AppliesTo(t *testing.T) {
+	require.True(t, (&apimodels.EnrichmentConfig{}).AppliesTo("team"))
+	require.True(t, (&apimodels.EnrichmentConfig{Receivers: []string{"other", "team"}}).AppliesTo("team"))
+	require.False(t, (&apimodels.EnrichmentConfig{Receivers: []string{"other"}}).AppliesTo("team"))
+}
@@ -0,0 +1,253 @@
+package notifier
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	gokit_log "github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+var (
+	ErrEscalationNotFound = errors.New("escalation not found")
+)
+
+// Escalation is the escalation state of an alert group notified to a receiver with an escalation chain.
+// Escalations are kept in memory and start over when Grafana restarts.
+type Escalation struct {
+	ID       string
+	GroupKey string
+	Receiver string
+	// GroupLabels are the labels the alert group is grouped by.
+	GroupLabels model.LabelSet
+	// StartedAt is when the alert group was first notified.
+	StartedAt time.Time
+	// Step is the number of escalation steps already notified.
+	Step int
+	// LastEscalatedAt is when the last escalation step was notified.
+	LastEscalatedAt time.Time
+
+	AcknowledgedBy string
+	AcknowledgedAt time.Time
+
+	alerts map[model.Fingerprint]*types.Alert
+}
+
+// Alerts returns the number of firing alerts of the group.
+func (e *Escalation) Alerts() int {
+	return len(e.alerts)
+}
+
+type escalations struct {
+	mtx sync.Mutex
+	// chains by receiver name, from the current configuration.
+	chains map[string]*apimodels.EscalationChain
+	// escalations by ID.
+	active map[string]*Escalation
+}
+
+func newEscalations() *escalations {
+	return &escalations{
+		chains: map[string]*apimodels.EscalationChain{},
+		active: map[string]*Escalation{},
+	}
+}
+
+func (e *escalations) setChains(chains []*apimodels.EscalationChain) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	e.chains = make(map[string]*apimodels.EscalationChain, len(chains))
+	for _, c := range chains {
+		e.chains[c.Receiver] = c
+	}
+}
+
+func (e *escalations) hasChain(receiver string) bool {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+	_, ok := e.chains[receiver]
+	return ok
+}
+
+func escalationID(groupKey string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(groupKey)))[:16]
+}
+
+// escalationStage keeps track of the alert groups flushed to a receiver that has an escalation chain.
+type escalationStage struct {
+	escalations *escalations
+	receiver    string
+}
+
+func (s *escalationStage) Exec(ctx context.Context, _ gokit_log.Logger, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+	groupKey, ok := notify.GroupKey(ctx)
+	if !ok {
+		return ctx, alerts, nil
+	}
+	now, ok := notify.Now(ctx)
+	if !ok {
+		now = time.Now()
+	}
+
+	firing := map[model.Fingerprint]*types.Alert{}
+	for _, a := range alerts {
+		if !a.ResolvedAt(now) {
+			firing[a.Fingerprint()] = a
+		}
+	}
+
+	id := escalationID(groupKey)
+	s.escalations.mtx.Lock()
+	defer s.escalations.mtx.Unlock()
+	if len(firing) == 0 {
+		delete(s.escalations.active, id)
+		return ctx, alerts, nil
+	}
+
+	e, ok := s.escalations.active[id]
+	if !ok {
+		groupLabels, _ := notify.GroupLabels(ctx)
+		e = &Escalation{
+			ID:          id,
+			GroupKey:    groupKey,
+			Receiver:    s.receiver,
+			GroupLabels: groupLabels,
+			StartedAt:   now,
+		}
+		s.escalations.active[id] = e
+	}
+	e.alerts = firing
+	return ctx, alerts, nil
+}
+
+// GetEscalations returns the escalations of the alert groups that are still firing.
+func (am *Alertmanager) GetEscalations() []Escalation {
+	am.escalations.mtx.Lock()
+	defer am.escalations.mtx.Unlock()
+
+	res := make([]Escalation, 0, len(am.escalations.active))
+	for _, e := range am.escalations.active {
+		res = append(res, *e)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].StartedAt.Before(res[j].StartedAt)
+	})
+	return res
+}
+
+// NextEscalationStep returns the next step of an escalation, or false if all steps were notified.
+func (am *Alertmanager) NextEscalationStep(e Escalation) (apimodels.EscalationStep, time.Time, bool) {
+	am.escalations.mtx.Lock()
+	defer am.escalations.mtx.Unlock()
+
+	chain, ok := am.escalations.chains[e.Receiver]
+	if !ok || e.Step >= len(chain.Steps) {
+		return apimodels.EscalationStep{}, time.Time{}, false
+	}
+	return chain.Steps[e.Step], nextEscalationAt(&e, chain), true
+}
+
+// AcknowledgeEscalation stops the escalation of an alert group.
+// It returns ErrEscalationNotFound if the alert group is not escalated.
+func (am *Alertmanager) AcknowledgeEscalation(id, user string) error {
+	am.escalations.mtx.Lock()
+	defer am.escalations.mtx.Unlock()
+
+	e, ok := am.escalations.active[id]
+	if !ok {
+		return ErrEscalationNotFound
+	}
+	e.AcknowledgedBy = user
+	e.AcknowledgedAt = time.Now()
+	return nil
+}
+
+func nextEscalationAt(e *Escalation, chain *apimodels.EscalationChain) time.Time {
+	from := e.StartedAt
+	if e.Step > 0 {
+		from = e.LastEscalatedAt
+	}
+	return from.Add(time.Duration(chain.Steps[e.Step].Wait))
+}
+
+// ProcessEscalations notifies the next step of the escalation chain of the alert groups
+// that have been firing and unacknowledged for long enough.
+func (am *Alertmanager) ProcessEscalations(ctx context.Context, now time.Time) error {
+	if am.InMaintenanceMode(now) {
+		return nil
+	}
+
+	type job struct {
+		escalation *Escalation
+		step       apimodels.EscalationStep
+		alerts     []*types.Alert
+	}
+
+	var jobs []job
+	am.escalations.mtx.Lock()
+	for id, e := range am.escalations.active {
+		chain, ok := am.escalations.chains[e.Receiver]
+		if !ok {
+			delete(am.escalations.active, id)
+			continue
+		}
+		if !e.AcknowledgedAt.IsZero() || e.Step >= len(chain.Steps) || now.Before(nextEscalationAt(e, chain)) {
+			continue
+		}
+
+		alerts := am.stillFiring(e, now)
+		if len(alerts) == 0 {
+			delete(am.escalations.active, id)
+			continue
+		}
+		jobs = append(jobs, job{escalation: e, step: chain.Steps[e.Step], alerts: alerts})
+		e.Step++
+		e.LastEscalatedAt = now
+	}
+	am.escalations.mtx.Unlock()
+
+	for _, j := range jobs {
+		integrations, err := am.integrationsForReceiver(j.step.Receiver)
+		if err != nil {
+			am.logger.Error("failed to escalate alert group", "escalation", j.escalation.ID, "receiver", j.step.Receiver, "err", err)
+			continue
+		}
+
+		notifyCtx := notify.WithGroupKey(ctx, j.escalation.GroupKey)
+		notifyCtx = notify.WithGroupLabels(notifyCtx, j.escalation.GroupLabels)
+		notifyCtx = notify.WithReceiverName(notifyCtx, j.step.Receiver)
+		notifyCtx = notify.WithNow(notifyCtx, now)
+		am.logger.Info("escalating alert group", "escalation", j.escalation.ID, "from", j.escalation.Receiver, "to", j.step.Receiver, "alerts", len(j.alerts))
+		for _, integration := range integrations {
+			if _, err := integration.Notify(notifyCtx, j.alerts...); err != nil {
+				am.logger.Error("failed to notify escalation", "escalation", j.escalation.ID, "integration", integration.Name(), "err", err)
+			}
+		}
+	}
+	return nil
+}
+
+// stillFiring returns the alerts of the escalation that are still firing and neither silenced nor inhibited.
+func (am *Alertmanager) stillFiring(e *Escalation, now time.Time) []*types.Alert {
+	var res []*types.Alert
+	for fp := range e.alerts {
+		a, err := am.alerts.Get(fp)
+		if err != nil || a.ResolvedAt(now) {
+			continue
+		}
+		if status := am.marker.Status(fp); len(status.SilencedBy) > 0 || len(status.InhibitedBy) > 0 {
+			continue
+		}
+		res = append(res, a)
+	}
+	return res
+}
@@ -0,0 +1,87 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	gfmodels "github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestProcessEscalations(t *testing.T) {
+	am := setupAMTest(t)
+
+	cfg, err := Load([]byte(`{
+		"alertmanager_config": {
+			"route": {"receiver": "team"},
+			"escalations": [{"receiver": "team", "steps": [{"receiver": "oncall", "wait": "10m"}, {"receiver": "manager", "wait": "20m"}]}],
+			"receivers": [
+				{"name": "team", "grafana_managed_receiver_configs": [{"uid": "", "name": "team", "type": "webhook", "settings": {"url": "http://localhost/team"}}]},
+				{"name": "oncall", "grafana_managed_receiver_configs": [{"uid": "", "name": "oncall", "type": "webhook", "settings": {"url": "http://localhost/oncall"}}]},
+				{"name": "manager", "grafana_managed_receiver_configs": [{"uid": "", "name": "manager", "type": "webhook", "settings": {"url": "http://localhost/manager"}}]}
+			]
+		}
+	}`))
+	require.NoError(t, err)
+	require.NoError(t, am.SaveAndApplyConfig(cfg))
+
+	var notified []string
+	bus.AddHandlerCtx("test", func(ctx context.Context, webhook *gfmodels.SendWebhookSync) error {
+		notified = append(notified, webhook.Url)
+		return nil
+	})
+
+	require.NoError(t, am.PutAlerts(apimodels.PostableAlerts{PostableAlerts: []models.PostableAlert{{
+		Alert:  models.Alert{Labels: models.LabelSet{"alertname": "HighLatency"}},
+		EndsAt: strfmt.DateTime(time.Now().Add(2 * time.Hour)),
+	}}}))
+	alert, err := am.alerts.Get(model.LabelSet{"alertname": "HighLatency"}.Fingerprint())
+	require.NoError(t, err)
+
+	// The alert group is flushed to the receiver with the escalation chain.
+	start := time.Now()
+	ctx := notify.WithGroupKey(context.Background(), "{}:{alertname=\"HighLatency\"}")
+	ctx = notify.WithNow(ctx, start)
+	stage := &escalationStage{escalations: am.escalations, receiver: "team"}
+	_, _, err = stage.Exec(ctx, nil, alert)
+	require.NoError(t, err)
+
+	escalations := am.GetEscalations()
+	require.Len(t, escalations, 1)
+	require.Equal(t, "team", escalations[0].Receiver)
+	require.Equal(t, 1, escalations[0].Alerts())
+
+	// Nothing to do before the wait of the first step.
+	require.NoError(t, am.ProcessEscalations(context.Background(), start.Add(5*time.Minute)))
+	require.Empty(t, notified)
+
+	require.NoError(t, am.ProcessEscalations(context.Background(), start.Add(11*time.Minute)))
+	require.Equal(t, []string{"http://localhost/oncall"}, notified)
+
+	// The wait of the second step starts from the first step.
+	require.NoError(t, am.ProcessEscalations(context.Background(), start.Add(25*time.Minute)))
+	require.Len(t, notified, 1)
+
+	// An acknowledged alert group is not escalated any further.
+	require.NoError(t, am.AcknowledgeEscalation(escalations[0].ID, "admin"))
+	require.NoError(t, am.ProcessEscalations(context.Background(), start.Add(time.Hour)))
+	require.Len(t, notified, 1)
+	require.Equal(t, "admin", am.GetEscalations()[0].AcknowledgedBy)
+
+	require.ErrorIs(t, am.AcknowledgeEscalation("unknown", "admin"), ErrEscalationNotFound)
+
+	// The escalation ends with the alert group resolution.
+	resolved := *alert
+	resolved.EndsAt = start
+	_, _, err = stage.Exec(ctx, nil, &resolved)
+	require.NoError(t, err)
+	require.Empty(t, am.GetEscalations())
+}
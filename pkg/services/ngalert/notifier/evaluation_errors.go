@@ -0,0 +1,137 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// maxSummarizedEvaluationErrors is the maximum number of failing rules listed in a summary.
+const maxSummarizedEvaluationErrors = 10
+
+// evaluationErrors batches the alerts firing because their rule failed to evaluate, so that they're summarized by at
+// most one notification per error notification window of the organization instead of one notification by rule.
+type evaluationErrors struct {
+	mtx sync.Mutex
+	// failing are the errors of the rules failing to evaluate since the last summary, by rule.
+	failing map[string]failingRule
+	// lastSummaryAt is when the last summary was sent.
+	lastSummaryAt time.Time
+}
+
+type failingRule struct {
+	name string
+	err  string
+}
+
+func newEvaluationErrors() *evaluationErrors {
+	return &evaluationErrors{failing: map[string]failingRule{}}
+}
+
+// isEvaluationError returns whether the alert is firing because its rule failed to evaluate.
+func isEvaluationError(alert *types.Alert) bool {
+	_, ok := alert.Annotations[ngmodels.EvaluationErrorAnnotation]
+	return ok
+}
+
+// record batches the alerts, and returns the summary of the failing rules if none was sent in the window.
+func (e *evaluationErrors) record(alerts []*types.Alert, window time.Duration, now time.Time) *types.Alert {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	for _, a := range alerts {
+		if a.ResolvedAt(now) {
+			continue
+		}
+		key := string(a.Labels[model.LabelName(ngmodels.RuleUIDLabel)])
+		if key == "" {
+			key = a.Fingerprint().String()
+		}
+		e.failing[key] = failingRule{
+			name: string(a.Labels[model.AlertNameLabel]),
+			err:  string(a.Annotations[ngmodels.EvaluationErrorAnnotation]),
+		}
+	}
+	if len(e.failing) == 0 || now.Sub(e.lastSummaryAt) < window {
+		return nil
+	}
+
+	summary := evaluationErrorsAlert(e.failing, window, now)
+	e.failing = map[string]failingRule{}
+	e.lastSummaryAt = now
+	return summary
+}
+
+func evaluationErrorsAlert(failing map[string]failingRule, window time.Duration, now time.Time) *types.Alert {
+	rules := make([]failingRule, 0, len(failing))
+	for _, r := range failing {
+		rules = append(rules, r)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].name < rules[j].name })
+
+	lines := make([]string, 0, maxSummarizedEvaluationErrors+1)
+	for i, r := range rules {
+		if i == maxSummarizedEvaluationErrors {
+			lines = append(lines, fmt.Sprintf("and %d more", len(rules)-i))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", r.name, r.err))
+	}
+	return &types.Alert{
+		Alert: model.Alert{
+			Labels: model.LabelSet{
+				model.AlertNameLabel: "RulesFailingToEvaluate",
+				"failing_rules":      model.LabelValue(fmt.Sprint(len(rules))),
+			},
+			Annotations: model.LabelSet{
+				"summary": model.LabelValue(fmt.Sprintf("%d alert rules are failing to evaluate", len(rules))),
+				"description": model.LabelValue(fmt.Sprintf("%s\nThe notifications of the rules failing to evaluate are summarized at most every %s.",
+					strings.Join(lines, "\n"), window)),
+			},
+			StartsAt: now,
+			EndsAt:   now.Add(window),
+		},
+		UpdatedAt: now,
+	}
+}
+
+// evaluationErrorStage removes the alerts firing because their rule failed to evaluate from the notifications when
+// the organization has an error notification window, and adds the summary of the failing rules when one is due.
+type evaluationErrorStage struct {
+	am *Alertmanager
+}
+
+func (s *evaluationErrorStage) Exec(ctx context.Context, _ log.Logger, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+	window := s.am.Settings.AlertingLimitsForOrg(s.am.orgID).ErrorNotificationWindow
+	if window <= 0 {
+		return ctx, alerts, nil
+	}
+
+	var errors, others []*types.Alert
+	for _, a := range alerts {
+		if isEvaluationError(a) {
+			errors = append(errors, a)
+		} else {
+			others = append(others, a)
+		}
+	}
+	if len(errors) == 0 {
+		return ctx, alerts, nil
+	}
+
+	s.am.Metrics.EvaluationErrorAlertsBatched.Add(float64(len(errors)))
+	if summary := s.am.evaluationErrors.record(errors, window, time.Now()); summary != nil {
+		s.am.logger.Debug("summarizing the rules failing to evaluate", "failing_rules", summary.Labels["failing_rules"])
+		others = append(others, summary)
+	}
+	return ctx, others, nil
+}
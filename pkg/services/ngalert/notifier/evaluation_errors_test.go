@@ -0,0 +1,71 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func evaluationErrorAlert(ruleUID, name string, now time.Time) *types.Alert {
+	return &types.Alert{Alert: model.Alert{
+		Labels:      model.LabelSet{model.AlertNameLabel: model.LabelValue(name), ngmodels.RuleUIDLabel: model.LabelValue(ruleUID)},
+		Annotations: model.LabelSet{ngmodels.EvaluationErrorAnnotation: "datasource unavailable"},
+		StartsAt:    now,
+		EndsAt:      now.Add(time.Hour),
+	}}
+}
+
+func TestEvaluationErrors(t *testing.T) {
+	e := newEvaluationErrors()
+	now := time.Date(2021, 10, 14, 10, 15, 0, 0, time.UTC)
+
+	summary := e.record([]*types.Alert{evaluationErrorAlert("a", "HighLatency", now), evaluationErrorAlert("b", "DiskFull", now)}, time.Hour, now)
+	require.NotNil(t, summary)
+	require.Equal(t, model.LabelValue("2"), summary.Labels["failing_rules"])
+	require.Equal(t, model.LabelValue("2 alert rules are failing to evaluate"), summary.Annotations["summary"])
+	require.Contains(t, summary.Annotations["description"], "DiskFull: datasource unavailable\nHighLatency: datasource unavailable")
+
+	// The errors are batched until the end of the window.
+	require.Nil(t, e.record([]*types.Alert{evaluationErrorAlert("a", "HighLatency", now)}, time.Hour, now.Add(30*time.Minute)))
+	require.Nil(t, e.record([]*types.Alert{evaluationErrorAlert("c", "HighCPU", now)}, time.Hour, now.Add(45*time.Minute)))
+	summary = e.record(nil, time.Hour, now.Add(time.Hour))
+	require.NotNil(t, summary)
+	require.Equal(t, model.LabelValue("2"), summary.Labels["failing_rules"])
+
+	// The resolved alerts aren't summarized.
+	require.Nil(t, e.record([]*types.Alert{evaluationErrorAlert("a", "HighLatency", now)}, time.Hour, now.Add(3*time.Hour)))
+}
+
+func TestEvaluationErrorStage(t *testing.T) {
+	am := setupAMTest(t)
+	now := time.Now()
+	firing := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{model.AlertNameLabel: "HighLatency"}, StartsAt: now, EndsAt: now.Add(time.Hour)}}
+	alerts := []*types.Alert{firing, evaluationErrorAlert("a", "DiskFull", now), evaluationErrorAlert("b", "HighCPU", now)}
+	stage := &evaluationErrorStage{am: am}
+
+	// Without an error notification window, the alerts are notified.
+	_, result, err := stage.Exec(context.Background(), log.NewNopLogger(), alerts...)
+	require.NoError(t, err)
+	require.Equal(t, alerts, result)
+
+	am.Settings.AlertingOrgLimits = setting.AlertingOrgLimits{ErrorNotificationWindow: time.Hour}
+	_, result, err = stage.Exec(context.Background(), log.NewNopLogger(), alerts...)
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	require.Equal(t, firing, result[0])
+	require.Equal(t, model.LabelValue("RulesFailingToEvaluate"), result[1].Labels[model.AlertNameLabel])
+	require.Equal(t, 2.0, testutil.ToFloat64(am.Metrics.EvaluationErrorAlertsBatched))
+
+	_, result, err = stage.Exec(context.Background(), log.NewNopLogger(), alerts...)
+	require.NoError(t, err)
+	require.Equal(t, []*types.Alert{firing}, result, "a single summary is sent in the window")
+}
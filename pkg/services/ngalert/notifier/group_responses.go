@@ -0,0 +1,116 @@
+package notifier
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels"
+)
+
+// groupResponses keeps the responses of the webhooks to the notifications of the alert groups, by integration and
+// group key. They are kept in memory and start over when Grafana restarts.
+type groupResponses struct {
+	mtx       sync.Mutex
+	responses map[string]*groupResponse
+	// lastGC is when the responses not updated for the retention of the notification log were last removed.
+	lastGC time.Time
+}
+
+type groupResponse struct {
+	response  map[string]interface{}
+	updatedAt time.Time
+}
+
+func newGroupResponses() *groupResponses {
+	return &groupResponses{responses: map[string]*groupResponse{}}
+}
+
+// get returns a copy of the response of the group, nil if there is none.
+func (g *groupResponses) get(key string) map[string]interface{} {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	r, ok := g.responses[key]
+	if !ok {
+		return nil
+	}
+	res := make(map[string]interface{}, len(r.response))
+	for k, v := range r.response {
+		res[k] = v
+	}
+	return res
+}
+
+// merge merges the response into the response of the group, and removes the responses of the groups not notified
+// since before expiredBefore, at most once an hour.
+func (g *groupResponses) merge(key string, response map[string]interface{}, now, expiredBefore time.Time) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	r, ok := g.responses[key]
+	if !ok {
+		r = &groupResponse{response: make(map[string]interface{}, len(response))}
+		g.responses[key] = r
+	}
+	for k, v := range response {
+		r.response[k] = v
+	}
+	r.updatedAt = now
+
+	if now.Sub(g.lastGC) < time.Hour {
+		return
+	}
+	g.lastGC = now
+	for k, r := range g.responses {
+		if r.updatedAt.Before(expiredBefore) {
+			delete(g.responses, k)
+		}
+	}
+}
+
+func (g *groupResponses) delete(key string) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+	delete(g.responses, key)
+}
+
+// groupResponseOf is the channels.GroupResponse of an alert group.
+type groupResponseOf struct {
+	am  *Alertmanager
+	key string
+}
+
+func (r groupResponseOf) Get() map[string]interface{} {
+	return r.am.groupResponses.get(r.key)
+}
+
+func (r groupResponseOf) Merge(response map[string]interface{}) {
+	now := time.Now()
+	r.am.groupResponses.merge(r.key, response, now, now.Add(-r.am.notificationLogRetention()))
+}
+
+// groupResponseNotifier lets the webhook integration include its responses to the previous notifications of the
+// alert group in the next ones. The responses of a group are removed once it is resolved, so that the group
+// firing again starts over.
+type groupResponseNotifier struct {
+	NotificationChannel
+	am *Alertmanager
+	// integration identifies the integration among the integrations of every receiver.
+	integration string
+}
+
+func (n *groupResponseNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	groupKey, ok := notify.GroupKey(ctx)
+	if !ok {
+		return n.NotificationChannel.Notify(ctx, as...)
+	}
+	key := n.integration + "/" + groupKey
+	retry, err := n.NotificationChannel.Notify(channels.WithGroupResponse(ctx, groupResponseOf{am: n.am, key: key}), as...)
+	if err == nil && types.Alerts(as...).Status() == model.AlertResolved {
+		n.am.groupResponses.delete(key)
+	}
+	return retry, err
+}
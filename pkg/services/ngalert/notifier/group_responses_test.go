@@ -0,0 +1,73 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	gfmodels "github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestGroupResponseNotifier(t *testing.T) {
+	am := setupAMTest(t)
+	require.NoError(t, am.SaveAndApplyDefaultConfig())
+	t.Cleanup(bus.ClearBusHandlers)
+
+	var sent []map[string]interface{}
+	responses := []string{`{"ticketId": "T-1", "status": "open"}`, `{"status": "updated"}`, `not json`, `{"status": "closed"}`, `{}`, `{}`}
+	bus.AddHandlerCtx("test", func(ctx context.Context, webhook *gfmodels.SendWebhookSync) error {
+		var body struct {
+			Response map[string]interface{} `json:"response"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(webhook.Body), &body))
+		sent = append(sent, body.Response)
+		webhook.ResponseBody([]byte(responses[len(sent)-1]))
+		return nil
+	})
+
+	settings := simplejson.New()
+	settings.Set("url", "http://localhost/tickets")
+	receiver := &apimodels.PostableApiReceiver{PostableGrafanaReceivers: apimodels.PostableGrafanaReceivers{
+		GrafanaManagedReceivers: []*apimodels.PostableGrafanaReceiver{{UID: "uid", Name: "tickets", Type: "webhook", Settings: settings}},
+	}}
+	receiver.Name = "tickets"
+	tmpl, err := am.getTemplate()
+	require.NoError(t, err)
+	integrations, err := am.buildReceiverIntegrations(receiver, tmpl)
+	require.NoError(t, err)
+	n := integrations[0]
+
+	now := time.Now()
+	firing := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "test"}, StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)}}
+	resolved := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "test"}, StartsAt: now.Add(-time.Hour), EndsAt: now.Add(-time.Minute)}}
+	ctx := notify.WithGroupKey(context.Background(), `{}:{alertname="test"}`)
+	ctx = notify.WithReceiverName(ctx, "tickets")
+	for _, a := range []*types.Alert{firing, firing, firing, resolved, firing} {
+		_, err := n.Notify(ctx, a)
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, []map[string]interface{}{
+		nil,
+		{"ticketId": "T-1", "status": "open"},
+		{"ticketId": "T-1", "status": "updated"},
+		// The responses that aren't JSON objects are ignored.
+		{"ticketId": "T-1", "status": "updated"},
+		// The group firing again after it was resolved starts over.
+		nil,
+	}, sent)
+
+	// The other groups have their own responses.
+	_, err = n.Notify(notify.WithGroupKey(ctx, `{}:{alertname="other"}`), firing)
+	require.NoError(t, err)
+	require.Nil(t, sent[len(sent)-1])
+}
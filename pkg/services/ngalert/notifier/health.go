@@ -0,0 +1,97 @@
+package notifier
+
+import (
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// AlertmanagerHealth is the health of the Alertmanager of an organization, for the administrators of the server.
+type AlertmanagerHealth struct {
+	Ready bool
+	// ConfigHash is the hash of the configuration applied, empty if none was.
+	ConfigHash string
+	// LastSyncAt is when the configuration was last synced from the database, and SyncError the error of the sync.
+	LastSyncAt time.Time
+	SyncError  string
+	// NotificationAttempts and NotificationFailures count the notifications sent by the integrations since the start.
+	NotificationAttempts    int64
+	NotificationFailures    int64
+	LastNotificationError   string
+	LastNotificationErrorAt time.Time
+}
+
+// FailureRate returns the part of the notifications that failed, 0 without notifications.
+func (h AlertmanagerHealth) FailureRate() float64 {
+	if h.NotificationAttempts == 0 {
+		return 0
+	}
+	return float64(h.NotificationFailures) / float64(h.NotificationAttempts)
+}
+
+// alertmanagerHealth records the syncs and notifications of an Alertmanager.
+type alertmanagerHealth struct {
+	mtx                     sync.Mutex
+	lastSyncAt              time.Time
+	syncErr                 error
+	notificationAttempts    int64
+	notificationFailures    int64
+	lastNotificationErr     error
+	lastNotificationErrorAt time.Time
+}
+
+func (h *alertmanagerHealth) recordSync(now time.Time, err error) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.lastSyncAt = now
+	h.syncErr = err
+}
+
+func (h *alertmanagerHealth) recordNotification(now time.Time, err error) {
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	h.notificationAttempts++
+	if err != nil {
+		h.notificationFailures++
+		h.lastNotificationErr = err
+		h.lastNotificationErrorAt = now
+	}
+}
+
+// Health returns the health of the Alertmanager.
+func (am *Alertmanager) Health() AlertmanagerHealth {
+	var result AlertmanagerHealth
+	am.reloadConfigMtx.RLock()
+	result.Ready = am.ready()
+	if am.configHash != [16]byte{} {
+		result.ConfigHash = hex.EncodeToString(am.configHash[:])
+	}
+	am.reloadConfigMtx.RUnlock()
+
+	h := am.health
+	h.mtx.Lock()
+	defer h.mtx.Unlock()
+	result.LastSyncAt = h.lastSyncAt
+	if h.syncErr != nil {
+		result.SyncError = h.syncErr.Error()
+	}
+	result.NotificationAttempts = h.notificationAttempts
+	result.NotificationFailures = h.notificationFailures
+	if h.lastNotificationErr != nil {
+		result.LastNotificationError = h.lastNotificationErr.Error()
+		result.LastNotificationErrorAt = h.lastNotificationErrorAt
+	}
+	return result
+}
+
+// AlertmanagersHealth returns the health of the Alertmanager of each organization.
+func (moa *MultiOrgAlertmanager) AlertmanagersHealth() map[int64]AlertmanagerHealth {
+	moa.alertmanagersMtx.RLock()
+	defer moa.alertmanagersMtx.RUnlock()
+
+	result := make(map[int64]AlertmanagerHealth, len(moa.alertmanagers))
+	for orgID, am := range moa.alertmanagers {
+		result[orgID] = am.Health()
+	}
+	return result
+}
@@ -0,0 +1,43 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertmanagerHealth(t *testing.T) {
+	am := setupAMTest(t)
+
+	h := am.Health()
+	require.False(t, h.Ready)
+	require.Empty(t, h.ConfigHash)
+	require.Zero(t, h.FailureRate())
+
+	alert := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "HighLatency"}}}
+	ctx := notify.WithReceiverName(context.Background(), "ops")
+	for i := 0; i < 3; i++ {
+		n := &instrumentedNotifier{NotificationChannel: &fakeNotificationChannel{}, am: am, integrationType: "webhook"}
+		_, err := n.Notify(ctx, alert)
+		require.NoError(t, err)
+	}
+	n := &instrumentedNotifier{NotificationChannel: &failingNotificationChannel{}, am: am, integrationType: "webhook"}
+	_, err := n.Notify(ctx, alert)
+	require.Error(t, err)
+
+	am.health.recordSync(am.health.lastNotificationErrorAt, errors.New("invalid configuration"))
+
+	h = am.Health()
+	require.Equal(t, int64(4), h.NotificationAttempts)
+	require.Equal(t, int64(1), h.NotificationFailures)
+	require.Equal(t, 0.25, h.FailureRate())
+	require.Equal(t, "unavailable", h.LastNotificationError)
+	require.False(t, h.LastNotificationErrorAt.IsZero())
+	require.Equal(t, "invalid configuration", h.SyncError)
+	require.False(t, h.LastSyncAt.IsZero())
+}
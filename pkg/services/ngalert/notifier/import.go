@@ -0,0 +1,379 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/alertmanager/config"
+	commoncfg "github.com/prometheus/common/config"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// redactedSecret is how the status API of the Alertmanager returns the secrets of its configuration.
+	redactedSecret = "<secret>"
+
+	fetchAlertmanagerConfigTimeout = 10 * time.Second
+	// maxAlertmanagerStatusSize is the maximum size of the status of an Alertmanager read by FetchAlertmanagerConfig.
+	maxAlertmanagerStatusSize = 10 << 20
+)
+
+// ErrAlertmanagerURLNotAllowed is returned by FetchAlertmanagerConfig when the host of the URL is not in the
+// whitelist of the data source proxy.
+var ErrAlertmanagerURLNotAllowed = errors.New("the host of the Alertmanager URL is not included in the data source proxy whitelist")
+
+// fetchAlertmanagerConfigClient is the client of FetchAlertmanagerConfig, whose redirects are checked like the URL.
+var fetchAlertmanagerConfigClient = &http.Client{
+	Timeout: fetchAlertmanagerConfigTimeout,
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return checkAlertmanagerURL(req.URL)
+	},
+}
+
+// checkAlertmanagerURL fails if the URL is not an HTTP URL, or if its host is not in the whitelist of the data source
+// proxy when there is one, so that the Alertmanager URLs can't reach more hosts than the data sources.
+func checkAlertmanagerURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid Alertmanager URL %q: the scheme should be http or https", u.Redacted())
+	}
+	if len(setting.DataProxyWhiteList) > 0 && !setting.DataProxyWhiteList[u.Host] {
+		return fmt.Errorf("%w: %s", ErrAlertmanagerURLNotAllowed, u.Host)
+	}
+	return nil
+}
+
+// FetchAlertmanagerConfig returns the configuration of a running external Alertmanager, read from its status API.
+// The basic auth credentials of the requests are the ones of the URL. The secrets of the configuration are redacted.
+func FetchAlertmanagerConfig(ctx context.Context, amURL string) ([]byte, error) {
+	u, err := url.Parse(amURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Alertmanager URL: %w", err)
+	}
+	if err := checkAlertmanagerURL(u); err != nil {
+		return nil, err
+	}
+
+	target := *u
+	target.User = nil
+	target.Path = path.Join("/", u.Path, "/api/v2/status")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if u.User != nil {
+		password, _ := u.User.Password()
+		req.SetBasicAuth(u.User.Username(), password)
+	}
+
+	resp, err := fetchAlertmanagerConfigClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the status of the Alertmanager: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get the status of the Alertmanager: unexpected status code %d", resp.StatusCode)
+	}
+
+	var status struct {
+		Config struct {
+			Original string `json:"original"`
+		} `json:"config"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxAlertmanagerStatusSize)).Decode(&status); err != nil {
+		return nil, fmt.Errorf("failed to decode the status of the Alertmanager: %w", err)
+	}
+	if status.Config.Original == "" {
+		return nil, errors.New("the status of the Alertmanager has no configuration")
+	}
+	return []byte(status.Config.Original), nil
+}
+
+// ImportAlertmanagerConfig converts the YAML configuration of a Prometheus Alertmanager to the configuration of the
+// embedded Alertmanager: its integrations become Grafana managed receivers, and its routes and inhibition rules are
+// kept. The content of the template files, which can't be read from the configuration, is given by name. It returns
+// the fields that are not imported, and why.
+func ImportAlertmanagerConfig(raw []byte, templateFiles map[string]string) (*apimodels.PostableUserConfig, []string, error) {
+	cfg, err := config.Load(string(raw))
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid Alertmanager configuration: %w", err)
+	}
+
+	i := &configImport{}
+	result := &apimodels.PostableUserConfig{
+		TemplateFiles: templateFiles,
+		AlertmanagerConfig: apimodels.PostableApiAlertingConfig{
+			Config: apimodels.Config{
+				Route:        cfg.Route,
+				InhibitRules: cfg.InhibitRules,
+			},
+		},
+	}
+	if len(cfg.Templates) > 0 && len(templateFiles) == 0 {
+		i.unsupported("templates", "the template files are not part of the configuration, their content must be given")
+	}
+	if len(cfg.MuteTimeIntervals) > 0 {
+		i.unsupported("mute_time_intervals", "time intervals are not supported, create mute timings instead")
+	}
+	i.importRoute("route", cfg.Route)
+
+	for _, r := range cfg.Receivers {
+		receiver := &apimodels.PostableApiReceiver{}
+		receiver.Name = r.Name
+		receiver.GrafanaManagedReceivers = i.importReceiver(r)
+		result.AlertmanagerConfig.Receivers = append(result.AlertmanagerConfig.Receivers, receiver)
+	}
+	return result, i.report, nil
+}
+
+// configImport collects the fields that are not imported.
+type configImport struct {
+	report []string
+}
+
+func (i *configImport) unsupported(field string, reason string) {
+	i.report = append(i.report, fmt.Sprintf("%s: %s", field, reason))
+}
+
+func (i *configImport) importRoute(field string, r *config.Route) {
+	if len(r.MuteTimeIntervals) > 0 {
+		i.unsupported(field+".mute_time_intervals", "time intervals are not supported, create mute timings instead")
+		r.MuteTimeIntervals = nil
+	}
+	for n, child := range r.Routes {
+		i.importRoute(fmt.Sprintf("%s.routes[%d]", field, n), child)
+	}
+}
+
+func (i *configImport) importReceiver(r *config.Receiver) []*apimodels.PostableGrafanaReceiver {
+	var receivers []*apimodels.PostableGrafanaReceiver
+	add := func(field string, integration string, sendResolved bool, httpConfig *commoncfg.HTTPClientConfig, settings map[string]interface{}, secureSettings map[string]string) {
+		if proxy := i.importHTTPConfig(field, httpConfig, integration == "webhook"); proxy != "" {
+			settings["proxyUrl"] = proxy
+		}
+		for key, value := range secureSettings {
+			if value == redactedSecret || value == "" {
+				if value == redactedSecret {
+					i.unsupported(field, fmt.Sprintf("the secret %s is redacted, set it after the import", key))
+				}
+				delete(secureSettings, key)
+			}
+		}
+		receivers = append(receivers, &apimodels.PostableGrafanaReceiver{
+			Name:                  r.Name,
+			Type:                  integration,
+			DisableResolveMessage: !sendResolved,
+			Settings:              simplejson.NewFromAny(settings),
+			SecureSettings:        secureSettings,
+		})
+	}
+	notDefault := func(field string, value string, defaultValue string, reason string) {
+		if value != defaultValue {
+			i.unsupported(field, reason)
+		}
+	}
+	receiverField := fmt.Sprintf("receivers[%s]", r.Name)
+
+	for n, c := range r.EmailConfigs {
+		field := fmt.Sprintf("%s.email_configs[%d]", receiverField, n)
+		i.unsupported(field, "the SMTP server of Grafana is used, its smarthost, sender and authentication are ignored")
+		notDefault(field+".html", c.HTML, config.DefaultEmailConfig.HTML, "the Grafana email template is used")
+		notDefault(field+".text", c.Text, config.DefaultEmailConfig.Text, "the Grafana email template is used")
+		for h := range c.Headers {
+			i.unsupported(fmt.Sprintf("%s.headers[%s]", field, h), "email headers are not supported")
+		}
+		add(field, "email", c.SendResolved(), nil, map[string]interface{}{
+			"addresses":   c.To,
+			"singleEmail": true,
+		}, nil)
+	}
+
+	for n, c := range r.SlackConfigs {
+		field := fmt.Sprintf("%s.slack_configs[%d]", receiverField, n)
+		settings := map[string]interface{}{}
+		setNotDefault(settings, "recipient", c.Channel, "")
+		setNotDefault(settings, "username", c.Username, config.DefaultSlackConfig.Username)
+		setNotDefault(settings, "icon_emoji", c.IconEmoji, config.DefaultSlackConfig.IconEmoji)
+		setNotDefault(settings, "icon_url", c.IconURL, config.DefaultSlackConfig.IconURL)
+		setNotDefault(settings, "title", c.Title, config.DefaultSlackConfig.Title)
+		setNotDefault(settings, "text", c.Text, config.DefaultSlackConfig.Text)
+		notDefault(field+".color", c.Color, config.DefaultSlackConfig.Color, "the color depends on the state of the alerts")
+		notDefault(field+".title_link", c.TitleLink, config.DefaultSlackConfig.TitleLink, "the title links to the alert rules")
+		notDefault(field+".pretext", c.Pretext, config.DefaultSlackConfig.Pretext, "pretexts are not supported")
+		notDefault(field+".footer", c.Footer, config.DefaultSlackConfig.Footer, "footers are not supported")
+		notDefault(field+".fallback", c.Fallback, config.DefaultSlackConfig.Fallback, "fallback texts are not supported")
+		notDefault(field+".callback_id", c.CallbackID, config.DefaultSlackConfig.CallbackID, "callback IDs are not supported")
+		notDefault(field+".image_url", c.ImageURL, "", "images are not supported")
+		notDefault(field+".thumb_url", c.ThumbURL, "", "thumbnails are not supported")
+		if len(c.Fields) > 0 {
+			i.unsupported(field+".fields", "fields are not supported")
+		}
+		if len(c.Actions) > 0 {
+			i.unsupported(field+".actions", "actions are not supported")
+		}
+		if c.APIURLFile != "" {
+			i.unsupported(field+".api_url_file", "the URL must be set after the import")
+		}
+		secureSettings := map[string]string{}
+		if c.APIURL != nil {
+			secureSettings["url"] = c.APIURL.String()
+			if secureSettings["url"] == "" {
+				secureSettings["url"] = redactedSecret
+			}
+		}
+		add(field, "slack", c.SendResolved(), c.HTTPConfig, settings, secureSettings)
+	}
+
+	for n, c := range r.WebhookConfigs {
+		field := fmt.Sprintf("%s.webhook_configs[%d]", receiverField, n)
+		settings := map[string]interface{}{
+			"url":        c.URL.String(),
+			"httpMethod": http.MethodPost,
+			"maxAlerts":  c.MaxAlerts,
+		}
+		secureSettings := map[string]string{}
+		if c.HTTPConfig != nil && c.HTTPConfig.BasicAuth != nil {
+			settings["username"] = c.HTTPConfig.BasicAuth.Username
+			secureSettings["password"] = string(c.HTTPConfig.BasicAuth.Password)
+		}
+		add(field, "webhook", c.SendResolved(), c.HTTPConfig, settings, secureSettings)
+	}
+
+	for n, c := range r.PagerdutyConfigs {
+		field := fmt.Sprintf("%s.pagerduty_configs[%d]", receiverField, n)
+		settings := map[string]interface{}{}
+		setNotDefault(settings, "severity", c.Severity, "")
+		setNotDefault(settings, "class", c.Class, "")
+		setNotDefault(settings, "component", c.Component, "")
+		setNotDefault(settings, "group", c.Group, "")
+		setNotDefault(settings, "summary", c.Description, config.DefaultPagerdutyConfig.Description)
+		key := c.RoutingKey
+		if key == "" {
+			key = c.ServiceKey
+			i.unsupported(field+".service_key", "the Events API v2 is used, the service key is imported as routing key")
+		}
+		if len(c.Details) > 0 {
+			i.unsupported(field+".details", "custom details are not supported")
+		}
+		if len(c.Images) > 0 || len(c.Links) > 0 {
+			i.unsupported(field, "images and links are not supported")
+		}
+		add(field, "pagerduty", c.SendResolved(), c.HTTPConfig, settings, map[string]string{"integrationKey": string(key)})
+	}
+
+	for n, c := range r.OpsGenieConfigs {
+		field := fmt.Sprintf("%s.opsgenie_configs[%d]", receiverField, n)
+		settings := map[string]interface{}{}
+		if c.APIURL != nil {
+			apiURL := c.APIURL.String()
+			if !strings.HasSuffix(apiURL, "/") {
+				apiURL += "/"
+			}
+			settings["apiUrl"] = apiURL + "v2/alerts"
+		}
+		notDefault(field+".message", c.Message, config.DefaultOpsGenieConfig.Message, "the Grafana message template is used")
+		notDefault(field+".description", c.Description, config.DefaultOpsGenieConfig.Description, "the Grafana message template is used")
+		notDefault(field+".note", c.Note, "", "notes are not supported")
+		notDefault(field+".priority", c.Priority, "", "the priority is read from the og_priority label of the alerts")
+		if len(c.Responders) > 0 {
+			i.unsupported(field+".responders", "responders are not supported")
+		}
+		if len(c.Details) > 0 {
+			i.unsupported(field+".details", "the labels of the alerts are sent as tags")
+		}
+		add(field, "opsgenie", c.SendResolved(), c.HTTPConfig, settings, map[string]string{"apiKey": string(c.APIKey)})
+	}
+
+	for n, c := range r.VictorOpsConfigs {
+		field := fmt.Sprintf("%s.victorops_configs[%d]", receiverField, n)
+		settings := map[string]interface{}{"messageType": c.MessageType}
+		if c.APIKey == redactedSecret {
+			i.unsupported(field, "the secret api_key is redacted, set the URL after the import")
+		} else if c.APIURL != nil {
+			apiURL := c.APIURL.String()
+			if !strings.HasSuffix(apiURL, "/") {
+				apiURL += "/"
+			}
+			settings["url"] = apiURL + url.PathEscape(string(c.APIKey)) + "/" + url.PathEscape(c.RoutingKey)
+		}
+		if c.APIKeyFile != "" {
+			i.unsupported(field+".api_key_file", "the URL must be set after the import")
+		}
+		notDefault(field+".state_message", c.StateMessage, config.DefaultVictorOpsConfig.StateMessage, "the Grafana message template is used")
+		notDefault(field+".entity_display_name", c.EntityDisplayName, config.DefaultVictorOpsConfig.EntityDisplayName, "the Grafana title template is used")
+		if len(c.CustomFields) > 0 {
+			i.unsupported(field+".custom_fields", "custom fields are not supported")
+		}
+		add(field, "victorops", c.SendResolved(), c.HTTPConfig, settings, nil)
+	}
+
+	for n, c := range r.PushoverConfigs {
+		field := fmt.Sprintf("%s.pushover_configs[%d]", receiverField, n)
+		settings := map[string]interface{}{
+			"retry":  strconv.Itoa(int(time.Duration(c.Retry).Seconds())),
+			"expire": strconv.Itoa(int(time.Duration(c.Expire).Seconds())),
+		}
+		setNotDefault(settings, "sound", c.Sound, "")
+		setNotDefault(settings, "message", c.Message, config.DefaultPushoverConfig.Message)
+		if c.Priority != config.DefaultPushoverConfig.Priority {
+			if _, err := strconv.Atoi(c.Priority); err == nil {
+				settings["priority"] = c.Priority
+			} else {
+				i.unsupported(field+".priority", "templated priorities are not supported")
+			}
+		}
+		notDefault(field+".title", c.Title, config.DefaultPushoverConfig.Title, "the Grafana title template is used")
+		notDefault(field+".url", c.URL, config.DefaultPushoverConfig.URL, "the URL links to the alert rules")
+		add(field, "pushover", c.SendResolved(), c.HTTPConfig, settings, map[string]string{
+			"userKey":  string(c.UserKey),
+			"apiToken": string(c.Token),
+		})
+	}
+
+	for n := range r.WechatConfigs {
+		i.unsupported(fmt.Sprintf("%s.wechat_configs[%d]", receiverField, n), "WeChat is not supported")
+	}
+	return receivers
+}
+
+// importHTTPConfig returns the proxy of the HTTP client configuration of an integration, only the basic auth of
+// webhooks is supported besides it.
+func (i *configImport) importHTTPConfig(field string, c *commoncfg.HTTPClientConfig, basicAuth bool) string {
+	if c == nil {
+		return ""
+	}
+	if (c.BasicAuth != nil && !basicAuth) || c.Authorization != nil || c.OAuth2 != nil || c.BearerToken != "" || c.BearerTokenFile != "" {
+		i.unsupported(field+".http_config", "authentication is not supported")
+	}
+	if c.TLSConfig != (commoncfg.TLSConfig{}) {
+		i.unsupported(field+".http_config.tls_config", "TLS configuration is not supported")
+	}
+	if c.ProxyURL.URL == nil {
+		return ""
+	}
+	return c.ProxyURL.String()
+}
+
+// setNotDefault sets a setting of a receiver if it isn't the default value of the Alertmanager, the Grafana receivers
+// have their own defaults.
+func setNotDefault(settings map[string]interface{}, key string, value string, defaultValue string) {
+	if value != defaultValue {
+		settings[key] = value
+	}
+}
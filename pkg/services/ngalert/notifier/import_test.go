@@ -0,0 +1,186 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const externalAlertmanagerConfig = `
+global:
+  smtp_smarthost: smtp.example.com:587
+  smtp_from: alertmanager@example.com
+  slack_api_url: https://hooks.slack.com/services/secret
+route:
+  receiver: team-a
+  group_by: [alertname]
+  routes:
+  - receiver: team-b
+    matchers: [team="b"]
+    mute_time_intervals: [weekends]
+mute_time_intervals:
+- name: weekends
+  time_intervals:
+  - weekdays: [saturday, sunday]
+inhibit_rules:
+- source_matchers: [severity="critical"]
+  target_matchers: [severity="warning"]
+  equal: [alertname]
+receivers:
+- name: team-a
+  email_configs:
+  - to: team-a@example.com
+  slack_configs:
+  - channel: '#team-a'
+    send_resolved: true
+    actions:
+    - type: button
+      text: Runbook
+      url: https://example.com/runbook
+- name: team-b
+  webhook_configs:
+  - url: https://example.com/hook
+    http_config:
+      basic_auth:
+        username: user
+        password: pass
+  wechat_configs:
+  - corp_id: corp
+    api_secret: secret
+templates:
+- /etc/alertmanager/*.tmpl
+`
+
+func TestImportAlertmanagerConfig(t *testing.T) {
+	t.Run("converts the integrations to Grafana receivers and keeps the routing", func(t *testing.T) {
+		cfg, unsupported, err := ImportAlertmanagerConfig([]byte(externalAlertmanagerConfig), map[string]string{"team.tmpl": `{{ define "team" }}{{ end }}`})
+		require.NoError(t, err)
+
+		require.Equal(t, "team-a", cfg.AlertmanagerConfig.Route.Receiver)
+		require.Len(t, cfg.AlertmanagerConfig.Route.Routes, 1)
+		require.Empty(t, cfg.AlertmanagerConfig.Route.Routes[0].MuteTimeIntervals)
+		require.Len(t, cfg.AlertmanagerConfig.InhibitRules, 1)
+		require.Contains(t, cfg.TemplateFiles, "team.tmpl")
+
+		require.Len(t, cfg.AlertmanagerConfig.Receivers, 2)
+		teamA := cfg.AlertmanagerConfig.Receivers[0]
+		require.Equal(t, "team-a", teamA.Name)
+		require.Len(t, teamA.GrafanaManagedReceivers, 2)
+		email := teamA.GrafanaManagedReceivers[0]
+		require.Equal(t, "email", email.Type)
+		require.True(t, email.DisableResolveMessage)
+		require.Equal(t, "team-a@example.com", email.Settings.Get("addresses").MustString())
+		slack := teamA.GrafanaManagedReceivers[1]
+		require.Equal(t, "slack", slack.Type)
+		require.False(t, slack.DisableResolveMessage)
+		require.Equal(t, "#team-a", slack.Settings.Get("recipient").MustString())
+		_, hasTitle := slack.Settings.CheckGet("title")
+		require.False(t, hasTitle, "the default Alertmanager template is not imported")
+		require.Equal(t, map[string]string{"url": "https://hooks.slack.com/services/secret"}, slack.SecureSettings)
+
+		teamB := cfg.AlertmanagerConfig.Receivers[1]
+		require.Len(t, teamB.GrafanaManagedReceivers, 1)
+		webhook := teamB.GrafanaManagedReceivers[0]
+		require.Equal(t, "webhook", webhook.Type)
+		require.Equal(t, "https://example.com/hook", webhook.Settings.Get("url").MustString())
+		require.Equal(t, "user", webhook.Settings.Get("username").MustString())
+		require.Equal(t, map[string]string{"password": "pass"}, webhook.SecureSettings)
+
+		require.ElementsMatch(t, []string{
+			"mute_time_intervals: time intervals are not supported, create mute timings instead",
+			"route.routes[0].mute_time_intervals: time intervals are not supported, create mute timings instead",
+			"receivers[team-a].email_configs[0]: the SMTP server of Grafana is used, its smarthost, sender and authentication are ignored",
+			"receivers[team-a].slack_configs[0].actions: actions are not supported",
+			"receivers[team-b].wechat_configs[0]: WeChat is not supported",
+		}, unsupported)
+	})
+
+	t.Run("reports the template files and the redacted secrets that are missing", func(t *testing.T) {
+		cfg, unsupported, err := ImportAlertmanagerConfig([]byte(`
+route:
+  receiver: team-a
+receivers:
+- name: team-a
+  slack_configs:
+  - api_url: <secret>
+    channel: '#team-a'
+templates:
+- /etc/alertmanager/*.tmpl
+`), nil)
+		require.NoError(t, err)
+		require.Empty(t, cfg.AlertmanagerConfig.Receivers[0].GrafanaManagedReceivers[0].SecureSettings)
+		require.ElementsMatch(t, []string{
+			"templates: the template files are not part of the configuration, their content must be given",
+			"receivers[team-a].slack_configs[0]: the secret url is redacted, set it after the import",
+		}, unsupported)
+	})
+
+	t.Run("rejects invalid configurations", func(t *testing.T) {
+		_, _, err := ImportAlertmanagerConfig([]byte(`
+route:
+  receiver: unknown
+receivers:
+- name: team-a
+`), nil)
+		require.Error(t, err)
+	})
+}
+
+func TestFetchAlertmanagerConfig(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, ok := r.BasicAuth()
+		if r.URL.Path != "/alertmanager/api/v2/status" || !ok || user != "admin" || password != "secret" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"config": {"original": "route:\n  receiver: team-a\n"}}`))
+	}))
+	defer server.Close()
+
+	raw, err := FetchAlertmanagerConfig(context.Background(), "http://admin:secret@"+server.Listener.Addr().String()+"/alertmanager")
+	require.NoError(t, err)
+	require.Equal(t, "route:\n  receiver: team-a\n", string(raw))
+
+	_, err = FetchAlertmanagerConfig(context.Background(), server.URL)
+	require.EqualError(t, err, "failed to get the status of the Alertmanager: unexpected status code 404")
+
+	_, err = FetchAlertmanagerConfig(context.Background(), "ftp://example.com")
+	require.Error(t, err)
+
+	t.Run("the host must be in the data source proxy whitelist", func(t *testing.T) {
+		t.Cleanup(func() { setting.DataProxyWhiteList = nil })
+		setting.DataProxyWhiteList = map[string]bool{"alertmanager:9093": true}
+		_, err := FetchAlertmanagerConfig(context.Background(), "http://admin:secret@"+server.Listener.Addr().String()+"/alertmanager")
+		require.ErrorIs(t, err, ErrAlertmanagerURLNotAllowed)
+
+		setting.DataProxyWhiteList[server.Listener.Addr().String()] = true
+		_, err = FetchAlertmanagerConfig(context.Background(), "http://admin:secret@"+server.Listener.Addr().String()+"/alertmanager")
+		require.NoError(t, err)
+	})
+
+	t.Run("the redirects must be in the data source proxy whitelist", func(t *testing.T) {
+		redirect := httptest.NewServer(http.RedirectHandler("http://169.254.169.254/latest/meta-data", http.StatusFound))
+		defer redirect.Close()
+		t.Cleanup(func() { setting.DataProxyWhiteList = nil })
+		setting.DataProxyWhiteList = map[string]bool{redirect.Listener.Addr().String(): true}
+		_, err := FetchAlertmanagerConfig(context.Background(), redirect.URL)
+		require.ErrorIs(t, err, ErrAlertmanagerURLNotAllowed)
+	})
+
+	t.Run("the size of the status is limited", func(t *testing.T) {
+		large := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"config": {"original": "`))
+			_, _ = w.Write(bytes.Repeat([]byte("a"), maxAlertmanagerStatusSize))
+			_, _ = w.Write([]byte(`"}}`))
+		}))
+		defer large.Close()
+		_, err := FetchAlertmanagerConfig(context.Background(), large.URL)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,264 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	// labelPolicyLookupRefreshInterval is how often the lookup tables fetched from a URL are refreshed.
+	labelPolicyLookupRefreshInterval = 5 * time.Minute
+	labelPolicyLookupTimeout         = 10 * time.Second
+	// maxLabelPolicyLookupSize is the maximum size of a lookup table fetched from a URL.
+	maxLabelPolicyLookupSize = 10 << 20
+)
+
+// compiledLabelPolicy is a label policy with its parsed matchers and its lookup table.
+type compiledLabelPolicy struct {
+	ngmodels.LabelPolicy
+	matchers labels.Matchers
+	lookup   map[string]string
+}
+
+func (p compiledLabelPolicy) apply(ls map[string]string) {
+	for _, m := range p.matchers {
+		if !m.Matches(ls[m.Name]) {
+			return
+		}
+	}
+
+	value, ok := ls[p.Label]
+	switch p.Action {
+	case ngmodels.LabelPolicyAdd:
+		if !ok || p.Overwrite {
+			ls[p.Label] = p.Value
+		}
+	case ngmodels.LabelPolicyRename:
+		if !ok {
+			return
+		}
+		if _, exists := ls[p.TargetLabel]; exists && !p.Overwrite {
+			return
+		}
+		ls[p.TargetLabel] = value
+		delete(ls, p.Label)
+	case ngmodels.LabelPolicyDrop:
+		delete(ls, p.Label)
+	case ngmodels.LabelPolicyMap:
+		target, found := p.lookup[value]
+		if !found || !ok {
+			if p.Default == "" {
+				return
+			}
+			target = p.Default
+		}
+		if _, exists := ls[p.TargetLabel]; exists && !p.Overwrite {
+			return
+		}
+		ls[p.TargetLabel] = target
+	}
+}
+
+// GetLabelPolicies returns the label policies of the organization, or nil if it has none.
+func (am *Alertmanager) GetLabelPolicies() *ngmodels.LabelPolicies {
+	am.labelPoliciesMtx.RLock()
+	defer am.labelPoliciesMtx.RUnlock()
+	return am.labelPolicies
+}
+
+// ApplyLabelPolicies rewrites the labels of the alerts with the label policies of the organization, before they are
+// sent to the embedded Alertmanager or to the external ones.
+func (am *Alertmanager) ApplyLabelPolicies(alerts apimodels.PostableAlerts) {
+	am.labelPoliciesMtx.RLock()
+	defer am.labelPoliciesMtx.RUnlock()
+	if len(am.compiledLabelPolicies) == 0 {
+		return
+	}
+
+	for i := range alerts.PostableAlerts {
+		if alerts.PostableAlerts[i].Labels == nil {
+			alerts.PostableAlerts[i].Labels = map[string]string{}
+		}
+		for _, p := range am.compiledLabelPolicies {
+			p.apply(alerts.PostableAlerts[i].Labels)
+		}
+	}
+}
+
+// SaveAndApplyLabelPolicies saves the label policies of the organization to the database and starts applying them.
+// The lookup tables fetched from a URL must be available.
+func (am *Alertmanager) SaveAndApplyLabelPolicies(p *ngmodels.LabelPolicies) error {
+	for i, policy := range p.Policies {
+		if err := policy.Validate(); err != nil {
+			return fmt.Errorf("label policy %d: %w", i, err)
+		}
+	}
+	compiled, err := compileLabelPolicies(context.Background(), p.Policies, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ngmodels.ErrLabelPolicyFailedValidation, err)
+	}
+
+	p.OrgID = am.orgID
+	if err := am.Store.SaveLabelPolicies(p); err != nil {
+		return err
+	}
+
+	am.labelPoliciesMtx.Lock()
+	defer am.labelPoliciesMtx.Unlock()
+	am.labelPolicies = p
+	am.compiledLabelPolicies = compiled
+	am.labelPolicyLookupsFetchedAt = time.Now()
+	am.logger.Info("label policies updated", "policies", len(p.Policies), "updated_by", p.UpdatedBy)
+	return nil
+}
+
+// DeleteLabelPolicies removes the label policies of the organization.
+func (am *Alertmanager) DeleteLabelPolicies() error {
+	if err := am.Store.DeleteLabelPolicies(am.orgID); err != nil {
+		return err
+	}
+
+	am.labelPoliciesMtx.Lock()
+	defer am.labelPoliciesMtx.Unlock()
+	am.labelPolicies = nil
+	am.compiledLabelPolicies = nil
+	am.logger.Info("label policies removed")
+	return nil
+}
+
+// SyncLabelPoliciesFromDatabase picks the label policies of the organization from the database, and refreshes the
+// lookup tables fetched from a URL. The last lookup table fetched is kept when it can't be refreshed.
+func (am *Alertmanager) SyncLabelPoliciesFromDatabase() error {
+	p, err := am.Store.GetLabelPolicies(am.orgID)
+	if err != nil && !errors.Is(err, ngmodels.ErrNoLabelPolicies) {
+		return err
+	}
+
+	am.labelPoliciesMtx.RLock()
+	current, previous, fetchedAt := am.labelPolicies, am.compiledLabelPolicies, am.labelPolicyLookupsFetchedAt
+	am.labelPoliciesMtx.RUnlock()
+
+	var policies, currentPolicies ngmodels.LabelPolicyList
+	if p != nil {
+		policies = p.Policies
+	}
+	if current != nil {
+		currentPolicies = current.Policies
+	}
+	if reflect.DeepEqual(policies, currentPolicies) && (!hasLookupURL(policies) || time.Since(fetchedAt) < labelPolicyLookupRefreshInterval) {
+		return nil
+	}
+
+	compiled, err := compileLabelPolicies(context.Background(), policies, previous)
+
+	am.labelPoliciesMtx.Lock()
+	defer am.labelPoliciesMtx.Unlock()
+	am.labelPolicies = p
+	am.compiledLabelPolicies = compiled
+	am.labelPolicyLookupsFetchedAt = time.Now()
+	return err
+}
+
+func hasLookupURL(policies ngmodels.LabelPolicyList) bool {
+	for _, p := range policies {
+		if p.Action == ngmodels.LabelPolicyMap && p.LookupURL != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// compileLabelPolicies parses the matchers of the policies and fetches their lookup tables. When a lookup table
+// can't be fetched, the one of the previous policy with the same URL is used, and the first error is returned.
+func compileLabelPolicies(ctx context.Context, policies ngmodels.LabelPolicyList, previous []compiledLabelPolicy) ([]compiledLabelPolicy, error) {
+	var fetchErr error
+	compiled := make([]compiledLabelPolicy, 0, len(policies))
+	for i, p := range policies {
+		matchers, err := p.ParseMatchers()
+		if err != nil {
+			return nil, fmt.Errorf("label policy %d: %w", i, err)
+		}
+		c := compiledLabelPolicy{LabelPolicy: p, matchers: matchers, lookup: p.Lookup}
+		if p.Action == ngmodels.LabelPolicyMap && p.LookupURL != "" {
+			c.lookup, err = fetchLabelPolicyLookup(ctx, p.LookupURL)
+			if err != nil {
+				if fetchErr == nil {
+					fetchErr = fmt.Errorf("label policy %d: failed to fetch the lookup table: %w", i, err)
+				}
+				if i < len(previous) && previous[i].LookupURL == p.LookupURL {
+					c.lookup = previous[i].lookup
+				}
+			}
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, fetchErr
+}
+
+func fetchLabelPolicyLookup(ctx context.Context, url string) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, labelPolicyLookupTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxLabelPolicyLookupSize))
+	if err != nil {
+		return nil, err
+	}
+	return ParseLabelPolicyLookup(b)
+}
+
+// ParseLabelPolicyLookup parses the lookup table of a map policy, a JSON object or a CSV file whose two columns are
+// the value of the label and the value of the target label.
+func ParseLabelPolicyLookup(b []byte) (map[string]string, error) {
+	b = bytes.TrimSpace(b)
+	lookup := map[string]string{}
+	if bytes.HasPrefix(b, []byte("{")) {
+		if err := json.Unmarshal(b, &lookup); err != nil {
+			return nil, fmt.Errorf("invalid lookup table: %w", err)
+		}
+		return lookup, nil
+	}
+
+	r := csv.NewReader(bytes.NewReader(b))
+	r.FieldsPerRecord = 2
+	r.TrimLeadingSpace = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid lookup table: %w", err)
+	}
+	for _, record := range records {
+		if !model.LabelValue(record[1]).IsValid() {
+			return nil, fmt.Errorf("invalid lookup table: invalid label value %q", record[1])
+		}
+		lookup[record[0]] = record[1]
+	}
+	return lookup, nil
+}
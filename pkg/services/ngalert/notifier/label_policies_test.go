@@ -0,0 +1,156 @@
+package notifier
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func postableAlerts(labels ...map[string]string) apimodels.PostableAlerts {
+	alerts := apimodels.PostableAlerts{}
+	for _, ls := range labels {
+		alerts.PostableAlerts = append(alerts.PostableAlerts, models.PostableAlert{
+			Alert:    models.Alert{Labels: ls},
+			StartsAt: strfmt.DateTime(time.Now()),
+		})
+	}
+	return alerts
+}
+
+func TestLabelPolicies(t *testing.T) {
+	am := setupAMTest(t)
+
+	alerts := postableAlerts(map[string]string{"alertname": "test", "service": "db", "env": "prod"})
+	am.ApplyLabelPolicies(alerts)
+	require.Equal(t, map[string]string{"alertname": "test", "service": "db", "env": "prod"}, map[string]string(alerts.PostableAlerts[0].Labels), "labels should not be rewritten without label policies")
+
+	require.NoError(t, am.SaveAndApplyLabelPolicies(&ngmodels.LabelPolicies{
+		UpdatedBy: "admin",
+		Policies: ngmodels.LabelPolicyList{
+			{Action: ngmodels.LabelPolicyMap, Label: "service", TargetLabel: "team", Lookup: map[string]string{"db": "storage"}, Default: "unknown"},
+			{Action: ngmodels.LabelPolicyRename, Label: "env", TargetLabel: "environment"},
+			{Action: ngmodels.LabelPolicyAdd, Matchers: []string{`team="storage"`}, Label: "pager", Value: "storage-oncall"},
+			{Action: ngmodels.LabelPolicyAdd, Label: "region", Value: "eu"},
+			{Action: ngmodels.LabelPolicyDrop, Label: "instance"},
+		},
+	}))
+
+	alerts = postableAlerts(
+		map[string]string{"alertname": "test", "service": "db", "env": "prod", "instance": "host:9090"},
+		map[string]string{"alertname": "test", "service": "web", "region": "us"},
+	)
+	am.ApplyLabelPolicies(alerts)
+	require.Equal(t, map[string]string{
+		"alertname":   "test",
+		"service":     "db",
+		"team":        "storage",
+		"environment": "prod",
+		"pager":       "storage-oncall",
+		"region":      "eu",
+	}, map[string]string(alerts.PostableAlerts[0].Labels))
+	require.Equal(t, map[string]string{
+		"alertname": "test",
+		"service":   "web",
+		"team":      "unknown",
+		"region":    "us",
+	}, map[string]string(alerts.PostableAlerts[1].Labels), "existing labels should not be overwritten")
+
+	t.Run("label policies are loaded from the database", func(t *testing.T) {
+		am.labelPolicies, am.compiledLabelPolicies = nil, nil
+		require.NoError(t, am.SyncLabelPoliciesFromDatabase())
+		require.Equal(t, "admin", am.GetLabelPolicies().UpdatedBy)
+		require.Len(t, am.compiledLabelPolicies, 5)
+	})
+
+	t.Run("invalid label policies are rejected", func(t *testing.T) {
+		err := am.SaveAndApplyLabelPolicies(&ngmodels.LabelPolicies{Policies: ngmodels.LabelPolicyList{
+			{Action: ngmodels.LabelPolicyDrop, Label: ngmodels.RuleUIDLabel},
+		}})
+		require.ErrorIs(t, err, ngmodels.ErrLabelPolicyFailedValidation)
+		require.Len(t, am.GetLabelPolicies().Policies, 5)
+	})
+
+	t.Run("label policies are deleted", func(t *testing.T) {
+		require.NoError(t, am.DeleteLabelPolicies())
+		require.Nil(t, am.GetLabelPolicies())
+		_, err := am.Store.GetLabelPolicies(am.orgID)
+		require.ErrorIs(t, err, ngmodels.ErrNoLabelPolicies)
+
+		alerts := postableAlerts(map[string]string{"alertname": "test", "instance": "host:9090"})
+		am.ApplyLabelPolicies(alerts)
+		require.Equal(t, map[string]string{"alertname": "test", "instance": "host:9090"}, map[string]string(alerts.PostableAlerts[0].Labels))
+	})
+}
+
+func TestLabelPolicies_LookupURL(t *testing.T) {
+	lookup := "db,storage\nweb,frontend\n"
+	failing := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(lookup))
+	}))
+	defer server.Close()
+
+	am := setupAMTest(t)
+	require.NoError(t, am.SaveAndApplyLabelPolicies(&ngmodels.LabelPolicies{Policies: ngmodels.LabelPolicyList{
+		{Action: ngmodels.LabelPolicyMap, Label: "service", TargetLabel: "team", LookupURL: server.URL},
+	}}))
+
+	apply := func() string {
+		alerts := postableAlerts(map[string]string{"service": "web"})
+		am.ApplyLabelPolicies(alerts)
+		return alerts.PostableAlerts[0].Labels["team"]
+	}
+	require.Equal(t, "frontend", apply())
+
+	t.Run("lookup table is refreshed", func(t *testing.T) {
+		lookup = "web,marketing\n"
+		require.NoError(t, am.SyncLabelPoliciesFromDatabase())
+		require.Equal(t, "frontend", apply(), "lookup table should not be refreshed before the refresh interval")
+
+		am.labelPolicyLookupsFetchedAt = time.Now().Add(-labelPolicyLookupRefreshInterval)
+		require.NoError(t, am.SyncLabelPoliciesFromDatabase())
+		require.Equal(t, "marketing", apply())
+	})
+
+	t.Run("last lookup table is kept when it can't be refreshed", func(t *testing.T) {
+		failing = true
+		am.labelPolicyLookupsFetchedAt = time.Now().Add(-labelPolicyLookupRefreshInterval)
+		require.Error(t, am.SyncLabelPoliciesFromDatabase())
+		require.Equal(t, "marketing", apply())
+	})
+
+	t.Run("label policies are rejected when the lookup table can't be fetched", func(t *testing.T) {
+		err := am.SaveAndApplyLabelPolicies(&ngmodels.LabelPolicies{Policies: ngmodels.LabelPolicyList{
+			{Action: ngmodels.LabelPolicyMap, Label: "service", TargetLabel: "owner", LookupURL: server.URL},
+		}})
+		require.ErrorIs(t, err, ngmodels.ErrLabelPolicyFailedValidation)
+	})
+}
+
+func TestParseLabelPolicyLookup(t *testing.T) {
+	lookup, err := ParseLabelPolicyLookup([]byte(`{"db": "storage", "web": "frontend"}`))
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"db": "storage", "web": "frontend"}, lookup)
+
+	lookup, err = ParseLabelPolicyLookup([]byte("db, storage\nweb,frontend\n"))
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"db": "storage", "web": "frontend"}, lookup)
+
+	_, err = ParseLabelPolicyLookup([]byte("db,storage,extra\n"))
+	require.Error(t, err)
+
+	_, err = ParseLabelPolicyLookup([]byte(`{"db": 1}`))
+	require.Error(t, err)
+}
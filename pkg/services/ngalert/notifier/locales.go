@@ -0,0 +1,86 @@
+package notifier
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/prometheus/alertmanager/template"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels"
+)
+
+// templates are the templates of the notifications: the templates of the configuration, and their variants for the
+// locales of the contact points, parsed when an integration first needs them.
+type templates struct {
+	*template.Template
+	am *Alertmanager
+	// paths are the files of the templates of the configuration.
+	paths     []string
+	localized map[string]*template.Template
+}
+
+func (am *Alertmanager) newTemplates(paths []string) (*templates, error) {
+	tmpl, err := am.templateFromPaths(paths...)
+	if err != nil {
+		return nil, err
+	}
+	return &templates{Template: tmpl, am: am, paths: paths, localized: map[string]*template.Template{}}, nil
+}
+
+// forLocale returns the templates of the locale, the templates of the configuration if it is empty. The definitions
+// of the locale replace the English definitions of the default templates, but not the definitions of the templates
+// of the configuration.
+func (t *templates) forLocale(locale string) (*template.Template, error) {
+	if locale == "" {
+		return t.Template, nil
+	}
+	if tmpl, ok := t.localized[locale]; ok {
+		return tmpl, nil
+	}
+	l, err := channels.LookupLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := t.am.localesDirPath()
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("unable to create locale directory %q: %w", dir, err)
+	}
+	// The name of the locale is checked by LookupLocale, it is a valid file name without glob patterns.
+	file := filepath.Join(dir, locale+".tmpl")
+	content := l.TemplateString()
+	// nolint:gosec
+	if current, err := ioutil.ReadFile(file); err != nil || string(current) != content {
+		if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+			return nil, fmt.Errorf("unable to create locale template file %q: %w", file, err)
+		}
+	}
+
+	// The templates of the configuration are parsed after the locale, so that they keep their definitions. The
+	// default templates must be parsed before it.
+	paths := make([]string, 0, len(t.paths)+1)
+	var configured []string
+	for _, p := range t.paths {
+		if filepath.Base(p) == defaultTemplateFile {
+			paths = append(paths, p)
+		} else {
+			configured = append(configured, p)
+		}
+	}
+	paths = append(append(paths, file), configured...)
+	tmpl, err := t.am.templateFromPaths(paths...)
+	if err != nil {
+		return nil, err
+	}
+	t.localized[locale] = tmpl
+	return tmpl, nil
+}
+
+// localesDirPath is the directory of the templates of the locales of the organization. It is not in the working
+// directory, whose files not in the configuration are removed when it is applied.
+func (am *Alertmanager) localesDirPath() string {
+	return filepath.Join(am.Settings.DataPath, workingDir, "locales", strconv.Itoa(int(am.orgID)))
+}
@@ -0,0 +1,68 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels"
+)
+
+func TestTemplatesForLocale(t *testing.T) {
+	am := setupAMTest(t)
+	cfg, err := Load([]byte(`{
+		"template_files": {"custom.tmpl": "{{ define \"__locale.Resolved\" }}Closed{{ end }}"},
+		"alertmanager_config": {
+			"route": {"receiver": "default"},
+			"receivers": [
+				{"name": "default", "grafana_managed_receiver_configs": [{"uid": "", "name": "default", "type": "webhook", "locale": "fr", "settings": {"url": "http://localhost/default"}}]}
+			]
+		}
+	}`))
+	require.NoError(t, err)
+	require.NoError(t, am.SaveAndApplyConfig(cfg))
+
+	now := time.Now()
+	alerts := []*types.Alert{
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "HighCPU"}, StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)}},
+		{Alert: model.Alert{Labels: model.LabelSet{"alertname": "HighCPU", "instance": "b"}, StartsAt: now.Add(-time.Hour), EndsAt: now.Add(-time.Minute)}},
+	}
+	expand := func(locale, text string) string {
+		templates, err := am.getTemplate()
+		require.NoError(t, err)
+		tmpl, err := templates.forLocale(locale)
+		require.NoError(t, err)
+		var tmplErr error
+		ctx := notify.WithGroupKey(context.Background(), "test")
+		expand, _ := channels.TmplText(ctx, tmpl, alerts, log.New("test"), &tmplErr)
+		s := expand(text)
+		require.NoError(t, tmplErr)
+		return s
+	}
+
+	require.Equal(t, "[FIRING:1]  (HighCPU)", expand("", `{{ template "default.title" . }}`))
+	require.Equal(t, "[EN ALERTE:1]  (HighCPU)", expand("fr", `{{ template "default.title" . }}`))
+	// The locales of a region fall back to the locale of their language.
+	require.Equal(t, "[EN ALERTE:1]  (HighCPU)", expand("fr-CA", `{{ template "default.title" . }}`))
+
+	message := expand("fr", `{{ template "default.message" . }}`)
+	require.Contains(t, message, "**En alerte**\n\nÉtiquettes:\n - alertname = HighCPU\n")
+	// The templates of the configuration keep their definitions.
+	require.Contains(t, message, "**Closed**")
+
+	require.Equal(t, "1,234,567.5 2021-10-15 10:00:00 UTC", expand("", `{{ template "locale.number" 1234567.5 }} {{ template "locale.date" 1634292000 }}`))
+	require.Equal(t, "1.234.567,5 15.10.2021 10:00:00 UTC", expand("de", `{{ template "locale.number" 1234567.5 }} {{ template "locale.date" 1634292000 }}`))
+
+	t.Run("the contact points with an unknown locale are invalid", func(t *testing.T) {
+		cfg.AlertmanagerConfig.Receivers[0].GrafanaManagedReceivers[0].Locale = "xx"
+		err := am.SaveAndApplyConfig(cfg)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `unknown locale "xx"`)
+	})
+}
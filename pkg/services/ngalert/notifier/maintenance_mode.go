@@ -0,0 +1,91 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// GetMaintenanceMode returns the maintenance mode of the organization, or nil if it's not in maintenance mode.
+func (am *Alertmanager) GetMaintenanceMode() *ngmodels.MaintenanceMode {
+	am.maintenanceMtx.RLock()
+	defer am.maintenanceMtx.RUnlock()
+	return am.maintenance
+}
+
+// InMaintenanceMode returns whether notifications of the organization are suppressed at t.
+func (am *Alertmanager) InMaintenanceMode(t time.Time) bool {
+	return am.GetMaintenanceMode().Active(t)
+}
+
+// SaveAndApplyMaintenanceMode saves the maintenance mode to the database and starts suppressing notifications.
+func (am *Alertmanager) SaveAndApplyMaintenanceMode(m *ngmodels.MaintenanceMode) error {
+	m.OrgID = am.orgID
+	if err := am.Store.SaveMaintenanceMode(m); err != nil {
+		return err
+	}
+
+	am.maintenanceMtx.Lock()
+	defer am.maintenanceMtx.Unlock()
+	am.maintenance = m
+	am.logger.Info("maintenance mode enabled", "expires_at", time.Unix(m.ExpiresAt, 0), "created_by", m.CreatedBy)
+	return nil
+}
+
+// DeleteMaintenanceMode disables the maintenance mode of the organization.
+func (am *Alertmanager) DeleteMaintenanceMode() error {
+	if err := am.Store.DeleteMaintenanceMode(am.orgID); err != nil {
+		return err
+	}
+
+	am.maintenanceMtx.Lock()
+	defer am.maintenanceMtx.Unlock()
+	am.maintenance = nil
+	am.logger.Info("maintenance mode disabled")
+	return nil
+}
+
+// SyncMaintenanceModeFromDatabase picks the maintenance mode of the organization from the database.
+// An expired maintenance mode is removed.
+func (am *Alertmanager) SyncMaintenanceModeFromDatabase() error {
+	m, err := am.Store.GetMaintenanceMode(am.orgID)
+	if err != nil && !errors.Is(err, ngmodels.ErrNoMaintenanceMode) {
+		return err
+	}
+
+	if m != nil && !m.Active(time.Now()) {
+		am.logger.Info("maintenance mode expired", "expires_at", time.Unix(m.ExpiresAt, 0))
+		if err := am.Store.DeleteMaintenanceMode(am.orgID); err != nil {
+			return err
+		}
+		m = nil
+	}
+
+	am.maintenanceMtx.Lock()
+	defer am.maintenanceMtx.Unlock()
+	am.maintenance = m
+	return nil
+}
+
+// maintenanceStage drops the notifications of a receiver while the organization is in maintenance mode.
+type maintenanceStage struct {
+	am       *Alertmanager
+	receiver string
+}
+
+func (s *maintenanceStage) Exec(ctx context.Context, l log.Logger, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+	if !s.am.InMaintenanceMode(time.Now()) {
+		return ctx, alerts, nil
+	}
+
+	groupKey, _ := notify.GroupKey(ctx)
+	s.am.logger.Info("notification suppressed", "suppressed", "maintenance_mode", "receiver", s.receiver, "group_key", groupKey, "alerts", len(alerts))
+	s.am.Metrics.SuppressedNotifications.WithLabelValues(s.receiver).Add(float64(len(alerts)))
+	return ctx, nil, nil
+}
@@ -0,0 +1,45 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestMaintenanceMode(t *testing.T) {
+	am := setupAMTest(t)
+	stage := &maintenanceStage{am: am, receiver: "test"}
+	alerts := []*types.Alert{{Alert: model.Alert{Labels: model.LabelSet{"alertname": "test"}}}}
+
+	_, res, err := stage.Exec(context.Background(), log.NewNopLogger(), alerts...)
+	require.NoError(t, err)
+	require.Len(t, res, 1, "notifications should be sent when not in maintenance mode")
+
+	require.NoError(t, am.SaveAndApplyMaintenanceMode(&ngmodels.MaintenanceMode{ExpiresAt: time.Now().Add(time.Hour).Unix()}))
+	_, res, err = stage.Exec(context.Background(), log.NewNopLogger(), alerts...)
+	require.NoError(t, err)
+	require.Len(t, res, 0, "notifications should be suppressed in maintenance mode")
+
+	t.Run("maintenance mode is loaded from the database", func(t *testing.T) {
+		am.maintenance = nil
+		require.NoError(t, am.SyncMaintenanceModeFromDatabase())
+		require.True(t, am.InMaintenanceMode(time.Now()))
+	})
+
+	t.Run("expired maintenance mode is removed", func(t *testing.T) {
+		require.NoError(t, am.SaveAndApplyMaintenanceMode(&ngmodels.MaintenanceMode{ExpiresAt: time.Now().Add(-time.Minute).Unix()}))
+		require.False(t, am.InMaintenanceMode(time.Now()))
+
+		require.NoError(t, am.SyncMaintenanceModeFromDatabase())
+		require.Nil(t, am.GetMaintenanceMode())
+		_, err := am.Store.GetMaintenanceMode(am.orgID)
+		require.ErrorIs(t, err, ngmodels.ErrNoMaintenanceMode)
+	})
+}
@@ -6,7 +6,13 @@ import (
 	"sync"
 	"time"
 
+	gokit_log "github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/cluster"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/logging"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/setting"
@@ -30,33 +36,105 @@ type MultiOrgAlertmanager struct {
 
 	configStore store.AlertingStore
 	orgStore    store.OrgStore
+	stateStore  StateStore
 
 	orgRegistry *metrics.OrgRegistries
+
+	// peer is the gossip cluster member shared by the Alertmanagers of every organization.
+	peer         ClusterPeer
+	settleCancel context.CancelFunc
+
+	// selfMonitor notifies the administrators of the organizations of the problems of the alerting, nil if disabled.
+	selfMonitor *SelfMonitor
+	// standby decides whether the Alertmanagers send the notifications or are a warm standby.
+	standby *standby
+
+	// resyncOrgs are the organizations whose Alertmanager is synced again before the next sync interval, resync
+	// wakes up the Run loop when one is added.
+	resyncMtx  sync.Mutex
+	resyncOrgs map[int64]struct{}
+	resync     chan struct{}
 }
 
-func NewMultiOrgAlertmanager(cfg *setting.Cfg, configStore store.AlertingStore, orgStore store.OrgStore) *MultiOrgAlertmanager {
-	return &MultiOrgAlertmanager{
+func NewMultiOrgAlertmanager(cfg *setting.Cfg, configStore store.AlertingStore, orgStore store.OrgStore, m *metrics.Metrics) (*MultiOrgAlertmanager, error) {
+	moa := &MultiOrgAlertmanager{
 		settings:      cfg,
 		logger:        log.New("multiorg.alertmanager"),
 		alertmanagers: map[int64]*Alertmanager{},
 		configStore:   configStore,
 		orgStore:      orgStore,
+		stateStore:    NewStateStore(cfg, configStore),
 		orgRegistry:   metrics.NewOrgRegistries(),
+		peer:          &NilPeer{},
+		resyncOrgs:    map[int64]struct{}{},
+		resync:        make(chan struct{}, 1),
+	}
+	bus.AddEventListener(moa.handleAlertmanagerConfigurationSaved)
+	bus.AddEventListener(moa.handleOrgCreated)
+	if cfg.SelfMonitoringEnabled {
+		moa.selfMonitor = newSelfMonitor(cfg)
+	}
+
+	// Clustering is only enabled when peers are configured, a single instance has nothing to gossip with.
+	if len(cfg.HAPeers) > 0 {
+		l := log.New("alertmanager.cluster")
+		peer, err := cluster.Create(
+			gokit_log.NewLogfmtLogger(logging.NewWrapper(l)),
+			m.Registerer,
+			cfg.HAListenAddr,
+			cfg.HAAdvertiseAddr,
+			cfg.HAPeers,
+			true,
+			cfg.HAPushPullInterval,
+			cfg.HAGossipInterval,
+			cluster.DefaultTcpTimeout,
+			cluster.DefaultProbeTimeout,
+			cluster.DefaultProbeInterval,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("unable to initialize gossip mesh: %w", err)
+		}
+
+		if err := peer.Join(cluster.DefaultReconnectInterval, cluster.DefaultReconnectTimeout); err != nil {
+			l.Error("unable to join gossip mesh while initializing cluster for high availability mode", "err", err)
+		}
+
+		// Attempt to verify the number of peers for 30s every 2s. Until the cluster settles a notification
+		// might be sent before the notification log of the other peers is received, and so be sent twice.
+		var ctx context.Context
+		ctx, moa.settleCancel = context.WithTimeout(context.Background(), 30*time.Second)
+		go peer.Settle(ctx, cluster.DefaultGossipInterval*10)
+		moa.peer = peer
 	}
+	moa.standby = newStandby(cfg.HAStandby, moa.peer)
+
+	return moa, nil
 }
 
 func (moa *MultiOrgAlertmanager) Run(ctx context.Context) error {
 	moa.logger.Info("starting MultiOrg Alertmanager")
 
+	ticker := time.NewTicker(SyncOrgsPollInterval)
+	defer ticker.Stop()
 	for {
 		select {
 		case <-ctx.Done():
 			moa.StopAndWait()
 			return nil
-		case <-time.After(SyncOrgsPollInterval):
+		case <-moa.resync:
+			moa.processResyncs(ctx)
+		case <-ticker.C:
 			if err := moa.LoadAndSyncAlertmanagersForOrgs(ctx); err != nil {
 				moa.logger.Error("error while synchronizing Alertmanager orgs", "err", err)
 			}
+			moa.SyncRecurringSilences(time.Now())
+			// a warm standby doesn't send notifications
+			moa.logStandbyChange()
+			if moa.standby.isActive() {
+				moa.WarnExpiringSilences(ctx, time.Now())
+				moa.ProcessEscalations(ctx, time.Now())
+				moa.CheckSelfMonitoring(ctx, time.Now())
+			}
 		}
 	}
 }
@@ -77,27 +155,15 @@ func (moa *MultiOrgAlertmanager) LoadAndSyncAlertmanagersForOrgs(ctx context.Con
 	return nil
 }
 
-func (moa *MultiOrgAlertmanager) SyncAlertmanagersForOrgs(orgIDs []int64) {
+// SyncAlertmanagersForOrgs creates the Alertmanagers of the organizations, syncs their configuration from the
+// database, and stops the Alertmanagers of the other organizations. It returns the errors of the syncs by organization.
+func (moa *MultiOrgAlertmanager) SyncAlertmanagersForOrgs(orgIDs []int64) map[int64]error {
 	orgsFound := make(map[int64]struct{}, len(orgIDs))
+	syncErrs := make(map[int64]error, len(orgIDs))
 	moa.alertmanagersMtx.Lock()
 	for _, orgID := range orgIDs {
 		orgsFound[orgID] = struct{}{}
-
-		existing, found := moa.alertmanagers[orgID]
-		if !found {
-			reg := moa.orgRegistry.GetOrCreateOrgRegistry(orgID)
-			am, err := newAlertmanager(orgID, moa.settings, moa.configStore, metrics.NewMetrics(reg))
-			if err != nil {
-				moa.logger.Error("unable to create Alertmanager for org", "org", orgID, "err", err)
-			}
-			moa.alertmanagers[orgID] = am
-			existing = am
-		}
-
-		//TODO: This will create an N+1 query
-		if err := existing.SyncAndApplyConfigFromDatabase(); err != nil {
-			moa.logger.Error("failed to apply Alertmanager config for org", "org", orgID, "err", err)
-		}
+		syncErrs[orgID] = moa.syncAlertmanager(orgID)
 	}
 
 	amsToStop := map[int64]*Alertmanager{}
@@ -116,6 +182,94 @@ func (moa *MultiOrgAlertmanager) SyncAlertmanagersForOrgs(orgIDs []int64) {
 		am.StopAndWait()
 		moa.logger.Info("stopped Alertmanager", "org", orgID)
 	}
+	return syncErrs
+}
+
+// syncAlertmanager creates the Alertmanager of the organization if it doesn't exist, and syncs its configuration,
+// maintenance mode and label policies from the database. alertmanagersMtx must be held.
+func (moa *MultiOrgAlertmanager) syncAlertmanager(orgID int64) error {
+	existing, found := moa.alertmanagers[orgID]
+	if !found {
+		reg := moa.orgRegistry.GetOrCreateOrgRegistry(orgID)
+		am, err := newAlertmanager(orgID, moa.settings, moa.configStore, moa.stateStore, moa.peer, metrics.NewMetrics(reg))
+		if err != nil {
+			moa.logger.Error("unable to create Alertmanager for org", "org", orgID, "err", err)
+			return fmt.Errorf("failed to create the Alertmanager: %w", err)
+		}
+		am.selfMonitor = moa.selfMonitor
+		am.standby = moa.standby
+		moa.alertmanagers[orgID] = am
+		existing = am
+	}
+
+	//TODO: This will create an N+1 query
+	err := existing.SyncAndApplyConfigFromDatabase()
+	if err != nil {
+		moa.logger.Error("failed to apply Alertmanager config for org", "org", orgID, "err", err)
+	}
+	if moa.selfMonitor != nil {
+		moa.selfMonitor.recordConfigApplied(orgID, err)
+	}
+	syncErr := err
+	if err := existing.SyncMaintenanceModeFromDatabase(); err != nil {
+		moa.logger.Error("failed to sync maintenance mode for org", "org", orgID, "err", err)
+		if syncErr == nil {
+			syncErr = fmt.Errorf("failed to sync maintenance mode: %w", err)
+		}
+	}
+	if err := existing.SyncLabelPoliciesFromDatabase(); err != nil {
+		moa.logger.Error("failed to sync label policies for org", "org", orgID, "err", err)
+		if syncErr == nil {
+			syncErr = fmt.Errorf("failed to sync label policies: %w", err)
+		}
+	}
+	existing.health.recordSync(time.Now(), syncErr)
+	return syncErr
+}
+
+// SyncRecurringSilences materializes the upcoming occurrences of the recurring silences of every organization.
+func (moa *MultiOrgAlertmanager) SyncRecurringSilences(now time.Time) {
+	moa.alertmanagersMtx.RLock()
+	defer moa.alertmanagersMtx.RUnlock()
+
+	for orgID, am := range moa.alertmanagers {
+		if !am.Ready() {
+			continue
+		}
+		if err := am.SyncRecurringSilences(now); err != nil {
+			moa.logger.Error("failed to sync recurring silences for org", "org", orgID, "err", err)
+		}
+	}
+}
+
+// WarnExpiringSilences sends the expiry warnings of the silences of every organization.
+func (moa *MultiOrgAlertmanager) WarnExpiringSilences(ctx context.Context, now time.Time) {
+	moa.alertmanagersMtx.RLock()
+	defer moa.alertmanagersMtx.RUnlock()
+
+	for orgID, am := range moa.alertmanagers {
+		if !am.Ready() {
+			continue
+		}
+		if err := am.WarnExpiringSilences(ctx, now); err != nil {
+			moa.logger.Error("failed to send silence expiry warnings for org", "org", orgID, "err", err)
+		}
+	}
+}
+
+// ProcessEscalations escalates the unacknowledged alert groups of every organization.
+func (moa *MultiOrgAlertmanager) ProcessEscalations(ctx context.Context, now time.Time) {
+	moa.alertmanagersMtx.RLock()
+	defer moa.alertmanagersMtx.RUnlock()
+
+	for orgID, am := range moa.alertmanagers {
+		if !am.Ready() {
+			continue
+		}
+		if err := am.ProcessEscalations(ctx, now); err != nil {
+			moa.logger.Error("failed to process escalations for org", "org", orgID, "err", err)
+		}
+	}
 }
 
 func (moa *MultiOrgAlertmanager) StopAndWait() {
@@ -125,6 +279,14 @@ func (moa *MultiOrgAlertmanager) StopAndWait() {
 	for _, am := range moa.alertmanagers {
 		am.StopAndWait()
 	}
+
+	p, ok := moa.peer.(*cluster.Peer)
+	if ok {
+		moa.settleCancel()
+		if err := p.Leave(10 * time.Second); err != nil {
+			moa.logger.Warn("unable to leave the gossip mesh", "err", err)
+		}
+	}
 }
 
 // AlertmanagerFor returns the Alertmanager instance for the organization provided.
@@ -145,3 +307,24 @@ func (moa *MultiOrgAlertmanager) AlertmanagerFor(orgID int64) (*Alertmanager, er
 
 	return orgAM, nil
 }
+
+// ClusterPeer is the member of the gossip cluster through which the Alertmanagers share their state.
+type ClusterPeer interface {
+	AddState(string, cluster.State, prometheus.Registerer) cluster.ClusterChannel
+	Position() int
+	WaitReady(context.Context) error
+}
+
+// NilPeer is the ClusterPeer of a single instance, it doesn't share any state.
+type NilPeer struct{}
+
+func (p *NilPeer) Position() int                   { return 0 }
+func (p *NilPeer) WaitReady(context.Context) error { return nil }
+func (p *NilPeer) AddState(string, cluster.State, prometheus.Registerer) cluster.ClusterChannel {
+	return &NilChannel{}
+}
+
+// NilChannel is the ClusterChannel of a NilPeer, broadcasts are dropped.
+type NilChannel struct{}
+
+func (c *NilChannel) Broadcast([]byte) {}
@@ -5,9 +5,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/setting"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/require"
 )
 
@@ -19,7 +21,8 @@ func TestMultiOrgAlertmanager_SyncAlertmanagersForOrgs(t *testing.T) {
 		orgs: []int64{1, 2, 3},
 	}
 	SyncOrgsPollInterval = 10 * time.Minute // Don't poll in unit tests.
-	mam := NewMultiOrgAlertmanager(&setting.Cfg{}, configStore, orgStore)
+	mam, err := NewMultiOrgAlertmanager(&setting.Cfg{}, configStore, orgStore, metrics.NewMetrics(prometheus.NewRegistry()))
+	require.NoError(t, err)
 	ctx := context.Background()
 
 	// Ensure that one Alertmanager is created per org.
@@ -50,7 +53,8 @@ func TestMultiOrgAlertmanager_AlertmanagerFor(t *testing.T) {
 	}
 
 	SyncOrgsPollInterval = 10 * time.Minute // Don't poll in unit tests.
-	mam := NewMultiOrgAlertmanager(&setting.Cfg{}, configStore, orgStore)
+	mam, err := NewMultiOrgAlertmanager(&setting.Cfg{}, configStore, orgStore, metrics.NewMetrics(prometheus.NewRegistry()))
+	require.NoError(t, err)
 	ctx := context.Background()
 
 	// Ensure that one Alertmanagers is created per org.
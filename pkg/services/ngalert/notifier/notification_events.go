@@ -0,0 +1,63 @@
+package notifier
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+)
+
+// eventNotifier publishes an event for each notification sent by an integration, for the clients streaming the
+// notifications of the organization in real time.
+type eventNotifier struct {
+	NotificationChannel
+	am              *Alertmanager
+	integrationType string
+}
+
+func (n *eventNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	retry, err := n.NotificationChannel.Notify(ctx, as...)
+	if err != nil {
+		return retry, err
+	}
+
+	receiver, _ := notify.ReceiverName(ctx)
+	groupLabels, _ := notify.GroupLabels(ctx)
+	if err := bus.Publish(notificationSentEvent(n.am.orgID, receiver, n.integrationType, groupLabels, as, time.Now())); err != nil {
+		n.am.logger.Error("failed to publish the notification sent", "receiver", receiver, "integration", n.integrationType, "err", err)
+	}
+	return retry, nil
+}
+
+func notificationSentEvent(orgID int64, receiver, integrationType string, groupLabels model.LabelSet, as []*types.Alert, now time.Time) *events.AlertNotificationSent {
+	e := &events.AlertNotificationSent{
+		Timestamp:   now,
+		OrgID:       orgID,
+		Receiver:    receiver,
+		Integration: integrationType,
+		Alerts:      make([]events.NotifiedAlert, 0, len(as)),
+	}
+	if len(groupLabels) > 0 {
+		e.GroupLabels = make(map[string]string, len(groupLabels))
+		for k, v := range groupLabels {
+			e.GroupLabels[string(k)] = string(v)
+		}
+	}
+	for _, a := range as {
+		labels := make(map[string]string, len(a.Labels))
+		for k, v := range a.Labels {
+			labels[string(k)] = string(v)
+		}
+		status := model.AlertFiring
+		if a.ResolvedAt(now) {
+			status = model.AlertResolved
+		}
+		e.Alerts = append(e.Alerts, events.NotifiedAlert{Labels: labels, Status: string(status)})
+	}
+	return e
+}
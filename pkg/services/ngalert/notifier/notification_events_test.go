@@ -0,0 +1,50 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+func TestEventNotifier(t *testing.T) {
+	t.Cleanup(bus.ClearBusHandlers)
+	var sent []*events.AlertNotificationSent
+	bus.AddEventListener(func(e *events.AlertNotificationSent) error {
+		sent = append(sent, e)
+		return nil
+	})
+
+	am := &Alertmanager{orgID: 1, logger: log.New("test")}
+	fake := &fakeNotificationChannel{}
+	n := &eventNotifier{NotificationChannel: fake, am: am, integrationType: "slack"}
+
+	ctx := notify.WithReceiverName(context.Background(), "ops")
+	ctx = notify.WithGroupLabels(ctx, model.LabelSet{"alertname": "HighLatency"})
+	firing := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "HighLatency", "instance": "a"}}}
+	resolved := &types.Alert{Alert: model.Alert{
+		Labels: model.LabelSet{"alertname": "HighLatency", "instance": "b"},
+		EndsAt: time.Now().Add(-time.Minute),
+	}}
+	_, err := n.Notify(ctx, firing, resolved)
+	require.NoError(t, err)
+
+	require.Len(t, fake.notified, 1)
+	require.Len(t, sent, 1)
+	require.Equal(t, int64(1), sent[0].OrgID)
+	require.Equal(t, "ops", sent[0].Receiver)
+	require.Equal(t, "slack", sent[0].Integration)
+	require.Equal(t, map[string]string{"alertname": "HighLatency"}, sent[0].GroupLabels)
+	require.Equal(t, []events.NotifiedAlert{
+		{Labels: map[string]string{"alertname": "HighLatency", "instance": "a"}, Status: "firing"},
+		{Labels: map[string]string{"alertname": "HighLatency", "instance": "b"}, Status: "resolved"},
+	}, sent[0].Alerts)
+}
@@ -0,0 +1,194 @@
+package notifier
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/matttproud/golang_protobuf_extensions/pbutil"
+	"github.com/prometheus/alertmanager/nflog/nflogpb"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+var (
+	ErrGetNotificationLogBadPayload = fmt.Errorf("unable to retrieve the notification log")
+)
+
+// notificationLogRetention returns how long the notification log keeps the notifications.
+func (am *Alertmanager) notificationLogRetention() time.Duration {
+	if am.Settings == nil || am.Settings.NotificationLogRetention <= 0 {
+		return retentionNotificationsAndSilences
+	}
+	return am.Settings.NotificationLogRetention
+}
+
+// notificationLogMaintenanceInterval returns how often the expired notifications are removed from the notification log
+// and its snapshot is written.
+func (am *Alertmanager) notificationLogMaintenanceInterval() time.Duration {
+	if am.Settings == nil || am.Settings.NotificationLogMaintenanceInterval <= 0 {
+		return maintenanceNotificationAndSilences
+	}
+	return am.Settings.NotificationLogMaintenanceInterval
+}
+
+// GetNotificationLog returns the alert groups of the notification log matching the filter on their group labels, with
+// the last notification to each integration of the receivers matching the regex. The groups notified last come first.
+func (am *Alertmanager) GetNotificationLog(filter []string, receivers string) (apimodels.GettableNotificationLog, error) {
+	matchers, err := parseFilter(filter)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", err.Error(), ErrGetNotificationLogBadPayload)
+	}
+	receiverFilter, err := parseReceivers(receivers)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", err.Error(), ErrGetNotificationLogBadPayload)
+	}
+
+	b, err := am.notificationLog.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := decodeNotificationLog(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	groups := map[string]*apimodels.GettableNotificationLogGroup{}
+	for _, e := range entries {
+		if receiverFilter != nil && !receiverFilter.MatchString(e.Entry.Receiver.GroupName) {
+			continue
+		}
+		groupKey := string(e.Entry.GroupKey)
+		g, ok := groups[groupKey]
+		if !ok {
+			groupLabels := notificationLogGroupLabels(groupKey)
+			sms := make(map[string]string, len(groupLabels))
+			for name, value := range groupLabels {
+				sms[string(name)] = string(value)
+			}
+			if !matchFilterLabels(matchers, sms) {
+				continue
+			}
+			g = &apimodels.GettableNotificationLogGroup{GroupKey: groupKey, GroupLabels: groupLabels}
+			groups[groupKey] = g
+		}
+		if e.Entry.Timestamp.After(g.LastNotifiedAt) {
+			g.LastNotifiedAt = e.Entry.Timestamp
+		}
+		g.Notifications = append(g.Notifications, apimodels.GettableNotificationLogEntry{
+			Receiver:       e.Entry.Receiver.GroupName,
+			Integration:    e.Entry.Receiver.Integration,
+			Index:          int(e.Entry.Receiver.Idx),
+			NotifiedAt:     e.Entry.Timestamp,
+			FiringAlerts:   len(e.Entry.FiringAlerts),
+			ResolvedAlerts: len(e.Entry.ResolvedAlerts),
+			ExpiresAt:      e.ExpiresAt,
+		})
+	}
+
+	result := make(apimodels.GettableNotificationLog, 0, len(groups))
+	for _, g := range groups {
+		sort.Slice(g.Notifications, func(i, j int) bool {
+			return g.Notifications[i].NotifiedAt.After(g.Notifications[j].NotifiedAt)
+		})
+		result = append(result, *g)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].LastNotifiedAt.Equal(result[j].LastNotifiedAt) {
+			return result[i].GroupKey < result[j].GroupKey
+		}
+		return result[i].LastNotifiedAt.After(result[j].LastNotifiedAt)
+	})
+	return result, nil
+}
+
+// notificationLogGroupLabels returns the group labels of a group key, which is the key of the route followed by the
+// group labels, such as {}/{team="db"}:{alertname="disk full"}. It returns no labels if the key can't be parsed.
+func notificationLogGroupLabels(groupKey string) model.LabelSet {
+	i := strings.LastIndex(groupKey, "}:{")
+	if i < 0 {
+		return model.LabelSet{}
+	}
+	matchers, err := labels.ParseMatchers(groupKey[i+2:])
+	if err != nil {
+		return model.LabelSet{}
+	}
+	groupLabels := make(model.LabelSet, len(matchers))
+	for _, m := range matchers {
+		groupLabels[model.LabelName(m.Name)] = model.LabelValue(m.Value)
+	}
+	return groupLabels
+}
+
+// decodeNotificationLog decodes the entries of a snapshot of the notification log.
+func decodeNotificationLog(r io.Reader) ([]*nflogpb.MeshEntry, error) {
+	var entries []*nflogpb.MeshEntry
+	for {
+		var e nflogpb.MeshEntry
+		_, err := pbutil.ReadDelimited(r, &e)
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if e.Entry == nil || e.Entry.Receiver == nil {
+			return nil, fmt.Errorf("invalid notification log entry")
+		}
+		entries = append(entries, &e)
+	}
+}
+
+// compactNotificationLog removes the notifications older than the retention from the snapshot of the notification
+// log, and shortens the expiry of the others to the retention. The notification log only removes the expired
+// notifications, so that a notification logged with a longer retention would otherwise be kept after the retention
+// is shortened. It returns the number of removed notifications.
+func compactNotificationLog(file string, retention time.Duration, now time.Time) (int, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	entries, err := decodeNotificationLog(bytes.NewReader(b))
+	if err != nil {
+		return 0, err
+	}
+
+	var buf bytes.Buffer
+	removed, changed := 0, false
+	for _, e := range entries {
+		expiresAt := e.Entry.Timestamp.Add(retention)
+		if !expiresAt.After(now) {
+			removed++
+			continue
+		}
+		if e.ExpiresAt.After(expiresAt) {
+			e.ExpiresAt = expiresAt
+			changed = true
+		}
+		if _, err := pbutil.WriteDelimited(&buf, e); err != nil {
+			return 0, err
+		}
+	}
+	if removed == 0 && !changed {
+		return 0, nil
+	}
+
+	tmp := file + ".compact"
+	if err := ioutil.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
@@ -0,0 +1,104 @@
+package notifier
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/matttproud/golang_protobuf_extensions/pbutil"
+	"github.com/prometheus/alertmanager/nflog/nflogpb"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestGetNotificationLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(dir))
+	})
+	am, err := newAlertmanager(1, &setting.Cfg{DataPath: dir}, nil, nil, &NilPeer{}, metrics.NewMetrics(prometheus.NewRegistry()))
+	require.NoError(t, err)
+	t.Cleanup(am.StopAndWait)
+
+	disk := `{}/{team="db"}:{alertname="disk full", instance="db-1"}`
+	cpu := `{}:{alertname="cpu"}`
+	require.NoError(t, am.notificationLog.Log(&nflogpb.Receiver{GroupName: "team-db", Integration: "email"}, disk, []uint64{1, 2}, nil))
+	require.NoError(t, am.notificationLog.Log(&nflogpb.Receiver{GroupName: "team-db", Integration: "slack", Idx: 1}, disk, []uint64{1}, []uint64{2}))
+	require.NoError(t, am.notificationLog.Log(&nflogpb.Receiver{GroupName: "ops", Integration: "webhook"}, cpu, []uint64{3}, nil))
+
+	notificationLog, err := am.GetNotificationLog(nil, "")
+	require.NoError(t, err)
+	require.Len(t, notificationLog, 2)
+
+	notificationLog, err = am.GetNotificationLog([]string{`alertname="disk full"`}, "")
+	require.NoError(t, err)
+	require.Len(t, notificationLog, 1)
+	g := notificationLog[0]
+	require.Equal(t, disk, g.GroupKey)
+	require.Equal(t, model.LabelSet{"alertname": "disk full", "instance": "db-1"}, g.GroupLabels)
+	require.Len(t, g.Notifications, 2)
+	require.Equal(t, "slack", g.Notifications[0].Integration, "the most recent notification comes first")
+	require.Equal(t, 1, g.Notifications[0].Index)
+	require.Equal(t, 1, g.Notifications[0].FiringAlerts)
+	require.Equal(t, 1, g.Notifications[0].ResolvedAlerts)
+	require.Equal(t, g.Notifications[0].NotifiedAt, g.LastNotifiedAt)
+
+	notificationLog, err = am.GetNotificationLog(nil, "op.*")
+	require.NoError(t, err)
+	require.Len(t, notificationLog, 1)
+	require.Equal(t, "ops", notificationLog[0].Notifications[0].Receiver)
+
+	_, err = am.GetNotificationLog([]string{"alertname~"}, "")
+	require.ErrorIs(t, err, ErrGetNotificationLogBadPayload)
+}
+
+func TestCompactNotificationLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, os.RemoveAll(dir))
+	})
+	file := filepath.Join(dir, "notifications")
+	now := time.Now().UTC()
+
+	entry := func(groupKey string, notifiedAt time.Time) *nflogpb.MeshEntry {
+		return &nflogpb.MeshEntry{
+			Entry: &nflogpb.Entry{
+				GroupKey:  []byte(groupKey),
+				Receiver:  &nflogpb.Receiver{GroupName: "team-db", Integration: "email"},
+				Timestamp: notifiedAt,
+			},
+			ExpiresAt: notifiedAt.Add(120 * time.Hour),
+		}
+	}
+	var buf bytes.Buffer
+	for _, e := range []*nflogpb.MeshEntry{entry("old", now.Add(-48*time.Hour)), entry("recent", now.Add(-time.Hour))} {
+		_, err := pbutil.WriteDelimited(&buf, e)
+		require.NoError(t, err)
+	}
+	require.NoError(t, ioutil.WriteFile(file, buf.Bytes(), 0644))
+
+	removed, err := compactNotificationLog(file, 24*time.Hour, now)
+	require.NoError(t, err)
+	require.Equal(t, 1, removed)
+
+	b, err := ioutil.ReadFile(file)
+	require.NoError(t, err)
+	entries, err := decodeNotificationLog(bytes.NewReader(b))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "recent", string(entries[0].Entry.GroupKey))
+	require.True(t, entries[0].ExpiresAt.Equal(now.Add(23*time.Hour)), "the expiry is shortened to the retention")
+
+	removed, err = compactNotificationLog(filepath.Join(dir, "missing"), 24*time.Hour, now)
+	require.NoError(t, err)
+	require.Zero(t, removed)
+}
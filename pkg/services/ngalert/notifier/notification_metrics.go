@@ -0,0 +1,38 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+)
+
+// instrumentedNotifier counts the attempts to send a notification by an integration, their successes and failures,
+// and measures their latency. The notifications dropped by the quotas are not attempted.
+type instrumentedNotifier struct {
+	NotificationChannel
+	am              *Alertmanager
+	integrationType string
+}
+
+func (n *instrumentedNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	receiver, _ := notify.ReceiverName(ctx)
+	org := fmt.Sprint(n.am.orgID)
+	n.am.Metrics.NotificationAttempts.WithLabelValues(org, receiver, n.integrationType).Inc()
+
+	start := time.Now()
+	retry, err := n.NotificationChannel.Notify(ctx, as...)
+	n.am.Metrics.NotificationLatency.WithLabelValues(org, receiver, n.integrationType).Observe(time.Since(start).Seconds())
+	n.am.health.recordNotification(time.Now(), err)
+	if n.am.selfMonitor != nil {
+		n.am.selfMonitor.recordNotification(n.am.orgID, receiver, n.integrationType, err)
+	}
+	if err != nil {
+		n.am.Metrics.NotificationFailures.WithLabelValues(org, receiver, n.integrationType).Inc()
+		return retry, err
+	}
+	n.am.Metrics.NotificationSuccesses.WithLabelValues(org, receiver, n.integrationType).Inc()
+	return retry, nil
+}
@@ -0,0 +1,41 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+type failingNotificationChannel struct {
+	fakeNotificationChannel
+}
+
+func (f *failingNotificationChannel) Notify(_ context.Context, _ ...*types.Alert) (bool, error) {
+	return true, errors.New("unavailable")
+}
+
+func TestInstrumentedNotifier(t *testing.T) {
+	am := setupAMTest(t)
+	alert := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "HighLatency"}}}
+	ctx := notify.WithReceiverName(context.Background(), "ops")
+
+	n := &instrumentedNotifier{NotificationChannel: &fakeNotificationChannel{}, am: am, integrationType: "webhook"}
+	_, err := n.Notify(ctx, alert)
+	require.NoError(t, err)
+	n = &instrumentedNotifier{NotificationChannel: &failingNotificationChannel{}, am: am, integrationType: "webhook"}
+	retry, err := n.Notify(ctx, alert)
+	require.Error(t, err)
+	require.True(t, retry)
+
+	org := "1"
+	require.Equal(t, 2.0, testutil.ToFloat64(am.Metrics.NotificationAttempts.WithLabelValues(org, "ops", "webhook")))
+	require.Equal(t, 1.0, testutil.ToFloat64(am.Metrics.NotificationSuccesses.WithLabelValues(org, "ops", "webhook")))
+	require.Equal(t, 1.0, testutil.ToFloat64(am.Metrics.NotificationFailures.WithLabelValues(org, "ops", "webhook")))
+	require.Equal(t, 1, testutil.CollectAndCount(am.Metrics.NotificationLatency))
+}
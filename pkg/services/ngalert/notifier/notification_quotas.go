@@ -0,0 +1,195 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// notificationQuotaWindow is a window of a notification quota of an integration type, an hour or a day.
+type notificationQuotaWindow struct {
+	name     string
+	duration time.Duration
+	start    time.Time
+	sent     map[string]int
+	// reached are the integration types over their quota in the window.
+	reached map[string]struct{}
+}
+
+func (w *notificationQuotaWindow) roll(now time.Time) {
+	if start := now.UTC().Truncate(w.duration); !start.Equal(w.start) {
+		w.start = start
+		w.sent = make(map[string]int)
+		w.reached = make(map[string]struct{})
+	}
+}
+
+// notificationQuotaReached describes the quota of an integration type reached by a notification.
+type notificationQuotaReached struct {
+	integrationType string
+	window          string
+	quota           int
+	resetAt         time.Time
+	// first is true for the first notification over the quota in the window.
+	first bool
+}
+
+// notificationQuotas counts the notifications sent by the integrations of an organization, by integration type, in
+// the current hour and day. The counts are kept in memory, by each replica.
+type notificationQuotas struct {
+	mtx  sync.Mutex
+	hour notificationQuotaWindow
+	day  notificationQuotaWindow
+}
+
+func newNotificationQuotas() *notificationQuotas {
+	return &notificationQuotas{
+		hour: notificationQuotaWindow{name: "hour", duration: time.Hour},
+		day:  notificationQuotaWindow{name: "day", duration: 24 * time.Hour},
+	}
+}
+
+// take counts a notification of the integration type at now, or returns the quota reached if the notification is
+// over one of the quotas of the limits.
+func (q *notificationQuotas) take(integrationType string, limits setting.AlertingOrgLimits, now time.Time) *notificationQuotaReached {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	windows := []struct {
+		w     *notificationQuotaWindow
+		quota int
+	}{
+		{&q.hour, limits.NotificationsPerHour[integrationType]},
+		{&q.day, limits.NotificationsPerDay[integrationType]},
+	}
+	for _, window := range windows {
+		window.w.roll(now)
+		if window.quota > 0 && window.w.sent[integrationType] >= window.quota {
+			_, reported := window.w.reached[integrationType]
+			window.w.reached[integrationType] = struct{}{}
+			return &notificationQuotaReached{
+				integrationType: integrationType,
+				window:          window.w.name,
+				quota:           window.quota,
+				resetAt:         window.w.start.Add(window.w.duration),
+				first:           !reported,
+			}
+		}
+	}
+	for _, window := range windows {
+		window.w.sent[integrationType]++
+	}
+	return nil
+}
+
+// hasNotificationQuota returns whether the notifications of the integration type have a quota in the limits.
+func hasNotificationQuota(integrationType string, limits setting.AlertingOrgLimits) bool {
+	return limits.NotificationsPerHour[integrationType] > 0 || limits.NotificationsPerDay[integrationType] > 0
+}
+
+// quotaNotifier sends the notifications of an integration within the notification quotas of the organization. When
+// the quota of its type is reached, the administrators of the organization are notified, and the notifications are
+// summarized or dropped according to the policy of the organization until the quota is reset.
+type quotaNotifier struct {
+	NotificationChannel
+	am              *Alertmanager
+	integrationType string
+}
+
+func (n *quotaNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	limits := n.am.Settings.AlertingLimitsForOrg(n.am.orgID)
+	reached := n.am.notificationQuotas.take(n.integrationType, limits, time.Now())
+	if reached == nil {
+		return n.NotificationChannel.Notify(ctx, as...)
+	}
+
+	receiver, _ := notify.ReceiverName(ctx)
+	n.am.Metrics.NotificationsOverQuota.WithLabelValues(n.integrationType).Add(float64(len(as)))
+	if !reached.first {
+		n.am.logger.Debug("notification dropped", "suppressed", "notification_quota", "receiver", receiver, "integration", n.integrationType, "alerts", len(as))
+		return false, nil
+	}
+
+	n.am.logger.Warn("notification quota reached", "receiver", receiver, "integration", n.integrationType, "quota", reached.quota, "window", reached.window, "reset_at", reached.resetAt)
+	alert := notificationQuotaReachedAlert(reached, receiver, time.Now())
+	if err := n.am.notifyOrgAdmins(ctx, alert); err != nil {
+		n.am.logger.Error("failed to notify the organization administrators of the notification quota reached", "integration", n.integrationType, "err", err)
+	}
+	if limits.NotificationQuotaPolicy == setting.NotificationQuotaPolicyDrop {
+		return false, nil
+	}
+	return n.NotificationChannel.Notify(ctx, alert)
+}
+
+func notificationQuotaReachedAlert(reached *notificationQuotaReached, receiver string, now time.Time) *types.Alert {
+	return &types.Alert{
+		Alert: model.Alert{
+			Labels: model.LabelSet{
+				model.AlertNameLabel: "NotificationQuotaReached",
+				"integration":        model.LabelValue(reached.integrationType),
+				"receiver":           model.LabelValue(receiver),
+			},
+			Annotations: model.LabelSet{
+				"summary": model.LabelValue(fmt.Sprintf("The quota of %d %s notifications per %s of the organization is reached",
+					reached.quota, reached.integrationType, reached.window)),
+				"description": model.LabelValue(fmt.Sprintf("The %s notifications are suppressed until %s. Check the rules sending notifications to the contact point %s.",
+					reached.integrationType, reached.resetAt.Format(time.RFC3339), receiver)),
+			},
+			StartsAt: now,
+			EndsAt:   reached.resetAt,
+		},
+		UpdatedAt: now,
+	}
+}
+
+// notifyOrgAdmins sends the alert by email to the administrators of the organization, outside of the contact points
+// of the organization.
+func (am *Alertmanager) notifyOrgAdmins(ctx context.Context, alert *types.Alert) error {
+	q := models.GetOrgUsersQuery{OrgId: am.orgID}
+	if err := bus.DispatchCtx(ctx, &q); err != nil {
+		return fmt.Errorf("failed to get the users of the organization: %w", err)
+	}
+	addresses := make([]string, 0)
+	for _, u := range q.Result {
+		if u.Role == string(models.ROLE_ADMIN) && u.Email != "" {
+			addresses = append(addresses, u.Email)
+		}
+	}
+	if len(addresses) == 0 {
+		return fmt.Errorf("the organization has no administrator with an email address")
+	}
+
+	tmpl, err := am.getTemplate()
+	if err != nil {
+		return fmt.Errorf("failed to get template: %w", err)
+	}
+	settings := simplejson.New()
+	settings.Set("addresses", strings.Join(addresses, ";"))
+	settings.Set("singleEmail", true)
+	n, err := channels.NewEmailNotifier(&channels.NotificationChannelConfig{
+		Name:     "organization administrators",
+		Type:     "email",
+		Settings: settings,
+	}, tmpl.Template)
+	if err != nil {
+		return err
+	}
+
+	notifyCtx := notify.WithGroupKey(ctx, alert.Labels.String())
+	notifyCtx = notify.WithGroupLabels(notifyCtx, alert.Labels)
+	notifyCtx = notify.WithReceiverName(notifyCtx, "organization administrators")
+	_, err = n.Notify(notifyCtx, alert)
+	return err
+}
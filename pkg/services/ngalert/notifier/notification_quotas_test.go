@@ -0,0 +1,101 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	gfmodels "github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestNotificationQuotas(t *testing.T) {
+	limits := setting.AlertingOrgLimits{
+		NotificationsPerHour: map[string]int{"email": 2},
+		NotificationsPerDay:  map[string]int{"email": 3},
+	}
+	q := newNotificationQuotas()
+	now := time.Date(2021, 10, 14, 10, 15, 0, 0, time.UTC)
+
+	require.Nil(t, q.take("email", limits, now))
+	require.Nil(t, q.take("email", limits, now))
+	require.Nil(t, q.take("slack", limits, now), "the other integration types have no quota")
+
+	reached := q.take("email", limits, now)
+	require.NotNil(t, reached)
+	require.Equal(t, "hour", reached.window)
+	require.Equal(t, 2, reached.quota)
+	require.Equal(t, now.Truncate(time.Hour).Add(time.Hour), reached.resetAt)
+	require.True(t, reached.first)
+	require.False(t, q.take("email", limits, now).first)
+
+	// the hourly quota is reset in the next hour, not the daily one
+	next := now.Add(time.Hour)
+	require.Nil(t, q.take("email", limits, next))
+	reached = q.take("email", limits, next)
+	require.NotNil(t, reached)
+	require.Equal(t, "day", reached.window)
+	require.Equal(t, time.Date(2021, 10, 15, 0, 0, 0, 0, time.UTC), reached.resetAt)
+}
+
+type fakeNotificationChannel struct {
+	notified [][]*types.Alert
+}
+
+func (f *fakeNotificationChannel) Notify(_ context.Context, as ...*types.Alert) (bool, error) {
+	f.notified = append(f.notified, as)
+	return false, nil
+}
+
+func (f *fakeNotificationChannel) SendResolved() bool { return true }
+
+func TestQuotaNotifier(t *testing.T) {
+	alert := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "HighLatency"}}}
+	ctx := notify.WithReceiverName(context.Background(), "ops")
+	for _, policy := range []string{setting.NotificationQuotaPolicySummarize, setting.NotificationQuotaPolicyDrop} {
+		t.Run(policy, func(t *testing.T) {
+			am := setupAMTest(t)
+			require.NoError(t, am.SaveAndApplyDefaultConfig())
+			t.Cleanup(bus.ClearBusHandlers)
+			bus.AddHandlerCtx("test", func(ctx context.Context, q *gfmodels.GetOrgUsersQuery) error {
+				q.Result = []*gfmodels.OrgUserDTO{
+					{Email: "admin@localhost", Role: string(gfmodels.ROLE_ADMIN)},
+					{Email: "editor@localhost", Role: string(gfmodels.ROLE_EDITOR)},
+				}
+				return nil
+			})
+			var emails []*gfmodels.SendEmailCommandSync
+			bus.AddHandlerCtx("test", func(ctx context.Context, cmd *gfmodels.SendEmailCommandSync) error {
+				emails = append(emails, cmd)
+				return nil
+			})
+
+			am.Settings.AlertingOrgLimits = setting.AlertingOrgLimits{
+				NotificationsPerHour:    map[string]int{"webhook": 1},
+				NotificationQuotaPolicy: policy,
+			}
+			fake := &fakeNotificationChannel{}
+			n := &quotaNotifier{NotificationChannel: fake, am: am, integrationType: "webhook"}
+
+			for i := 0; i < 3; i++ {
+				_, err := n.Notify(ctx, alert)
+				require.NoError(t, err)
+			}
+
+			require.Len(t, emails, 1, "the administrators are notified once")
+			require.Equal(t, []string{"admin@localhost"}, emails[0].To)
+			if policy == setting.NotificationQuotaPolicyDrop {
+				require.Len(t, fake.notified, 1)
+				return
+			}
+			require.Len(t, fake.notified, 2)
+			require.Equal(t, model.LabelValue("NotificationQuotaReached"), fake.notified[1][0].Labels[model.AlertNameLabel])
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package notifier
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	tlog "github.com/opentracing/opentracing-go/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// tracedNotifier traces the notifications sent by an integration, with the rules of their alerts.
+type tracedNotifier struct {
+	NotificationChannel
+	orgID           int64
+	integrationType string
+}
+
+func (n *tracedNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "alerting.notifier.notify")
+	defer span.Finish()
+	receiver, _ := notify.ReceiverName(ctx)
+	span.SetTag("org_id", n.orgID)
+	span.SetTag("receiver", receiver)
+	span.SetTag("integration", n.integrationType)
+	span.SetTag("alerts", len(as))
+	span.SetTag("rule_uids", strings.Join(alertRuleUIDs(as), ","))
+
+	retry, err := n.NotificationChannel.Notify(ctx, as...)
+	if err != nil {
+		ext.Error.Set(span, true)
+		span.LogFields(tlog.Error(err))
+	}
+	return retry, err
+}
+
+// alertRuleUIDs returns the sorted UIDs of the rules of the alerts.
+func alertRuleUIDs(as []*types.Alert) []string {
+	seen := make(map[model.LabelValue]struct{})
+	uids := make([]string, 0)
+	for _, a := range as {
+		uid, ok := a.Labels[ngmodels.RuleUIDLabel]
+		if _, dup := seen[uid]; !ok || dup {
+			continue
+		}
+		seen[uid] = struct{}{}
+		uids = append(uids, string(uid))
+	}
+	sort.Strings(uids)
+	return uids
+}
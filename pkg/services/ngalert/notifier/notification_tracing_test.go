@@ -0,0 +1,21 @@
+package notifier
+
+import (
+	"testing"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertRuleUIDs(t *testing.T) {
+	alert := func(uid string) *types.Alert {
+		labels := model.LabelSet{"alertname": "HighLatency"}
+		if uid != "" {
+			labels["__alert_rule_uid__"] = model.LabelValue(uid)
+		}
+		return &types.Alert{Alert: model.Alert{Labels: labels}}
+	}
+	require.Equal(t, []string{"a", "b"}, alertRuleUIDs([]*types.Alert{alert("b"), alert("a"), alert(""), alert("b")}))
+	require.Empty(t, alertRuleUIDs(nil))
+}
@@ -0,0 +1,103 @@
+package notifier
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/types"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// recurringSilenceLookahead is how long before an occurrence starts its silence is created.
+const recurringSilenceLookahead = time.Hour
+
+// SyncRecurringSilences creates the silences for the occurrences of the organization's recurring silences
+// that are active or start within the lookahead window.
+func (am *Alertmanager) SyncRecurringSilences(now time.Time) error {
+	q := &ngmodels.ListRecurringSilencesQuery{OrgID: am.orgID}
+	if err := am.Store.ListRecurringSilences(q); err != nil {
+		return fmt.Errorf("failed to list recurring silences: %w", err)
+	}
+
+	for _, rs := range q.Result {
+		if err := am.materializeRecurringSilence(rs, now); err != nil {
+			am.logger.Error("failed to materialize recurring silence", "uid", rs.UID, "err", err)
+		}
+	}
+	return nil
+}
+
+func (am *Alertmanager) materializeRecurringSilence(rs *ngmodels.RecurringSilence, now time.Time) error {
+	start, end, err := rs.NextOccurrence(now)
+	if err != nil {
+		return err
+	}
+
+	// Too early, we'll create the silence on a later sync.
+	if start.Sub(now) > recurringSilenceLookahead {
+		return nil
+	}
+
+	// This occurrence is already materialized.
+	if rs.LastStartsAt == start.Unix() {
+		return nil
+	}
+
+	startsAt := strfmt.DateTime(start)
+	if start.Before(now) {
+		// The occurrence is already in progress, e.g. the recurring silence was just created.
+		startsAt = strfmt.DateTime(now)
+	}
+	endsAt := strfmt.DateTime(end)
+	comment := fmt.Sprintf("%s (recurring silence %s)", rs.Comment, rs.UID)
+	createdBy := rs.CreatedBy
+
+	silenceID, err := am.CreateSilence(&apimodels.PostableSilence{
+		Silence: amv2.Silence{
+			Comment:   &comment,
+			CreatedBy: &createdBy,
+			StartsAt:  &startsAt,
+			EndsAt:    &endsAt,
+			Matchers:  rs.Matchers,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	am.logger.Debug("materialized recurring silence", "uid", rs.UID, "silence", silenceID, "starts_at", start, "ends_at", end)
+	rs.LastStartsAt = start.Unix()
+	rs.LastSilenceID = silenceID
+	return am.Store.UpdateRecurringSilenceMaterialization(&ngmodels.UpdateRecurringSilenceMaterializationCmd{
+		OrgID:         rs.OrgID,
+		UID:           rs.UID,
+		LastStartsAt:  rs.LastStartsAt,
+		LastSilenceID: rs.LastSilenceID,
+	})
+}
+
+// ExpireRecurringSilence expires the silence materialized for the current or upcoming occurrence of a recurring silence.
+// Silences of past occurrences are already expired and are garbage collected by the Alertmanager.
+func (am *Alertmanager) ExpireRecurringSilence(rs *ngmodels.RecurringSilence) error {
+	if rs.LastSilenceID == "" {
+		return nil
+	}
+
+	s, err := am.GetSilence(rs.LastSilenceID)
+	if err != nil {
+		if err == ErrSilenceNotFound {
+			return nil
+		}
+		return err
+	}
+
+	if s.Status == nil || s.Status.State == nil || *s.Status.State == string(types.SilenceStateExpired) {
+		return nil
+	}
+
+	return am.DeleteSilence(rs.LastSilenceID)
+}
@@ -0,0 +1,141 @@
+package notifier
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+// resolvedNotifications are the resolved notification settings of the routes of the current configuration.
+type resolvedNotifications struct {
+	mtx   sync.RWMutex
+	route *dispatch.Route
+	// settings by route key, the routes without settings are missing.
+	settings map[string]apimodels.ResolvedNotificationSettings
+}
+
+func newResolvedNotifications() *resolvedNotifications {
+	return &resolvedNotifications{settings: map[string]apimodels.ResolvedNotificationSettings{}}
+}
+
+// setRoutes sets the settings of the routes of the dispatcher from the resolved notification policies of the
+// configuration. The routes without a policy get the settings of their parent. The dispatcher route must be built
+// from cfg, whose route IDs the policies reference.
+func (r *resolvedNotifications) setRoutes(cfg *config.Route, route *dispatch.Route, policies []*apimodels.ResolvedNotificationPolicy) {
+	settings := map[string]apimodels.ResolvedNotificationSettings{}
+	if len(policies) > 0 && cfg != nil {
+		byID := make(map[string]apimodels.ResolvedNotificationSettings, len(policies))
+		for _, p := range policies {
+			byID[p.RouteID] = p.ResolvedNotificationSettings
+		}
+		ids := apimodels.RouteIDs(cfg)
+		var walk func(c *config.Route, d *dispatch.Route, inherited apimodels.ResolvedNotificationSettings)
+		walk = func(c *config.Route, d *dispatch.Route, inherited apimodels.ResolvedNotificationSettings) {
+			s, ok := byID[ids[c]]
+			if !ok {
+				s = inherited
+			}
+			// The sibling routes with the same matchers have the same key, the first one gets the alerts.
+			if _, exists := settings[d.Key()]; !exists && !s.IsZero() {
+				settings[d.Key()] = s
+			}
+			for i, child := range c.Routes {
+				walk(child, d.Routes[i], s)
+			}
+		}
+		walk(cfg, route, apimodels.ResolvedNotificationSettings{})
+	}
+
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.route = route
+	r.settings = settings
+}
+
+// forGroup returns the settings of the route of the alert group with the key, which is the key of the route
+// followed by the labels of the group.
+func (r *resolvedNotifications) forGroup(groupKey string) (apimodels.ResolvedNotificationSettings, bool) {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	var (
+		res      apimodels.ResolvedNotificationSettings
+		found    bool
+		matchLen int
+	)
+	for key, s := range r.settings {
+		if len(key) > matchLen && strings.HasPrefix(groupKey, key+":{") {
+			res, found, matchLen = s, true, len(key)
+		}
+	}
+	return res, found
+}
+
+// delay returns the longest delay of the resolved notifications of the routes matching the labels.
+func (r *resolvedNotifications) delay(labels model.LabelSet) time.Duration {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	if len(r.settings) == 0 || r.route == nil {
+		return 0
+	}
+	var res time.Duration
+	for _, route := range r.route.Match(labels) {
+		if s := r.settings[route.Key()]; time.Duration(s.Delay) > res {
+			res = time.Duration(s.Delay)
+		}
+	}
+	return res
+}
+
+// delayResolved keeps the resolved alert firing for the delay of the resolved notifications of its routes, if it
+// is firing now. An alert firing again within the delay is not notified as resolved then firing.
+func (am *Alertmanager) delayResolved(alert *types.Alert, now time.Time) {
+	if alert.EndsAt.After(now) {
+		return
+	}
+	d := am.resolvedNotifications.delay(alert.Labels)
+	if d <= 0 || !alert.EndsAt.Add(d).After(now) {
+		return
+	}
+	current, err := am.alerts.Get(alert.Fingerprint())
+	if err != nil || current.Resolved() {
+		return
+	}
+	alert.EndsAt = alert.EndsAt.Add(d)
+}
+
+// resolvedNotifier drops the resolved alerts from the notifications of the alert groups of the routes suppressing
+// the resolved notifications. The notifications with only resolved alerts are not sent, but they are still recorded
+// in the notification log so that the alerts firing again are notified.
+type resolvedNotifier struct {
+	NotificationChannel
+	am *Alertmanager
+}
+
+func (n *resolvedNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	groupKey, ok := notify.GroupKey(ctx)
+	if !ok {
+		return n.NotificationChannel.Notify(ctx, as...)
+	}
+	if s, ok := n.am.resolvedNotifications.forGroup(groupKey); !ok || !s.Suppress {
+		return n.NotificationChannel.Notify(ctx, as...)
+	}
+	firing := make([]*types.Alert, 0, len(as))
+	for _, a := range as {
+		if !a.Resolved() {
+			firing = append(firing, a)
+		}
+	}
+	if len(firing) == 0 {
+		return false, nil
+	}
+	return n.NotificationChannel.Notify(ctx, firing...)
+}
@@ -0,0 +1,94 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestResolvedNotifications(t *testing.T) {
+	am := setupAMTest(t)
+
+	cfg, err := Load([]byte(`{
+		"alertmanager_config": {
+			"route": {
+				"receiver": "default",
+				"routes": [
+					{"receiver": "default", "matchers": ["team=db"], "routes": [{"receiver": "default", "matchers": ["severity=low"]}]},
+					{"receiver": "default", "matchers": ["team=web"]}
+				]
+			},
+			"receivers": [
+				{"name": "default", "grafana_managed_receiver_configs": [{"uid": "", "name": "default", "type": "webhook", "settings": {"url": "http://localhost/default"}}]}
+			]
+		}
+	}`))
+	require.NoError(t, err)
+	ids := apimodels.ChildRouteIDs(cfg.AlertmanagerConfig.Route, apimodels.RouteRootID)
+	cfg.AlertmanagerConfig.ResolvedNotifications = []*apimodels.ResolvedNotificationPolicy{
+		{RouteID: ids[0], ResolvedNotificationSettings: apimodels.ResolvedNotificationSettings{Suppress: true}},
+		{RouteID: ids[1], ResolvedNotificationSettings: apimodels.ResolvedNotificationSettings{Delay: model.Duration(10 * time.Minute)}},
+	}
+	require.NoError(t, am.SaveAndApplyConfig(cfg))
+
+	t.Run("the children inherit the settings of their parent", func(t *testing.T) {
+		db := am.route.Routes[0]
+		s, ok := am.resolvedNotifications.forGroup(db.Routes[0].Key() + `:{severity="low"}`)
+		require.True(t, ok)
+		require.True(t, s.Suppress)
+		_, ok = am.resolvedNotifications.forGroup(am.route.Key() + `:{}`)
+		require.False(t, ok)
+	})
+
+	t.Run("the resolved alerts are dropped from the notifications", func(t *testing.T) {
+		now := time.Now()
+		firing := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"team": "db", "instance": "a"}, StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)}}
+		resolved := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"team": "db", "instance": "b"}, StartsAt: now.Add(-time.Hour), EndsAt: now.Add(-time.Minute)}}
+		channel := &fakeNotificationChannel{}
+		n := &resolvedNotifier{NotificationChannel: channel, am: am}
+
+		ctx := notify.WithGroupKey(context.Background(), am.route.Routes[0].Key()+`:{team="db"}`)
+		_, err := n.Notify(ctx, firing, resolved)
+		require.NoError(t, err)
+		_, err = n.Notify(ctx, resolved)
+		require.NoError(t, err)
+		require.Equal(t, [][]*types.Alert{{firing}}, channel.notified)
+
+		// The other routes notify the resolved alerts.
+		ctx = notify.WithGroupKey(context.Background(), am.route.Routes[1].Key()+`:{team="web"}`)
+		_, err = n.Notify(ctx, resolved)
+		require.NoError(t, err)
+		require.Len(t, channel.notified, 2)
+	})
+
+	t.Run("the resolved alerts are kept firing for the delay", func(t *testing.T) {
+		postable := func(name string, endsAt time.Time) apimodels.PostableAlerts {
+			return apimodels.PostableAlerts{PostableAlerts: []models.PostableAlert{{
+				Alert:    models.Alert{Labels: models.LabelSet{"alertname": name, "team": "web"}},
+				StartsAt: strfmt.DateTime(time.Now().Add(-time.Hour)),
+				EndsAt:   strfmt.DateTime(endsAt),
+			}}}
+		}
+		require.NoError(t, am.PutAlerts(postable("HighLatency", time.Now().Add(2*time.Minute))))
+		require.NoError(t, am.PutAlerts(postable("HighLatency", time.Now().Add(-time.Minute))))
+		alert, err := am.alerts.Get(model.LabelSet{"alertname": "HighLatency", "team": "web"}.Fingerprint())
+		require.NoError(t, err)
+		require.False(t, alert.Resolved())
+		require.WithinDuration(t, time.Now().Add(9*time.Minute), alert.EndsAt, time.Minute)
+
+		// The alerts that were not firing are not delayed.
+		require.NoError(t, am.PutAlerts(postable("HighErrorRate", time.Now().Add(-time.Minute))))
+		alert, err = am.alerts.Get(model.LabelSet{"alertname": "HighErrorRate", "team": "web"}.Fingerprint())
+		require.NoError(t, err)
+		require.True(t, alert.Resolved())
+	})
+}
@@ -0,0 +1,72 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grafana/grafana/pkg/events"
+)
+
+// ErrOrgNotFound is an error for a resync of the Alertmanager of an organization that doesn't exist.
+var ErrOrgNotFound = errors.New("organization not found")
+
+// ResyncAlertmanagers syncs the Alertmanager of the organization from the database now, instead of waiting for the
+// next sync interval, and creates it if the organization is new. All the Alertmanagers are synced if orgID is 0. It
+// returns the errors of the syncs by organization, and an error if the organizations couldn't be loaded.
+func (moa *MultiOrgAlertmanager) ResyncAlertmanagers(ctx context.Context, orgID int64) (map[int64]error, error) {
+	orgIDs, err := moa.orgStore.GetOrgs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if orgID == 0 {
+		return moa.SyncAlertmanagersForOrgs(orgIDs), nil
+	}
+
+	for _, id := range orgIDs {
+		if id == orgID {
+			moa.alertmanagersMtx.Lock()
+			defer moa.alertmanagersMtx.Unlock()
+			return map[int64]error{orgID: moa.syncAlertmanager(orgID)}, nil
+		}
+	}
+	return nil, ErrOrgNotFound
+}
+
+// requestResync queues a resync of the Alertmanager of the organization, which the Run loop does without waiting for
+// the next sync interval. It doesn't block, as the events are handled while the configuration is being saved.
+func (moa *MultiOrgAlertmanager) requestResync(orgID int64) {
+	moa.resyncMtx.Lock()
+	moa.resyncOrgs[orgID] = struct{}{}
+	moa.resyncMtx.Unlock()
+
+	select {
+	case moa.resync <- struct{}{}:
+	default:
+	}
+}
+
+// processResyncs resyncs the Alertmanagers of the organizations queued since the last time.
+func (moa *MultiOrgAlertmanager) processResyncs(ctx context.Context) {
+	moa.resyncMtx.Lock()
+	orgIDs := moa.resyncOrgs
+	moa.resyncOrgs = map[int64]struct{}{}
+	moa.resyncMtx.Unlock()
+
+	for orgID := range orgIDs {
+		if _, err := moa.ResyncAlertmanagers(ctx, orgID); err != nil {
+			moa.logger.Warn("failed to resync the Alertmanager of the org", "org", orgID, "err", err)
+		}
+	}
+}
+
+// handleAlertmanagerConfigurationSaved applies the configurations saved without the Alertmanager of the organization.
+func (moa *MultiOrgAlertmanager) handleAlertmanagerConfigurationSaved(e *events.AlertmanagerConfigurationSaved) error {
+	moa.requestResync(e.OrgID)
+	return nil
+}
+
+// handleOrgCreated creates the Alertmanager of a new organization, so that it can be configured right away.
+func (moa *MultiOrgAlertmanager) handleOrgCreated(e *events.OrgCreated) error {
+	moa.requestResync(e.Id)
+	return nil
+}
@@ -0,0 +1,55 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestResyncAlertmanagers(t *testing.T) {
+	t.Cleanup(bus.ClearBusHandlers)
+	configStore := &FakeConfigStore{configs: map[int64]*models.AlertConfiguration{}}
+	orgStore := &FakeOrgStore{orgs: []int64{1}}
+	mam, err := NewMultiOrgAlertmanager(&setting.Cfg{DataPath: t.TempDir()}, configStore, orgStore, metrics.NewMetrics(prometheus.NewRegistry()))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("syncs the Alertmanager of an organization", func(t *testing.T) {
+		errs, err := mam.ResyncAlertmanagers(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, map[int64]error{1: nil}, errs)
+		am, err := mam.AlertmanagerFor(1)
+		require.NoError(t, err)
+		require.NotEmpty(t, am.Health().ConfigHash)
+	})
+
+	t.Run("fails for an organization that doesn't exist", func(t *testing.T) {
+		_, err := mam.ResyncAlertmanagers(ctx, 2)
+		require.ErrorIs(t, err, ErrOrgNotFound)
+	})
+
+	t.Run("creates the Alertmanager of a new organization when it's created", func(t *testing.T) {
+		orgStore.orgs = []int64{1, 2}
+		require.NoError(t, bus.Publish(&events.OrgCreated{Timestamp: time.Now(), Id: 2}))
+		<-mam.resync
+		mam.processResyncs(ctx)
+		_, err := mam.AlertmanagerFor(2)
+		require.NoError(t, err)
+	})
+
+	t.Run("queues the organizations whose configuration is saved", func(t *testing.T) {
+		require.NoError(t, bus.Publish(&events.AlertmanagerConfigurationSaved{Timestamp: time.Now(), OrgID: 1}))
+		require.NoError(t, bus.Publish(&events.AlertmanagerConfigurationSaved{Timestamp: time.Now(), OrgID: 2}))
+		require.Len(t, mam.resync, 1)
+		require.Equal(t, map[int64]struct{}{1: {}, 2: {}}, mam.resyncOrgs)
+	})
+}
@@ -0,0 +1,140 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/prometheus/alertmanager/template"
+	"github.com/prometheus/alertmanager/types"
+
+	"github.com/grafana/grafana/pkg/components/securejsondata"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier/channels"
+	"github.com/grafana/grafana/pkg/services/ngalert/secrets"
+)
+
+// secretReferences returns the settings and secure settings of the config that reference a secret
+// of an external secret manager, by setting name.
+func secretReferences(cfg *channels.NotificationChannelConfig) map[string]secrets.Reference {
+	refs := map[string]secrets.Reference{}
+	if cfg.Settings != nil {
+		for k, v := range cfg.Settings.MustMap() {
+			if s, ok := v.(string); ok {
+				if ref, ok := secrets.ParseReference(s); ok {
+					refs[k] = ref
+				}
+			}
+		}
+	}
+	for k, v := range cfg.SecureSettings.Decrypt() {
+		if ref, ok := secrets.ParseReference(v); ok {
+			refs[k] = ref
+		}
+	}
+	return refs
+}
+
+// secretRefNotifier resolves the secret references of an integration when sending notifications,
+// the integration is rebuilt whenever one of its secrets changes.
+type secretRefNotifier struct {
+	cfg      *channels.NotificationChannelConfig
+	refs     map[string]secrets.Reference
+	tmpl     *template.Template
+	resolver *secrets.Resolver
+
+	mtx      sync.Mutex
+	resolved map[string]string
+	current  NotificationChannel
+}
+
+func newSecretRefNotifier(cfg *channels.NotificationChannelConfig, refs map[string]secrets.Reference, tmpl *template.Template, resolver *secrets.Resolver) *secretRefNotifier {
+	return &secretRefNotifier{
+		cfg:      cfg,
+		refs:     refs,
+		tmpl:     tmpl,
+		resolver: resolver,
+	}
+}
+
+func (n *secretRefNotifier) Notify(ctx context.Context, as ...*types.Alert) (bool, error) {
+	current, err := n.notifier(ctx)
+	if err != nil {
+		// The secret manager might be temporarily unavailable.
+		return true, err
+	}
+	return current.Notify(ctx, as...)
+}
+
+func (n *secretRefNotifier) SendResolved() bool {
+	return !n.cfg.DisableResolveMessage
+}
+
+func (n *secretRefNotifier) notifier(ctx context.Context) (NotificationChannel, error) {
+	resolved := make(map[string]string, len(n.refs))
+	for k, ref := range n.refs {
+		v, err := n.resolver.Resolve(ctx, ref)
+		if err != nil {
+			return nil, err
+		}
+		resolved[k] = v
+	}
+
+	n.mtx.Lock()
+	defer n.mtx.Unlock()
+	if n.current != nil && sameSecrets(n.resolved, resolved) {
+		return n.current, nil
+	}
+
+	cfg, err := n.resolvedConfig(resolved)
+	if err != nil {
+		return nil, err
+	}
+	current, err := newNotificationChannel(cfg, n.tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build notifier with resolved secrets: %w", err)
+	}
+	n.current, n.resolved = current, resolved
+	return current, nil
+}
+
+// resolvedConfig returns a copy of the config where the secrets are plain settings.
+func (n *secretRefNotifier) resolvedConfig(resolved map[string]string) (*channels.NotificationChannelConfig, error) {
+	settings := simplejson.New()
+	if n.cfg.Settings != nil {
+		b, err := n.cfg.Settings.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		if settings, err = simplejson.NewJson(b); err != nil {
+			return nil, err
+		}
+	}
+
+	secureSettings := make(securejsondata.SecureJsonData, len(n.cfg.SecureSettings))
+	for k, v := range n.cfg.SecureSettings {
+		if _, ok := resolved[k]; !ok {
+			secureSettings[k] = v
+		}
+	}
+	for k, v := range resolved {
+		settings.Set(k, v)
+	}
+
+	cfg := *n.cfg
+	cfg.Settings = settings
+	cfg.SecureSettings = secureSettings
+	return &cfg, nil
+}
+
+func sameSecrets(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,72 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	gfmodels "github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/secrets"
+)
+
+type fakeSecretsProvider struct {
+	value string
+}
+
+func (p *fakeSecretsProvider) Resolve(_ context.Context, _ secrets.Reference) (string, error) {
+	return p.value, nil
+}
+
+func TestSecretRefNotifier(t *testing.T) {
+	am := setupAMTest(t)
+	provider := &fakeSecretsProvider{value: "http://localhost/first"}
+	require.NoError(t, am.SaveAndApplyDefaultConfig())
+	am.secrets = secrets.NewResolverWithProviders(map[string]secrets.Provider{secrets.VaultScheme: provider}, nil, 0)
+
+	var urls []string
+	bus.AddHandlerCtx("test", func(ctx context.Context, webhook *gfmodels.SendWebhookSync) error {
+		urls = append(urls, webhook.Url)
+		return nil
+	})
+
+	settings := simplejson.New()
+	settings.Set("url", "vault://secret/webhook#url")
+	r := &apimodels.PostableGrafanaReceiver{UID: "uid", Name: "webhook", Type: "webhook", Settings: settings}
+	tmpl, err := am.getTemplate()
+	require.NoError(t, err)
+	n, err := am.buildReceiverIntegration(r, tmpl)
+	require.NoError(t, err)
+
+	alert := &types.Alert{Alert: model.Alert{Labels: model.LabelSet{"alertname": "test"}, StartsAt: time.Now()}}
+	ctx := notify.WithGroupKey(context.Background(), "group")
+	ctx = notify.WithReceiverName(ctx, "webhook")
+	_, err = n.Notify(ctx, alert)
+	require.NoError(t, err)
+
+	// The rotated secret is picked up on the next notification.
+	provider.value = "http://localhost/second"
+	_, err = n.Notify(ctx, alert)
+	require.NoError(t, err)
+	require.Equal(t, []string{"http://localhost/first", "http://localhost/second"}, urls)
+
+	// References to a secret manager that isn't configured are invalid.
+	settings.Set("url", "aws-secretsmanager://prod/webhook#url")
+	_, err = am.buildReceiverIntegration(r, tmpl)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), secrets.ErrProviderNotConfigured.Error())
+
+	// References outside of the path prefix of the organization are invalid.
+	am.secrets = secrets.NewResolverWithProviders(map[string]secrets.Provider{secrets.VaultScheme: provider}, map[string]string{secrets.VaultScheme: "secret/grafana/org-2/"}, 0)
+	settings.Set("url", "vault://secret/grafana/org-1/webhook#url")
+	_, err = am.buildReceiverIntegration(r, tmpl)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), secrets.ErrReferenceOutOfScope.Error())
+}
@@ -0,0 +1,220 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// SelfMonitor tracks the health of the alerting of this instance between two checks, and notifies the administrators
+// of the organizations of its problems: the ticks missed by the scheduler, a high failure rate of the evaluations of
+// the rules, the repeated failures of an integration to send notifications, and an Alertmanager configuration that
+// fails to apply. A problem is notified once, and again if it comes back after being resolved.
+type SelfMonitor struct {
+	evaluationFailureRate float64
+	minEvaluations        int
+	notificationFailures  int
+
+	mtx         sync.Mutex
+	missedTicks int
+	orgs        map[int64]*orgHealth
+	// notified are the fingerprints of the problems notified to the administrators of each organization.
+	notified map[int64]map[model.Fingerprint]struct{}
+}
+
+type orgHealth struct {
+	evaluations int
+	failures    int
+	// notificationFailures are the consecutive failures of the integrations of the receivers.
+	notificationFailures map[receiverIntegration]int
+	configErr            error
+}
+
+type receiverIntegration struct {
+	receiver    string
+	integration string
+}
+
+func newSelfMonitor(cfg *setting.Cfg) *SelfMonitor {
+	return &SelfMonitor{
+		evaluationFailureRate: cfg.SelfMonitoringEvaluationFailureRate,
+		minEvaluations:        cfg.SelfMonitoringMinEvaluations,
+		notificationFailures:  cfg.SelfMonitoringNotificationFailures,
+		orgs:                  make(map[int64]*orgHealth),
+		notified:              make(map[int64]map[model.Fingerprint]struct{}),
+	}
+}
+
+func (m *SelfMonitor) org(orgID int64) *orgHealth {
+	h, ok := m.orgs[orgID]
+	if !ok {
+		h = &orgHealth{notificationFailures: make(map[receiverIntegration]int)}
+		m.orgs[orgID] = h
+	}
+	return h
+}
+
+// RecordTick records a tick of the scheduler processed at now, the scheduler missed a tick when it processes it more
+// than an interval late.
+func (m *SelfMonitor) RecordTick(tick, now time.Time, interval time.Duration) {
+	if now.Sub(tick) <= interval {
+		return
+	}
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.missedTicks++
+}
+
+// RecordEvaluation records an evaluation of a rule, which failed if it returned an error or a result in error.
+func (m *SelfMonitor) RecordEvaluation(key models.AlertRuleKey, results eval.Results, err error) {
+	failed := err != nil
+	for _, r := range results {
+		if r.State == eval.Error {
+			failed = true
+			break
+		}
+	}
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	h := m.org(key.OrgID)
+	h.evaluations++
+	if failed {
+		h.failures++
+	}
+}
+
+// recordNotification records an attempt of the integration of the receiver to send a notification.
+func (m *SelfMonitor) recordNotification(orgID int64, receiver, integration string, err error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	key := receiverIntegration{receiver: receiver, integration: integration}
+	h := m.org(orgID)
+	if err == nil {
+		delete(h.notificationFailures, key)
+		return
+	}
+	h.notificationFailures[key]++
+}
+
+// recordConfigApplied records the result of applying the Alertmanager configuration of the organization.
+func (m *SelfMonitor) recordConfigApplied(orgID int64, err error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.org(orgID).configErr = err
+}
+
+// check returns the problems of the organizations not notified yet, and resets the counts of the ticks and
+// evaluations for the next check.
+func (m *SelfMonitor) check(orgIDs []int64, now time.Time) map[int64][]*types.Alert {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	problems := make(map[int64][]*types.Alert)
+	for _, orgID := range orgIDs {
+		var alerts []*types.Alert
+		if m.missedTicks > 0 {
+			alerts = append(alerts, selfMonitoringAlert(now, "AlertingSchedulerMissedTicks", nil,
+				fmt.Sprintf("The alert rule scheduler missed %d ticks", m.missedTicks),
+				"The scheduler processed its ticks late, the alert rules were evaluated late. Check the load of the Grafana server and the duration of the evaluations of the rules."))
+		}
+		h := m.org(orgID)
+		if h.evaluations >= m.minEvaluations && h.evaluations > 0 {
+			if rate := float64(h.failures) / float64(h.evaluations); rate >= m.evaluationFailureRate {
+				alerts = append(alerts, selfMonitoringAlert(now, "AlertingEvaluationFailureRate", nil,
+					fmt.Sprintf("%.0f%% of the %d evaluations of the alert rules failed", rate*100, h.evaluations),
+					"Check the health of the alert rules and the availability of their datasources."))
+			}
+		}
+		for key, failures := range h.notificationFailures {
+			if failures < m.notificationFailures {
+				continue
+			}
+			alerts = append(alerts, selfMonitoringAlert(now, "AlertingNotificationFailures",
+				model.LabelSet{"receiver": model.LabelValue(key.receiver), "integration": model.LabelValue(key.integration)},
+				fmt.Sprintf("The %s integration of the contact point %s failed to send %d notifications in a row", key.integration, key.receiver, failures),
+				"Check the settings of the contact point and the availability of the service it sends the notifications to."))
+		}
+		if h.configErr != nil {
+			alerts = append(alerts, selfMonitoringAlert(now, "AlertingAlertmanagerConfigFailed", nil,
+				"The Alertmanager configuration of the organization fails to apply",
+				fmt.Sprintf("The notifications are sent with the last configuration applied: %s", h.configErr)))
+		}
+
+		current := make(map[model.Fingerprint]struct{}, len(alerts))
+		for _, a := range alerts {
+			fp := a.Fingerprint()
+			current[fp] = struct{}{}
+			if _, ok := m.notified[orgID][fp]; !ok {
+				problems[orgID] = append(problems[orgID], a)
+			}
+		}
+		m.notified[orgID] = current
+		h.evaluations, h.failures = 0, 0
+	}
+	m.missedTicks = 0
+	for _, alerts := range problems {
+		sort.Slice(alerts, func(i, j int) bool {
+			if alerts[i].Name() != alerts[j].Name() {
+				return alerts[i].Name() < alerts[j].Name()
+			}
+			return alerts[i].Labels.Before(alerts[j].Labels)
+		})
+	}
+	return problems
+}
+
+func selfMonitoringAlert(now time.Time, name string, labels model.LabelSet, summary, description string) *types.Alert {
+	ls := model.LabelSet{model.AlertNameLabel: model.LabelValue(name)}
+	for k, v := range labels {
+		ls[k] = v
+	}
+	return &types.Alert{
+		Alert: model.Alert{
+			Labels: ls,
+			Annotations: model.LabelSet{
+				"summary":     model.LabelValue(summary),
+				"description": model.LabelValue(description),
+			},
+			StartsAt: now,
+		},
+		UpdatedAt: now,
+	}
+}
+
+// SelfMonitor returns the self-monitoring of the alerting, nil if it is disabled.
+func (moa *MultiOrgAlertmanager) SelfMonitor() *SelfMonitor {
+	return moa.selfMonitor
+}
+
+// CheckSelfMonitoring notifies the administrators of the organizations of the problems of the alerting found since the
+// last check.
+func (moa *MultiOrgAlertmanager) CheckSelfMonitoring(ctx context.Context, now time.Time) {
+	if moa.selfMonitor == nil {
+		return
+	}
+	moa.alertmanagersMtx.RLock()
+	defer moa.alertmanagersMtx.RUnlock()
+
+	orgIDs := make([]int64, 0, len(moa.alertmanagers))
+	for orgID := range moa.alertmanagers {
+		orgIDs = append(orgIDs, orgID)
+	}
+	for orgID, alerts := range moa.selfMonitor.check(orgIDs, now) {
+		am := moa.alertmanagers[orgID]
+		for _, alert := range alerts {
+			moa.logger.Warn("alerting problem", "org", orgID, "alertname", alert.Name(), "summary", alert.Annotations["summary"])
+			if err := am.notifyOrgAdmins(ctx, alert); err != nil {
+				moa.logger.Error("failed to notify the organization administrators of an alerting problem", "org", orgID, "alertname", alert.Name(), "err", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	gfmodels "github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func alertNames(t *testing.T, problems map[int64][]*types.Alert, orgID int64) []string {
+	t.Helper()
+	names := make([]string, 0, len(problems[orgID]))
+	for _, a := range problems[orgID] {
+		names = append(names, a.Name())
+	}
+	return names
+}
+
+func TestSelfMonitor(t *testing.T) {
+	m := newSelfMonitor(&setting.Cfg{
+		SelfMonitoringEvaluationFailureRate: 0.5,
+		SelfMonitoringMinEvaluations:        4,
+		SelfMonitoringNotificationFailures:  2,
+	})
+	now := time.Date(2021, 10, 14, 10, 15, 0, 0, time.UTC)
+
+	t.Run("no problem", func(t *testing.T) {
+		m.RecordTick(now, now.Add(time.Second), 10*time.Second)
+		m.RecordEvaluation(models.AlertRuleKey{OrgID: 1, UID: "a"}, eval.Results{{State: eval.Error}}, nil)
+		m.recordNotification(1, "ops", "slack", errors.New("unavailable"))
+		require.Empty(t, m.check([]int64{1, 2}, now))
+	})
+
+	t.Run("problems", func(t *testing.T) {
+		m.RecordTick(now, now.Add(11*time.Second), 10*time.Second)
+		for i := 0; i < 4; i++ {
+			var err error
+			if i%2 == 0 {
+				err = errors.New("timeout")
+			}
+			m.RecordEvaluation(models.AlertRuleKey{OrgID: 1, UID: "a"}, eval.Results{{State: eval.Normal}}, err)
+		}
+		m.recordNotification(1, "ops", "slack", errors.New("unavailable"))
+		m.recordConfigApplied(2, errors.New("invalid template"))
+
+		problems := m.check([]int64{1, 2}, now)
+		require.Equal(t, []string{"AlertingEvaluationFailureRate", "AlertingNotificationFailures", "AlertingSchedulerMissedTicks"}, alertNames(t, problems, 1))
+		require.Equal(t, []string{"AlertingAlertmanagerConfigFailed", "AlertingSchedulerMissedTicks"}, alertNames(t, problems, 2))
+		require.Equal(t, model.LabelValue("ops"), problems[1][1].Labels["receiver"])
+	})
+
+	t.Run("the problems are notified once", func(t *testing.T) {
+		problems := m.check([]int64{1, 2}, now)
+		require.Empty(t, problems, "the ticks and evaluations are counted between two checks, the other problems are notified")
+	})
+
+	t.Run("the resolved problems are notified again when they come back", func(t *testing.T) {
+		m.recordNotification(1, "ops", "slack", nil)
+		m.recordConfigApplied(2, nil)
+		require.Empty(t, m.check([]int64{1, 2}, now))
+
+		m.recordConfigApplied(2, errors.New("invalid template"))
+		require.Equal(t, []string{"AlertingAlertmanagerConfigFailed"}, alertNames(t, m.check([]int64{1, 2}, now), 2))
+	})
+}
+
+func TestCheckSelfMonitoring(t *testing.T) {
+	am := setupAMTest(t)
+	require.NoError(t, am.SaveAndApplyDefaultConfig())
+	t.Cleanup(bus.ClearBusHandlers)
+	bus.AddHandlerCtx("test", func(ctx context.Context, q *gfmodels.GetOrgUsersQuery) error {
+		q.Result = []*gfmodels.OrgUserDTO{{Email: "admin@localhost", Role: string(gfmodels.ROLE_ADMIN)}}
+		return nil
+	})
+	var emails []*gfmodels.SendEmailCommandSync
+	bus.AddHandlerCtx("test", func(ctx context.Context, cmd *gfmodels.SendEmailCommandSync) error {
+		emails = append(emails, cmd)
+		return nil
+	})
+
+	monitor := newSelfMonitor(&setting.Cfg{SelfMonitoringEvaluationFailureRate: 0.5, SelfMonitoringNotificationFailures: 1})
+	moa := &MultiOrgAlertmanager{
+		alertmanagers: map[int64]*Alertmanager{1: am},
+		logger:        log.New("test"),
+		selfMonitor:   monitor,
+	}
+	monitor.recordConfigApplied(1, errors.New("invalid template"))
+
+	moa.CheckSelfMonitoring(context.Background(), time.Now())
+	require.Len(t, emails, 1)
+	require.Equal(t, []string{"admin@localhost"}, emails[0].To)
+	moa.CheckSelfMonitoring(context.Background(), time.Now())
+	require.Len(t, emails, 1)
+}
@@ -0,0 +1,149 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/silence"
+	"github.com/prometheus/alertmanager/silence/silencepb"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+// WarnExpiringSilences notifies the contact point configured in silence_expiry_warning_contact_point
+// of the active silences that expire within the warning period while some of the alerts they mute are still firing.
+// A warning is sent once per silence, unless the silence is extended.
+func (am *Alertmanager) WarnExpiringSilences(ctx context.Context, now time.Time) error {
+	receiverName := am.Settings.SilenceExpiryWarningContactPoint
+	if receiverName == "" || am.Settings.SilenceExpiryWarningPeriod <= 0 || am.InMaintenanceMode(now) {
+		return nil
+	}
+
+	sils, _, err := am.silences.Query(silence.QState(types.SilenceStateActive))
+	if err != nil {
+		return fmt.Errorf("%s: %w", ErrGetSilencesInternal.Error(), err)
+	}
+
+	am.warnedSilencesMtx.Lock()
+	defer am.warnedSilencesMtx.Unlock()
+
+	active := make(map[string]struct{}, len(sils))
+	var expiring []*silencepb.Silence
+	for _, s := range sils {
+		active[s.Id] = struct{}{}
+		if s.EndsAt.Sub(now) > am.Settings.SilenceExpiryWarningPeriod {
+			continue
+		}
+		if endsAt, ok := am.warnedSilences[s.Id]; ok && endsAt.Equal(s.EndsAt) {
+			continue
+		}
+		expiring = append(expiring, s)
+	}
+
+	// Forget the silences that expired or were deleted.
+	for id := range am.warnedSilences {
+		if _, ok := active[id]; !ok {
+			delete(am.warnedSilences, id)
+		}
+	}
+
+	if len(expiring) == 0 {
+		return nil
+	}
+
+	firing := am.countSilencedAlerts(now)
+
+	var integrations []notify.Integration
+	for _, s := range expiring {
+		if firing[s.Id] == 0 {
+			continue
+		}
+
+		if integrations == nil {
+			integrations, err = am.integrationsForReceiver(receiverName)
+			if err != nil {
+				return err
+			}
+		}
+
+		alert := silenceExpiryWarningAlert(s, firing[s.Id], now)
+		notifyCtx := notify.WithGroupKey(ctx, alert.Labels.String()+s.EndsAt.String())
+		notifyCtx = notify.WithGroupLabels(notifyCtx, alert.Labels)
+		notifyCtx = notify.WithReceiverName(notifyCtx, receiverName)
+		for _, integration := range integrations {
+			if _, err := integration.Notify(notifyCtx, alert); err != nil {
+				am.logger.Error("failed to send silence expiry warning", "silence", s.Id, "integration", integration.Name(), "err", err)
+			}
+		}
+		am.warnedSilences[s.Id] = s.EndsAt
+	}
+	return nil
+}
+
+// countSilencedAlerts returns the number of firing alerts muted by each silence.
+func (am *Alertmanager) countSilencedAlerts(now time.Time) map[string]int {
+	counts := map[string]int{}
+	alerts := am.alerts.GetPending()
+	defer alerts.Close()
+
+	am.reloadConfigMtx.RLock()
+	defer am.reloadConfigMtx.RUnlock()
+	for a := range alerts.Next() {
+		if a.ResolvedAt(now) {
+			continue
+		}
+		// Refresh the status of the alert, silences might have been created since it was last notified.
+		am.silencer.Mutes(a.Labels)
+		for _, id := range am.marker.Status(a.Fingerprint()).SilencedBy {
+			counts[id]++
+		}
+	}
+	return counts
+}
+
+// integrationsForReceiver builds the integrations of the receiver with the given name in the current configuration.
+func (am *Alertmanager) integrationsForReceiver(name string) ([]notify.Integration, error) {
+	tmpl, err := am.getTemplate()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get template: %w", err)
+	}
+
+	am.reloadConfigMtx.RLock()
+	var receiver *apimodels.PostableApiReceiver
+	for _, r := range am.config.AlertmanagerConfig.Receivers {
+		if r.Name == name {
+			receiver = r
+			break
+		}
+	}
+	am.reloadConfigMtx.RUnlock()
+
+	if receiver == nil {
+		return nil, fmt.Errorf("contact point %q for silence expiry warnings not found", name)
+	}
+	return am.buildReceiverIntegrations(receiver, tmpl)
+}
+
+func silenceExpiryWarningAlert(s *silencepb.Silence, firing int, now time.Time) *types.Alert {
+	return &types.Alert{
+		Alert: model.Alert{
+			Labels: model.LabelSet{
+				model.AlertNameLabel: "SilenceExpiring",
+				"silence_id":         model.LabelValue(s.Id),
+				"created_by":         model.LabelValue(s.CreatedBy),
+			},
+			Annotations: model.LabelSet{
+				"summary": model.LabelValue(fmt.Sprintf("Silence %s created by %s expires at %s", s.Id, s.CreatedBy, s.EndsAt.Format(time.RFC3339))),
+				"description": model.LabelValue(fmt.Sprintf("%d firing alert(s) are muted by the silence and will send notifications once it expires. Silence comment: %s",
+					firing, s.Comment)),
+			},
+			StartsAt: now,
+			EndsAt:   s.EndsAt,
+		},
+		UpdatedAt: now,
+	}
+}
@@ -0,0 +1,66 @@
+package notifier
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	gfmodels "github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestWarnExpiringSilences(t *testing.T) {
+	am := setupAMTest(t)
+	am.Settings.SilenceExpiryWarningContactPoint = "silence-warnings"
+	am.Settings.SilenceExpiryWarningPeriod = 15 * time.Minute
+
+	cfg, err := Load([]byte(`{
+		"alertmanager_config": {
+			"route": {"receiver": "silence-warnings"},
+			"receivers": [{
+				"name": "silence-warnings",
+				"grafana_managed_receiver_configs": [{"uid": "", "name": "webhook", "type": "webhook", "settings": {"url": "http://localhost/hook"}}]
+			}]
+		}
+	}`))
+	require.NoError(t, err)
+	require.NoError(t, am.SaveAndApplyConfig(cfg))
+
+	var warnings []string
+	bus.AddHandlerCtx("test", func(ctx context.Context, webhook *gfmodels.SendWebhookSync) error {
+		warnings = append(warnings, webhook.Body)
+		return nil
+	})
+
+	name, value, isRegex := "alertname", "Maintenance", false
+	matchers := models.Matchers{{Name: &name, Value: &value, IsRegex: &isRegex}}
+	newSilence := func(endsAt time.Time) string {
+		startsAt, end := strfmt.DateTime(time.Now()), strfmt.DateTime(endsAt)
+		comment, createdBy := "maintenance", "admin"
+		id, err := am.CreateSilence(&apimodels.PostableSilence{Silence: models.Silence{
+			Comment: &comment, CreatedBy: &createdBy, StartsAt: &startsAt, EndsAt: &end, Matchers: matchers,
+		}})
+		require.NoError(t, err)
+		return id
+	}
+	expiring := newSilence(time.Now().Add(5 * time.Minute))
+	newSilence(time.Now().Add(2 * time.Hour))
+
+	require.NoError(t, am.PutAlerts(apimodels.PostableAlerts{PostableAlerts: []models.PostableAlert{{
+		Alert: models.Alert{Labels: models.LabelSet{"alertname": "Maintenance"}},
+	}}}))
+
+	require.NoError(t, am.WarnExpiringSilences(context.Background(), time.Now()))
+	require.Len(t, warnings, 1)
+	require.True(t, strings.Contains(warnings[0], expiring), "the warning should reference the expiring silence")
+
+	// The warning is only sent once per silence.
+	require.NoError(t, am.WarnExpiringSilences(context.Background(), time.Now()))
+	require.Len(t, warnings, 1)
+}
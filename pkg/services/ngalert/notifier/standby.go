@@ -0,0 +1,153 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/notify"
+	"github.com/prometheus/alertmanager/types"
+)
+
+const (
+	// StandbyDisabled is the mode where every replica sends the notifications.
+	StandbyDisabled = "disabled"
+	// StandbyManual is the mode where the replica is a warm standby until it's promoted.
+	StandbyManual = "manual"
+	// StandbyLeaderElection is the mode where the first peer of the cluster sends the notifications.
+	StandbyLeaderElection = "leader_election"
+)
+
+// ErrStandbyDisabled is an error for a promotion or demotion of a replica not in a warm standby mode.
+var ErrStandbyDisabled = errors.New("the warm standby mode is disabled")
+
+// StandbyStatus is whether the Alertmanagers of the replica send the notifications or are a warm standby.
+type StandbyStatus struct {
+	Mode string
+	// Active is true if the Alertmanagers send the notifications.
+	Active bool
+	// Promoted is true if the replica was promoted with the API, it's then active whatever the leader election.
+	Promoted bool
+	// Position is the position of the replica in the gossip cluster, the leader is at 0.
+	Position int
+}
+
+// standby decides whether the Alertmanagers of the replica send the notifications. A warm standby keeps its
+// Alertmanagers running and synced with the cluster, so that it takes over without losing the notification log and
+// silences, and without sending the notifications sent by the active replica again.
+type standby struct {
+	mode string
+	peer ClusterPeer
+
+	mtx      sync.RWMutex
+	promoted bool
+	// active is the last status observed, to log the changes.
+	active bool
+}
+
+func newStandby(mode string, peer ClusterPeer) *standby {
+	if mode == "" {
+		mode = StandbyDisabled
+	}
+	s := &standby{mode: mode, peer: peer}
+	s.active = s.status().Active
+	return s
+}
+
+func (s *standby) status() StandbyStatus {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	status := StandbyStatus{Mode: s.mode, Promoted: s.promoted, Position: s.peer.Position()}
+	switch s.mode {
+	case StandbyManual:
+		status.Active = s.promoted
+	case StandbyLeaderElection:
+		status.Active = s.promoted || status.Position == 0
+	default:
+		status.Active = true
+	}
+	return status
+}
+
+func (s *standby) isActive() bool {
+	return s.status().Active
+}
+
+func (s *standby) setPromoted(promoted bool) error {
+	if s.mode == StandbyDisabled {
+		return ErrStandbyDisabled
+	}
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.promoted = promoted
+	return nil
+}
+
+// changed returns the status and whether the replica became active or standby since the last call.
+func (s *standby) changed() (StandbyStatus, bool) {
+	status := s.status()
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if status.Active == s.active {
+		return status, false
+	}
+	s.active = status.Active
+	return status, true
+}
+
+// StandbyStatus returns whether the Alertmanagers of the replica send the notifications or are a warm standby.
+func (moa *MultiOrgAlertmanager) StandbyStatus() StandbyStatus {
+	return moa.standby.status()
+}
+
+// Promote makes the Alertmanagers of a warm standby replica send the notifications, or keeps them active whatever the
+// leader election.
+func (moa *MultiOrgAlertmanager) Promote() (StandbyStatus, error) {
+	if err := moa.standby.setPromoted(true); err != nil {
+		return StandbyStatus{}, err
+	}
+	moa.logger.Info("replica promoted, the Alertmanagers send the notifications")
+	moa.logStandbyChange()
+	return moa.standby.status(), nil
+}
+
+// Demote cancels the promotion of the replica, which becomes a warm standby again unless it's elected.
+func (moa *MultiOrgAlertmanager) Demote() (StandbyStatus, error) {
+	if err := moa.standby.setPromoted(false); err != nil {
+		return StandbyStatus{}, err
+	}
+	moa.logger.Info("replica demoted")
+	moa.logStandbyChange()
+	return moa.standby.status(), nil
+}
+
+// logStandbyChange logs when the replica becomes active or a warm standby, such as after a leader election.
+func (moa *MultiOrgAlertmanager) logStandbyChange() {
+	status, changed := moa.standby.changed()
+	if !changed {
+		return
+	}
+	if status.Active {
+		moa.logger.Info("the Alertmanagers of the replica are active", "mode", status.Mode, "position", status.Position, "promoted", status.Promoted)
+	} else {
+		moa.logger.Info("the Alertmanagers of the replica are a warm standby", "mode", status.Mode, "position", status.Position)
+	}
+}
+
+// standbyStage drops the notifications of a receiver while the replica is a warm standby. They are dropped before
+// the notification log, which only logs the notifications of the active replica.
+type standbyStage struct {
+	am       *Alertmanager
+	receiver string
+}
+
+func (s *standbyStage) Exec(ctx context.Context, l log.Logger, alerts ...*types.Alert) (context.Context, []*types.Alert, error) {
+	if s.am.standby == nil || s.am.standby.isActive() {
+		return ctx, alerts, nil
+	}
+
+	groupKey, _ := notify.GroupKey(ctx)
+	s.am.logger.Debug("notification not sent by a warm standby", "receiver", s.receiver, "group_key", groupKey, "alerts", len(alerts))
+	return ctx, nil, nil
+}
@@ -0,0 +1,63 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-kit/kit/log"
+	"github.com/prometheus/alertmanager/types"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStandby(t *testing.T) {
+	t.Run("every replica is active if the standby mode is disabled", func(t *testing.T) {
+		s := newStandby("", &positionedPeer{position: 1})
+		require.True(t, s.isActive())
+		require.ErrorIs(t, s.setPromoted(true), ErrStandbyDisabled)
+	})
+
+	t.Run("a manual standby is active once promoted", func(t *testing.T) {
+		s := newStandby(StandbyManual, &positionedPeer{position: 0})
+		require.False(t, s.isActive())
+		require.NoError(t, s.setPromoted(true))
+		require.True(t, s.isActive())
+		require.NoError(t, s.setPromoted(false))
+		require.False(t, s.isActive())
+	})
+
+	t.Run("the first peer of the cluster is elected", func(t *testing.T) {
+		peer := &positionedPeer{position: 1}
+		s := newStandby(StandbyLeaderElection, peer)
+		require.False(t, s.isActive())
+
+		// the leader left the cluster
+		peer.position = 0
+		status, changed := s.changed()
+		require.True(t, changed)
+		require.True(t, status.Active)
+		_, changed = s.changed()
+		require.False(t, changed)
+
+		peer.position = 2
+		require.NoError(t, s.setPromoted(true))
+		require.True(t, s.isActive(), "a promoted replica is active whatever the election")
+	})
+}
+
+func TestStandbyStage(t *testing.T) {
+	am := setupAMTest(t)
+	peer := &positionedPeer{position: 1}
+	am.standby = newStandby(StandbyLeaderElection, peer)
+	stage := &standbyStage{am: am, receiver: "test"}
+	alerts := []*types.Alert{{Alert: model.Alert{Labels: model.LabelSet{"alertname": "test"}}}}
+
+	_, res, err := stage.Exec(context.Background(), log.NewNopLogger(), alerts...)
+	require.NoError(t, err)
+	require.Empty(t, res, "a warm standby doesn't send notifications")
+
+	peer.position = 0
+	_, res, err = stage.Exec(context.Background(), log.NewNopLogger(), alerts...)
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+}
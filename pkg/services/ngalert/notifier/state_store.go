@@ -0,0 +1,311 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/option"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// defaultStateSyncInterval is how often the state is persisted if the interval isn't set.
+const defaultStateSyncInterval = time.Minute
+
+// StateStore persists the snapshots of the notification log and silences of the embedded Alertmanagers,
+// so that the Grafana instance taking over an organization doesn't lose its silences or send duplicate notifications.
+type StateStore interface {
+	GetAlertmanagerState(orgID int64, kind string) (*ngmodels.AlertmanagerState, error)
+	SaveAlertmanagerState(s *ngmodels.AlertmanagerState) error
+}
+
+// NewStateStore returns the state store configured in the settings,
+// or nil if the state is only kept on the local disk.
+func NewStateStore(cfg *setting.Cfg, db store.AlertmanagerStateStore) StateStore {
+	switch cfg.AlertmanagerStateStorage {
+	case "database":
+		return db
+	case "s3":
+		return &objectStateStore{prefix: cfg.AlertmanagerStatePrefix, bucket: &s3Bucket{name: cfg.AlertmanagerStateBucket, region: cfg.AlertmanagerStateS3Region}}
+	case "gcs":
+		return &objectStateStore{prefix: cfg.AlertmanagerStatePrefix, bucket: &gcsBucket{name: cfg.AlertmanagerStateBucket, keyFile: cfg.AlertmanagerStateGCSKeyFile}}
+	default:
+		return nil
+	}
+}
+
+// errObjectNotFound is returned by buckets for unknown keys.
+var errObjectNotFound = errors.New("object not found")
+
+type bucket interface {
+	get(ctx context.Context, key string) ([]byte, error)
+	put(ctx context.Context, key string, data []byte) error
+}
+
+// objectStateStore stores the state as JSON objects, one per organization and kind.
+type objectStateStore struct {
+	prefix string
+	bucket bucket
+}
+
+func (s *objectStateStore) key(orgID int64, kind string) string {
+	return path.Join(s.prefix, strconv.FormatInt(orgID, 10), kind)
+}
+
+func (s *objectStateStore) GetAlertmanagerState(orgID int64, kind string) (*ngmodels.AlertmanagerState, error) {
+	b, err := s.bucket.get(context.Background(), s.key(orgID, kind))
+	if err != nil {
+		if errors.Is(err, errObjectNotFound) {
+			return nil, ngmodels.ErrNoAlertmanagerState
+		}
+		return nil, err
+	}
+	state := &ngmodels.AlertmanagerState{}
+	if err := json.Unmarshal(b, state); err != nil {
+		return nil, fmt.Errorf("%w: %s", ngmodels.ErrAlertmanagerStateCorrupted, err)
+	}
+	return state, nil
+}
+
+func (s *objectStateStore) SaveAlertmanagerState(state *ngmodels.AlertmanagerState) error {
+	b, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return s.bucket.put(context.Background(), s.key(state.OrgID, state.Kind), b)
+}
+
+type s3Bucket struct {
+	name   string
+	region string
+
+	once   sync.Once
+	client *s3.S3
+	err    error
+}
+
+func (b *s3Bucket) init() error {
+	b.once.Do(func() {
+		cfg := aws.NewConfig()
+		if b.region != "" {
+			cfg = cfg.WithRegion(b.region)
+		}
+		sess, err := session.NewSession(cfg)
+		if err != nil {
+			b.err = err
+			return
+		}
+		b.client = s3.New(sess)
+	})
+	return b.err
+}
+
+func (b *s3Bucket) get(ctx context.Context, key string) ([]byte, error) {
+	if err := b.init(); err != nil {
+		return nil, err
+	}
+	out, err := b.client.GetObjectWithContext(ctx, &s3.GetObjectInput{Bucket: aws.String(b.name), Key: aws.String(key)})
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == s3.ErrCodeNoSuchKey {
+			return nil, errObjectNotFound
+		}
+		return nil, err
+	}
+	defer func() {
+		_ = out.Body.Close()
+	}()
+	return ioutil.ReadAll(out.Body)
+}
+
+func (b *s3Bucket) put(ctx context.Context, key string, data []byte) error {
+	if err := b.init(); err != nil {
+		return err
+	}
+	_, err := b.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(b.name),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("application/json"),
+	})
+	return err
+}
+
+type gcsBucket struct {
+	name    string
+	keyFile string
+
+	once   sync.Once
+	client *storage.Client
+	err    error
+}
+
+func (b *gcsBucket) init(ctx context.Context) error {
+	b.once.Do(func() {
+		opts := []option.ClientOption{option.WithScopes(storage.ScopeReadWrite)}
+		if b.keyFile != "" {
+			keyData, err := ioutil.ReadFile(b.keyFile)
+			if err != nil {
+				b.err = fmt.Errorf("failed to read the service account key file: %w", err)
+				return
+			}
+			creds, err := google.CredentialsFromJSON(ctx, keyData, storage.ScopeReadWrite)
+			if err != nil {
+				b.err = fmt.Errorf("failed to parse the service account key file: %w", err)
+				return
+			}
+			opts = []option.ClientOption{option.WithCredentials(creds)}
+		}
+		b.client, b.err = storage.NewClient(context.Background(), opts...)
+	})
+	return b.err
+}
+
+func (b *gcsBucket) get(ctx context.Context, key string) ([]byte, error) {
+	if err := b.init(ctx); err != nil {
+		return nil, err
+	}
+	r, err := b.client.Bucket(b.name).Object(key).NewReader(ctx)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotExist) {
+			return nil, errObjectNotFound
+		}
+		return nil, err
+	}
+	defer func() {
+		_ = r.Close()
+	}()
+	return ioutil.ReadAll(r)
+}
+
+func (b *gcsBucket) put(ctx context.Context, key string, data []byte) error {
+	if err := b.init(ctx); err != nil {
+		return err
+	}
+	w := b.client.Bucket(b.name).Object(key).NewWriter(ctx)
+	w.ContentType = "application/json"
+	if _, err := w.Write(data); err != nil {
+		_ = w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+type snapshotter interface {
+	Snapshot(w io.Writer) (int64, error)
+}
+
+// restoreState replaces the local snapshot file of kind with the snapshot of the state store.
+// The local snapshot file is kept if the state store has no snapshot or if it's corrupted.
+func (am *Alertmanager) restoreState(kind, file string) {
+	if am.stateStore == nil {
+		return
+	}
+
+	state, err := am.stateStore.GetAlertmanagerState(am.orgID, kind)
+	if err != nil {
+		if !errors.Is(err, ngmodels.ErrNoAlertmanagerState) {
+			am.logger.Error("failed to get the Alertmanager state, using the local snapshot", "kind", kind, "err", err)
+		}
+		return
+	}
+	snapshot, err := state.Snapshot()
+	if err != nil {
+		am.logger.Error("failed to restore the Alertmanager state, using the local snapshot", "kind", kind, "err", err)
+		return
+	}
+	if err := writeFileAtomically(file, snapshot); err != nil {
+		am.logger.Error("failed to restore the Alertmanager state, using the local snapshot", "kind", kind, "err", err)
+		return
+	}
+	am.persistedState[kind] = state.Checksum
+	am.logger.Debug("restored the Alertmanager state", "kind", kind)
+}
+
+// discardCorruptedSnapshot removes the local snapshot file if it can't be loaded,
+// starting with an empty state is better than not starting at all.
+func (am *Alertmanager) discardCorruptedSnapshot(file string, load func() error) {
+	if err := load(); err != nil {
+		am.logger.Error("failed to load the local snapshot, discarding it", "file", file, "err", err)
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			am.logger.Error("failed to remove the local snapshot", "file", file, "err", err)
+		}
+	}
+}
+
+// persistState saves the snapshots of the notification log and the silences that changed since they were last saved.
+func (am *Alertmanager) persistState() {
+	for kind, s := range map[string]snapshotter{
+		ngmodels.AlertmanagerStateNotificationLog: am.notificationLog,
+		ngmodels.AlertmanagerStateSilences:        am.silences,
+	} {
+		var buf bytes.Buffer
+		if _, err := s.Snapshot(&buf); err != nil {
+			am.logger.Error("failed to snapshot the Alertmanager state", "kind", kind, "err", err)
+			continue
+		}
+
+		state := ngmodels.NewAlertmanagerState(am.orgID, kind, buf.Bytes())
+		if am.persistedState[kind] == state.Checksum {
+			continue
+		}
+		if err := am.stateStore.SaveAlertmanagerState(state); err != nil {
+			am.logger.Error("failed to persist the Alertmanager state", "kind", kind, "err", err)
+			continue
+		}
+		am.persistedState[kind] = state.Checksum
+	}
+}
+
+// runStatePersistence persists the state periodically, and a last time when the Alertmanager stops.
+func (am *Alertmanager) runStatePersistence() {
+	defer am.wg.Done()
+
+	interval := am.Settings.AlertmanagerStateSyncInterval
+	if interval <= 0 {
+		interval = defaultStateSyncInterval
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			am.persistState()
+		case <-am.stopc:
+			am.persistState()
+			return
+		}
+	}
+}
+
+// writeFileAtomically writes the file through a temporary file, so it's never left partially written.
+func writeFileAtomically(file string, b []byte) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0750); err != nil {
+		return err
+	}
+	tmp := file + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0640); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
@@ -0,0 +1,89 @@
+package notifier
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-openapi/strfmt"
+	"github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestAlertmanagerStatePersistence(t *testing.T) {
+	stateStore := &FakeConfigStore{configs: map[int64]*ngmodels.AlertConfiguration{}}
+	newAM := func() *Alertmanager {
+		dir, err := ioutil.TempDir("", "")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			require.NoError(t, os.RemoveAll(dir))
+		})
+		am, err := newAlertmanager(1, &setting.Cfg{DataPath: dir}, stateStore, stateStore, &NilPeer{}, metrics.NewMetrics(prometheus.NewRegistry()))
+		require.NoError(t, err)
+		return am
+	}
+
+	am := newAM()
+	name, value, isRegex := "alertname", "Maintenance", false
+	startsAt, endsAt := strfmt.DateTime(time.Now()), strfmt.DateTime(time.Now().Add(time.Hour))
+	comment, createdBy := "maintenance", "admin"
+	silenceID, err := am.CreateSilence(&apimodels.PostableSilence{Silence: models.Silence{
+		Comment:   &comment,
+		CreatedBy: &createdBy,
+		StartsAt:  &startsAt,
+		EndsAt:    &endsAt,
+		Matchers:  models.Matchers{{Name: &name, Value: &value, IsRegex: &isRegex}},
+	}})
+	require.NoError(t, err)
+	am.StopAndWait()
+
+	// The state is persisted when the Alertmanager stops.
+	state, err := stateStore.GetAlertmanagerState(1, ngmodels.AlertmanagerStateSilences)
+	require.NoError(t, err)
+	_, err = state.Snapshot()
+	require.NoError(t, err)
+	_, err = stateStore.GetAlertmanagerState(1, ngmodels.AlertmanagerStateNotificationLog)
+	require.NoError(t, err)
+
+	// Another instance restores the silences from the state store.
+	other := newAM()
+	s, err := other.GetSilence(silenceID)
+	require.NoError(t, err)
+	require.Equal(t, comment, *s.Comment)
+	other.StopAndWait()
+
+	t.Run("corrupted state is ignored", func(t *testing.T) {
+		state.Checksum = "invalid"
+		require.NoError(t, stateStore.SaveAlertmanagerState(state))
+
+		am := newAM()
+		_, err := am.GetSilence(silenceID)
+		require.ErrorIs(t, err, ErrSilenceNotFound)
+		am.StopAndWait()
+	})
+
+	t.Run("corrupted local snapshot is discarded", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			require.NoError(t, os.RemoveAll(dir))
+		})
+		cfg := &setting.Cfg{DataPath: dir}
+		orgDir := filepath.Join(dir, workingDir, "1")
+		require.NoError(t, os.MkdirAll(orgDir, 0750))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(orgDir, "silences"), []byte("corrupted"), 0640))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(orgDir, "notifications"), []byte("corrupted"), 0640))
+
+		am, err := newAlertmanager(1, cfg, stateStore, nil, &NilPeer{}, metrics.NewMetrics(prometheus.NewRegistry()))
+		require.NoError(t, err)
+		am.StopAndWait()
+	})
+}
@@ -2,13 +2,18 @@ package notifier
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 )
 
 type FakeConfigStore struct {
-	configs map[int64]*models.AlertConfiguration
+	configs           map[int64]*models.AlertConfiguration
+	recurringSilences []*models.RecurringSilence
+	maintenanceModes  map[int64]*models.MaintenanceMode
+	labelPolicies     map[int64]*models.LabelPolicies
+	states            map[string]*models.AlertmanagerState
 }
 
 func (f *FakeConfigStore) GetLatestAlertmanagerConfiguration(query *models.GetLatestAlertmanagerConfigurationQuery) error {
@@ -47,6 +52,105 @@ func (f *FakeConfigStore) SaveAlertmanagerConfigurationWithCallback(cmd *models.
 	return nil
 }
 
+func (f *FakeConfigStore) GetRecurringSilence(query *models.GetRecurringSilenceQuery) error {
+	for _, s := range f.recurringSilences {
+		if s.OrgID == query.OrgID && s.UID == query.UID {
+			query.Result = s
+			return nil
+		}
+	}
+	return models.ErrRecurringSilenceNotFound
+}
+
+func (f *FakeConfigStore) ListRecurringSilences(query *models.ListRecurringSilencesQuery) error {
+	for _, s := range f.recurringSilences {
+		if query.OrgID == 0 || s.OrgID == query.OrgID {
+			query.Result = append(query.Result, s)
+		}
+	}
+	return nil
+}
+
+func (f *FakeConfigStore) SaveRecurringSilence(cmd *models.SaveRecurringSilenceCmd) error {
+	f.recurringSilences = append(f.recurringSilences, cmd.RecurringSilence)
+	return nil
+}
+
+func (f *FakeConfigStore) DeleteRecurringSilence(orgID int64, uid string) error {
+	for i, s := range f.recurringSilences {
+		if s.OrgID == orgID && s.UID == uid {
+			f.recurringSilences = append(f.recurringSilences[:i], f.recurringSilences[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func (f *FakeConfigStore) UpdateRecurringSilenceMaterialization(cmd *models.UpdateRecurringSilenceMaterializationCmd) error {
+	for _, s := range f.recurringSilences {
+		if s.OrgID == cmd.OrgID && s.UID == cmd.UID {
+			s.LastStartsAt = cmd.LastStartsAt
+			s.LastSilenceID = cmd.LastSilenceID
+		}
+	}
+	return nil
+}
+
+func (f *FakeConfigStore) GetMaintenanceMode(orgID int64) (*models.MaintenanceMode, error) {
+	if m, ok := f.maintenanceModes[orgID]; ok {
+		return m, nil
+	}
+	return nil, models.ErrNoMaintenanceMode
+}
+
+func (f *FakeConfigStore) SaveMaintenanceMode(m *models.MaintenanceMode) error {
+	if f.maintenanceModes == nil {
+		f.maintenanceModes = map[int64]*models.MaintenanceMode{}
+	}
+	f.maintenanceModes[m.OrgID] = m
+	return nil
+}
+
+func (f *FakeConfigStore) DeleteMaintenanceMode(orgID int64) error {
+	delete(f.maintenanceModes, orgID)
+	return nil
+}
+
+func (f *FakeConfigStore) GetLabelPolicies(orgID int64) (*models.LabelPolicies, error) {
+	if p, ok := f.labelPolicies[orgID]; ok {
+		return p, nil
+	}
+	return nil, models.ErrNoLabelPolicies
+}
+
+func (f *FakeConfigStore) SaveLabelPolicies(p *models.LabelPolicies) error {
+	if f.labelPolicies == nil {
+		f.labelPolicies = map[int64]*models.LabelPolicies{}
+	}
+	f.labelPolicies[p.OrgID] = p
+	return nil
+}
+
+func (f *FakeConfigStore) DeleteLabelPolicies(orgID int64) error {
+	delete(f.labelPolicies, orgID)
+	return nil
+}
+
+func (f *FakeConfigStore) GetAlertmanagerState(orgID int64, kind string) (*models.AlertmanagerState, error) {
+	if s, ok := f.states[fmt.Sprintf("%d/%s", orgID, kind)]; ok {
+		return s, nil
+	}
+	return nil, models.ErrNoAlertmanagerState
+}
+
+func (f *FakeConfigStore) SaveAlertmanagerState(s *models.AlertmanagerState) error {
+	if f.states == nil {
+		f.states = map[string]*models.AlertmanagerState{}
+	}
+	f.states[fmt.Sprintf("%d/%s", s.OrgID, s.Kind)] = s
+	return nil
+}
+
 type FakeOrgStore struct {
 	orgs []int64
 }
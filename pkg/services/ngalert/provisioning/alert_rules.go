@@ -0,0 +1,271 @@
+package provisioning
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// AlertRuleService manages the provisioned alert rules.
+type AlertRuleService struct {
+	ruleStore       store.RuleStore
+	provenanceStore store.ProvisioningStore
+	log             log.Logger
+}
+
+func NewAlertRuleService(ruleStore store.RuleStore, provenanceStore store.ProvisioningStore, log log.Logger) *AlertRuleService {
+	return &AlertRuleService{
+		ruleStore:       ruleStore,
+		provenanceStore: provenanceStore,
+		log:             log,
+	}
+}
+
+// ReplaceRuleGroup replaces the rules of a rule group with the rules of the config. Every rule must have a UID,
+// the rules that don't exist are created with it. The group is not updated if its rules are unchanged, so that
+// provisioning the same files again doesn't create new versions of the rules. updatedBy is recorded as the creator
+// of the new versions, and the user updatedByUserID becomes the owner of the rules that are created or whose queries
+// change, 0 being the service identity of the rules provisioned from files.
+func (s *AlertRuleService) ReplaceRuleGroup(orgID int64, namespaceUID string, group apimodels.PostableRuleGroupConfig, provenance ngmodels.Provenance, updatedBy string, updatedByUserID int64) error {
+	for _, r := range group.Rules {
+		if r.GrafanaManagedAlert == nil {
+			return fmt.Errorf("rule group %q has a rule that is not a Grafana managed rule", group.Name)
+		}
+		if r.GrafanaManagedAlert.UID == "" {
+			return fmt.Errorf("rule %q of rule group %q has no UID", r.GrafanaManagedAlert.Title, group.Name)
+		}
+	}
+
+	q := ngmodels.ListRuleGroupAlertRulesQuery{
+		OrgID:        orgID,
+		NamespaceUID: namespaceUID,
+		RuleGroup:    group.Name,
+	}
+	if err := s.ruleStore.GetRuleGroupAlertRules(&q); err != nil {
+		return fmt.Errorf("failed to get the rules of rule group %q: %w", group.Name, err)
+	}
+
+	if ruleGroupChanged(q.Result, orgID, namespaceUID, group) {
+		s.log.Debug("updating provisioned rule group", "org", orgID, "namespace", namespaceUID, "group", group.Name)
+		err := s.ruleStore.UpdateRuleGroup(store.UpdateRuleGroupCmd{
+			OrgID:           orgID,
+			NamespaceUID:    namespaceUID,
+			RuleGroupConfig: group,
+			CreateWithUID:   true,
+			UpdatedBy:       updatedBy,
+			UpdatedByUserID: updatedByUserID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update rule group %q: %w", group.Name, err)
+		}
+	}
+
+	kept := make(map[string]struct{}, len(group.Rules))
+	for _, r := range group.Rules {
+		kept[r.GrafanaManagedAlert.UID] = struct{}{}
+		if err := s.provenanceStore.SetProvenance(orgID, ngmodels.ResourceTypeAlertRule, r.GrafanaManagedAlert.UID, provenance); err != nil {
+			return err
+		}
+	}
+	for _, r := range q.Result {
+		if _, ok := kept[r.UID]; ok {
+			continue
+		}
+		if err := s.provenanceStore.SetProvenance(orgID, ngmodels.ResourceTypeAlertRule, r.UID, ngmodels.ProvenanceNone); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetAlertRule returns an alert rule and its provenance.
+func (s *AlertRuleService) GetAlertRule(orgID int64, uid string) (*ngmodels.AlertRule, ngmodels.Provenance, error) {
+	q := ngmodels.GetAlertRuleByUIDQuery{OrgID: orgID, UID: uid}
+	if err := s.ruleStore.GetAlertRuleByUID(&q); err != nil {
+		return nil, ngmodels.ProvenanceNone, err
+	}
+	provenance, err := s.provenanceStore.GetProvenance(orgID, ngmodels.ResourceTypeAlertRule, uid)
+	if err != nil {
+		return nil, ngmodels.ProvenanceNone, err
+	}
+	return q.Result, provenance, nil
+}
+
+// GetRuleGroup returns the rules of a rule group and their provenance, by UID.
+func (s *AlertRuleService) GetRuleGroup(orgID int64, namespaceUID, group string) ([]*ngmodels.AlertRule, map[string]ngmodels.Provenance, error) {
+	q := ngmodels.ListRuleGroupAlertRulesQuery{
+		OrgID:        orgID,
+		NamespaceUID: namespaceUID,
+		RuleGroup:    group,
+	}
+	if err := s.ruleStore.GetRuleGroupAlertRules(&q); err != nil {
+		return nil, nil, err
+	}
+	provenances, err := s.provenanceStore.GetProvenances(orgID, ngmodels.ResourceTypeAlertRule)
+	if err != nil {
+		return nil, nil, err
+	}
+	return q.Result, provenances, nil
+}
+
+// SaveAlertRule creates an alert rule, or updates the alert rule with the same UID, and sets its provenance.
+// A UID is generated for new rules without one. The rule gets the interval of its rule group, new groups get
+// the default interval. If the rule has a version, the update fails with ngmodels.ErrAlertRuleVersionConflict
+// when the rule has been changed since that version. The user updatedByUserID becomes the owner of the rule if it
+// is created or its queries change.
+func (s *AlertRuleService) SaveAlertRule(rule ngmodels.AlertRule, provenance ngmodels.Provenance, updatedBy string, updatedByUserID int64) (*ngmodels.AlertRule, error) {
+	var existing *ngmodels.AlertRule
+	if rule.UID == "" {
+		rule.UID = util.GenerateShortUID()
+	} else {
+		q := ngmodels.GetAlertRuleByUIDQuery{OrgID: rule.OrgID, UID: rule.UID}
+		err := s.ruleStore.GetAlertRuleByUID(&q)
+		switch {
+		case err == nil:
+			existing = q.Result
+		case !errors.Is(err, ngmodels.ErrAlertRuleNotFound):
+			return nil, err
+		}
+	}
+	expectedVersion := rule.Version
+	if expectedVersion != 0 && (existing == nil || existing.Version != expectedVersion) {
+		return nil, fmt.Errorf("%w: rule %q was changed since version %d", ngmodels.ErrAlertRuleVersionConflict, rule.UID, expectedVersion)
+	}
+	if existing != nil && (existing.NamespaceUID != rule.NamespaceUID || existing.RuleGroup != rule.RuleGroup) {
+		return nil, fmt.Errorf("%w: rule %q cannot be moved to another folder or rule group", ngmodels.ErrAlertRuleFailedValidation, rule.UID)
+	}
+
+	q := ngmodels.ListRuleGroupAlertRulesQuery{
+		OrgID:        rule.OrgID,
+		NamespaceUID: rule.NamespaceUID,
+		RuleGroup:    rule.RuleGroup,
+	}
+	if err := s.ruleStore.GetRuleGroupAlertRules(&q); err != nil {
+		return nil, fmt.Errorf("failed to get the rules of rule group %q: %w", rule.RuleGroup, err)
+	}
+	rule.IntervalSeconds = 0
+	rule.GroupConcurrency = 0
+	if len(q.Result) > 0 {
+		rule.IntervalSeconds = q.Result[0].IntervalSeconds
+		rule.GroupConcurrency = q.Result[0].GroupConcurrency
+	}
+
+	// Saving the same rule again doesn't create a new version of it.
+	desired := rule
+	desired.Data = append([]ngmodels.AlertQuery(nil), rule.Data...)
+	if existing == nil || desired.PreSave(time.Now) != nil || !sameRule(existing, &desired) {
+		if err := s.ruleStore.UpsertAlertRules([]store.UpsertRule{{Existing: existing, New: rule, CreateWithUID: true, UpdatedBy: updatedBy, UpdatedByUserID: updatedByUserID, ExpectedVersion: expectedVersion}}); err != nil {
+			return nil, fmt.Errorf("failed to save rule %q: %w", rule.UID, err)
+		}
+	}
+	if err := s.provenanceStore.SetProvenance(rule.OrgID, ngmodels.ResourceTypeAlertRule, rule.UID, provenance); err != nil {
+		return nil, err
+	}
+
+	saved := ngmodels.GetAlertRuleByUIDQuery{OrgID: rule.OrgID, UID: rule.UID}
+	if err := s.ruleStore.GetAlertRuleByUID(&saved); err != nil {
+		return nil, err
+	}
+	return saved.Result, nil
+}
+
+// DeleteAlertRule deletes an alert rule and its provenance. Deleting a rule that doesn't exist is not an error.
+func (s *AlertRuleService) DeleteAlertRule(orgID int64, uid string) error {
+	if err := s.ruleStore.DeleteAlertRuleByUID(orgID, uid); err != nil {
+		return fmt.Errorf("failed to delete rule %q: %w", uid, err)
+	}
+	return s.provenanceStore.SetProvenance(orgID, ngmodels.ResourceTypeAlertRule, uid, ngmodels.ProvenanceNone)
+}
+
+// ruleGroupChanged returns true if the existing rules of a group differ from the rules of the config.
+func ruleGroupChanged(existing []*ngmodels.AlertRule, orgID int64, namespaceUID string, group apimodels.PostableRuleGroupConfig) bool {
+	if len(existing) != len(group.Rules) {
+		return true
+	}
+
+	existingByUID := make(map[string]*ngmodels.AlertRule, len(existing))
+	for _, r := range existing {
+		existingByUID[r.UID] = r
+	}
+
+	for _, r := range group.Rules {
+		e, ok := existingByUID[r.GrafanaManagedAlert.UID]
+		if !ok {
+			return true
+		}
+		desired := ngmodels.AlertRule{
+			OrgID:           orgID,
+			Title:           r.GrafanaManagedAlert.Title,
+			Condition:       r.GrafanaManagedAlert.Condition,
+			Data:            append([]ngmodels.AlertQuery(nil), r.GrafanaManagedAlert.Data...),
+			IntervalSeconds: int64(time.Duration(group.Interval).Seconds()),
+			UID:             r.GrafanaManagedAlert.UID,
+			NamespaceUID:    namespaceUID,
+			RuleGroup:       group.Name,
+			NoDataState:     ngmodels.NoDataState(r.GrafanaManagedAlert.NoDataState),
+			ExecErrState:    ngmodels.ExecutionErrorState(r.GrafanaManagedAlert.ExecErrState),
+		}
+		desired.GroupConcurrency = group.Concurrency
+		if r.ApiRuleNode != nil {
+			desired.For = time.Duration(r.ApiRuleNode.For)
+			desired.Annotations = r.ApiRuleNode.Annotations
+			desired.Labels = r.ApiRuleNode.Labels
+		}
+		// The queries are normalized when they are saved.
+		if err := desired.PreSave(time.Now); err != nil || !sameRule(e, &desired) {
+			return true
+		}
+	}
+	return false
+}
+
+func sameRule(existing, desired *ngmodels.AlertRule) bool {
+	if existing.OrgID != desired.OrgID ||
+		existing.Title != desired.Title ||
+		existing.Condition != desired.Condition ||
+		existing.IntervalSeconds != desired.IntervalSeconds ||
+		existing.GroupConcurrency != desired.GroupConcurrency ||
+		existing.NamespaceUID != desired.NamespaceUID ||
+		existing.RuleGroup != desired.RuleGroup ||
+		existing.For != desired.For ||
+		!sameLabels(existing.Annotations, desired.Annotations) ||
+		!sameLabels(existing.Labels, desired.Labels) {
+		return false
+	}
+	if desired.NoDataState != "" && existing.NoDataState != desired.NoDataState {
+		return false
+	}
+	if desired.ExecErrState != "" && existing.ExecErrState != desired.ExecErrState {
+		return false
+	}
+
+	if len(existing.Data) != len(desired.Data) {
+		return false
+	}
+	for i := range existing.Data {
+		e, d := existing.Data[i], desired.Data[i]
+		if e.RefID != d.RefID || e.QueryType != d.QueryType || e.DatasourceUID != d.DatasourceUID || e.RelativeTimeRange != d.RelativeTimeRange {
+			return false
+		}
+		var em, dm interface{}
+		if json.Unmarshal(e.Model, &em) != nil || json.Unmarshal(d.Model, &dm) != nil || !reflect.DeepEqual(em, dm) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameLabels(a, b map[string]string) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
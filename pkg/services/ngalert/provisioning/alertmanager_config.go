@@ -0,0 +1,320 @@
+package provisioning
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/alertmanager/config"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// ErrInvalidConfigChanges is an error for changes resulting in an invalid Alertmanager configuration.
+var ErrInvalidConfigChanges = errors.New("invalid Alertmanager configuration changes")
+
+// AlertmanagerConfigChanges are changes made at once to the contact points, notification policies and
+// templates of the Alertmanager configuration of an organization.
+type AlertmanagerConfigChanges struct {
+	// ContactPoints are created, or replace the contact points with the same name.
+	ContactPoints []*apimodels.PostableApiReceiver
+	// DeleteContactPoints are the names of the contact points to delete.
+	DeleteContactPoints []string
+	// Policies replaces the notification policy tree.
+	Policies *config.Route
+	// ResetPolicies resets the notification policy tree to the default one.
+	ResetPolicies bool
+	// EditPolicies changes the notification policy tree in place, after Policies and ResetPolicies.
+	EditPolicies func(root *config.Route) error
+	// ResolvedNotifications replace the resolved notification settings of the routes after EditPolicies, nil
+	// settings remove them. EditPolicies can add the routes it changes to the map.
+	ResolvedNotifications map[*config.Route]*apimodels.ResolvedNotificationSettings
+	// Templates are created, or replace the templates with the same name.
+	Templates map[string]string
+	// DeleteTemplates are the names of the templates to delete.
+	DeleteTemplates []string
+}
+
+func (c AlertmanagerConfigChanges) empty() bool {
+	return len(c.ContactPoints) == 0 && len(c.DeleteContactPoints) == 0 && c.Policies == nil && !c.ResetPolicies &&
+		c.EditPolicies == nil && len(c.ResolvedNotifications) == 0 && len(c.Templates) == 0 && len(c.DeleteTemplates) == 0
+}
+
+// AlertmanagerConfigService manages the provisioned parts of the Alertmanager configurations.
+type AlertmanagerConfigService struct {
+	store           store.AlertingStore
+	provenanceStore store.ProvisioningStore
+	mam             *notifier.MultiOrgAlertmanager
+	log             log.Logger
+}
+
+func NewAlertmanagerConfigService(store store.AlertingStore, provenanceStore store.ProvisioningStore, mam *notifier.MultiOrgAlertmanager, log log.Logger) *AlertmanagerConfigService {
+	return &AlertmanagerConfigService{
+		store:           store,
+		provenanceStore: provenanceStore,
+		mam:             mam,
+		log:             log,
+	}
+}
+
+// Apply applies the changes to the Alertmanager configuration of an organization and sets the provenance of
+// the changed resources. The configuration is not saved if the changes leave it unchanged.
+func (s *AlertmanagerConfigService) Apply(orgID int64, changes AlertmanagerConfigChanges, provenance ngmodels.Provenance) error {
+	if changes.empty() {
+		return nil
+	}
+
+	cfg, err := s.latestConfig(orgID)
+	if err != nil {
+		return err
+	}
+	before, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+
+	if err := applyConfigChanges(cfg, changes); err != nil {
+		if errors.Is(err, ErrResourceInUse) || errors.Is(err, ErrRouteNotFound) || errors.Is(err, ErrRouteVersionConflict) {
+			return err
+		}
+		return fmt.Errorf("%w: %s", ErrInvalidConfigChanges, err)
+	}
+	after, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+
+	if string(before) != string(after) {
+		s.log.Debug("updating provisioned Alertmanager configuration", "org", orgID)
+		if err := cfg.ProcessConfig(); err != nil {
+			return fmt.Errorf("failed to post process Alertmanager configuration: %w", err)
+		}
+		am, err := s.mam.AlertmanagerFor(orgID)
+		if err != nil {
+			return err
+		}
+		if err := am.SaveAndApplyConfig(cfg); err != nil {
+			if errors.Is(err, store.ErrAlertmanagerConfigurationConflict) {
+				return err
+			}
+			return fmt.Errorf("%w: failed to save and apply Alertmanager configuration: %s", ErrInvalidConfigChanges, err)
+		}
+	}
+
+	return s.setProvenances(orgID, changes, provenance)
+}
+
+// GetContactPoints returns the contact points of an organization with their secure settings decrypted, and their
+// provenance by name.
+func (s *AlertmanagerConfigService) GetContactPoints(orgID int64) ([]*apimodels.PostableApiReceiver, map[string]ngmodels.Provenance, error) {
+	cfg, err := s.latestConfig(orgID)
+	if err != nil {
+		return nil, nil, err
+	}
+	provenances, err := s.provenanceStore.GetProvenances(orgID, ngmodels.ResourceTypeContactPoint)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg.AlertmanagerConfig.Receivers, provenances, nil
+}
+
+// GetPolicies returns the notification policy tree of an organization and its provenance.
+func (s *AlertmanagerConfigService) GetPolicies(orgID int64) (*config.Route, ngmodels.Provenance, error) {
+	cfg, provenance, err := s.policies(orgID)
+	if err != nil {
+		return nil, ngmodels.ProvenanceNone, err
+	}
+	return cfg.AlertmanagerConfig.Route, provenance, nil
+}
+
+// policies returns the Alertmanager configuration of an organization and the provenance of its notification
+// policies.
+func (s *AlertmanagerConfigService) policies(orgID int64) (*apimodels.PostableUserConfig, ngmodels.Provenance, error) {
+	cfg, err := s.latestConfig(orgID)
+	if err != nil {
+		return nil, ngmodels.ProvenanceNone, err
+	}
+	provenance, err := s.provenanceStore.GetProvenance(orgID, ngmodels.ResourceTypeNotificationPolicy, ngmodels.NotificationPolicyResourceKey)
+	if err != nil {
+		return nil, ngmodels.ProvenanceNone, err
+	}
+	return cfg, provenance, nil
+}
+
+// GetTemplates returns the notification templates of an organization, and their provenance by name.
+func (s *AlertmanagerConfigService) GetTemplates(orgID int64) (map[string]string, map[string]ngmodels.Provenance, error) {
+	cfg, err := s.latestConfig(orgID)
+	if err != nil {
+		return nil, nil, err
+	}
+	provenances, err := s.provenanceStore.GetProvenances(orgID, ngmodels.ResourceTypeTemplate)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg.TemplateFiles, provenances, nil
+}
+
+func (s *AlertmanagerConfigService) setProvenances(orgID int64, changes AlertmanagerConfigChanges, provenance ngmodels.Provenance) error {
+	for _, r := range changes.ContactPoints {
+		if err := s.provenanceStore.SetProvenance(orgID, ngmodels.ResourceTypeContactPoint, r.Name, provenance); err != nil {
+			return err
+		}
+	}
+	for _, name := range changes.DeleteContactPoints {
+		if err := s.provenanceStore.SetProvenance(orgID, ngmodels.ResourceTypeContactPoint, name, ngmodels.ProvenanceNone); err != nil {
+			return err
+		}
+	}
+	for name := range changes.Templates {
+		if err := s.provenanceStore.SetProvenance(orgID, ngmodels.ResourceTypeTemplate, name, provenance); err != nil {
+			return err
+		}
+	}
+	for _, name := range changes.DeleteTemplates {
+		if err := s.provenanceStore.SetProvenance(orgID, ngmodels.ResourceTypeTemplate, name, ngmodels.ProvenanceNone); err != nil {
+			return err
+		}
+	}
+	switch {
+	case changes.Policies != nil, changes.EditPolicies != nil, len(changes.ResolvedNotifications) > 0:
+		return s.provenanceStore.SetProvenance(orgID, ngmodels.ResourceTypeNotificationPolicy, ngmodels.NotificationPolicyResourceKey, provenance)
+	case changes.ResetPolicies:
+		return s.provenanceStore.SetProvenance(orgID, ngmodels.ResourceTypeNotificationPolicy, ngmodels.NotificationPolicyResourceKey, ngmodels.ProvenanceNone)
+	}
+	return nil
+}
+
+// latestConfig returns the latest Alertmanager configuration of an organization, with decrypted secure settings
+// so that they are encrypted again with the secure settings of the changes.
+func (s *AlertmanagerConfigService) latestConfig(orgID int64) (*apimodels.PostableUserConfig, error) {
+	q := ngmodels.GetLatestAlertmanagerConfigurationQuery{OrgID: orgID}
+	if err := s.store.GetLatestAlertmanagerConfiguration(&q); err != nil {
+		if errors.Is(err, store.ErrNoAlertmanagerConfiguration) {
+			return notifier.LoadDefault()
+		}
+		return nil, fmt.Errorf("failed to get latest configuration: %w", err)
+	}
+
+	cfg, err := notifier.Load([]byte(q.Result.AlertmanagerConfiguration))
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.DecryptSecureSettings(); err != nil {
+		return nil, err
+	}
+	// The changes made from this configuration fail if it is changed meanwhile.
+	cfg.Fingerprint = q.Result.Fingerprint()
+	return cfg, nil
+}
+
+// applyConfigChanges applies the changes to an Alertmanager configuration, the deletions first.
+func applyConfigChanges(cfg *apimodels.PostableUserConfig, changes AlertmanagerConfigChanges) error {
+	amConfig := &cfg.AlertmanagerConfig
+
+	for _, name := range changes.DeleteContactPoints {
+		for i, r := range amConfig.Receivers {
+			if r.Name == name {
+				amConfig.Receivers = append(amConfig.Receivers[:i], amConfig.Receivers[i+1:]...)
+				break
+			}
+		}
+	}
+	for _, name := range changes.DeleteTemplates {
+		delete(cfg.TemplateFiles, name)
+	}
+
+	for _, cp := range changes.ContactPoints {
+		if cp.Type() != apimodels.GrafanaReceiverType {
+			return fmt.Errorf("contact point %q must have Grafana managed receivers", cp.Name)
+		}
+		replaced := false
+		for i, r := range amConfig.Receivers {
+			if r.Name == cp.Name {
+				copySecureSettings(r, cp)
+				amConfig.Receivers[i] = cp
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			amConfig.Receivers = append(amConfig.Receivers, cp)
+		}
+	}
+
+	routesBefore := apimodels.RouteIDs(amConfig.Route)
+	if changes.ResetPolicies {
+		def, err := notifier.LoadDefault()
+		if err != nil {
+			return err
+		}
+		amConfig.Route = def.AlertmanagerConfig.Route
+	}
+	if changes.Policies != nil {
+		amConfig.Route = changes.Policies
+	}
+	if changes.EditPolicies != nil {
+		if err := changes.EditPolicies(amConfig.Route); err != nil {
+			return err
+		}
+	}
+	keepPolicyOwners(amConfig, routesBefore)
+	keepResolvedNotifications(amConfig, routesBefore)
+	if err := setResolvedNotifications(amConfig, changes.ResolvedNotifications); err != nil {
+		return err
+	}
+
+	if len(changes.Templates) > 0 && cfg.TemplateFiles == nil {
+		cfg.TemplateFiles = make(map[string]string, len(changes.Templates))
+	}
+	for name, tmpl := range changes.Templates {
+		cfg.TemplateFiles[name] = tmpl
+	}
+
+	receivers := make(map[string]struct{}, len(amConfig.Receivers))
+	for _, r := range amConfig.Receivers {
+		receivers[r.Name] = struct{}{}
+	}
+	deleted := make([]string, 0, len(changes.DeleteContactPoints))
+	for _, name := range changes.DeleteContactPoints {
+		if _, ok := receivers[name]; !ok {
+			deleted = append(deleted, name)
+		}
+	}
+	if err := checkContactPointsNotUsed(amConfig, deleted); err != nil {
+		return err
+	}
+	for _, name := range apimodels.AllReceivers(amConfig.Route) {
+		if _, ok := receivers[name]; !ok {
+			return fmt.Errorf("the notification policies use the contact point %q that doesn't exist", name)
+		}
+	}
+	return nil
+}
+
+// copySecureSettings copies the secure settings of the receivers of the current contact point that are missing
+// from the receivers with the same UID of the new one, so that they don't have to be sent again.
+func copySecureSettings(current, new *apimodels.PostableApiReceiver) {
+	currentReceivers := make(map[string]*apimodels.PostableGrafanaReceiver, len(current.GrafanaManagedReceivers))
+	for _, r := range current.GrafanaManagedReceivers {
+		currentReceivers[r.UID] = r
+	}
+	for _, r := range new.GrafanaManagedReceivers {
+		cr, ok := currentReceivers[r.UID]
+		if !ok {
+			continue
+		}
+		for key, value := range cr.SecureSettings {
+			if _, ok := r.SecureSettings[key]; ok {
+				continue
+			}
+			if r.SecureSettings == nil {
+				r.SecureSettings = make(map[string]string, len(cr.SecureSettings))
+			}
+			r.SecureSettings[key] = value
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package provisioning
+
+import (
+	"testing"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+)
+
+func grafanaReceiver(name, uid string) *apimodels.PostableApiReceiver {
+	return &apimodels.PostableApiReceiver{
+		Receiver: config.Receiver{Name: name},
+		PostableGrafanaReceivers: apimodels.PostableGrafanaReceivers{
+			GrafanaManagedReceivers: []*apimodels.PostableGrafanaReceiver{{UID: uid, Name: name, Type: "email"}},
+		},
+	}
+}
+
+func receiverNames(cfg *apimodels.PostableUserConfig) []string {
+	names := make([]string, 0, len(cfg.AlertmanagerConfig.Receivers))
+	for _, r := range cfg.AlertmanagerConfig.Receivers {
+		names = append(names, r.Name)
+	}
+	return names
+}
+
+func TestApplyConfigChanges(t *testing.T) {
+	t.Run("contact points are added, replaced and deleted", func(t *testing.T) {
+		cfg, err := notifier.LoadDefault()
+		require.NoError(t, err)
+		cfg.AlertmanagerConfig.Receivers = append(cfg.AlertmanagerConfig.Receivers, grafanaReceiver("old", "old-uid"), grafanaReceiver("replaced", "r1"))
+
+		err = applyConfigChanges(cfg, AlertmanagerConfigChanges{
+			ContactPoints:       []*apimodels.PostableApiReceiver{grafanaReceiver("replaced", "r2"), grafanaReceiver("new", "n1")},
+			DeleteContactPoints: []string{"old"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, []string{"grafana-default-email", "replaced", "new"}, receiverNames(cfg))
+		require.Equal(t, "r2", cfg.AlertmanagerConfig.Receivers[1].GrafanaManagedReceivers[0].UID)
+	})
+
+	t.Run("contact points must be Grafana managed", func(t *testing.T) {
+		cfg, err := notifier.LoadDefault()
+		require.NoError(t, err)
+
+		err = applyConfigChanges(cfg, AlertmanagerConfigChanges{
+			ContactPoints: []*apimodels.PostableApiReceiver{{Receiver: config.Receiver{Name: "empty"}}},
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("policies are replaced and reset", func(t *testing.T) {
+		cfg, err := notifier.LoadDefault()
+		require.NoError(t, err)
+
+		err = applyConfigChanges(cfg, AlertmanagerConfigChanges{
+			ContactPoints: []*apimodels.PostableApiReceiver{grafanaReceiver("team", "t1")},
+			Policies:      &config.Route{Receiver: "team"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "team", cfg.AlertmanagerConfig.Route.Receiver)
+
+		err = applyConfigChanges(cfg, AlertmanagerConfigChanges{ResetPolicies: true})
+		require.NoError(t, err)
+		require.Equal(t, "grafana-default-email", cfg.AlertmanagerConfig.Route.Receiver)
+	})
+
+	t.Run("policies must use existing contact points", func(t *testing.T) {
+		cfg, err := notifier.LoadDefault()
+		require.NoError(t, err)
+
+		err = applyConfigChanges(cfg, AlertmanagerConfigChanges{
+			Policies: &config.Route{Receiver: "default", Routes: []*config.Route{{Receiver: "unknown"}}},
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `"default"`)
+	})
+
+	t.Run("deleting a contact point used by the policies fails", func(t *testing.T) {
+		cfg, err := notifier.LoadDefault()
+		require.NoError(t, err)
+
+		err = applyConfigChanges(cfg, AlertmanagerConfigChanges{DeleteContactPoints: []string{"grafana-default-email"}})
+		require.ErrorIs(t, err, ErrResourceInUse)
+		require.Contains(t, err.Error(), "route")
+	})
+
+	t.Run("deleting a contact point used by an escalation chain fails", func(t *testing.T) {
+		cfg, err := notifier.LoadDefault()
+		require.NoError(t, err)
+		cfg.AlertmanagerConfig.Receivers = append(cfg.AlertmanagerConfig.Receivers, grafanaReceiver("oncall", "o1"))
+		cfg.AlertmanagerConfig.Escalations = []*apimodels.EscalationChain{{
+			Receiver: "grafana-default-email",
+			Steps:    []apimodels.EscalationStep{{Receiver: "oncall"}},
+		}}
+
+		err = applyConfigChanges(cfg, AlertmanagerConfigChanges{DeleteContactPoints: []string{"oncall"}})
+		require.ErrorIs(t, err, ErrResourceInUse)
+		require.Contains(t, err.Error(), `escalation chain of "grafana-default-email"`)
+	})
+
+	t.Run("templates are upserted and deleted", func(t *testing.T) {
+		cfg, err := notifier.LoadDefault()
+		require.NoError(t, err)
+		cfg.TemplateFiles = map[string]string{"old": "old template"}
+
+		err = applyConfigChanges(cfg, AlertmanagerConfigChanges{
+			Templates:       map[string]string{"new": "new template"},
+			DeleteTemplates: []string{"old"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"new": "new template"}, cfg.TemplateFiles)
+	})
+}
@@ -0,0 +1,138 @@
+package provisioning
+
+import (
+	"regexp"
+	"sort"
+
+	"github.com/prometheus/alertmanager/dispatch"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+var (
+	templateUseRe    = regexp.MustCompile(`{{-?\s*template\s+"([^"]+)"`)
+	templateDefineRe = regexp.MustCompile(`{{-?\s*define\s+"([^"]+)"`)
+)
+
+// RuleDependencies are the resources the notifications of the alerts of a set of rules depend on.
+type RuleDependencies struct {
+	// ContactPoints are the names of the contact points the alerts are routed to by the labels of the rules, and of
+	// the contact points of the steps of their escalation chains.
+	ContactPoints []string
+	// MuteTimings are the UIDs of the mute timings muting the alerts by the labels of the rules.
+	MuteTimings []string
+	// Templates are the names of the template files defining the templates used by the contact points.
+	Templates []string
+}
+
+// GetRuleDependencies returns the contact points, mute timings and templates the notifications of the alerts of the
+// rules depend on. The labels of the query results are not known before the evaluation, so the dependencies are
+// found by the labels of the rules.
+func (s *AlertmanagerConfigService) GetRuleDependencies(orgID int64, rules []*ngmodels.AlertRule, muteTimings []*ngmodels.RecurringSilence) (*RuleDependencies, error) {
+	cfg, err := s.latestConfig(orgID)
+	if err != nil {
+		return nil, err
+	}
+	return ruleDependencies(cfg, rules, muteTimings)
+}
+
+func ruleDependencies(cfg *apimodels.PostableUserConfig, rules []*ngmodels.AlertRule, muteTimings []*ngmodels.RecurringSilence) (*RuleDependencies, error) {
+	deps := &RuleDependencies{ContactPoints: []string{}, MuteTimings: []string{}, Templates: []string{}}
+
+	contactPoints := map[string]struct{}{}
+	if cfg.AlertmanagerConfig.Route != nil {
+		route := dispatch.NewRoute(cfg.AlertmanagerConfig.Route, nil)
+		for _, r := range rules {
+			for _, matched := range route.Match(ruleLabelSet(r)) {
+				contactPoints[matched.RouteOpts.Receiver] = struct{}{}
+			}
+		}
+	}
+	for _, chain := range cfg.AlertmanagerConfig.Escalations {
+		if _, ok := contactPoints[chain.Receiver]; !ok {
+			continue
+		}
+		for _, step := range chain.Steps {
+			contactPoints[step.Receiver] = struct{}{}
+		}
+	}
+
+	usedTemplates := map[string]struct{}{}
+	for _, r := range cfg.AlertmanagerConfig.Receivers {
+		if _, ok := contactPoints[r.Name]; !ok {
+			continue
+		}
+		deps.ContactPoints = append(deps.ContactPoints, r.Name)
+		for _, gr := range r.GrafanaManagedReceivers {
+			if gr.Settings != nil {
+				addSettingsTemplateUses(usedTemplates, gr.Settings.Interface())
+			}
+		}
+	}
+	sort.Strings(deps.ContactPoints)
+
+	for _, rs := range muteTimings {
+		usage, err := muteTimingUsage(rs, rules)
+		if err != nil {
+			return nil, err
+		}
+		if len(usage.Rules) > 0 {
+			deps.MuteTimings = append(deps.MuteTimings, rs.UID)
+		}
+	}
+	sort.Strings(deps.MuteTimings)
+
+	deps.Templates = templateFilesDefining(cfg.TemplateFiles, usedTemplates)
+	return deps, nil
+}
+
+// addSettingsTemplateUses adds the templates used by the string values of the settings of a receiver.
+func addSettingsTemplateUses(used map[string]struct{}, v interface{}) {
+	switch v := v.(type) {
+	case string:
+		addTemplateUses(used, v)
+	case map[string]interface{}:
+		for _, e := range v {
+			addSettingsTemplateUses(used, e)
+		}
+	case []interface{}:
+		for _, e := range v {
+			addSettingsTemplateUses(used, e)
+		}
+	}
+}
+
+func addTemplateUses(used map[string]struct{}, text string) {
+	for _, m := range templateUseRe.FindAllStringSubmatch(text, -1) {
+		used[m[1]] = struct{}{}
+	}
+}
+
+// templateFilesDefining returns the names of the template files defining the used templates, and the templates
+// these use in turn.
+func templateFilesDefining(files map[string]string, used map[string]struct{}) []string {
+	included := map[string]struct{}{}
+	for changed := true; changed; {
+		changed = false
+		for name, content := range files {
+			if _, ok := included[name]; ok {
+				continue
+			}
+			for _, m := range templateDefineRe.FindAllStringSubmatch(content, -1) {
+				if _, ok := used[m[1]]; ok {
+					included[name] = struct{}{}
+					addTemplateUses(used, content)
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	result := make([]string, 0, len(included))
+	for name := range included {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
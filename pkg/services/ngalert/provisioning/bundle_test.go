@@ -0,0 +1,71 @@
+package provisioning
+
+import (
+	"testing"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestRuleDependencies(t *testing.T) {
+	receiver := func(name, title string) *apimodels.PostableApiReceiver {
+		return &apimodels.PostableApiReceiver{
+			Receiver: config.Receiver{Name: name},
+			PostableGrafanaReceivers: apimodels.PostableGrafanaReceivers{GrafanaManagedReceivers: []*apimodels.PostableGrafanaReceiver{
+				{Name: name, Type: "slack", Settings: simplejson.NewFromAny(map[string]interface{}{"title": title})},
+			}},
+		}
+	}
+	cfg := &apimodels.PostableUserConfig{
+		TemplateFiles: map[string]string{
+			"db":      `{{ define "db.title" }}{{ template "common.prefix" . }} Database{{ end }}`,
+			"common":  `{{ define "common.prefix" }}[{{ .Status }}]{{ end }}`,
+			"unused":  `{{ define "web.title" }}Web{{ end }}`,
+			"default": `{{ define "default.title" }}Alert{{ end }}`,
+		},
+		AlertmanagerConfig: apimodels.PostableApiAlertingConfig{
+			Config: apimodels.Config{
+				Route: &config.Route{
+					Receiver: "default",
+					Routes: []*config.Route{
+						{Receiver: "db", Matchers: config.Matchers{{Type: labels.MatchEqual, Name: "team", Value: "db"}}},
+						{Receiver: "web", Matchers: config.Matchers{{Type: labels.MatchEqual, Name: "team", Value: "web"}}},
+					},
+				},
+				Escalations: []*apimodels.EscalationChain{{Receiver: "db", Steps: []apimodels.EscalationStep{{Receiver: "oncall"}}}},
+			},
+			Receivers: []*apimodels.PostableApiReceiver{
+				receiver("default", `{{ template "default.title" . }}`),
+				receiver("db", `{{ template "db.title" . }}`),
+				receiver("web", `{{ template "web.title" . }}`),
+				receiver("oncall", "On call"),
+			},
+		},
+	}
+	name, value := "team", "db"
+	muteTimings := []*ngmodels.RecurringSilence{
+		{UID: "db-night", Matchers: amv2.Matchers{{Name: &name, Value: &value}}},
+		{UID: "web-night", Matchers: amv2.Matchers{{Name: &name, Value: new(string)}}},
+	}
+	rules := []*ngmodels.AlertRule{
+		{UID: "a", Title: "Slow queries", Labels: map[string]string{"team": "db"}},
+	}
+
+	deps, err := ruleDependencies(cfg, rules, muteTimings)
+	require.NoError(t, err)
+	require.Equal(t, []string{"db", "oncall"}, deps.ContactPoints)
+	require.Equal(t, []string{"db-night"}, deps.MuteTimings)
+	require.Equal(t, []string{"common", "db"}, deps.Templates)
+
+	deps, err = ruleDependencies(cfg, nil, muteTimings)
+	require.NoError(t, err)
+	require.Empty(t, deps.ContactPoints)
+	require.Empty(t, deps.MuteTimings)
+	require.Empty(t, deps.Templates)
+}
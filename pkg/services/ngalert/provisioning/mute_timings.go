@@ -0,0 +1,144 @@
+package provisioning
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// MuteTimingService manages the provisioned mute timings, the recurring silences muting the alerts at
+// given times of the week.
+type MuteTimingService struct {
+	store           store.RecurringSilenceStore
+	provenanceStore store.ProvisioningStore
+	mam             *notifier.MultiOrgAlertmanager
+	log             log.Logger
+}
+
+func NewMuteTimingService(store store.RecurringSilenceStore, provenanceStore store.ProvisioningStore, mam *notifier.MultiOrgAlertmanager, log log.Logger) *MuteTimingService {
+	return &MuteTimingService{
+		store:           store,
+		provenanceStore: provenanceStore,
+		mam:             mam,
+		log:             log,
+	}
+}
+
+// GetMuteTimings returns the mute timings of an organization and their provenance, by UID. Every recurring silence
+// is a mute timing, the provisioned ones have a provenance.
+func (s *MuteTimingService) GetMuteTimings(orgID int64) ([]*ngmodels.RecurringSilence, map[string]ngmodels.Provenance, error) {
+	q := ngmodels.ListRecurringSilencesQuery{OrgID: orgID}
+	if err := s.store.ListRecurringSilences(&q); err != nil {
+		return nil, nil, err
+	}
+	provenances, err := s.provenanceStore.GetProvenances(orgID, ngmodels.ResourceTypeMuteTiming)
+	if err != nil {
+		return nil, nil, err
+	}
+	return q.Result, provenances, nil
+}
+
+// GetMuteTiming returns a mute timing and its provenance.
+func (s *MuteTimingService) GetMuteTiming(orgID int64, uid string) (*ngmodels.RecurringSilence, ngmodels.Provenance, error) {
+	q := ngmodels.GetRecurringSilenceQuery{OrgID: orgID, UID: uid}
+	if err := s.store.GetRecurringSilence(&q); err != nil {
+		return nil, ngmodels.ProvenanceNone, err
+	}
+	provenance, err := s.provenanceStore.GetProvenance(orgID, ngmodels.ResourceTypeMuteTiming, uid)
+	if err != nil {
+		return nil, ngmodels.ProvenanceNone, err
+	}
+	return q.Result, provenance, nil
+}
+
+// SaveMuteTiming creates a mute timing with its UID, or updates the mute timing with the same UID.
+func (s *MuteTimingService) SaveMuteTiming(rs *ngmodels.RecurringSilence, provenance ngmodels.Provenance) error {
+	if rs.UID == "" {
+		return errors.New("mute timing has no UID")
+	}
+	if err := rs.Validate(); err != nil {
+		return fmt.Errorf("mute timing %q: %w", rs.UID, err)
+	}
+
+	q := ngmodels.GetRecurringSilenceQuery{OrgID: rs.OrgID, UID: rs.UID}
+	err := s.store.GetRecurringSilence(&q)
+	switch {
+	case errors.Is(err, ngmodels.ErrRecurringSilenceNotFound):
+	case err != nil:
+		return err
+	case sameRecurrence(q.Result, rs):
+		// keep the silence of the current occurrence
+		rs.LastStartsAt = q.Result.LastStartsAt
+		rs.LastSilenceID = q.Result.LastSilenceID
+	default:
+		// the silence of the current occurrence is re-created on the next sync
+		if err := s.expire(q.Result); err != nil {
+			return err
+		}
+	}
+
+	if err := s.store.SaveRecurringSilence(&ngmodels.SaveRecurringSilenceCmd{RecurringSilence: rs}); err != nil {
+		return fmt.Errorf("failed to save mute timing %q: %w", rs.UID, err)
+	}
+	return s.provenanceStore.SetProvenance(rs.OrgID, ngmodels.ResourceTypeMuteTiming, rs.UID, provenance)
+}
+
+// DeleteMuteTiming deletes a mute timing and expires its current silence. Deleting a mute timing that
+// doesn't exist is not an error.
+func (s *MuteTimingService) DeleteMuteTiming(orgID int64, uid string) error {
+	q := ngmodels.GetRecurringSilenceQuery{OrgID: orgID, UID: uid}
+	err := s.store.GetRecurringSilence(&q)
+	switch {
+	case errors.Is(err, ngmodels.ErrRecurringSilenceNotFound):
+	case err != nil:
+		return err
+	default:
+		if err := s.expire(q.Result); err != nil {
+			return err
+		}
+		if err := s.store.DeleteRecurringSilence(orgID, uid); err != nil {
+			return fmt.Errorf("failed to delete mute timing %q: %w", uid, err)
+		}
+	}
+	return s.provenanceStore.SetProvenance(orgID, ngmodels.ResourceTypeMuteTiming, uid, ngmodels.ProvenanceNone)
+}
+
+func (s *MuteTimingService) expire(rs *ngmodels.RecurringSilence) error {
+	am, err := s.mam.AlertmanagerFor(rs.OrgID)
+	if err != nil {
+		return err
+	}
+	if err := am.ExpireRecurringSilence(rs); err != nil {
+		return fmt.Errorf("failed to expire the silence of mute timing %q: %w", rs.UID, err)
+	}
+	return nil
+}
+
+func sameRecurrence(a, b *ngmodels.RecurringSilence) bool {
+	if a.StartTime != b.StartTime || a.DurationSeconds != b.DurationSeconds || a.Timezone != b.Timezone ||
+		a.Comment != b.Comment || len(a.Weekdays) != len(b.Weekdays) || len(a.Matchers) != len(b.Matchers) {
+		return false
+	}
+	for i := range a.Weekdays {
+		if a.Weekdays[i] != b.Weekdays[i] {
+			return false
+		}
+	}
+	for i := range a.Matchers {
+		am, bm := a.Matchers[i], b.Matchers[i]
+		if am.Name == nil || bm.Name == nil || am.Value == nil || bm.Value == nil || am.IsRegex == nil || bm.IsRegex == nil {
+			return false
+		}
+		if *am.Name != *bm.Name || *am.Value != *bm.Value || *am.IsRegex != *bm.IsRegex {
+			return false
+		}
+		if (am.IsEqual == nil || *am.IsEqual) != (bm.IsEqual == nil || *bm.IsEqual) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,95 @@
+package provisioning
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/prometheus/alertmanager/config"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+// CheckResolvedNotifications returns an error if a resolved notification policy of the Alertmanager configuration
+// references a route that is not in the notification policy tree.
+func CheckResolvedNotifications(amConfig *apimodels.PostableApiAlertingConfig) error {
+	if len(amConfig.ResolvedNotifications) == 0 {
+		return nil
+	}
+	exists := make(map[string]struct{})
+	for _, id := range apimodels.RouteIDs(amConfig.Route) {
+		exists[id] = struct{}{}
+	}
+	for _, p := range amConfig.ResolvedNotifications {
+		if _, ok := exists[p.RouteID]; !ok {
+			return fmt.Errorf("the resolved notification policy references the notification policy %s that doesn't exist", p.RouteID)
+		}
+	}
+	return nil
+}
+
+// keepResolvedNotifications updates the route IDs of the resolved notification policies after a change of the
+// notification policy tree, like keepPolicyOwners.
+func keepResolvedNotifications(amConfig *apimodels.PostableApiAlertingConfig, before map[*config.Route]string) {
+	if len(amConfig.ResolvedNotifications) == 0 {
+		return
+	}
+	remap := newRouteIDRemap(amConfig.Route, before)
+	policies := amConfig.ResolvedNotifications[:0]
+	for _, p := range amConfig.ResolvedNotifications {
+		id, ok := remap(p.RouteID)
+		if !ok {
+			continue
+		}
+		p.RouteID = id
+		policies = append(policies, p)
+	}
+	amConfig.ResolvedNotifications = policies
+}
+
+// setResolvedNotifications replaces the resolved notification policies of the routes, the routes with nil or zero
+// settings lose theirs. The routes must be in the notification policy tree.
+func setResolvedNotifications(amConfig *apimodels.PostableApiAlertingConfig, settings map[*config.Route]*apimodels.ResolvedNotificationSettings) error {
+	if len(settings) == 0 {
+		return nil
+	}
+	ids := apimodels.RouteIDs(amConfig.Route)
+	changed := make(map[string]*apimodels.ResolvedNotificationSettings, len(settings))
+	changedIDs := make([]string, 0, len(settings))
+	for r, s := range settings {
+		id, ok := ids[r]
+		if !ok {
+			return fmt.Errorf("%w: the route of the resolved notifications is not in the tree", ErrRouteNotFound)
+		}
+		if s != nil {
+			if err := s.Validate(); err != nil {
+				return err
+			}
+		}
+		changed[id] = s
+		changedIDs = append(changedIDs, id)
+	}
+	sort.Strings(changedIDs)
+	policies := amConfig.ResolvedNotifications[:0]
+	for _, p := range amConfig.ResolvedNotifications {
+		if _, ok := changed[p.RouteID]; !ok {
+			policies = append(policies, p)
+		}
+	}
+	for _, id := range changedIDs {
+		if s := changed[id]; s != nil && !s.IsZero() {
+			policies = append(policies, &apimodels.ResolvedNotificationPolicy{RouteID: id, ResolvedNotificationSettings: *s})
+		}
+	}
+	amConfig.ResolvedNotifications = policies
+	return nil
+}
+
+// resolvedNotificationsByRoute returns the settings of the resolved notification policies by route ID.
+func resolvedNotificationsByRoute(policies []*apimodels.ResolvedNotificationPolicy) map[string]*apimodels.ResolvedNotificationSettings {
+	res := make(map[string]*apimodels.ResolvedNotificationSettings, len(policies))
+	for _, p := range policies {
+		s := p.ResolvedNotificationSettings
+		res[p.RouteID] = &s
+	}
+	return res
+}
@@ -0,0 +1,70 @@
+package provisioning
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+func TestResolvedNotifications(t *testing.T) {
+	delayed := apimodels.ResolvedNotificationSettings{Delay: model.Duration(5 * time.Minute)}
+
+	t.Run("policies must reference existing routes", func(t *testing.T) {
+		cfg, id := ownedConfig(t)
+		cfg.AlertmanagerConfig.ResolvedNotifications = []*apimodels.ResolvedNotificationPolicy{{RouteID: id, ResolvedNotificationSettings: delayed}}
+		require.NoError(t, CheckResolvedNotifications(&cfg.AlertmanagerConfig))
+		cfg.AlertmanagerConfig.ResolvedNotifications[0].RouteID = "unknown"
+		require.Error(t, CheckResolvedNotifications(&cfg.AlertmanagerConfig))
+	})
+
+	t.Run("settings are set with the changes of the routes", func(t *testing.T) {
+		cfg, id := ownedConfig(t)
+		changes := AlertmanagerConfigChanges{ResolvedNotifications: map[*config.Route]*apimodels.ResolvedNotificationSettings{}}
+		changes.EditPolicies = func(root *config.Route) error {
+			updated, err := updateRoute(root, id, "", &config.Route{Receiver: "team-a", Match: map[string]string{"team": "a2"}})
+			if err == nil {
+				changes.ResolvedNotifications[updated.Route] = &delayed
+			}
+			return err
+		}
+		require.NoError(t, applyConfigChanges(cfg, changes))
+		updated := routeByReceiver(t, cfg.AlertmanagerConfig.Route, "team-a")
+		require.Equal(t, []*apimodels.ResolvedNotificationPolicy{{RouteID: updated.ID, ResolvedNotificationSettings: delayed}}, cfg.AlertmanagerConfig.ResolvedNotifications)
+
+		// Empty settings remove the policy.
+		err := applyConfigChanges(cfg, AlertmanagerConfigChanges{ResolvedNotifications: map[*config.Route]*apimodels.ResolvedNotificationSettings{
+			updated.Route: {},
+		}})
+		require.NoError(t, err)
+		require.Empty(t, cfg.AlertmanagerConfig.ResolvedNotifications)
+
+		err = applyConfigChanges(cfg, AlertmanagerConfigChanges{ResolvedNotifications: map[*config.Route]*apimodels.ResolvedNotificationSettings{
+			updated.Route: {Suppress: true, Delay: delayed.Delay},
+		}})
+		require.Error(t, err)
+	})
+
+	t.Run("policies follow the routes and are removed with them", func(t *testing.T) {
+		cfg, id := ownedConfig(t)
+		cfg.AlertmanagerConfig.ResolvedNotifications = []*apimodels.ResolvedNotificationPolicy{{RouteID: id, ResolvedNotificationSettings: delayed}}
+		teamB := routeByReceiver(t, cfg.AlertmanagerConfig.Route, "team-b")
+		err := applyConfigChanges(cfg, AlertmanagerConfigChanges{EditPolicies: func(root *config.Route) error {
+			_, err := moveRoute(root, id, "", teamB.ID, -1)
+			return err
+		}})
+		require.NoError(t, err)
+		moved := routeByReceiver(t, cfg.AlertmanagerConfig.Route, "team-a")
+		require.Equal(t, moved.ID, cfg.AlertmanagerConfig.ResolvedNotifications[0].RouteID)
+
+		err = applyConfigChanges(cfg, AlertmanagerConfigChanges{EditPolicies: func(root *config.Route) error {
+			return deleteRoute(root, moved.ID, "")
+		}})
+		require.NoError(t, err)
+		require.Empty(t, cfg.AlertmanagerConfig.ResolvedNotifications)
+	})
+}
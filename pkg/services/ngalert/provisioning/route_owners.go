@@ -0,0 +1,150 @@
+package provisioning
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/alertmanager/config"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+)
+
+// ErrPolicyNotOwned is an error for a change of the Alertmanager configuration outside of the notification
+// policies owned by the teams of the user.
+var ErrPolicyNotOwned = errors.New("notification policy is not owned by the teams of the user")
+
+// CheckPolicyOwners returns an error if a policy owner of the Alertmanager configuration references a route that
+// is not in the notification policy tree.
+func CheckPolicyOwners(amConfig *apimodels.PostableApiAlertingConfig) error {
+	if len(amConfig.PolicyOwners) == 0 {
+		return nil
+	}
+	ids := apimodels.RouteIDs(amConfig.Route)
+	exists := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		exists[id] = struct{}{}
+	}
+	for _, o := range amConfig.PolicyOwners {
+		if _, ok := exists[o.RouteID]; !ok {
+			return fmt.Errorf("the policy owner of team %d references the notification policy %s that doesn't exist", o.TeamID, o.RouteID)
+		}
+	}
+	return nil
+}
+
+// CheckOwnedPolicyChanges returns ErrPolicyNotOwned unless the only changes from the current Alertmanager
+// configuration to the changed one are changes of the routes owned by the teams and of their children. The matchers,
+// the continue flag and the position of the owned routes can't be changed, they decide which alerts the routes get.
+func CheckOwnedPolicyChanges(current, changed *apimodels.PostableUserConfig, teams []int64) error {
+	currentRest, changedRest := *current, *changed
+	currentRest.Fingerprint, changedRest.Fingerprint = "", ""
+	currentRest.AlertmanagerConfig.Route, changedRest.AlertmanagerConfig.Route = nil, nil
+	same, err := sameJSON(currentRest, changedRest)
+	if err != nil {
+		return err
+	}
+	if !same {
+		return fmt.Errorf("%w: only the notification policies can be changed", ErrPolicyNotOwned)
+	}
+
+	owned := ownedRoutes(current.AlertmanagerConfig.PolicyOwners, teams)
+	same, err = sameJSON(policySkeleton(current.AlertmanagerConfig.Route, owned), policySkeleton(changed.AlertmanagerConfig.Route, owned))
+	if err != nil {
+		return err
+	}
+	if !same {
+		return fmt.Errorf("%w: only the notification policies owned by the teams of the user and their children can be changed", ErrPolicyNotOwned)
+	}
+	return nil
+}
+
+func ownedRoutes(owners []*apimodels.PolicyOwner, teams []int64) map[string]struct{} {
+	member := make(map[int64]struct{}, len(teams))
+	for _, t := range teams {
+		member[t] = struct{}{}
+	}
+	owned := make(map[string]struct{})
+	for _, o := range owners {
+		if _, ok := member[o.TeamID]; ok {
+			owned[o.RouteID] = struct{}{}
+		}
+	}
+	return owned
+}
+
+// policySkeleton returns the notification policy tree with the owned routes replaced by their ID and continue flag.
+func policySkeleton(root *config.Route, owned map[string]struct{}) interface{} {
+	if root == nil {
+		return nil
+	}
+	ids := apimodels.RouteIDs(root)
+	var skeleton func(r *config.Route) interface{}
+	skeleton = func(r *config.Route) interface{} {
+		if _, ok := owned[ids[r]]; ok {
+			return map[string]interface{}{"owned": ids[r], "continue": r.Continue}
+		}
+		route := *r
+		route.Routes = nil
+		children := make([]interface{}, 0, len(r.Routes))
+		for _, child := range r.Routes {
+			children = append(children, skeleton(child))
+		}
+		return map[string]interface{}{"route": &route, "routes": children}
+	}
+	return skeleton(root)
+}
+
+// keepPolicyOwners updates the route IDs of the policy owners after a change of the notification policy tree, from
+// the routes they referenced before it. The owners of the routes that were removed are removed, unless a route with
+// the same ID replaced them.
+func keepPolicyOwners(amConfig *apimodels.PostableApiAlertingConfig, before map[*config.Route]string) {
+	if len(amConfig.PolicyOwners) == 0 {
+		return
+	}
+	remap := newRouteIDRemap(amConfig.Route, before)
+	owners := amConfig.PolicyOwners[:0]
+	for _, o := range amConfig.PolicyOwners {
+		id, ok := remap(o.RouteID)
+		if !ok {
+			continue
+		}
+		o.RouteID = id
+		owners = append(owners, o)
+	}
+	amConfig.PolicyOwners = owners
+}
+
+// newRouteIDRemap returns a function returning the ID of a route after a change of the notification policy tree from
+// its ID before it, and false if the route was removed and no route with the same ID replaced it.
+func newRouteIDRemap(root *config.Route, before map[*config.Route]string) func(id string) (string, bool) {
+	beforeRoutes := make(map[string]*config.Route, len(before))
+	for r, id := range before {
+		beforeRoutes[id] = r
+	}
+	after := apimodels.RouteIDs(root)
+	exists := make(map[string]struct{}, len(after))
+	for _, id := range after {
+		exists[id] = struct{}{}
+	}
+	return func(id string) (string, bool) {
+		if newID, ok := after[beforeRoutes[id]]; ok {
+			return newID, true
+		}
+		_, ok := exists[id]
+		return id, ok
+	}
+}
+
+func sameJSON(a, b interface{}) (bool, error) {
+	ab, err := json.Marshal(a)
+	if err != nil {
+		return false, err
+	}
+	bb, err := json.Marshal(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(ab, bb), nil
+}
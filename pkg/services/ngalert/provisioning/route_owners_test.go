@@ -0,0 +1,112 @@
+package provisioning
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/notifier"
+)
+
+func ownedConfig(t *testing.T) (*apimodels.PostableUserConfig, string) {
+	t.Helper()
+	cfg, err := notifier.LoadDefault()
+	require.NoError(t, err)
+	cfg.AlertmanagerConfig.Route = testRouteTree()
+	for _, name := range []string{"default", "team-a", "team-a-critical", "team-b"} {
+		cfg.AlertmanagerConfig.Receivers = append(cfg.AlertmanagerConfig.Receivers, grafanaReceiver(name, name))
+	}
+	teamA := routeByReceiver(t, cfg.AlertmanagerConfig.Route, "team-a")
+	cfg.AlertmanagerConfig.PolicyOwners = []*apimodels.PolicyOwner{{RouteID: teamA.ID, TeamID: 1}}
+	return cfg, teamA.ID
+}
+
+func copyConfig(t *testing.T, cfg *apimodels.PostableUserConfig) *apimodels.PostableUserConfig {
+	t.Helper()
+	b, err := json.Marshal(cfg)
+	require.NoError(t, err)
+	res, err := notifier.Load(b)
+	require.NoError(t, err)
+	return res
+}
+
+func TestCheckOwnedPolicyChanges(t *testing.T) {
+	t.Run("the owned routes and their children can be changed", func(t *testing.T) {
+		current, _ := ownedConfig(t)
+		changed := copyConfig(t, current)
+		teamA := changed.AlertmanagerConfig.Route.Routes[0]
+		teamA.Receiver = "team-a-other"
+		teamA.Routes = append(teamA.Routes, &config.Route{Receiver: "team-a-warning", Match: map[string]string{"severity": "warning"}})
+
+		require.NoError(t, CheckOwnedPolicyChanges(current, changed, []int64{1}))
+		require.ErrorIs(t, CheckOwnedPolicyChanges(current, changed, []int64{2}), ErrPolicyNotOwned)
+	})
+
+	t.Run("the matchers and the position of the owned routes can't be changed", func(t *testing.T) {
+		current, _ := ownedConfig(t)
+		changed := copyConfig(t, current)
+		changed.AlertmanagerConfig.Route.Routes[0].Match = map[string]string{"team": "b"}
+		require.ErrorIs(t, CheckOwnedPolicyChanges(current, changed, []int64{1}), ErrPolicyNotOwned)
+
+		changed = copyConfig(t, current)
+		routes := changed.AlertmanagerConfig.Route.Routes
+		routes[0], routes[1] = routes[1], routes[0]
+		require.ErrorIs(t, CheckOwnedPolicyChanges(current, changed, []int64{1}), ErrPolicyNotOwned)
+	})
+
+	t.Run("the other routes and settings can't be changed", func(t *testing.T) {
+		current, _ := ownedConfig(t)
+		changed := copyConfig(t, current)
+		changed.AlertmanagerConfig.Route.Routes[1].Receiver = "other"
+		require.ErrorIs(t, CheckOwnedPolicyChanges(current, changed, []int64{1}), ErrPolicyNotOwned)
+
+		changed = copyConfig(t, current)
+		changed.TemplateFiles = map[string]string{"new": "{{ define \"new\" }}{{ end }}"}
+		require.ErrorIs(t, CheckOwnedPolicyChanges(current, changed, []int64{1}), ErrPolicyNotOwned)
+
+		changed = copyConfig(t, current)
+		changed.AlertmanagerConfig.PolicyOwners = append(changed.AlertmanagerConfig.PolicyOwners, &apimodels.PolicyOwner{RouteID: RouteRootID, TeamID: 1})
+		require.ErrorIs(t, CheckOwnedPolicyChanges(current, changed, []int64{1}), ErrPolicyNotOwned)
+	})
+}
+
+func TestPolicyOwners(t *testing.T) {
+	t.Run("owners must reference existing routes", func(t *testing.T) {
+		cfg, _ := ownedConfig(t)
+		require.NoError(t, CheckPolicyOwners(&cfg.AlertmanagerConfig))
+		cfg.AlertmanagerConfig.PolicyOwners[0].RouteID = "unknown"
+		require.Error(t, CheckPolicyOwners(&cfg.AlertmanagerConfig))
+	})
+
+	t.Run("owners follow the routes changed individually", func(t *testing.T) {
+		cfg, id := ownedConfig(t)
+		teamB := routeByReceiver(t, cfg.AlertmanagerConfig.Route, "team-b")
+		err := applyConfigChanges(cfg, AlertmanagerConfigChanges{EditPolicies: func(root *config.Route) error {
+			_, err := moveRoute(root, id, "", teamB.ID, -1)
+			return err
+		}})
+		require.NoError(t, err)
+		moved := routeByReceiver(t, cfg.AlertmanagerConfig.Route, "team-a")
+		require.NotEqual(t, id, moved.ID)
+		require.Equal(t, moved.ID, cfg.AlertmanagerConfig.PolicyOwners[0].RouteID)
+	})
+
+	t.Run("owners of removed routes are removed", func(t *testing.T) {
+		cfg, id := ownedConfig(t)
+		err := applyConfigChanges(cfg, AlertmanagerConfigChanges{EditPolicies: func(root *config.Route) error {
+			return deleteRoute(root, id, "")
+		}})
+		require.NoError(t, err)
+		require.Empty(t, cfg.AlertmanagerConfig.PolicyOwners)
+	})
+
+	t.Run("owners are kept when the tree is replaced by the same routes", func(t *testing.T) {
+		cfg, id := ownedConfig(t)
+		err := applyConfigChanges(cfg, AlertmanagerConfigChanges{Policies: testRouteTree()})
+		require.NoError(t, err)
+		require.Equal(t, id, cfg.AlertmanagerConfig.PolicyOwners[0].RouteID)
+	})
+}
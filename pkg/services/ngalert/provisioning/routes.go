@@ -0,0 +1,309 @@
+package provisioning
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/prometheus/alertmanager/config"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+var (
+	// ErrRouteNotFound is an error for an unknown route of the notification policy tree.
+	ErrRouteNotFound = errors.New("could not find notification policy")
+	// ErrRouteVersionConflict is an error for a change of a route of the notification policy tree with a version
+	// that is not the current version of the route.
+	ErrRouteVersionConflict = errors.New("notification policy was changed")
+)
+
+// RouteRootID is the ID of the root of the notification policy tree.
+const RouteRootID = apimodels.RouteRootID
+
+// PolicyRoute is a route of the notification policy tree. Its ID is generated from its matchers and the ID of its
+// parent, so it's kept by the changes of the other routes and of the settings of the route, but not by the changes
+// of its matchers or by its move. Its version is generated from the route and its children, it changes with them.
+type PolicyRoute struct {
+	ID       string
+	ParentID string
+	Version  string
+	Route    *config.Route
+	// ResolvedNotifications are the settings of the resolved notifications of the route, nil if it has none.
+	ResolvedNotifications *apimodels.ResolvedNotificationSettings
+}
+
+// GetRoutes returns the routes of the notification policy tree of an organization, the parents before their
+// children, and the provenance of the tree.
+func (s *AlertmanagerConfigService) GetRoutes(orgID int64) ([]PolicyRoute, ngmodels.Provenance, error) {
+	cfg, provenance, err := s.policies(orgID)
+	if err != nil {
+		return nil, ngmodels.ProvenanceNone, err
+	}
+	routes := policyRoutes(cfg.AlertmanagerConfig.Route)
+	resolved := resolvedNotificationsByRoute(cfg.AlertmanagerConfig.ResolvedNotifications)
+	for i := range routes {
+		routes[i].ResolvedNotifications = resolved[routes[i].ID]
+	}
+	return routes, provenance, nil
+}
+
+// CreateRoute adds the route to the children of the route parentID, at index, or last if index is out of range,
+// with the resolved notification settings if not nil. The version is the expected version of the parent, it's not
+// checked if empty. It returns the created route.
+func (s *AlertmanagerConfigService) CreateRoute(orgID int64, parentID, version string, route *config.Route, resolved *apimodels.ResolvedNotificationSettings, index int, provenance ngmodels.Provenance) (*PolicyRoute, error) {
+	var created *PolicyRoute
+	changes := AlertmanagerConfigChanges{ResolvedNotifications: map[*config.Route]*apimodels.ResolvedNotificationSettings{}}
+	changes.EditPolicies = func(root *config.Route) (err error) {
+		created, err = createRoute(root, parentID, version, route, index)
+		if err == nil && resolved != nil {
+			changes.ResolvedNotifications[route] = resolved
+		}
+		return err
+	}
+	if err := s.Apply(orgID, changes, provenance); err != nil {
+		return nil, err
+	}
+	created.ResolvedNotifications = nonZeroResolvedNotifications(resolved)
+	return created, nil
+}
+
+// UpdateRoute replaces the route with the ID and its resolved notification settings, its children are kept if route
+// has none. The version is the expected version of the route, it's not checked if empty. It returns the updated
+// route, whose ID changes with its matchers.
+func (s *AlertmanagerConfigService) UpdateRoute(orgID int64, id, version string, route *config.Route, resolved *apimodels.ResolvedNotificationSettings, provenance ngmodels.Provenance) (*PolicyRoute, error) {
+	var updated *PolicyRoute
+	changes := AlertmanagerConfigChanges{ResolvedNotifications: map[*config.Route]*apimodels.ResolvedNotificationSettings{}}
+	changes.EditPolicies = func(root *config.Route) (err error) {
+		updated, err = updateRoute(root, id, version, route)
+		if err == nil {
+			changes.ResolvedNotifications[updated.Route] = resolved
+		}
+		return err
+	}
+	if err := s.Apply(orgID, changes, provenance); err != nil {
+		return nil, err
+	}
+	updated.ResolvedNotifications = nonZeroResolvedNotifications(resolved)
+	return updated, nil
+}
+
+// DeleteRoute deletes the route with the ID and its children. The version is the expected version of the route,
+// it's not checked if empty.
+func (s *AlertmanagerConfigService) DeleteRoute(orgID int64, id, version string, provenance ngmodels.Provenance) error {
+	return s.Apply(orgID, AlertmanagerConfigChanges{EditPolicies: func(root *config.Route) error {
+		return deleteRoute(root, id, version)
+	}}, provenance)
+}
+
+// MoveRoute moves the route with the ID and its children to the children of the route parentID, at index, or last
+// if index is out of range. The version is the expected version of the route, it's not checked if empty. It returns
+// the moved route, whose ID changes with its parent.
+func (s *AlertmanagerConfigService) MoveRoute(orgID int64, id, version, parentID string, index int, provenance ngmodels.Provenance) (*PolicyRoute, error) {
+	var moved *PolicyRoute
+	err := s.Apply(orgID, AlertmanagerConfigChanges{EditPolicies: func(root *config.Route) (err error) {
+		moved, err = moveRoute(root, id, version, parentID, index)
+		return err
+	}}, provenance)
+	if err != nil {
+		return nil, err
+	}
+	// The resolved notification settings follow the route to its new ID.
+	cfg, _, err := s.policies(orgID)
+	if err != nil {
+		return nil, err
+	}
+	moved.ResolvedNotifications = resolvedNotificationsByRoute(cfg.AlertmanagerConfig.ResolvedNotifications)[moved.ID]
+	return moved, nil
+}
+
+func nonZeroResolvedNotifications(s *apimodels.ResolvedNotificationSettings) *apimodels.ResolvedNotificationSettings {
+	if s == nil || s.IsZero() {
+		return nil
+	}
+	return s
+}
+
+func createRoute(root *config.Route, parentID, version string, route *config.Route, index int) (*PolicyRoute, error) {
+	parent, err := findRoute(root, parentID, version)
+	if err != nil {
+		return nil, err
+	}
+	parent.Route.Routes = insertRoute(parent.Route.Routes, route, index)
+	return findRouteByPointer(root, route)
+}
+
+func updateRoute(root *config.Route, id, version string, route *config.Route) (*PolicyRoute, error) {
+	current, err := findRoute(root, id, version)
+	if err != nil {
+		return nil, err
+	}
+	if route.Routes == nil {
+		route.Routes = current.Route.Routes
+	}
+	if id == RouteRootID {
+		if hasMatchers(route) {
+			return nil, errors.New("the root route must not have any matchers")
+		}
+		if route.Receiver == "" {
+			return nil, errors.New("the root route must have a receiver")
+		}
+	}
+	*current.Route = *route
+	return findRouteByPointer(root, current.Route)
+}
+
+func deleteRoute(root *config.Route, id, version string) error {
+	if id == RouteRootID {
+		return errors.New("the root route can't be deleted")
+	}
+	current, err := findRoute(root, id, version)
+	if err != nil {
+		return err
+	}
+	current.parent.Routes = removeRoute(current.parent.Routes, current.Route)
+	return nil
+}
+
+func moveRoute(root *config.Route, id, version, parentID string, index int) (*PolicyRoute, error) {
+	if id == RouteRootID {
+		return nil, errors.New("the root route can't be moved")
+	}
+	current, err := findRoute(root, id, version)
+	if err != nil {
+		return nil, err
+	}
+	if parentID == id || findRouteIn(current.Route, id, parentID) {
+		return nil, errors.New("a route can't be moved to its children")
+	}
+	// The parent is found before the route is removed, which can change the IDs of its siblings.
+	parent, err := findRoute(root, parentID, "")
+	if err != nil {
+		return nil, err
+	}
+	current.parent.Routes = removeRoute(current.parent.Routes, current.Route)
+	parent.Route.Routes = insertRoute(parent.Route.Routes, current.Route, index)
+	return findRouteByPointer(root, current.Route)
+}
+
+// routeNode is a route of the tree with its parent.
+type routeNode struct {
+	PolicyRoute
+	parent *config.Route
+}
+
+// walkRoutes calls fn with the routes of the tree, the parents before their children.
+func walkRoutes(root *config.Route, fn func(n routeNode) bool) {
+	if root == nil {
+		return
+	}
+	var walk func(r, parent *config.Route, id, parentID string) bool
+	walk = func(r, parent *config.Route, id, parentID string) bool {
+		if !fn(routeNode{PolicyRoute: PolicyRoute{ID: id, ParentID: parentID, Route: r}, parent: parent}) {
+			return false
+		}
+		for i, childID := range apimodels.ChildRouteIDs(r, id) {
+			if !walk(r.Routes[i], r, childID, id) {
+				return false
+			}
+		}
+		return true
+	}
+	walk(root, nil, RouteRootID, "")
+}
+
+func policyRoutes(root *config.Route) []PolicyRoute {
+	var res []PolicyRoute
+	walkRoutes(root, func(n routeNode) bool {
+		n.Version = routeVersion(n.Route)
+		res = append(res, n.PolicyRoute)
+		return true
+	})
+	return res
+}
+
+// findRoute returns the route with the ID, ErrRouteNotFound if there is none, or ErrRouteVersionConflict if the
+// version is not empty and is not the version of the route.
+func findRoute(root *config.Route, id, version string) (*routeNode, error) {
+	var found *routeNode
+	walkRoutes(root, func(n routeNode) bool {
+		if n.ID != id {
+			return true
+		}
+		found = &n
+		return false
+	})
+	if found == nil {
+		return nil, fmt.Errorf("%w: %s", ErrRouteNotFound, id)
+	}
+	found.Version = routeVersion(found.Route)
+	if version != "" && version != found.Version {
+		return nil, fmt.Errorf("%w: notification policy %s is at version %s, not %s", ErrRouteVersionConflict, id, found.Version, version)
+	}
+	return found, nil
+}
+
+// findRouteIn returns whether the children of the route with the ID, at any depth, include the route targetID.
+func findRouteIn(root *config.Route, id, targetID string) bool {
+	found := false
+	var walk func(r *config.Route, rid string)
+	walk = func(r *config.Route, rid string) {
+		for i, childID := range apimodels.ChildRouteIDs(r, rid) {
+			if childID == targetID {
+				found = true
+			}
+			walk(r.Routes[i], childID)
+		}
+	}
+	walk(root, id)
+	return found
+}
+
+// findRouteByPointer returns the route of the tree that is r, after a change of the tree.
+func findRouteByPointer(root *config.Route, r *config.Route) (*PolicyRoute, error) {
+	var found *PolicyRoute
+	walkRoutes(root, func(n routeNode) bool {
+		if n.Route != r {
+			return true
+		}
+		n.Version = routeVersion(n.Route)
+		found = &n.PolicyRoute
+		return false
+	})
+	if found == nil {
+		return nil, errors.New("the changed notification policy is not in the tree")
+	}
+	return found, nil
+}
+
+func insertRoute(routes []*config.Route, r *config.Route, index int) []*config.Route {
+	if index < 0 || index >= len(routes) {
+		return append(routes, r)
+	}
+	routes = append(routes, nil)
+	copy(routes[index+1:], routes[index:])
+	routes[index] = r
+	return routes
+}
+
+func removeRoute(routes []*config.Route, r *config.Route) []*config.Route {
+	for i, child := range routes {
+		if child == r {
+			return append(routes[:i:i], routes[i+1:]...)
+		}
+	}
+	return routes
+}
+
+func hasMatchers(r *config.Route) bool {
+	return len(r.Matchers) > 0 || len(r.Match) > 0 || len(r.MatchRE) > 0
+}
+
+// routeVersion returns the version of the route and its children.
+func routeVersion(r *config.Route) string {
+	b, _ := json.Marshal(r)
+	sum := sha256.Sum256(b)
+	return fmt.Sprintf("%x", sum[:8])
+}
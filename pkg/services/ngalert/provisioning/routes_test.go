@@ -0,0 +1,132 @@
+package provisioning
+
+import (
+	"testing"
+
+	"github.com/prometheus/alertmanager/config"
+	"github.com/stretchr/testify/require"
+)
+
+func testRouteTree() *config.Route {
+	return &config.Route{
+		Receiver: "default",
+		Routes: []*config.Route{
+			{Receiver: "team-a", Match: map[string]string{"team": "a"}, Routes: []*config.Route{
+				{Receiver: "team-a-critical", Match: map[string]string{"severity": "critical"}},
+			}},
+			{Receiver: "team-b", Match: map[string]string{"team": "b"}},
+		},
+	}
+}
+
+func routeByReceiver(t *testing.T, root *config.Route, receiver string) PolicyRoute {
+	t.Helper()
+	for _, r := range policyRoutes(root) {
+		if r.Route.Receiver == receiver {
+			return r
+		}
+	}
+	require.Failf(t, "route not found", "no route notifies %s", receiver)
+	return PolicyRoute{}
+}
+
+func TestPolicyRoutes(t *testing.T) {
+	t.Run("IDs are kept by the changes of the other routes and of the settings", func(t *testing.T) {
+		root := testRouteTree()
+		critical := routeByReceiver(t, root, "team-a-critical")
+
+		root.Routes = append([]*config.Route{{Receiver: "team-c", Match: map[string]string{"team": "c"}}}, root.Routes...)
+		root.Routes[1].Receiver = "team-a-renamed"
+		root.Routes[1].Routes[0].Receiver = "critical-renamed"
+
+		require.Equal(t, critical.ID, routeByReceiver(t, root, "critical-renamed").ID)
+		require.Equal(t, RouteRootID, policyRoutes(root)[0].ID)
+	})
+
+	t.Run("routes with the same matchers have different IDs", func(t *testing.T) {
+		root := &config.Route{Receiver: "default", Routes: []*config.Route{{Receiver: "a"}, {Receiver: "b"}}}
+		require.NotEqual(t, routeByReceiver(t, root, "a").ID, routeByReceiver(t, root, "b").ID)
+	})
+
+	t.Run("versions change with the route and its children", func(t *testing.T) {
+		root := testRouteTree()
+		teamA := routeByReceiver(t, root, "team-a")
+		teamB := routeByReceiver(t, root, "team-b")
+
+		root.Routes[0].Routes[0].Receiver = "changed"
+
+		require.NotEqual(t, teamA.Version, routeByReceiver(t, root, "team-a").Version)
+		require.Equal(t, teamB.Version, routeByReceiver(t, root, "team-b").Version)
+	})
+}
+
+func TestRouteChanges(t *testing.T) {
+	t.Run("a route is created at the index", func(t *testing.T) {
+		root := testRouteTree()
+		created, err := createRoute(root, RouteRootID, "", &config.Route{Receiver: "team-c"}, 1)
+		require.NoError(t, err)
+		require.Equal(t, RouteRootID, created.ParentID)
+		require.Equal(t, "team-c", root.Routes[1].Receiver)
+		require.Len(t, root.Routes, 3)
+	})
+
+	t.Run("a route is updated and keeps its children", func(t *testing.T) {
+		root := testRouteTree()
+		teamA := routeByReceiver(t, root, "team-a")
+		updated, err := updateRoute(root, teamA.ID, teamA.Version, &config.Route{Receiver: "other", Match: map[string]string{"team": "a"}})
+		require.NoError(t, err)
+		require.Equal(t, teamA.ID, updated.ID)
+		require.Equal(t, "other", root.Routes[0].Receiver)
+		require.Len(t, root.Routes[0].Routes, 1)
+	})
+
+	t.Run("the root must keep a receiver and no matchers", func(t *testing.T) {
+		root := testRouteTree()
+		_, err := updateRoute(root, RouteRootID, "", &config.Route{Receiver: "default", Match: map[string]string{"team": "a"}})
+		require.Error(t, err)
+		_, err = updateRoute(root, RouteRootID, "", &config.Route{})
+		require.Error(t, err)
+	})
+
+	t.Run("a change with an outdated version fails", func(t *testing.T) {
+		root := testRouteTree()
+		teamA := routeByReceiver(t, root, "team-a")
+		root.Routes[0].Routes[0].Receiver = "changed"
+
+		_, err := updateRoute(root, teamA.ID, teamA.Version, &config.Route{Receiver: "other"})
+		require.ErrorIs(t, err, ErrRouteVersionConflict)
+		require.ErrorIs(t, deleteRoute(root, teamA.ID, teamA.Version), ErrRouteVersionConflict)
+	})
+
+	t.Run("a route is deleted with its children", func(t *testing.T) {
+		root := testRouteTree()
+		teamA := routeByReceiver(t, root, "team-a")
+		require.NoError(t, deleteRoute(root, teamA.ID, ""))
+		require.Len(t, policyRoutes(root), 2)
+		require.ErrorIs(t, deleteRoute(root, teamA.ID, ""), ErrRouteNotFound)
+		require.Error(t, deleteRoute(root, RouteRootID, ""))
+	})
+
+	t.Run("a route is moved with its children", func(t *testing.T) {
+		root := testRouteTree()
+		teamA := routeByReceiver(t, root, "team-a")
+		teamB := routeByReceiver(t, root, "team-b")
+		moved, err := moveRoute(root, teamA.ID, teamA.Version, teamB.ID, 0)
+		require.NoError(t, err)
+		require.Equal(t, teamB.ID, moved.ParentID)
+		require.Len(t, root.Routes, 1)
+		require.Equal(t, "team-a", root.Routes[0].Routes[0].Receiver)
+		require.Len(t, root.Routes[0].Routes[0].Routes, 1)
+	})
+
+	t.Run("a route can't be moved to its children", func(t *testing.T) {
+		root := testRouteTree()
+		teamA := routeByReceiver(t, root, "team-a")
+		critical := routeByReceiver(t, root, "team-a-critical")
+		_, err := moveRoute(root, teamA.ID, "", critical.ID, 0)
+		require.Error(t, err)
+		_, err = moveRoute(root, teamA.ID, "", teamA.ID, 0)
+		require.Error(t, err)
+		require.Len(t, policyRoutes(root), 4)
+	})
+}
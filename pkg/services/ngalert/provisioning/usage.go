@@ -0,0 +1,171 @@
+package provisioning
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/dispatch"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/prometheus/common/model"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ErrResourceInUse is an error for the deletion of a resource referenced by other resources.
+var ErrResourceInUse = errors.New("resource is in use")
+
+// ContactPointUsage is where a contact point is referenced.
+type ContactPointUsage struct {
+	// Routes are the paths of the notification policies notifying the contact point, such as route.routes[0].
+	Routes []string
+	// Escalations are the receivers of the escalation chains with a step notifying the contact point.
+	Escalations []string
+	// Rules are the rules whose alerts are routed to the contact point by the labels of the rules.
+	Rules []*ngmodels.AlertRule
+}
+
+// MuteTimingUsage is where a mute timing applies.
+type MuteTimingUsage struct {
+	// Rules are the rules whose alerts are muted by the mute timing, by the labels of the rules.
+	Rules []*ngmodels.AlertRule
+}
+
+// GetContactPointUsage returns the notification policies and escalation chains referencing the contact point, and
+// the rules among rules whose alerts are routed to it.
+func (s *AlertmanagerConfigService) GetContactPointUsage(orgID int64, name string, rules []*ngmodels.AlertRule) (*ContactPointUsage, error) {
+	cfg, err := s.latestConfig(orgID)
+	if err != nil {
+		return nil, err
+	}
+	return contactPointUsage(&cfg.AlertmanagerConfig, name, rules), nil
+}
+
+func contactPointUsage(amConfig *apimodels.PostableApiAlertingConfig, name string, rules []*ngmodels.AlertRule) *ContactPointUsage {
+	usage := &ContactPointUsage{
+		Routes:      routesNotifying(amConfig.Route, "route", name),
+		Escalations: escalationsNotifying(amConfig.Escalations, name),
+	}
+	if amConfig.Route == nil {
+		return usage
+	}
+	route := dispatch.NewRoute(amConfig.Route, nil)
+	for _, r := range rules {
+		for _, matched := range route.Match(ruleLabelSet(r)) {
+			if matched.RouteOpts.Receiver == name {
+				usage.Rules = append(usage.Rules, r)
+				break
+			}
+		}
+	}
+	return usage
+}
+
+// GetMuteTimingUsage returns the rules among rules whose alerts are muted by the mute timing.
+func (s *MuteTimingService) GetMuteTimingUsage(orgID int64, uid string, rules []*ngmodels.AlertRule) (*MuteTimingUsage, error) {
+	rs, _, err := s.GetMuteTiming(orgID, uid)
+	if err != nil {
+		return nil, err
+	}
+	return muteTimingUsage(rs, rules)
+}
+
+func muteTimingUsage(rs *ngmodels.RecurringSilence, rules []*ngmodels.AlertRule) (*MuteTimingUsage, error) {
+	matchers, err := toLabelMatchers(rs.Matchers)
+	if err != nil {
+		return nil, fmt.Errorf("mute timing %q: %w", rs.UID, err)
+	}
+	usage := &MuteTimingUsage{}
+	for _, r := range rules {
+		if matchers.Matches(ruleLabelSet(r)) {
+			usage.Rules = append(usage.Rules, r)
+		}
+	}
+	return usage, nil
+}
+
+// checkContactPointsNotUsed returns ErrResourceInUse if the Alertmanager configuration still references one of the
+// deleted contact points.
+func checkContactPointsNotUsed(amConfig *apimodels.PostableApiAlertingConfig, deleted []string) error {
+	for _, name := range deleted {
+		refs := routesNotifying(amConfig.Route, "route", name)
+		for _, receiver := range escalationsNotifying(amConfig.Escalations, name) {
+			refs = append(refs, fmt.Sprintf("escalation chain of %q", receiver))
+		}
+		if len(refs) > 0 {
+			return fmt.Errorf("%w: contact point %q is used by %s", ErrResourceInUse, name, strings.Join(refs, ", "))
+		}
+	}
+	return nil
+}
+
+// routesNotifying returns the paths of the route and its children that notify the receiver.
+func routesNotifying(r *config.Route, path string, receiver string) []string {
+	if r == nil {
+		return nil
+	}
+	var res []string
+	if r.Receiver == receiver {
+		res = append(res, path)
+	}
+	for i, child := range r.Routes {
+		res = append(res, routesNotifying(child, fmt.Sprintf("%s.routes[%d]", path, i), receiver)...)
+	}
+	return res
+}
+
+// escalationsNotifying returns the receivers of the escalation chains with a step notifying the receiver.
+func escalationsNotifying(chains []*apimodels.EscalationChain, receiver string) []string {
+	var res []string
+	for _, chain := range chains {
+		for _, step := range chain.Steps {
+			if step.Receiver == receiver {
+				res = append(res, chain.Receiver)
+				break
+			}
+		}
+	}
+	return res
+}
+
+// ruleLabelSet returns the labels of the alerts of the rule known before its evaluation, the labels of the rule and
+// the labels Grafana adds to them.
+func ruleLabelSet(r *ngmodels.AlertRule) model.LabelSet {
+	ls := make(model.LabelSet, len(r.Labels)+3)
+	for k, v := range r.Labels {
+		ls[model.LabelName(k)] = model.LabelValue(v)
+	}
+	ls[model.LabelName(ngmodels.RuleUIDLabel)] = model.LabelValue(r.UID)
+	ls[model.LabelName(ngmodels.NamespaceUIDLabel)] = model.LabelValue(r.NamespaceUID)
+	ls[model.AlertNameLabel] = model.LabelValue(r.Title)
+	return ls
+}
+
+func toLabelMatchers(matchers amv2.Matchers) (labels.Matchers, error) {
+	res := make(labels.Matchers, 0, len(matchers))
+	for _, m := range matchers {
+		if m.Name == nil || m.Value == nil {
+			return nil, errors.New("matcher has no name or value")
+		}
+		isRegex := m.IsRegex != nil && *m.IsRegex
+		isEqual := m.IsEqual == nil || *m.IsEqual
+		t := labels.MatchEqual
+		switch {
+		case isRegex && isEqual:
+			t = labels.MatchRegexp
+		case isRegex:
+			t = labels.MatchNotRegexp
+		case !isEqual:
+			t = labels.MatchNotEqual
+		}
+		matcher, err := labels.NewMatcher(t, *m.Name, *m.Value)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, matcher)
+	}
+	return res, nil
+}
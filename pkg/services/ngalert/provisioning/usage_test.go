@@ -0,0 +1,72 @@
+package provisioning
+
+import (
+	"testing"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/alertmanager/pkg/labels"
+	"github.com/stretchr/testify/require"
+
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func ruleUIDs(rules []*ngmodels.AlertRule) []string {
+	uids := make([]string, 0, len(rules))
+	for _, r := range rules {
+		uids = append(uids, r.UID)
+	}
+	return uids
+}
+
+func TestContactPointUsage(t *testing.T) {
+	amConfig := &apimodels.PostableApiAlertingConfig{
+		Config: apimodels.Config{
+			Route: &config.Route{
+				Receiver: "default",
+				Routes: []*config.Route{
+					{Receiver: "db", Matchers: config.Matchers{{Type: labels.MatchEqual, Name: "team", Value: "db"}}},
+					{Receiver: "web", Matchers: config.Matchers{{Type: labels.MatchEqual, Name: "team", Value: "web"}}, Routes: []*config.Route{
+						{Receiver: "db", Matchers: config.Matchers{{Type: labels.MatchEqual, Name: "alertname", Value: "Database down"}}},
+					}},
+				},
+			},
+			Escalations: []*apimodels.EscalationChain{{Receiver: "web", Steps: []apimodels.EscalationStep{{Receiver: "db"}}}},
+		},
+	}
+	rules := []*ngmodels.AlertRule{
+		{UID: "a", Title: "Slow queries", Labels: map[string]string{"team": "db"}},
+		{UID: "b", Title: "Database down", Labels: map[string]string{"team": "web"}},
+		{UID: "c", Title: "Errors", Labels: map[string]string{"team": "web"}},
+		{UID: "d", Title: "Disk"},
+	}
+
+	usage := contactPointUsage(amConfig, "db", rules)
+	require.Equal(t, []string{"route.routes[0]", "route.routes[1].routes[0]"}, usage.Routes)
+	require.Equal(t, []string{"web"}, usage.Escalations)
+	require.Equal(t, []string{"a", "b"}, ruleUIDs(usage.Rules))
+
+	usage = contactPointUsage(amConfig, "default", rules)
+	require.Equal(t, []string{"route"}, usage.Routes)
+	require.Empty(t, usage.Escalations)
+	require.Equal(t, []string{"d"}, ruleUIDs(usage.Rules))
+
+	usage = contactPointUsage(amConfig, "unused", rules)
+	require.Empty(t, usage.Routes)
+	require.Empty(t, usage.Rules)
+}
+
+func TestMuteTimingUsage(t *testing.T) {
+	name, value, isRegex := "team", "db|web", true
+	rs := &ngmodels.RecurringSilence{UID: "night", Matchers: amv2.Matchers{{Name: &name, Value: &value, IsRegex: &isRegex}}}
+	rules := []*ngmodels.AlertRule{
+		{UID: "a", Labels: map[string]string{"team": "db"}},
+		{UID: "b", Labels: map[string]string{"team": "infra"}},
+		{UID: "c", Labels: map[string]string{"team": "web"}},
+	}
+
+	usage, err := muteTimingUsage(rs, rules)
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "c"}, ruleUIDs(usage.Rules))
+}
@@ -0,0 +1,244 @@
+package querylog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// queueCapacity is the number of queries waiting to be logged, the queries over it are dropped.
+	queueCapacity = 10000
+	// maxEntriesPerWrite is the maximum number of queries of a write to the file or of a push to Loki.
+	maxEntriesPerWrite = 1000
+	// lokiJob is the job label of the stream of the queries in Loki.
+	lokiJob = "grafana-alerting-query-log"
+)
+
+var timeNow = time.Now
+
+// Entry is a datasource query of an evaluation of an alert rule.
+type Entry struct {
+	Timestamp       time.Time `json:"ts"`
+	OrgID           int64     `json:"org_id"`
+	RuleUID         string    `json:"rule_uid"`
+	RefID           string    `json:"ref_id"`
+	DatasourceUID   string    `json:"datasource_uid"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	Series          int       `json:"series"`
+	DataPoints      int       `json:"data_points"`
+	Error           string    `json:"error,omitempty"`
+}
+
+// writer writes the entries of the log.
+type writer interface {
+	write(ctx context.Context, entries []Entry) error
+	close() error
+}
+
+// Logger logs the datasource queries of the evaluations of the alert rules, one JSON object by query, to a file or to
+// Loki, so the owners of the datasources can attribute their load to the rules.
+type Logger struct {
+	writer writer
+	queue  chan Entry
+	log    log.Logger
+}
+
+// NewLogger returns a logger writing to the destination of the query log of the configuration.
+func NewLogger(cfg *setting.Cfg, logger log.Logger) (*Logger, error) {
+	var w writer
+	switch cfg.QueryLog {
+	case setting.QueryLogFile:
+		path := cfg.QueryLogFilePath
+		if path == "" {
+			path = filepath.Join(cfg.LogsPath, "alerting-queries.log")
+		}
+		fw, err := newFileWriter(path)
+		if err != nil {
+			return nil, err
+		}
+		w = fw
+	case setting.QueryLogLoki:
+		w = &lokiWriter{
+			url:      cfg.QueryLogLokiURL,
+			username: cfg.QueryLogLokiBasicAuthUsername,
+			password: cfg.QueryLogLokiBasicAuthPassword,
+			client:   &http.Client{Timeout: 10 * time.Second},
+		}
+	default:
+		return nil, fmt.Errorf("unsupported query log %q", cfg.QueryLog)
+	}
+	return &Logger{writer: w, queue: make(chan Entry, queueCapacity), log: logger}, nil
+}
+
+// LogQuery queues a query of an evaluation of the rule. It doesn't wait for the query to be written.
+func (l *Logger) LogQuery(key models.AlertRuleKey, q expr.QueryStats) {
+	e := Entry{
+		Timestamp:       timeNow(),
+		OrgID:           key.OrgID,
+		RuleUID:         key.UID,
+		RefID:           q.RefID,
+		DatasourceUID:   q.DatasourceUID,
+		DurationSeconds: q.Duration.Seconds(),
+		Series:          q.Series,
+		DataPoints:      q.DataPoints,
+	}
+	if q.Error != nil {
+		e.Error = q.Error.Error()
+	}
+	select {
+	case l.queue <- e:
+	default:
+		l.log.Warn("query log queue is full, the query is dropped", "rule_uid", key.UID, "ref_id", q.RefID)
+	}
+}
+
+// Run writes the queued queries until the context is done.
+func (l *Logger) Run(ctx context.Context) error {
+	defer func() {
+		if err := l.writer.close(); err != nil {
+			l.log.Error("failed to close the query log", "err", err)
+		}
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e := <-l.queue:
+			entries := []Entry{e}
+			// the queries queued in the meantime are written together
+			for more := true; more && len(entries) < maxEntriesPerWrite; {
+				select {
+				case e := <-l.queue:
+					entries = append(entries, e)
+				default:
+					more = false
+				}
+			}
+			if err := l.writer.write(ctx, entries); err != nil {
+				l.log.Error("failed to write the query log", "count", len(entries), "err", err)
+			}
+		}
+	}
+}
+
+// fileWriter appends the entries to a file, one JSON object by line.
+type fileWriter struct {
+	f *os.File
+}
+
+func newFileWriter(path string) (*fileWriter, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, fmt.Errorf("failed to create the directory of the query log: %w", err)
+	}
+	// We can ignore the gosec G304 warning on this one because the path comes from the configuration of the server.
+	// nolint:gosec
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the query log: %w", err)
+	}
+	return &fileWriter{f: f}, nil
+}
+
+func (w *fileWriter) write(_ context.Context, entries []Entry) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	_, err := w.f.Write(buf.Bytes())
+	return err
+}
+
+func (w *fileWriter) close() error {
+	return w.f.Close()
+}
+
+// lokiWriter pushes the entries to Loki, in a stream of the job grafana-alerting-query-log by organization.
+type lokiWriter struct {
+	url      string
+	username string
+	password string
+	client   *http.Client
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+func (w *lokiWriter) write(ctx context.Context, entries []Entry) error {
+	b, err := json.Marshal(map[string][]lokiStream{"streams": lokiStreams(entries)})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Grafana")
+	if w.username != "" {
+		req.SetBasicAuth(w.username, w.password)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+func (w *lokiWriter) close() error {
+	return nil
+}
+
+// lokiStreams returns the streams of the entries by organization, with the entries of each stream in time order as
+// Loki requires.
+func lokiStreams(entries []Entry) []lokiStream {
+	sorted := append([]Entry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+	byOrg := make(map[int64]*lokiStream)
+	var orgs []int64
+	for _, e := range sorted {
+		s, ok := byOrg[e.OrgID]
+		if !ok {
+			s = &lokiStream{Stream: map[string]string{"job": lokiJob, "org_id": fmt.Sprint(e.OrgID)}}
+			byOrg[e.OrgID] = s
+			orgs = append(orgs, e.OrgID)
+		}
+		line, _ := json.Marshal(e)
+		s.Values = append(s.Values, [2]string{fmt.Sprint(e.Timestamp.UnixNano()), string(line)})
+	}
+	streams := make([]lokiStream, 0, len(orgs))
+	for _, org := range orgs {
+		streams = append(streams, *byOrg[org])
+	}
+	return streams
+}
@@ -0,0 +1,130 @@
+package querylog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestLokiStreams(t *testing.T) {
+	now := time.Unix(1640995200, 0)
+	entries := []Entry{
+		{Timestamp: now.Add(time.Second), OrgID: 1, RuleUID: "b"},
+		{Timestamp: now, OrgID: 2, RuleUID: "c"},
+		{Timestamp: now, OrgID: 1, RuleUID: "a"},
+	}
+
+	streams := lokiStreams(entries)
+	require.Len(t, streams, 2)
+	require.Equal(t, map[string]string{"job": lokiJob, "org_id": "2"}, streams[0].Stream)
+	require.Equal(t, map[string]string{"job": lokiJob, "org_id": "1"}, streams[1].Stream)
+	require.Len(t, streams[1].Values, 2)
+	require.Equal(t, "1640995200000000000", streams[1].Values[0][0], "the entries of a stream are in time order")
+	var e Entry
+	require.NoError(t, json.Unmarshal([]byte(streams[1].Values[0][1]), &e))
+	require.Equal(t, "a", e.RuleUID)
+}
+
+func TestLogger(t *testing.T) {
+	now := time.Unix(1640995200, 0).UTC()
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() { timeNow = time.Now })
+	key := models.AlertRuleKey{OrgID: 1, UID: "rule"}
+	stats := expr.QueryStats{RefID: "A", DatasourceUID: "prometheus", Duration: 2 * time.Second, Series: 3, DataPoints: 30}
+	expected := Entry{
+		Timestamp:       now,
+		OrgID:           1,
+		RuleUID:         "rule",
+		RefID:           "A",
+		DatasourceUID:   "prometheus",
+		DurationSeconds: 2,
+		Series:          3,
+		DataPoints:      30,
+	}
+
+	run := func(t *testing.T, l *Logger) {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			require.NoError(t, l.Run(ctx))
+			close(done)
+		}()
+		t.Cleanup(func() {
+			cancel()
+			<-done
+		})
+	}
+
+	t.Run("file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "log", "queries.log")
+		l, err := NewLogger(&setting.Cfg{QueryLog: setting.QueryLogFile, QueryLogFilePath: path}, log.New("test"))
+		require.NoError(t, err)
+		run(t, l)
+
+		l.LogQuery(key, stats)
+		failed := stats
+		failed.Error = errors.New("timeout")
+		l.LogQuery(key, failed)
+
+		var lines []string
+		require.Eventually(t, func() bool {
+			b, err := ioutil.ReadFile(path)
+			require.NoError(t, err)
+			lines = strings.Split(strings.TrimSpace(string(b)), "\n")
+			return len(lines) == 2
+		}, 5*time.Second, 10*time.Millisecond)
+		var e Entry
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), &e))
+		require.Equal(t, expected, e)
+		require.NoError(t, json.Unmarshal([]byte(lines[1]), &e))
+		require.Equal(t, "timeout", e.Error)
+	})
+
+	t.Run("loki", func(t *testing.T) {
+		received := make(chan map[string][]lokiStream, 1)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, password, _ := r.BasicAuth()
+			require.Equal(t, "user", user)
+			require.Equal(t, "password", password)
+			var body map[string][]lokiStream
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			received <- body
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		t.Cleanup(srv.Close)
+
+		l, err := NewLogger(&setting.Cfg{
+			QueryLog:                      setting.QueryLogLoki,
+			QueryLogLokiURL:               srv.URL,
+			QueryLogLokiBasicAuthUsername: "user",
+			QueryLogLokiBasicAuthPassword: "password",
+		}, log.New("test"))
+		require.NoError(t, err)
+		run(t, l)
+		l.LogQuery(key, stats)
+
+		select {
+		case body := <-received:
+			require.Len(t, body["streams"], 1)
+			var e Entry
+			require.NoError(t, json.Unmarshal([]byte(body["streams"][0].Values[0][1]), &e))
+			require.Equal(t, expected, e)
+		case <-time.After(5 * time.Second):
+			t.Fatal("the query log was not pushed to Loki")
+		}
+	})
+}
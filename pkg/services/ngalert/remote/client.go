@@ -0,0 +1,86 @@
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/remote/evalv1"
+)
+
+// evaluationTimeout is the timeout of an evaluation by a worker, a bit longer than the timeout of the evaluation in
+// the worker so that the worker returns its own error.
+const evaluationTimeout = 35 * time.Second
+
+// Client sends the evaluations of the alert rules to the evaluation workers, in turn.
+type Client struct {
+	conns   []*grpc.ClientConn
+	workers []evalv1.EvaluatorClient
+	token   string
+	next    uint64
+}
+
+// NewClient returns a client of the evaluation workers at the addresses, authenticated with the token if not empty.
+func NewClient(addrs []string, token string) (*Client, error) {
+	if len(addrs) == 0 {
+		return nil, errors.New("no evaluation worker")
+	}
+	c := &Client{token: token}
+	for _, addr := range addrs {
+		// the connection is established in the background, and re-established if a worker restarts
+		conn, err := grpc.Dial(addr, grpc.WithInsecure(), grpc.WithUnaryInterceptor(tracingClientInterceptor))
+		if err != nil {
+			_ = c.Close()
+			return nil, fmt.Errorf("failed to connect to the evaluation worker %s: %w", addr, err)
+		}
+		c.conns = append(c.conns, conn)
+		c.workers = append(c.workers, evalv1.NewEvaluatorClient(conn))
+	}
+	return c, nil
+}
+
+// ConditionEval evaluates the condition by a worker.
+func (c *Client) ConditionEval(ctx context.Context, condition *models.Condition, now time.Time) (eval.Results, error) {
+	queries, err := json.Marshal(condition.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the queries: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, evaluationTimeout)
+	defer cancel()
+	if c.token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, tokenKey, c.token)
+	}
+
+	worker := c.workers[atomic.AddUint64(&c.next, 1)%uint64(len(c.workers))]
+	resp, err := worker.Evaluate(ctx, &evalv1.EvaluateRequest{
+		OrgId:       condition.OrgID,
+		Condition:   condition.Condition,
+		Data:        queries,
+		Now:         now.UnixNano(),
+		OwnerUserId: condition.OwnerUserID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate the condition by an evaluation worker: %w", err)
+	}
+	return resultsFromProto(resp.GetResults()), nil
+}
+
+// Close closes the connections to the workers.
+func (c *Client) Close() error {
+	var err error
+	for _, conn := range c.conns {
+		if cerr := conn.Close(); cerr != nil {
+			err = cerr
+		}
+	}
+	return err
+}
@@ -0,0 +1,80 @@
+package remote
+
+import (
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/remote/evalv1"
+)
+
+// resultsToProto converts the results of an evaluation to the results of the response of a worker.
+func resultsToProto(results eval.Results) []*evalv1.Result {
+	out := make([]*evalv1.Result, 0, len(results))
+	for _, r := range results {
+		pr := &evalv1.Result{
+			Instance:           r.Instance,
+			State:              int32(r.State),
+			EvaluatedAt:        r.EvaluatedAt.UnixNano(),
+			EvaluationDuration: int64(r.EvaluationDuration),
+			EvaluationString:   r.EvaluationString,
+			Warnings:           r.Warnings,
+		}
+		if r.Error != nil {
+			pr.Error = r.Error.Error()
+		}
+		if len(r.Values) > 0 {
+			pr.Values = make(map[string]*evalv1.NumberValueCapture, len(r.Values))
+			for k, v := range r.Values {
+				pv := &evalv1.NumberValueCapture{
+					Var:     v.Var,
+					Labels:  v.Labels,
+					Samples: v.Samples,
+				}
+				if v.Value != nil {
+					pv.Value, pv.HasValue = *v.Value, true
+				}
+				pr.Values[k] = pv
+			}
+		}
+		out = append(out, pr)
+	}
+	return out
+}
+
+// resultsFromProto converts the results of the response of a worker to the results of an evaluation.
+func resultsFromProto(results []*evalv1.Result) eval.Results {
+	out := make(eval.Results, 0, len(results))
+	for _, pr := range results {
+		r := eval.Result{
+			Instance:           data.Labels(pr.GetInstance()),
+			State:              eval.State(pr.GetState()),
+			EvaluatedAt:        time.Unix(0, pr.GetEvaluatedAt()),
+			EvaluationDuration: time.Duration(pr.GetEvaluationDuration()),
+			EvaluationString:   pr.GetEvaluationString(),
+			Warnings:           pr.GetWarnings(),
+		}
+		if pr.GetError() != "" {
+			r.Error = errors.New(pr.GetError())
+		}
+		if len(pr.GetValues()) > 0 {
+			r.Values = make(map[string]eval.NumberValueCapture, len(pr.GetValues()))
+			for k, pv := range pr.GetValues() {
+				v := eval.NumberValueCapture{
+					Var:     pv.GetVar(),
+					Labels:  data.Labels(pv.GetLabels()),
+					Samples: pv.GetSamples(),
+				}
+				if pv.GetHasValue() {
+					value := pv.GetValue()
+					v.Value = &value
+				}
+				r.Values[k] = v
+			}
+		}
+		out = append(out, r)
+	}
+	return out
+}
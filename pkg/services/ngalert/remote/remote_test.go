@@ -0,0 +1,75 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/remote/evalv1"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestResultsConversion(t *testing.T) {
+	value := 42.0
+	now := time.Unix(1640995200, 0)
+	results := eval.Results{
+		{
+			Instance:           data.Labels{"pod": "a"},
+			State:              eval.Alerting,
+			EvaluatedAt:        now,
+			EvaluationDuration: 2 * time.Second,
+			EvaluationString:   "[ var='B' labels={pod=a} value=42 ]",
+			Values: map[string]eval.NumberValueCapture{
+				"B": {Var: "B", Labels: data.Labels{"pod": "a"}, Value: &value},
+				"C": {Var: "C", Samples: []string{"error"}},
+			},
+			Warnings: []string{"truncated"},
+		},
+		{
+			State:       eval.Error,
+			Error:       errors.New("failed to execute query A"),
+			EvaluatedAt: now,
+		},
+	}
+	require.Equal(t, results, resultsFromProto(resultsToProto(results)))
+}
+
+func TestServer(t *testing.T) {
+	lis := bufconn.Listen(1024 * 1024)
+	srv := NewServer(eval.Evaluator{Cfg: &setting.Cfg{ExpressionsEnabled: true}, Log: log.New("test")}, nil, "secret", log.New("test"))
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() {
+		_ = srv.Serve(ctx, lis)
+	}()
+
+	conn, err := grpc.DialContext(ctx, "bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return lis.Dial()
+	}))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	client := evalv1.NewEvaluatorClient(conn)
+
+	t.Run("evaluations without the token are rejected", func(t *testing.T) {
+		_, err := client.Evaluate(ctx, &evalv1.EvaluateRequest{OrgId: 1, Condition: "A", Data: []byte("[]")})
+		require.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("evaluations without queries are rejected", func(t *testing.T) {
+		ctx := metadata.AppendToOutgoingContext(ctx, tokenKey, "secret")
+		_, err := client.Evaluate(ctx, &evalv1.EvaluateRequest{OrgId: 1, Condition: "A", Data: []byte("[]")})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
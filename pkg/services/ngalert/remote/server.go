@@ -0,0 +1,114 @@
+package remote
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/remote/evalv1"
+	"github.com/grafana/grafana/pkg/tsdb"
+)
+
+// tokenKey is the gRPC metadata key of the token shared by the scheduler and the workers.
+const tokenKey = "authorization"
+
+// Server is an evaluation worker: it executes the queries and expressions of the alert rules sent by the schedulers
+// and returns the results of the evaluations. The states of the rules stay in the schedulers.
+type Server struct {
+	evalv1.UnimplementedEvaluatorServer
+
+	evaluator   eval.Evaluator
+	dataService *tsdb.Service
+	token       string
+	log         log.Logger
+}
+
+// NewServer returns an evaluation worker evaluating the rules with the evaluator, and accepting the evaluations with
+// the token only, if not empty.
+func NewServer(evaluator eval.Evaluator, dataService *tsdb.Service, token string, logger log.Logger) *Server {
+	return &Server{
+		evaluator:   evaluator,
+		dataService: dataService,
+		token:       token,
+		log:         logger,
+	}
+}
+
+// Evaluate evaluates the condition of an alert rule.
+func (s *Server) Evaluate(ctx context.Context, req *evalv1.EvaluateRequest) (*evalv1.EvaluateResponse, error) {
+	if err := s.authenticate(ctx); err != nil {
+		return nil, err
+	}
+
+	var queries []models.AlertQuery
+	if err := json.Unmarshal(req.GetData(), &queries); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid queries: %v", err)
+	}
+	condition := models.Condition{
+		Condition:   req.GetCondition(),
+		OrgID:       req.GetOrgId(),
+		Data:        queries,
+		OwnerUserID: req.GetOwnerUserId(),
+	}
+	if !condition.IsValid() {
+		return nil, status.Error(codes.InvalidArgument, "invalid condition: no queries or expressions")
+	}
+
+	results, err := s.evaluator.ConditionEvalWithContext(ctx, &condition, time.Unix(0, req.GetNow()), s.dataService)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to evaluate the condition: %v", err)
+	}
+	return &evalv1.EvaluateResponse{Results: resultsToProto(results)}, nil
+}
+
+func (s *Server) authenticate(ctx context.Context) error {
+	if s.token == "" {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, t := range md.Get(tokenKey) {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(s.token)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "invalid evaluation worker token")
+}
+
+// Run serves the evaluations on the address until the context is done.
+func (s *Server) Run(ctx context.Context, addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	return s.Serve(ctx, lis)
+}
+
+// Serve serves the evaluations on the listener until the context is done.
+func (s *Server) Serve(ctx context.Context, lis net.Listener) error {
+	srv := grpc.NewServer(grpc.UnaryInterceptor(tracingServerInterceptor))
+	evalv1.RegisterEvaluatorServer(srv, s)
+
+	errCh := make(chan error, 1)
+	go func() {
+		s.log.Info("evaluation worker listening", "address", lis.Addr().String())
+		errCh <- srv.Serve(lis)
+	}()
+	select {
+	case <-ctx.Done():
+		srv.GracefulStop()
+		return nil
+	case err := <-errCh:
+		return err
+	}
+}
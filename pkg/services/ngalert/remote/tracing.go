@@ -0,0 +1,20 @@
+package remote
+
+import (
+	"context"
+
+	otgrpc "github.com/opentracing-contrib/go-grpc"
+	"github.com/opentracing/opentracing-go"
+	"google.golang.org/grpc"
+)
+
+// tracingClientInterceptor propagates the span of the evaluation to the worker. The global tracer is read for each
+// call, since it may be set up after the client is created.
+func tracingClientInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return otgrpc.OpenTracingClientInterceptor(opentracing.GlobalTracer())(ctx, method, req, reply, cc, invoker, opts...)
+}
+
+// tracingServerInterceptor starts the span of the evaluation by the worker, a child of the span of the scheduler.
+func tracingServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	return otgrpc.OpenTracingServerInterceptor(opentracing.GlobalTracer())(ctx, req, info, handler)
+}
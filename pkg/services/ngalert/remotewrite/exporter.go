@@ -0,0 +1,204 @@
+package remotewrite
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/prometheus/common/model"
+	"github.com/prometheus/prometheus/pkg/value"
+	"github.com/prometheus/prometheus/prompb"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	// alertMetricName and alertForStateMetricName are the names of the series of the alert instances of Prometheus.
+	alertMetricName         = "ALERTS"
+	alertForStateMetricName = "ALERTS_FOR_STATE"
+	alertStateLabel         = "alertstate"
+
+	// queueCapacity is the number of evaluations waiting to be written, the series of the evaluations over it are
+	// dropped.
+	queueCapacity = 1000
+	// maxSamplesPerRequest is the maximum number of samples of a remote write request.
+	maxSamplesPerRequest = 2000
+)
+
+// Exporter remote writes the ALERTS and ALERTS_FOR_STATE series of the alert instances after each evaluation, with
+// the labels of the instances, so alert states can be graphed and joined with other series as with the alerting rules
+// of Prometheus:
+//
+//	ALERTS{alertname="...", alertstate="pending|firing", ...} 1
+//	ALERTS_FOR_STATE{alertname="...", ...} <start of the pending or firing state, in seconds>
+//
+// The series of the instances that are resolved are ended with stale markers.
+type Exporter struct {
+	url      string
+	username string
+	password string
+	client   *http.Client
+	queue    chan []prompb.TimeSeries
+	metrics  *metrics.Metrics
+	log      log.Logger
+}
+
+// NewExporter returns an exporter writing to the remote write endpoint of the configuration.
+func NewExporter(cfg *setting.Cfg, m *metrics.Metrics, logger log.Logger) *Exporter {
+	return &Exporter{
+		url:      cfg.StateRemoteWriteURL,
+		username: cfg.StateRemoteWriteBasicAuthUsername,
+		password: cfg.StateRemoteWriteBasicAuthPassword,
+		client:   &http.Client{Timeout: cfg.StateRemoteWriteTimeout},
+		queue:    make(chan []prompb.TimeSeries, queueCapacity),
+		metrics:  m,
+		log:      logger,
+	}
+}
+
+// Export queues the series of the states of an evaluation at now. It doesn't wait for them to be written.
+func (e *Exporter) Export(states []*state.State, now time.Time) {
+	series := seriesFromStates(states, now)
+	if len(series) == 0 {
+		return
+	}
+	select {
+	case e.queue <- series:
+	default:
+		e.metrics.StateRemoteWriteSamples.WithLabelValues("dropped").Add(float64(len(series)))
+		e.log.Warn("remote write queue is full, the series of the alert instances are dropped", "count", len(series))
+	}
+}
+
+// Run writes the queued series until the context is done.
+func (e *Exporter) Run(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case series := <-e.queue:
+			// the series queued in the meantime are written in the same requests
+			for more := true; more && len(series) < maxSamplesPerRequest; {
+				select {
+				case s := <-e.queue:
+					series = append(series, s...)
+				default:
+					more = false
+				}
+			}
+			for len(series) > 0 {
+				n := len(series)
+				if n > maxSamplesPerRequest {
+					n = maxSamplesPerRequest
+				}
+				if err := e.write(ctx, series[:n]); err != nil {
+					e.metrics.StateRemoteWriteSamples.WithLabelValues("failed").Add(float64(n))
+					e.log.Error("failed to remote write the series of the alert instances", "url", e.url, "count", n, "err", err)
+				} else {
+					e.metrics.StateRemoteWriteSamples.WithLabelValues("sent").Add(float64(n))
+				}
+				series = series[n:]
+			}
+		}
+	}
+}
+
+func (e *Exporter) write(ctx context.Context, series []prompb.TimeSeries) error {
+	b, err := (&prompb.WriteRequest{Timeseries: series}).Marshal()
+	if err != nil {
+		return fmt.Errorf("failed to encode the write request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(snappy.Encode(nil, b)))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("User-Agent", "Grafana")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if e.username != "" {
+		req.SetBasicAuth(e.username, e.password)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// seriesFromStates returns the series of the pending and firing states, and the stale markers of the resolved states.
+func seriesFromStates(states []*state.State, now time.Time) []prompb.TimeSeries {
+	ts := timestamp(now)
+	stale := math.Float64frombits(value.StaleNaN)
+	series := make([]prompb.TimeSeries, 0, 2*len(states))
+	for _, s := range states {
+		var alertState string
+		var v, forState float64
+		switch {
+		case s.State == eval.Alerting:
+			alertState, v, forState = "firing", 1, float64(s.StartsAt.Unix())
+		case s.State == eval.Pending:
+			alertState, v, forState = "pending", 1, float64(s.StartsAt.Unix())
+		case s.Resolved:
+			alertState, v, forState = "firing", stale, stale
+		default:
+			continue
+		}
+		series = append(series,
+			prompb.TimeSeries{
+				Labels:  seriesLabels(alertMetricName, s, alertState),
+				Samples: []prompb.Sample{{Value: v, Timestamp: ts}},
+			},
+			prompb.TimeSeries{
+				Labels:  seriesLabels(alertForStateMetricName, s, ""),
+				Samples: []prompb.Sample{{Value: forState, Timestamp: ts}},
+			},
+		)
+	}
+	return series
+}
+
+// seriesLabels returns the labels of a series of the state, sorted by name as remote write requires. The internal
+// labels of Grafana are not labels of the series.
+func seriesLabels(name string, s *state.State, alertState string) []prompb.Label {
+	labels := make([]prompb.Label, 0, len(s.Labels)+2)
+	labels = append(labels, prompb.Label{Name: model.MetricNameLabel, Value: name})
+	if alertState != "" {
+		labels = append(labels, prompb.Label{Name: alertStateLabel, Value: alertState})
+	}
+	for k, v := range s.Labels {
+		if strings.HasPrefix(k, model.ReservedLabelPrefix) || k == alertStateLabel {
+			continue
+		}
+		labels = append(labels, prompb.Label{Name: k, Value: v})
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		return labels[i].Name < labels[j].Name
+	})
+	return labels
+}
+
+func timestamp(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
@@ -0,0 +1,103 @@
+package remotewrite
+
+import (
+	"context"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/prometheus/pkg/value"
+	"github.com/prometheus/prometheus/prompb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestSeriesFromStates(t *testing.T) {
+	now := time.Unix(1640995200, 0)
+	startsAt := now.Add(-5 * time.Minute)
+	labels := data.Labels{"alertname": "HighLatency", "pod": "a", "__alert_rule_uid__": "uid"}
+	states := []*state.State{
+		{State: eval.Alerting, StartsAt: startsAt, Labels: labels},
+		{State: eval.Pending, StartsAt: startsAt, Labels: data.Labels{"alertname": "HighLatency", "pod": "b"}},
+		{State: eval.Normal, Labels: data.Labels{"alertname": "HighLatency", "pod": "c"}},
+		{State: eval.Normal, Resolved: true, Labels: data.Labels{"alertname": "HighLatency", "pod": "d"}},
+	}
+
+	series := seriesFromStates(states, now)
+	require.Len(t, series, 6)
+
+	require.Equal(t, []prompb.Label{
+		{Name: "__name__", Value: "ALERTS"},
+		{Name: "alertname", Value: "HighLatency"},
+		{Name: "alertstate", Value: "firing"},
+		{Name: "pod", Value: "a"},
+	}, series[0].Labels)
+	require.Equal(t, []prompb.Sample{{Value: 1, Timestamp: now.Unix() * 1000}}, series[0].Samples)
+	require.Equal(t, []prompb.Label{
+		{Name: "__name__", Value: "ALERTS_FOR_STATE"},
+		{Name: "alertname", Value: "HighLatency"},
+		{Name: "pod", Value: "a"},
+	}, series[1].Labels)
+	require.Equal(t, float64(startsAt.Unix()), series[1].Samples[0].Value)
+
+	require.Equal(t, prompb.Label{Name: "alertstate", Value: "pending"}, series[2].Labels[2])
+
+	// the series of the resolved instance are ended by stale markers
+	require.Equal(t, prompb.Label{Name: "pod", Value: "d"}, series[4].Labels[3])
+	require.True(t, value.IsStaleNaN(series[4].Samples[0].Value))
+	require.True(t, value.IsStaleNaN(series[5].Samples[0].Value))
+}
+
+func TestExporter(t *testing.T) {
+	received := make(chan *prompb.WriteRequest, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, _ := r.BasicAuth()
+		require.Equal(t, "user", user)
+		require.Equal(t, "password", password)
+		require.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+		compressed, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		b, err := snappy.Decode(nil, compressed)
+		require.NoError(t, err)
+		var req prompb.WriteRequest
+		require.NoError(t, req.Unmarshal(b))
+		received <- &req
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	cfg := &setting.Cfg{
+		StateRemoteWriteURL:               srv.URL,
+		StateRemoteWriteBasicAuthUsername: "user",
+		StateRemoteWriteBasicAuthPassword: "password",
+		StateRemoteWriteTimeout:           time.Second,
+	}
+	e := NewExporter(cfg, metrics.NewMetrics(prometheus.NewRegistry()), log.New("test"))
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() {
+		_ = e.Run(ctx)
+	}()
+
+	e.Export([]*state.State{{State: eval.Alerting, StartsAt: time.Unix(10, 0), Labels: data.Labels{"alertname": "A"}}}, time.Unix(20, 0))
+
+	select {
+	case req := <-received:
+		require.Len(t, req.Timeseries, 2)
+		require.Equal(t, []prompb.Sample{{Value: 10, Timestamp: 20000}}, req.Timeseries[1].Samples)
+		require.False(t, math.IsNaN(req.Timeseries[0].Samples[0].Value))
+	case <-time.After(5 * time.Second):
+		t.Fatal("the series were not written")
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/prometheus/alertmanager/api/v2/models"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 )
@@ -32,11 +33,25 @@ func FromAlertStateToPostableAlerts(logger log.Logger, firingStates []*state.Sta
 			continue
 		}
 		nL := alertState.Labels.Copy()
+		if alertState.IncidentID != "" {
+			nL[ngModels.IncidentIDLabel] = alertState.IncidentID
+		}
 		nA := data.Labels(alertState.Annotations).Copy()
 
 		if len(alertState.Results) > 0 {
 			nA["__value_string__"] = alertState.Results[0].EvaluationString
 		}
+		if alertState.State == eval.Alerting && alertState.Error != nil && len(alertState.Results) > 0 &&
+			alertState.Results[len(alertState.Results)-1].EvaluationState == eval.Error {
+			nA[ngModels.EvaluationErrorAnnotation] = alertState.Error.Error()
+		}
+
+		if alertState.Screenshot != nil {
+			if alertState.Screenshot.URL != "" {
+				nA[ngModels.ScreenshotURLAnnotation] = alertState.Screenshot.URL
+			}
+			nA[ngModels.ScreenshotPathAnnotation] = alertState.Screenshot.Path
+		}
 
 		genURL := appURL
 		if uid := nL[ngModels.RuleUIDLabel]; len(uid) > 0 && u != nil {
@@ -0,0 +1,45 @@
+package schedule
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// datasourcesInMaintenance are the UIDs of the datasources in maintenance, by organization.
+type datasourcesInMaintenance map[int64]map[string]struct{}
+
+// fetchDatasourcesInMaintenance returns the datasources in maintenance at now, fetched once per tick for all the
+// evaluations of the tick. No datasource is in maintenance when they can't be fetched.
+func (sch *schedule) fetchDatasourcesInMaintenance(now time.Time) datasourcesInMaintenance {
+	if sch.datasourceMaintenanceStore == nil {
+		return nil
+	}
+	windows, err := sch.datasourceMaintenanceStore.GetActiveDatasourceMaintenanceWindows(now)
+	if err != nil {
+		sch.log.Error("unable to get the maintenance windows of the datasources", "err", err)
+		return nil
+	}
+	res := datasourcesInMaintenance{}
+	for _, w := range windows {
+		if res[w.OrgID] == nil {
+			res[w.OrgID] = map[string]struct{}{}
+		}
+		res[w.OrgID][w.DatasourceUID] = struct{}{}
+	}
+	return res
+}
+
+// holding returns the datasources in maintenance of the rule, if it holds during their maintenance.
+func (m datasourcesInMaintenance) holding(alertRule *models.AlertRule) []string {
+	if !alertRule.HoldOnDatasourceMaintenance || len(m[alertRule.OrgID]) == 0 {
+		return nil
+	}
+	var uids []string
+	for _, uid := range alertRule.DatasourceUIDs() {
+		if _, ok := m[alertRule.OrgID][uid]; ok {
+			uids = append(uids, uid)
+		}
+	}
+	return uids
+}
@@ -0,0 +1,95 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+type fakeDatasourceMaintenanceStore struct {
+	store.DatasourceMaintenanceStore
+	windows []*models.DatasourceMaintenanceWindow
+}
+
+func (f *fakeDatasourceMaintenanceStore) GetActiveDatasourceMaintenanceWindows(t time.Time) ([]*models.DatasourceMaintenanceWindow, error) {
+	var res []*models.DatasourceMaintenanceWindow
+	for _, w := range f.windows {
+		if w.Active(t) {
+			res = append(res, w)
+		}
+	}
+	return res, nil
+}
+
+func TestDatasourceMaintenance(t *testing.T) {
+	sched, mockedClock := setupScheduler(t, newFakeRuleStore(t), &fakeInstanceStore{}, newFakeAdminConfigStore(t))
+	now := mockedClock.Now()
+	sched.datasourceMaintenanceStore = &fakeDatasourceMaintenanceStore{windows: []*models.DatasourceMaintenanceWindow{
+		{OrgID: 1, DatasourceUID: "prometheus", StartsAt: now.Add(-time.Hour).Unix(), EndsAt: now.Add(time.Hour).Unix()},
+		{OrgID: 1, DatasourceUID: "loki", StartsAt: now.Add(time.Hour).Unix(), EndsAt: now.Add(2 * time.Hour).Unix()},
+		{OrgID: 2, DatasourceUID: "loki", StartsAt: now.Add(-time.Hour).Unix(), EndsAt: now.Add(time.Hour).Unix()},
+	}}
+
+	rule := &models.AlertRule{
+		OrgID:           1,
+		UID:             "rule",
+		Title:           "rule",
+		Condition:       "B",
+		IntervalSeconds: 60,
+		Version:         1,
+		Data: []models.AlertQuery{
+			{RefID: "A", DatasourceUID: "prometheus", Model: json.RawMessage(`{"expr": "up"}`)},
+			{RefID: "B", DatasourceUID: "-100", Model: json.RawMessage(`{"type": "math", "expression": "$A > 0"}`)},
+		},
+		HoldOnDatasourceMaintenance: true,
+	}
+	inMaintenance := sched.fetchDatasourcesInMaintenance(now)
+
+	t.Run("the rules holding during the maintenance of their datasources are held", func(t *testing.T) {
+		require.Equal(t, []string{"prometheus"}, inMaintenance.holding(rule))
+
+		other := *rule
+		other.HoldOnDatasourceMaintenance = false
+		require.Empty(t, inMaintenance.holding(&other))
+		other = *rule
+		other.OrgID = 2
+		require.Empty(t, inMaintenance.holding(&other))
+		// The windows not in effect are ignored.
+		other = *rule
+		other.Data = []models.AlertQuery{{RefID: "A", DatasourceUID: "loki"}}
+		require.Empty(t, inMaintenance.holding(&other))
+	})
+
+	t.Run("the held rules are not evaluated and their alerts keep their state", func(t *testing.T) {
+		sched.stateManager.Put([]*state.State{{
+			AlertRuleUID:       rule.UID,
+			OrgID:              rule.OrgID,
+			CacheId:            "firing",
+			State:              eval.Alerting,
+			Labels:             data.Labels{"alertname": "rule"},
+			StartsAt:           now.Add(-time.Hour),
+			EndsAt:             now.Add(-time.Minute),
+			LastEvaluationTime: now.Add(-time.Hour),
+		}})
+
+		// The query of the datasource would fail to be evaluated, without data service.
+		err := sched.evaluateRule(context.Background(), rule.GetKey(), &alertRuleInfo{rule: rule}, &evalContext{now: now, version: rule.Version, inMaintenance: inMaintenance}, 1)
+		require.NoError(t, err)
+
+		s, err := sched.stateManager.Get(rule.OrgID, rule.UID, "firing")
+		require.NoError(t, err)
+		require.Equal(t, eval.Alerting, s.State)
+		require.False(t, s.Resolved)
+		require.Equal(t, now, s.LastEvaluationTime)
+		require.True(t, s.EndsAt.After(now))
+	})
+}
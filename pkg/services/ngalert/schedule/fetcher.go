@@ -5,11 +5,10 @@ import (
 )
 
 func (sch *schedule) fetchAllDetails() []*models.AlertRule {
-	q := models.ListAlertRulesQuery{}
-	err := sch.ruleStore.GetAlertRulesForScheduling(&q)
+	rules, err := sch.rules.fetch()
 	if err != nil {
 		sch.log.Error("failed to fetch alert definitions", "err", err)
 		return nil
 	}
-	return q.Result
+	return rules
 }
@@ -0,0 +1,54 @@
+package schedule
+
+import (
+	"sync"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// ruleGroupKey identifies a rule group of an organization.
+type ruleGroupKey struct {
+	orgID        int64
+	namespaceUID string
+	group        string
+}
+
+func ruleGroupKeyOf(rule *models.AlertRule) ruleGroupKey {
+	return ruleGroupKey{orgID: rule.OrgID, namespaceUID: rule.NamespaceUID, group: rule.RuleGroup}
+}
+
+// groupEvaluationSlots limits the number of rules of each rule group evaluated at the same time, so that a group
+// querying a rate-limited data source throttles itself without delaying the evaluations of the other groups. Only the
+// groups with running evaluations are tracked, so that a change of the limit of a group applies to its running
+// evaluations and the removed groups are forgotten.
+type groupEvaluationSlots struct {
+	mtx     sync.Mutex
+	running map[ruleGroupKey]int
+}
+
+func newGroupEvaluationSlots() *groupEvaluationSlots {
+	return &groupEvaluationSlots{running: make(map[ruleGroupKey]int)}
+}
+
+// tryAcquire takes an evaluation slot of the rule group, out of limit slots, without waiting. It returns the function
+// releasing it, and false if the group has no free slot. There is no limit if limit is 0, the evaluations are
+// still counted in case the limit is set while they run.
+func (s *groupEvaluationSlots) tryAcquire(key ruleGroupKey, limit int) (release func(), ok bool) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if limit > 0 && s.running[key] >= limit {
+		return nil, false
+	}
+	s.running[key]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mtx.Lock()
+			defer s.mtx.Unlock()
+			if s.running[key]--; s.running[key] <= 0 {
+				delete(s.running, key)
+			}
+		})
+	}, true
+}
@@ -0,0 +1,48 @@
+package schedule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupEvaluationSlots(t *testing.T) {
+	group := ruleGroupKey{orgID: 1, namespaceUID: "folder", group: "group"}
+
+	t.Run("a change of the limit applies to the running evaluations", func(t *testing.T) {
+		slots := newGroupEvaluationSlots()
+		release1, ok := slots.tryAcquire(group, 2)
+		require.True(t, ok)
+		release2, ok := slots.tryAcquire(group, 2)
+		require.True(t, ok)
+
+		_, ok = slots.tryAcquire(group, 1)
+		require.False(t, ok)
+		release1()
+		_, ok = slots.tryAcquire(group, 1)
+		require.False(t, ok)
+		release2()
+		release3, ok := slots.tryAcquire(group, 1)
+		require.True(t, ok)
+		release3()
+	})
+
+	t.Run("the evaluations without limit are counted", func(t *testing.T) {
+		slots := newGroupEvaluationSlots()
+		release, ok := slots.tryAcquire(group, 0)
+		require.True(t, ok)
+		_, ok = slots.tryAcquire(group, 1)
+		require.False(t, ok)
+		release()
+	})
+
+	t.Run("the groups without running evaluations are forgotten", func(t *testing.T) {
+		slots := newGroupEvaluationSlots()
+		release, ok := slots.tryAcquire(group, 1)
+		require.True(t, ok)
+		require.Len(t, slots.running, 1)
+		release()
+		release()
+		require.Empty(t, slots.running)
+	})
+}
@@ -0,0 +1,22 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// evaluateHeartbeat evaluates a heartbeat rule over the time of its last heartbeat.
+func (sch *schedule) evaluateHeartbeat(alertRule *models.AlertRule, now time.Time) (eval.Results, error) {
+	if sch.heartbeatStore == nil {
+		return nil, errors.New("heartbeat rules are not supported by the scheduler")
+	}
+	h, err := sch.heartbeatStore.GetAlertRuleHeartbeat(alertRule.OrgID, alertRule.UID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the last heartbeat: %w", err)
+	}
+	return eval.EvaluateHeartbeat(alertRule.Heartbeat, time.Unix(h.ReceivedAt, 0), now), nil
+}
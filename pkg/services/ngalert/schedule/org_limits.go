@@ -0,0 +1,95 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// orgEvaluationSlots limits the number of rules of each organization evaluated at the same time, so that the rules
+// of an organization cannot starve the evaluations of the others.
+type orgEvaluationSlots struct {
+	mtx   sync.Mutex
+	slots map[int64]chan struct{}
+}
+
+func newOrgEvaluationSlots() *orgEvaluationSlots {
+	return &orgEvaluationSlots{slots: make(map[int64]chan struct{})}
+}
+
+// acquire waits for an evaluation slot of the organization, out of limit slots, and returns the function releasing
+// it, and whether it had to wait. It fails if the context is done first. There is no limit if limit is 0.
+func (s *orgEvaluationSlots) acquire(ctx context.Context, orgID int64, limit int) (release func(), throttled bool, err error) {
+	if limit <= 0 {
+		return func() {}, false, nil
+	}
+
+	s.mtx.Lock()
+	slots, ok := s.slots[orgID]
+	if !ok || cap(slots) != limit {
+		slots = make(chan struct{}, limit)
+		s.slots[orgID] = slots
+	}
+	s.mtx.Unlock()
+
+	release = func() { <-slots }
+	select {
+	case slots <- struct{}{}:
+		return release, false, nil
+	default:
+	}
+	select {
+	case slots <- struct{}{}:
+		return release, true, nil
+	case <-ctx.Done():
+		return nil, true, ctx.Err()
+	}
+}
+
+// tryAcquire takes an evaluation slot of the organization, out of limit slots, without waiting. It returns the
+// function releasing it, and false if the organization has no free slot. There is no limit if limit is 0.
+func (s *orgEvaluationSlots) tryAcquire(orgID int64, limit int) (release func(), ok bool) {
+	if limit <= 0 {
+		return func() {}, true
+	}
+
+	s.mtx.Lock()
+	slots, ok := s.slots[orgID]
+	if !ok || cap(slots) != limit {
+		slots = make(chan struct{}, limit)
+		s.slots[orgID] = slots
+	}
+	s.mtx.Unlock()
+
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, true
+	default:
+		return nil, false
+	}
+}
+
+// orgLimits returns the limits of the evaluations of the organization.
+func (sch *schedule) orgLimits(orgID int64) setting.AlertingOrgLimits {
+	if sch.evaluator.Cfg == nil {
+		return setting.AlertingOrgLimits{}
+	}
+	return sch.evaluator.Cfg.AlertingLimitsForOrg(orgID)
+}
+
+// limitInstances replaces the results of a rule by an error when the rule would take the alert instances of the
+// organization, other is the number of instances of the other rules, over the limit. There is no limit if limit is 0.
+func limitInstances(results eval.Results, other, limit int, now time.Time) (eval.Results, bool) {
+	if limit <= 0 || other+len(results) <= limit {
+		return results, false
+	}
+	return eval.Results{{
+		State:       eval.Error,
+		Error:       fmt.Errorf("the rule has %d alert instances, the organization has %d alert instances in other rules and a limit of %d", len(results), other, limit),
+		EvaluatedAt: now,
+	}}, true
+}
@@ -0,0 +1,79 @@
+package schedule
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+)
+
+func TestOrgEvaluationSlots(t *testing.T) {
+	slots := newOrgEvaluationSlots()
+	ctx := context.Background()
+
+	release, throttled, err := slots.acquire(ctx, 1, 1)
+	require.NoError(t, err)
+	require.False(t, throttled)
+
+	// the other organizations are not limited by the evaluations of the organization 1
+	releaseOther, throttled, err := slots.acquire(ctx, 2, 1)
+	require.NoError(t, err)
+	require.False(t, throttled)
+	releaseOther()
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_, throttled, err = slots.acquire(timeoutCtx, 1, 1)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.True(t, throttled)
+
+	release()
+	release, _, err = slots.acquire(ctx, 1, 1)
+	require.NoError(t, err)
+	release()
+
+	t.Run("no limit", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			_, throttled, err := slots.acquire(ctx, 3, 0)
+			require.NoError(t, err)
+			require.False(t, throttled)
+		}
+	})
+
+	t.Run("without waiting", func(t *testing.T) {
+		release, ok := slots.tryAcquire(4, 1)
+		require.True(t, ok)
+		_, ok = slots.tryAcquire(4, 1)
+		require.False(t, ok)
+		release()
+		release, ok = slots.tryAcquire(4, 1)
+		require.True(t, ok)
+		release()
+	})
+}
+
+func TestLimitInstances(t *testing.T) {
+	now := time.Now()
+	results := eval.Results{
+		{Instance: data.Labels{"pod": "a"}, State: eval.Normal},
+		{Instance: data.Labels{"pod": "b"}, State: eval.Alerting},
+	}
+
+	limited, ok := limitInstances(results, 8, 10, now)
+	require.False(t, ok)
+	require.Equal(t, results, limited)
+
+	limited, ok = limitInstances(results, 9, 10, now)
+	require.True(t, ok)
+	require.Len(t, limited, 1)
+	require.Equal(t, eval.Error, limited[0].State)
+	require.EqualError(t, limited[0].Error, "the rule has 2 alert instances, the organization has 9 alert instances in other rules and a limit of 10")
+
+	limited, ok = limitInstances(results, 100, 0, now)
+	require.False(t, ok)
+	require.Equal(t, results, limited)
+}
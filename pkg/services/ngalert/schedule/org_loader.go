@@ -0,0 +1,144 @@
+package schedule
+
+import (
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+)
+
+// orgLoader tracks the organizations whose alert rules and states are loaded, when they are loaded lazily: the
+// scheduler only evaluates the rules of the loaded organizations. An organization is loaded on its first request to
+// the alerting API, or by the warm-up, which loads batchSize organizations on each tick of the scheduler until all
+// the organizations with rules were loaded once. A loaded organization is unloaded once it has no request for
+// idleTTL and no alerts, there is no unloading if idleTTL is 0.
+type orgLoader struct {
+	mtx       sync.Mutex
+	batchSize int
+	idleTTL   time.Duration
+	// loaded maps the loaded organizations to the time of their last request, and warmedUp holds the organizations
+	// loaded at least once, the warm-up doesn't load them again.
+	loaded   map[int64]time.Time
+	warmedUp map[int64]struct{}
+}
+
+func newOrgLoader(batchSize int, idleTTL time.Duration) *orgLoader {
+	return &orgLoader{
+		batchSize: batchSize,
+		idleTTL:   idleTTL,
+		loaded:    make(map[int64]time.Time),
+		warmedUp:  make(map[int64]struct{}),
+	}
+}
+
+// isLoaded returns whether the rules of the organization are loaded.
+func (l *orgLoader) isLoaded(orgID int64) bool {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	_, ok := l.loaded[orgID]
+	return ok
+}
+
+// activate records a request of the organization at now. It returns true if the organization was not loaded.
+func (l *orgLoader) activate(orgID int64, now time.Time) bool {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	_, ok := l.loaded[orgID]
+	l.loaded[orgID] = now
+	l.warmedUp[orgID] = struct{}{}
+	return !ok
+}
+
+// warmUp loads the next organizations of orgIDs never loaded, up to the size of the batch, and returns them.
+func (l *orgLoader) warmUp(orgIDs []int64, now time.Time) []int64 {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	var res []int64
+	for _, orgID := range orgIDs {
+		if len(res) >= l.batchSize {
+			break
+		}
+		if _, ok := l.warmedUp[orgID]; ok {
+			continue
+		}
+		l.loaded[orgID] = now
+		l.warmedUp[orgID] = struct{}{}
+		res = append(res, orgID)
+	}
+	return res
+}
+
+// unloadIdle unloads the organizations without request since idleTTL, except those with alerts, and returns them.
+func (l *orgLoader) unloadIdle(now time.Time, hasAlerts func(orgID int64) bool) []int64 {
+	if l.idleTTL <= 0 {
+		return nil
+	}
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	var res []int64
+	for orgID, last := range l.loaded {
+		if now.Sub(last) < l.idleTTL || hasAlerts(orgID) {
+			continue
+		}
+		delete(l.loaded, orgID)
+		res = append(res, orgID)
+	}
+	return res
+}
+
+func (l *orgLoader) count() int {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	return len(l.loaded)
+}
+
+// ActivateOrg records a request of the organization to the alerting API, it loads the rules and states of the
+// organization if they are loaded lazily and it is not loaded.
+func (sch *schedule) ActivateOrg(orgID int64) {
+	if sch.orgLoader == nil {
+		return
+	}
+	if sch.orgLoader.activate(orgID, sch.clock.Now()) {
+		sch.loadOrg(orgID)
+	}
+}
+
+// syncOrgs loads the next organizations of the warm-up and unloads the idle ones, on each tick of the scheduler.
+func (sch *schedule) syncOrgs(now time.Time) {
+	if sch.orgLoader == nil {
+		return
+	}
+	for _, orgID := range sch.orgLoader.warmUp(sch.rules.organizations(), now) {
+		sch.loadOrg(orgID)
+	}
+
+	idle := sch.orgLoader.unloadIdle(now, sch.hasAlerts)
+	for _, orgID := range idle {
+		sch.log.Info("unloading the alert rules of the idle organization", "org", orgID)
+		sch.stateManager.UnloadOrg(orgID)
+	}
+	if len(idle) > 0 {
+		// the rules of the organizations are not fetched anymore, so they are unregistered and stopped
+		sch.rules.invalidate()
+		sch.metrics.LoadedOrgs.Set(float64(sch.orgLoader.count()))
+	}
+}
+
+// loadOrg loads the states of the alert instances of the organization before its rules, which are evaluated from
+// the next tick.
+func (sch *schedule) loadOrg(orgID int64) {
+	sch.log.Info("loading the alert rules of the organization", "org", orgID)
+	sch.stateManager.WarmOrg(orgID)
+	sch.rules.invalidate()
+	sch.metrics.LoadedOrgs.Set(float64(sch.orgLoader.count()))
+}
+
+// hasAlerts returns whether an alert instance of the organization is not normal.
+func (sch *schedule) hasAlerts(orgID int64) bool {
+	for _, s := range sch.stateManager.GetAll(orgID) {
+		if s.State != eval.Normal {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrgLoader(t *testing.T) {
+	now := time.Now()
+	noAlerts := func(int64) bool { return false }
+
+	t.Run("the warm-up loads a batch of organizations on each tick, once", func(t *testing.T) {
+		l := newOrgLoader(2, 0)
+		require.Equal(t, []int64{1, 2}, l.warmUp([]int64{1, 2, 3}, now))
+		require.True(t, l.isLoaded(1))
+		require.False(t, l.isLoaded(3))
+		require.Equal(t, []int64{3}, l.warmUp([]int64{1, 2, 3}, now))
+		require.Empty(t, l.warmUp([]int64{1, 2, 3}, now))
+	})
+
+	t.Run("a request loads the organization", func(t *testing.T) {
+		l := newOrgLoader(0, 0)
+		require.True(t, l.activate(1, now))
+		require.False(t, l.activate(1, now))
+		require.True(t, l.isLoaded(1))
+		require.Empty(t, l.unloadIdle(now.Add(time.Hour), noAlerts), "the organizations are not unloaded without TTL")
+	})
+
+	t.Run("the idle organizations without alerts are unloaded", func(t *testing.T) {
+		l := newOrgLoader(1, time.Hour)
+		l.warmUp([]int64{1}, now)
+		l.activate(2, now)
+		l.activate(3, now.Add(30*time.Minute))
+		require.Empty(t, l.unloadIdle(now.Add(30*time.Minute), noAlerts))
+
+		unloaded := l.unloadIdle(now.Add(time.Hour), func(orgID int64) bool { return orgID == 2 })
+		require.Equal(t, []int64{1}, unloaded)
+		require.False(t, l.isLoaded(1))
+		require.True(t, l.isLoaded(2), "an organization with alerts is not unloaded")
+		require.Empty(t, l.warmUp([]int64{1}, now), "the warm-up doesn't load an unloaded organization again")
+		require.Equal(t, 2, l.count())
+	})
+}
+
+func TestLazyOrgLoading(t *testing.T) {
+	ruleStore := newFakeRuleStore(t)
+	sch, clk := setupScheduler(t, ruleStore, &fakeInstanceStore{}, newFakeAdminConfigStore(t))
+	sch.orgLoader = newOrgLoader(1, time.Hour)
+	sch.rules.orgs = sch.orgLoader.isLoaded
+	rule1 := CreateTestAlertRule(t, ruleStore, 10, 1)
+	rule2 := CreateTestAlertRule(t, ruleStore, 10, 2)
+
+	fetch := func() []string {
+		rules, err := sch.rules.fetch()
+		require.NoError(t, err)
+		uids := make([]string, 0, len(rules))
+		for _, r := range rules {
+			uids = append(uids, r.UID)
+		}
+		return uids
+	}
+
+	require.Empty(t, fetch(), "no organization is loaded at startup")
+	require.Equal(t, []int64{1, 2}, sch.rules.organizations())
+
+	sch.syncOrgs(clk.Now())
+	require.Equal(t, []string{rule1.UID}, fetch(), "the warm-up loads the first organization")
+
+	sch.ActivateOrg(2)
+	require.Equal(t, []string{rule1.UID, rule2.UID}, fetch(), "a request loads the organization")
+
+	sch.syncOrgs(clk.Now().Add(time.Hour))
+	require.Empty(t, fetch(), "the idle organizations are unloaded")
+}
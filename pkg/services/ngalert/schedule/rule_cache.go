@@ -0,0 +1,150 @@
+package schedule
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+)
+
+// ruleCache keeps the alert rules of all organisations in memory, so that the scheduler doesn't load them from the
+// database on every tick. The rules are reloaded when the watermark of the rules changes, and then only the new
+// and updated rules are loaded. The watermark doesn't use the updated column, which has a precision of a second
+// and depends on the clocks of the Grafana instances.
+type ruleCache struct {
+	store store.RuleStore
+
+	mtx       sync.RWMutex
+	loaded    bool
+	watermark models.AlertRulesWatermark
+	rules     map[models.AlertRuleKey]*models.AlertRule
+	// sorted lists the rules in a stable order, so that the evaluations are spread the same way on every tick.
+	sorted []*models.AlertRule
+	// orgs filters the organizations whose rules are loaded, all of them if nil. orgIDs are the organizations with
+	// rules, whether they are loaded or not.
+	orgs   func(orgID int64) bool
+	orgIDs []int64
+}
+
+func newRuleCache(store store.RuleStore) *ruleCache {
+	return &ruleCache{store: store, rules: make(map[models.AlertRuleKey]*models.AlertRule)}
+}
+
+// fetch returns all the alert rules, the changes since the previous fetch are loaded from the database.
+func (c *ruleCache) fetch() ([]*models.AlertRule, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	// The watermark is read first, so a change made while the rules are loaded is loaded again by the next fetch.
+	q := models.GetAlertRulesWatermarkQuery{}
+	if err := c.store.GetAlertRulesWatermark(&q); err != nil {
+		return nil, err
+	}
+	if c.loaded && q.Result == c.watermark {
+		return c.sorted, nil
+	}
+	if q.Result.FolderDefaults != c.watermark.FolderDefaults {
+		// The rules inherit the defaults of their folders, they are all loaded again when the defaults change.
+		c.rules = make(map[models.AlertRuleKey]*models.AlertRule)
+	}
+
+	if err := c.sync(); err != nil {
+		return nil, err
+	}
+	c.watermark = q.Result
+	c.loaded = true
+	return c.sorted, nil
+}
+
+// sync loads the new and updated rules and drops the deleted ones.
+func (c *ruleCache) sync() error {
+	keys := models.ListAlertRulesQuery{}
+	if err := c.store.GetAlertRulesForScheduling(&keys); err != nil {
+		return err
+	}
+
+	skipped := make(map[int64]bool)
+	for _, r := range keys.Result {
+		if _, ok := skipped[r.OrgID]; !ok {
+			skipped[r.OrgID] = c.orgs != nil && !c.orgs(r.OrgID)
+		}
+	}
+
+	changed := make([]int64, 0)
+	for _, r := range keys.Result {
+		if skipped[r.OrgID] {
+			continue
+		}
+		if cached, ok := c.rules[r.GetKey()]; !ok || cached.Version != r.Version {
+			changed = append(changed, r.ID)
+		}
+	}
+	loaded := make(map[models.AlertRuleKey]*models.AlertRule, len(changed))
+	if len(changed) > 0 {
+		q := models.ListAlertRulesByIDsQuery{IDs: changed}
+		if err := c.store.GetAlertRulesByIDs(&q); err != nil {
+			return err
+		}
+		for _, r := range q.Result {
+			loaded[r.GetKey()] = r
+		}
+	}
+
+	rules := make(map[models.AlertRuleKey]*models.AlertRule, len(keys.Result))
+	for _, r := range keys.Result {
+		if skipped[r.OrgID] {
+			continue
+		}
+		key := r.GetKey()
+		if l, ok := loaded[key]; ok {
+			rules[key] = l
+		} else if cached, ok := c.rules[key]; ok && cached.Version == r.Version {
+			rules[key] = cached
+		}
+		// Otherwise the rule was deleted after the keys were loaded.
+	}
+
+	sorted := make([]*models.AlertRule, 0, len(rules))
+	for _, r := range rules {
+		sorted = append(sorted, r)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].OrgID != sorted[j].OrgID {
+			return sorted[i].OrgID < sorted[j].OrgID
+		}
+		return sorted[i].UID < sorted[j].UID
+	})
+
+	orgIDs := make([]int64, 0, len(skipped))
+	for orgID := range skipped {
+		orgIDs = append(orgIDs, orgID)
+	}
+	sort.Slice(orgIDs, func(i, j int) bool { return orgIDs[i] < orgIDs[j] })
+
+	c.rules = rules
+	c.sorted = sorted
+	c.orgIDs = orgIDs
+	return nil
+}
+
+// invalidate makes the next fetch load the rules again, such as when an organization is loaded or unloaded.
+func (c *ruleCache) invalidate() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.loaded = false
+}
+
+// organizations returns the organizations with rules as of the last fetch, whether their rules are loaded or not.
+func (c *ruleCache) organizations() []int64 {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+	return c.orgIDs
+}
+
+// get returns the cached rule, or nil if the rule is not in the cache.
+func (c *ruleCache) get(key models.AlertRuleKey) *models.AlertRule {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+	return c.rules[key]
+}
@@ -0,0 +1,70 @@
+package schedule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// countingRuleStore counts the rules loaded in full by the rule cache.
+type countingRuleStore struct {
+	*fakeRuleStore
+	loaded []int64
+}
+
+func (s *countingRuleStore) GetAlertRulesByIDs(q *models.ListAlertRulesByIDsQuery) error {
+	s.loaded = append(s.loaded, q.IDs...)
+	return s.fakeRuleStore.GetAlertRulesByIDs(q)
+}
+
+func TestRuleCache(t *testing.T) {
+	fake := newFakeRuleStore(t)
+	setRules := func(rules ...*models.AlertRule) {
+		fake.mtx.Lock()
+		defer fake.mtx.Unlock()
+		fake.rules = map[int64]map[string]map[string][]*models.AlertRule{1: {"group": {"folder": rules}}}
+	}
+	rule := func(id int64, uid string, version int64) *models.AlertRule {
+		return &models.AlertRule{ID: id, OrgID: 1, UID: uid, Title: uid, Version: version, IntervalSeconds: 10}
+	}
+	store := &countingRuleStore{fakeRuleStore: fake}
+	cache := newRuleCache(store)
+
+	fetch := func() []string {
+		rules, err := cache.fetch()
+		require.NoError(t, err)
+		uids := make([]string, 0, len(rules))
+		for _, r := range rules {
+			uids = append(uids, r.UID)
+		}
+		return uids
+	}
+
+	setRules(rule(1, "b", 1), rule(2, "a", 1))
+	require.Equal(t, []string{"a", "b"}, fetch())
+	require.ElementsMatch(t, []int64{1, 2}, store.loaded)
+
+	t.Run("unchanged rules are not loaded again", func(t *testing.T) {
+		store.loaded = nil
+		require.Equal(t, []string{"a", "b"}, fetch())
+		require.Empty(t, store.loaded)
+	})
+
+	t.Run("only the updated and new rules are loaded", func(t *testing.T) {
+		store.loaded = nil
+		setRules(rule(1, "b", 2), rule(2, "a", 1), rule(3, "c", 1))
+		require.Equal(t, []string{"a", "b", "c"}, fetch())
+		require.ElementsMatch(t, []int64{1, 3}, store.loaded)
+		require.Equal(t, int64(2), cache.get(models.AlertRuleKey{OrgID: 1, UID: "b"}).Version)
+	})
+
+	t.Run("the deleted rules are dropped", func(t *testing.T) {
+		store.loaded = nil
+		setRules(rule(1, "b", 2), rule(3, "c", 1))
+		require.Equal(t, []string{"b", "c"}, fetch())
+		require.Empty(t, store.loaded)
+		require.Nil(t, cache.get(models.AlertRuleKey{OrgID: 1, UID: "a"}))
+	})
+}
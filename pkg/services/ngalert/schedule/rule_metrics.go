@@ -0,0 +1,47 @@
+package schedule
+
+import (
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// observeRuleEvaluation exports the metrics of an evaluation of a rule opting in to the metrics by rule, and deletes
+// the metrics of a rule that stopped opting in. It returns whether the rule opts in.
+func (sch *schedule) observeRuleEvaluation(alertRule *models.AlertRule, duration time.Duration, results eval.Results, err error) bool {
+	if sch.ruleMetrics == nil {
+		return false
+	}
+	if alertRule.Labels[models.RuleMetricsLabel] != "true" {
+		sch.ruleMetrics.Forget(alertRule.OrgID, alertRule.UID)
+		return false
+	}
+	sch.ruleMetrics.ObserveEvaluation(alertRule.OrgID, alertRule.UID, duration, countResults(results, err))
+	return true
+}
+
+// observeRuleAlerts exports the number of alerts by state of a rule opting in to the metrics by rule.
+func (sch *schedule) observeRuleAlerts(alertRule *models.AlertRule) {
+	alerts := map[string]int{}
+	for _, s := range []eval.State{eval.Normal, eval.Alerting, eval.Pending, eval.NoData, eval.Error} {
+		alerts[strings.ToLower(s.String())] = 0
+	}
+	for _, s := range sch.stateManager.GetStatesForRuleUID(alertRule.OrgID, alertRule.UID) {
+		alerts[strings.ToLower(s.State.String())]++
+	}
+	sch.ruleMetrics.ObserveAlerts(alertRule.OrgID, alertRule.UID, alerts)
+}
+
+// countResults counts the results of an evaluation by state, a failed evaluation is a result in error.
+func countResults(results eval.Results, err error) map[string]int {
+	if err != nil {
+		return map[string]int{strings.ToLower(eval.Error.String()): 1}
+	}
+	counts := make(map[string]int)
+	for _, r := range results {
+		counts[strings.ToLower(r.State.String())]++
+	}
+	return counts
+}
@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/grafana/grafana/pkg/expr"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/alerting"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
@@ -19,6 +20,11 @@ import (
 	"github.com/grafana/grafana/pkg/tsdb"
 
 	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/ext"
+	tlog "github.com/opentracing/opentracing-go/log"
+	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -32,6 +38,9 @@ type ScheduleService interface {
 	Unpause() error
 	AlertmanagersFor(orgID int64) []*url.URL
 	DroppedAlertmanagersFor(orgID int64) []*url.URL
+	AlertmanagersHealthFor(orgID int64) []sender.TargetHealth
+	EvaluationLagFor(orgID int64) time.Duration
+	ActivateOrg(orgID int64)
 
 	// the following are used by tests only used for tests
 	evalApplied(models.AlertRuleKey, time.Time)
@@ -43,8 +52,10 @@ type schedule struct {
 	// base tick rate (fastest possible configured check)
 	baseInterval time.Duration
 
-	// each alert rule gets its own channel and routine
+	// registry holds the alert rules scheduled, their evaluations wait in the queue for one of the poolSize workers
 	registry alertRuleRegistry
+	queue    *evaluationQueue
+	poolSize int
 
 	maxAttempts int64
 
@@ -65,14 +76,28 @@ type schedule struct {
 	log log.Logger
 
 	evaluator eval.Evaluator
+	// remoteEvaluator evaluates the rules in evaluation workers instead of the evaluator, if not nil.
+	remoteEvaluator RemoteEvaluator
 
-	ruleStore        store.RuleStore
+	ruleStore store.RuleStore
+	rules     *ruleCache
+	// orgLoader loads the organizations lazily, if not nil.
+	orgLoader        *orgLoader
 	instanceStore    store.InstanceStore
 	adminConfigStore store.AdminConfigurationStore
 	orgStore         store.OrgStore
+	heartbeatStore   store.HeartbeatStore
 	dataService      *tsdb.Service
+	// datasourceMaintenanceStore provides the maintenance windows of the datasources, if not nil.
+	datasourceMaintenanceStore store.DatasourceMaintenanceStore
 
 	stateManager *state.Manager
+	// stateExporter exports the states of the alert instances after each evaluation, if not nil.
+	stateExporter StateExporter
+	// evalRecorder records each evaluation of the rules, if not nil.
+	evalRecorder EvaluationRecorder
+	// queryLogger logs the datasource queries of the rules evaluated by the scheduler, if not nil.
+	queryLogger QueryLogger
 
 	appURL string
 
@@ -83,7 +108,41 @@ type schedule struct {
 	sendersMtx              sync.RWMutex
 	sendersCfgHash          map[int64]string
 	senders                 map[int64]*sender.Sender
+	sendAlertsTo            map[int64]models.AlertmanagersChoice
 	adminConfigPollInterval time.Duration
+
+	// evalSlots limits the concurrent evaluations of each organization.
+	evalSlots *orgEvaluationSlots
+	// groupEvalSlots limits the concurrent evaluations of each rule group.
+	groupEvalSlots *groupEvaluationSlots
+	// evalLags are the lags of the latest evaluations of each organization.
+	evalLags *evaluationLags
+
+	// ruleMetrics exports the metrics of the rules opting in to the metrics by rule, if not nil.
+	ruleMetrics *metrics.RuleMetrics
+
+	// selfMonitor records the ticks and evaluations for the self-monitoring of the alerting, if not nil.
+	selfMonitor *notifier.SelfMonitor
+}
+
+// RemoteEvaluator evaluates the conditions of the alert rules out of the scheduler.
+type RemoteEvaluator interface {
+	ConditionEval(ctx context.Context, condition *models.Condition, now time.Time) (eval.Results, error)
+}
+
+// StateExporter exports the states of the alert instances of an evaluation, such as to a remote write endpoint.
+type StateExporter interface {
+	Export(states []*state.State, now time.Time)
+}
+
+// EvaluationRecorder records the evaluations of the alert rules, such as for the insights of the rules.
+type EvaluationRecorder interface {
+	RecordEvaluation(key models.AlertRuleKey, now time.Time, duration time.Duration, results eval.Results, err error)
+}
+
+// QueryLogger logs the datasource queries of the evaluations of the alert rules.
+type QueryLogger interface {
+	LogQuery(key models.AlertRuleKey, q expr.QueryStats)
 }
 
 // SchedulerCfg is the scheduler configuration.
@@ -95,20 +154,48 @@ type SchedulerCfg struct {
 	MaxAttempts             int64
 	StopAppliedFunc         func(models.AlertRuleKey)
 	Evaluator               eval.Evaluator
+	RemoteEvaluator         RemoteEvaluator
+	StateExporter           StateExporter
+	EvaluationRecorder      EvaluationRecorder
+	QueryLogger             QueryLogger
 	RuleStore               store.RuleStore
 	OrgStore                store.OrgStore
 	InstanceStore           store.InstanceStore
 	AdminConfigStore        store.AdminConfigurationStore
+	HeartbeatStore          store.HeartbeatStore
 	MultiOrgNotifier        *notifier.MultiOrgAlertmanager
 	Metrics                 *metrics.Metrics
 	AdminConfigPollInterval time.Duration
+	// RuleMetricsMaxRules is the maximum number of rules exporting metrics by rule, 0 is no limit.
+	RuleMetricsMaxRules int
+	// PoolSize is the number of workers evaluating the rules, and MaxQueueSize the maximum number of evaluations
+	// waiting for a worker, 0 is no limit.
+	PoolSize     int
+	MaxQueueSize int
+	// LazyOrgLoading loads the rules and states of the organizations lazily, OrgWarmUpBatchSize organizations on
+	// each tick, and unloads the organizations idle for OrgIdleTTL, 0 is never.
+	LazyOrgLoading     bool
+	OrgWarmUpBatchSize int
+	OrgIdleTTL         time.Duration
+	// DatasourceMaintenanceStore provides the maintenance windows of the datasources the rules hold during.
+	DatasourceMaintenanceStore store.DatasourceMaintenanceStore
 }
 
 // NewScheduler returns a new schedule.
 func NewScheduler(cfg SchedulerCfg, dataService *tsdb.Service, appURL string, stateManager *state.Manager) *schedule {
 	ticker := alerting.NewTicker(cfg.C.Now(), time.Second*0, cfg.C, int64(cfg.BaseInterval.Seconds()))
+	poolSize := cfg.PoolSize
+	if poolSize <= 0 {
+		poolSize = defaultPoolSize
+	}
+	var queueDepth prometheus.Gauge
+	if cfg.Metrics != nil {
+		queueDepth = cfg.Metrics.SchedulerQueueDepth
+	}
 	sch := schedule{
-		registry:                alertRuleRegistry{alertRuleInfo: make(map[models.AlertRuleKey]alertRuleInfo)},
+		registry:                alertRuleRegistry{alertRuleInfo: make(map[models.AlertRuleKey]*alertRuleInfo)},
+		queue:                   newEvaluationQueue(cfg.MaxQueueSize, queueDepth),
+		poolSize:                poolSize,
 		maxAttempts:             cfg.MaxAttempts,
 		clock:                   cfg.C,
 		baseInterval:            cfg.BaseInterval,
@@ -117,9 +204,15 @@ func NewScheduler(cfg SchedulerCfg, dataService *tsdb.Service, appURL string, st
 		evalAppliedFunc:         cfg.EvalAppliedFunc,
 		stopAppliedFunc:         cfg.StopAppliedFunc,
 		evaluator:               cfg.Evaluator,
+		remoteEvaluator:         cfg.RemoteEvaluator,
+		stateExporter:           cfg.StateExporter,
+		evalRecorder:            cfg.EvaluationRecorder,
+		queryLogger:             cfg.QueryLogger,
 		ruleStore:               cfg.RuleStore,
+		rules:                   newRuleCache(cfg.RuleStore),
 		instanceStore:           cfg.InstanceStore,
 		orgStore:                cfg.OrgStore,
+		heartbeatStore:          cfg.HeartbeatStore,
 		dataService:             dataService,
 		adminConfigStore:        cfg.AdminConfigStore,
 		multiOrgNotifier:        cfg.MultiOrgNotifier,
@@ -127,8 +220,24 @@ func NewScheduler(cfg SchedulerCfg, dataService *tsdb.Service, appURL string, st
 		appURL:                  appURL,
 		stateManager:            stateManager,
 		senders:                 map[int64]*sender.Sender{},
+		sendAlertsTo:            map[int64]models.AlertmanagersChoice{},
+		evalSlots:               newOrgEvaluationSlots(),
+		groupEvalSlots:          newGroupEvaluationSlots(),
+		evalLags:                newEvaluationLags(),
 		sendersCfgHash:          map[int64]string{},
 		adminConfigPollInterval: cfg.AdminConfigPollInterval,
+
+		datasourceMaintenanceStore: cfg.DatasourceMaintenanceStore,
+	}
+	if cfg.MultiOrgNotifier != nil {
+		sch.selfMonitor = cfg.MultiOrgNotifier.SelfMonitor()
+	}
+	if cfg.Metrics != nil {
+		sch.ruleMetrics = metrics.NewRuleMetrics(cfg.Metrics, cfg.RuleMetricsMaxRules)
+	}
+	if cfg.LazyOrgLoading {
+		sch.orgLoader = newOrgLoader(cfg.OrgWarmUpBatchSize, cfg.OrgIdleTTL)
+		sch.rules.orgs = sch.orgLoader.isLoaded
 	}
 	return &sch
 }
@@ -180,24 +289,29 @@ func (sch *schedule) SyncAndApplyConfigFromDatabase() error {
 	if err != nil {
 		return err
 	}
+	cfgs = sch.withDatasourceAlertmanagers(cfgs)
 
 	sch.log.Debug("found admin configurations", "count", len(cfgs))
 
 	orgsFound := make(map[int64]struct{}, len(cfgs))
 	sch.sendersMtx.Lock()
+	sch.sendAlertsTo = make(map[int64]models.AlertmanagersChoice, len(cfgs))
 	for _, cfg := range cfgs {
 		orgsFound[cfg.OrgID] = struct{}{} // keep track of the which senders we need to keep.
+		sch.sendAlertsTo[cfg.OrgID] = cfg.SendAlertsTo
 
 		existing, ok := sch.senders[cfg.OrgID]
+		// Alerts are not sent to the external Alertmanager(s) when the tenant only uses the internal one.
+		noExternal := len(cfg.Alertmanagers) == 0 || cfg.SendAlertsTo == models.InternalAlertmanager
 
 		// If the tenant has no Alertmanager(s) configured and no running sender no-op.
-		if !ok && len(cfg.Alertmanagers) == 0 {
+		if !ok && noExternal {
 			sch.log.Debug("no external alertmanagers configured", "org", cfg.OrgID)
 			continue
 		}
 
 		// We have a running sender but no Alertmanager(s) configured, shut it down.
-		if ok && len(cfg.Alertmanagers) == 0 {
+		if ok && noExternal {
 			sch.log.Debug("no external alertmanager(s) configured, sender will be stopped", "org", cfg.OrgID)
 			delete(orgsFound, cfg.OrgID)
 			continue
@@ -222,7 +336,7 @@ func (sch *schedule) SyncAndApplyConfigFromDatabase() error {
 
 		// No sender and have Alertmanager(s) to send to - start a new one.
 		sch.log.Info("creating new sender for the external alertmanagers", "org", cfg.OrgID, "alertmanagers", cfg.Alertmanagers)
-		s, err := sender.New(sch.metrics)
+		s, err := sender.New(cfg.OrgID, sch.metrics)
 		if err != nil {
 			sch.log.Error("unable to start the sender", "err", err, "org", cfg.OrgID)
 			continue
@@ -263,6 +377,51 @@ func (sch *schedule) SyncAndApplyConfigFromDatabase() error {
 	return nil
 }
 
+// withDatasourceAlertmanagers adds the Alertmanager datasources that handle Grafana managed alerts to the
+// Alertmanager(s) of the admin configurations, organizations without admin configuration get one.
+// The datasources are read on every sync, so adding, updating or removing one updates the senders.
+func (sch *schedule) withDatasourceAlertmanagers(cfgs []*models.AdminConfiguration) []*models.AdminConfiguration {
+	dss, err := sch.adminConfigStore.GetAlertmanagerDatasources()
+	if err != nil {
+		sch.log.Error("unable to get the Alertmanager datasources", "err", err)
+		return cfgs
+	}
+
+	discovered := map[int64][]string{}
+	for _, ds := range dss {
+		if !sender.HandlesGrafanaManagedAlerts(ds) {
+			continue
+		}
+		amURL, err := sender.DatasourceAlertmanagerURL(ds)
+		if err != nil {
+			sch.log.Error("unable to send alerts to the Alertmanager datasource", "org", ds.OrgId, "datasource", ds.Uid, "err", err)
+			continue
+		}
+		discovered[ds.OrgId] = append(discovered[ds.OrgId], amURL)
+	}
+	if len(discovered) == 0 {
+		return cfgs
+	}
+
+	res := make([]*models.AdminConfiguration, 0, len(cfgs)+len(discovered))
+	for _, cfg := range cfgs {
+		urls, ok := discovered[cfg.OrgID]
+		if !ok {
+			res = append(res, cfg)
+			continue
+		}
+		// Don't modify the configuration of the store.
+		c := *cfg
+		c.Alertmanagers = append(append(make([]string, 0, len(cfg.Alertmanagers)+len(urls)), cfg.Alertmanagers...), urls...)
+		res = append(res, &c)
+		delete(discovered, cfg.OrgID)
+	}
+	for orgID, urls := range discovered {
+		res = append(res, &models.AdminConfiguration{OrgID: orgID, Alertmanagers: urls})
+	}
+	return res
+}
+
 // AlertmanagersFor returns all the discovered Alertmanager(s) for a particular organization.
 func (sch *schedule) AlertmanagersFor(orgID int64) []*url.URL {
 	sch.sendersMtx.RLock()
@@ -287,6 +446,23 @@ func (sch *schedule) DroppedAlertmanagersFor(orgID int64) []*url.URL {
 	return s.DroppedAlertmanagers()
 }
 
+// AlertmanagersHealthFor returns the health of the configured Alertmanager(s) for a particular organization.
+func (sch *schedule) AlertmanagersHealthFor(orgID int64) []sender.TargetHealth {
+	sch.sendersMtx.RLock()
+	defer sch.sendersMtx.RUnlock()
+	s, ok := sch.senders[orgID]
+	if !ok {
+		return []sender.TargetHealth{}
+	}
+
+	return s.Health()
+}
+
+// EvaluationLagFor returns how late the latest evaluation of the organization started, 0 if there was none.
+func (sch *schedule) EvaluationLagFor(orgID int64) time.Duration {
+	return sch.evalLags.get(orgID)
+}
+
 func (sch *schedule) adminConfigSync(ctx context.Context) error {
 	for {
 		select {
@@ -310,10 +486,21 @@ func (sch *schedule) adminConfigSync(ctx context.Context) error {
 
 func (sch *schedule) ruleEvaluationLoop(ctx context.Context) error {
 	dispatcherGroup, ctx := errgroup.WithContext(ctx)
+	for i := 0; i < sch.poolSize; i++ {
+		dispatcherGroup.Go(func() error {
+			return sch.evaluationWorker(ctx)
+		})
+	}
 	for {
 		select {
 		case tick := <-sch.heartbeat.C:
+			if sch.selfMonitor != nil {
+				sch.selfMonitor.RecordTick(tick, sch.clock.Now(), sch.baseInterval)
+			}
+			tickSpan := opentracing.StartSpan("alerting.scheduler.tick")
+			tickSpan.SetTag("tick_unixnano", tick.UnixNano())
 			tickNum := tick.Unix() / int64(sch.baseInterval.Seconds())
+			sch.syncOrgs(tick)
 			alertRules := sch.fetchAllDetails()
 			sch.log.Debug("alert rules fetched", "count", len(alertRules))
 
@@ -325,23 +512,22 @@ func (sch *schedule) ruleEvaluationLoop(ctx context.Context) error {
 
 			type readyToRunItem struct {
 				key      models.AlertRuleKey
-				ruleInfo alertRuleInfo
+				ruleInfo *alertRuleInfo
+				interval time.Duration
+				rule     *models.AlertRule
 			}
 
 			readyToRun := make([]readyToRunItem, 0)
 			for _, item := range alertRules {
+				if !item.IsActive(tick) {
+					// the rules outside of their activation window are unregistered like the deleted rules
+					continue
+				}
 				key := item.GetKey()
 				itemVersion := item.Version
-				newRoutine := !sch.registry.exists(key)
 				ruleInfo := sch.registry.getOrCreateInfo(key, itemVersion)
 				invalidInterval := item.IntervalSeconds%int64(sch.baseInterval.Seconds()) != 0
 
-				if newRoutine && !invalidInterval {
-					dispatcherGroup.Go(func() error {
-						return sch.ruleRoutine(ctx, key, ruleInfo.evalCh, ruleInfo.stopCh)
-					})
-				}
-
 				if invalidInterval {
 					// this is expected to be always false
 					// give that we validate interval during alert rule updates
@@ -351,7 +537,7 @@ func (sch *schedule) ruleEvaluationLoop(ctx context.Context) error {
 
 				itemFrequency := item.IntervalSeconds / int64(sch.baseInterval.Seconds())
 				if item.IntervalSeconds != 0 && tickNum%itemFrequency == 0 {
-					readyToRun = append(readyToRun, readyToRunItem{key: key, ruleInfo: ruleInfo})
+					readyToRun = append(readyToRun, readyToRunItem{key: key, ruleInfo: ruleInfo, interval: time.Duration(item.IntervalSeconds) * time.Second, rule: item})
 				}
 
 				// remove the alert rule from the registered alert rules
@@ -363,23 +549,41 @@ func (sch *schedule) ruleEvaluationLoop(ctx context.Context) error {
 				step = sch.baseInterval.Nanoseconds() / int64(len(readyToRun))
 			}
 
-			for i := range readyToRun {
-				item := readyToRun[i]
-
-				time.AfterFunc(time.Duration(int64(i)*step), func() {
-					item.ruleInfo.evalCh <- &evalContext{now: tick, version: item.ruleInfo.version}
+			// the evaluations are spread over the base interval, an evaluation is shed if it's still waiting for a
+			// worker when the next evaluation of its rule is due
+			var inMaintenance datasourcesInMaintenance
+			if len(readyToRun) > 0 {
+				inMaintenance = sch.fetchDatasourcesInMaintenance(tick)
+			}
+			now := timeNow()
+			for i, item := range readyToRun {
+				due := now.Add(time.Duration(int64(i) * step))
+				sch.enqueue(&evaluationTask{
+					key:              item.key,
+					info:             item.ruleInfo,
+					evalCtx:          &evalContext{now: tick, version: item.ruleInfo.version, tick: tickSpan.Context(), inMaintenance: inMaintenance},
+					due:              due,
+					deadline:         due.Add(item.interval),
+					scheduled:        due,
+					group:            ruleGroupKeyOf(item.rule),
+					groupConcurrency: int(item.rule.GroupConcurrency),
 				})
 			}
+			tickSpan.SetTag("rules", len(alertRules))
+			tickSpan.SetTag("rules_evaluated", len(readyToRun))
+			tickSpan.Finish()
 
-			// unregister and stop routines of the deleted alert rules
+			// unregister the deleted alert rules, their evaluations still waiting are dropped
 			for key := range registeredDefinitions {
 				ruleInfo, err := sch.registry.get(key)
 				if err != nil {
-					sch.log.Error("failed to get alert rule routine information", "err", err)
+					sch.log.Error("failed to get alert rule information", "err", err)
 					continue
 				}
-				ruleInfo.stopCh <- struct{}{}
 				sch.registry.del(key)
+				if !ruleInfo.stop() {
+					sch.forgetRule(key)
+				}
 			}
 		case <-ctx.Done():
 			waitErr := dispatcherGroup.Wait()
@@ -399,104 +603,191 @@ func (sch *schedule) ruleEvaluationLoop(ctx context.Context) error {
 	}
 }
 
-func (sch *schedule) ruleRoutine(grafanaCtx context.Context, key models.AlertRuleKey, evalCh <-chan *evalContext, stopCh <-chan struct{}) error {
-	sch.log.Debug("alert rule routine started", "key", key)
+// evaluateRule evaluates an alert rule, and processes and sends the alerts of the evaluation.
+func (sch *schedule) evaluateRule(grafanaCtx context.Context, key models.AlertRuleKey, info *alertRuleInfo, ctx *evalContext, attempt int64) error {
+	start := timeNow()
+	span, tracingCtx := startEvaluationSpan(grafanaCtx, key, ctx, attempt)
+	defer span.Finish()
+
+	// fetch latest alert rule version, from the rule cache if it has it. The cached rule is used even with the same
+	// version, it's loaded again when the defaults of its folder change.
+	alertRule := info.rule
+	if cached := sch.rules.get(key); cached != nil && cached.Version >= ctx.version {
+		alertRule = cached
+	} else if alertRule == nil || alertRule.Version < ctx.version {
+		q := models.GetAlertRuleByUIDQuery{OrgID: key.OrgID, UID: key.UID}
+		err := sch.ruleStore.GetAlertRuleByUID(&q)
+		if err != nil {
+			sch.log.Error("failed to fetch alert rule", "key", key)
+			return err
+		}
+		alertRule = q.Result
+	}
+	if alertRule != info.rule {
+		sch.log.Debug("new alert rule version fetched", "title", alertRule.Title, "key", key, "version", alertRule.Version)
+		info.rule = alertRule
+	}
+
+	if uids := ctx.inMaintenance.holding(alertRule); len(uids) > 0 {
+		// the rule is not evaluated while its datasources are down for maintenance, its alerts keep their state
+		sch.log.Debug("alert rule held during the maintenance of its datasources", "title", alertRule.Title, "key", key, "datasources", uids)
+		sch.metrics.EvalHeld.WithLabelValues(fmt.Sprint(alertRule.OrgID)).Inc()
+		span.SetTag("held", true)
+		heldStates := sch.stateManager.HoldStates(alertRule, ctx.now)
+		sch.saveAlertStates(heldStates)
+		sch.sendAlerts(tracingCtx, alertRule, heldStates, timeNow())
+		return nil
+	}
 
-	evalRunning := false
-	var attempt int64
-	var alertRule *models.AlertRule
-	for {
-		select {
-		case ctx := <-evalCh:
-			if evalRunning {
-				continue
+	var results eval.Results
+	var err error
+	if alertRule.Composite.IsComposite() {
+		// composite rules are evaluated over the states of their rules, without queries
+		results = sch.stateManager.EvaluateComposite(alertRule, ctx.now)
+	} else if alertRule.Heartbeat.IsHeartbeat() {
+		// heartbeat rules are evaluated over the time of their last heartbeat, without queries
+		results, err = sch.evaluateHeartbeat(alertRule, ctx.now)
+	} else {
+		// the threshold expressions with an exit value compare the numbers that passed them at the
+		// previous evaluation with the exit value
+		var queries []models.AlertQuery
+		queries, err = eval.WithPreviouslyFiring(alertRule.Data, func(refID string) []data.Labels {
+			return sch.stateManager.GetNonZeroValueLabels(alertRule.OrgID, alertRule.UID, refID)
+		})
+		if err == nil {
+			condition := models.Condition{
+				Condition:   alertRule.Condition,
+				OrgID:       alertRule.OrgID,
+				Data:        queries,
+				OwnerUserID: alertRule.OwnerUserID,
 			}
-
-			evaluate := func(attempt int64) error {
-				start := timeNow()
-
-				// fetch latest alert rule version
-				if alertRule == nil || alertRule.Version < ctx.version {
-					q := models.GetAlertRuleByUIDQuery{OrgID: key.OrgID, UID: key.UID}
-					err := sch.ruleStore.GetAlertRuleByUID(&q)
-					if err != nil {
-						sch.log.Error("failed to fetch alert rule", "key", key)
-						return err
-					}
-					alertRule = q.Result
-					sch.log.Debug("new alert rule version fetched", "title", alertRule.Title, "key", key, "version", alertRule.Version)
+			if sch.remoteEvaluator != nil {
+				results, err = sch.remoteEvaluator.ConditionEval(tracingCtx, &condition, ctx.now)
+			} else {
+				evalCtx := tracingCtx
+				if sch.queryLogger != nil {
+					evalCtx = expr.WithQueryObserver(tracingCtx, func(q expr.QueryStats) {
+						sch.queryLogger.LogQuery(key, q)
+					})
 				}
+				results, err = sch.evaluator.ConditionEvalWithContext(evalCtx, &condition, ctx.now, sch.dataService)
+			}
+		}
+		if err == nil && !alertRule.Thresholds.IsEmpty() {
+			results = eval.ApplyThresholds(alertRule.Thresholds, results)
+		}
+	}
+	var (
+		end    = timeNow()
+		tenant = fmt.Sprint(alertRule.OrgID)
+		dur    = end.Sub(start).Seconds()
+	)
+
+	if err == nil {
+		// the rule fails rather than taking the alert instances of the organization over its limit
+		var limited bool
+		other := sch.stateManager.CountOtherRules(alertRule.OrgID, alertRule.UID)
+		results, limited = limitInstances(results, other, sch.orgLimits(alertRule.OrgID).MaxInstances, ctx.now)
+		if limited {
+			sch.metrics.InstancesLimited.WithLabelValues(tenant).Inc()
+			sch.log.Warn("alert rule over the limit of alert instances of the organization", "title", alertRule.Title, "key", key)
+		}
+	}
 
-				condition := models.Condition{
-					Condition: alertRule.Condition,
-					OrgID:     alertRule.OrgID,
-					Data:      alertRule.Data,
-				}
-				results, err := sch.evaluator.ConditionEval(&condition, ctx.now, sch.dataService)
-				var (
-					end    = timeNow()
-					tenant = fmt.Sprint(alertRule.OrgID)
-					dur    = end.Sub(start).Seconds()
-				)
-
-				sch.metrics.EvalTotal.WithLabelValues(tenant).Inc()
-				sch.metrics.EvalDuration.WithLabelValues(tenant).Observe(dur)
-				if err != nil {
-					sch.metrics.EvalFailures.WithLabelValues(tenant).Inc()
-					// consider saving alert instance on error
-					sch.log.Error("failed to evaluate alert rule", "title", alertRule.Title,
-						"key", key, "attempt", attempt, "now", ctx.now, "duration", end.Sub(start), "error", err)
-					return err
-				}
+	sch.metrics.EvalTotal.WithLabelValues(tenant).Inc()
+	sch.metrics.EvalDuration.WithLabelValues(tenant).Observe(dur)
+	if sch.evalRecorder != nil {
+		sch.evalRecorder.RecordEvaluation(key, ctx.now, end.Sub(start), results, err)
+	}
+	if sch.selfMonitor != nil {
+		sch.selfMonitor.RecordEvaluation(key, results, err)
+	}
+	exportRuleMetrics := sch.observeRuleEvaluation(alertRule, end.Sub(start), results, err)
+	if err != nil {
+		sch.metrics.EvalFailures.WithLabelValues(tenant).Inc()
+		// consider saving alert instance on error
+		sch.log.Error("failed to evaluate alert rule", "title", alertRule.Title,
+			"key", key, "attempt", attempt, "now", ctx.now, "duration", end.Sub(start), "error", err)
+		ext.Error.Set(span, true)
+		span.LogFields(tlog.Error(err))
+		return err
+	}
+	span.SetTag("results", len(results))
 
-				processedStates := sch.stateManager.ProcessEvalResults(alertRule, results)
-				sch.saveAlertStates(processedStates)
-				alerts := FromAlertStateToPostableAlerts(sch.log, processedStates, sch.stateManager, sch.appURL)
-
-				sch.log.Debug("sending alerts to notifier", "count", len(alerts.PostableAlerts), "alerts", alerts.PostableAlerts, "org", alertRule.OrgID)
-				n, err := sch.multiOrgNotifier.AlertmanagerFor(alertRule.OrgID)
-				if err == nil {
-					if err := n.PutAlerts(alerts); err != nil {
-						sch.log.Error("failed to put alerts in the notifier", "count", len(alerts.PostableAlerts), "err", err)
-					}
-				} else {
-					sch.log.Error("unable to lookup local notifier for this org - alerts not delivered", "org", alertRule.OrgID, "count", len(alerts.PostableAlerts), "err", err)
-				}
+	stateSpan, _ := opentracing.StartSpanFromContext(tracingCtx, "alerting.rule.state")
+	processedStates := sch.stateManager.ProcessEvalResults(alertRule, results)
+	sch.saveAlertStates(processedStates)
+	if sch.stateExporter != nil {
+		sch.stateExporter.Export(processedStates, ctx.now)
+	}
+	if exportRuleMetrics {
+		sch.observeRuleAlerts(alertRule)
+	}
+	stateSpan.SetTag("states", len(processedStates))
+	stateSpan.Finish()
+	sch.sendAlerts(tracingCtx, alertRule, processedStates, end)
+	return nil
+}
 
-				// Send alerts to external Alertmanager(s) if we have a sender for this organization.
-				sch.sendersMtx.RLock()
-				defer sch.sendersMtx.RUnlock()
-				s, ok := sch.senders[alertRule.OrgID]
-				if ok {
-					s.SendAlerts(alerts)
-				}
+// sendAlerts sends the alerts of the states of an alert rule to the embedded and external Alertmanagers.
+func (sch *schedule) sendAlerts(tracingCtx context.Context, alertRule *models.AlertRule, processedStates []*state.State, end time.Time) {
+	key := alertRule.GetKey()
+	if alertRule.IsShadow() {
+		// the states of the shadow rules are only kept to be compared with the states of their original rule
+		sch.log.Debug("alerts of shadow rule not notified", "title", alertRule.Title, "key", key, "original", alertRule.ShadowOf)
+		return
+	}
+	alerts := FromAlertStateToPostableAlerts(sch.log, processedStates, sch.stateManager, sch.appURL)
 
-				return nil
-			}
+	notifySpan, _ := opentracing.StartSpanFromContext(tracingCtx, "alerting.rule.notification")
+	notifySpan.SetTag("alerts", len(alerts.PostableAlerts))
+	defer notifySpan.Finish()
 
-			func() {
-				evalRunning = true
-				defer func() {
-					evalRunning = false
-					sch.evalApplied(key, ctx.now)
-				}()
-
-				for attempt = 0; attempt < sch.maxAttempts; attempt++ {
-					err := evaluate(attempt)
-					if err == nil {
-						break
-					}
-				}
-			}()
-		case <-stopCh:
-			sch.stopApplied(key)
-			sch.log.Debug("stopping alert rule routine", "key", key)
-			// interrupt evaluation if it's running
-			return nil
-		case <-grafanaCtx.Done():
-			return grafanaCtx.Err()
+	sch.sendersMtx.RLock()
+	defer sch.sendersMtx.RUnlock()
+	sendAlertsTo := sch.sendAlertsTo[alertRule.OrgID]
+
+	n, err := sch.multiOrgNotifier.AlertmanagerFor(alertRule.OrgID)
+	if err == nil {
+		// the labels are rewritten the same way for the embedded and the external Alertmanagers
+		n.ApplyLabelPolicies(alerts)
+	}
+	if sendAlertsTo != models.ExternalAlertmanagers {
+		sch.log.Debug("sending alerts to notifier", "count", len(alerts.PostableAlerts), "alerts", alerts.PostableAlerts, "org", alertRule.OrgID)
+		if err == nil {
+			if err := n.PutAlerts(alerts); err != nil {
+				sch.log.Error("failed to put alerts in the notifier", "count", len(alerts.PostableAlerts), "err", err)
+			}
+		} else {
+			sch.log.Error("unable to lookup local notifier for this org - alerts not delivered", "org", alertRule.OrgID, "count", len(alerts.PostableAlerts), "err", err)
 		}
 	}
+
+	// Send alerts to external Alertmanager(s) if we have a sender for this organization,
+	// unless the organization is in maintenance mode.
+	s, ok := sch.senders[alertRule.OrgID]
+	if ok && n != nil && n.InMaintenanceMode(end) {
+		sch.log.Debug("organization in maintenance mode, alerts not sent to external Alertmanagers", "org", alertRule.OrgID, "count", len(alerts.PostableAlerts))
+		ok = false
+	}
+	if ok {
+		s.SendAlerts(alerts)
+	}
+}
+
+// startEvaluationSpan starts the span of an attempt to evaluate an alert rule, which follows from the span of the
+// tick of the scheduler.
+func startEvaluationSpan(ctx context.Context, key models.AlertRuleKey, evalCtx *evalContext, attempt int64) (opentracing.Span, context.Context) {
+	var opts []opentracing.StartSpanOption
+	if evalCtx.tick != nil {
+		opts = append(opts, opentracing.FollowsFrom(evalCtx.tick))
+	}
+	span := opentracing.StartSpan("alerting.rule.evaluation", opts...)
+	span.SetTag("rule_uid", key.UID)
+	span.SetTag("org_id", key.OrgID)
+	span.SetTag("attempt", attempt)
+	span.SetTag("now_unixnano", evalCtx.now.UnixNano())
+	return span, opentracing.ContextWithSpan(ctx, span)
 }
 
 func (sch *schedule) saveAlertStates(states []*state.State) {
@@ -520,26 +811,25 @@ func (sch *schedule) saveAlertStates(states []*state.State) {
 
 type alertRuleRegistry struct {
 	mu            sync.Mutex
-	alertRuleInfo map[models.AlertRuleKey]alertRuleInfo
+	alertRuleInfo map[models.AlertRuleKey]*alertRuleInfo
 }
 
-// getOrCreateInfo returns the channel for the specific alert rule
+// getOrCreateInfo returns the information of the specific alert rule
 // if it does not exists creates one and returns it
-func (r *alertRuleRegistry) getOrCreateInfo(key models.AlertRuleKey, ruleVersion int64) alertRuleInfo {
+func (r *alertRuleRegistry) getOrCreateInfo(key models.AlertRuleKey, ruleVersion int64) *alertRuleInfo {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	info, ok := r.alertRuleInfo[key]
 	if !ok {
-		r.alertRuleInfo[key] = alertRuleInfo{evalCh: make(chan *evalContext), stopCh: make(chan struct{}), version: ruleVersion}
-		return r.alertRuleInfo[key]
+		info = &alertRuleInfo{}
+		r.alertRuleInfo[key] = info
 	}
 	info.version = ruleVersion
-	r.alertRuleInfo[key] = info
 	return info
 }
 
-// get returns the channel for the specific alert rule
+// get returns the information of the specific alert rule
 // if the key does not exist returns an error
 func (r *alertRuleRegistry) get(key models.AlertRuleKey) (*alertRuleInfo, error) {
 	r.mu.Lock()
@@ -549,15 +839,7 @@ func (r *alertRuleRegistry) get(key models.AlertRuleKey) (*alertRuleInfo, error)
 	if !ok {
 		return nil, fmt.Errorf("%v key not found", key)
 	}
-	return &info, nil
-}
-
-func (r *alertRuleRegistry) exists(key models.AlertRuleKey) bool {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
-	_, ok := r.alertRuleInfo[key]
-	return ok
+	return info, nil
 }
 
 func (r *alertRuleRegistry) del(key models.AlertRuleKey) {
@@ -593,14 +875,51 @@ func (r *alertRuleRegistry) keyMap() map[models.AlertRuleKey]struct{} {
 }
 
 type alertRuleInfo struct {
-	evalCh  chan *evalContext
-	stopCh  chan struct{}
 	version int64
+
+	mtx sync.Mutex
+	// running is whether an evaluation of the rule is running, a rule is evaluated by one worker at a time, and
+	// stopped whether the rule was deleted.
+	running bool
+	stopped bool
+	// rule is the last version of the rule fetched, only used by the worker evaluating the rule.
+	rule *models.AlertRule
+}
+
+// start marks the rule as evaluated, unless it's already or it was deleted.
+func (info *alertRuleInfo) start() (started bool, stopped bool) {
+	info.mtx.Lock()
+	defer info.mtx.Unlock()
+	if info.stopped || info.running {
+		return false, info.stopped
+	}
+	info.running = true
+	return true, false
+}
+
+// finish marks the evaluation of the rule as finished, it returns whether the rule was deleted meanwhile.
+func (info *alertRuleInfo) finish() (stopped bool) {
+	info.mtx.Lock()
+	defer info.mtx.Unlock()
+	info.running = false
+	return info.stopped
+}
+
+// stop marks the rule as deleted, it returns whether an evaluation of the rule is running.
+func (info *alertRuleInfo) stop() (running bool) {
+	info.mtx.Lock()
+	defer info.mtx.Unlock()
+	info.stopped = true
+	return info.running
 }
 
 type evalContext struct {
 	now     time.Time
 	version int64
+	// tick is the span of the tick of the scheduler that scheduled the evaluation.
+	tick opentracing.SpanContext
+	// inMaintenance are the datasources in maintenance at the tick.
+	inMaintenance datasourcesInMaintenance
 }
 
 // overrideCfg is only used on tests.
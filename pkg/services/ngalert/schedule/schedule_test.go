@@ -18,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/schedule"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/ngalert/tests"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
@@ -101,7 +102,7 @@ func TestWarmStateCache(t *testing.T) {
 		Metrics:                 metrics.NewMetrics(prometheus.NewRegistry()),
 		AdminConfigPollInterval: 10 * time.Minute, // do not poll in unit tests.
 	}
-	st := state.NewManager(schedCfg.Logger, nilMetrics, dbstore, dbstore)
+	st := state.NewManager(schedCfg.Logger, nilMetrics, dbstore, dbstore, nil, nil, nil)
 	st.Warm()
 
 	t.Run("instance cache has expected entries", func(t *testing.T) {
@@ -146,7 +147,7 @@ func TestAlertingTicker(t *testing.T) {
 		Metrics:                 metrics.NewMetrics(prometheus.NewRegistry()),
 		AdminConfigPollInterval: 10 * time.Minute, // do not poll in unit tests.
 	}
-	st := state.NewManager(schedCfg.Logger, nilMetrics, dbstore, dbstore)
+	st := state.NewManager(schedCfg.Logger, nilMetrics, dbstore, dbstore, nil, nil, nil)
 	sched := schedule.NewScheduler(schedCfg, nil, "http://localhost", st)
 
 	ctx := context.Background()
@@ -216,6 +217,61 @@ func TestAlertingTicker(t *testing.T) {
 	})
 }
 
+func TestActivationWindow(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, 1)
+	mockedClock := clock.NewMock()
+
+	rule := tests.CreateTestAlertRule(t, dbstore, 1)
+	window := *rule
+	window.ActiveFrom = mockedClock.Now().Add(2 * time.Second)
+	window.ActiveUntil = mockedClock.Now().Add(4 * time.Second)
+	require.NoError(t, dbstore.UpsertAlertRules([]store.UpsertRule{{Existing: rule, New: window}}))
+
+	evalAppliedCh := make(chan evalAppliedInfo, 1)
+	stopAppliedCh := make(chan models.AlertRuleKey, 1)
+	schedCfg := schedule.SchedulerCfg{
+		C:            mockedClock,
+		BaseInterval: time.Second,
+		EvalAppliedFunc: func(alertDefKey models.AlertRuleKey, now time.Time) {
+			evalAppliedCh <- evalAppliedInfo{alertDefKey: alertDefKey, now: now}
+		},
+		StopAppliedFunc: func(alertDefKey models.AlertRuleKey) {
+			stopAppliedCh <- alertDefKey
+		},
+		RuleStore:               dbstore,
+		InstanceStore:           dbstore,
+		Logger:                  log.New("ngalert schedule test"),
+		Metrics:                 metrics.NewMetrics(prometheus.NewRegistry()),
+		AdminConfigPollInterval: 10 * time.Minute, // do not poll in unit tests.
+	}
+	st := state.NewManager(schedCfg.Logger, nilMetrics, dbstore, dbstore, nil, nil, nil)
+	sched := schedule.NewScheduler(schedCfg, nil, "http://localhost", st)
+
+	go func() {
+		err := sched.Run(context.Background())
+		require.NoError(t, err)
+	}()
+	runtime.Gosched()
+
+	t.Run("the rule is not evaluated before its activation window", func(t *testing.T) {
+		tick := advanceClock(t, mockedClock)
+		assertEvalRun(t, evalAppliedCh, tick)
+	})
+
+	t.Run("the rule is evaluated in its activation window", func(t *testing.T) {
+		tick := advanceClock(t, mockedClock)
+		assertEvalRun(t, evalAppliedCh, tick, rule.GetKey())
+		tick = advanceClock(t, mockedClock)
+		assertEvalRun(t, evalAppliedCh, tick, rule.GetKey())
+	})
+
+	t.Run("the rule is stopped at the end of its activation window", func(t *testing.T) {
+		tick := advanceClock(t, mockedClock)
+		assertEvalRun(t, evalAppliedCh, tick)
+		assertStopRun(t, stopAppliedCh, rule.GetKey())
+	})
+}
+
 func assertEvalRun(t *testing.T, ch <-chan evalAppliedInfo, tick time.Time, keys ...models.AlertRuleKey) {
 	timeout := time.After(time.Second)
 
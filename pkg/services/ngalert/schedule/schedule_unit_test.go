@@ -9,7 +9,9 @@ import (
 	"time"
 
 	"github.com/benbjohnson/clock"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
+	gfmodels "github.com/grafana/grafana/pkg/models"
 	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
@@ -18,7 +20,10 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/opentracing/opentracing-go"
+	"github.com/opentracing/opentracing-go/mocktracer"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/require"
 )
@@ -224,12 +229,88 @@ func TestSendingToExternalAlertmanager_WithMultipleOrgs(t *testing.T) {
 	}, 10*time.Second, 200*time.Millisecond)
 }
 
+func TestSendingToInternalAlertmanagerOnly(t *testing.T) {
+	fakeAM := NewFakeExternalAlertmanager(t)
+	defer fakeAM.Close()
+	fakeRuleStore := newFakeRuleStore(t)
+	fakeInstanceStore := &fakeInstanceStore{}
+	fakeAdminConfigStore := newFakeAdminConfigStore(t)
+
+	adminConfig := &models.AdminConfiguration{OrgID: 1, Alertmanagers: []string{fakeAM.server.URL}, SendAlertsTo: models.InternalAlertmanager}
+	require.NoError(t, fakeAdminConfigStore.UpdateAdminConfiguration(store.UpdateAdminConfigurationCmd{AdminConfiguration: adminConfig}))
+
+	sched, _ := setupScheduler(t, fakeRuleStore, fakeInstanceStore, fakeAdminConfigStore)
+
+	// No sender is started for an organization only sending alerts to its internal Alertmanager.
+	require.NoError(t, sched.SyncAndApplyConfigFromDatabase())
+	sched.sendersMtx.Lock()
+	require.Equal(t, 0, len(sched.senders))
+	require.Equal(t, models.InternalAlertmanager, sched.sendAlertsTo[1])
+	sched.sendersMtx.Unlock()
+
+	// Once it sends them to all the Alertmanagers, the sender is started.
+	adminConfig = &models.AdminConfiguration{OrgID: 1, Alertmanagers: []string{fakeAM.server.URL}, SendAlertsTo: models.AllAlertmanagers}
+	require.NoError(t, fakeAdminConfigStore.UpdateAdminConfiguration(store.UpdateAdminConfigurationCmd{AdminConfiguration: adminConfig}))
+	require.NoError(t, sched.SyncAndApplyConfigFromDatabase())
+	sched.sendersMtx.Lock()
+	require.Equal(t, 1, len(sched.senders))
+	require.Equal(t, models.AllAlertmanagers, sched.sendAlertsTo[1])
+	sched.sendersMtx.Unlock()
+
+	sched.sendersMtx.Lock()
+	for _, s := range sched.senders {
+		s.Stop()
+	}
+	sched.sendersMtx.Unlock()
+}
+
+func TestSendingToDatasourceAlertmanager(t *testing.T) {
+	fakeAM := NewFakeExternalAlertmanager(t)
+	defer fakeAM.Close()
+	fakeRuleStore := newFakeRuleStore(t)
+	fakeInstanceStore := &fakeInstanceStore{}
+	fakeAdminConfigStore := newFakeAdminConfigStore(t)
+
+	fakeAdminConfigStore.datasources = []*gfmodels.DataSource{
+		{
+			OrgId:    1,
+			Name:     "external",
+			Type:     gfmodels.DS_ALERTMANAGER,
+			Url:      fakeAM.server.URL,
+			JsonData: simplejson.NewFromAny(map[string]interface{}{"implementation": "prometheus", "handleGrafanaManagedAlerts": true}),
+		},
+		{
+			OrgId:    1,
+			Name:     "not handling Grafana managed alerts",
+			Type:     gfmodels.DS_ALERTMANAGER,
+			Url:      "http://localhost:9093",
+			JsonData: simplejson.NewFromAny(map[string]interface{}{"implementation": "prometheus"}),
+		},
+	}
+	sched, _ := setupScheduler(t, fakeRuleStore, fakeInstanceStore, fakeAdminConfigStore)
+
+	// The organization has no admin configuration, the Alertmanager is discovered from its datasource.
+	require.NoError(t, sched.SyncAndApplyConfigFromDatabase())
+	require.Eventually(t, func() bool {
+		return len(sched.AlertmanagersFor(1)) == 1 && len(sched.DroppedAlertmanagersFor(1)) == 0
+	}, 10*time.Second, 200*time.Millisecond)
+
+	// When the datasource no longer handles Grafana managed alerts, the sender is stopped.
+	fakeAdminConfigStore.datasources[0].JsonData.Set("handleGrafanaManagedAlerts", false)
+	require.NoError(t, sched.SyncAndApplyConfigFromDatabase())
+	sched.sendersMtx.Lock()
+	require.Equal(t, 0, len(sched.senders))
+	sched.sendersMtx.Unlock()
+}
+
 func setupScheduler(t *testing.T, rs store.RuleStore, is store.InstanceStore, acs store.AdminConfigurationStore) (*schedule, *clock.Mock) {
 	t.Helper()
 
 	mockedClock := clock.NewMock()
 	logger := log.New("ngalert schedule test")
 	nilMetrics := metrics.NewMetrics(nil)
+	moa, err := notifier.NewMultiOrgAlertmanager(&setting.Cfg{}, &notifier.FakeConfigStore{}, &notifier.FakeOrgStore{}, metrics.NewMetrics(prometheus.NewRegistry()))
+	require.NoError(t, err)
 	schedCfg := SchedulerCfg{
 		C:                       mockedClock,
 		BaseInterval:            time.Second,
@@ -238,12 +319,12 @@ func setupScheduler(t *testing.T, rs store.RuleStore, is store.InstanceStore, ac
 		RuleStore:               rs,
 		InstanceStore:           is,
 		AdminConfigStore:        acs,
-		MultiOrgNotifier:        notifier.NewMultiOrgAlertmanager(&setting.Cfg{}, &notifier.FakeConfigStore{}, &notifier.FakeOrgStore{}),
+		MultiOrgNotifier:        moa,
 		Logger:                  logger,
 		Metrics:                 metrics.NewMetrics(prometheus.NewRegistry()),
 		AdminConfigPollInterval: 10 * time.Minute, // do not poll in unit tests.
 	}
-	st := state.NewManager(schedCfg.Logger, nilMetrics, rs, is)
+	st := state.NewManager(schedCfg.Logger, nilMetrics, rs, is, nil, nil, nil)
 	return NewScheduler(schedCfg, nil, "http://localhost", st), mockedClock
 }
 
@@ -302,3 +383,39 @@ func CreateTestAlertRule(t *testing.T, dbstore *fakeRuleStore, intervalSeconds i
 	t.Logf("alert definition: %v with interval: %d created", rule.GetKey(), rule.IntervalSeconds)
 	return rule
 }
+
+func TestStartEvaluationSpan(t *testing.T) {
+	tracer := mocktracer.New()
+	tick := tracer.StartSpan("alerting.scheduler.tick")
+	opentracing.SetGlobalTracer(tracer)
+	t.Cleanup(func() { opentracing.SetGlobalTracer(opentracing.NoopTracer{}) })
+
+	now := time.Now()
+	span, ctx := startEvaluationSpan(context.Background(), models.AlertRuleKey{OrgID: 1, UID: "rule"}, &evalContext{now: now, tick: tick.Context()}, 2)
+	require.Equal(t, span, opentracing.SpanFromContext(ctx))
+	span.Finish()
+
+	finished := tracer.FinishedSpans()
+	require.Len(t, finished, 1)
+	require.Equal(t, "alerting.rule.evaluation", finished[0].OperationName)
+	require.Equal(t, "rule", finished[0].Tag("rule_uid"))
+	require.Equal(t, int64(1), finished[0].Tag("org_id"))
+	require.Equal(t, int64(2), finished[0].Tag("attempt"))
+	require.Equal(t, tick.Context().(mocktracer.MockSpanContext).SpanID, finished[0].ParentID, "the evaluation follows from the tick")
+}
+
+func TestObserveRuleEvaluation(t *testing.T) {
+	sch, _ := setupScheduler(t, newFakeRuleStore(t), &fakeInstanceStore{}, newFakeAdminConfigStore(t))
+	rule := &models.AlertRule{OrgID: 1, UID: "rule", Labels: map[string]string{models.RuleMetricsLabel: "true"}}
+	results := eval.Results{{State: eval.Alerting}, {State: eval.Alerting}, {State: eval.Normal}}
+
+	require.True(t, sch.observeRuleEvaluation(rule, time.Second, results, nil))
+	require.True(t, sch.observeRuleEvaluation(rule, time.Second, nil, fmt.Errorf("failed")))
+	require.Equal(t, 2.0, testutil.ToFloat64(sch.metrics.RuleEvalResults.WithLabelValues("1", "rule", "alerting")))
+	require.Equal(t, 1.0, testutil.ToFloat64(sch.metrics.RuleEvalResults.WithLabelValues("1", "rule", "normal")))
+	require.Equal(t, 1.0, testutil.ToFloat64(sch.metrics.RuleEvalResults.WithLabelValues("1", "rule", "error")))
+
+	rule.Labels = nil
+	require.False(t, sch.observeRuleEvaluation(rule, time.Second, results, nil))
+	require.Equal(t, 0, testutil.CollectAndCount(sch.metrics.RuleEvalResults), "the metrics of the rule that stopped opting in are deleted")
+}
@@ -5,6 +5,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"sort"
 	"sync"
 	"testing"
 	"time"
@@ -31,7 +32,8 @@ type fakeRuleStore struct {
 	rules map[int64]map[string]map[string][]*models.AlertRule
 }
 
-func (f *fakeRuleStore) DeleteAlertRuleByUID(_ int64, _ string) error { return nil }
+func (f *fakeRuleStore) DeleteAlertRuleByUID(_ int64, _ string) error     { return nil }
+func (f *fakeRuleStore) DeleteAlertRulesByUID(_ int64, _ ...string) error { return nil }
 func (f *fakeRuleStore) DeleteNamespaceAlertRules(_ int64, _ string) ([]string, error) {
 	return []string{}, nil
 }
@@ -62,6 +64,11 @@ func (f *fakeRuleStore) GetAlertRuleByUID(q *models.GetAlertRuleByUIDQuery) erro
 	return nil
 }
 
+func (f *fakeRuleStore) GetAlertRuleVersions(_ *models.ListAlertRuleVersionsQuery) error { return nil }
+func (f *fakeRuleStore) GetAlertRuleVersion(_ *models.GetAlertRuleVersionQuery) error {
+	return models.ErrAlertRuleVersionNotFound
+}
+
 // For now, we're not implementing namespace filtering.
 func (f *fakeRuleStore) GetAlertRulesForScheduling(q *models.ListAlertRulesQuery) error {
 	f.mtx.Lock()
@@ -69,15 +76,62 @@ func (f *fakeRuleStore) GetAlertRulesForScheduling(q *models.ListAlertRulesQuery
 
 	for _, rg := range f.rules {
 		for _, n := range rg {
-			for _, r := range n {
-				q.Result = append(q.Result, r...)
+			for _, rules := range n {
+				for _, r := range rules {
+					if !r.IsPaused {
+						q.Result = append(q.Result, r)
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+func (f *fakeRuleStore) GetAlertRulesByIDs(q *models.ListAlertRulesByIDsQuery) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	ids := make(map[int64]struct{}, len(q.IDs))
+	for _, id := range q.IDs {
+		ids[id] = struct{}{}
+	}
+	for _, rg := range f.rules {
+		for _, n := range rg {
+			for _, rules := range n {
+				for _, r := range rules {
+					if _, ok := ids[r.ID]; ok {
+						q.Result = append(q.Result, r)
+					}
+				}
 			}
 		}
 	}
 
 	return nil
 }
-func (f *fakeRuleStore) GetOrgAlertRules(_ *models.ListAlertRulesQuery) error { return nil }
+func (f *fakeRuleStore) GetAlertRulesWatermark(q *models.GetAlertRulesWatermarkQuery) error {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+
+	for _, rg := range f.rules {
+		for _, n := range rg {
+			for _, rules := range n {
+				for _, r := range rules {
+					q.Result.Count++
+					q.Result.VersionSum += r.Version
+					if r.ID > q.Result.MaxID {
+						q.Result.MaxID = r.ID
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
+func (f *fakeRuleStore) GetOrgAlertRules(_ *models.ListAlertRulesQuery) error   { return nil }
+func (f *fakeRuleStore) SearchAlertRules(_ *models.SearchAlertRulesQuery) error { return nil }
 func (f *fakeRuleStore) GetNamespaceAlertRules(_ *models.ListNamespaceAlertRulesQuery) error {
 	return nil
 }
@@ -115,8 +169,16 @@ func (f *fakeRuleStore) GetNamespaces(_ int64, _ *models2.SignedInUser) (map[str
 func (f *fakeRuleStore) GetNamespaceByTitle(_ string, _ int64, _ *models2.SignedInUser, _ bool) (*models2.Folder, error) {
 	return nil, nil
 }
+func (f *fakeRuleStore) GetNamespaceByUID(_ string, _ int64, _ *models2.SignedInUser, _ bool) (*models2.Folder, error) {
+	return nil, nil
+}
+func (f *fakeRuleStore) CreateNamespace(_, _ string, _ int64, _ *models2.SignedInUser) (*models2.Folder, error) {
+	return nil, nil
+}
 func (f *fakeRuleStore) GetOrgRuleGroups(_ *models.ListOrgRuleGroupsQuery) error { return nil }
+func (f *fakeRuleStore) CountAlertRules() ([]*models.AlertRuleCount, error)      { return nil, nil }
 func (f *fakeRuleStore) UpsertAlertRules(_ []store.UpsertRule) error             { return nil }
+func (f *fakeRuleStore) MoveAlertRules(_ store.MoveAlertRulesCmd) error          { return nil }
 func (f *fakeRuleStore) UpdateRuleGroup(cmd store.UpdateRuleGroupCmd) error {
 	f.mtx.Lock()
 	defer f.mtx.Unlock()
@@ -180,6 +242,15 @@ func (f *fakeRuleStore) UpdateRuleGroup(cmd store.UpdateRuleGroupCmd) error {
 	return nil
 }
 
+func (f *fakeRuleStore) UpdateRuleGroups(cmds []store.UpdateRuleGroupCmd) error {
+	for _, cmd := range cmds {
+		if err := f.UpdateRuleGroup(cmd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type fakeInstanceStore struct{}
 
 func (f *fakeInstanceStore) GetAlertInstance(_ *models.GetAlertInstanceQuery) error     { return nil }
@@ -194,8 +265,9 @@ func newFakeAdminConfigStore(t *testing.T) *fakeAdminConfigStore {
 }
 
 type fakeAdminConfigStore struct {
-	mtx     sync.Mutex
-	configs map[int64]*models.AdminConfiguration
+	mtx         sync.Mutex
+	configs     map[int64]*models.AdminConfiguration
+	datasources []*models2.DataSource
 }
 
 func (f *fakeAdminConfigStore) GetAdminConfiguration(orgID int64) (*models.AdminConfiguration, error) {
@@ -229,6 +301,12 @@ func (f *fakeAdminConfigStore) UpdateAdminConfiguration(cmd store.UpdateAdminCon
 	return nil
 }
 
+func (f *fakeAdminConfigStore) GetAlertmanagerDatasources() ([]*models2.DataSource, error) {
+	f.mtx.Lock()
+	defer f.mtx.Unlock()
+	return f.datasources, nil
+}
+
 type FakeExternalAlertmanager struct {
 	t      *testing.T
 	mtx    sync.Mutex
@@ -252,6 +330,8 @@ func (am *FakeExternalAlertmanager) URL() string {
 	return am.server.URL
 }
 
+// AlertNamesCompare returns whether the names of the alerts received are the expected ones, in any order as
+// the alerts of different organizations are sent concurrently.
 func (am *FakeExternalAlertmanager) AlertNamesCompare(expected []string) bool {
 	n := []string{}
 	alerts := am.Alerts()
@@ -268,7 +348,10 @@ func (am *FakeExternalAlertmanager) AlertNamesCompare(expected []string) bool {
 		}
 	}
 
-	return assert.ObjectsAreEqual(expected, n)
+	e := append([]string{}, expected...)
+	sort.Strings(e)
+	sort.Strings(n)
+	return assert.ObjectsAreEqual(e, n)
 }
 
 func (am *FakeExternalAlertmanager) AlertsCount() int {
@@ -286,6 +369,16 @@ func (am *FakeExternalAlertmanager) Alerts() amv2.PostableAlerts {
 
 func (am *FakeExternalAlertmanager) Handler() func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
+		// Answer the health checks of the sender.
+		switch r.URL.Path {
+		case "/-/ready":
+			return
+		case "/api/v2/status":
+			_, err := w.Write([]byte(`{"config":{"original":""},"versionInfo":{"version":"0.22.2"}}`))
+			require.NoError(am.t, err)
+			return
+		}
+
 		b, err := ioutil.ReadAll(r.Body)
 		require.NoError(am.t, err)
 
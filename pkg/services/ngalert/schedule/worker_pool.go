@@ -0,0 +1,287 @@
+package schedule
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"runtime/pprof"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+const (
+	// defaultPoolSize is the number of workers of a scheduler without a configured pool size.
+	defaultPoolSize = 100
+
+	// the reasons of the shed evaluations
+	shedQueueFull = "queue_full"
+	shedLate      = "late"
+)
+
+// retryDelay is how long an evaluation waits in the queue again when the previous evaluation of its rule is still
+// running or its organization is at its limit of concurrent evaluations.
+var retryDelay = 100 * time.Millisecond
+
+// evaluationTask is an evaluation of an alert rule waiting for a worker of the scheduler.
+type evaluationTask struct {
+	key     models.AlertRuleKey
+	info    *alertRuleInfo
+	evalCtx *evalContext
+	// due is when the evaluation is due, and deadline when the next evaluation of the rule is: the evaluation is shed
+	// if it's still waiting then. There is no deadline if zero.
+	due      time.Time
+	deadline time.Time
+	// scheduled is when the evaluation was first due, due changes when the evaluation goes back to the queue.
+	scheduled time.Time
+	// throttled is whether the evaluation already waited for its organization.
+	throttled bool
+	// group is the rule group of the rule, whose rules are evaluated groupConcurrency at a time at most.
+	group            ruleGroupKey
+	groupConcurrency int
+	// groupThrottled is whether the evaluation already waited for its rule group.
+	groupThrottled bool
+}
+
+// taskHeap implements heap.Interface, the evaluations due first are first.
+type taskHeap []*evaluationTask
+
+func (h taskHeap) Len() int            { return len(h) }
+func (h taskHeap) Less(i, j int) bool  { return h[i].due.Before(h[j].due) }
+func (h taskHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *taskHeap) Push(x interface{}) { *h = append(*h, x.(*evaluationTask)) }
+func (h *taskHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	t := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return t
+}
+
+// evaluationQueue is the priority queue of the evaluations waiting for a worker of the scheduler, by due time. It
+// holds at most maxSize evaluations, there is no limit if 0.
+type evaluationQueue struct {
+	mtx     sync.Mutex
+	tasks   taskHeap
+	maxSize int
+	depth   prometheus.Gauge
+	// ready wakes up a waiting worker when an evaluation may be due.
+	ready chan struct{}
+}
+
+func newEvaluationQueue(maxSize int, depth prometheus.Gauge) *evaluationQueue {
+	return &evaluationQueue{maxSize: maxSize, depth: depth, ready: make(chan struct{}, 1)}
+}
+
+// push adds an evaluation to the queue, it returns false if the queue is full.
+func (q *evaluationQueue) push(t *evaluationTask) bool {
+	q.mtx.Lock()
+	if q.maxSize > 0 && len(q.tasks) >= q.maxSize {
+		q.mtx.Unlock()
+		return false
+	}
+	heap.Push(&q.tasks, t)
+	q.observe()
+	q.mtx.Unlock()
+
+	q.wakeUp()
+	return true
+}
+
+// pop removes the first evaluation of the queue if it's due at now. Otherwise it returns how long until the first
+// evaluation is due, 0 if the queue is empty.
+func (q *evaluationQueue) pop(now time.Time) (*evaluationTask, time.Duration) {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+
+	if len(q.tasks) == 0 {
+		return nil, 0
+	}
+	if wait := q.tasks[0].due.Sub(now); wait > 0 {
+		return nil, wait
+	}
+	t := heap.Pop(&q.tasks).(*evaluationTask)
+	q.observe()
+	// let another worker take the next evaluation if it's due too
+	if len(q.tasks) > 0 && !q.tasks[0].due.After(now) {
+		q.wakeUp()
+	}
+	return t, 0
+}
+
+func (q *evaluationQueue) len() int {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	return len(q.tasks)
+}
+
+func (q *evaluationQueue) wakeUp() {
+	select {
+	case q.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (q *evaluationQueue) observe() {
+	if q.depth != nil {
+		q.depth.Set(float64(len(q.tasks)))
+	}
+}
+
+// evaluationLags are the lags of the latest evaluations of each organization, between when they were due and when
+// they started.
+type evaluationLags struct {
+	mtx  sync.Mutex
+	lags map[int64]time.Duration
+}
+
+func newEvaluationLags() *evaluationLags {
+	return &evaluationLags{lags: map[int64]time.Duration{}}
+}
+
+func (l *evaluationLags) observe(orgID int64, lag time.Duration) {
+	if lag < 0 {
+		lag = 0
+	}
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.lags[orgID] = lag
+}
+
+func (l *evaluationLags) get(orgID int64) time.Duration {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	return l.lags[orgID]
+}
+
+// enqueue adds an evaluation to the queue of the workers, it's shed if the queue is full.
+func (sch *schedule) enqueue(t *evaluationTask) {
+	if sch.queue.push(t) {
+		return
+	}
+	sch.metrics.EvalShed.WithLabelValues(fmt.Sprint(t.key.OrgID), shedQueueFull).Inc()
+	sch.log.Warn("alert rule evaluation shed: the queue of the scheduler is full", "key", t.key, "now", t.evalCtx.now, "size", sch.queue.maxSize)
+}
+
+// evaluationWorker evaluates the rules of the queue as they become due, until the context is done.
+func (sch *schedule) evaluationWorker(ctx context.Context) error {
+	for {
+		t, wait := sch.queue.pop(timeNow())
+		if t != nil {
+			sch.runTask(ctx, t)
+			continue
+		}
+
+		// wait for a new evaluation, or for the first one to be due
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			timeout = timer.C
+		}
+		select {
+		case <-sch.queue.ready:
+		case <-timeout:
+		case <-ctx.Done():
+			return nil
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// runTask evaluates a rule, with up to maxAttempts attempts. The evaluation is shed once the next evaluation of the
+// rule is due, and it goes back to the queue while the previous evaluation of the rule is running or the rule group
+// or the organization of the rule is at its limit of concurrent evaluations.
+func (sch *schedule) runTask(ctx context.Context, t *evaluationTask) {
+	if !t.deadline.IsZero() && !timeNow().Before(t.deadline) {
+		sch.metrics.EvalShed.WithLabelValues(fmt.Sprint(t.key.OrgID), shedLate).Inc()
+		sch.log.Warn("alert rule evaluation shed: the next evaluation of the rule is due", "key", t.key, "now", t.evalCtx.now)
+		return
+	}
+
+	started, stopped := t.info.start()
+	if stopped {
+		return
+	}
+	if !started {
+		t.due = timeNow().Add(retryDelay)
+		sch.enqueue(t)
+		return
+	}
+
+	releaseGroup, ok := sch.groupEvalSlots.tryAcquire(t.group, t.groupConcurrency)
+	if !ok {
+		if t.info.finish() {
+			sch.forgetRule(t.key)
+			return
+		}
+		if !t.groupThrottled {
+			t.groupThrottled = true
+			sch.metrics.EvalGroupThrottled.WithLabelValues(fmt.Sprint(t.key.OrgID)).Inc()
+		}
+		t.due = timeNow().Add(retryDelay)
+		sch.enqueue(t)
+		return
+	}
+
+	maxConcurrent := sch.orgLimits(t.key.OrgID).MaxConcurrentEvaluations
+	release, ok := sch.evalSlots.tryAcquire(t.key.OrgID, maxConcurrent)
+	if !ok {
+		releaseGroup()
+		if t.info.finish() {
+			sch.forgetRule(t.key)
+			return
+		}
+		if !t.throttled {
+			t.throttled = true
+			sch.metrics.EvalThrottled.WithLabelValues(fmt.Sprint(t.key.OrgID)).Inc()
+		}
+		t.due = timeNow().Add(retryDelay)
+		sch.enqueue(t)
+		return
+	}
+
+	sch.evalLags.observe(t.key.OrgID, timeNow().Sub(t.scheduled))
+	sch.metrics.SchedulerBusyWorkers.Inc()
+	defer func() {
+		release()
+		releaseGroup()
+		sch.metrics.SchedulerBusyWorkers.Dec()
+		if t.info.finish() {
+			sch.forgetRule(t.key)
+		}
+		sch.evalApplied(t.key, t.evalCtx.now)
+	}()
+
+	// the samples of the evaluation in the CPU profiles, and its goroutines, are labelled with the rule
+	pprof.Do(ctx, evaluationLabels(t.key), func(ctx context.Context) {
+		for attempt := int64(0); attempt < sch.maxAttempts; attempt++ {
+			err := sch.evaluateRule(ctx, t.key, t.info, t.evalCtx, attempt)
+			if err == nil {
+				break
+			}
+		}
+	})
+}
+
+// evaluationLabels are the pprof labels of the evaluations of a rule, so that the profiles captured from a busy
+// instance can be attributed to the rules.
+func evaluationLabels(key models.AlertRuleKey) pprof.LabelSet {
+	return pprof.Labels("org_id", strconv.FormatInt(key.OrgID, 10), "rule_uid", key.UID)
+}
+
+// forgetRule cleans up after a deleted rule, once it's not evaluated anymore.
+func (sch *schedule) forgetRule(key models.AlertRuleKey) {
+	if sch.ruleMetrics != nil {
+		sch.ruleMetrics.Forget(key.OrgID, key.UID)
+	}
+	sch.stopApplied(key)
+	sch.log.Debug("alert rule stopped", "key", key)
+}
@@ -0,0 +1,148 @@
+package schedule
+
+import (
+	"context"
+	"runtime/pprof"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestEvaluationQueue(t *testing.T) {
+	depth := prometheus.NewGauge(prometheus.GaugeOpts{Name: "depth"})
+	q := newEvaluationQueue(3, depth)
+	now := time.Now()
+
+	for _, offset := range []time.Duration{2 * time.Second, 0, time.Second} {
+		require.True(t, q.push(&evaluationTask{key: models.AlertRuleKey{UID: offset.String()}, due: now.Add(offset)}))
+	}
+	require.False(t, q.push(&evaluationTask{due: now}), "the queue is full")
+	require.Equal(t, 3.0, testutil.ToFloat64(depth))
+
+	task, wait := q.pop(now)
+	require.Equal(t, "0s", task.key.UID, "the evaluation due first is first")
+	require.Zero(t, wait)
+
+	task, wait = q.pop(now)
+	require.Nil(t, task, "the next evaluation is not due")
+	require.Equal(t, time.Second, wait)
+
+	task, _ = q.pop(now.Add(3 * time.Second))
+	require.Equal(t, "1s", task.key.UID)
+	task, _ = q.pop(now.Add(3 * time.Second))
+	require.Equal(t, "2s", task.key.UID)
+
+	task, wait = q.pop(now.Add(3 * time.Second))
+	require.Nil(t, task)
+	require.Zero(t, wait, "the queue is empty")
+	require.Equal(t, 0.0, testutil.ToFloat64(depth))
+
+	t.Run("no limit", func(t *testing.T) {
+		q := newEvaluationQueue(0, nil)
+		for i := 0; i < 10; i++ {
+			require.True(t, q.push(&evaluationTask{due: now}))
+		}
+		require.Equal(t, 10, q.len())
+	})
+}
+
+func TestRunTask(t *testing.T) {
+	sch, _ := setupScheduler(t, newFakeRuleStore(t), &fakeInstanceStore{}, newFakeAdminConfigStore(t))
+	var evaluated, stopped []models.AlertRuleKey
+	sch.evalAppliedFunc = func(key models.AlertRuleKey, _ time.Time) { evaluated = append(evaluated, key) }
+	sch.stopAppliedFunc = func(key models.AlertRuleKey) { stopped = append(stopped, key) }
+	key := models.AlertRuleKey{OrgID: 1, UID: "rule"}
+	newTask := func(info *alertRuleInfo) *evaluationTask {
+		return &evaluationTask{key: key, info: info, evalCtx: &evalContext{now: time.Now()}, due: time.Now(), deadline: time.Now().Add(time.Minute)}
+	}
+
+	t.Run("an evaluation of a rule still waiting when the next one is due is shed", func(t *testing.T) {
+		task := newTask(&alertRuleInfo{})
+		task.deadline = time.Now().Add(-time.Second)
+		sch.runTask(context.Background(), task)
+		require.Empty(t, evaluated)
+		require.Equal(t, 1.0, testutil.ToFloat64(sch.metrics.EvalShed.WithLabelValues("1", shedLate)))
+	})
+
+	t.Run("an evaluation of a rule already evaluated goes back to the queue", func(t *testing.T) {
+		info := &alertRuleInfo{}
+		started, _ := info.start()
+		require.True(t, started)
+
+		sch.runTask(context.Background(), newTask(info))
+		require.Empty(t, evaluated)
+		require.Equal(t, 1, sch.queue.len())
+		task, _ := sch.queue.pop(time.Now().Add(time.Second))
+		require.Equal(t, key, task.key)
+
+		// the rule is forgotten once the running evaluation finishes
+		require.True(t, info.stop())
+		require.Empty(t, stopped)
+		require.True(t, info.finish())
+	})
+
+	t.Run("an evaluation of a rule whose group is at its limit goes back to the queue", func(t *testing.T) {
+		group := ruleGroupKey{orgID: 1, namespaceUID: "folder", group: "group"}
+		release, ok := sch.groupEvalSlots.tryAcquire(group, 1)
+		require.True(t, ok)
+
+		task := newTask(&alertRuleInfo{})
+		task.group, task.groupConcurrency = group, 1
+		sch.runTask(context.Background(), task)
+		require.Empty(t, evaluated)
+		require.Equal(t, 1.0, testutil.ToFloat64(sch.metrics.EvalGroupThrottled.WithLabelValues("1")))
+		task, _ = sch.queue.pop(time.Now().Add(time.Second))
+		require.Equal(t, key, task.key)
+		require.True(t, task.groupThrottled)
+
+		// the other groups are not throttled
+		_, ok = sch.groupEvalSlots.tryAcquire(ruleGroupKey{orgID: 1, namespaceUID: "folder", group: "other"}, 1)
+		require.True(t, ok)
+		release()
+		_, ok = sch.groupEvalSlots.tryAcquire(group, 1)
+		require.True(t, ok)
+	})
+
+	t.Run("an evaluation of a deleted rule is dropped", func(t *testing.T) {
+		info := &alertRuleInfo{}
+		require.False(t, info.stop())
+		sch.runTask(context.Background(), newTask(info))
+		require.Empty(t, evaluated)
+		require.Zero(t, sch.queue.len())
+	})
+
+	t.Run("evaluations over the size of the queue are shed", func(t *testing.T) {
+		sch.queue = newEvaluationQueue(1, nil)
+		sch.enqueue(newTask(&alertRuleInfo{}))
+		sch.enqueue(newTask(&alertRuleInfo{}))
+		require.Equal(t, 1, sch.queue.len())
+		require.Equal(t, 1.0, testutil.ToFloat64(sch.metrics.EvalShed.WithLabelValues("1", shedQueueFull)))
+	})
+}
+
+func TestEvaluationLags(t *testing.T) {
+	lags := newEvaluationLags()
+	require.Zero(t, lags.get(1))
+
+	lags.observe(1, 2*time.Second)
+	lags.observe(2, -time.Second)
+	require.Equal(t, 2*time.Second, lags.get(1))
+	require.Zero(t, lags.get(2), "an evaluation started early has no lag")
+
+	lags.observe(1, time.Second)
+	require.Equal(t, time.Second, lags.get(1), "only the latest evaluation counts")
+}
+
+func TestEvaluationLabels(t *testing.T) {
+	pprof.Do(context.Background(), evaluationLabels(models.AlertRuleKey{OrgID: 2, UID: "rule"}), func(ctx context.Context) {
+		orgID, _ := pprof.Label(ctx, "org_id")
+		require.Equal(t, "2", orgID)
+		uid, _ := pprof.Label(ctx, "rule_uid")
+		require.Equal(t, "rule", uid)
+	})
+}
@@ -0,0 +1,133 @@
+package screenshot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/imguploader"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	screenshotWidth  = 1000
+	screenshotHeight = 500
+)
+
+// ErrScreenshotsUnavailable is returned when no image renderer is available.
+var ErrScreenshotsUnavailable = errors.New("screenshots are unavailable, no image renderer found")
+
+// Screenshot is the image of a panel at the time an alert fired.
+type Screenshot struct {
+	// Path is the location of the image on disk.
+	Path string
+	// URL is the public URL of the image in the external image storage, if any.
+	URL string
+}
+
+// ScreenshotOptions identify the panel to take a screenshot of.
+type ScreenshotOptions struct {
+	OrgID        int64
+	DashboardUID string
+	PanelID      int64
+}
+
+func (o ScreenshotOptions) cacheKey() string {
+	return fmt.Sprintf("%d/%s/%d", o.OrgID, o.DashboardUID, o.PanelID)
+}
+
+// ScreenshotService takes screenshots of the panels linked to alert rules.
+type ScreenshotService interface {
+	Take(ctx context.Context, opts ScreenshotOptions) (*Screenshot, error)
+}
+
+type cacheEntry struct {
+	screenshot *Screenshot
+	expiresAt  time.Time
+}
+
+// RenderScreenshotService takes screenshots with the image rendering service and uploads them
+// to the external image storage. Screenshots are cached so alert instances of the same rule
+// firing together share a single screenshot.
+type RenderScreenshotService struct {
+	log      log.Logger
+	renderer rendering.Service
+	uploader imguploader.ImageUploader
+	timeout  time.Duration
+	cacheTTL time.Duration
+
+	mtx   sync.Mutex
+	cache map[string]cacheEntry
+}
+
+func NewRenderScreenshotService(cfg *setting.Cfg, renderer rendering.Service, uploader imguploader.ImageUploader) *RenderScreenshotService {
+	return &RenderScreenshotService{
+		log:      log.New("ngalert.screenshot"),
+		renderer: renderer,
+		uploader: uploader,
+		timeout:  cfg.ScreenshotTimeout,
+		cacheTTL: cfg.ScreenshotCacheTTL,
+		cache:    map[string]cacheEntry{},
+	}
+}
+
+// Take returns the screenshot of the panel, from the cache if a screenshot was taken recently.
+func (s *RenderScreenshotService) Take(ctx context.Context, opts ScreenshotOptions) (*Screenshot, error) {
+	if !s.renderer.IsAvailable() {
+		return nil, ErrScreenshotsUnavailable
+	}
+
+	key := opts.cacheKey()
+	now := time.Now()
+	s.mtx.Lock()
+	entry, ok := s.cache[key]
+	if ok && now.Before(entry.expiresAt) {
+		s.mtx.Unlock()
+		return entry.screenshot, nil
+	}
+	s.mtx.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	start := time.Now()
+	result, err := s.renderer.Render(ctx, rendering.Opts{
+		Width:           screenshotWidth,
+		Height:          screenshotHeight,
+		Timeout:         s.timeout,
+		OrgID:           opts.OrgID,
+		OrgRole:         models.ROLE_ADMIN,
+		Path:            fmt.Sprintf("d-solo/%s/_?orgId=%d&panelId=%d", opts.DashboardUID, opts.OrgID, opts.PanelID),
+		ConcurrentLimit: setting.AlertingRenderLimit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render panel: %w", err)
+	}
+	s.log.Debug("rendered panel", "dashboard", opts.DashboardUID, "panel", opts.PanelID, "path", result.FilePath, "took", time.Since(start))
+
+	screenshot := &Screenshot{Path: result.FilePath}
+	if s.uploader != nil {
+		url, err := s.uploader.Upload(ctx, result.FilePath)
+		if err != nil {
+			// The image can still be embedded in emails.
+			s.log.Warn("failed to upload screenshot to the external image storage", "path", result.FilePath, "err", err)
+		}
+		screenshot.URL = url
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.cache[key] = cacheEntry{screenshot: screenshot, expiresAt: now.Add(s.cacheTTL)}
+	// Drop the expired screenshots, the image files are removed by the cleanup service.
+	for k, e := range s.cache {
+		if now.After(e.expiresAt) {
+			delete(s.cache, k)
+		}
+	}
+	return screenshot, nil
+}
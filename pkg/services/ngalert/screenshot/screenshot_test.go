@@ -0,0 +1,63 @@
+package screenshot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeRenderer struct {
+	rendering.Service
+	available bool
+	renders   []rendering.Opts
+}
+
+func (r *fakeRenderer) IsAvailable() bool {
+	return r.available
+}
+
+func (r *fakeRenderer) Render(_ context.Context, opts rendering.Opts) (*rendering.RenderResult, error) {
+	r.renders = append(r.renders, opts)
+	return &rendering.RenderResult{FilePath: "/tmp/panel.png"}, nil
+}
+
+type fakeUploader struct{}
+
+func (fakeUploader) Upload(_ context.Context, path string) (string, error) {
+	return "https://images.example.com/panel.png", nil
+}
+
+func TestRenderScreenshotService(t *testing.T) {
+	cfg := &setting.Cfg{ScreenshotTimeout: time.Second, ScreenshotCacheTTL: time.Minute}
+	opts := ScreenshotOptions{OrgID: 1, DashboardUID: "dash", PanelID: 2}
+
+	t.Run("returns an error when no renderer is available", func(t *testing.T) {
+		s := NewRenderScreenshotService(cfg, &fakeRenderer{}, fakeUploader{})
+		_, err := s.Take(context.Background(), opts)
+		require.ErrorIs(t, err, ErrScreenshotsUnavailable)
+	})
+
+	t.Run("renders and uploads the panel once per cache period", func(t *testing.T) {
+		renderer := &fakeRenderer{available: true}
+		s := NewRenderScreenshotService(cfg, renderer, fakeUploader{})
+
+		screenshot, err := s.Take(context.Background(), opts)
+		require.NoError(t, err)
+		require.Equal(t, &Screenshot{Path: "/tmp/panel.png", URL: "https://images.example.com/panel.png"}, screenshot)
+		require.Len(t, renderer.renders, 1)
+		require.Equal(t, "d-solo/dash/_?orgId=1&panelId=2", renderer.renders[0].Path)
+
+		_, err = s.Take(context.Background(), opts)
+		require.NoError(t, err)
+		require.Len(t, renderer.renders, 1)
+
+		_, err = s.Take(context.Background(), ScreenshotOptions{OrgID: 1, DashboardUID: "dash", PanelID: 3})
+		require.NoError(t, err)
+		require.Len(t, renderer.renders, 2)
+	})
+}
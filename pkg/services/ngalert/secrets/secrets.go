@@ -0,0 +1,305 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/secretsmanager"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	VaultScheme             = "vault"
+	AWSSecretsManagerScheme = "aws-secretsmanager"
+)
+
+var (
+	// ErrProviderNotConfigured is returned when resolving a reference to a secret manager that isn't configured.
+	ErrProviderNotConfigured = errors.New("secret manager is not configured")
+	// ErrSecretKeyNotFound is returned when the key of a reference is not found in the secret.
+	ErrSecretKeyNotFound = errors.New("key not found in secret")
+	// ErrReferenceOutOfScope is returned for a reference to a secret outside of the path prefix of the organization.
+	ErrReferenceOutOfScope = errors.New("secret is outside of the path prefix of the organization")
+)
+
+// Reference points to a secret stored in an external secret manager, e.g. vault://secret/pagerduty#integrationKey.
+type Reference struct {
+	Scheme string
+	// Path identifies the secret in the secret manager.
+	Path string
+	// Key is the field of the secret to read. The whole secret is used if empty, this is only supported by
+	// secret managers storing secrets as plain strings.
+	Key string
+}
+
+func (r Reference) String() string {
+	s := r.Scheme + "://" + r.Path
+	if r.Key != "" {
+		s += "#" + r.Key
+	}
+	return s
+}
+
+// ParseReference parses a secret reference. It returns false if the value is not a reference.
+func ParseReference(v string) (Reference, bool) {
+	for _, scheme := range []string{VaultScheme, AWSSecretsManagerScheme} {
+		prefix := scheme + "://"
+		if !strings.HasPrefix(v, prefix) {
+			continue
+		}
+		path := strings.TrimPrefix(v, prefix)
+		key := ""
+		if i := strings.LastIndex(path, "#"); i >= 0 {
+			path, key = path[:i], path[i+1:]
+		}
+		if path == "" {
+			return Reference{}, false
+		}
+		return Reference{Scheme: scheme, Path: path, Key: key}, true
+	}
+	return Reference{}, false
+}
+
+// Provider reads secrets from a secret manager.
+type Provider interface {
+	Resolve(ctx context.Context, ref Reference) (string, error)
+}
+
+type cachedSecret struct {
+	value     string
+	expiresAt time.Time
+}
+
+// Resolver resolves secret references of an organization with the provider of their scheme and caches the
+// resolved secrets.
+type Resolver struct {
+	providers map[string]Provider
+	// prefixes are the prefixes of the paths the organization can reference by scheme, any path if empty.
+	prefixes map[string]string
+	ttl      time.Duration
+
+	mtx   sync.Mutex
+	cache map[string]cachedSecret
+}
+
+// NewResolver returns a resolver of the organization for the secret managers configured in the settings.
+func NewResolver(cfg *setting.Cfg, orgID int64) *Resolver {
+	providers := map[string]Provider{}
+	prefixes := map[string]string{}
+	if cfg.VaultAddress != "" {
+		providers[VaultScheme] = NewVaultProvider(cfg.VaultAddress, cfg.VaultToken)
+		prefixes[VaultScheme] = orgPathPrefix(cfg.VaultPathPrefix, orgID)
+	}
+	if cfg.AWSSecretsManagerRegion != "" {
+		providers[AWSSecretsManagerScheme] = NewAWSSecretsManagerProvider(cfg.AWSSecretsManagerRegion)
+		prefixes[AWSSecretsManagerScheme] = orgPathPrefix(cfg.AWSSecretsManagerPathPrefix, orgID)
+	}
+	return NewResolverWithProviders(providers, prefixes, cfg.SecretsCacheTTL)
+}
+
+// NewResolverWithProviders returns a resolver for the given providers and path prefixes, indexed by scheme.
+func NewResolverWithProviders(providers map[string]Provider, prefixes map[string]string, ttl time.Duration) *Resolver {
+	return &Resolver{
+		providers: providers,
+		prefixes:  prefixes,
+		ttl:       ttl,
+		cache:     map[string]cachedSecret{},
+	}
+}
+
+// orgPathPrefix returns the path prefix of the secrets of the organization, {org_id} being replaced with its ID.
+// The prefix ends with a slash so that an organization can't reference the secrets of the organizations whose ID
+// starts with its own.
+func orgPathPrefix(prefix string, orgID int64) string {
+	if prefix == "" {
+		return ""
+	}
+	prefix = strings.ReplaceAll(prefix, "{org_id}", strconv.FormatInt(orgID, 10))
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return prefix
+}
+
+// Configured returns an error if the secret manager of the reference is not configured, or if the secret is
+// outside of the path prefix of the organization.
+func (r *Resolver) Configured(ref Reference) error {
+	if _, ok := r.providers[ref.Scheme]; !ok {
+		return fmt.Errorf("%w: %s", ErrProviderNotConfigured, ref.Scheme)
+	}
+	return r.inScope(ref)
+}
+
+// inScope returns an error if the path of the reference is outside of the path prefix of the organization.
+func (r *Resolver) inScope(ref Reference) error {
+	prefix := r.prefixes[ref.Scheme]
+	if prefix == "" {
+		return nil
+	}
+	path := ref.Path
+	if ref.Scheme == AWSSecretsManagerScheme {
+		// The path is the name of the secret or its ARN, which ends with the name.
+		if i := strings.Index(path, ":secret:"); strings.HasPrefix(path, "arn:") && i >= 0 {
+			path = path[i+len(":secret:"):]
+		}
+	}
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "." || segment == ".." {
+			return fmt.Errorf("%w: %s", ErrReferenceOutOfScope, ref)
+		}
+	}
+	if !strings.HasPrefix(path, prefix) {
+		return fmt.Errorf("%w: %s is not in %s", ErrReferenceOutOfScope, ref, prefix)
+	}
+	return nil
+}
+
+// Resolve returns the secret of the reference, from the cache if it was resolved less than the TTL ago.
+func (r *Resolver) Resolve(ctx context.Context, ref Reference) (string, error) {
+	p, ok := r.providers[ref.Scheme]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrProviderNotConfigured, ref.Scheme)
+	}
+	// The references are checked again when resolved, the configuration might predate the prefixes.
+	if err := r.inScope(ref); err != nil {
+		return "", err
+	}
+
+	key := ref.String()
+	now := time.Now()
+	r.mtx.Lock()
+	cached, ok := r.cache[key]
+	r.mtx.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.value, nil
+	}
+
+	value, err := p.Resolve(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve secret %s: %w", key, err)
+	}
+
+	r.mtx.Lock()
+	r.cache[key] = cachedSecret{value: value, expiresAt: now.Add(r.ttl)}
+	r.mtx.Unlock()
+	return value, nil
+}
+
+// VaultProvider reads secrets from the KV version 2 secrets engine of HashiCorp Vault.
+// The path of a reference starts with the mount of the secrets engine, e.g. vault://secret/pagerduty#integrationKey.
+type VaultProvider struct {
+	address string
+	token   string
+	client  *http.Client
+}
+
+func NewVaultProvider(address, token string) *VaultProvider {
+	return &VaultProvider{
+		address: strings.TrimSuffix(address, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *VaultProvider) Resolve(ctx context.Context, ref Reference) (string, error) {
+	if ref.Key == "" {
+		return "", errors.New("vault references require a key")
+	}
+	parts := strings.SplitN(ref.Path, "/", 2)
+	if len(parts) != 2 {
+		return "", fmt.Errorf("vault reference path %q should start with the secrets engine mount", ref.Path)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/v1/%s/data/%s", p.address, parts[0], parts[1]), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code %d from vault", resp.StatusCode)
+	}
+
+	var secret struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return "", fmt.Errorf("failed to parse vault response: %w", err)
+	}
+	v, ok := secret.Data.Data[ref.Key]
+	if !ok {
+		return "", ErrSecretKeyNotFound
+	}
+	return fmt.Sprint(v), nil
+}
+
+// AWSSecretsManagerProvider reads secrets from AWS Secrets Manager. The path of a reference is the name or ARN of
+// the secret, its key is read from secrets stored as JSON objects.
+type AWSSecretsManagerProvider struct {
+	region string
+
+	once   sync.Once
+	client *secretsmanager.SecretsManager
+	err    error
+}
+
+func NewAWSSecretsManagerProvider(region string) *AWSSecretsManagerProvider {
+	return &AWSSecretsManagerProvider{region: region}
+}
+
+func (p *AWSSecretsManagerProvider) Resolve(ctx context.Context, ref Reference) (string, error) {
+	p.once.Do(func() {
+		sess, err := session.NewSession(&aws.Config{Region: aws.String(p.region)})
+		if err != nil {
+			p.err = err
+			return
+		}
+		p.client = secretsmanager.New(sess)
+	})
+	if p.err != nil {
+		return "", p.err
+	}
+
+	out, err := p.client.GetSecretValueWithContext(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(ref.Path)})
+	if err != nil {
+		return "", err
+	}
+	value := aws.StringValue(out.SecretString)
+	if ref.Key == "" {
+		return value, nil
+	}
+
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return "", fmt.Errorf("secret is not a JSON object: %w", err)
+	}
+	v, ok := fields[ref.Key]
+	if !ok {
+		return "", ErrSecretKeyNotFound
+	}
+	return fmt.Sprint(v), nil
+}
@@ -0,0 +1,114 @@
+package secrets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReference(t *testing.T) {
+	ref, ok := ParseReference("vault://secret/pagerduty#integrationKey")
+	require.True(t, ok)
+	require.Equal(t, Reference{Scheme: VaultScheme, Path: "secret/pagerduty", Key: "integrationKey"}, ref)
+
+	ref, ok = ParseReference("aws-secretsmanager://arn:aws:secretsmanager:us-east-1:123456789012:secret:slack")
+	require.True(t, ok)
+	require.Equal(t, Reference{Scheme: AWSSecretsManagerScheme, Path: "arn:aws:secretsmanager:us-east-1:123456789012:secret:slack"}, ref)
+
+	for _, v := range []string{"", "https://hooks.slack.com/services/abc", "vault://", "plain-token"} {
+		_, ok := ParseReference(v)
+		require.False(t, ok, v)
+	}
+}
+
+func TestVaultProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		require.Equal(t, "/v1/secret/data/pagerduty", r.URL.Path)
+		_, _ = w.Write([]byte(`{"data": {"data": {"integrationKey": "abc"}}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	p := NewVaultProvider(srv.URL, "token")
+	v, err := p.Resolve(context.Background(), Reference{Scheme: VaultScheme, Path: "secret/pagerduty", Key: "integrationKey"})
+	require.NoError(t, err)
+	require.Equal(t, "abc", v)
+
+	_, err = p.Resolve(context.Background(), Reference{Scheme: VaultScheme, Path: "secret/pagerduty", Key: "unknown"})
+	require.ErrorIs(t, err, ErrSecretKeyNotFound)
+
+	_, err = NewVaultProvider(srv.URL, "invalid").Resolve(context.Background(), Reference{Scheme: VaultScheme, Path: "secret/pagerduty", Key: "integrationKey"})
+	require.Error(t, err)
+}
+
+type fakeProvider struct {
+	calls int
+	value string
+}
+
+func (p *fakeProvider) Resolve(_ context.Context, _ Reference) (string, error) {
+	p.calls++
+	return p.value, nil
+}
+
+func TestResolver(t *testing.T) {
+	p := &fakeProvider{value: "abc"}
+	r := NewResolverWithProviders(map[string]Provider{VaultScheme: p}, nil, time.Minute)
+	ref := Reference{Scheme: VaultScheme, Path: "secret/pagerduty", Key: "integrationKey"}
+
+	for i := 0; i < 3; i++ {
+		v, err := r.Resolve(context.Background(), ref)
+		require.NoError(t, err)
+		require.Equal(t, "abc", v)
+	}
+	require.Equal(t, 1, p.calls, "the secret should be cached")
+
+	_, err := r.Resolve(context.Background(), Reference{Scheme: AWSSecretsManagerScheme, Path: "slack"})
+	require.ErrorIs(t, err, ErrProviderNotConfigured)
+	require.ErrorIs(t, r.Configured(Reference{Scheme: AWSSecretsManagerScheme, Path: "slack"}), ErrProviderNotConfigured)
+}
+
+func TestResolverPathPrefix(t *testing.T) {
+	p := &fakeProvider{value: "abc"}
+	resolver := func(orgID int64) *Resolver {
+		return NewResolverWithProviders(map[string]Provider{VaultScheme: p, AWSSecretsManagerScheme: p}, map[string]string{
+			VaultScheme:             orgPathPrefix("secret/grafana/org-{org_id}", orgID),
+			AWSSecretsManagerScheme: orgPathPrefix("grafana/org-{org_id}/", orgID),
+		}, time.Minute)
+	}
+	org1, org2 := resolver(1), resolver(2)
+	ref := Reference{Scheme: VaultScheme, Path: "secret/grafana/org-1/pagerduty", Key: "integrationKey"}
+
+	v, err := org1.Resolve(context.Background(), ref)
+	require.NoError(t, err)
+	require.Equal(t, "abc", v)
+	require.NoError(t, org1.Configured(ref))
+
+	// Org 2 can't reference the secrets of org 1, even once they are cached.
+	_, err = org2.Resolve(context.Background(), ref)
+	require.ErrorIs(t, err, ErrReferenceOutOfScope)
+	require.ErrorIs(t, org2.Configured(ref), ErrReferenceOutOfScope)
+	require.Equal(t, 1, p.calls)
+
+	for _, path := range []string{
+		"secret/pagerduty",
+		"secret/grafana/org-10/pagerduty",
+		"secret/grafana/org-1/../org-2/pagerduty",
+	} {
+		require.ErrorIs(t, org1.Configured(Reference{Scheme: VaultScheme, Path: path, Key: "key"}), ErrReferenceOutOfScope, path)
+	}
+
+	// The name of the secret of an ARN is checked.
+	require.NoError(t, org2.Configured(Reference{Scheme: AWSSecretsManagerScheme, Path: "arn:aws:secretsmanager:us-east-1:123456789012:secret:grafana/org-2/slack-AbCdEf"}))
+	require.ErrorIs(t, org2.Configured(Reference{Scheme: AWSSecretsManagerScheme, Path: "arn:aws:secretsmanager:us-east-1:123456789012:secret:grafana/org-1/slack-AbCdEf"}), ErrReferenceOutOfScope)
+
+	// Any path can be referenced without prefix.
+	require.NoError(t, NewResolverWithProviders(map[string]Provider{VaultScheme: p}, nil, time.Minute).Configured(Reference{Scheme: VaultScheme, Path: "secret/pagerduty", Key: "key"}))
+}
@@ -0,0 +1,94 @@
+package sender
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	common_config "github.com/prometheus/common/config"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// httpClientConfig returns the HTTP client configuration of the requests to the Alertmanager(s). The HTTP client
+// only reads TLS certificates and keys from files, so they're written to a private directory removed on Stop.
+func (s *Sender) httpClientConfig(auth *ngmodels.ExternalAlertmanagerAuth) (common_config.HTTPClientConfig, error) {
+	cfg := common_config.HTTPClientConfig{FollowRedirects: true}
+	if auth == nil {
+		return cfg, nil
+	}
+
+	if auth.TLS != nil {
+		tlsCfg, err := s.tlsConfig(auth.TLS)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.TLSConfig = tlsCfg
+	}
+
+	if auth.OAuth2 != nil {
+		secret, err := ngmodels.DecryptSecret(auth.OAuth2.ClientSecret)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid OAuth2 client secret: %w", err)
+		}
+		cfg.OAuth2 = &common_config.OAuth2{
+			ClientID:     auth.OAuth2.ClientID,
+			ClientSecret: common_config.Secret(secret),
+			TokenURL:     auth.OAuth2.TokenURL,
+			Scopes:       auth.OAuth2.Scopes,
+		}
+	}
+
+	return cfg, cfg.Validate()
+}
+
+func (s *Sender) tlsConfig(tls *ngmodels.ExternalAlertmanagerTLS) (common_config.TLSConfig, error) {
+	cfg := common_config.TLSConfig{
+		ServerName:         tls.ServerName,
+		InsecureSkipVerify: tls.InsecureSkipVerify,
+	}
+
+	key, err := ngmodels.DecryptSecret(tls.ClientKey)
+	if err != nil {
+		return cfg, fmt.Errorf("invalid TLS client key: %w", err)
+	}
+
+	if s.tlsDir == "" {
+		s.tlsDir, err = ioutil.TempDir("", "grafana-sender-")
+		if err != nil {
+			return cfg, fmt.Errorf("failed to create the directory of the TLS certificates: %w", err)
+		}
+	}
+
+	files := []struct {
+		name    string
+		content string
+		target  *string
+	}{
+		{"ca.crt", tls.CACert, &cfg.CAFile},
+		{"client.crt", tls.ClientCert, &cfg.CertFile},
+		{"client.key", key, &cfg.KeyFile},
+	}
+	for _, f := range files {
+		if f.content == "" {
+			continue
+		}
+		p := filepath.Join(s.tlsDir, f.name)
+		if err := ioutil.WriteFile(p, []byte(f.content), 0600); err != nil {
+			return cfg, fmt.Errorf("failed to write %s: %w", f.name, err)
+		}
+		*f.target = p
+	}
+	return cfg, nil
+}
+
+// removeTLSFiles removes the TLS certificates and key written for the HTTP client.
+func (s *Sender) removeTLSFiles() {
+	if s.tlsDir == "" {
+		return
+	}
+	if err := os.RemoveAll(s.tlsDir); err != nil {
+		s.logger.Warn("failed to remove the TLS certificates", "dir", s.tlsDir, "err", err)
+	}
+}
@@ -0,0 +1,94 @@
+package sender
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestSender_OAuth2(t *testing.T) {
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, "client_credentials", r.Form.Get("grant_type"))
+		user, password, ok := r.BasicAuth()
+		require.True(t, ok)
+		require.Equal(t, "grafana", user)
+		require.Equal(t, "secret", password)
+		w.Header().Set("Content-Type", "application/json")
+		_, err := w.Write([]byte(`{"access_token":"token","token_type":"Bearer","expires_in":3600}`))
+		require.NoError(t, err)
+	}))
+	t.Cleanup(tokenServer.Close)
+	am := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path == "/api/v2/status" {
+			_, err := w.Write([]byte(`{"config":{"original":""},"versionInfo":{"version":"0.22.2"}}`))
+			require.NoError(t, err)
+		}
+	}))
+	t.Cleanup(am.Close)
+
+	secret, err := ngmodels.EncryptSecret("secret")
+	require.NoError(t, err)
+	s, err := New(1, metrics.NewMetrics(prometheus.NewRegistry()))
+	require.NoError(t, err)
+	t.Cleanup(s.Stop)
+	require.NoError(t, s.ApplyConfig(&ngmodels.AdminConfiguration{
+		OrgID:         1,
+		Alertmanagers: []string{am.URL},
+		Auth: &ngmodels.ExternalAlertmanagerAuth{
+			OAuth2: &ngmodels.ExternalAlertmanagerOAuth2{
+				ClientID:     "grafana",
+				ClientSecret: secret,
+				TokenURL:     tokenServer.URL,
+			},
+		},
+	}))
+
+	s.checkHealth(context.Background())
+	health := s.Health()
+	require.Len(t, health, 1)
+	require.True(t, health[0].Healthy, health[0].LastError)
+}
+
+func TestSender_TLSFiles(t *testing.T) {
+	key, err := ngmodels.EncryptSecret("client key")
+	require.NoError(t, err)
+	s, err := New(1, metrics.NewMetrics(prometheus.NewRegistry()))
+	require.NoError(t, err)
+
+	cfg, err := s.httpClientConfig(&ngmodels.ExternalAlertmanagerAuth{
+		TLS: &ngmodels.ExternalAlertmanagerTLS{
+			ClientCert: "client cert",
+			ClientKey:  key,
+			ServerName: "alertmanager",
+		},
+	})
+	require.NoError(t, err)
+	require.Empty(t, cfg.TLSConfig.CAFile)
+	require.Equal(t, "alertmanager", cfg.TLSConfig.ServerName)
+
+	b, err := ioutil.ReadFile(cfg.TLSConfig.KeyFile)
+	require.NoError(t, err)
+	require.Equal(t, "client key", string(b))
+	fi, err := os.Stat(cfg.TLSConfig.CertFile)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+	// The files are removed when the sender stops.
+	s.Stop()
+	_, err = os.Stat(s.tlsDir)
+	require.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,44 @@
+package sender
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// HandlesGrafanaManagedAlerts returns whether the Alertmanager datasource is set to receive the alerts of the Grafana managed rules.
+func HandlesGrafanaManagedAlerts(ds *models.DataSource) bool {
+	return ds.Type == models.DS_ALERTMANAGER && ds.JsonData != nil && ds.JsonData.Get("handleGrafanaManagedAlerts").MustBool(false)
+}
+
+// DatasourceAlertmanagerURL returns the URL alerts are sent to for an Alertmanager datasource,
+// including the basic authentication credentials of the datasource.
+func DatasourceAlertmanagerURL(ds *models.DataSource) (string, error) {
+	u, err := url.Parse(ds.Url)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL of datasource %q: %w", ds.Name, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("invalid URL of datasource %q: unsupported scheme %q", ds.Name, u.Scheme)
+	}
+
+	// The API of Cortex Alertmanagers is served under /alertmanager, as in the Alertmanager proxy.
+	implementation := "cortex"
+	if ds.JsonData != nil {
+		implementation = ds.JsonData.Get("implementation").MustString("cortex")
+	}
+	if implementation != "prometheus" {
+		u.Path = path.Join("/", u.Path, "alertmanager")
+	}
+
+	if ds.BasicAuth {
+		password := ds.DecryptedBasicAuthPassword()
+		if password == "" {
+			return "", fmt.Errorf("basic authentication of datasource %q is enabled but no password is set", ds.Name)
+		}
+		u.User = url.UserPassword(ds.BasicAuthUser, password)
+	}
+	return u.String(), nil
+}
@@ -0,0 +1,191 @@
+package sender
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"time"
+)
+
+const (
+	healthCheckInterval = 30 * time.Second
+	healthCheckTimeout  = 5 * time.Second
+)
+
+// TargetHealth is the result of the last health check of an external Alertmanager.
+type TargetHealth struct {
+	// URL of the Alertmanager, without the password.
+	URL     string
+	Healthy bool
+	// Version and ConfigHash are read from the status API of the Alertmanager.
+	Version    string
+	ConfigHash string
+	LastCheck  time.Time
+	LastError  string
+}
+
+// amStatus is the subset of the Alertmanager v2 status we check.
+type amStatus struct {
+	Config struct {
+		Original string `json:"original"`
+	} `json:"config"`
+	VersionInfo struct {
+		Version string `json:"version"`
+	} `json:"versionInfo"`
+}
+
+// Health returns the health of the configured Alertmanager(s), ordered by URL.
+func (s *Sender) Health() []TargetHealth {
+	s.healthMtx.RLock()
+	defer s.healthMtx.RUnlock()
+
+	res := make([]TargetHealth, 0, len(s.health))
+	for _, h := range s.health {
+		res = append(res, *h)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].URL < res[j].URL
+	})
+	return res
+}
+
+// unhealthy returns whether the last health check of the Alertmanager failed.
+// Alertmanagers that weren't checked yet are considered healthy.
+func (s *Sender) unhealthy(amURL string) bool {
+	s.healthMtx.RLock()
+	defer s.healthMtx.RUnlock()
+
+	h, ok := s.health[amURL]
+	return ok && !h.Healthy
+}
+
+func (s *Sender) runHealthChecks(ctx context.Context) {
+	ticker := time.NewTicker(healthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.checkHealth(ctx)
+		case <-s.checkc:
+			s.checkHealth(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// checkHealth checks every configured Alertmanager and reapplies the configuration when the health of one changes,
+// alerts are then only sent to the healthy ones.
+func (s *Sender) checkHealth(ctx context.Context) {
+	s.cfgMtx.Lock()
+	defer s.cfgMtx.Unlock()
+	if s.cfg == nil {
+		return
+	}
+
+	changed := false
+	checked := make(map[string]struct{}, len(s.cfg.Alertmanagers))
+	for _, amURL := range s.cfg.Alertmanagers {
+		checked[amURL] = struct{}{}
+		h := s.checkTarget(ctx, amURL)
+
+		s.healthMtx.Lock()
+		// Alertmanagers that weren't checked yet are considered healthy.
+		prev, ok := s.health[amURL]
+		if (!ok || prev.Healthy) != h.Healthy {
+			changed = true
+		}
+		s.health[amURL] = h
+		s.healthMtx.Unlock()
+
+		if !h.Healthy {
+			s.logger.Warn("external Alertmanager is unhealthy", "alertmanager", h.URL, "err", h.LastError)
+		}
+		healthy := 0.0
+		if h.Healthy {
+			healthy = 1
+		}
+		s.metrics.ExternalAlertmanagerHealthy.WithLabelValues(fmt.Sprint(s.orgID), h.URL).Set(healthy)
+	}
+
+	s.healthMtx.Lock()
+	for amURL, h := range s.health {
+		if _, ok := checked[amURL]; !ok {
+			delete(s.health, amURL)
+			s.metrics.ExternalAlertmanagerHealthy.DeleteLabelValues(fmt.Sprint(s.orgID), h.URL)
+		}
+	}
+	s.healthMtx.Unlock()
+
+	if changed {
+		if err := s.applyConfig(); err != nil {
+			s.logger.Error("failed to apply the configuration after a health change", "err", err)
+		}
+	}
+}
+
+// checkTarget checks that the Alertmanager is ready and reads its version and configuration from the status API.
+func (s *Sender) checkTarget(ctx context.Context, amURL string) *TargetHealth {
+	h := &TargetHealth{URL: amURL, LastCheck: time.Now()}
+	u, err := url.Parse(amURL)
+	if err != nil {
+		h.LastError = err.Error()
+		return h
+	}
+	h.URL = u.Redacted()
+
+	if err := s.probe(ctx, u, "/-/ready", nil); err != nil {
+		h.LastError = fmt.Sprintf("not ready: %s", err)
+		return h
+	}
+
+	status := amStatus{}
+	if err := s.probe(ctx, u, "/api/v2/status", &status); err != nil {
+		h.LastError = fmt.Sprintf("failed to get status: %s", err)
+		return h
+	}
+	h.Healthy = true
+	h.Version = status.VersionInfo.Version
+	h.ConfigHash = fmt.Sprintf("%x", sha256.Sum256([]byte(status.Config.Original)))
+	return h
+}
+
+func (s *Sender) probe(ctx context.Context, u *url.URL, endpoint string, result interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	target := *u
+	target.User = nil
+	target.Path = path.Join("/", u.Path, endpoint)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return err
+	}
+	if u.User != nil {
+		password, _ := u.User.Password()
+		req.SetBasicAuth(u.User.Username(), password)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			s.logger.Warn("failed to close response body", "err", err)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
@@ -0,0 +1,78 @@
+package sender
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	common_config "github.com/prometheus/common/config"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestSender_CheckHealth(t *testing.T) {
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/prefix/-/ready":
+		case "/prefix/api/v2/status":
+			user, password, ok := r.BasicAuth()
+			require.True(t, ok)
+			require.Equal(t, "admin", user)
+			require.Equal(t, "secret", password)
+			_, err := w.Write([]byte(`{"config":{"original":"route: {}"},"versionInfo":{"version":"0.22.2"}}`))
+			require.NoError(t, err)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(healthy.Close)
+	notReady := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(notReady.Close)
+
+	healthyURL := "http://admin:secret@" + healthy.Listener.Addr().String() + "/prefix"
+	m := metrics.NewMetrics(prometheus.NewRegistry())
+	s, err := New(1, m)
+	require.NoError(t, err)
+	t.Cleanup(s.Stop)
+	require.NoError(t, s.ApplyConfig(&ngmodels.AdminConfiguration{
+		OrgID:         1,
+		Alertmanagers: []string{healthyURL, notReady.URL},
+	}))
+
+	s.checkHealth(context.Background())
+
+	health := s.Health()
+	require.Len(t, health, 2)
+	byURL := map[string]TargetHealth{}
+	for _, h := range health {
+		byURL[h.URL] = h
+	}
+
+	h := byURL["http://admin:xxxxx@"+healthy.Listener.Addr().String()+"/prefix"]
+	require.True(t, h.Healthy)
+	require.Equal(t, "0.22.2", h.Version)
+	require.Equal(t, "f1d6ecfb1bd7b2a121eb0efe13aefa36237429013acd00697a0cbb0e842fca97", h.ConfigHash)
+	require.Empty(t, h.LastError)
+
+	h = byURL[notReady.URL]
+	require.False(t, h.Healthy)
+	require.Equal(t, "not ready: unexpected status code 503", h.LastError)
+
+	require.Equal(t, 0.0, testutil.ToFloat64(m.ExternalAlertmanagerHealthy.WithLabelValues("1", notReady.URL)))
+	require.True(t, s.unhealthy(notReady.URL))
+	require.False(t, s.unhealthy(healthyURL))
+
+	// The unhealthy Alertmanager is no longer part of the notifier configuration.
+	cfg, err := buildNotifierConfig(s.cfg, map[string]bool{notReady.URL: true}, common_config.HTTPClientConfig{})
+	require.NoError(t, err)
+	require.Len(t, cfg.AlertingConfig.AlertmanagerConfigs, 2)
+	require.Empty(t, cfg.AlertingConfig.AlertmanagerConfigs[0].RelabelConfigs)
+	require.Len(t, cfg.AlertingConfig.AlertmanagerConfigs[1].RelabelConfigs, 1)
+}
@@ -2,6 +2,7 @@ package sender
 
 import (
 	"context"
+	"net/http"
 	"net/url"
 	"strings"
 	"sync"
@@ -22,6 +23,7 @@ import (
 	"github.com/prometheus/prometheus/discovery"
 	"github.com/prometheus/prometheus/notifier"
 	"github.com/prometheus/prometheus/pkg/labels"
+	"github.com/prometheus/prometheus/pkg/relabel"
 )
 
 const (
@@ -37,17 +39,39 @@ type Sender struct {
 
 	manager *notifier.Manager
 
+	sdCtx     context.Context
 	sdCancel  context.CancelFunc
 	sdManager *discovery.Manager
+
+	orgID   int64
+	metrics *metrics.Metrics
+
+	cfgMtx sync.Mutex
+	cfg    *ngmodels.AdminConfiguration
+
+	// health is the result of the last health check of each configured Alertmanager, by URL.
+	healthMtx sync.RWMutex
+	health    map[string]*TargetHealth
+	client    *http.Client
+	// checkc triggers a health check, e.g. when the configuration changes.
+	checkc chan struct{}
+	// tlsDir is where the TLS certificates and key of the configuration are written.
+	tlsDir string
 }
 
-func New(metrics *metrics.Metrics) (*Sender, error) {
-	l := log.New("sender")
+func New(orgID int64, metrics *metrics.Metrics) (*Sender, error) {
+	l := log.New("sender", "org", orgID)
 	sdCtx, sdCancel := context.WithCancel(context.Background())
 	s := &Sender{
 		logger:      l,
 		gokitLogger: gokit_log.NewLogfmtLogger(logging.NewWrapper(l)),
+		sdCtx:       sdCtx,
 		sdCancel:    sdCancel,
+		orgID:       orgID,
+		metrics:     metrics,
+		health:      map[string]*TargetHealth{},
+		client:      &http.Client{},
+		checkc:      make(chan struct{}, 1),
 	}
 
 	s.manager = notifier.NewManager(
@@ -62,7 +86,46 @@ func New(metrics *metrics.Metrics) (*Sender, error) {
 
 // ApplyConfig syncs a configuration with the sender.
 func (s *Sender) ApplyConfig(cfg *ngmodels.AdminConfiguration) error {
-	notifierCfg, err := buildNotifierConfig(cfg)
+	s.cfgMtx.Lock()
+	defer s.cfgMtx.Unlock()
+
+	s.cfg = cfg
+	if err := s.applyConfig(); err != nil {
+		return err
+	}
+
+	select {
+	case s.checkc <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// applyConfig applies the last configuration, leaving out the unhealthy Alertmanager(s) unless none is healthy.
+// It must be called with cfgMtx held.
+func (s *Sender) applyConfig() error {
+	unhealthy := map[string]bool{}
+	for _, amURL := range s.cfg.Alertmanagers {
+		if s.unhealthy(amURL) {
+			unhealthy[amURL] = true
+		}
+	}
+	if len(unhealthy) == len(s.cfg.Alertmanagers) {
+		// Sending to unhealthy Alertmanagers is better than not sending at all.
+		unhealthy = map[string]bool{}
+	}
+
+	httpCfg, err := s.httpClientConfig(s.cfg.Auth)
+	if err != nil {
+		return err
+	}
+	client, err := common_config.NewClientFromConfig(httpCfg, "sender")
+	if err != nil {
+		return err
+	}
+	s.client = client
+
+	notifierCfg, err := buildNotifierConfig(s.cfg, unhealthy, httpCfg)
 	if err != nil {
 		return err
 	}
@@ -80,7 +143,7 @@ func (s *Sender) ApplyConfig(cfg *ngmodels.AdminConfiguration) error {
 }
 
 func (s *Sender) Run() {
-	s.wg.Add(2)
+	s.wg.Add(3)
 
 	go func() {
 		if err := s.sdManager.Run(); err != nil {
@@ -93,6 +156,11 @@ func (s *Sender) Run() {
 		s.manager.Run(s.sdManager.SyncCh())
 		s.wg.Done()
 	}()
+
+	go func() {
+		s.runHealthChecks(s.sdCtx)
+		s.wg.Done()
+	}()
 }
 
 // SendAlerts sends a set of alerts to the configured Alertmanager(s).
@@ -116,6 +184,7 @@ func (s *Sender) Stop() {
 	s.sdCancel()
 	s.manager.Stop()
 	s.wg.Wait()
+	s.removeTLSFiles()
 }
 
 // Alertmanagers returns a list of the discovered Alertmanager(s).
@@ -128,7 +197,8 @@ func (s *Sender) DroppedAlertmanagers() []*url.URL {
 	return s.manager.DroppedAlertmanagers()
 }
 
-func buildNotifierConfig(cfg *ngmodels.AdminConfiguration) (*config.Config, error) {
+// buildNotifierConfig builds the notifier configuration of the Alertmanager(s), the unhealthy ones are dropped.
+func buildNotifierConfig(cfg *ngmodels.AdminConfiguration, unhealthy map[string]bool, httpCfg common_config.HTTPClientConfig) (*config.Config, error) {
 	amConfigs := make([]*config.AlertmanagerConfig, 0, len(cfg.Alertmanagers))
 	for _, amURL := range cfg.Alertmanagers {
 		u, err := url.Parse(amURL)
@@ -150,10 +220,22 @@ func buildNotifierConfig(cfg *ngmodels.AdminConfiguration) (*config.Config, erro
 			PathPrefix:              u.Path,
 			Timeout:                 model.Duration(defaultTimeout),
 			ServiceDiscoveryConfigs: sdConfig,
+			HTTPClientConfig:        httpCfg,
+		}
+
+		if unhealthy[amURL] {
+			amConfig.RelabelConfigs = []*relabel.Config{{
+				SourceLabels: model.LabelNames{model.AddressLabel},
+				Separator:    ";",
+				Regex:        relabel.MustNewRegexp(".*"),
+				Action:       relabel.Drop,
+			}}
 		}
 
 		// Check the URL for basic authentication information first
 		if u.User != nil {
+			// The basic authentication of the URL takes precedence over OAuth2, they can't be combined.
+			amConfig.HTTPClientConfig.OAuth2 = nil
 			amConfig.HTTPClientConfig.BasicAuth = &common_config.BasicAuth{
 				Username: u.User.Username(),
 			}
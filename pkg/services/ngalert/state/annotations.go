@@ -0,0 +1,108 @@
+package state
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// AnnotationWriter writes the annotations of the changes of the states of the alerts.
+type AnnotationWriter interface {
+	WriteStateChange(alertRule *ngModels.AlertRule, result eval.Result, oldState, newState eval.State) error
+}
+
+// NewAnnotationWriter returns the writer of the backend configured by state_annotations_backend, nil when the
+// annotations are disabled.
+func NewAnnotationWriter(backend string, store store.StateAnnotationStore) AnnotationWriter {
+	switch backend {
+	case "disabled":
+		return nil
+	case "table":
+		return &tableAnnotationWriter{store: store}
+	default:
+		return &sqlAnnotationWriter{}
+	}
+}
+
+// writesAnnotations returns false if the rule opted out of the annotations of the changes of the states of its alerts.
+func writesAnnotations(alertRule *ngModels.AlertRule) bool {
+	return alertRule.Labels[ngModels.StateAnnotationsLabel] != "false"
+}
+
+func annotationText(alertRule *ngModels.AlertRule, result eval.Result, newState eval.State) string {
+	return fmt.Sprintf("%s {%s} - %s", alertRule.Title, result.Instance.String(), newState.String())
+}
+
+// sqlAnnotationWriter writes the annotations in the annotations table, in the dashboard of the rules linked to a panel.
+type sqlAnnotationWriter struct{}
+
+func (w *sqlAnnotationWriter) WriteStateChange(alertRule *ngModels.AlertRule, result eval.Result, oldState, newState eval.State) error {
+	// the shadow rules don't annotate the dashboard of their original rule
+	if alertRule.IsShadow() {
+		return nil
+	}
+	dashUid, ok := alertRule.GetAnnotation(ngModels.DashboardUIDAnnotation)
+	if !ok {
+		return nil
+	}
+
+	panelUid, _ := alertRule.GetAnnotation(ngModels.PanelIDAnnotation)
+
+	panelId, err := strconv.ParseInt(panelUid, 10, 64)
+	if err != nil {
+		return fmt.Errorf("error parsing panelUID %q: %w", panelUid, err)
+	}
+
+	query := &models.GetDashboardQuery{
+		Uid:   dashUid,
+		OrgId: alertRule.OrgID,
+	}
+
+	err = sqlstore.GetDashboard(query)
+	if err != nil {
+		return fmt.Errorf("error getting dashboard %q: %w", dashUid, err)
+	}
+
+	item := &annotations.Item{
+		OrgId:       alertRule.OrgID,
+		DashboardId: query.Result.Id,
+		PanelId:     panelId,
+		PrevState:   oldState.String(),
+		NewState:    newState.String(),
+		Text:        annotationText(alertRule, result, newState),
+		Epoch:       result.EvaluatedAt.UnixNano() / int64(time.Millisecond),
+	}
+
+	annotationRepo := annotations.GetRepository()
+	return annotationRepo.Save(item)
+}
+
+// tableAnnotationWriter writes the annotations of every rule in the dedicated table of the annotations of the alerts.
+type tableAnnotationWriter struct {
+	store store.StateAnnotationStore
+}
+
+func (w *tableAnnotationWriter) WriteStateChange(alertRule *ngModels.AlertRule, result eval.Result, oldState, newState eval.State) error {
+	a := &ngModels.AlertStateAnnotation{
+		OrgID:     alertRule.OrgID,
+		RuleUID:   alertRule.UID,
+		Labels:    result.Instance,
+		PrevState: oldState.String(),
+		NewState:  newState.String(),
+		Text:      annotationText(alertRule, result, newState),
+		Epoch:     result.EvaluatedAt.UnixNano() / int64(time.Millisecond),
+	}
+	if dashUID, ok := alertRule.GetAnnotation(ngModels.DashboardUIDAnnotation); ok && !alertRule.IsShadow() {
+		a.DashboardUID = dashUID
+		panelID, _ := alertRule.GetAnnotation(ngModels.PanelIDAnnotation)
+		a.PanelID, _ = strconv.ParseInt(panelID, 10, 64)
+	}
+	return w.store.SaveAlertStateAnnotation(a)
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/templatefuncs"
 	prometheusModel "github.com/prometheus/common/model"
 )
 
@@ -40,6 +41,9 @@ func (c *cache) getOrCreate(alertRule *ngModels.AlertRule, result eval.Result) *
 	labels := result.Instance.Copy()
 	attachRuleLabels(labels, alertRule)
 	ruleLabels, annotations := c.expandRuleLabelsAndAnnotations(alertRule, labels, result)
+	for k, v := range alertRule.Links.Annotations() {
+		annotations[k] = v
+	}
 
 	// if duplicate labels exist, alertRule label will take precedence
 	lbs := mergeLabels(ruleLabels, result.Instance)
@@ -111,6 +115,8 @@ func (c *cache) expandRuleLabelsAndAnnotations(alertRule *ngModels.AlertRule, la
 type templateCaptureValue struct {
 	Labels map[string]string
 	Value  *float64
+	// Samples are the most recent log lines of a query in logs mode, via {{ $values.A.Samples }}.
+	Samples []string
 }
 
 // String implements the Stringer interface to print the value of each RefID
@@ -123,8 +129,6 @@ func (v templateCaptureValue) String() string {
 }
 
 func expandTemplate(name, text string, labels map[string]string, alertInstance eval.Result) (result string, resultErr error) {
-	name = "__alert_" + name
-	text = "{{- $labels := .Labels -}}{{- $values := .Values -}}{{- $value := .Value -}}" + text
 	// It'd better to have no alert description than to kill the whole process
 	// if there's a bug in the template.
 	defer func() {
@@ -137,34 +141,50 @@ func expandTemplate(name, text string, labels map[string]string, alertInstance e
 		}
 	}()
 
-	tmpl, err := text_template.New(name).Option("missingkey=error").Parse(text)
+	tmpl, err := parseTemplate(name, text)
 	if err != nil {
-		return "", fmt.Errorf("error parsing template %v: %s", name, err.Error())
+		return "", err
 	}
 	var buffer bytes.Buffer
-	if err := tmpl.Execute(&buffer, struct {
-		Labels map[string]string
-		Values map[string]templateCaptureValue
-		Value  string
-	}{
+	if err := tmpl.Execute(&buffer, templateData{
 		Labels: labels,
 		Values: func() map[string]templateCaptureValue {
 			m := make(map[string]templateCaptureValue)
 			for k, v := range alertInstance.Values {
 				m[k] = templateCaptureValue{
-					Labels: v.Labels,
-					Value:  v.Value,
+					Labels:  v.Labels,
+					Value:   v.Value,
+					Samples: v.Samples,
 				}
 			}
 			return m
 		}(),
 		Value: alertInstance.EvaluationString,
 	}); err != nil {
-		return "", fmt.Errorf("error executing template %v: %s", name, err.Error())
+		return "", fmt.Errorf("error executing template %v: %s", tmpl.Name(), err.Error())
 	}
 	return buffer.String(), nil
 }
 
+// parseTemplate parses the template of a label or annotation, with the variables $labels, $values and $value and
+// the functions of the templatefuncs registry.
+func parseTemplate(name, text string) (*text_template.Template, error) {
+	name = "__alert_" + name
+	text = "{{- $labels := .Labels -}}{{- $values := .Values -}}{{- $value := .Value -}}" + text
+	tmpl, err := text_template.New(name).Option("missingkey=error").Funcs(templatefuncs.FuncMap()).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing template %v: %s", name, err.Error())
+	}
+	return tmpl, nil
+}
+
+// templateData is the data of the templates of the labels and annotations.
+type templateData struct {
+	Labels map[string]string
+	Values map[string]templateCaptureValue
+	Value  string
+}
+
 func (c *cache) set(entry *State) {
 	c.mtxStates.Lock()
 	defer c.mtxStates.Unlock()
@@ -198,6 +218,19 @@ func (c *cache) getAll(orgID int64) []*State {
 	return states
 }
 
+// countOtherRules returns the number of states of the organization, except those of the rule.
+func (c *cache) countOtherRules(orgID int64, alertRuleUID string) int {
+	c.mtxStates.RLock()
+	defer c.mtxStates.RUnlock()
+	count := 0
+	for uid, ruleStates := range c.states[orgID] {
+		if uid != alertRuleUID {
+			count += len(ruleStates)
+		}
+	}
+	return count
+}
+
 func (c *cache) getStatesForRuleUID(orgID int64, alertRuleUID string) []*State {
 	var ruleStates []*State
 	c.mtxStates.RLock()
@@ -215,6 +248,13 @@ func (c *cache) removeByRuleUID(orgID int64, uid string) {
 	delete(c.states[orgID], uid)
 }
 
+// removeByOrg deletes all entries in the state cache of the organization.
+func (c *cache) removeByOrg(orgID int64) {
+	c.mtxStates.Lock()
+	defer c.mtxStates.Unlock()
+	delete(c.states, orgID)
+}
+
 func (c *cache) reset() {
 	c.mtxStates.Lock()
 	defer c.mtxStates.Unlock()
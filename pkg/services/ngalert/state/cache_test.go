@@ -88,6 +88,34 @@ func TestExpandTemplate(t *testing.T) {
 			EvaluationString: "[ var='A' labels={instance=foo} value=10 ]",
 		},
 		expected: "[ var='A' labels={instance=foo} value=10 ]",
+	}, {
+		name: "log samples are expanded from $values",
+		text: "{{ $values.A }} errors:{{ range $values.A.Samples }} {{ . }};{{ end }}",
+		alertInstance: eval.Result{
+			Values: map[string]eval.NumberValueCapture{
+				"A": {
+					Var:     "A",
+					Labels:  data.Labels{"app": "api"},
+					Value:   ptr.Float64(2),
+					Samples: []string{"timeout", "connection refused"},
+				},
+			},
+		},
+		expected: "2 errors: timeout; connection refused;",
+	}, {
+		name:   "functions of the registry are available",
+		text:   "{{ $labels.instance | trimSuffix \":9100\" }} uses {{ $values.A.Value | humanizeBytes }}",
+		labels: data.Labels{"instance": "foo:9100"},
+		alertInstance: eval.Result{
+			Values: map[string]eval.NumberValueCapture{
+				"A": {
+					Var:    "A",
+					Labels: data.Labels{},
+					Value:  ptr.Float64(1536),
+				},
+			},
+		},
+		expected: "foo uses 1.5 KiB",
 	}}
 
 	for _, c := range cases {
@@ -0,0 +1,44 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// compositeFiringVar is the name of the value with the number of firing rules in the results of composite rules.
+const compositeFiringVar = "firing"
+
+// EvaluateComposite evaluates the condition of a composite rule over the current states of its rules, without
+// queries. The rules that don't exist or have not been evaluated yet are not firing.
+func (st *Manager) EvaluateComposite(alertRule *ngModels.AlertRule, now time.Time) eval.Results {
+	firing := make([]string, 0, len(alertRule.Composite.RuleUIDs))
+	for _, uid := range alertRule.Composite.RuleUIDs {
+		for _, s := range st.GetStatesForRuleUID(alertRule.OrgID, uid) {
+			if s.State == eval.Alerting {
+				firing = append(firing, uid)
+				break
+			}
+		}
+	}
+
+	count := float64(len(firing))
+	result := eval.Result{
+		Instance:         data.Labels{},
+		State:            eval.Normal,
+		EvaluatedAt:      now,
+		EvaluationString: fmt.Sprintf("%d of %d rules firing: [%s]", len(firing), len(alertRule.Composite.RuleUIDs), strings.Join(firing, ", ")),
+		Values: map[string]eval.NumberValueCapture{
+			compositeFiringVar: {Var: compositeFiringVar, Value: &count},
+		},
+	}
+	if len(firing) >= alertRule.Composite.Threshold() {
+		result.State = eval.Alerting
+	}
+	return eval.Results{result}
+}
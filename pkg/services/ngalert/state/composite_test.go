@@ -0,0 +1,60 @@
+package state_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestEvaluateComposite(t *testing.T) {
+	now := time.Now()
+	st := state.NewManager(log.New("test_evaluate_composite"), nilMetrics, nil, nil, nil, nil, nil)
+	evaluate := func(uid string, states ...eval.State) {
+		rule := &models.AlertRule{OrgID: 1, UID: uid, Title: uid, NamespaceUID: "namespace", IntervalSeconds: 10}
+		results := make(eval.Results, 0, len(states))
+		for i, s := range states {
+			results = append(results, eval.Result{Instance: data.Labels{"instance": string(rune('a' + i))}, State: s, EvaluatedAt: now})
+		}
+		st.ProcessEvalResults(rule, results)
+	}
+	evaluate("rule-1", eval.Normal, eval.Alerting)
+	evaluate("rule-2", eval.Alerting)
+	evaluate("rule-3", eval.Normal)
+	evaluate("rule-4", eval.NoData)
+
+	composite := func(minFiring int, uids ...string) eval.Result {
+		rule := &models.AlertRule{OrgID: 1, UID: "composite", Composite: models.CompositeCondition{RuleUIDs: uids, MinFiring: minFiring}}
+		results := st.EvaluateComposite(rule, now)
+		require.Len(t, results, 1)
+		return results[0]
+	}
+
+	t.Run("fires if enough rules are firing", func(t *testing.T) {
+		r := composite(2, "rule-1", "rule-2", "rule-3", "rule-4", "missing")
+		require.Equal(t, eval.Alerting, r.State)
+		require.Equal(t, "2 of 5 rules firing: [rule-1, rule-2]", r.EvaluationString)
+		require.Equal(t, float64(2), *r.Values["firing"].Value)
+	})
+
+	t.Run("is normal if not enough rules are firing", func(t *testing.T) {
+		r := composite(3, "rule-1", "rule-2", "rule-3", "rule-4", "missing")
+		require.Equal(t, eval.Normal, r.State)
+	})
+
+	t.Run("needs all rules to fire without a minimum", func(t *testing.T) {
+		require.Equal(t, eval.Alerting, composite(0, "rule-1", "rule-2").State)
+		require.Equal(t, eval.Normal, composite(0, "rule-1", "rule-2", "rule-3").State)
+	})
+
+	t.Run("doesn't count the rules of other organisations", func(t *testing.T) {
+		rule := &models.AlertRule{OrgID: 2, UID: "composite", Composite: models.CompositeCondition{RuleUIDs: []string{"rule-2"}}}
+		require.Equal(t, eval.Normal, st.EvaluateComposite(rule, now)[0].State)
+	})
+}
@@ -0,0 +1,244 @@
+package state
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+)
+
+// Incident is a group of related alerts of different rules, firing at the same time with the same values of the
+// correlation labels.
+type Incident struct {
+	ID    string
+	OrgID int64
+	// Labels are the correlation labels shared by the alerts of the incident.
+	Labels   data.Labels
+	StartsAt time.Time
+	// LastActiveAt is the last time an alert of the incident was firing.
+	LastActiveAt time.Time
+	Alerts       []IncidentAlert
+}
+
+// Firing returns whether an alert of the incident is firing.
+func (i Incident) Firing() bool {
+	for _, a := range i.Alerts {
+		if a.Firing {
+			return true
+		}
+	}
+	return false
+}
+
+// IncidentAlert is an alert correlated into an incident.
+type IncidentAlert struct {
+	RuleUID  string
+	Labels   data.Labels
+	StartsAt time.Time
+	// Firing is false once the alert resolved, the alert stays in the incident until the incident is closed.
+	Firing bool
+}
+
+// Correlator groups the firing alerts of the rules of an organization into incidents. The alerts with the same values
+// of the correlation labels are in the same incident while an alert of the incident is firing, or less than the
+// correlation window after the last one resolved.
+type Correlator struct {
+	labels []string
+	window time.Duration
+
+	mtx sync.Mutex
+	// incidents are the open incidents by organization and correlation key.
+	incidents map[int64]map[string]*incident
+}
+
+type incident struct {
+	id           string
+	labels       data.Labels
+	startsAt     time.Time
+	lastActiveAt time.Time
+	// alerts are the alerts of the incident by rule UID and cache ID, firing the number of those firing.
+	alerts map[string]*IncidentAlert
+	firing int
+}
+
+// NewCorrelator returns the correlator of the alerts configured by correlation_labels, nil when the alerts aren't
+// correlated.
+func NewCorrelator(labels []string, window time.Duration) *Correlator {
+	if len(labels) == 0 {
+		return nil
+	}
+	return &Correlator{labels: labels, window: window, incidents: map[int64]map[string]*incident{}}
+}
+
+// correlationKey returns the correlation labels of the alert and their key, an empty key if the alert has none.
+func (c *Correlator) correlationKey(lbs data.Labels) (string, data.Labels) {
+	var parts []string
+	shared := data.Labels{}
+	for _, name := range c.labels {
+		if v, ok := lbs[name]; ok && v != "" {
+			parts = append(parts, fmt.Sprintf("%s=%q", name, v))
+			shared[name] = v
+		}
+	}
+	return strings.Join(parts, ","), shared
+}
+
+// incidentID returns an identifier of the incident that doesn't change when the alert starting it is evaluated
+// again after a restart.
+func incidentID(orgID int64, key string, startsAt time.Time) string {
+	h := fnv.New64a()
+	_, _ = fmt.Fprintf(h, "%d/%s/%d", orgID, key, startsAt.UnixNano())
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+func alertKey(s *State) string {
+	return s.AlertRuleUID + "/" + s.CacheId
+}
+
+func (i *incident) closed(now time.Time, window time.Duration) bool {
+	return i.firing == 0 && now.Sub(i.lastActiveAt) > window
+}
+
+// observe correlates the alert after its evaluation, and sets the identifier of its incident. The identifier is kept
+// once the alert resolved, so that the resolved alert has the same labels as the firing one.
+func (c *Correlator) observe(s *State, now time.Time) {
+	key, shared := c.correlationKey(s.Labels)
+	if key == "" {
+		return
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	orgIncidents, ok := c.incidents[s.OrgID]
+	if !ok {
+		orgIncidents = map[string]*incident{}
+		c.incidents[s.OrgID] = orgIncidents
+	}
+	inc := orgIncidents[key]
+	if inc != nil && inc.closed(now, c.window) {
+		delete(orgIncidents, key)
+		inc = nil
+	}
+
+	if s.State != eval.Alerting {
+		if inc == nil || inc.id != s.IncidentID {
+			return
+		}
+		if a, ok := inc.alerts[alertKey(s)]; ok && a.Firing {
+			a.Firing = false
+			inc.firing--
+		}
+		return
+	}
+
+	if inc == nil {
+		inc = &incident{
+			id:       incidentID(s.OrgID, key, s.StartsAt),
+			labels:   shared,
+			startsAt: s.StartsAt,
+			alerts:   map[string]*IncidentAlert{},
+		}
+		orgIncidents[key] = inc
+	}
+	inc.lastActiveAt = now
+	a, ok := inc.alerts[alertKey(s)]
+	if !ok {
+		a = &IncidentAlert{RuleUID: s.AlertRuleUID, Labels: s.Labels, StartsAt: s.StartsAt}
+		inc.alerts[alertKey(s)] = a
+	}
+	if !a.Firing {
+		a.Firing = true
+		inc.firing++
+	}
+	s.IncidentID = inc.id
+}
+
+// remove removes the alert from its incident, such as when the alert is stale.
+func (c *Correlator) remove(s *State) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.removeMatching(s.OrgID, func(key string, _ *IncidentAlert) bool { return key == alertKey(s) })
+}
+
+// removeRule removes the alerts of the rule from their incidents.
+func (c *Correlator) removeRule(orgID int64, ruleUID string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.removeMatching(orgID, func(_ string, a *IncidentAlert) bool { return a.RuleUID == ruleUID })
+}
+
+// removeMatching removes the matching alerts and the incidents left without alerts. The mutex must be held.
+func (c *Correlator) removeMatching(orgID int64, matches func(key string, a *IncidentAlert) bool) {
+	for key, inc := range c.incidents[orgID] {
+		for k, a := range inc.alerts {
+			if !matches(k, a) {
+				continue
+			}
+			if a.Firing {
+				inc.firing--
+			}
+			delete(inc.alerts, k)
+		}
+		if len(inc.alerts) == 0 {
+			delete(c.incidents[orgID], key)
+		}
+	}
+}
+
+// removeOrg removes the incidents of the organization.
+func (c *Correlator) removeOrg(orgID int64) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	delete(c.incidents, orgID)
+}
+
+func (c *Correlator) reset() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.incidents = map[int64]map[string]*incident{}
+}
+
+// Incidents returns the open incidents of the organization, the latest first.
+func (c *Correlator) Incidents(orgID int64, now time.Time) []Incident {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	result := make([]Incident, 0, len(c.incidents[orgID]))
+	for key, inc := range c.incidents[orgID] {
+		if inc.closed(now, c.window) {
+			delete(c.incidents[orgID], key)
+			continue
+		}
+		i := Incident{
+			ID:           inc.id,
+			OrgID:        orgID,
+			Labels:       inc.labels.Copy(),
+			StartsAt:     inc.startsAt,
+			LastActiveAt: inc.lastActiveAt,
+			Alerts:       make([]IncidentAlert, 0, len(inc.alerts)),
+		}
+		for _, a := range inc.alerts {
+			i.Alerts = append(i.Alerts, *a)
+		}
+		sort.Slice(i.Alerts, func(j, k int) bool {
+			if !i.Alerts[j].StartsAt.Equal(i.Alerts[k].StartsAt) {
+				return i.Alerts[j].StartsAt.Before(i.Alerts[k].StartsAt)
+			}
+			return i.Alerts[j].Labels.String() < i.Alerts[k].Labels.String()
+		})
+		result = append(result, i)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].StartsAt.Equal(result[j].StartsAt) {
+			return result[i].StartsAt.After(result[j].StartsAt)
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
@@ -0,0 +1,76 @@
+package state
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+)
+
+func TestCorrelator(t *testing.T) {
+	require.Nil(t, NewCorrelator(nil, time.Minute))
+
+	c := NewCorrelator([]string{"cluster", "service"}, 5*time.Minute)
+	now := time.Unix(1000, 0)
+	newState := func(ruleUID string, lbs data.Labels) *State {
+		return &State{AlertRuleUID: ruleUID, OrgID: 1, CacheId: lbs.String(), Labels: lbs, State: eval.Alerting, StartsAt: now}
+	}
+
+	db := newState("db", data.Labels{"cluster": "eu", "service": "api", "alertname": "DatabaseDown"})
+	latency := newState("latency", data.Labels{"cluster": "eu", "service": "api", "alertname": "HighLatency"})
+	other := newState("latency", data.Labels{"cluster": "us", "service": "api", "alertname": "HighLatency"})
+	uncorrelated := newState("disk", data.Labels{"alertname": "DiskFull"})
+	c.observe(db, now)
+	c.observe(latency, now.Add(time.Minute))
+	c.observe(other, now.Add(time.Minute))
+	c.observe(uncorrelated, now.Add(time.Minute))
+
+	// The alerts of the different rules with the same correlation labels are in the same incident.
+	require.NotEmpty(t, db.IncidentID)
+	require.Equal(t, db.IncidentID, latency.IncidentID)
+	require.NotEqual(t, db.IncidentID, other.IncidentID)
+	require.Empty(t, uncorrelated.IncidentID)
+
+	incidents := c.Incidents(1, now.Add(time.Minute))
+	require.Len(t, incidents, 2)
+	var eu Incident
+	for _, i := range incidents {
+		if i.ID == db.IncidentID {
+			eu = i
+		}
+	}
+	require.Equal(t, data.Labels{"cluster": "eu", "service": "api"}, eu.Labels)
+	require.Len(t, eu.Alerts, 2)
+	require.True(t, eu.Firing())
+	require.Empty(t, c.Incidents(2, now))
+
+	// The resolved alerts keep their incident, which is joined by the new alerts during the correlation window.
+	db.State, latency.State = eval.Normal, eval.Normal
+	c.observe(db, now.Add(2*time.Minute))
+	c.observe(latency, now.Add(2*time.Minute))
+	require.NotEmpty(t, db.IncidentID)
+	incidents = c.Incidents(1, now.Add(3*time.Minute))
+	require.Len(t, incidents, 2)
+
+	cpu := newState("cpu", data.Labels{"cluster": "eu", "service": "api", "alertname": "HighCPU"})
+	c.observe(cpu, now.Add(5*time.Minute))
+	require.Equal(t, db.IncidentID, cpu.IncidentID)
+
+	// After the correlation window, a new alert starts a new incident.
+	cpu.State = eval.Normal
+	c.observe(cpu, now.Add(6*time.Minute))
+	db.State, db.StartsAt = eval.Alerting, now.Add(12*time.Minute)
+	c.observe(db, now.Add(12*time.Minute))
+	require.NotEqual(t, cpu.IncidentID, db.IncidentID)
+
+	c.removeRule(1, "latency")
+	incidents = c.Incidents(1, now.Add(12*time.Minute))
+	require.Len(t, incidents, 1)
+	require.Equal(t, db.IncidentID, incidents[0].ID)
+
+	c.remove(db)
+	require.Empty(t, c.Incidents(1, now.Add(12*time.Minute)))
+}
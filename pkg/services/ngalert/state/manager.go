@@ -1,19 +1,21 @@
 package state
 
 import (
-	"fmt"
+	"context"
 	"strconv"
 	"time"
 
-	"github.com/grafana/grafana/pkg/models"
-	"github.com/grafana/grafana/pkg/services/annotations"
-	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
 
 	"github.com/grafana/grafana/pkg/infra/log"
 
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/screenshot"
 	"github.com/grafana/grafana/pkg/services/ngalert/store"
 )
 
@@ -29,9 +31,15 @@ type Manager struct {
 
 	ruleStore     store.RuleStore
 	instanceStore store.InstanceStore
+	// screenshots is nil when screenshots are disabled.
+	screenshots screenshot.ScreenshotService
+	// annotations is nil when the annotations of the changes of the states are disabled.
+	annotations AnnotationWriter
+	// correlator is nil when the alerts aren't correlated into incidents.
+	correlator *Correlator
 }
 
-func NewManager(logger log.Logger, metrics *metrics.Metrics, ruleStore store.RuleStore, instanceStore store.InstanceStore) *Manager {
+func NewManager(logger log.Logger, metrics *metrics.Metrics, ruleStore store.RuleStore, instanceStore store.InstanceStore, screenshots screenshot.ScreenshotService, annotations AnnotationWriter, correlator *Correlator) *Manager {
 	manager := &Manager{
 		cache:         newCache(logger, metrics),
 		quit:          make(chan struct{}),
@@ -40,6 +48,9 @@ func NewManager(logger log.Logger, metrics *metrics.Metrics, ruleStore store.Rul
 		metrics:       metrics,
 		ruleStore:     ruleStore,
 		instanceStore: instanceStore,
+		screenshots:   screenshots,
+		annotations:   annotations,
+		correlator:    correlator,
 	}
 	go manager.recordMetrics()
 	return manager
@@ -58,59 +69,67 @@ func (st *Manager) Warm() {
 		st.log.Error("unable to fetch orgIds", "msg", err.Error())
 	}
 
-	var states []*State
 	for _, orgId := range orgIds {
-		// Get Rules
-		ruleCmd := ngModels.ListAlertRulesQuery{
-			OrgID: orgId,
-		}
-		if err := st.ruleStore.GetOrgAlertRules(&ruleCmd); err != nil {
-			st.log.Error("unable to fetch previous state", "msg", err.Error())
-		}
+		st.WarmOrg(orgId)
+	}
+}
 
-		ruleByUID := make(map[string]*ngModels.AlertRule, len(ruleCmd.Result))
-		for _, rule := range ruleCmd.Result {
-			ruleByUID[rule.UID] = rule
-		}
+// WarmOrg loads the previous states of the alert instances of the organization in the cache.
+func (st *Manager) WarmOrg(orgId int64) {
+	// Get Rules
+	ruleCmd := ngModels.ListAlertRulesQuery{
+		OrgID: orgId,
+	}
+	if err := st.ruleStore.GetOrgAlertRules(&ruleCmd); err != nil {
+		st.log.Error("unable to fetch previous state", "msg", err.Error())
+	}
 
-		// Get Instances
-		cmd := ngModels.ListAlertInstancesQuery{
-			RuleOrgID: orgId,
-		}
-		if err := st.instanceStore.ListAlertInstances(&cmd); err != nil {
-			st.log.Error("unable to fetch previous state", "msg", err.Error())
-		}
+	ruleByUID := make(map[string]*ngModels.AlertRule, len(ruleCmd.Result))
+	for _, rule := range ruleCmd.Result {
+		ruleByUID[rule.UID] = rule
+	}
 
-		for _, entry := range cmd.Result {
-			ruleForEntry, ok := ruleByUID[entry.RuleUID]
-			if !ok {
-				st.log.Error("rule not found for instance, ignoring", "rule", entry.RuleUID)
-				continue
-			}
+	// Get Instances
+	cmd := ngModels.ListAlertInstancesQuery{
+		RuleOrgID: orgId,
+	}
+	if err := st.instanceStore.ListAlertInstances(&cmd); err != nil {
+		st.log.Error("unable to fetch previous state", "msg", err.Error())
+	}
 
-			lbs := map[string]string(entry.Labels)
-			cacheId, err := entry.Labels.StringKey()
-			if err != nil {
-				st.log.Error("error getting cacheId for entry", "msg", err.Error())
-			}
-			stateForEntry := &State{
-				AlertRuleUID:       entry.RuleUID,
-				OrgID:              entry.RuleOrgID,
-				CacheId:            cacheId,
-				Labels:             lbs,
-				State:              translateInstanceState(entry.CurrentState),
-				Results:            []Evaluation{},
-				StartsAt:           entry.CurrentStateSince,
-				EndsAt:             entry.CurrentStateEnd,
-				LastEvaluationTime: entry.LastEvalTime,
-				Annotations:        ruleForEntry.Annotations,
-			}
-			states = append(states, stateForEntry)
+	for _, entry := range cmd.Result {
+		ruleForEntry, ok := ruleByUID[entry.RuleUID]
+		if !ok {
+			st.log.Error("rule not found for instance, ignoring", "rule", entry.RuleUID)
+			continue
 		}
+
+		lbs := map[string]string(entry.Labels)
+		cacheId, err := entry.Labels.StringKey()
+		if err != nil {
+			st.log.Error("error getting cacheId for entry", "msg", err.Error())
+		}
+		st.set(&State{
+			AlertRuleUID:       entry.RuleUID,
+			OrgID:              entry.RuleOrgID,
+			CacheId:            cacheId,
+			Labels:             lbs,
+			State:              translateInstanceState(entry.CurrentState),
+			Results:            []Evaluation{},
+			StartsAt:           entry.CurrentStateSince,
+			EndsAt:             entry.CurrentStateEnd,
+			LastEvaluationTime: entry.LastEvalTime,
+			Annotations:        ruleForEntry.Annotations,
+		})
 	}
+}
 
-	for _, s := range states {
-		st.set(s)
+// UnloadOrg drops the states of the alert instances of the organization from the cache, they are still saved in
+// the instance store.
+func (st *Manager) UnloadOrg(orgID int64) {
+	st.cache.removeByOrg(orgID)
+	if st.correlator != nil {
+		st.correlator.removeOrg(orgID)
 	}
 }
 
@@ -129,11 +148,17 @@ func (st *Manager) Get(orgID int64, alertRuleUID, stateId string) (*State, error
 // ResetCache is used to ensure a clean cache on startup.
 func (st *Manager) ResetCache() {
 	st.cache.reset()
+	if st.correlator != nil {
+		st.correlator.reset()
+	}
 }
 
 // RemoveByRuleUID deletes all entries in the state manager that match the given rule UID.
 func (st *Manager) RemoveByRuleUID(orgID int64, ruleUID string) {
 	st.cache.removeByRuleUID(orgID, ruleUID)
+	if st.correlator != nil {
+		st.correlator.removeRule(orgID, ruleUID)
+	}
 }
 
 func (st *Manager) ProcessEvalResults(alertRule *ngModels.AlertRule, results eval.Results) []*State {
@@ -149,45 +174,102 @@ func (st *Manager) ProcessEvalResults(alertRule *ngModels.AlertRule, results eva
 	return states
 }
 
+// HoldStates keeps the states of the alerts of a rule that is not evaluated at now, such as during the maintenance
+// of its datasources: the alerts don't become stale, and the firing alerts are not resolved by the Alertmanager.
+func (st *Manager) HoldStates(alertRule *ngModels.AlertRule, now time.Time) []*State {
+	st.log.Debug("state manager holding states", "uid", alertRule.UID)
+	states := st.GetStatesForRuleUID(alertRule.OrgID, alertRule.UID)
+	for _, s := range states {
+		s.LastEvaluationTime = now
+		s.Resolved = false
+		if s.State == eval.Alerting {
+			s.setEndsAt(alertRule, eval.Result{EvaluatedAt: now})
+		}
+		st.set(s)
+	}
+	return states
+}
+
 //Set the current state based on evaluation results
 func (st *Manager) setNextState(alertRule *ngModels.AlertRule, result eval.Result) *State {
 	currentState := st.getOrCreate(alertRule, result)
-
-	currentState.LastEvaluationTime = result.EvaluatedAt
-	currentState.EvaluationDuration = result.EvaluationDuration
-	currentState.Results = append(currentState.Results, Evaluation{
-		EvaluationTime:   result.EvaluatedAt,
-		EvaluationState:  result.State,
-		EvaluationString: result.EvaluationString,
-		Values:           NewEvaluationValues(result.Values),
-	})
-	currentState.TrimResults(alertRule)
 	oldState := currentState.State
 
 	st.log.Debug("setting alert state", "uid", alertRule.UID)
-	switch result.State {
-	case eval.Normal:
-		currentState.resultNormal(alertRule, result)
-	case eval.Alerting:
-		currentState.resultAlerting(alertRule, result)
-	case eval.Error:
-		currentState.resultError(alertRule, result)
-	case eval.NoData:
-		currentState.resultNoData(alertRule, result)
-	case eval.Pending: // we do not emit results with this state
-	}
+	currentState.applyResult(alertRule, result)
 
 	// Set Resolved property so the scheduler knows to send a postable alert
 	// to Alertmanager.
 	currentState.Resolved = oldState == eval.Alerting && currentState.State == eval.Normal
+	if st.correlator != nil {
+		st.correlator.observe(currentState, result.EvaluatedAt)
+	}
+
+	if currentState.State != eval.Alerting {
+		currentState.Screenshot = nil
+	} else if oldState != eval.Alerting {
+		currentState.Screenshot = st.takeScreenshot(alertRule)
+	}
 
 	st.set(currentState)
 	if oldState != currentState.State {
-		go st.createAlertAnnotation(currentState.State, alertRule, result, oldState)
+		if st.annotations != nil && writesAnnotations(alertRule) {
+			go st.createAlertAnnotation(currentState.State, alertRule, result, oldState)
+		}
+		st.publishStateChange(alertRule, currentState, result, oldState)
 	}
 	return currentState
 }
 
+// publishStateChange publishes the change of the state of the alert, for the clients streaming the state of the
+// alerts in real time.
+func (st *Manager) publishStateChange(alertRule *ngModels.AlertRule, currentState *State, result eval.Result, oldState eval.State) {
+	err := bus.Publish(&events.AlertStateChanged{
+		Timestamp:     result.EvaluatedAt,
+		OrgID:         alertRule.OrgID,
+		RuleUID:       alertRule.UID,
+		RuleTitle:     alertRule.Title,
+		NamespaceUID:  alertRule.NamespaceUID,
+		Labels:        currentState.Labels,
+		PreviousState: oldState.String(),
+		State:         currentState.State.String(),
+		Value:         result.EvaluationString,
+	})
+	if err != nil {
+		st.log.Error("failed to publish the change of the state of the alert", "alertRuleUID", alertRule.UID, "error", err)
+	}
+}
+
+// takeScreenshot returns the screenshot of the panel linked to the alert rule, or nil if the rule
+// isn't linked to a panel or the screenshot failed.
+func (st *Manager) takeScreenshot(alertRule *ngModels.AlertRule) *screenshot.Screenshot {
+	if st.screenshots == nil {
+		return nil
+	}
+
+	dashUID, ok := alertRule.GetAnnotation(ngModels.DashboardUIDAnnotation)
+	if !ok {
+		return nil
+	}
+	panelUID, _ := alertRule.GetAnnotation(ngModels.PanelIDAnnotation)
+	panelID, err := strconv.ParseInt(panelUID, 10, 64)
+	if err != nil {
+		st.log.Error("error parsing panelID for alert screenshot", "panelID", panelUID, "alertRuleUID", alertRule.UID, "error", err.Error())
+		return nil
+	}
+
+	s, err := st.screenshots.Take(context.Background(), screenshot.ScreenshotOptions{
+		OrgID:        alertRule.OrgID,
+		DashboardUID: dashUID,
+		PanelID:      panelID,
+	})
+	if err != nil {
+		st.log.Warn("failed to take screenshot for alert", "dashboardUID", dashUID, "panelID", panelID, "alertRuleUID", alertRule.UID, "error", err.Error())
+		return nil
+	}
+	return s
+}
+
 func (st *Manager) GetAll(orgID int64) []*State {
 	return st.cache.getAll(orgID)
 }
@@ -196,6 +278,20 @@ func (st *Manager) GetStatesForRuleUID(orgID int64, alertRuleUID string) []*Stat
 	return st.cache.getStatesForRuleUID(orgID, alertRuleUID)
 }
 
+// Incidents returns the open incidents of the organization, the latest first. It returns nil when the alerts aren't
+// correlated.
+func (st *Manager) Incidents(orgID int64) []Incident {
+	if st.correlator == nil {
+		return nil
+	}
+	return st.correlator.Incidents(orgID, time.Now())
+}
+
+// CountOtherRules returns the number of alert instances of the organization, except those of the rule.
+func (st *Manager) CountOtherRules(orgID int64, alertRuleUID string) int {
+	return st.cache.countOtherRules(orgID, alertRuleUID)
+}
+
 func (st *Manager) recordMetrics() {
 	// TODO: parameterize?
 	// Setting to a reasonable default scrape interval for Prometheus.
@@ -233,46 +329,8 @@ func translateInstanceState(state ngModels.InstanceStateType) eval.State {
 
 func (st *Manager) createAlertAnnotation(new eval.State, alertRule *ngModels.AlertRule, result eval.Result, oldState eval.State) {
 	st.log.Debug("alert state changed creating annotation", "alertRuleUID", alertRule.UID, "newState", new.String())
-	dashUid, ok := alertRule.Annotations["__dashboardUid__"]
-	if !ok {
-		return
-	}
-
-	panelUid := alertRule.Annotations["__panelId__"]
-
-	panelId, err := strconv.ParseInt(panelUid, 10, 64)
-	if err != nil {
-		st.log.Error("error parsing panelUID for alert annotation", "panelUID", panelUid, "alertRuleUID", alertRule.UID, "error", err.Error())
-		return
-	}
-
-	query := &models.GetDashboardQuery{
-		Uid:   dashUid,
-		OrgId: alertRule.OrgID,
-	}
-
-	err = sqlstore.GetDashboard(query)
-	if err != nil {
-		st.log.Error("error getting dashboard for alert annotation", "dashboardUID", dashUid, "alertRuleUID", alertRule.UID, "error", err.Error())
-		return
-	}
-
-	annotationText := fmt.Sprintf("%s {%s} - %s", alertRule.Title, result.Instance.String(), new.String())
-
-	item := &annotations.Item{
-		OrgId:       alertRule.OrgID,
-		DashboardId: query.Result.Id,
-		PanelId:     panelId,
-		PrevState:   oldState.String(),
-		NewState:    new.String(),
-		Text:        annotationText,
-		Epoch:       result.EvaluatedAt.UnixNano() / int64(time.Millisecond),
-	}
-
-	annotationRepo := annotations.GetRepository()
-	if err = annotationRepo.Save(item); err != nil {
+	if err := st.annotations.WriteStateChange(alertRule, result, oldState, new); err != nil {
 		st.log.Error("error saving alert annotation", "alertRuleUID", alertRule.UID, "error", err.Error())
-		return
 	}
 }
 
@@ -283,6 +341,9 @@ func (st *Manager) staleResultsHandler(alertRule *ngModels.AlertRule, states map
 		if !ok && isItStale(s.LastEvaluationTime, alertRule.IntervalSeconds) {
 			st.log.Debug("removing stale state entry", "orgID", s.OrgID, "alertRuleUID", s.AlertRuleUID, "cacheID", s.CacheId)
 			st.cache.deleteEntry(s.OrgID, s.AlertRuleUID, s.CacheId)
+			if st.correlator != nil {
+				st.correlator.remove(s)
+			}
 			ilbs := ngModels.InstanceLabels(s.Labels)
 			_, labelsHash, err := ilbs.StringAndHash()
 			if err != nil {
@@ -299,3 +360,26 @@ func (st *Manager) staleResultsHandler(alertRule *ngModels.AlertRule, states map
 func isItStale(lastEval time.Time, intervalSeconds int64) bool {
 	return lastEval.Add(2 * time.Duration(intervalSeconds) * time.Second).Before(time.Now())
 }
+
+// GetNonZeroValueLabels returns the labels of the values of the query or expression refID that were not zero at the
+// last evaluation of the alerts of the rule, such as the numbers that passed a threshold expression.
+func (st *Manager) GetNonZeroValueLabels(orgID int64, alertRuleUID, refID string) []data.Labels {
+	seen := make(map[string]struct{})
+	result := make([]data.Labels, 0)
+	for _, s := range st.GetStatesForRuleUID(orgID, alertRuleUID) {
+		if len(s.Results) == 0 {
+			continue
+		}
+		v, ok := s.Results[len(s.Results)-1].Values[refID]
+		if !ok || v.Value == nil || *v.Value == 0 {
+			continue
+		}
+		key := v.Labels.String()
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		result = append(result, v.Labels)
+	}
+	return result
+}
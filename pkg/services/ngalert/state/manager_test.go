@@ -1,6 +1,8 @@
 package state_test
 
 import (
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
@@ -11,6 +13,8 @@ import (
 	"github.com/grafana/grafana/pkg/services/ngalert/metrics"
 	"github.com/grafana/grafana/pkg/services/ngalert/state"
 
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/log"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
@@ -853,7 +857,7 @@ func TestProcessEvalResults(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		st := state.NewManager(log.New("test_state_manager"), nilMetrics, nil, nil)
+		st := state.NewManager(log.New("test_state_manager"), nilMetrics, nil, nil, nil, nil, nil)
 		t.Run(tc.desc, func(t *testing.T) {
 			for _, res := range tc.evalResults {
 				_ = st.ProcessEvalResults(tc.alertRule, res)
@@ -948,7 +952,7 @@ func TestStaleResultsHandler(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		st := state.NewManager(log.New("test_stale_results_handler"), nilMetrics, dbstore, dbstore)
+		st := state.NewManager(log.New("test_stale_results_handler"), nilMetrics, dbstore, dbstore, nil, nil, nil)
 		st.Warm()
 		existingStatesForRule := st.GetStatesForRuleUID(rule.OrgID, rule.UID)
 
@@ -968,3 +972,86 @@ func TestStaleResultsHandler(t *testing.T) {
 		assert.Equal(t, tc.finalStateCount, len(existingStatesForRule))
 	}
 }
+
+func TestGetNonZeroValueLabels(t *testing.T) {
+	st := state.NewManager(log.New("test_non_zero_value_labels"), nilMetrics, nil, nil, nil, nil, nil)
+	rule := &models.AlertRule{OrgID: 1, UID: "rule", Title: "rule", NamespaceUID: "namespace", IntervalSeconds: 10}
+	value := func(v float64) *float64 { return &v }
+	result := func(instance string, c float64) eval.Result {
+		labels := data.Labels{"instance": instance}
+		return eval.Result{
+			Instance:    labels,
+			State:       eval.Normal,
+			EvaluatedAt: time.Now(),
+			Values:      map[string]eval.NumberValueCapture{"C": {Var: "C", Labels: labels, Value: value(c)}},
+		}
+	}
+
+	st.ProcessEvalResults(rule, eval.Results{result("a", 1), result("b", 1)})
+	st.ProcessEvalResults(rule, eval.Results{result("a", 1), result("b", 0)})
+
+	require.Equal(t, []data.Labels{{"instance": "a"}}, st.GetNonZeroValueLabels(1, "rule", "C"))
+	require.Empty(t, st.GetNonZeroValueLabels(1, "rule", "B"))
+	require.Empty(t, st.GetNonZeroValueLabels(1, "other", "C"))
+}
+
+func TestProcessEvalResultsPublishesStateChanges(t *testing.T) {
+	t.Cleanup(bus.ClearBusHandlers)
+	var changes []*events.AlertStateChanged
+	bus.AddEventListener(func(e *events.AlertStateChanged) error {
+		changes = append(changes, e)
+		return nil
+	})
+
+	st := state.NewManager(log.New("test_state_changes"), nilMetrics, nil, nil, nil, nil, nil)
+	rule := &models.AlertRule{OrgID: 1, UID: "rule", Title: "rule", NamespaceUID: "namespace", IntervalSeconds: 10}
+	result := func(s eval.State) eval.Result {
+		return eval.Result{Instance: data.Labels{"instance": "a"}, State: s, EvaluatedAt: time.Now(), EvaluationString: "[ var='A' value=1 ]"}
+	}
+
+	st.ProcessEvalResults(rule, eval.Results{result(eval.Normal)})
+	require.Empty(t, changes)
+	st.ProcessEvalResults(rule, eval.Results{result(eval.Alerting)})
+	st.ProcessEvalResults(rule, eval.Results{result(eval.Alerting)})
+	require.Len(t, changes, 1)
+	require.Equal(t, "Normal", changes[0].PreviousState)
+	require.Equal(t, "Alerting", changes[0].State)
+	require.Equal(t, "rule", changes[0].RuleUID)
+	require.Equal(t, "namespace", changes[0].NamespaceUID)
+	require.Equal(t, "a", changes[0].Labels["instance"])
+	require.Equal(t, "[ var='A' value=1 ]", changes[0].Value)
+}
+
+type fakeAnnotationWriter struct {
+	mtx     sync.Mutex
+	changes []string
+}
+
+func (w *fakeAnnotationWriter) WriteStateChange(alertRule *models.AlertRule, _ eval.Result, oldState, newState eval.State) error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	w.changes = append(w.changes, fmt.Sprintf("%s: %s -> %s", alertRule.UID, oldState, newState))
+	return nil
+}
+
+func (w *fakeAnnotationWriter) get() []string {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	return append([]string(nil), w.changes...)
+}
+
+func TestProcessEvalResultsWritesAnnotations(t *testing.T) {
+	require.Nil(t, state.NewAnnotationWriter("disabled", nil))
+
+	writer := &fakeAnnotationWriter{}
+	st := state.NewManager(log.New("test_state_annotations"), nilMetrics, nil, nil, nil, writer, nil)
+	result := eval.Result{Instance: data.Labels{"instance": "a"}, State: eval.Alerting, EvaluatedAt: time.Now()}
+
+	optedOut := &models.AlertRule{OrgID: 1, UID: "opted-out", Title: "opted out", IntervalSeconds: 10, Labels: map[string]string{models.StateAnnotationsLabel: "false"}}
+	st.ProcessEvalResults(optedOut, eval.Results{result})
+	rule := &models.AlertRule{OrgID: 1, UID: "rule", Title: "rule", IntervalSeconds: 10}
+	st.ProcessEvalResults(rule, eval.Results{result})
+
+	require.Eventually(t, func() bool { return len(writer.get()) > 0 }, time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{"rule: Normal -> Alerting"}, writer.get())
+}
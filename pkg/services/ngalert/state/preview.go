@@ -0,0 +1,42 @@
+package state
+
+import (
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// Preview computes the states of the alerts of a rule over successive evaluations, with their labels and annotations
+// rendered, without the states of the Manager, so a rule can be tested before it is saved.
+type Preview struct {
+	cache *cache
+}
+
+func NewPreview(logger log.Logger) *Preview {
+	return &Preview{cache: newCache(logger, nil)}
+}
+
+// ProcessEvalResults returns the states of the alerts of the rule after the evaluation results. The alerts that are
+// not in the results are removed, they are resolved.
+func (p *Preview) ProcessEvalResults(alertRule *ngModels.AlertRule, results eval.Results) []*State {
+	states := make([]*State, 0, len(results))
+	current := make(map[string]struct{}, len(results))
+	for _, result := range results {
+		s := p.cache.getOrCreate(alertRule, result)
+		s.applyResult(alertRule, result)
+		states = append(states, s)
+		current[s.CacheId] = struct{}{}
+	}
+	for _, s := range p.cache.getStatesForRuleUID(alertRule.OrgID, alertRule.UID) {
+		if _, ok := current[s.CacheId]; !ok {
+			p.cache.deleteEntry(s.OrgID, s.AlertRuleUID, s.CacheId)
+		}
+	}
+	return states
+}
+
+// PreviewResults returns the states of the alerts of the rule after the evaluation results, as if the rule was
+// evaluated for the first time.
+func PreviewResults(logger log.Logger, alertRule *ngModels.AlertRule, results eval.Results) []*State {
+	return NewPreview(logger).ProcessEvalResults(alertRule, results)
+}
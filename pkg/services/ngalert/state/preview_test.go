@@ -0,0 +1,64 @@
+package state_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ngalert/eval"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/state"
+)
+
+func TestPreviewResults(t *testing.T) {
+	now := time.Now()
+	rule := &models.AlertRule{
+		OrgID:           1,
+		Title:           "disk",
+		NamespaceUID:    "namespace",
+		For:             time.Minute,
+		IntervalSeconds: 10,
+		NoDataState:     models.Alerting,
+		Labels:          map[string]string{"team": "{{ $labels.instance }}-team"},
+		Annotations:     map[string]string{"summary": "{{ $labels.instance }} is full"},
+	}
+	results := eval.Results{
+		{Instance: data.Labels{"instance": "a"}, State: eval.Alerting, EvaluatedAt: now},
+		{Instance: data.Labels{"instance": "b"}, State: eval.Normal, EvaluatedAt: now},
+		{Instance: data.Labels{"instance": "c"}, State: eval.NoData, EvaluatedAt: now},
+	}
+
+	states := state.PreviewResults(log.New("test_preview_results"), rule, results)
+	require.Len(t, states, 3)
+	require.Equal(t, eval.Pending, states[0].State, "the rule is pending for its duration")
+	require.Equal(t, "a-team", states[0].Labels["team"])
+	require.Equal(t, "disk", states[0].Labels["alertname"])
+	require.Equal(t, "a is full", states[0].Annotations["summary"])
+	require.Equal(t, eval.Normal, states[1].State)
+	require.Equal(t, eval.Alerting, states[2].State, "the no data state of the rule is applied")
+}
+
+func TestPreview(t *testing.T) {
+	now := time.Now()
+	rule := &models.AlertRule{OrgID: 1, UID: "rule", Title: "disk", For: 15 * time.Second, IntervalSeconds: 10}
+	evaluate := func(p *state.Preview, at time.Time, instances ...string) map[string]eval.State {
+		results := make(eval.Results, 0, len(instances))
+		for _, instance := range instances {
+			results = append(results, eval.Result{Instance: data.Labels{"instance": instance}, State: eval.Alerting, EvaluatedAt: at})
+		}
+		states := map[string]eval.State{}
+		for _, s := range p.ProcessEvalResults(rule, results) {
+			states[s.Labels["instance"]] = s.State
+		}
+		return states
+	}
+
+	p := state.NewPreview(log.New("test_preview"))
+	require.Equal(t, map[string]eval.State{"a": eval.Pending}, evaluate(p, now, "a"))
+	require.Equal(t, map[string]eval.State{"a": eval.Pending, "b": eval.Pending}, evaluate(p, now.Add(10*time.Second), "a", "b"))
+	require.Equal(t, map[string]eval.State{"a": eval.Alerting}, evaluate(p, now.Add(20*time.Second), "a"))
+	require.Equal(t, map[string]eval.State{"a": eval.Alerting, "b": eval.Pending}, evaluate(p, now.Add(30*time.Second), "a", "b"), "the alerts not in the results are resolved")
+}
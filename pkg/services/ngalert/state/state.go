@@ -6,6 +6,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/screenshot"
 )
 
 type State struct {
@@ -23,6 +24,12 @@ type State struct {
 	Annotations        map[string]string
 	Labels             data.Labels
 	Error              error
+	// Warnings are the warnings of the last evaluation, such as the truncation of query results.
+	Warnings []string
+	// Screenshot of the rule's panel taken when the alert started firing.
+	Screenshot *screenshot.Screenshot
+	// IncidentID is the identifier of the incident the alert is correlated into, empty if it isn't.
+	IncidentID string
 }
 
 type Evaluation struct {
@@ -53,6 +60,32 @@ func NewEvaluationValues(m map[string]eval.NumberValueCapture) map[string]Evalua
 	return result
 }
 
+// applyResult sets the state of the alert from the result of an evaluation of the rule.
+func (a *State) applyResult(alertRule *ngModels.AlertRule, result eval.Result) {
+	a.LastEvaluationTime = result.EvaluatedAt
+	a.EvaluationDuration = result.EvaluationDuration
+	a.Warnings = result.Warnings
+	a.Results = append(a.Results, Evaluation{
+		EvaluationTime:   result.EvaluatedAt,
+		EvaluationState:  result.State,
+		EvaluationString: result.EvaluationString,
+		Values:           NewEvaluationValues(result.Values),
+	})
+	a.TrimResults(alertRule)
+
+	switch result.State {
+	case eval.Normal:
+		a.resultNormal(alertRule, result)
+	case eval.Alerting:
+		a.resultAlerting(alertRule, result)
+	case eval.Error:
+		a.resultError(alertRule, result)
+	case eval.NoData:
+		a.resultNoData(alertRule, result)
+	case eval.Pending: // we do not emit results with this state
+	}
+}
+
 func (a *State) resultNormal(alertRule *ngModels.AlertRule, result eval.Result) {
 	if a.State != eval.Normal {
 		a.EndsAt = result.EvaluatedAt
@@ -0,0 +1,179 @@
+package state
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"text/template/parse"
+
+	prometheusModel "github.com/prometheus/common/model"
+
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// TemplateIssue is an error or a warning of the template of a label or annotation of a rule.
+type TemplateIssue struct {
+	// Kind is label or annotation.
+	Kind    string
+	Name    string
+	Message string
+}
+
+// TemplateValidation is the result of the validation of the templates of a rule. The templates with errors fail
+// for every alert, the templates with warnings can fail for some alerts.
+type TemplateValidation struct {
+	Errors   []TemplateIssue
+	Warnings []TemplateIssue
+}
+
+// templateCaptureFields are the fields of the values of $values.
+var templateCaptureFields = map[string]struct{}{"Labels": {}, "Value": {}, "Samples": {}}
+
+// ValidateTemplates parses the templates of the labels and annotations of the rule, checks the variables they use
+// against the queries and expressions of the rule, and executes them with placeholder values.
+func ValidateTemplates(alertRule *ngModels.AlertRule) TemplateValidation {
+	var result TemplateValidation
+	validate := func(kind string, templates map[string]string) {
+		names := make([]string, 0, len(templates))
+		for name := range templates {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			errs, warnings := validateTemplate(alertRule, templates[name])
+			for _, msg := range errs {
+				result.Errors = append(result.Errors, TemplateIssue{Kind: kind, Name: name, Message: msg})
+			}
+			for _, msg := range warnings {
+				result.Warnings = append(result.Warnings, TemplateIssue{Kind: kind, Name: name, Message: msg})
+			}
+		}
+	}
+	validate("label", alertRule.Labels)
+	validate("annotation", alertRule.Annotations)
+	return result
+}
+
+func validateTemplate(alertRule *ngModels.AlertRule, text string) (errs []string, warnings []string) {
+	tmpl, err := parseTemplate(alertRule.Title, text)
+	if err != nil {
+		return []string{err.Error()}, nil
+	}
+
+	queries := make(map[string]bool, len(alertRule.Data))
+	for _, q := range alertRule.Data {
+		isExpression, _ := q.IsExpression()
+		queries[q.RefID] = isExpression
+	}
+	// The labels of the templates are the labels of the series, with the labels Grafana adds for the rule.
+	data := templateData{
+		Labels: map[string]string{
+			prometheusModel.AlertNameLabel: alertRule.Title,
+			ngModels.RuleUIDLabel:          alertRule.UID,
+			ngModels.NamespaceUIDLabel:     alertRule.NamespaceUID,
+		},
+		Values: make(map[string]templateCaptureValue, len(queries)),
+	}
+	zero := 0.0
+	for refID := range queries {
+		data.Values[refID] = templateCaptureValue{Labels: map[string]string{}, Value: &zero}
+	}
+
+	var series []string
+	// A variable can be used several times in a template.
+	seen := map[string]struct{}{}
+	add := func(issues *[]string, msg string) {
+		if _, ok := seen[msg]; !ok {
+			seen[msg] = struct{}{}
+			*issues = append(*issues, msg)
+		}
+	}
+	walkTemplateVariables(tmpl.Tree.Root, func(ident []string) {
+		if len(ident) < 2 {
+			return
+		}
+		switch ident[0] {
+		case "$labels":
+			name := ident[1]
+			if _, ok := data.Labels[name]; ok {
+				return
+			}
+			if _, ok := alertRule.Labels[name]; ok {
+				add(&warnings, fmt.Sprintf("$labels.%s is a label of the rule, $labels only has the labels of the series", name))
+			} else {
+				add(&warnings, fmt.Sprintf("$labels.%s fails for the series without the label %s, use index $labels %q for an optional label", name, name, name))
+			}
+			series = append(series, name)
+		case "$values":
+			refID := ident[1]
+			isExpression, ok := queries[refID]
+			if !ok {
+				add(&errs, fmt.Sprintf("$values.%s is not a query or expression of the rule", refID))
+				return
+			}
+			if !isExpression {
+				add(&warnings, fmt.Sprintf("$values.%s is a data source query, it only has a value when the query returns numbers", refID))
+			}
+			if len(ident) > 2 {
+				if _, ok := templateCaptureFields[ident[2]]; !ok {
+					add(&errs, fmt.Sprintf("$values.%s has no field %s, it has Labels, Value and Samples", refID, ident[2]))
+				}
+			}
+		}
+	})
+	if len(errs) > 0 {
+		return errs, warnings
+	}
+
+	// The labels the template uses are added so that only the other errors of the execution are reported.
+	for _, name := range series {
+		data.Labels[name] = ""
+	}
+	if err := tmpl.Execute(ioutil.Discard, data); err != nil {
+		errs = append(errs, fmt.Sprintf("error executing template %v: %s", tmpl.Name(), err.Error()))
+	}
+	return errs, warnings
+}
+
+// walkTemplateVariables calls f with the identifiers of each variable of the template, such as $labels and instance
+// for $labels.instance.
+func walkTemplateVariables(node parse.Node, f func(ident []string)) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			walkTemplateVariables(c, f)
+		}
+	case *parse.ActionNode:
+		walkTemplateVariables(n.Pipe, f)
+	case *parse.IfNode:
+		walkTemplateVariables(&n.BranchNode, f)
+	case *parse.RangeNode:
+		walkTemplateVariables(&n.BranchNode, f)
+	case *parse.WithNode:
+		walkTemplateVariables(&n.BranchNode, f)
+	case *parse.BranchNode:
+		walkTemplateVariables(n.Pipe, f)
+		walkTemplateVariables(n.List, f)
+		walkTemplateVariables(n.ElseList, f)
+	case *parse.TemplateNode:
+		walkTemplateVariables(n.Pipe, f)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Cmds {
+			walkTemplateVariables(c, f)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			walkTemplateVariables(arg, f)
+		}
+	case *parse.ChainNode:
+		walkTemplateVariables(n.Node, f)
+	case *parse.VariableNode:
+		f(n.Ident)
+	}
+}
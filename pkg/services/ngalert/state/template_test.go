@@ -0,0 +1,82 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr"
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+func TestValidateTemplates(t *testing.T) {
+	rule := &ngModels.AlertRule{
+		Title: "disk",
+		Data: []ngModels.AlertQuery{
+			{RefID: "A", DatasourceUID: "prometheus"},
+			{RefID: "B", DatasourceUID: expr.DatasourceUID},
+		},
+		Labels: map[string]string{"severity": "critical"},
+	}
+
+	cases := []struct {
+		name     string
+		text     string
+		errors   int
+		warnings []string
+	}{{
+		name: "valid template",
+		text: "{{ $labels.alertname }} is {{ $values.B }} ({{ $value }})",
+	}, {
+		name:   "template that doesn't parse",
+		text:   "{{ $labels.instance ",
+		errors: 1,
+	}, {
+		name:   "unknown function",
+		text:   "{{ humanize1024 $values.B.Value }}",
+		errors: 1,
+	}, {
+		name: "functions of the registry",
+		text: "{{ humanize $values.B.Value }}",
+	}, {
+		name:   "unknown query",
+		text:   "{{ $values.C }}",
+		errors: 1,
+	}, {
+		name:   "unknown field of a value",
+		text:   "{{ $values.B.Val }}",
+		errors: 1,
+	}, {
+		name:   "comparison of the pointer of a value",
+		text:   "{{ if gt $values.B.Value 80.0 }}high{{ end }}",
+		errors: 1,
+	}, {
+		name:     "label of the series",
+		text:     "{{ $labels.instance }} {{ $labels.instance }}",
+		warnings: []string{`$labels.instance fails for the series without the label instance, use index $labels "instance" for an optional label`},
+	}, {
+		name:     "label of the rule",
+		text:     "{{ $labels.severity }}",
+		warnings: []string{"$labels.severity is a label of the rule, $labels only has the labels of the series"},
+	}, {
+		name:     "value of a data source query",
+		text:     "{{ range $k, $v := $values }}{{ $k }}{{ end }}{{ $values.A.Value }}",
+		warnings: []string{"$values.A is a data source query, it only has a value when the query returns numbers"},
+	}}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := *rule
+			r.Annotations = map[string]string{"summary": c.text}
+			result := ValidateTemplates(&r)
+			require.Len(t, result.Errors, c.errors)
+			var warnings []string
+			for _, w := range result.Warnings {
+				require.Equal(t, "annotation", w.Kind)
+				require.Equal(t, "summary", w.Name)
+				warnings = append(warnings, w.Message)
+			}
+			require.Equal(t, c.warnings, warnings)
+		})
+	}
+}
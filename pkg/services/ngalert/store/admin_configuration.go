@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/grafana/grafana/pkg/models"
 	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 )
@@ -22,6 +23,7 @@ type AdminConfigurationStore interface {
 	GetAdminConfigurations() ([]*ngmodels.AdminConfiguration, error)
 	DeleteAdminConfiguration(orgID int64) error
 	UpdateAdminConfiguration(UpdateAdminConfigurationCmd) error
+	GetAlertmanagerDatasources() ([]*models.DataSource, error)
 }
 
 func (st *DBstore) GetAdminConfiguration(orgID int64) (*ngmodels.AdminConfiguration, error) {
@@ -63,6 +65,19 @@ func (st DBstore) GetAdminConfigurations() ([]*ngmodels.AdminConfiguration, erro
 	return cfg, nil
 }
 
+// GetAlertmanagerDatasources returns the Alertmanager datasources of every organization.
+func (st DBstore) GetAlertmanagerDatasources() ([]*models.DataSource, error) {
+	var dss []*models.DataSource
+	err := st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		return sess.Where("type = ?", models.DS_ALERTMANAGER).Asc("id").Find(&dss)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dss, nil
+}
+
 func (st DBstore) DeleteAdminConfiguration(orgID int64) error {
 	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
 		_, err := sess.Exec("DELETE FROM ngalert_configuration WHERE org_id = ?", orgID)
@@ -29,29 +29,79 @@ type UpdateRuleGroupCmd struct {
 	OrgID           int64
 	NamespaceUID    string
 	RuleGroupConfig apimodels.PostableRuleGroupConfig
+	// CreateWithUID creates the rules with an unknown UID instead of failing, so provisioned rules keep their UID.
+	CreateWithUID bool
+	// UpdatedBy is recorded as the creator of the new versions of the rules.
+	UpdatedBy string
+	// UpdatedByUserID becomes the owner of the rules created or whose queries changed, 0 for the service identity.
+	UpdatedByUserID int64
+	// Check is called in the transaction with the rules of the group before they are replaced, the group is not
+	// updated if it returns an error.
+	Check func(existing []*ngmodels.AlertRule) error
 }
 
 type UpsertRule struct {
-	Existing *ngmodels.AlertRule
-	New      ngmodels.AlertRule
+	Existing      *ngmodels.AlertRule
+	New           ngmodels.AlertRule
+	CreateWithUID bool
+	// UpdatedBy is recorded as the creator of the new version of the rule.
+	UpdatedBy string
+	// UpdatedByUserID becomes the owner of the rule if it is created or its queries changed, 0 for the service
+	// identity.
+	UpdatedByUserID int64
+	// RestoredFrom is the version of the rule restored by the update, if any.
+	RestoredFrom int64
+	// ExpectedVersion is the version of the rule the update was made from, if any. The update fails with
+	// ngmodels.ErrAlertRuleVersionConflict if the rule has been changed since.
+	ExpectedVersion int64
+	// Move saves the rule in the folder and the rule group of New, instead of those of Existing.
+	Move bool
+}
+
+// MoveAlertRulesCmd moves alert rules to a rule group, of the same or another folder.
+type MoveAlertRulesCmd struct {
+	OrgID int64
+	// Rules are moved only if they weren't changed since they were read.
+	Rules        []*ngmodels.AlertRule
+	NamespaceUID string
+	RuleGroup    string
+	// NewGroup fails the move with ngmodels.ErrRuleGroupExists if the rule group already has rules.
+	NewGroup bool
+	// Copy creates the rules in the rule group instead of moving them, with the UIDs of the rules.
+	Copy bool
+	// UpdatedBy is recorded as the creator of the new versions of the rules.
+	UpdatedBy string
+	// UpdatedByUserID becomes the owner of the copies of the rules, 0 for the service identity.
+	UpdatedByUserID int64
 }
 
 // Store is the interface for persisting alert rules and instances
 type RuleStore interface {
 	DeleteAlertRuleByUID(orgID int64, ruleUID string) error
+	DeleteAlertRulesByUID(orgID int64, ruleUIDs ...string) error
 	DeleteNamespaceAlertRules(orgID int64, namespaceUID string) ([]string, error)
 	DeleteRuleGroupAlertRules(orgID int64, namespaceUID string, ruleGroup string) ([]string, error)
 	DeleteAlertInstancesByRuleUID(orgID int64, ruleUID string) error
 	GetAlertRuleByUID(*ngmodels.GetAlertRuleByUIDQuery) error
+	GetAlertRuleVersions(*ngmodels.ListAlertRuleVersionsQuery) error
+	GetAlertRuleVersion(*ngmodels.GetAlertRuleVersionQuery) error
 	GetAlertRulesForScheduling(query *ngmodels.ListAlertRulesQuery) error
+	GetAlertRulesByIDs(query *ngmodels.ListAlertRulesByIDsQuery) error
+	GetAlertRulesWatermark(query *ngmodels.GetAlertRulesWatermarkQuery) error
+	SearchAlertRules(query *ngmodels.SearchAlertRulesQuery) error
 	GetOrgAlertRules(query *ngmodels.ListAlertRulesQuery) error
 	GetNamespaceAlertRules(query *ngmodels.ListNamespaceAlertRulesQuery) error
 	GetRuleGroupAlertRules(query *ngmodels.ListRuleGroupAlertRulesQuery) error
 	GetNamespaces(int64, *models.SignedInUser) (map[string]*models.Folder, error)
 	GetNamespaceByTitle(string, int64, *models.SignedInUser, bool) (*models.Folder, error)
+	GetNamespaceByUID(string, int64, *models.SignedInUser, bool) (*models.Folder, error)
+	CreateNamespace(title, uid string, orgID int64, user *models.SignedInUser) (*models.Folder, error)
 	GetOrgRuleGroups(query *ngmodels.ListOrgRuleGroupsQuery) error
+	CountAlertRules() ([]*ngmodels.AlertRuleCount, error)
 	UpsertAlertRules([]UpsertRule) error
+	MoveAlertRules(MoveAlertRulesCmd) error
 	UpdateRuleGroup(UpdateRuleGroupCmd) error
+	UpdateRuleGroups([]UpdateRuleGroupCmd) error
 }
 
 func getAlertRuleByUID(sess *sqlstore.DBSession, alertRuleUID string, orgID int64) (*ngmodels.AlertRule, error) {
@@ -67,28 +117,75 @@ func getAlertRuleByUID(sess *sqlstore.DBSession, alertRuleUID string, orgID int6
 	return &alertRule, nil
 }
 
-// DeleteAlertRuleByUID is a handler for deleting an alert rule.
-func (st DBstore) DeleteAlertRuleByUID(orgID int64, ruleUID string) error {
-	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
-		_, err := sess.Exec("DELETE FROM alert_rule WHERE org_id = ? AND uid = ?", orgID, ruleUID)
-		if err != nil {
+// GetAlertRuleVersions is a handler for retrieving the versions of an alert rule, the latest first.
+func (st DBstore) GetAlertRuleVersions(query *ngmodels.ListAlertRuleVersionsQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		versions := make([]*ngmodels.AlertRuleVersion, 0)
+		if err := sess.Where("rule_org_id = ? AND rule_uid = ?", query.OrgID, query.RuleUID).Desc("version").Find(&versions); err != nil {
 			return err
 		}
+		query.Result = versions
+		return nil
+	})
+}
 
-		_, err = sess.Exec("DELETE FROM alert_rule_version WHERE rule_org_id = ? and rule_uid = ?", orgID, ruleUID)
-
+// GetAlertRuleVersion is a handler for retrieving a version of an alert rule.
+func (st DBstore) GetAlertRuleVersion(query *ngmodels.GetAlertRuleVersionQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		version := ngmodels.AlertRuleVersion{RuleOrgID: query.OrgID, RuleUID: query.RuleUID, Version: query.Version}
+		has, err := sess.Get(&version)
 		if err != nil {
 			return err
 		}
+		if !has {
+			return ngmodels.ErrAlertRuleVersionNotFound
+		}
+		query.Result = &version
+		return nil
+	})
+}
 
-		_, err = sess.Exec("DELETE FROM alert_instance WHERE rule_org_id = ? AND rule_uid = ?", orgID, ruleUID)
-		if err != nil {
-			return err
+// DeleteAlertRuleByUID is a handler for deleting an alert rule.
+func (st DBstore) DeleteAlertRuleByUID(orgID int64, ruleUID string) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		return deleteAlertRuleByUID(sess, orgID, ruleUID)
+	})
+}
+
+// DeleteAlertRulesByUID is a handler for deleting several alert rules in a single transaction.
+func (st DBstore) DeleteAlertRulesByUID(orgID int64, ruleUIDs ...string) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		for _, uid := range ruleUIDs {
+			if err := deleteAlertRuleByUID(sess, orgID, uid); err != nil {
+				return err
+			}
 		}
 		return nil
 	})
 }
 
+func deleteAlertRuleByUID(sess *sqlstore.DBSession, orgID int64, ruleUID string) error {
+	_, err := sess.Exec("DELETE FROM alert_rule WHERE org_id = ? AND uid = ?", orgID, ruleUID)
+	if err != nil {
+		return err
+	}
+
+	_, err = sess.Exec("DELETE FROM alert_rule_version WHERE rule_org_id = ? and rule_uid = ?", orgID, ruleUID)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = sess.Exec("DELETE FROM alert_instance WHERE rule_org_id = ? AND rule_uid = ?", orgID, ruleUID)
+	if err != nil {
+		return err
+	}
+	if err := deleteAlertRuleHeartbeat(sess, orgID, ruleUID); err != nil {
+		return err
+	}
+	return deleteRuleTemplateInstance(sess, orgID, ruleUID)
+}
+
 // DeleteNamespaceAlertRules is a handler for deleting namespace alert rules. A list of deleted rule UIDs are returned.
 func (st DBstore) DeleteNamespaceAlertRules(orgID int64, namespaceUID string) ([]string, error) {
 	ruleUIDs := []string{}
@@ -116,6 +213,12 @@ func (st DBstore) DeleteNamespaceAlertRules(orgID int64, namespaceUID string) ([
 			return err
 		}
 
+		if _, err := sess.Exec(`DELETE FROM alert_rule_heartbeat WHERE org_id = ? AND rule_uid NOT IN (
+			SELECT uid FROM alert_rule where org_id = ?
+		)`, orgID, orgID); err != nil {
+			return err
+		}
+
 		return nil
 	})
 	return ruleUIDs, err
@@ -153,6 +256,12 @@ func (st DBstore) DeleteRuleGroupAlertRules(orgID int64, namespaceUID string, ru
 			return err
 		}
 
+		if _, err := sess.Exec(`DELETE FROM alert_rule_heartbeat WHERE org_id = ? AND rule_uid NOT IN (
+			SELECT uid FROM alert_rule where org_id = ?
+		)`, orgID, orgID); err != nil {
+			return err
+		}
+
 		return nil
 	})
 
@@ -162,14 +271,15 @@ func (st DBstore) DeleteRuleGroupAlertRules(orgID int64, namespaceUID string, ru
 // DeleteAlertInstanceByRuleUID is a handler for deleting alert instances by alert rule UID when a rule has been updated
 func (st DBstore) DeleteAlertInstancesByRuleUID(orgID int64, ruleUID string) error {
 	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
-		_, err := sess.Exec("DELETE FROM alert_instance WHERE rule_org_id = ? AND rule_uid = ?", orgID, ruleUID)
-		if err != nil {
-			return err
-		}
-		return nil
+		return deleteAlertInstancesByRuleUID(sess, orgID, ruleUID)
 	})
 }
 
+func deleteAlertInstancesByRuleUID(sess *sqlstore.DBSession, orgID int64, ruleUID string) error {
+	_, err := sess.Exec("DELETE FROM alert_instance WHERE rule_org_id = ? AND rule_uid = ?", orgID, ruleUID)
+	return err
+}
+
 // GetAlertRuleByUID is a handler for retrieving an alert rule from that database by its UID and organisation ID.
 // It returns ngmodels.ErrAlertRuleNotFound if no alert rule is found for the provided ID.
 func (st DBstore) GetAlertRuleByUID(query *ngmodels.GetAlertRuleByUIDQuery) error {
@@ -178,6 +288,9 @@ func (st DBstore) GetAlertRuleByUID(query *ngmodels.GetAlertRuleByUIDQuery) erro
 		if err != nil {
 			return err
 		}
+		if err := st.applyFolderDefaults(sess, alertRule); err != nil {
+			return err
+		}
 		query.Result = alertRule
 		return nil
 	})
@@ -186,134 +299,302 @@ func (st DBstore) GetAlertRuleByUID(query *ngmodels.GetAlertRuleByUIDQuery) erro
 // UpsertAlertRules is a handler for creating/updating alert rules.
 func (st DBstore) UpsertAlertRules(rules []UpsertRule) error {
 	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
-		newRules := make([]ngmodels.AlertRule, 0, len(rules))
-		ruleVersions := make([]ngmodels.AlertRuleVersion, 0, len(rules))
-		for _, r := range rules {
-			if r.Existing == nil && r.New.UID != "" {
-				// check by UID
-				existingAlertRule, err := getAlertRuleByUID(sess, r.New.UID, r.New.OrgID)
-				if err != nil {
-					if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
-						return fmt.Errorf("failed to get alert rule %s: %w", r.New.UID, err)
-					}
-					return err
-				}
+		if err := st.upsertAlertRules(sess, rules); err != nil {
+			if st.SQLStore.Dialect.IsUniqueConstraintViolation(err) {
+				return ngmodels.ErrAlertRuleUniqueConstraintViolation
+			}
+			return err
+		}
+		return nil
+	})
+}
+
+// MoveAlertRules is a handler for moving or copying alert rules to another rule group, which can be in another
+// folder. The rules moved to an existing group get the interval of the group and are added after its rules.
+func (st DBstore) MoveAlertRules(cmd MoveAlertRulesCmd) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		q := &ngmodels.ListRuleGroupAlertRulesQuery{OrgID: cmd.OrgID, NamespaceUID: cmd.NamespaceUID, RuleGroup: cmd.RuleGroup}
+		if err := getRuleGroupAlertRules(sess, q); err != nil {
+			return err
+		}
+		if cmd.NewGroup && len(q.Result) > 0 {
+			return fmt.Errorf("%w: %q", ngmodels.ErrRuleGroupExists, cmd.RuleGroup)
+		}
+		var intervalSeconds, concurrency int64
+		index := 0
+		for _, r := range q.Result {
+			intervalSeconds = r.IntervalSeconds
+			concurrency = r.GroupConcurrency
+			if r.RuleGroupIndex > index {
+				index = r.RuleGroupIndex
+			}
+		}
+
+		upsertRules := make([]UpsertRule, 0, len(cmd.Rules))
+		for _, r := range cmd.Rules {
+			index++
+			moved := *r
+			moved.NamespaceUID = cmd.NamespaceUID
+			moved.RuleGroup = cmd.RuleGroup
+			moved.RuleGroupIndex = index
+			// the interval of the group is 0 when it's the interval of the folder
+			if len(q.Result) > 0 {
+				moved.IntervalSeconds = intervalSeconds
+				moved.GroupConcurrency = concurrency
+			}
+			if cmd.Copy {
+				moved.ID = 0
+				upsertRules = append(upsertRules, UpsertRule{New: moved, UpdatedBy: cmd.UpdatedBy, UpdatedByUserID: cmd.UpdatedByUserID, CreateWithUID: true})
+				continue
+			}
+			upsertRules = append(upsertRules, UpsertRule{Existing: r, New: moved, UpdatedBy: cmd.UpdatedBy, Move: true})
+		}
+		if err := st.upsertAlertRules(sess, upsertRules); err != nil {
+			if st.SQLStore.Dialect.IsUniqueConstraintViolation(err) {
+				return ngmodels.ErrAlertRuleUniqueConstraintViolation
+			}
+			return err
+		}
+		if cmd.Copy {
+			return nil
+		}
+
+		// The labels of the alerts include the folder, they're evaluated again.
+		for _, r := range cmd.Rules {
+			if err := deleteAlertInstancesByRuleUID(sess, cmd.OrgID, r.UID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (st DBstore) upsertAlertRules(sess *sqlstore.DBSession, rules []UpsertRule) error {
+	newRules := make([]ngmodels.AlertRule, 0, len(rules))
+	ruleVersions := make([]ngmodels.AlertRuleVersion, 0, len(rules))
+	// The rules are saved with their own settings only: the settings that are the same as the defaults of their folder
+	// are removed, so that the rules keep inheriting the defaults when they change.
+	folderDefaults := make(map[string]*ngmodels.FolderDefaults)
+	trimFolderDefaults := func(rule *ngmodels.AlertRule) (*ngmodels.FolderDefaults, error) {
+		d, ok := folderDefaults[rule.NamespaceUID]
+		if !ok {
+			var err error
+			if d, err = getFolderDefaults(sess, rule.OrgID, rule.NamespaceUID); err != nil {
+				return nil, fmt.Errorf("failed to get the defaults of folder %s: %w", rule.NamespaceUID, err)
+			}
+			folderDefaults[rule.NamespaceUID] = d
+		}
+		if d != nil {
+			d.Trim(rule)
+		}
+		return d, nil
+	}
+	withDefaults := func(rule ngmodels.AlertRule, d *ngmodels.FolderDefaults) ngmodels.AlertRule {
+		if d != nil {
+			d.Apply(&rule)
+		}
+		return rule
+	}
+	for _, r := range rules {
+		if r.Existing == nil && r.New.UID != "" {
+			// check by UID
+			existingAlertRule, err := getAlertRuleByUID(sess, r.New.UID, r.New.OrgID)
+			switch {
+			case err == nil:
 				r.Existing = existingAlertRule
+			case !errors.Is(err, ngmodels.ErrAlertRuleNotFound):
+				return err
+			case !r.CreateWithUID:
+				return fmt.Errorf("failed to get alert rule %s: %w", r.New.UID, err)
 			}
+		}
 
-			var parentVersion int64
-			switch r.Existing {
-			case nil: // new rule
+		if r.ExpectedVersion != 0 && (r.Existing == nil || r.Existing.Version != r.ExpectedVersion) {
+			return fmt.Errorf("%w: alert rule %q was changed since version %d", ngmodels.ErrAlertRuleVersionConflict, r.New.UID, r.ExpectedVersion)
+		}
+
+		var parentVersion int64
+		switch r.Existing {
+		case nil: // new rule
+			if r.New.UID == "" {
 				uid, err := GenerateNewAlertRuleUID(sess, r.New.OrgID, r.New.Title)
 				if err != nil {
 					return fmt.Errorf("failed to generate UID for alert rule %q: %w", r.New.Title, err)
 				}
 				r.New.UID = uid
+			} else if !util.IsValidShortUID(r.New.UID) || len(r.New.UID) > 40 {
+				return fmt.Errorf("%w: UID %q is not valid", ngmodels.ErrAlertRuleFailedValidation, r.New.UID)
+			}
 
-				if r.New.IntervalSeconds == 0 {
-					r.New.IntervalSeconds = st.DefaultIntervalSeconds
-				}
+			d, err := trimFolderDefaults(&r.New)
+			if err != nil {
+				return err
+			}
 
-				r.New.Version = 1
+			// the settings neither the rule nor its folder has are the defaults of the rules
+			if r.New.IntervalSeconds == 0 && (d == nil || d.IntervalSeconds == 0) {
+				r.New.IntervalSeconds = st.DefaultIntervalSeconds
+			}
 
-				if r.New.NoDataState == "" {
-					// set default no data state
-					r.New.NoDataState = ngmodels.NoData
-				}
+			r.New.Version = 1
+			r.New.OwnerUserID = r.UpdatedByUserID
 
-				if r.New.ExecErrState == "" {
-					// set default error state
-					r.New.ExecErrState = ngmodels.AlertingErrState
-				}
+			if r.New.NoDataState == "" && (d == nil || d.NoDataState == "") {
+				// set default no data state
+				r.New.NoDataState = ngmodels.NoData
+			}
 
-				if err := st.validateAlertRule(r.New); err != nil {
-					return err
-				}
+			if r.New.ExecErrState == "" && (d == nil || d.ExecErrState == "") {
+				// set default error state
+				r.New.ExecErrState = ngmodels.AlertingErrState
+			}
 
-				if err := (&r.New).PreSave(TimeNow); err != nil {
-					return err
-				}
+			if err := setHeartbeatToken(&r.New, nil); err != nil {
+				return err
+			}
 
-				newRules = append(newRules, r.New)
-			default:
-				// explicitly set the existing properties if missing
-				// do not rely on xorm
-				if r.New.Title == "" {
-					r.New.Title = r.Existing.Title
-				}
+			if err := st.validateAlertRule(withDefaults(r.New, d)); err != nil {
+				return err
+			}
 
-				if r.New.Condition == "" {
-					r.New.Condition = r.Existing.Condition
-				}
+			if err := validateRuleLinks(sess, r.New); err != nil {
+				return err
+			}
 
-				if len(r.New.Data) == 0 {
-					r.New.Data = r.Existing.Data
-				}
+			if err := validateShadowRule(sess, r.New); err != nil {
+				return err
+			}
+
+			if err := (&r.New).PreSave(TimeNow); err != nil {
+				return err
+			}
+
+			newRules = append(newRules, r.New)
+		default:
+			// explicitly set the existing properties if missing
+			// do not rely on xorm
+			if r.New.Title == "" {
+				r.New.Title = r.Existing.Title
+			}
+
+			if r.New.Condition == "" {
+				r.New.Condition = r.Existing.Condition
+			}
+
+			if len(r.New.Data) == 0 {
+				r.New.Data = r.Existing.Data
+			}
 
-				r.New.ID = r.Existing.ID
-				r.New.OrgID = r.Existing.OrgID
+			r.New.ID = r.Existing.ID
+			r.New.OrgID = r.Existing.OrgID
+			if !r.Move {
 				r.New.NamespaceUID = r.Existing.NamespaceUID
 				r.New.RuleGroup = r.Existing.RuleGroup
-				r.New.Version = r.Existing.Version + 1
+			}
+			r.New.Version = r.Existing.Version + 1
 
-				if r.New.ExecErrState == "" {
-					r.New.ExecErrState = r.Existing.ExecErrState
-				}
+			if r.New.ExecErrState == "" {
+				r.New.ExecErrState = r.Existing.ExecErrState
+			}
 
-				if r.New.NoDataState == "" {
-					r.New.NoDataState = r.Existing.NoDataState
-				}
+			if r.New.NoDataState == "" {
+				r.New.NoDataState = r.Existing.NoDataState
+			}
 
-				if err := st.validateAlertRule(r.New); err != nil {
-					return err
-				}
+			d, err := trimFolderDefaults(&r.New)
+			if err != nil {
+				return err
+			}
 
-				if err := (&r.New).PreSave(TimeNow); err != nil {
-					return err
-				}
+			if err := setHeartbeatToken(&r.New, r.Existing); err != nil {
+				return err
+			}
 
-				// no way to update multiple rules at once
-				if _, err := sess.ID(r.Existing.ID).AllCols().Update(r.New); err != nil {
-					return fmt.Errorf("failed to update rule %s: %w", r.New.Title, err)
-				}
+			if err := st.validateAlertRule(withDefaults(r.New, d)); err != nil {
+				return err
+			}
 
-				parentVersion = r.Existing.Version
+			if err := validateRuleLinks(sess, r.New); err != nil {
+				return err
 			}
 
-			ruleVersions = append(ruleVersions, ngmodels.AlertRuleVersion{
-				RuleOrgID:        r.New.OrgID,
-				RuleUID:          r.New.UID,
-				RuleNamespaceUID: r.New.NamespaceUID,
-				RuleGroup:        r.New.RuleGroup,
-				ParentVersion:    parentVersion,
-				Version:          r.New.Version,
-				Created:          r.New.Updated,
-				Condition:        r.New.Condition,
-				Title:            r.New.Title,
-				Data:             r.New.Data,
-				IntervalSeconds:  r.New.IntervalSeconds,
-				NoDataState:      r.New.NoDataState,
-				ExecErrState:     r.New.ExecErrState,
-				For:              r.New.For,
-				Annotations:      r.New.Annotations,
-				Labels:           r.New.Labels,
-			})
-		}
+			if err := validateShadowRule(sess, r.New); err != nil {
+				return err
+			}
 
-		if len(newRules) > 0 {
-			if _, err := sess.Insert(&newRules); err != nil {
-				return fmt.Errorf("failed to create new rules: %w", err)
+			if err := (&r.New).PreSave(TimeNow); err != nil {
+				return err
+			}
+
+			// the queries are executed with the data source permissions of the user who last changed them
+			r.New.OwnerUserID = r.Existing.OwnerUserID
+			if !ngmodels.SameQueries(r.New.Data, r.Existing.Data) {
+				r.New.OwnerUserID = r.UpdatedByUserID
 			}
-		}
 
-		if len(ruleVersions) > 0 {
-			if _, err := sess.Insert(&ruleVersions); err != nil {
-				return fmt.Errorf("failed to create new rule versions: %w", err)
+			// no way to update multiple rules at once
+			// the rule is updated only if it's still the existing version, so concurrent updates don't overwrite
+			// each other
+			affected, err := sess.ID(r.Existing.ID).Where("version = ?", r.Existing.Version).AllCols().Update(&r.New)
+			if err != nil {
+				return fmt.Errorf("failed to update rule %s: %w", r.New.Title, err)
+			}
+			if affected == 0 {
+				return fmt.Errorf("%w: alert rule %q was changed since version %d", ngmodels.ErrAlertRuleVersionConflict, r.New.UID, r.Existing.Version)
 			}
+
+			parentVersion = r.Existing.Version
 		}
 
-		return nil
-	})
+		if err := saveAlertRuleHeartbeat(sess, r.New, r.New.Updated); err != nil {
+			return fmt.Errorf("failed to save the heartbeat of rule %s: %w", r.New.Title, err)
+		}
+
+		ruleVersions = append(ruleVersions, ngmodels.AlertRuleVersion{
+			RuleOrgID:        r.New.OrgID,
+			RuleUID:          r.New.UID,
+			RuleNamespaceUID: r.New.NamespaceUID,
+			RuleGroup:        r.New.RuleGroup,
+			ParentVersion:    parentVersion,
+			RestoredFrom:     r.RestoredFrom,
+			Version:          r.New.Version,
+			Created:          r.New.Updated,
+			CreatedBy:        r.UpdatedBy,
+			Condition:        r.New.Condition,
+			Title:            r.New.Title,
+			Data:             r.New.Data,
+			IntervalSeconds:  r.New.IntervalSeconds,
+			NoDataState:      r.New.NoDataState,
+			ExecErrState:     r.New.ExecErrState,
+			For:              r.New.For,
+			Annotations:      r.New.Annotations,
+			Labels:           r.New.Labels,
+			Composite:        r.New.Composite,
+			Thresholds:       r.New.Thresholds,
+			Heartbeat:        r.New.Heartbeat,
+			Links:            r.New.Links,
+			ShadowOf:         r.New.ShadowOf,
+			ActiveFrom:       r.New.ActiveFrom,
+			ActiveUntil:      r.New.ActiveUntil,
+
+			HoldOnDatasourceMaintenance: r.New.HoldOnDatasourceMaintenance,
+			GroupConcurrency:            r.New.GroupConcurrency,
+		})
+	}
+
+	if len(newRules) > 0 {
+		if _, err := sess.Insert(&newRules); err != nil {
+			return fmt.Errorf("failed to create new rules: %w", err)
+		}
+	}
+
+	if len(ruleVersions) > 0 {
+		if _, err := sess.Insert(&ruleVersions); err != nil {
+			return fmt.Errorf("failed to create new rule versions: %w", err)
+		}
+	}
+
+	return nil
 }
 
 // GetOrgAlertRules is a handler for retrieving alert rules of specific organisation.
@@ -331,10 +612,14 @@ func (st DBstore) GetOrgAlertRules(query *ngmodels.ListAlertRulesQuery) error {
 			}
 			q = fmt.Sprintf("%s AND namespace_uid IN (%s)", q, strings.Join(placeholders, ","))
 		}
+		q = fmt.Sprintf("%s ORDER BY rule_group_idx, id", q)
 
 		if err := sess.SQL(q, params...).Find(&alertRules); err != nil {
 			return err
 		}
+		if err := st.applyFolderDefaults(sess, alertRules...); err != nil {
+			return err
+		}
 
 		query.Result = alertRules
 		return nil
@@ -346,10 +631,13 @@ func (st DBstore) GetNamespaceAlertRules(query *ngmodels.ListNamespaceAlertRules
 	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
 		alertRules := make([]*ngmodels.AlertRule, 0)
 		// TODO rewrite using group by namespace_uid, rule_group
-		q := "SELECT * FROM alert_rule WHERE org_id = ? and namespace_uid = ?"
+		q := "SELECT * FROM alert_rule WHERE org_id = ? and namespace_uid = ? ORDER BY rule_group_idx, id"
 		if err := sess.SQL(q, query.OrgID, query.NamespaceUID).Find(&alertRules); err != nil {
 			return err
 		}
+		if err := st.applyFolderDefaults(sess, alertRules...); err != nil {
+			return err
+		}
 
 		query.Result = alertRules
 		return nil
@@ -359,18 +647,25 @@ func (st DBstore) GetNamespaceAlertRules(query *ngmodels.ListNamespaceAlertRules
 // GetRuleGroupAlertRules is a handler for retrieving rule group alert rules of specific organisation.
 func (st DBstore) GetRuleGroupAlertRules(query *ngmodels.ListRuleGroupAlertRulesQuery) error {
 	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
-		alertRules := make([]*ngmodels.AlertRule, 0)
-
-		q := "SELECT * FROM alert_rule WHERE org_id = ? and namespace_uid = ? and rule_group = ?"
-		if err := sess.SQL(q, query.OrgID, query.NamespaceUID, query.RuleGroup).Find(&alertRules); err != nil {
+		if err := getRuleGroupAlertRules(sess, query); err != nil {
 			return err
 		}
-
-		query.Result = alertRules
-		return nil
+		return st.applyFolderDefaults(sess, query.Result...)
 	})
 }
 
+func getRuleGroupAlertRules(sess *sqlstore.DBSession, query *ngmodels.ListRuleGroupAlertRulesQuery) error {
+	alertRules := make([]*ngmodels.AlertRule, 0)
+
+	q := "SELECT * FROM alert_rule WHERE org_id = ? and namespace_uid = ? and rule_group = ? ORDER BY rule_group_idx, id"
+	if err := sess.SQL(q, query.OrgID, query.NamespaceUID, query.RuleGroup).Find(&alertRules); err != nil {
+		return err
+	}
+
+	query.Result = alertRules
+	return nil
+}
+
 // GetNamespaces returns the folders that are visible to the user
 func (st DBstore) GetNamespaces(orgID int64, user *models.SignedInUser) (map[string]*models.Folder, error) {
 	s := dashboards.NewFolderService(orgID, user, st.SQLStore)
@@ -416,13 +711,68 @@ func (st DBstore) GetNamespaceByTitle(namespace string, orgID int64, user *model
 	return folder, nil
 }
 
+// GetNamespaceByUID is a handler for retrieving a namespace by its UID. Alerting rules follow a Grafana folder-like structure which we call namespaces.
+func (st DBstore) GetNamespaceByUID(uid string, orgID int64, user *models.SignedInUser, withCanSave bool) (*models.Folder, error) {
+	s := dashboards.NewFolderService(orgID, user, st.SQLStore)
+	folder, err := s.GetFolderByUID(uid)
+	if err != nil {
+		return nil, err
+	}
+
+	if withCanSave {
+		g := guardian.New(folder.Id, orgID, user)
+		if canSave, err := g.CanSave(); err != nil || !canSave {
+			if err != nil {
+				st.Logger.Error("checking can save permission has failed", "userId", user.UserId, "username", user.Login, "namespace", uid, "orgId", orgID, "error", err)
+			}
+			return nil, ngmodels.ErrCannotEditNamespace
+		}
+	}
+
+	return folder, nil
+}
+
+// CreateNamespace creates a folder with the title and UID, a UID is generated if it is empty. The user must be allowed
+// to create folders.
+func (st DBstore) CreateNamespace(title, uid string, orgID int64, user *models.SignedInUser) (*models.Folder, error) {
+	s := dashboards.NewFolderService(orgID, user, st.SQLStore)
+	return s.CreateFolder(title, uid)
+}
+
 // GetAlertRulesForScheduling returns alert rule info (identifier, interval, version state)
 // that is useful for it's scheduling.
 func (st DBstore) GetAlertRulesForScheduling(query *ngmodels.ListAlertRulesQuery) error {
 	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
 		alerts := make([]*ngmodels.AlertRule, 0)
-		q := "SELECT uid, org_id, interval_seconds, version FROM alert_rule"
-		if err := sess.SQL(q).Find(&alerts); err != nil {
+		q := "SELECT id, uid, org_id, interval_seconds, version FROM alert_rule WHERE is_paused = ?"
+		if err := sess.SQL(q, false).Find(&alerts); err != nil {
+			return err
+		}
+
+		query.Result = alerts
+		return nil
+	})
+}
+
+// alertRulesByIDsBatchSize keeps the number of parameters of the queries below the limits of the databases.
+const alertRulesByIDsBatchSize = 500
+
+// GetAlertRulesByIDs is a handler for retrieving the alert rules with the given IDs, of all organisations.
+func (st DBstore) GetAlertRulesByIDs(query *ngmodels.ListAlertRulesByIDsQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		alerts := make([]*ngmodels.AlertRule, 0, len(query.IDs))
+		for start := 0; start < len(query.IDs); start += alertRulesByIDsBatchSize {
+			end := start + alertRulesByIDsBatchSize
+			if end > len(query.IDs) {
+				end = len(query.IDs)
+			}
+			batch := make([]*ngmodels.AlertRule, 0, end-start)
+			if err := sess.In("id", query.IDs[start:end]).Find(&batch); err != nil {
+				return err
+			}
+			alerts = append(alerts, batch...)
+		}
+		if err := st.applyFolderDefaults(sess, alerts...); err != nil {
 			return err
 		}
 
@@ -431,6 +781,91 @@ func (st DBstore) GetAlertRulesForScheduling(query *ngmodels.ListAlertRulesQuery
 	})
 }
 
+// GetAlertRulesWatermark is a handler for retrieving the watermark of the alert rules of all organisations. It's a
+// single row, and one for the defaults of the folders, so comparing it with a previous watermark is a cheap way to
+// know if the rules were changed.
+func (st DBstore) GetAlertRulesWatermark(query *ngmodels.GetAlertRulesWatermarkQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		var watermark ngmodels.AlertRulesWatermark
+		q := "SELECT COUNT(*) AS rule_count, COALESCE(SUM(version), 0) AS version_sum, COALESCE(MAX(id), 0) AS max_id FROM alert_rule"
+		if _, err := sess.SQL(q).Get(&watermark); err != nil {
+			return err
+		}
+		q = "SELECT COUNT(*) AS defaults_count, COALESCE(SUM(version), 0) AS version_sum, COALESCE(MAX(id), 0) AS max_id FROM alert_rule_folder_defaults"
+		if _, err := sess.SQL(q).Get(&watermark.FolderDefaults); err != nil {
+			return err
+		}
+
+		query.Result = watermark
+		return nil
+	})
+}
+
+// SearchAlertRules is a handler for searching the alert rules of an organisation.
+func (st DBstore) SearchAlertRules(query *ngmodels.SearchAlertRulesQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		alertRules := make([]*ngmodels.AlertRule, 0)
+		like := st.SQLStore.Dialect.LikeStr()
+		q := "SELECT * FROM alert_rule WHERE org_id = ?"
+		params := []interface{}{query.OrgID}
+
+		if len(query.NamespaceUIDs) > 0 {
+			placeholders := make([]string, 0, len(query.NamespaceUIDs))
+			for _, folderUID := range query.NamespaceUIDs {
+				params = append(params, folderUID)
+				placeholders = append(placeholders, "?")
+			}
+			q = fmt.Sprintf("%s AND namespace_uid IN (%s)", q, strings.Join(placeholders, ","))
+		}
+
+		if query.Text != "" {
+			text := "%" + strings.ToLower(query.Text) + "%"
+			q = fmt.Sprintf("%s AND (LOWER(title) %s ? OR LOWER(annotations) %s ?)", q, like, like)
+			params = append(params, text, text)
+		}
+
+		order := "title"
+		if query.SortBy == ngmodels.AlertRulesSortByUpdated {
+			order = "updated"
+		}
+		if query.Desc {
+			order += " DESC"
+		}
+		// The id keeps the order of the rules with the same title or updated time stable between pages.
+		q = fmt.Sprintf("%s ORDER BY %s, id", q, order)
+
+		if err := sess.SQL(q, params...).Find(&alertRules); err != nil {
+			return err
+		}
+		// The labels are filtered with the labels the rules inherit from their folders.
+		if err := st.applyFolderDefaults(sess, alertRules...); err != nil {
+			return err
+		}
+		if len(query.Labels) > 0 {
+			matching := make([]*ngmodels.AlertRule, 0, len(alertRules))
+			for _, r := range alertRules {
+				if hasLabels(r, query.Labels) {
+					matching = append(matching, r)
+				}
+			}
+			alertRules = matching
+		}
+
+		query.Result = alertRules
+		return nil
+	})
+}
+
+// hasLabels returns true if the rule has all the labels.
+func hasLabels(rule *ngmodels.AlertRule, labels map[string]string) bool {
+	for k, v := range labels {
+		if value, ok := rule.Labels[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
+}
+
 // GenerateNewAlertRuleUID generates a unique UID for a rule.
 // This is set as a variable so that the tests can override it.
 // The ruleTitle is only used by the mocked functions.
@@ -453,14 +888,49 @@ var GenerateNewAlertRuleUID = func(sess *sqlstore.DBSession, orgID int64, ruleTi
 
 // validateAlertRule validates the alert rule interval and organisation.
 func (st DBstore) validateAlertRule(alertRule ngmodels.AlertRule) error {
-	if len(alertRule.Data) == 0 {
+	if alertRule.Composite.IsComposite() {
+		if len(alertRule.Data) > 0 {
+			return fmt.Errorf("%w: composite rules have no queries or expressions", ngmodels.ErrAlertRuleFailedValidation)
+		}
+		if alertRule.Heartbeat.IsHeartbeat() {
+			return fmt.Errorf("%w: composite rules cannot be heartbeat rules", ngmodels.ErrAlertRuleFailedValidation)
+		}
+		if err := alertRule.Composite.Validate(alertRule.UID); err != nil {
+			return err
+		}
+	} else if alertRule.Heartbeat.IsHeartbeat() {
+		if len(alertRule.Data) > 0 {
+			return fmt.Errorf("%w: heartbeat rules have no queries or expressions", ngmodels.ErrAlertRuleFailedValidation)
+		}
+		if err := alertRule.Heartbeat.Validate(alertRule.IntervalSeconds); err != nil {
+			return err
+		}
+	} else if len(alertRule.Data) == 0 {
 		return fmt.Errorf("%w: no queries or expressions are found", ngmodels.ErrAlertRuleFailedValidation)
 	}
 
+	if !alertRule.Thresholds.IsEmpty() {
+		if err := alertRule.Thresholds.Validate(alertRule.Data); err != nil {
+			return err
+		}
+	}
+
+	if err := alertRule.Links.Validate(); err != nil {
+		return err
+	}
+
+	if !alertRule.ActiveFrom.IsZero() && !alertRule.ActiveUntil.IsZero() && !alertRule.ActiveUntil.After(alertRule.ActiveFrom) {
+		return fmt.Errorf("%w: the end of the activation window should be after its start", ngmodels.ErrAlertRuleFailedValidation)
+	}
+
 	if alertRule.Title == "" {
 		return fmt.Errorf("%w: title is empty", ngmodels.ErrAlertRuleFailedValidation)
 	}
 
+	if alertRule.GroupConcurrency < 0 {
+		return fmt.Errorf("%w: the concurrency of the rule group should not be negative", ngmodels.ErrAlertRuleFailedValidation)
+	}
+
 	if alertRule.IntervalSeconds%int64(st.BaseInterval.Seconds()) != 0 || alertRule.IntervalSeconds <= 0 {
 		return fmt.Errorf("%w: interval (%v) should be non-zero and divided exactly by scheduler interval: %v", ngmodels.ErrAlertRuleFailedValidation, time.Duration(alertRule.IntervalSeconds)*time.Second, st.BaseInterval)
 	}
@@ -482,86 +952,185 @@ func (st DBstore) validateAlertRule(alertRule ngmodels.AlertRule) error {
 	return nil
 }
 
+// validateRuleLinks checks that the dashboard and the panel the rule links to exist in the organization of the rule.
+func validateRuleLinks(sess *sqlstore.DBSession, alertRule ngmodels.AlertRule) error {
+	if alertRule.Links.DashboardUID == "" {
+		return nil
+	}
+	dashboard := models.Dashboard{}
+	exists, err := sess.Where("org_id = ? AND uid = ? AND is_folder = ?", alertRule.OrgID, alertRule.Links.DashboardUID, false).Get(&dashboard)
+	if err != nil {
+		return fmt.Errorf("failed to get the dashboard of rule %s: %w", alertRule.Title, err)
+	}
+	if !exists {
+		return fmt.Errorf("%w: dashboard %s not found", ngmodels.ErrAlertRuleFailedValidation, alertRule.Links.DashboardUID)
+	}
+	if alertRule.Links.PanelID > 0 && !ngmodels.HasPanel(dashboard.Data, alertRule.Links.PanelID) {
+		return fmt.Errorf("%w: panel %d not found in dashboard %s", ngmodels.ErrAlertRuleFailedValidation, alertRule.Links.PanelID, alertRule.Links.DashboardUID)
+	}
+	return nil
+}
+
+// validateShadowRule checks that the original rule of a shadow rule exists in the organization of the rule, and that
+// shadows are not chained.
+func validateShadowRule(sess *sqlstore.DBSession, alertRule ngmodels.AlertRule) error {
+	if !alertRule.IsShadow() {
+		return nil
+	}
+	if alertRule.ShadowOf == alertRule.UID {
+		return fmt.Errorf("%w: rule %s can't be a shadow of itself", ngmodels.ErrAlertRuleFailedValidation, alertRule.UID)
+	}
+	original := ngmodels.AlertRule{}
+	exists, err := sess.Where("org_id = ? AND uid = ?", alertRule.OrgID, alertRule.ShadowOf).Get(&original)
+	if err != nil {
+		return fmt.Errorf("failed to get the original rule of rule %s: %w", alertRule.Title, err)
+	}
+	if !exists {
+		return fmt.Errorf("%w: original rule %s not found", ngmodels.ErrAlertRuleFailedValidation, alertRule.ShadowOf)
+	}
+	if original.IsShadow() {
+		return fmt.Errorf("%w: original rule %s is itself a shadow rule", ngmodels.ErrAlertRuleFailedValidation, alertRule.ShadowOf)
+	}
+	shadows, err := sess.Where("org_id = ? AND shadow_of = ?", alertRule.OrgID, alertRule.UID).Count(&ngmodels.AlertRule{})
+	if err != nil {
+		return fmt.Errorf("failed to count the shadow rules of rule %s: %w", alertRule.Title, err)
+	}
+	if shadows > 0 {
+		return fmt.Errorf("%w: rule %s has shadow rules and can't be a shadow rule", ngmodels.ErrAlertRuleFailedValidation, alertRule.UID)
+	}
+	return nil
+}
+
 // UpdateRuleGroup creates new rules and updates and/or deletes existing rules
 func (st DBstore) UpdateRuleGroup(cmd UpdateRuleGroupCmd) error {
+	return st.UpdateRuleGroups([]UpdateRuleGroupCmd{cmd})
+}
+
+// UpdateRuleGroups updates several rule groups in a single transaction, either all of them are updated or none.
+func (st DBstore) UpdateRuleGroups(cmds []UpdateRuleGroupCmd) error {
 	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
-		ruleGroup := cmd.RuleGroupConfig.Name
-		q := &ngmodels.ListRuleGroupAlertRulesQuery{
-			OrgID:        cmd.OrgID,
-			NamespaceUID: cmd.NamespaceUID,
-			RuleGroup:    ruleGroup,
+		for _, cmd := range cmds {
+			if err := st.updateRuleGroup(sess, cmd); err != nil {
+				return err
+			}
 		}
-		if err := st.GetRuleGroupAlertRules(q); err != nil {
+		return nil
+	})
+}
+
+func (st DBstore) updateRuleGroup(sess *sqlstore.DBSession, cmd UpdateRuleGroupCmd) error {
+	ruleGroup := cmd.RuleGroupConfig.Name
+	q := &ngmodels.ListRuleGroupAlertRulesQuery{
+		OrgID:        cmd.OrgID,
+		NamespaceUID: cmd.NamespaceUID,
+		RuleGroup:    ruleGroup,
+	}
+	if err := getRuleGroupAlertRules(sess, q); err != nil {
+		return err
+	}
+	existingGroupRules := q.Result
+	if cmd.Check != nil {
+		if err := cmd.Check(existingGroupRules); err != nil {
 			return err
 		}
-		existingGroupRules := q.Result
+	}
 
-		existingGroupRulesUIDs := make(map[string]ngmodels.AlertRule, len(existingGroupRules))
-		for _, r := range existingGroupRules {
-			existingGroupRulesUIDs[r.UID] = *r
-		}
+	existingGroupRulesUIDs := make(map[string]ngmodels.AlertRule, len(existingGroupRules))
+	for _, r := range existingGroupRules {
+		existingGroupRulesUIDs[r.UID] = *r
+	}
 
-		upsertRules := make([]UpsertRule, 0)
-		for _, r := range cmd.RuleGroupConfig.Rules {
-			if r.GrafanaManagedAlert == nil {
-				continue
-			}
+	upsertRules := make([]UpsertRule, 0)
+	for i, r := range cmd.RuleGroupConfig.Rules {
+		if r.GrafanaManagedAlert == nil {
+			continue
+		}
 
-			new := ngmodels.AlertRule{
-				OrgID:           cmd.OrgID,
-				Title:           r.GrafanaManagedAlert.Title,
-				Condition:       r.GrafanaManagedAlert.Condition,
-				Data:            r.GrafanaManagedAlert.Data,
-				UID:             r.GrafanaManagedAlert.UID,
-				IntervalSeconds: int64(time.Duration(cmd.RuleGroupConfig.Interval).Seconds()),
-				NamespaceUID:    cmd.NamespaceUID,
-				RuleGroup:       ruleGroup,
-				NoDataState:     ngmodels.NoDataState(r.GrafanaManagedAlert.NoDataState),
-				ExecErrState:    ngmodels.ExecutionErrorState(r.GrafanaManagedAlert.ExecErrState),
-			}
+		new := ngmodels.AlertRule{
+			OrgID:           cmd.OrgID,
+			Title:           r.GrafanaManagedAlert.Title,
+			Condition:       r.GrafanaManagedAlert.Condition,
+			Data:            r.GrafanaManagedAlert.Data,
+			UID:             r.GrafanaManagedAlert.UID,
+			IntervalSeconds: int64(time.Duration(cmd.RuleGroupConfig.Interval).Seconds()),
+			NamespaceUID:    cmd.NamespaceUID,
+			RuleGroup:       ruleGroup,
+			RuleGroupIndex:  i + 1,
+			NoDataState:     ngmodels.NoDataState(r.GrafanaManagedAlert.NoDataState),
+			ExecErrState:    ngmodels.ExecutionErrorState(r.GrafanaManagedAlert.ExecErrState),
+		}
 
-			if r.ApiRuleNode != nil {
-				new.For = time.Duration(r.ApiRuleNode.For)
-				new.Annotations = r.ApiRuleNode.Annotations
-				new.Labels = r.ApiRuleNode.Labels
-			}
+		if r.GrafanaManagedAlert.Composite != nil {
+			new.Composite = *r.GrafanaManagedAlert.Composite
+		}
+		if r.GrafanaManagedAlert.Thresholds != nil {
+			new.Thresholds = *r.GrafanaManagedAlert.Thresholds
+		}
+		if r.GrafanaManagedAlert.Heartbeat != nil {
+			new.Heartbeat = *r.GrafanaManagedAlert.Heartbeat
+		}
+		if r.GrafanaManagedAlert.Links != nil {
+			new.Links = *r.GrafanaManagedAlert.Links
+		}
+		new.ShadowOf = r.GrafanaManagedAlert.ShadowOf
+		if r.GrafanaManagedAlert.ActiveFrom != nil {
+			new.ActiveFrom = *r.GrafanaManagedAlert.ActiveFrom
+		}
+		if r.GrafanaManagedAlert.ActiveUntil != nil {
+			new.ActiveUntil = *r.GrafanaManagedAlert.ActiveUntil
+		}
+		new.HoldOnDatasourceMaintenance = r.GrafanaManagedAlert.HoldOnDatasourceMaintenance
+		new.GroupConcurrency = cmd.RuleGroupConfig.Concurrency
 
-			upsertRule := UpsertRule{
-				New: new,
-			}
+		if r.ApiRuleNode != nil {
+			new.For = time.Duration(r.ApiRuleNode.For)
+			new.Annotations = r.ApiRuleNode.Annotations
+			new.Labels = r.ApiRuleNode.Labels
+		}
 
-			if existingGroupRule, ok := existingGroupRulesUIDs[r.GrafanaManagedAlert.UID]; ok {
-				upsertRule.Existing = &existingGroupRule
-				// remove the rule from existingGroupRulesUIDs
-				delete(existingGroupRulesUIDs, r.GrafanaManagedAlert.UID)
-			}
-			upsertRules = append(upsertRules, upsertRule)
+		upsertRule := UpsertRule{
+			New:             new,
+			CreateWithUID:   cmd.CreateWithUID,
+			UpdatedBy:       cmd.UpdatedBy,
+			UpdatedByUserID: cmd.UpdatedByUserID,
+			ExpectedVersion: r.GrafanaManagedAlert.Version,
 		}
 
-		if err := st.UpsertAlertRules(upsertRules); err != nil {
-			if st.SQLStore.Dialect.IsUniqueConstraintViolation(err) {
-				return ngmodels.ErrAlertRuleUniqueConstraintViolation
-			}
-			return err
+		if existingGroupRule, ok := existingGroupRulesUIDs[r.GrafanaManagedAlert.UID]; ok {
+			upsertRule.Existing = &existingGroupRule
+			upsertRule.New.IsPaused = existingGroupRule.IsPaused
+			// remove the rule from existingGroupRulesUIDs
+			delete(existingGroupRulesUIDs, r.GrafanaManagedAlert.UID)
 		}
+		if r.GrafanaManagedAlert.IsPaused != nil {
+			upsertRule.New.IsPaused = *r.GrafanaManagedAlert.IsPaused
+		}
+		upsertRules = append(upsertRules, upsertRule)
+	}
 
-		// delete instances for rules that will not be removed
-		for _, rule := range existingGroupRules {
-			if _, ok := existingGroupRulesUIDs[rule.UID]; !ok {
-				if err := st.DeleteAlertInstancesByRuleUID(cmd.OrgID, rule.UID); err != nil {
-					return err
-				}
-			}
+	if err := st.upsertAlertRules(sess, upsertRules); err != nil {
+		if st.SQLStore.Dialect.IsUniqueConstraintViolation(err) {
+			return ngmodels.ErrAlertRuleUniqueConstraintViolation
 		}
+		return err
+	}
 
-		// delete the remaining rules
-		for ruleUID := range existingGroupRulesUIDs {
-			if err := st.DeleteAlertRuleByUID(cmd.OrgID, ruleUID); err != nil {
+	// delete instances for rules that will not be removed
+	for _, rule := range existingGroupRules {
+		if _, ok := existingGroupRulesUIDs[rule.UID]; !ok {
+			if err := deleteAlertInstancesByRuleUID(sess, cmd.OrgID, rule.UID); err != nil {
 				return err
 			}
 		}
-		return nil
-	})
+	}
+
+	// delete the remaining rules
+	for ruleUID := range existingGroupRulesUIDs {
+		if err := deleteAlertRuleByUID(sess, cmd.OrgID, ruleUID); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (st DBstore) GetOrgRuleGroups(query *ngmodels.ListOrgRuleGroupsQuery) error {
@@ -578,7 +1147,7 @@ func (st DBstore) GetOrgRuleGroups(query *ngmodels.ListOrgRuleGroupsQuery) error
 			}
 			q = fmt.Sprintf(" %s AND namespace_uid IN (%s)", q, strings.Join(placeholders, ","))
 		}
-		q = fmt.Sprintf(" %s ORDER BY namespace_title", q)
+		q = fmt.Sprintf(" %s ORDER BY namespace_title, rule_group", q)
 
 		if err := sess.SQL(q, params...).Find(&ruleGroups); err != nil {
 			return err
@@ -588,3 +1157,13 @@ func (st DBstore) GetOrgRuleGroups(query *ngmodels.ListOrgRuleGroupsQuery) error
 		return nil
 	})
 }
+
+// CountAlertRules returns the number of alert rules of each organization with rules, and how many of them are paused.
+func (st DBstore) CountAlertRules() ([]*ngmodels.AlertRuleCount, error) {
+	counts := make([]*ngmodels.AlertRuleCount, 0)
+	err := st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		q := "SELECT org_id, COUNT(*) AS rules, SUM(CASE WHEN is_paused = ? THEN 1 ELSE 0 END) AS paused FROM alert_rule GROUP BY org_id ORDER BY org_id"
+		return sess.SQL(q, true).Find(&counts)
+	})
+	return counts, err
+}
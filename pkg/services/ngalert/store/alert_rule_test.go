@@ -0,0 +1,905 @@
+//go:build integration
+// +build integration
+
+package store_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	grafanamodels "github.com/grafana/grafana/pkg/models"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/store"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestUpdateRuleGroups(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	ruleGroupCmd := func(name string, intervalSeconds int64, titles ...string) store.UpdateRuleGroupCmd {
+		rules := make([]apimodels.PostableExtendedRuleNode, 0, len(titles))
+		for _, title := range titles {
+			rules = append(rules, apimodels.PostableExtendedRuleNode{
+				ApiRuleNode: &apimodels.ApiRuleNode{},
+				GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+					Title:     title,
+					Condition: "A",
+					Data: []models.AlertQuery{
+						{
+							Model: json.RawMessage(`{
+									"datasourceUid": "-100",
+									"type":"math",
+									"expression":"2 + 2 > 1"
+								}`),
+							RelativeTimeRange: models.RelativeTimeRange{
+								From: models.Duration(5 * time.Hour),
+								To:   models.Duration(3 * time.Hour),
+							},
+							RefID: "A",
+						},
+					},
+				},
+			})
+		}
+		return store.UpdateRuleGroupCmd{
+			OrgID:        1,
+			NamespaceUID: "namespace",
+			RuleGroupConfig: apimodels.PostableRuleGroupConfig{
+				Name:     name,
+				Interval: model.Duration(time.Duration(intervalSeconds) * time.Second),
+				Rules:    rules,
+			},
+		}
+	}
+	groupRules := func(t *testing.T, name string) []*models.AlertRule {
+		q := models.ListRuleGroupAlertRulesQuery{OrgID: 1, NamespaceUID: "namespace", RuleGroup: name}
+		require.NoError(t, dbstore.GetRuleGroupAlertRules(&q))
+		return q.Result
+	}
+
+	t.Run("updates all the groups", func(t *testing.T) {
+		err := dbstore.UpdateRuleGroups([]store.UpdateRuleGroupCmd{
+			ruleGroupCmd("group-1", 60, "rule 1"),
+			ruleGroupCmd("group-2", 60, "rule 2", "rule 3"),
+		})
+		require.NoError(t, err)
+		require.Len(t, groupRules(t, "group-1"), 1)
+		require.Len(t, groupRules(t, "group-2"), 2)
+	})
+
+	t.Run("updates none of the groups if one fails", func(t *testing.T) {
+		err := dbstore.UpdateRuleGroups([]store.UpdateRuleGroupCmd{
+			ruleGroupCmd("group-3", 60, "rule 4"),
+			// the interval is not a multiple of the base interval
+			ruleGroupCmd("group-4", 15, "rule 5"),
+		})
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+		require.Empty(t, groupRules(t, "group-3"))
+		require.Empty(t, groupRules(t, "group-4"))
+	})
+
+	t.Run("keeps the order of the rules", func(t *testing.T) {
+		titles := func(rules []*models.AlertRule) []string {
+			result := make([]string, 0, len(rules))
+			for _, r := range rules {
+				result = append(result, r.Title)
+			}
+			return result
+		}
+		require.NoError(t, dbstore.UpdateRuleGroup(ruleGroupCmd("group-5", 60, "rule 6", "rule 7")))
+		rules := groupRules(t, "group-5")
+		require.Equal(t, []string{"rule 6", "rule 7"}, titles(rules))
+
+		cmd := ruleGroupCmd("group-5", 60, "rule 7", "rule 6")
+		cmd.RuleGroupConfig.Rules[0].GrafanaManagedAlert.UID = rules[1].UID
+		cmd.RuleGroupConfig.Rules[1].GrafanaManagedAlert.UID = rules[0].UID
+		require.NoError(t, dbstore.UpdateRuleGroup(cmd))
+		require.Equal(t, []string{"rule 7", "rule 6"}, titles(groupRules(t, "group-5")))
+	})
+
+	t.Run("keeps the rules paused", func(t *testing.T) {
+		rules := groupRules(t, "group-1")
+		paused := *rules[0]
+		paused.IsPaused = true
+		require.NoError(t, dbstore.UpsertAlertRules([]store.UpsertRule{{Existing: rules[0], New: paused}}))
+
+		scheduled := models.ListAlertRulesQuery{}
+		require.NoError(t, dbstore.GetAlertRulesForScheduling(&scheduled))
+		for _, r := range scheduled.Result {
+			require.NotEqual(t, paused.UID, r.UID)
+		}
+
+		cmd := ruleGroupCmd("group-1", 60, "rule 1")
+		cmd.RuleGroupConfig.Rules[0].GrafanaManagedAlert.UID = paused.UID
+		require.NoError(t, dbstore.UpdateRuleGroup(cmd))
+		require.True(t, groupRules(t, "group-1")[0].IsPaused)
+
+		resume := false
+		cmd.RuleGroupConfig.Rules[0].GrafanaManagedAlert.IsPaused = &resume
+		require.NoError(t, dbstore.UpdateRuleGroup(cmd))
+		require.False(t, groupRules(t, "group-1")[0].IsPaused)
+	})
+
+	t.Run("updates nothing if the check fails", func(t *testing.T) {
+		cmd := ruleGroupCmd("group-1", 60, "rule 8")
+		cmd.Check = func(existing []*models.AlertRule) error {
+			require.Len(t, existing, 1)
+			return models.ErrAlertRuleVersionConflict
+		}
+		require.ErrorIs(t, dbstore.UpdateRuleGroup(cmd), models.ErrAlertRuleVersionConflict)
+		rules := groupRules(t, "group-1")
+		require.Len(t, rules, 1)
+		require.Equal(t, "rule 1", rules[0].Title)
+	})
+}
+
+func TestSearchAlertRules(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	rule := func(title string, labels, annotations map[string]string) apimodels.PostableExtendedRuleNode {
+		return apimodels.PostableExtendedRuleNode{
+			ApiRuleNode: &apimodels.ApiRuleNode{Labels: labels, Annotations: annotations},
+			GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+				Title:     title,
+				Condition: "A",
+				Data: []models.AlertQuery{
+					{
+						Model: json.RawMessage(`{
+								"datasourceUid": "-100",
+								"type":"math",
+								"expression":"2 + 2 > 1"
+							}`),
+						RelativeTimeRange: models.RelativeTimeRange{
+							From: models.Duration(5 * time.Hour),
+							To:   models.Duration(3 * time.Hour),
+						},
+						RefID: "A",
+					},
+				},
+			},
+		}
+	}
+	for namespace, rules := range map[string][]apimodels.PostableExtendedRuleNode{
+		"search-1": {
+			rule("High latency", map[string]string{"team": "sre"}, map[string]string{"summary": "the API is slow"}),
+			rule("Disk full", map[string]string{"team": "ops"}, nil),
+		},
+		"search-2": {
+			rule("Errors", map[string]string{"team": "sre", "severity": "critical"}, map[string]string{"runbook": "check the latency"}),
+		},
+	} {
+		require.NoError(t, dbstore.UpdateRuleGroup(store.UpdateRuleGroupCmd{
+			OrgID:        1,
+			NamespaceUID: namespace,
+			RuleGroupConfig: apimodels.PostableRuleGroupConfig{
+				Name:     "group",
+				Interval: model.Duration(time.Minute),
+				Rules:    rules,
+			},
+		}))
+	}
+
+	search := func(t *testing.T, q models.SearchAlertRulesQuery) []string {
+		q.OrgID = 1
+		if q.NamespaceUIDs == nil {
+			q.NamespaceUIDs = []string{"search-1", "search-2"}
+		}
+		require.NoError(t, dbstore.SearchAlertRules(&q))
+		titles := make([]string, 0, len(q.Result))
+		for _, r := range q.Result {
+			titles = append(titles, r.Title)
+		}
+		return titles
+	}
+
+	t.Run("sorts by title", func(t *testing.T) {
+		require.Equal(t, []string{"Disk full", "Errors", "High latency"}, search(t, models.SearchAlertRulesQuery{}))
+		require.Equal(t, []string{"High latency", "Errors", "Disk full"}, search(t, models.SearchAlertRulesQuery{Desc: true}))
+	})
+
+	t.Run("filters by folder", func(t *testing.T) {
+		require.Equal(t, []string{"Errors"}, search(t, models.SearchAlertRulesQuery{NamespaceUIDs: []string{"search-2"}}))
+	})
+
+	t.Run("searches the titles and the annotations", func(t *testing.T) {
+		require.Equal(t, []string{"Errors", "High latency"}, search(t, models.SearchAlertRulesQuery{Text: "LATENCY"}))
+	})
+
+	t.Run("filters by labels", func(t *testing.T) {
+		require.Equal(t, []string{"Errors", "High latency"}, search(t, models.SearchAlertRulesQuery{Labels: map[string]string{"team": "sre"}}))
+		require.Equal(t, []string{"Errors"}, search(t, models.SearchAlertRulesQuery{Labels: map[string]string{"team": "sre", "severity": "critical"}}))
+	})
+}
+
+func TestMoveAlertRules(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	rule := func(title string) apimodels.PostableExtendedRuleNode {
+		return apimodels.PostableExtendedRuleNode{
+			ApiRuleNode: &apimodels.ApiRuleNode{},
+			GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+				Title:     title,
+				Condition: "A",
+				Data: []models.AlertQuery{
+					{
+						Model:             json.RawMessage(`{"datasourceUid": "-100", "type":"math", "expression":"2 + 2 > 1"}`),
+						RelativeTimeRange: models.RelativeTimeRange{From: models.Duration(5 * time.Hour), To: models.Duration(3 * time.Hour)},
+						RefID:             "A",
+					},
+				},
+			},
+		}
+	}
+	createGroup := func(namespace, name string, interval time.Duration, titles ...string) []*models.AlertRule {
+		rules := make([]apimodels.PostableExtendedRuleNode, 0, len(titles))
+		for _, title := range titles {
+			rules = append(rules, rule(title))
+		}
+		require.NoError(t, dbstore.UpdateRuleGroup(store.UpdateRuleGroupCmd{
+			OrgID:        1,
+			NamespaceUID: namespace,
+			RuleGroupConfig: apimodels.PostableRuleGroupConfig{
+				Name:     name,
+				Interval: model.Duration(interval),
+				Rules:    rules,
+			},
+		}))
+		return groupRules(t, dbstore, namespace, name)
+	}
+
+	source := createGroup("folder-1", "source", time.Minute, "rule 1", "rule 2")
+	target := createGroup("folder-2", "target", 2*time.Minute, "rule 3")
+
+	t.Run("moves a rule to an existing group", func(t *testing.T) {
+		require.NoError(t, dbstore.MoveAlertRules(store.MoveAlertRulesCmd{
+			OrgID:        1,
+			Rules:        source[:1],
+			NamespaceUID: "folder-2",
+			RuleGroup:    "target",
+			UpdatedBy:    "editor",
+		}))
+		moved := groupRules(t, dbstore, "folder-2", "target")
+		require.Len(t, moved, 2)
+		require.Equal(t, target[0].UID, moved[0].UID)
+		require.Equal(t, source[0].UID, moved[1].UID)
+		require.Equal(t, int64(120), moved[1].IntervalSeconds)
+		require.Equal(t, source[0].Version+1, moved[1].Version)
+		require.Len(t, groupRules(t, dbstore, "folder-1", "source"), 1)
+	})
+
+	t.Run("fails if a rule was changed", func(t *testing.T) {
+		err := dbstore.MoveAlertRules(store.MoveAlertRulesCmd{
+			OrgID:        1,
+			Rules:        source[:1],
+			NamespaceUID: "folder-1",
+			RuleGroup:    "source",
+		})
+		require.ErrorIs(t, err, models.ErrAlertRuleVersionConflict)
+	})
+
+	t.Run("fails if the new group exists", func(t *testing.T) {
+		err := dbstore.MoveAlertRules(store.MoveAlertRulesCmd{
+			OrgID:        1,
+			Rules:        source[1:],
+			NamespaceUID: "folder-2",
+			RuleGroup:    "target",
+			NewGroup:     true,
+		})
+		require.ErrorIs(t, err, models.ErrRuleGroupExists)
+		require.Len(t, groupRules(t, dbstore, "folder-1", "source"), 1)
+	})
+
+	t.Run("copies a rule to a new group", func(t *testing.T) {
+		copied := *source[1]
+		copied.UID = "copy-of-rule-2"
+		require.NoError(t, dbstore.MoveAlertRules(store.MoveAlertRulesCmd{
+			OrgID:        1,
+			Rules:        []*models.AlertRule{&copied},
+			NamespaceUID: "folder-2",
+			RuleGroup:    "copies",
+			NewGroup:     true,
+			Copy:         true,
+			UpdatedBy:    "editor",
+		}))
+		copies := groupRules(t, dbstore, "folder-2", "copies")
+		require.Len(t, copies, 1)
+		require.Equal(t, "copy-of-rule-2", copies[0].UID)
+		require.Equal(t, source[1].Title, copies[0].Title)
+		require.Equal(t, int64(1), copies[0].Version)
+		require.NotEqual(t, source[1].ID, copies[0].ID)
+
+		sourceRules := groupRules(t, dbstore, "folder-1", "source")
+		require.Len(t, sourceRules, 1)
+		require.Equal(t, source[1].UID, sourceRules[0].UID)
+		require.Equal(t, source[1].Version, sourceRules[0].Version)
+	})
+}
+
+func TestCompositeAlertRules(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	saveComposite := func(composite models.CompositeCondition, data ...models.AlertQuery) error {
+		return dbstore.UpdateRuleGroup(store.UpdateRuleGroupCmd{
+			OrgID:        1,
+			NamespaceUID: "namespace",
+			RuleGroupConfig: apimodels.PostableRuleGroupConfig{
+				Name:     "composite",
+				Interval: model.Duration(time.Duration(baseIntervalSeconds) * time.Second),
+				Rules: []apimodels.PostableExtendedRuleNode{{
+					ApiRuleNode: &apimodels.ApiRuleNode{},
+					GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+						Title:     "service down",
+						Data:      data,
+						Composite: &composite,
+					},
+				}},
+			},
+		})
+	}
+
+	t.Run("stores the condition of composite rules", func(t *testing.T) {
+		composite := models.CompositeCondition{RuleUIDs: []string{"rule-1", "rule-2", "rule-3"}, MinFiring: 2}
+		require.NoError(t, saveComposite(composite))
+		rules := groupRules(t, dbstore, "namespace", "composite")
+		require.Len(t, rules, 1)
+		require.Equal(t, composite, rules[0].Composite)
+		require.Empty(t, rules[0].Data)
+
+		q := models.ListAlertRuleVersionsQuery{OrgID: 1, RuleUID: rules[0].UID}
+		require.NoError(t, dbstore.GetAlertRuleVersions(&q))
+		require.Equal(t, composite, q.Result[0].Composite)
+	})
+
+	t.Run("fails if the condition is not valid", func(t *testing.T) {
+		err := saveComposite(models.CompositeCondition{RuleUIDs: []string{"rule-1"}, MinFiring: 2})
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("fails if a composite rule has queries", func(t *testing.T) {
+		err := saveComposite(models.CompositeCondition{RuleUIDs: []string{"rule-1"}}, models.AlertQuery{
+			Model:             json.RawMessage(`{"datasourceUid": "-100", "type":"math", "expression":"2 + 2 > 1"}`),
+			RelativeTimeRange: models.RelativeTimeRange{From: models.Duration(5 * time.Hour), To: models.Duration(3 * time.Hour)},
+			RefID:             "A",
+		})
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("rules that are not composite have no condition", func(t *testing.T) {
+		q := models.ListAlertRulesQuery{OrgID: 1}
+		require.NoError(t, dbstore.GetOrgAlertRules(&q))
+		for _, r := range q.Result {
+			if r.RuleGroup != "composite" {
+				require.False(t, r.Composite.IsComposite())
+			}
+		}
+	})
+}
+
+func TestAlertRuleThresholds(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	saveThresholds := func(thresholds models.Thresholds) error {
+		return dbstore.UpdateRuleGroup(store.UpdateRuleGroupCmd{
+			OrgID:        1,
+			NamespaceUID: "namespace",
+			RuleGroupConfig: apimodels.PostableRuleGroupConfig{
+				Name:     "thresholds",
+				Interval: model.Duration(time.Duration(baseIntervalSeconds) * time.Second),
+				Rules: []apimodels.PostableExtendedRuleNode{{
+					ApiRuleNode: &apimodels.ApiRuleNode{},
+					GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+						Title:     "high cpu",
+						Condition: "A",
+						Data: []models.AlertQuery{{
+							Model:             json.RawMessage(`{"datasourceUid": "-100", "type":"math", "expression":"2 + 2 > 1"}`),
+							RelativeTimeRange: models.RelativeTimeRange{From: models.Duration(5 * time.Hour), To: models.Duration(3 * time.Hour)},
+							RefID:             "A",
+						}},
+						Thresholds: &thresholds,
+					},
+				}},
+			},
+		})
+	}
+
+	t.Run("stores the thresholds", func(t *testing.T) {
+		thresholds := models.Thresholds{
+			RefID: "A",
+			Type:  models.ThresholdTypeGreaterThan,
+			Levels: []models.Threshold{
+				{Value: 80, Labels: map[string]string{"severity": "warning"}},
+				{Value: 90, Labels: map[string]string{"severity": "critical"}},
+			},
+		}
+		require.NoError(t, saveThresholds(thresholds))
+		rules := groupRules(t, dbstore, "namespace", "thresholds")
+		require.Len(t, rules, 1)
+		require.Equal(t, thresholds, rules[0].Thresholds)
+	})
+
+	t.Run("fails if the thresholds are not valid", func(t *testing.T) {
+		err := saveThresholds(models.Thresholds{
+			RefID:  "B",
+			Type:   models.ThresholdTypeGreaterThan,
+			Levels: []models.Threshold{{Value: 80, Labels: map[string]string{"severity": "warning"}}},
+		})
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+}
+
+func TestAlertRuleOwner(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	saveRule := func(uid, expression string, userID int64) error {
+		return dbstore.UpdateRuleGroup(store.UpdateRuleGroupCmd{
+			OrgID:           1,
+			NamespaceUID:    "namespace",
+			UpdatedByUserID: userID,
+			RuleGroupConfig: apimodels.PostableRuleGroupConfig{
+				Name:     "owner",
+				Interval: model.Duration(time.Duration(baseIntervalSeconds) * time.Second),
+				Rules: []apimodels.PostableExtendedRuleNode{{
+					ApiRuleNode: &apimodels.ApiRuleNode{},
+					GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+						UID:       uid,
+						Title:     "high cpu",
+						Condition: "A",
+						Data: []models.AlertQuery{{
+							Model:             json.RawMessage(`{"datasourceUid": "-100", "type":"math", "expression":"` + expression + `"}`),
+							RelativeTimeRange: models.RelativeTimeRange{From: models.Duration(5 * time.Hour), To: models.Duration(3 * time.Hour)},
+							RefID:             "A",
+						}},
+					},
+				}},
+			},
+		})
+	}
+
+	require.NoError(t, saveRule("", "2 + 2 > 1", 10))
+	rules := groupRules(t, dbstore, "namespace", "owner")
+	require.Len(t, rules, 1)
+	require.Equal(t, int64(10), rules[0].OwnerUserID)
+	uid := rules[0].UID
+
+	t.Run("keeps the owner if the queries did not change", func(t *testing.T) {
+		require.NoError(t, saveRule(uid, "2 + 2 > 1", 20))
+		require.Equal(t, int64(10), groupRules(t, dbstore, "namespace", "owner")[0].OwnerUserID)
+	})
+
+	t.Run("the user who changed the queries becomes the owner", func(t *testing.T) {
+		require.NoError(t, saveRule(uid, "2 + 2 > 3", 20))
+		require.Equal(t, int64(20), groupRules(t, dbstore, "namespace", "owner")[0].OwnerUserID)
+	})
+}
+
+func TestHeartbeatAlertRules(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	saveHeartbeat := func(uid string, heartbeat models.HeartbeatCondition) error {
+		return dbstore.UpdateRuleGroup(store.UpdateRuleGroupCmd{
+			OrgID:        1,
+			NamespaceUID: "namespace",
+			RuleGroupConfig: apimodels.PostableRuleGroupConfig{
+				Name:     "heartbeat",
+				Interval: model.Duration(time.Duration(baseIntervalSeconds) * time.Second),
+				Rules: []apimodels.PostableExtendedRuleNode{{
+					ApiRuleNode: &apimodels.ApiRuleNode{},
+					GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+						UID:       uid,
+						Title:     "pipeline alive",
+						Heartbeat: &heartbeat,
+					},
+				}},
+			},
+		})
+	}
+
+	timeout := int64(5 * baseIntervalSeconds)
+	require.NoError(t, saveHeartbeat("", models.HeartbeatCondition{Token: "chosen", TimeoutSeconds: timeout}))
+	rules := groupRules(t, dbstore, "namespace", "heartbeat")
+	require.Len(t, rules, 1)
+	rule := rules[0]
+
+	t.Run("generates the token of the heartbeat rules", func(t *testing.T) {
+		require.Len(t, rule.Heartbeat.Token, 32)
+		require.NotEqual(t, "chosen", rule.Heartbeat.Token)
+		require.Equal(t, timeout, rule.Heartbeat.TimeoutSeconds)
+
+		h, err := dbstore.GetAlertRuleHeartbeat(1, rule.UID)
+		require.NoError(t, err)
+		require.Equal(t, rule.Heartbeat.Token, h.Token)
+		require.Equal(t, rule.Updated.Unix(), h.ReceivedAt, "the rule is created as if it received a heartbeat")
+	})
+
+	t.Run("records the heartbeats by token", func(t *testing.T) {
+		receivedAt := rule.Updated.Add(time.Minute)
+		h, err := dbstore.RecordHeartbeat(rule.Heartbeat.Token, receivedAt)
+		require.NoError(t, err)
+		require.Equal(t, rule.UID, h.RuleUID)
+
+		h, err = dbstore.GetAlertRuleHeartbeat(1, rule.UID)
+		require.NoError(t, err)
+		require.Equal(t, receivedAt.Unix(), h.ReceivedAt)
+
+		_, err = dbstore.RecordHeartbeat("unknown", receivedAt)
+		require.ErrorIs(t, err, models.ErrHeartbeatNotFound)
+	})
+
+	t.Run("keeps the token and the last heartbeat when the rule is updated", func(t *testing.T) {
+		before, err := dbstore.GetAlertRuleHeartbeat(1, rule.UID)
+		require.NoError(t, err)
+		require.NoError(t, saveHeartbeat(rule.UID, models.HeartbeatCondition{TimeoutSeconds: 2 * timeout}))
+		updated := groupRules(t, dbstore, "namespace", "heartbeat")[0]
+		require.Equal(t, rule.Heartbeat.Token, updated.Heartbeat.Token)
+		require.Equal(t, 2*timeout, updated.Heartbeat.TimeoutSeconds)
+
+		after, err := dbstore.GetAlertRuleHeartbeat(1, rule.UID)
+		require.NoError(t, err)
+		require.Equal(t, before, after)
+	})
+
+	t.Run("fails if the timeout is shorter than the interval", func(t *testing.T) {
+		err := saveHeartbeat("", models.HeartbeatCondition{TimeoutSeconds: baseIntervalSeconds - 1})
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("deletes the heartbeat with the rule", func(t *testing.T) {
+		_, err := dbstore.DeleteRuleGroupAlertRules(1, "namespace", "heartbeat")
+		require.NoError(t, err)
+		_, err = dbstore.GetAlertRuleHeartbeat(1, rule.UID)
+		require.ErrorIs(t, err, models.ErrHeartbeatNotFound)
+		_, err = dbstore.RecordHeartbeat(rule.Heartbeat.Token, time.Now())
+		require.ErrorIs(t, err, models.ErrHeartbeatNotFound)
+	})
+}
+
+func TestAlertRuleLinks(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	dashboard, err := simplejson.NewJson([]byte(`{
+		"title": "disks",
+		"panels": [
+			{"id": 1, "type": "timeseries"},
+			{"id": 2, "type": "row", "collapsed": true, "panels": [{"id": 3, "type": "timeseries"}]}
+		]
+	}`))
+	require.NoError(t, err)
+	dash, err := dbstore.SQLStore.SaveDashboard(grafanamodels.SaveDashboardCommand{Dashboard: dashboard, OrgId: 1, UserId: 1})
+	require.NoError(t, err)
+
+	saveLinks := func(links models.RuleLinks) error {
+		return dbstore.UpdateRuleGroup(store.UpdateRuleGroupCmd{
+			OrgID:        1,
+			NamespaceUID: "namespace",
+			RuleGroupConfig: apimodels.PostableRuleGroupConfig{
+				Name:     "links",
+				Interval: model.Duration(time.Duration(baseIntervalSeconds) * time.Second),
+				Rules: []apimodels.PostableExtendedRuleNode{{
+					ApiRuleNode: &apimodels.ApiRuleNode{},
+					GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+						Title:     "disk full",
+						Condition: "A",
+						Data: []models.AlertQuery{{
+							Model:             json.RawMessage(`{"datasourceUid": "-100", "type":"math", "expression":"2 + 2 > 1"}`),
+							RelativeTimeRange: models.RelativeTimeRange{From: models.Duration(5 * time.Hour), To: models.Duration(3 * time.Hour)},
+							RefID:             "A",
+						}},
+						Links: &links,
+					},
+				}},
+			},
+		})
+	}
+
+	t.Run("stores the links to a panel of a collapsed row", func(t *testing.T) {
+		links := models.RuleLinks{RunbookURL: "https://example.com/runbooks/disk", DashboardUID: dash.Uid, PanelID: 3}
+		require.NoError(t, saveLinks(links))
+		rules := groupRules(t, dbstore, "namespace", "links")
+		require.Len(t, rules, 1)
+		require.Equal(t, links, rules[0].Links)
+	})
+
+	t.Run("fails if the dashboard doesn't exist", func(t *testing.T) {
+		err := saveLinks(models.RuleLinks{DashboardUID: "unknown"})
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("fails if the panel doesn't exist", func(t *testing.T) {
+		err := saveLinks(models.RuleLinks{DashboardUID: dash.Uid, PanelID: 4})
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("fails if the runbook URL is not absolute", func(t *testing.T) {
+		err := saveLinks(models.RuleLinks{RunbookURL: "runbooks/disk"})
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+}
+
+func TestShadowAlertRules(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	saveRules := func(rules ...apimodels.PostableGrafanaRule) error {
+		nodes := make([]apimodels.PostableExtendedRuleNode, 0, len(rules))
+		for i := range rules {
+			r := rules[i]
+			r.Condition = "A"
+			r.Data = []models.AlertQuery{{
+				Model:             json.RawMessage(`{"datasourceUid": "-100", "type":"math", "expression":"2 + 2 > 1"}`),
+				RelativeTimeRange: models.RelativeTimeRange{From: models.Duration(5 * time.Hour), To: models.Duration(3 * time.Hour)},
+				RefID:             "A",
+			}}
+			nodes = append(nodes, apimodels.PostableExtendedRuleNode{ApiRuleNode: &apimodels.ApiRuleNode{}, GrafanaManagedAlert: &r})
+		}
+		return dbstore.UpdateRuleGroup(store.UpdateRuleGroupCmd{
+			OrgID:         1,
+			NamespaceUID:  "namespace",
+			CreateWithUID: true,
+			RuleGroupConfig: apimodels.PostableRuleGroupConfig{
+				Name:     "shadow",
+				Interval: model.Duration(time.Duration(baseIntervalSeconds) * time.Second),
+				Rules:    nodes,
+			},
+		})
+	}
+	original := apimodels.PostableGrafanaRule{UID: "original", Title: "disk full"}
+
+	require.NoError(t, saveRules(original))
+	require.NoError(t, saveRules(original, apimodels.PostableGrafanaRule{UID: "shadow", Title: "disk full (shadow)", ShadowOf: "original"}))
+	rules := groupRules(t, dbstore, "namespace", "shadow")
+	require.Len(t, rules, 2)
+	require.False(t, rules[0].IsShadow())
+	require.Equal(t, "original", rules[1].ShadowOf)
+
+	t.Run("fails if the original rule doesn't exist", func(t *testing.T) {
+		err := saveRules(original, apimodels.PostableGrafanaRule{UID: "shadow", Title: "disk full (shadow)", ShadowOf: "unknown"})
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("fails if the shadows are chained", func(t *testing.T) {
+		err := saveRules(original,
+			apimodels.PostableGrafanaRule{UID: "shadow", Title: "disk full (shadow)", ShadowOf: "original"},
+			apimodels.PostableGrafanaRule{UID: "shadow-2", Title: "disk full (shadow 2)", ShadowOf: "shadow"})
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+
+		err = saveRules(apimodels.PostableGrafanaRule{UID: "original", Title: "disk full", ShadowOf: "shadow"},
+			apimodels.PostableGrafanaRule{UID: "shadow", Title: "disk full (shadow)", ShadowOf: "original"})
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+
+	t.Run("cuts over by removing the shadow flag", func(t *testing.T) {
+		require.NoError(t, saveRules(apimodels.PostableGrafanaRule{UID: "shadow", Title: "disk full (shadow)"}))
+		rules := groupRules(t, dbstore, "namespace", "shadow")
+		require.Len(t, rules, 1)
+		require.False(t, rules[0].IsShadow())
+	})
+}
+
+func TestAlertRuleActivationWindow(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	saveWindow := func(from, until *time.Time) error {
+		return dbstore.UpdateRuleGroup(store.UpdateRuleGroupCmd{
+			OrgID:         1,
+			NamespaceUID:  "namespace",
+			CreateWithUID: true,
+			RuleGroupConfig: apimodels.PostableRuleGroupConfig{
+				Name:     "window",
+				Interval: model.Duration(time.Duration(baseIntervalSeconds) * time.Second),
+				Rules: []apimodels.PostableExtendedRuleNode{{
+					ApiRuleNode: &apimodels.ApiRuleNode{},
+					GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+						UID:       "checkout",
+						Title:     "checkout errors",
+						Condition: "A",
+						Data: []models.AlertQuery{{
+							Model:             json.RawMessage(`{"datasourceUid": "-100", "type":"math", "expression":"2 + 2 > 1"}`),
+							RelativeTimeRange: models.RelativeTimeRange{From: models.Duration(5 * time.Hour), To: models.Duration(3 * time.Hour)},
+							RefID:             "A",
+						}},
+						ActiveFrom:  from,
+						ActiveUntil: until,
+					},
+				}},
+			},
+		})
+	}
+	launch := time.Date(2021, 10, 14, 9, 0, 0, 0, time.UTC)
+	end := launch.Add(30 * 24 * time.Hour)
+
+	t.Run("stores the activation window", func(t *testing.T) {
+		require.NoError(t, saveWindow(&launch, &end))
+		rules := groupRules(t, dbstore, "namespace", "window")
+		require.Len(t, rules, 1)
+		require.True(t, launch.Equal(rules[0].ActiveFrom))
+		require.True(t, end.Equal(rules[0].ActiveUntil))
+	})
+
+	t.Run("removes the activation window", func(t *testing.T) {
+		require.NoError(t, saveWindow(nil, nil))
+		rules := groupRules(t, dbstore, "namespace", "window")
+		require.Len(t, rules, 1)
+		require.True(t, rules[0].ActiveFrom.IsZero())
+		require.True(t, rules[0].ActiveUntil.IsZero())
+	})
+
+	t.Run("fails if the window ends before it starts", func(t *testing.T) {
+		err := saveWindow(&end, &launch)
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+}
+
+func TestRuleGroupConcurrency(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	rule := func(uid string) apimodels.PostableExtendedRuleNode {
+		return apimodels.PostableExtendedRuleNode{
+			ApiRuleNode: &apimodels.ApiRuleNode{},
+			GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+				UID:       uid,
+				Title:     uid,
+				Condition: "A",
+				Data: []models.AlertQuery{{
+					Model:             json.RawMessage(`{"datasourceUid": "-100", "type":"math", "expression":"2 + 2 > 1"}`),
+					RelativeTimeRange: models.RelativeTimeRange{From: models.Duration(5 * time.Hour), To: models.Duration(3 * time.Hour)},
+					RefID:             "A",
+				}},
+			},
+		}
+	}
+	saveGroup := func(name string, concurrency int64, uids ...string) error {
+		rules := make([]apimodels.PostableExtendedRuleNode, 0, len(uids))
+		for _, uid := range uids {
+			rules = append(rules, rule(uid))
+		}
+		return dbstore.UpdateRuleGroup(store.UpdateRuleGroupCmd{
+			OrgID:         1,
+			NamespaceUID:  "namespace",
+			CreateWithUID: true,
+			RuleGroupConfig: apimodels.PostableRuleGroupConfig{
+				Name:        name,
+				Interval:    model.Duration(time.Duration(baseIntervalSeconds) * time.Second),
+				Rules:       rules,
+				Concurrency: concurrency,
+			},
+		})
+	}
+
+	t.Run("stores the concurrency of the group with each of its rules", func(t *testing.T) {
+		require.NoError(t, saveGroup("throttled", 2, "billing", "invoices", "payments"))
+		rules := groupRules(t, dbstore, "namespace", "throttled")
+		require.Len(t, rules, 3)
+		for _, r := range rules {
+			require.Equal(t, int64(2), r.GroupConcurrency)
+		}
+	})
+
+	t.Run("the rules moved to the group take its concurrency", func(t *testing.T) {
+		require.NoError(t, saveGroup("unlimited", 0, "checkout"))
+		require.NoError(t, dbstore.MoveAlertRules(store.MoveAlertRulesCmd{
+			OrgID:        1,
+			Rules:        groupRules(t, dbstore, "namespace", "unlimited"),
+			NamespaceUID: "namespace",
+			RuleGroup:    "throttled",
+		}))
+		rules := groupRules(t, dbstore, "namespace", "throttled")
+		require.Len(t, rules, 4)
+		require.Equal(t, "checkout", rules[3].UID)
+		require.Equal(t, int64(2), rules[3].GroupConcurrency)
+	})
+
+	t.Run("fails if the concurrency is negative", func(t *testing.T) {
+		err := saveGroup("invalid", -1, "refunds")
+		require.ErrorIs(t, err, models.ErrAlertRuleFailedValidation)
+	})
+}
+
+func groupRules(t *testing.T, dbstore *store.DBstore, namespace, name string) []*models.AlertRule {
+	t.Helper()
+	q := models.ListRuleGroupAlertRulesQuery{OrgID: 1, NamespaceUID: namespace, RuleGroup: name}
+	require.NoError(t, dbstore.GetRuleGroupAlertRules(&q))
+	return q.Result
+}
+
+func TestCountAlertRules(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	counts, err := dbstore.CountAlertRules()
+	require.NoError(t, err)
+	require.Empty(t, counts)
+
+	first := tests.CreateTestAlertRule(t, dbstore, baseIntervalSeconds)
+	tests.CreateTestAlertRule(t, dbstore, baseIntervalSeconds)
+	paused := *first
+	paused.IsPaused = true
+	require.NoError(t, dbstore.UpsertAlertRules([]store.UpsertRule{{Existing: first, New: paused}}))
+
+	counts, err = dbstore.CountAlertRules()
+	require.NoError(t, err)
+	require.Equal(t, []*models.AlertRuleCount{{OrgID: 1, Rules: 2, Paused: 1}}, counts)
+}
+
+func TestFolderDefaultsAlertRules(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	watermark := func(t *testing.T) models.AlertRulesWatermark {
+		q := models.GetAlertRulesWatermarkQuery{}
+		require.NoError(t, dbstore.GetAlertRulesWatermark(&q))
+		return q.Result
+	}
+
+	require.NoError(t, dbstore.SaveFolderDefaults(&models.FolderDefaults{
+		OrgID:           1,
+		FolderUID:       "defaults",
+		IntervalSeconds: 60,
+		Labels:          map[string]string{"team": "db"},
+	}))
+	require.NoError(t, dbstore.UpdateRuleGroup(store.UpdateRuleGroupCmd{
+		OrgID:        1,
+		NamespaceUID: "defaults",
+		RuleGroupConfig: apimodels.PostableRuleGroupConfig{
+			Name: "group",
+			Rules: []apimodels.PostableExtendedRuleNode{
+				{
+					ApiRuleNode: &apimodels.ApiRuleNode{Labels: map[string]string{"service": "api"}},
+					GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+						Title:     "rule",
+						Condition: "A",
+						Data: []models.AlertQuery{
+							{
+								Model: json.RawMessage(`{
+										"datasourceUid": "-100",
+										"type":"math",
+										"expression":"2 + 2 > 1"
+									}`),
+								RelativeTimeRange: models.RelativeTimeRange{
+									From: models.Duration(5 * time.Hour),
+									To:   models.Duration(3 * time.Hour),
+								},
+								RefID: "A",
+							},
+						},
+					},
+				},
+			},
+		},
+	}))
+
+	rules := groupRules(t, dbstore, "defaults", "group")
+	require.Len(t, rules, 1)
+	require.Equal(t, int64(60), rules[0].IntervalSeconds)
+	require.Equal(t, map[string]string{"team": "db", "service": "api"}, rules[0].Labels)
+
+	t.Run("the rules get the new defaults", func(t *testing.T) {
+		before := watermark(t)
+		require.NoError(t, dbstore.SaveFolderDefaults(&models.FolderDefaults{
+			OrgID:           1,
+			FolderUID:       "defaults",
+			IntervalSeconds: 120,
+			Labels:          map[string]string{"team": "web"},
+		}))
+		require.NotEqual(t, before, watermark(t))
+
+		rules := groupRules(t, dbstore, "defaults", "group")
+		require.Len(t, rules, 1)
+		require.Equal(t, int64(120), rules[0].IntervalSeconds)
+		require.Equal(t, map[string]string{"team": "web", "service": "api"}, rules[0].Labels)
+	})
+
+	t.Run("the rules get the Grafana defaults when the defaults are deleted", func(t *testing.T) {
+		before := watermark(t)
+		require.NoError(t, dbstore.DeleteFolderDefaults(1, "defaults"))
+		require.NotEqual(t, before, watermark(t))
+
+		rules := groupRules(t, dbstore, "defaults", "group")
+		require.Len(t, rules, 1)
+		require.Equal(t, dbstore.DefaultIntervalSeconds, rules[0].IntervalSeconds)
+		require.Equal(t, models.NoData, rules[0].NoDataState)
+		require.Equal(t, map[string]string{"service": "api"}, rules[0].Labels)
+	})
+}
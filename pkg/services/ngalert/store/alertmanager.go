@@ -3,7 +3,10 @@ package store
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/sqlstore"
 )
@@ -11,6 +14,9 @@ import (
 var (
 	// ErrNoAlertmanagerConfiguration is an error for when no alertmanager configuration is found.
 	ErrNoAlertmanagerConfiguration = fmt.Errorf("could not find an Alertmanager configuration")
+	// ErrAlertmanagerConfigurationConflict is an error for when the Alertmanager configuration was changed since
+	// the new one was fetched.
+	ErrAlertmanagerConfigurationConflict = fmt.Errorf("the Alertmanager configuration was changed since it was fetched")
 )
 
 // GetLatestAlertmanagerConfiguration returns the lastest version of the alertmanager configuration.
@@ -43,7 +49,18 @@ type SaveCallback func() error
 // SaveAlertmanagerConfigurationWithCallback creates an alertmanager configuration version and then executes a callback.
 // If the callback results in error in rollsback the transaction.
 func (st DBstore) SaveAlertmanagerConfigurationWithCallback(cmd *models.SaveAlertmanagerConfigurationCmd, callback SaveCallback) error {
-	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+	err := st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		if cmd.FetchedFingerprint != "" {
+			latest := models.AlertConfiguration{}
+			ok, err := sess.Desc("id").Where("org_id = ?", cmd.OrgID).Limit(1).Get(&latest)
+			if err != nil {
+				return err
+			}
+			if !ok || latest.Fingerprint() != cmd.FetchedFingerprint {
+				return ErrAlertmanagerConfigurationConflict
+			}
+		}
+
 		config := models.AlertConfiguration{
 			AlertmanagerConfiguration: cmd.AlertmanagerConfiguration,
 			ConfigurationVersion:      cmd.ConfigurationVersion,
@@ -60,4 +77,12 @@ func (st DBstore) SaveAlertmanagerConfigurationWithCallback(cmd *models.SaveAler
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	if err := bus.Publish(&events.AlertmanagerConfigurationSaved{Timestamp: time.Now(), OrgID: cmd.OrgID}); err != nil {
+		st.Logger.Error("failed to publish the saved Alertmanager configuration", "org", cmd.OrgID, "err", err)
+	}
+	return nil
 }
@@ -0,0 +1,54 @@
+package store
+
+import (
+	"context"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// AlertmanagerStateStore is the database interface for the snapshots of the embedded Alertmanagers state.
+type AlertmanagerStateStore interface {
+	GetAlertmanagerState(orgID int64, kind string) (*ngmodels.AlertmanagerState, error)
+	SaveAlertmanagerState(s *ngmodels.AlertmanagerState) error
+}
+
+// GetAlertmanagerState returns the snapshot of kind of an organization's Alertmanager.
+// It returns ngmodels.ErrNoAlertmanagerState if no snapshot is stored.
+func (st DBstore) GetAlertmanagerState(orgID int64, kind string) (*ngmodels.AlertmanagerState, error) {
+	s := &ngmodels.AlertmanagerState{}
+	err := st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		ok, err := sess.Table("alertmanager_state").Where("org_id = ? AND kind = ?", orgID, kind).Get(s)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ngmodels.ErrNoAlertmanagerState
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// SaveAlertmanagerState replaces the snapshot of the state.
+func (st DBstore) SaveAlertmanagerState(s *ngmodels.AlertmanagerState) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		existing := &ngmodels.AlertmanagerState{}
+		has, err := sess.Table("alertmanager_state").Where("org_id = ? AND kind = ?", s.OrgID, s.Kind).Get(existing)
+		if err != nil {
+			return err
+		}
+
+		if !has {
+			_, err := sess.Table("alertmanager_state").Insert(s)
+			return err
+		}
+
+		s.ID = existing.ID
+		_, err = sess.Table("alertmanager_state").ID(existing.ID).AllCols().Update(s)
+		return err
+	})
+}
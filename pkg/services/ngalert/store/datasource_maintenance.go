@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// DatasourceMaintenanceStore is the database interface for the maintenance windows of the datasources.
+type DatasourceMaintenanceStore interface {
+	GetDatasourceMaintenanceWindows(orgID int64, datasourceUID string) ([]*ngmodels.DatasourceMaintenanceWindow, error)
+	GetActiveDatasourceMaintenanceWindows(t time.Time) ([]*ngmodels.DatasourceMaintenanceWindow, error)
+	SaveDatasourceMaintenanceWindow(w *ngmodels.DatasourceMaintenanceWindow) error
+	DeleteDatasourceMaintenanceWindow(orgID int64, datasourceUID string, id int64) error
+}
+
+// GetDatasourceMaintenanceWindows returns the maintenance windows of a datasource, by start.
+func (st DBstore) GetDatasourceMaintenanceWindows(orgID int64, datasourceUID string) ([]*ngmodels.DatasourceMaintenanceWindow, error) {
+	var windows []*ngmodels.DatasourceMaintenanceWindow
+	err := st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		return sess.Table("alert_datasource_maintenance_window").
+			Where("org_id = ? AND datasource_uid = ?", orgID, datasourceUID).
+			Asc("starts_at", "id").
+			Find(&windows)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return windows, nil
+}
+
+// GetActiveDatasourceMaintenanceWindows returns the maintenance windows of all the organizations in effect at t.
+func (st DBstore) GetActiveDatasourceMaintenanceWindows(t time.Time) ([]*ngmodels.DatasourceMaintenanceWindow, error) {
+	var windows []*ngmodels.DatasourceMaintenanceWindow
+	err := st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		return sess.Table("alert_datasource_maintenance_window").
+			Where("starts_at <= ? AND ends_at > ?", t.Unix(), t.Unix()).
+			Find(&windows)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return windows, nil
+}
+
+// SaveDatasourceMaintenanceWindow creates a maintenance window of a datasource.
+func (st DBstore) SaveDatasourceMaintenanceWindow(w *ngmodels.DatasourceMaintenanceWindow) error {
+	if err := w.Validate(); err != nil {
+		return err
+	}
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		_, err := sess.Table("alert_datasource_maintenance_window").Insert(w)
+		return err
+	})
+}
+
+// DeleteDatasourceMaintenanceWindow deletes a maintenance window of a datasource.
+// It returns ngmodels.ErrDatasourceMaintenanceWindowNotFound if the datasource has no window with the ID.
+func (st DBstore) DeleteDatasourceMaintenanceWindow(orgID int64, datasourceUID string, id int64) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		res, err := sess.Exec("DELETE FROM alert_datasource_maintenance_window WHERE org_id = ? AND datasource_uid = ? AND id = ?", orgID, datasourceUID, id)
+		if err != nil {
+			return err
+		}
+		deleted, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if deleted == 0 {
+			return ngmodels.ErrDatasourceMaintenanceWindowNotFound
+		}
+		return nil
+	})
+}
@@ -0,0 +1,54 @@
+//go:build integration
+// +build integration
+
+package store_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestDatasourceMaintenanceWindows(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+	now := time.Unix(1634292000, 0)
+
+	current := &models.DatasourceMaintenanceWindow{OrgID: 1, DatasourceUID: "prometheus", Reason: "upgrade", StartsAt: now.Add(-time.Hour).Unix(), EndsAt: now.Add(time.Hour).Unix()}
+	planned := &models.DatasourceMaintenanceWindow{OrgID: 1, DatasourceUID: "prometheus", StartsAt: now.Add(time.Hour).Unix(), EndsAt: now.Add(2 * time.Hour).Unix()}
+	other := &models.DatasourceMaintenanceWindow{OrgID: 2, DatasourceUID: "prometheus", StartsAt: now.Add(-time.Hour).Unix(), EndsAt: now.Unix()}
+	for _, w := range []*models.DatasourceMaintenanceWindow{planned, current, other} {
+		require.NoError(t, dbstore.SaveDatasourceMaintenanceWindow(w))
+	}
+
+	windows, err := dbstore.GetDatasourceMaintenanceWindows(1, "prometheus")
+	require.NoError(t, err)
+	require.Len(t, windows, 2)
+	require.Equal(t, current.ID, windows[0].ID)
+	require.Equal(t, "upgrade", windows[0].Reason)
+	require.Equal(t, planned.ID, windows[1].ID)
+
+	t.Run("returns the windows in effect", func(t *testing.T) {
+		active, err := dbstore.GetActiveDatasourceMaintenanceWindows(now)
+		require.NoError(t, err)
+		require.Len(t, active, 1)
+		require.Equal(t, current.ID, active[0].ID)
+	})
+
+	t.Run("rejects the windows ending before they start", func(t *testing.T) {
+		err := dbstore.SaveDatasourceMaintenanceWindow(&models.DatasourceMaintenanceWindow{OrgID: 1, DatasourceUID: "prometheus", StartsAt: now.Unix(), EndsAt: now.Unix()})
+		require.ErrorIs(t, err, models.ErrDatasourceMaintenanceWindowFailedValidation)
+	})
+
+	t.Run("deletes the windows of the datasource", func(t *testing.T) {
+		require.ErrorIs(t, dbstore.DeleteDatasourceMaintenanceWindow(1, "loki", current.ID), models.ErrDatasourceMaintenanceWindowNotFound)
+		require.NoError(t, dbstore.DeleteDatasourceMaintenanceWindow(1, "prometheus", current.ID))
+		windows, err := dbstore.GetDatasourceMaintenanceWindows(1, "prometheus")
+		require.NoError(t, err)
+		require.Len(t, windows, 1)
+		require.Equal(t, planned.ID, windows[0].ID)
+	})
+}
@@ -0,0 +1,122 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// FolderDefaultsStore is the database interface for the defaults the alert rules of the folders inherit.
+type FolderDefaultsStore interface {
+	GetFolderDefaults(orgID int64, folderUID string) (*ngmodels.FolderDefaults, error)
+	SaveFolderDefaults(d *ngmodels.FolderDefaults) error
+	DeleteFolderDefaults(orgID int64, folderUID string) error
+}
+
+// GetFolderDefaults returns the defaults of the folder identified by the organization and UID.
+// It returns ngmodels.ErrFolderDefaultsNotFound if the folder has no defaults.
+func (st DBstore) GetFolderDefaults(orgID int64, folderUID string) (*ngmodels.FolderDefaults, error) {
+	var d *ngmodels.FolderDefaults
+	err := st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		var err error
+		d, err = getFolderDefaults(sess, orgID, folderUID)
+		if err != nil {
+			return err
+		}
+		if d == nil {
+			return ngmodels.ErrFolderDefaultsNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// SaveFolderDefaults creates or replaces the defaults of a folder.
+func (st DBstore) SaveFolderDefaults(d *ngmodels.FolderDefaults) error {
+	if err := d.Validate(st.BaseInterval); err != nil {
+		return err
+	}
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		existing, err := getFolderDefaults(sess, d.OrgID, d.FolderUID)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			d.Version = 1
+			_, err := sess.Table("alert_rule_folder_defaults").Insert(d)
+			return err
+		}
+		d.ID = existing.ID
+		d.Version = existing.Version + 1
+		_, err = sess.Table("alert_rule_folder_defaults").ID(existing.ID).AllCols().Update(d)
+		return err
+	})
+}
+
+// DeleteFolderDefaults deletes the defaults of a folder. The settings the rules of the folder inherited are then the
+// defaults of the rules.
+func (st DBstore) DeleteFolderDefaults(orgID int64, folderUID string) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		_, err := sess.Exec("DELETE FROM alert_rule_folder_defaults WHERE org_id = ? AND folder_uid = ?", orgID, folderUID)
+		return err
+	})
+}
+
+// getFolderDefaults returns the defaults of a folder, or nil if it has none.
+func getFolderDefaults(sess *sqlstore.DBSession, orgID int64, folderUID string) (*ngmodels.FolderDefaults, error) {
+	d := &ngmodels.FolderDefaults{}
+	ok, err := sess.Table("alert_rule_folder_defaults").Where("org_id = ? AND folder_uid = ?", orgID, folderUID).Get(d)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return d, nil
+}
+
+// applyFolderDefaults sets the settings the rules inherit from the defaults of their folders. The settings neither a
+// rule nor its folder has, such as after the defaults are deleted, are the defaults of the rules.
+func (st DBstore) applyFolderDefaults(sess *sqlstore.DBSession, rules ...*ngmodels.AlertRule) error {
+	if len(rules) == 0 {
+		return nil
+	}
+	orgs := make(map[int64]struct{})
+	orgIDs := make([]int64, 0, 1)
+	for _, r := range rules {
+		if _, ok := orgs[r.OrgID]; !ok {
+			orgs[r.OrgID] = struct{}{}
+			orgIDs = append(orgIDs, r.OrgID)
+		}
+	}
+	defaults := make([]*ngmodels.FolderDefaults, 0)
+	if err := sess.Table("alert_rule_folder_defaults").In("org_id", orgIDs).Find(&defaults); err != nil {
+		return fmt.Errorf("failed to get the defaults of the folders: %w", err)
+	}
+	type folderKey struct {
+		orgID int64
+		uid   string
+	}
+	byFolder := make(map[folderKey]*ngmodels.FolderDefaults, len(defaults))
+	for _, d := range defaults {
+		byFolder[folderKey{orgID: d.OrgID, uid: d.FolderUID}] = d
+	}
+
+	for _, r := range rules {
+		if d, ok := byFolder[folderKey{orgID: r.OrgID, uid: r.NamespaceUID}]; ok {
+			d.Apply(r)
+		}
+		if r.IntervalSeconds == 0 {
+			r.IntervalSeconds = st.DefaultIntervalSeconds
+		}
+		if r.NoDataState == "" {
+			r.NoDataState = ngmodels.NoData
+		}
+		if r.ExecErrState == "" {
+			r.ExecErrState = ngmodels.AlertingErrState
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// heartbeatTokenLength is the length of the tokens of the heartbeat URLs.
+const heartbeatTokenLength = 32
+
+// HeartbeatStore is the database interface for the heartbeats of the heartbeat rules.
+type HeartbeatStore interface {
+	GetAlertRuleHeartbeat(orgID int64, ruleUID string) (*ngmodels.AlertRuleHeartbeat, error)
+	RecordHeartbeat(token string, receivedAt time.Time) (*ngmodels.AlertRuleHeartbeat, error)
+}
+
+// GetAlertRuleHeartbeat returns the last heartbeat of a heartbeat rule.
+// It returns ngmodels.ErrHeartbeatNotFound if the rule is not a heartbeat rule.
+func (st DBstore) GetAlertRuleHeartbeat(orgID int64, ruleUID string) (*ngmodels.AlertRuleHeartbeat, error) {
+	h := &ngmodels.AlertRuleHeartbeat{}
+	err := st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		ok, err := sess.Table("alert_rule_heartbeat").Where("org_id = ? AND rule_uid = ?", orgID, ruleUID).Get(h)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ngmodels.ErrHeartbeatNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// RecordHeartbeat records a heartbeat of the rule with the token, and returns it.
+// It returns ngmodels.ErrHeartbeatNotFound if no rule has the token.
+func (st DBstore) RecordHeartbeat(token string, receivedAt time.Time) (*ngmodels.AlertRuleHeartbeat, error) {
+	h := &ngmodels.AlertRuleHeartbeat{}
+	err := st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		ok, err := sess.Table("alert_rule_heartbeat").Where("token = ?", token).Get(h)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ngmodels.ErrHeartbeatNotFound
+		}
+		h.ReceivedAt = receivedAt.Unix()
+		_, err = sess.Table("alert_rule_heartbeat").ID(h.ID).Cols("received_at").Update(h)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// setHeartbeatToken sets the token of a heartbeat rule, the token of the existing rule if it has one or a new one.
+func setHeartbeatToken(rule *ngmodels.AlertRule, existing *ngmodels.AlertRule) error {
+	if !rule.Heartbeat.IsHeartbeat() {
+		rule.Heartbeat.Token = ""
+		return nil
+	}
+	if existing != nil && existing.Heartbeat.Token != "" {
+		rule.Heartbeat.Token = existing.Heartbeat.Token
+		return nil
+	}
+	token, err := util.GetRandomString(heartbeatTokenLength)
+	if err != nil {
+		return err
+	}
+	rule.Heartbeat.Token = token
+	return nil
+}
+
+// saveAlertRuleHeartbeat keeps the heartbeat of a rule in sync with its condition: a heartbeat rule has a heartbeat,
+// received at its creation until the first one, and the other rules have none.
+func saveAlertRuleHeartbeat(sess *sqlstore.DBSession, rule ngmodels.AlertRule, now time.Time) error {
+	if !rule.Heartbeat.IsHeartbeat() {
+		return deleteAlertRuleHeartbeat(sess, rule.OrgID, rule.UID)
+	}
+
+	existing := &ngmodels.AlertRuleHeartbeat{}
+	has, err := sess.Table("alert_rule_heartbeat").Where("org_id = ? AND rule_uid = ?", rule.OrgID, rule.UID).Get(existing)
+	if err != nil {
+		return err
+	}
+	if has {
+		if existing.Token == rule.Heartbeat.Token {
+			return nil
+		}
+		existing.Token = rule.Heartbeat.Token
+		_, err := sess.Table("alert_rule_heartbeat").ID(existing.ID).Cols("token").Update(existing)
+		return err
+	}
+
+	_, err = sess.Table("alert_rule_heartbeat").Insert(&ngmodels.AlertRuleHeartbeat{
+		OrgID:      rule.OrgID,
+		RuleUID:    rule.UID,
+		Token:      rule.Heartbeat.Token,
+		ReceivedAt: now.Unix(),
+	})
+	return err
+}
+
+func deleteAlertRuleHeartbeat(sess *sqlstore.DBSession, orgID int64, ruleUID string) error {
+	_, err := sess.Exec("DELETE FROM alert_rule_heartbeat WHERE org_id = ? AND rule_uid = ?", orgID, ruleUID)
+	return err
+}
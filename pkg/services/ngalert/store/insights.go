@@ -0,0 +1,64 @@
+package store
+
+import (
+	"context"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// InsightsStore is the database interface for the insights of the alert rules.
+type InsightsStore interface {
+	GetAlertRuleInsights(query *ngmodels.GetAlertRuleInsightsQuery) error
+	SaveAlertRuleInsights(insights []*ngmodels.AlertRuleInsight) error
+	DeleteAlertRuleInsightsBefore(periodStart int64) error
+}
+
+// GetAlertRuleInsights returns the insights of the alert rules of an organization, for each rule and period since
+// the start of the query.
+func (st DBstore) GetAlertRuleInsights(query *ngmodels.GetAlertRuleInsightsQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		insights := make([]*ngmodels.AlertRuleInsight, 0)
+		if err := sess.Table("alert_rule_insight").Where("org_id = ? AND period_start >= ?", query.OrgID, query.From).Asc("id").Find(&insights); err != nil {
+			return err
+		}
+		query.Result = insights
+		return nil
+	})
+}
+
+// SaveAlertRuleInsights adds the insights to the insights saved for the same rules and periods.
+func (st DBstore) SaveAlertRuleInsights(insights []*ngmodels.AlertRuleInsight) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		for _, i := range insights {
+			existing := &ngmodels.AlertRuleInsight{}
+			has, err := sess.Table("alert_rule_insight").Where("org_id = ? AND rule_uid = ? AND period_start = ?", i.OrgID, i.RuleUID, i.PeriodStart).Get(existing)
+			if err != nil {
+				return err
+			}
+
+			if !has {
+				saved := *i
+				saved.ID = 0
+				if _, err := sess.Table("alert_rule_insight").Insert(&saved); err != nil {
+					return err
+				}
+				continue
+			}
+
+			existing.Add(i)
+			if _, err := sess.Table("alert_rule_insight").ID(existing.ID).AllCols().Update(existing); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteAlertRuleInsightsBefore deletes the insights of the periods that started before periodStart.
+func (st DBstore) DeleteAlertRuleInsightsBefore(periodStart int64) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		_, err := sess.Exec("DELETE FROM alert_rule_insight WHERE period_start < ?", periodStart)
+		return err
+	})
+}
@@ -0,0 +1,43 @@
+//go:build integration
+// +build integration
+
+package store_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestAlertRuleInsights(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	insight := func(org int64, period int64, evaluations int64, lastErrorAt int64) *models.AlertRuleInsight {
+		return &models.AlertRuleInsight{
+			OrgID:              org,
+			RuleUID:            "rule",
+			PeriodStart:        period,
+			Evaluations:        evaluations,
+			DurationSeconds:    float64(evaluations),
+			MaxDurationSeconds: float64(evaluations),
+			LastErrorAt:        lastErrorAt,
+		}
+	}
+	require.NoError(t, dbstore.SaveAlertRuleInsights([]*models.AlertRuleInsight{insight(1, 3600, 2, 0), insight(1, 7200, 1, 0), insight(2, 7200, 1, 0)}))
+	require.NoError(t, dbstore.SaveAlertRuleInsights([]*models.AlertRuleInsight{insight(1, 7200, 3, 7300)}))
+
+	q := models.GetAlertRuleInsightsQuery{OrgID: 1, From: 7200}
+	require.NoError(t, dbstore.GetAlertRuleInsights(&q))
+	require.Len(t, q.Result, 1)
+	require.Equal(t, int64(4), q.Result[0].Evaluations, "the evaluations are added to the saved aggregate")
+	require.Equal(t, 3.0, q.Result[0].MaxDurationSeconds)
+	require.Equal(t, int64(7300), q.Result[0].LastErrorAt)
+
+	require.NoError(t, dbstore.DeleteAlertRuleInsightsBefore(7200))
+	q = models.GetAlertRuleInsightsQuery{OrgID: 1}
+	require.NoError(t, dbstore.GetAlertRuleInsights(&q))
+	require.Len(t, q.Result, 1)
+}
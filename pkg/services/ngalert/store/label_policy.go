@@ -0,0 +1,63 @@
+package store
+
+import (
+	"context"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// LabelPolicyStore is the database interface for the label policies of organizations.
+type LabelPolicyStore interface {
+	GetLabelPolicies(orgID int64) (*ngmodels.LabelPolicies, error)
+	SaveLabelPolicies(p *ngmodels.LabelPolicies) error
+	DeleteLabelPolicies(orgID int64) error
+}
+
+// GetLabelPolicies returns the label policies of an organization.
+// It returns ngmodels.ErrNoLabelPolicies if the organization has none.
+func (st DBstore) GetLabelPolicies(orgID int64) (*ngmodels.LabelPolicies, error) {
+	p := &ngmodels.LabelPolicies{}
+	err := st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		ok, err := sess.Table("alert_label_policy").Where("org_id = ?", orgID).Get(p)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ngmodels.ErrNoLabelPolicies
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// SaveLabelPolicies replaces the label policies of an organization.
+func (st DBstore) SaveLabelPolicies(p *ngmodels.LabelPolicies) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		existing := &ngmodels.LabelPolicies{}
+		has, err := sess.Table("alert_label_policy").Where("org_id = ?", p.OrgID).Get(existing)
+		if err != nil {
+			return err
+		}
+
+		if !has {
+			_, err := sess.Table("alert_label_policy").Insert(p)
+			return err
+		}
+
+		p.ID = existing.ID
+		_, err = sess.Table("alert_label_policy").ID(existing.ID).AllCols().Update(p)
+		return err
+	})
+}
+
+// DeleteLabelPolicies removes the label policies of an organization.
+func (st DBstore) DeleteLabelPolicies(orgID int64) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		_, err := sess.Exec("DELETE FROM alert_label_policy WHERE org_id = ?", orgID)
+		return err
+	})
+}
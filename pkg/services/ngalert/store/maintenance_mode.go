@@ -0,0 +1,63 @@
+package store
+
+import (
+	"context"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// MaintenanceModeStore is the database interface for the maintenance mode of organizations.
+type MaintenanceModeStore interface {
+	GetMaintenanceMode(orgID int64) (*ngmodels.MaintenanceMode, error)
+	SaveMaintenanceMode(m *ngmodels.MaintenanceMode) error
+	DeleteMaintenanceMode(orgID int64) error
+}
+
+// GetMaintenanceMode returns the maintenance mode of an organization.
+// It returns ngmodels.ErrNoMaintenanceMode if the organization is not in maintenance mode.
+func (st DBstore) GetMaintenanceMode(orgID int64) (*ngmodels.MaintenanceMode, error) {
+	m := &ngmodels.MaintenanceMode{}
+	err := st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		ok, err := sess.Table("alert_maintenance_mode").Where("org_id = ?", orgID).Get(m)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ngmodels.ErrNoMaintenanceMode
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// SaveMaintenanceMode puts an organization into maintenance mode, or extends its current maintenance mode.
+func (st DBstore) SaveMaintenanceMode(m *ngmodels.MaintenanceMode) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		existing := &ngmodels.MaintenanceMode{}
+		has, err := sess.Table("alert_maintenance_mode").Where("org_id = ?", m.OrgID).Get(existing)
+		if err != nil {
+			return err
+		}
+
+		if !has {
+			_, err := sess.Table("alert_maintenance_mode").Insert(m)
+			return err
+		}
+
+		m.ID = existing.ID
+		_, err = sess.Table("alert_maintenance_mode").ID(existing.ID).AllCols().Omit("created_at").Update(m)
+		return err
+	})
+}
+
+// DeleteMaintenanceMode takes an organization out of maintenance mode.
+func (st DBstore) DeleteMaintenanceMode(orgID int64) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		_, err := sess.Exec("DELETE FROM alert_maintenance_mode WHERE org_id = ?", orgID)
+		return err
+	})
+}
@@ -0,0 +1,65 @@
+package store
+
+import (
+	"context"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// ProvisioningStore is the database interface for the provenance of the provisioned resources.
+type ProvisioningStore interface {
+	GetProvenance(orgID int64, recordType, recordKey string) (ngmodels.Provenance, error)
+	GetProvenances(orgID int64, recordType string) (map[string]ngmodels.Provenance, error)
+	SetProvenance(orgID int64, recordType, recordKey string, provenance ngmodels.Provenance) error
+}
+
+// GetProvenance returns the provenance of a resource, ngmodels.ProvenanceNone if it is not provisioned.
+func (st DBstore) GetProvenance(orgID int64, recordType, recordKey string) (ngmodels.Provenance, error) {
+	record := &ngmodels.ProvenanceRecord{}
+	err := st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		_, err := sess.Table("provenance_type").Where("org_id = ? AND record_type = ? AND record_key = ?", orgID, recordType, recordKey).Get(record)
+		return err
+	})
+	if err != nil {
+		return ngmodels.ProvenanceNone, err
+	}
+	return record.Provenance, nil
+}
+
+// GetProvenances returns the provenance of the provisioned resources of a type, by key.
+func (st DBstore) GetProvenances(orgID int64, recordType string) (map[string]ngmodels.Provenance, error) {
+	records := make([]*ngmodels.ProvenanceRecord, 0)
+	err := st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		return sess.Table("provenance_type").Where("org_id = ? AND record_type = ?", orgID, recordType).Find(&records)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]ngmodels.Provenance, len(records))
+	for _, r := range records {
+		result[r.RecordKey] = r.Provenance
+	}
+	return result, nil
+}
+
+// SetProvenance sets the provenance of a resource. Setting ngmodels.ProvenanceNone removes the provenance,
+// for instance when the resource is deleted.
+func (st DBstore) SetProvenance(orgID int64, recordType, recordKey string, provenance ngmodels.Provenance) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		if _, err := sess.Exec("DELETE FROM provenance_type WHERE org_id = ? AND record_type = ? AND record_key = ?", orgID, recordType, recordKey); err != nil {
+			return err
+		}
+		if provenance == ngmodels.ProvenanceNone {
+			return nil
+		}
+		_, err := sess.Table("provenance_type").Insert(&ngmodels.ProvenanceRecord{
+			OrgID:      orgID,
+			RecordKey:  recordKey,
+			RecordType: recordType,
+			Provenance: provenance,
+		})
+		return err
+	})
+}
@@ -0,0 +1,103 @@
+//go:build integration
+// +build integration
+
+package store_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+)
+
+func TestProvenance(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	t.Run("a resource without provenance is not provisioned", func(t *testing.T) {
+		p, err := dbstore.GetProvenance(1, models.ResourceTypeAlertRule, "unknown")
+		require.NoError(t, err)
+		require.Equal(t, models.ProvenanceNone, p)
+	})
+
+	t.Run("provenance is set, listed and removed", func(t *testing.T) {
+		require.NoError(t, dbstore.SetProvenance(1, models.ResourceTypeAlertRule, "uid-1", models.ProvenanceFile))
+		// Setting the provenance again replaces it.
+		require.NoError(t, dbstore.SetProvenance(1, models.ResourceTypeAlertRule, "uid-1", models.ProvenanceFile))
+		require.NoError(t, dbstore.SetProvenance(1, models.ResourceTypeAlertRule, "uid-2", models.ProvenanceFile))
+		require.NoError(t, dbstore.SetProvenance(2, models.ResourceTypeAlertRule, "uid-3", models.ProvenanceFile))
+		require.NoError(t, dbstore.SetProvenance(1, models.ResourceTypeContactPoint, "uid-1", models.ProvenanceFile))
+
+		p, err := dbstore.GetProvenance(1, models.ResourceTypeAlertRule, "uid-1")
+		require.NoError(t, err)
+		require.Equal(t, models.ProvenanceFile, p)
+
+		provenances, err := dbstore.GetProvenances(1, models.ResourceTypeAlertRule)
+		require.NoError(t, err)
+		require.Equal(t, map[string]models.Provenance{"uid-1": models.ProvenanceFile, "uid-2": models.ProvenanceFile}, provenances)
+
+		require.NoError(t, dbstore.SetProvenance(1, models.ResourceTypeAlertRule, "uid-1", models.ProvenanceNone))
+		provenances, err = dbstore.GetProvenances(1, models.ResourceTypeAlertRule)
+		require.NoError(t, err)
+		require.Equal(t, map[string]models.Provenance{"uid-2": models.ProvenanceFile}, provenances)
+	})
+}
+
+func TestProvisionedAlertRuleOwner(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+	service := provisioning.NewAlertRuleService(dbstore, dbstore, log.New("test"))
+
+	group := func(name, uid, expression string) apimodels.PostableRuleGroupConfig {
+		return apimodels.PostableRuleGroupConfig{
+			Name:     name,
+			Interval: model.Duration(time.Duration(baseIntervalSeconds) * time.Second),
+			Rules: []apimodels.PostableExtendedRuleNode{{
+				ApiRuleNode: &apimodels.ApiRuleNode{},
+				GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+					UID:       uid,
+					Title:     "high cpu " + uid,
+					Condition: "A",
+					Data: []models.AlertQuery{{
+						Model:             json.RawMessage(`{"datasourceUid": "-100", "type":"math", "expression":"` + expression + `"}`),
+						RelativeTimeRange: models.RelativeTimeRange{From: models.Duration(5 * time.Hour), To: models.Duration(3 * time.Hour)},
+						RefID:             "A",
+					}},
+				},
+			}},
+		}
+	}
+
+	t.Run("the acting user owns the rules of a replaced rule group", func(t *testing.T) {
+		require.NoError(t, service.ReplaceRuleGroup(1, "namespace", group("api", "api-1", "2 + 2 > 1"), models.ProvenanceAPI, "admin", 10))
+		rules := groupRules(t, dbstore, "namespace", "api")
+		require.Len(t, rules, 1)
+		require.Equal(t, int64(10), rules[0].OwnerUserID)
+
+		require.NoError(t, service.ReplaceRuleGroup(1, "namespace", group("api", "api-1", "2 + 2 > 3"), models.ProvenanceAPI, "editor", 20))
+		require.Equal(t, int64(20), groupRules(t, dbstore, "namespace", "api")[0].OwnerUserID)
+	})
+
+	t.Run("the acting user owns a saved rule", func(t *testing.T) {
+		rule := groupRules(t, dbstore, "namespace", "api")[0]
+		rule.Data[0].Model = json.RawMessage(`{"datasourceUid": "-100", "type":"math", "expression":"2 + 2 > 4"}`)
+		rule.Version = 0
+		saved, err := service.SaveAlertRule(*rule, models.ProvenanceAPI, "viewer", 30)
+		require.NoError(t, err)
+		require.Equal(t, int64(30), saved.OwnerUserID)
+		require.Equal(t, int64(30), groupRules(t, dbstore, "namespace", "api")[0].OwnerUserID)
+	})
+
+	t.Run("the rules provisioned from files are owned by the service identity", func(t *testing.T) {
+		require.NoError(t, service.ReplaceRuleGroup(1, "namespace", group("file", "file-1", "2 + 2 > 1"), models.ProvenanceFile, "provisioning", 0))
+		rules := groupRules(t, dbstore, "namespace", "file")
+		require.Len(t, rules, 1)
+		require.Equal(t, int64(0), rules[0].OwnerUserID)
+	})
+}
@@ -0,0 +1,97 @@
+package store
+
+import (
+	"context"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// RecurringSilenceStore is the database interface for recurring silences.
+type RecurringSilenceStore interface {
+	GetRecurringSilence(query *ngmodels.GetRecurringSilenceQuery) error
+	ListRecurringSilences(query *ngmodels.ListRecurringSilencesQuery) error
+	SaveRecurringSilence(cmd *ngmodels.SaveRecurringSilenceCmd) error
+	DeleteRecurringSilence(orgID int64, uid string) error
+	UpdateRecurringSilenceMaterialization(cmd *ngmodels.UpdateRecurringSilenceMaterializationCmd) error
+}
+
+// GetRecurringSilence returns the recurring silence identified by the organisation and UID of the query.
+// It returns ngmodels.ErrRecurringSilenceNotFound if no recurring silence is found.
+func (st DBstore) GetRecurringSilence(query *ngmodels.GetRecurringSilenceQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		s := &ngmodels.RecurringSilence{}
+		ok, err := sess.Table("alert_recurring_silence").Where("org_id = ? AND uid = ?", query.OrgID, query.UID).Get(s)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ngmodels.ErrRecurringSilenceNotFound
+		}
+		query.Result = s
+		return nil
+	})
+}
+
+// ListRecurringSilences returns the recurring silences of an organisation, or of all organisations if
+// the query has no organisation.
+func (st DBstore) ListRecurringSilences(query *ngmodels.ListRecurringSilencesQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		silences := make([]*ngmodels.RecurringSilence, 0)
+		q := sess.Table("alert_recurring_silence")
+		if query.OrgID > 0 {
+			q = q.Where("org_id = ?", query.OrgID)
+		}
+		if err := q.Asc("id").Find(&silences); err != nil {
+			return err
+		}
+		query.Result = silences
+		return nil
+	})
+}
+
+// SaveRecurringSilence creates a recurring silence, or updates it if one with the same UID already exists.
+// A UID is generated for new recurring silences that don't have one.
+func (st DBstore) SaveRecurringSilence(cmd *ngmodels.SaveRecurringSilenceCmd) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		s := cmd.RecurringSilence
+		if s.UID == "" {
+			s.UID = util.GenerateShortUID()
+		}
+
+		existing := &ngmodels.RecurringSilence{}
+		has, err := sess.Table("alert_recurring_silence").Where("org_id = ? AND uid = ?", s.OrgID, s.UID).Get(existing)
+		if err != nil {
+			return err
+		}
+
+		if !has {
+			_, err := sess.Table("alert_recurring_silence").Insert(s)
+			return err
+		}
+
+		// Updating the recurrence rule resets its materialization state, it's up to the caller
+		// to expire the silence materialized under the previous rule.
+		s.ID = existing.ID
+		_, err = sess.Table("alert_recurring_silence").ID(existing.ID).AllCols().Update(s)
+		return err
+	})
+}
+
+// DeleteRecurringSilence deletes the recurring silence identified by the organisation and UID.
+func (st DBstore) DeleteRecurringSilence(orgID int64, uid string) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		_, err := sess.Exec("DELETE FROM alert_recurring_silence WHERE org_id = ? AND uid = ?", orgID, uid)
+		return err
+	})
+}
+
+// UpdateRecurringSilenceMaterialization records the last occurrence of a recurring silence that was materialized.
+func (st DBstore) UpdateRecurringSilenceMaterialization(cmd *ngmodels.UpdateRecurringSilenceMaterializationCmd) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		_, err := sess.Exec("UPDATE alert_recurring_silence SET last_starts_at = ?, last_silence_id = ? WHERE org_id = ? AND uid = ?",
+			cmd.LastStartsAt, cmd.LastSilenceID, cmd.OrgID, cmd.UID)
+		return err
+	})
+}
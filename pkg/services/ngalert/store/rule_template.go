@@ -0,0 +1,124 @@
+package store
+
+import (
+	"context"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// RuleTemplateStore is the database interface for the rule templates and the back-references of the rules
+// instantiated from them.
+type RuleTemplateStore interface {
+	GetRuleTemplate(orgID int64, uid string) (*ngmodels.RuleTemplate, error)
+	ListRuleTemplates(orgID int64) ([]*ngmodels.RuleTemplate, error)
+	SaveRuleTemplate(t *ngmodels.RuleTemplate) error
+	DeleteRuleTemplate(orgID int64, uid string) error
+	ListRuleTemplateInstances(orgID int64, templateUID string) ([]*ngmodels.RuleTemplateInstance, error)
+	SaveRuleTemplateInstance(i *ngmodels.RuleTemplateInstance) error
+}
+
+// GetRuleTemplate returns the rule template identified by the organization and UID.
+// It returns ngmodels.ErrRuleTemplateNotFound if no rule template is found.
+func (st DBstore) GetRuleTemplate(orgID int64, uid string) (*ngmodels.RuleTemplate, error) {
+	t := &ngmodels.RuleTemplate{}
+	err := st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		ok, err := sess.Table("alert_rule_template").Where("org_id = ? AND uid = ?", orgID, uid).Get(t)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ngmodels.ErrRuleTemplateNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// ListRuleTemplates returns the rule templates of an organization, by title.
+func (st DBstore) ListRuleTemplates(orgID int64) ([]*ngmodels.RuleTemplate, error) {
+	templates := make([]*ngmodels.RuleTemplate, 0)
+	err := st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		return sess.Table("alert_rule_template").Where("org_id = ?", orgID).Asc("title", "id").Find(&templates)
+	})
+	return templates, err
+}
+
+// SaveRuleTemplate creates a rule template, or updates it and increments its version if one with the same UID
+// already exists. A UID is generated for new rule templates that don't have one.
+func (st DBstore) SaveRuleTemplate(t *ngmodels.RuleTemplate) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		if t.UID == "" {
+			t.UID = util.GenerateShortUID()
+		}
+
+		existing := &ngmodels.RuleTemplate{}
+		has, err := sess.Table("alert_rule_template").Where("org_id = ? AND uid = ?", t.OrgID, t.UID).Get(existing)
+		if err != nil {
+			return err
+		}
+
+		if !has {
+			t.Version = 1
+			_, err := sess.Table("alert_rule_template").Insert(t)
+			return err
+		}
+
+		t.ID = existing.ID
+		t.Version = existing.Version + 1
+		_, err = sess.Table("alert_rule_template").ID(existing.ID).AllCols().Update(t)
+		return err
+	})
+}
+
+// DeleteRuleTemplate deletes the rule template identified by the organization and UID, and the back-references of
+// its instances. The rules instantiated from the template are kept.
+func (st DBstore) DeleteRuleTemplate(orgID int64, uid string) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		if _, err := sess.Exec("DELETE FROM alert_rule_template WHERE org_id = ? AND uid = ?", orgID, uid); err != nil {
+			return err
+		}
+		_, err := sess.Exec("DELETE FROM alert_rule_template_instance WHERE org_id = ? AND template_uid = ?", orgID, uid)
+		return err
+	})
+}
+
+// ListRuleTemplateInstances returns the back-references of the rules instantiated from a rule template.
+func (st DBstore) ListRuleTemplateInstances(orgID int64, templateUID string) ([]*ngmodels.RuleTemplateInstance, error) {
+	instances := make([]*ngmodels.RuleTemplateInstance, 0)
+	err := st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		return sess.Table("alert_rule_template_instance").Where("org_id = ? AND template_uid = ?", orgID, templateUID).Asc("id").Find(&instances)
+	})
+	return instances, err
+}
+
+// SaveRuleTemplateInstance records the rule template a rule was rendered from, a rule is the instance of a
+// single template.
+func (st DBstore) SaveRuleTemplateInstance(i *ngmodels.RuleTemplateInstance) error {
+	return st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		existing := &ngmodels.RuleTemplateInstance{}
+		has, err := sess.Table("alert_rule_template_instance").Where("org_id = ? AND rule_uid = ?", i.OrgID, i.RuleUID).Get(existing)
+		if err != nil {
+			return err
+		}
+
+		if !has {
+			_, err := sess.Table("alert_rule_template_instance").Insert(i)
+			return err
+		}
+
+		i.ID = existing.ID
+		_, err = sess.Table("alert_rule_template_instance").ID(existing.ID).AllCols().Update(i)
+		return err
+	})
+}
+
+// deleteRuleTemplateInstance removes the back-reference of a deleted rule to its rule template, if any.
+func deleteRuleTemplateInstance(sess *sqlstore.DBSession, orgID int64, ruleUID string) error {
+	_, err := sess.Exec("DELETE FROM alert_rule_template_instance WHERE org_id = ? AND rule_uid = ?", orgID, ruleUID)
+	return err
+}
@@ -0,0 +1,138 @@
+package store
+
+import (
+	"context"
+
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+// StateAnnotationsBatchSize is the number of annotations moved from the annotations table in each transaction.
+const StateAnnotationsBatchSize = 1000
+
+// StateAnnotationStore is the database interface for the dedicated table of the annotations of the changes of the
+// states of the alerts.
+type StateAnnotationStore interface {
+	GetAlertStateAnnotations(query *ngmodels.GetAlertStateAnnotationsQuery) error
+	SaveAlertStateAnnotation(a *ngmodels.AlertStateAnnotation) error
+	MoveStateAnnotations(batchSize int) (int, error)
+}
+
+// GetAlertStateAnnotations returns the annotations of the changes of the states of the alerts, the most recent first.
+func (st DBstore) GetAlertStateAnnotations(query *ngmodels.GetAlertStateAnnotationsQuery) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		q := sess.Table("alert_state_annotation").Where("org_id = ?", query.OrgID)
+		if query.RuleUID != "" {
+			q = q.Where("rule_uid = ?", query.RuleUID)
+		}
+		if query.From > 0 {
+			q = q.Where("epoch >= ?", query.From)
+		}
+		annotations := make([]*ngmodels.AlertStateAnnotation, 0)
+		if err := q.Desc("epoch", "id").Find(&annotations); err != nil {
+			return err
+		}
+		query.Result = annotations
+		return nil
+	})
+}
+
+// SaveAlertStateAnnotation saves the annotation of a change of the state of an alert.
+func (st DBstore) SaveAlertStateAnnotation(a *ngmodels.AlertStateAnnotation) error {
+	return st.SQLStore.WithDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+		_, err := sess.Table("alert_state_annotation").Insert(a)
+		return err
+	})
+}
+
+// legacyStateAnnotation is an annotation of a change of the state of an alert in the annotations table.
+type legacyStateAnnotation struct {
+	ID          int64  `xorm:"id"`
+	OrgID       int64  `xorm:"org_id"`
+	DashboardID int64  `xorm:"dashboard_id"`
+	PanelID     int64  `xorm:"panel_id"`
+	PrevState   string `xorm:"prev_state"`
+	NewState    string `xorm:"new_state"`
+	Text        string `xorm:"text"`
+	Epoch       int64  `xorm:"epoch"`
+}
+
+// MoveStateAnnotations moves the annotations of the changes of the states of the Grafana managed alerts from the
+// annotations table to the dedicated table, batchSize annotations in each transaction so that a failure keeps the
+// annotations already moved. These are the annotations with a state and without a legacy alert.
+// It returns the number of moved annotations.
+func (st DBstore) MoveStateAnnotations(batchSize int) (int, error) {
+	moved := 0
+	for {
+		n, batchMoved := 0, 0
+		err := st.SQLStore.WithTransactionalDbSession(context.Background(), func(sess *sqlstore.DBSession) error {
+			items := make([]*legacyStateAnnotation, 0, batchSize)
+			if err := sess.Table("annotation").Where("alert_id = 0 AND new_state <> ''").Asc("id").Limit(batchSize).Find(&items); err != nil {
+				return err
+			}
+			if len(items) == 0 {
+				return nil
+			}
+
+			dashboardIDs := make([]interface{}, 0, len(items))
+			for _, item := range items {
+				if item.DashboardID > 0 {
+					dashboardIDs = append(dashboardIDs, item.DashboardID)
+				}
+			}
+			dashboardUIDs := make(map[int64]string)
+			if len(dashboardIDs) > 0 {
+				var dashboards []struct {
+					ID  int64  `xorm:"id"`
+					UID string `xorm:"uid"`
+				}
+				if err := sess.Table("dashboard").In("id", dashboardIDs...).Cols("id", "uid").Find(&dashboards); err != nil {
+					return err
+				}
+				for _, d := range dashboards {
+					dashboardUIDs[d.ID] = d.UID
+				}
+			}
+
+			for _, item := range items {
+				// The annotation is only moved by the instance deleting it, when several instances start together.
+				res, err := sess.Exec("DELETE FROM annotation WHERE id = ?", item.ID)
+				if err != nil {
+					return err
+				}
+				deleted, err := res.RowsAffected()
+				if err != nil {
+					return err
+				}
+				if deleted == 0 {
+					continue
+				}
+				if _, err := sess.Exec("DELETE FROM annotation_tag WHERE annotation_id = ?", item.ID); err != nil {
+					return err
+				}
+				a := &ngmodels.AlertStateAnnotation{
+					OrgID:        item.OrgID,
+					DashboardUID: dashboardUIDs[item.DashboardID],
+					PanelID:      item.PanelID,
+					PrevState:    item.PrevState,
+					NewState:     item.NewState,
+					Text:         item.Text,
+					Epoch:        item.Epoch,
+				}
+				if _, err := sess.Table("alert_state_annotation").Insert(a); err != nil {
+					return err
+				}
+				batchMoved++
+			}
+			n = len(items)
+			return nil
+		})
+		if err != nil {
+			return moved, err
+		}
+		moved += batchMoved
+		if n < batchSize {
+			return moved, nil
+		}
+	}
+}
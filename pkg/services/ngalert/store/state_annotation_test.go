@@ -0,0 +1,68 @@
+//go:build integration
+// +build integration
+
+package store_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	grafanamodels "github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/tests"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+)
+
+func TestAlertStateAnnotations(t *testing.T) {
+	_, dbstore := tests.SetupTestEnv(t, baseIntervalSeconds)
+
+	require.NoError(t, dbstore.SaveAlertStateAnnotation(&models.AlertStateAnnotation{
+		OrgID: 1, RuleUID: "rule", Labels: map[string]string{"instance": "a"}, PrevState: "Normal", NewState: "Alerting", Text: "disk full", Epoch: 1000,
+	}))
+	require.NoError(t, dbstore.SaveAlertStateAnnotation(&models.AlertStateAnnotation{
+		OrgID: 1, RuleUID: "other", PrevState: "Alerting", NewState: "Normal", Text: "cpu", Epoch: 2000,
+	}))
+
+	q := models.GetAlertStateAnnotationsQuery{OrgID: 1, RuleUID: "rule"}
+	require.NoError(t, dbstore.GetAlertStateAnnotations(&q))
+	require.Len(t, q.Result, 1)
+	require.Equal(t, map[string]string{"instance": "a"}, q.Result[0].Labels)
+	require.Equal(t, "Alerting", q.Result[0].NewState)
+
+	t.Run("moves the annotations of the alerts from the annotations table", func(t *testing.T) {
+		dashboard := simplejson.NewFromAny(map[string]interface{}{"title": "disks"})
+		dash, err := dbstore.SQLStore.SaveDashboard(grafanamodels.SaveDashboardCommand{Dashboard: dashboard, OrgId: 1, UserId: 1})
+		require.NoError(t, err)
+
+		repo := &sqlstore.SQLAnnotationRepo{}
+		for i := int64(1); i <= 3; i++ {
+			require.NoError(t, repo.Save(&annotations.Item{OrgId: 1, DashboardId: dash.Id, PanelId: 2, PrevState: "Normal", NewState: "Alerting", Text: "disk full", Epoch: 3000 + i}))
+		}
+		// The annotations of the users and of the legacy alerts are not moved.
+		require.NoError(t, repo.Save(&annotations.Item{OrgId: 1, DashboardId: dash.Id, Text: "deploy", Epoch: 3000, Tags: []string{"deploy"}}))
+		require.NoError(t, repo.Save(&annotations.Item{OrgId: 1, DashboardId: dash.Id, AlertId: 1, PrevState: "ok", NewState: "alerting", Text: "legacy", Epoch: 3000}))
+
+		moved, err := dbstore.MoveStateAnnotations(2)
+		require.NoError(t, err)
+		require.Equal(t, 3, moved)
+
+		q := models.GetAlertStateAnnotationsQuery{OrgID: 1}
+		require.NoError(t, dbstore.GetAlertStateAnnotations(&q))
+		require.Len(t, q.Result, 5)
+		require.Equal(t, int64(3003), q.Result[0].Epoch)
+		require.Equal(t, dash.Uid, q.Result[0].DashboardUID)
+		require.Equal(t, int64(2), q.Result[0].PanelID)
+		require.Empty(t, q.Result[0].RuleUID)
+
+		items, err := repo.Find(&annotations.ItemQuery{OrgId: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, items, 2)
+
+		moved, err = dbstore.MoveStateAnnotations(2)
+		require.NoError(t, err)
+		require.Zero(t, moved)
+	})
+}
@@ -0,0 +1,28 @@
+// Command docs generates the documentation of the functions of the templates from their registry.
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/grafana/grafana/pkg/services/ngalert/templatefuncs"
+)
+
+func main() {
+	var output string
+	flag.StringVar(&output, "of", templatefuncs.DocsPath, "output file")
+	flag.Parse()
+
+	//nolint
+	f, err := os.Create(output)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := templatefuncs.WriteDocs(f); err != nil {
+		log.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatal(err)
+	}
+}
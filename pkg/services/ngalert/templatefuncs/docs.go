@@ -0,0 +1,56 @@
+package templatefuncs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+)
+
+// DocsPath is the path of the documentation of the functions, from the root of the repository.
+const DocsPath = "docs/sources/alerting/unified-alerting/message-templating/template-functions.md"
+
+// exampleData is the data the examples are executed with: an alert of the notification templates.
+var exampleData = struct {
+	Labels   map[string]string
+	StartsAt time.Time
+}{
+	Labels:   map[string]string{"alertname": "HighCPU", "instance": "node-1"},
+	StartsAt: time.Unix(1634292000, 0).UTC(),
+}
+
+const docsHeader = `+++
+title = "Template functions"
+keywords = ["grafana", "alerting", "guide", "contact point", "templating", "functions"]
++++
+
+<!-- Generated from the registry of pkg/services/ngalert/templatefuncs, run go run ./pkg/services/ngalert/templatefuncs/cmd/docs to update it. -->
+
+# Template functions
+
+The [message templates]({{< relref "./_index.md" >}}) and the templates of the labels and annotations of the alert rules can use the following functions, in addition to the [functions of Go templates](https://golang.org/pkg/text/template/#hdr-Functions). The functions taking a value as their last argument can be used in pipelines, such as ` + "`{{ $values.B.Value | humanize }}`" + `.
+
+The numbers can be integers, decimals, numeric strings or the values of ` + "`$values`" + ` in the templates of the alert rules. The times can be times, such as ` + "`.StartsAt`" + ` in the message templates, or Unix timestamps in seconds.
+
+The examples use an alert with the labels ` + "`alertname=HighCPU`" + ` and ` + "`instance=node-1`" + ` that started at 2021-10-15 10:00:00 UTC.
+`
+
+// WriteDocs writes the markdown documentation of the functions, by category.
+func WriteDocs(w io.Writer) error {
+	var buf bytes.Buffer
+	buf.WriteString(docsHeader)
+	for _, category := range Categories {
+		fmt.Fprintf(&buf, "\n## %s\n", category)
+		for _, f := range Functions {
+			if f.Category != category {
+				continue
+			}
+			fmt.Fprintf(&buf, "\n### %s\n\n`%s`\n\n%s\n", f.Name, f.Usage, f.Description)
+			for _, e := range f.Examples {
+				fmt.Fprintf(&buf, "\n```\n%s\n```\n\nOutput:\n\n```\n%s\n```\n", e.Template, e.Output)
+			}
+		}
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
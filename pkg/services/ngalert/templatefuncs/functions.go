@@ -0,0 +1,532 @@
+// Package templatefuncs is the registry of the functions of the notification templates and of the templates of the
+// labels and annotations of the alert rules, in addition to the built-in functions of Go templates. The functions
+// have no side effects and don't access the network or the files.
+package templatefuncs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Function is a function of the templates, documented by its description and its examples.
+type Function struct {
+	Name     string
+	Category string
+	// Usage is how the function is called, such as "add a b".
+	Usage       string
+	Description string
+	Examples    []Example
+	Func        interface{}
+}
+
+// Example is a template calling the function and the output it renders.
+type Example struct {
+	Template string
+	Output   string
+}
+
+// Categories are the categories of the functions, in the order of the documentation.
+var Categories = []string{"Math", "Strings", "Dates", "Encoding", "Humanize"}
+
+// Functions are the functions of the templates, in the order of the documentation within their category.
+var Functions = []Function{
+	{
+		Name: "add", Category: "Math", Usage: "add a b",
+		Description: "Returns the sum of the numbers.",
+		Examples:    []Example{{`{{ add 1 2.5 }}`, `3.5`}},
+		Func:        mathFunc(func(a, b float64) (float64, error) { return a + b, nil }),
+	},
+	{
+		Name: "sub", Category: "Math", Usage: "sub a b",
+		Description: "Returns a minus b.",
+		Examples:    []Example{{`{{ sub 10 2.5 }}`, `7.5`}},
+		Func:        mathFunc(func(a, b float64) (float64, error) { return a - b, nil }),
+	},
+	{
+		Name: "mul", Category: "Math", Usage: "mul a b",
+		Description: "Returns the product of the numbers.",
+		Examples:    []Example{{`{{ mul 3 0.5 }}`, `1.5`}},
+		Func:        mathFunc(func(a, b float64) (float64, error) { return a * b, nil }),
+	},
+	{
+		Name: "div", Category: "Math", Usage: "div a b",
+		Description: "Returns a divided by b, the template fails if b is 0.",
+		Examples:    []Example{{`{{ div 10 4 }}`, `2.5`}},
+		Func: mathFunc(func(a, b float64) (float64, error) {
+			if b == 0 {
+				return 0, errors.New("division by zero")
+			}
+			return a / b, nil
+		}),
+	},
+	{
+		Name: "round", Category: "Math", Usage: "round precision a",
+		Description: "Returns the number rounded to the number of decimals.",
+		Examples:    []Example{{`{{ round 2 3.14159 }}`, `3.14`}, {`{{ 41.7 | round 0 }}`, `42`}},
+		Func: func(precision int, v interface{}) (float64, error) {
+			f, err := toFloat(v)
+			if err != nil {
+				return 0, err
+			}
+			p := math.Pow(10, float64(precision))
+			return math.Round(f*p) / p, nil
+		},
+	},
+	{
+		Name: "abs", Category: "Math", Usage: "abs a",
+		Description: "Returns the absolute value of the number.",
+		Examples:    []Example{{`{{ abs -3 }}`, `3`}},
+		Func:        unaryMathFunc(math.Abs),
+	},
+	{
+		Name: "floor", Category: "Math", Usage: "floor a",
+		Description: "Returns the greatest integer less than or equal to the number.",
+		Examples:    []Example{{`{{ floor 2.7 }}`, `2`}},
+		Func:        unaryMathFunc(math.Floor),
+	},
+	{
+		Name: "ceil", Category: "Math", Usage: "ceil a",
+		Description: "Returns the least integer greater than or equal to the number.",
+		Examples:    []Example{{`{{ ceil 2.1 }}`, `3`}},
+		Func:        unaryMathFunc(math.Ceil),
+	},
+	{
+		Name: "min", Category: "Math", Usage: "min a b",
+		Description: "Returns the smaller of the numbers.",
+		Examples:    []Example{{`{{ min 3 7 }}`, `3`}},
+		Func:        mathFunc(func(a, b float64) (float64, error) { return math.Min(a, b), nil }),
+	},
+	{
+		Name: "max", Category: "Math", Usage: "max a b",
+		Description: "Returns the larger of the numbers.",
+		Examples:    []Example{{`{{ max 3 7 }}`, `7`}},
+		Func:        mathFunc(func(a, b float64) (float64, error) { return math.Max(a, b), nil }),
+	},
+	{
+		Name: "contains", Category: "Strings", Usage: "contains substr s",
+		Description: "Returns whether the string contains the substring.",
+		Examples:    []Example{{`{{ if contains "prod" "eu-prod-1" }}production{{ end }}`, `production`}},
+		Func:        func(substr, s string) bool { return strings.Contains(s, substr) },
+	},
+	{
+		Name: "hasPrefix", Category: "Strings", Usage: "hasPrefix prefix s",
+		Description: "Returns whether the string starts with the prefix.",
+		Examples:    []Example{{`{{ hasPrefix "eu-" "eu-prod-1" }}`, `true`}},
+		Func:        func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+	},
+	{
+		Name: "hasSuffix", Category: "Strings", Usage: "hasSuffix suffix s",
+		Description: "Returns whether the string ends with the suffix.",
+		Examples:    []Example{{`{{ hasSuffix ".local" "db.local" }}`, `true`}},
+		Func:        func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+	},
+	{
+		Name: "replace", Category: "Strings", Usage: "replace old new s",
+		Description: "Replaces every occurrence of old by new in the string.",
+		Examples:    []Example{{`{{ replace "-" " " "high-cpu-usage" }}`, `high cpu usage`}},
+		Func:        func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	},
+	{
+		Name: "trimSpace", Category: "Strings", Usage: "trimSpace s",
+		Description: "Removes the leading and trailing white space of the string.",
+		Examples:    []Example{{`{{ trimSpace "  disk full  " }}`, `disk full`}},
+		Func:        strings.TrimSpace,
+	},
+	{
+		Name: "trimPrefix", Category: "Strings", Usage: "trimPrefix prefix s",
+		Description: "Removes the prefix from the string.",
+		Examples:    []Example{{`{{ trimPrefix "https://" "https://grafana.com" }}`, `grafana.com`}},
+		Func:        func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	},
+	{
+		Name: "trimSuffix", Category: "Strings", Usage: "trimSuffix suffix s",
+		Description: "Removes the suffix from the string.",
+		Examples:    []Example{{`{{ trimSuffix ":9100" "node-1:9100" }}`, `node-1`}},
+		Func:        func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+	},
+	{
+		Name: "truncate", Category: "Strings", Usage: "truncate length s",
+		Description: "Returns the first characters of the string, up to the length.",
+		Examples:    []Example{{`{{ truncate 4 "production" }}`, `prod`}},
+		Func: func(length int, s string) string {
+			if r := []rune(s); length >= 0 && len(r) > length {
+				return string(r[:length])
+			}
+			return s
+		},
+	},
+	{
+		Name: "split", Category: "Strings", Usage: "split sep s",
+		Description: "Splits the string around each separator, the result can be used with range or index.",
+		Examples:    []Example{{`{{ index (split ":" "node-1:9100") 0 }}`, `node-1`}},
+		Func:        func(sep, s string) []string { return strings.Split(s, sep) },
+	},
+	{
+		Name: "regexMatch", Category: "Strings", Usage: "regexMatch regex s",
+		Description: "Returns whether the string matches the regular expression.",
+		Examples:    []Example{{`{{ regexMatch "^node-[0-9]+$" "node-12" }}`, `true`}},
+		Func: func(regex, s string) (bool, error) {
+			re, err := regexp.Compile(regex)
+			if err != nil {
+				return false, err
+			}
+			return re.MatchString(s), nil
+		},
+	},
+	{
+		Name: "regexFind", Category: "Strings", Usage: "regexFind regex s",
+		Description: "Returns the first match of the regular expression in the string, or an empty string.",
+		Examples:    []Example{{`{{ regexFind "[0-9]+" "node-12" }}`, `12`}},
+		Func: func(regex, s string) (string, error) {
+			re, err := regexp.Compile(regex)
+			if err != nil {
+				return "", err
+			}
+			return re.FindString(s), nil
+		},
+	},
+	{
+		Name: "regexReplaceAll", Category: "Strings", Usage: "regexReplaceAll regex replacement s",
+		Description: "Replaces the matches of the regular expression in the string, the replacement can reference the groups of the match with $1, $2 or ${name}.",
+		Examples:    []Example{{`{{ regexReplaceAll "^(.*):[0-9]+$" "$1" "node-1:9100" }}`, `node-1`}},
+		Func: func(regex, replacement, s string) (string, error) {
+			re, err := regexp.Compile(regex)
+			if err != nil {
+				return "", err
+			}
+			return re.ReplaceAllString(s, replacement), nil
+		},
+	},
+	{
+		Name: "now", Category: "Dates", Usage: "now",
+		Description: "Returns the current time, when the template is executed.",
+		Examples:    []Example{{`{{ now | date "2006" | len }}`, `4`}},
+		Func:        time.Now,
+	},
+	{
+		Name: "date", Category: "Dates", Usage: "date layout t",
+		Description: "Formats the time in UTC with the layout of the Go time package, such as 2006-01-02 15:04:05. The time can also be a Unix timestamp in seconds.",
+		Examples:    []Example{{`{{ date "2006-01-02 15:04" 1634292000 }}`, `2021-10-15 10:00`}},
+		Func: func(layout string, t interface{}) (string, error) {
+			tt, err := toTime(t)
+			if err != nil {
+				return "", err
+			}
+			return tt.UTC().Format(layout), nil
+		},
+	},
+	{
+		Name: "dateInZone", Category: "Dates", Usage: "dateInZone layout t zone",
+		Description: "Formats the time like date, in the time zone of the IANA database, such as Europe/Paris.",
+		Examples:    []Example{{`{{ dateInZone "15:04 MST" 1634292000 "Europe/Paris" }}`, `12:00 CEST`}},
+		Func: func(layout string, t interface{}, zone string) (string, error) {
+			tt, err := toTime(t)
+			if err != nil {
+				return "", err
+			}
+			loc, err := time.LoadLocation(zone)
+			if err != nil {
+				return "", err
+			}
+			return tt.In(loc).Format(layout), nil
+		},
+	},
+	{
+		Name: "unixEpoch", Category: "Dates", Usage: "unixEpoch t",
+		Description: "Returns the Unix timestamp of the time in seconds.",
+		Examples:    []Example{{`{{ unixEpoch .StartsAt }}`, `1634292000`}},
+		Func: func(t interface{}) (int64, error) {
+			tt, err := toTime(t)
+			if err != nil {
+				return 0, err
+			}
+			return tt.Unix(), nil
+		},
+	},
+	{
+		Name: "since", Category: "Dates", Usage: "since t",
+		Description: "Returns the number of seconds elapsed since the time, to be used with humanizeDuration.",
+		Examples:    []Example{{`{{ if gt (since 1634292000) 0.0 }}past{{ end }}`, `past`}},
+		Func: func(t interface{}) (float64, error) {
+			tt, err := toTime(t)
+			if err != nil {
+				return 0, err
+			}
+			return time.Since(tt).Seconds(), nil
+		},
+	},
+	{
+		Name: "toJson", Category: "Encoding", Usage: "toJson v",
+		Description: "Encodes the value in JSON.",
+		Examples:    []Example{{`{{ toJson .Labels }}`, `{"alertname":"HighCPU","instance":"node-1"}`}},
+		Func: func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+	},
+	{
+		Name: "toPrettyJson", Category: "Encoding", Usage: "toPrettyJson v",
+		Description: "Encodes the value in indented JSON.",
+		Examples:    []Example{{`{{ toPrettyJson (split "," "a,b") }}`, "[\n  \"a\",\n  \"b\"\n]"}},
+		Func: func(v interface{}) (string, error) {
+			b, err := json.MarshalIndent(v, "", "  ")
+			return string(b), err
+		},
+	},
+	{
+		Name: "urlEncode", Category: "Encoding", Usage: "urlEncode s",
+		Description: "Escapes the string to be used in a query parameter of a URL.",
+		Examples:    []Example{{`https://example.com/search?q={{ urlEncode "cpu > 90%" }}`, `https://example.com/search?q=cpu+%3E+90%25`}},
+		Func:        url.QueryEscape,
+	},
+	{
+		Name: "urlPathEncode", Category: "Encoding", Usage: "urlPathEncode s",
+		Description: "Escapes the string to be used in a segment of the path of a URL.",
+		Examples:    []Example{{`https://example.com/hosts/{{ urlPathEncode "node 1/a" }}`, `https://example.com/hosts/node%201%2Fa`}},
+		Func:        url.PathEscape,
+	},
+	{
+		Name: "formatNumber", Category: "Humanize", Usage: "formatNumber decimalSeparator thousandsSeparator v",
+		Description: "Formats the number with the separators of the decimals and of the thousands, use round to limit the decimals.",
+		Examples:    []Example{{`{{ formatNumber "," " " 1234567.25 }}`, `1 234 567,25`}, {`{{ 1234.5678 | round 2 | formatNumber "." "," }}`, `1,234.57`}},
+		Func: func(decimalSep, thousandsSep string, v interface{}) (string, error) {
+			f, err := toFloat(v)
+			if err != nil {
+				return "", err
+			}
+			return formatNumber(f, decimalSep, thousandsSep), nil
+		},
+	},
+	{
+		Name: "humanize", Category: "Humanize", Usage: "humanize v",
+		Description: "Formats the number with the metric prefixes, such as k for thousands and m for thousandths.",
+		Examples:    []Example{{`{{ humanize 1234567 }}`, `1.235M`}, {`{{ humanize 0.0025 }}`, `2.5m`}},
+		Func:        unaryHumanizeFunc(humanize),
+	},
+	{
+		Name: "humanizeBytes", Category: "Humanize", Usage: "humanizeBytes v",
+		Description: "Formats the number of bytes with the binary prefixes, such as KiB for 1024 bytes.",
+		Examples:    []Example{{`{{ humanizeBytes 1536 }}`, `1.5 KiB`}, {`{{ humanizeBytes 5368709120 }}`, `5 GiB`}},
+		Func:        unaryHumanizeFunc(humanizeBytes),
+	},
+	{
+		Name: "humanizeDuration", Category: "Humanize", Usage: "humanizeDuration seconds",
+		Description: "Formats the number of seconds as a duration.",
+		Examples:    []Example{{`{{ humanizeDuration 3725 }}`, `1h 2m 5s`}, {`{{ humanizeDuration 0.25 }}`, `250ms`}},
+		Func:        unaryHumanizeFunc(humanizeDuration),
+	},
+	{
+		Name: "humanizePercentage", Category: "Humanize", Usage: "humanizePercentage ratio",
+		Description: "Formats the ratio as a percentage.",
+		Examples:    []Example{{`{{ humanizePercentage 0.1234 }}`, `12.34%`}},
+		Func: unaryHumanizeFunc(func(v float64) string {
+			return strconv.FormatFloat(math.Round(v*10000)/100, 'f', -1, 64) + "%"
+		}),
+	},
+}
+
+var funcMap = func() map[string]interface{} {
+	m := make(map[string]interface{}, len(Functions))
+	for _, f := range Functions {
+		m[f.Name] = f.Func
+	}
+	return m
+}()
+
+// FuncMap returns the functions by name, to be added to the templates. It must not be changed.
+func FuncMap() map[string]interface{} {
+	return funcMap
+}
+
+func mathFunc(f func(a, b float64) (float64, error)) func(a, b interface{}) (float64, error) {
+	return func(a, b interface{}) (float64, error) {
+		fa, err := toFloat(a)
+		if err != nil {
+			return 0, err
+		}
+		fb, err := toFloat(b)
+		if err != nil {
+			return 0, err
+		}
+		return f(fa, fb)
+	}
+}
+
+func unaryMathFunc(f func(float64) float64) func(interface{}) (float64, error) {
+	return func(v interface{}) (float64, error) {
+		fv, err := toFloat(v)
+		if err != nil {
+			return 0, err
+		}
+		return f(fv), nil
+	}
+}
+
+func unaryHumanizeFunc(f func(float64) string) func(interface{}) (string, error) {
+	return func(v interface{}) (string, error) {
+		fv, err := toFloat(v)
+		if err != nil {
+			return "", err
+		}
+		return f(fv), nil
+	}
+}
+
+// toFloat converts the numbers, the numeric strings and the values of the queries of the rules to float64.
+func toFloat(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case float64:
+		return n, nil
+	case *float64:
+		if n == nil {
+			return 0, errors.New("the value is missing")
+		}
+		return *n, nil
+	case float32:
+		return float64(n), nil
+	case int:
+		return float64(n), nil
+	case int64:
+		return float64(n), nil
+	case int32:
+		return float64(n), nil
+	case uint:
+		return float64(n), nil
+	case uint64:
+		return float64(n), nil
+	case string:
+		return strconv.ParseFloat(strings.TrimSpace(n), 64)
+	case fmt.Stringer:
+		return strconv.ParseFloat(strings.TrimSpace(n.String()), 64)
+	}
+	return 0, fmt.Errorf("%v is not a number", v)
+}
+
+// toTime converts the times and the Unix timestamps in seconds to time.Time.
+func toTime(v interface{}) (time.Time, error) {
+	if t, ok := v.(time.Time); ok {
+		return t, nil
+	}
+	if t, ok := v.(*time.Time); ok && t != nil {
+		return *t, nil
+	}
+	f, err := toFloat(v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%v is not a time", v)
+	}
+	sec, frac := math.Modf(f)
+	return time.Unix(int64(sec), int64(frac*float64(time.Second))), nil
+}
+
+func humanize(v float64) string {
+	if v == 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	if math.Abs(v) >= 1 {
+		prefix := ""
+		for _, p := range []string{"k", "M", "G", "T", "P", "E", "Z", "Y"} {
+			if math.Abs(v) < 1000 {
+				break
+			}
+			prefix = p
+			v /= 1000
+		}
+		return formatFloat(v, 4) + prefix
+	}
+	prefix := ""
+	for _, p := range []string{"m", "u", "n", "p", "f", "a", "z", "y"} {
+		if math.Abs(v) >= 1 {
+			break
+		}
+		prefix = p
+		v *= 1000
+	}
+	return formatFloat(v, 4) + prefix
+}
+
+func humanizeBytes(v float64) string {
+	if math.Abs(v) < 1024 || math.IsNaN(v) || math.IsInf(v, 0) {
+		return formatFloat(v, 4) + " B"
+	}
+	prefix := ""
+	for _, p := range []string{"KiB", "MiB", "GiB", "TiB", "PiB", "EiB"} {
+		if math.Abs(v) < 1024 {
+			break
+		}
+		prefix = p
+		v /= 1024
+	}
+	return formatFloat(v, 4) + " " + prefix
+}
+
+func humanizeDuration(v float64) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	if v == 0 {
+		return "0s"
+	}
+	sign := ""
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	if v < 1 {
+		return sign + formatFloat(v*1000, 4) + "ms"
+	}
+	seconds := int64(v) % 60
+	minutes := (int64(v) / 60) % 60
+	hours := (int64(v) / 3600) % 24
+	days := int64(v) / 86400
+	var parts []string
+	if days > 0 {
+		parts = append(parts, fmt.Sprintf("%dd", days))
+	}
+	if hours > 0 {
+		parts = append(parts, fmt.Sprintf("%dh", hours))
+	}
+	if minutes > 0 {
+		parts = append(parts, fmt.Sprintf("%dm", minutes))
+	}
+	if seconds > 0 || len(parts) == 0 {
+		parts = append(parts, fmt.Sprintf("%ds", seconds))
+	}
+	return sign + strings.Join(parts, " ")
+}
+
+func formatNumber(v float64, decimalSep, thousandsSep string) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	s := strconv.FormatFloat(math.Abs(v), 'f', -1, 64)
+	integer, decimals := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		integer, decimals = s[:i], s[i+1:]
+	}
+	var b strings.Builder
+	if v < 0 {
+		b.WriteByte('-')
+	}
+	for i, d := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteString(thousandsSep)
+		}
+		b.WriteRune(d)
+	}
+	if decimals != "" {
+		b.WriteString(decimalSep)
+		b.WriteString(decimals)
+	}
+	return b.String()
+}
+
+// formatFloat formats the number with up to the significant digits, without trailing zeros.
+func formatFloat(v float64, digits int) string {
+	return strconv.FormatFloat(v, 'g', digits, 64)
+}
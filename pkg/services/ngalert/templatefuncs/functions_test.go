@@ -0,0 +1,59 @@
+package templatefuncs
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"text/template"
+
+	amtemplate "github.com/prometheus/alertmanager/template"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFunctions(t *testing.T) {
+	t.Run("the examples render their output", func(t *testing.T) {
+		for _, f := range Functions {
+			for _, e := range f.Examples {
+				tmpl, err := template.New(f.Name).Funcs(FuncMap()).Parse(e.Template)
+				require.NoError(t, err)
+				var buf bytes.Buffer
+				require.NoError(t, tmpl.Execute(&buf, exampleData), e.Template)
+				require.Equal(t, e.Output, buf.String(), e.Template)
+			}
+		}
+	})
+
+	t.Run("the functions are documented in a category", func(t *testing.T) {
+		categories := make(map[string]bool, len(Categories))
+		for _, c := range Categories {
+			categories[c] = true
+		}
+		names := map[string]bool{}
+		for _, f := range Functions {
+			require.True(t, categories[f.Category], f.Name)
+			require.NotEmpty(t, f.Examples, f.Name)
+			require.False(t, names[f.Name], "%s is registered twice", f.Name)
+			names[f.Name] = true
+			_, ok := amtemplate.DefaultFuncs[f.Name]
+			require.False(t, ok, "%s overrides a function of the Alertmanager", f.Name)
+		}
+	})
+
+	t.Run("the functions fail on invalid arguments", func(t *testing.T) {
+		for _, text := range []string{`{{ div 1 0 }}`, `{{ add "a" 1 }}`, `{{ regexMatch "(" "a" }}`, `{{ date "2006" "yesterday" }}`, `{{ dateInZone "15:04" 0 "Nowhere/Nothing" }}`} {
+			tmpl, err := template.New("").Funcs(FuncMap()).Parse(text)
+			require.NoError(t, err)
+			require.Error(t, tmpl.Execute(&bytes.Buffer{}, nil), text)
+		}
+	})
+
+	t.Run("the documentation is up to date", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, WriteDocs(&buf))
+		//nolint
+		current, err := ioutil.ReadFile(filepath.Join("../../../..", DocsPath))
+		require.NoError(t, err)
+		require.Equal(t, string(current), buf.String(), "%s is out of date, run go run ./pkg/services/ngalert/templatefuncs/cmd/docs", DocsPath)
+	})
+}
@@ -102,13 +102,15 @@ func (ns *NotificationService) Run(ctx context.Context) error {
 
 func (ns *NotificationService) SendWebhookSync(ctx context.Context, cmd *models.SendWebhookSync) error {
 	return ns.sendWebRequestSync(ctx, &Webhook{
-		Url:         cmd.Url,
-		User:        cmd.User,
-		Password:    cmd.Password,
-		Body:        cmd.Body,
-		HttpMethod:  cmd.HttpMethod,
-		HttpHeader:  cmd.HttpHeader,
-		ContentType: cmd.ContentType,
+		Url:          cmd.Url,
+		User:         cmd.User,
+		Password:     cmd.Password,
+		Body:         cmd.Body,
+		HttpMethod:   cmd.HttpMethod,
+		HttpHeader:   cmd.HttpHeader,
+		ContentType:  cmd.ContentType,
+		ProxyURL:     cmd.ProxyURL,
+		ResponseBody: cmd.ResponseBody,
 	})
 }
 
@@ -9,6 +9,8 @@ import (
 	"io/ioutil"
 	"net"
 	"net/http"
+	"net/url"
+	"sync"
 	"time"
 
 	"golang.org/x/net/context/ctxhttp"
@@ -24,8 +26,14 @@ type Webhook struct {
 	HttpMethod  string
 	HttpHeader  map[string]string
 	ContentType string
+	ProxyURL    string
+	// ResponseBody, if not nil, is called with the body of a successful response, up to maxWebhookResponseBody.
+	ResponseBody func(body []byte)
 }
 
+// maxWebhookResponseBody is the maximum size of the body of a response read for Webhook.ResponseBody.
+const maxWebhookResponseBody = 64 << 10
+
 var netTransport = &http.Transport{
 	TLSClientConfig: &tls.Config{
 		Renegotiation: tls.RenegotiateFreelyAsClient,
@@ -41,6 +49,38 @@ var netClient = &http.Client{
 	Transport: netTransport,
 }
 
+var (
+	proxyClientsMtx sync.Mutex
+	// proxyClients are the clients of the webhooks sent through a proxy, by proxy URL.
+	proxyClients = map[string]*http.Client{}
+)
+
+// clientFor returns the client sending requests through the proxy, or the default client if proxyURL is empty.
+func clientFor(proxyURL string) (*http.Client, error) {
+	if proxyURL == "" {
+		return netClient, nil
+	}
+
+	proxyClientsMtx.Lock()
+	defer proxyClientsMtx.Unlock()
+	if c, ok := proxyClients[proxyURL]; ok {
+		return c, nil
+	}
+
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	transport := netTransport.Clone()
+	transport.Proxy = http.ProxyURL(u)
+	c := &http.Client{
+		Timeout:   netClient.Timeout,
+		Transport: transport,
+	}
+	proxyClients[proxyURL] = c
+	return c, nil
+}
+
 func (ns *NotificationService) sendWebRequestSync(ctx context.Context, webhook *Webhook) error {
 	ns.log.Debug("Sending webhook", "url", webhook.Url, "http method", webhook.HttpMethod)
 
@@ -72,7 +112,12 @@ func (ns *NotificationService) sendWebRequestSync(ctx context.Context, webhook *
 		request.Header.Set(k, v)
 	}
 
-	resp, err := ctxhttp.Do(ctx, netClient, request)
+	client, err := clientFor(webhook.ProxyURL)
+	if err != nil {
+		return err
+	}
+
+	resp, err := ctxhttp.Do(ctx, client, request)
 	if err != nil {
 		return err
 	}
@@ -84,6 +129,14 @@ func (ns *NotificationService) sendWebRequestSync(ctx context.Context, webhook *
 
 	if resp.StatusCode/100 == 2 {
 		ns.log.Debug("Webhook succeeded", "url", webhook.Url, "statuscode", resp.Status)
+		if webhook.ResponseBody != nil {
+			body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxWebhookResponseBody))
+			if err != nil {
+				ns.log.Warn("Failed to read the response body of the webhook", "url", webhook.Url, "err", err)
+			} else {
+				webhook.ResponseBody(body)
+			}
+		}
 		// flushing the body enables the transport to reuse the same connection
 		if _, err := io.Copy(ioutil.Discard, resp.Body); err != nil {
 			ns.log.Error("Failed to copy resp.Body to ioutil.Discard", "err", err)
@@ -0,0 +1,334 @@
+package alerting
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	amv2 "github.com/prometheus/alertmanager/api/v2/models"
+	"github.com/prometheus/alertmanager/config"
+	"github.com/prometheus/common/model"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	dboards "github.com/grafana/grafana/pkg/dashboards"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/ngalert/provisioning"
+)
+
+// muteTimingCreator is the creator of the recurring silences of the provisioned mute timings.
+const muteTimingCreator = "provisioning"
+
+// Services are the alerting services the provisioned resources are applied with.
+type Services struct {
+	AlertRules          *provisioning.AlertRuleService
+	AlertmanagerConfigs *provisioning.AlertmanagerConfigService
+	MuteTimings         *provisioning.MuteTimingService
+}
+
+// Provision alert rules, contact points, notification policies, mute timings and templates
+func Provision(configDirectory string, store dboards.Store, services Services) error {
+	ap := newAlertingProvisioner(log.New("provisioning.alerting"), store, services, ngmodels.ProvenanceFile)
+	return ap.applyChanges(configDirectory)
+}
+
+// AlertingProvisioner is responsible for provisioning the alerting resources
+type AlertingProvisioner struct {
+	log              log.Logger
+	cfgProvider      *configReader
+	dashboardService dashboards.DashboardProvisioningService
+	services         Services
+	// provenance is the provenance of the provisioned resources.
+	provenance ngmodels.Provenance
+}
+
+func newAlertingProvisioner(log log.Logger, store dboards.Store, services Services, provenance ngmodels.Provenance) AlertingProvisioner {
+	return AlertingProvisioner{
+		log:              log,
+		cfgProvider:      &configReader{log: log},
+		dashboardService: dashboards.NewProvisioningService(store),
+		services:         services,
+		provenance:       provenance,
+	}
+}
+
+func (ap *AlertingProvisioner) applyChanges(configPath string) error {
+	configs, err := ap.cfgProvider.readConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	for _, cfg := range configs {
+		if err := ap.apply(cfg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (ap *AlertingProvisioner) apply(cfg *alertingAsConfig) error {
+	if err := ap.deleteRules(cfg.DeleteRules); err != nil {
+		return err
+	}
+	if err := ap.provisionRuleGroups(cfg.RuleGroups); err != nil {
+		return err
+	}
+	if err := ap.provisionAlertmanagerConfigs(cfg); err != nil {
+		return err
+	}
+	if err := ap.deleteMuteTimings(cfg.DeleteMuteTimings); err != nil {
+		return err
+	}
+	return ap.provisionMuteTimings(cfg.MuteTimings)
+}
+
+func (ap *AlertingProvisioner) deleteRules(rules []*deleteRuleConfig) error {
+	for _, rule := range rules {
+		ap.log.Info("Deleting alert rule", "org", rule.OrgID, "uid", rule.UID)
+		if err := ap.services.AlertRules.DeleteAlertRule(rule.OrgID, rule.UID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ap *AlertingProvisioner) provisionRuleGroups(groups []*ruleGroupFromConfig) error {
+	for _, group := range groups {
+		folder, err := ap.getOrCreateFolder(group.OrgID, group.Folder)
+		if err != nil {
+			return fmt.Errorf("failed to provision the folder of rule group %q: %w", group.Name, err)
+		}
+
+		ruleGroupConfig, err := group.toRuleGroupConfig()
+		if err != nil {
+			return fmt.Errorf("rule group %q: %w", group.Name, err)
+		}
+
+		ap.log.Debug("Provisioning rule group", "org", group.OrgID, "folder", group.Folder, "group", group.Name)
+		// The rules provisioned from files are owned by the service identity, their queries are executed with the
+		// permissions of Grafana rather than of a user.
+		if err := ap.services.AlertRules.ReplaceRuleGroup(group.OrgID, folder.Uid, ruleGroupConfig, ap.provenance, "provisioning", 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// provisionAlertmanagerConfigs applies the contact points, notification policies and templates of each organization
+// at once, so that the Alertmanager configuration is saved once per organization.
+func (ap *AlertingProvisioner) provisionAlertmanagerConfigs(cfg *alertingAsConfig) error {
+	changes := make(map[int64]*provisioning.AlertmanagerConfigChanges)
+	changesFor := func(orgID int64) *provisioning.AlertmanagerConfigChanges {
+		if _, ok := changes[orgID]; !ok {
+			changes[orgID] = &provisioning.AlertmanagerConfigChanges{}
+		}
+		return changes[orgID]
+	}
+
+	for _, cp := range cfg.ContactPoints {
+		c := changesFor(cp.OrgID)
+		c.ContactPoints = append(c.ContactPoints, cp.toPostableApiReceiver())
+	}
+	for _, cp := range cfg.DeleteContactPoints {
+		c := changesFor(cp.OrgID)
+		c.DeleteContactPoints = append(c.DeleteContactPoints, cp.Name)
+	}
+	for _, orgID := range cfg.ResetPolicies {
+		changesFor(orgID).ResetPolicies = true
+	}
+	for _, p := range cfg.Policies {
+		changesFor(p.OrgID).Policies = p.Policy
+	}
+	for _, t := range cfg.Templates {
+		c := changesFor(t.OrgID)
+		if c.Templates == nil {
+			c.Templates = make(map[string]string)
+		}
+		c.Templates[t.Name] = t.Template
+	}
+	for _, t := range cfg.DeleteTemplates {
+		c := changesFor(t.OrgID)
+		c.DeleteTemplates = append(c.DeleteTemplates, t.Name)
+	}
+
+	for orgID, c := range changes {
+		ap.log.Debug("Provisioning Alertmanager configuration", "org", orgID)
+		if err := ap.services.AlertmanagerConfigs.Apply(orgID, *c, ap.provenance); err != nil {
+			return fmt.Errorf("failed to provision the Alertmanager configuration of organization %d: %w", orgID, err)
+		}
+	}
+	return nil
+}
+
+func (ap *AlertingProvisioner) deleteMuteTimings(muteTimings []*deleteMuteTimingConfig) error {
+	for _, mt := range muteTimings {
+		ap.log.Info("Deleting mute timing", "org", mt.OrgID, "uid", mt.UID)
+		if err := ap.services.MuteTimings.DeleteMuteTiming(mt.OrgID, mt.UID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ap *AlertingProvisioner) provisionMuteTimings(muteTimings []*muteTimingFromConfig) error {
+	for _, mt := range muteTimings {
+		rs, err := mt.toRecurringSilence()
+		if err != nil {
+			return fmt.Errorf("mute timing %q: %w", mt.UID, err)
+		}
+		ap.log.Debug("Provisioning mute timing", "org", mt.OrgID, "uid", mt.UID)
+		if err := ap.services.MuteTimings.SaveMuteTiming(rs, ap.provenance); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getOrCreateFolder returns the folder with the title, it is created if it doesn't exist.
+func (ap *AlertingProvisioner) getOrCreateFolder(orgID int64, title string) (*models.Dashboard, error) {
+	cmd := &models.GetDashboardQuery{Slug: models.SlugifyTitle(title), OrgId: orgID}
+	err := bus.Dispatch(cmd)
+	if err != nil && !errors.Is(err, models.ErrDashboardNotFound) {
+		return nil, err
+	}
+
+	if errors.Is(err, models.ErrDashboardNotFound) {
+		dash := &dashboards.SaveDashboardDTO{}
+		dash.Dashboard = models.NewDashboardFolder(title)
+		dash.Dashboard.IsFolder = true
+		dash.Overwrite = true
+		dash.OrgId = orgID
+		return ap.dashboardService.SaveFolderForProvisionedDashboards(dash)
+	}
+
+	if !cmd.Result.IsFolder {
+		return nil, fmt.Errorf("got invalid response. expected folder, found dashboard")
+	}
+	return cmd.Result, nil
+}
+
+func (group *ruleGroupFromConfig) toRuleGroupConfig() (apimodels.PostableRuleGroupConfig, error) {
+	interval, err := model.ParseDuration(group.Interval)
+	if err != nil {
+		return apimodels.PostableRuleGroupConfig{}, fmt.Errorf("invalid interval: %w", err)
+	}
+
+	result := apimodels.PostableRuleGroupConfig{
+		Name:     group.Name,
+		Interval: interval,
+	}
+	for _, rule := range group.Rules {
+		node := &apimodels.ApiRuleNode{
+			Labels:      rule.Labels,
+			Annotations: rule.Annotations,
+		}
+		if rule.For != "" {
+			node.For, err = model.ParseDuration(rule.For)
+			if err != nil {
+				return apimodels.PostableRuleGroupConfig{}, fmt.Errorf("rule %q has an invalid for: %w", rule.UID, err)
+			}
+		}
+
+		data := make([]ngmodels.AlertQuery, 0, len(rule.Data))
+		for _, query := range rule.Data {
+			queryModel, err := json.Marshal(query.Model)
+			if err != nil {
+				return apimodels.PostableRuleGroupConfig{}, fmt.Errorf("rule %q has an invalid query model: %w", rule.UID, err)
+			}
+			data = append(data, ngmodels.AlertQuery{
+				RefID:         query.RefID,
+				QueryType:     query.QueryType,
+				DatasourceUID: query.DatasourceUID,
+				RelativeTimeRange: ngmodels.RelativeTimeRange{
+					From: ngmodels.Duration(time.Duration(query.From) * time.Second),
+					To:   ngmodels.Duration(time.Duration(query.To) * time.Second),
+				},
+				Model: queryModel,
+			})
+		}
+
+		result.Rules = append(result.Rules, apimodels.PostableExtendedRuleNode{
+			ApiRuleNode: node,
+			GrafanaManagedAlert: &apimodels.PostableGrafanaRule{
+				UID:          rule.UID,
+				Title:        rule.Title,
+				Condition:    rule.Condition,
+				Data:         data,
+				NoDataState:  apimodels.NoDataState(rule.NoDataState),
+				ExecErrState: apimodels.ExecutionErrorState(rule.ExecErrState),
+			},
+		})
+	}
+	return result, nil
+}
+
+func (cp *contactPointFromConfig) toPostableApiReceiver() *apimodels.PostableApiReceiver {
+	receivers := make([]*apimodels.PostableGrafanaReceiver, 0, len(cp.Receivers))
+	for _, r := range cp.Receivers {
+		receivers = append(receivers, &apimodels.PostableGrafanaReceiver{
+			UID:                   r.UID,
+			Name:                  cp.Name,
+			Type:                  r.Type,
+			DisableResolveMessage: r.DisableResolveMessage,
+			Settings:              simplejson.NewFromAny(r.Settings),
+			SecureSettings:        r.SecureSettings,
+		})
+	}
+	return &apimodels.PostableApiReceiver{
+		Receiver: config.Receiver{Name: cp.Name},
+		PostableGrafanaReceivers: apimodels.PostableGrafanaReceivers{
+			GrafanaManagedReceivers: receivers,
+		},
+	}
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+func (mt *muteTimingFromConfig) toRecurringSilence() (*ngmodels.RecurringSilence, error) {
+	duration, err := time.ParseDuration(mt.Duration)
+	if err != nil {
+		return nil, fmt.Errorf("invalid duration: %w", err)
+	}
+
+	rs := &ngmodels.RecurringSilence{
+		OrgID:           mt.OrgID,
+		UID:             mt.UID,
+		Comment:         mt.Comment,
+		CreatedBy:       muteTimingCreator,
+		StartTime:       mt.StartTime,
+		DurationSeconds: int64(duration.Seconds()),
+		Timezone:        mt.Timezone,
+	}
+	for _, name := range mt.Weekdays {
+		weekday, ok := weekdays[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("invalid weekday %q", name)
+		}
+		rs.Weekdays = append(rs.Weekdays, weekday)
+	}
+	for _, m := range mt.Matchers {
+		m := m
+		rs.Matchers = append(rs.Matchers, &amv2.Matcher{
+			Name:    &m.Name,
+			Value:   &m.Value,
+			IsRegex: &m.IsRegex,
+			IsEqual: &m.IsEqual,
+		})
+	}
+	return rs, nil
+}
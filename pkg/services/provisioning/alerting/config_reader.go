@@ -0,0 +1,237 @@
+package alerting
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/provisioning/utils"
+	"gopkg.in/yaml.v2"
+)
+
+type configReader struct {
+	log log.Logger
+}
+
+func (cr *configReader) readConfig(path string) ([]*alertingAsConfig, error) {
+	var alertingConfigs []*alertingAsConfig
+	cr.log.Debug("Looking for alerting provisioning files", "path", path)
+
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		cr.log.Error("Can't read alerting provisioning files from directory", "path", path, "error", err)
+		return alertingConfigs, nil
+	}
+
+	for _, file := range files {
+		if strings.HasSuffix(file.Name(), ".yaml") || strings.HasSuffix(file.Name(), ".yml") {
+			cr.log.Debug("Parsing alerting provisioning file", "path", path, "file.Name", file.Name())
+			cfg, err := cr.parseConfig(path, file)
+			if err != nil {
+				return nil, fmt.Errorf("failure to parse file %s: %w", file.Name(), err)
+			}
+
+			if cfg != nil {
+				alertingConfigs = append(alertingConfigs, cfg)
+			}
+		}
+	}
+
+	cr.log.Debug("Validating alerting provisioning files")
+	if err := validateRequiredFields(alertingConfigs); err != nil {
+		return nil, err
+	}
+
+	if err := checkOrgIDs(alertingConfigs); err != nil {
+		return nil, err
+	}
+
+	return alertingConfigs, nil
+}
+
+func (cr *configReader) parseConfig(path string, file os.FileInfo) (*alertingAsConfig, error) {
+	filename, _ := filepath.Abs(filepath.Join(path, file.Name()))
+
+	// nolint:gosec
+	// We can ignore the gosec G304 warning on this one because `filename` comes from ps.Cfg.ProvisioningPath
+	yamlFile, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiVersion *configVersion
+	if err := yaml.Unmarshal(yamlFile, &apiVersion); err != nil {
+		return nil, err
+	}
+	if apiVersion == nil {
+		// The file is empty.
+		return nil, nil
+	}
+	if v := apiVersion.APIVersion.Value(); v != 1 {
+		return nil, fmt.Errorf("unsupported apiVersion %d, the only supported version is 1", v)
+	}
+
+	var cfg *alertingAsConfigV1
+	if err := yaml.Unmarshal(yamlFile, &cfg); err != nil {
+		return nil, err
+	}
+
+	return cfg.mapToAlertingFromConfig(), nil
+}
+
+// checkOrgIDs sets the organization of the resources without one to the main organization, and checks that
+// the organizations of the provisioned resources exist.
+func checkOrgIDs(configs []*alertingAsConfig) error {
+	orgIDs := make([]*int64, 0)
+	for _, cfg := range configs {
+		for _, g := range cfg.RuleGroups {
+			orgIDs = append(orgIDs, &g.OrgID)
+		}
+		for _, cp := range cfg.ContactPoints {
+			orgIDs = append(orgIDs, &cp.OrgID)
+		}
+		for _, p := range cfg.Policies {
+			orgIDs = append(orgIDs, &p.OrgID)
+		}
+		for i := range cfg.ResetPolicies {
+			orgIDs = append(orgIDs, &cfg.ResetPolicies[i])
+		}
+		for _, mt := range cfg.MuteTimings {
+			orgIDs = append(orgIDs, &mt.OrgID)
+		}
+		for _, t := range cfg.Templates {
+			orgIDs = append(orgIDs, &t.OrgID)
+		}
+		for _, r := range cfg.DeleteRules {
+			orgIDs = append(orgIDs, &r.OrgID)
+		}
+		for _, cp := range cfg.DeleteContactPoints {
+			orgIDs = append(orgIDs, &cp.OrgID)
+		}
+		for _, mt := range cfg.DeleteMuteTimings {
+			orgIDs = append(orgIDs, &mt.OrgID)
+		}
+		for _, t := range cfg.DeleteTemplates {
+			orgIDs = append(orgIDs, &t.OrgID)
+		}
+	}
+
+	checked := make(map[int64]struct{})
+	for _, orgID := range orgIDs {
+		if *orgID < 1 {
+			*orgID = 1
+		}
+		if _, ok := checked[*orgID]; ok {
+			continue
+		}
+		if err := utils.CheckOrgExists(*orgID); err != nil {
+			return fmt.Errorf("failed to provision alerting resources of organization %d: %w", *orgID, err)
+		}
+		checked[*orgID] = struct{}{}
+	}
+	return nil
+}
+
+func validateRequiredFields(configs []*alertingAsConfig) error {
+	for _, cfg := range configs {
+		var errStrings []string
+		missing := func(item string, index int, field string) {
+			errStrings = append(errStrings, fmt.Sprintf("%s item %d in configuration doesn't contain required field %s", item, index+1, field))
+		}
+
+		for i, g := range cfg.RuleGroups {
+			if g.Name == "" {
+				missing("Rule group", i, "name")
+			}
+			if g.Folder == "" {
+				missing("Rule group", i, "folder")
+			}
+			if g.Interval == "" {
+				missing("Rule group", i, "interval")
+			}
+			for j, r := range g.Rules {
+				item := fmt.Sprintf("Rule of rule group %d,", i+1)
+				if r.UID == "" {
+					missing(item, j, "uid")
+				}
+				if r.Title == "" {
+					missing(item, j, "title")
+				}
+				if r.Condition == "" {
+					missing(item, j, "condition")
+				}
+				if len(r.Data) == 0 {
+					missing(item, j, "data")
+				}
+			}
+		}
+		for i, r := range cfg.DeleteRules {
+			if r.UID == "" {
+				missing("Deleted rule", i, "uid")
+			}
+		}
+
+		for i, cp := range cfg.ContactPoints {
+			if cp.Name == "" {
+				missing("Contact point", i, "name")
+			}
+			if len(cp.Receivers) == 0 {
+				missing("Contact point", i, "receivers")
+			}
+			for j, r := range cp.Receivers {
+				item := fmt.Sprintf("Receiver of contact point %d,", i+1)
+				if r.UID == "" {
+					missing(item, j, "uid")
+				}
+				if r.Type == "" {
+					missing(item, j, "type")
+				}
+			}
+		}
+		for i, cp := range cfg.DeleteContactPoints {
+			if cp.Name == "" {
+				missing("Deleted contact point", i, "name")
+			}
+		}
+
+		for i, p := range cfg.Policies {
+			if p.Policy == nil {
+				missing("Policies", i, "policy")
+			}
+		}
+
+		for i, mt := range cfg.MuteTimings {
+			if mt.UID == "" {
+				missing("Mute timing", i, "uid")
+			}
+			if mt.Duration == "" {
+				missing("Mute timing", i, "duration")
+			}
+		}
+		for i, mt := range cfg.DeleteMuteTimings {
+			if mt.UID == "" {
+				missing("Deleted mute timing", i, "uid")
+			}
+		}
+
+		for i, t := range cfg.Templates {
+			if t.Name == "" {
+				missing("Template", i, "name")
+			}
+		}
+		for i, t := range cfg.DeleteTemplates {
+			if t.Name == "" {
+				missing("Deleted template", i, "name")
+			}
+		}
+
+		if len(errStrings) != 0 {
+			return fmt.Errorf(strings.Join(errStrings, "\n"))
+		}
+	}
+
+	return nil
+}
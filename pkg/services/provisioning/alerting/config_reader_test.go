@@ -0,0 +1,145 @@
+package alerting
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+const (
+	allResources       = "testdata/all-resources"
+	noRequiredFields   = "testdata/no-required-fields"
+	unsupportedVersion = "testdata/unsupported-version"
+	brokenYaml         = "testdata/broken-yaml"
+	emptyFile          = "testdata/empty"
+)
+
+func TestConfigReader(t *testing.T) {
+	bus.ClearBusHandlers()
+	var checkedOrgs []int64
+	bus.AddHandler("test", func(q *models.GetOrgByIdQuery) error {
+		checkedOrgs = append(checkedOrgs, q.Id)
+		if q.Id > 3 {
+			return models.ErrOrgNotFound
+		}
+		return nil
+	})
+	t.Cleanup(bus.ClearBusHandlers)
+
+	cr := &configReader{log: log.New("test logger")}
+
+	t.Run("can read all the resources", func(t *testing.T) {
+		checkedOrgs = nil
+		t.Setenv("TEST_VAR", "sre")
+		configs, err := cr.readConfig(allResources)
+		require.NoError(t, err)
+		require.Len(t, configs, 1)
+		cfg := configs[0]
+
+		require.Len(t, cfg.RuleGroups, 1)
+		group := cfg.RuleGroups[0]
+		assert.Equal(t, int64(1), group.OrgID)
+		assert.Equal(t, "my_rule_group", group.Name)
+		assert.Equal(t, "my_first_folder", group.Folder)
+		require.Len(t, group.Rules, 1)
+		rule := group.Rules[0]
+		assert.Equal(t, "my_id_1", rule.UID)
+		assert.Equal(t, map[string]string{"team": "sre"}, rule.Labels)
+		require.Len(t, rule.Data, 1)
+		assert.Equal(t, int64(600), rule.Data[0].From)
+		assert.Equal(t, map[string]interface{}{"type": "math", "expression": "2 + 3 > 1"}, rule.Data[0].Model)
+		assert.Equal(t, []*deleteRuleConfig{{OrgID: 2, UID: "my_id_2"}}, cfg.DeleteRules)
+
+		require.Len(t, cfg.ContactPoints, 1)
+		require.Len(t, cfg.ContactPoints[0].Receivers, 1)
+		assert.Equal(t, map[string]string{"password": "secret"}, cfg.ContactPoints[0].Receivers[0].SecureSettings)
+		assert.Equal(t, []*deleteByNameConfig{{OrgID: 1, Name: "my_old_contact_point"}}, cfg.DeleteContactPoints)
+
+		require.Len(t, cfg.Policies, 1)
+		assert.Equal(t, "my_email", cfg.Policies[0].Policy.Receiver)
+		assert.Equal(t, []int64{3}, cfg.ResetPolicies)
+
+		require.Len(t, cfg.MuteTimings, 1)
+		mt := cfg.MuteTimings[0]
+		assert.Equal(t, []*matcherFromConfig{
+			{Name: "team", Value: "sre", IsEqual: true},
+			{Name: "env", Value: "dev.*", IsRegex: true, IsEqual: false},
+		}, mt.Matchers)
+		rs, err := mt.toRecurringSilence()
+		require.NoError(t, err)
+		assert.Equal(t, []time.Weekday{time.Saturday, time.Sunday}, rs.Weekdays)
+		assert.Equal(t, int64(7200), rs.DurationSeconds)
+		require.NoError(t, rs.Validate())
+
+		require.Len(t, cfg.Templates, 1)
+		assert.Equal(t, "my_template", cfg.Templates[0].Name)
+		assert.Equal(t, []*deleteByNameConfig{{OrgID: 1, Name: "my_old_template"}}, cfg.DeleteTemplates)
+
+		assert.ElementsMatch(t, []int64{1, 2, 3}, checkedOrgs)
+	})
+
+	t.Run("the rule groups are converted to Grafana managed rules", func(t *testing.T) {
+		configs, err := cr.readConfig(allResources)
+		require.NoError(t, err)
+
+		group, err := configs[0].RuleGroups[0].toRuleGroupConfig()
+		require.NoError(t, err)
+		assert.Equal(t, time.Minute, time.Duration(group.Interval))
+		require.Len(t, group.Rules, 1)
+		assert.Equal(t, 5*time.Minute, time.Duration(group.Rules[0].ApiRuleNode.For))
+		assert.Equal(t, "my_id_1", group.Rules[0].GrafanaManagedAlert.UID)
+		assert.Equal(t, 10*time.Minute, time.Duration(group.Rules[0].GrafanaManagedAlert.Data[0].RelativeTimeRange.From))
+		assert.JSONEq(t, `{"type": "math", "expression": "2 + 3 > 1"}`, string(group.Rules[0].GrafanaManagedAlert.Data[0].Model))
+	})
+
+	t.Run("the required fields are validated", func(t *testing.T) {
+		_, err := cr.readConfig(noRequiredFields)
+		require.Error(t, err)
+		for _, missing := range []string{
+			"Rule group item 1 in configuration doesn't contain required field folder",
+			"Rule group item 1 in configuration doesn't contain required field interval",
+			"Rule of rule group 1, item 1 in configuration doesn't contain required field uid",
+			"Receiver of contact point 1, item 1 in configuration doesn't contain required field uid",
+			"Mute timing item 1 in configuration doesn't contain required field uid",
+		} {
+			assert.Contains(t, err.Error(), missing)
+		}
+	})
+
+	t.Run("the organizations must exist", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(dir+"/alerting.yaml", []byte("apiVersion: 1\nresetPolicies: [4]\n"), 0600))
+		_, err := cr.readConfig(dir)
+		require.ErrorIs(t, err, models.ErrOrgNotFound)
+	})
+
+	t.Run("only the first version is supported", func(t *testing.T) {
+		_, err := cr.readConfig(unsupportedVersion)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported apiVersion 2")
+	})
+
+	t.Run("broken yaml returns an error", func(t *testing.T) {
+		_, err := cr.readConfig(brokenYaml)
+		require.Error(t, err)
+	})
+
+	t.Run("empty files are skipped", func(t *testing.T) {
+		configs, err := cr.readConfig(emptyFile)
+		require.NoError(t, err)
+		assert.Empty(t, configs)
+	})
+
+	t.Run("a missing directory is not an error", func(t *testing.T) {
+		configs, err := cr.readConfig("testdata/doesnotexist")
+		require.NoError(t, err)
+		assert.Empty(t, configs)
+	})
+}
@@ -0,0 +1,341 @@
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	dboards "github.com/grafana/grafana/pkg/dashboards"
+	"github.com/grafana/grafana/pkg/infra/log"
+	apimodels "github.com/grafana/grafana/pkg/services/ngalert/api/tooling/definitions"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// gitSyncTimeout is the timeout of the pull of the repository.
+const gitSyncTimeout = 5 * time.Minute
+
+// GitSyncConfig is the Git repository the alerting resources are provisioned from.
+type GitSyncConfig struct {
+	Repository string
+	Branch     string
+	// Path is the directory of the provisioning files in the repository, its root if empty.
+	Path     string
+	Interval time.Duration
+	// Dir is the directory of the clone of the repository.
+	Dir string
+}
+
+// GitSync provisions the alerting resources of the provisioning files of a Git repository, pulled on an interval.
+// The resources get the git provenance. The files are not applied while some of their resources are provisioned
+// otherwise or taken over in the UI, these conflicts are reported in the status.
+type GitSync struct {
+	cfg GitSyncConfig
+	log log.Logger
+
+	readConfig func(path string) ([]*alertingAsConfig, error)
+	conflicts  func(configs []*alertingAsConfig) ([]apimodels.GitSyncConflict, error)
+	apply      func(cfg *alertingAsConfig) error
+	git        func(ctx context.Context, dir string, args ...string) (string, error)
+
+	// syncMtx serializes the syncs, statusMtx protects the status.
+	syncMtx   sync.Mutex
+	statusMtx sync.RWMutex
+	status    apimodels.GitSyncStatus
+}
+
+func NewGitSync(cfg GitSyncConfig, store dboards.Store, services Services) *GitSync {
+	logger := log.New("provisioning.alerting.git")
+	ap := newAlertingProvisioner(logger, store, services, ngmodels.ProvenanceGit)
+	return &GitSync{
+		cfg:        cfg,
+		log:        logger,
+		readConfig: ap.cfgProvider.readConfig,
+		conflicts:  ap.conflicts,
+		apply:      ap.apply,
+		git:        runGit,
+		status: apimodels.GitSyncStatus{
+			Repository: redactRepository(cfg.Repository),
+			Branch:     cfg.Branch,
+			Path:       cfg.Path,
+		},
+	}
+}
+
+// Run syncs the repository on the interval until the context is canceled.
+func (gs *GitSync) Run(ctx context.Context) error {
+	gs.log.Info("starting Git sync", "repository", gs.status.Repository, "branch", gs.cfg.Branch, "path", gs.cfg.Path, "interval", gs.cfg.Interval)
+	gs.Sync(ctx, false)
+
+	ticker := time.NewTicker(gs.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			gs.Sync(ctx, false)
+		}
+	}
+}
+
+// Status returns the status of the last sync.
+func (gs *GitSync) Status() apimodels.GitSyncStatus {
+	gs.statusMtx.RLock()
+	defer gs.statusMtx.RUnlock()
+	s := gs.status
+	s.Conflicts = append([]apimodels.GitSyncConflict(nil), gs.status.Conflicts...)
+	return s
+}
+
+// Sync pulls the repository and applies its files, unless the commit was already applied without force.
+// It returns the status of the sync.
+func (gs *GitSync) Sync(ctx context.Context, force bool) apimodels.GitSyncStatus {
+	gs.syncMtx.Lock()
+	defer gs.syncMtx.Unlock()
+
+	s := gs.Status()
+	s.LastSync = time.Now()
+	commit, conflicts, err := gs.sync(ctx, s, force)
+	switch {
+	case err != nil:
+		s.Error = strings.ReplaceAll(err.Error(), gs.cfg.Repository, s.Repository)
+		s.Conflicts = conflicts
+		gs.log.Error("failed to sync the alerting resources from Git", "commit", commit, "err", s.Error, "conflicts", len(conflicts))
+	case commit != s.Commit || s.Error != "" || force:
+		gs.log.Info("synced the alerting resources from Git", "commit", commit)
+		s.Commit = commit
+		s.LastSuccess = s.LastSync
+		s.Error = ""
+		s.Conflicts = nil
+	}
+
+	gs.statusMtx.Lock()
+	gs.status = s
+	gs.statusMtx.Unlock()
+	return s
+}
+
+func (gs *GitSync) sync(ctx context.Context, last apimodels.GitSyncStatus, force bool) (string, []apimodels.GitSyncConflict, error) {
+	ctx, cancel := context.WithTimeout(ctx, gitSyncTimeout)
+	defer cancel()
+	commit, err := gs.pull(ctx)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to pull the repository: %w", err)
+	}
+	if commit == last.Commit && last.Error == "" && !force {
+		return commit, nil, nil
+	}
+
+	path := filepath.Join(gs.cfg.Dir, gs.cfg.Path)
+	if _, err := os.Stat(path); err != nil {
+		return commit, nil, fmt.Errorf("failed to read the path %q of the repository: %w", gs.cfg.Path, err)
+	}
+	configs, err := gs.readConfig(path)
+	if err != nil {
+		return commit, nil, err
+	}
+	conflicts, err := gs.conflicts(configs)
+	if err != nil {
+		return commit, nil, fmt.Errorf("failed to check the provenance of the resources: %w", err)
+	}
+	if len(conflicts) > 0 {
+		return commit, conflicts, fmt.Errorf("%d resources of the repository are managed otherwise, the commit %s is not applied", len(conflicts), commit)
+	}
+	for _, cfg := range configs {
+		if err := gs.apply(cfg); err != nil {
+			return commit, nil, err
+		}
+	}
+	return commit, nil, nil
+}
+
+// pull clones the branch of the repository, or fetches it if it is already cloned, and returns its last commit.
+func (gs *GitSync) pull(ctx context.Context) (string, error) {
+	if _, err := os.Stat(filepath.Join(gs.cfg.Dir, ".git")); errors.Is(err, os.ErrNotExist) {
+		if err := os.MkdirAll(filepath.Dir(gs.cfg.Dir), 0750); err != nil {
+			return "", err
+		}
+		if _, err := gs.git(ctx, "", "clone", "--quiet", "--depth", "1", "--single-branch", "--branch", gs.cfg.Branch, gs.cfg.Repository, gs.cfg.Dir); err != nil {
+			return "", err
+		}
+	} else {
+		// The repository of the configuration may have changed since the clone.
+		if _, err := gs.git(ctx, gs.cfg.Dir, "remote", "set-url", "origin", gs.cfg.Repository); err != nil {
+			return "", err
+		}
+		if _, err := gs.git(ctx, gs.cfg.Dir, "fetch", "--quiet", "--depth", "1", "origin", gs.cfg.Branch); err != nil {
+			return "", err
+		}
+		if _, err := gs.git(ctx, gs.cfg.Dir, "reset", "--quiet", "--hard", "FETCH_HEAD"); err != nil {
+			return "", err
+		}
+	}
+	commit, err := gs.git(ctx, gs.cfg.Dir, "rev-parse", "HEAD")
+	return strings.TrimSpace(commit), err
+}
+
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	// nolint:gosec
+	// The arguments come from the configuration of the Git sync, not from the users.
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	// Prompting for the credentials would block the sync.
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+// redactRepository removes the password from the URL of a repository.
+func redactRepository(repository string) string {
+	u, err := url.Parse(repository)
+	if err != nil || u.User == nil {
+		return repository
+	}
+	return u.Redacted()
+}
+
+// conflicts returns the resources of the configs that are provisioned with another provenance than the provenance of
+// the provisioner, or taken over in the UI.
+func (ap *AlertingProvisioner) conflicts(configs []*alertingAsConfig) ([]apimodels.GitSyncConflict, error) {
+	conflicts := make([]apimodels.GitSyncConflict, 0)
+	check := func(orgID int64, resourceType, key string, provenance ngmodels.Provenance) {
+		if provenance != ngmodels.ProvenanceNone && provenance != ap.provenance {
+			conflicts = append(conflicts, apimodels.GitSyncConflict{OrgID: orgID, ResourceType: resourceType, Key: key, Provenance: string(provenance)})
+		}
+	}
+
+	contactPoints := make(map[int64]map[string]ngmodels.Provenance)
+	contactPointProvenance := func(orgID int64, name string) (ngmodels.Provenance, error) {
+		if _, ok := contactPoints[orgID]; !ok {
+			_, provenances, err := ap.services.AlertmanagerConfigs.GetContactPoints(orgID)
+			if err != nil {
+				return ngmodels.ProvenanceNone, err
+			}
+			contactPoints[orgID] = provenances
+		}
+		return contactPoints[orgID][name], nil
+	}
+	templates := make(map[int64]map[string]ngmodels.Provenance)
+	templateProvenance := func(orgID int64, name string) (ngmodels.Provenance, error) {
+		if _, ok := templates[orgID]; !ok {
+			_, provenances, err := ap.services.AlertmanagerConfigs.GetTemplates(orgID)
+			if err != nil {
+				return ngmodels.ProvenanceNone, err
+			}
+			templates[orgID] = provenances
+		}
+		return templates[orgID][name], nil
+	}
+	ruleProvenance := func(orgID int64, uid string) (ngmodels.Provenance, error) {
+		_, provenance, err := ap.services.AlertRules.GetAlertRule(orgID, uid)
+		if errors.Is(err, ngmodels.ErrAlertRuleNotFound) {
+			return ngmodels.ProvenanceNone, nil
+		}
+		return provenance, err
+	}
+	muteTimingProvenance := func(orgID int64, uid string) (ngmodels.Provenance, error) {
+		_, provenance, err := ap.services.MuteTimings.GetMuteTiming(orgID, uid)
+		if errors.Is(err, ngmodels.ErrRecurringSilenceNotFound) {
+			return ngmodels.ProvenanceNone, nil
+		}
+		return provenance, err
+	}
+	policies := make(map[int64]struct{})
+	checkPolicies := func(orgID int64) error {
+		if _, ok := policies[orgID]; ok {
+			return nil
+		}
+		policies[orgID] = struct{}{}
+		_, provenance, err := ap.services.AlertmanagerConfigs.GetPolicies(orgID)
+		if err != nil {
+			return err
+		}
+		check(orgID, ngmodels.ResourceTypeNotificationPolicy, ngmodels.NotificationPolicyResourceKey, provenance)
+		return nil
+	}
+
+	for _, cfg := range configs {
+		for _, g := range cfg.RuleGroups {
+			for _, r := range g.Rules {
+				p, err := ruleProvenance(g.OrgID, r.UID)
+				if err != nil {
+					return nil, err
+				}
+				check(g.OrgID, ngmodels.ResourceTypeAlertRule, r.UID, p)
+			}
+		}
+		for _, r := range cfg.DeleteRules {
+			p, err := ruleProvenance(r.OrgID, r.UID)
+			if err != nil {
+				return nil, err
+			}
+			check(r.OrgID, ngmodels.ResourceTypeAlertRule, r.UID, p)
+		}
+		for _, cp := range cfg.ContactPoints {
+			p, err := contactPointProvenance(cp.OrgID, cp.Name)
+			if err != nil {
+				return nil, err
+			}
+			check(cp.OrgID, ngmodels.ResourceTypeContactPoint, cp.Name, p)
+		}
+		for _, cp := range cfg.DeleteContactPoints {
+			p, err := contactPointProvenance(cp.OrgID, cp.Name)
+			if err != nil {
+				return nil, err
+			}
+			check(cp.OrgID, ngmodels.ResourceTypeContactPoint, cp.Name, p)
+		}
+		for _, p := range cfg.Policies {
+			if err := checkPolicies(p.OrgID); err != nil {
+				return nil, err
+			}
+		}
+		for _, orgID := range cfg.ResetPolicies {
+			if err := checkPolicies(orgID); err != nil {
+				return nil, err
+			}
+		}
+		for _, mt := range cfg.MuteTimings {
+			p, err := muteTimingProvenance(mt.OrgID, mt.UID)
+			if err != nil {
+				return nil, err
+			}
+			check(mt.OrgID, ngmodels.ResourceTypeMuteTiming, mt.UID, p)
+		}
+		for _, mt := range cfg.DeleteMuteTimings {
+			p, err := muteTimingProvenance(mt.OrgID, mt.UID)
+			if err != nil {
+				return nil, err
+			}
+			check(mt.OrgID, ngmodels.ResourceTypeMuteTiming, mt.UID, p)
+		}
+		for _, t := range cfg.Templates {
+			p, err := templateProvenance(t.OrgID, t.Name)
+			if err != nil {
+				return nil, err
+			}
+			check(t.OrgID, ngmodels.ResourceTypeTemplate, t.Name, p)
+		}
+		for _, t := range cfg.DeleteTemplates {
+			p, err := templateProvenance(t.OrgID, t.Name)
+			if err != nil {
+				return nil, err
+			}
+			check(t.OrgID, ngmodels.ResourceTypeTemplate, t.Name, p)
+		}
+	}
+	return conflicts, nil
+}
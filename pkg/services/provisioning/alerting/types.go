@@ -0,0 +1,358 @@
+package alerting
+
+import (
+	"github.com/prometheus/alertmanager/config"
+
+	"github.com/grafana/grafana/pkg/services/provisioning/values"
+)
+
+type configVersion struct {
+	APIVersion values.Int64Value `json:"apiVersion" yaml:"apiVersion"`
+}
+
+// alertingAsConfig is normalized data object for alerting config data. Any config version should be mappable
+// to this type.
+type alertingAsConfig struct {
+	RuleGroups          []*ruleGroupFromConfig
+	DeleteRules         []*deleteRuleConfig
+	ContactPoints       []*contactPointFromConfig
+	DeleteContactPoints []*deleteByNameConfig
+	Policies            []*policiesFromConfig
+	ResetPolicies       []int64
+	MuteTimings         []*muteTimingFromConfig
+	DeleteMuteTimings   []*deleteMuteTimingConfig
+	Templates           []*templateFromConfig
+	DeleteTemplates     []*deleteByNameConfig
+}
+
+type ruleGroupFromConfig struct {
+	OrgID    int64
+	Name     string
+	Folder   string
+	Interval string
+	Rules    []*ruleFromConfig
+}
+
+type ruleFromConfig struct {
+	UID          string
+	Title        string
+	Condition    string
+	Data         []*queryFromConfig
+	NoDataState  string
+	ExecErrState string
+	For          string
+	Labels       map[string]string
+	Annotations  map[string]string
+}
+
+type queryFromConfig struct {
+	RefID         string
+	QueryType     string
+	DatasourceUID string
+	From          int64
+	To            int64
+	Model         map[string]interface{}
+}
+
+type deleteRuleConfig struct {
+	OrgID int64
+	UID   string
+}
+
+type contactPointFromConfig struct {
+	OrgID     int64
+	Name      string
+	Receivers []*receiverFromConfig
+}
+
+type receiverFromConfig struct {
+	UID                   string
+	Type                  string
+	DisableResolveMessage bool
+	Settings              map[string]interface{}
+	SecureSettings        map[string]string
+}
+
+type deleteByNameConfig struct {
+	OrgID int64
+	Name  string
+}
+
+type policiesFromConfig struct {
+	OrgID  int64
+	Policy *config.Route
+}
+
+type muteTimingFromConfig struct {
+	OrgID     int64
+	UID       string
+	Matchers  []*matcherFromConfig
+	Comment   string
+	Weekdays  []string
+	StartTime string
+	Duration  string
+	Timezone  string
+}
+
+type matcherFromConfig struct {
+	Name    string
+	Value   string
+	IsRegex bool
+	IsEqual bool
+}
+
+type deleteMuteTimingConfig struct {
+	OrgID int64
+	UID   string
+}
+
+type templateFromConfig struct {
+	OrgID    int64
+	Name     string
+	Template string
+}
+
+// alertingAsConfigV1 is mapping for the first version of the alerting configs. This is mapped to its normalized version.
+type alertingAsConfigV1 struct {
+	configVersion
+
+	Groups              []*ruleGroupFromConfigV1    `json:"groups" yaml:"groups"`
+	DeleteRules         []*deleteRuleConfigV1       `json:"deleteRules" yaml:"deleteRules"`
+	ContactPoints       []*contactPointFromConfigV1 `json:"contactPoints" yaml:"contactPoints"`
+	DeleteContactPoints []*deleteByNameConfigV1     `json:"deleteContactPoints" yaml:"deleteContactPoints"`
+	Policies            []*policiesFromConfigV1     `json:"policies" yaml:"policies"`
+	ResetPolicies       []values.Int64Value         `json:"resetPolicies" yaml:"resetPolicies"`
+	MuteTimings         []*muteTimingFromConfigV1   `json:"muteTimings" yaml:"muteTimings"`
+	DeleteMuteTimings   []*deleteMuteTimingConfigV1 `json:"deleteMuteTimings" yaml:"deleteMuteTimings"`
+	Templates           []*templateFromConfigV1     `json:"templates" yaml:"templates"`
+	DeleteTemplates     []*deleteByNameConfigV1     `json:"deleteTemplates" yaml:"deleteTemplates"`
+}
+
+type ruleGroupFromConfigV1 struct {
+	OrgID    values.Int64Value   `json:"orgId" yaml:"orgId"`
+	Name     values.StringValue  `json:"name" yaml:"name"`
+	Folder   values.StringValue  `json:"folder" yaml:"folder"`
+	Interval values.StringValue  `json:"interval" yaml:"interval"`
+	Rules    []*ruleFromConfigV1 `json:"rules" yaml:"rules"`
+}
+
+type ruleFromConfigV1 struct {
+	UID          values.StringValue    `json:"uid" yaml:"uid"`
+	Title        values.StringValue    `json:"title" yaml:"title"`
+	Condition    values.StringValue    `json:"condition" yaml:"condition"`
+	Data         []*queryFromConfigV1  `json:"data" yaml:"data"`
+	NoDataState  values.StringValue    `json:"noDataState" yaml:"noDataState"`
+	ExecErrState values.StringValue    `json:"execErrState" yaml:"execErrState"`
+	For          values.StringValue    `json:"for" yaml:"for"`
+	Labels       values.StringMapValue `json:"labels" yaml:"labels"`
+	Annotations  values.StringMapValue `json:"annotations" yaml:"annotations"`
+}
+
+type queryFromConfigV1 struct {
+	RefID             values.StringValue        `json:"refId" yaml:"refId"`
+	QueryType         values.StringValue        `json:"queryType" yaml:"queryType"`
+	DatasourceUID     values.StringValue        `json:"datasourceUid" yaml:"datasourceUid"`
+	RelativeTimeRange relativeTimeRangeConfigV1 `json:"relativeTimeRange" yaml:"relativeTimeRange"`
+	Model             values.JSONValue          `json:"model" yaml:"model"`
+}
+
+// relativeTimeRangeConfigV1 is the time range of a query, in seconds before the evaluation.
+type relativeTimeRangeConfigV1 struct {
+	From values.Int64Value `json:"from" yaml:"from"`
+	To   values.Int64Value `json:"to" yaml:"to"`
+}
+
+type deleteRuleConfigV1 struct {
+	OrgID values.Int64Value  `json:"orgId" yaml:"orgId"`
+	UID   values.StringValue `json:"uid" yaml:"uid"`
+}
+
+type contactPointFromConfigV1 struct {
+	OrgID     values.Int64Value       `json:"orgId" yaml:"orgId"`
+	Name      values.StringValue      `json:"name" yaml:"name"`
+	Receivers []*receiverFromConfigV1 `json:"receivers" yaml:"receivers"`
+}
+
+type receiverFromConfigV1 struct {
+	UID                   values.StringValue    `json:"uid" yaml:"uid"`
+	Type                  values.StringValue    `json:"type" yaml:"type"`
+	DisableResolveMessage values.BoolValue      `json:"disableResolveMessage" yaml:"disableResolveMessage"`
+	Settings              values.JSONValue      `json:"settings" yaml:"settings"`
+	SecureSettings        values.StringMapValue `json:"secureSettings" yaml:"secureSettings"`
+}
+
+type deleteByNameConfigV1 struct {
+	OrgID values.Int64Value  `json:"orgId" yaml:"orgId"`
+	Name  values.StringValue `json:"name" yaml:"name"`
+}
+
+// policiesFromConfigV1 is the notification policy tree of an organization, in the format of the Alertmanager route.
+type policiesFromConfigV1 struct {
+	OrgID  values.Int64Value `json:"orgId" yaml:"orgId"`
+	Policy *config.Route     `json:"policy" yaml:"policy"`
+}
+
+type muteTimingFromConfigV1 struct {
+	OrgID     values.Int64Value      `json:"orgId" yaml:"orgId"`
+	UID       values.StringValue     `json:"uid" yaml:"uid"`
+	Matchers  []*matcherFromConfigV1 `json:"matchers" yaml:"matchers"`
+	Comment   values.StringValue     `json:"comment" yaml:"comment"`
+	Weekdays  []values.StringValue   `json:"weekdays" yaml:"weekdays"`
+	StartTime values.StringValue     `json:"startTime" yaml:"startTime"`
+	Duration  values.StringValue     `json:"duration" yaml:"duration"`
+	Timezone  values.StringValue     `json:"timezone" yaml:"timezone"`
+}
+
+type matcherFromConfigV1 struct {
+	Name    values.StringValue `json:"name" yaml:"name"`
+	Value   values.StringValue `json:"value" yaml:"value"`
+	IsRegex values.BoolValue   `json:"isRegex" yaml:"isRegex"`
+	// IsEqual is a pointer so that the matchers are equality matchers by default.
+	IsEqual *values.BoolValue `json:"isEqual" yaml:"isEqual"`
+}
+
+type deleteMuteTimingConfigV1 struct {
+	OrgID values.Int64Value  `json:"orgId" yaml:"orgId"`
+	UID   values.StringValue `json:"uid" yaml:"uid"`
+}
+
+type templateFromConfigV1 struct {
+	OrgID    values.Int64Value  `json:"orgId" yaml:"orgId"`
+	Name     values.StringValue `json:"name" yaml:"name"`
+	Template values.StringValue `json:"template" yaml:"template"`
+}
+
+// mapToAlertingFromConfig maps config syntax to normalized alertingAsConfig object. Every version
+// of the config syntax should have this function.
+func (cfg *alertingAsConfigV1) mapToAlertingFromConfig() *alertingAsConfig {
+	r := &alertingAsConfig{}
+	if cfg == nil {
+		return r
+	}
+
+	for _, group := range cfg.Groups {
+		g := &ruleGroupFromConfig{
+			OrgID:    group.OrgID.Value(),
+			Name:     group.Name.Value(),
+			Folder:   group.Folder.Value(),
+			Interval: group.Interval.Value(),
+		}
+		for _, rule := range group.Rules {
+			rl := &ruleFromConfig{
+				UID:          rule.UID.Value(),
+				Title:        rule.Title.Value(),
+				Condition:    rule.Condition.Value(),
+				NoDataState:  rule.NoDataState.Value(),
+				ExecErrState: rule.ExecErrState.Value(),
+				For:          rule.For.Value(),
+				Labels:       rule.Labels.Value(),
+				Annotations:  rule.Annotations.Value(),
+			}
+			for _, query := range rule.Data {
+				rl.Data = append(rl.Data, &queryFromConfig{
+					RefID:         query.RefID.Value(),
+					QueryType:     query.QueryType.Value(),
+					DatasourceUID: query.DatasourceUID.Value(),
+					From:          query.RelativeTimeRange.From.Value(),
+					To:            query.RelativeTimeRange.To.Value(),
+					Model:         query.Model.Value(),
+				})
+			}
+			g.Rules = append(g.Rules, rl)
+		}
+		r.RuleGroups = append(r.RuleGroups, g)
+	}
+
+	for _, rule := range cfg.DeleteRules {
+		r.DeleteRules = append(r.DeleteRules, &deleteRuleConfig{
+			OrgID: rule.OrgID.Value(),
+			UID:   rule.UID.Value(),
+		})
+	}
+
+	for _, cp := range cfg.ContactPoints {
+		c := &contactPointFromConfig{
+			OrgID: cp.OrgID.Value(),
+			Name:  cp.Name.Value(),
+		}
+		for _, receiver := range cp.Receivers {
+			c.Receivers = append(c.Receivers, &receiverFromConfig{
+				UID:                   receiver.UID.Value(),
+				Type:                  receiver.Type.Value(),
+				DisableResolveMessage: receiver.DisableResolveMessage.Value(),
+				Settings:              receiver.Settings.Value(),
+				SecureSettings:        receiver.SecureSettings.Value(),
+			})
+		}
+		r.ContactPoints = append(r.ContactPoints, c)
+	}
+
+	for _, cp := range cfg.DeleteContactPoints {
+		r.DeleteContactPoints = append(r.DeleteContactPoints, &deleteByNameConfig{
+			OrgID: cp.OrgID.Value(),
+			Name:  cp.Name.Value(),
+		})
+	}
+
+	for _, policies := range cfg.Policies {
+		r.Policies = append(r.Policies, &policiesFromConfig{
+			OrgID:  policies.OrgID.Value(),
+			Policy: policies.Policy,
+		})
+	}
+
+	for _, orgID := range cfg.ResetPolicies {
+		r.ResetPolicies = append(r.ResetPolicies, orgID.Value())
+	}
+
+	for _, mt := range cfg.MuteTimings {
+		m := &muteTimingFromConfig{
+			OrgID:     mt.OrgID.Value(),
+			UID:       mt.UID.Value(),
+			Comment:   mt.Comment.Value(),
+			StartTime: mt.StartTime.Value(),
+			Duration:  mt.Duration.Value(),
+			Timezone:  mt.Timezone.Value(),
+		}
+		for _, matcher := range mt.Matchers {
+			isEqual := true
+			if matcher.IsEqual != nil {
+				isEqual = matcher.IsEqual.Value()
+			}
+			m.Matchers = append(m.Matchers, &matcherFromConfig{
+				Name:    matcher.Name.Value(),
+				Value:   matcher.Value.Value(),
+				IsRegex: matcher.IsRegex.Value(),
+				IsEqual: isEqual,
+			})
+		}
+		for _, weekday := range mt.Weekdays {
+			m.Weekdays = append(m.Weekdays, weekday.Value())
+		}
+		r.MuteTimings = append(r.MuteTimings, m)
+	}
+
+	for _, mt := range cfg.DeleteMuteTimings {
+		r.DeleteMuteTimings = append(r.DeleteMuteTimings, &deleteMuteTimingConfig{
+			OrgID: mt.OrgID.Value(),
+			UID:   mt.UID.Value(),
+		})
+	}
+
+	for _, tmpl := range cfg.Templates {
+		r.Templates = append(r.Templates, &templateFromConfig{
+			OrgID:    tmpl.OrgID.Value(),
+			Name:     tmpl.Name.Value(),
+			Template: tmpl.Template.Value(),
+		})
+	}
+
+	for _, tmpl := range cfg.DeleteTemplates {
+		r.DeleteTemplates = append(r.DeleteTemplates, &deleteByNameConfig{
+			OrgID: tmpl.OrgID.Value(),
+			Name:  tmpl.Name.Value(),
+		})
+	}
+
+	return r
+}
@@ -5,9 +5,12 @@ import (
 	"path/filepath"
 	"sync"
 
+	dboards "github.com/grafana/grafana/pkg/dashboards"
 	"github.com/grafana/grafana/pkg/infra/log"
 	plugifaces "github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/ngalert"
+	"github.com/grafana/grafana/pkg/services/provisioning/alerting"
 	"github.com/grafana/grafana/pkg/services/provisioning/dashboards"
 	"github.com/grafana/grafana/pkg/services/provisioning/datasources"
 	"github.com/grafana/grafana/pkg/services/provisioning/notifiers"
@@ -17,17 +20,19 @@ import (
 	"github.com/grafana/grafana/pkg/util/errutil"
 )
 
-func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, pluginManager plugifaces.Manager) (
-	*ProvisioningServiceImpl, error) {
+func ProvideService(cfg *setting.Cfg, sqlStore *sqlstore.SQLStore, pluginManager plugifaces.Manager,
+	alertNG *ngalert.AlertNG) (*ProvisioningServiceImpl, error) {
 	s := &ProvisioningServiceImpl{
 		Cfg:                     cfg,
 		SQLStore:                sqlStore,
 		PluginManager:           pluginManager,
+		AlertNG:                 alertNG,
 		log:                     log.New("provisioning"),
 		newDashboardProvisioner: dashboards.New,
 		provisionNotifiers:      notifiers.Provision,
 		provisionDatasources:    datasources.Provision,
 		provisionPlugins:        plugins.Provision,
+		provisionAlerting:       alerting.Provision,
 	}
 	return s, nil
 }
@@ -39,6 +44,7 @@ type ProvisioningService interface {
 	ProvisionPlugins() error
 	ProvisionNotifications() error
 	ProvisionDashboards() error
+	ProvisionAlerting() error
 	GetDashboardProvisionerResolvedPath(name string) string
 	GetAllowUIUpdatesFromConfig(name string) bool
 }
@@ -51,6 +57,7 @@ func NewProvisioningServiceImpl() *ProvisioningServiceImpl {
 		provisionNotifiers:      notifiers.Provision,
 		provisionDatasources:    datasources.Provision,
 		provisionPlugins:        plugins.Provision,
+		provisionAlerting:       alerting.Provision,
 	}
 }
 
@@ -74,6 +81,7 @@ type ProvisioningServiceImpl struct {
 	Cfg                     *setting.Cfg
 	SQLStore                *sqlstore.SQLStore
 	PluginManager           plugifaces.Manager
+	AlertNG                 *ngalert.AlertNG
 	log                     log.Logger
 	pollingCtxCancel        context.CancelFunc
 	newDashboardProvisioner dashboards.DashboardProvisionerFactory
@@ -81,6 +89,7 @@ type ProvisioningServiceImpl struct {
 	provisionNotifiers      func(string) error
 	provisionDatasources    func(string) error
 	provisionPlugins        func(string, plugifaces.Manager) error
+	provisionAlerting       func(string, dboards.Store, alerting.Services) error
 	mutex                   sync.Mutex
 }
 
@@ -100,6 +109,11 @@ func (ps *ProvisioningServiceImpl) RunInitProvisioners() error {
 		return err
 	}
 
+	err = ps.ProvisionAlerting()
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -150,6 +164,22 @@ func (ps *ProvisioningServiceImpl) ProvisionNotifications() error {
 	return errutil.Wrap("Alert notification provisioning error", err)
 }
 
+// ProvisionAlerting provisions the resources of the unified alerting, it does nothing if the unified alerting
+// is disabled.
+func (ps *ProvisioningServiceImpl) ProvisionAlerting() error {
+	if ps.AlertNG == nil || ps.AlertNG.IsDisabled() || ps.provisionAlerting == nil {
+		return nil
+	}
+
+	alertingPath := filepath.Join(ps.Cfg.ProvisioningPath, "alerting")
+	err := ps.provisionAlerting(alertingPath, ps.SQLStore, alerting.Services{
+		AlertRules:          ps.AlertNG.AlertRuleService,
+		AlertmanagerConfigs: ps.AlertNG.AlertmanagerConfigService,
+		MuteTimings:         ps.AlertNG.MuteTimingService,
+	})
+	return errutil.Wrap("Alerting provisioning error", err)
+}
+
 func (ps *ProvisioningServiceImpl) ProvisionDashboards() error {
 	dashboardPath := filepath.Join(ps.Cfg.ProvisioningPath, "dashboards")
 	dashProvisioner, err := ps.newDashboardProvisioner(dashboardPath, ps.SQLStore)
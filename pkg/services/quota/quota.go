@@ -2,6 +2,7 @@ package quota
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/models"
@@ -10,6 +11,18 @@ import (
 
 var ErrInvalidQuotaTarget = errors.New("invalid quota target")
 
+// QuotaReachedError is the error of a quota reached, the usage of the target in the scope is its limit.
+type QuotaReachedError struct {
+	Target string `json:"target"`
+	Scope  string `json:"scope"`
+	Limit  int64  `json:"limit"`
+	Used   int64  `json:"used"`
+}
+
+func (e *QuotaReachedError) Error() string {
+	return fmt.Sprintf("quota of %s reached: %d used of the %s limit of %d", e.Target, e.Used, e.Scope, e.Limit)
+}
+
 func ProvideService(cfg *setting.Cfg, tokenService models.UserTokenService) *QuotaService {
 	return &QuotaService{
 		Cfg:              cfg,
@@ -23,20 +36,47 @@ type QuotaService struct {
 }
 
 func (qs *QuotaService) QuotaReached(c *models.ReqContext, target string) (bool, error) {
+	err := qs.checkQuota(c, target, 1, nil)
+	var reached *QuotaReachedError
+	if errors.As(err, &reached) {
+		return true, nil
+	}
+	return err != nil, err
+}
+
+// CheckQuota fails with a *QuotaReachedError if the quota of the target doesn't allow added more.
+func (qs *QuotaService) CheckQuota(c *models.ReqContext, target string, added int64) error {
+	if added <= 0 {
+		return nil
+	}
+	return qs.checkQuota(c, target, added, nil)
+}
+
+// CheckQuotaWithUsage fails with a *QuotaReachedError if the quota of the target doesn't allow added more than used,
+// the usage of the target by the organization of the request. It is used for the targets without table, the usage of
+// which is counted by the services owning them.
+func (qs *QuotaService) CheckQuotaWithUsage(c *models.ReqContext, target string, used, added int64) error {
+	if added <= 0 {
+		return nil
+	}
+	return qs.checkQuota(c, target, added, &used)
+}
+
+func (qs *QuotaService) checkQuota(c *models.ReqContext, target string, added int64, orgUsed *int64) error {
 	if !qs.Cfg.Quota.Enabled {
-		return false, nil
+		return nil
 	}
 	// No request context means this is a background service, like LDAP Background Sync.
 	// TODO: we should replace the req context with a more limited interface or struct,
 	//       something that we could easily provide from background jobs.
 	if c == nil {
-		return false, nil
+		return nil
 	}
 
 	// get the list of scopes that this target is valid for. Org, User, Global
 	scopes, err := qs.getQuotaScopes(target)
 	if err != nil {
-		return false, err
+		return err
 	}
 
 	for _, scope := range scopes {
@@ -48,26 +88,26 @@ func (qs *QuotaService) QuotaReached(c *models.ReqContext, target string) (bool,
 				continue
 			}
 			if scope.DefaultLimit == 0 {
-				return true, nil
+				return &QuotaReachedError{Target: target, Scope: scope.Name}
 			}
 			if target == "session" {
 				usedSessions, err := qs.AuthTokenService.ActiveTokenCount(c.Req.Context())
 				if err != nil {
-					return false, err
+					return err
 				}
 
 				if usedSessions > scope.DefaultLimit {
 					c.Logger.Debug("Sessions limit reached", "active", usedSessions, "limit", scope.DefaultLimit)
-					return true, nil
+					return &QuotaReachedError{Target: target, Scope: scope.Name, Limit: scope.DefaultLimit, Used: usedSessions}
 				}
 				continue
 			}
 			query := models.GetGlobalQuotaByTargetQuery{Target: scope.Target, IsNgAlertEnabled: qs.Cfg.IsNgAlertEnabled()}
 			if err := bus.Dispatch(&query); err != nil {
-				return true, err
+				return err
 			}
-			if query.Result.Used >= scope.DefaultLimit {
-				return true, nil
+			if query.Result.Used+added > scope.DefaultLimit {
+				return &QuotaReachedError{Target: target, Scope: scope.Name, Limit: scope.DefaultLimit, Used: query.Result.Used}
 			}
 		case "org":
 			if !c.IsSignedIn {
@@ -80,17 +120,16 @@ func (qs *QuotaService) QuotaReached(c *models.ReqContext, target string) (bool,
 				IsNgAlertEnabled: qs.Cfg.IsNgAlertEnabled(),
 			}
 			if err := bus.Dispatch(&query); err != nil {
-				return true, err
+				return err
+			}
+			if orgUsed != nil {
+				query.Result.Used = *orgUsed
 			}
 			if query.Result.Limit < 0 {
 				continue
 			}
-			if query.Result.Limit == 0 {
-				return true, nil
-			}
-
-			if query.Result.Used >= query.Result.Limit {
-				return true, nil
+			if query.Result.Limit == 0 || query.Result.Used+added > query.Result.Limit {
+				return &QuotaReachedError{Target: target, Scope: scope.Name, Limit: query.Result.Limit, Used: query.Result.Used}
 			}
 		case "user":
 			if !c.IsSignedIn || c.UserId == 0 {
@@ -98,22 +137,18 @@ func (qs *QuotaService) QuotaReached(c *models.ReqContext, target string) (bool,
 			}
 			query := models.GetUserQuotaByTargetQuery{UserId: c.UserId, Target: scope.Target, Default: scope.DefaultLimit, IsNgAlertEnabled: qs.Cfg.IsNgAlertEnabled()}
 			if err := bus.Dispatch(&query); err != nil {
-				return true, err
+				return err
 			}
 			if query.Result.Limit < 0 {
 				continue
 			}
-			if query.Result.Limit == 0 {
-				return true, nil
-			}
-
-			if query.Result.Used >= query.Result.Limit {
-				return true, nil
+			if query.Result.Limit == 0 || query.Result.Used+added > query.Result.Limit {
+				return &QuotaReachedError{Target: target, Scope: scope.Name, Limit: query.Result.Limit, Used: query.Result.Used}
 			}
 		}
 	}
 
-	return false, nil
+	return nil
 }
 
 func (qs *QuotaService) getQuotaScopes(target string) ([]models.QuotaScope, error) {
@@ -168,6 +203,26 @@ func (qs *QuotaService) getQuotaScopes(target string) ([]models.QuotaScope, erro
 			models.QuotaScope{Name: "org", Target: target, DefaultLimit: qs.Cfg.Quota.Org.AlertRule},
 		)
 		return scopes, nil
+	case "alert_rule_group":
+		scopes = append(scopes,
+			models.QuotaScope{Name: "org", Target: target, DefaultLimit: qs.Cfg.Quota.Org.AlertRuleGroup},
+		)
+		return scopes, nil
+	case "silence":
+		scopes = append(scopes,
+			models.QuotaScope{Name: "org", Target: target, DefaultLimit: qs.Cfg.Quota.Org.Silence},
+		)
+		return scopes, nil
+	case "mute_timing":
+		scopes = append(scopes,
+			models.QuotaScope{Name: "org", Target: target, DefaultLimit: qs.Cfg.Quota.Org.MuteTiming},
+		)
+		return scopes, nil
+	case "contact_point":
+		scopes = append(scopes,
+			models.QuotaScope{Name: "org", Target: target, DefaultLimit: qs.Cfg.Quota.Org.ContactPoint},
+		)
+		return scopes, nil
 	default:
 		return scopes, ErrInvalidQuotaTarget
 	}
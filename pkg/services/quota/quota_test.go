@@ -0,0 +1,50 @@
+package quota
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func TestCheckQuota(t *testing.T) {
+	t.Cleanup(bus.ClearBusHandlers)
+	bus.AddHandler("test", func(q *models.GetOrgQuotaByTargetQuery) error {
+		q.Result = &models.OrgQuotaDTO{OrgId: q.OrgId, Target: q.Target, Limit: q.Default, Used: 3}
+		return nil
+	})
+
+	cfg := setting.NewCfg()
+	cfg.Quota = setting.QuotaSettings{
+		Enabled: true,
+		Org:     &setting.OrgQuota{AlertRuleGroup: 5, Silence: 5, ContactPoint: -1},
+	}
+	qs := &QuotaService{Cfg: cfg}
+	c := &models.ReqContext{IsSignedIn: true, SignedInUser: &models.SignedInUser{OrgId: 1}, Logger: log.New("test")}
+
+	t.Run("passes if the quota allows the change", func(t *testing.T) {
+		require.NoError(t, qs.CheckQuota(c, "alert_rule_group", 2))
+		require.NoError(t, qs.CheckQuota(c, "alert_rule_group", 0))
+		require.NoError(t, qs.CheckQuotaWithUsage(c, "contact_point", 100, 1))
+
+		reached, err := qs.QuotaReached(c, "alert_rule_group")
+		require.NoError(t, err)
+		require.False(t, reached)
+	})
+
+	t.Run("fails with the quota reached", func(t *testing.T) {
+		err := qs.CheckQuota(c, "alert_rule_group", 3)
+		require.Equal(t, &QuotaReachedError{Target: "alert_rule_group", Scope: "org", Limit: 5, Used: 3}, err)
+
+		err = qs.CheckQuotaWithUsage(c, "silence", 5, 1)
+		require.Equal(t, &QuotaReachedError{Target: "silence", Scope: "org", Limit: 5, Used: 5}, err)
+	})
+
+	t.Run("fails for unknown targets", func(t *testing.T) {
+		require.ErrorIs(t, qs.CheckQuota(c, "unknown", 1), ErrInvalidQuotaTarget)
+	})
+}
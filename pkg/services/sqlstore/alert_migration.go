@@ -0,0 +1,38 @@
+package sqlstore
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrations/ualert"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// errDryRun rolls back the transaction of the dry run of a migration.
+var errDryRun = errors.New("dry run")
+
+// PreviewDashAlertMigration returns the report of a dry run of the migration of the dashboard alerts to unified
+// alerting. The dry run changes nothing, it is rolled back.
+func (ss *SQLStore) PreviewDashAlertMigration(ctx context.Context) (*ualert.MigrationReport, error) {
+	var report *ualert.MigrationReport
+	err := ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		var err error
+		report, err = ualert.PreviewDashAlertMigration(sess.Session, migrator.NewMigrator(ss.engine, ss.Cfg))
+		if err != nil {
+			return err
+		}
+		return errDryRun
+	})
+	if !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+	return report, nil
+}
+
+// RollbackDashAlertMigration rolls back the migration of the dashboard alerts to unified alerting, removing the
+// unified alerting data, so that the dashboard alerts are migrated again at the next start.
+func (ss *SQLStore) RollbackDashAlertMigration(ctx context.Context) error {
+	return ss.WithTransactionalDbSession(ctx, func(sess *DBSession) error {
+		return ualert.RollbackDashAlertMigration(sess.Session, migrator.NewMigrator(ss.engine, ss.Cfg))
+	})
+}
@@ -0,0 +1,111 @@
+//go:build integration
+// +build integration
+
+package sqlstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrations/ualert"
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func TestDashAlertMigrationPreview(t *testing.T) {
+	sqlStore := InitTestDB(t)
+	dash := insertTestDashboard(t, sqlStore, "dashboard with alerts", 1, 0, false)
+
+	for _, cmd := range []*models.CreateAlertNotificationCommand{
+		{Uid: "ops", Name: "ops", Type: "email", OrgId: 1, Settings: simplejson.New()},
+		{Uid: "chat", Name: "chat", Type: "hipchat", OrgId: 1, Settings: simplejson.New()},
+	} {
+		require.NoError(t, CreateAlertNotificationCommand(cmd))
+	}
+
+	settings := func(params string) *simplejson.Json {
+		s, err := simplejson.NewJson([]byte(`{
+			"conditions": [{
+				"evaluator": {"params": [80], "type": "gt"},
+				"operator": {"type": "and"},
+				"query": {"params": ` + params + `, "datasourceId": 1, "model": {"refId": "A"}},
+				"reducer": {"type": "avg"}
+			}],
+			"noDataState": "no_data",
+			"executionErrorState": "alerting",
+			"notifications": [{"uid": "ops"}, {"uid": "chat"}]
+		}`))
+		require.NoError(t, err)
+		return s
+	}
+	require.NoError(t, SaveAlerts(&models.SaveAlertsCommand{
+		DashboardId: dash.Id,
+		OrgId:       1,
+		UserId:      1,
+		Alerts: []*models.Alert{
+			{PanelId: 1, DashboardId: dash.Id, OrgId: 1, Name: "cpu high", Frequency: 60, Settings: settings(`["A", "5m", "now"]`)},
+			{PanelId: 2, DashboardId: dash.Id, OrgId: 1, Name: "broken", Frequency: 60, Settings: settings(`["A"]`)},
+		},
+	}))
+
+	t.Run("reports the rules and the contact points of the alerts", func(t *testing.T) {
+		report, err := sqlStore.PreviewDashAlertMigration(context.Background())
+		require.NoError(t, err)
+		require.Len(t, report.Alerts, 2)
+		alerts := make(map[string]ualert.AlertMigrationReport)
+		for _, a := range report.Alerts {
+			alerts[a.AlertName] = a
+		}
+
+		require.Equal(t, "cpu high", alerts["cpu high"].RuleTitle)
+		require.Equal(t, ualert.GENERAL_FOLDER, alerts["cpu high"].FolderTitle)
+		require.Equal(t, "autogen-contact-point-1", alerts["cpu high"].Receiver)
+		require.Empty(t, alerts["cpu high"].Error)
+		require.NotEmpty(t, alerts["broken"].Error)
+		require.Empty(t, alerts["broken"].RuleTitle)
+
+		require.Len(t, report.Channels, 2)
+		require.Equal(t, "ops", report.Channels[0].UID)
+		require.Equal(t, []string{"autogen-contact-point-1"}, report.Channels[0].Receivers)
+		require.False(t, report.Channels[0].Unmappable)
+		require.Equal(t, "chat", report.Channels[1].UID)
+		require.True(t, report.Channels[1].Unmappable)
+	})
+
+	t.Run("saves nothing", func(t *testing.T) {
+		err := sqlStore.WithDbSession(context.Background(), func(sess *DBSession) error {
+			rules, err := sess.Table("alert_rule").Count()
+			require.NoError(t, err)
+			require.Zero(t, rules)
+			folders, err := sess.Table("dashboard").Where("title = ?", ualert.GENERAL_FOLDER).Count()
+			require.NoError(t, err)
+			require.Zero(t, folders)
+			return nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("rolls back the completed migrations", func(t *testing.T) {
+		require.ErrorIs(t, sqlStore.RollbackDashAlertMigration(context.Background()), ualert.ErrDashAlertMigrationNotRun)
+
+		const migID = "move dashboard alerts to unified alerting"
+		err := sqlStore.WithDbSession(context.Background(), func(sess *DBSession) error {
+			_, err := sess.Insert(&migrator.MigrationLog{MigrationID: migID, Success: true, Timestamp: time.Now()})
+			return err
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, sqlStore.RollbackDashAlertMigration(context.Background()))
+		err = sqlStore.WithDbSession(context.Background(), func(sess *DBSession) error {
+			exists, err := sess.Table("migration_log").Where("migration_id = ?", migID).Exist()
+			require.NoError(t, err)
+			require.False(t, exists)
+			return nil
+		})
+		require.NoError(t, err)
+	})
+}
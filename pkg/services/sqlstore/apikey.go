@@ -65,13 +65,15 @@ func AddApiKey(cmd *models.AddApiKeyCommand) error {
 			return models.ErrInvalidApiKeyExpiration
 		}
 		t := models.ApiKey{
-			OrgId:   cmd.OrgId,
-			Name:    cmd.Name,
-			Role:    cmd.Role,
-			Key:     cmd.Key,
-			Created: updated,
-			Updated: updated,
-			Expires: expires,
+			OrgId:        cmd.OrgId,
+			Name:         cmd.Name,
+			Role:         cmd.Role,
+			Key:          cmd.Key,
+			Created:      updated,
+			Updated:      updated,
+			Expires:      expires,
+			Scope:        cmd.Scope,
+			ScopeFolders: cmd.ScopeFolders,
 		}
 
 		if _, err := sess.Insert(&t); err != nil {
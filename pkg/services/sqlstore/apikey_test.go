@@ -45,6 +45,20 @@ func TestApiKeyDataAccess(t *testing.T) {
 			assert.Nil(t, query.Result.Expires)
 		})
 
+		t.Run("Add a scoped key", func(t *testing.T) {
+			cmd := models.AddApiKeyCommand{OrgId: 1, Name: "alerting", Key: "asd-alerting",
+				Scope: models.ApiKeyScopeAlerting, ScopeFolders: []string{"ops", "dev"}}
+			err := AddApiKey(&cmd)
+			assert.Nil(t, err)
+
+			query := models.GetApiKeyByNameQuery{KeyName: "alerting", OrgId: 1}
+			err = GetApiKeyByName(&query)
+			assert.Nil(t, err)
+
+			assert.Equal(t, models.ApiKeyScopeAlerting, query.Result.Scope)
+			assert.Equal(t, []string{"ops", "dev"}, query.Result.ScopeFolders)
+		})
+
 		t.Run("Add an expiring key", func(t *testing.T) {
 			// expires in one hour
 			cmd := models.AddApiKeyCommand{OrgId: 1, Name: "expiring-in-an-hour", Key: "asd2", SecondsToLive: 3600}
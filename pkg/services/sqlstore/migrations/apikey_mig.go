@@ -82,4 +82,12 @@ func addApiKeyMigrations(mg *Migrator) {
 	mg.AddMigration("Add expires to api_key table", NewAddColumnMigration(apiKeyV2, &Column{
 		Name: "expires", Type: DB_BigInt, Nullable: true,
 	}))
+
+	mg.AddMigration("Add scope to api_key table", NewAddColumnMigration(apiKeyV2, &Column{
+		Name: "scope", Type: DB_NVarchar, Length: 190, Nullable: true,
+	}))
+
+	mg.AddMigration("Add scope_folders to api_key table", NewAddColumnMigration(apiKeyV2, &Column{
+		Name: "scope_folders", Type: DB_Text, Nullable: true,
+	}))
 }
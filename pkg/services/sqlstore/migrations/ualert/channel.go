@@ -77,10 +77,12 @@ func (m *migration) getNotificationChannelMap() (channelsPerOrg, defaultChannels
 	return allChannelsMap, defaultChannelsMap, nil
 }
 
-func (m *migration) updateReceiverAndRoute(allChannels channelsPerOrg, defaultChannels defaultChannelsPerOrg, da dashAlert, rule *alertRule, amConfig *PostableUserConfig) error {
+// updateReceiverAndRoute adds the receiver and the route of the rule to the configuration, and returns the name of
+// the receiver of the rule.
+func (m *migration) updateReceiverAndRoute(allChannels channelsPerOrg, defaultChannels defaultChannelsPerOrg, da dashAlert, rule *alertRule, amConfig *PostableUserConfig) (string, error) {
 	// Create receiver and route for this rule.
 	if allChannels == nil {
-		return nil
+		return "", nil
 	}
 
 	channelIDs := extractChannelIDs(da)
@@ -88,33 +90,35 @@ func (m *migration) updateReceiverAndRoute(allChannels channelsPerOrg, defaultCh
 		// If there are no channels associated, we skip adding any routes,
 		// receivers or labels to rules so that it goes through the default
 		// route.
-		return nil
+		return amConfig.AlertmanagerConfig.Route.Receiver, nil
 	}
 
 	recv, route, err := m.makeReceiverAndRoute(rule.UID, rule.OrgID, channelIDs, defaultChannels[rule.OrgID], allChannels[rule.OrgID])
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	if recv != nil {
 		amConfig.AlertmanagerConfig.Receivers = append(amConfig.AlertmanagerConfig.Receivers, recv)
 	}
-	if route != nil {
-		amConfig.AlertmanagerConfig.Route.Routes = append(amConfig.AlertmanagerConfig.Route.Routes, route)
+	if route == nil {
+		return amConfig.AlertmanagerConfig.Route.Receiver, nil
 	}
-
-	return nil
+	amConfig.AlertmanagerConfig.Route.Routes = append(amConfig.AlertmanagerConfig.Route.Routes, route)
+	return route.Receiver, nil
 }
 
 func (m *migration) makeReceiverAndRoute(ruleUid string, orgID int64, channelUids []interface{}, defaultChannels []*notificationChannel, allChannels map[interface{}]*notificationChannel) (*PostableApiReceiver, *Route, error) {
 	portedChannels := []*PostableGrafanaReceiver{}
 	var receiver *PostableApiReceiver
 
-	addChannel := func(c *notificationChannel) error {
+	addChannel := func(c *notificationChannel, receiverName string) error {
 		if c.Type == "hipchat" || c.Type == "sensu" {
 			m.mg.Logger.Error("alert migration error: discontinued notification channel found", "type", c.Type, "name", c.Name, "uid", c.Uid)
+			m.reportChannel(c, "", discontinuedChannelReason)
 			return nil
 		}
+		m.reportChannel(c, receiverName, "")
 
 		uid, ok := m.generateChannelUID()
 		if !ok {
@@ -185,12 +189,6 @@ func (m *migration) makeReceiverAndRoute(ruleUid string, orgID int64, channelUid
 			return nil, nil, nil
 		}
 	} else {
-		for n := range filteredChannelUids {
-			if err := addChannel(allChannels[n]); err != nil {
-				return nil, nil, err
-			}
-		}
-
 		if ruleUid == "default_route" {
 			receiverName = "autogen-contact-point-default"
 		} else {
@@ -198,6 +196,12 @@ func (m *migration) makeReceiverAndRoute(ruleUid string, orgID int64, channelUid
 			receiverName = fmt.Sprintf("autogen-contact-point-%d", m.lastReceiverID)
 		}
 
+		for n := range filteredChannelUids {
+			if err := addChannel(allChannels[n], receiverName); err != nil {
+				return nil, nil, err
+			}
+		}
+
 		m.portedChannelGroupsPerOrg[orgID][chanKey] = receiverName
 		receiver = &PostableApiReceiver{
 			Name:                    receiverName,
@@ -284,8 +288,10 @@ func (m *migration) addUnmigratedChannels(orgID int64, amConfigs *PostableUserCo
 		}
 		if c.Type == "hipchat" || c.Type == "sensu" {
 			m.mg.Logger.Error("alert migration error: discontinued notification channel found", "type", c.Type, "name", c.Name, "uid", c.Uid)
+			m.reportChannel(c, "", discontinuedChannelReason)
 			continue
 		}
+		m.reportChannel(c, receiver.Name, "")
 
 		uid, ok := m.generateChannelUID()
 		if !ok {
@@ -0,0 +1,156 @@
+package ualert
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"xorm.io/xorm"
+
+	"github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+// discontinuedChannelReason is the reason why the notification channels of discontinued types are not migrated.
+const discontinuedChannelReason = "discontinued notification channel type"
+
+// ErrDashAlertMigrationNotRun is an error for when there is no completed migration to roll back.
+var ErrDashAlertMigrationNotRun = errors.New("the dashboard alerts have not been migrated to unified alerting")
+
+// MigrationReport is the report of a dry run of the migration of the dashboard alerts to unified alerting.
+type MigrationReport struct {
+	Alerts   []AlertMigrationReport   `json:"alerts"`
+	Channels []ChannelMigrationReport `json:"channels"`
+}
+
+// AlertMigrationReport is the alert rule a dashboard alert is migrated to, or the error for why it can't be.
+type AlertMigrationReport struct {
+	OrgID        int64  `json:"orgId"`
+	AlertID      int64  `json:"alertId"`
+	AlertName    string `json:"alertName"`
+	DashboardUID string `json:"dashboardUid"`
+	PanelID      int64  `json:"panelId"`
+	RuleTitle    string `json:"ruleTitle,omitempty"`
+	RuleGroup    string `json:"ruleGroup,omitempty"`
+	FolderTitle  string `json:"folderTitle,omitempty"`
+	// Receiver is the contact point the alerts of the rule are routed to, none if the organisation has no
+	// notification channels.
+	Receiver string `json:"receiver,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// ChannelMigrationReport is the contact points a notification channel is migrated to, or the reason why it can't be.
+type ChannelMigrationReport struct {
+	OrgID     int64    `json:"orgId"`
+	ChannelID int64    `json:"channelId"`
+	UID       string   `json:"uid"`
+	Name      string   `json:"name"`
+	Type      string   `json:"type"`
+	Receivers []string `json:"receivers,omitempty"`
+	// Unmappable channels are not migrated, for the Reason.
+	Unmappable bool   `json:"unmappable"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+func (r *MigrationReport) addAlert(da dashAlert, migrated *migratedAlert, err error) {
+	a := AlertMigrationReport{
+		OrgID:        da.OrgId,
+		AlertID:      da.Id,
+		AlertName:    da.Name,
+		DashboardUID: da.DashboardUID,
+		PanelID:      da.PanelId,
+	}
+	if err != nil {
+		a.Error = err.Error()
+	} else {
+		a.RuleTitle = migrated.rule.Title
+		a.RuleGroup = migrated.rule.RuleGroup
+		a.FolderTitle = migrated.folder.Title
+		a.Receiver = migrated.receiver
+	}
+	r.Alerts = append(r.Alerts, a)
+}
+
+func (r *MigrationReport) addChannels(channels map[*notificationChannel]*ChannelMigrationReport) {
+	for _, c := range channels {
+		sort.Strings(c.Receivers)
+		r.Channels = append(r.Channels, *c)
+	}
+	sort.Slice(r.Channels, func(i, j int) bool {
+		if r.Channels[i].OrgID != r.Channels[j].OrgID {
+			return r.Channels[i].OrgID < r.Channels[j].OrgID
+		}
+		return r.Channels[i].ChannelID < r.Channels[j].ChannelID
+	})
+}
+
+// reportChannel reports that the channel is migrated to the receiver, or not migrated for the reason, in a dry run.
+func (m *migration) reportChannel(c *notificationChannel, receiver string, reason string) {
+	if m.report == nil {
+		return
+	}
+	if m.channelReports == nil {
+		m.channelReports = make(map[*notificationChannel]*ChannelMigrationReport)
+	}
+	cr, ok := m.channelReports[c]
+	if !ok {
+		cr = &ChannelMigrationReport{OrgID: c.OrgID, ChannelID: c.ID, UID: c.Uid, Name: c.Name, Type: c.Type}
+		m.channelReports[c] = cr
+	}
+	if reason != "" {
+		cr.Unmappable = true
+		cr.Reason = reason
+		return
+	}
+	for _, r := range cr.Receivers {
+		if r == receiver {
+			return
+		}
+	}
+	cr.Receivers = append(cr.Receivers, receiver)
+}
+
+// PreviewDashAlertMigration runs a dry run of the migration of the dashboard alerts to unified alerting, and returns
+// the report of the alert rules and the contact points they would be migrated to. The session must be in a
+// transaction which is rolled back, the folders of the migrated rules are created in it.
+func PreviewDashAlertMigration(sess *xorm.Session, mg *migrator.Migrator) (*MigrationReport, error) {
+	report := &MigrationReport{
+		Alerts:   make([]AlertMigrationReport, 0),
+		Channels: make([]ChannelMigrationReport, 0),
+	}
+	m := &migration{
+		seenChannelUIDs:           make(map[string]struct{}),
+		migratedChannelsPerOrg:    make(map[int64]map[*notificationChannel]struct{}),
+		portedChannelGroupsPerOrg: make(map[int64]map[string]string),
+		report:                    report,
+	}
+	if err := m.Exec(sess, mg); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// RollbackDashAlertMigration removes the unified alerting data, and the entries of the completed migration of the
+// dashboard alerts from the migration log so that they are migrated again at the next start with unified alerting
+// enabled. It returns ErrDashAlertMigrationNotRun if there is no completed migration.
+func RollbackDashAlertMigration(sess *xorm.Session, mg *migrator.Migrator) error {
+	logs, err := mg.GetMigrationLog()
+	if err != nil {
+		return err
+	}
+	cloneMigTitle := fmt.Sprintf("clone %s", migTitle)
+	_, migrationRun := logs[migTitle]
+	_, cloneMigrationRun := logs[cloneMigTitle]
+	if !migrationRun && !cloneMigrationRun {
+		return ErrDashAlertMigrationNotRun
+	}
+
+	if err := (&rmMigration{}).Exec(sess, mg); err != nil {
+		return err
+	}
+	for _, id := range []string{migTitle, cloneMigTitle} {
+		if err := (&clearMigrationEntry{migrationID: id}).Exec(sess, mg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -22,6 +22,39 @@ func AddTablesMigrations(mg *migrator.Migrator) {
 
 	// Create Admin Configuration
 	AddAlertAdminConfigMigrations(mg)
+
+	// Create recurring silences
+	AddRecurringSilenceMigrations(mg)
+
+	// Create maintenance mode
+	AddMaintenanceModeMigrations(mg)
+
+	// Create Alertmanager state snapshots
+	AddAlertmanagerStateMigrations(mg)
+
+	// Create provenance of the provisioned resources
+	AddProvisioningMigrations(mg)
+
+	// Create insights of the alert rules
+	AddAlertRuleInsightMigrations(mg)
+
+	// Create heartbeats of the heartbeat rules
+	AddAlertRuleHeartbeatMigrations(mg)
+
+	// Create label policies
+	AddLabelPolicyMigrations(mg)
+
+	// Create rule templates and the back-references of their instances
+	AddRuleTemplateMigrations(mg)
+
+	// Create defaults of the rules of the folders
+	AddFolderDefaultsMigrations(mg)
+
+	// Create annotations of the changes of the states of the alerts
+	AddAlertStateAnnotationMigrations(mg)
+
+	// Create maintenance windows of the datasources
+	AddDatasourceMaintenanceMigrations(mg)
 }
 
 // AddAlertDefinitionMigrations should not be modified.
@@ -204,6 +237,39 @@ func AddAlertRuleMigrations(mg *migrator.Migrator, defaultIntervalSeconds int64)
 	mg.AddMigration("add index in alert_rule on org_id, namespase_uid and title columns", migrator.NewAddIndexMigration(alertRule, &migrator.Index{
 		Cols: []string{"org_id", "namespace_uid", "title"}, Type: migrator.UniqueIndex,
 	}))
+
+	// The rule search sorts the rules of an organisation by title or by the time they were updated.
+	mg.AddMigration("add index in alert_rule on org_id and title columns for the search", migrator.NewAddIndexMigration(alertRule, &migrator.Index{
+		Cols: []string{"org_id", "title"}, Type: migrator.IndexType,
+	}))
+
+	mg.AddMigration("add index in alert_rule on org_id and updated columns", migrator.NewAddIndexMigration(alertRule, &migrator.Index{
+		Cols: []string{"org_id", "updated"}, Type: migrator.IndexType,
+	}))
+
+	mg.AddMigration("add column rule_group_idx to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "rule_group_idx", Type: migrator.DB_Int, Nullable: false, Default: "0"}))
+
+	mg.AddMigration("add column is_paused to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "is_paused", Type: migrator.DB_Bool, Nullable: false, Default: "0"}))
+
+	mg.AddMigration("add column composite to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "composite", Type: migrator.DB_Text, Nullable: true}))
+
+	mg.AddMigration("add column thresholds to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "thresholds", Type: migrator.DB_Text, Nullable: true}))
+
+	mg.AddMigration("add column owner_user_id to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "owner_user_id", Type: migrator.DB_BigInt, Nullable: false, Default: "0"}))
+
+	mg.AddMigration("add column heartbeat to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "heartbeat", Type: migrator.DB_Text, Nullable: true}))
+
+	mg.AddMigration("add column links to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "links", Type: migrator.DB_Text, Nullable: true}))
+
+	mg.AddMigration("add column shadow_of to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "shadow_of", Type: migrator.DB_NVarchar, Length: 40, Nullable: false, Default: "''"}))
+
+	mg.AddMigration("add column active_from to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "active_from", Type: migrator.DB_DateTime, Nullable: true}))
+
+	mg.AddMigration("add column active_until to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "active_until", Type: migrator.DB_DateTime, Nullable: true}))
+
+	mg.AddMigration("add column hold_on_datasource_maintenance to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "hold_on_datasource_maintenance", Type: migrator.DB_Bool, Nullable: false, Default: "0"}))
+
+	mg.AddMigration("add column group_concurrency to alert_rule", migrator.NewAddColumnMigration(alertRule, &migrator.Column{Name: "group_concurrency", Type: migrator.DB_Int, Nullable: false, Default: "0"}))
 }
 
 func AddAlertRuleVersionMigrations(mg *migrator.Migrator) {
@@ -247,6 +313,27 @@ func AddAlertRuleVersionMigrations(mg *migrator.Migrator) {
 
 	// add labels column
 	mg.AddMigration("add column labels to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "labels", Type: migrator.DB_Text, Nullable: true}))
+
+	// add created_by column
+	mg.AddMigration("add column created_by to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "created_by", Type: migrator.DB_NVarchar, Length: 190, Nullable: true}))
+
+	mg.AddMigration("add column composite to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "composite", Type: migrator.DB_Text, Nullable: true}))
+
+	mg.AddMigration("add column thresholds to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "thresholds", Type: migrator.DB_Text, Nullable: true}))
+
+	mg.AddMigration("add column heartbeat to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "heartbeat", Type: migrator.DB_Text, Nullable: true}))
+
+	mg.AddMigration("add column links to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "links", Type: migrator.DB_Text, Nullable: true}))
+
+	mg.AddMigration("add column shadow_of to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "shadow_of", Type: migrator.DB_NVarchar, Length: 40, Nullable: false, Default: "''"}))
+
+	mg.AddMigration("add column active_from to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "active_from", Type: migrator.DB_DateTime, Nullable: true}))
+
+	mg.AddMigration("add column active_until to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "active_until", Type: migrator.DB_DateTime, Nullable: true}))
+
+	mg.AddMigration("add column hold_on_datasource_maintenance to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "hold_on_datasource_maintenance", Type: migrator.DB_Bool, Nullable: false, Default: "0"}))
+
+	mg.AddMigration("add column group_concurrency to alert_rule_version", migrator.NewAddColumnMigration(alertRuleVersion, &migrator.Column{Name: "group_concurrency", Type: migrator.DB_Int, Nullable: false, Default: "0"}))
 }
 
 func AddAlertmanagerConfigMigrations(mg *migrator.Migrator) {
@@ -296,4 +383,285 @@ func AddAlertAdminConfigMigrations(mg *migrator.Migrator) {
 
 	mg.AddMigration("create_ngalert_configuration_table", migrator.NewAddTableMigration(adminConfiguration))
 	mg.AddMigration("add index in ngalert_configuration on org_id column", migrator.NewAddIndexMigration(adminConfiguration, adminConfiguration.Indices[0]))
+	mg.AddMigration("add column auth to ngalert_configuration", migrator.NewAddColumnMigration(adminConfiguration, &migrator.Column{
+		Name: "auth", Type: migrator.DB_Text, Nullable: true,
+	}))
+	mg.AddMigration("add column send_alerts_to to ngalert_configuration", migrator.NewAddColumnMigration(adminConfiguration, &migrator.Column{
+		Name: "send_alerts_to", Type: migrator.DB_SmallInt, Nullable: false, Default: "0",
+	}))
+}
+
+func AddRecurringSilenceMigrations(mg *migrator.Migrator) {
+	recurringSilence := migrator.Table{
+		Name: "alert_recurring_silence",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "matchers", Type: migrator.DB_Text, Nullable: false},
+			{Name: "comment", Type: migrator.DB_Text, Nullable: true},
+			{Name: "created_by", Type: migrator.DB_NVarchar, Length: 190, Nullable: true},
+			{Name: "weekdays", Type: migrator.DB_NVarchar, Length: 40, Nullable: true},
+			{Name: "start_time", Type: migrator.DB_NVarchar, Length: 5, Nullable: false},
+			{Name: "duration_seconds", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "timezone", Type: migrator.DB_NVarchar, Length: 40, Nullable: true},
+			{Name: "last_starts_at", Type: migrator.DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "last_silence_id", Type: migrator.DB_NVarchar, Length: 40, Nullable: true},
+			{Name: "created_at", Type: migrator.DB_Int, Nullable: false},
+			{Name: "updated_at", Type: migrator.DB_Int, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "uid"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_recurring_silence table", migrator.NewAddTableMigration(recurringSilence))
+	mg.AddMigration("add unique index in alert_recurring_silence on org_id and uid columns", migrator.NewAddIndexMigration(recurringSilence, recurringSilence.Indices[0]))
+}
+
+func AddMaintenanceModeMigrations(mg *migrator.Migrator) {
+	maintenanceMode := migrator.Table{
+		Name: "alert_maintenance_mode",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "reason", Type: migrator.DB_Text, Nullable: true},
+			{Name: "created_by", Type: migrator.DB_NVarchar, Length: 190, Nullable: true},
+			{Name: "expires_at", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "created_at", Type: migrator.DB_Int, Nullable: false},
+			{Name: "updated_at", Type: migrator.DB_Int, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_maintenance_mode table", migrator.NewAddTableMigration(maintenanceMode))
+	mg.AddMigration("add unique index in alert_maintenance_mode on org_id column", migrator.NewAddIndexMigration(maintenanceMode, maintenanceMode.Indices[0]))
+}
+
+func AddAlertmanagerStateMigrations(mg *migrator.Migrator) {
+	alertmanagerState := migrator.Table{
+		Name: "alertmanager_state",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "kind", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "data", Type: migrator.DB_MediumText, Nullable: false},
+			{Name: "checksum", Type: migrator.DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "updated_at", Type: migrator.DB_Int, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "kind"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alertmanager_state table", migrator.NewAddTableMigration(alertmanagerState))
+	mg.AddMigration("add unique index in alertmanager_state on org_id, kind columns", migrator.NewAddIndexMigration(alertmanagerState, alertmanagerState.Indices[0]))
+}
+
+func AddProvisioningMigrations(mg *migrator.Migrator) {
+	provenanceType := migrator.Table{
+		Name: "provenance_type",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "record_key", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "record_type", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "provenance", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"record_type", "record_key", "org_id"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create provenance_type table", migrator.NewAddTableMigration(provenanceType))
+	mg.AddMigration("add unique index in provenance_type on record_type, record_key, org_id columns", migrator.NewAddIndexMigration(provenanceType, provenanceType.Indices[0]))
+}
+
+func AddAlertRuleInsightMigrations(mg *migrator.Migrator) {
+	alertRuleInsight := migrator.Table{
+		Name: "alert_rule_insight",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "period_start", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "evaluations", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "failures", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "no_data", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "duration_seconds", Type: migrator.DB_Double, Nullable: false},
+			{Name: "max_duration_seconds", Type: migrator.DB_Double, Nullable: false},
+			{Name: "last_error", Type: migrator.DB_Text, Nullable: true},
+			{Name: "last_error_at", Type: migrator.DB_BigInt, Nullable: false, Default: "0"},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "rule_uid", "period_start"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"period_start"}, Type: migrator.IndexType},
+		},
+	}
+
+	mg.AddMigration("create alert_rule_insight table", migrator.NewAddTableMigration(alertRuleInsight))
+	mg.AddMigration("add unique index in alert_rule_insight on org_id, rule_uid, period_start columns", migrator.NewAddIndexMigration(alertRuleInsight, alertRuleInsight.Indices[0]))
+	mg.AddMigration("add index in alert_rule_insight on period_start column", migrator.NewAddIndexMigration(alertRuleInsight, alertRuleInsight.Indices[1]))
+}
+
+func AddAlertRuleHeartbeatMigrations(mg *migrator.Migrator) {
+	alertRuleHeartbeat := migrator.Table{
+		Name: "alert_rule_heartbeat",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "token", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "received_at", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "rule_uid"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"token"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_rule_heartbeat table", migrator.NewAddTableMigration(alertRuleHeartbeat))
+	mg.AddMigration("add unique index in alert_rule_heartbeat on org_id, rule_uid columns", migrator.NewAddIndexMigration(alertRuleHeartbeat, alertRuleHeartbeat.Indices[0]))
+	mg.AddMigration("add unique index in alert_rule_heartbeat on token column", migrator.NewAddIndexMigration(alertRuleHeartbeat, alertRuleHeartbeat.Indices[1]))
+}
+
+func AddLabelPolicyMigrations(mg *migrator.Migrator) {
+	labelPolicy := migrator.Table{
+		Name: "alert_label_policy",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "policies", Type: migrator.DB_MediumText, Nullable: false},
+			{Name: "updated_by", Type: migrator.DB_NVarchar, Length: 190, Nullable: true},
+			{Name: "updated_at", Type: migrator.DB_Int, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_label_policy table", migrator.NewAddTableMigration(labelPolicy))
+	mg.AddMigration("add unique index in alert_label_policy on org_id column", migrator.NewAddIndexMigration(labelPolicy, labelPolicy.Indices[0]))
+}
+
+func AddRuleTemplateMigrations(mg *migrator.Migrator) {
+	ruleTemplate := migrator.Table{
+		Name: "alert_rule_template",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "title", Type: migrator.DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "description", Type: migrator.DB_Text, Nullable: true},
+			{Name: "parameters", Type: migrator.DB_Text, Nullable: false},
+			{Name: "rule", Type: migrator.DB_MediumText, Nullable: false},
+			{Name: "version", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "updated_by", Type: migrator.DB_NVarchar, Length: 190, Nullable: true},
+			{Name: "updated_at", Type: migrator.DB_Int, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "uid"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_rule_template table", migrator.NewAddTableMigration(ruleTemplate))
+	mg.AddMigration("add unique index in alert_rule_template on org_id and uid columns", migrator.NewAddIndexMigration(ruleTemplate, ruleTemplate.Indices[0]))
+
+	ruleTemplateInstance := migrator.Table{
+		Name: "alert_rule_template_instance",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "template_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "parameters", Type: migrator.DB_Text, Nullable: false},
+			{Name: "template_version", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "updated_at", Type: migrator.DB_Int, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "rule_uid"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"org_id", "template_uid"}},
+		},
+	}
+
+	mg.AddMigration("create alert_rule_template_instance table", migrator.NewAddTableMigration(ruleTemplateInstance))
+	mg.AddMigration("add unique index in alert_rule_template_instance on org_id and rule_uid columns", migrator.NewAddIndexMigration(ruleTemplateInstance, ruleTemplateInstance.Indices[0]))
+	mg.AddMigration("add index in alert_rule_template_instance on org_id and template_uid columns", migrator.NewAddIndexMigration(ruleTemplateInstance, ruleTemplateInstance.Indices[1]))
+}
+
+func AddFolderDefaultsMigrations(mg *migrator.Migrator) {
+	folderDefaults := migrator.Table{
+		Name: "alert_rule_folder_defaults",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "folder_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "interval_seconds", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "no_data_state", Type: migrator.DB_NVarchar, Length: 15, Nullable: false},
+			{Name: "exec_err_state", Type: migrator.DB_NVarchar, Length: 15, Nullable: false},
+			{Name: "labels", Type: migrator.DB_Text, Nullable: true},
+			{Name: "updated_by", Type: migrator.DB_NVarchar, Length: 190, Nullable: true},
+			{Name: "updated_at", Type: migrator.DB_Int, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "folder_uid"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create alert_rule_folder_defaults table", migrator.NewAddTableMigration(folderDefaults))
+	mg.AddMigration("add unique index in alert_rule_folder_defaults on org_id and folder_uid columns", migrator.NewAddIndexMigration(folderDefaults, folderDefaults.Indices[0]))
+	mg.AddMigration("add column version to alert_rule_folder_defaults", migrator.NewAddColumnMigration(folderDefaults, &migrator.Column{Name: "version", Type: migrator.DB_BigInt, Nullable: false, Default: "0"}))
+}
+
+func AddAlertStateAnnotationMigrations(mg *migrator.Migrator) {
+	stateAnnotation := migrator.Table{
+		Name: "alert_state_annotation",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "rule_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "labels", Type: migrator.DB_Text, Nullable: true},
+			{Name: "dashboard_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: true},
+			{Name: "panel_id", Type: migrator.DB_BigInt, Nullable: true},
+			{Name: "prev_state", Type: migrator.DB_NVarchar, Length: 25, Nullable: false},
+			{Name: "new_state", Type: migrator.DB_NVarchar, Length: 25, Nullable: false},
+			{Name: "text", Type: migrator.DB_Text, Nullable: false},
+			{Name: "epoch", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "rule_uid", "epoch"}},
+			{Cols: []string{"org_id", "epoch"}},
+		},
+	}
+
+	mg.AddMigration("create alert_state_annotation table", migrator.NewAddTableMigration(stateAnnotation))
+	mg.AddMigration("add index in alert_state_annotation on org_id, rule_uid and epoch columns", migrator.NewAddIndexMigration(stateAnnotation, stateAnnotation.Indices[0]))
+	mg.AddMigration("add index in alert_state_annotation on org_id and epoch columns", migrator.NewAddIndexMigration(stateAnnotation, stateAnnotation.Indices[1]))
+}
+
+func AddDatasourceMaintenanceMigrations(mg *migrator.Migrator) {
+	maintenanceWindow := migrator.Table{
+		Name: "alert_datasource_maintenance_window",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "datasource_uid", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "reason", Type: migrator.DB_Text, Nullable: true},
+			{Name: "created_by", Type: migrator.DB_NVarchar, Length: 190, Nullable: true},
+			{Name: "starts_at", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "ends_at", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "created_at", Type: migrator.DB_Int, Nullable: false},
+			{Name: "updated_at", Type: migrator.DB_Int, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"org_id", "datasource_uid"}},
+			{Cols: []string{"ends_at"}},
+		},
+	}
+
+	mg.AddMigration("create alert_datasource_maintenance_window table", migrator.NewAddTableMigration(maintenanceWindow))
+	mg.AddMigration("add index in alert_datasource_maintenance_window on org_id and datasource_uid columns", migrator.NewAddIndexMigration(maintenanceWindow, maintenanceWindow.Indices[0]))
+	mg.AddMigration("add index in alert_datasource_maintenance_window on ends_at column", migrator.NewAddIndexMigration(maintenanceWindow, maintenanceWindow.Indices[1]))
 }
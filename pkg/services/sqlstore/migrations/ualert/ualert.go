@@ -153,6 +153,10 @@ type migration struct {
 	silences                  []*pb.MeshSilence
 	portedChannelGroupsPerOrg map[int64]map[string]string // Org -> Channel group key -> receiver name.
 	lastReceiverID            int                         // For the auto generated receivers.
+
+	// report is the report of a dry run, nil when the migration is run.
+	report         *MigrationReport
+	channelReports map[*notificationChannel]*ChannelMigrationReport
 }
 
 func (m *migration) SQL(dialect migrator.Dialect) string {
@@ -193,141 +197,183 @@ func (m *migration) Exec(sess *xorm.Session, mg *migrator.Migrator) error {
 	}
 
 	for _, da := range dashAlerts {
-		newCond, err := transConditions(*da.ParsedSettings, da.OrgId, dsIDMap)
+		migrated, err := m.migrateAlert(da, dsIDMap, dashIDMap, allChannelsPerOrg, defaultChannelsPerOrg, amConfigPerOrg)
+		if m.report != nil {
+			// the dry run reports the alerts that can't be migrated instead of failing, and saves nothing
+			m.report.addAlert(da, migrated, err)
+			continue
+		}
 		if err != nil {
 			return err
 		}
+		if err := m.insertRule(migrated.rule); err != nil {
+			return err
+		}
+	}
 
-		da.DashboardUID = dashIDMap[[2]int64{da.OrgId, da.DashboardId}]
-
-		// get dashboard
-		dash := dashboard{}
-		exists, err := m.sess.Where("org_id=? AND uid=?", da.OrgId, da.DashboardUID).Get(&dash)
+	for orgID, amConfig := range amConfigPerOrg {
+		// Create a separate receiver for all the unmigrated channels.
+		err = m.addUnmigratedChannels(orgID, amConfig, allChannelsPerOrg[orgID], defaultChannelsPerOrg[orgID])
 		if err != nil {
-			return MigrationError{
-				Err:     fmt.Errorf("failed to get dashboard %s under organisation %d: %w", da.DashboardUID, da.OrgId, err),
-				AlertId: da.Id,
-			}
+			return err
 		}
-		if !exists {
-			return MigrationError{
-				Err:     fmt.Errorf("dashboard with UID %v under organisation %d not found: %w", da.DashboardUID, da.OrgId, err),
-				AlertId: da.Id,
-			}
+
+		if m.report != nil {
+			continue
 		}
 
-		// get folder if exists
-		folder, err := m.getFolder(dash, da)
-		if err != nil {
-			return MigrationError{
-				Err:     err,
-				AlertId: da.Id,
-			}
+		if err := m.writeAlertmanagerConfig(orgID, amConfig, allChannelsPerOrg[orgID]); err != nil {
+			return err
 		}
 
-		switch {
-		case dash.HasAcl:
-			// create folder and assign the permissions of the dashboard (included default and inherited)
-			ptr, err := m.createFolder(dash.OrgId, fmt.Sprintf(DASHBOARD_FOLDER, getMigrationString(da)))
-			if err != nil {
-				return MigrationError{
-					Err:     fmt.Errorf("failed to create folder: %w", err),
-					AlertId: da.Id,
-				}
-			}
-			folder = *ptr
-			permissions, err := m.getACL(dash.OrgId, dash.Id)
-			if err != nil {
-				return MigrationError{
-					Err:     fmt.Errorf("failed to get dashboard %d under organisation %d permissions: %w", dash.Id, dash.OrgId, err),
-					AlertId: da.Id,
-				}
-			}
-			err = m.setACL(folder.OrgId, folder.Id, permissions)
-			if err != nil {
-				return MigrationError{
-					Err:     fmt.Errorf("failed to set folder %d under organisation %d permissions: %w", folder.Id, folder.OrgId, err),
-					AlertId: da.Id,
-				}
-			}
-		case dash.FolderId > 0:
-			// link the new rule to the existing folder
-		default:
-			// get or create general folder
-			ptr, err := m.getOrCreateGeneralFolder(dash.OrgId)
-			if err != nil {
-				return MigrationError{
-					Err:     fmt.Errorf("failed to get or create general folder under organisation %d: %w", dash.OrgId, err),
-					AlertId: da.Id,
-				}
-			}
-			// No need to assign default permissions to general folder
-			// because they are included to the query result if it's a folder with no permissions
-			// https://github.com/grafana/grafana/blob/076e2ce06a6ecf15804423fcc8dca1b620a321e5/pkg/services/sqlstore/dashboard_acl.go#L109
-			folder = *ptr
+		if err := m.writeSilencesFile(orgID); err != nil {
+			m.mg.Logger.Error("alert migration error: failed to write silence file", "err", err)
 		}
+	}
 
-		if folder.Uid == "" {
-			return MigrationError{
-				Err:     fmt.Errorf("empty folder identifier"),
+	if m.report != nil {
+		m.report.addChannels(m.channelReports)
+	}
+
+	return nil
+}
+
+// migratedAlert is a dashboard alert migrated to an alert rule, in a folder and notifying a receiver.
+type migratedAlert struct {
+	rule     *alertRule
+	folder   dashboard
+	receiver string
+}
+
+// migrateAlert migrates a dashboard alert to an alert rule, in its folder created if needed, and adds its receiver
+// and route to the Alertmanager configuration of its organisation. The rule is not saved.
+func (m *migration) migrateAlert(da dashAlert, dsIDMap dsUIDLookup, dashIDMap map[[2]int64]string, allChannelsPerOrg channelsPerOrg, defaultChannelsPerOrg defaultChannelsPerOrg, amConfigPerOrg amConfigsPerOrg) (*migratedAlert, error) {
+	newCond, err := transConditions(*da.ParsedSettings, da.OrgId, dsIDMap)
+	if err != nil {
+		return nil, err
+	}
+
+	da.DashboardUID = dashIDMap[[2]int64{da.OrgId, da.DashboardId}]
+
+	// get dashboard
+	dash := dashboard{}
+	exists, err := m.sess.Where("org_id=? AND uid=?", da.OrgId, da.DashboardUID).Get(&dash)
+	if err != nil {
+		return nil, MigrationError{
+			Err:     fmt.Errorf("failed to get dashboard %s under organisation %d: %w", da.DashboardUID, da.OrgId, err),
+			AlertId: da.Id,
+		}
+	}
+	if !exists {
+		return nil, MigrationError{
+			Err:     fmt.Errorf("dashboard with UID %v under organisation %d not found: %w", da.DashboardUID, da.OrgId, err),
+			AlertId: da.Id,
+		}
+	}
+
+	// get folder if exists
+	folder, err := m.getFolder(dash, da)
+	if err != nil {
+		return nil, MigrationError{
+			Err:     err,
+			AlertId: da.Id,
+		}
+	}
+
+	switch {
+	case dash.HasAcl:
+		// create folder and assign the permissions of the dashboard (included default and inherited)
+		ptr, err := m.createFolder(dash.OrgId, fmt.Sprintf(DASHBOARD_FOLDER, getMigrationString(da)))
+		if err != nil {
+			return nil, MigrationError{
+				Err:     fmt.Errorf("failed to create folder: %w", err),
 				AlertId: da.Id,
 			}
 		}
-		rule, err := m.makeAlertRule(*newCond, da, folder.Uid)
+		folder = *ptr
+		permissions, err := m.getACL(dash.OrgId, dash.Id)
 		if err != nil {
-			return err
-		}
-
-		if _, ok := amConfigPerOrg[rule.OrgID]; !ok {
-			m.mg.Logger.Info("no configuration found", "org", rule.OrgID)
-		} else {
-			if err := m.updateReceiverAndRoute(allChannelsPerOrg, defaultChannelsPerOrg, da, rule, amConfigPerOrg[rule.OrgID]); err != nil {
-				return err
+			return nil, MigrationError{
+				Err:     fmt.Errorf("failed to get dashboard %d under organisation %d permissions: %w", dash.Id, dash.OrgId, err),
+				AlertId: da.Id,
 			}
 		}
-
-		if strings.HasPrefix(mg.Dialect.DriverName(), migrator.Postgres) {
-			err = mg.InTransaction(func(sess *xorm.Session) error {
-				_, err = sess.Insert(rule)
-				return err
-			})
-		} else {
-			_, err = m.sess.Insert(rule)
+		err = m.setACL(folder.OrgId, folder.Id, permissions)
+		if err != nil {
+			return nil, MigrationError{
+				Err:     fmt.Errorf("failed to set folder %d under organisation %d permissions: %w", folder.Id, folder.OrgId, err),
+				AlertId: da.Id,
+			}
 		}
+	case dash.FolderId > 0:
+		// link the new rule to the existing folder
+	default:
+		// get or create general folder
+		ptr, err := m.getOrCreateGeneralFolder(dash.OrgId)
 		if err != nil {
-			// TODO better error handling, if constraint
-			rule.Title += fmt.Sprintf(" %v", rule.UID)
-			rule.RuleGroup += fmt.Sprintf(" %v", rule.UID)
-
-			_, err = m.sess.Insert(rule)
-			if err != nil {
-				return err
+			return nil, MigrationError{
+				Err:     fmt.Errorf("failed to get or create general folder under organisation %d: %w", dash.OrgId, err),
+				AlertId: da.Id,
 			}
 		}
+		// No need to assign default permissions to general folder
+		// because they are included to the query result if it's a folder with no permissions
+		// https://github.com/grafana/grafana/blob/076e2ce06a6ecf15804423fcc8dca1b620a321e5/pkg/services/sqlstore/dashboard_acl.go#L109
+		folder = *ptr
+	}
 
-		// create entry in alert_rule_version
-		_, err = m.sess.Insert(rule.makeVersion())
-		if err != nil {
-			return err
+	if folder.Uid == "" {
+		return nil, MigrationError{
+			Err:     fmt.Errorf("empty folder identifier"),
+			AlertId: da.Id,
 		}
 	}
+	rule, err := m.makeAlertRule(*newCond, da, folder.Uid)
+	if err != nil {
+		return nil, err
+	}
 
-	for orgID, amConfig := range amConfigPerOrg {
-		// Create a separate receiver for all the unmigrated channels.
-		err = m.addUnmigratedChannels(orgID, amConfig, allChannelsPerOrg[orgID], defaultChannelsPerOrg[orgID])
+	var receiver string
+	if _, ok := amConfigPerOrg[rule.OrgID]; !ok {
+		m.mg.Logger.Info("no configuration found", "org", rule.OrgID)
+	} else {
+		receiver, err = m.updateReceiverAndRoute(allChannelsPerOrg, defaultChannelsPerOrg, da, rule, amConfigPerOrg[rule.OrgID])
 		if err != nil {
-			return err
+			return nil, err
 		}
+	}
 
-		if err := m.writeAlertmanagerConfig(orgID, amConfig, allChannelsPerOrg[orgID]); err != nil {
+	return &migratedAlert{rule: rule, folder: folder, receiver: receiver}, nil
+}
+
+// insertRule saves a migrated alert rule and its first version.
+func (m *migration) insertRule(rule *alertRule) error {
+	var err error
+	if strings.HasPrefix(m.mg.Dialect.DriverName(), migrator.Postgres) {
+		err = m.mg.InTransaction(func(sess *xorm.Session) error {
+			_, err = sess.Insert(rule)
 			return err
-		}
+		})
+	} else {
+		_, err = m.sess.Insert(rule)
+	}
+	if err != nil {
+		// TODO better error handling, if constraint
+		rule.Title += fmt.Sprintf(" %v", rule.UID)
+		rule.RuleGroup += fmt.Sprintf(" %v", rule.UID)
 
-		if err := m.writeSilencesFile(orgID); err != nil {
-			m.mg.Logger.Error("alert migration error: failed to write silence file", "err", err)
+		_, err = m.sess.Insert(rule)
+		if err != nil {
+			return err
 		}
 	}
 
+	// create entry in alert_rule_version
+	_, err = m.sess.Insert(rule.makeVersion())
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
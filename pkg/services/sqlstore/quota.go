@@ -14,6 +14,33 @@ const (
 	dashboardTarget = "dashboard"
 )
 
+// alertingQuotaUsageSQL are the queries of the usage by an organization of the quota targets of alerting that are
+// not tables. The usage of the silences and of the contact points, which are not in the database, is counted by
+// alerting.
+var alertingQuotaUsageSQL = map[string]string{
+	"alert_rule_group": "SELECT COUNT(*) AS count FROM (SELECT DISTINCT namespace_uid, rule_group FROM alert_rule WHERE org_id=?) rule_groups",
+	"mute_timing":      "SELECT COUNT(*) AS count FROM alert_recurring_silence WHERE org_id=?",
+	"silence":          "",
+	"contact_point":    "",
+}
+
+// getAlertingOrgQuotaUsed returns the usage by the organization of a quota target of alerting that is not a table,
+// and false if the target is not one of them.
+func getAlertingOrgQuotaUsed(target string, orgID int64, isNgAlertEnabled bool) (int64, bool, error) {
+	rawSQL, ok := alertingQuotaUsageSQL[target]
+	if !ok {
+		return 0, false, nil
+	}
+	if rawSQL == "" || !isNgAlertEnabled {
+		return 0, true, nil
+	}
+	resp := make([]*targetCount, 0)
+	if err := x.SQL(rawSQL, orgID).Find(&resp); err != nil {
+		return 0, true, err
+	}
+	return resp[0].Count, true, nil
+}
+
 func init() {
 	bus.AddHandler("sql", GetOrgQuotaByTarget)
 	bus.AddHandler("sql", GetOrgQuotas)
@@ -40,8 +67,11 @@ func GetOrgQuotaByTarget(query *models.GetOrgQuotaByTargetQuery) error {
 		quota.Limit = query.Default
 	}
 
-	var used int64
-	if query.Target != alertRuleTarget || query.IsNgAlertEnabled {
+	used, isAlertingTarget, err := getAlertingOrgQuotaUsed(query.Target, query.OrgId, query.IsNgAlertEnabled)
+	if err != nil {
+		return err
+	}
+	if !isAlertingTarget && (query.Target != alertRuleTarget || query.IsNgAlertEnabled) {
 		// get quota used.
 		rawSQL := fmt.Sprintf("SELECT COUNT(*) AS count FROM %s WHERE org_id=?",
 			dialect.Quote(query.Target))
@@ -93,8 +123,11 @@ func GetOrgQuotas(query *models.GetOrgQuotasQuery) error {
 
 	result := make([]*models.OrgQuotaDTO, len(quotas))
 	for i, q := range quotas {
-		var used int64
-		if q.Target != alertRuleTarget || query.IsNgAlertEnabled {
+		used, isAlertingTarget, err := getAlertingOrgQuotaUsed(q.Target, q.OrgId, query.IsNgAlertEnabled)
+		if err != nil {
+			return err
+		}
+		if !isAlertingTarget && (q.Target != alertRuleTarget || query.IsNgAlertEnabled) {
 			// get quota used.
 			rawSQL := fmt.Sprintf("SELECT COUNT(*) as count from %s where org_id=?", dialect.Quote(q.Target))
 			resp := make([]*targetCount, 0)
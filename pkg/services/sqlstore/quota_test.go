@@ -20,11 +20,15 @@ func TestQuotaCommandsAndQueries(t *testing.T) {
 	setting.Quota = setting.QuotaSettings{
 		Enabled: true,
 		Org: &setting.OrgQuota{
-			User:       5,
-			Dashboard:  5,
-			DataSource: 5,
-			ApiKey:     5,
-			AlertRule:  5,
+			User:           5,
+			Dashboard:      5,
+			DataSource:     5,
+			ApiKey:         5,
+			AlertRule:      5,
+			AlertRuleGroup: 5,
+			Silence:        5,
+			MuteTiming:     5,
+			ContactPoint:   5,
 		},
 		User: &setting.UserQuota{
 			Org: 5,
@@ -101,12 +105,23 @@ func TestQuotaCommandsAndQueries(t *testing.T) {
 			require.Equal(t, int64(0), query.Result.Used)
 		})
 
+		t.Run("Should be able to get zero used org alerting quotas counted by alerting or without rules", func(t *testing.T) {
+			for _, target := range []string{"alert_rule_group", "mute_timing", "silence", "contact_point"} {
+				query := models.GetOrgQuotaByTargetQuery{OrgId: orgId, Target: target, Default: 5, IsNgAlertEnabled: true}
+				err = GetOrgQuotaByTarget(&query)
+
+				require.NoError(t, err)
+				require.Equal(t, int64(5), query.Result.Limit)
+				require.Equal(t, int64(0), query.Result.Used)
+			}
+		})
+
 		t.Run("Should be able to quota list for org", func(t *testing.T) {
 			query := models.GetOrgQuotasQuery{OrgId: orgId}
 			err = GetOrgQuotas(&query)
 
 			require.NoError(t, err)
-			require.Len(t, query.Result, 5)
+			require.Len(t, query.Result, 9)
 			for _, res := range query.Result {
 				limit := int64(5) // default quota limit
 				used := int64(0)
@@ -417,6 +417,162 @@ type Cfg struct {
 
 	// Unified Alerting
 	AdminConfigPollInterval time.Duration
+	// SilenceExpiryWarningContactPoint is the contact point notified of silences about to expire.
+	SilenceExpiryWarningContactPoint string
+	SilenceExpiryWarningPeriod       time.Duration
+	// ScreenshotsEnabled enables the screenshots of the panels linked to alert rules in notifications.
+	ScreenshotsEnabled bool
+	ScreenshotTimeout  time.Duration
+	ScreenshotCacheTTL time.Duration
+	// AlertStateAnnotationsBackend is where the annotations of the changes of the states of the alerts are written.
+	AlertStateAnnotationsBackend string
+	// SecretsCacheTTL is how long the secrets referenced by receivers are cached once resolved.
+	SecretsCacheTTL         time.Duration
+	VaultAddress            string
+	VaultToken              string
+	AWSSecretsManagerRegion string
+	// VaultPathPrefix and AWSSecretsManagerPathPrefix are the prefixes of the paths of the secrets an organization can
+	// reference, where {org_id} is replaced with the ID of the organization. Any path can be referenced if empty.
+	VaultPathPrefix             string
+	AWSSecretsManagerPathPrefix string
+	// AlertmanagerStateStorage is where the notification log and silences of the embedded Alertmanagers are persisted.
+	AlertmanagerStateStorage      string
+	AlertmanagerStateSyncInterval time.Duration
+	AlertmanagerStateBucket       string
+	AlertmanagerStatePrefix       string
+	AlertmanagerStateS3Region     string
+	AlertmanagerStateGCSKeyFile   string
+	// NotificationLogRetention is how long the notification log keeps the notifications of the alert groups.
+	NotificationLogRetention time.Duration
+	// NotificationLogMaintenanceInterval is how often the expired notifications are removed from the notification log.
+	NotificationLogMaintenanceInterval time.Duration
+	// HAPeers are the initial peers of the gossip cluster of the embedded Alertmanagers, clustering is disabled if empty.
+	HAPeers            []string
+	HAListenAddr       string
+	HAAdvertiseAddr    string
+	HAPeerTimeout      time.Duration
+	HAGossipInterval   time.Duration
+	HAPushPullInterval time.Duration
+	// HAStandby is how the replica decides whether its embedded Alertmanagers send the notifications or stay a warm
+	// standby: disabled, manual or leader_election.
+	HAStandby string
+	// MaxQueryResultFrames, MaxQueryResultSeries and MaxQueryResultDataPoints cap the result of each query of an
+	// alert rule evaluation, larger results are truncated. 0 is no limit.
+	MaxQueryResultFrames     int
+	MaxQueryResultSeries     int
+	MaxQueryResultDataPoints int
+	// QueryResultSpillThresholdMB is the estimated size in megabytes of the series of the result of a query of an
+	// alert rule evaluation above which its largest series are spilled to temporary files in QueryResultSpillDir, the
+	// default directory for temporary files if empty. 0 never spills.
+	QueryResultSpillThresholdMB int
+	QueryResultSpillDir         string
+	// RuleMetricsMaxRules is the maximum number of alert rules exporting metrics by rule, 0 is no limit.
+	RuleMetricsMaxRules int
+	// CorrelationLabels are the labels the firing alerts of the different rules share to be correlated into
+	// incidents, the alerts aren't correlated if empty.
+	CorrelationLabels []string
+	// CorrelationWindow is how long after its last firing alert an incident is still joined by the new alerts.
+	CorrelationWindow time.Duration
+	// DispatchShards is the number of shards the flushes of the alert groups are dispatched to by group key, the
+	// flushes are not sharded if 0.
+	DispatchShards int
+	// SchedulerPoolSize is the number of workers of the scheduler evaluating the alert rules, and
+	// SchedulerMaxQueueSize the maximum number of evaluations waiting for a worker, the evaluations over it are
+	// shed. 0 is no limit.
+	SchedulerPoolSize     int
+	SchedulerMaxQueueSize int
+	// LazyOrgLoading loads the alert rules and states of an organization on its first request to the alerting API,
+	// or with the OrgWarmUpBatchSize organizations loaded on each tick of the scheduler, instead of all of them at
+	// startup. The organizations without request for OrgIdleTTL and without alerts are unloaded, 0 is never.
+	LazyOrgLoading     bool
+	OrgWarmUpBatchSize int
+	OrgIdleTTL         time.Duration
+	// AlertingOrgLimits are the limits of the alert rule evaluations of each organization, AlertingOrgLimitsOverrides
+	// replace them for some organizations.
+	AlertingOrgLimits          AlertingOrgLimits
+	AlertingOrgLimitsOverrides map[int64]AlertingOrgLimits
+	// EvaluationWorkers are the addresses of the remote evaluation workers the scheduler sends the evaluations of the
+	// alert rules to, the rules are evaluated locally if empty.
+	EvaluationWorkers []string
+	// EvaluationWorkerListenAddr is the address of the gRPC server of the evaluation worker of this instance, there is
+	// no worker if empty. EvaluationWorkerOnly disables the scheduler of the instance.
+	EvaluationWorkerListenAddr string
+	EvaluationWorkerOnly       bool
+	// EvaluationWorkerToken is the token shared by the scheduler and the evaluation workers.
+	EvaluationWorkerToken string
+	// GitSyncRepository is the Git repository the alerting resources are provisioned from on the GitSyncInterval,
+	// with the provisioning files of the directory GitSyncPath of the branch GitSyncBranch. There is no Git sync if
+	// empty.
+	GitSyncRepository string
+	GitSyncBranch     string
+	GitSyncPath       string
+	GitSyncInterval   time.Duration
+	// StateRemoteWriteURL is the Prometheus remote write endpoint the ALERTS and ALERTS_FOR_STATE series of the alert
+	// instances are written to after each evaluation, they are not written if empty.
+	StateRemoteWriteURL               string
+	StateRemoteWriteBasicAuthUsername string
+	StateRemoteWriteBasicAuthPassword string
+	StateRemoteWriteTimeout           time.Duration
+	// QueryLog is where the datasource queries of the alert rule evaluations are logged, QueryLogFile or QueryLogLoki,
+	// they are not logged if empty.
+	QueryLog                      string
+	QueryLogFilePath              string
+	QueryLogLokiURL               string
+	QueryLogLokiBasicAuthUsername string
+	QueryLogLokiBasicAuthPassword string
+	// SelfMonitoringEnabled notifies the administrators of the organizations of the problems of the alerting: the
+	// ticks missed by the scheduler, an evaluation failure rate of at least SelfMonitoringEvaluationFailureRate over
+	// SelfMonitoringMinEvaluations evaluations, SelfMonitoringNotificationFailures consecutive failures of an
+	// integration and an Alertmanager configuration failing to apply.
+	SelfMonitoringEnabled               bool
+	SelfMonitoringEvaluationFailureRate float64
+	SelfMonitoringMinEvaluations        int
+	SelfMonitoringNotificationFailures  int
+}
+
+// AlertingOrgLimits are the limits of the alert rule evaluations of an organization, so that an organization cannot
+// starve the others. A limit of 0 is no limit.
+type AlertingOrgLimits struct {
+	// MaxConcurrentEvaluations is the maximum number of rules of the organization evaluated at the same time.
+	MaxConcurrentEvaluations int
+	// MaxQueryRange is the maximum time range of a query of a rule.
+	MaxQueryRange time.Duration
+	// MaxInstances is the maximum number of alert instances of all the rules of the organization.
+	MaxInstances int
+	// NotificationsPerHour and NotificationsPerDay are the maximum numbers of notifications sent by the integrations
+	// of the organization in an hour and in a day, by integration type. NotificationQuotaPolicy is how the
+	// notifications over these quotas are handled, summarized if empty.
+	NotificationsPerHour    map[string]int
+	NotificationsPerDay     map[string]int
+	NotificationQuotaPolicy string
+	// ErrorNotificationWindow is the minimum time between the notifications of the alerts firing because their rule
+	// failed to evaluate, which are batched into a summary of the failing rules. They're notified like the other
+	// alerts if 0.
+	ErrorNotificationWindow time.Duration
+}
+
+// The policies of the notifications over the notification quotas of an organization.
+const (
+	// NotificationQuotaPolicySummarize replaces the first notification over the quota of an integration by a
+	// summary of the quota reached, and drops the next ones until the quota is reset.
+	NotificationQuotaPolicySummarize = "summarize"
+	// NotificationQuotaPolicyDrop drops the notifications over the quota until it is reset.
+	NotificationQuotaPolicyDrop = "drop"
+)
+
+const (
+	// QueryLogFile logs the datasource queries of the alert rule evaluations to a file.
+	QueryLogFile = "file"
+	// QueryLogLoki pushes the datasource queries of the alert rule evaluations to Loki.
+	QueryLogLoki = "loki"
+)
+
+// AlertingLimitsForOrg returns the limits of the alert rule evaluations of the organization.
+func (cfg *Cfg) AlertingLimitsForOrg(orgID int64) AlertingOrgLimits {
+	if l, ok := cfg.AlertingOrgLimitsOverrides[orgID]; ok {
+		return l
+	}
+	return cfg.AlertingOrgLimits
 }
 
 // IsLiveConfigEnabled returns true if live should be able to save configs to SQL tables
@@ -1386,9 +1542,244 @@ func (cfg *Cfg) readUnifiedAlertingSettings(iniFile *ini.File) error {
 	ua := iniFile.Section("unified_alerting")
 	s := ua.Key("admin_config_poll_interval_seconds").MustInt(60)
 	cfg.AdminConfigPollInterval = time.Second * time.Duration(s)
+	cfg.SilenceExpiryWarningContactPoint = ua.Key("silence_expiry_warning_contact_point").MustString("")
+	s = ua.Key("silence_expiry_warning_period_seconds").MustInt(900)
+	cfg.SilenceExpiryWarningPeriod = time.Second * time.Duration(s)
+	cfg.ScreenshotsEnabled = ua.Key("screenshots_enabled").MustBool(false)
+	s = ua.Key("screenshot_timeout_seconds").MustInt(10)
+	cfg.ScreenshotTimeout = time.Second * time.Duration(s)
+	s = ua.Key("screenshot_cache_ttl_seconds").MustInt(60)
+	cfg.ScreenshotCacheTTL = time.Second * time.Duration(s)
+	cfg.AlertStateAnnotationsBackend = ua.Key("state_annotations_backend").MustString("sql")
+	switch cfg.AlertStateAnnotationsBackend {
+	case "sql", "table", "disabled":
+	default:
+		return fmt.Errorf("unsupported state_annotations_backend %q, should be one of sql, table or disabled", cfg.AlertStateAnnotationsBackend)
+	}
+	s = ua.Key("secrets_cache_ttl_seconds").MustInt(300)
+	cfg.SecretsCacheTTL = time.Second * time.Duration(s)
+	cfg.VaultAddress = ua.Key("vault_address").MustString("")
+	cfg.VaultToken = ua.Key("vault_token").MustString("")
+	cfg.AWSSecretsManagerRegion = ua.Key("aws_secrets_manager_region").MustString("")
+	cfg.VaultPathPrefix = ua.Key("vault_path_prefix").MustString("secret/grafana/org-{org_id}/")
+	cfg.AWSSecretsManagerPathPrefix = ua.Key("aws_secrets_manager_path_prefix").MustString("grafana/org-{org_id}/")
+	cfg.AlertmanagerStateStorage = ua.Key("alertmanager_state_storage").MustString("disk")
+	switch cfg.AlertmanagerStateStorage {
+	case "disk", "database", "s3", "gcs":
+	default:
+		return fmt.Errorf("unsupported alertmanager_state_storage %q, should be one of disk, database, s3 or gcs", cfg.AlertmanagerStateStorage)
+	}
+	s = ua.Key("alertmanager_state_sync_interval_seconds").MustInt(60)
+	cfg.AlertmanagerStateSyncInterval = time.Second * time.Duration(s)
+	cfg.AlertmanagerStateBucket = ua.Key("alertmanager_state_bucket").MustString("")
+	cfg.AlertmanagerStatePrefix = ua.Key("alertmanager_state_prefix").MustString("alertmanager")
+	cfg.AlertmanagerStateS3Region = ua.Key("alertmanager_state_s3_region").MustString("")
+	cfg.AlertmanagerStateGCSKeyFile = ua.Key("alertmanager_state_gcs_key_file").MustString("")
+	if (cfg.AlertmanagerStateStorage == "s3" || cfg.AlertmanagerStateStorage == "gcs") && cfg.AlertmanagerStateBucket == "" {
+		return fmt.Errorf("alertmanager_state_bucket is required to persist the Alertmanager state in %s", cfg.AlertmanagerStateStorage)
+	}
+	cfg.HAPeers = util.SplitString(ua.Key("ha_peers").MustString(""))
+	cfg.HAListenAddr = ua.Key("ha_listen_address").MustString("0.0.0.0:9094")
+	cfg.HAAdvertiseAddr = ua.Key("ha_advertise_address").MustString("")
+	cfg.HAStandby = ua.Key("ha_standby").MustString("disabled")
+	switch cfg.HAStandby {
+	case "disabled", "manual", "leader_election":
+	default:
+		return fmt.Errorf("unsupported ha_standby %q, should be one of disabled, manual or leader_election", cfg.HAStandby)
+	}
+	cfg.CorrelationLabels = util.SplitString(ua.Key("correlation_labels").MustString(""))
+	durations := []struct {
+		key    string
+		def    string
+		target *time.Duration
+	}{
+		{"ha_peer_timeout", "15s", &cfg.HAPeerTimeout},
+		{"ha_gossip_interval", "200ms", &cfg.HAGossipInterval},
+		{"ha_push_pull_interval", "60s", &cfg.HAPushPullInterval},
+		{"state_remote_write_timeout", "10s", &cfg.StateRemoteWriteTimeout},
+		{"git_sync_interval", "5m", &cfg.GitSyncInterval},
+		{"org_idle_ttl", "0s", &cfg.OrgIdleTTL},
+		{"notification_log_retention", "120h", &cfg.NotificationLogRetention},
+		{"notification_log_maintenance_interval", "15m", &cfg.NotificationLogMaintenanceInterval},
+		{"correlation_window", "5m", &cfg.CorrelationWindow},
+	}
+	for _, d := range durations {
+		v, err := time.ParseDuration(ua.Key(d.key).MustString(d.def))
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", d.key, err)
+		}
+		*d.target = v
+	}
+	if cfg.NotificationLogRetention <= 0 {
+		return fmt.Errorf("invalid notification_log_retention: must be positive, got %s", cfg.NotificationLogRetention)
+	}
+	if cfg.NotificationLogMaintenanceInterval <= 0 {
+		return fmt.Errorf("invalid notification_log_maintenance_interval: must be positive, got %s", cfg.NotificationLogMaintenanceInterval)
+	}
+	if cfg.CorrelationWindow < 0 {
+		return fmt.Errorf("invalid correlation_window: must not be negative, got %s", cfg.CorrelationWindow)
+	}
+	limits := []struct {
+		key    string
+		def    int
+		target *int
+	}{
+		{"max_query_result_frames", 10000, &cfg.MaxQueryResultFrames},
+		{"max_query_result_series", 10000, &cfg.MaxQueryResultSeries},
+		{"max_query_result_datapoints", 5000000, &cfg.MaxQueryResultDataPoints},
+		{"rule_metrics_max_rules", 100, &cfg.RuleMetricsMaxRules},
+		{"scheduler_max_queue_size", 100000, &cfg.SchedulerMaxQueueSize},
+		{"org_warm_up_batch_size", 10, &cfg.OrgWarmUpBatchSize},
+		{"dispatch_shards", 0, &cfg.DispatchShards},
+		{"query_result_spill_threshold_mb", 0, &cfg.QueryResultSpillThresholdMB},
+	}
+	for _, l := range limits {
+		v := ua.Key(l.key).MustInt(l.def)
+		if v < 0 {
+			return fmt.Errorf("invalid %s: must not be negative, got %d", l.key, v)
+		}
+		*l.target = v
+	}
+	cfg.QueryResultSpillDir = ua.Key("query_result_spill_dir").MustString("")
+	cfg.SchedulerPoolSize = ua.Key("scheduler_pool_size").MustInt(100)
+	if cfg.SchedulerPoolSize <= 0 {
+		return fmt.Errorf("invalid scheduler_pool_size: must be positive, got %d", cfg.SchedulerPoolSize)
+	}
+	cfg.LazyOrgLoading = ua.Key("lazy_org_loading").MustBool(false)
+	cfg.StateRemoteWriteURL = ua.Key("state_remote_write_url").MustString("")
+	cfg.StateRemoteWriteBasicAuthUsername = ua.Key("state_remote_write_basic_auth_username").MustString("")
+	cfg.StateRemoteWriteBasicAuthPassword = ua.Key("state_remote_write_basic_auth_password").MustString("")
+	cfg.QueryLog = ua.Key("query_log").MustString("")
+	cfg.QueryLogFilePath = ua.Key("query_log_file_path").MustString("")
+	cfg.QueryLogLokiURL = ua.Key("query_log_loki_url").MustString("")
+	cfg.QueryLogLokiBasicAuthUsername = ua.Key("query_log_loki_basic_auth_username").MustString("")
+	cfg.QueryLogLokiBasicAuthPassword = ua.Key("query_log_loki_basic_auth_password").MustString("")
+	switch cfg.QueryLog {
+	case "", QueryLogFile:
+	case QueryLogLoki:
+		if cfg.QueryLogLokiURL == "" {
+			return fmt.Errorf("query_log_loki_url is required to push the query log to Loki")
+		}
+	default:
+		return fmt.Errorf("unsupported query_log %q, should be file, loki or empty", cfg.QueryLog)
+	}
+	cfg.SelfMonitoringEnabled = ua.Key("self_monitoring_enabled").MustBool(false)
+	cfg.SelfMonitoringEvaluationFailureRate = ua.Key("self_monitoring_evaluation_failure_rate").MustFloat64(0.5)
+	if cfg.SelfMonitoringEvaluationFailureRate <= 0 || cfg.SelfMonitoringEvaluationFailureRate > 1 {
+		return fmt.Errorf("invalid self_monitoring_evaluation_failure_rate: must be between 0 and 1, got %v", cfg.SelfMonitoringEvaluationFailureRate)
+	}
+	cfg.SelfMonitoringMinEvaluations = ua.Key("self_monitoring_min_evaluations").MustInt(10)
+	cfg.SelfMonitoringNotificationFailures = ua.Key("self_monitoring_notification_failures").MustInt(5)
+	if cfg.SelfMonitoringNotificationFailures < 1 {
+		return fmt.Errorf("invalid self_monitoring_notification_failures: must be positive, got %d", cfg.SelfMonitoringNotificationFailures)
+	}
+	cfg.EvaluationWorkers = util.SplitString(ua.Key("evaluation_workers").MustString(""))
+	cfg.EvaluationWorkerListenAddr = ua.Key("evaluation_worker_listen_address").MustString("")
+	cfg.EvaluationWorkerOnly = ua.Key("evaluation_worker_only").MustBool(false)
+	cfg.EvaluationWorkerToken = ua.Key("evaluation_worker_token").MustString("")
+	if cfg.EvaluationWorkerOnly && cfg.EvaluationWorkerListenAddr == "" {
+		return fmt.Errorf("evaluation_worker_listen_address is required to run an evaluation worker only")
+	}
+	cfg.GitSyncRepository = ua.Key("git_sync_repository").MustString("")
+	cfg.GitSyncBranch = ua.Key("git_sync_branch").MustString("main")
+	cfg.GitSyncPath = ua.Key("git_sync_path").MustString("")
+	if cfg.GitSyncRepository != "" && cfg.GitSyncInterval <= 0 {
+		return fmt.Errorf("invalid git_sync_interval: must be positive, got %s", cfg.GitSyncInterval)
+	}
+	orgLimits, err := readAlertingOrgLimits(ua, "org_", AlertingOrgLimits{})
+	if err != nil {
+		return err
+	}
+	cfg.AlertingOrgLimits = orgLimits
+	cfg.AlertingOrgLimitsOverrides = make(map[int64]AlertingOrgLimits)
+	for _, section := range iniFile.Sections() {
+		if !strings.HasPrefix(section.Name(), "unified_alerting.org_limits.") {
+			continue
+		}
+		orgID, err := strconv.ParseInt(strings.TrimPrefix(section.Name(), "unified_alerting.org_limits."), 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid organization ID in section %s: %w", section.Name(), err)
+		}
+		// the limits that are not overridden are the limits of all the organizations
+		l, err := readAlertingOrgLimits(section, "", cfg.AlertingOrgLimits)
+		if err != nil {
+			return fmt.Errorf("%s: %w", section.Name(), err)
+		}
+		cfg.AlertingOrgLimitsOverrides[orgID] = l
+	}
 	return nil
 }
 
+func readAlertingOrgLimits(section *ini.Section, prefix string, defaults AlertingOrgLimits) (AlertingOrgLimits, error) {
+	l := AlertingOrgLimits{
+		MaxConcurrentEvaluations: section.Key(prefix + "max_concurrent_evaluations").MustInt(defaults.MaxConcurrentEvaluations),
+		MaxInstances:             section.Key(prefix + "max_instances").MustInt(defaults.MaxInstances),
+		MaxQueryRange:            defaults.MaxQueryRange,
+		ErrorNotificationWindow:  defaults.ErrorNotificationWindow,
+		NotificationsPerHour:     defaults.NotificationsPerHour,
+		NotificationsPerDay:      defaults.NotificationsPerDay,
+		NotificationQuotaPolicy:  section.Key(prefix + "notification_quota_policy").MustString(defaults.NotificationQuotaPolicy),
+	}
+	switch l.NotificationQuotaPolicy {
+	case "", NotificationQuotaPolicySummarize, NotificationQuotaPolicyDrop:
+	default:
+		return l, fmt.Errorf("invalid %snotification_quota_policy %q: must be %s or %s", prefix, l.NotificationQuotaPolicy, NotificationQuotaPolicySummarize, NotificationQuotaPolicyDrop)
+	}
+	if l.MaxConcurrentEvaluations < 0 || l.MaxInstances < 0 {
+		return l, fmt.Errorf("invalid %smax_concurrent_evaluations or %smax_instances: must not be negative", prefix, prefix)
+	}
+	for key, target := range map[string]*map[string]int{
+		"notifications_per_hour": &l.NotificationsPerHour,
+		"notifications_per_day":  &l.NotificationsPerDay,
+	} {
+		s := section.Key(prefix + key).String()
+		if s == "" {
+			continue
+		}
+		quotas, err := parseNotificationQuotas(s)
+		if err != nil {
+			return l, fmt.Errorf("invalid %s%s: %w", prefix, key, err)
+		}
+		*target = quotas
+	}
+	if s := section.Key(prefix + "max_query_range").String(); s != "" {
+		v, err := gtime.ParseDuration(s)
+		if err != nil {
+			return l, fmt.Errorf("invalid %smax_query_range: %w", prefix, err)
+		}
+		l.MaxQueryRange = v
+	}
+	if s := section.Key(prefix + "error_notification_window").String(); s != "" {
+		v, err := gtime.ParseDuration(s)
+		if err != nil {
+			return l, fmt.Errorf("invalid %serror_notification_window: %w", prefix, err)
+		}
+		if v < 0 {
+			return l, fmt.Errorf("invalid %serror_notification_window: must not be negative, got %s", prefix, v)
+		}
+		l.ErrorNotificationWindow = v
+	}
+	return l, nil
+}
+
+// parseNotificationQuotas parses a comma-separated list of integration types and their quotas, such as
+// "email:100,slack:500".
+func parseNotificationQuotas(s string) (map[string]int, error) {
+	quotas := make(map[string]int)
+	for _, q := range util.SplitString(s) {
+		parts := strings.SplitN(q, ":", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("%q is not <integration type>:<quota>", q)
+		}
+		v, err := strconv.Atoi(parts[1])
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("the quota of %q must be a number, not negative", parts[0])
+		}
+		quotas[parts[0]] = v
+	}
+	return quotas, nil
+}
+
 func readAlertingSettings(iniFile *ini.File) error {
 	alerting := iniFile.Section("alerting")
 	AlertingEnabled = alerting.Key("enabled").MustBool(true)
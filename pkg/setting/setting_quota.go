@@ -5,11 +5,15 @@ import (
 )
 
 type OrgQuota struct {
-	User       int64 `target:"org_user"`
-	DataSource int64 `target:"data_source"`
-	Dashboard  int64 `target:"dashboard"`
-	ApiKey     int64 `target:"api_key"`
-	AlertRule  int64 `target:"alert_rule"`
+	User           int64 `target:"org_user"`
+	DataSource     int64 `target:"data_source"`
+	Dashboard      int64 `target:"dashboard"`
+	ApiKey         int64 `target:"api_key"`
+	AlertRule      int64 `target:"alert_rule"`
+	AlertRuleGroup int64 `target:"alert_rule_group"`
+	Silence        int64 `target:"silence"`
+	MuteTiming     int64 `target:"mute_timing"`
+	ContactPoint   int64 `target:"contact_point"`
 }
 
 type UserQuota struct {
@@ -68,17 +72,26 @@ func (cfg *Cfg) readQuotaSettings() {
 
 	var alertOrgQuota int64
 	var alertGlobalQuota int64
+	var alertRuleGroupOrgQuota, silenceOrgQuota, muteTimingOrgQuota, contactPointOrgQuota int64
 	if cfg.IsNgAlertEnabled() {
 		alertOrgQuota = quota.Key("org_alert_rule").MustInt64(100)
 		alertGlobalQuota = quota.Key("global_alert_rule").MustInt64(-1)
+		alertRuleGroupOrgQuota = quota.Key("org_alert_rule_group").MustInt64(-1)
+		silenceOrgQuota = quota.Key("org_silence").MustInt64(-1)
+		muteTimingOrgQuota = quota.Key("org_mute_timing").MustInt64(-1)
+		contactPointOrgQuota = quota.Key("org_contact_point").MustInt64(-1)
 	}
 	// per ORG Limits
 	Quota.Org = &OrgQuota{
-		User:       quota.Key("org_user").MustInt64(10),
-		DataSource: quota.Key("org_data_source").MustInt64(10),
-		Dashboard:  quota.Key("org_dashboard").MustInt64(10),
-		ApiKey:     quota.Key("org_api_key").MustInt64(10),
-		AlertRule:  alertOrgQuota,
+		User:           quota.Key("org_user").MustInt64(10),
+		DataSource:     quota.Key("org_data_source").MustInt64(10),
+		Dashboard:      quota.Key("org_dashboard").MustInt64(10),
+		ApiKey:         quota.Key("org_api_key").MustInt64(10),
+		AlertRule:      alertOrgQuota,
+		AlertRuleGroup: alertRuleGroupOrgQuota,
+		Silence:        silenceOrgQuota,
+		MuteTiming:     muteTimingOrgQuota,
+		ContactPoint:   contactPointOrgQuota,
 	}
 
 	// per User limits
@@ -427,3 +427,74 @@ func TestGetCDNPathWithAlphaVersion(t *testing.T) {
 	require.Equal(t, "http://cdn.grafana.com/grafana-oss/pre-releases/v7.5.0-alpha.11124/", cfg.GetContentDeliveryURL("grafana-oss"))
 	require.Equal(t, "http://cdn.grafana.com/grafana/pre-releases/v7.5.0-alpha.11124/", cfg.GetContentDeliveryURL("grafana"))
 }
+
+func TestAlertingOrgLimits(t *testing.T) {
+	f := ini.Empty()
+	ua, err := f.NewSection("unified_alerting")
+	require.NoError(t, err)
+	_, err = ua.NewKey("org_max_concurrent_evaluations", "10")
+	require.NoError(t, err)
+	_, err = ua.NewKey("org_max_query_range", "1d")
+	require.NoError(t, err)
+	override, err := f.NewSection("unified_alerting.org_limits.2")
+	require.NoError(t, err)
+	_, err = override.NewKey("max_instances", "100")
+	require.NoError(t, err)
+
+	cfg := NewCfg()
+	require.NoError(t, cfg.readUnifiedAlertingSettings(f))
+	require.Equal(t, AlertingOrgLimits{MaxConcurrentEvaluations: 10, MaxQueryRange: 24 * time.Hour}, cfg.AlertingLimitsForOrg(1))
+	require.Equal(t, AlertingOrgLimits{MaxConcurrentEvaluations: 10, MaxQueryRange: 24 * time.Hour, MaxInstances: 100}, cfg.AlertingLimitsForOrg(2))
+
+	_, err = override.NewKey("max_concurrent_evaluations", "-1")
+	require.NoError(t, err)
+	require.Error(t, cfg.readUnifiedAlertingSettings(f))
+}
+
+func TestAlertingNotificationQuotas(t *testing.T) {
+	f := ini.Empty()
+	ua, err := f.NewSection("unified_alerting")
+	require.NoError(t, err)
+	_, err = ua.NewKey("org_notifications_per_hour", "email:100, slack:500")
+	require.NoError(t, err)
+	override, err := f.NewSection("unified_alerting.org_limits.2")
+	require.NoError(t, err)
+	_, err = override.NewKey("notifications_per_day", "email:1000")
+	require.NoError(t, err)
+	_, err = override.NewKey("notification_quota_policy", "drop")
+	require.NoError(t, err)
+
+	cfg := NewCfg()
+	require.NoError(t, cfg.readUnifiedAlertingSettings(f))
+	require.Equal(t, map[string]int{"email": 100, "slack": 500}, cfg.AlertingLimitsForOrg(1).NotificationsPerHour)
+	require.Nil(t, cfg.AlertingLimitsForOrg(1).NotificationsPerDay)
+	require.Equal(t, "", cfg.AlertingLimitsForOrg(1).NotificationQuotaPolicy)
+	require.Equal(t, map[string]int{"email": 100, "slack": 500}, cfg.AlertingLimitsForOrg(2).NotificationsPerHour)
+	require.Equal(t, map[string]int{"email": 1000}, cfg.AlertingLimitsForOrg(2).NotificationsPerDay)
+	require.Equal(t, NotificationQuotaPolicyDrop, cfg.AlertingLimitsForOrg(2).NotificationQuotaPolicy)
+
+	_, err = ua.NewKey("org_notifications_per_day", "email")
+	require.NoError(t, err)
+	require.Error(t, cfg.readUnifiedAlertingSettings(f))
+}
+
+func TestAlertingErrorNotificationWindow(t *testing.T) {
+	f := ini.Empty()
+	ua, err := f.NewSection("unified_alerting")
+	require.NoError(t, err)
+	_, err = ua.NewKey("org_error_notification_window", "1h")
+	require.NoError(t, err)
+	override, err := f.NewSection("unified_alerting.org_limits.2")
+	require.NoError(t, err)
+	_, err = override.NewKey("error_notification_window", "0")
+	require.NoError(t, err)
+
+	cfg := NewCfg()
+	require.NoError(t, cfg.readUnifiedAlertingSettings(f))
+	require.Equal(t, time.Hour, cfg.AlertingLimitsForOrg(1).ErrorNotificationWindow)
+	require.Equal(t, time.Duration(0), cfg.AlertingLimitsForOrg(2).ErrorNotificationWindow)
+
+	_, err = ua.NewKey("org_error_notification_window", "-1h")
+	require.NoError(t, err)
+	require.Error(t, cfg.readUnifiedAlertingSettings(f))
+}
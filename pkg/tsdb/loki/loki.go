@@ -58,6 +58,9 @@ var (
 	legendFormat = regexp.MustCompile(`\{\{\s*(.+?)\s*\}\}`)
 )
 
+// defaultMaxLines is the maximum number of lines of a log query without maxLines.
+const defaultMaxLines = 1000
+
 type datasourceInfo struct {
 	HTTPClient        *http.Client
 	URL               string
@@ -73,6 +76,7 @@ type ResponseModel struct {
 	Interval     string `json:"interval"`
 	IntervalMS   int    `json:"intervalMS"`
 	Resolution   int64  `json:"resolution"`
+	MaxLines     int    `json:"maxLines"`
 }
 
 func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.InstanceFactoryFunc {
@@ -144,8 +148,11 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		span.SetTag("stop_unixnano", query.End.UnixNano())
 		defer span.Finish()
 
-		//Currently hard coded as not used - applies to log queries
-		limit := 1000
+		//Applies to log queries, such as the queries of log alert conditions
+		limit := defaultMaxLines
+		if query.MaxLines > 0 {
+			limit = query.MaxLines
+		}
 		//Currently hard coded as not used - applies to queries which produce a stream response
 		interval := time.Second * 1
 
@@ -216,6 +223,7 @@ func (s *Service) parseQuery(dsInfo *datasourceInfo, queryContext *backend.Query
 			Expr:         model.Expr,
 			Step:         step,
 			LegendFormat: model.LegendFormat,
+			MaxLines:     model.MaxLines,
 			Start:        start,
 			End:          end,
 			RefID:        query.RefID,
@@ -228,6 +236,11 @@ func (s *Service) parseQuery(dsInfo *datasourceInfo, queryContext *backend.Query
 func parseResponse(value *loghttp.QueryResponse, query *lokiQuery) (data.Frames, error) {
 	frames := data.Frames{}
 
+	//Log queries return streams, a frame of log lines for each stream
+	if streams, ok := value.Data.Result.(loghttp.Streams); ok {
+		return parseStreams(streams), nil
+	}
+
 	//We are currently processing only matrix results (for alerting)
 	matrix, ok := value.Data.Result.(loghttp.Matrix)
 	if !ok {
@@ -257,6 +270,32 @@ func parseResponse(value *loghttp.QueryResponse, query *lokiQuery) (data.Frames,
 	return frames, nil
 }
 
+func parseStreams(streams loghttp.Streams) data.Frames {
+	frames := make(data.Frames, 0, len(streams))
+	for _, stream := range streams {
+		name := stream.Labels.String()
+		tags := make(map[string]string, len(stream.Labels))
+		timeVector := make([]time.Time, 0, len(stream.Entries))
+		lines := make([]string, 0, len(stream.Entries))
+
+		for k, v := range stream.Labels {
+			tags[k] = v
+		}
+
+		for _, e := range stream.Entries {
+			timeVector = append(timeVector, e.Timestamp.UTC())
+			lines = append(lines, e.Line)
+		}
+
+		frame := data.NewFrame(name,
+			data.NewField("time", nil, timeVector),
+			data.NewField("line", tags, lines))
+		frame.SetMeta(&data.FrameMeta{PreferredVisualization: data.VisTypeLogs})
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
 func (s *Service) getDSInfo(pluginCtx backend.PluginContext) (*datasourceInfo, error) {
 	i, err := s.im.Get(pluginCtx)
 	if err != nil {
@@ -172,6 +172,37 @@ func TestParseResponse(t *testing.T) {
 			t.Errorf("Result mismatch (-want +got):\n%s", diff)
 		}
 	})
+
+	t.Run("streams should be parsed as log lines", func(t *testing.T) {
+		value := loghttp.QueryResponse{
+			Data: loghttp.QueryResponseData{
+				Result: loghttp.Streams{
+					loghttp.Stream{
+						Labels: loghttp.LabelSet{"app": "Application"},
+						Entries: []loghttp.Entry{
+							{Timestamp: time.Unix(2, 0), Line: "level=error msg=second"},
+							{Timestamp: time.Unix(1, 0), Line: "level=error msg=first"},
+						},
+					},
+				},
+			},
+		}
+
+		frames, err := parseResponse(&value, &lokiQuery{})
+		require.NoError(t, err)
+
+		testFrame := data.NewFrame(`{app="Application"}`,
+			data.NewField("time", nil, []time.Time{
+				time.Date(1970, 1, 1, 0, 0, 2, 0, time.UTC),
+				time.Date(1970, 1, 1, 0, 0, 1, 0, time.UTC),
+			}),
+			data.NewField("line", data.Labels{"app": "Application"}, []string{"level=error msg=second", "level=error msg=first"}),
+		)
+		testFrame.SetMeta(&data.FrameMeta{PreferredVisualization: data.VisTypeLogs})
+		if diff := cmp.Diff(testFrame, frames[0], data.FrameTestCompareOptions()...); diff != "" {
+			t.Errorf("Result mismatch (-want +got):\n%s", diff)
+		}
+	})
 }
 
 type mockCalculator struct {
@@ -6,6 +6,7 @@ type lokiQuery struct {
 	Expr         string
 	Step         time.Duration
 	LegendFormat string
+	MaxLines     int
 	Start        time.Time
 	End          time.Time
 	RefID        string
@@ -1,6 +1,12 @@
 import React, { PureComponent } from 'react';
 import { DragDropContext, Droppable, DropResult } from 'react-beautiful-dnd';
-import { DataQuery, DataSourceInstanceSettings, PanelData, RelativeTimeRange } from '@grafana/data';
+import {
+  DataQuery,
+  DataSourceInstanceSettings,
+  getDefaultRelativeTimeRange,
+  PanelData,
+  RelativeTimeRange,
+} from '@grafana/data';
 import { getDataSourceSrv } from '@grafana/runtime';
 import { QueryWrapper } from './QueryWrapper';
 import { AlertQuery } from 'app/types/unified-alerting-dto';
@@ -44,7 +50,22 @@ export class QueryRows extends PureComponent<Props, State> {
         }
         return {
           ...item,
-          relativeTimeRange: timeRange,
+          relativeTimeRange: { ...timeRange, timeShift: item.relativeTimeRange?.timeShift },
+        };
+      })
+    );
+  };
+
+  onChangeTimeShift = (timeShift: number | undefined, index: number) => {
+    const { queries, onQueriesChange } = this.props;
+    onQueriesChange(
+      queries.map((item, itemIndex) => {
+        if (itemIndex !== index) {
+          return item;
+        }
+        return {
+          ...item,
+          relativeTimeRange: { ...(item.relativeTimeRange ?? getDefaultRelativeTimeRange()), timeShift },
         };
       })
     );
@@ -161,6 +182,7 @@ export class QueryRows extends PureComponent<Props, State> {
                       onDuplicateQuery={onDuplicateQuery}
                       onRunQueries={onRunQueries}
                       onChangeTimeRange={this.onChangeTimeRange}
+                      onChangeTimeShift={this.onChangeTimeShift}
                     />
                   );
                 })}
@@ -1,4 +1,4 @@
-import React, { FC, ReactNode, useState } from 'react';
+import React, { FC, FocusEvent, FormEvent, ReactNode, useState } from 'react';
 import { css } from '@emotion/css';
 import { cloneDeep } from 'lodash';
 import {
@@ -8,8 +8,9 @@ import {
   PanelData,
   RelativeTimeRange,
   getDefaultRelativeTimeRange,
+  rangeUtil,
 } from '@grafana/data';
-import { useStyles2, RelativeTimeRangePicker } from '@grafana/ui';
+import { useStyles2, Input, InlineSwitch, RelativeTimeRangePicker, Tooltip } from '@grafana/ui';
 import { QueryEditorRow } from 'app/features/query/components/QueryEditorRow';
 import { VizWrapper } from './VizWrapper';
 import { isExpressionQuery } from 'app/features/expressions/guards';
@@ -17,6 +18,9 @@ import { TABLE, TIMESERIES } from '../../utils/constants';
 import { AlertQuery } from 'app/types/unified-alerting-dto';
 import { SupportedPanelPlugins } from '../PanelPluginsButtonGroup';
 
+// evalMode logs counts the log lines of each stream of a query of a logs data source.
+type LogsEvalQuery = DataQuery & { evalMode?: 'logs' };
+
 interface Props {
   data: PanelData;
   query: AlertQuery;
@@ -25,6 +29,7 @@ interface Props {
   onChangeDataSource: (settings: DataSourceInstanceSettings, index: number) => void;
   onChangeQuery: (query: DataQuery, index: number) => void;
   onChangeTimeRange?: (timeRange: RelativeTimeRange, index: number) => void;
+  onChangeTimeShift?: (timeShift: number | undefined, index: number) => void;
   onRemoveQuery: (query: DataQuery) => void;
   onDuplicateQuery: (query: AlertQuery) => void;
   onRunQueries: () => void;
@@ -38,6 +43,7 @@ export const QueryWrapper: FC<Props> = ({
   onChangeDataSource,
   onChangeQuery,
   onChangeTimeRange,
+  onChangeTimeShift,
   onRunQueries,
   onRemoveQuery,
   onDuplicateQuery,
@@ -53,11 +59,47 @@ export const QueryWrapper: FC<Props> = ({
       return null;
     }
 
+    const timeShift = query.relativeTimeRange?.timeShift;
+    const onTimeShiftBlur = (event: FocusEvent<HTMLInputElement>) => {
+      const value = event.target.value.trim();
+      onChangeTimeShift?.(value ? rangeUtil.intervalToSeconds(value) : undefined, index);
+    };
+
+    const onLogsModeChange = (event: FormEvent<HTMLInputElement>) => {
+      const model: LogsEvalQuery = { ...query.model, evalMode: event.currentTarget.checked ? 'logs' : undefined };
+      onChangeQuery(model, index);
+    };
+
     return (
-      <RelativeTimeRangePicker
-        timeRange={query.relativeTimeRange ?? getDefaultRelativeTimeRange()}
-        onChange={(range) => onChangeTimeRange(range, index)}
-      />
+      <>
+        {dsSettings.meta.logs && (
+          <Tooltip
+            content={`Count the log lines of each stream. The most recent lines are in {{ $values.${query.refId}.Samples }} in annotations`}
+          >
+            <InlineSwitch
+              label="Count log lines"
+              showLabel={true}
+              value={(query.model as LogsEvalQuery).evalMode === 'logs'}
+              onChange={onLogsModeChange}
+            />
+          </Tooltip>
+        )}
+        <RelativeTimeRangePicker
+          timeRange={query.relativeTimeRange ?? getDefaultRelativeTimeRange()}
+          onChange={(range) => onChangeTimeRange(range, index)}
+        />
+        {onChangeTimeShift && (
+          <Tooltip content="Optional. Move the time range back, such as 1d to compare with the same time yesterday">
+            <Input
+              className={styles.timeShift}
+              placeholder="Time shift"
+              defaultValue={timeShift ? rangeUtil.secondsToHms(timeShift) : ''}
+              onBlur={onTimeShiftBlur}
+              width={12}
+            />
+          </Tooltip>
+        )}
+      </>
     );
   };
 
@@ -92,4 +134,7 @@ const getStyles = (theme: GrafanaTheme2) => ({
     border-radius: ${theme.shape.borderRadius(1)};
     padding-bottom: ${theme.spacing(1)};
   `,
+  timeShift: css`
+    margin-left: ${theme.spacing(1)};
+  `,
 });
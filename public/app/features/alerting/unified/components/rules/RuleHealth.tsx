@@ -20,6 +20,25 @@ export const RuleHealth: FC<Prom> = ({ rule }) => {
       </Tooltip>
     );
   }
+  if (rule.warnings?.length) {
+    return (
+      <Tooltip
+        theme="info"
+        content={
+          <>
+            {rule.warnings.map((warning) => (
+              <div key={warning}>{warning}</div>
+            ))}
+          </>
+        }
+      >
+        <div className={style.warn}>
+          <Icon name="exclamation-triangle" />
+          <span>{rule.health}</span>
+        </div>
+      </Tooltip>
+    );
+  }
   return <>{rule.health}</>;
 };
 
@@ -316,6 +316,8 @@ async function saveGrafanaRule(values: RuleFormValues, existing?: RuleWithLocati
       } else {
         const uid = (freshExisting.rule as RulerGrafanaRuleDTO).grafana_alert.uid!;
         formRule.grafana_alert.uid = uid;
+        // the version the form was opened with, so the update fails if someone else changed the rule meanwhile
+        formRule.grafana_alert.version = (existing.rule as RulerGrafanaRuleDTO).grafana_alert.version;
         await setRulerRuleGroup(GRAFANA_RULES_SOURCE_NAME, freshExisting.namespace, {
           name: freshExisting.group.name,
           interval: evaluateEvery,
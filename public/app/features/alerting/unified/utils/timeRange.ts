@@ -31,7 +31,15 @@ const getReferencedIds = (model: ExpressionQuery, queries: AlertQuery[]): string
       return getReferencedIdsForMath(model, queries);
     case ExpressionQueryType.resample:
     case ExpressionQueryType.reduce:
+    case ExpressionQueryType.threshold:
+    case ExpressionQueryType.movingWindow:
+    case ExpressionQueryType.rate:
+    case ExpressionQueryType.outliers:
+    case ExpressionQueryType.forecast:
+    case ExpressionQueryType.absent:
       return getReferencedIdsForReduce(model);
+    case ExpressionQueryType.join:
+      return getReferencedIdsForJoin(model);
   }
 };
 
@@ -74,3 +82,8 @@ const getReferencedIdsForMath = (model: ExpressionQuery, queries: AlertQuery[])
 const getReferencedIdsForReduce = (model: ExpressionQuery) => {
   return model.expression ? [model.expression] : undefined;
 };
+
+const getReferencedIdsForJoin = (model: ExpressionQuery) => {
+  const refIds = [model.left, model.right].filter((refId): refId is string => Boolean(refId));
+  return refIds.length ? refIds : undefined;
+};
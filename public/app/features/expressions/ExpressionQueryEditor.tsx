@@ -6,6 +6,13 @@ import { Resample } from './components/Resample';
 import { Reduce } from './components/Reduce';
 import { Math } from './components/Math';
 import { ClassicConditions } from './components/ClassicConditions';
+import { Threshold } from './components/Threshold';
+import { MovingWindow } from './components/MovingWindow';
+import { Rate } from './components/Rate';
+import { Outliers } from './components/Outliers';
+import { Forecast } from './components/Forecast';
+import { Absent } from './components/Absent';
+import { Join } from './components/Join';
 import { getDefaults } from './utils/expressionTypes';
 import { ExpressionQuery, ExpressionQueryType, gelTypes } from './types';
 
@@ -35,6 +42,27 @@ export class ExpressionQueryEditor extends PureComponent<Props> {
 
       case ExpressionQueryType.classic:
         return <ClassicConditions onChange={onChange} query={query} refIds={refIds} />;
+
+      case ExpressionQueryType.threshold:
+        return <Threshold refIds={refIds} onChange={onChange} labelWidth={labelWidth} query={query} />;
+
+      case ExpressionQueryType.movingWindow:
+        return <MovingWindow refIds={refIds} onChange={onChange} labelWidth={labelWidth} query={query} />;
+
+      case ExpressionQueryType.rate:
+        return <Rate refIds={refIds} onChange={onChange} labelWidth={labelWidth} query={query} />;
+
+      case ExpressionQueryType.outliers:
+        return <Outliers refIds={refIds} onChange={onChange} labelWidth={labelWidth} query={query} />;
+
+      case ExpressionQueryType.forecast:
+        return <Forecast refIds={refIds} onChange={onChange} labelWidth={labelWidth} query={query} />;
+
+      case ExpressionQueryType.absent:
+        return <Absent refIds={refIds} onChange={onChange} labelWidth={labelWidth} query={query} />;
+
+      case ExpressionQueryType.join:
+        return <Join refIds={refIds} onChange={onChange} labelWidth={labelWidth} query={query} />;
     }
   }
 
@@ -0,0 +1,51 @@
+import React, { FC, FocusEvent } from 'react';
+import { SelectableValue } from '@grafana/data';
+import { InlineField, InlineFieldRow, Input, Select } from '@grafana/ui';
+import { ExpressionQuery } from '../types';
+
+interface Props {
+  labelWidth: number;
+  refIds: Array<SelectableValue<string>>;
+  query: ExpressionQuery;
+  onChange: (query: ExpressionQuery) => void;
+}
+
+const formatLabels = (labels?: Record<string, string>) =>
+  Object.entries(labels ?? {})
+    .map(([key, value]) => `${key}=${value}`)
+    .join(', ');
+
+const parseLabels = (text: string): Record<string, string> | undefined => {
+  const labels: Record<string, string> = {};
+  text.split(',').forEach((pair) => {
+    const [key, ...value] = pair.split('=');
+    if (key.trim()) {
+      labels[key.trim()] = value.join('=').trim();
+    }
+  });
+  return Object.keys(labels).length ? labels : undefined;
+};
+
+export const Absent: FC<Props> = ({ labelWidth, onChange, refIds, query }) => {
+  const onRefIdChange = (value: SelectableValue<string>) => {
+    onChange({ ...query, expression: value.value });
+  };
+
+  const onLabelsBlur = (event: FocusEvent<HTMLInputElement>) => {
+    onChange({ ...query, labels: parseLabels(event.target.value) });
+  };
+
+  return (
+    <InlineFieldRow>
+      <InlineField label="Input" labelWidth={labelWidth}>
+        <Select menuShouldPortal onChange={onRefIdChange} options={refIds} value={query.expression} width={20} />
+      </InlineField>
+      <InlineField
+        label="Labels"
+        tooltip="Optional. The labels of the expected series, such as job=api, and of the alert when it's absent"
+      >
+        <Input onBlur={onLabelsBlur} defaultValue={formatLabels(query.labels)} placeholder="job=api" width={40} />
+      </InlineField>
+    </InlineFieldRow>
+  );
+};
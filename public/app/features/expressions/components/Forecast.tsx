@@ -0,0 +1,69 @@
+import React, { ChangeEvent, FC } from 'react';
+import { SelectableValue } from '@grafana/data';
+import { InlineField, InlineFieldRow, Input, Select } from '@grafana/ui';
+import { ExpressionQuery, forecastAlgorithms } from '../types';
+
+interface Props {
+  labelWidth: number;
+  refIds: Array<SelectableValue<string>>;
+  query: ExpressionQuery;
+  onChange: (query: ExpressionQuery) => void;
+}
+
+export const Forecast: FC<Props> = ({ labelWidth, onChange, refIds, query }) => {
+  const algorithm = forecastAlgorithms.find((o) => o.value === query.algorithm);
+
+  const onRefIdChange = (value: SelectableValue<string>) => {
+    onChange({ ...query, expression: value.value });
+  };
+
+  const onSelectAlgorithm = (value: SelectableValue<string>) => {
+    onChange({ ...query, algorithm: value.value });
+  };
+
+  const onHorizonChange = (event: ChangeEvent<HTMLInputElement>) => {
+    onChange({ ...query, horizon: event.target.value });
+  };
+
+  const onAlphaChange = (event: ChangeEvent<HTMLInputElement>) => {
+    const alpha = event.target.value === '' ? undefined : parseFloat(event.target.value);
+    onChange({ ...query, alpha });
+  };
+
+  const onBetaChange = (event: ChangeEvent<HTMLInputElement>) => {
+    const beta = event.target.value === '' ? undefined : parseFloat(event.target.value);
+    onChange({ ...query, beta });
+  };
+
+  return (
+    <>
+      <InlineFieldRow>
+        <InlineField label="Algorithm" labelWidth={labelWidth}>
+          <Select
+            menuShouldPortal
+            options={forecastAlgorithms}
+            value={algorithm}
+            onChange={onSelectAlgorithm}
+            width={25}
+          />
+        </InlineField>
+        <InlineField label="Input">
+          <Select menuShouldPortal onChange={onRefIdChange} options={refIds} value={query.expression} width={20} />
+        </InlineField>
+        <InlineField label="Horizon" tooltip="The time after the last point to predict the value at: 30m, 4h, 1d">
+          <Input onChange={onHorizonChange} value={query.horizon} width={15} />
+        </InlineField>
+      </InlineFieldRow>
+      {query.algorithm === 'holt' && (
+        <InlineFieldRow>
+          <InlineField label="Alpha" labelWidth={labelWidth} tooltip="The smoothing factor of the level, 0.5 by default">
+            <Input type="number" onChange={onAlphaChange} value={query.alpha ?? ''} width={12} />
+          </InlineField>
+          <InlineField label="Beta" tooltip="The smoothing factor of the trend, 0.1 by default">
+            <Input type="number" onChange={onBetaChange} value={query.beta ?? ''} width={12} />
+          </InlineField>
+        </InlineFieldRow>
+      )}
+    </>
+  );
+};
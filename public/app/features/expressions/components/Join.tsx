@@ -0,0 +1,63 @@
+import React, { ChangeEvent, FC } from 'react';
+import { SelectableValue } from '@grafana/data';
+import { InlineField, InlineFieldRow, Input, Select, TagsInput } from '@grafana/ui';
+import { ExpressionQuery, joinOperators } from '../types';
+
+interface Props {
+  labelWidth: number;
+  refIds: Array<SelectableValue<string>>;
+  query: ExpressionQuery;
+  onChange: (query: ExpressionQuery) => void;
+}
+
+export const Join: FC<Props> = ({ labelWidth, onChange, refIds, query }) => {
+  const operator = joinOperators.find((o) => o.value === query.operator);
+
+  const onLeftChange = (value: SelectableValue<string>) => {
+    onChange({ ...query, left: value.value });
+  };
+
+  const onRightChange = (value: SelectableValue<string>) => {
+    onChange({ ...query, right: value.value });
+  };
+
+  const onSelectOperator = (value: SelectableValue<string>) => {
+    onChange({ ...query, operator: value.value });
+  };
+
+  const onOnChange = (on: string[]) => {
+    onChange({ ...query, on: on.length ? on : undefined });
+  };
+
+  const onToleranceChange = (event: ChangeEvent<HTMLInputElement>) => {
+    onChange({ ...query, tolerance: event.target.value || undefined });
+  };
+
+  return (
+    <>
+      <InlineFieldRow>
+        <InlineField label="Left" labelWidth={labelWidth}>
+          <Select menuShouldPortal onChange={onLeftChange} options={refIds} value={query.left} width={20} />
+        </InlineField>
+        <InlineField label="Operator">
+          <Select menuShouldPortal options={joinOperators} value={operator} onChange={onSelectOperator} width={15} />
+        </InlineField>
+        <InlineField label="Right">
+          <Select menuShouldPortal onChange={onRightChange} options={refIds} value={query.right} width={20} />
+        </InlineField>
+      </InlineFieldRow>
+      <InlineFieldRow>
+        <InlineField
+          label="On"
+          labelWidth={labelWidth}
+          tooltip="Optional. The labels the series must have the same values for, their shared labels by default"
+        >
+          <TagsInput tags={query.on ?? []} onChange={onOnChange} placeholder="Label" />
+        </InlineField>
+        <InlineField label="Tolerance" tooltip="Optional. The maximum time between the paired points: 10s, 1m">
+          <Input onChange={onToleranceChange} value={query.tolerance ?? ''} width={15} />
+        </InlineField>
+      </InlineFieldRow>
+    </>
+  );
+};
@@ -0,0 +1,41 @@
+import React, { ChangeEvent, FC } from 'react';
+import { SelectableValue } from '@grafana/data';
+import { InlineField, InlineFieldRow, Input, Select } from '@grafana/ui';
+import { ExpressionQuery, movingWindowTypes } from '../types';
+
+interface Props {
+  labelWidth: number;
+  refIds: Array<SelectableValue<string>>;
+  query: ExpressionQuery;
+  onChange: (query: ExpressionQuery) => void;
+}
+
+export const MovingWindow: FC<Props> = ({ labelWidth, onChange, refIds, query }) => {
+  const reducer = movingWindowTypes.find((o) => o.value === query.reducer);
+
+  const onRefIdChange = (value: SelectableValue<string>) => {
+    onChange({ ...query, expression: value.value });
+  };
+
+  const onWindowChange = (event: ChangeEvent<HTMLInputElement>) => {
+    onChange({ ...query, window: event.target.value });
+  };
+
+  const onSelectReducer = (value: SelectableValue<string>) => {
+    onChange({ ...query, reducer: value.value });
+  };
+
+  return (
+    <InlineFieldRow>
+      <InlineField label="Function" labelWidth={labelWidth}>
+        <Select menuShouldPortal options={movingWindowTypes} value={reducer} onChange={onSelectReducer} width={20} />
+      </InlineField>
+      <InlineField label="Input">
+        <Select menuShouldPortal onChange={onRefIdChange} options={refIds} value={query.expression} width={20} />
+      </InlineField>
+      <InlineField label="Window" tooltip="10s, 1m, 30m, 1h">
+        <Input onChange={onWindowChange} value={query.window} width={15} />
+      </InlineField>
+    </InlineFieldRow>
+  );
+};
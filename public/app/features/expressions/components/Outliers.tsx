@@ -0,0 +1,41 @@
+import React, { ChangeEvent, FC } from 'react';
+import { SelectableValue } from '@grafana/data';
+import { InlineField, InlineFieldRow, Input, Select } from '@grafana/ui';
+import { ExpressionQuery, outlierAlgorithms } from '../types';
+
+interface Props {
+  labelWidth: number;
+  refIds: Array<SelectableValue<string>>;
+  query: ExpressionQuery;
+  onChange: (query: ExpressionQuery) => void;
+}
+
+export const Outliers: FC<Props> = ({ labelWidth, onChange, refIds, query }) => {
+  const algorithm = outlierAlgorithms.find((o) => o.value === query.algorithm);
+
+  const onRefIdChange = (value: SelectableValue<string>) => {
+    onChange({ ...query, expression: value.value });
+  };
+
+  const onSelectAlgorithm = (value: SelectableValue<string>) => {
+    onChange({ ...query, algorithm: value.value });
+  };
+
+  const onThresholdChange = (event: ChangeEvent<HTMLInputElement>) => {
+    onChange({ ...query, threshold: parseFloat(event.target.value) });
+  };
+
+  return (
+    <InlineFieldRow>
+      <InlineField label="Algorithm" labelWidth={labelWidth}>
+        <Select menuShouldPortal options={outlierAlgorithms} value={algorithm} onChange={onSelectAlgorithm} width={25} />
+      </InlineField>
+      <InlineField label="Input">
+        <Select menuShouldPortal onChange={onRefIdChange} options={refIds} value={query.expression} width={20} />
+      </InlineField>
+      <InlineField label="Threshold" tooltip={algorithm?.description}>
+        <Input type="number" onChange={onThresholdChange} value={query.threshold} width={12} />
+      </InlineField>
+    </InlineFieldRow>
+  );
+};
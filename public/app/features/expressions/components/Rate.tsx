@@ -0,0 +1,32 @@
+import React, { FC, FormEvent } from 'react';
+import { SelectableValue } from '@grafana/data';
+import { InlineField, InlineFieldRow, InlineSwitch, Select } from '@grafana/ui';
+import { ExpressionQuery } from '../types';
+
+interface Props {
+  labelWidth: number;
+  refIds: Array<SelectableValue<string>>;
+  query: ExpressionQuery;
+  onChange: (query: ExpressionQuery) => void;
+}
+
+export const Rate: FC<Props> = ({ labelWidth, onChange, refIds, query }) => {
+  const onRefIdChange = (value: SelectableValue<string>) => {
+    onChange({ ...query, expression: value.value });
+  };
+
+  const onCounterChange = (event: FormEvent<HTMLInputElement>) => {
+    onChange({ ...query, counter: event.currentTarget.checked });
+  };
+
+  return (
+    <InlineFieldRow>
+      <InlineField label="Input" labelWidth={labelWidth}>
+        <Select menuShouldPortal onChange={onRefIdChange} options={refIds} value={query.expression} width={20} />
+      </InlineField>
+      <InlineField label="Counter" tooltip="The input is a cumulative counter, a decrease is a reset of the counter">
+        <InlineSwitch value={query.counter ?? false} onChange={onCounterChange} />
+      </InlineField>
+    </InlineFieldRow>
+  );
+};
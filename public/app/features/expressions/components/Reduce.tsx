@@ -11,7 +11,8 @@ interface Props {
 }
 
 export const Reduce: FC<Props> = ({ labelWidth, onChange, refIds, query }) => {
-  const reducer = reducerTypes.find((o) => o.value === query.reducer);
+  // Other percentiles, such as p99.9, are custom values.
+  const reducer = reducerTypes.find((o) => o.value === query.reducer) ?? { value: query.reducer, label: query.reducer };
 
   const onRefIdChange = (value: SelectableValue<string>) => {
     onChange({ ...query, expression: value.value });
@@ -24,7 +25,14 @@ export const Reduce: FC<Props> = ({ labelWidth, onChange, refIds, query }) => {
   return (
     <InlineFieldRow>
       <InlineField label="Function" labelWidth={labelWidth}>
-        <Select menuShouldPortal options={reducerTypes} value={reducer} onChange={onSelectReducer} width={25} />
+        <Select
+          menuShouldPortal
+          allowCustomValue
+          options={reducerTypes}
+          value={reducer}
+          onChange={onSelectReducer}
+          width={25}
+        />
       </InlineField>
       <InlineField label="Input" labelWidth={labelWidth}>
         <Select menuShouldPortal onChange={onRefIdChange} options={refIds} value={query.expression} width={20} />
@@ -0,0 +1,58 @@
+import React, { ChangeEvent, FC } from 'react';
+import { SelectableValue } from '@grafana/data';
+import { InlineField, InlineFieldRow, Input, Select } from '@grafana/ui';
+import { ExpressionQuery, thresholdTypes } from '../types';
+
+interface Props {
+  labelWidth: number;
+  refIds: Array<SelectableValue<string>>;
+  query: ExpressionQuery;
+  onChange: (query: ExpressionQuery) => void;
+}
+
+export const Threshold: FC<Props> = ({ labelWidth, onChange, refIds, query }) => {
+  const thresholdType = thresholdTypes.find((o) => o.value === query.thresholdType);
+
+  const onRefIdChange = (value: SelectableValue<string>) => {
+    onChange({ ...query, expression: value.value });
+  };
+
+  const onSelectThresholdType = (value: SelectableValue<string>) => {
+    onChange({ ...query, thresholdType: value.value });
+  };
+
+  const onEnterChange = (event: ChangeEvent<HTMLInputElement>) => {
+    onChange({ ...query, enter: parseFloat(event.target.value) });
+  };
+
+  const onExitChange = (event: ChangeEvent<HTMLInputElement>) => {
+    const exit = event.target.value === '' ? undefined : parseFloat(event.target.value);
+    onChange({ ...query, exit });
+  };
+
+  return (
+    <InlineFieldRow>
+      <InlineField label="Input" labelWidth={labelWidth}>
+        <Select menuShouldPortal onChange={onRefIdChange} options={refIds} value={query.expression} width={20} />
+      </InlineField>
+      <InlineField label="Is">
+        <Select
+          menuShouldPortal
+          options={thresholdTypes}
+          value={thresholdType}
+          onChange={onSelectThresholdType}
+          width={15}
+        />
+      </InlineField>
+      <InlineField label="Enter" tooltip="The value to pass to start firing">
+        <Input type="number" onChange={onEnterChange} value={query.enter} width={12} />
+      </InlineField>
+      <InlineField
+        label="Exit"
+        tooltip="Optional. The value a firing series must pass back to stop firing, to avoid flapping around the enter value"
+      >
+        <Input type="number" onChange={onExitChange} value={query.exit ?? ''} width={12} />
+      </InlineField>
+    </InlineFieldRow>
+  );
+};
@@ -6,6 +6,13 @@ export enum ExpressionQueryType {
   reduce = 'reduce',
   resample = 'resample',
   classic = 'classic_conditions',
+  threshold = 'threshold',
+  movingWindow = 'moving_window',
+  rate = 'rate',
+  outliers = 'outliers',
+  forecast = 'forecast',
+  absent = 'absent',
+  join = 'join',
 }
 
 export const gelTypes: Array<SelectableValue<ExpressionQueryType>> = [
@@ -13,6 +20,13 @@ export const gelTypes: Array<SelectableValue<ExpressionQueryType>> = [
   { value: ExpressionQueryType.reduce, label: 'Reduce' },
   { value: ExpressionQueryType.resample, label: 'Resample' },
   { value: ExpressionQueryType.classic, label: 'Classic condition' },
+  { value: ExpressionQueryType.threshold, label: 'Threshold' },
+  { value: ExpressionQueryType.movingWindow, label: 'Moving window' },
+  { value: ExpressionQueryType.rate, label: 'Rate' },
+  { value: ExpressionQueryType.outliers, label: 'Outliers' },
+  { value: ExpressionQueryType.forecast, label: 'Forecast' },
+  { value: ExpressionQueryType.absent, label: 'Absent' },
+  { value: ExpressionQueryType.join, label: 'Join' },
 ];
 
 export const reducerTypes: Array<SelectableValue<string>> = [
@@ -21,6 +35,13 @@ export const reducerTypes: Array<SelectableValue<string>> = [
   { value: ReducerID.mean, label: 'Mean', description: 'Get the average value' },
   { value: ReducerID.sum, label: 'Sum', description: 'Get the sum of all values' },
   { value: ReducerID.count, label: 'Count', description: 'Get the number of values' },
+  { value: ReducerID.last, label: 'Last', description: 'Get the last value' },
+  { value: 'median', label: 'Median', description: 'Get the median value' },
+  { value: 'p90', label: 'p90', description: 'Get the 90th percentile' },
+  { value: 'p95', label: 'p95', description: 'Get the 95th percentile' },
+  { value: 'p99', label: 'p99', description: 'Get the 99th percentile' },
+  { value: 'stddev', label: 'Standard deviation', description: 'Get the standard deviation of the values' },
+  { value: 'variance', label: 'Variance', description: 'Get the variance of the values' },
 ];
 
 export const downsamplingTypes: Array<SelectableValue<string>> = [
@@ -30,6 +51,36 @@ export const downsamplingTypes: Array<SelectableValue<string>> = [
   { value: ReducerID.sum, label: 'Sum', description: 'Fill with the sum of all values' },
 ];
 
+export const movingWindowTypes: Array<SelectableValue<string>> = [
+  { value: ReducerID.mean, label: 'Mean', description: 'The average value of the window' },
+  { value: ReducerID.min, label: 'Min', description: 'The minimum value of the window' },
+  { value: ReducerID.max, label: 'Max', description: 'The maximum value of the window' },
+  { value: ReducerID.sum, label: 'Sum', description: 'The sum of the values of the window' },
+];
+
+export const outlierAlgorithms: Array<SelectableValue<string>> = [
+  { value: 'zscore', label: 'Z-score', description: 'The number of standard deviations from the mean' },
+  { value: 'mad', label: 'MAD', description: 'The number of median absolute deviations from the median' },
+  { value: 'dbscan', label: 'DBSCAN', description: 'The maximum distance between the values of a group' },
+];
+
+export const forecastAlgorithms: Array<SelectableValue<string>> = [
+  { value: 'linear', label: 'Linear regression', description: 'Fit a line to the points of the series' },
+  { value: 'holt', label: 'Holt', description: 'Follow the recent changes of the trend more closely' },
+];
+
+export const joinOperators: Array<SelectableValue<string>> = [
+  { value: '+', label: '+' },
+  { value: '-', label: '-' },
+  { value: '*', label: '*' },
+  { value: '/', label: '/' },
+];
+
+export const thresholdTypes: Array<SelectableValue<string>> = [
+  { value: 'gt', label: 'Above' },
+  { value: 'lt', label: 'Below' },
+];
+
 export const upsamplingTypes: Array<SelectableValue<string>> = [
   { value: 'pad', label: 'pad', description: 'fill with the last known value' },
   { value: 'backfilling', label: 'backfilling', description: 'fill with the next known value' },
@@ -48,6 +99,21 @@ export interface ExpressionQuery extends DataQuery {
   downsampler?: string;
   upsampler?: string;
   conditions?: ClassicCondition[];
+  thresholdType?: string;
+  enter?: number;
+  exit?: number;
+  counter?: boolean;
+  algorithm?: string;
+  threshold?: number;
+  horizon?: string;
+  alpha?: number;
+  beta?: number;
+  labels?: Record<string, string>;
+  left?: string;
+  right?: string;
+  operator?: string;
+  on?: string[];
+  tolerance?: string;
 }
 export interface ClassicCondition {
   evaluator: {
@@ -29,6 +29,67 @@ export const getDefaults = (query: ExpressionQuery) => {
       }
       break;
 
+    case ExpressionQueryType.threshold:
+      if (!query.thresholdType) {
+        query.thresholdType = 'gt';
+      }
+      if (query.enter === undefined) {
+        query.enter = 0;
+      }
+      query.reducer = undefined;
+      query.expression = undefined;
+      break;
+
+    case ExpressionQueryType.movingWindow:
+      if (!query.reducer) {
+        query.reducer = ReducerID.mean;
+      }
+      if (!query.window) {
+        query.window = '5m';
+      }
+      query.expression = undefined;
+      break;
+
+    case ExpressionQueryType.rate:
+      query.reducer = undefined;
+      query.expression = undefined;
+      break;
+
+    case ExpressionQueryType.outliers:
+      if (!query.algorithm) {
+        query.algorithm = 'mad';
+      }
+      if (query.threshold === undefined) {
+        query.threshold = 3;
+      }
+      query.reducer = undefined;
+      query.expression = undefined;
+      break;
+
+    case ExpressionQueryType.forecast:
+      if (!query.algorithm) {
+        query.algorithm = 'linear';
+      }
+      if (!query.horizon) {
+        query.horizon = '1h';
+      }
+      query.reducer = undefined;
+      query.expression = undefined;
+      break;
+
+    case ExpressionQueryType.absent:
+      query.reducer = undefined;
+      query.expression = undefined;
+      break;
+
+    case ExpressionQueryType.join:
+      if (!query.operator) {
+        query.operator = '/';
+      }
+      query.reducer = undefined;
+      query.expression = undefined;
+      break;
+
     default:
       query.reducer = undefined;
   }
@@ -3,6 +3,8 @@
 export type AlertManagerCortexConfig = {
   template_files: Record<string, string>;
   alertmanager_config: AlertmanagerConfig;
+  /** the fingerprint of the Grafana Alertmanager configuration, sent back with the changes */
+  fingerprint?: string;
 };
 
 export type TLSConfig = {
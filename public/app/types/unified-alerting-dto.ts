@@ -31,6 +31,7 @@ interface PromRuleDTOBase {
   evaluationTime?: number;
   lastEvaluation?: string;
   lastError?: string;
+  warnings?: string[];
 }
 
 export interface PromAlertingRuleDTO extends PromRuleDTOBase {
@@ -106,14 +107,46 @@ interface AlertDataQuery extends DataQuery {
   intervalMs?: number;
 }
 
+export interface AlertQueryTimeRange extends RelativeTimeRange {
+  /** The seconds the time range is moved back by, such as a day to compare with the same time yesterday. */
+  timeShift?: number;
+}
+
 export interface AlertQuery {
   refId: string;
   queryType: string;
-  relativeTimeRange?: RelativeTimeRange;
+  relativeTimeRange?: AlertQueryTimeRange;
   datasourceUid: string;
   model: AlertDataQuery;
 }
 
+export interface CompositeCondition {
+  rule_uids: string[];
+  min_firing?: number;
+}
+
+export interface HeartbeatCondition {
+  token?: string;
+  timeout_seconds: number;
+}
+
+export interface RuleLinks {
+  runbook_url?: string;
+  dashboard_uid?: string;
+  panel_id?: number;
+}
+
+export interface Threshold {
+  value: number;
+  labels: Labels;
+}
+
+export interface Thresholds {
+  ref_id: string;
+  type: 'gt' | 'lt';
+  levels: Threshold[];
+}
+
 export interface PostableGrafanaRuleDefinition {
   uid?: string;
   title: string;
@@ -121,6 +154,16 @@ export interface PostableGrafanaRuleDefinition {
   no_data_state: GrafanaAlertStateDecision;
   exec_err_state: GrafanaAlertStateDecision;
   data: AlertQuery[];
+  version?: number;
+  is_paused?: boolean;
+  composite?: CompositeCondition;
+  thresholds?: Thresholds;
+  heartbeat?: HeartbeatCondition;
+  links?: RuleLinks;
+  shadow_of?: string;
+  active_from?: string;
+  active_until?: string;
+  hold_on_datasource_maintenance?: boolean;
 }
 export interface GrafanaRuleDefinition extends PostableGrafanaRuleDefinition {
   uid: string;
@@ -150,6 +193,7 @@ export type RulerRuleGroupDTO<R = RulerRuleDTO> = {
   name: string;
   interval?: string;
   rules: R[];
+  concurrency?: number;
 };
 
 export type PostableRulerRuleGroupDTO = RulerRuleGroupDTO<PostableRuleDTO>;
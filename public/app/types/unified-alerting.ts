@@ -25,6 +25,7 @@ interface RuleBase {
   lastEvaluation?: string;
   evaluationTime?: number;
   lastError?: string;
+  warnings?: string[];
 }
 
 export interface AlertingRule extends RuleBase {